@@ -6,28 +6,62 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"os/signal"
+	"regexp"
+	"strconv"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
+	"pandapages/api/internal/alignment"
+	"pandapages/api/internal/blob"
 	"pandapages/api/internal/db"
+	"pandapages/api/internal/delivery"
+	"pandapages/api/internal/dictionary"
+	"pandapages/api/internal/digest"
+	"pandapages/api/internal/embedding"
+	"pandapages/api/internal/gutenberg"
 	"pandapages/api/internal/httpadmin"
 	"pandapages/api/internal/httpapi"
 	"pandapages/api/internal/httpmiddleware"
+	"pandapages/api/internal/llm"
+	"pandapages/api/internal/maintenance"
+	"pandapages/api/internal/moderation"
+	"pandapages/api/internal/phonics"
+	"pandapages/api/internal/ratelimit"
+	"pandapages/api/internal/renderjobs"
 	"pandapages/api/internal/session"
+	"pandapages/api/internal/tracing"
+	"pandapages/api/internal/tts"
+	"pandapages/api/internal/urlimport"
+	"pandapages/api/internal/webhooks"
+	"pandapages/api/internal/webpush"
 )
 
 const (
-	listenAddress     = ":8080"
-	readHeaderTimeout = 5 * time.Second
-	readTimeout       = 5 * time.Minute
-	writeTimeout      = 6 * time.Minute
-	idleTimeout       = 60 * time.Second
-	shutdownTimeout   = 10 * time.Second
-	maxHeaderBytes    = 1 << 20 // 1 MiB
+	defaultListenAddress = ":8080"
+	readHeaderTimeout    = 5 * time.Second
+	readTimeout          = 5 * time.Minute
+	writeTimeout         = 6 * time.Minute
+	idleTimeout          = 60 * time.Second
+	// Queued spans get this long to reach the collector on exit.
+	traceFlushTimeout = 5 * time.Second
+	// Shutdown lets in-flight requests, such as a large ingest, finish for
+	// this long before closing their connections. Compose's stop grace
+	// period must exceed it.
+	defaultShutdownTimeout = 30 * time.Second
+	maxHeaderBytes         = 1 << 20 // 1 MiB
+
+	// A Reader page turn is a read or two and a progress write, so these
+	// leave a fast reader plenty of room while capping a runaway loop well
+	// below what the connection pool can serve.
+	defaultReadsPerMinute  = 600
+	defaultWritesPerMinute = 120
 )
 
 type runtimeConfig struct {
@@ -35,8 +69,51 @@ type runtimeConfig struct {
 	passcode      string
 	adminKey      string
 	cookieSecure  bool
+	listenAddress string
+	tls           tlsSettings
 	logLevel      slog.Level
 	sessionSigner *session.Manager
+	database      db.Options
+	warmStories   int
+
+	readsPerMinute  int
+	writesPerMinute int
+	trustProxy      bool
+	shutdownTimeout time.Duration
+	tracing         bool
+	maintenance     bool
+
+	sensitivityWords []string
+	// tts narrates story versions and llm writes them; each is nil when no
+	// provider is configured.
+	tts tts.Provider
+	llm llm.Provider
+	// moderation reviews the stories llm writes before they are saved; nil
+	// when no provider is configured.
+	moderation moderation.Provider
+	// alignment times narrated words for read-along highlighting; nil when
+	// no provider is configured.
+	alignment alignment.Provider
+	// smtp emails stories to readers' devices; nil when no relay is
+	// configured.
+	smtp *delivery.SMTP
+	// pushSubject is the contact push services see with Web Push
+	// notifications; empty when push is off.
+	pushSubject string
+	// embedding turns stories and queries into vectors for semantic search;
+	// nil when no provider is configured.
+	embedding embedding.Provider
+	// gutenberg is the catalog the Studio searches for books to import; nil
+	// when it is turned off.
+	gutenberg *gutenberg.Catalog
+	// fetcher downloads documents the Studio imports from an address; nil
+	// when that is turned off.
+	fetcher *urlimport.Fetcher
+	// dictionary defines the words readers tap; nil when it is turned off.
+	dictionary *dictionary.Client
+	// phonics is the progression stories are scored against for the
+	// Library's decodable filter; nil when it is turned off.
+	phonics *phonics.Progression
 }
 
 func loadRuntimeConfig(getenv func(string) string) (runtimeConfig, error) {
@@ -50,22 +127,285 @@ func loadRuntimeConfig(getenv func(string) string) (runtimeConfig, error) {
 		return runtimeConfig{}, err
 	}
 
-	cookieSecure := getenv("PP_COOKIE_SECURE") == "true"
+	database := db.DefaultOptions()
+	database.ReplicaURL = strings.TrimSpace(getenv("DATABASE_URL_RO"))
+	if raw := strings.TrimSpace(getenv("PP_DB_SLOW_QUERY_MS")); raw != "" {
+		ms, err := strconv.Atoi(raw)
+		if err != nil || ms < 0 {
+			return runtimeConfig{}, fmt.Errorf("PP_DB_SLOW_QUERY_MS must be a non-negative whole number of milliseconds")
+		}
+		database.SlowQueryThreshold = time.Duration(ms) * time.Millisecond
+	}
+	if raw := strings.TrimSpace(getenv("PP_DB_MAX_CONNS")); raw != "" {
+		conns, err := strconv.ParseInt(raw, 10, 32)
+		if err != nil || conns < 1 {
+			return runtimeConfig{}, fmt.Errorf("PP_DB_MAX_CONNS must be a positive whole number")
+		}
+		database.MaxConns = int32(conns)
+		database.MinConns = min(database.MinConns, database.MaxConns)
+	}
+	if raw := strings.TrimSpace(getenv("PP_DB_CONNECT_RETRY_SECONDS")); raw != "" {
+		seconds, err := strconv.Atoi(raw)
+		if err != nil || seconds < 0 {
+			return runtimeConfig{}, fmt.Errorf("PP_DB_CONNECT_RETRY_SECONDS must be a non-negative whole number")
+		}
+		database.ConnectRetry = time.Duration(seconds) * time.Second
+	}
+
+	warmStories := 0
+	if raw := strings.TrimSpace(getenv("PP_WARM_STORIES")); raw != "" {
+		if warmStories, err = strconv.Atoi(raw); err != nil || warmStories < 0 {
+			return runtimeConfig{}, fmt.Errorf("PP_WARM_STORIES must be a non-negative whole number")
+		}
+	}
+
+	readsPerMinute, err := parsePerMinute(getenv, "PP_RATE_LIMIT_READS_PER_MINUTE", defaultReadsPerMinute)
+	if err != nil {
+		return runtimeConfig{}, err
+	}
+	writesPerMinute, err := parsePerMinute(getenv, "PP_RATE_LIMIT_WRITES_PER_MINUTE", defaultWritesPerMinute)
+	if err != nil {
+		return runtimeConfig{}, err
+	}
+
+	shutdownTimeout := defaultShutdownTimeout
+	if raw := strings.TrimSpace(getenv("PP_SHUTDOWN_TIMEOUT_SECONDS")); raw != "" {
+		seconds, err := strconv.Atoi(raw)
+		if err != nil || seconds < 1 {
+			return runtimeConfig{}, fmt.Errorf("PP_SHUTDOWN_TIMEOUT_SECONDS must be a positive whole number")
+		}
+		shutdownTimeout = time.Duration(seconds) * time.Second
+	}
+
+	listenAddress := defaultListenAddress
+	if raw := strings.TrimSpace(getenv("PP_LISTEN_ADDR")); raw != "" {
+		if _, _, err := net.SplitHostPort(raw); err != nil {
+			return runtimeConfig{}, fmt.Errorf("PP_LISTEN_ADDR must be host:port or :port")
+		}
+		listenAddress = raw
+	}
+	tls, err := loadTLSSettings(getenv)
+	if err != nil {
+		return runtimeConfig{}, err
+	}
+
+	// Cookies served over the API's own TLS are always secure.
+	cookieSecure := getenv("PP_COOKIE_SECURE") == "true" || tls.enabled()
 	sessionSigner, err := session.New(getenv("PP_SESSION_SECRET"), cookieSecure)
 	if err != nil {
 		return runtimeConfig{}, fmt.Errorf("PP_SESSION_SECRET is invalid: %w", err)
 	}
 
+	databaseURL := strings.TrimSpace(getenv("DATABASE_URL"))
+	if databaseURL == "" {
+		return runtimeConfig{}, fmt.Errorf("DATABASE_URL is required")
+	}
+	if err := db.ValidateURL(databaseURL); err != nil {
+		return runtimeConfig{}, fmt.Errorf("DATABASE_URL is invalid: %w", err)
+	}
+	if database.ReplicaURL != "" {
+		if err := db.ValidateURL(database.ReplicaURL); err != nil {
+			return runtimeConfig{}, fmt.Errorf("DATABASE_URL_RO is invalid: %w", err)
+		}
+	}
+
+	speech, err := tts.Load(getenv)
+	if err != nil {
+		return runtimeConfig{}, err
+	}
+	recognizer, err := alignment.Load(getenv)
+	if err != nil {
+		return runtimeConfig{}, err
+	}
+	writer, err := llm.Load(getenv)
+	if err != nil {
+		return runtimeConfig{}, err
+	}
+	moderator, err := moderation.Load(getenv)
+	if err != nil {
+		return runtimeConfig{}, err
+	}
+	relay, err := delivery.Load(getenv)
+	if err != nil {
+		return runtimeConfig{}, err
+	}
+	pushSubject, err := webpush.Load(getenv)
+	if err != nil {
+		return runtimeConfig{}, err
+	}
+	embedder, err := embedding.Load(getenv)
+	if err != nil {
+		return runtimeConfig{}, err
+	}
+	catalog, err := gutenberg.Load(getenv)
+	if err != nil {
+		return runtimeConfig{}, err
+	}
+	fetcher, err := urlimport.Load(getenv)
+	if err != nil {
+		return runtimeConfig{}, err
+	}
+	words, err := dictionary.Load(getenv)
+	if err != nil {
+		return runtimeConfig{}, err
+	}
+	progression, err := phonics.Load(getenv)
+	if err != nil {
+		return runtimeConfig{}, err
+	}
+	if database.Blobs, err = blob.Load(getenv); err != nil {
+		return runtimeConfig{}, err
+	}
+
 	return runtimeConfig{
-		databaseURL:   getenv("DATABASE_URL"),
+		databaseURL:   databaseURL,
 		passcode:      passcode,
 		adminKey:      strings.TrimSpace(getenv("PP_ADMIN_KEY")),
 		cookieSecure:  cookieSecure,
+		listenAddress: listenAddress,
+		tls:           tls,
 		logLevel:      logLevel,
 		sessionSigner: sessionSigner,
+		database:      database,
+		warmStories:   warmStories,
+
+		readsPerMinute:  readsPerMinute,
+		writesPerMinute: writesPerMinute,
+		trustProxy:      getenv("PP_TRUST_PROXY") == "true",
+		shutdownTimeout: shutdownTimeout,
+		tracing:         tracing.Configured(getenv),
+		maintenance:     getenv("PP_MAINTENANCE") == "true",
+
+		sensitivityWords: splitList(getenv("PP_SENSITIVITY_WORDS")),
+		tts:              speech,
+		llm:              writer,
+		moderation:       moderator,
+		alignment:        recognizer,
+		smtp:             relay,
+		pushSubject:      pushSubject,
+		embedding:        embedder,
+		gutenberg:        catalog,
+		fetcher:          fetcher,
+		dictionary:       words,
+		phonics:          progression,
 	}, nil
 }
 
+// summary lists the effective settings for the startup log. Secrets are
+// never included: the passcode, session secret and admin key appear only as
+// whether they are set, and database URLs lose their passwords.
+func (cfg runtimeConfig) summary() []any {
+	relay := "off"
+	if cfg.smtp != nil {
+		relay = cfg.smtp.Name()
+	}
+	catalog := "off"
+	if cfg.gutenberg != nil {
+		catalog = cfg.gutenberg.Name()
+	}
+	fetchScope := "off"
+	if cfg.fetcher != nil {
+		fetchScope = cfg.fetcher.Scope()
+	}
+	words := "off"
+	if cfg.dictionary != nil {
+		words = cfg.dictionary.Name()
+	}
+	progression := "off"
+	if cfg.phonics != nil {
+		progression = cfg.phonics.Name()
+	}
+	blobs := "database"
+	if cfg.database.Blobs != nil {
+		blobs = cfg.database.Blobs.Name()
+	}
+	return []any{
+		"database", redactDatabaseURL(cfg.databaseURL),
+		"database_replica", redactDatabaseURL(cfg.database.ReplicaURL),
+		"db_max_conns", cfg.database.MaxConns,
+		"db_slow_query", cfg.database.SlowQueryThreshold.String(),
+		"db_connect_retry", cfg.database.ConnectRetry.String(),
+		"blob_store", blobs,
+		"listen", cfg.listenAddress,
+		"tls", cfg.tls.mode(),
+		"cookie_secure", cfg.cookieSecure,
+		"admin_key_set", cfg.adminKey != "",
+		"log_level", cfg.logLevel.String(),
+		"warm_stories", cfg.warmStories,
+		"reads_per_minute", cfg.readsPerMinute,
+		"writes_per_minute", cfg.writesPerMinute,
+		"trust_proxy", cfg.trustProxy,
+		"shutdown_timeout", cfg.shutdownTimeout.String(),
+		"tracing", cfg.tracing,
+		"maintenance", cfg.maintenance,
+		"sensitivity_words", len(cfg.sensitivityWords),
+		"tts", providerName(cfg.tts),
+		"alignment", providerName(cfg.alignment),
+		"llm", providerName(cfg.llm),
+		"moderation", providerName(cfg.moderation),
+		"smtp", relay,
+		"push", cfg.pushSubject != "",
+		"embedding", providerName(cfg.embedding),
+		"gutenberg", catalog,
+		"import_fetch", fetchScope,
+		"dictionary", words,
+		"phonics", progression,
+	}
+}
+
+// providerName is a configured provider's name, or off.
+func providerName(provider interface{ Name() string }) string {
+	if provider == nil {
+		return "off"
+	}
+	return provider.Name()
+}
+
+// keywordPassword matches the password of a keyword/value connection string,
+// quoted or not.
+var keywordPassword = regexp.MustCompile(`password\s*=\s*(?:'(?:\\.|[^'])*'|\S+)`)
+
+// redactDatabaseURL hides the password of a URL or keyword/value connection
+// string, whether given as userinfo or as a parameter.
+func redactDatabaseURL(raw string) string {
+	if raw == "" {
+		return ""
+	}
+	if parsed, err := url.Parse(raw); err == nil && parsed.Scheme != "" {
+		query := parsed.Query()
+		if query.Has("password") {
+			query.Set("password", "xxxxx")
+			parsed.RawQuery = query.Encode()
+		}
+		return parsed.Redacted()
+	}
+	return keywordPassword.ReplaceAllString(raw, "password=xxxxx")
+}
+
+// parsePerMinute reads a rate limit; 0 turns that limit off.
+func parsePerMinute(getenv func(string) string, name string, fallback int) (int, error) {
+	raw := strings.TrimSpace(getenv(name))
+	if raw == "" {
+		return fallback, nil
+	}
+	perMinute, err := strconv.Atoi(raw)
+	if err != nil || perMinute < 0 {
+		return 0, fmt.Errorf("%s must be a non-negative whole number", name)
+	}
+	return perMinute, nil
+}
+
+// splitList parses a comma-separated setting, dropping blank entries.
+func splitList(raw string) []string {
+	var values []string
+	for _, value := range strings.Split(raw, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
 func parseLogLevel(raw string) (slog.Level, error) {
 	switch strings.ToLower(strings.TrimSpace(raw)) {
 	case "", "info":
@@ -81,8 +421,8 @@ func parseLogLevel(raw string) (slog.Level, error) {
 	}
 }
 
-func newLogger(output io.Writer, level slog.Level) *slog.Logger {
-	return slog.New(slog.NewTextHandler(output, &slog.HandlerOptions{Level: level}))
+func newLogger(output io.Writer, level slog.Leveler) *slog.Logger {
+	return slog.New(httpmiddleware.NewLogHandler(slog.NewTextHandler(output, &slog.HandlerOptions{Level: level})))
 }
 
 func validPasscode(passcode string) bool {
@@ -97,9 +437,9 @@ func validPasscode(passcode string) bool {
 	return true
 }
 
-func newServer(handler http.Handler) *http.Server {
+func newServer(addr string, handler http.Handler) *http.Server {
 	return &http.Server{
-		Addr:              listenAddress,
+		Addr:              addr,
 		Handler:           handler,
 		ReadHeaderTimeout: readHeaderTimeout,
 		ReadTimeout:       readTimeout,
@@ -124,32 +464,139 @@ func run() error {
 		return err
 	}
 
-	slog.SetDefault(newLogger(os.Stderr, cfg.logLevel))
-	slog.Debug("logging configured", "level", cfg.logLevel.String())
+	// The admin API can change the level at runtime, so investigating an
+	// issue does not need a restart that loses the reproduction.
+	logLevel := new(slog.LevelVar)
+	logLevel.Set(cfg.logLevel)
+	slog.SetDefault(newLogger(os.Stderr, logLevel))
+	slog.Info("effective configuration", cfg.summary()...)
+
+	if cfg.tracing {
+		shutdownTracing, err := tracing.Start(context.Background())
+		if err != nil {
+			return err
+		}
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), traceFlushTimeout)
+			defer cancel()
+			if err := shutdownTracing(ctx); err != nil {
+				slog.Warn("trace export incomplete", "err", err)
+			}
+		}()
+		slog.Info("tracing enabled")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	store := db.MustOpen(cfg.databaseURL)
+	// A stop signal also ends the wait for a database that is still starting.
+	store, err := db.Open(ctx, cfg.databaseURL, cfg.database)
+	if err != nil {
+		return err
+	}
 	defer store.Close()
 
+	// Warming is best effort: a failure costs only the first reader's wait.
+	if cfg.warmStories > 0 {
+		warmed, err := store.Warm(ctx, cfg.warmStories)
+		if err != nil {
+			slog.Warn("cache warm-up incomplete", "stories", warmed, "err", err)
+		} else {
+			slog.Info("cache warmed", "stories", warmed)
+		}
+	}
+
+	// Every instance signs pushes with the key the first one stored.
+	var pushSender *webpush.Sender
+	if cfg.pushSubject != "" {
+		pushSender, err = webpush.New(ctx, store, cfg.pushSubject)
+		if err != nil {
+			return err
+		}
+	}
+
+	// One switch serves both handlers: admin routes flip it, reader routes
+	// honour it.
+	maintenanceSwitch := maintenance.New(cfg.maintenance)
+	if cfg.maintenance {
+		slog.Warn("maintenance mode on; reader routes answer 503")
+	}
+
 	public := httpapi.New(httpapi.Config{
 		Passcode: cfg.passcode,
 		Sessions: cfg.sessionSigner,
+
+		ReadLimit:         ratelimit.New(cfg.readsPerMinute),
+		WriteLimit:        ratelimit.New(cfg.writesPerMinute),
+		TrustForwardedFor: cfg.trustProxy,
+		Maintenance:       maintenanceSwitch,
+		Delivery:          cfg.smtp != nil,
+		Embedder:          cfg.embedding,
+		Dictionary:        cfg.dictionary,
+		Phonics:           cfg.phonics,
+		Push:              pushSender,
+		Blobs:             cfg.database.Blobs,
 	}, store)
 
 	admin := httpadmin.New(httpadmin.Config{
 		AdminKey: cfg.adminKey,
 		Sessions: cfg.sessionSigner,
+
+		SensitivityWords: cfg.sensitivityWords,
+		Maintenance:      maintenanceSwitch,
+		LogLevel:         logLevel,
+		Narration:        cfg.tts != nil,
+		Alignment:        cfg.alignment != nil,
+		Search:           cfg.embedding != nil,
+		Generator:        cfg.llm,
+		Moderator:        cfg.moderation,
+		Gutenberg:        cfg.gutenberg,
+		Fetcher:          cfg.fetcher,
+		Phonics:          cfg.phonics,
+		Blobs:            cfg.database.Blobs,
 	}, store)
 
-	server := newServer(newRootHandler(public, admin))
-	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
-	defer stop()
+	server := newServer(cfg.listenAddress, newRootHandler(public, admin))
+	serve := cfg.tls.serve(server)
+
+	// Background workers stop with ctx; wait for them before the store closes.
+	var workers sync.WaitGroup
+	workers.Go(func() { webhooks.NewDispatcher(store).Run(ctx) })
+	workers.Go(func() { renderjobs.NewWorker(store).Run(ctx) })
+	if cfg.database.Blobs != nil {
+		workers.Go(func() { blob.NewSweeper(store, cfg.database.Blobs).Run(ctx) })
+	}
+	if cfg.tts != nil {
+		workers.Go(func() { tts.NewWorker(store, cfg.tts).Run(ctx) })
+	}
+	if cfg.alignment != nil {
+		workers.Go(func() { alignment.NewWorker(store, cfg.alignment).Run(ctx) })
+	}
+	if cfg.smtp != nil {
+		workers.Go(func() { delivery.NewDispatcher(store, cfg.smtp).Run(ctx) })
+		workers.Go(func() { digest.NewDispatcher(store, cfg.smtp).Run(ctx) })
+	}
+	if pushSender != nil {
+		workers.Go(func() { webpush.NewDispatcher(store, pushSender).Run(ctx) })
+	}
+	if cfg.embedding != nil {
+		workers.Go(func() { embedding.NewWorker(store, cfg.embedding).Run(ctx) })
+		workers.Go(func() { embedding.NewReindexWorker(store, cfg.embedding).Run(ctx) })
+	}
+	if cfg.phonics != nil {
+		workers.Go(func() { phonics.NewWorker(store, cfg.phonics).Run(ctx) })
+	}
+	defer func() {
+		stop()
+		workers.Wait()
+	}()
 
 	errCh := make(chan error, 1)
 	go func() {
-		errCh <- server.ListenAndServe()
+		errCh <- serve()
 	}()
 
-	slog.Info("api listening", "addr", listenAddress)
+	slog.Info("api listening", "addr", cfg.listenAddress, "tls", cfg.tls.enabled())
 
 	select {
 	case err := <-errCh:
@@ -158,11 +605,11 @@ func run() error {
 		}
 		return nil
 	case <-ctx.Done():
-		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
-		defer cancel()
-
-		if err := server.Shutdown(shutdownCtx); err != nil {
-			return fmt.Errorf("graceful shutdown: %w", err)
+		// A second signal now stops the process without waiting.
+		stop()
+		slog.Info("api shutting down", "timeout", cfg.shutdownTimeout.String())
+		if err := drain(server, cfg.shutdownTimeout); err != nil {
+			return err
 		}
 		if err := <-errCh; err != nil && !errors.Is(err, http.ErrServerClosed) {
 			return fmt.Errorf("serve HTTP: %w", err)
@@ -171,6 +618,19 @@ func run() error {
 	}
 }
 
+// drain stops accepting connections and waits up to timeout for in-flight
+// requests. Any still running then have their connections closed, which
+// cancels their contexts and so their queries before run closes the store.
+func drain(server *http.Server, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		_ = server.Close()
+		return fmt.Errorf("graceful shutdown: %w", err)
+	}
+	return nil
+}
+
 func main() {
 	if err := run(); err != nil {
 		slog.Error("api stopped", "err", err)
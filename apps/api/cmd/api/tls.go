@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// tlsSettings lets a small install terminate TLS itself instead of behind a
+// reverse proxy, from certificate files or from an ACME CA such as Let's
+// Encrypt. The zero value serves plain HTTP.
+type tlsSettings struct {
+	certFile string
+	keyFile  string
+
+	acmeDomains  []string
+	acmeCacheDir string
+	acmeEmail    string
+}
+
+func loadTLSSettings(getenv func(string) string) (tlsSettings, error) {
+	settings := tlsSettings{
+		certFile:     strings.TrimSpace(getenv("PP_TLS_CERT")),
+		keyFile:      strings.TrimSpace(getenv("PP_TLS_KEY")),
+		acmeDomains:  splitList(getenv("PP_ACME_DOMAINS")),
+		acmeCacheDir: strings.TrimSpace(getenv("PP_ACME_CACHE_DIR")),
+		acmeEmail:    strings.TrimSpace(getenv("PP_ACME_EMAIL")),
+	}
+	if (settings.certFile == "") != (settings.keyFile == "") {
+		return tlsSettings{}, fmt.Errorf("PP_TLS_CERT and PP_TLS_KEY must be set together")
+	}
+	if settings.certFile != "" && len(settings.acmeDomains) > 0 {
+		return tlsSettings{}, fmt.Errorf("PP_TLS_CERT and PP_ACME_DOMAINS cannot both be set")
+	}
+	// Without a persistent cache every restart orders new certificates and
+	// soon meets the CA's rate limits.
+	if len(settings.acmeDomains) > 0 && settings.acmeCacheDir == "" {
+		return tlsSettings{}, fmt.Errorf("PP_ACME_CACHE_DIR is required with PP_ACME_DOMAINS")
+	}
+	return settings, nil
+}
+
+func (t tlsSettings) enabled() bool {
+	return t.certFile != "" || len(t.acmeDomains) > 0
+}
+
+// mode names how TLS is served, for the startup log.
+func (t tlsSettings) mode() string {
+	switch {
+	case t.certFile != "":
+		return "files"
+	case len(t.acmeDomains) > 0:
+		return "acme"
+	default:
+		return "off"
+	}
+}
+
+// serve configures server for t and returns the call that runs it.
+// Certificate files are read once at startup; ACME certificates are
+// obtained on first use and renewed in the background through the
+// TLS-ALPN-01 challenge, which needs the listener reachable on port 443.
+func (t tlsSettings) serve(server *http.Server) func() error {
+	switch {
+	case t.certFile != "":
+		return func() error { return server.ListenAndServeTLS(t.certFile, t.keyFile) }
+	case len(t.acmeDomains) > 0:
+		manager := &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(t.acmeDomains...),
+			Cache:      autocert.DirCache(t.acmeCacheDir),
+			Email:      t.acmeEmail,
+		}
+		server.TLSConfig = manager.TLSConfig()
+		return func() error { return server.ListenAndServeTLS("", "") }
+	default:
+		return server.ListenAndServe
+	}
+}
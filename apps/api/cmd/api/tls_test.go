@@ -0,0 +1,61 @@
+package main
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestLoadRuntimeConfigParsesListenAddressAndTLS(t *testing.T) {
+	t.Parallel()
+
+	values := map[string]string{
+		"DATABASE_URL":      testDatabaseURL,
+		"PP_PASSCODE":       "123456",
+		"PP_SESSION_SECRET": strings.Repeat("s", 32),
+	}
+	getenv := func(key string) string { return values[key] }
+
+	cfg, err := loadRuntimeConfig(getenv)
+	if err != nil || cfg.listenAddress != defaultListenAddress || cfg.tls.enabled() || cfg.cookieSecure {
+		t.Fatalf("defaults = %q tls %v secure %v, error %v", cfg.listenAddress, cfg.tls.enabled(), cfg.cookieSecure, err)
+	}
+
+	values["PP_LISTEN_ADDR"] = "127.0.0.1:8443"
+	values["PP_TLS_CERT"] = "/etc/pandapages/cert.pem"
+	values["PP_TLS_KEY"] = "/etc/pandapages/key.pem"
+	cfg, err = loadRuntimeConfig(getenv)
+	if err != nil || cfg.listenAddress != "127.0.0.1:8443" || !cfg.tls.enabled() || !cfg.cookieSecure {
+		t.Fatalf("certificate files = %q tls %v secure %v, error %v", cfg.listenAddress, cfg.tls.enabled(), cfg.cookieSecure, err)
+	}
+
+	for name, change := range map[string]map[string]string{
+		"PP_LISTEN_ADDR":    {"PP_LISTEN_ADDR": "8080"},
+		"PP_TLS_KEY":        {"PP_TLS_KEY": ""},
+		"PP_ACME_DOMAINS":   {"PP_ACME_DOMAINS": "pages.example"},
+		"PP_ACME_CACHE_DIR": {"PP_TLS_CERT": "", "PP_TLS_KEY": "", "PP_ACME_DOMAINS": "pages.example"},
+	} {
+		invalid := map[string]string{}
+		for key, value := range values {
+			invalid[key] = value
+		}
+		for key, value := range change {
+			invalid[key] = value
+		}
+		if _, err := loadRuntimeConfig(func(key string) string { return invalid[key] }); err == nil || !strings.Contains(err.Error(), name) {
+			t.Errorf("%v: error = %v, want one naming %s", change, err, name)
+		}
+	}
+}
+
+func TestACMEServesTheTLSALPNChallenge(t *testing.T) {
+	settings := tlsSettings{acmeDomains: []string{"pages.example"}, acmeCacheDir: t.TempDir()}
+	server := newServer(":443", http.NotFoundHandler())
+	if settings.serve(server) == nil || server.TLSConfig == nil || server.TLSConfig.GetCertificate == nil {
+		t.Fatal("ACME did not configure certificates")
+	}
+	if !slices.Contains(server.TLSConfig.NextProtos, "acme-tls/1") {
+		t.Fatalf("NextProtos = %v, want the TLS-ALPN-01 protocol", server.TLSConfig.NextProtos)
+	}
+}
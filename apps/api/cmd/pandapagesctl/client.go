@@ -0,0 +1,19 @@
+package main
+
+import (
+	"fmt"
+
+	"pandapages/api/pkg/client"
+)
+
+// newClient returns a client of the admin API. Every command needs both
+// credentials, so a missing one is reported before anything is sent.
+func newClient(baseURL, passcode, adminKey string) (*client.Client, error) {
+	if passcode == "" {
+		return nil, fmt.Errorf("PP_PASSCODE is required")
+	}
+	if adminKey == "" {
+		return nil, fmt.Errorf("PP_ADMIN_KEY is required")
+	}
+	return client.New(baseURL, passcode, client.WithAdminKey(adminKey))
+}
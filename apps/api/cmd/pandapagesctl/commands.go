@@ -0,0 +1,220 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+
+	"pandapages/api/internal/model"
+	"pandapages/api/pkg/client"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// importFiles uploads each Markdown file as the draft of its story, and with
+// -publish publishes the draft it created.
+func importFiles(ctx context.Context, c *client.Client, args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	slug := flags.String("slug", "", "story slug (default: from the file name)")
+	title := flags.String("title", "", "story title (default: frontmatter title or first heading)")
+	author := flags.String("author", "", "story author (default: frontmatter author)")
+	publish := flags.Bool("publish", false, "publish each imported draft")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	files := flags.Args()
+	if len(files) == 0 {
+		return fmt.Errorf("import needs at least one Markdown file")
+	}
+	if len(files) > 1 && (*slug != "" || *title != "") {
+		return fmt.Errorf("-slug and -title apply to a single file")
+	}
+
+	for _, path := range files {
+		request, err := draftFromFile(path, *slug, *title, *author)
+		if err != nil {
+			return err
+		}
+		draft, err := c.Draft(ctx, request)
+		if err != nil {
+			return fmt.Errorf("import %s: %w", path, err)
+		}
+		fmt.Fprintf(stdout, "%s\tversion %d\t%s\n", draft.Slug, draft.Version, draft.Outcome)
+		if *publish {
+			if err := publishVersion(ctx, c, draft.Slug, draft.VersionID, stdout); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// draftFromFile reads a story. The slug defaults to the file name and the
+// title to the frontmatter title, then to the first level-one heading; the
+// API itself reads the rest of the frontmatter.
+func draftFromFile(path, slug, title, author string) (model.AdminDraftUpsertRequest, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return model.AdminDraftUpsertRequest{}, err
+	}
+	return draftFromMarkdown(path, string(raw), slug, title, author)
+}
+
+func draftFromMarkdown(path, markdown, slug, title, author string) (model.AdminDraftUpsertRequest, error) {
+	frontmatter := readFrontmatter(markdown)
+
+	if slug == "" {
+		slug = slugFromFileName(path)
+	}
+	if title == "" {
+		title, _ = frontmatter["title"].(string)
+	}
+	if title == "" {
+		title = firstHeading(markdown)
+	}
+	if strings.TrimSpace(title) == "" {
+		return model.AdminDraftUpsertRequest{}, fmt.Errorf("%s: no title in frontmatter or a first heading; pass -title", path)
+	}
+	if author == "" {
+		author, _ = frontmatter["author"].(string)
+	}
+
+	request := model.AdminDraftUpsertRequest{Slug: slug, Title: strings.TrimSpace(title), Markdown: markdown}
+	if author = strings.TrimSpace(author); author != "" {
+		request.Author = &author
+	}
+	return request, nil
+}
+
+var nonSlug = regexp.MustCompile(`[^a-z0-9]+`)
+
+func slugFromFileName(path string) string {
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	return strings.Trim(nonSlug.ReplaceAllString(strings.ToLower(name), "-"), "-")
+}
+
+// readFrontmatter returns the YAML block a story may open with, or nothing
+// when it has none or it does not parse; the API reports malformed
+// frontmatter itself.
+func readFrontmatter(markdown string) map[string]any {
+	rest, ok := strings.CutPrefix(strings.TrimPrefix(markdown, "\ufeff"), "---\n")
+	if !ok {
+		return nil
+	}
+	block, _, ok := strings.Cut(rest, "\n---")
+	if !ok {
+		return nil
+	}
+	var values map[string]any
+	if err := yaml.Unmarshal([]byte(block), &values); err != nil {
+		return nil
+	}
+	return values
+}
+
+func firstHeading(markdown string) string {
+	scanner := bufio.NewScanner(strings.NewReader(markdown))
+	for scanner.Scan() {
+		if heading, ok := strings.CutPrefix(scanner.Text(), "# "); ok {
+			return strings.TrimSpace(heading)
+		}
+	}
+	return ""
+}
+
+// publish publishes a version of a story, by default its current draft.
+func publish(ctx context.Context, c *client.Client, args []string, stdout io.Writer) error {
+	if len(args) < 1 || len(args) > 2 {
+		return fmt.Errorf("usage: publish SLUG [VERSION_ID]")
+	}
+	slug := args[0]
+	if len(args) == 2 {
+		return publishVersion(ctx, c, slug, args[1], stdout)
+	}
+
+	story, err := c.AdminStory(ctx, slug)
+	if err != nil {
+		return fmt.Errorf("publish %s: %w", slug, err)
+	}
+	if story.DraftVersion == nil {
+		return fmt.Errorf("publish %s: the story has no draft to publish", slug)
+	}
+	return publishVersion(ctx, c, slug, story.DraftVersion.VersionID, stdout)
+}
+
+func publishVersion(ctx context.Context, c *client.Client, slug, versionID string, stdout io.Writer) error {
+	out, err := c.Publish(ctx, slug, versionID)
+	if err != nil {
+		return fmt.Errorf("publish %s: %w", slug, err)
+	}
+	if out.PublishedVersion != nil {
+		fmt.Fprintf(stdout, "%s\tpublished version %d\n", out.Slug, out.PublishedVersion.Version)
+	}
+	if out.SensitivityWarning {
+		fmt.Fprintf(stdout, "%s\twarning: matches a sensitivity term; see its sensitivity report\n", out.Slug)
+	}
+	return nil
+}
+
+// list prints every story of the library, one page of the API at a time.
+func list(ctx context.Context, c *client.Client, args []string, stdout io.Writer) error {
+	if len(args) != 0 {
+		return fmt.Errorf("usage: list")
+	}
+	table := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(table, "SLUG\tSTATUS\tVERSIONS\tTITLE")
+	cursor := ""
+	for {
+		page, err := c.AdminStories(ctx, model.PageRequest{Cursor: cursor, Limit: 100})
+		if err != nil {
+			return fmt.Errorf("list: %w", err)
+		}
+		for _, story := range page.Items {
+			fmt.Fprintf(table, "%s\t%s\t%d\t%s\n", story.Slug, story.Status, story.VersionCount, story.Title)
+		}
+		if page.NextCursor == nil || *page.NextCursor == "" {
+			break
+		}
+		cursor = *page.NextCursor
+	}
+	return table.Flush()
+}
+
+// export writes a story's bundle, by default to SLUG.pandapages.json in the
+// current directory. "-o -" writes it to standard output.
+func export(ctx context.Context, c *client.Client, args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	output := flags.String("o", "", "output file (default SLUG.pandapages.json; - for standard output)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return fmt.Errorf("usage: export [-o FILE] SLUG")
+	}
+	slug := flags.Arg(0)
+
+	bundle, err := c.Export(ctx, slug)
+	if err != nil {
+		return fmt.Errorf("export %s: %w", slug, err)
+	}
+	if *output == "-" {
+		_, err := stdout.Write(bundle)
+		return err
+	}
+	path := *output
+	if path == "" {
+		path = slug + ".pandapages.json"
+	}
+	if err := os.WriteFile(path, bundle, 0o644); err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "%s\t%s\n", slug, path)
+	return nil
+}
@@ -0,0 +1,95 @@
+// Command pandapagesctl scripts library curation through the admin API:
+// importing Markdown files as drafts, publishing, listing stories,
+// exporting bundles and seeding a development library.
+//
+// It reads the API's address from -url or PANDAPAGES_URL, and its
+// credentials from PP_PASSCODE and PP_ADMIN_KEY only, so they stay out of
+// shell history and process listings.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"pandapages/api/pkg/client"
+)
+
+const defaultAPIURL = "http://localhost:8080"
+
+const usage = `usage: pandapagesctl [-url URL] COMMAND [ARGS]
+
+Commands:
+  import [-slug SLUG] [-title TITLE] [-author AUTHOR] [-publish] FILE...
+        upload Markdown files as story drafts
+  publish SLUG [VERSION_ID]
+        publish a version, by default the story's draft
+  list  list every story with its status
+  export [-o FILE] SLUG
+        save a story's bundle, by default to SLUG.pandapages.json
+  seed  add sample child profiles and stories to an empty library
+
+Environment:
+  PANDAPAGES_URL  API address (default ` + defaultAPIURL + `)
+  PP_PASSCODE     the library passcode
+  PP_ADMIN_KEY    an admin key with the roles the command needs
+`
+
+type command func(ctx context.Context, c *client.Client, args []string, stdout io.Writer) error
+
+var commands = map[string]command{
+	"import":  importFiles,
+	"publish": publish,
+	"list":    list,
+	"export":  export,
+	"seed":    seed,
+}
+
+func run(ctx context.Context, args []string, getenv func(string) string, stdout, stderr io.Writer) error {
+	flags := flag.NewFlagSet("pandapagesctl", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() { fmt.Fprint(stderr, usage) }
+	apiURL := getenv("PANDAPAGES_URL")
+	if apiURL == "" {
+		apiURL = defaultAPIURL
+	}
+	flags.StringVar(&apiURL, "url", apiURL, "API address")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return flag.ErrHelp
+	}
+	name := flags.Arg(0)
+	cmd, ok := commands[name]
+	if !ok {
+		flags.Usage()
+		return fmt.Errorf("unknown command %q", name)
+	}
+
+	c, err := newClient(apiURL, getenv("PP_PASSCODE"), getenv("PP_ADMIN_KEY"))
+	if err != nil {
+		return err
+	}
+	return cmd(ctx, c, flags.Args()[1:], stdout)
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := run(ctx, os.Args[1:], os.Getenv, os.Stdout, os.Stderr); err != nil {
+		stop()
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(2)
+		}
+		fmt.Fprintln(os.Stderr, "pandapagesctl:", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"pandapages/api/internal/model"
+	"pandapages/api/pkg/client"
+)
+
+// fakeAdminAPI answers the routes pandapagesctl uses, insisting on the
+// session cookie from unlock and the admin key, and records each call.
+func fakeAdminAPI(t *testing.T) (*httptest.Server, *[]string) {
+	t.Helper()
+	var calls []string
+	stories := map[string]bool{"the-snail": true, "the-tortoise-and-the-hare": true}
+	var settings model.SettingsPayload
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/auth/unlock", func(w http.ResponseWriter, r *http.Request) {
+		var body struct{ Passcode string }
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body.Passcode != "123456" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		http.SetCookie(w, &http.Cookie{Name: "pp_session", Value: "signed", Path: "/"})
+	})
+	mux.HandleFunc("GET /api/v1/settings", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(settings)
+	})
+	mux.HandleFunc("PUT /api/v1/settings", func(w http.ResponseWriter, r *http.Request) {
+		var body model.SettingsUpsert
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		calls = append(calls, "settings "+body.Child.Name+" prompt="+body.Prompt.ID+" rules="+string(body.Prompt.Rules))
+		settings = model.SettingsPayload{Child: body.Child, Prompt: body.Prompt}
+		settings.Child.ID, settings.Prompt.ID = "c-"+body.Child.Name, "p-1"
+		_ = json.NewEncoder(w).Encode(settings)
+	})
+	admin := http.NewServeMux()
+	admin.HandleFunc("POST /api/v1/admin/stories/draft", func(w http.ResponseWriter, r *http.Request) {
+		var body model.AdminDraftUpsertRequest
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body.Title == "" {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			_ = json.NewEncoder(w).Encode(map[string]any{"error": map[string]any{
+				"code": "validation_failed", "message": "story is invalid", "requestId": "req-1",
+				"issues": []model.AdminValidationIssue{{Field: "title", Code: "required", Message: "Enter a title"}},
+			}})
+			return
+		}
+		calls = append(calls, "draft "+body.Slug+" "+body.Title)
+		stories[body.Slug] = true
+		_ = json.NewEncoder(w).Encode(model.AdminDraftUpsertResponse{Slug: body.Slug, VersionID: "v-2", Version: 2, Outcome: "created"})
+	})
+	admin.HandleFunc("GET /api/v1/admin/stories/{slug}", func(w http.ResponseWriter, r *http.Request) {
+		if !stories[r.PathValue("slug")] {
+			w.WriteHeader(http.StatusNotFound)
+			_ = json.NewEncoder(w).Encode(map[string]any{"error": map[string]any{"code": "story_not_found", "message": "story was not found"}})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(model.AdminStoryDetailResponse{
+			Slug: r.PathValue("slug"), DraftVersion: &model.AdminVersionPointerSummary{VersionID: "v-3", Version: 3},
+		})
+	})
+	admin.HandleFunc("POST /api/v1/admin/stories/{slug}/publish", func(w http.ResponseWriter, r *http.Request) {
+		var body struct{ VersionID string }
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		calls = append(calls, "publish "+r.PathValue("slug")+" "+body.VersionID)
+		_ = json.NewEncoder(w).Encode(model.AdminStoryStatusResponse{
+			Slug: r.PathValue("slug"), PublishedVersion: &model.AdminVersionPointerSummary{VersionID: body.VersionID, Version: 2},
+		})
+	})
+	admin.HandleFunc("GET /api/v1/admin/stories", func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, "list "+r.URL.Query().Get("cursor"))
+		page := model.AdminStoriesListResponse{Items: []model.AdminStorySummary{{Slug: "the-snail", Title: "The Snail", Status: model.AdminStoryStatusPublished, VersionCount: 2}}}
+		if r.URL.Query().Get("cursor") == "" {
+			next := "page-2"
+			page = model.AdminStoriesListResponse{Items: []model.AdminStorySummary{{Slug: "moon-song", Title: "Moon Song", Status: model.AdminStoryStatusDraftOnly, VersionCount: 1}}, NextCursor: &next}
+		}
+		_ = json.NewEncoder(w).Encode(page)
+	})
+	admin.HandleFunc("GET /api/v1/admin/stories/{slug}/export", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"format":"pandapages.story","story":{"slug":"` + r.PathValue("slug") + `"}}`))
+	})
+	mux.Handle("/api/v1/admin/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if cookie, err := r.Cookie("pp_session"); err != nil || cookie.Value != "signed" || r.Header.Get(client.AdminKeyHeader) != "admin-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		admin.ServeHTTP(w, r)
+	}))
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func runCtl(t *testing.T, server *httptest.Server, args ...string) (string, error) {
+	t.Helper()
+	env := map[string]string{"PANDAPAGES_URL": server.URL, "PP_PASSCODE": "123456", "PP_ADMIN_KEY": "admin-key"}
+	var stdout, stderr bytes.Buffer
+	err := run(t.Context(), args, func(key string) string { return env[key] }, &stdout, &stderr)
+	return stdout.String(), err
+}
+
+func TestImportUploadsDraftsAndPublishesThem(t *testing.T) {
+	server, calls := fakeAdminAPI(t)
+	dir := t.TempDir()
+	withFrontmatter := filepath.Join(dir, "The Snail.md")
+	withHeading := filepath.Join(dir, "moon_song.md")
+	if err := os.WriteFile(withFrontmatter, []byte("---\ntitle: The Slow Snail\n---\n# Home\n\nThe snail went home.\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(withHeading, []byte("# Moon Song\n\nHush now.\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := runCtl(t, server, "import", "-publish", withFrontmatter, withHeading); err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	want := []string{
+		"draft the-snail The Slow Snail", "publish the-snail v-2",
+		"draft moon-song Moon Song", "publish moon-song v-2",
+	}
+	if strings.Join(*calls, "|") != strings.Join(want, "|") {
+		t.Fatalf("calls = %q, want %q", *calls, want)
+	}
+
+	untitled := filepath.Join(dir, "untitled.md")
+	if err := os.WriteFile(untitled, []byte("Just text.\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := runCtl(t, server, "import", untitled); err == nil || !strings.Contains(err.Error(), "-title") {
+		t.Fatalf("untitled import error = %v, want a hint to pass -title", err)
+	}
+	if _, err := runCtl(t, server, "import", "-title", " ", "-slug", "blank", withHeading); err == nil {
+		t.Fatal("blank title was accepted")
+	}
+}
+
+func TestPublishDefaultsToTheDraftVersion(t *testing.T) {
+	server, calls := fakeAdminAPI(t)
+	if _, err := runCtl(t, server, "publish", "the-snail"); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	if _, err := runCtl(t, server, "publish", "the-snail", "v-1"); err != nil {
+		t.Fatalf("publish version: %v", err)
+	}
+	if got := strings.Join(*calls, "|"); got != "publish the-snail v-3|publish the-snail v-1" {
+		t.Fatalf("calls = %q", got)
+	}
+}
+
+func TestListFollowsEveryPage(t *testing.T) {
+	server, calls := fakeAdminAPI(t)
+	out, err := runCtl(t, server, "list")
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(*calls) != 2 || !strings.Contains(out, "moon-song") || !strings.Contains(out, "the-snail") {
+		t.Fatalf("calls = %q, output:\n%s", *calls, out)
+	}
+}
+
+func TestExportWritesTheBundle(t *testing.T) {
+	server, _ := fakeAdminAPI(t)
+	path := filepath.Join(t.TempDir(), "snail.json")
+	if _, err := runCtl(t, server, "export", "-o", path, "the-snail"); err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil || !json.Valid(raw) || !strings.Contains(string(raw), `"slug":"the-snail"`) {
+		t.Fatalf("bundle = %s, error %v", raw, err)
+	}
+}
+
+func TestAPIErrorsNameTheIssuesAndRequestID(t *testing.T) {
+	server, _ := fakeAdminAPI(t)
+	c, err := newClient(server.URL, "123456", "admin-key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.Draft(t.Context(), model.AdminDraftUpsertRequest{Slug: "x"})
+	if err == nil || !strings.Contains(err.Error(), "title: Enter a title") || !strings.Contains(err.Error(), "request ID req-1") {
+		t.Fatalf("error = %v", err)
+	}
+
+	wrong, err := newClient(server.URL, "000000", "admin-key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wrong.AdminStories(t.Context(), model.PageRequest{}); err == nil || !strings.HasPrefix(err.Error(), "unlock:") {
+		t.Fatalf("wrong passcode error = %v", err)
+	}
+	if _, err := newClient(server.URL, "123456", ""); err == nil || !strings.Contains(err.Error(), "PP_ADMIN_KEY") {
+		t.Fatalf("missing admin key error = %v", err)
+	}
+}
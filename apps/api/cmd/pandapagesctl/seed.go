@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+
+	"pandapages/api/internal/model"
+	"pandapages/api/pkg/client"
+)
+
+// samples are short public-domain stories, so a fresh development library
+// has something to read.
+//
+//go:embed samples/*.md
+var samples embed.FS
+
+// sampleChildren are the child profiles seed creates; the last one becomes
+// the active profile.
+var sampleChildren = []model.ChildProfile{
+	{Name: "Ada", AgeMonths: 54, Interests: []string{"animals", "stars"}, Sensitivities: []string{}},
+	{Name: "Sam", AgeMonths: 34, Interests: []string{"trains", "boats"}, Sensitivities: []string{"spiders"}},
+}
+
+// seed fills an empty development library: unlocking creates the default
+// account, then it adds child profiles and publishes the sample stories.
+// Running it again changes nothing that is already there.
+func seed(ctx context.Context, c *client.Client, args []string, stdout io.Writer) error {
+	if len(args) != 0 {
+		return fmt.Errorf("usage: seed")
+	}
+	if err := seedChildren(ctx, c, stdout); err != nil {
+		return err
+	}
+
+	names, err := fs.Glob(samples, "samples/*.md")
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		raw, err := samples.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		request, err := draftFromMarkdown(path.Base(name), string(raw), "", "", "")
+		if err != nil {
+			return err
+		}
+
+		_, err = c.AdminStory(ctx, request.Slug)
+		if err == nil {
+			fmt.Fprintf(stdout, "%s\talready in the library\n", request.Slug)
+			continue
+		}
+		if !client.IsNotFound(err) {
+			return fmt.Errorf("seed %s: %w", request.Slug, err)
+		}
+
+		draft, err := c.Draft(ctx, request)
+		if err != nil {
+			return fmt.Errorf("seed %s: %w", request.Slug, err)
+		}
+		if err := publishVersion(ctx, c, draft.Slug, draft.VersionID, stdout); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// seedChildren adds the sample child profiles unless the account already
+// has an active one. Each settings write creates a child profile; the prompt
+// profile the first write creates is reused by the rest. An empty account
+// reports its prompt rules as null, which the API would store as is.
+func seedChildren(ctx context.Context, c *client.Client, stdout io.Writer) error {
+	settings, err := c.Settings(ctx)
+	if err != nil {
+		return fmt.Errorf("seed settings: %w", err)
+	}
+	if settings.Child.ID != "" {
+		fmt.Fprintf(stdout, "child profiles\talready set up\n")
+		return nil
+	}
+	for _, child := range sampleChildren {
+		upsert := model.SettingsUpsert{Child: child, Prompt: settings.Prompt}
+		if len(upsert.Prompt.Rules) == 0 || string(upsert.Prompt.Rules) == "null" {
+			upsert.Prompt.Rules = json.RawMessage(`{}`)
+		}
+		if settings, err = c.SaveSettings(ctx, upsert); err != nil {
+			return fmt.Errorf("seed child profile %s: %w", child.Name, err)
+		}
+		fmt.Fprintf(stdout, "child profile\t%s\n", child.Name)
+	}
+	return nil
+}
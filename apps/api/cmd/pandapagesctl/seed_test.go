@@ -0,0 +1,60 @@
+package main
+
+import (
+	"io/fs"
+	"path"
+	"strings"
+	"testing"
+
+	"pandapages/api/internal/storyingest"
+)
+
+func TestSamplesIngest(t *testing.T) {
+	names, err := fs.Glob(samples, "samples/*.md")
+	if err != nil || len(names) < 3 {
+		t.Fatalf("samples = %v, error %v", names, err)
+	}
+	for _, name := range names {
+		raw, err := samples.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		request, err := draftFromMarkdown(path.Base(name), string(raw), "", "", "")
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if request.Author == nil || *request.Author != "Aesop" {
+			t.Errorf("%s: author = %v", name, request.Author)
+		}
+		if _, err := storyingest.Ingest(storyingest.Input{Slug: request.Slug, Title: request.Title, Markdown: request.Markdown}); err != nil {
+			t.Errorf("%s does not ingest: %v", name, err)
+		}
+	}
+}
+
+func TestSeedFillsAnEmptyLibraryOnce(t *testing.T) {
+	server, calls := fakeAdminAPI(t)
+	out, err := runCtl(t, server, "seed")
+	if err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	want := []string{
+		"settings Ada prompt= rules={}", "settings Sam prompt=p-1 rules={}",
+		"draft the-lion-and-the-mouse The Lion and the Mouse", "publish the-lion-and-the-mouse v-2",
+		"draft the-town-mouse-and-the-country-mouse The Town Mouse and the Country Mouse", "publish the-town-mouse-and-the-country-mouse v-2",
+	}
+	if strings.Join(*calls, "|") != strings.Join(want, "|") {
+		t.Fatalf("calls = %q, want %q", *calls, want)
+	}
+	if !strings.Contains(out, "the-tortoise-and-the-hare\talready in the library") {
+		t.Fatalf("output does not report the existing story:\n%s", out)
+	}
+
+	*calls = nil
+	if _, err := runCtl(t, server, "seed"); err != nil {
+		t.Fatalf("second seed: %v", err)
+	}
+	if len(*calls) != 0 {
+		t.Fatalf("second seed changed the library: %q", *calls)
+	}
+}
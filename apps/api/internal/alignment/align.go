@@ -0,0 +1,166 @@
+package alignment
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"pandapages/api/internal/model"
+	"pandapages/api/internal/storyingest"
+)
+
+// maxMatchCells bounds the matching table. A segment is a paragraph, so this
+// only stops a pathological one; its words are then spread over the
+// recording instead.
+const maxMatchCells = 1 << 20
+
+var (
+	tagRe   = regexp.MustCompile(`<[^>]*>`)
+	breakRe = regexp.MustCompile(`(?i)<br\s*/?>`)
+)
+
+// Text is a segment's text as the Reader shows it: its rendered HTML's text
+// content, without soft hyphens. Inline markup does not split words, so
+// "<em>upon</em>." is the one word "upon.", as a browser would wrap it.
+func Text(renderedHTML string) string {
+	text := breakRe.ReplaceAllString(renderedHTML, " ")
+	text = html.UnescapeString(tagRe.ReplaceAllString(text, ""))
+	text = strings.ReplaceAll(text, storyingest.SoftHyphen, "")
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// Align times each word of text, split at spaces as the Reader shows it,
+// from the words a provider heard in a recording of duration. Words are
+// matched in order by their letters and digits, so punctuation and case do
+// not matter. A word the provider heard differently, such as one read from a
+// pronunciation respelling, takes a share of the time between the matched
+// words around it in proportion to its length. Timings never overlap and
+// stay within the recording.
+func Align(text string, spoken []SpokenWord, duration time.Duration) []model.WordTiming {
+	display := strings.Fields(text)
+	if len(display) == 0 {
+		return nil
+	}
+	total := max(0, int(duration.Milliseconds()))
+
+	displayKeys := make([]string, len(display))
+	for index, word := range display {
+		displayKeys[index] = matchKey(word)
+	}
+	var heardKeys []string
+	var heard []SpokenWord
+	for _, word := range spoken {
+		if key := matchKey(word.Text); key != "" {
+			heardKeys = append(heardKeys, key)
+			heard = append(heard, word)
+		}
+	}
+
+	out := make([]model.WordTiming, len(display))
+	matched := make([]bool, len(display))
+	end := 0
+	for index, word := range match(displayKeys, heardKeys) {
+		out[index].Text = display[index]
+		if word < 0 {
+			continue
+		}
+		matched[index] = true
+		start := max(clamp(int(heard[word].Start.Milliseconds()), 0, total), end)
+		end = max(clamp(int(heard[word].End.Milliseconds()), 0, total), start)
+		out[index].StartMs, out[index].EndMs = start, end
+	}
+
+	// Spread each run of unmatched words between its neighbours.
+	for first := 0; first < len(display); {
+		if matched[first] {
+			first++
+			continue
+		}
+		last := first
+		for last < len(display) && !matched[last] {
+			last++
+		}
+		from, to := 0, total
+		if first > 0 {
+			from = out[first-1].EndMs
+		}
+		if last < len(display) {
+			to = out[last].StartMs
+		}
+		to = max(to, from)
+		weights, sum := make([]int, last-first), 0
+		for offset := range weights {
+			weights[offset] = max(1, utf8.RuneCountInString(displayKeys[first+offset]))
+			sum += weights[offset]
+		}
+		spent := 0
+		for offset, weight := range weights {
+			out[first+offset].StartMs = from + (to-from)*spent/sum
+			spent += weight
+			out[first+offset].EndMs = from + (to-from)*spent/sum
+		}
+		first = last
+	}
+	return out
+}
+
+// match pairs display words with heard words by the longest common
+// subsequence of their keys. It returns, for each display word, the index of
+// its heard word or -1.
+func match(display, heard []string) []int {
+	out := make([]int, len(display))
+	for index := range out {
+		out[index] = -1
+	}
+	if len(heard) == 0 || len(display)*len(heard) > maxMatchCells {
+		return out
+	}
+	// lengths[i][j] is the longest common subsequence of display[i:] and
+	// heard[j:].
+	width := len(heard) + 1
+	lengths := make([]int32, (len(display)+1)*width)
+	for i := len(display) - 1; i >= 0; i-- {
+		for j := len(heard) - 1; j >= 0; j-- {
+			switch {
+			case display[i] != "" && display[i] == heard[j]:
+				lengths[i*width+j] = lengths[(i+1)*width+j+1] + 1
+			case lengths[(i+1)*width+j] >= lengths[i*width+j+1]:
+				lengths[i*width+j] = lengths[(i+1)*width+j]
+			default:
+				lengths[i*width+j] = lengths[i*width+j+1]
+			}
+		}
+	}
+	for i, j := 0, 0; i < len(display) && j < len(heard); {
+		switch {
+		case display[i] != "" && display[i] == heard[j]:
+			out[i] = j
+			i++
+			j++
+		case lengths[(i+1)*width+j] >= lengths[i*width+j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	return out
+}
+
+// matchKey is a word's lowercased letters and digits, so "Hello," matches
+// "hello" and "don’t" matches "don't".
+func matchKey(word string) string {
+	var key strings.Builder
+	for _, r := range word {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			key.WriteRune(unicode.ToLower(r))
+		}
+	}
+	return key.String()
+}
+
+func clamp(value, low, high int) int {
+	return min(max(value, low), high)
+}
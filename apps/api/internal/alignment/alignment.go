@@ -0,0 +1,192 @@
+// Package alignment times the words of narrated segments so the Reader can
+// highlight each word as it is spoken. A Provider transcribes a recording
+// with word timestamps, Whisper style; Align matches the transcript to the
+// segment's text, which the recording does not always say word for word,
+// and a Worker drives queued alignment jobs a segment at a time like the
+// narration worker.
+package alignment
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	ProviderOpenAI  = "openai"
+	ProviderWhisper = "whisper"
+
+	defaultOpenAIURL   = "https://api.openai.com/v1/audio/transcriptions"
+	defaultOpenAIModel = "whisper-1"
+
+	// Transcribing a long paragraph takes a cloud service several seconds;
+	// this bounds a provider that stops answering.
+	requestTimeout = 2 * time.Minute
+	// maxResponseBytes is far more than a word list for one segment needs.
+	maxResponseBytes = 4 << 20
+	// maxPromptRunes keeps the script hint within Whisper's prompt window,
+	// which is about 224 tokens.
+	maxPromptRunes = 800
+)
+
+// ErrProvider marks a provider that answered with an error status or a
+// transcript that could not be read. Its message never carries the
+// provider's response.
+var ErrProvider = errors.New("alignment provider failed")
+
+// SpokenWord is one word a provider heard, with when it was said.
+type SpokenWord struct {
+	Text  string
+	Start time.Duration
+	End   time.Duration
+}
+
+type Provider interface {
+	// Name identifies the provider in logs and the startup summary.
+	Name() string
+	// Transcribe returns the words spoken in a WAV recording, in order.
+	// script is what the recording was made from, a hint for spelling.
+	Transcribe(ctx context.Context, wav []byte, script string) ([]SpokenWord, error)
+}
+
+// Load builds the provider PP_ALIGNMENT_PROVIDER names. It returns nil, and
+// no error, when none is configured.
+func Load(getenv func(string) string) (Provider, error) {
+	name := strings.ToLower(strings.TrimSpace(getenv("PP_ALIGNMENT_PROVIDER")))
+	endpoint := strings.TrimSpace(getenv("PP_ALIGNMENT_URL"))
+	apiKey := strings.TrimSpace(getenv("PP_ALIGNMENT_API_KEY"))
+	modelName := cmp.Or(strings.TrimSpace(getenv("PP_ALIGNMENT_MODEL")), defaultOpenAIModel)
+	if endpoint != "" {
+		if parsed, err := url.Parse(endpoint); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return nil, fmt.Errorf("PP_ALIGNMENT_URL must be an http or https URL")
+		}
+	}
+
+	switch name {
+	case "":
+		return nil, nil
+	case ProviderOpenAI:
+		if apiKey == "" {
+			return nil, fmt.Errorf("PP_ALIGNMENT_API_KEY is required for the openai provider")
+		}
+		return &Whisper{
+			name:   ProviderOpenAI,
+			URL:    cmp.Or(endpoint, defaultOpenAIURL),
+			APIKey: apiKey,
+			Model:  modelName,
+			client: newHTTPClient(),
+		}, nil
+	case ProviderWhisper:
+		if endpoint == "" {
+			return nil, fmt.Errorf("PP_ALIGNMENT_URL is required for the whisper provider")
+		}
+		return &Whisper{name: ProviderWhisper, URL: endpoint, APIKey: apiKey, Model: modelName, client: newHTTPClient()}, nil
+	default:
+		return nil, fmt.Errorf("PP_ALIGNMENT_PROVIDER must be openai or whisper")
+	}
+}
+
+func newHTTPClient() *http.Client {
+	return &http.Client{Timeout: requestTimeout}
+}
+
+// Whisper is OpenAI's transcription endpoint, or a self-hosted Whisper
+// server that copies its API, asked for word timestamps. The API key is
+// optional for a self-hosted server.
+type Whisper struct {
+	name   string
+	URL    string
+	APIKey string
+	Model  string
+	client *http.Client
+}
+
+func (p *Whisper) Name() string { return p.name }
+
+func (p *Whisper) Transcribe(ctx context.Context, wav []byte, script string) ([]SpokenWord, error) {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	file, err := form.CreateFormFile("file", "segment.wav")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := file.Write(wav); err != nil {
+		return nil, err
+	}
+	for _, field := range [][2]string{
+		{"model", p.Model},
+		{"response_format", "verbose_json"},
+		{"timestamp_granularities[]", "word"},
+		{"prompt", truncateRunes(script, maxPromptRunes)},
+	} {
+		if err := form.WriteField(field[0], field[1]); err != nil {
+			return nil, err
+		}
+	}
+	if err := form.Close(); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.URL, &body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "pandapages-alignment/1")
+	if p.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.APIKey)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("%w: status %d", ErrProvider, resp.StatusCode)
+	}
+	var transcript struct {
+		Words []struct {
+			Word  string  `json:"word"`
+			Start float64 `json:"start"`
+			End   float64 `json:"end"`
+		} `json:"words"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseBytes)).Decode(&transcript); err != nil {
+		return nil, fmt.Errorf("%w: transcript is not valid JSON", ErrProvider)
+	}
+	words := make([]SpokenWord, 0, len(transcript.Words))
+	for _, word := range transcript.Words {
+		if word.Start < 0 || word.End < word.Start {
+			return nil, fmt.Errorf("%w: word timestamps are out of order", ErrProvider)
+		}
+		words = append(words, SpokenWord{
+			Text:  strings.TrimSpace(word.Word),
+			Start: seconds(word.Start),
+			End:   seconds(word.End),
+		})
+	}
+	return words, nil
+}
+
+func seconds(value float64) time.Duration {
+	return time.Duration(value * float64(time.Second))
+}
+
+func truncateRunes(value string, limit int) string {
+	runes := []rune(value)
+	if len(runes) <= limit {
+		return value
+	}
+	return string(runes[:limit])
+}
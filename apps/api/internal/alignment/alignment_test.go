@@ -0,0 +1,274 @@
+package alignment
+
+import (
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"pandapages/api/internal/model"
+)
+
+func heard(words ...any) []SpokenWord {
+	var out []SpokenWord
+	for index := 0; index < len(words); index += 3 {
+		out = append(out, SpokenWord{
+			Text:  words[index].(string),
+			Start: time.Duration(words[index+1].(int)) * time.Millisecond,
+			End:   time.Duration(words[index+2].(int)) * time.Millisecond,
+		})
+	}
+	return out
+}
+
+func TestAlignMatchesHeardWordsIgnoringCaseAndPunctuation(t *testing.T) {
+	got := Align("“Hello,” said Panda.", heard("hello", 100, 400, "said", 450, 700, "panda", 750, 1200), 1500*time.Millisecond)
+	want := []model.WordTiming{
+		{Text: "“Hello,”", StartMs: 100, EndMs: 400},
+		{Text: "said", StartMs: 450, EndMs: 700},
+		{Text: "Panda.", StartMs: 750, EndMs: 1200},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Align = %#v, want %#v", got, want)
+	}
+}
+
+func TestAlignSpreadsUnheardWordsBetweenTheirNeighbours(t *testing.T) {
+	// Hermione was read from its respelling, so it is heard as other words;
+	// it takes the gap between "met" and "today".
+	got := Align("Panda met Hermione today.", heard(
+		"Panda", 0, 300, "met", 300, 500, "her", 500, 700, "my", 700, 800, "oh", 800, 900, "knee", 900, 1100, "today", 1200, 1600,
+	), 2*time.Second)
+	if len(got) != 4 || got[2].Text != "Hermione" || got[2].StartMs != 500 || got[2].EndMs != 1200 {
+		t.Fatalf("Align = %#v", got)
+	}
+
+	// Unheard words share the rest of the recording by length.
+	got = Align("Once upon a time", heard("once", 0, 400), time.Second)
+	want := []model.WordTiming{
+		{Text: "Once", StartMs: 0, EndMs: 400},
+		{Text: "upon", StartMs: 400, EndMs: 666},
+		{Text: "a", StartMs: 666, EndMs: 733},
+		{Text: "time", StartMs: 733, EndMs: 1000},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Align = %#v, want %#v", got, want)
+	}
+}
+
+func TestAlignKeepsTimingsInOrderAndWithinTheRecording(t *testing.T) {
+	got := Align("one two three", heard("one", 0, 600, "two", 500, 900, "three", 1800, 2500), 2*time.Second)
+	for index, word := range got {
+		if word.StartMs > word.EndMs || word.EndMs > 2000 || (index > 0 && word.StartMs < got[index-1].EndMs) {
+			t.Fatalf("Align = %#v", got)
+		}
+	}
+	if got[1].StartMs != 600 || got[2].EndMs != 2000 {
+		t.Fatalf("Align = %#v", got)
+	}
+	if got := Align("  ", heard("one", 0, 10), time.Second); got != nil {
+		t.Fatalf("empty text Align = %#v", got)
+	}
+	if got := Align("Nothing heard", nil, time.Second); len(got) != 2 || got[1].EndMs != 1000 {
+		t.Fatalf("unheard Align = %#v", got)
+	}
+}
+
+func TestTextIsTheReadersTextContent(t *testing.T) {
+	got := Text("<p>Once <em>up\u00adon</em>. A&nbsp;time<br>after &amp; <strong>time</strong></p>")
+	if want := "Once upon. A time after & time"; got != want {
+		t.Fatalf("Text = %q, want %q", got, want)
+	}
+}
+
+func TestLoadBuildsTheConfiguredProvider(t *testing.T) {
+	env := func(values map[string]string) func(string) string {
+		return func(key string) string { return values[key] }
+	}
+	if provider, err := Load(env(nil)); err != nil || provider != nil {
+		t.Fatalf("unconfigured Load = %v, %v", provider, err)
+	}
+	provider, err := Load(env(map[string]string{"PP_ALIGNMENT_PROVIDER": "OpenAI", "PP_ALIGNMENT_API_KEY": "key"}))
+	if err != nil {
+		t.Fatalf("openai Load error = %v", err)
+	}
+	if whisper, ok := provider.(*Whisper); !ok || whisper.URL != defaultOpenAIURL || whisper.Model != defaultOpenAIModel || whisper.Name() != "openai" {
+		t.Fatalf("openai provider = %#v", provider)
+	}
+	provider, err = Load(env(map[string]string{"PP_ALIGNMENT_PROVIDER": "whisper", "PP_ALIGNMENT_URL": "http://whisper:8000/v1/audio/transcriptions"}))
+	if err != nil || provider.Name() != "whisper" {
+		t.Fatalf("whisper Load = %v, %v", provider, err)
+	}
+
+	for name, values := range map[string]map[string]string{
+		"unknown":       {"PP_ALIGNMENT_PROVIDER": "aeneas"},
+		"openai key":    {"PP_ALIGNMENT_PROVIDER": "openai"},
+		"whisper url":   {"PP_ALIGNMENT_PROVIDER": "whisper"},
+		"malformed url": {"PP_ALIGNMENT_PROVIDER": "whisper", "PP_ALIGNMENT_URL": "ftp://whisper"},
+	} {
+		if _, err := Load(env(values)); err == nil {
+			t.Errorf("%s: Load succeeded", name)
+		}
+	}
+}
+
+func TestWhisperAsksForWordTimestamps(t *testing.T) {
+	var fields map[string]string
+	var file, authorization string
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		_, params, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		reader := multipart.NewReader(r.Body, params["boundary"])
+		fields = map[string]string{}
+		for {
+			part, err := reader.NextPart()
+			if err != nil {
+				break
+			}
+			value, _ := io.ReadAll(part)
+			if part.FormName() == "file" {
+				file = string(value)
+			} else {
+				fields[part.FormName()] = string(value)
+			}
+		}
+		w.WriteHeader(status)
+		_, _ = io.WriteString(w, `{"text":"Once upon.","words":[{"word":" Once","start":0.1,"end":0.42},{"word":"upon","start":0.5,"end":0.9}]}`)
+	}))
+	t.Cleanup(server.Close)
+
+	provider := &Whisper{name: ProviderOpenAI, URL: server.URL, APIKey: "secret", Model: "whisper-1", client: server.Client()}
+	words, err := provider.Transcribe(context.Background(), []byte("RIFF"), "Once upon.")
+	if err != nil {
+		t.Fatalf("Transcribe error = %v", err)
+	}
+	if !reflect.DeepEqual(words, []SpokenWord{{Text: "Once", Start: 100 * time.Millisecond, End: 420 * time.Millisecond}, {Text: "upon", Start: 500 * time.Millisecond, End: 900 * time.Millisecond}}) {
+		t.Fatalf("words = %#v", words)
+	}
+	if file != "RIFF" || fields["model"] != "whisper-1" || fields["response_format"] != "verbose_json" ||
+		fields["timestamp_granularities[]"] != "word" || fields["prompt"] != "Once upon." || authorization != "Bearer secret" {
+		t.Fatalf("request fields = %#v, file = %q, authorization = %q", fields, file, authorization)
+	}
+
+	status = http.StatusBadGateway
+	if _, err := provider.Transcribe(context.Background(), []byte("RIFF"), ""); err == nil || !strings.Contains(err.Error(), "502") {
+		t.Fatalf("error status Transcribe error = %v", err)
+	}
+}
+
+type fakeStore struct {
+	queued   []model.AlignmentJob
+	segments []model.AlignmentSegment
+	recorded [][]model.WordTiming
+	failure  *string
+	finished int
+}
+
+func (s *fakeStore) AlignmentClaimJob(context.Context, time.Duration) (model.AlignmentJob, bool, error) {
+	if len(s.queued) == 0 {
+		return model.AlignmentJob{}, false, nil
+	}
+	job := s.queued[0]
+	s.queued = s.queued[1:]
+	job.Status = model.RenderJobRunning
+	return job, true, nil
+}
+
+func (s *fakeStore) AlignmentPendingSegments(_ context.Context, job model.AlignmentJob, limit int) ([]model.AlignmentSegment, error) {
+	var out []model.AlignmentSegment
+	for _, segment := range s.segments {
+		if segment.Ordinal > job.CursorOrdinal && len(out) < limit {
+			out = append(out, segment)
+		}
+	}
+	return out, nil
+}
+
+func (s *fakeStore) AlignmentRecordSegment(_ context.Context, job model.AlignmentJob, segment model.AlignmentSegment, words []model.WordTiming, _ time.Duration) (model.AlignmentJob, error) {
+	s.recorded = append(s.recorded, words)
+	job.CursorOrdinal = segment.Ordinal
+	job.AlignedSegments++
+	return job, nil
+}
+
+func (s *fakeStore) AlignmentFinishJob(_ context.Context, job model.AlignmentJob, failure *string) (model.AlignmentJob, error) {
+	s.finished++
+	s.failure = failure
+	job.Status = model.RenderJobCompleted
+	if failure != nil {
+		job.Status = model.RenderJobFailed
+	}
+	return job, nil
+}
+
+type fakeProvider struct {
+	failOn  string
+	scripts []string
+}
+
+func (p *fakeProvider) Name() string { return "fake" }
+
+// Transcribe hears the script, a word every 100ms.
+func (p *fakeProvider) Transcribe(_ context.Context, _ []byte, script string) ([]SpokenWord, error) {
+	if script == p.failOn {
+		return nil, ErrProvider
+	}
+	p.scripts = append(p.scripts, script)
+	var out []SpokenWord
+	for index, word := range strings.Fields(script) {
+		start := time.Duration(index) * 100 * time.Millisecond
+		out = append(out, SpokenWord{Text: word, Start: start, End: start + 90*time.Millisecond})
+	}
+	return out, nil
+}
+
+func segment(ordinal int, html string) model.AlignmentSegment {
+	out := model.AlignmentSegment{AudioID: "audio", Audio: model.NarrationAudio{Duration: time.Second}}
+	out.Ordinal, out.RenderedHTML = ordinal, html
+	return out
+}
+
+func TestWorkerAlignsEverySegmentFromItsScript(t *testing.T) {
+	pronounced := segment(2, "<p>Hi Hermione.</p>")
+	pronounced.Pronunciations = []model.ReaderPronunciation{{Text: "Hermione", Say: "her-MY-oh-nee"}}
+	store := &fakeStore{
+		queued: []model.AlignmentJob{{ID: "job"}},
+		segments: []model.AlignmentSegment{
+			segment(1, "<p>Once <em>upon</em>.</p>"),
+			pronounced,
+			segment(3, `<figure><img src="x" alt=""></figure>`),
+		},
+	}
+	provider := &fakeProvider{}
+	worker := NewWorker(store, provider)
+	worker.runner.Batch = 2
+
+	worker.RunOnce(context.Background())
+	if strings.Join(provider.scripts, "|") != "Once upon .|Hi her-MY-oh-nee." {
+		t.Fatalf("scripts = %q", provider.scripts)
+	}
+	if len(store.recorded) != 3 || store.recorded[2] != nil || store.finished != 1 || store.failure != nil {
+		t.Fatalf("recorded = %#v, finished = %d, failure = %v", store.recorded, store.finished, store.failure)
+	}
+	if words := store.recorded[1]; len(words) != 2 || words[1].Text != "Hermione." || words[1].StartMs != 90 || words[1].EndMs != 1000 {
+		t.Fatalf("pronounced words = %#v", words)
+	}
+}
+
+func TestWorkerFailsAJobAtTheSegmentTheProviderRefuses(t *testing.T) {
+	store := &fakeStore{
+		queued:   []model.AlignmentJob{{ID: "job"}},
+		segments: []model.AlignmentSegment{segment(1, "<p>One.</p>"), segment(2, "<p>Two.</p>")},
+	}
+	NewWorker(store, &fakeProvider{failOn: "Two."}).RunOnce(context.Background())
+	if len(store.recorded) != 1 || store.failure == nil || *store.failure != "segment 2 could not be aligned" {
+		t.Fatalf("recorded = %d, failure = %v", len(store.recorded), store.failure)
+	}
+}
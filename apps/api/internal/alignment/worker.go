@@ -0,0 +1,93 @@
+package alignment
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"pandapages/api/internal/jobrunner"
+	"pandapages/api/internal/model"
+	"pandapages/api/internal/providerlog"
+	"pandapages/api/internal/tts"
+)
+
+const (
+	defaultInterval = 10 * time.Second
+	// The lease is renewed after every segment, so it need only outlast one
+	// provider call.
+	claimLease = requestTimeout + time.Minute
+	// Pending segments carry their recordings, so batches stay small.
+	batchSize = 4
+)
+
+type Store interface {
+	AlignmentClaimJob(ctx context.Context, lease time.Duration) (model.AlignmentJob, bool, error)
+	AlignmentPendingSegments(ctx context.Context, job model.AlignmentJob, limit int) ([]model.AlignmentSegment, error)
+	AlignmentRecordSegment(ctx context.Context, job model.AlignmentJob, segment model.AlignmentSegment, words []model.WordTiming, lease time.Duration) (model.AlignmentJob, error)
+	AlignmentFinishJob(ctx context.Context, job model.AlignmentJob, failure *string) (model.AlignmentJob, error)
+}
+
+// Worker aligns queued jobs. A recording the provider cannot transcribe
+// fails the job; asking again resumes from it, since segments already
+// aligned keep their timings.
+type Worker struct {
+	runner jobrunner.Runner[model.AlignmentJob, model.AlignmentSegment]
+}
+
+func NewWorker(store Store, provider Provider) *Worker {
+	return &Worker{runner: jobrunner.Runner[model.AlignmentJob, model.AlignmentSegment]{
+		Jobs:     alignmentJobs{store: store, provider: provider},
+		Kind:     "alignment",
+		Item:     "segment",
+		Lease:    claimLease,
+		Interval: defaultInterval,
+		Batch:    batchSize,
+	}}
+}
+
+// Run processes queued jobs until ctx is cancelled.
+func (w *Worker) Run(ctx context.Context) { w.runner.Run(ctx) }
+
+// RunOnce claims one job and aligns it until it finishes or ctx ends.
+func (w *Worker) RunOnce(ctx context.Context) { w.runner.RunOnce(ctx) }
+
+// alignmentJobs is the jobrunner side of alignment jobs.
+type alignmentJobs struct {
+	store    Store
+	provider Provider
+}
+
+func (a alignmentJobs) Claim(ctx context.Context, lease time.Duration) (model.AlignmentJob, bool, error) {
+	return a.store.AlignmentClaimJob(ctx, lease)
+}
+
+func (a alignmentJobs) Pending(ctx context.Context, job model.AlignmentJob, limit int) ([]model.AlignmentSegment, error) {
+	return a.store.AlignmentPendingSegments(ctx, job, limit)
+}
+
+func (a alignmentJobs) Process(ctx context.Context, job model.AlignmentJob, segment model.AlignmentSegment, lease time.Duration) (model.AlignmentJob, string, error) {
+	var words []model.WordTiming
+	if text := Text(segment.RenderedHTML); text != "" {
+		spoken, err := a.provider.Transcribe(ctx, segment.Audio.Data, tts.Script(segment.NarrationSegment))
+		if ctx.Err() != nil {
+			return job, "", ctx.Err()
+		}
+		if err != nil {
+			providerlog.Failure("alignment segment failed", a.provider.Name(), "job", job.ID, "ordinal", segment.Ordinal)
+			return job, "segment " + strconv.Itoa(segment.Ordinal) + " could not be aligned", nil
+		}
+		words = Align(text, spoken, segment.Audio.Duration)
+	}
+	next, err := a.store.AlignmentRecordSegment(ctx, job, segment, words, lease)
+	return next, "", err
+}
+
+func (a alignmentJobs) Finish(ctx context.Context, job model.AlignmentJob, failure *string) (model.AlignmentJob, error) {
+	return a.store.AlignmentFinishJob(ctx, job, failure)
+}
+
+func (alignmentJobs) ID(job model.AlignmentJob) string { return job.ID }
+
+func (alignmentJobs) Summary(job model.AlignmentJob) []any {
+	return []any{"status", string(job.Status), "aligned", job.AlignedSegments, "total", job.TotalSegments}
+}
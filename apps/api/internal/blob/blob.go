@@ -0,0 +1,107 @@
+// Package blob keeps generated artifacts, such as uploaded images,
+// narration, EPUB books and backups, in a local directory or an S3 or
+// Google Cloud Storage bucket instead of the database. Buckets also sign
+// short-lived URLs, so large downloads go straight to the reader rather than
+// through the API.
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// SignedURLLifetime is how long a signed download URL stays valid: long
+// enough to start a download, short enough that a leaked link soon expires.
+const SignedURLLifetime = 15 * time.Minute
+
+var (
+	// ErrNotFound marks a key with no object.
+	ErrNotFound = errors.New("blob was not found")
+	// ErrUnsigned marks a store that cannot sign URLs; the API serves its
+	// objects itself.
+	ErrUnsigned = errors.New("blob store cannot sign URLs")
+)
+
+// keyRe accepts the keys the API makes: slash-separated names of letters,
+// digits, dots, hyphens and underscores, none of them only dots.
+var keyRe = regexp.MustCompile(`^[A-Za-z0-9_-][A-Za-z0-9._-]*(?:/[A-Za-z0-9_-][A-Za-z0-9._-]*)*$`)
+
+func validKey(key string) bool {
+	return len(key) <= 512 && keyRe.MatchString(key)
+}
+
+// Store keeps objects by key. Put replaces an object whole; size is the
+// body's length.
+type Store interface {
+	Name() string
+	Put(ctx context.Context, key, contentType string, body io.Reader, size int64) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Exists(ctx context.Context, key string) (bool, error)
+	Delete(ctx context.Context, key string) error
+	// SignedURL lets anyone holding it download the object until ttl
+	// passes. A filename has it downloaded as an attachment of that name.
+	SignedURL(ctx context.Context, key string, ttl time.Duration, filename string) (string, error)
+}
+
+// Load reads PP_BLOB_STORE: database, the default, keeps artifacts in
+// PostgreSQL and returns nil; fs keeps them under PP_BLOB_DIR; s3 and gcs
+// keep them in PP_BLOB_BUCKET with an access key, an HMAC key for gcs.
+func Load(getenv func(string) string) (Store, error) {
+	kind := strings.ToLower(strings.TrimSpace(getenv("PP_BLOB_STORE")))
+	switch kind {
+	case "", "database":
+		return nil, nil
+	case "fs":
+		dir := strings.TrimSpace(getenv("PP_BLOB_DIR"))
+		if dir == "" {
+			return nil, fmt.Errorf("PP_BLOB_DIR is required when PP_BLOB_STORE is fs")
+		}
+		return NewDir(dir)
+	case "s3", "gcs":
+	default:
+		return nil, fmt.Errorf("PP_BLOB_STORE must be database, fs, s3 or gcs")
+	}
+
+	name := strings.TrimSpace(getenv("PP_BLOB_BUCKET"))
+	if name == "" {
+		return nil, fmt.Errorf("PP_BLOB_BUCKET is required when PP_BLOB_STORE is %s", kind)
+	}
+	accessKey := strings.TrimSpace(getenv("PP_BLOB_ACCESS_KEY_ID"))
+	secret := getenv("PP_BLOB_SECRET_ACCESS_KEY")
+	if accessKey == "" || secret == "" {
+		return nil, fmt.Errorf("PP_BLOB_ACCESS_KEY_ID and PP_BLOB_SECRET_ACCESS_KEY are required when PP_BLOB_STORE is %s", kind)
+	}
+	region := strings.TrimSpace(getenv("PP_BLOB_REGION"))
+	var endpoint *url.URL
+	if raw := strings.TrimSpace(getenv("PP_BLOB_ENDPOINT")); raw != "" {
+		parsed, err := url.Parse(raw)
+		if err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Host == "" ||
+			parsed.User != nil || strings.Trim(parsed.Path, "/") != "" || parsed.RawQuery != "" {
+			return nil, fmt.Errorf("PP_BLOB_ENDPOINT must be an http or https origin")
+		}
+		endpoint = &url.URL{Scheme: parsed.Scheme, Host: parsed.Host}
+	}
+	if kind == "gcs" {
+		return NewGCS(name, accessKey, secret, endpoint), nil
+	}
+	if region == "" {
+		region = "us-east-1"
+	}
+	return NewS3(name, region, accessKey, secret, endpoint), nil
+}
+
+// ReadAll reads a whole object.
+func ReadAll(ctx context.Context, store Store, key string) ([]byte, error) {
+	body, err := store.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	return io.ReadAll(body)
+}
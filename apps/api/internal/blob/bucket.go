@@ -0,0 +1,328 @@
+package blob
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// maxSignedURLLifetime is the longest both S3 and GCS accept.
+	maxSignedURLLifetime = 7 * 24 * time.Hour
+	unsignedPayload      = "UNSIGNED-PAYLOAD"
+	emptyPayloadHash     = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	signingTimeFormat    = "20060102T150405Z"
+)
+
+// dialect is how a service names the parts of a version 4 signature. GCS
+// takes S3's scheme with its interoperability HMAC keys, under its own names.
+type dialect struct {
+	service    string
+	algorithm  string
+	keyPrefix  string
+	terminator string
+	// header prefixes the signature headers and query parameter names
+	// prefixes the signed URL parameters.
+	header string
+	query  string
+}
+
+var (
+	s3Dialect = dialect{
+		service: "s3", algorithm: "AWS4-HMAC-SHA256", keyPrefix: "AWS4", terminator: "aws4_request",
+		header: "x-amz-", query: "X-Amz-",
+	}
+	gcsDialect = dialect{
+		service: "storage", algorithm: "GOOG4-HMAC-SHA256", keyPrefix: "GOOG4", terminator: "goog4_request",
+		header: "x-goog-", query: "X-Goog-",
+	}
+)
+
+// Bucket keeps objects in an S3 or GCS bucket over their XML APIs.
+type Bucket struct {
+	name      string
+	dialect   dialect
+	region    string
+	accessKey string
+	secret    string
+	// origin and prefix place an object at origin/prefix/key: the bucket is in
+	// the host for AWS, and the first path segment elsewhere.
+	origin *url.URL
+	prefix string
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewS3 uses an S3 bucket, or one on another S3-compatible service when
+// endpoint is set.
+func NewS3(bucket, region, accessKey, secret string, endpoint *url.URL) *Bucket {
+	b := &Bucket{name: "s3:" + bucket, dialect: s3Dialect, region: region, accessKey: accessKey, secret: secret}
+	if endpoint == nil {
+		b.origin = &url.URL{Scheme: "https", Host: bucket + ".s3." + region + ".amazonaws.com"}
+	} else {
+		b.origin, b.prefix = endpoint, "/"+bucket
+	}
+	return b.init()
+}
+
+// NewGCS uses a Google Cloud Storage bucket with an HMAC key.
+func NewGCS(bucket, accessKey, secret string, endpoint *url.URL) *Bucket {
+	if endpoint == nil {
+		endpoint = &url.URL{Scheme: "https", Host: "storage.googleapis.com"}
+	}
+	b := &Bucket{
+		name: "gcs:" + bucket, dialect: gcsDialect, region: "auto", accessKey: accessKey, secret: secret,
+		origin: endpoint, prefix: "/" + bucket,
+	}
+	return b.init()
+}
+
+func (b *Bucket) init() *Bucket {
+	b.client = &http.Client{Timeout: 5 * time.Minute}
+	b.now = time.Now
+	return b
+}
+
+func (b *Bucket) Name() string {
+	return b.name
+}
+
+func (b *Bucket) objectURL(key string) (*url.URL, error) {
+	if !validKey(key) {
+		return nil, fmt.Errorf("blob key is invalid")
+	}
+	u := *b.origin
+	u.Path = b.prefix + "/" + key
+	return &u, nil
+}
+
+func (b *Bucket) Put(ctx context.Context, key, contentType string, body io.Reader, size int64) error {
+	header := http.Header{}
+	header.Set("Content-Type", contentType)
+	res, err := b.do(ctx, http.MethodPut, key, header, body, size)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	return b.check(res, key)
+}
+
+func (b *Bucket) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	res, err := b.do(ctx, http.MethodGet, key, nil, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	if err := b.check(res, key); err != nil {
+		res.Body.Close()
+		return nil, err
+	}
+	return res.Body, nil
+}
+
+func (b *Bucket) Exists(ctx context.Context, key string) (bool, error) {
+	res, err := b.do(ctx, http.MethodHead, key, nil, nil, 0)
+	if err != nil {
+		return false, err
+	}
+	defer res.Body.Close()
+	err = b.check(res, key)
+	if err == ErrNotFound {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Delete removes the object; a missing one is not an error.
+func (b *Bucket) Delete(ctx context.Context, key string) error {
+	res, err := b.do(ctx, http.MethodDelete, key, nil, nil, 0)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if err := b.check(res, key); err != nil && err != ErrNotFound {
+		return err
+	}
+	return nil
+}
+
+// SignedURL presigns a GET for the object. The signature covers only the
+// host, so any client can follow it.
+func (b *Bucket) SignedURL(_ context.Context, key string, ttl time.Duration, filename string) (string, error) {
+	u, err := b.objectURL(key)
+	if err != nil {
+		return "", err
+	}
+	if ttl <= 0 || ttl > maxSignedURLLifetime {
+		return "", fmt.Errorf("signed URL lifetime must be at most %s", maxSignedURLLifetime)
+	}
+	at := b.now().UTC()
+	q := b.dialect.query
+	query := url.Values{}
+	query.Set(q+"Algorithm", b.dialect.algorithm)
+	query.Set(q+"Credential", b.accessKey+"/"+b.scope(at))
+	query.Set(q+"Date", at.Format(signingTimeFormat))
+	query.Set(q+"Expires", strconv.FormatInt(int64(ttl/time.Second), 10))
+	query.Set(q+"SignedHeaders", "host")
+	if filename != "" {
+		query.Set("response-content-disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	}
+	u.RawQuery = canonicalQuery(query)
+	signature := b.signature(http.MethodGet, u, http.Header{}, unsignedPayload, at)
+	u.RawQuery += "&" + q + "Signature=" + signature
+	return u.String(), nil
+}
+
+// do sends a request signed in its headers. Uploads are signed without
+// hashing the body, so it is streamed once; TLS protects it in transit.
+func (b *Bucket) do(ctx context.Context, method, key string, header http.Header, body io.Reader, size int64) (*http.Response, error) {
+	u, err := b.objectURL(key)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	payload := emptyPayloadHash
+	if body != nil {
+		payload = unsignedPayload
+		req.ContentLength = size
+	}
+	at := b.now().UTC()
+	req.Header.Set(b.dialect.header+"content-sha256", payload)
+	req.Header.Set(b.dialect.header+"date", at.Format(signingTimeFormat))
+	signed := http.Header{}
+	for name, values := range req.Header {
+		signed[name] = values
+	}
+	signature := b.signature(method, req.URL, signed, payload, at)
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		b.dialect.algorithm, b.accessKey, b.scope(at), signedHeaderNames(signed), signature))
+	return b.client.Do(req)
+}
+
+func (b *Bucket) check(res *http.Response, key string) error {
+	switch {
+	case res.StatusCode >= 200 && res.StatusCode < 300:
+		return nil
+	case res.StatusCode == http.StatusNotFound:
+		return ErrNotFound
+	default:
+		return fmt.Errorf("%s %s: %s", b.name, key, res.Status)
+	}
+}
+
+func (b *Bucket) scope(at time.Time) string {
+	return at.Format("20060102") + "/" + b.region + "/" + b.dialect.service + "/" + b.dialect.terminator
+}
+
+// signature is the version 4 signature of a request to u carrying header,
+// which must include the date header for header signing; host is always
+// signed.
+func (b *Bucket) signature(method string, u *url.URL, header http.Header, payload string, at time.Time) string {
+	header = header.Clone()
+	header.Set("Host", u.Host)
+	canonical := strings.Join([]string{
+		method,
+		canonicalPath(u.Path),
+		u.RawQuery,
+		canonicalHeaders(header),
+		signedHeaderNames(header),
+		payload,
+	}, "\n")
+	sum := sha256.Sum256([]byte(canonical))
+	toSign := strings.Join([]string{
+		b.dialect.algorithm,
+		at.Format(signingTimeFormat),
+		b.scope(at),
+		hex.EncodeToString(sum[:]),
+	}, "\n")
+	key := hmacSHA256([]byte(b.dialect.keyPrefix+b.secret), at.Format("20060102"))
+	for _, part := range []string{b.region, b.dialect.service, b.dialect.terminator} {
+		key = hmacSHA256(key, part)
+	}
+	return hex.EncodeToString(hmacSHA256(key, toSign))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func canonicalPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = uriEncode(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+func canonicalQuery(query url.Values) string {
+	pairs := make([]string, 0, len(query))
+	for name, values := range query {
+		for _, value := range values {
+			pairs = append(pairs, uriEncode(name)+"="+uriEncode(value))
+		}
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, "&")
+}
+
+func canonicalHeaders(header http.Header) string {
+	var out strings.Builder
+	for _, name := range sortedHeaderNames(header) {
+		values := header.Values(name)
+		for i, value := range values {
+			values[i] = strings.Join(strings.Fields(value), " ")
+		}
+		out.WriteString(name + ":" + strings.Join(values, ",") + "\n")
+	}
+	return out.String()
+}
+
+func signedHeaderNames(header http.Header) string {
+	names := sortedHeaderNames(header)
+	if header.Get("Host") == "" {
+		names = append(names, "host")
+		sort.Strings(names)
+	}
+	return strings.Join(names, ";")
+}
+
+func sortedHeaderNames(header http.Header) []string {
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, strings.ToLower(name))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// uriEncode percent-encodes everything but the unreserved characters, as
+// version 4 signing requires.
+func uriEncode(s string) string {
+	var out strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || strings.IndexByte("-._~", c) >= 0 {
+			out.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&out, "%%%02X", c)
+	}
+	return out.String()
+}
@@ -0,0 +1,71 @@
+package blob
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+const (
+	sweepInterval = 5 * time.Minute
+	sweepBatch    = 100
+)
+
+// GarbageStore lists objects no row references any more and forgets those
+// deleted.
+type GarbageStore interface {
+	BlobGarbage(ctx context.Context, limit int) ([]string, error)
+	BlobGarbageDeleted(ctx context.Context, keys []string) error
+}
+
+// Sweeper deletes objects left by replaced recordings and deleted rows. One
+// it fails to delete stays listed and is tried again next time.
+type Sweeper struct {
+	store    GarbageStore
+	blobs    Store
+	interval time.Duration
+}
+
+func NewSweeper(store GarbageStore, blobs Store) *Sweeper {
+	return &Sweeper{store: store, blobs: blobs, interval: sweepInterval}
+}
+
+// Run sweeps until ctx is cancelled, batch after batch while whole ones are
+// deleted.
+func (s *Sweeper) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		for s.RunOnce(ctx) == sweepBatch && ctx.Err() == nil {
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce deletes one batch of objects and returns how many it deleted.
+func (s *Sweeper) RunOnce(ctx context.Context) int {
+	keys, err := s.store.BlobGarbage(ctx, sweepBatch)
+	if err != nil {
+		slog.Error("blob garbage query failed")
+		return 0
+	}
+	deleted := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if ctx.Err() != nil {
+			break
+		}
+		if err := s.blobs.Delete(ctx, key); err != nil {
+			slog.Warn("blob delete failed", "blob_store", s.blobs.Name())
+			continue
+		}
+		deleted = append(deleted, key)
+	}
+	if err := s.store.BlobGarbageDeleted(context.WithoutCancel(ctx), deleted); err != nil {
+		slog.Error("blob garbage update failed")
+	}
+	return len(deleted)
+}
@@ -16,13 +16,17 @@ import (
 	"pandapages/api/internal/model"
 	"pandapages/api/internal/readercontract"
 	"pandapages/api/internal/storyingest"
+	"pandapages/api/internal/storylint"
+	"pandapages/api/internal/webhooks"
+
+	"github.com/jackc/pgx/v5"
 )
 
 var errStoredVersionInvalid = errors.New("stored story version is invalid")
 
 type storedVersionQueryer interface {
-	QueryRowContext(context.Context, string, ...any) *sql.Row
-	QueryContext(context.Context, string, ...any) (*sql.Rows, error)
+	QueryRow(context.Context, string, ...any) pgx.Row
+	Query(context.Context, string, ...any) (pgx.Rows, error)
 }
 
 type storedReaderVersionSnapshot struct {
@@ -186,7 +190,7 @@ func validateStoredReaderVersionWithLock(
 	if lock {
 		versionLock = " FOR UPDATE"
 	}
-	if err := queryer.QueryRowContext(ctx, `
+	if err := queryer.QueryRow(ctx, `
 		SELECT version, created_at, frontmatter::text, markdown, rendered_html, content_hash
 		FROM story_versions
 		WHERE id = $1
@@ -224,7 +228,7 @@ func validateStoredReaderVersionWithLock(
 	if lock {
 		segmentLock = " FOR SHARE OF segment"
 	}
-	rows, err := queryer.QueryContext(ctx, `
+	rows, err := queryer.Query(ctx, `
 		SELECT
 			segment.id,
 			segment.ordinal,
@@ -247,7 +251,6 @@ func validateStoredReaderVersionWithLock(
 
 	identities := make([]readercontract.StoredSegmentIdentity, 0, 32)
 	wordCount := int64(0)
-	chapterCount := 0
 	for rows.Next() {
 		var (
 			segmentID         sql.NullString
@@ -316,9 +319,6 @@ func validateStoredReaderVersionWithLock(
 			identity.ChapterKey = &key
 			identity.ChapterOccurrence = &value
 		}
-		if identity.Kind == readercontract.SegmentKindHeading && identity.HeadingLevel != nil && *identity.HeadingLevel == 2 {
-			chapterCount++
-		}
 		wordCount += segmentWordCount.Int64
 		identities = append(identities, identity)
 	}
@@ -328,23 +328,16 @@ func validateStoredReaderVersionWithLock(
 	if len(identities) == 0 {
 		return storedReaderVersionSnapshot{}, fmt.Errorf("%w: no readable segments", errStoredVersionInvalid)
 	}
-	if _, err := readercontract.ValidateStoredSegmentIdentities(identities); err != nil {
+	chapters, err := storyingest.ChapterRuleFromFrontmatter(frontmatter.Values)
+	if err != nil {
+		return storedReaderVersionSnapshot{}, fmt.Errorf("%w: chapter rule", errStoredVersionInvalid)
+	}
+	chapterCount, err := readercontract.ValidateStoredSegmentIdentities(identities, chapters)
+	if err != nil {
 		return storedReaderVersionSnapshot{}, fmt.Errorf("%w: segment identities", errStoredVersionInvalid)
 	}
 
-	authorValue := ""
-	if frontmatter.Author != nil {
-		authorValue = *frontmatter.Author
-	}
-	canonical, err := storyingest.CanonicalizeStoredBody(storyingest.Input{
-		Slug:      slug,
-		Title:     frontmatter.Title,
-		Author:    authorValue,
-		Markdown:  markdown,
-		Language:  frontmatter.Language,
-		SourceURL: stringValue(frontmatter.SourceURL),
-		Rights:    frontmatter.Rights,
-	}, frontmatter.Values)
+	canonical, err := frontmatter.canonicalize(slug, markdown)
 	if err != nil {
 		return storedReaderVersionSnapshot{}, fmt.Errorf("%w: canonical story body", errStoredVersionInvalid)
 	}
@@ -367,6 +360,24 @@ func validateStoredReaderVersionWithLock(
 	return snapshot, nil
 }
 
+// canonicalize re-ingests a stored body under its stored metadata, as the
+// current renderer would have written it.
+func (f normalizedStoredFrontmatter) canonicalize(slug, markdown string) (storyingest.Output, error) {
+	author := ""
+	if f.Author != nil {
+		author = *f.Author
+	}
+	return storyingest.CanonicalizeStoredBody(storyingest.Input{
+		Slug:      slug,
+		Title:     f.Title,
+		Author:    author,
+		Markdown:  markdown,
+		Language:  f.Language,
+		SourceURL: stringValue(f.SourceURL),
+		Rights:    f.Rights,
+	}, f.Values)
+}
+
 func stringValue(value *string) string {
 	if value == nil {
 		return ""
@@ -398,7 +409,7 @@ func storedReaderSegmentsMatchWithLock(
 	if lock {
 		segmentLock = " FOR SHARE"
 	}
-	rows, err := queryer.QueryContext(ctx, `
+	rows, err := queryer.Query(ctx, `
 		SELECT
 			ordinal,
 			segment_kind,
@@ -409,7 +420,9 @@ func storedReaderSegmentsMatchWithLock(
 			chapter_occurrence,
 			markdown,
 			rendered_html,
-			word_count
+			word_count,
+			speaker,
+			pronunciations::text
 		FROM story_segments
 		WHERE story_version_id = $1
 		ORDER BY ordinal ASC
@@ -432,6 +445,8 @@ func storedReaderSegmentsMatchWithLock(
 			markdown          sql.NullString
 			renderedHTML      sql.NullString
 			wordCount         sql.NullInt64
+			speaker           sql.NullString
+			pronunciations    sql.NullString
 		)
 		if err := rows.Scan(
 			&ordinal,
@@ -444,6 +459,8 @@ func storedReaderSegmentsMatchWithLock(
 			&markdown,
 			&renderedHTML,
 			&wordCount,
+			&speaker,
+			&pronunciations,
 		); err != nil {
 			return false, err
 		}
@@ -460,7 +477,16 @@ func storedReaderSegmentsMatchWithLock(
 			!nullableIntMatches(chapterOccurrence, segment.ChapterOccurrence) ||
 			!markdown.Valid || markdown.String != segment.Markdown ||
 			!renderedHTML.Valid || renderedHTML.String != segment.RenderedHTML ||
-			!wordCount.Valid || wordCount.Int64 != int64(segment.WordCount) {
+			!wordCount.Valid || wordCount.Int64 != int64(segment.WordCount) ||
+			!nullableStringMatches(speaker, optionalString(segment.Speaker)) {
+			return false, nil
+		}
+		expectedHints, err := pronunciationsJSON(segment.Pronunciations)
+		if err != nil {
+			return false, err
+		}
+		if pronunciations.Valid != (expectedHints != nil) ||
+			expectedHints != nil && !jsonDocumentsEqual([]byte(pronunciations.String), []byte(*expectedHints)) {
 			return false, nil
 		}
 		index++
@@ -546,8 +572,8 @@ func normalizeJSONNumbers(value any) (any, bool) {
 	}
 }
 
-func (s *Store) AdminPreview(req model.AdminPreviewRequest) (model.AdminPreviewResponse, error) {
-	out, err := canonicalAdminStoryInput(req)
+func (s *Store) AdminPreview(ctx context.Context, req model.AdminPreviewRequest) (model.AdminPreviewResponse, error) {
+	out, err := canonicalAdminStoryInput(ctx, req)
 	if err != nil {
 		return model.AdminPreviewResponse{}, err
 	}
@@ -564,18 +590,44 @@ func (s *Store) AdminPreview(req model.AdminPreviewRequest) (model.AdminPreviewR
 		SegmentCount: len(out.Segments),
 		WordCount:    wordCount,
 		ChapterCount: chapterCount,
-		Warnings:     []model.AdminValidationIssue{},
+		Readability:  readabilityModel(out.Readability),
+		Warnings:     adminStrippedIssues(req.Markdown),
 	}, nil
 }
 
-func canonicalAdminStoryInput(req model.AdminStoryInput) (storyingest.Output, error) {
+// AdminLint runs the advisory structural checks on the same canonical input
+// that preview and draft creation use.
+func (s *Store) AdminLint(ctx context.Context, req model.AdminStoryInput) (model.AdminLintResponse, error) {
+	out, err := canonicalAdminStoryInput(ctx, req)
+	if err != nil {
+		return model.AdminLintResponse{}, err
+	}
+	return model.AdminLintResponse{Warnings: adminLintWarnings(out)}, nil
+}
+
+func adminLintWarnings(out storyingest.Output) []model.AdminLintWarning {
+	findings := storylint.Lint(out)
+	warnings := make([]model.AdminLintWarning, 0, len(findings))
+	for _, finding := range findings {
+		warning := model.AdminLintWarning{Code: finding.Code, Message: finding.Message}
+		if finding.Ordinal > 0 {
+			warning.Segment = &model.AdminLintLocator{Ordinal: finding.Ordinal, ContentKey: finding.ContentKey}
+		}
+		warnings = append(warnings, warning)
+	}
+	return warnings
+}
+
+func canonicalAdminStoryInput(ctx context.Context, req model.AdminStoryInput) (storyingest.Output, error) {
 	slug := strings.TrimSpace(req.Slug)
 	title := strings.TrimSpace(req.Title)
 	author := ""
 	if req.Author != nil {
 		author = strings.TrimSpace(*req.Author)
 	}
-	language := "en-GB"
+	// Without a declared language, ingest reads frontmatter and then
+	// detects one from the text.
+	language := ""
 	if req.Language != nil && strings.TrimSpace(*req.Language) != "" {
 		language = strings.TrimSpace(*req.Language)
 	}
@@ -608,20 +660,37 @@ func canonicalAdminStoryInput(req model.AdminStoryInput) (storyingest.Output, er
 	if _, err := json.Marshal(req.Rights); err != nil {
 		issues = append(issues, model.AdminValidationIssue{Field: "rights", Code: "invalid", Message: "Enter valid rights information"})
 	}
+	if len(issues) > 0 && strings.TrimSpace(req.Markdown) != "" && utf8.ValidString(req.Markdown) {
+		// Report frontmatter defects alongside field issues so one round trip
+		// shows everything wrong with the submission.
+		issues = append(issues, adminIngestIssues(storyingest.CheckFrontmatter(req.Markdown))...)
+	}
 	if len(issues) > 0 {
 		return storyingest.Output{}, &model.AdminValidationError{Issues: issues}
 	}
 
-	out, err := storyingest.Ingest(storyingest.Input{
+	// Gutenberg packaging never belongs in a story version; preview and
+	// validate report what was removed.
+	markdown, _ := storyingest.StripGutenberg(req.Markdown)
+	out, err := storyingest.IngestContext(ctx, storyingest.Input{
 		Slug:      slug,
 		Title:     title,
 		Author:    author,
-		Markdown:  req.Markdown,
+		Markdown:  markdown,
 		Language:  language,
 		SourceURL: sourceURL,
 		Rights:    req.Rights,
+
+		MarkdownExtensions: req.MarkdownExtensions,
+		ChapterLevel:       req.ChapterLevel,
+		ChapterPattern:     req.ChapterPattern,
+		NumberChapters:     req.NumberChapters,
 	})
 	if err != nil {
+		var inputErr *storyingest.InputError
+		if errors.As(err, &inputErr) && len(inputErr.Problems) > 0 {
+			return storyingest.Output{}, &model.AdminValidationError{Issues: adminIngestIssues(inputErr.Problems)}
+		}
 		return storyingest.Output{}, &model.AdminValidationError{Issues: []model.AdminValidationIssue{{
 			Field: "markdown", Code: "invalid", Message: "Story content could not be processed",
 		}}}
@@ -629,12 +698,91 @@ func canonicalAdminStoryInput(req model.AdminStoryInput) (storyingest.Output, er
 	return out, nil
 }
 
+// AdminValidate runs the draft canonicalisation without storing anything.
+// Invalid input is the answer rather than an error.
+func (s *Store) AdminValidate(ctx context.Context, req model.AdminStoryInput) (model.AdminValidateResponse, error) {
+	response := model.AdminValidateResponse{
+		Valid:    true,
+		Issues:   []model.AdminValidationIssue{},
+		Warnings: []model.AdminValidationIssue{},
+	}
+	if utf8.ValidString(req.Markdown) {
+		response.Warnings = adminIngestIssues(storyingest.UnsupportedConstructs(req.Markdown))
+		response.Warnings = append(response.Warnings, adminStrippedIssues(req.Markdown)...)
+	}
+	if _, err := canonicalAdminStoryInput(ctx, req); err != nil {
+		var validationErr *model.AdminValidationError
+		if !errors.As(err, &validationErr) {
+			return model.AdminValidateResponse{}, err
+		}
+		response.Valid = false
+		response.Issues = validationErr.Issues
+	}
+	return response, nil
+}
+
+// adminStrippedIssues reports the Gutenberg packaging canonicalisation
+// removes from the submitted Markdown.
+func adminStrippedIssues(markdown string) []model.AdminValidationIssue {
+	_, removed := storyingest.StripGutenberg(markdown)
+	return adminIngestIssues(removed)
+}
+
+// adminIngestIssues maps ingest problems to admin issues. Parser messages stay
+// internal; admins see a fixed message and the line to look at.
+func adminIngestIssues(problems []storyingest.Problem) []model.AdminValidationIssue {
+	issues := make([]model.AdminValidationIssue, 0, len(problems))
+	for _, problem := range problems {
+		issue := model.AdminValidationIssue{Field: problem.Field, Code: problem.Code, Line: problem.Line}
+		switch problem.Code {
+		case "invalid_yaml":
+			issue.Message = "Frontmatter is not valid YAML"
+		case "unclosed":
+			issue.Message = "Close the frontmatter with ---"
+		case "too_large":
+			issue.Message = "Shorten the frontmatter"
+		case "type_mismatch":
+			if problem.Field == "frontmatter.rights" {
+				issue.Message = "Rights must be a set of key: value pairs"
+			} else {
+				issue.Message = "Use plain text for " + strings.TrimPrefix(problem.Field, "frontmatter.")
+			}
+		case "no_readable_content":
+			issue.Code = "invalid"
+			issue.Message = "Add readable story content"
+		case "unsupported":
+			issue.Message = "Raw HTML is not rendered and will be left out"
+		case "required":
+			issue.Message = "Enter a value"
+		case "invalid_encoding":
+			issue.Message = "Enter valid text"
+		case "invalid_media":
+			issue.Message = "Use media:<id> with the id of an uploaded image"
+		case "invalid_chapter_level":
+			issue.Message = "Use a chapter heading level from 1 to 6"
+		case "invalid_chapter_pattern":
+			issue.Message = "Use a valid regular expression for chapter headings"
+		case "boilerplate_removed":
+			issue.Message = "Project Gutenberg header or licence text was removed"
+		case "contents_removed":
+			issue.Message = "Table of contents was removed; the Reader builds its own"
+		case "unsupported_extension":
+			issue.Message = "Use supported Markdown extensions: " + strings.Join(storyingest.SupportedExtensions(), ", ")
+		default:
+			issue.Code = "invalid"
+			issue.Message = "Story content could not be processed"
+		}
+		issues = append(issues, issue)
+	}
+	return issues
+}
+
 func adminSegmentCounts(segments []storyingest.Segment) (int, int) {
 	wordCount := 0
 	chapterCount := 0
 	for _, segment := range segments {
 		wordCount += segment.WordCount
-		if segment.Kind == readercontract.SegmentKindHeading && segment.HeadingLevel != nil && *segment.HeadingLevel == 2 {
+		if segment.OpensChapter() {
 			chapterCount++
 		}
 	}
@@ -657,13 +805,13 @@ func stringValueFromMap(values map[string]any, key string) string {
 // AdminDraftUpsert is account-scoped. Body hashes retain their historical role
 // as idempotency candidate keys, but reuse succeeds only when the complete
 // locked immutable version still matches the canonical incoming story.
-func (s *Store) AdminDraftUpsert(accountID string, req model.AdminDraftUpsertRequest) (model.AdminDraftUpsertResponse, error) {
+func (s *Store) AdminDraftUpsert(ctx context.Context, accountID string, req model.AdminDraftUpsertRequest) (model.AdminDraftUpsertResponse, error) {
 	accountID = strings.TrimSpace(accountID)
 	if accountID == "" {
 		return model.AdminDraftUpsertResponse{}, fmt.Errorf("account required")
 	}
 
-	ing, err := canonicalAdminStoryInput(req)
+	ing, err := canonicalAdminStoryInput(ctx, req)
 	if err != nil {
 		return model.AdminDraftUpsertResponse{}, err
 	}
@@ -671,15 +819,31 @@ func (s *Store) AdminDraftUpsert(accountID string, req model.AdminDraftUpsertReq
 	if err != nil {
 		return model.AdminDraftUpsertResponse{}, err
 	}
+	readability, err := readabilityJSON(ing.Readability)
+	if err != nil {
+		return model.AdminDraftUpsertResponse{}, err
+	}
+	vocabulary, err := vocabularyJSON(ing.Vocabulary)
+	if err != nil {
+		return model.AdminDraftUpsertResponse{}, err
+	}
 
-	ctx, cancel := s.ctx()
+	ctx, cancel := s.ctx(ctx)
 	defer cancel()
 
-	tx, err := s.db.BeginTx(ctx, nil)
+	tx, err := s.writes(ctx).Begin(ctx)
+	if err != nil {
+		return model.AdminDraftUpsertResponse{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	mediaIssues, err := missingMediaIssues(ctx, tx, accountID, ing.MediaIDs)
 	if err != nil {
 		return model.AdminDraftUpsertResponse{}, err
 	}
-	defer func() { _ = tx.Rollback() }()
+	if len(mediaIssues) > 0 {
+		return model.AdminDraftUpsertResponse{}, &model.AdminValidationError{Issues: mediaIssues}
+	}
 
 	// story upsert (account-scoped)
 	sourceJSON, _ := json.Marshal(ing.Source)
@@ -689,7 +853,7 @@ func (s *Store) AdminDraftUpsert(accountID string, req model.AdminDraftUpsertReq
 		storyID      string
 		storyCreated bool
 	)
-	err = tx.QueryRowContext(ctx, `
+	err = tx.QueryRow(ctx, `
 		INSERT INTO stories (account_id, slug, title, author, language, source, rights, updated_at)
 		VALUES ($1,$2,$3,NULLIF(BTRIM($4),''),$5,$6::jsonb,$7::jsonb, now())
 		ON CONFLICT (account_id, slug) DO UPDATE SET
@@ -710,7 +874,7 @@ func (s *Store) AdminDraftUpsert(accountID string, req model.AdminDraftUpsertReq
 	// version to equal this request. Metadata-only changes therefore return the
 	// explicit repair-required conflict instead of silently reusing old metadata
 	// or changing the established body-hash identity policy.
-	candidateRows, err := tx.QueryContext(ctx, `
+	candidateRows, err := tx.Query(ctx, `
 		SELECT id
 		FROM story_versions
 		WHERE story_id = $1
@@ -725,20 +889,21 @@ func (s *Store) AdminDraftUpsert(accountID string, req model.AdminDraftUpsertReq
 	for candidateRows.Next() {
 		var candidateID string
 		if err := candidateRows.Scan(&candidateID); err != nil {
-			_ = candidateRows.Close()
+			candidateRows.Close()
 			return model.AdminDraftUpsertResponse{}, err
 		}
 		if strings.TrimSpace(candidateID) == "" {
-			_ = candidateRows.Close()
+			candidateRows.Close()
 			return model.AdminDraftUpsertResponse{}, fmt.Errorf("%w", model.ErrAdminVersionRepairRequired)
 		}
 		existingVersionIDs = append(existingVersionIDs, candidateID)
 	}
 	if err := candidateRows.Err(); err != nil {
-		_ = candidateRows.Close()
+		candidateRows.Close()
 		return model.AdminDraftUpsertResponse{}, err
 	}
-	if err := candidateRows.Close(); err != nil {
+	candidateRows.Close()
+	if err := candidateRows.Err(); err != nil {
 		return model.AdminDraftUpsertResponse{}, err
 	}
 	if len(existingVersionIDs) > 1 {
@@ -766,7 +931,7 @@ func (s *Store) AdminDraftUpsert(accountID string, req model.AdminDraftUpsertReq
 		}
 
 		// point draft at the existing version
-		_, err = tx.ExecContext(ctx, `
+		_, err = tx.Exec(ctx, `
 			UPDATE stories
 			SET draft_version_id=$2,
 			    updated_at=now()
@@ -776,27 +941,14 @@ func (s *Store) AdminDraftUpsert(accountID string, req model.AdminDraftUpsertReq
 			return model.AdminDraftUpsertResponse{}, err
 		}
 
-		// contributors link (still useful even if content existed)
-		if strings.TrimSpace(ing.Author) != "" {
-			var contribID string
-			// No-op update returns id reliably (requires UNIQUE(contributors.name))
-			_ = tx.QueryRowContext(ctx, `
-				INSERT INTO contributors (name)
-				VALUES ($1)
-				ON CONFLICT (name) DO UPDATE SET name = EXCLUDED.name
-				RETURNING id
-			`, ing.Author).Scan(&contribID)
-
-			if strings.TrimSpace(contribID) != "" {
-				_, _ = tx.ExecContext(ctx, `
-					INSERT INTO story_contributors (story_id, contributor_id, role)
-					VALUES ($1,$2,'author')
-					ON CONFLICT DO NOTHING
-				`, storyID, contribID)
-			}
+		if err := setVersionModeration(ctx, tx, existingVersionID, req.Moderation); err != nil {
+			return model.AdminDraftUpsertResponse{}, err
 		}
 
-		if err := tx.Commit(); err != nil {
+		// contributors link (still useful even if content existed)
+		linkStoryAuthor(ctx, tx, storyID, ing.Author)
+
+		if err := tx.Commit(ctx); err != nil {
 			return model.AdminDraftUpsertResponse{}, err
 		}
 
@@ -812,12 +964,13 @@ func (s *Store) AdminDraftUpsert(accountID string, req model.AdminDraftUpsertReq
 			ChapterCount:   storedVersion.ChapterCount,
 			RenderedHTML:   storedVersion.RenderedHTML,
 			Outcome:        model.AdminDraftOutcomeReused,
+			Warnings:       adminLintWarnings(ing),
 		}, nil
 	}
 
 	// next version number (only for new content)
 	var nextVersion int
-	if err := tx.QueryRowContext(ctx, `
+	if err := tx.QueryRow(ctx, `
 		SELECT COALESCE(MAX(version), 0) + 1
 		FROM story_versions
 		WHERE story_id = $1
@@ -825,127 +978,22 @@ func (s *Store) AdminDraftUpsert(accountID string, req model.AdminDraftUpsertReq
 		return model.AdminDraftUpsertResponse{}, err
 	}
 
-	var versionID string
-	err = tx.QueryRowContext(ctx, `
-		INSERT INTO story_versions (story_id, version, frontmatter, markdown, rendered_html, content_hash)
-		VALUES ($1,$2,$3::jsonb,$4,$5,$6)
-		RETURNING id
-	`, storyID, nextVersion, string(frontmatterJSON), ing.Markdown, ing.RenderedHTML, ing.ContentHash).Scan(&versionID)
+	// Segments the current draft already has are reported as unchanged.
+	previous, err := draftSegmentHashes(ctx, tx, storyID)
 	if err != nil {
 		return model.AdminDraftUpsertResponse{}, err
 	}
 
-	// --- Sections (chapters) + segment section assignment ---
-	headingText := func(md string) string {
-		s := strings.TrimSpace(md)
-		s = strings.TrimLeft(s, "#")
-		return strings.TrimSpace(s)
-	}
-
-	type chapter struct {
-		StartSegOrdinal int
-		Title           string
-		SectionOrdinal  int
-		ID              string
-	}
-	chapters := make([]chapter, 0, 16)
-
-	for _, seg := range ing.Segments {
-		if seg.Kind == "heading" && seg.HeadingLevel != nil && *seg.HeadingLevel == 2 {
-			t := headingText(seg.Markdown)
-			if strings.TrimSpace(t) == "" {
-				t = fmt.Sprintf("Chapter %d", len(chapters)+1)
-			}
-			chapters = append(chapters, chapter{
-				StartSegOrdinal: seg.Ordinal,
-				Title:           t,
-				SectionOrdinal:  len(chapters) + 1,
-			})
-		}
-	}
-
-	sectionIDByStart := map[int]string{}
-
-	if len(chapters) == 0 {
-		// No chapters -> one generic section for whole story
-		var sectionID string
-		err = tx.QueryRowContext(ctx, `
-			INSERT INTO story_sections (story_version_id, kind, title, ordinal)
-			VALUES ($1, 'section', NULL, 1)
-			RETURNING id
-		`, versionID).Scan(&sectionID)
-		if err != nil {
-			return model.AdminDraftUpsertResponse{}, err
-		}
-		sectionIDByStart[1] = sectionID
-	} else {
-		for i := range chapters {
-			var secID string
-			err = tx.QueryRowContext(ctx, `
-				INSERT INTO story_sections (story_version_id, kind, title, ordinal)
-				VALUES ($1, 'chapter', $2, $3)
-				RETURNING id
-			`, versionID, chapters[i].Title, chapters[i].SectionOrdinal).Scan(&secID)
-			if err != nil {
-				return model.AdminDraftUpsertResponse{}, err
-			}
-			chapters[i].ID = secID
-			sectionIDByStart[chapters[i].StartSegOrdinal] = secID
-		}
+	versionID, unchanged, err := insertStoryVersion(ctx, tx, storyID, nextVersion, ing, frontmatterJSON, readability, vocabulary, previous)
+	if err != nil {
+		return model.AdminDraftUpsertResponse{}, err
 	}
-
-	var currentChapterID string
-
-	for _, seg := range ing.Segments {
-		var sectionArg any = nil
-
-		if len(chapters) == 0 {
-			sectionArg = sectionIDByStart[1]
-		} else {
-			// H1 title stays unsectioned; H2 starts a chapter; everything after belongs to current chapter
-			if seg.Kind == "heading" && seg.HeadingLevel != nil && *seg.HeadingLevel == 1 {
-				sectionArg = nil
-			} else if seg.Kind == "heading" && seg.HeadingLevel != nil && *seg.HeadingLevel == 2 {
-				if id, ok := sectionIDByStart[seg.Ordinal]; ok {
-					currentChapterID = id
-					sectionArg = currentChapterID
-				}
-			} else if currentChapterID != "" {
-				sectionArg = currentChapterID
-			} else {
-				sectionArg = nil
-			}
-		}
-
-		_, err := tx.ExecContext(ctx, `
-			INSERT INTO story_segments (
-				story_version_id, section_id, ordinal,
-				segment_kind, heading_level, content_key, content_occurrence,
-				chapter_key, chapter_occurrence,
-				markdown, rendered_html, word_count
-			)
-			VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12)
-		`,
-			versionID,
-			sectionArg,
-			seg.Ordinal,
-			string(seg.Kind),
-			seg.HeadingLevel,
-			seg.ContentKey,
-			seg.ContentOccurrence,
-			seg.ChapterKey,
-			seg.ChapterOccurrence,
-			seg.Markdown,
-			seg.RenderedHTML,
-			seg.WordCount,
-		)
-		if err != nil {
-			return model.AdminDraftUpsertResponse{}, err
-		}
+	if err := setVersionModeration(ctx, tx, versionID, req.Moderation); err != nil {
+		return model.AdminDraftUpsertResponse{}, err
 	}
 
 	// update draft pointer ONLY (publish is separate endpoint)
-	_, err = tx.ExecContext(ctx, `
+	_, err = tx.Exec(ctx, `
 		UPDATE stories
 		SET draft_version_id=$2,
 		    updated_at=now()
@@ -955,27 +1003,22 @@ func (s *Store) AdminDraftUpsert(accountID string, req model.AdminDraftUpsertReq
 		return model.AdminDraftUpsertResponse{}, err
 	}
 
-	// contributors: ensure author exists & link if provided
-	if strings.TrimSpace(ing.Author) != "" {
-		var contribID string
-		// No-op update returns id reliably (requires UNIQUE(contributors.name))
-		_ = tx.QueryRowContext(ctx, `
-			INSERT INTO contributors (name)
-			VALUES ($1)
-			ON CONFLICT (name) DO UPDATE SET name = EXCLUDED.name
-			RETURNING id
-		`, ing.Author).Scan(&contribID)
-
-		if strings.TrimSpace(contribID) != "" {
-			_, _ = tx.ExecContext(ctx, `
-				INSERT INTO story_contributors (story_id, contributor_id, role)
-				VALUES ($1,$2,'author')
-				ON CONFLICT DO NOTHING
-			`, storyID, contribID)
+	// Apply the account retention policy now that the new version is the
+	// draft and therefore protected.
+	retention, err := accountVersionRetention(ctx, tx, accountID)
+	if err != nil {
+		return model.AdminDraftUpsertResponse{}, err
+	}
+	if retention != nil {
+		if _, _, err := pruneStoryVersions(ctx, tx, storyID, *retention, false); err != nil {
+			return model.AdminDraftUpsertResponse{}, err
 		}
 	}
 
-	if err := tx.Commit(); err != nil {
+	// contributors: ensure author exists & link if provided
+	linkStoryAuthor(ctx, tx, storyID, ing.Author)
+
+	if err := tx.Commit(ctx); err != nil {
 		return model.AdminDraftUpsertResponse{}, err
 	}
 
@@ -985,27 +1028,32 @@ func (s *Store) AdminDraftUpsert(accountID string, req model.AdminDraftUpsertReq
 		outcome = model.AdminDraftOutcomeCreatedStory
 	}
 	return model.AdminDraftUpsertResponse{
-		StoryID:        storyID,
-		StoryVersionID: versionID,
-		Slug:           ing.Slug,
-		VersionID:      versionID,
-		Version:        nextVersion,
-		SegmentsCount:  len(ing.Segments),
-		SegmentCount:   len(ing.Segments),
-		WordCount:      wordCount,
-		ChapterCount:   chapterCount,
-		RenderedHTML:   ing.RenderedHTML,
-		Outcome:        outcome,
+		StoryID:           storyID,
+		StoryVersionID:    versionID,
+		Slug:              ing.Slug,
+		VersionID:         versionID,
+		Version:           nextVersion,
+		SegmentsCount:     len(ing.Segments),
+		SegmentCount:      len(ing.Segments),
+		WordCount:         wordCount,
+		ChapterCount:      chapterCount,
+		UnchangedSegments: unchanged,
+		RenderedHTML:      ing.RenderedHTML,
+		Outcome:           outcome,
+		Warnings:          adminLintWarnings(ing),
 	}, nil
 }
 
-func (s *Store) AdminPublish(accountID string, slug string, versionID string) error {
-	_, err := s.AdminPublishStory(accountID, slug, versionID)
+func (s *Store) AdminPublish(ctx context.Context, accountID string, slug string, versionID string) error {
+	_, err := s.AdminPublishStory(ctx, accountID, slug, versionID, false)
 	return err
 }
 
-func (s *Store) AdminPublishStory(accountID string, slug string, versionID string) (model.AdminStoryStatusResponse, error) {
-	ctx, cancel := s.ctx()
+// AdminPublishStory points the story's published version at versionID. A
+// version moderation blocked is refused, as is a flagged one unless
+// acknowledgeModeration says a publisher reviewed its findings.
+func (s *Store) AdminPublishStory(ctx context.Context, accountID string, slug string, versionID string, acknowledgeModeration bool) (model.AdminStoryStatusResponse, error) {
+	ctx, cancel := s.ctx(ctx)
 	defer cancel()
 
 	accountID = strings.TrimSpace(accountID)
@@ -1019,11 +1067,11 @@ func (s *Store) AdminPublishStory(accountID string, slug string, versionID strin
 	// READ COMMITTED lets the segment-locking query observe a mutation that
 	// completed while it waited for the version lock. The locks then keep the
 	// validated version stable until the pointer update commits.
-	tx, err := s.db.BeginTx(ctx, nil)
+	tx, err := s.writes(ctx).Begin(ctx)
 	if err != nil {
 		return model.AdminStoryStatusResponse{}, err
 	}
-	defer func() { _ = tx.Rollback() }()
+	defer func() { _ = tx.Rollback(ctx) }()
 
 	// Lock the account-owned story first. The old pointer remains unchanged
 	// unless every immutable version invariant validates and the transaction
@@ -1045,11 +1093,18 @@ func (s *Store) AdminPublishStory(accountID string, slug string, versionID strin
 		}
 		return model.AdminStoryStatusResponse{}, err
 	}
+	if err := checkPublishModeration(ctx, tx, versionID, acknowledgeModeration); err != nil {
+		return model.AdminStoryStatusResponse{}, err
+	}
 
-	if err := tx.QueryRowContext(ctx, `
+	// A story published for the first time, or again after being taken
+	// down, is announced to readers; a new version replacing one is not.
+	announce := !story.IsPublished
+	if err := tx.QueryRow(ctx, `
 		UPDATE stories
 		SET published_version_id = $2,
 		    is_published = true,
+		    unpublished_at = NULL,
 		    updated_at = now()
 		WHERE id = $1
 		RETURNING updated_at
@@ -1063,8 +1118,196 @@ func (s *Store) AdminPublishStory(accountID string, slug string, versionID strin
 	if err != nil {
 		return model.AdminStoryStatusResponse{}, err
 	}
-	if err := tx.Commit(); err != nil {
+	status := adminStoryStatusResponse(inspected)
+	if err := enqueueWebhookEvent(ctx, tx, accountID, webhooks.StoryPayload(model.WebhookEventStoryPublished, time.Now(), status)); err != nil {
 		return model.AdminStoryStatusResponse{}, err
 	}
-	return adminStoryStatusResponse(inspected), nil
+	if announce {
+		if err := enqueuePushStory(ctx, tx, accountID, slug); err != nil {
+			return model.AdminStoryStatusResponse{}, err
+		}
+	}
+	if err := notifyStoryChanged(ctx, tx, accountID, slug); err != nil {
+		return model.AdminStoryStatusResponse{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return model.AdminStoryStatusResponse{}, err
+	}
+	s.readerCache.forget(accountID, slug)
+	return status, nil
+}
+
+// insertStoryVersion writes one immutable version with its sections and
+// segments. Callers own version numbering and story pointers.
+// linkStoryAuthor ensures a named author exists as a contributor and is linked
+// to the story. A failure aborts the transaction, so commit reports it.
+func linkStoryAuthor(ctx context.Context, tx pgx.Tx, storyID, author string) {
+	if strings.TrimSpace(author) == "" {
+		return
+	}
+	var contribID string
+	// No-op update returns id reliably (requires UNIQUE(contributors.name))
+	_ = tx.QueryRow(ctx, `
+		INSERT INTO contributors (name)
+		VALUES ($1)
+		ON CONFLICT (name) DO UPDATE SET name = EXCLUDED.name
+		RETURNING id
+	`, author).Scan(&contribID)
+
+	if strings.TrimSpace(contribID) != "" {
+		_, _ = tx.Exec(ctx, `
+			INSERT INTO story_contributors (story_id, contributor_id, role)
+			VALUES ($1,$2,'author')
+			ON CONFLICT DO NOTHING
+		`, storyID, contribID)
+	}
+}
+
+func insertStoryVersion(
+	ctx context.Context,
+	tx pgx.Tx,
+	storyID string,
+	nextVersion int,
+	ing storyingest.Output,
+	frontmatterJSON []byte,
+	readability []byte,
+	vocabulary []byte,
+	previous map[string]bool,
+) (string, int, error) {
+	var versionID string
+	err := tx.QueryRow(ctx, `
+		INSERT INTO story_versions (story_id, version, frontmatter, markdown, rendered_html, content_hash, readability, vocabulary)
+		VALUES ($1,$2,$3::jsonb,$4,$5,$6,$7::jsonb,$8::jsonb)
+		RETURNING id
+	`, storyID, nextVersion, string(frontmatterJSON), ing.Markdown, ing.RenderedHTML, ing.ContentHash, string(readability), string(vocabulary)).Scan(&versionID)
+	if err != nil {
+		return "", 0, err
+	}
+
+	// --- Sections (chapters) + segment section assignment ---
+	headingText := func(md string) string {
+		s := strings.TrimSpace(md)
+		s = strings.TrimLeft(s, "#")
+		return strings.TrimSpace(s)
+	}
+
+	type chapter struct {
+		StartSegOrdinal int
+		Title           string
+		SectionOrdinal  int
+		ID              string
+	}
+	chapters := make([]chapter, 0, 16)
+
+	for _, seg := range ing.Segments {
+		if seg.OpensChapter() {
+			t := headingText(seg.Markdown)
+			if strings.TrimSpace(t) == "" {
+				t = fmt.Sprintf("Chapter %d", len(chapters)+1)
+			}
+			chapters = append(chapters, chapter{
+				StartSegOrdinal: seg.Ordinal,
+				Title:           t,
+				SectionOrdinal:  len(chapters) + 1,
+			})
+		}
+	}
+
+	sectionIDByStart := map[int]string{}
+
+	if len(chapters) == 0 {
+		// No chapters -> one generic section for whole story
+		var sectionID string
+		err = tx.QueryRow(ctx, `
+			INSERT INTO story_sections (story_version_id, kind, title, ordinal)
+			VALUES ($1, 'section', NULL, 1)
+			RETURNING id
+		`, versionID).Scan(&sectionID)
+		if err != nil {
+			return "", 0, err
+		}
+		sectionIDByStart[1] = sectionID
+	} else {
+		for i := range chapters {
+			var secID string
+			err = tx.QueryRow(ctx, `
+				INSERT INTO story_sections (story_version_id, kind, title, ordinal)
+				VALUES ($1, 'chapter', $2, $3)
+				RETURNING id
+			`, versionID, chapters[i].Title, chapters[i].SectionOrdinal).Scan(&secID)
+			if err != nil {
+				return "", 0, err
+			}
+			chapters[i].ID = secID
+			sectionIDByStart[chapters[i].StartSegOrdinal] = secID
+		}
+	}
+
+	var currentChapterID string
+
+	// Segments are written with one COPY, so a long book costs one statement
+	// rather than one per segment.
+	fresh := freshSegments{versionID: versionID}
+	unchanged := 0
+
+	for _, seg := range ing.Segments {
+		var sectionArg any = nil
+
+		if len(chapters) == 0 {
+			sectionArg = sectionIDByStart[1]
+		} else {
+			// A chapter heading starts a chapter; an H1 title outside one stays
+			// unsectioned; everything after belongs to current chapter
+			if seg.OpensChapter() {
+				if id, ok := sectionIDByStart[seg.Ordinal]; ok {
+					currentChapterID = id
+					sectionArg = currentChapterID
+				}
+			} else if seg.Kind == "heading" && seg.HeadingLevel != nil && *seg.HeadingLevel == 1 {
+				sectionArg = nil
+			} else if currentChapterID != "" {
+				sectionArg = currentChapterID
+			} else {
+				sectionArg = nil
+			}
+		}
+
+		if previous[seg.Hash] {
+			unchanged++
+		}
+		if err := fresh.add(sectionArg, seg); err != nil {
+			return "", 0, err
+		}
+	}
+	if err := fresh.copyTo(ctx, tx); err != nil {
+		return "", 0, err
+	}
+	return versionID, unchanged, nil
+}
+
+// draftSegmentHashes returns the content hashes of the story's current draft
+// segments.
+func draftSegmentHashes(ctx context.Context, tx pgx.Tx, storyID string) (map[string]bool, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT segment.content_hash
+		FROM stories st
+		JOIN story_segments AS segment
+		  ON segment.story_version_id = st.draft_version_id
+		WHERE st.id = $1
+		  AND segment.content_hash IS NOT NULL
+	`, storyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	hashes := map[string]bool{}
+	for rows.Next() {
+		var hash string
+		if err := rows.Scan(&hash); err != nil {
+			return nil, err
+		}
+		hashes[hash] = true
+	}
+	return hashes, rows.Err()
 }
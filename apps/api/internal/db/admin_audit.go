@@ -0,0 +1,205 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"pandapages/api/internal/model"
+)
+
+const (
+	defaultAdminAuditLimit = 50
+	maxAdminAuditLimit     = 200
+)
+
+// auditedTx is the transaction AdminAudited runs a mutation in, with the
+// blob store objects the mutation wrote, which are deleted again if it does
+// not commit.
+type auditedTx struct {
+	tx    pgx.Tx
+	blobs []string
+}
+
+type auditedTxKey struct{}
+
+// dbtx is what the pool and a transaction both offer. Begin on a transaction
+// opens a savepoint.
+type dbtx interface {
+	Begin(context.Context) (pgx.Tx, error)
+	Exec(context.Context, string, ...any) (pgconn.CommandTag, error)
+	Query(context.Context, string, ...any) (pgx.Rows, error)
+	QueryRow(context.Context, string, ...any) pgx.Row
+}
+
+// writes is where an admin mutation runs: the transaction AdminAudited
+// opened, when ctx carries one, and the pool otherwise. A mutation that
+// begins its own transaction there gets a savepoint of the audited one.
+func (s *Store) writes(ctx context.Context) dbtx {
+	if audited, ok := ctx.Value(auditedTxKey{}).(*auditedTx); ok {
+		return audited.tx
+	}
+	return s.db
+}
+
+// AdminAudited runs mutate and appends the audit entry it returns to the
+// account's audit trail in one transaction, so a mutation commits only with
+// its audit row. Store methods called with the context mutate is given join
+// that transaction. The log is append-only: no Store method updates or
+// deletes audit rows.
+func (s *Store) AdminAudited(ctx context.Context, accountID, actor string, mutate func(ctx context.Context) (model.AdminAuditEntry, error)) (err error) {
+	accountID = strings.TrimSpace(accountID)
+	if !accountIDRe.MatchString(accountID) {
+		return fmt.Errorf("account required")
+	}
+	actor = strings.TrimSpace(actor)
+	if actor == "" {
+		return fmt.Errorf("audit actor required")
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	audited := &auditedTx{tx: tx}
+	defer func() {
+		_ = tx.Rollback(context.WithoutCancel(ctx))
+		if err != nil {
+			for _, key := range audited.blobs {
+				s.dropBlob(ctx, key)
+			}
+		}
+	}()
+
+	entry, err := mutate(context.WithValue(ctx, auditedTxKey{}, audited))
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(string(entry.Action)) == "" {
+		return fmt.Errorf("audit action required")
+	}
+	summary := entry.Summary
+	if summary == nil {
+		summary = map[string]any{}
+	}
+	summaryJSON, err := json.Marshal(summary)
+	if err != nil {
+		return fmt.Errorf("encode audit summary: %w", err)
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO admin_audit_log (account_id, actor, action, story_slug, summary)
+		VALUES ($1, $2, $3, NULLIF(BTRIM($4), ''), $5::jsonb)
+	`, accountID, actor, string(entry.Action), entry.Slug, string(summaryJSON)); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// adminAuditFilterWhere selects an account's audit records matching a filter,
+// bound as $1 to $6.
+const adminAuditFilterWhere = `account_id = $1
+		  AND ($2 = '' OR action = $2)
+		  AND ($3 = '' OR story_slug = $3)
+		  AND ($4 = '' OR actor = $4)
+		  AND ($5::timestamptz IS NULL OR created_at >= $5::timestamptz)
+		  AND ($6::timestamptz IS NULL OR created_at < $6::timestamptz)`
+
+// AdminListAudit returns the newest audit records first. Every filter is
+// optional; an empty filter returns the most recent page for the account.
+func (s *Store) AdminListAudit(ctx context.Context, accountID string, filter model.AdminAuditFilter) (model.AdminAuditListResponse, error) {
+	accountID = strings.TrimSpace(accountID)
+	if !accountIDRe.MatchString(accountID) {
+		return model.AdminAuditListResponse{}, fmt.Errorf("account required")
+	}
+	limit := pageSize(filter.Limit, defaultAdminAuditLimit, maxAdminAuditLimit)
+	var (
+		afterCreated time.Time
+		afterID      string
+	)
+	after, err := decodeCursor(filter.Cursor, &afterCreated, &afterID)
+	if err != nil {
+		return model.AdminAuditListResponse{}, err
+	}
+	cursorArgs := []any{nil, nil}
+	if after {
+		if !accountIDRe.MatchString(afterID) {
+			return model.AdminAuditListResponse{}, model.ErrInvalidCursor
+		}
+		cursorArgs = []any{afterCreated, afterID}
+	}
+
+	var since, until any
+	if filter.Since != nil {
+		since = filter.Since.UTC()
+	}
+	if filter.Until != nil {
+		until = filter.Until.UTC()
+	}
+
+	filterArgs := []any{accountID, string(filter.Action), strings.TrimSpace(filter.Slug), strings.TrimSpace(filter.Actor), since, until}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	rows, err := s.db.Query(ctx, `
+		SELECT id, actor, action, story_slug, summary::text, created_at
+		FROM admin_audit_log
+		WHERE `+adminAuditFilterWhere+`
+		  AND ($7::timestamptz IS NULL OR (created_at, id) < ($7::timestamptz, $8::uuid))
+		ORDER BY created_at DESC, id DESC
+		LIMIT $9
+	`, append(filterArgs, cursorArgs[0], cursorArgs[1], limit+1)...)
+	if err != nil {
+		return model.AdminAuditListResponse{}, err
+	}
+	defer rows.Close()
+
+	items := make([]model.AdminAuditRecord, 0, limit+1)
+	var lastCreated time.Time
+	for rows.Next() {
+		var (
+			record      model.AdminAuditRecord
+			action      string
+			slug        *string
+			summaryJSON string
+			createdAt   time.Time
+		)
+		if err := rows.Scan(&record.ID, &record.Actor, &action, &slug, &summaryJSON, &createdAt); err != nil {
+			return model.AdminAuditListResponse{}, err
+		}
+		summary := map[string]any{}
+		if err := json.Unmarshal([]byte(summaryJSON), &summary); err != nil {
+			return model.AdminAuditListResponse{}, fmt.Errorf("decode audit summary: %w", err)
+		}
+		record.Action = model.AdminAuditAction(action)
+		record.Slug = slug
+		record.Summary = summary
+		record.CreatedAt = createdAt.UTC().Format(time.RFC3339Nano)
+		if len(items) < limit {
+			lastCreated = createdAt
+		}
+		items = append(items, record)
+	}
+	if err := rows.Err(); err != nil {
+		return model.AdminAuditListResponse{}, err
+	}
+	items, more := trimPage(items, limit)
+	total, err := pageTotal(ctx, s.db, filter.PageRequest, `SELECT count(*) FROM admin_audit_log WHERE `+adminAuditFilterWhere, filterArgs...)
+	if err != nil {
+		return model.AdminAuditListResponse{}, err
+	}
+	out := model.AdminAuditListResponse{Items: items, Total: total}
+	if more {
+		out.NextCursor = encodeCursor(lastCreated, items[len(items)-1].ID)
+	}
+	return out, nil
+}
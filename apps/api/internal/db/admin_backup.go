@@ -0,0 +1,129 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"pandapages/api/internal/model"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// AdminBackupIndex lists what a backup of the account holds: its settings,
+// reading progress, story slugs and media. Stories, progress and media come
+// from one snapshot; story bundles and media bytes are read afterwards, one
+// at a time, so a backup never holds the whole library in memory.
+func (s *Store) AdminBackupIndex(ctx context.Context, accountID string) (model.BackupIndex, error) {
+	accountID = strings.TrimSpace(accountID)
+	if !accountIDRe.MatchString(accountID) {
+		return model.BackupIndex{}, fmt.Errorf("account required")
+	}
+
+	reader, err := s.SettingsGet(ctx, accountID)
+	if err != nil {
+		return model.BackupIndex{}, err
+	}
+	hyphenation, err := s.AdminGetHyphenation(ctx, accountID)
+	if err != nil {
+		return model.BackupIndex{}, err
+	}
+	retention, err := s.AdminGetVersionRetention(ctx, accountID)
+	if err != nil {
+		return model.BackupIndex{}, err
+	}
+	out := model.BackupIndex{
+		Settings: model.BackupSettings{Reader: reader, Hyphenation: hyphenation.Enabled, KeepVersions: retention.Keep},
+		Stories:  []string{},
+		Media:    []model.Media{},
+		Progress: []model.BackupProgress{},
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return model.BackupIndex{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	rows, err := tx.Query(ctx, `
+		SELECT slug FROM stories WHERE account_id = $1 ORDER BY slug ASC
+	`, accountID)
+	if err != nil {
+		return model.BackupIndex{}, err
+	}
+	out.Stories, err = pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return model.BackupIndex{}, err
+	}
+
+	rows, err = tx.Query(ctx, `
+		SELECT `+mediaColumns+`
+		FROM media
+		WHERE account_id = $1
+		ORDER BY created_at ASC, id ASC
+	`, accountID)
+	if err != nil {
+		return model.BackupIndex{}, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		media, err := scanMedia(rows)
+		if err != nil {
+			return model.BackupIndex{}, err
+		}
+		out.Media = append(out.Media, media)
+	}
+	if err := rows.Err(); err != nil {
+		return model.BackupIndex{}, err
+	}
+
+	// Readers only ever use the account's oldest Default profile.
+	rows, err = tx.Query(ctx, `
+		SELECT story.slug, version.version, progress.locator, progress.percent, progress.updated_at
+		FROM reading_progress AS progress
+		JOIN stories AS story ON story.id = progress.story_id
+		JOIN story_versions AS version
+		  ON version.id = progress.story_version_id
+		 AND version.story_id = story.id
+		WHERE story.account_id = $1
+		  AND progress.profile_id = (
+			SELECT id FROM profiles
+			WHERE account_id = $1 AND name = 'Default'
+			ORDER BY created_at ASC
+			LIMIT 1
+		  )
+		ORDER BY story.slug ASC
+	`, accountID)
+	if err != nil {
+		return model.BackupIndex{}, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			item      model.BackupProgress
+			locator   []byte
+			updatedAt time.Time
+		)
+		if err := rows.Scan(&item.Slug, &item.Version, &locator, &item.Percent, &updatedAt); err != nil {
+			return model.BackupIndex{}, err
+		}
+		if err := json.Unmarshal(locator, &item.Locator); err != nil {
+			return model.BackupIndex{}, fmt.Errorf("decode stored Reader locator: %w", err)
+		}
+		item.Percent = clamp01(item.Percent)
+		item.UpdatedAt = updatedAt.UTC().Format(time.RFC3339Nano)
+		out.Progress = append(out.Progress, item)
+	}
+	if err := rows.Err(); err != nil {
+		return model.BackupIndex{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return model.BackupIndex{}, err
+	}
+	return out, nil
+}
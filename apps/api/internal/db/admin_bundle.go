@@ -0,0 +1,604 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"pandapages/api/internal/model"
+	"pandapages/api/internal/storyingest"
+	"pandapages/api/internal/webhooks"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// importedBundleVersion is one bundle version after it has been re-derived
+// from its Markdown and checked against the bundle's own copy.
+type importedBundleVersion struct {
+	Bundle          model.StoryBundleVersion
+	Output          storyingest.Output
+	FrontmatterJSON []byte
+	CreatedAt       *time.Time
+}
+
+// AdminExportStory builds a portable bundle of one story and every version it
+// holds. Export refuses stories that need repair rather than copying corrupt
+// content to another instance.
+func (s *Store) AdminExportStory(ctx context.Context, accountID, slug string) (model.StoryBundle, error) {
+	accountID = strings.TrimSpace(accountID)
+	slug = strings.TrimSpace(slug)
+	if !accountIDRe.MatchString(accountID) || storyingest.ValidateSlug(slug) != nil {
+		return model.StoryBundle{}, fmt.Errorf("%w", model.ErrAdminStoryNotFound)
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return model.StoryBundle{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	story, err := loadAdminStory(ctx, tx, accountID, slug, false)
+	if err != nil {
+		return model.StoryBundle{}, err
+	}
+	inspected, err := inspectAdminStory(ctx, tx, story)
+	if err != nil {
+		return model.StoryBundle{}, err
+	}
+	if inspected.Summary.Status == model.AdminStoryStatusRepairRequired {
+		return model.StoryBundle{}, fmt.Errorf("%w", model.ErrAdminVersionRepairRequired)
+	}
+
+	bundle := model.StoryBundle{
+		Format:        model.StoryBundleFormat,
+		FormatVersion: model.StoryBundleFormatVersion,
+		ExportedAt:    time.Now().UTC().Format(time.RFC3339Nano),
+		Story: model.StoryBundleStory{
+			Slug:      story.Slug,
+			Title:     inspected.Summary.Title,
+			Author:    cloneString(inspected.Summary.Author),
+			Language:  inspected.Summary.Language,
+			Rights:    cloneJSONMap(inspected.Summary.Rights),
+			SourceURL: cloneString(inspected.Summary.SourceURL),
+		},
+		Versions: make([]model.StoryBundleVersion, 0, len(inspected.Versions)),
+		Media:    []model.StoryBundleMedia{},
+	}
+
+	// inspectAdminStory lists newest first; bundles read oldest first.
+	for i := len(inspected.Versions) - 1; i >= 0; i-- {
+		summary := inspected.Versions[i]
+		version, err := exportBundleVersion(ctx, tx, story, summary)
+		if err != nil {
+			return model.StoryBundle{}, err
+		}
+		bundle.Versions = append(bundle.Versions, version)
+	}
+	bundle.Media = bundleMedia(bundle.Versions)
+
+	if bundle.Story.Contributors, err = loadBundleContributors(ctx, tx, story.ID); err != nil {
+		return model.StoryBundle{}, err
+	}
+	tags, err := loadStoryTags(ctx, tx, story.ID)
+	if err != nil {
+		return model.StoryBundle{}, err
+	}
+	bundle.Story.Tags = make([]string, 0, len(tags))
+	for _, tag := range tags {
+		bundle.Story.Tags = append(bundle.Story.Tags, tag.Name)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return model.StoryBundle{}, err
+	}
+	return bundle, nil
+}
+
+func exportBundleVersion(ctx context.Context, tx pgx.Tx, story adminStoryRow, summary model.AdminVersionSummary) (model.StoryBundleVersion, error) {
+	snapshot, err := inspectStoredReaderVersion(ctx, tx, story.ID, summary.VersionID, story.Slug)
+	if errors.Is(err, errStoredVersionInvalid) || errors.Is(err, sql.ErrNoRows) {
+		return model.StoryBundleVersion{}, fmt.Errorf("%w", model.ErrAdminVersionRepairRequired)
+	}
+	if err != nil {
+		return model.StoryBundleVersion{}, err
+	}
+
+	version := model.StoryBundleVersion{
+		Version:      snapshot.Version,
+		CreatedAt:    snapshot.CreatedAt.UTC().Format(time.RFC3339Nano),
+		IsDraft:      summary.IsDraft,
+		IsPublished:  summary.IsPublished,
+		Frontmatter:  snapshot.Frontmatter.Values,
+		Markdown:     snapshot.Markdown,
+		RenderedHTML: snapshot.RenderedHTML,
+		ContentHash:  snapshot.ContentHash,
+		Readability:  summary.Readability,
+		Sections:     []model.StoryBundleSection{},
+		Segments:     make([]model.StoryBundleSegment, 0, snapshot.SegmentCount),
+	}
+
+	sectionRows, err := tx.Query(ctx, `
+		SELECT ordinal, kind, title
+		FROM story_sections
+		WHERE story_version_id = $1
+		ORDER BY ordinal ASC
+	`, summary.VersionID)
+	if err != nil {
+		return model.StoryBundleVersion{}, err
+	}
+	defer sectionRows.Close()
+	for sectionRows.Next() {
+		var (
+			section model.StoryBundleSection
+			title   sql.NullString
+		)
+		if err := sectionRows.Scan(&section.Ordinal, &section.Kind, &title); err != nil {
+			return model.StoryBundleVersion{}, err
+		}
+		section.Title = nullStringValue(title)
+		version.Sections = append(version.Sections, section)
+	}
+	if err := sectionRows.Err(); err != nil {
+		return model.StoryBundleVersion{}, err
+	}
+	sectionRows.Close()
+	if err := sectionRows.Err(); err != nil {
+		return model.StoryBundleVersion{}, err
+	}
+
+	segmentRows, err := tx.Query(ctx, `
+		SELECT
+			segment.ordinal,
+			section.ordinal,
+			segment.segment_kind,
+			segment.heading_level,
+			segment.content_key,
+			segment.content_occurrence,
+			segment.chapter_key,
+			segment.chapter_occurrence,
+			segment.markdown,
+			segment.rendered_html,
+			segment.word_count
+		FROM story_segments AS segment
+		LEFT JOIN story_sections AS section ON section.id = segment.section_id
+		WHERE segment.story_version_id = $1
+		ORDER BY segment.ordinal ASC
+	`, summary.VersionID)
+	if err != nil {
+		return model.StoryBundleVersion{}, err
+	}
+	defer segmentRows.Close()
+	for segmentRows.Next() {
+		var (
+			segment           model.StoryBundleSegment
+			sectionOrdinal    sql.NullInt64
+			headingLevel      sql.NullInt64
+			chapterKey        sql.NullString
+			chapterOccurrence sql.NullInt64
+		)
+		if err := segmentRows.Scan(
+			&segment.Ordinal,
+			&sectionOrdinal,
+			&segment.Kind,
+			&headingLevel,
+			&segment.ContentKey,
+			&segment.ContentOccurrence,
+			&chapterKey,
+			&chapterOccurrence,
+			&segment.Markdown,
+			&segment.RenderedHTML,
+			&segment.WordCount,
+		); err != nil {
+			return model.StoryBundleVersion{}, err
+		}
+		segment.SectionOrdinal = nullIntValue(sectionOrdinal)
+		segment.HeadingLevel = nullIntValue(headingLevel)
+		segment.ChapterKey = nullStringValue(chapterKey)
+		segment.ChapterOccurrence = nullIntValue(chapterOccurrence)
+		version.Segments = append(version.Segments, segment)
+	}
+	if err := segmentRows.Err(); err != nil {
+		return model.StoryBundleVersion{}, err
+	}
+	return version, nil
+}
+
+func loadBundleContributors(ctx context.Context, tx pgx.Tx, storyID string) ([]model.StoryContributor, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT c.name, sc.role
+		FROM story_contributors sc
+		JOIN contributors c ON c.id = sc.contributor_id
+		WHERE sc.story_id = $1
+		ORDER BY `+contributorRoleRank+`, lower(c.name), c.id
+	`, storyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []model.StoryContributor{}
+	for rows.Next() {
+		var (
+			item model.StoryContributor
+			role string
+		)
+		if err := rows.Scan(&item.Name, &role); err != nil {
+			return nil, err
+		}
+		item.Role = model.ContributorRole(role)
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// bundleMedia collects image references across versions, in first-seen order.
+func bundleMedia(versions []model.StoryBundleVersion) []model.StoryBundleMedia {
+	media := []model.StoryBundleMedia{}
+	index := map[string]int{}
+	for _, version := range versions {
+		for _, ref := range storyingest.MediaRefs(version.Markdown) {
+			i, ok := index[ref]
+			if !ok {
+				i = len(media)
+				index[ref] = i
+				media = append(media, model.StoryBundleMedia{URL: ref, Versions: []int{}})
+			}
+			media[i].Versions = append(media[i].Versions, version.Version)
+		}
+	}
+	return media
+}
+
+// AdminImportStory creates a new story from a bundle, keeping its version
+// numbers and draft/published pointers. Every version is re-derived from its
+// Markdown before anything is written; an existing slug is never overwritten.
+func (s *Store) AdminImportStory(ctx context.Context, accountID string, bundle model.StoryBundle) (model.AdminStoryStatusResponse, error) {
+	accountID = strings.TrimSpace(accountID)
+	if !accountIDRe.MatchString(accountID) {
+		return model.AdminStoryStatusResponse{}, fmt.Errorf("account required")
+	}
+	versions, err := prepareStoryBundle(bundle)
+	if err != nil {
+		return model.AdminStoryStatusResponse{}, err
+	}
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+	tx, err := s.writes(ctx).Begin(ctx)
+	if err != nil {
+		return model.AdminStoryStatusResponse{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	status, err := importStoryBundle(ctx, tx, accountID, bundle, versions)
+	if err != nil {
+		return model.AdminStoryStatusResponse{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return model.AdminStoryStatusResponse{}, err
+	}
+	return status, nil
+}
+
+// importStoryBundle writes a prepared bundle as a new story of the account.
+func importStoryBundle(ctx context.Context, tx pgx.Tx, accountID string, bundle model.StoryBundle, versions []importedBundleVersion) (model.AdminStoryStatusResponse, error) {
+	slug := strings.TrimSpace(bundle.Story.Slug)
+	current := currentBundleVersion(versions)
+
+	// Bundles carry media references only, so the target account must
+	// already hold every image any version uses.
+	mediaIDs := []string{}
+	seenMedia := map[string]bool{}
+	for _, version := range versions {
+		for _, id := range version.Output.MediaIDs {
+			if !seenMedia[id] {
+				seenMedia[id] = true
+				mediaIDs = append(mediaIDs, id)
+			}
+		}
+	}
+	mediaIssues, err := missingMediaIssues(ctx, tx, accountID, mediaIDs)
+	if err != nil {
+		return model.AdminStoryStatusResponse{}, err
+	}
+	if len(mediaIssues) > 0 {
+		return model.AdminStoryStatusResponse{}, &model.AdminValidationError{Issues: mediaIssues}
+	}
+
+	sourceJSON, _ := json.Marshal(current.Output.Source)
+	rightsJSON, _ := json.Marshal(current.Output.Rights)
+	var storyID string
+	err = tx.QueryRow(ctx, `
+		INSERT INTO stories (account_id, slug, title, author, language, source, rights, updated_at)
+		VALUES ($1,$2,$3,NULLIF(BTRIM($4),''),$5,$6::jsonb,$7::jsonb, now())
+		RETURNING id
+	`, accountID, slug, current.Output.Title, current.Output.Author, current.Output.Language,
+		string(sourceJSON), string(rightsJSON)).Scan(&storyID)
+	if isUniqueViolation(err) {
+		return model.AdminStoryStatusResponse{}, fmt.Errorf("%w", model.ErrAdminStoryConflict)
+	}
+	if err != nil {
+		return model.AdminStoryStatusResponse{}, err
+	}
+
+	var draftID, publishedID *string
+	for _, version := range versions {
+		readability, err := readabilityJSON(version.Output.Readability)
+		if err != nil {
+			return model.AdminStoryStatusResponse{}, err
+		}
+		vocabulary, err := vocabularyJSON(version.Output.Vocabulary)
+		if err != nil {
+			return model.AdminStoryStatusResponse{}, err
+		}
+		versionID, _, err := insertStoryVersion(ctx, tx, storyID, version.Bundle.Version, version.Output, version.FrontmatterJSON, readability, vocabulary, nil)
+		if err != nil {
+			return model.AdminStoryStatusResponse{}, err
+		}
+		if version.CreatedAt != nil {
+			if _, err := tx.Exec(ctx, `
+				UPDATE story_versions SET created_at = $2 WHERE id = $1
+			`, versionID, *version.CreatedAt); err != nil {
+				return model.AdminStoryStatusResponse{}, err
+			}
+		}
+		if version.Bundle.IsDraft {
+			draftID = cloneString(&versionID)
+		}
+		if version.Bundle.IsPublished {
+			publishedID = cloneString(&versionID)
+		}
+	}
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE stories
+		SET draft_version_id = $2,
+		    published_version_id = $3,
+		    is_published = $3::uuid IS NOT NULL,
+		    updated_at = now()
+		WHERE id = $1
+	`, storyID, draftID, publishedID); err != nil {
+		return model.AdminStoryStatusResponse{}, err
+	}
+
+	for _, contributor := range bundle.Story.Contributors {
+		var contributorID string
+		if err := tx.QueryRow(ctx, `
+			INSERT INTO contributors (name)
+			VALUES ($1)
+			ON CONFLICT (name) DO UPDATE SET name = EXCLUDED.name
+			RETURNING id
+		`, strings.TrimSpace(contributor.Name)).Scan(&contributorID); err != nil {
+			return model.AdminStoryStatusResponse{}, err
+		}
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO story_contributors (story_id, contributor_id, role)
+			VALUES ($1,$2,$3)
+			ON CONFLICT DO NOTHING
+		`, storyID, contributorID, string(contributor.Role)); err != nil {
+			return model.AdminStoryStatusResponse{}, err
+		}
+	}
+
+	if len(bundle.Story.Tags) > 0 {
+		tags := make([]string, 0, len(bundle.Story.Tags))
+		for _, name := range bundle.Story.Tags {
+			value, _ := model.NormalizeAdminTagName(name)
+			tags = append(tags, value)
+		}
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO tags (account_id, name)
+			SELECT $1::uuid, name FROM unnest($2::text[]) AS name
+			ON CONFLICT (account_id, lower(name)) DO NOTHING
+		`, accountID, tags); err != nil {
+			return model.AdminStoryStatusResponse{}, err
+		}
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO story_tags (story_id, tag_id)
+			SELECT $1::uuid, t.id
+			FROM tags t
+			WHERE t.account_id = $2
+			  AND lower(t.name) IN (SELECT lower(name) FROM unnest($3::text[]) AS name)
+			ON CONFLICT DO NOTHING
+		`, storyID, accountID, tags); err != nil {
+			return model.AdminStoryStatusResponse{}, err
+		}
+	}
+
+	story, err := loadAdminStory(ctx, tx, accountID, slug, true)
+	if err != nil {
+		return model.AdminStoryStatusResponse{}, err
+	}
+	inspected, err := inspectAdminStory(ctx, tx, story)
+	if err != nil {
+		return model.AdminStoryStatusResponse{}, err
+	}
+	status := adminStoryStatusResponse(inspected)
+	if publishedID != nil {
+		if err := enqueueWebhookEvent(ctx, tx, accountID, webhooks.StoryPayload(model.WebhookEventStoryPublished, time.Now(), status)); err != nil {
+			return model.AdminStoryStatusResponse{}, err
+		}
+		if err := enqueuePushStory(ctx, tx, accountID, slug); err != nil {
+			return model.AdminStoryStatusResponse{}, err
+		}
+	}
+	return status, nil
+}
+
+// prepareStoryBundle validates a bundle without touching the database and
+// returns its versions in ascending version order.
+func prepareStoryBundle(bundle model.StoryBundle) ([]importedBundleVersion, error) {
+	issues := []model.AdminValidationIssue{}
+	issue := func(field, code, message string) {
+		issues = append(issues, model.AdminValidationIssue{Field: field, Code: code, Message: message})
+	}
+
+	if bundle.Format != model.StoryBundleFormat || bundle.FormatVersion != model.StoryBundleFormatVersion {
+		issue("format", "unsupported", "Bundle format is not supported")
+		return nil, &model.AdminValidationError{Issues: issues}
+	}
+	slug := strings.TrimSpace(bundle.Story.Slug)
+	if storyingest.ValidateSlug(slug) != nil {
+		issue("story.slug", "invalid", "Slug must be lowercase words separated by hyphens")
+	}
+	if len(bundle.Versions) == 0 {
+		issue("versions", "required", "Bundle must contain at least one version")
+	}
+	for i, contributor := range bundle.Story.Contributors {
+		name := strings.TrimSpace(contributor.Name)
+		if name == "" || utf8.RuneCountInString(name) > maxContributorNameRunes || !contributor.Role.Valid() {
+			issue(fmt.Sprintf("story.contributors[%d]", i), "invalid", "Contributor name or role is invalid")
+		}
+	}
+	for i, name := range bundle.Story.Tags {
+		if _, ok := model.NormalizeAdminTagName(name); !ok {
+			issue(fmt.Sprintf("story.tags[%d]", i), "invalid", "Tag name is invalid")
+		}
+	}
+	if len(issues) > 0 {
+		return nil, &model.AdminValidationError{Issues: issues}
+	}
+
+	versions := make([]importedBundleVersion, 0, len(bundle.Versions))
+	seenVersions := map[int]bool{}
+	seenHashes := map[string]bool{}
+	drafts, published := 0, 0
+	for i, version := range bundle.Versions {
+		field := fmt.Sprintf("versions[%d]", i)
+		if version.Version <= 0 || seenVersions[version.Version] {
+			issue(field+".version", "invalid", "Version numbers must be positive and unique")
+			continue
+		}
+		seenVersions[version.Version] = true
+		if version.IsDraft {
+			drafts++
+		}
+		if version.IsPublished {
+			published++
+		}
+
+		imported, ok := canonicalBundleVersion(slug, version)
+		if !ok {
+			issue(field, "mismatch", "Version content does not match its Markdown")
+			continue
+		}
+		if seenHashes[imported.Output.ContentHash] {
+			issue(field+".contentHash", "duplicate", "Version content repeats an earlier version")
+			continue
+		}
+		seenHashes[imported.Output.ContentHash] = true
+		if version.CreatedAt != "" {
+			createdAt, err := time.Parse(time.RFC3339Nano, version.CreatedAt)
+			if err != nil {
+				issue(field+".createdAt", "invalid", "Created time must be RFC 3339")
+				continue
+			}
+			imported.CreatedAt = &createdAt
+		}
+		versions = append(versions, imported)
+	}
+	if drafts > 1 {
+		issue("versions", "multiple_drafts", "At most one version may be the draft")
+	}
+	if published > 1 {
+		issue("versions", "multiple_published", "At most one version may be published")
+	}
+	if len(issues) > 0 {
+		return nil, &model.AdminValidationError{Issues: issues}
+	}
+
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].Bundle.Version < versions[j].Bundle.Version
+	})
+	return versions, nil
+}
+
+// canonicalBundleVersion re-derives one version exactly as stored versions
+// are re-validated, then requires the bundle's body, hash, and segments to
+// agree with the result.
+func canonicalBundleVersion(slug string, version model.StoryBundleVersion) (importedBundleVersion, bool) {
+	raw, err := json.Marshal(version.Frontmatter)
+	if err != nil {
+		return importedBundleVersion{}, false
+	}
+	frontmatter, err := normalizeStoredFrontmatter(raw)
+	if err != nil {
+		return importedBundleVersion{}, false
+	}
+	out, err := frontmatter.canonicalize(slug, version.Markdown)
+	if err != nil {
+		return importedBundleVersion{}, false
+	}
+	frontmatterJSON, err := json.Marshal(out.Frontmatter)
+	if err != nil || !jsonDocumentsEqual(frontmatterJSON, frontmatter.JSON) {
+		return importedBundleVersion{}, false
+	}
+	if out.Markdown != version.Markdown || out.RenderedHTML != version.RenderedHTML ||
+		out.ContentHash != version.ContentHash || !bundleSegmentsMatch(version.Segments, out.Segments) {
+		return importedBundleVersion{}, false
+	}
+	return importedBundleVersion{Bundle: version, Output: out, FrontmatterJSON: frontmatterJSON}, true
+}
+
+func bundleSegmentsMatch(bundle []model.StoryBundleSegment, canonical []storyingest.Segment) bool {
+	if len(bundle) != len(canonical) {
+		return false
+	}
+	for i, segment := range bundle {
+		expected := canonical[i]
+		if segment.Ordinal != expected.Ordinal || segment.Kind != string(expected.Kind) ||
+			!equalIntPointers(segment.HeadingLevel, expected.HeadingLevel) ||
+			segment.ContentKey != expected.ContentKey || segment.ContentOccurrence != expected.ContentOccurrence ||
+			!equalStringPointers(segment.ChapterKey, expected.ChapterKey) ||
+			!equalIntPointers(segment.ChapterOccurrence, expected.ChapterOccurrence) ||
+			segment.Markdown != expected.Markdown || segment.RenderedHTML != expected.RenderedHTML ||
+			segment.WordCount != expected.WordCount {
+			return false
+		}
+	}
+	return true
+}
+
+// currentBundleVersion picks the version whose metadata names the story:
+// the draft, then the published version, then the newest.
+func currentBundleVersion(versions []importedBundleVersion) importedBundleVersion {
+	for _, version := range versions {
+		if version.Bundle.IsDraft {
+			return version
+		}
+	}
+	for _, version := range versions {
+		if version.Bundle.IsPublished {
+			return version
+		}
+	}
+	return versions[len(versions)-1]
+}
+
+func nullIntValue(value sql.NullInt64) *int {
+	if !value.Valid {
+		return nil
+	}
+	result := int(value.Int64)
+	return &result
+}
+
+func equalIntPointers(left, right *int) bool {
+	if left == nil || right == nil {
+		return left == nil && right == nil
+	}
+	return *left == *right
+}
+
+func equalStringPointers(left, right *string) bool {
+	if left == nil || right == nil {
+		return left == nil && right == nil
+	}
+	return *left == *right
+}
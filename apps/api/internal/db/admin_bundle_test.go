@@ -0,0 +1,161 @@
+package db
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"pandapages/api/internal/model"
+	"pandapages/api/internal/storyingest"
+)
+
+func testBundleVersion(t *testing.T, version int, markdown string) model.StoryBundleVersion {
+	t.Helper()
+	out, err := storyingest.Ingest(storyingest.Input{
+		Slug:     "bundle-story",
+		Title:    "Bundle Story",
+		Author:   "Panda Author",
+		Markdown: markdown,
+	})
+	if err != nil {
+		t.Fatalf("Ingest: %v", err)
+	}
+	segments := make([]model.StoryBundleSegment, 0, len(out.Segments))
+	for _, segment := range out.Segments {
+		segments = append(segments, model.StoryBundleSegment{
+			Ordinal:           segment.Ordinal,
+			Kind:              string(segment.Kind),
+			HeadingLevel:      segment.HeadingLevel,
+			ContentKey:        segment.ContentKey,
+			ContentOccurrence: segment.ContentOccurrence,
+			ChapterKey:        segment.ChapterKey,
+			ChapterOccurrence: segment.ChapterOccurrence,
+			Markdown:          segment.Markdown,
+			RenderedHTML:      segment.RenderedHTML,
+			WordCount:         segment.WordCount,
+		})
+	}
+	return model.StoryBundleVersion{
+		Version:      version,
+		Frontmatter:  out.Frontmatter,
+		Markdown:     out.Markdown,
+		RenderedHTML: out.RenderedHTML,
+		ContentHash:  out.ContentHash,
+		Segments:     segments,
+	}
+}
+
+func testBundle(t *testing.T, versions ...model.StoryBundleVersion) model.StoryBundle {
+	t.Helper()
+	bundle := model.StoryBundle{
+		Format:        model.StoryBundleFormat,
+		FormatVersion: model.StoryBundleFormatVersion,
+		Story: model.StoryBundleStory{
+			Slug:         "bundle-story",
+			Contributors: []model.StoryContributor{{Name: "Panda Author", Role: model.ContributorRoleAuthor}},
+			Tags:         []string{"Animals"},
+		},
+		Versions: versions,
+	}
+	// Bundles arrive as JSON, so exercise the decoded frontmatter shape.
+	encoded, err := json.Marshal(bundle)
+	if err != nil {
+		t.Fatalf("marshal bundle: %v", err)
+	}
+	var decoded model.StoryBundle
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatalf("unmarshal bundle: %v", err)
+	}
+	return decoded
+}
+
+func TestPrepareStoryBundleAcceptsCanonicalVersionsInOrder(t *testing.T) {
+	second := testBundleVersion(t, 2, "# Bundle Story\n\n## One\n\nSecond telling.\n")
+	second.IsDraft = true
+	first := testBundleVersion(t, 1, "# Bundle Story\n\nFirst telling.\n")
+	first.IsPublished = true
+	first.CreatedAt = "2026-01-02T03:04:05Z"
+
+	versions, err := prepareStoryBundle(testBundle(t, second, first))
+	if err != nil {
+		t.Fatalf("prepareStoryBundle: %v", err)
+	}
+	if len(versions) != 2 || versions[0].Bundle.Version != 1 || versions[1].Bundle.Version != 2 {
+		t.Fatalf("versions = %#v", versions)
+	}
+	if versions[0].CreatedAt == nil || versions[1].CreatedAt != nil {
+		t.Fatalf("created times = %v / %v", versions[0].CreatedAt, versions[1].CreatedAt)
+	}
+	if current := currentBundleVersion(versions); current.Bundle.Version != 2 {
+		t.Fatalf("current version = %d, want draft 2", current.Bundle.Version)
+	}
+}
+
+func TestPrepareStoryBundleRejectsInconsistentBundles(t *testing.T) {
+	tests := []struct {
+		name      string
+		mutate    func(*model.StoryBundle)
+		wantField string
+		wantCode  string
+	}{
+		{
+			name:      "unknown format",
+			mutate:    func(b *model.StoryBundle) { b.FormatVersion = 99 },
+			wantField: "format",
+			wantCode:  "unsupported",
+		},
+		{
+			name:      "tampered rendering",
+			mutate:    func(b *model.StoryBundle) { b.Versions[0].RenderedHTML += "<p>extra</p>" },
+			wantField: "versions[0]",
+			wantCode:  "mismatch",
+		},
+		{
+			name:      "tampered segment",
+			mutate:    func(b *model.StoryBundle) { b.Versions[0].Segments[1].WordCount++ },
+			wantField: "versions[0]",
+			wantCode:  "mismatch",
+		},
+		{
+			name: "repeated version number",
+			mutate: func(b *model.StoryBundle) {
+				b.Versions = append(b.Versions, b.Versions[0])
+			},
+			wantField: "versions[2].version",
+			wantCode:  "invalid",
+		},
+		{
+			name: "two drafts",
+			mutate: func(b *model.StoryBundle) {
+				b.Versions[0].IsDraft = true
+				b.Versions[1].IsDraft = true
+			},
+			wantField: "versions",
+			wantCode:  "multiple_drafts",
+		},
+		{
+			name:      "bad contributor role",
+			mutate:    func(b *model.StoryBundle) { b.Story.Contributors[0].Role = "narrator" },
+			wantField: "story.contributors[0]",
+			wantCode:  "invalid",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			bundle := testBundle(t,
+				testBundleVersion(t, 1, "# Bundle Story\n\nFirst telling.\n"),
+				testBundleVersion(t, 2, "# Bundle Story\n\nSecond telling.\n"),
+			)
+			test.mutate(&bundle)
+			_, err := prepareStoryBundle(bundle)
+			var validationErr *model.AdminValidationError
+			if !errors.As(err, &validationErr) || len(validationErr.Issues) == 0 {
+				t.Fatalf("prepareStoryBundle error = %v, want validation error", err)
+			}
+			got := validationErr.Issues[0]
+			if got.Field != test.wantField || got.Code != test.wantCode {
+				t.Fatalf("issue = %#v, want %s/%s", got, test.wantField, test.wantCode)
+			}
+		})
+	}
+}
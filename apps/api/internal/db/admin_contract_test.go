@@ -16,7 +16,7 @@ func TestAdminPreviewUsesCanonicalDraftInputWithoutStoreAccess(t *testing.T) {
 	language := "cy"
 	sourceURL := "  https://example.invalid/source  "
 	rights := map[string]any{"label": "Public domain"}
-	response, err := (&Store{}).AdminPreview(model.AdminPreviewRequest{
+	response, err := (&Store{}).AdminPreview(t.Context(), model.AdminPreviewRequest{
 		Slug:      "contract-story",
 		Title:     "  Contract Story  ",
 		Author:    &author,
@@ -84,7 +84,7 @@ func TestCanonicalAdminStoryInputReturnsFiniteIssues(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			_, err := canonicalAdminStoryInput(test.request)
+			_, err := canonicalAdminStoryInput(t.Context(), test.request)
 			var validationErr *model.AdminValidationError
 			if !errors.As(err, &validationErr) || len(validationErr.Issues) == 0 {
 				t.Fatalf("validation error = %v", err)
@@ -100,6 +100,46 @@ func TestCanonicalAdminStoryInputReturnsFiniteIssues(t *testing.T) {
 	}
 }
 
+func TestAdminValidateCollectsLocatedIssues(t *testing.T) {
+	response, err := (&Store{}).AdminValidate(t.Context(), model.AdminStoryInput{
+		Slug:     "Bad Slug",
+		Title:    "Story",
+		Markdown: "---\ntitle: Story\nauthor: [Someone]\nrights: public\n---\n# Story\n\n<div>hidden</div>\n",
+	})
+	if err != nil {
+		t.Fatalf("AdminValidate: %v", err)
+	}
+	want := []model.AdminValidationIssue{
+		{Field: "slug", Code: "invalid", Message: "Use lowercase letters, numbers, and hyphens"},
+		{Field: "frontmatter.author", Code: "type_mismatch", Message: "Use plain text for author", Line: 3},
+		{Field: "frontmatter.rights", Code: "type_mismatch", Message: "Rights must be a set of key: value pairs", Line: 4},
+	}
+	if response.Valid || !reflect.DeepEqual(response.Issues, want) {
+		t.Fatalf("issues = %#v", response)
+	}
+	if len(response.Warnings) != 1 || response.Warnings[0].Code != "unsupported" || response.Warnings[0].Line != 8 {
+		t.Fatalf("warnings = %#v", response.Warnings)
+	}
+
+	response, err = (&Store{}).AdminValidate(t.Context(), model.AdminStoryInput{
+		Slug: "story", Title: "Story", Markdown: "\n---\ntitle: [unterminated\n---\nStory",
+	})
+	if err != nil || response.Valid || len(response.Issues) != 1 ||
+		response.Issues[0].Code != "invalid_yaml" || response.Issues[0].Line != 3 {
+		t.Fatalf("yaml response = %#v, %v", response, err)
+	}
+	if strings.Contains(response.Issues[0].Message, "yaml:") {
+		t.Fatalf("validation exposed parser detail: %#v", response.Issues[0])
+	}
+
+	response, err = (&Store{}).AdminValidate(t.Context(), model.AdminStoryInput{
+		Slug: "story", Title: "Story", Markdown: "# Story\n\nReadable <!-- note -->.\n",
+	})
+	if err != nil || !response.Valid || len(response.Issues) != 0 || len(response.Warnings) != 0 {
+		t.Fatalf("valid response = %#v, %v", response, err)
+	}
+}
+
 func TestImmutableAdminMetadataIncludesRightsAndRejectsMalformedUTF8(t *testing.T) {
 	rights := map[string]any{"label": "Public domain", "year": 1908}
 	output, err := storyingest.Ingest(storyingest.Input{
@@ -180,3 +220,37 @@ func mustJSON(t *testing.T, value any) []byte {
 	}
 	return encoded
 }
+
+func TestAdminMetadataIssuesValidateOnlySetFields(t *testing.T) {
+	blank, language, author := "  ", "not a tag", ""
+	issues := adminMetadataIssues(model.AdminStoryMetadataPatch{Title: &blank, Language: &language, Author: &author})
+	if len(issues) != 2 || issues[0].Field != "title" || issues[1].Field != "language" {
+		t.Fatalf("issues = %#v", issues)
+	}
+	valid := "cy"
+	if issues := adminMetadataIssues(model.AdminStoryMetadataPatch{Language: &valid}); len(issues) != 0 {
+		t.Fatalf("valid patch issues = %#v", issues)
+	}
+}
+
+func TestVersionsToPruneKeepsNewestAndProtectedVersions(t *testing.T) {
+	versions := []retainedVersion{
+		{ID: "v1", Version: 1},
+		{ID: "v2", Version: 2, Protected: true},
+		{ID: "v3", Version: 3},
+		{ID: "v4", Version: 4},
+		{ID: "v5", Version: 5},
+		{ID: "v6", Version: 6},
+	}
+	doomed := versionsToPrune(versions, 2)
+	got := []int{}
+	for _, version := range doomed {
+		got = append(got, version.Version)
+	}
+	if !reflect.DeepEqual(got, []int{1, 3, 4}) {
+		t.Fatalf("pruned versions = %v", got)
+	}
+	if len(versionsToPrune(versions, 10)) != 0 {
+		t.Fatal("keep larger than history pruned versions")
+	}
+}
@@ -0,0 +1,51 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"pandapages/api/internal/model"
+)
+
+func (s *Store) AdminGetHyphenation(ctx context.Context, accountID string) (model.AdminHyphenation, error) {
+	accountID = strings.TrimSpace(accountID)
+	if !accountIDRe.MatchString(accountID) {
+		return model.AdminHyphenation{}, fmt.Errorf("account required")
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	var out model.AdminHyphenation
+	if err := s.db.QueryRow(ctx, `
+		SELECT reader_hyphenation FROM accounts WHERE id = $1
+	`, accountID).Scan(&out.Enabled); err != nil {
+		return model.AdminHyphenation{}, err
+	}
+	return out, nil
+}
+
+// AdminSetHyphenation turns soft hyphens on or off for the account's readers.
+// Stored versions are unchanged; the Reader strips soft hyphens when it is off.
+func (s *Store) AdminSetHyphenation(ctx context.Context, accountID string, enabled bool) (model.AdminHyphenation, error) {
+	accountID = strings.TrimSpace(accountID)
+	if !accountIDRe.MatchString(accountID) {
+		return model.AdminHyphenation{}, fmt.Errorf("account required")
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	var out model.AdminHyphenation
+	if err := s.writes(ctx).QueryRow(ctx, `
+		UPDATE accounts
+		SET reader_hyphenation = $2,
+		    updated_at = now()
+		WHERE id = $1
+		RETURNING reader_hyphenation
+	`, accountID, enabled).Scan(&out.Enabled); err != nil {
+		return model.AdminHyphenation{}, err
+	}
+	return out, nil
+}
@@ -11,6 +11,8 @@ import (
 	"pandapages/api/internal/model"
 	"pandapages/api/internal/readercontract"
 	"pandapages/api/internal/storyingest"
+
+	"github.com/jackc/pgx/v5"
 )
 
 type adminStoryRow struct {
@@ -21,8 +23,19 @@ type adminStoryRow struct {
 	UpdatedAt          time.Time
 	DraftVersionID     *string
 	PublishedVersionID *string
+
+	// Catalogue metadata from the story row. A metadata patch changes these
+	// without creating a version, so they take precedence over the metadata
+	// recorded in version frontmatter.
+	Title    string
+	Author   *string
+	Language string
+	Rights   map[string]any
 }
 
+const adminStoryColumns = `id, slug, is_published, created_at, updated_at, draft_version_id, published_version_id,
+	title, NULLIF(BTRIM(author), ''), language, rights::text`
+
 type inspectedAdminVersion struct {
 	Summary    model.AdminVersionSummary
 	Inspection adminVersionInspection
@@ -40,6 +53,7 @@ type adminVersionInspection struct {
 	SegmentCount int
 	WordCount    int
 	ChapterCount int
+	Readability  *model.Readability
 }
 
 type inspectedAdminStory struct {
@@ -48,26 +62,52 @@ type inspectedAdminStory struct {
 	Versions []model.AdminVersionSummary
 }
 
-func (s *Store) AdminListStories(accountID string) (model.AdminStoriesListResponse, error) {
+const (
+	defaultAdminStoriesPageSize  = 50
+	maxAdminStoriesPageSize      = 200
+	defaultAdminVersionsPageSize = 20
+	maxAdminVersionsPageSize     = 100
+)
+
+func (s *Store) AdminListStories(ctx context.Context, accountID string, page model.PageRequest) (model.AdminStoriesListResponse, error) {
 	accountID = strings.TrimSpace(accountID)
 	if !accountIDRe.MatchString(accountID) {
 		return model.AdminStoriesListResponse{}, fmt.Errorf("account required")
 	}
+	limit := pageSize(page.Limit, defaultAdminStoriesPageSize, maxAdminStoriesPageSize)
+	var (
+		afterUpdated time.Time
+		afterSlug    string
+	)
+	after, err := decodeCursor(page.Cursor, &afterUpdated, &afterSlug)
+	if err != nil {
+		return model.AdminStoriesListResponse{}, err
+	}
+	cursorArgs := []any{nil, nil}
+	if after {
+		cursorArgs = []any{afterUpdated, afterSlug}
+	}
 
-	ctx, cancel := s.ctx()
+	ctx, cancel := s.ctx(ctx)
 	defer cancel()
-	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
 	if err != nil {
 		return model.AdminStoriesListResponse{}, err
 	}
-	defer func() { _ = tx.Rollback() }()
+	defer func() { _ = tx.Rollback(ctx) }()
 
-	rows, err := tx.QueryContext(ctx, `
-		SELECT id, slug, is_published, created_at, updated_at, draft_version_id, published_version_id
+	rows, err := tx.Query(ctx, `
+		SELECT `+adminStoryColumns+`
 		FROM stories
 		WHERE account_id = $1
+		  AND (
+			$2::timestamptz IS NULL
+			OR updated_at < $2::timestamptz
+			OR (updated_at = $2::timestamptz AND slug > $3::text)
+		  )
 		ORDER BY updated_at DESC, slug ASC
-	`, accountID)
+		LIMIT $4
+	`, accountID, cursorArgs[0], cursorArgs[1], limit+1)
 	if err != nil {
 		return model.AdminStoriesListResponse{}, err
 	}
@@ -75,18 +115,20 @@ func (s *Store) AdminListStories(accountID string) (model.AdminStoriesListRespon
 	for rows.Next() {
 		story, err := scanAdminStory(rows)
 		if err != nil {
-			_ = rows.Close()
+			rows.Close()
 			return model.AdminStoriesListResponse{}, err
 		}
 		stories = append(stories, story)
 	}
 	if err := rows.Err(); err != nil {
-		_ = rows.Close()
+		rows.Close()
 		return model.AdminStoriesListResponse{}, err
 	}
-	if err := rows.Close(); err != nil {
+	rows.Close()
+	if err := rows.Err(); err != nil {
 		return model.AdminStoriesListResponse{}, err
 	}
+	stories, more := trimPage(stories, limit)
 
 	items := make([]model.AdminStorySummary, 0, len(stories))
 	for _, story := range stories {
@@ -96,26 +138,35 @@ func (s *Store) AdminListStories(accountID string) (model.AdminStoriesListRespon
 		}
 		items = append(items, inspected.Summary)
 	}
-	if err := tx.Commit(); err != nil {
+	total, err := pageTotal(ctx, tx, page, `SELECT count(*) FROM stories WHERE account_id = $1`, accountID)
+	if err != nil {
+		return model.AdminStoriesListResponse{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
 		return model.AdminStoriesListResponse{}, err
 	}
-	return model.AdminStoriesListResponse{Items: items}, nil
+	out := model.AdminStoriesListResponse{Items: items, Total: total}
+	if more {
+		last := stories[len(stories)-1]
+		out.NextCursor = encodeCursor(last.UpdatedAt, last.Slug)
+	}
+	return out, nil
 }
 
-func (s *Store) AdminGetStory(accountID, slug string) (model.AdminStoryDetailResponse, error) {
+func (s *Store) AdminGetStory(ctx context.Context, accountID, slug string) (model.AdminStoryDetailResponse, error) {
 	accountID = strings.TrimSpace(accountID)
 	slug = strings.TrimSpace(slug)
 	if !accountIDRe.MatchString(accountID) || storyingest.ValidateSlug(slug) != nil {
 		return model.AdminStoryDetailResponse{}, fmt.Errorf("%w", model.ErrAdminStoryNotFound)
 	}
 
-	ctx, cancel := s.ctx()
+	ctx, cancel := s.ctx(ctx)
 	defer cancel()
-	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
 	if err != nil {
 		return model.AdminStoryDetailResponse{}, err
 	}
-	defer func() { _ = tx.Rollback() }()
+	defer func() { _ = tx.Rollback(ctx) }()
 
 	story, err := loadAdminStory(ctx, tx, accountID, slug, false)
 	if err != nil {
@@ -125,13 +176,101 @@ func (s *Store) AdminGetStory(accountID, slug string) (model.AdminStoryDetailRes
 	if err != nil {
 		return model.AdminStoryDetailResponse{}, err
 	}
-	if err := tx.Commit(); err != nil {
+	if err := tx.Commit(ctx); err != nil {
 		return model.AdminStoryDetailResponse{}, err
 	}
 	return adminStoryDetail(inspected), nil
 }
 
-func (s *Store) AdminGetVersionSource(accountID, slug, versionID string) (model.AdminVersionSourceResponse, error) {
+// AdminListStoryVersions returns one page of a story's versions, newest
+// first, each with the health AdminGetStory reports for it.
+func (s *Store) AdminListStoryVersions(ctx context.Context, accountID, slug string, page model.PageRequest) (model.AdminStoryVersionsResponse, error) {
+	accountID = strings.TrimSpace(accountID)
+	slug = strings.TrimSpace(slug)
+	if !accountIDRe.MatchString(accountID) || storyingest.ValidateSlug(slug) != nil {
+		return model.AdminStoryVersionsResponse{}, fmt.Errorf("%w", model.ErrAdminStoryNotFound)
+	}
+	limit := pageSize(page.Limit, defaultAdminVersionsPageSize, maxAdminVersionsPageSize)
+	var (
+		afterVersion int64
+		afterID      string
+	)
+	after, err := decodeCursor(page.Cursor, &afterVersion, &afterID)
+	if err != nil {
+		return model.AdminStoryVersionsResponse{}, err
+	}
+	cursorArgs := []any{nil, nil}
+	if after {
+		if !accountIDRe.MatchString(afterID) {
+			return model.AdminStoryVersionsResponse{}, model.ErrInvalidCursor
+		}
+		cursorArgs = []any{afterVersion, afterID}
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return model.AdminStoryVersionsResponse{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	story, err := loadAdminStory(ctx, tx, accountID, slug, false)
+	if err != nil {
+		return model.AdminStoryVersionsResponse{}, err
+	}
+	rows, err := tx.Query(ctx, `
+		SELECT id, version, created_at
+		FROM story_versions
+		WHERE story_id = $1
+		  AND (
+			$2::bigint IS NULL
+			OR version < $2::bigint
+			OR (version = $2::bigint AND id > $3::uuid)
+		  )
+		ORDER BY version DESC, id ASC
+		LIMIT $4
+	`, story.ID, cursorArgs[0], cursorArgs[1], limit+1)
+	if err != nil {
+		return model.AdminStoryVersionsResponse{}, err
+	}
+	type versionRow struct {
+		ID        string
+		Version   int64
+		CreatedAt time.Time
+	}
+	versionRows, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (versionRow, error) {
+		var version versionRow
+		err := row.Scan(&version.ID, &version.Version, &version.CreatedAt)
+		return version, err
+	})
+	if err != nil {
+		return model.AdminStoryVersionsResponse{}, err
+	}
+	versionRows, more := trimPage(versionRows, limit)
+
+	out := model.AdminStoryVersionsResponse{Items: make([]model.AdminVersionSummary, 0, len(versionRows))}
+	for _, version := range versionRows {
+		inspected, _, err := inspectAdminVersionSummary(ctx, tx, story, version.ID, version.Version, version.CreatedAt)
+		if err != nil {
+			return model.AdminStoryVersionsResponse{}, err
+		}
+		out.Items = append(out.Items, inspected.Summary)
+	}
+	if out.Total, err = pageTotal(ctx, tx, page, `SELECT count(*) FROM story_versions WHERE story_id = $1`, story.ID); err != nil {
+		return model.AdminStoryVersionsResponse{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return model.AdminStoryVersionsResponse{}, err
+	}
+	if more {
+		last := versionRows[len(versionRows)-1]
+		out.NextCursor = encodeCursor(last.Version, last.ID)
+	}
+	return out, nil
+}
+
+func (s *Store) AdminGetVersionSource(ctx context.Context, accountID, slug, versionID string) (model.AdminVersionSourceResponse, error) {
 	accountID = strings.TrimSpace(accountID)
 	slug = strings.TrimSpace(slug)
 	versionID = strings.TrimSpace(versionID)
@@ -139,13 +278,13 @@ func (s *Store) AdminGetVersionSource(accountID, slug, versionID string) (model.
 		return model.AdminVersionSourceResponse{}, fmt.Errorf("%w", model.ErrAdminStoryNotFound)
 	}
 
-	ctx, cancel := s.ctx()
+	ctx, cancel := s.ctx(ctx)
 	defer cancel()
-	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
 	if err != nil {
 		return model.AdminVersionSourceResponse{}, err
 	}
-	defer func() { _ = tx.Rollback() }()
+	defer func() { _ = tx.Rollback(ctx) }()
 
 	story, err := loadAdminStory(ctx, tx, accountID, slug, false)
 	if err != nil {
@@ -161,7 +300,15 @@ func (s *Store) AdminGetVersionSource(accountID, slug, versionID string) (model.
 	if err != nil {
 		return model.AdminVersionSourceResponse{}, err
 	}
-	if err := tx.Commit(); err != nil {
+	generation, err := versionGeneration(ctx, tx, accountID, versionID)
+	if err != nil {
+		return model.AdminVersionSourceResponse{}, err
+	}
+	moderation, err := versionModeration(ctx, tx, versionID)
+	if err != nil {
+		return model.AdminVersionSourceResponse{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
 		return model.AdminVersionSourceResponse{}, err
 	}
 
@@ -183,6 +330,8 @@ func (s *Store) AdminGetVersionSource(accountID, slug, versionID string) (model.
 		IsDraft:      equalOptionalID(story.DraftVersionID, versionID),
 		IsPublished:  story.IsPublished && equalOptionalID(story.PublishedVersionID, versionID),
 		Health:       model.AdminVersionHealthReady,
+		Generation:   generation,
+		Moderation:   moderation,
 	}, nil
 }
 
@@ -195,6 +344,8 @@ func scanAdminStory(scanner adminStoryScanner) (adminStoryRow, error) {
 		story       adminStoryRow
 		draftID     sql.NullString
 		publishedID sql.NullString
+		author      sql.NullString
+		rightsJSON  string
 	)
 	if err := scanner.Scan(
 		&story.ID,
@@ -204,21 +355,67 @@ func scanAdminStory(scanner adminStoryScanner) (adminStoryRow, error) {
 		&story.UpdatedAt,
 		&draftID,
 		&publishedID,
+		&story.Title,
+		&author,
+		&story.Language,
+		&rightsJSON,
 	); err != nil {
 		return adminStoryRow{}, err
 	}
 	story.DraftVersionID = nullStringValue(draftID)
 	story.PublishedVersionID = nullStringValue(publishedID)
+	story.Author = nullStringValue(author)
+	if decoded, ok := decodeJSONDocument([]byte(rightsJSON)); ok {
+		story.Rights, _ = decoded.(map[string]any)
+	}
 	return story, nil
 }
 
-func loadAdminStory(ctx context.Context, tx *sql.Tx, accountID, slug string, lock bool) (adminStoryRow, error) {
+// inspectAdminVersionSummary summarizes one stored version of story and
+// reports whether it needs repair. Only unexpected database errors fail.
+func inspectAdminVersionSummary(ctx context.Context, tx pgx.Tx, story adminStoryRow, versionID string, version int64, createdAt time.Time) (inspectedAdminVersion, bool, error) {
+	versionNumber := positiveVersion(version)
+	inspected := inspectedAdminVersion{Summary: model.AdminVersionSummary{
+		VersionID:   versionID,
+		Version:     versionNumber,
+		CreatedAt:   createdAt.UTC().Format(time.RFC3339Nano),
+		IsDraft:     equalOptionalID(story.DraftVersionID, versionID),
+		IsPublished: story.IsPublished && equalOptionalID(story.PublishedVersionID, versionID),
+		Health:      model.AdminVersionHealthRepairRequired,
+	}}
+	repairRequired := false
+	inspection, validationErr := inspectAdminVersion(ctx, tx, story.ID, versionID)
+	switch {
+	case validationErr == nil:
+		inspected.Inspection = inspection
+		inspected.Summary.Version = inspection.Version
+		inspected.Summary.SegmentCount = inspection.SegmentCount
+		inspected.Summary.WordCount = inspection.WordCount
+		inspected.Summary.ChapterCount = inspection.ChapterCount
+		inspected.Summary.Readability = inspection.Readability
+		inspected.Summary.Health = model.AdminVersionHealthReady
+	case errors.Is(validationErr, errStoredVersionInvalid):
+		repairRequired = true
+	case errors.Is(validationErr, sql.ErrNoRows):
+		inspected.Summary.Health = model.AdminVersionHealthUnavailable
+		repairRequired = true
+	default:
+		return inspectedAdminVersion{}, false, validationErr
+	}
+	if version <= 0 || int64(versionNumber) != version {
+		repairRequired = true
+		inspected.Summary.Health = model.AdminVersionHealthRepairRequired
+	}
+	return inspected, repairRequired, nil
+}
+
+func loadAdminStory(ctx context.Context, tx pgx.Tx, accountID, slug string, lock bool) (adminStoryRow, error) {
 	lockClause := ""
 	if lock {
 		lockClause = " FOR UPDATE"
 	}
-	story, err := scanAdminStory(tx.QueryRowContext(ctx, `
-		SELECT id, slug, is_published, created_at, updated_at, draft_version_id, published_version_id
+	story, err := scanAdminStory(tx.QueryRow(ctx, `
+		SELECT `+adminStoryColumns+`
 		FROM stories
 		WHERE account_id = $1
 		  AND slug = $2
@@ -229,8 +426,8 @@ func loadAdminStory(ctx context.Context, tx *sql.Tx, accountID, slug string, loc
 	return story, err
 }
 
-func inspectAdminStory(ctx context.Context, tx *sql.Tx, story adminStoryRow) (inspectedAdminStory, error) {
-	rows, err := tx.QueryContext(ctx, `
+func inspectAdminStory(ctx context.Context, tx pgx.Tx, story adminStoryRow) (inspectedAdminStory, error) {
+	rows, err := tx.Query(ctx, `
 		SELECT id, version, created_at
 		FROM story_versions
 		WHERE story_id = $1
@@ -248,16 +445,17 @@ func inspectAdminStory(ctx context.Context, tx *sql.Tx, story adminStoryRow) (in
 	for rows.Next() {
 		var version versionRow
 		if err := rows.Scan(&version.ID, &version.Version, &version.CreatedAt); err != nil {
-			_ = rows.Close()
+			rows.Close()
 			return inspectedAdminStory{}, err
 		}
 		versionRows = append(versionRows, version)
 	}
 	if err := rows.Err(); err != nil {
-		_ = rows.Close()
+		rows.Close()
 		return inspectedAdminStory{}, err
 	}
-	if err := rows.Close(); err != nil {
+	rows.Close()
+	if err := rows.Err(); err != nil {
 		return inspectedAdminStory{}, err
 	}
 
@@ -265,36 +463,11 @@ func inspectAdminStory(ctx context.Context, tx *sql.Tx, story adminStoryRow) (in
 	byID := make(map[string]inspectedAdminVersion, len(versionRows))
 	repairRequired := false
 	for _, version := range versionRows {
-		versionNumber := positiveVersion(version.Version)
-		inspected := inspectedAdminVersion{Summary: model.AdminVersionSummary{
-			VersionID:   version.ID,
-			Version:     versionNumber,
-			CreatedAt:   version.CreatedAt.UTC().Format(time.RFC3339Nano),
-			IsDraft:     equalOptionalID(story.DraftVersionID, version.ID),
-			IsPublished: story.IsPublished && equalOptionalID(story.PublishedVersionID, version.ID),
-			Health:      model.AdminVersionHealthRepairRequired,
-		}}
-		inspection, validationErr := inspectAdminVersion(ctx, tx, story.ID, version.ID)
-		switch {
-		case validationErr == nil:
-			inspected.Inspection = inspection
-			inspected.Summary.Version = inspection.Version
-			inspected.Summary.SegmentCount = inspection.SegmentCount
-			inspected.Summary.WordCount = inspection.WordCount
-			inspected.Summary.ChapterCount = inspection.ChapterCount
-			inspected.Summary.Health = model.AdminVersionHealthReady
-		case errors.Is(validationErr, errStoredVersionInvalid):
-			repairRequired = true
-		case errors.Is(validationErr, sql.ErrNoRows):
-			inspected.Summary.Health = model.AdminVersionHealthUnavailable
-			repairRequired = true
-		default:
-			return inspectedAdminStory{}, validationErr
-		}
-		if version.Version <= 0 || int64(versionNumber) != version.Version {
-			repairRequired = true
-			inspected.Summary.Health = model.AdminVersionHealthRepairRequired
+		inspected, repair, err := inspectAdminVersionSummary(ctx, tx, story, version.ID, version.Version, version.CreatedAt)
+		if err != nil {
+			return inspectedAdminStory{}, err
 		}
+		repairRequired = repairRequired || repair
 		versions = append(versions, inspected)
 		byID[version.ID] = inspected
 	}
@@ -324,6 +497,12 @@ func inspectAdminStory(ctx context.Context, tx *sql.Tx, story adminStoryRow) (in
 		language = metadata.Language
 		rights = cloneJSONMap(metadata.Rights)
 		sourceURL = cloneString(metadata.SourceURL)
+		if strings.TrimSpace(story.Title) != "" && story.Rights != nil {
+			title = story.Title
+			author = cloneString(story.Author)
+			language = story.Language
+			rights = cloneJSONMap(story.Rights)
+		}
 	}
 
 	publicVersions := make([]model.AdminVersionSummary, 0, len(versions))
@@ -343,6 +522,7 @@ func inspectAdminStory(ctx context.Context, tx *sql.Tx, story adminStoryRow) (in
 			PublishedVersion: publishedPointer,
 			DraftVersion:     draftPointer,
 			VersionCount:     len(versions),
+			Readability:      currentReadability(story, byID),
 			UpdatedAt:        story.UpdatedAt.UTC().Format(time.RFC3339Nano),
 		},
 		Versions: publicVersions,
@@ -363,12 +543,14 @@ func inspectAdminVersion(
 		renderedHTMLReadable sql.NullBool
 		contentHash          sql.NullString
 		computedContentHash  sql.NullString
+		readabilityJSON      sql.NullString
 	)
-	if err := queryer.QueryRowContext(ctx, `
+	if err := queryer.QueryRow(ctx, `
 		SELECT
 			version,
 			created_at,
 			frontmatter::text,
+			readability::text,
 			btrim(markdown) <> '',
 			btrim(rendered_html) <> '',
 			content_hash,
@@ -383,6 +565,7 @@ func inspectAdminVersion(
 		&version,
 		&createdAt,
 		&frontmatterJSON,
+		&readabilityJSON,
 		&markdownReadable,
 		&renderedHTMLReadable,
 		&contentHash,
@@ -405,7 +588,7 @@ func inspectAdminVersion(
 		return adminVersionInspection{}, fmt.Errorf("%w: immutable metadata", errStoredVersionInvalid)
 	}
 
-	rows, err := queryer.QueryContext(ctx, `
+	rows, err := queryer.Query(ctx, `
 		SELECT
 			ordinal,
 			segment_kind,
@@ -523,7 +706,11 @@ func inspectAdminVersion(
 	if len(identities) == 0 {
 		return adminVersionInspection{}, fmt.Errorf("%w: no readable segments", errStoredVersionInvalid)
 	}
-	chapterCount, err := readercontract.ValidateStoredSegmentIdentities(identities)
+	chapters, err := storyingest.ChapterRuleFromFrontmatter(frontmatter.Values)
+	if err != nil {
+		return adminVersionInspection{}, fmt.Errorf("%w: chapter rule", errStoredVersionInvalid)
+	}
+	chapterCount, err := readercontract.ValidateStoredSegmentIdentities(identities, chapters)
 	if err != nil {
 		return adminVersionInspection{}, fmt.Errorf("%w: segment identities", errStoredVersionInvalid)
 	}
@@ -535,9 +722,24 @@ func inspectAdminVersion(
 		SegmentCount: len(identities),
 		WordCount:    int(wordCount),
 		ChapterCount: chapterCount,
+		Readability:  decodeReadability(readabilityJSON),
 	}, nil
 }
 
+// currentReadability reports the version readers see, or the draft when the
+// story is not published.
+func currentReadability(story adminStoryRow, byID map[string]inspectedAdminVersion) *model.Readability {
+	for _, pointer := range []*string{story.PublishedVersionID, story.DraftVersionID} {
+		if pointer == nil {
+			continue
+		}
+		if version, ok := byID[*pointer]; ok {
+			return version.Summary.Readability
+		}
+	}
+	return nil
+}
+
 func adminStoryDetail(story inspectedAdminStory) model.AdminStoryDetailResponse {
 	summary := story.Summary
 	return model.AdminStoryDetailResponse{
@@ -551,6 +753,7 @@ func adminStoryDetail(story inspectedAdminStory) model.AdminStoryDetailResponse
 		PublishedVersion: summary.PublishedVersion,
 		DraftVersion:     summary.DraftVersion,
 		VersionCount:     summary.VersionCount,
+		Readability:      summary.Readability,
 		CreatedAt:        story.Row.CreatedAt.UTC().Format(time.RFC3339Nano),
 		UpdatedAt:        summary.UpdatedAt,
 		Versions:         story.Versions,
@@ -0,0 +1,148 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"pandapages/api/internal/model"
+	"pandapages/api/internal/storyingest"
+)
+
+var storyLanguageRe = regexp.MustCompile(`^[A-Za-z]{2,8}(?:-[A-Za-z0-9]{1,8})*$`)
+
+// AdminPatchStoryMetadata updates catalogue metadata on the story row without
+// re-ingesting content. Stored versions are untouched, so their frontmatter
+// still records the metadata each version was created with.
+func (s *Store) AdminPatchStoryMetadata(ctx context.Context, accountID, slug string, patch model.AdminStoryMetadataPatch) (model.AdminStoryMetadataResponse, error) {
+	accountID = strings.TrimSpace(accountID)
+	slug = strings.TrimSpace(slug)
+	if !accountIDRe.MatchString(accountID) || storyingest.ValidateSlug(slug) != nil {
+		return model.AdminStoryMetadataResponse{}, fmt.Errorf("%w", model.ErrAdminStoryNotFound)
+	}
+	if issues := adminMetadataIssues(patch); len(issues) > 0 {
+		return model.AdminStoryMetadataResponse{}, &model.AdminValidationError{Issues: issues}
+	}
+
+	var title, author, language, rights any
+	if patch.Title != nil {
+		title = strings.TrimSpace(*patch.Title)
+	}
+	if patch.Author != nil {
+		author = strings.TrimSpace(*patch.Author)
+	}
+	if patch.Language != nil {
+		language = strings.TrimSpace(*patch.Language)
+	}
+	if patch.Rights != nil {
+		encoded, err := json.Marshal(patch.Rights)
+		if err != nil {
+			return model.AdminStoryMetadataResponse{}, err
+		}
+		rights = string(encoded)
+	}
+	tags := make([]string, 0, len(patch.Tags))
+	for _, name := range patch.Tags {
+		value, ok := model.NormalizeAdminTagName(name)
+		if !ok {
+			return model.AdminStoryMetadataResponse{}, fmt.Errorf("tag name invalid")
+		}
+		tags = append(tags, value)
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+	tx, err := s.writes(ctx).Begin(ctx)
+	if err != nil {
+		return model.AdminStoryMetadataResponse{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	story, err := loadAdminStory(ctx, tx, accountID, slug, true)
+	if err != nil {
+		return model.AdminStoryMetadataResponse{}, err
+	}
+
+	var (
+		out        = model.AdminStoryMetadataResponse{Slug: story.Slug}
+		authorText sql.NullString
+		rightsJSON string
+		updatedAt  time.Time
+	)
+	if err := tx.QueryRow(ctx, `
+		UPDATE stories
+		SET title = COALESCE($2::text, title),
+		    author = CASE WHEN $3::text IS NULL THEN author ELSE NULLIF($3::text, '') END,
+		    language = COALESCE($4::text, language),
+		    rights = COALESCE($5::jsonb, rights),
+		    updated_at = now()
+		WHERE id = $1
+		RETURNING title, NULLIF(BTRIM(author), ''), language, rights::text, updated_at
+	`, story.ID, title, author, language, rights).Scan(&out.Title, &authorText, &out.Language, &rightsJSON, &updatedAt); err != nil {
+		return model.AdminStoryMetadataResponse{}, err
+	}
+	out.Author = nullStringValue(authorText)
+	out.UpdatedAt = updatedAt.UTC().Format(time.RFC3339Nano)
+	out.Rights = map[string]any{}
+	if decoded, ok := decodeJSONDocument([]byte(rightsJSON)); ok {
+		if value, ok := decoded.(map[string]any); ok {
+			out.Rights = value
+		}
+	}
+	if out.Author != nil && !equalStringPointers(out.Author, story.Author) {
+		linkStoryAuthor(ctx, tx, story.ID, *out.Author)
+	}
+
+	if patch.Tags != nil {
+		if _, err := tx.Exec(ctx, `
+			DELETE FROM story_tags st
+			USING tags t
+			WHERE st.story_id = $1
+			  AND st.tag_id = t.id
+			  AND lower(t.name) NOT IN (SELECT lower(name) FROM unnest($2::text[]) AS name)
+		`, story.ID, tags); err != nil {
+			return model.AdminStoryMetadataResponse{}, err
+		}
+		if len(tags) > 0 {
+			if err := linkStoryTags(ctx, tx, accountID, story.ID, tags); err != nil {
+				return model.AdminStoryMetadataResponse{}, err
+			}
+		}
+	}
+	if out.Tags, err = loadStoryTags(ctx, tx, story.ID); err != nil {
+		return model.AdminStoryMetadataResponse{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return model.AdminStoryMetadataResponse{}, err
+	}
+	return out, nil
+}
+
+func adminMetadataIssues(patch model.AdminStoryMetadataPatch) []model.AdminValidationIssue {
+	issues := []model.AdminValidationIssue{}
+	if patch.Title != nil {
+		if strings.TrimSpace(*patch.Title) == "" {
+			issues = append(issues, model.AdminValidationIssue{Field: "title", Code: "required", Message: "Enter a title"})
+		} else if !utf8.ValidString(*patch.Title) {
+			issues = append(issues, model.AdminValidationIssue{Field: "title", Code: "invalid_encoding", Message: "Enter valid text"})
+		}
+	}
+	if patch.Author != nil && !utf8.ValidString(*patch.Author) {
+		issues = append(issues, model.AdminValidationIssue{Field: "author", Code: "invalid_encoding", Message: "Enter valid text"})
+	}
+	if patch.Language != nil && !storyLanguageRe.MatchString(strings.TrimSpace(*patch.Language)) {
+		issues = append(issues, model.AdminValidationIssue{Field: "language", Code: "invalid", Message: "Use a language tag such as en-GB"})
+	}
+	if patch.Rights != nil {
+		if _, err := json.Marshal(patch.Rights); err != nil {
+			issues = append(issues, model.AdminValidationIssue{Field: "rights", Code: "invalid", Message: "Enter valid rights information"})
+		}
+	}
+	return issues
+}
@@ -0,0 +1,269 @@
+package db
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"pandapages/api/internal/model"
+	"pandapages/api/internal/readercontract"
+	"pandapages/api/internal/storyingest"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// maxRenamedSlugSuffix bounds the search for a free slug under new-slug.
+const maxRenamedSlugSuffix = 100
+
+// AdminRestoreMedia stores a backed-up image under its original ID, so story
+// Markdown that references it keeps working. It reports whether the image was
+// created; an identical image the account already holds is left alone.
+func (s *Store) AdminRestoreMedia(ctx context.Context, accountID string, media model.Media, data []byte) (bool, error) {
+	accountID = strings.TrimSpace(accountID)
+	mediaID := strings.ToLower(strings.TrimSpace(media.ID))
+	if !accountIDRe.MatchString(accountID) || !accountIDRe.MatchString(mediaID) {
+		return false, fmt.Errorf("%w", model.ErrMediaConflict)
+	}
+	if !model.ValidMediaContentType(media.ContentType) || len(data) == 0 {
+		return false, fmt.Errorf("media invalid")
+	}
+	sum := sha256.Sum256(data)
+	digest := hex.EncodeToString(sum[:])
+	if media.SHA256 != "" && media.SHA256 != digest {
+		return false, fmt.Errorf("media checksum mismatch")
+	}
+
+	stored, key, err := s.mediaBytes(ctx, accountID, media.ContentType, data)
+	if err != nil {
+		return false, err
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	tag, err := s.writes(ctx).Exec(ctx, `
+		INSERT INTO media (id, account_id, content_type, byte_size, sha256, data, blob_key)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (id) DO NOTHING
+	`, mediaID, accountID, media.ContentType, len(data), digest, stored, key)
+	if key != nil && (err != nil || tag.RowsAffected() == 0) {
+		s.dropBlob(ctx, *key)
+	}
+	if err != nil {
+		return false, err
+	}
+	if tag.RowsAffected() == 1 {
+		return true, nil
+	}
+
+	var ownerID, storedDigest string
+	if err := s.writes(ctx).QueryRow(ctx, `
+		SELECT account_id::text, sha256 FROM media WHERE id = $1
+	`, mediaID).Scan(&ownerID, &storedDigest); err != nil {
+		return false, err
+	}
+	if ownerID != accountID || storedDigest != digest {
+		return false, fmt.Errorf("%w", model.ErrMediaConflict)
+	}
+	return false, nil
+}
+
+// AdminRestoreStory imports one story bundle from a backup. A slug the account
+// already uses is skipped, overwritten or moved to the first free "-N" slug,
+// as conflict says. Overwriting deletes the existing story with its versions
+// and progress in the same transaction that imports the bundle.
+func (s *Store) AdminRestoreStory(ctx context.Context, accountID string, bundle model.StoryBundle, conflict model.RestoreConflict) (model.AdminRestoreStory, error) {
+	accountID = strings.TrimSpace(accountID)
+	if !accountIDRe.MatchString(accountID) {
+		return model.AdminRestoreStory{}, fmt.Errorf("account required")
+	}
+	if !conflict.Valid() {
+		return model.AdminRestoreStory{}, fmt.Errorf("restore conflict strategy invalid")
+	}
+	versions, err := prepareStoryBundle(bundle)
+	if err != nil {
+		return model.AdminRestoreStory{}, err
+	}
+	slug := strings.TrimSpace(bundle.Story.Slug)
+	out := model.AdminRestoreStory{Slug: slug, RestoredAs: slug, Outcome: model.RestoreOutcomeCreated}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+	tx, err := s.writes(ctx).Begin(ctx)
+	if err != nil {
+		return model.AdminRestoreStory{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var existingID string
+	err = tx.QueryRow(ctx, `
+		SELECT id FROM stories WHERE account_id = $1 AND slug = $2 FOR UPDATE
+	`, accountID, slug).Scan(&existingID)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+	case err != nil:
+		return model.AdminRestoreStory{}, err
+	case conflict == model.RestoreConflictSkip:
+		return model.AdminRestoreStory{Slug: slug, Outcome: model.RestoreOutcomeSkipped}, nil
+	case conflict == model.RestoreConflictOverwrite:
+		if _, err := tx.Exec(ctx, `DELETE FROM stories WHERE id = $1`, existingID); err != nil {
+			return model.AdminRestoreStory{}, err
+		}
+		out.Outcome = model.RestoreOutcomeOverwritten
+	case conflict == model.RestoreConflictNewSlug:
+		renamed, err := freeStorySlug(ctx, tx, accountID, slug)
+		if err != nil {
+			return model.AdminRestoreStory{}, err
+		}
+		bundle.Story.Slug = renamed
+		out.RestoredAs = renamed
+		out.Outcome = model.RestoreOutcomeRenamed
+	}
+
+	if _, err := importStoryBundle(ctx, tx, accountID, bundle, versions); err != nil {
+		return model.AdminRestoreStory{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return model.AdminRestoreStory{}, err
+	}
+	return out, nil
+}
+
+// freeStorySlug finds the first of slug-2, slug-3, ... the account does not
+// use. A slug is never renamed into one that would fail validation.
+func freeStorySlug(ctx context.Context, tx pgx.Tx, accountID, slug string) (string, error) {
+	for n := 2; n <= maxRenamedSlugSuffix; n++ {
+		candidate := slug + "-" + strconv.Itoa(n)
+		if storyingest.ValidateSlug(candidate) != nil {
+			break
+		}
+		var taken bool
+		if err := tx.QueryRow(ctx, `
+			SELECT EXISTS (SELECT 1 FROM stories WHERE account_id = $1 AND slug = $2)
+		`, accountID, candidate).Scan(&taken); err != nil {
+			return "", err
+		}
+		if !taken {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("%w", model.ErrAdminStoryConflict)
+}
+
+// AdminRestoreSettings applies backed-up reader settings and library policies.
+// Unless overwrite is set, an account that already has a child profile keeps
+// all of its settings. Profile IDs from the backup are not reused.
+func (s *Store) AdminRestoreSettings(ctx context.Context, accountID string, settings model.BackupSettings, overwrite bool) (bool, error) {
+	if !overwrite {
+		current, err := s.SettingsGet(ctx, accountID)
+		if err != nil {
+			return false, err
+		}
+		if current.Child.ID != "" {
+			return false, nil
+		}
+	}
+	if keep := settings.KeepVersions; keep != nil && (*keep < model.MinVersionRetention || *keep > model.MaxVersionRetention) {
+		return false, fmt.Errorf("version retention out of range")
+	}
+
+	upsert := model.SettingsUpsert{Child: settings.Reader.Child, Prompt: settings.Reader.Prompt}
+	upsert.Child.ID, upsert.Prompt.ID = "", ""
+	if string(upsert.Prompt.Rules) == "null" {
+		upsert.Prompt.Rules = nil
+	}
+	if _, err := s.SettingsPut(ctx, accountID, upsert); err != nil {
+		return false, err
+	}
+	if _, err := s.AdminSetHyphenation(ctx, accountID, settings.Hyphenation); err != nil {
+		return false, err
+	}
+	if _, err := s.AdminSetVersionRetention(ctx, accountID, settings.KeepVersions); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// AdminRestoreProgress puts the reader back at a backed-up place in a story.
+// It reports false, changing nothing, when the story no longer has that
+// version, the locator does not match it, or newer progress is stored.
+func (s *Store) AdminRestoreProgress(ctx context.Context, accountID string, progress model.BackupProgress) (bool, error) {
+	if err := progress.Locator.Validate(); err != nil {
+		return false, nil
+	}
+	percent := progress.Percent
+	if math.IsNaN(percent) || math.IsInf(percent, 0) || percent < 0 || percent > 1 {
+		return false, nil
+	}
+	updatedAt, err := time.Parse(time.RFC3339Nano, progress.UpdatedAt)
+	if err != nil {
+		return false, nil
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	profileID, err := s.getDefaultProfileID(ctx, accountID)
+	if err != nil {
+		return false, err
+	}
+	tx, err := s.writes(ctx).Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var storyID, versionID string
+	err = tx.QueryRow(ctx, `
+		SELECT story.id, version.id
+		FROM stories AS story
+		JOIN story_versions AS version ON version.story_id = story.id
+		WHERE story.account_id = $1
+		  AND story.slug = $2
+		  AND version.version = $3
+		FOR SHARE OF story
+	`, accountID, progress.Slug, progress.Version).Scan(&storyID, &versionID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if err := matchLocatorSegment(ctx, tx, versionID, progress.Locator); err != nil {
+		if errors.Is(err, readercontract.ErrLocatorMismatch) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	locatorJSON, err := json.Marshal(progress.Locator)
+	if err != nil {
+		return false, err
+	}
+	tag, err := tx.Exec(ctx, `
+		INSERT INTO reading_progress (profile_id, story_id, story_version_id, locator, percent, updated_at)
+		VALUES ($1,$2,$3,$4,$5,$6)
+		ON CONFLICT (profile_id, story_id)
+		DO UPDATE SET
+			story_version_id=EXCLUDED.story_version_id,
+			locator=EXCLUDED.locator,
+			percent=EXCLUDED.percent,
+			updated_at=EXCLUDED.updated_at
+		WHERE reading_progress.updated_at < EXCLUDED.updated_at
+	`, profileID, storyID, versionID, locatorJSON, percent, updatedAt)
+	if err != nil {
+		return false, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
@@ -0,0 +1,215 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"pandapages/api/internal/model"
+	"pandapages/api/internal/storyingest"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func (s *Store) AdminGetVersionRetention(ctx context.Context, accountID string) (model.AdminVersionRetention, error) {
+	accountID = strings.TrimSpace(accountID)
+	if !accountIDRe.MatchString(accountID) {
+		return model.AdminVersionRetention{}, fmt.Errorf("account required")
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	var keep sql.NullInt64
+	if err := s.db.QueryRow(ctx, `
+		SELECT version_retention FROM accounts WHERE id = $1
+	`, accountID).Scan(&keep); err != nil {
+		return model.AdminVersionRetention{}, err
+	}
+	return model.AdminVersionRetention{Keep: nullIntValue(keep)}, nil
+}
+
+// AdminSetVersionRetention sets or, with a nil keep, clears the account
+// policy. Existing versions are pruned by the next draft or prune request.
+func (s *Store) AdminSetVersionRetention(ctx context.Context, accountID string, keep *int) (model.AdminVersionRetention, error) {
+	accountID = strings.TrimSpace(accountID)
+	if !accountIDRe.MatchString(accountID) {
+		return model.AdminVersionRetention{}, fmt.Errorf("account required")
+	}
+	var value any
+	if keep != nil {
+		if *keep < model.MinVersionRetention || *keep > model.MaxVersionRetention {
+			return model.AdminVersionRetention{}, fmt.Errorf("version retention out of range")
+		}
+		value = *keep
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	var stored sql.NullInt64
+	if err := s.writes(ctx).QueryRow(ctx, `
+		UPDATE accounts
+		SET version_retention = $2::integer,
+		    updated_at = now()
+		WHERE id = $1
+		RETURNING version_retention
+	`, accountID, value).Scan(&stored); err != nil {
+		return model.AdminVersionRetention{}, err
+	}
+	return model.AdminVersionRetention{Keep: nullIntValue(stored)}, nil
+}
+
+// AdminPruneVersions deletes a story's older versions beyond keep; a zero
+// keep uses the account policy. Sections and segments cascade with their
+// version. With dryRun nothing is deleted and the response lists what would be.
+func (s *Store) AdminPruneVersions(ctx context.Context, accountID, slug string, keep int, dryRun bool) (model.AdminPruneVersionsResponse, error) {
+	accountID = strings.TrimSpace(accountID)
+	slug = strings.TrimSpace(slug)
+	if !accountIDRe.MatchString(accountID) || storyingest.ValidateSlug(slug) != nil {
+		return model.AdminPruneVersionsResponse{}, fmt.Errorf("%w", model.ErrAdminStoryNotFound)
+	}
+	if keep != 0 && (keep < model.MinVersionRetention || keep > model.MaxVersionRetention) {
+		return model.AdminPruneVersionsResponse{}, fmt.Errorf("version retention out of range")
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+	tx, err := s.writes(ctx).Begin(ctx)
+	if err != nil {
+		return model.AdminPruneVersionsResponse{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	story, err := loadAdminStory(ctx, tx, accountID, slug, true)
+	if err != nil {
+		return model.AdminPruneVersionsResponse{}, err
+	}
+	if keep == 0 {
+		policy, err := accountVersionRetention(ctx, tx, accountID)
+		if err != nil {
+			return model.AdminPruneVersionsResponse{}, err
+		}
+		if policy == nil {
+			return model.AdminPruneVersionsResponse{}, fmt.Errorf("%w", model.ErrAdminRetentionUnset)
+		}
+		keep = *policy
+	}
+
+	pruned, remaining, err := pruneStoryVersions(ctx, tx, story.ID, keep, dryRun)
+	if err != nil {
+		return model.AdminPruneVersionsResponse{}, err
+	}
+	if !dryRun {
+		if err := tx.Commit(ctx); err != nil {
+			return model.AdminPruneVersionsResponse{}, err
+		}
+	}
+	return model.AdminPruneVersionsResponse{
+		Slug:           story.Slug,
+		Keep:           keep,
+		DryRun:         dryRun,
+		PrunedVersions: pruned,
+		VersionCount:   remaining,
+	}, nil
+}
+
+func accountVersionRetention(ctx context.Context, tx pgx.Tx, accountID string) (*int, error) {
+	var keep sql.NullInt64
+	err := tx.QueryRow(ctx, `
+		SELECT version_retention FROM accounts WHERE id = $1
+	`, accountID).Scan(&keep)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return nullIntValue(keep), nil
+}
+
+type retainedVersion struct {
+	ID        string
+	Version   int
+	Protected bool
+}
+
+// pruneStoryVersions deletes the story's versions that fall outside the
+// newest keep and are not protected, returning the pruned version numbers
+// and how many versions remain. The caller holds the story row lock.
+func pruneStoryVersions(ctx context.Context, tx pgx.Tx, storyID string, keep int, dryRun bool) ([]int, int, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT
+			version.id,
+			version.version,
+			(version.id = story.draft_version_id
+			 OR version.id = story.published_version_id
+			 OR EXISTS (
+				SELECT 1 FROM reading_progress progress
+				WHERE progress.story_version_id = version.id
+			 )) AS protected
+		FROM story_versions version
+		JOIN stories story ON story.id = version.story_id
+		WHERE version.story_id = $1
+	`, storyID)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+	versions := []retainedVersion{}
+	for rows.Next() {
+		var (
+			version   retainedVersion
+			protected sql.NullBool
+		)
+		if err := rows.Scan(&version.ID, &version.Version, &protected); err != nil {
+			return nil, 0, err
+		}
+		version.Protected = protected.Valid && protected.Bool
+		versions = append(versions, version)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	doomed := versionsToPrune(versions, keep)
+	pruned := make([]int, 0, len(doomed))
+	ids := make([]string, 0, len(doomed))
+	for _, version := range doomed {
+		pruned = append(pruned, version.Version)
+		ids = append(ids, version.ID)
+	}
+	if len(ids) > 0 && !dryRun {
+		if _, err := tx.Exec(ctx, `
+			DELETE FROM story_versions
+			WHERE story_id = $1
+			  AND id = ANY($2::uuid[])
+		`, storyID, ids); err != nil {
+			return nil, 0, err
+		}
+	}
+	return pruned, len(versions) - len(doomed), nil
+}
+
+// versionsToPrune keeps the newest keep versions by number and every
+// protected version, and returns the rest oldest first.
+func versionsToPrune(versions []retainedVersion, keep int) []retainedVersion {
+	ordered := append([]retainedVersion(nil), versions...)
+	sort.Slice(ordered, func(i, j int) bool { return ordered[i].Version > ordered[j].Version })
+	doomed := []retainedVersion{}
+	for index, version := range ordered {
+		if index < keep || version.Protected {
+			continue
+		}
+		doomed = append(doomed, version)
+	}
+	sort.Slice(doomed, func(i, j int) bool { return doomed[i].Version < doomed[j].Version })
+	return doomed
+}
@@ -0,0 +1,127 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"pandapages/api/internal/model"
+	"pandapages/api/internal/sensitivity"
+	"pandapages/api/internal/storyingest"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// AdminSensitivityReport scans one version of a story. An empty versionID
+// selects the draft version, falling back to the published one. words is the
+// deployment-wide list; the account's active child-profile sensitivities are
+// always included.
+func (s *Store) AdminSensitivityReport(ctx context.Context, accountID, slug, versionID string, words []string) (model.AdminSensitivityReport, error) {
+	accountID = strings.TrimSpace(accountID)
+	slug = strings.TrimSpace(slug)
+	versionID = strings.TrimSpace(versionID)
+	if !accountIDRe.MatchString(accountID) || storyingest.ValidateSlug(slug) != nil {
+		return model.AdminSensitivityReport{}, fmt.Errorf("%w", model.ErrAdminStoryNotFound)
+	}
+	if versionID != "" && !accountIDRe.MatchString(versionID) {
+		return model.AdminSensitivityReport{}, fmt.Errorf("%w", model.ErrAdminStoryNotFound)
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return model.AdminSensitivityReport{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	report := model.AdminSensitivityReport{Matches: []model.AdminSensitivityMatch{}}
+	err = tx.QueryRow(ctx, `
+		SELECT st.slug, version.id, version.version
+		FROM stories st
+		JOIN story_versions version
+		  ON version.story_id = st.id
+		 AND version.id = COALESCE(NULLIF($3, '')::uuid, st.draft_version_id, st.published_version_id)
+		WHERE st.account_id = $1
+		  AND st.slug = $2
+	`, accountID, slug, versionID).Scan(&report.Slug, &report.VersionID, &report.Version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.AdminSensitivityReport{}, fmt.Errorf("%w", model.ErrAdminStoryNotFound)
+	}
+	if err != nil {
+		return model.AdminSensitivityReport{}, err
+	}
+
+	terms := make([]sensitivity.Term, 0, len(words)+8)
+	rows, err := tx.Query(ctx, `
+		SELECT DISTINCT term
+		FROM profile_settings ps
+		JOIN profiles p
+		  ON p.id = ps.profile_id
+		 AND p.account_id = $1
+		JOIN child_profiles cp
+		  ON cp.id = ps.active_child_profile_id
+		 AND cp.account_id = $1
+		CROSS JOIN LATERAL jsonb_array_elements_text(
+			CASE WHEN jsonb_typeof(cp.sensitivities) = 'array' THEN cp.sensitivities ELSE '[]'::jsonb END
+		) AS term
+		ORDER BY term
+	`, accountID)
+	if err != nil {
+		return model.AdminSensitivityReport{}, err
+	}
+	for rows.Next() {
+		var term string
+		if err := rows.Scan(&term); err != nil {
+			rows.Close()
+			return model.AdminSensitivityReport{}, err
+		}
+		terms = append(terms, sensitivity.Term{Text: term, Source: sensitivity.SourceChildProfile})
+	}
+	if err := rows.Err(); err != nil {
+		return model.AdminSensitivityReport{}, err
+	}
+	for _, word := range words {
+		terms = append(terms, sensitivity.Term{Text: word, Source: sensitivity.SourceWordList})
+	}
+	terms = sensitivity.NormalizeTerms(terms)
+	report.Terms = len(terms)
+
+	rows, err = tx.Query(ctx, `
+		SELECT ordinal, content_key, rendered_html
+		FROM story_segments
+		WHERE story_version_id = $1
+		ORDER BY ordinal
+	`, report.VersionID)
+	if err != nil {
+		return model.AdminSensitivityReport{}, err
+	}
+	segments := make([]sensitivity.Segment, 0, 64)
+	for rows.Next() {
+		var segment sensitivity.Segment
+		if err := rows.Scan(&segment.Ordinal, &segment.ContentKey, &segment.RenderedHTML); err != nil {
+			rows.Close()
+			return model.AdminSensitivityReport{}, err
+		}
+		segments = append(segments, segment)
+	}
+	if err := rows.Err(); err != nil {
+		return model.AdminSensitivityReport{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return model.AdminSensitivityReport{}, err
+	}
+
+	for _, match := range sensitivity.Scan(segments, terms) {
+		report.Matches = append(report.Matches, model.AdminSensitivityMatch{
+			Term:    match.Term,
+			Source:  match.Source,
+			Segment: model.AdminLintLocator{Ordinal: match.Ordinal, ContentKey: match.ContentKey},
+			Count:   match.Count,
+		})
+	}
+	report.Flagged = len(report.Matches) > 0
+	return report, nil
+}
@@ -0,0 +1,352 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"pandapages/api/internal/model"
+	"pandapages/api/internal/storyingest"
+)
+
+const adminTagColumns = `
+	t.id, t.name, t.created_at,
+	(SELECT count(*) FROM story_tags st WHERE st.tag_id = t.id)
+`
+
+const (
+	defaultAdminTagsPageSize = 100
+	maxAdminTagsPageSize     = 500
+)
+
+func (s *Store) AdminListTags(ctx context.Context, accountID string, page model.PageRequest) (model.AdminTagsListResponse, error) {
+	accountID = strings.TrimSpace(accountID)
+	if !accountIDRe.MatchString(accountID) {
+		return model.AdminTagsListResponse{}, fmt.Errorf("account required")
+	}
+	limit := pageSize(page.Limit, defaultAdminTagsPageSize, maxAdminTagsPageSize)
+	var afterName, afterID string
+	after, err := decodeCursor(page.Cursor, &afterName, &afterID)
+	if err != nil {
+		return model.AdminTagsListResponse{}, err
+	}
+	cursorArgs := []any{nil, nil}
+	if after {
+		if !accountIDRe.MatchString(afterID) {
+			return model.AdminTagsListResponse{}, model.ErrInvalidCursor
+		}
+		cursorArgs = []any{afterName, afterID}
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	rows, err := s.db.Query(ctx, `
+		SELECT `+adminTagColumns+`
+		FROM tags t
+		WHERE t.account_id = $1
+		  AND ($2::text IS NULL OR (lower(t.name), t.id) > (lower($2::text), $3::uuid))
+		ORDER BY lower(t.name), t.id
+		LIMIT $4
+	`, accountID, cursorArgs[0], cursorArgs[1], limit+1)
+	if err != nil {
+		return model.AdminTagsListResponse{}, err
+	}
+	items, err := scanAdminTags(rows)
+	if err != nil {
+		return model.AdminTagsListResponse{}, err
+	}
+	items, more := trimPage(items, limit)
+	total, err := pageTotal(ctx, s.db, page, `SELECT count(*) FROM tags WHERE account_id = $1`, accountID)
+	if err != nil {
+		return model.AdminTagsListResponse{}, err
+	}
+	out := model.AdminTagsListResponse{Items: items, Total: total}
+	if more {
+		last := items[len(items)-1]
+		out.NextCursor = encodeCursor(last.Name, last.ID)
+	}
+	return out, nil
+}
+
+func (s *Store) AdminCreateTag(ctx context.Context, accountID string, name string) (model.AdminTag, error) {
+	accountID = strings.TrimSpace(accountID)
+	if !accountIDRe.MatchString(accountID) {
+		return model.AdminTag{}, fmt.Errorf("account required")
+	}
+	name, ok := model.NormalizeAdminTagName(name)
+	if !ok {
+		return model.AdminTag{}, fmt.Errorf("tag name invalid")
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	tag, err := scanAdminTag(s.writes(ctx).QueryRow(ctx, `
+		INSERT INTO tags AS t (account_id, name)
+		VALUES ($1, $2)
+		RETURNING `+adminTagColumns, accountID, name))
+	if isUniqueViolation(err) {
+		return model.AdminTag{}, fmt.Errorf("%w", model.ErrAdminTagConflict)
+	}
+	return tag, err
+}
+
+func (s *Store) AdminRenameTag(ctx context.Context, accountID string, tagID string, name string) (model.AdminTag, error) {
+	accountID = strings.TrimSpace(accountID)
+	if !accountIDRe.MatchString(accountID) {
+		return model.AdminTag{}, fmt.Errorf("account required")
+	}
+	tagID = strings.TrimSpace(tagID)
+	if !accountIDRe.MatchString(tagID) {
+		return model.AdminTag{}, fmt.Errorf("%w", model.ErrAdminTagNotFound)
+	}
+	name, ok := model.NormalizeAdminTagName(name)
+	if !ok {
+		return model.AdminTag{}, fmt.Errorf("tag name invalid")
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	tag, err := scanAdminTag(s.writes(ctx).QueryRow(ctx, `
+		UPDATE tags AS t
+		SET name = $3
+		WHERE t.account_id = $1 AND t.id = $2
+		RETURNING `+adminTagColumns, accountID, tagID, name))
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.AdminTag{}, fmt.Errorf("%w", model.ErrAdminTagNotFound)
+	}
+	if isUniqueViolation(err) {
+		return model.AdminTag{}, fmt.Errorf("%w", model.ErrAdminTagConflict)
+	}
+	return tag, err
+}
+
+// AdminMergeTags moves every story link from source to target and removes the
+// source tag. Stories already carrying both keep a single link to target.
+func (s *Store) AdminMergeTags(ctx context.Context, accountID string, sourceID string, targetID string) (model.AdminTag, error) {
+	accountID = strings.TrimSpace(accountID)
+	if !accountIDRe.MatchString(accountID) {
+		return model.AdminTag{}, fmt.Errorf("account required")
+	}
+	sourceID = strings.TrimSpace(sourceID)
+	targetID = strings.TrimSpace(targetID)
+	if !accountIDRe.MatchString(sourceID) || !accountIDRe.MatchString(targetID) || strings.EqualFold(sourceID, targetID) {
+		return model.AdminTag{}, fmt.Errorf("%w", model.ErrAdminTagNotFound)
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+	tx, err := s.writes(ctx).Begin(ctx)
+	if err != nil {
+		return model.AdminTag{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var found int
+	if err := tx.QueryRow(ctx, `
+		SELECT count(*)
+		FROM (
+			SELECT id FROM tags
+			WHERE account_id = $1 AND id IN ($2, $3)
+			ORDER BY id
+			FOR UPDATE
+		) locked
+	`, accountID, sourceID, targetID).Scan(&found); err != nil {
+		return model.AdminTag{}, err
+	}
+	if found != 2 {
+		return model.AdminTag{}, fmt.Errorf("%w", model.ErrAdminTagNotFound)
+	}
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO story_tags (story_id, tag_id)
+		SELECT story_id, $2::uuid
+		FROM story_tags
+		WHERE tag_id = $1
+		ON CONFLICT DO NOTHING
+	`, sourceID, targetID); err != nil {
+		return model.AdminTag{}, err
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM tags WHERE id = $1`, sourceID); err != nil {
+		return model.AdminTag{}, err
+	}
+	tag, err := scanAdminTag(tx.QueryRow(ctx, `
+		SELECT `+adminTagColumns+`
+		FROM tags t
+		WHERE t.id = $1
+	`, targetID))
+	if err != nil {
+		return model.AdminTag{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return model.AdminTag{}, err
+	}
+	return tag, nil
+}
+
+func (s *Store) AdminDeleteTag(ctx context.Context, accountID string, tagID string) error {
+	accountID = strings.TrimSpace(accountID)
+	if !accountIDRe.MatchString(accountID) {
+		return fmt.Errorf("account required")
+	}
+	tagID = strings.TrimSpace(tagID)
+	if !accountIDRe.MatchString(tagID) {
+		return fmt.Errorf("%w", model.ErrAdminTagNotFound)
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	res, err := s.writes(ctx).Exec(ctx, `DELETE FROM tags WHERE account_id = $1 AND id = $2`, accountID, tagID)
+	if err != nil {
+		return err
+	}
+	n := res.RowsAffected()
+	if n == 0 {
+		return fmt.Errorf("%w", model.ErrAdminTagNotFound)
+	}
+	return nil
+}
+
+// AdminAddStoryTags links the named tags to a story, creating any tag that
+// does not exist yet. Names already linked are left as they are.
+func (s *Store) AdminAddStoryTags(ctx context.Context, accountID string, slug string, names []string) (model.AdminStoryTagsResponse, error) {
+	return s.changeStoryTags(ctx, accountID, slug, names, true)
+}
+
+// AdminRemoveStoryTags unlinks the named tags from a story. Tags themselves
+// are kept even when no story uses them any more.
+func (s *Store) AdminRemoveStoryTags(ctx context.Context, accountID string, slug string, names []string) (model.AdminStoryTagsResponse, error) {
+	return s.changeStoryTags(ctx, accountID, slug, names, false)
+}
+
+func (s *Store) changeStoryTags(ctx context.Context, accountID, slug string, names []string, add bool) (model.AdminStoryTagsResponse, error) {
+	accountID = strings.TrimSpace(accountID)
+	slug = strings.TrimSpace(slug)
+	if !accountIDRe.MatchString(accountID) || storyingest.ValidateSlug(slug) != nil {
+		return model.AdminStoryTagsResponse{}, fmt.Errorf("%w", model.ErrAdminStoryNotFound)
+	}
+	normalized := make([]string, 0, len(names))
+	for _, name := range names {
+		value, ok := model.NormalizeAdminTagName(name)
+		if !ok {
+			return model.AdminStoryTagsResponse{}, fmt.Errorf("tag name invalid")
+		}
+		normalized = append(normalized, value)
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+	tx, err := s.writes(ctx).Begin(ctx)
+	if err != nil {
+		return model.AdminStoryTagsResponse{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	story, err := loadAdminStory(ctx, tx, accountID, slug, true)
+	if err != nil {
+		return model.AdminStoryTagsResponse{}, err
+	}
+
+	if add {
+		if err := linkStoryTags(ctx, tx, accountID, story.ID, normalized); err != nil {
+			return model.AdminStoryTagsResponse{}, err
+		}
+	} else {
+		if _, err := tx.Exec(ctx, `
+			DELETE FROM story_tags st
+			USING tags t
+			WHERE st.story_id = $1
+			  AND st.tag_id = t.id
+			  AND t.account_id = $2
+			  AND lower(t.name) IN (SELECT lower(name) FROM unnest($3::text[]) AS name)
+		`, story.ID, accountID, normalized); err != nil {
+			return model.AdminStoryTagsResponse{}, err
+		}
+	}
+
+	tags, err := loadStoryTags(ctx, tx, story.ID)
+	if err != nil {
+		return model.AdminStoryTagsResponse{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return model.AdminStoryTagsResponse{}, err
+	}
+	return model.AdminStoryTagsResponse{Slug: story.Slug, Tags: tags}, nil
+}
+
+// linkStoryTags links the named tags to a story, creating missing tags in the
+// account. Names must already be normalised.
+func linkStoryTags(ctx context.Context, tx pgx.Tx, accountID, storyID string, names []string) error {
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO tags (account_id, name)
+		SELECT $1::uuid, name FROM unnest($2::text[]) AS name
+		ON CONFLICT (account_id, lower(name)) DO NOTHING
+	`, accountID, names); err != nil {
+		return err
+	}
+	_, err := tx.Exec(ctx, `
+		INSERT INTO story_tags (story_id, tag_id)
+		SELECT $1::uuid, t.id
+		FROM tags t
+		WHERE t.account_id = $2
+		  AND lower(t.name) IN (SELECT lower(name) FROM unnest($3::text[]) AS name)
+		ON CONFLICT DO NOTHING
+	`, storyID, accountID, names)
+	return err
+}
+
+func loadStoryTags(ctx context.Context, tx pgx.Tx, storyID string) ([]model.AdminTag, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT `+adminTagColumns+`
+		FROM story_tags link
+		JOIN tags t ON t.id = link.tag_id
+		WHERE link.story_id = $1
+		ORDER BY lower(t.name), t.id
+	`, storyID)
+	if err != nil {
+		return nil, err
+	}
+	return scanAdminTags(rows)
+}
+
+func scanAdminTags(rows pgx.Rows) ([]model.AdminTag, error) {
+	defer rows.Close()
+	items := []model.AdminTag{}
+	for rows.Next() {
+		tag, err := scanAdminTag(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, tag)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func scanAdminTag(row rowScanner) (model.AdminTag, error) {
+	var (
+		tag       model.AdminTag
+		createdAt time.Time
+	)
+	if err := row.Scan(&tag.ID, &tag.Name, &createdAt, &tag.StoryCount); err != nil {
+		return model.AdminTag{}, err
+	}
+	tag.CreatedAt = createdAt.UTC().Format(time.RFC3339Nano)
+	return tag, nil
+}
+
+func isUniqueViolation(err error) bool {
+	var postgresError *pgconn.PgError
+	return errors.As(err, &postgresError) && postgresError.Code == "23505"
+}
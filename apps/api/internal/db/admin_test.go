@@ -1,6 +1,11 @@
 package db
 
-import "testing"
+import (
+	"strings"
+	"testing"
+
+	"pandapages/api/internal/storyingest"
+)
 
 func TestJSONDocumentsEqualComparesNumbersSemanticallyWithoutLosingPrecision(t *testing.T) {
 	tests := []struct {
@@ -36,3 +41,39 @@ func TestJSONDocumentsEqualComparesNumbersSemanticallyWithoutLosingPrecision(t *
 		})
 	}
 }
+
+func TestMediaIssuesReportOnlyMissingMedia(t *testing.T) {
+	owned := "0b0c7f0e-8b7a-4f55-9a43-a0a1b3b1c2d3"
+	missing := "9f8e7d6c-5b4a-4321-8fed-cba987654321"
+	issues := mediaIssues([]string{owned, missing}, map[string]bool{owned: true})
+	if len(issues) != 1 || issues[0].Code != "media_not_found" || issues[0].Message != "Upload image media:"+missing+" or remove it" {
+		t.Fatalf("issues = %#v", issues)
+	}
+}
+
+func TestFreshSegmentsFillEveryColumn(t *testing.T) {
+	fresh := freshSegments{versionID: "version"}
+	seg := storyingest.Segment{
+		Ordinal:        1,
+		Kind:           "paragraph",
+		Markdown:       "Hello.",
+		Hash:           "hash",
+		Pronunciations: []storyingest.Pronunciation{{Text: "Hermione", Respelling: "her-MY-oh-nee"}},
+	}
+	if err := fresh.add(nil, seg); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	if len(fresh.rows) != 1 || len(fresh.rows[0]) != len(segmentColumns) {
+		t.Fatalf("rows = %#v", fresh.rows)
+	}
+	row := fresh.rows[0]
+	if row[0] != "version" || row[3] != "paragraph" || row[9] != "Hello." {
+		t.Fatalf("row = %#v", row)
+	}
+	if hash, ok := row[13].(*string); !ok || hash == nil || *hash != "hash" {
+		t.Fatalf("content hash = %#v", row[13])
+	}
+	if hints, ok := row[14].(*string); !ok || hints == nil || !strings.Contains(*hints, "her-MY-oh-nee") {
+		t.Fatalf("pronunciations = %#v", row[14])
+	}
+}
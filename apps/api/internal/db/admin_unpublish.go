@@ -1,38 +1,46 @@
 package db
 
 import (
+	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"pandapages/api/internal/model"
 	"pandapages/api/internal/storyingest"
+	"pandapages/api/internal/webhooks"
 )
 
 // AdminUnpublish atomically removes only the public pointer. Immutable
 // versions, the draft pointer, and reading progress remain untouched.
-func (s *Store) AdminUnpublish(accountID, slug string) (model.AdminStoryStatusResponse, error) {
+func (s *Store) AdminUnpublish(ctx context.Context, accountID, slug string) (model.AdminStoryStatusResponse, error) {
 	accountID = strings.TrimSpace(accountID)
 	slug = strings.TrimSpace(slug)
 	if !accountIDRe.MatchString(accountID) || storyingest.ValidateSlug(slug) != nil {
 		return model.AdminStoryStatusResponse{}, fmt.Errorf("%w", model.ErrAdminStoryNotFound)
 	}
 
-	ctx, cancel := s.ctx()
+	ctx, cancel := s.ctx(ctx)
 	defer cancel()
-	tx, err := s.db.BeginTx(ctx, nil)
+	tx, err := s.writes(ctx).Begin(ctx)
 	if err != nil {
 		return model.AdminStoryStatusResponse{}, err
 	}
-	defer func() { _ = tx.Rollback() }()
+	defer func() { _ = tx.Rollback(ctx) }()
 
 	story, err := loadAdminStory(ctx, tx, accountID, slug, true)
 	if err != nil {
 		return model.AdminStoryStatusResponse{}, err
 	}
-	if err := tx.QueryRowContext(ctx, `
+	wasPublished := story.IsPublished || story.PublishedVersionID != nil
+	if err := tx.QueryRow(ctx, `
 		UPDATE stories
 		SET published_version_id = NULL,
 		    is_published = false,
+		    unpublished_at = CASE
+		      WHEN published_version_id IS NOT NULL OR is_published THEN now()
+		      ELSE unpublished_at
+		    END,
 		    updated_at = CASE
 		      WHEN published_version_id IS NOT NULL OR is_published THEN now()
 		      ELSE updated_at
@@ -49,8 +57,18 @@ func (s *Store) AdminUnpublish(accountID, slug string) (model.AdminStoryStatusRe
 	if err != nil {
 		return model.AdminStoryStatusResponse{}, err
 	}
-	if err := tx.Commit(); err != nil {
+	status := adminStoryStatusResponse(inspected)
+	if wasPublished {
+		if err := enqueueWebhookEvent(ctx, tx, accountID, webhooks.StoryPayload(model.WebhookEventStoryUnpublished, time.Now(), status)); err != nil {
+			return model.AdminStoryStatusResponse{}, err
+		}
+	}
+	if err := notifyStoryChanged(ctx, tx, accountID, slug); err != nil {
+		return model.AdminStoryStatusResponse{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
 		return model.AdminStoryStatusResponse{}, err
 	}
-	return adminStoryStatusResponse(inspected), nil
+	s.readerCache.forget(accountID, slug)
+	return status, nil
 }
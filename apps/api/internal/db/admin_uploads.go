@@ -0,0 +1,261 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"pandapages/api/internal/model"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// adminUploadTTL bounds how long an unfinished upload keeps its chunks.
+const adminUploadTTL = 24 * time.Hour
+
+var uploadSHA256Re = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+const adminUploadColumns = `id, kind, total_bytes, received_bytes, sha256, created_at, expires_at`
+
+func (s *Store) AdminCreateUpload(ctx context.Context, accountID string, req model.AdminUploadCreate) (model.AdminUpload, error) {
+	accountID = strings.TrimSpace(accountID)
+	if !accountIDRe.MatchString(accountID) {
+		return model.AdminUpload{}, fmt.Errorf("account required")
+	}
+	if !req.Kind.Valid() || req.TotalBytes <= 0 {
+		return model.AdminUpload{}, fmt.Errorf("upload invalid")
+	}
+	var checksum any
+	if req.SHA256 != "" {
+		if !uploadSHA256Re.MatchString(req.SHA256) {
+			return model.AdminUpload{}, fmt.Errorf("upload checksum invalid")
+		}
+		checksum = req.SHA256
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return model.AdminUpload{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	// Sweep abandoned uploads from every account; chunks cascade.
+	if _, err := tx.Exec(ctx, `DELETE FROM admin_uploads WHERE expires_at <= now()`); err != nil {
+		return model.AdminUpload{}, err
+	}
+	upload, err := scanAdminUpload(tx.QueryRow(ctx, `
+		INSERT INTO admin_uploads (account_id, kind, total_bytes, sha256, expires_at)
+		VALUES ($1, $2, $3, $4, now() + make_interval(secs => $5))
+		RETURNING `+adminUploadColumns,
+		accountID, string(req.Kind), req.TotalBytes, checksum, adminUploadTTL.Seconds()))
+	if err != nil {
+		return model.AdminUpload{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return model.AdminUpload{}, err
+	}
+	return upload, nil
+}
+
+func (s *Store) AdminGetUpload(ctx context.Context, accountID, uploadID string) (model.AdminUpload, error) {
+	accountID, uploadID, err := adminUploadIDs(accountID, uploadID)
+	if err != nil {
+		return model.AdminUpload{}, err
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	upload, err := scanAdminUpload(s.db.QueryRow(ctx, `
+		SELECT `+adminUploadColumns+`
+		FROM admin_uploads
+		WHERE account_id = $1
+		  AND id = $2
+		  AND expires_at > now()
+	`, accountID, uploadID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.AdminUpload{}, fmt.Errorf("%w", model.ErrAdminUploadNotFound)
+	}
+	return upload, err
+}
+
+// AdminAppendUploadChunk stores data at offset, which must be exactly where
+// the upload currently ends. A retried chunk that already landed therefore
+// reports ErrAdminUploadOffset, and the client resumes from ReceivedBytes.
+func (s *Store) AdminAppendUploadChunk(ctx context.Context, accountID, uploadID string, offset int64, data []byte) (model.AdminUpload, error) {
+	accountID, uploadID, err := adminUploadIDs(accountID, uploadID)
+	if err != nil {
+		return model.AdminUpload{}, err
+	}
+	if len(data) == 0 {
+		return model.AdminUpload{}, fmt.Errorf("upload chunk empty")
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return model.AdminUpload{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	upload, err := scanAdminUpload(tx.QueryRow(ctx, `
+		SELECT `+adminUploadColumns+`
+		FROM admin_uploads
+		WHERE account_id = $1
+		  AND id = $2
+		  AND expires_at > now()
+		FOR UPDATE
+	`, accountID, uploadID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.AdminUpload{}, fmt.Errorf("%w", model.ErrAdminUploadNotFound)
+	}
+	if err != nil {
+		return model.AdminUpload{}, err
+	}
+	if offset != upload.ReceivedBytes {
+		return model.AdminUpload{}, fmt.Errorf("%w", model.ErrAdminUploadOffset)
+	}
+	if int64(len(data)) > upload.TotalBytes-upload.ReceivedBytes {
+		return model.AdminUpload{}, fmt.Errorf("%w", model.ErrAdminUploadTooLarge)
+	}
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO admin_upload_chunks (upload_id, byte_offset, data)
+		VALUES ($1, $2, $3)
+	`, uploadID, offset, data); err != nil {
+		return model.AdminUpload{}, err
+	}
+	upload, err = scanAdminUpload(tx.QueryRow(ctx, `
+		UPDATE admin_uploads
+		SET received_bytes = received_bytes + $2
+		WHERE id = $1
+		RETURNING `+adminUploadColumns, uploadID, int64(len(data))))
+	if err != nil {
+		return model.AdminUpload{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return model.AdminUpload{}, err
+	}
+	return upload, nil
+}
+
+// AdminReadUpload returns the upload and its chunks assembled in order.
+func (s *Store) AdminReadUpload(ctx context.Context, accountID, uploadID string) (model.AdminUpload, []byte, error) {
+	accountID, uploadID, err := adminUploadIDs(accountID, uploadID)
+	if err != nil {
+		return model.AdminUpload{}, nil, err
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return model.AdminUpload{}, nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	upload, err := scanAdminUpload(tx.QueryRow(ctx, `
+		SELECT `+adminUploadColumns+`
+		FROM admin_uploads
+		WHERE account_id = $1
+		  AND id = $2
+		  AND expires_at > now()
+	`, accountID, uploadID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.AdminUpload{}, nil, fmt.Errorf("%w", model.ErrAdminUploadNotFound)
+	}
+	if err != nil {
+		return model.AdminUpload{}, nil, err
+	}
+
+	rows, err := tx.Query(ctx, `
+		SELECT data
+		FROM admin_upload_chunks
+		WHERE upload_id = $1
+		ORDER BY byte_offset ASC
+	`, uploadID)
+	if err != nil {
+		return model.AdminUpload{}, nil, err
+	}
+	defer rows.Close()
+	var content bytes.Buffer
+	content.Grow(int(upload.ReceivedBytes))
+	for rows.Next() {
+		var chunk []byte
+		if err := rows.Scan(&chunk); err != nil {
+			return model.AdminUpload{}, nil, err
+		}
+		content.Write(chunk)
+	}
+	if err := rows.Err(); err != nil {
+		return model.AdminUpload{}, nil, err
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return model.AdminUpload{}, nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return model.AdminUpload{}, nil, err
+	}
+	return upload, content.Bytes(), nil
+}
+
+func (s *Store) AdminDeleteUpload(ctx context.Context, accountID, uploadID string) error {
+	accountID, uploadID, err := adminUploadIDs(accountID, uploadID)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	result, err := s.db.Exec(ctx, `
+		DELETE FROM admin_uploads
+		WHERE account_id = $1
+		  AND id = $2
+	`, accountID, uploadID)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("%w", model.ErrAdminUploadNotFound)
+	}
+	return nil
+}
+
+func adminUploadIDs(accountID, uploadID string) (string, string, error) {
+	accountID = strings.TrimSpace(accountID)
+	uploadID = strings.TrimSpace(uploadID)
+	if !accountIDRe.MatchString(accountID) {
+		return "", "", fmt.Errorf("account required")
+	}
+	if !accountIDRe.MatchString(uploadID) {
+		return "", "", fmt.Errorf("%w", model.ErrAdminUploadNotFound)
+	}
+	return accountID, uploadID, nil
+}
+
+func scanAdminUpload(row rowScanner) (model.AdminUpload, error) {
+	var (
+		upload               model.AdminUpload
+		kind                 string
+		checksum             sql.NullString
+		createdAt, expiresAt time.Time
+	)
+	if err := row.Scan(&upload.ID, &kind, &upload.TotalBytes, &upload.ReceivedBytes, &checksum, &createdAt, &expiresAt); err != nil {
+		return model.AdminUpload{}, err
+	}
+	upload.Kind = model.AdminUploadKind(kind)
+	upload.SHA256 = nullStringValue(checksum)
+	upload.CreatedAt = createdAt.UTC().Format(time.RFC3339Nano)
+	upload.ExpiresAt = expiresAt.UTC().Format(time.RFC3339Nano)
+	return upload, nil
+}
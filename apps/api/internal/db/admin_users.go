@@ -0,0 +1,199 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"pandapages/api/internal/model"
+)
+
+var adminKeyHashRe = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+const maxAdminUserNameRunes = 80
+
+// AdminAuthenticateKey resolves the digest of a presented admin key to an
+// active admin user of the account. Unknown, disabled, and cross-account keys
+// all report model.ErrAdminUserNotFound.
+func (s *Store) AdminAuthenticateKey(ctx context.Context, accountID string, keyHash string) (model.AdminPrincipal, error) {
+	accountID = strings.TrimSpace(accountID)
+	if !accountIDRe.MatchString(accountID) {
+		return model.AdminPrincipal{}, fmt.Errorf("account required")
+	}
+	if !adminKeyHashRe.MatchString(keyHash) {
+		return model.AdminPrincipal{}, model.ErrAdminUserNotFound
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	var (
+		principal model.AdminPrincipal
+		roles     string
+	)
+	err := s.db.QueryRow(ctx, `
+		SELECT id, name, array_to_string(roles, ',')
+		FROM admin_users
+		WHERE account_id = $1
+		  AND key_hash = $2
+		  AND disabled_at IS NULL
+	`, accountID, keyHash).Scan(&principal.UserID, &principal.Name, &roles)
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.AdminPrincipal{}, model.ErrAdminUserNotFound
+	}
+	if err != nil {
+		return model.AdminPrincipal{}, err
+	}
+	principal.Roles = splitAdminRoles(roles)
+	return principal, nil
+}
+
+func (s *Store) AdminCreateUser(ctx context.Context, accountID string, req model.AdminUserCreate) (model.AdminUserRecord, error) {
+	accountID = strings.TrimSpace(accountID)
+	if !accountIDRe.MatchString(accountID) {
+		return model.AdminUserRecord{}, fmt.Errorf("account required")
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len([]rune(name)) > maxAdminUserNameRunes {
+		return model.AdminUserRecord{}, fmt.Errorf("admin user name invalid")
+	}
+	if !adminKeyHashRe.MatchString(req.KeyHash) {
+		return model.AdminUserRecord{}, fmt.Errorf("admin key hash invalid")
+	}
+	roles, err := joinAdminRoles(req.Roles)
+	if err != nil {
+		return model.AdminUserRecord{}, err
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	row := s.writes(ctx).QueryRow(ctx, `
+		INSERT INTO admin_users (account_id, name, key_hash, roles)
+		VALUES ($1, $2, $3, string_to_array($4, ','))
+		RETURNING id, name, array_to_string(roles, ','), created_at, disabled_at
+	`, accountID, name, req.KeyHash, roles)
+	record, err := scanAdminUser(row)
+	if isUniqueViolation(err) {
+		return model.AdminUserRecord{}, fmt.Errorf("%w", model.ErrAdminUserConflict)
+	}
+	return record, err
+}
+
+func (s *Store) AdminListUsers(ctx context.Context, accountID string) (model.AdminUsersListResponse, error) {
+	accountID = strings.TrimSpace(accountID)
+	if !accountIDRe.MatchString(accountID) {
+		return model.AdminUsersListResponse{}, fmt.Errorf("account required")
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	rows, err := s.db.Query(ctx, `
+		SELECT id, name, array_to_string(roles, ','), created_at, disabled_at
+		FROM admin_users
+		WHERE account_id = $1
+		ORDER BY lower(name), id
+	`, accountID)
+	if err != nil {
+		return model.AdminUsersListResponse{}, err
+	}
+	defer rows.Close()
+
+	items := []model.AdminUserRecord{}
+	for rows.Next() {
+		record, err := scanAdminUser(rows)
+		if err != nil {
+			return model.AdminUsersListResponse{}, err
+		}
+		items = append(items, record)
+	}
+	if err := rows.Err(); err != nil {
+		return model.AdminUsersListResponse{}, err
+	}
+	return model.AdminUsersListResponse{Items: items}, nil
+}
+
+// AdminDisableUser revokes an admin user's key. Rows are kept so audit actors
+// remain attributable; disabling twice is a no-op that returns the record.
+func (s *Store) AdminDisableUser(ctx context.Context, accountID string, userID string) (model.AdminUserRecord, error) {
+	accountID = strings.TrimSpace(accountID)
+	if !accountIDRe.MatchString(accountID) {
+		return model.AdminUserRecord{}, fmt.Errorf("account required")
+	}
+	userID = strings.TrimSpace(userID)
+	if !accountIDRe.MatchString(userID) {
+		return model.AdminUserRecord{}, fmt.Errorf("%w", model.ErrAdminUserNotFound)
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	row := s.writes(ctx).QueryRow(ctx, `
+		UPDATE admin_users
+		SET disabled_at = COALESCE(disabled_at, now())
+		WHERE account_id = $1 AND id = $2
+		RETURNING id, name, array_to_string(roles, ','), created_at, disabled_at
+	`, accountID, userID)
+	record, err := scanAdminUser(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.AdminUserRecord{}, fmt.Errorf("%w", model.ErrAdminUserNotFound)
+	}
+	return record, err
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanAdminUser(row rowScanner) (model.AdminUserRecord, error) {
+	var (
+		record     model.AdminUserRecord
+		roles      string
+		createdAt  time.Time
+		disabledAt sql.NullTime
+	)
+	if err := row.Scan(&record.ID, &record.Name, &roles, &createdAt, &disabledAt); err != nil {
+		return model.AdminUserRecord{}, err
+	}
+	record.Roles = splitAdminRoles(roles)
+	record.CreatedAt = createdAt.UTC().Format(time.RFC3339Nano)
+	if disabledAt.Valid {
+		value := disabledAt.Time.UTC().Format(time.RFC3339Nano)
+		record.DisabledAt = &value
+	}
+	return record, nil
+}
+
+func joinAdminRoles(roles []model.AdminRole) (string, error) {
+	if len(roles) == 0 {
+		return "", fmt.Errorf("admin roles required")
+	}
+	seen := map[model.AdminRole]bool{}
+	out := make([]string, 0, len(roles))
+	for _, role := range roles {
+		if !role.Valid() {
+			return "", fmt.Errorf("admin role invalid")
+		}
+		if seen[role] {
+			continue
+		}
+		seen[role] = true
+		out = append(out, string(role))
+	}
+	return strings.Join(out, ","), nil
+}
+
+func splitAdminRoles(raw string) []model.AdminRole {
+	roles := []model.AdminRole{}
+	for _, part := range strings.Split(raw, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			roles = append(roles, model.AdminRole(part))
+		}
+	}
+	return roles
+}
@@ -0,0 +1,277 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"pandapages/api/internal/model"
+	"pandapages/api/internal/storyingest"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// alignmentJobColumns reads a job aliased as job, joined to its version and
+// story by narrationJobJoins.
+const alignmentJobColumns = `
+	job.id, job.account_id, story.slug, job.story_version_id::text, job.status,
+	job.total_segments, job.aligned_segments, COALESCE(job.cursor_ordinal, -1),
+	job.error, job.created_at, job.updated_at, job.finished_at
+`
+
+var alignmentJobs = leasedJobs[model.AlignmentJob]{
+	table:    "alignment_jobs",
+	columns:  alignmentJobColumns,
+	joins:    narrationJobJoins,
+	done:     "aligned_segments",
+	cursor:   "cursor_ordinal",
+	scan:     scanAlignmentJob,
+	notFound: model.ErrAlignmentJobNotFound,
+}
+
+// AdminStartAlignmentJob queues word timing of one version of a story: every
+// segment with a recording that has not been aligned. Only one job may be
+// queued or running per version.
+func (s *Store) AdminStartAlignmentJob(ctx context.Context, accountID, slug, versionID string) (model.AlignmentJob, error) {
+	accountID = strings.TrimSpace(accountID)
+	if !accountIDRe.MatchString(accountID) {
+		return model.AlignmentJob{}, fmt.Errorf("account required")
+	}
+	if storyingest.ValidateSlug(slug) != nil || !accountIDRe.MatchString(versionID) {
+		return model.AlignmentJob{}, fmt.Errorf("%w", model.ErrAdminStoryNotFound)
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	job, err := scanAlignmentJob(s.writes(ctx).QueryRow(ctx, `
+		WITH job AS (
+			INSERT INTO alignment_jobs (account_id, story_version_id, total_segments)
+			SELECT $1, version.id, (
+				SELECT count(*)
+				FROM story_segments AS segment
+				JOIN segment_audio AS audio
+				  ON audio.segment_id = segment.id
+				WHERE segment.story_version_id = version.id
+				  AND audio.word_timings IS NULL
+			)
+			FROM story_versions AS version
+			JOIN stories AS story
+			  ON story.id = version.story_id
+			WHERE story.account_id = $1
+			  AND story.slug = $2
+			  AND version.id = $3
+			RETURNING *
+		)
+		SELECT `+alignmentJobColumns+`
+		FROM job`+narrationJobJoins,
+		accountID, slug, versionID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.AlignmentJob{}, fmt.Errorf("%w", model.ErrAdminStoryNotFound)
+	}
+	if isUniqueViolation(err) {
+		return model.AlignmentJob{}, model.ErrAlignmentJobActive
+	}
+	return job, err
+}
+
+func (s *Store) AdminGetAlignmentJob(ctx context.Context, accountID, jobID string) (model.AlignmentJob, error) {
+	accountID = strings.TrimSpace(accountID)
+	if !accountIDRe.MatchString(accountID) {
+		return model.AlignmentJob{}, fmt.Errorf("account required")
+	}
+	if !accountIDRe.MatchString(jobID) {
+		return model.AlignmentJob{}, model.ErrAlignmentJobNotFound
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	job, err := scanAlignmentJob(s.db.QueryRow(ctx, `
+		SELECT `+alignmentJobColumns+`
+		FROM alignment_jobs AS job`+narrationJobJoins+`
+		WHERE job.id = $1
+		  AND job.account_id = $2
+	`, jobID, accountID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.AlignmentJob{}, model.ErrAlignmentJobNotFound
+	}
+	return job, err
+}
+
+// AlignmentClaimJob leases the oldest queued job, or a running one whose
+// worker stopped renewing its lease. ok is false when there is nothing to do.
+func (s *Store) AlignmentClaimJob(ctx context.Context, lease time.Duration) (model.AlignmentJob, bool, error) {
+	return alignmentJobs.claim(ctx, s, lease)
+}
+
+// AlignmentPendingSegments lists, in reading order, up to limit segments
+// after the job's cursor whose recordings have still to be aligned, with the
+// recordings.
+func (s *Store) AlignmentPendingSegments(ctx context.Context, job model.AlignmentJob, limit int) ([]model.AlignmentSegment, error) {
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	rows, err := s.db.Query(ctx, `
+		SELECT segment.ordinal, segment.rendered_html, segment.pronunciations::text,
+		       audio.id::text, audio.content_type, audio.duration_ms, audio.data, audio.blob_key
+		FROM story_segments AS segment
+		JOIN segment_audio AS audio
+		  ON audio.segment_id = segment.id
+		WHERE segment.story_version_id = $1
+		  AND segment.ordinal > $2
+		  AND audio.word_timings IS NULL
+		ORDER BY segment.ordinal ASC
+		LIMIT $3
+	`, job.VersionID, job.CursorOrdinal, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	segments := []model.AlignmentSegment{}
+	for rows.Next() {
+		var (
+			segment        model.AlignmentSegment
+			pronunciations sql.NullString
+			durationMs     int64
+			key            *string
+		)
+		if err := rows.Scan(
+			&segment.Ordinal,
+			&segment.RenderedHTML,
+			&pronunciations,
+			&segment.AudioID,
+			&segment.Audio.ContentType,
+			&durationMs,
+			&segment.Audio.Data,
+			&key,
+		); err != nil {
+			return nil, err
+		}
+		if segment.Pronunciations, err = decodePronunciations(pronunciations); err != nil {
+			return nil, fmt.Errorf("decode segment pronunciations: %w", err)
+		}
+		segment.Audio.Duration = time.Duration(durationMs) * time.Millisecond
+		if key != nil {
+			segment.Audio.BlobKey = *key
+		}
+		segments = append(segments, segment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+	for i := range segments {
+		if segments[i].Audio.BlobKey == "" {
+			continue
+		}
+		key := segments[i].Audio.BlobKey
+		if segments[i].Audio.Data, err = s.blobData(ctx, nil, &key); err != nil {
+			return nil, err
+		}
+	}
+	return segments, nil
+}
+
+// AlignmentRecordSegment stores the word timings of one recording, then moves
+// the job's cursor past its segment and renews the lease. A recording
+// replaced since it was read keeps no timings: they were made for the old
+// one.
+func (s *Store) AlignmentRecordSegment(ctx context.Context, job model.AlignmentJob, segment model.AlignmentSegment, words []model.WordTiming, lease time.Duration) (model.AlignmentJob, error) {
+	if words == nil {
+		words = []model.WordTiming{}
+	}
+	encoded, err := json.Marshal(words)
+	if err != nil {
+		return model.AlignmentJob{}, err
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return model.AlignmentJob{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	tag, err := tx.Exec(ctx, `
+		UPDATE segment_audio
+		SET word_timings = $3::jsonb
+		WHERE id = $1
+		  AND account_id = $2
+	`, segment.AudioID, job.AccountID, string(encoded))
+	if err != nil {
+		return model.AlignmentJob{}, err
+	}
+
+	updated, err := alignmentJobs.advance(ctx, tx, job.ID, tag.RowsAffected(), segment.Ordinal, lease)
+	if err != nil {
+		return model.AlignmentJob{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return model.AlignmentJob{}, err
+	}
+	return updated, nil
+}
+
+// AlignmentFinishJob completes a job, or fails it with failure. Readers see
+// the new timings from then on: the story's cached segments are dropped here
+// and on every other instance.
+func (s *Store) AlignmentFinishJob(ctx context.Context, job model.AlignmentJob, failure *string) (model.AlignmentJob, error) {
+	return alignmentJobs.finish(ctx, s, job.ID, failure, func(finished model.AlignmentJob) (string, string, bool) {
+		return finished.AccountID, finished.Slug, finished.AlignedSegments > 0
+	})
+}
+
+// decodeWordTimings reads a recording's stored timings; NULL is a recording
+// not yet aligned.
+func decodeWordTimings(raw sql.NullString) ([]model.WordTiming, error) {
+	if !raw.Valid {
+		return nil, nil
+	}
+	var out []model.WordTiming
+	if err := json.Unmarshal([]byte(raw.String), &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func scanAlignmentJob(row pgx.Row) (model.AlignmentJob, error) {
+	var (
+		job        model.AlignmentJob
+		status     string
+		createdAt  time.Time
+		updatedAt  time.Time
+		finishedAt sql.NullTime
+	)
+	if err := row.Scan(
+		&job.ID,
+		&job.AccountID,
+		&job.Slug,
+		&job.VersionID,
+		&status,
+		&job.TotalSegments,
+		&job.AlignedSegments,
+		&job.CursorOrdinal,
+		&job.Error,
+		&createdAt,
+		&updatedAt,
+		&finishedAt,
+	); err != nil {
+		return model.AlignmentJob{}, err
+	}
+	job.Status = model.RenderJobStatus(status)
+	job.CreatedAt = createdAt.UTC().Format(time.RFC3339Nano)
+	job.UpdatedAt = updatedAt.UTC().Format(time.RFC3339Nano)
+	if finishedAt.Valid {
+		formatted := finishedAt.Time.UTC().Format(time.RFC3339Nano)
+		job.FinishedAt = &formatted
+	}
+	return job, nil
+}
@@ -0,0 +1,73 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"pandapages/api/internal/model"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// BedtimeSettings returns the account's bedtime window, or the defaults
+// when it has chosen none.
+func (s *Store) BedtimeSettings(ctx context.Context, accountID string) (model.BedtimeSettings, error) {
+	accountID = strings.TrimSpace(accountID)
+	if !accountIDRe.MatchString(accountID) {
+		return model.BedtimeSettings{}, fmt.Errorf("account required")
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	settings, err := scanBedtimeSettings(s.reads().QueryRow(ctx, `
+		SELECT to_char(start_time, 'HH24:MI'), to_char(end_time, 'HH24:MI'), time_zone, max_words
+		FROM bedtime_settings
+		WHERE account_id = $1
+	`, accountID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.DefaultBedtimeSettings, nil
+	}
+	return settings, err
+}
+
+// PutBedtimeSettings replaces the account's bedtime window. The time zone
+// must be one the database knows, as the digest's must.
+func (s *Store) PutBedtimeSettings(ctx context.Context, accountID string, settings model.BedtimeSettings) (model.BedtimeSettings, error) {
+	accountID = strings.TrimSpace(accountID)
+	if !accountIDRe.MatchString(accountID) {
+		return model.BedtimeSettings{}, fmt.Errorf("account required")
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	saved, err := scanBedtimeSettings(s.db.QueryRow(ctx, `
+		INSERT INTO bedtime_settings (account_id, start_time, end_time, time_zone, max_words)
+		SELECT $1, $2::time, $3::time, zone.name, $5
+		FROM pg_timezone_names AS zone
+		WHERE zone.name = $4
+		ON CONFLICT (account_id) DO UPDATE
+		SET start_time = EXCLUDED.start_time,
+		    end_time = EXCLUDED.end_time,
+		    time_zone = EXCLUDED.time_zone,
+		    max_words = EXCLUDED.max_words,
+		    updated_at = now()
+		RETURNING to_char(start_time, 'HH24:MI'), to_char(end_time, 'HH24:MI'), time_zone, max_words
+	`, accountID, settings.Start, settings.End, settings.TimeZone, settings.MaxWords))
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.BedtimeSettings{}, fmt.Errorf("%w", model.ErrBedtimeTimeZone)
+	}
+	return saved, err
+}
+
+func scanBedtimeSettings(row pgx.Row) (model.BedtimeSettings, error) {
+	var settings model.BedtimeSettings
+	if err := row.Scan(&settings.Start, &settings.End, &settings.TimeZone, &settings.MaxWords); err != nil {
+		return model.BedtimeSettings{}, err
+	}
+	return settings, nil
+}
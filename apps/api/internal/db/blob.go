@@ -0,0 +1,106 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+
+	"pandapages/api/internal/blob"
+)
+
+// putBlob stores data in the blob store under a new key of its own, such as
+// media/<account>/<random>.png. A key is never reused, so the sweeper can
+// delete one as soon as no row references it. Within AdminAudited the key is
+// remembered, so an audited transaction that rolls back deletes it.
+func (s *Store) putBlob(ctx context.Context, kind, accountID, extension, contentType string, data []byte) (string, error) {
+	var name [16]byte
+	if _, err := rand.Read(name[:]); err != nil {
+		return "", err
+	}
+	key := kind + "/" + accountID + "/" + hex.EncodeToString(name[:]) + extension
+	if err := s.blobs.Put(ctx, key, contentType, bytes.NewReader(data), int64(len(data))); err != nil {
+		return "", fmt.Errorf("store %s blob: %w", kind, err)
+	}
+	if audited, ok := ctx.Value(auditedTxKey{}).(*auditedTx); ok {
+		audited.blobs = append(audited.blobs, key)
+	}
+	return key, nil
+}
+
+// dropBlob deletes an object whose row was never written. One left behind is
+// only wasted space, so a failure is logged.
+func (s *Store) dropBlob(ctx context.Context, key string) {
+	if err := s.blobs.Delete(context.WithoutCancel(ctx), key); err != nil {
+		slog.Warn("unreferenced blob was not deleted", "blob_store", s.blobs.Name(), "err", err)
+	}
+}
+
+// blobData returns a row's bytes: data itself, or the object at key when the
+// row keeps them in the blob store.
+func (s *Store) blobData(ctx context.Context, data []byte, key *string) ([]byte, error) {
+	if key == nil {
+		return data, nil
+	}
+	if s.blobs == nil {
+		return nil, fmt.Errorf("blob %s is kept in a blob store, and none is configured", *key)
+	}
+	return blob.ReadAll(ctx, s.blobs, *key)
+}
+
+// BlobGarbage lists up to limit objects that no row references any more.
+//
+// The segment_audio trigger checks for other references before a narration
+// job's copy of the row commits, so a shared recording can be collected while
+// it is being copied. Each key is checked again here, and one still
+// referenced is forgotten rather than listed. A copy needs a live source row,
+// so a key unreferenced now stays that way.
+func (s *Store) BlobGarbage(ctx context.Context, limit int) ([]string, error) {
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	if _, err := s.db.Exec(ctx, `
+		DELETE FROM blob_garbage AS garbage
+		WHERE EXISTS (
+			SELECT 1
+			FROM segment_audio AS audio
+			WHERE audio.blob_key = garbage.key
+		)
+	`); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Query(ctx, `
+		SELECT key
+		FROM blob_garbage
+		ORDER BY created_at ASC, key ASC
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	keys := []string{}
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+// BlobGarbageDeleted forgets objects the sweeper has deleted.
+func (s *Store) BlobGarbageDeleted(ctx context.Context, keys []string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	_, err := s.db.Exec(ctx, `DELETE FROM blob_garbage WHERE key = ANY($1)`, keys)
+	return err
+}
@@ -0,0 +1,79 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	// connectAttemptTimeout bounds one connection attempt, so a database
+	// that accepts TCP but never answers does not hang startup.
+	connectAttemptTimeout = 3 * time.Second
+
+	// The delay between connection attempts starts at firstConnectDelay and
+	// doubles up to maxConnectDelay.
+	firstConnectDelay = 250 * time.Millisecond
+	maxConnectDelay   = 5 * time.Second
+)
+
+// connectDelay is the wait after the given failed attempt, counting from 1.
+func connectDelay(attempt int) time.Duration {
+	delay := firstConnectDelay
+	for range attempt - 1 {
+		delay *= 2
+		if delay >= maxConnectDelay {
+			return maxConnectDelay
+		}
+	}
+	return delay
+}
+
+// connect opens the pool and pings it, retrying with exponential backoff for
+// up to opt.ConnectRetry. A database that is still starting, as under
+// docker compose, is then waited for rather than fatal. The final error
+// says how long it tried.
+func connect(ctx context.Context, cfg *pgxpool.Config, opt Options) (*pgxpool.Pool, error) {
+	started := time.Now()
+	for attempt := 1; ; attempt++ {
+		pool, err := connectOnce(ctx, cfg)
+		if err == nil {
+			if attempt > 1 {
+				slog.Info("database connected", "attempts", attempt)
+			}
+			return pool, nil
+		}
+
+		delay := connectDelay(attempt)
+		if ctx.Err() != nil || time.Since(started)+delay > opt.ConnectRetry {
+			if attempt == 1 {
+				return nil, fmt.Errorf("connect to database: %w", err)
+			}
+			return nil, fmt.Errorf("connect to database: gave up after %d attempts over %s: %w",
+				attempt, time.Since(started).Round(time.Millisecond), err)
+		}
+		slog.Warn("database not ready; retrying", "attempt", attempt, "retry_in", delay.String(), "err", err)
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("connect to database: %w", ctx.Err())
+		case <-time.After(delay):
+		}
+	}
+}
+
+func connectOnce(ctx context.Context, cfg *pgxpool.Config) (*pgxpool.Pool, error) {
+	ctx, cancel := context.WithTimeout(ctx, connectAttemptTimeout)
+	defer cancel()
+	pool, err := pgxpool.NewWithConfig(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := pool.Ping(ctx); err != nil {
+		pool.Close()
+		return nil, err
+	}
+	return pool, nil
+}
@@ -0,0 +1,64 @@
+package db
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConnectDelayDoublesUpToTheCap(t *testing.T) {
+	want := []time.Duration{250 * time.Millisecond, 500 * time.Millisecond, time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for index, delay := range want {
+		if got := connectDelay(index + 1); got != delay {
+			t.Fatalf("delay after attempt %d = %s, want %s", index+1, got, delay)
+		}
+	}
+}
+
+// unreachableURL names a port nothing listens on, so every attempt is
+// refused at once.
+const unreachableURL = "postgres://pandapages@127.0.0.1:1/pandapages?sslmode=disable"
+
+func TestOpenRetriesAnUnreachableDatabaseThenGivesUp(t *testing.T) {
+	opt := DefaultOptions()
+	opt.ConnectRetry = 800 * time.Millisecond
+	started := time.Now()
+	_, err := Open(t.Context(), unreachableURL, opt)
+	if err == nil || !strings.Contains(err.Error(), "gave up after 3 attempts") {
+		t.Fatalf("error = %v, want it to give up after 3 attempts", err)
+	}
+	if elapsed := time.Since(started); elapsed < 700*time.Millisecond {
+		t.Fatalf("gave up after %s, before backing off", elapsed)
+	}
+
+	opt.ConnectRetry = 0
+	if _, err := Open(t.Context(), unreachableURL, opt); err == nil || strings.Contains(err.Error(), "gave up") {
+		t.Fatalf("error without retries = %v, want a single attempt", err)
+	}
+}
+
+func TestOpenStopsWaitingWhenCancelled(t *testing.T) {
+	opt := DefaultOptions()
+	opt.ConnectRetry = time.Minute
+	ctx, cancel := context.WithTimeout(t.Context(), 100*time.Millisecond)
+	defer cancel()
+	started := time.Now()
+	if _, err := Open(ctx, unreachableURL, opt); err == nil {
+		t.Fatal("Open succeeded against an unreachable database")
+	}
+	if elapsed := time.Since(started); elapsed > 2*time.Second {
+		t.Fatalf("cancelled Open took %s", elapsed)
+	}
+}
+
+func TestOpenRejectsMalformedURLs(t *testing.T) {
+	if _, err := Open(t.Context(), " ", DefaultOptions()); err == nil {
+		t.Fatal("blank URL was accepted")
+	}
+	opt := DefaultOptions()
+	opt.ReplicaURL = "postgres://%zz"
+	if _, err := Open(t.Context(), unreachableURL, opt); err == nil || !strings.HasPrefix(err.Error(), "replica:") {
+		t.Fatalf("malformed replica URL error = %v", err)
+	}
+}
@@ -0,0 +1,161 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"pandapages/api/internal/model"
+	"pandapages/api/internal/storyingest"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const maxContributorNameRunes = 200
+
+// contributorRoleRank orders credits the way model.ContributorRoles lists them.
+const contributorRoleRank = `
+	CASE sc.role
+		WHEN 'author' THEN 0
+		WHEN 'reteller' THEN 1
+		WHEN 'translator' THEN 2
+		WHEN 'illustrator' THEN 3
+		ELSE 4
+	END
+`
+
+// storyContributorsJSON is a scalar subquery over the outer `st` stories row.
+// Reader queries embed it so credits come from the same statement snapshot as
+// the rest of the story.
+const storyContributorsJSON = `(
+	SELECT COALESCE(
+		json_agg(json_build_object('name', c.name, 'role', sc.role)
+			ORDER BY ` + contributorRoleRank + `, lower(c.name), c.id),
+		'[]'::json
+	)::text
+	FROM story_contributors sc
+	JOIN contributors c ON c.id = sc.contributor_id
+	WHERE sc.story_id = st.id
+)`
+
+func (s *Store) AdminListStoryContributors(ctx context.Context, accountID, slug string) (model.AdminStoryContributorsResponse, error) {
+	return s.changeStoryContributors(ctx, accountID, slug, nil)
+}
+
+// AdminAddStoryContributor credits a named contributor on a story, creating
+// the contributor when the name is new. Repeating an existing credit is a
+// no-op.
+func (s *Store) AdminAddStoryContributor(ctx context.Context, accountID, slug string, contributor model.StoryContributor) (model.AdminStoryContributorsResponse, error) {
+	name := strings.TrimSpace(contributor.Name)
+	if name == "" || utf8.RuneCountInString(name) > maxContributorNameRunes {
+		return model.AdminStoryContributorsResponse{}, fmt.Errorf("contributor name invalid")
+	}
+	if !contributor.Role.Valid() {
+		return model.AdminStoryContributorsResponse{}, fmt.Errorf("contributor role invalid")
+	}
+	return s.changeStoryContributors(ctx, accountID, slug, func(ctx context.Context, tx pgx.Tx, storyID string) error {
+		var contributorID string
+		// No-op update returns id reliably (requires UNIQUE(contributors.name))
+		if err := tx.QueryRow(ctx, `
+			INSERT INTO contributors (name)
+			VALUES ($1)
+			ON CONFLICT (name) DO UPDATE SET name = EXCLUDED.name
+			RETURNING id
+		`, name).Scan(&contributorID); err != nil {
+			return err
+		}
+		_, err := tx.Exec(ctx, `
+			INSERT INTO story_contributors (story_id, contributor_id, role)
+			VALUES ($1, $2, $3)
+			ON CONFLICT DO NOTHING
+		`, storyID, contributorID, string(contributor.Role))
+		return err
+	})
+}
+
+// AdminRemoveStoryContributor removes one credit. The contributor row is kept
+// because contributors are shared between stories.
+func (s *Store) AdminRemoveStoryContributor(ctx context.Context, accountID, slug, contributorID string, role model.ContributorRole) (model.AdminStoryContributorsResponse, error) {
+	contributorID = strings.TrimSpace(contributorID)
+	if !accountIDRe.MatchString(contributorID) || !role.Valid() {
+		return model.AdminStoryContributorsResponse{}, fmt.Errorf("%w", model.ErrAdminContributorNotFound)
+	}
+	return s.changeStoryContributors(ctx, accountID, slug, func(ctx context.Context, tx pgx.Tx, storyID string) error {
+		res, err := tx.Exec(ctx, `
+			DELETE FROM story_contributors
+			WHERE story_id = $1 AND contributor_id = $2 AND role = $3
+		`, storyID, contributorID, string(role))
+		if err != nil {
+			return err
+		}
+		n := res.RowsAffected()
+		if n == 0 {
+			return fmt.Errorf("%w", model.ErrAdminContributorNotFound)
+		}
+		return nil
+	})
+}
+
+// changeStoryContributors runs change (if any) against an account-scoped
+// story and returns the resulting credits from the same transaction.
+func (s *Store) changeStoryContributors(ctx context.Context, accountID, slug string, change func(context.Context, pgx.Tx, string) error) (model.AdminStoryContributorsResponse, error) {
+	accountID = strings.TrimSpace(accountID)
+	slug = strings.TrimSpace(slug)
+	if !accountIDRe.MatchString(accountID) || storyingest.ValidateSlug(slug) != nil {
+		return model.AdminStoryContributorsResponse{}, fmt.Errorf("%w", model.ErrAdminStoryNotFound)
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+	tx, err := s.writes(ctx).Begin(ctx)
+	if err != nil {
+		return model.AdminStoryContributorsResponse{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	story, err := loadAdminStory(ctx, tx, accountID, slug, change != nil)
+	if err != nil {
+		return model.AdminStoryContributorsResponse{}, err
+	}
+	if change != nil {
+		if err := change(ctx, tx, story.ID); err != nil {
+			return model.AdminStoryContributorsResponse{}, err
+		}
+	}
+
+	rows, err := tx.Query(ctx, `
+		SELECT c.id, c.name, sc.role
+		FROM story_contributors sc
+		JOIN contributors c ON c.id = sc.contributor_id
+		WHERE sc.story_id = $1
+		ORDER BY `+contributorRoleRank+`, lower(c.name), c.id
+	`, story.ID)
+	if err != nil {
+		return model.AdminStoryContributorsResponse{}, err
+	}
+	defer rows.Close()
+	items := []model.AdminStoryContributor{}
+	for rows.Next() {
+		var (
+			item model.AdminStoryContributor
+			role string
+		)
+		if err := rows.Scan(&item.ContributorID, &item.Name, &role); err != nil {
+			return model.AdminStoryContributorsResponse{}, err
+		}
+		item.Role = model.ContributorRole(role)
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return model.AdminStoryContributorsResponse{}, err
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return model.AdminStoryContributorsResponse{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return model.AdminStoryContributorsResponse{}, err
+	}
+	return model.AdminStoryContributorsResponse{Slug: story.Slug, Items: items}, nil
+}
@@ -0,0 +1,257 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"pandapages/api/internal/model"
+)
+
+// dashboardSlot is the minutes one child read of one story in one local
+// hour.
+type dashboardSlot struct {
+	storyID  string
+	slug     string
+	title    string
+	day      string
+	hour     int
+	minutes  int
+	finished bool
+	lastRead time.Time
+	grade    *float64
+}
+
+// dashboardTag is a tag of a story the child read.
+type dashboardTag struct {
+	storyID string
+	name    string
+}
+
+// ChildDashboard reports one of the account's child profiles' reading over
+// the model.DashboardWeeks weeks ending on the date at is in the account's
+// digest time zone. A missing or cross-account child is ErrProfileNotFound.
+func (s *Store) ChildDashboard(ctx context.Context, accountID, childID string, at time.Time) (model.ChildDashboard, error) {
+	accountID, childID = strings.TrimSpace(accountID), strings.TrimSpace(childID)
+	if !accountIDRe.MatchString(accountID) {
+		return model.ChildDashboard{}, fmt.Errorf("account required")
+	}
+	if !accountIDRe.MatchString(childID) {
+		return model.ChildDashboard{}, fmt.Errorf("%w", model.ErrProfileNotFound)
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	out := model.ChildDashboard{ChildProfileID: childID}
+	err := s.reads().QueryRow(ctx, `
+		SELECT COALESCE(child.name, ''), zone.name, ($3::timestamptz AT TIME ZONE zone.name)::date::text
+		FROM child_profiles AS child
+		CROSS JOIN (
+			SELECT COALESCE((SELECT time_zone FROM digest_settings WHERE account_id = $1), 'UTC') AS name
+		) AS zone
+		WHERE child.id = $2
+		  AND child.account_id = $1
+	`, accountID, childID, at).Scan(&out.Name, &out.TimeZone, &out.To)
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.ChildDashboard{}, fmt.Errorf("%w", model.ErrProfileNotFound)
+	}
+	if err != nil {
+		return model.ChildDashboard{}, err
+	}
+
+	rows, err := s.reads().Query(ctx, `
+		SELECT DISTINCT (minute AT TIME ZONE $3)::date::text
+		FROM reading_activity
+		WHERE account_id = $1
+		  AND child_profile_id = $2
+		  AND minute <= $4
+		  AND (minute AT TIME ZONE $3)::date > $5::date - $6::int
+	`, accountID, childID, out.TimeZone, at, out.To, digestStreakDays)
+	if err != nil {
+		return model.ChildDashboard{}, err
+	}
+	read := map[string]bool{}
+	for rows.Next() {
+		var day string
+		if err := rows.Scan(&day); err != nil {
+			rows.Close()
+			return model.ChildDashboard{}, err
+		}
+		read[day] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return model.ChildDashboard{}, err
+	}
+
+	// Grades come from each story's published version: reading is kept per
+	// story, not per version.
+	window := model.DashboardWeeks * 7
+	rows, err = s.reads().Query(ctx, `
+		SELECT story.id::text, story.slug, story.title,
+		       (activity.minute AT TIME ZONE $3)::date::text,
+		       extract(hour FROM activity.minute AT TIME ZONE $3)::int,
+		       count(*), bool_or(activity.finished), max(activity.minute),
+		       (version.readability->>'fleschKincaidGrade')::float8
+		FROM reading_activity AS activity
+		JOIN stories AS story
+		  ON story.id = activity.story_id
+		LEFT JOIN story_versions AS version
+		  ON version.id = story.published_version_id
+		 AND version.story_id = story.id
+		WHERE activity.account_id = $1
+		  AND activity.child_profile_id = $2
+		  AND activity.minute <= $4
+		  AND (activity.minute AT TIME ZONE $3)::date > $5::date - $6::int
+		GROUP BY story.id, version.id, 4, 5
+	`, accountID, childID, out.TimeZone, at, out.To, window)
+	if err != nil {
+		return model.ChildDashboard{}, err
+	}
+	var slots []dashboardSlot
+	for rows.Next() {
+		var (
+			slot  dashboardSlot
+			grade sql.NullFloat64
+		)
+		if err := rows.Scan(&slot.storyID, &slot.slug, &slot.title, &slot.day, &slot.hour, &slot.minutes, &slot.finished, &slot.lastRead, &grade); err != nil {
+			rows.Close()
+			return model.ChildDashboard{}, err
+		}
+		if grade.Valid && !math.IsNaN(grade.Float64) && !math.IsInf(grade.Float64, 0) {
+			slot.grade = &grade.Float64
+		}
+		slots = append(slots, slot)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return model.ChildDashboard{}, err
+	}
+
+	rows, err = s.reads().Query(ctx, `
+		SELECT link.story_id::text, tag.name
+		FROM story_tags AS link
+		JOIN tags AS tag
+		  ON tag.id = link.tag_id
+		 AND tag.account_id = $1
+		WHERE link.story_id IN (
+			SELECT story_id
+			FROM reading_activity
+			WHERE account_id = $1
+			  AND child_profile_id = $2
+			  AND minute <= $4
+			  AND (minute AT TIME ZONE $3)::date > $5::date - $6::int
+		)
+	`, accountID, childID, out.TimeZone, at, out.To, window)
+	if err != nil {
+		return model.ChildDashboard{}, err
+	}
+	defer rows.Close()
+	var tags []dashboardTag
+	for rows.Next() {
+		var tag dashboardTag
+		if err := rows.Scan(&tag.storyID, &tag.name); err != nil {
+			return model.ChildDashboard{}, err
+		}
+		tags = append(tags, tag)
+	}
+	if err := rows.Err(); err != nil {
+		return model.ChildDashboard{}, err
+	}
+	return buildChildDashboard(out, read, slots, tags)
+}
+
+// buildChildDashboard fills in a dashboard whose child, time zone and To are
+// set from the days the child read and their reading in the window.
+func buildChildDashboard(out model.ChildDashboard, read map[string]bool, slots []dashboardSlot, tags []dashboardTag) (model.ChildDashboard, error) {
+	end, err := time.Parse(time.DateOnly, out.To)
+	if err != nil {
+		return model.ChildDashboard{}, err
+	}
+	start := end.AddDate(0, 0, 1-model.DashboardWeeks*7)
+	out.From = start.Format(time.DateOnly)
+	out.StreakDays = readingStreak(read, end)
+	out.Recent = []model.DashboardStory{}
+	out.FavoriteTags = []model.DashboardTag{}
+	out.TimeOfDay = make([]int, 24)
+	out.LevelTrend = make([]model.DashboardLevel, model.DashboardWeeks)
+	graded := make([]struct{ minutes, sum float64 }, model.DashboardWeeks)
+	for week := range out.LevelTrend {
+		out.LevelTrend[week].From = start.AddDate(0, 0, week*7).Format(time.DateOnly)
+	}
+
+	stories := map[string]*model.DashboardStory{}
+	for _, slot := range slots {
+		day, err := time.Parse(time.DateOnly, slot.day)
+		if err != nil {
+			return model.ChildDashboard{}, err
+		}
+		week := int(day.Sub(start).Hours()/24) / 7
+		if day.Before(start) || week >= model.DashboardWeeks || slot.hour < 0 || slot.hour >= 24 {
+			continue
+		}
+		out.Minutes += slot.minutes
+		out.TimeOfDay[slot.hour] += slot.minutes
+		out.LevelTrend[week].Minutes += slot.minutes
+		if slot.grade != nil {
+			graded[week].minutes += float64(slot.minutes)
+			graded[week].sum += float64(slot.minutes) * *slot.grade
+		}
+
+		story, ok := stories[slot.storyID]
+		if !ok {
+			story = &model.DashboardStory{Slug: slot.slug, Title: slot.title}
+			stories[slot.storyID] = story
+		}
+		story.Minutes += slot.minutes
+		story.Finished = story.Finished || slot.finished
+		if slot.lastRead.After(story.LastReadAt) {
+			story.LastReadAt = slot.lastRead
+		}
+	}
+	for week := range out.LevelTrend {
+		if graded[week].minutes > 0 {
+			grade := math.Round(graded[week].sum/graded[week].minutes*10) / 10
+			out.LevelTrend[week].Grade = &grade
+		}
+	}
+
+	for _, story := range stories {
+		out.Recent = append(out.Recent, *story)
+	}
+	sort.Slice(out.Recent, func(i, j int) bool {
+		if !out.Recent[i].LastReadAt.Equal(out.Recent[j].LastReadAt) {
+			return out.Recent[i].LastReadAt.After(out.Recent[j].LastReadAt)
+		}
+		return out.Recent[i].Slug < out.Recent[j].Slug
+	})
+	if len(out.Recent) > model.DashboardRecentStories {
+		out.Recent = out.Recent[:model.DashboardRecentStories]
+	}
+
+	tagMinutes := map[string]int{}
+	for _, tag := range tags {
+		if story, ok := stories[tag.storyID]; ok {
+			tagMinutes[tag.name] += story.Minutes
+		}
+	}
+	for name, minutes := range tagMinutes {
+		out.FavoriteTags = append(out.FavoriteTags, model.DashboardTag{Name: name, Minutes: minutes})
+	}
+	sort.Slice(out.FavoriteTags, func(i, j int) bool {
+		if out.FavoriteTags[i].Minutes != out.FavoriteTags[j].Minutes {
+			return out.FavoriteTags[i].Minutes > out.FavoriteTags[j].Minutes
+		}
+		return out.FavoriteTags[i].Name < out.FavoriteTags[j].Name
+	})
+	if len(out.FavoriteTags) > model.DashboardFavoriteTags {
+		out.FavoriteTags = out.FavoriteTags[:model.DashboardFavoriteTags]
+	}
+	return out, nil
+}
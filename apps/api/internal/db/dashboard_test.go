@@ -0,0 +1,68 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"pandapages/api/internal/model"
+)
+
+func TestBuildChildDashboardAggregatesTheWindow(t *testing.T) {
+	at := func(value string) time.Time {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return parsed
+	}
+	easy, hard := 1.0, 4.0
+	read := map[string]bool{"2026-10-16": true, "2026-10-15": true, "2026-10-14": true, "2026-10-10": true}
+	slots := []dashboardSlot{
+		{storyID: "owls", slug: "owls", title: "Owls", day: "2026-09-20", hour: 19, minutes: 10, lastRead: at("2026-09-20T19:10:00Z"), grade: &easy},
+		{storyID: "owls", slug: "owls", title: "Owls", day: "2026-10-14", hour: 19, minutes: 5, finished: true, lastRead: at("2026-10-14T19:05:00Z"), grade: &easy},
+		{storyID: "boats", slug: "boats", title: "Boats", day: "2026-10-16", hour: 7, minutes: 15, lastRead: at("2026-10-16T07:15:00Z"), grade: &hard},
+		{storyID: "moon", slug: "moon", title: "Moon", day: "2026-10-15", hour: 19, minutes: 3, lastRead: at("2026-10-15T19:03:00Z")},
+		// Outside the window: left out.
+		{storyID: "old", slug: "old", title: "Old", day: "2026-09-19", hour: 8, minutes: 50, lastRead: at("2026-09-19T08:50:00Z")},
+	}
+	tags := []dashboardTag{
+		{storyID: "owls", name: "animals"},
+		{storyID: "boats", name: "adventure"},
+		{storyID: "moon", name: "animals"},
+		{storyID: "old", name: "history"},
+	}
+
+	dashboard, err := buildChildDashboard(model.ChildDashboard{ChildProfileID: "ada", Name: "Ada", To: "2026-10-17", TimeZone: "UTC"}, read, slots, tags)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dashboard.From != "2026-09-20" || dashboard.Minutes != 33 || dashboard.StreakDays != 3 {
+		t.Fatalf("dashboard = %+v", dashboard)
+	}
+	if len(dashboard.TimeOfDay) != 24 || dashboard.TimeOfDay[19] != 18 || dashboard.TimeOfDay[7] != 15 || dashboard.TimeOfDay[8] != 0 {
+		t.Fatalf("time of day = %v", dashboard.TimeOfDay)
+	}
+	if len(dashboard.Recent) != 3 || dashboard.Recent[0].Slug != "boats" || dashboard.Recent[2].Slug != "owls" ||
+		dashboard.Recent[2].Minutes != 15 || !dashboard.Recent[2].Finished {
+		t.Fatalf("recent = %+v", dashboard.Recent)
+	}
+	if len(dashboard.FavoriteTags) != 2 || dashboard.FavoriteTags[0] != (model.DashboardTag{Name: "animals", Minutes: 18}) ||
+		dashboard.FavoriteTags[1] != (model.DashboardTag{Name: "adventure", Minutes: 15}) {
+		t.Fatalf("favorite tags = %+v", dashboard.FavoriteTags)
+	}
+
+	trend := dashboard.LevelTrend
+	if len(trend) != model.DashboardWeeks || trend[0].From != "2026-09-20" || trend[3].From != "2026-10-11" {
+		t.Fatalf("level trend = %+v", trend)
+	}
+	if trend[0].Minutes != 10 || trend[0].Grade == nil || *trend[0].Grade != 1 {
+		t.Fatalf("first week = %+v", trend[0])
+	}
+	if trend[1].Minutes != 0 || trend[1].Grade != nil {
+		t.Fatalf("quiet week = %+v", trend[1])
+	}
+	// (5×1 + 15×4) / 20 graded minutes; the ungraded story only adds minutes.
+	if trend[3].Minutes != 23 || trend[3].Grade == nil || *trend[3].Grade != 3.3 {
+		t.Fatalf("last week = %+v, grade %v", trend[3], trend[3].Grade)
+	}
+}
@@ -0,0 +1,389 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"pandapages/api/internal/model"
+)
+
+const (
+	deliveryDestinationColumns = `id, name, address, created_at`
+	storyDeliveryColumns       = `d.id, st.slug, d.destination_id, d.address, d.status, d.version, d.attempts, d.last_error, d.created_at, d.sent_at`
+	storyDeliveryLog           = 50
+)
+
+func (s *Store) DeliveryDestinations(ctx context.Context, accountID string) (model.DeliveryDestinationsResponse, error) {
+	accountID = strings.TrimSpace(accountID)
+	if !accountIDRe.MatchString(accountID) {
+		return model.DeliveryDestinationsResponse{}, fmt.Errorf("account required")
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	rows, err := s.reads().Query(ctx, `
+		SELECT `+deliveryDestinationColumns+`
+		FROM delivery_destinations
+		WHERE account_id = $1
+		ORDER BY created_at ASC, id ASC
+	`, accountID)
+	if err != nil {
+		return model.DeliveryDestinationsResponse{}, err
+	}
+	defer rows.Close()
+	items := []model.DeliveryDestination{}
+	for rows.Next() {
+		destination, err := scanDeliveryDestination(rows)
+		if err != nil {
+			return model.DeliveryDestinationsResponse{}, err
+		}
+		items = append(items, destination)
+	}
+	if err := rows.Err(); err != nil {
+		return model.DeliveryDestinationsResponse{}, err
+	}
+	return model.DeliveryDestinationsResponse{Items: items}, nil
+}
+
+// CreateDeliveryDestination adds an address, at most
+// model.MaxDeliveryDestinations per account and each address once.
+func (s *Store) CreateDeliveryDestination(ctx context.Context, accountID string, create model.DeliveryDestinationCreate) (model.DeliveryDestination, error) {
+	accountID = strings.TrimSpace(accountID)
+	if !accountIDRe.MatchString(accountID) {
+		return model.DeliveryDestination{}, fmt.Errorf("account required")
+	}
+	name := strings.TrimSpace(create.Name)
+	if !model.ValidDeliveryDestinationName(name) {
+		return model.DeliveryDestination{}, fmt.Errorf("destination name invalid")
+	}
+	address, ok := model.DeliveryAddress(create.Address)
+	if !ok {
+		return model.DeliveryDestination{}, fmt.Errorf("destination address invalid")
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return model.DeliveryDestination{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	// The account row serialises creates, so two at once cannot both pass
+	// the limit.
+	var count int
+	if err := tx.QueryRow(ctx, `
+		SELECT count(destination.id)
+		FROM (SELECT id FROM accounts WHERE id = $1 FOR UPDATE) AS account
+		LEFT JOIN delivery_destinations AS destination
+		  ON destination.account_id = account.id
+	`, accountID).Scan(&count); err != nil {
+		return model.DeliveryDestination{}, err
+	}
+	if count >= model.MaxDeliveryDestinations {
+		return model.DeliveryDestination{}, fmt.Errorf("%w", model.ErrDestinationLimit)
+	}
+	destination, err := scanDeliveryDestination(tx.QueryRow(ctx, `
+		INSERT INTO delivery_destinations (account_id, name, address)
+		VALUES ($1, $2, $3)
+		RETURNING `+deliveryDestinationColumns, accountID, name, address))
+	if isUniqueViolation(err) {
+		return model.DeliveryDestination{}, fmt.Errorf("%w", model.ErrDestinationExists)
+	}
+	if err != nil {
+		return model.DeliveryDestination{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return model.DeliveryDestination{}, err
+	}
+	return destination, nil
+}
+
+// DeleteDeliveryDestination removes an address. Deliveries to it that have
+// not gone out yet fail rather than being sent somewhere the account no
+// longer wants; sent ones keep the address they went to.
+func (s *Store) DeleteDeliveryDestination(ctx context.Context, accountID, destinationID string) error {
+	accountID = strings.TrimSpace(accountID)
+	destinationID = strings.TrimSpace(destinationID)
+	if !accountIDRe.MatchString(accountID) {
+		return fmt.Errorf("account required")
+	}
+	if !accountIDRe.MatchString(destinationID) {
+		return fmt.Errorf("%w", model.ErrDestinationNotFound)
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	// Pending rows are found by destination before the delete clears it.
+	if _, err := tx.Exec(ctx, `
+		UPDATE story_deliveries
+		SET status = 'failed',
+		    last_error = 'destination_removed',
+		    next_attempt_at = NULL
+		WHERE account_id = $1
+		  AND destination_id = $2
+		  AND status = 'pending'
+	`, accountID, destinationID); err != nil {
+		return err
+	}
+	result, err := tx.Exec(ctx, `
+		DELETE FROM delivery_destinations
+		WHERE account_id = $1
+		  AND id = $2
+	`, accountID, destinationID)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("%w", model.ErrDestinationNotFound)
+	}
+	return tx.Commit(ctx)
+}
+
+// SendStory queues the published story for email to one of the account's
+// destinations. The book is made when the delivery is sent, so it is the
+// version published then.
+func (s *Store) SendStory(ctx context.Context, accountID, slug, destinationID string) (model.StoryDelivery, error) {
+	accountID = strings.TrimSpace(accountID)
+	destinationID = strings.TrimSpace(destinationID)
+	if !accountIDRe.MatchString(accountID) {
+		return model.StoryDelivery{}, fmt.Errorf("account required")
+	}
+	if !accountIDRe.MatchString(destinationID) {
+		return model.StoryDelivery{}, fmt.Errorf("%w", model.ErrDestinationNotFound)
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return model.StoryDelivery{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var storyID string
+	err = tx.QueryRow(ctx, `
+		SELECT id
+		FROM stories
+		WHERE account_id = $1
+		  AND slug = $2
+		  AND is_published = true
+		  AND published_version_id IS NOT NULL
+	`, accountID, slug).Scan(&storyID)
+	if err != nil {
+		return model.StoryDelivery{}, s.removedOr(ctx, accountID, slug, err)
+	}
+	var address string
+	err = tx.QueryRow(ctx, `
+		SELECT address
+		FROM delivery_destinations
+		WHERE account_id = $1
+		  AND id = $2
+		FOR SHARE
+	`, accountID, destinationID).Scan(&address)
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.StoryDelivery{}, fmt.Errorf("%w", model.ErrDestinationNotFound)
+	}
+	if err != nil {
+		return model.StoryDelivery{}, err
+	}
+	delivery, err := scanStoryDelivery(tx.QueryRow(ctx, `
+		WITH d AS (
+			INSERT INTO story_deliveries (account_id, story_id, destination_id, address, next_attempt_at)
+			VALUES ($1, $2, $3, $4, now())
+			RETURNING *
+		)
+		SELECT `+storyDeliveryColumns+`
+		FROM d
+		JOIN stories st ON st.id = d.story_id
+	`, accountID, storyID, destinationID, address))
+	if err != nil {
+		return model.StoryDelivery{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return model.StoryDelivery{}, err
+	}
+	return delivery, nil
+}
+
+func (s *Store) StoryDelivery(ctx context.Context, accountID, deliveryID string) (model.StoryDelivery, error) {
+	accountID = strings.TrimSpace(accountID)
+	deliveryID = strings.TrimSpace(deliveryID)
+	if !accountIDRe.MatchString(accountID) {
+		return model.StoryDelivery{}, fmt.Errorf("account required")
+	}
+	if !accountIDRe.MatchString(deliveryID) {
+		return model.StoryDelivery{}, fmt.Errorf("%w", model.ErrDeliveryNotFound)
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	// Read from the primary: a client polls this straight after sending.
+	delivery, err := scanStoryDelivery(s.db.QueryRow(ctx, `
+		SELECT `+storyDeliveryColumns+`
+		FROM story_deliveries d
+		JOIN stories st ON st.id = d.story_id
+		WHERE d.account_id = $1
+		  AND d.id = $2
+	`, accountID, deliveryID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.StoryDelivery{}, fmt.Errorf("%w", model.ErrDeliveryNotFound)
+	}
+	return delivery, err
+}
+
+// StoryDeliveries returns the account's most recent deliveries, newest first.
+func (s *Store) StoryDeliveries(ctx context.Context, accountID string) (model.StoryDeliveriesResponse, error) {
+	accountID = strings.TrimSpace(accountID)
+	if !accountIDRe.MatchString(accountID) {
+		return model.StoryDeliveriesResponse{}, fmt.Errorf("account required")
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	rows, err := s.db.Query(ctx, `
+		SELECT `+storyDeliveryColumns+`
+		FROM story_deliveries d
+		JOIN stories st ON st.id = d.story_id
+		WHERE d.account_id = $1
+		ORDER BY d.created_at DESC, d.id DESC
+		LIMIT $2
+	`, accountID, storyDeliveryLog)
+	if err != nil {
+		return model.StoryDeliveriesResponse{}, err
+	}
+	defer rows.Close()
+	items := []model.StoryDelivery{}
+	for rows.Next() {
+		delivery, err := scanStoryDelivery(rows)
+		if err != nil {
+			return model.StoryDeliveriesResponse{}, err
+		}
+		items = append(items, delivery)
+	}
+	if err := rows.Err(); err != nil {
+		return model.StoryDeliveriesResponse{}, err
+	}
+	return model.StoryDeliveriesResponse{Items: items}, nil
+}
+
+// DeliveryClaim leases up to limit due deliveries across accounts.
+func (s *Store) DeliveryClaim(ctx context.Context, limit int, lease time.Duration) ([]model.PendingStoryDelivery, error) {
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	rows, err := s.db.Query(ctx, `
+		WITH due AS (
+			SELECT id
+			FROM story_deliveries
+			WHERE status = 'pending'
+			  AND next_attempt_at <= now()
+			ORDER BY next_attempt_at ASC, id ASC
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE story_deliveries d
+		SET next_attempt_at = now() + make_interval(secs => $2)
+		FROM due, stories st
+		WHERE d.id = due.id
+		  AND st.id = d.story_id
+		RETURNING d.id, d.account_id, st.slug, d.address, d.attempts
+	`, limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []model.PendingStoryDelivery{}
+	for rows.Next() {
+		var item model.PendingStoryDelivery
+		if err := rows.Scan(&item.ID, &item.AccountID, &item.Slug, &item.Address, &item.Attempts); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+func (s *Store) DeliveryRecordAttempt(ctx context.Context, deliveryID string, attempt model.StoryDeliveryAttempt) error {
+	status := model.DeliveryPending
+	switch {
+	case attempt.Sent:
+		status = model.DeliverySent
+		attempt.NextAttemptAt = nil
+	case attempt.NextAttemptAt == nil:
+		status = model.DeliveryFailed
+	}
+	var version, lastError any
+	if attempt.Version > 0 {
+		version = attempt.Version
+	}
+	if attempt.Error != "" {
+		lastError = attempt.Error
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	// A delivery failed meanwhile, by removing its destination, stays failed.
+	_, err := s.db.Exec(ctx, `
+		UPDATE story_deliveries
+		SET status = $2,
+		    version = COALESCE($3, version),
+		    attempts = attempts + 1,
+		    last_error = $4,
+		    last_attempt_at = now(),
+		    next_attempt_at = $5,
+		    sent_at = CASE WHEN $2 = 'sent' THEN now() END
+		WHERE id = $1
+		  AND status = 'pending'
+	`, deliveryID, string(status), version, lastError, attempt.NextAttemptAt)
+	return err
+}
+
+func scanDeliveryDestination(row rowScanner) (model.DeliveryDestination, error) {
+	var (
+		destination model.DeliveryDestination
+		createdAt   time.Time
+	)
+	if err := row.Scan(&destination.ID, &destination.Name, &destination.Address, &createdAt); err != nil {
+		return model.DeliveryDestination{}, err
+	}
+	destination.CreatedAt = createdAt.UTC().Format(time.RFC3339Nano)
+	return destination, nil
+}
+
+func scanStoryDelivery(row rowScanner) (model.StoryDelivery, error) {
+	var (
+		delivery      model.StoryDelivery
+		destinationID sql.NullString
+		status        string
+		version       sql.NullInt64
+		lastError     sql.NullString
+		createdAt     time.Time
+		sentAt        sql.NullTime
+	)
+	if err := row.Scan(&delivery.ID, &delivery.Slug, &destinationID, &delivery.Address, &status, &version,
+		&delivery.Attempts, &lastError, &createdAt, &sentAt); err != nil {
+		return model.StoryDelivery{}, err
+	}
+	delivery.DestinationID = nullStringValue(destinationID)
+	delivery.Status = model.DeliveryStatus(status)
+	delivery.Version = nullIntValue(version)
+	delivery.Error = nullStringValue(lastError)
+	delivery.CreatedAt = createdAt.UTC().Format(time.RFC3339Nano)
+	delivery.SentAt = nullTimeString(sentAt)
+	return delivery, nil
+}
@@ -0,0 +1,67 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"pandapages/api/internal/model"
+)
+
+// DictionaryEntry returns the kept answer for word in language, or
+// sql.ErrNoRows for a word never looked up.
+func (s *Store) DictionaryEntry(ctx context.Context, language, word string) (model.DictionaryEntry, error) {
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	var (
+		raw       sql.NullString
+		fetchedAt time.Time
+	)
+	if err := s.reads().QueryRow(ctx, `
+		SELECT definition::text, fetched_at
+		FROM dictionary_entries
+		WHERE language = $1
+		  AND word = $2
+	`, language, word).Scan(&raw, &fetchedAt); err != nil {
+		return model.DictionaryEntry{}, err
+	}
+	out := model.DictionaryEntry{FetchedAt: fetchedAt}
+	if raw.Valid {
+		var definition model.Definition
+		if err := json.Unmarshal([]byte(raw.String), &definition); err != nil {
+			return model.DictionaryEntry{}, fmt.Errorf("decode dictionary entry: %w", err)
+		}
+		out.Definition = &definition
+	}
+	return out, nil
+}
+
+// DictionarySave keeps the dictionary's answer for word in language,
+// replacing an older one; a nil definition records that it did not know the
+// word.
+func (s *Store) DictionarySave(ctx context.Context, language, word string, definition *model.Definition) error {
+	var raw *string
+	if definition != nil {
+		data, err := json.Marshal(definition)
+		if err != nil {
+			return err
+		}
+		text := string(data)
+		raw = &text
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	_, err := s.db.Exec(ctx, `
+		INSERT INTO dictionary_entries (language, word, definition)
+		VALUES ($1, $2, $3::jsonb)
+		ON CONFLICT (language, word) DO UPDATE
+		SET definition = EXCLUDED.definition,
+		    fetched_at = now()
+	`, language, word, raw)
+	return err
+}
@@ -0,0 +1,356 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"pandapages/api/internal/model"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// digestStreakDays bounds how far back a reading streak is counted.
+const digestStreakDays = 366
+
+// recordReadingActivity credits a minute of reading, the current one when
+// minute is nil, to the child profile active for profileID, inside the
+// caller's transaction.
+func recordReadingActivity(ctx context.Context, tx pgx.Tx, accountID, profileID, storyID string, minute *time.Time, finished bool) error {
+	_, err := tx.Exec(ctx, `
+		INSERT INTO reading_activity (account_id, story_id, minute, child_profile_id, finished)
+		SELECT $1, $2, date_trunc('minute', COALESCE($5::timestamptz, now())), child.id, $4
+		FROM (SELECT 1) AS one
+		LEFT JOIN profile_settings AS settings
+		  ON settings.profile_id = $3
+		LEFT JOIN child_profiles AS child
+		  ON child.id = settings.active_child_profile_id
+		 AND child.account_id = $1
+		ON CONFLICT (account_id, story_id, minute) DO UPDATE
+		SET child_profile_id = EXCLUDED.child_profile_id,
+		    finished = reading_activity.finished OR EXCLUDED.finished
+	`, accountID, storyID, profileID, finished, minute)
+	return err
+}
+
+// DigestSettings returns the account's weekly email choices, or the
+// defaults when it has made none.
+func (s *Store) DigestSettings(ctx context.Context, accountID string) (model.DigestSettings, error) {
+	accountID = strings.TrimSpace(accountID)
+	if !accountIDRe.MatchString(accountID) {
+		return model.DigestSettings{}, fmt.Errorf("account required")
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	settings, err := scanDigestSettings(s.db.QueryRow(ctx, `
+		SELECT enabled, address, send_day, to_char(send_time, 'HH24:MI'), time_zone
+		FROM digest_settings
+		WHERE account_id = $1
+	`, accountID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.DefaultDigestSettings, nil
+	}
+	return settings, err
+}
+
+// PutDigestSettings replaces the account's weekly email choices. The time
+// zone must be one the database knows, since digests are timed there.
+func (s *Store) PutDigestSettings(ctx context.Context, accountID string, settings model.DigestSettings) (model.DigestSettings, error) {
+	accountID = strings.TrimSpace(accountID)
+	if !accountIDRe.MatchString(accountID) {
+		return model.DigestSettings{}, fmt.Errorf("account required")
+	}
+	day := digestDay(settings.Day)
+	if day < 0 {
+		return model.DigestSettings{}, fmt.Errorf("digest day invalid")
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	saved, err := scanDigestSettings(s.db.QueryRow(ctx, `
+		INSERT INTO digest_settings (account_id, enabled, address, send_day, send_time, time_zone)
+		SELECT $1, $2, $3, $4, $5::time, zone.name
+		FROM pg_timezone_names AS zone
+		WHERE zone.name = $6
+		ON CONFLICT (account_id) DO UPDATE
+		SET enabled = EXCLUDED.enabled,
+		    address = EXCLUDED.address,
+		    send_day = EXCLUDED.send_day,
+		    send_time = EXCLUDED.send_time,
+		    time_zone = EXCLUDED.time_zone,
+		    retry_at = NULL,
+		    attempts = 0,
+		    updated_at = now()
+		RETURNING enabled, address, send_day, to_char(send_time, 'HH24:MI'), time_zone
+	`, accountID, settings.Enabled, settings.Address, day, settings.Time, settings.TimeZone))
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.DigestSettings{}, fmt.Errorf("%w", model.ErrDigestTimeZone)
+	}
+	return saved, err
+}
+
+// DigestClaim leases up to limit accounts whose digest is due: it is their
+// send day in their time zone, the send time has passed, and this week's has
+// not gone yet.
+func (s *Store) DigestClaim(ctx context.Context, limit int, lease time.Duration) ([]model.DueDigest, error) {
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	rows, err := s.db.Query(ctx, `
+		WITH due AS (
+			SELECT account_id
+			FROM digest_settings
+			WHERE enabled
+			  AND address <> ''
+			  AND extract(dow FROM now() AT TIME ZONE time_zone) = send_day
+			  AND (now() AT TIME ZONE time_zone)::time >= send_time
+			  AND (last_sent_on IS NULL OR last_sent_on < (now() AT TIME ZONE time_zone)::date)
+			  AND (retry_at IS NULL OR retry_at <= now())
+			ORDER BY account_id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE digest_settings AS settings
+		SET retry_at = now() + make_interval(secs => $2)
+		FROM due
+		WHERE settings.account_id = due.account_id
+		RETURNING settings.account_id, settings.address, settings.attempts
+	`, limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []model.DueDigest{}
+	for rows.Next() {
+		var item model.DueDigest
+		if err := rows.Scan(&item.AccountID, &item.Address, &item.Attempts); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// DigestRecordAttempt settles a claimed digest: once sent or given up on,
+// the week is done; otherwise it is tried again at NextAttemptAt, if that is
+// still the send day.
+func (s *Store) DigestRecordAttempt(ctx context.Context, accountID string, attempt model.DigestAttempt) error {
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	var err error
+	if attempt.Sent || attempt.NextAttemptAt == nil {
+		_, err = s.db.Exec(ctx, `
+			UPDATE digest_settings
+			SET last_sent_on = (now() AT TIME ZONE time_zone)::date,
+			    retry_at = NULL,
+			    attempts = 0
+			WHERE account_id = $1
+		`, accountID)
+	} else {
+		_, err = s.db.Exec(ctx, `
+			UPDATE digest_settings
+			SET retry_at = $2,
+			    attempts = attempts + 1
+			WHERE account_id = $1
+		`, accountID, *attempt.NextAttemptAt)
+	}
+	return err
+}
+
+// readingDay is the minutes one child read on one local date.
+type readingDay struct {
+	childID   string
+	childName string
+	day       string
+	minutes   int
+}
+
+// readingBook is a story one child finished in the report's week.
+type readingBook struct {
+	childID string
+	book    model.FinishedBook
+}
+
+// ReadingReport reports the account's reading over the seven local days
+// ending on the date at is in the account's digest time zone.
+func (s *Store) ReadingReport(ctx context.Context, accountID string, at time.Time) (model.ReadingReport, error) {
+	accountID = strings.TrimSpace(accountID)
+	if !accountIDRe.MatchString(accountID) {
+		return model.ReadingReport{}, fmt.Errorf("account required")
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	var zone, to string
+	if err := s.reads().QueryRow(ctx, `
+		SELECT zone.name, ($2::timestamptz AT TIME ZONE zone.name)::date::text
+		FROM (
+			SELECT COALESCE((SELECT time_zone FROM digest_settings WHERE account_id = $1), 'UTC') AS name
+		) AS zone
+	`, accountID, at).Scan(&zone, &to); err != nil {
+		return model.ReadingReport{}, err
+	}
+
+	rows, err := s.reads().Query(ctx, `
+		SELECT COALESCE(activity.child_profile_id::text, ''), COALESCE(child.name, ''),
+		       (activity.minute AT TIME ZONE $2)::date::text, count(DISTINCT activity.minute)
+		FROM reading_activity AS activity
+		LEFT JOIN child_profiles AS child
+		  ON child.id = activity.child_profile_id
+		 AND child.account_id = activity.account_id
+		WHERE activity.account_id = $1
+		  AND activity.minute <= $3
+		  AND (activity.minute AT TIME ZONE $2)::date > $4::date - $5::int
+		GROUP BY 1, 2, 3
+	`, accountID, zone, at, to, digestStreakDays)
+	if err != nil {
+		return model.ReadingReport{}, err
+	}
+	var days []readingDay
+	for rows.Next() {
+		var day readingDay
+		if err := rows.Scan(&day.childID, &day.childName, &day.day, &day.minutes); err != nil {
+			rows.Close()
+			return model.ReadingReport{}, err
+		}
+		days = append(days, day)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return model.ReadingReport{}, err
+	}
+
+	rows, err = s.reads().Query(ctx, `
+		SELECT DISTINCT COALESCE(activity.child_profile_id::text, ''), story.slug, story.title
+		FROM reading_activity AS activity
+		JOIN stories AS story
+		  ON story.id = activity.story_id
+		WHERE activity.account_id = $1
+		  AND activity.finished
+		  AND activity.minute <= $3
+		  AND (activity.minute AT TIME ZONE $2)::date > $4::date - 7
+		ORDER BY 2
+	`, accountID, zone, at, to)
+	if err != nil {
+		return model.ReadingReport{}, err
+	}
+	defer rows.Close()
+	var books []readingBook
+	for rows.Next() {
+		var book readingBook
+		if err := rows.Scan(&book.childID, &book.book.Slug, &book.book.Title); err != nil {
+			return model.ReadingReport{}, err
+		}
+		books = append(books, book)
+	}
+	if err := rows.Err(); err != nil {
+		return model.ReadingReport{}, err
+	}
+	return buildReadingReport(zone, to, days, books)
+}
+
+// buildReadingReport gathers each child's week from their daily minutes and
+// finished books. Children who read nothing in the week are left out.
+func buildReadingReport(zone, to string, days []readingDay, books []readingBook) (model.ReadingReport, error) {
+	end, err := time.Parse(time.DateOnly, to)
+	if err != nil {
+		return model.ReadingReport{}, err
+	}
+	from := end.AddDate(0, 0, -6).Format(time.DateOnly)
+	report := model.ReadingReport{From: from, To: to, TimeZone: zone, Children: []model.ChildReading{}}
+
+	byChild := map[string]*model.ChildReading{}
+	read := map[string]map[string]bool{}
+	var order []string
+	child := func(id, name string) *model.ChildReading {
+		if reading, ok := byChild[id]; ok {
+			return reading
+		}
+		reading := &model.ChildReading{Name: name, BooksFinished: []model.FinishedBook{}}
+		if id != "" {
+			reading.ChildProfileID = &id
+		}
+		byChild[id] = reading
+		read[id] = map[string]bool{}
+		order = append(order, id)
+		return reading
+	}
+	for _, day := range days {
+		reading := child(day.childID, day.childName)
+		read[day.childID][day.day] = true
+		if day.day >= from && day.day <= to {
+			reading.Minutes += day.minutes
+		}
+	}
+	for _, book := range books {
+		if reading, ok := byChild[book.childID]; ok {
+			reading.BooksFinished = append(reading.BooksFinished, book.book)
+		}
+	}
+
+	sort.SliceStable(order, func(i, j int) bool {
+		// Named children first, by name; unassigned reading last.
+		a, b := byChild[order[i]], byChild[order[j]]
+		if (order[i] == "") != (order[j] == "") {
+			return order[j] == ""
+		}
+		return a.Name < b.Name
+	})
+	for _, id := range order {
+		reading := byChild[id]
+		if reading.Minutes == 0 && len(reading.BooksFinished) == 0 {
+			continue
+		}
+		reading.StreakDays = readingStreak(read[id], end)
+		report.Children = append(report.Children, *reading)
+	}
+	return report, nil
+}
+
+// readingStreak counts the run of local dates in read that ends on end, or
+// the day before when nothing has been read on end yet.
+func readingStreak(read map[string]bool, end time.Time) int {
+	streak, day := 0, end
+	if !read[day.Format(time.DateOnly)] {
+		day = day.AddDate(0, 0, -1)
+	}
+	for read[day.Format(time.DateOnly)] {
+		streak++
+		day = day.AddDate(0, 0, -1)
+	}
+	return streak
+}
+
+// digestDay numbers a day name from Sunday, or gives -1.
+func digestDay(name string) int {
+	for index, day := range model.DigestDays {
+		if day == name {
+			return index
+		}
+	}
+	return -1
+}
+
+func scanDigestSettings(row pgx.Row) (model.DigestSettings, error) {
+	var (
+		settings model.DigestSettings
+		day      int
+	)
+	if err := row.Scan(&settings.Enabled, &settings.Address, &day, &settings.Time, &settings.TimeZone); err != nil {
+		return model.DigestSettings{}, err
+	}
+	if day < 0 || day >= len(model.DigestDays) {
+		return model.DigestSettings{}, fmt.Errorf("stored digest day is invalid")
+	}
+	settings.Day = model.DigestDays[day]
+	return settings, nil
+}
@@ -0,0 +1,50 @@
+package db
+
+import (
+	"testing"
+
+	"pandapages/api/internal/model"
+)
+
+func TestBuildReadingReportCountsTheWeekAndStreaks(t *testing.T) {
+	days := []readingDay{
+		{childID: "ada", childName: "Ada", day: "2026-10-17", minutes: 10},
+		{childID: "ada", childName: "Ada", day: "2026-10-16", minutes: 5},
+		{childID: "ada", childName: "Ada", day: "2026-10-15", minutes: 5},
+		// Before the week: counts toward the streak, not the minutes.
+		{childID: "ada", childName: "Ada", day: "2026-10-10", minutes: 30},
+		{childID: "ada", childName: "Ada", day: "2026-10-11", minutes: 1},
+		{childID: "ada", childName: "Ada", day: "2026-10-12", minutes: 1},
+		{childID: "ada", childName: "Ada", day: "2026-10-13", minutes: 1},
+		{childID: "ada", childName: "Ada", day: "2026-10-14", minutes: 1},
+		// Not read today yet: the streak ending yesterday still counts.
+		{childID: "bo", childName: "Bo", day: "2026-10-16", minutes: 7},
+		{childID: "bo", childName: "Bo", day: "2026-10-15", minutes: 3},
+		{childID: "bo", childName: "Bo", day: "2026-10-13", minutes: 3},
+		{childID: "", day: "2026-10-12", minutes: 2},
+		// Nothing in the week: left out.
+		{childID: "cy", childName: "Cy", day: "2026-09-01", minutes: 9},
+	}
+	books := []readingBook{{childID: "bo", book: model.FinishedBook{Slug: "owls", Title: "Owls"}}}
+
+	report, err := buildReadingReport("Europe/London", "2026-10-17", days, books)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.From != "2026-10-11" || report.To != "2026-10-17" || report.TimeZone != "Europe/London" {
+		t.Fatalf("report = %+v", report)
+	}
+	if len(report.Children) != 3 {
+		t.Fatalf("children = %+v", report.Children)
+	}
+	ada, bo, unassigned := report.Children[0], report.Children[1], report.Children[2]
+	if ada.Name != "Ada" || ada.ChildProfileID == nil || ada.Minutes != 24 || ada.StreakDays != 8 || len(ada.BooksFinished) != 0 {
+		t.Fatalf("ada = %+v", ada)
+	}
+	if bo.Name != "Bo" || bo.Minutes != 13 || bo.StreakDays != 2 || len(bo.BooksFinished) != 1 {
+		t.Fatalf("bo = %+v", bo)
+	}
+	if unassigned.ChildProfileID != nil || unassigned.Minutes != 2 || unassigned.StreakDays != 0 {
+		t.Fatalf("unassigned = %+v", unassigned)
+	}
+}
@@ -0,0 +1,204 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"pandapages/api/internal/model"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// AdminGetDiscussion lists every chapter of a story version with its
+// discussion prompts, if any have been written. Chapters are cut as a quiz's
+// are.
+func (s *Store) AdminGetDiscussion(ctx context.Context, accountID, slug, versionID string) (model.AdminDiscussionResponse, error) {
+	accountID, versionID = strings.TrimSpace(accountID), strings.TrimSpace(versionID)
+	if err := validQuizTarget(accountID, slug, versionID); err != nil {
+		return model.AdminDiscussionResponse{}, err
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	chapters, err := s.adminQuizChapters(ctx, accountID, slug, versionID)
+	if err != nil {
+		return model.AdminDiscussionResponse{}, err
+	}
+	out := model.AdminDiscussionResponse{Slug: slug, VersionID: versionID, Chapters: make([]model.AdminChapterDiscussion, len(chapters))}
+	for index, chapter := range chapters {
+		out.Chapters[index] = model.AdminChapterDiscussion{ChapterDiscussion: model.ChapterDiscussion{
+			Chapter: chapter.Chapter, ChapterKey: chapter.ChapterKey, ChapterOccurrence: chapter.ChapterOccurrence,
+			Title: chapter.Title, Questions: []string{},
+		}}
+	}
+
+	rows, err := s.db.Query(ctx, `
+		SELECT chapter, questions::text, updated_at
+		FROM story_discussions
+		WHERE account_id = $1
+		  AND story_version_id = $2
+		ORDER BY chapter ASC
+	`, accountID, versionID)
+	if err != nil {
+		return model.AdminDiscussionResponse{}, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		discussion, chapter, err := scanAdminChapterDiscussion(rows)
+		if err != nil {
+			return model.AdminDiscussionResponse{}, err
+		}
+		if chapter >= 1 && chapter <= len(out.Chapters) {
+			current := &out.Chapters[chapter-1]
+			current.Questions, current.UpdatedAt = discussion.Questions, discussion.UpdatedAt
+		}
+	}
+	return out, rows.Err()
+}
+
+func scanAdminChapterDiscussion(row pgx.Row) (model.AdminChapterDiscussion, int, error) {
+	var (
+		out       model.AdminChapterDiscussion
+		chapter   int
+		questions string
+		updatedAt time.Time
+	)
+	if err := row.Scan(&chapter, &questions, &updatedAt); err != nil {
+		return model.AdminChapterDiscussion{}, 0, err
+	}
+	if err := json.Unmarshal([]byte(questions), &out.Questions); err != nil {
+		return model.AdminChapterDiscussion{}, 0, fmt.Errorf("decode discussion questions: %w", err)
+	}
+	updated := updatedAt.UTC().Format(time.RFC3339Nano)
+	out.UpdatedAt = &updated
+	return out, chapter, nil
+}
+
+// AdminSaveDiscussion writes a chapter's discussion prompts, replacing any
+// it had.
+func (s *Store) AdminSaveDiscussion(ctx context.Context, accountID, slug, versionID string, chapter int, questions []string) (model.AdminChapterDiscussion, error) {
+	accountID, versionID = strings.TrimSpace(accountID), strings.TrimSpace(versionID)
+	if err := validQuizTarget(accountID, slug, versionID); err != nil {
+		return model.AdminChapterDiscussion{}, err
+	}
+	encoded, err := json.Marshal(questions)
+	if err != nil {
+		return model.AdminChapterDiscussion{}, err
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	chapters, err := s.adminQuizChapters(ctx, accountID, slug, versionID)
+	if err != nil {
+		return model.AdminChapterDiscussion{}, err
+	}
+	if chapter < 1 || chapter > len(chapters) {
+		return model.AdminChapterDiscussion{}, fmt.Errorf("%w", model.ErrQuizChapterNotFound)
+	}
+	source := chapters[chapter-1]
+	discussion, _, err := scanAdminChapterDiscussion(s.writes(ctx).QueryRow(ctx, `
+		INSERT INTO story_discussions (
+			story_version_id, chapter, account_id, chapter_key, chapter_occurrence, title, questions
+		)
+		VALUES ($2, $3, $1, $4, $5, $6, $7::jsonb)
+		ON CONFLICT (story_version_id, chapter) DO UPDATE
+		SET chapter_key = EXCLUDED.chapter_key,
+		    chapter_occurrence = EXCLUDED.chapter_occurrence,
+		    title = EXCLUDED.title,
+		    questions = EXCLUDED.questions,
+		    updated_at = now()
+		RETURNING chapter, questions::text, updated_at
+	`, accountID, versionID, chapter, source.ChapterKey, source.ChapterOccurrence, source.Title, string(encoded)))
+	if err != nil {
+		return model.AdminChapterDiscussion{}, err
+	}
+	discussion.Chapter, discussion.ChapterKey, discussion.ChapterOccurrence, discussion.Title = source.Chapter, source.ChapterKey, source.ChapterOccurrence, source.Title
+	return discussion, nil
+}
+
+// AdminDeleteDiscussion removes a chapter's discussion prompts. A chapter
+// without any is ErrDiscussionNotFound.
+func (s *Store) AdminDeleteDiscussion(ctx context.Context, accountID, slug, versionID string, chapter int) error {
+	accountID, versionID = strings.TrimSpace(accountID), strings.TrimSpace(versionID)
+	if err := validQuizTarget(accountID, slug, versionID); err != nil {
+		return err
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	tag, err := s.writes(ctx).Exec(ctx, `
+		DELETE FROM story_discussions AS discussion
+		USING story_versions AS version, stories AS story
+		WHERE discussion.story_version_id = $3
+		  AND discussion.chapter = $4
+		  AND discussion.account_id = $1
+		  AND version.id = discussion.story_version_id
+		  AND story.id = version.story_id
+		  AND story.account_id = $1
+		  AND story.slug = $2
+	`, accountID, slug, versionID, chapter)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%w", model.ErrDiscussionNotFound)
+	}
+	return nil
+}
+
+// ReaderDiscussion returns the discussion prompts of a story's published
+// version. A story with none reports sql.ErrNoRows, as a missing story does.
+func (s *Store) ReaderDiscussion(ctx context.Context, accountID, slug string) (model.StoryDiscussion, error) {
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	rows, err := s.reads().Query(ctx, `
+		SELECT st.slug, version.version, discussion.chapter, discussion.chapter_key,
+			discussion.chapter_occurrence, discussion.title, discussion.questions::text
+		FROM stories st
+		JOIN story_versions AS version
+		  ON version.id = st.published_version_id
+		 AND version.story_id = st.id
+		JOIN story_discussions AS discussion
+		  ON discussion.story_version_id = version.id
+		WHERE st.account_id = $1
+		  AND st.slug = $2
+		  AND st.is_published = true
+		ORDER BY discussion.chapter ASC
+	`, accountID, slug)
+	if err != nil {
+		return model.StoryDiscussion{}, err
+	}
+	defer rows.Close()
+	out := model.StoryDiscussion{Chapters: []model.ChapterDiscussion{}}
+	for rows.Next() {
+		var (
+			chapter           model.ChapterDiscussion
+			chapterKey, title sql.NullString
+			chapterOccurrence sql.NullInt64
+			questions         string
+		)
+		if err := rows.Scan(&out.Slug, &out.Version, &chapter.Chapter, &chapterKey, &chapterOccurrence, &title, &questions); err != nil {
+			return model.StoryDiscussion{}, err
+		}
+		if err := json.Unmarshal([]byte(questions), &chapter.Questions); err != nil {
+			return model.StoryDiscussion{}, fmt.Errorf("decode discussion questions: %w", err)
+		}
+		chapter.ChapterKey, chapter.ChapterOccurrence, chapter.Title = nullStringValue(chapterKey), nullIntValue(chapterOccurrence), nullStringValue(title)
+		out.Chapters = append(out.Chapters, chapter)
+	}
+	if err := rows.Err(); err != nil {
+		return model.StoryDiscussion{}, err
+	}
+	if len(out.Chapters) == 0 {
+		return model.StoryDiscussion{}, s.removedOr(ctx, accountID, slug, sql.ErrNoRows)
+	}
+	return out, nil
+}
@@ -0,0 +1,129 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"pandapages/api/internal/model"
+)
+
+// RecordClientEvents adds a batch of Reader events to the account's daily
+// counts, credited to the child profile active now. Segment views and read
+// aloud also mark their minute as reading time for the weekly report.
+// Events for stories the account has not published are dropped and counted.
+func (s *Store) RecordClientEvents(ctx context.Context, accountID string, events []model.ClientEvent) (model.ClientEventsResponse, error) {
+	accountID = strings.TrimSpace(accountID)
+	if !accountIDRe.MatchString(accountID) {
+		return model.ClientEventsResponse{}, fmt.Errorf("account required")
+	}
+	if len(events) == 0 {
+		return model.ClientEventsResponse{}, nil
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	profileID, err := s.getDefaultProfileID(ctx, accountID)
+	if err != nil {
+		return model.ClientEventsResponse{}, err
+	}
+
+	slugs := make([]string, 0, len(events))
+	for _, event := range events {
+		slugs = append(slugs, event.Slug)
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return model.ClientEventsResponse{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	rows, err := tx.Query(ctx, `
+		SELECT slug, id
+		FROM stories
+		WHERE account_id = $1
+		  AND slug = ANY($2)
+		  AND is_published
+	`, accountID, slugs)
+	if err != nil {
+		return model.ClientEventsResponse{}, err
+	}
+	storyIDs := map[string]string{}
+	for rows.Next() {
+		var slug, id string
+		if err := rows.Scan(&slug, &id); err != nil {
+			rows.Close()
+			return model.ClientEventsResponse{}, err
+		}
+		storyIDs[slug] = id
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return model.ClientEventsResponse{}, err
+	}
+
+	type countKey struct {
+		storyID string
+		kind    model.ClientEventKind
+		day     string
+	}
+	type minuteKey struct {
+		storyID string
+		minute  time.Time
+	}
+	var out model.ClientEventsResponse
+	counts := map[countKey]int{}
+	var keys []countKey
+	minutes := map[minuteKey]bool{}
+	var minuteKeys []minuteKey
+	for _, event := range events {
+		storyID, ok := storyIDs[event.Slug]
+		if !ok {
+			out.Dropped++
+			continue
+		}
+		out.Recorded++
+		key := countKey{storyID: storyID, kind: event.Kind, day: event.At.UTC().Format(time.DateOnly)}
+		if counts[key] == 0 {
+			keys = append(keys, key)
+		}
+		counts[key]++
+		if event.Kind == model.ClientEventSegmentViewed || event.Kind == model.ClientEventReadAloudUsed {
+			minute := minuteKey{storyID: storyID, minute: event.At.UTC().Truncate(time.Minute)}
+			if !minutes[minute] {
+				minutes[minute] = true
+				minuteKeys = append(minuteKeys, minute)
+			}
+		}
+	}
+
+	for _, key := range keys {
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO story_event_counts (account_id, story_id, child_profile_id, kind, day, count)
+			SELECT $1, $2, child.id, $4, $5::date, $6
+			FROM (SELECT 1) AS one
+			LEFT JOIN profile_settings AS settings
+			  ON settings.profile_id = $3
+			LEFT JOIN child_profiles AS child
+			  ON child.id = settings.active_child_profile_id
+			 AND child.account_id = $1
+			ON CONFLICT ON CONSTRAINT story_event_counts_key DO UPDATE
+			SET count = story_event_counts.count + EXCLUDED.count
+		`, accountID, key.storyID, profileID, string(key.kind), key.day, counts[key]); err != nil {
+			return model.ClientEventsResponse{}, err
+		}
+	}
+	for _, key := range minuteKeys {
+		minute := key.minute
+		if err := recordReadingActivity(ctx, tx, accountID, profileID, key.storyID, &minute, false); err != nil {
+			return model.ClientEventsResponse{}, err
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return model.ClientEventsResponse{}, err
+	}
+	return out, nil
+}
@@ -0,0 +1,28 @@
+package db
+
+import (
+	"context"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// shareFlight runs load once for every concurrent caller with the same key.
+// Each caller still gives up when its own ctx ends; load itself should not
+// depend on any one caller's ctx, or the first to leave fails the rest.
+// Callers share the result, so one that mutates it must copy it first.
+func shareFlight[T any](ctx context.Context, group *singleflight.Group, key string, load func() (T, error)) (T, error) {
+	flight := group.DoChan(key, func() (any, error) {
+		return load()
+	})
+	select {
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	case result := <-flight:
+		if result.Err != nil {
+			var zero T
+			return zero, result.Err
+		}
+		return result.Val.(T), nil
+	}
+}
@@ -0,0 +1,49 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"testing/synctest"
+
+	"golang.org/x/sync/singleflight"
+)
+
+func TestShareFlightRunsConcurrentLoadsOnce(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		var group singleflight.Group
+		loads := 0
+		release := make(chan struct{})
+		load := func() (string, error) {
+			loads++
+			<-release
+			return "story", nil
+		}
+
+		var readers sync.WaitGroup
+		results := make([]string, 4)
+		for index := range results {
+			readers.Go(func() { results[index], _ = shareFlight(t.Context(), &group, "account/bedtime", load) })
+		}
+		synctest.Wait()
+
+		// A device that gives up leaves without failing the others.
+		leaving, leave := context.WithCancel(t.Context())
+		leave()
+		if _, err := shareFlight(leaving, &group, "account/bedtime", load); !errors.Is(err, context.Canceled) {
+			t.Fatalf("cancelled caller err = %v", err)
+		}
+
+		close(release)
+		readers.Wait()
+		if loads != 1 {
+			t.Fatalf("loads = %d, want 1", loads)
+		}
+		for index, result := range results {
+			if result != "story" {
+				t.Fatalf("reader %d got %q", index, result)
+			}
+		}
+	})
+}
@@ -0,0 +1,194 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"pandapages/api/internal/model"
+	"pandapages/api/internal/storyingest"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// AdminGenerationProfiles returns the child and prompt profiles a generation
+// request names, or the account's active ones for empty IDs. A missing
+// active profile is returned empty; a named one that is missing or belongs
+// to another account is ErrProfileNotFound.
+func (s *Store) AdminGenerationProfiles(ctx context.Context, accountID, childProfileID, promptProfileID string) (model.ChildProfile, model.PromptProfile, error) {
+	accountID = strings.TrimSpace(accountID)
+	if !accountIDRe.MatchString(accountID) {
+		return model.ChildProfile{}, model.PromptProfile{}, fmt.Errorf("account required")
+	}
+	childProfileID = strings.TrimSpace(childProfileID)
+	promptProfileID = strings.TrimSpace(promptProfileID)
+	if (childProfileID != "" && !accountIDRe.MatchString(childProfileID)) ||
+		(promptProfileID != "" && !accountIDRe.MatchString(promptProfileID)) {
+		return model.ChildProfile{}, model.PromptProfile{}, fmt.Errorf("%w", model.ErrProfileNotFound)
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	var (
+		childID, childName   sql.NullString
+		ageMonths            sql.NullInt32
+		interests, sens      []byte
+		promptID, promptName sql.NullString
+		rules                []byte
+		schemaVersion        sql.NullInt32
+	)
+	// The named profile wins over the active one; a named profile that
+	// matches nothing leaves its ID column NULL, which is reported as missing.
+	err := s.reads().QueryRow(ctx, `
+		WITH active AS (
+			SELECT ps.active_child_profile_id, ps.active_prompt_profile_id
+			FROM profile_settings ps
+			JOIN profiles p
+			  ON p.id = ps.profile_id
+			 AND p.account_id = $1
+			ORDER BY p.created_at ASC, p.id ASC
+			LIMIT 1
+		)
+		SELECT
+			cp.id::text, cp.name, cp.age_months,
+			COALESCE(cp.interests, '[]'::jsonb), COALESCE(cp.sensitivities, '[]'::jsonb),
+			pp.id::text, pp.name, COALESCE(pp.rules, '{}'::jsonb), pp.schema_version
+		FROM (SELECT 1) AS one
+		LEFT JOIN active ON true
+		LEFT JOIN child_profiles cp
+		  ON cp.id = COALESCE(NULLIF($2, '')::uuid, active.active_child_profile_id)
+		 AND cp.account_id = $1
+		LEFT JOIN prompt_profiles pp
+		  ON pp.id = COALESCE(NULLIF($3, '')::uuid, active.active_prompt_profile_id)
+		 AND pp.account_id = $1
+	`, accountID, childProfileID, promptProfileID).Scan(
+		&childID, &childName, &ageMonths, &interests, &sens,
+		&promptID, &promptName, &rules, &schemaVersion,
+	)
+	if err != nil {
+		return model.ChildProfile{}, model.PromptProfile{}, err
+	}
+	if (childProfileID != "" && !childID.Valid) || (promptProfileID != "" && !promptID.Valid) {
+		return model.ChildProfile{}, model.PromptProfile{}, fmt.Errorf("%w", model.ErrProfileNotFound)
+	}
+
+	var child model.ChildProfile
+	if childID.Valid {
+		child = model.ChildProfile{ID: childID.String, Name: strings.TrimSpace(childName.String), AgeMonths: int(ageMonths.Int32)}
+		_ = json.Unmarshal(interests, &child.Interests)
+		_ = json.Unmarshal(sens, &child.Sensitivities)
+	}
+	var prompt model.PromptProfile
+	if promptID.Valid {
+		prompt = model.PromptProfile{ID: promptID.String, Name: strings.TrimSpace(promptName.String), SchemaVersion: int(schemaVersion.Int32), Rules: rules}
+	}
+	return child, prompt, nil
+}
+
+// AdminRecordGeneration writes one generation attempt. A succeeded record
+// names the version it wrote, which must belong to the account's story.
+func (s *Store) AdminRecordGeneration(ctx context.Context, accountID string, record model.GenerationRecord) (model.StoryGeneration, error) {
+	accountID = strings.TrimSpace(accountID)
+	if !accountIDRe.MatchString(accountID) {
+		return model.StoryGeneration{}, fmt.Errorf("account required")
+	}
+	if record.Status != model.GenerationSucceeded && record.Status != model.GenerationFailed {
+		return model.StoryGeneration{}, fmt.Errorf("generation status %q is invalid", record.Status)
+	}
+	if record.Status == model.GenerationSucceeded && (storyingest.ValidateSlug(record.Slug) != nil || !accountIDRe.MatchString(record.VersionID)) {
+		return model.StoryGeneration{}, fmt.Errorf("%w", model.ErrAdminStoryNotFound)
+	}
+	request, response := jsonObjectOrEmpty(record.Request), jsonObjectOrEmpty(record.Response)
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	// Profiles are linked only when they are the account's; the rows are
+	// history, so a profile deleted later just leaves the link NULL.
+	row := s.writes(ctx).QueryRow(ctx, `
+		WITH version AS (
+			SELECT story.id AS story_id, version.id AS version_id
+			FROM stories AS story
+			JOIN story_versions AS version
+			  ON version.story_id = story.id
+			WHERE story.account_id = $1
+			  AND story.slug = $2
+			  AND version.id = NULLIF($3, '')::uuid
+		)
+		INSERT INTO generation_jobs (
+			account_id, status, story_id, story_version_id, child_profile_id, prompt_profile_id,
+			theme, request_payload, response_payload, model, prompt_version, error, tokens_in, tokens_out
+		)
+		SELECT
+			$1, $4::generation_status, version.story_id, version.version_id,
+			(SELECT id FROM child_profiles WHERE id = NULLIF($5, '')::uuid AND account_id = $1),
+			(SELECT id FROM prompt_profiles WHERE id = NULLIF($6, '')::uuid AND account_id = $1),
+			$7, $8::jsonb, $9::jsonb, NULLIF($10, ''), $11, $12, NULLIF($13, 0), NULLIF($14, 0)
+		FROM (SELECT 1) AS one
+		LEFT JOIN version ON true
+		WHERE $4 = 'failed' OR version.version_id IS NOT NULL
+		RETURNING id::text, model, prompt_version, theme, child_profile_id::text, prompt_profile_id::text,
+			tokens_in, tokens_out, created_at
+	`, accountID, record.Slug, record.VersionID, string(record.Status),
+		record.ChildProfileID, record.PromptProfileID, record.Theme, string(request), string(response),
+		record.Model, record.PromptVersion, record.Error, record.TokensIn, record.TokensOut)
+	generation, err := scanStoryGeneration(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.StoryGeneration{}, fmt.Errorf("%w", model.ErrAdminStoryNotFound)
+	}
+	return generation, err
+}
+
+// versionGeneration returns the latest succeeded generation that wrote a
+// version, or nil for a version written by hand.
+func versionGeneration(ctx context.Context, tx pgx.Tx, accountID, versionID string) (*model.StoryGeneration, error) {
+	generation, err := scanStoryGeneration(tx.QueryRow(ctx, `
+		SELECT id::text, model, prompt_version, theme, child_profile_id::text, prompt_profile_id::text,
+			tokens_in, tokens_out, created_at
+		FROM generation_jobs
+		WHERE account_id = $1
+		  AND story_version_id = $2
+		  AND status = 'succeeded'
+		ORDER BY created_at DESC, id DESC
+		LIMIT 1
+	`, accountID, versionID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &generation, nil
+}
+
+func scanStoryGeneration(row pgx.Row) (model.StoryGeneration, error) {
+	var (
+		out                 model.StoryGeneration
+		modelName, version  sql.NullString
+		theme               sql.NullString
+		childID, promptID   sql.NullString
+		tokensIn, tokensOut sql.NullInt64
+		createdAt           time.Time
+	)
+	if err := row.Scan(&out.ID, &modelName, &version, &theme, &childID, &promptID, &tokensIn, &tokensOut, &createdAt); err != nil {
+		return model.StoryGeneration{}, err
+	}
+	out.Model, out.PromptVersion, out.Theme = modelName.String, version.String, theme.String
+	out.ChildProfileID, out.PromptProfileID = nullStringValue(childID), nullStringValue(promptID)
+	out.TokensIn, out.TokensOut = nullIntValue(tokensIn), nullIntValue(tokensOut)
+	out.CreatedAt = createdAt.UTC().Format(time.RFC3339Nano)
+	return out, nil
+}
+
+func jsonObjectOrEmpty(raw json.RawMessage) json.RawMessage {
+	var object map[string]any
+	if json.Unmarshal(raw, &object) != nil || object == nil {
+		return json.RawMessage(`{}`)
+	}
+	return raw
+}
@@ -0,0 +1,113 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"pandapages/api/internal/model"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	// idempotencyTTL is how long a response is replayed to retries.
+	idempotencyTTL = 24 * time.Hour
+	// idempotencyStaleClaim frees a key whose request never finished,
+	// because the process died under it, for the next retry to run.
+	idempotencyStaleClaim = 5 * time.Minute
+)
+
+// IdempotencyClaim reserves key for a request with fingerprint. claimed is
+// true when the caller should run the request and then save or release its
+// response; otherwise prior is what the key already holds, which may be a
+// request still running (Status zero) or one with another fingerprint.
+func (s *Store) IdempotencyClaim(ctx context.Context, accountID, key, fingerprint string) (prior model.IdempotentResponse, claimed bool, err error) {
+	accountID = strings.TrimSpace(accountID)
+	if !accountIDRe.MatchString(accountID) {
+		return model.IdempotentResponse{}, false, fmt.Errorf("account required")
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return model.IdempotentResponse{}, false, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if _, err := tx.Exec(ctx, `DELETE FROM idempotency_keys WHERE expires_at <= now()`); err != nil {
+		return model.IdempotentResponse{}, false, err
+	}
+	err = tx.QueryRow(ctx, `
+		INSERT INTO idempotency_keys (account_id, key, fingerprint, expires_at)
+		VALUES ($1, $2, $3, now() + make_interval(secs => $4))
+		ON CONFLICT (account_id, key) DO UPDATE
+		SET fingerprint = EXCLUDED.fingerprint,
+		    created_at = now(),
+		    expires_at = EXCLUDED.expires_at
+		WHERE idempotency_keys.status IS NULL
+		  AND idempotency_keys.created_at <= now() - make_interval(secs => $5)
+		RETURNING true`,
+		accountID, key, fingerprint, idempotencyTTL.Seconds(), idempotencyStaleClaim.Seconds()).Scan(&claimed)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return model.IdempotentResponse{}, false, err
+	}
+	if !claimed {
+		var (
+			status      sql.NullInt32
+			contentType sql.NullString
+		)
+		if err := tx.QueryRow(ctx, `
+			SELECT fingerprint, status, content_type, body
+			FROM idempotency_keys
+			WHERE account_id = $1 AND key = $2`,
+			accountID, key).Scan(&prior.Fingerprint, &status, &contentType, &prior.Body); err != nil {
+			return model.IdempotentResponse{}, false, err
+		}
+		prior.Status = int(status.Int32)
+		prior.ContentType = contentType.String
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return model.IdempotentResponse{}, false, err
+	}
+	return prior, claimed, nil
+}
+
+// IdempotencySave records the response to a claimed key for retries to
+// replay.
+func (s *Store) IdempotencySave(ctx context.Context, accountID, key string, response model.IdempotentResponse) error {
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+	body := response.Body
+	if body == nil {
+		body = []byte{}
+	}
+	tag, err := s.db.Exec(ctx, `
+		UPDATE idempotency_keys
+		SET status = $4, content_type = NULLIF($5, ''), body = $6
+		WHERE account_id = $1 AND key = $2 AND fingerprint = $3 AND status IS NULL`,
+		accountID, key, response.Fingerprint, response.Status, response.ContentType, body)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() != 1 {
+		return fmt.Errorf("idempotency key is no longer claimed")
+	}
+	return nil
+}
+
+// IdempotencyRelease forgets a claimed key whose request failed, so that a
+// retry runs it again.
+func (s *Store) IdempotencyRelease(ctx context.Context, accountID, key, fingerprint string) error {
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+	_, err := s.db.Exec(ctx, `
+		DELETE FROM idempotency_keys
+		WHERE account_id = $1 AND key = $2 AND fingerprint = $3 AND status IS NULL`,
+		accountID, key, fingerprint)
+	return err
+}
@@ -0,0 +1,153 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"pandapages/api/internal/model"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("pandapages/api/internal/db")
+
+// queryBucketsMs are the upper bounds of the latency histogram buckets.
+var queryBucketsMs = []float64{1, 2, 5, 10, 25, 50, 100, 250, 500, 1000, 2500}
+
+// unlabelledQuery names queries issued outside a Store method.
+const unlabelledQuery = "other"
+
+type queryLabelKey struct{}
+type queryStartKey struct{}
+
+// withQueryLabel names the queries run with ctx after the Store method that
+// called s.ctx, so timings read as "Library" rather than as SQL text.
+func withQueryLabel(ctx context.Context, skip int) context.Context {
+	pc, _, _, ok := runtime.Caller(skip + 1)
+	if !ok {
+		return ctx
+	}
+	return context.WithValue(ctx, queryLabelKey{}, queryLabel(runtime.FuncForPC(pc).Name()))
+}
+
+// queryLabel reduces "pandapages/api/internal/db.(*Store).Library.func1" to
+// "Library".
+func queryLabel(function string) string {
+	function = function[strings.LastIndex(function, "/")+1:]
+	function = strings.TrimPrefix(function, "db.")
+	function = strings.TrimPrefix(function, "(*Store).")
+	if name, _, ok := strings.Cut(function, "."); ok {
+		return name
+	}
+	return function
+}
+
+// startStoreSpan opens the span a Store method's queries run under, named by
+// the label withQueryLabel gave ctx.
+func startStoreSpan(ctx context.Context) (context.Context, trace.Span) {
+	label, ok := ctx.Value(queryLabelKey{}).(string)
+	if !ok {
+		label = unlabelledQuery
+	}
+	return tracer.Start(ctx, "db.Store."+label,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("db.system.name", "postgresql")),
+	)
+}
+
+type queryHistogram struct {
+	count   int64
+	totalMs float64
+	buckets []int64
+}
+
+// queryTracer times every statement, logs those slower than its threshold
+// and keeps a latency histogram per label.
+type queryTracer struct {
+	slow time.Duration
+
+	mu         sync.Mutex
+	histograms map[string]*queryHistogram
+}
+
+func newQueryTracer(slow time.Duration) *queryTracer {
+	return &queryTracer{slow: slow, histograms: map[string]*queryHistogram{}}
+}
+
+func (t *queryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, _ pgx.TraceQueryStartData) context.Context {
+	return context.WithValue(ctx, queryStartKey{}, time.Now())
+}
+
+func (t *queryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	start, ok := ctx.Value(queryStartKey{}).(time.Time)
+	if !ok {
+		return
+	}
+	label, ok := ctx.Value(queryLabelKey{}).(string)
+	if !ok {
+		label = unlabelledQuery
+	}
+	t.record(label, time.Since(start), data.Err)
+
+	// A missing row is an answer, not a failure of the Store call.
+	if data.Err != nil && !errors.Is(data.Err, sql.ErrNoRows) {
+		span := trace.SpanFromContext(ctx)
+		span.RecordError(data.Err)
+		span.SetStatus(codes.Error, "query failed")
+	}
+}
+
+func (t *queryTracer) record(label string, elapsed time.Duration, err error) {
+	ms := float64(elapsed) / float64(time.Millisecond)
+	if t.slow > 0 && elapsed >= t.slow {
+		slog.Warn("slow database query", "query", label, "duration_ms", ms, "threshold_ms", t.slow.Milliseconds(), "failed", err != nil)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	histogram, ok := t.histograms[label]
+	if !ok {
+		histogram = &queryHistogram{buckets: make([]int64, len(queryBucketsMs))}
+		t.histograms[label] = histogram
+	}
+	histogram.count++
+	histogram.totalMs += ms
+	for index, bound := range queryBucketsMs {
+		if ms <= bound {
+			histogram.buckets[index]++
+		}
+	}
+}
+
+// stats returns the histograms sorted by label. Bucket counts are
+// cumulative; queries slower than the last bound count only toward Count.
+func (t *queryTracer) stats() []model.QueryLatency {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	out := make([]model.QueryLatency, 0, len(t.histograms))
+	for label, histogram := range t.histograms {
+		latency := model.QueryLatency{
+			Query:   label,
+			Count:   histogram.count,
+			TotalMs: histogram.totalMs,
+			Buckets: make([]model.QueryLatencyBucket, len(queryBucketsMs)),
+		}
+		for index, bound := range queryBucketsMs {
+			latency.Buckets[index] = model.QueryLatencyBucket{LeMs: bound, Count: histogram.buckets[index]}
+		}
+		out = append(out, latency)
+	}
+	slices.SortFunc(out, func(a, b model.QueryLatency) int { return strings.Compare(a.Query, b.Query) })
+	return out
+}
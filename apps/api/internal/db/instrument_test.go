@@ -0,0 +1,119 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestQueryLabelNamesTheStoreMethod(t *testing.T) {
+	tests := map[string]string{
+		"pandapages/api/internal/db.(*Store).Library":                "Library",
+		"pandapages/api/internal/db.(*Store).AdminDraftUpsert.func2": "AdminDraftUpsert",
+		"pandapages/api/internal/db.validateStoredReaderVersion":     "validateStoredReaderVersion",
+	}
+	for function, want := range tests {
+		if got := queryLabel(function); got != want {
+			t.Errorf("queryLabel(%q) = %q, want %q", function, got, want)
+		}
+	}
+
+	store := &Store{}
+	parent, cancelParent := context.WithCancel(t.Context())
+	ctx, cancel := store.ctx(parent)
+	defer cancel()
+	if got := ctx.Value(queryLabelKey{}); got != "TestQueryLabelNamesTheStoreMethod" {
+		t.Fatalf("s.ctx() label = %v", got)
+	}
+	if _, ok := ctx.Deadline(); !ok {
+		t.Fatal("s.ctx() has no query timeout")
+	}
+	cancelParent()
+	if !errors.Is(ctx.Err(), context.Canceled) {
+		t.Fatalf("s.ctx() after the request was cancelled: err = %v", ctx.Err())
+	}
+}
+
+func TestStoreCallsAreTracedAsChildSpans(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	request, span := otel.Tracer("test").Start(t.Context(), "GET /api/v1/library")
+	defer span.End()
+	store := &Store{}
+	ctx, cancel := store.ctx(request)
+	queries := newQueryTracer(0)
+	query := queries.TraceQueryStart(ctx, nil, pgx.TraceQueryStartData{})
+	queries.TraceQueryEnd(query, nil, pgx.TraceQueryEndData{Err: errors.New("connection reset")})
+	if len(recorder.Ended()) != 0 {
+		t.Fatal("Store span ended before the call's cancel")
+	}
+	cancel()
+
+	ended := recorder.Ended()
+	if len(ended) != 1 {
+		t.Fatalf("ended spans = %d, want 1", len(ended))
+	}
+	call := ended[0]
+	if call.Name() != "db.Store.TestStoreCallsAreTracedAsChildSpans" {
+		t.Fatalf("span name = %q", call.Name())
+	}
+	if call.Parent().SpanID() != span.SpanContext().SpanID() {
+		t.Fatal("Store span is not a child of the request span")
+	}
+	if call.Status().Code != codes.Error || len(call.Events()) != 1 {
+		t.Fatalf("failed query: status %v, events %v", call.Status(), call.Events())
+	}
+}
+
+func TestQueryTracerBucketsLatencyAndLogsSlowQueries(t *testing.T) {
+	var logs bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, nil)))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
+	tracer := newQueryTracer(100 * time.Millisecond)
+	tracer.record("Library", 3*time.Millisecond, nil)
+	tracer.record("Library", 40*time.Millisecond, nil)
+	tracer.record("Library", 4*time.Second, nil)
+	tracer.record("ProgressGet", time.Millisecond, nil)
+
+	stats := tracer.stats()
+	if len(stats) != 2 || stats[0].Query != "Library" || stats[1].Query != "ProgressGet" {
+		t.Fatalf("stats = %#v", stats)
+	}
+	library := stats[0]
+	if library.Count != 3 || library.TotalMs != 4043 {
+		t.Fatalf("Library count %d total %v", library.Count, library.TotalMs)
+	}
+	counts := map[float64]int64{}
+	for _, bucket := range library.Buckets {
+		counts[bucket.LeMs] = bucket.Count
+	}
+	if counts[2] != 0 || counts[5] != 1 || counts[50] != 2 || counts[2500] != 2 {
+		t.Fatalf("Library buckets = %#v", library.Buckets)
+	}
+
+	if strings.Count(logs.String(), "slow database query") != 1 || !strings.Contains(logs.String(), "query=Library") {
+		t.Fatalf("slow-query log = %q", logs.String())
+	}
+}
+
+func TestQueryTracerAttributesUnlabelledQueries(t *testing.T) {
+	tracer := newQueryTracer(0)
+	ctx := tracer.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{})
+	tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{})
+	if stats := tracer.stats(); len(stats) != 1 || stats[0].Query != unlabelledQuery {
+		t.Fatalf("stats = %#v", stats)
+	}
+}
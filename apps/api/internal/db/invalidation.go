@@ -0,0 +1,118 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// invalidationChannel carries cache invalidations between API instances.
+// A mutation notifies it inside its transaction, so the message is
+// delivered exactly when the change commits, and never if it rolls back.
+const invalidationChannel = "pandapages_cache"
+
+// storyInvalidation names a story whose cached versions are stale.
+type storyInvalidation struct {
+	AccountID string `json:"accountId"`
+	Slug      string `json:"slug"`
+}
+
+// notifyStoryChanged tells every instance, this one included, to forget its
+// cached versions of a story once tx commits.
+func notifyStoryChanged(ctx context.Context, tx pgx.Tx, accountID, slug string) error {
+	payload, err := json.Marshal(storyInvalidation{AccountID: accountID, Slug: slug})
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, `SELECT pg_notify($1, $2)`, invalidationChannel, string(payload))
+	return err
+}
+
+// invalidationListener holds its own connection rather than one of the
+// pool's, so a small pool is not one connection short, and applies every
+// invalidation another instance sends to this instance's reader cache.
+type invalidationListener struct {
+	config *pgx.ConnConfig
+	cache  *readerCache
+
+	stop context.CancelFunc
+	done chan struct{}
+}
+
+// listenForInvalidations starts the listener. It never fails: while it is
+// disconnected it retries with backoff, and it clears the whole cache after
+// reconnecting, since any invalidation sent meanwhile is lost.
+func listenForInvalidations(config *pgx.ConnConfig, cache *readerCache) *invalidationListener {
+	config = config.Copy()
+	config.Tracer = nil // LISTEN's long wait is not a slow query
+	ctx, stop := context.WithCancel(context.Background())
+	l := &invalidationListener{config: config, cache: cache, stop: stop, done: make(chan struct{})}
+	go l.run(ctx)
+	return l
+}
+
+func (l *invalidationListener) run(ctx context.Context) {
+	defer close(l.done)
+	for attempt, first := 1, true; ; first = false {
+		subscribed, err := l.listen(ctx, !first)
+		if ctx.Err() != nil {
+			return // closing
+		}
+		if subscribed {
+			attempt = 1
+		}
+		delay := connectDelay(attempt)
+		slog.Warn("cache invalidation listener disconnected; retrying", "retry_in", delay.String(), "err", err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		attempt++
+	}
+}
+
+// listen subscribes and applies notifications until the connection fails,
+// reporting whether it got as far as subscribing. After a reconnect it
+// clears the cache before applying new ones.
+func (l *invalidationListener) listen(ctx context.Context, reconnect bool) (bool, error) {
+	conn, err := pgx.ConnectConfig(ctx, l.config)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close(context.Background())
+	if _, err := conn.Exec(ctx, "LISTEN "+invalidationChannel); err != nil {
+		return false, err
+	}
+	if reconnect {
+		l.cache.clear()
+		slog.Info("cache invalidation listener reconnected; reader cache cleared")
+	}
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return true, err
+		}
+		l.apply(notification.Payload)
+	}
+}
+
+func (l *invalidationListener) apply(payload string) {
+	var story storyInvalidation
+	if err := json.Unmarshal([]byte(payload), &story); err != nil || story.AccountID == "" || story.Slug == "" {
+		slog.Warn("ignoring malformed cache invalidation", "payload", payload)
+		return
+	}
+	l.cache.forget(story.AccountID, story.Slug)
+}
+
+func (l *invalidationListener) close() {
+	if l == nil {
+		return
+	}
+	l.stop()
+	<-l.done
+}
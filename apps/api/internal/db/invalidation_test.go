@@ -0,0 +1,35 @@
+package db
+
+import "testing"
+
+func TestInvalidationForgetsOnlyTheNamedStory(t *testing.T) {
+	cache := newReaderCache(1 << 20)
+	cache.put(readerCacheKey{accountID: "a", slug: "moon", versionID: "v1"}, cachedVersion("moon"))
+	cache.put(readerCacheKey{accountID: "a", slug: "sun", versionID: "v2"}, cachedVersion("sun"))
+	cache.put(readerCacheKey{accountID: "b", slug: "moon", versionID: "v3"}, cachedVersion("other account"))
+	listener := &invalidationListener{cache: cache}
+
+	listener.apply(`{"accountId":"a","slug":"moon"}`)
+	if _, ok := cache.get(readerCacheKey{accountID: "a", slug: "moon", versionID: "v1"}); ok {
+		t.Fatal("the invalidated story is still cached")
+	}
+	if cache.stats().Entries != 2 {
+		t.Fatalf("entries = %d, want the other two kept", cache.stats().Entries)
+	}
+
+	for _, malformed := range []string{"", "moon", `{"accountId":"a"}`} {
+		listener.apply(malformed)
+	}
+	if cache.stats().Entries != 2 {
+		t.Fatalf("a malformed invalidation evicted entries: %d left", cache.stats().Entries)
+	}
+
+	cache.clear()
+	if stats := cache.stats(); stats.Entries != 0 || stats.Bytes != 0 {
+		t.Fatalf("after clear = %#v", stats)
+	}
+	cache.put(readerCacheKey{accountID: "a", slug: "sun", versionID: "v4"}, cachedVersion("sun"))
+	if _, ok := cache.get(readerCacheKey{accountID: "a", slug: "sun", versionID: "v4"}); !ok {
+		t.Fatal("the cache does not store after clear")
+	}
+}
@@ -0,0 +1,143 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"pandapages/api/internal/model"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// leasedJobs is a table of jobs a worker claims under a lease and walks with
+// a cursor, as narration_jobs and alignment_jobs are. columns reads a job
+// aliased as job, through joins; done counts the items recorded and cursor
+// is where a reclaimed job resumes.
+type leasedJobs[J any] struct {
+	table    string
+	columns  string
+	joins    string
+	done     string
+	cursor   string
+	scan     func(pgx.Row) (J, error)
+	notFound error
+}
+
+// claim leases the oldest queued job, or a running one whose worker stopped
+// renewing its lease. ok is false when there is nothing to do.
+func (t leasedJobs[J]) claim(ctx context.Context, s *Store, lease time.Duration) (job J, ok bool, err error) {
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	job, err = t.scan(s.db.QueryRow(ctx, `
+		WITH job AS (
+			UPDATE `+t.table+`
+			SET status = 'running',
+			    lease_until = now() + make_interval(secs => $1),
+			    updated_at = now()
+			WHERE id = (
+				SELECT id
+				FROM `+t.table+`
+				WHERE status = 'queued'
+				   OR (status = 'running' AND lease_until < now())
+				ORDER BY created_at ASC, id ASC
+				LIMIT 1
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING *
+		)
+		SELECT `+t.columns+`
+		FROM job`+t.joins, lease.Seconds()))
+	if errors.Is(err, sql.ErrNoRows) {
+		var none J
+		return none, false, nil
+	}
+	if err != nil {
+		var none J
+		return none, false, err
+	}
+	return job, true, nil
+}
+
+// advance counts done more items recorded, moves the running job's cursor to
+// cursor and renews its lease, inside the transaction that recorded them.
+func (t leasedJobs[J]) advance(ctx context.Context, tx pgx.Tx, jobID string, done int64, cursor any, lease time.Duration) (J, error) {
+	job, err := t.scan(tx.QueryRow(ctx, `
+		WITH job AS (
+			UPDATE `+t.table+`
+			SET `+t.done+` = `+t.done+` + $2,
+			    `+t.cursor+` = $3,
+			    lease_until = now() + make_interval(secs => $4),
+			    updated_at = now()
+			WHERE id = $1
+			  AND status = 'running'
+			RETURNING *
+		)
+		SELECT `+t.columns+`
+		FROM job`+t.joins,
+		jobID, done, cursor, lease.Seconds()))
+	if errors.Is(err, sql.ErrNoRows) {
+		return job, t.notFound
+	}
+	return job, err
+}
+
+// finish completes a running job, or fails it with failure. changed names
+// the story whose Reader payload the finished job altered, if any: it is
+// dropped from the cache here and on every other instance.
+func (t leasedJobs[J]) finish(ctx context.Context, s *Store, jobID string, failure *string, changed func(J) (accountID, slug string, ok bool)) (J, error) {
+	var none J
+	status := model.RenderJobCompleted
+	if failure != nil {
+		status = model.RenderJobFailed
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return none, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	finished, err := t.scan(tx.QueryRow(ctx, `
+		WITH job AS (
+			UPDATE `+t.table+`
+			SET status = $2,
+			    error = $3,
+			    lease_until = NULL,
+			    finished_at = now(),
+			    updated_at = now()
+			WHERE id = $1
+			  AND status = 'running'
+			RETURNING *
+		)
+		SELECT `+t.columns+`
+		FROM job`+t.joins,
+		jobID, string(status), failure))
+	if errors.Is(err, sql.ErrNoRows) {
+		return none, t.notFound
+	}
+	if err != nil {
+		return none, err
+	}
+	accountID, slug, ok := "", "", false
+	if changed != nil {
+		accountID, slug, ok = changed(finished)
+	}
+	if ok {
+		if err := notifyStoryChanged(ctx, tx, accountID, slug); err != nil {
+			return none, err
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return none, err
+	}
+	if ok {
+		s.readerCache.forget(accountID, slug)
+	}
+	return finished, nil
+}
@@ -0,0 +1,150 @@
+package db
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"pandapages/api/internal/model"
+	"pandapages/api/internal/storyingest"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const mediaColumns = `id, content_type, byte_size, sha256, created_at`
+
+// AdminCreateMedia stores an uploaded image. The caller has already checked
+// the size; the content type must be one the Reader is allowed to load.
+func (s *Store) AdminCreateMedia(ctx context.Context, accountID, contentType string, data []byte) (model.Media, error) {
+	accountID = strings.TrimSpace(accountID)
+	if !accountIDRe.MatchString(accountID) {
+		return model.Media{}, fmt.Errorf("account required")
+	}
+	if !model.ValidMediaContentType(contentType) || len(data) == 0 {
+		return model.Media{}, fmt.Errorf("media invalid")
+	}
+	sum := sha256.Sum256(data)
+	stored, key, err := s.mediaBytes(ctx, accountID, contentType, data)
+	if err != nil {
+		return model.Media{}, err
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	media, err := scanMedia(s.writes(ctx).QueryRow(ctx, `
+		INSERT INTO media (account_id, content_type, byte_size, sha256, data, blob_key)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING `+mediaColumns,
+		accountID, contentType, len(data), hex.EncodeToString(sum[:]), stored, key))
+	if err != nil && key != nil {
+		s.dropBlob(ctx, *key)
+	}
+	return media, err
+}
+
+// mediaBytes returns what a new media row keeps: the bytes themselves, or
+// the key they were stored under in the blob store.
+func (s *Store) mediaBytes(ctx context.Context, accountID, contentType string, data []byte) ([]byte, *string, error) {
+	if s.blobs == nil {
+		return data, nil, nil
+	}
+	key, err := s.putBlob(ctx, "media", accountID, "."+strings.TrimPrefix(contentType, "image/"), contentType, data)
+	if err != nil {
+		return nil, nil, err
+	}
+	return nil, &key, nil
+}
+
+// Media returns an account's image and its bytes for serving.
+func (s *Store) Media(ctx context.Context, accountID, mediaID string) (model.Media, []byte, error) {
+	accountID = strings.TrimSpace(accountID)
+	mediaID = strings.ToLower(strings.TrimSpace(mediaID))
+	if !accountIDRe.MatchString(accountID) || !accountIDRe.MatchString(mediaID) {
+		return model.Media{}, nil, fmt.Errorf("%w", model.ErrMediaNotFound)
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	var (
+		data []byte
+		key  *string
+	)
+	media, err := scanMedia(s.db.QueryRow(ctx, `
+		SELECT `+mediaColumns+`, data, blob_key
+		FROM media
+		WHERE account_id = $1
+		  AND id = $2
+	`, accountID, mediaID), &data, &key)
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.Media{}, nil, fmt.Errorf("%w", model.ErrMediaNotFound)
+	}
+	if err != nil {
+		return model.Media{}, nil, err
+	}
+	if data, err = s.blobData(ctx, data, key); err != nil {
+		return model.Media{}, nil, err
+	}
+	return media, data, nil
+}
+
+func scanMedia(row rowScanner, extra ...any) (model.Media, error) {
+	var (
+		media     model.Media
+		createdAt time.Time
+	)
+	dest := append([]any{&media.ID, &media.ContentType, &media.ByteSize, &media.SHA256, &createdAt}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return model.Media{}, err
+	}
+	media.Ref = storyingest.MediaRefPrefix + media.ID
+	media.URL = storyingest.MediaURL(media.ID)
+	media.CreatedAt = createdAt.UTC().Format(time.RFC3339Nano)
+	return media, nil
+}
+
+// missingMediaIssues reports story media references the account does not own.
+func missingMediaIssues(ctx context.Context, tx pgx.Tx, accountID string, ids []string) ([]model.AdminValidationIssue, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	rows, err := tx.Query(ctx, `
+		SELECT id::text FROM media WHERE account_id = $1 AND id = ANY($2::uuid[])
+	`, accountID, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	found := map[string]bool{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		found[id] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return mediaIssues(ids, found), nil
+}
+
+func mediaIssues(ids []string, found map[string]bool) []model.AdminValidationIssue {
+	issues := []model.AdminValidationIssue{}
+	for _, id := range ids {
+		if !found[id] {
+			issues = append(issues, model.AdminValidationIssue{
+				Field:   "markdown",
+				Code:    "media_not_found",
+				Message: "Upload image " + storyingest.MediaRefPrefix + id + " or remove it",
+			})
+		}
+	}
+	return issues
+}
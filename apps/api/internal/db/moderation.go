@@ -0,0 +1,102 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"pandapages/api/internal/model"
+)
+
+// setVersionModeration stores a generated draft's verdict on the version it
+// wrote or reused, replacing any earlier verdict. A nil verdict leaves the
+// version as it is, so a hand-written draft that reuses a generated version
+// keeps that version's verdict.
+func setVersionModeration(ctx context.Context, tx pgx.Tx, versionID string, moderation *model.StoryModeration) error {
+	if moderation == nil {
+		return nil
+	}
+	switch moderation.Verdict {
+	case model.ModerationPassed, model.ModerationFlagged, model.ModerationBlocked:
+	default:
+		return fmt.Errorf("moderation verdict %q is invalid", moderation.Verdict)
+	}
+	findings := moderation.Findings
+	if findings == nil {
+		findings = []model.ModerationFinding{}
+	}
+	findingsJSON, err := json.Marshal(findings)
+	if err != nil {
+		return err
+	}
+	moderatedAt, err := time.Parse(time.RFC3339Nano, moderation.ModeratedAt)
+	if err != nil {
+		moderatedAt = time.Now()
+	}
+	_, err = tx.Exec(ctx, `
+		UPDATE story_versions
+		SET moderation_verdict = $2,
+		    moderation_provider = $3,
+		    moderation_findings = $4::jsonb,
+		    moderated_at = $5
+		WHERE id = $1
+	`, versionID, string(moderation.Verdict), moderation.Provider, string(findingsJSON), moderatedAt)
+	return err
+}
+
+// versionModeration loads a version's verdict, nil for a version no
+// moderation reviewed.
+func versionModeration(ctx context.Context, tx pgx.Tx, versionID string) (*model.StoryModeration, error) {
+	var (
+		verdict, provider sql.NullString
+		findings          []byte
+		moderatedAt       sql.NullTime
+	)
+	err := tx.QueryRow(ctx, `
+		SELECT moderation_verdict, moderation_provider, moderation_findings, moderated_at
+		FROM story_versions
+		WHERE id = $1
+	`, versionID).Scan(&verdict, &provider, &findings, &moderatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !verdict.Valid {
+		return nil, nil
+	}
+	out := model.StoryModeration{
+		Verdict:  model.ModerationVerdict(verdict.String),
+		Provider: nullStringValue(provider),
+		Findings: []model.ModerationFinding{},
+	}
+	_ = json.Unmarshal(findings, &out.Findings)
+	if moderatedAt.Valid {
+		out.ModeratedAt = moderatedAt.Time.UTC().Format(time.RFC3339Nano)
+	}
+	return &out, nil
+}
+
+// checkPublishModeration refuses a blocked version, and a flagged one the
+// publisher has not acknowledged.
+func checkPublishModeration(ctx context.Context, tx pgx.Tx, versionID string, acknowledged bool) error {
+	moderation, err := versionModeration(ctx, tx, versionID)
+	if err != nil || moderation == nil {
+		return err
+	}
+	switch moderation.Verdict {
+	case model.ModerationBlocked:
+		return fmt.Errorf("%w", model.ErrModerationBlocked)
+	case model.ModerationFlagged:
+		if !acknowledged {
+			return fmt.Errorf("%w", model.ErrModerationUnacknowledged)
+		}
+	}
+	return nil
+}
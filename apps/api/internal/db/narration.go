@@ -0,0 +1,378 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"pandapages/api/internal/model"
+	"pandapages/api/internal/storyingest"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// narrationJobColumns reads a job aliased as job, joined to its version and
+// story by narrationJobJoins.
+const (
+	narrationJobColumns = `
+	job.id, job.account_id, story.slug, job.story_version_id::text, job.voice, job.status,
+	job.total_segments, job.narrated_segments, COALESCE(job.cursor_ordinal, -1),
+	job.error, job.created_at, job.updated_at, job.finished_at
+`
+	narrationJobJoins = `
+	JOIN story_versions AS version
+	  ON version.id = job.story_version_id
+	JOIN stories AS story
+	  ON story.id = version.story_id
+`
+)
+
+var narrationJobs = leasedJobs[model.NarrationJob]{
+	table:    "narration_jobs",
+	columns:  narrationJobColumns,
+	joins:    narrationJobJoins,
+	done:     "narrated_segments",
+	cursor:   "cursor_ordinal",
+	scan:     scanNarrationJob,
+	notFound: model.ErrNarrationJobNotFound,
+}
+
+// AdminStartNarrationJob queues narration of one version of a story: every
+// segment with words to say that lacks audio in voice. A segment whose
+// content the account already has recorded in voice, found by content hash,
+// gets a copy of that recording instead, so the unchanged segments of a new
+// version are not paid for again. Only one job may be queued or running per
+// version.
+func (s *Store) AdminStartNarrationJob(ctx context.Context, accountID, slug, versionID, voice string) (model.NarrationJob, error) {
+	accountID = strings.TrimSpace(accountID)
+	if !accountIDRe.MatchString(accountID) {
+		return model.NarrationJob{}, fmt.Errorf("account required")
+	}
+	if storyingest.ValidateSlug(slug) != nil || !accountIDRe.MatchString(versionID) {
+		return model.NarrationJob{}, fmt.Errorf("%w", model.ErrAdminStoryNotFound)
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	tx, err := s.writes(ctx).Begin(ctx)
+	if err != nil {
+		return model.NarrationJob{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	// Sources are share-locked, so one deleted meanwhile is either copied
+	// before its blob is collected or not copied at all.
+	copied, err := tx.Exec(ctx, `
+		INSERT INTO segment_audio (account_id, segment_id, voice, content_type, duration_ms, byte_size, data, blob_key, word_timings)
+		SELECT $1, segment.id, source.voice, source.content_type, source.duration_ms,
+		       source.byte_size, source.data, source.blob_key, source.word_timings
+		FROM story_versions AS version
+		JOIN stories AS story
+		  ON story.id = version.story_id
+		JOIN story_segments AS segment
+		  ON segment.story_version_id = version.id
+		LEFT JOIN segment_audio AS audio
+		  ON audio.segment_id = segment.id
+		CROSS JOIN LATERAL (
+			SELECT earlier_audio.*
+			FROM story_segments AS earlier
+			JOIN segment_audio AS earlier_audio
+			  ON earlier_audio.segment_id = earlier.id
+			WHERE earlier.content_hash = segment.content_hash
+			  AND earlier_audio.account_id = $1
+			  AND earlier_audio.voice = $4
+			ORDER BY earlier_audio.created_at DESC
+			LIMIT 1
+			FOR SHARE OF earlier_audio
+		) AS source
+		WHERE story.account_id = $1
+		  AND story.slug = $2
+		  AND version.id = $3
+		  AND segment.content_hash IS NOT NULL
+		  AND segment.segment_kind NOT IN ('pagebreak', 'scene-break')
+		  AND (audio.id IS NULL OR audio.voice <> $4)
+		ON CONFLICT (segment_id) DO UPDATE
+		SET id = gen_random_uuid(),
+		    voice = EXCLUDED.voice,
+		    content_type = EXCLUDED.content_type,
+		    duration_ms = EXCLUDED.duration_ms,
+		    byte_size = EXCLUDED.byte_size,
+		    data = EXCLUDED.data,
+		    blob_key = EXCLUDED.blob_key,
+		    word_timings = EXCLUDED.word_timings,
+		    created_at = now()
+	`, accountID, slug, versionID, voice)
+	if err != nil {
+		return model.NarrationJob{}, err
+	}
+
+	job, err := scanNarrationJob(tx.QueryRow(ctx, `
+		WITH job AS (
+			INSERT INTO narration_jobs (account_id, story_version_id, voice, total_segments)
+			SELECT $1, version.id, $4, (
+				SELECT count(*)
+				FROM story_segments AS segment
+				LEFT JOIN segment_audio AS audio
+				  ON audio.segment_id = segment.id
+				WHERE segment.story_version_id = version.id
+				  AND segment.segment_kind NOT IN ('pagebreak', 'scene-break')
+				  AND (audio.id IS NULL OR audio.voice <> $4)
+			)
+			FROM story_versions AS version
+			JOIN stories AS story
+			  ON story.id = version.story_id
+			WHERE story.account_id = $1
+			  AND story.slug = $2
+			  AND version.id = $3
+			RETURNING *
+		)
+		SELECT `+narrationJobColumns+`
+		FROM job`+narrationJobJoins,
+		accountID, slug, versionID, voice))
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.NarrationJob{}, fmt.Errorf("%w", model.ErrAdminStoryNotFound)
+	}
+	if isUniqueViolation(err) {
+		return model.NarrationJob{}, model.ErrNarrationJobActive
+	}
+	if err != nil {
+		return model.NarrationJob{}, err
+	}
+	if copied.RowsAffected() > 0 {
+		if err := notifyStoryChanged(ctx, tx, accountID, slug); err != nil {
+			return model.NarrationJob{}, err
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return model.NarrationJob{}, err
+	}
+	if copied.RowsAffected() > 0 {
+		s.readerCache.forget(accountID, slug)
+	}
+	return job, nil
+}
+
+func (s *Store) AdminGetNarrationJob(ctx context.Context, accountID, jobID string) (model.NarrationJob, error) {
+	accountID = strings.TrimSpace(accountID)
+	if !accountIDRe.MatchString(accountID) {
+		return model.NarrationJob{}, fmt.Errorf("account required")
+	}
+	if !accountIDRe.MatchString(jobID) {
+		return model.NarrationJob{}, model.ErrNarrationJobNotFound
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	job, err := scanNarrationJob(s.db.QueryRow(ctx, `
+		SELECT `+narrationJobColumns+`
+		FROM narration_jobs AS job`+narrationJobJoins+`
+		WHERE job.id = $1
+		  AND job.account_id = $2
+	`, jobID, accountID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.NarrationJob{}, model.ErrNarrationJobNotFound
+	}
+	return job, err
+}
+
+// NarrationClaimJob leases the oldest queued job, or a running one whose
+// worker stopped renewing its lease. ok is false when there is nothing to do.
+func (s *Store) NarrationClaimJob(ctx context.Context, lease time.Duration) (model.NarrationJob, bool, error) {
+	return narrationJobs.claim(ctx, s, lease)
+}
+
+// NarrationPendingSegments lists, in reading order, up to limit segments
+// after the job's cursor that it has still to narrate.
+func (s *Store) NarrationPendingSegments(ctx context.Context, job model.NarrationJob, limit int) ([]model.NarrationSegment, error) {
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	rows, err := s.db.Query(ctx, `
+		SELECT segment.ordinal, segment.rendered_html, segment.pronunciations::text
+		FROM story_segments AS segment
+		LEFT JOIN segment_audio AS audio
+		  ON audio.segment_id = segment.id
+		WHERE segment.story_version_id = $1
+		  AND segment.ordinal > $2
+		  AND segment.segment_kind NOT IN ('pagebreak', 'scene-break')
+		  AND (audio.id IS NULL OR audio.voice <> $3)
+		ORDER BY segment.ordinal ASC
+		LIMIT $4
+	`, job.VersionID, job.CursorOrdinal, job.Voice, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	segments := []model.NarrationSegment{}
+	for rows.Next() {
+		var (
+			segment        model.NarrationSegment
+			pronunciations sql.NullString
+		)
+		if err := rows.Scan(&segment.Ordinal, &segment.RenderedHTML, &pronunciations); err != nil {
+			return nil, err
+		}
+		if segment.Pronunciations, err = decodePronunciations(pronunciations); err != nil {
+			return nil, fmt.Errorf("decode segment pronunciations: %w", err)
+		}
+		segments = append(segments, segment)
+	}
+	return segments, rows.Err()
+}
+
+// NarrationRecordSegment stores one segment's audio, replacing any earlier
+// recording, then moves the job's cursor past it and renews the lease. A nil
+// audio marks a segment with nothing to say.
+func (s *Store) NarrationRecordSegment(ctx context.Context, job model.NarrationJob, ordinal int, audio *model.NarrationAudio, lease time.Duration) (model.NarrationJob, error) {
+	var (
+		stored []byte
+		key    *string
+	)
+	if audio != nil {
+		stored = audio.Data
+		if s.blobs != nil {
+			put, err := s.putBlob(ctx, "audio", job.AccountID, ".wav", audio.ContentType, audio.Data)
+			if err != nil {
+				return model.NarrationJob{}, err
+			}
+			stored, key = nil, &put
+		}
+	}
+	referenced, committed := false, false
+	defer func() {
+		if key != nil && !(referenced && committed) {
+			s.dropBlob(ctx, *key)
+		}
+	}()
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return model.NarrationJob{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	narrated := 0
+	if audio != nil {
+		// A replaced recording gets a new ID, since its URL is cached as
+		// immutable, and loses the word timings of the old one.
+		tag, err := tx.Exec(ctx, `
+			INSERT INTO segment_audio (account_id, segment_id, voice, content_type, duration_ms, byte_size, data, blob_key)
+			SELECT $1, segment.id, $4, $5, $6, $7, $8, $9
+			FROM story_segments AS segment
+			WHERE segment.story_version_id = $2
+			  AND segment.ordinal = $3
+			ON CONFLICT (segment_id) DO UPDATE
+			SET id = gen_random_uuid(),
+			    voice = EXCLUDED.voice,
+			    content_type = EXCLUDED.content_type,
+			    duration_ms = EXCLUDED.duration_ms,
+			    byte_size = EXCLUDED.byte_size,
+			    data = EXCLUDED.data,
+			    blob_key = EXCLUDED.blob_key,
+			    word_timings = NULL,
+			    created_at = now()
+		`, job.AccountID, job.VersionID, ordinal, job.Voice, audio.ContentType,
+			max(1, audio.Duration.Milliseconds()), len(audio.Data), stored, key)
+		if err != nil {
+			return model.NarrationJob{}, err
+		}
+		narrated = 1
+		referenced = tag.RowsAffected() == 1
+	}
+
+	updated, err := narrationJobs.advance(ctx, tx, job.ID, int64(narrated), ordinal, lease)
+	if err != nil {
+		return model.NarrationJob{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return model.NarrationJob{}, err
+	}
+	committed = true
+	return updated, nil
+}
+
+// NarrationFinishJob completes a job, or fails it with failure. Readers see
+// the new audio from then on: the story's cached segments are dropped here
+// and on every other instance.
+func (s *Store) NarrationFinishJob(ctx context.Context, job model.NarrationJob, failure *string) (model.NarrationJob, error) {
+	return narrationJobs.finish(ctx, s, job.ID, failure, func(finished model.NarrationJob) (string, string, bool) {
+		return finished.AccountID, finished.Slug, finished.NarratedSegments > 0
+	})
+}
+
+// SegmentAudio returns an account's segment recording for serving. One kept
+// in the blob store comes with its key and without its bytes, so the caller
+// can send the Reader there.
+func (s *Store) SegmentAudio(ctx context.Context, accountID, audioID string) (model.NarrationAudio, error) {
+	accountID = strings.TrimSpace(accountID)
+	audioID = strings.ToLower(strings.TrimSpace(audioID))
+	if !accountIDRe.MatchString(accountID) || !accountIDRe.MatchString(audioID) {
+		return model.NarrationAudio{}, fmt.Errorf("%w", model.ErrAudioNotFound)
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	var (
+		audio      model.NarrationAudio
+		durationMs int64
+	)
+	err := s.reads().QueryRow(ctx, `
+		SELECT content_type, duration_ms, data, COALESCE(blob_key, '')
+		FROM segment_audio
+		WHERE account_id = $1
+		  AND id = $2
+	`, accountID, audioID).Scan(&audio.ContentType, &durationMs, &audio.Data, &audio.BlobKey)
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.NarrationAudio{}, fmt.Errorf("%w", model.ErrAudioNotFound)
+	}
+	if err != nil {
+		return model.NarrationAudio{}, err
+	}
+	audio.Duration = time.Duration(durationMs) * time.Millisecond
+	return audio, nil
+}
+
+func scanNarrationJob(row pgx.Row) (model.NarrationJob, error) {
+	var (
+		job        model.NarrationJob
+		status     string
+		createdAt  time.Time
+		updatedAt  time.Time
+		finishedAt sql.NullTime
+	)
+	if err := row.Scan(
+		&job.ID,
+		&job.AccountID,
+		&job.Slug,
+		&job.VersionID,
+		&job.Voice,
+		&status,
+		&job.TotalSegments,
+		&job.NarratedSegments,
+		&job.CursorOrdinal,
+		&job.Error,
+		&createdAt,
+		&updatedAt,
+		&finishedAt,
+	); err != nil {
+		return model.NarrationJob{}, err
+	}
+	job.Status = model.RenderJobStatus(status)
+	job.CreatedAt = createdAt.UTC().Format(time.RFC3339Nano)
+	job.UpdatedAt = updatedAt.UTC().Format(time.RFC3339Nano)
+	if finishedAt.Valid {
+		formatted := finishedAt.Time.UTC().Format(time.RFC3339Nano)
+		job.FinishedAt = &formatted
+	}
+	return job, nil
+}
@@ -0,0 +1,76 @@
+package db
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+
+	"pandapages/api/internal/model"
+)
+
+// Every list is paginated by keyset: a page is the rows sorted after the last
+// row of the previous one, so pages stay stable while rows are added and deep
+// pages cost no more than the first.
+
+// pageSize resolves a requested page size against a list's default and cap.
+func pageSize(requested, fallback, max int) int {
+	if requested <= 0 {
+		return fallback
+	}
+	return min(requested, max)
+}
+
+// encodeCursor records the sort key of the last row of a page, in ORDER BY
+// order. Cursors are opaque to clients.
+func encodeCursor(keys ...any) *string {
+	// Keys are strings, numbers and times, which always encode.
+	raw, _ := json.Marshal(keys)
+	cursor := base64.RawURLEncoding.EncodeToString(raw)
+	return &cursor
+}
+
+// decodeCursor reads a cursor issued by encodeCursor into one pointer per
+// key. It reports false, leaving dst untouched, for the empty first-page
+// cursor.
+func decodeCursor(cursor string, dst ...any) (bool, error) {
+	if cursor == "" {
+		return false, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return false, model.ErrInvalidCursor
+	}
+	var keys []json.RawMessage
+	if err := json.Unmarshal(raw, &keys); err != nil || len(keys) != len(dst) {
+		return false, model.ErrInvalidCursor
+	}
+	for index, key := range keys {
+		if err := json.Unmarshal(key, dst[index]); err != nil {
+			return false, model.ErrInvalidCursor
+		}
+	}
+	return true, nil
+}
+
+// trimPage drops the extra row a list fetches to learn whether another page
+// follows, returning the page and whether it was there.
+func trimPage[T any](items []T, limit int) ([]T, bool) {
+	if len(items) > limit {
+		return items[:limit], true
+	}
+	return items, false
+}
+
+// pageTotal counts a list's items across every page when the request asked
+// for it, and is nil otherwise. query counts the rows the list pages through,
+// ignoring the cursor.
+func pageTotal(ctx context.Context, q storedVersionQueryer, page model.PageRequest, query string, args ...any) (*int64, error) {
+	if !page.Total {
+		return nil, nil
+	}
+	var total int64
+	if err := q.QueryRow(ctx, query, args...).Scan(&total); err != nil {
+		return nil, err
+	}
+	return &total, nil
+}
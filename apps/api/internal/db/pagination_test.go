@@ -0,0 +1,67 @@
+package db
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"pandapages/api/internal/model"
+)
+
+func TestCursorRoundTripsSortKeys(t *testing.T) {
+	updated := time.Date(2026, 3, 4, 5, 6, 7, 891011000, time.UTC)
+	cursor := encodeCursor(updated, "moonlit-cafe", int64(7))
+
+	var (
+		gotUpdated time.Time
+		gotSlug    string
+		gotVersion int64
+	)
+	after, err := decodeCursor(*cursor, &gotUpdated, &gotSlug, &gotVersion)
+	if err != nil || !after {
+		t.Fatalf("decodeCursor = %t, %v", after, err)
+	}
+	if !gotUpdated.Equal(updated) || gotSlug != "moonlit-cafe" || gotVersion != 7 {
+		t.Fatalf("decoded keys = %v %q %d", gotUpdated, gotSlug, gotVersion)
+	}
+}
+
+func TestDecodeCursorStartsAtTheFirstPageWhenEmpty(t *testing.T) {
+	var slug string
+	after, err := decodeCursor("", &slug)
+	if err != nil || after {
+		t.Fatalf("decodeCursor(empty) = %t, %v", after, err)
+	}
+}
+
+func TestDecodeCursorRejectsCursorsItDidNotIssue(t *testing.T) {
+	for _, cursor := range []string{
+		"not base64!",
+		"bm90IGpzb24",                     // not json
+		*encodeCursor("one"),              // too few keys
+		*encodeCursor("slug", "extra", 1), // too many keys
+		*encodeCursor(42, "slug"),         // wrong key type
+	} {
+		var (
+			updated time.Time
+			slug    string
+		)
+		if _, err := decodeCursor(cursor, &updated, &slug); !errors.Is(err, model.ErrInvalidCursor) {
+			t.Fatalf("decodeCursor(%q) error = %v", cursor, err)
+		}
+	}
+}
+
+func TestPageSizeAndTrimPage(t *testing.T) {
+	if pageSize(0, 50, 200) != 50 || pageSize(10, 50, 200) != 10 || pageSize(1000, 50, 200) != 200 {
+		t.Fatal("pageSize does not apply the default and cap")
+	}
+	page, more := trimPage([]int{1, 2, 3}, 2)
+	if !more || len(page) != 2 {
+		t.Fatalf("trimPage(3 of 2) = %v, %t", page, more)
+	}
+	page, more = trimPage([]int{1, 2}, 2)
+	if more || len(page) != 2 {
+		t.Fatalf("trimPage(2 of 2) = %v, %t", page, more)
+	}
+}
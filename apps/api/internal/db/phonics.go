@@ -0,0 +1,129 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"pandapages/api/internal/model"
+	"pandapages/api/internal/storyingest"
+)
+
+// PhonicsPendingVersions returns story versions, across accounts, written in
+// language and with stored vocabulary but no analysis under progression,
+// newest first.
+func (s *Store) PhonicsPendingVersions(ctx context.Context, progression, language string, limit int) ([]model.PhonicsSource, error) {
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	rows, err := s.db.Query(ctx, `
+		SELECT version.id, st.account_id, version.vocabulary->'frequencies'
+		FROM story_versions AS version
+		JOIN stories st
+		  ON st.id = version.story_id
+		LEFT JOIN story_version_phonics AS phonics
+		  ON phonics.story_version_id = version.id
+		 AND phonics.progression = $1
+		WHERE phonics.story_version_id IS NULL
+		  AND jsonb_typeof(version.vocabulary->'frequencies') = 'object'
+		  AND lower(split_part(COALESCE(version.frontmatter->>'language', ''), '-', 1)) = $2
+		ORDER BY version.created_at DESC, version.id ASC
+		LIMIT $3
+	`, progression, language, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []model.PhonicsSource{}
+	for rows.Next() {
+		var (
+			item        model.PhonicsSource
+			frequencies []byte
+		)
+		if err := rows.Scan(&item.VersionID, &item.AccountID, &frequencies); err != nil {
+			return nil, err
+		}
+		// An unreadable map scores as a story with no words rather than
+		// holding up the batch.
+		_ = json.Unmarshal(frequencies, &item.Frequencies)
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// PhonicsSave stores a version's analysis, replacing one under an earlier
+// progression.
+func (s *Store) PhonicsSave(ctx context.Context, source model.PhonicsSource, analysis model.PhonicsAnalysis) error {
+	raw, err := json.Marshal(analysis)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	_, err = s.db.Exec(ctx, `
+		INSERT INTO story_version_phonics (story_version_id, account_id, progression, decodable_set, analysis)
+		VALUES ($1, $2, $3, $4, $5::jsonb)
+		ON CONFLICT (story_version_id) DO UPDATE
+		SET progression = EXCLUDED.progression,
+		    decodable_set = EXCLUDED.decodable_set,
+		    analysis = EXCLUDED.analysis,
+		    created_at = now()
+	`, source.VersionID, source.AccountID, analysis.Progression, analysis.DecodableSet, string(raw))
+	return err
+}
+
+// AdminPhonics returns a story version's analysis under progression. An
+// empty versionID is the published version, else the latest one.
+func (s *Store) AdminPhonics(ctx context.Context, accountID, slug, versionID, progression string) (model.AdminPhonicsResponse, error) {
+	accountID, versionID = strings.TrimSpace(accountID), strings.TrimSpace(versionID)
+	if !accountIDRe.MatchString(accountID) {
+		return model.AdminPhonicsResponse{}, fmt.Errorf("account required")
+	}
+	if storyingest.ValidateSlug(slug) != nil || (versionID != "" && !accountIDRe.MatchString(versionID)) {
+		return model.AdminPhonicsResponse{}, fmt.Errorf("%w", model.ErrAdminStoryNotFound)
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	var (
+		out      = model.AdminPhonicsResponse{Slug: slug}
+		analysis sql.NullString
+	)
+	err := s.db.QueryRow(ctx, `
+		SELECT version.id, phonics.analysis::text
+		FROM stories AS story
+		JOIN story_versions AS version
+		  ON version.story_id = story.id
+		 AND version.id = COALESCE(NULLIF($3, '')::uuid, story.published_version_id, (
+			SELECT latest.id
+			FROM story_versions AS latest
+			WHERE latest.story_id = story.id
+			ORDER BY latest.version DESC
+			LIMIT 1
+		 ))
+		LEFT JOIN story_version_phonics AS phonics
+		  ON phonics.story_version_id = version.id
+		 AND phonics.progression = $4
+		WHERE story.account_id = $1
+		  AND story.slug = $2
+	`, accountID, slug, versionID, progression).Scan(&out.VersionID, &analysis)
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.AdminPhonicsResponse{}, fmt.Errorf("%w", model.ErrAdminStoryNotFound)
+	}
+	if err != nil {
+		return model.AdminPhonicsResponse{}, err
+	}
+	if !analysis.Valid {
+		return model.AdminPhonicsResponse{}, fmt.Errorf("%w", model.ErrPhonicsNotFound)
+	}
+	if err := json.Unmarshal([]byte(analysis.String), &out.PhonicsAnalysis); err != nil {
+		return model.AdminPhonicsResponse{}, fmt.Errorf("decode phonics analysis: %w", err)
+	}
+	return out, nil
+}
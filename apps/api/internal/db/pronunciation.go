@@ -0,0 +1,45 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"pandapages/api/internal/model"
+	"pandapages/api/internal/storyingest"
+)
+
+func readerPronunciations(hints []storyingest.Pronunciation) []model.ReaderPronunciation {
+	if len(hints) == 0 {
+		return nil
+	}
+	out := make([]model.ReaderPronunciation, 0, len(hints))
+	for _, hint := range hints {
+		out = append(out, model.ReaderPronunciation{Text: hint.Text, Say: hint.Respelling, IPA: hint.IPA})
+	}
+	return out
+}
+
+// pronunciationsJSON encodes a segment's hints for storage; segments without
+// hints store NULL.
+func pronunciationsJSON(hints []storyingest.Pronunciation) (*string, error) {
+	if len(hints) == 0 {
+		return nil, nil
+	}
+	raw, err := json.Marshal(readerPronunciations(hints))
+	if err != nil {
+		return nil, err
+	}
+	value := string(raw)
+	return &value, nil
+}
+
+func decodePronunciations(raw sql.NullString) ([]model.ReaderPronunciation, error) {
+	if !raw.Valid {
+		return nil, nil
+	}
+	var out []model.ReaderPronunciation
+	if err := json.Unmarshal([]byte(raw.String), &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
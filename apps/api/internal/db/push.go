@@ -0,0 +1,294 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"pandapages/api/internal/model"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// pushReminderWindow is how late a reminder may still go out, as after a
+// restart; a day whose window passed gets none.
+const pushReminderWindow = time.Hour
+
+// PushVAPIDKey returns the server's VAPID private key, storing candidate as
+// the key when there is none yet. Every instance offers its own candidate and
+// all of them get the first one stored.
+func (s *Store) PushVAPIDKey(ctx context.Context, candidate []byte) ([]byte, error) {
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	if _, err := s.db.Exec(ctx, `
+		INSERT INTO push_vapid_keys (private_key)
+		VALUES ($1)
+		ON CONFLICT (id) DO NOTHING
+	`, candidate); err != nil {
+		return nil, err
+	}
+	var key []byte
+	err := s.db.QueryRow(ctx, `SELECT private_key FROM push_vapid_keys`).Scan(&key)
+	return key, err
+}
+
+// PushSubscribe stores a browser's subscription, at most
+// model.MaxPushSubscriptions per account. Subscribing again replaces the
+// browser's keys, and moves it from any other account.
+func (s *Store) PushSubscribe(ctx context.Context, accountID string, save model.PushSubscriptionSave) (model.PushSubscription, error) {
+	accountID = strings.TrimSpace(accountID)
+	if !accountIDRe.MatchString(accountID) {
+		return model.PushSubscription{}, fmt.Errorf("account required")
+	}
+	if save.Endpoint == "" || len(save.Endpoint) > model.MaxPushEndpointBytes || len(save.P256DH) != 65 || len(save.Auth) != 16 {
+		return model.PushSubscription{}, fmt.Errorf("push subscription invalid")
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return model.PushSubscription{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	// The account row serialises subscribes, so two at once cannot both pass
+	// the limit.
+	var count int
+	if err := tx.QueryRow(ctx, `
+		SELECT count(subscription.id)
+		FROM (SELECT id FROM accounts WHERE id = $1 FOR UPDATE) AS account
+		LEFT JOIN push_subscriptions AS subscription
+		  ON subscription.account_id = account.id
+		 AND subscription.endpoint <> $2
+	`, accountID, save.Endpoint).Scan(&count); err != nil {
+		return model.PushSubscription{}, err
+	}
+	if count >= model.MaxPushSubscriptions {
+		return model.PushSubscription{}, fmt.Errorf("%w", model.ErrPushSubscriptionLimit)
+	}
+
+	var (
+		subscription model.PushSubscription
+		createdAt    time.Time
+	)
+	if err := tx.QueryRow(ctx, `
+		INSERT INTO push_subscriptions (account_id, endpoint, p256dh, auth)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (endpoint) DO UPDATE
+		SET account_id = EXCLUDED.account_id,
+		    p256dh = EXCLUDED.p256dh,
+		    auth = EXCLUDED.auth,
+		    updated_at = now()
+		RETURNING id, created_at, xmax = 0
+	`, accountID, save.Endpoint, save.P256DH, save.Auth).Scan(&subscription.ID, &createdAt, &subscription.Created); err != nil {
+		return model.PushSubscription{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return model.PushSubscription{}, err
+	}
+	subscription.CreatedAt = createdAt.UTC().Format(time.RFC3339Nano)
+	return subscription, nil
+}
+
+// PushUnsubscribe removes a browser's subscription, with the notifications
+// still waiting for it.
+func (s *Store) PushUnsubscribe(ctx context.Context, accountID, endpoint string) error {
+	accountID = strings.TrimSpace(accountID)
+	if !accountIDRe.MatchString(accountID) {
+		return fmt.Errorf("account required")
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	result, err := s.db.Exec(ctx, `
+		DELETE FROM push_subscriptions
+		WHERE account_id = $1
+		  AND endpoint = $2
+	`, accountID, endpoint)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("%w", model.ErrPushSubscriptionNotFound)
+	}
+	return nil
+}
+
+// PushSettings returns the account's notification choices, or the defaults
+// when it has made none.
+func (s *Store) PushSettings(ctx context.Context, accountID string) (model.PushSettings, error) {
+	accountID = strings.TrimSpace(accountID)
+	if !accountIDRe.MatchString(accountID) {
+		return model.PushSettings{}, fmt.Errorf("account required")
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	settings, err := scanPushSettings(s.db.QueryRow(ctx, `
+		SELECT reminders, to_char(reminder_time, 'HH24:MI'), time_zone, new_stories
+		FROM push_settings
+		WHERE account_id = $1
+	`, accountID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.DefaultPushSettings, nil
+	}
+	return settings, err
+}
+
+// PutPushSettings replaces the account's notification choices. The time
+// zone must be one the database knows, since reminders are timed there.
+func (s *Store) PutPushSettings(ctx context.Context, accountID string, settings model.PushSettings) (model.PushSettings, error) {
+	accountID = strings.TrimSpace(accountID)
+	if !accountIDRe.MatchString(accountID) {
+		return model.PushSettings{}, fmt.Errorf("account required")
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	saved, err := scanPushSettings(s.db.QueryRow(ctx, `
+		INSERT INTO push_settings (account_id, reminders, reminder_time, time_zone, new_stories)
+		SELECT $1, $2, $3::time, zone.name, $5
+		FROM pg_timezone_names AS zone
+		WHERE zone.name = $4
+		ON CONFLICT (account_id) DO UPDATE
+		SET reminders = EXCLUDED.reminders,
+		    reminder_time = EXCLUDED.reminder_time,
+		    time_zone = EXCLUDED.time_zone,
+		    new_stories = EXCLUDED.new_stories,
+		    updated_at = now()
+		RETURNING reminders, to_char(reminder_time, 'HH24:MI'), time_zone, new_stories
+	`, accountID, settings.Reminders, settings.ReminderTime, settings.TimeZone, settings.NewStories))
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.PushSettings{}, fmt.Errorf("%w", model.ErrPushTimeZone)
+	}
+	return saved, err
+}
+
+// PushQueueReminders queues today's bedtime reminder for every browser of
+// each account whose reminder time has come in its time zone, and returns
+// how many were queued.
+func (s *Store) PushQueueReminders(ctx context.Context) (int, error) {
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	result, err := s.db.Exec(ctx, `
+		WITH due AS (
+			UPDATE push_settings AS settings
+			SET last_reminded_on = (now() AT TIME ZONE settings.time_zone)::date
+			WHERE settings.reminders
+			  AND (now() AT TIME ZONE settings.time_zone)::time - settings.reminder_time
+			      BETWEEN interval '0' AND make_interval(secs => $1)
+			  AND (settings.last_reminded_on IS NULL
+			       OR settings.last_reminded_on < (now() AT TIME ZONE settings.time_zone)::date)
+			RETURNING settings.account_id
+		)
+		INSERT INTO push_deliveries (subscription_id, kind)
+		SELECT subscription.id, 'reminder'
+		FROM due
+		JOIN push_subscriptions AS subscription
+		  ON subscription.account_id = due.account_id
+	`, pushReminderWindow.Seconds())
+	if err != nil {
+		return 0, err
+	}
+	return int(result.RowsAffected()), nil
+}
+
+// PushClaim leases up to limit due notifications across accounts.
+func (s *Store) PushClaim(ctx context.Context, limit int, lease time.Duration) ([]model.PendingPush, error) {
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	rows, err := s.db.Query(ctx, `
+		WITH due AS (
+			SELECT id
+			FROM push_deliveries
+			WHERE next_attempt_at <= now()
+			ORDER BY next_attempt_at ASC, id ASC
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE push_deliveries AS delivery
+		SET next_attempt_at = now() + make_interval(secs => $2)
+		FROM due, push_subscriptions AS subscription
+		WHERE delivery.id = due.id
+		  AND subscription.id = delivery.subscription_id
+		RETURNING delivery.id, subscription.account_id, delivery.kind, COALESCE(delivery.slug, ''),
+		          subscription.endpoint, subscription.p256dh, subscription.auth,
+		          delivery.attempts, delivery.created_at
+	`, limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []model.PendingPush{}
+	for rows.Next() {
+		var (
+			item model.PendingPush
+			kind string
+		)
+		if err := rows.Scan(&item.ID, &item.AccountID, &kind, &item.Slug, &item.Endpoint,
+			&item.P256DH, &item.Auth, &item.Attempts, &item.CreatedAt); err != nil {
+			return nil, err
+		}
+		item.Kind = model.PushKind(kind)
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// PushRecordAttempt settles one claimed notification: it is deleted once sent
+// or given up on, and its subscription too when the push service says the
+// browser is gone.
+func (s *Store) PushRecordAttempt(ctx context.Context, push model.PendingPush, attempt model.PushAttempt) error {
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	var err error
+	switch {
+	case attempt.Gone:
+		_, err = s.db.Exec(ctx, `
+			DELETE FROM push_subscriptions
+			WHERE endpoint = $1
+		`, push.Endpoint)
+	case attempt.Sent || attempt.NextAttemptAt == nil:
+		_, err = s.db.Exec(ctx, `DELETE FROM push_deliveries WHERE id = $1`, push.ID)
+	default:
+		_, err = s.db.Exec(ctx, `
+			UPDATE push_deliveries
+			SET attempts = attempts + 1,
+			    next_attempt_at = $2
+			WHERE id = $1
+		`, push.ID, *attempt.NextAttemptAt)
+	}
+	return err
+}
+
+// enqueuePushStory queues a new-story notification for every browser of the
+// account, unless it has turned them off, inside the caller's transaction.
+func enqueuePushStory(ctx context.Context, tx pgx.Tx, accountID, slug string) error {
+	_, err := tx.Exec(ctx, `
+		INSERT INTO push_deliveries (subscription_id, kind, slug)
+		SELECT subscription.id, 'story', $2
+		FROM push_subscriptions AS subscription
+		LEFT JOIN push_settings AS settings
+		  ON settings.account_id = subscription.account_id
+		WHERE subscription.account_id = $1
+		  AND COALESCE(settings.new_stories, true)
+	`, accountID, slug)
+	return err
+}
+
+func scanPushSettings(row pgx.Row) (model.PushSettings, error) {
+	var settings model.PushSettings
+	err := row.Scan(&settings.Reminders, &settings.ReminderTime, &settings.TimeZone, &settings.NewStories)
+	return settings, err
+}
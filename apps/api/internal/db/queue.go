@@ -0,0 +1,259 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+
+	"pandapages/api/internal/model"
+)
+
+// Queue lists the up-next queue of the account's profile, first story first.
+// Stories taken down since they were queued are left out.
+func (s *Store) Queue(ctx context.Context, accountID string) ([]model.QueueItem, error) {
+	accountID = strings.TrimSpace(accountID)
+	if !accountIDRe.MatchString(accountID) {
+		return nil, fmt.Errorf("account required")
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	profileID, err := s.getDefaultProfileID(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	return queueItems(ctx, s.db, profileID)
+}
+
+// QueueAdd puts a published story at the end of the queue. A story already
+// queued keeps its place, and added reports false.
+func (s *Store) QueueAdd(ctx context.Context, accountID, slug string) ([]model.QueueItem, bool, error) {
+	accountID = strings.TrimSpace(accountID)
+	if !accountIDRe.MatchString(accountID) {
+		return nil, false, fmt.Errorf("account required")
+	}
+	slug = strings.TrimSpace(slug)
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	tx, profileID, err := s.lockQueue(ctx, accountID)
+	if err != nil {
+		return nil, false, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var storyID string
+	err = tx.QueryRow(ctx, `
+		SELECT id
+		FROM stories
+		WHERE account_id = $1
+		  AND slug = $2
+		  AND is_published = true
+		  AND published_version_id IS NOT NULL
+	`, accountID, slug).Scan(&storyID)
+	if err != nil {
+		return nil, false, s.removedOr(ctx, accountID, slug, err)
+	}
+
+	var (
+		present bool
+		queued  int
+		next    int
+	)
+	if err := tx.QueryRow(ctx, `
+		SELECT COALESCE(bool_or(story_id = $2), false), count(*), COALESCE(MAX(position) + 1, 0)
+		FROM reading_queue
+		WHERE profile_id = $1
+	`, profileID, storyID).Scan(&present, &queued, &next); err != nil {
+		return nil, false, err
+	}
+	if !present {
+		if queued >= model.MaxQueueItems {
+			return nil, false, fmt.Errorf("%w", model.ErrQueueFull)
+		}
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO reading_queue (profile_id, story_id, position)
+			VALUES ($1, $2, $3)
+		`, profileID, storyID, next); err != nil {
+			return nil, false, err
+		}
+	}
+
+	items, err := queueItems(ctx, tx, profileID)
+	if err != nil {
+		return nil, false, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, false, err
+	}
+	return items, !present, nil
+}
+
+// QueueRemove takes a story out of the queue.
+func (s *Store) QueueRemove(ctx context.Context, accountID, slug string) ([]model.QueueItem, error) {
+	accountID = strings.TrimSpace(accountID)
+	if !accountIDRe.MatchString(accountID) {
+		return nil, fmt.Errorf("account required")
+	}
+	slug = strings.TrimSpace(slug)
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	tx, profileID, err := s.lockQueue(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	tag, err := tx.Exec(ctx, `
+		DELETE FROM reading_queue q
+		USING stories st
+		WHERE q.profile_id = $1
+		  AND st.id = q.story_id
+		  AND st.account_id = $2
+		  AND st.slug = $3
+	`, profileID, accountID, slug)
+	if err != nil {
+		return nil, err
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, fmt.Errorf("%w", model.ErrQueueItemNotFound)
+	}
+
+	items, err := queueItems(ctx, tx, profileID)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// QueueReorder puts the queue in the order slugs gives, which must name every
+// queued story exactly once.
+func (s *Store) QueueReorder(ctx context.Context, accountID string, slugs []string) ([]model.QueueItem, error) {
+	accountID = strings.TrimSpace(accountID)
+	if !accountIDRe.MatchString(accountID) {
+		return nil, fmt.Errorf("account required")
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	tx, profileID, err := s.lockQueue(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	current, err := queueItems(ctx, tx, profileID)
+	if err != nil {
+		return nil, err
+	}
+	if len(slugs) != len(current) {
+		return nil, fmt.Errorf("%w", model.ErrQueueOrder)
+	}
+	queued := make(map[string]bool, len(current))
+	for _, item := range current {
+		queued[item.Slug] = true
+	}
+	for _, slug := range slugs {
+		if !queued[slug] {
+			return nil, fmt.Errorf("%w", model.ErrQueueOrder)
+		}
+		delete(queued, slug)
+	}
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE reading_queue q
+		SET position = ordered.position - 1
+		FROM unnest($3::text[]) WITH ORDINALITY AS ordered(slug, position)
+		JOIN stories st
+		  ON st.account_id = $2
+		 AND st.slug = ordered.slug
+		WHERE q.profile_id = $1
+		  AND q.story_id = st.id
+	`, profileID, accountID, slugs); err != nil {
+		return nil, err
+	}
+
+	items, err := queueItems(ctx, tx, profileID)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// lockQueue begins a transaction holding the profile's row, so concurrent
+// changes to its queue take turns, and drops stories taken down since they
+// were queued.
+func (s *Store) lockQueue(ctx context.Context, accountID string) (pgx.Tx, string, error) {
+	profileID, err := s.getDefaultProfileID(ctx, accountID)
+	if err != nil {
+		return nil, "", err
+	}
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	var locked string
+	if err := tx.QueryRow(ctx, `
+		SELECT id
+		FROM profiles
+		WHERE id = $1
+		  AND account_id = $2
+		FOR UPDATE
+	`, profileID, accountID).Scan(&locked); err != nil {
+		_ = tx.Rollback(ctx)
+		return nil, "", err
+	}
+	if _, err := tx.Exec(ctx, `
+		DELETE FROM reading_queue q
+		USING stories st
+		WHERE q.profile_id = $1
+		  AND st.id = q.story_id
+		  AND NOT (st.is_published AND st.published_version_id IS NOT NULL)
+	`, profileID); err != nil {
+		_ = tx.Rollback(ctx)
+		return nil, "", err
+	}
+	return tx, profileID, nil
+}
+
+func queueItems(ctx context.Context, q storedVersionQueryer, profileID string) ([]model.QueueItem, error) {
+	rows, err := q.Query(ctx, `
+		SELECT st.slug, st.title, q.added_at
+		FROM reading_queue q
+		JOIN stories st ON st.id = q.story_id
+		WHERE q.profile_id = $1
+		  AND st.is_published = true
+		  AND st.published_version_id IS NOT NULL
+		ORDER BY q.position, q.added_at, st.slug
+	`, profileID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []model.QueueItem{}
+	for rows.Next() {
+		var item model.QueueItem
+		if err := rows.Scan(&item.Slug, &item.Title, &item.AddedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,372 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"pandapages/api/internal/model"
+	"pandapages/api/internal/readercontract"
+	"pandapages/api/internal/storyingest"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// quizSegment is one stored segment as quiz chapters are cut from it.
+type quizSegment struct {
+	Kind              readercontract.SegmentKind
+	ContentKey        string
+	ContentOccurrence int
+	ChapterKey        *string
+	ChapterOccurrence *int
+	Markdown          string
+}
+
+// quizChapters groups a version's segments, in reading order, into its
+// chapters. Text before the first chapter heading belongs to no chapter; a
+// version without chapters is one untitled chapter. Asides are notes for
+// grown-ups and page and scene breaks have no text, so none is quizzed on.
+func quizChapters(segments []quizSegment) []model.QuizChapterSource {
+	chaptered := false
+	for _, segment := range segments {
+		if segment.ChapterKey != nil {
+			chaptered = true
+			break
+		}
+	}
+	var (
+		chapters []model.QuizChapterSource
+		texts    []string
+	)
+	flush := func() {
+		if len(chapters) > 0 {
+			chapters[len(chapters)-1].Markdown = strings.Join(texts, "\n\n")
+		}
+		texts = texts[:0]
+	}
+	if !chaptered {
+		chapters = append(chapters, model.QuizChapterSource{Chapter: 1})
+	}
+	for _, segment := range segments {
+		opens := segment.Kind == readercontract.SegmentKindHeading && segment.ChapterKey != nil &&
+			*segment.ChapterKey == segment.ContentKey && segment.ChapterOccurrence != nil &&
+			*segment.ChapterOccurrence == segment.ContentOccurrence
+		if opens {
+			flush()
+			key, occurrence := *segment.ChapterKey, *segment.ChapterOccurrence
+			chapter := model.QuizChapterSource{Chapter: len(chapters) + 1, ChapterKey: &key, ChapterOccurrence: &occurrence}
+			if title := strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(segment.Markdown), "#")); title != "" {
+				chapter.Title = &title
+			}
+			chapters = append(chapters, chapter)
+			continue
+		}
+		if chaptered && segment.ChapterKey == nil {
+			continue
+		}
+		switch segment.Kind {
+		case readercontract.SegmentKindAside, readercontract.SegmentKindPageBreak, readercontract.SegmentKindSceneBreak:
+			continue
+		}
+		if text := strings.TrimSpace(segment.Markdown); text != "" {
+			texts = append(texts, text)
+		}
+	}
+	flush()
+	for index := range chapters {
+		chapters[index].ChapterCount = len(chapters)
+	}
+	return chapters
+}
+
+// adminQuizChapters returns the chapters of a version of the account's story
+// at slug, or ErrAdminStoryNotFound when there is no such version.
+func (s *Store) adminQuizChapters(ctx context.Context, accountID, slug, versionID string) ([]model.QuizChapterSource, error) {
+	rows, err := s.writes(ctx).Query(ctx, `
+		SELECT segment.segment_kind, segment.content_key, segment.content_occurrence,
+			segment.chapter_key, segment.chapter_occurrence, segment.markdown
+		FROM stories AS story
+		JOIN story_versions AS version
+		  ON version.story_id = story.id
+		 AND version.id = $3::uuid
+		LEFT JOIN story_segments AS segment
+		  ON segment.story_version_id = version.id
+		WHERE story.account_id = $1
+		  AND story.slug = $2
+		ORDER BY segment.ordinal ASC
+	`, accountID, slug, versionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	found := false
+	segments := []quizSegment{}
+	for rows.Next() {
+		var (
+			kind, contentKey  sql.NullString
+			contentOccurrence sql.NullInt64
+			chapterKey        sql.NullString
+			chapterOccurrence sql.NullInt64
+			markdown          sql.NullString
+		)
+		if err := rows.Scan(&kind, &contentKey, &contentOccurrence, &chapterKey, &chapterOccurrence, &markdown); err != nil {
+			return nil, err
+		}
+		// A version without segments still yields one row, of NULLs.
+		found = true
+		if !kind.Valid {
+			continue
+		}
+		segments = append(segments, quizSegment{
+			Kind:              readercontract.SegmentKind(kind.String),
+			ContentKey:        contentKey.String,
+			ContentOccurrence: int(contentOccurrence.Int64),
+			ChapterKey:        nullStringValue(chapterKey),
+			ChapterOccurrence: nullIntValue(chapterOccurrence),
+			Markdown:          markdown.String,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("%w", model.ErrAdminStoryNotFound)
+	}
+	return quizChapters(segments), nil
+}
+
+func validQuizTarget(accountID, slug, versionID string) error {
+	if !accountIDRe.MatchString(accountID) {
+		return fmt.Errorf("account required")
+	}
+	if storyingest.ValidateSlug(slug) != nil || !accountIDRe.MatchString(versionID) {
+		return fmt.Errorf("%w", model.ErrAdminStoryNotFound)
+	}
+	return nil
+}
+
+// AdminQuizChapter returns the text of one chapter of a story version, to
+// write its quiz from.
+func (s *Store) AdminQuizChapter(ctx context.Context, accountID, slug, versionID string, chapter int) (model.QuizChapterSource, error) {
+	accountID, versionID = strings.TrimSpace(accountID), strings.TrimSpace(versionID)
+	if err := validQuizTarget(accountID, slug, versionID); err != nil {
+		return model.QuizChapterSource{}, err
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	chapters, err := s.adminQuizChapters(ctx, accountID, slug, versionID)
+	if err != nil {
+		return model.QuizChapterSource{}, err
+	}
+	if chapter < 1 || chapter > len(chapters) {
+		return model.QuizChapterSource{}, fmt.Errorf("%w", model.ErrQuizChapterNotFound)
+	}
+	return chapters[chapter-1], nil
+}
+
+// AdminGetQuiz lists every chapter of a story version with its quiz, if one
+// has been written.
+func (s *Store) AdminGetQuiz(ctx context.Context, accountID, slug, versionID string) (model.AdminQuizResponse, error) {
+	accountID, versionID = strings.TrimSpace(accountID), strings.TrimSpace(versionID)
+	if err := validQuizTarget(accountID, slug, versionID); err != nil {
+		return model.AdminQuizResponse{}, err
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	chapters, err := s.adminQuizChapters(ctx, accountID, slug, versionID)
+	if err != nil {
+		return model.AdminQuizResponse{}, err
+	}
+	out := model.AdminQuizResponse{Slug: slug, VersionID: versionID, Chapters: make([]model.AdminChapterQuiz, len(chapters))}
+	for index, chapter := range chapters {
+		out.Chapters[index] = model.AdminChapterQuiz{ChapterQuiz: model.ChapterQuiz{
+			Chapter: chapter.Chapter, ChapterKey: chapter.ChapterKey, ChapterOccurrence: chapter.ChapterOccurrence,
+			Title: chapter.Title, Questions: []model.QuizQuestion{},
+		}}
+	}
+
+	rows, err := s.db.Query(ctx, `
+		SELECT chapter, questions::text, status, model, prompt_version, updated_at
+		FROM story_quizzes
+		WHERE account_id = $1
+		  AND story_version_id = $2
+		ORDER BY chapter ASC
+	`, accountID, versionID)
+	if err != nil {
+		return model.AdminQuizResponse{}, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		quiz, chapter, err := scanAdminChapterQuiz(rows)
+		if err != nil {
+			return model.AdminQuizResponse{}, err
+		}
+		// A stored quiz always names a chapter of its version; segments are
+		// immutable, so the chapters cannot have moved since.
+		if chapter >= 1 && chapter <= len(out.Chapters) {
+			current := &out.Chapters[chapter-1]
+			current.Questions, current.Status, current.Model, current.PromptVersion, current.UpdatedAt =
+				quiz.Questions, quiz.Status, quiz.Model, quiz.PromptVersion, quiz.UpdatedAt
+		}
+	}
+	return out, rows.Err()
+}
+
+func scanAdminChapterQuiz(row pgx.Row) (model.AdminChapterQuiz, int, error) {
+	var (
+		out               model.AdminChapterQuiz
+		chapter           int
+		questions, status string
+		modelName, prompt sql.NullString
+		updatedAt         time.Time
+	)
+	if err := row.Scan(&chapter, &questions, &status, &modelName, &prompt, &updatedAt); err != nil {
+		return model.AdminChapterQuiz{}, 0, err
+	}
+	if err := json.Unmarshal([]byte(questions), &out.Questions); err != nil {
+		return model.AdminChapterQuiz{}, 0, fmt.Errorf("decode quiz questions: %w", err)
+	}
+	quizStatus := model.QuizStatus(status)
+	updated := updatedAt.UTC().Format(time.RFC3339Nano)
+	out.Status, out.Model, out.PromptVersion, out.UpdatedAt = &quizStatus, nullStringValue(modelName), nullStringValue(prompt), &updated
+	return out, chapter, nil
+}
+
+// AdminSaveQuiz writes a chapter's quiz, replacing any it had.
+func (s *Store) AdminSaveQuiz(ctx context.Context, accountID, slug, versionID string, chapter int, save model.QuizSave) (model.AdminChapterQuiz, error) {
+	accountID, versionID = strings.TrimSpace(accountID), strings.TrimSpace(versionID)
+	if err := validQuizTarget(accountID, slug, versionID); err != nil {
+		return model.AdminChapterQuiz{}, err
+	}
+	if save.Status != model.QuizDraft && save.Status != model.QuizApproved {
+		return model.AdminChapterQuiz{}, fmt.Errorf("quiz status %q is invalid", save.Status)
+	}
+	questions, err := json.Marshal(save.Questions)
+	if err != nil {
+		return model.AdminChapterQuiz{}, err
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	chapters, err := s.adminQuizChapters(ctx, accountID, slug, versionID)
+	if err != nil {
+		return model.AdminChapterQuiz{}, err
+	}
+	if chapter < 1 || chapter > len(chapters) {
+		return model.AdminChapterQuiz{}, fmt.Errorf("%w", model.ErrQuizChapterNotFound)
+	}
+	source := chapters[chapter-1]
+	quiz, _, err := scanAdminChapterQuiz(s.writes(ctx).QueryRow(ctx, `
+		INSERT INTO story_quizzes (
+			story_version_id, chapter, account_id, chapter_key, chapter_occurrence, title,
+			questions, status, model, prompt_version
+		)
+		VALUES ($2, $3, $1, $4, $5, $6, $7::jsonb, $8, NULLIF($9, ''), NULLIF($10, ''))
+		ON CONFLICT (story_version_id, chapter) DO UPDATE
+		SET chapter_key = EXCLUDED.chapter_key,
+		    chapter_occurrence = EXCLUDED.chapter_occurrence,
+		    title = EXCLUDED.title,
+		    questions = EXCLUDED.questions,
+		    status = EXCLUDED.status,
+		    model = EXCLUDED.model,
+		    prompt_version = EXCLUDED.prompt_version,
+		    updated_at = now()
+		RETURNING chapter, questions::text, status, model, prompt_version, updated_at
+	`, accountID, versionID, chapter, source.ChapterKey, source.ChapterOccurrence, source.Title,
+		string(questions), string(save.Status), save.Model, save.PromptVersion))
+	if err != nil {
+		return model.AdminChapterQuiz{}, err
+	}
+	quiz.Chapter, quiz.ChapterKey, quiz.ChapterOccurrence, quiz.Title = source.Chapter, source.ChapterKey, source.ChapterOccurrence, source.Title
+	return quiz, nil
+}
+
+// AdminDeleteQuiz removes a chapter's quiz. A chapter without one is
+// ErrQuizNotFound.
+func (s *Store) AdminDeleteQuiz(ctx context.Context, accountID, slug, versionID string, chapter int) error {
+	accountID, versionID = strings.TrimSpace(accountID), strings.TrimSpace(versionID)
+	if err := validQuizTarget(accountID, slug, versionID); err != nil {
+		return err
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	tag, err := s.writes(ctx).Exec(ctx, `
+		DELETE FROM story_quizzes AS quiz
+		USING story_versions AS version, stories AS story
+		WHERE quiz.story_version_id = $3
+		  AND quiz.chapter = $4
+		  AND quiz.account_id = $1
+		  AND version.id = quiz.story_version_id
+		  AND story.id = version.story_id
+		  AND story.account_id = $1
+		  AND story.slug = $2
+	`, accountID, slug, versionID, chapter)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%w", model.ErrQuizNotFound)
+	}
+	return nil
+}
+
+// ReaderQuiz returns the approved quizzes of a story's published version. A
+// story with none reports sql.ErrNoRows, as a missing story does.
+func (s *Store) ReaderQuiz(ctx context.Context, accountID, slug string) (model.StoryQuiz, error) {
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	rows, err := s.reads().Query(ctx, `
+		SELECT st.slug, version.version, quiz.chapter, quiz.chapter_key, quiz.chapter_occurrence,
+			quiz.title, quiz.questions::text
+		FROM stories st
+		JOIN story_versions AS version
+		  ON version.id = st.published_version_id
+		 AND version.story_id = st.id
+		JOIN story_quizzes AS quiz
+		  ON quiz.story_version_id = version.id
+		 AND quiz.status = 'approved'
+		WHERE st.account_id = $1
+		  AND st.slug = $2
+		  AND st.is_published = true
+		ORDER BY quiz.chapter ASC
+	`, accountID, slug)
+	if err != nil {
+		return model.StoryQuiz{}, err
+	}
+	defer rows.Close()
+	out := model.StoryQuiz{Chapters: []model.ChapterQuiz{}}
+	for rows.Next() {
+		var (
+			chapter           model.ChapterQuiz
+			chapterKey, title sql.NullString
+			chapterOccurrence sql.NullInt64
+			questions         string
+		)
+		if err := rows.Scan(&out.Slug, &out.Version, &chapter.Chapter, &chapterKey, &chapterOccurrence, &title, &questions); err != nil {
+			return model.StoryQuiz{}, err
+		}
+		if err := json.Unmarshal([]byte(questions), &chapter.Questions); err != nil {
+			return model.StoryQuiz{}, fmt.Errorf("decode quiz questions: %w", err)
+		}
+		chapter.ChapterKey, chapter.ChapterOccurrence, chapter.Title = nullStringValue(chapterKey), nullIntValue(chapterOccurrence), nullStringValue(title)
+		out.Chapters = append(out.Chapters, chapter)
+	}
+	if err := rows.Err(); err != nil {
+		return model.StoryQuiz{}, err
+	}
+	if len(out.Chapters) == 0 {
+		return model.StoryQuiz{}, s.removedOr(ctx, accountID, slug, sql.ErrNoRows)
+	}
+	return out, nil
+}
@@ -0,0 +1,39 @@
+package db
+
+import (
+	"testing"
+
+	"pandapages/api/internal/readercontract"
+)
+
+func TestQuizChaptersGroupSegmentsUnderTheirHeadings(t *testing.T) {
+	key := func(value string) *string { return &value }
+	one := func() *int { value := 1; return &value }
+	segments := []quizSegment{
+		{Kind: readercontract.SegmentKindParagraph, ContentKey: "intro", ContentOccurrence: 1, Markdown: "A note before the story."},
+		{Kind: readercontract.SegmentKindHeading, ContentKey: "first", ContentOccurrence: 1, ChapterKey: key("first"), ChapterOccurrence: one(), Markdown: "## The Station"},
+		{Kind: readercontract.SegmentKindParagraph, ContentKey: "p1", ContentOccurrence: 1, ChapterKey: key("first"), ChapterOccurrence: one(), Markdown: "The train yawned."},
+		{Kind: readercontract.SegmentKindAside, ContentKey: "aside", ContentOccurrence: 1, ChapterKey: key("first"), ChapterOccurrence: one(), Markdown: "Ask about trains."},
+		{Kind: readercontract.SegmentKindHeading, ContentKey: "sub", ContentOccurrence: 1, ChapterKey: key("first"), ChapterOccurrence: one(), Markdown: "### Later"},
+		{Kind: readercontract.SegmentKindHeading, ContentKey: "second", ContentOccurrence: 1, ChapterKey: key("second"), ChapterOccurrence: one(), Markdown: "## Home"},
+		{Kind: readercontract.SegmentKindSceneBreak, ContentKey: "break", ContentOccurrence: 1, ChapterKey: key("second"), ChapterOccurrence: one(), Markdown: "***"},
+		{Kind: readercontract.SegmentKindParagraph, ContentKey: "p2", ContentOccurrence: 1, ChapterKey: key("second"), ChapterOccurrence: one(), Markdown: "All asleep."},
+	}
+	chapters := quizChapters(segments)
+	if len(chapters) != 2 || chapters[0].ChapterCount != 2 {
+		t.Fatalf("chapters = %#v", chapters)
+	}
+	if chapters[0].Chapter != 1 || *chapters[0].Title != "The Station" || *chapters[0].ChapterKey != "first" ||
+		chapters[0].Markdown != "The train yawned.\n\n### Later" {
+		t.Fatalf("first chapter = %#v", chapters[0])
+	}
+	if chapters[1].Chapter != 2 || *chapters[1].Title != "Home" || chapters[1].Markdown != "All asleep." {
+		t.Fatalf("second chapter = %#v", chapters[1])
+	}
+
+	whole := quizChapters(segments[:1])
+	if len(whole) != 1 || whole[0].Chapter != 1 || whole[0].ChapterKey != nil || whole[0].Title != nil ||
+		whole[0].Markdown != "A note before the story." {
+		t.Fatalf("unchaptered = %#v", whole)
+	}
+}
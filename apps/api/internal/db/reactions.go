@@ -0,0 +1,84 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"pandapages/api/internal/model"
+)
+
+// SetStoryReaction records the profile's reaction to a published story,
+// replacing any earlier one; a nil reaction takes it back. It answers with
+// the story's counts after the change.
+func (s *Store) SetStoryReaction(ctx context.Context, accountID, slug string, reaction *model.StoryReaction) (model.StoryReactionResponse, error) {
+	accountID = strings.TrimSpace(accountID)
+	if !accountIDRe.MatchString(accountID) {
+		return model.StoryReactionResponse{}, fmt.Errorf("account required")
+	}
+	slug = strings.TrimSpace(slug)
+	if reaction != nil && !reaction.Valid() {
+		return model.StoryReactionResponse{}, fmt.Errorf("unknown reaction %q", *reaction)
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	profileID, err := s.getDefaultProfileID(ctx, accountID)
+	if err != nil {
+		return model.StoryReactionResponse{}, err
+	}
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return model.StoryReactionResponse{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var storyID string
+	err = tx.QueryRow(ctx, `
+		SELECT id
+		FROM stories
+		WHERE account_id = $1
+		  AND slug = $2
+		  AND is_published = true
+		  AND published_version_id IS NOT NULL
+	`, accountID, slug).Scan(&storyID)
+	if err != nil {
+		return model.StoryReactionResponse{}, s.removedOr(ctx, accountID, slug, err)
+	}
+
+	if reaction != nil {
+		_, err = tx.Exec(ctx, `
+			INSERT INTO story_reactions (profile_id, story_id, reaction)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (profile_id, story_id) DO UPDATE
+			SET reaction = EXCLUDED.reaction,
+			    updated_at = now()
+		`, profileID, storyID, string(*reaction))
+	} else {
+		_, err = tx.Exec(ctx, `
+			DELETE FROM story_reactions
+			WHERE profile_id = $1
+			  AND story_id = $2
+		`, profileID, storyID)
+	}
+	if err != nil {
+		return model.StoryReactionResponse{}, err
+	}
+
+	out := model.StoryReactionResponse{Reaction: reaction}
+	if err := tx.QueryRow(ctx, `
+		SELECT
+			count(*) FILTER (WHERE reaction = 'loved_it'),
+			count(*) FILTER (WHERE reaction = 'silly'),
+			count(*) FILTER (WHERE reaction = 'scary')
+		FROM story_reactions
+		WHERE story_id = $1
+	`, storyID).Scan(&out.Counts.LovedIt, &out.Counts.Silly, &out.Counts.Scary); err != nil {
+		return model.StoryReactionResponse{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return model.StoryReactionResponse{}, err
+	}
+	return out, nil
+}
@@ -0,0 +1,39 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"pandapages/api/internal/model"
+	"pandapages/api/internal/storyingest"
+)
+
+func readabilityJSON(r storyingest.Readability) ([]byte, error) {
+	return json.Marshal(readabilityModel(r))
+}
+
+func readabilityModel(r storyingest.Readability) model.Readability {
+	return model.Readability{
+		Words:               r.Words,
+		Sentences:           r.Sentences,
+		Syllables:           r.Syllables,
+		AvgWordsPerSentence: r.AvgWordsPerSentence,
+		AvgSyllablesPerWord: r.AvgSyllablesPerWord,
+		AvgWordLength:       r.AvgWordLength,
+		FleschReadingEase:   r.FleschReadingEase,
+		FleschKincaidGrade:  r.FleschKincaidGrade,
+	}
+}
+
+// decodeReadability treats metrics as advisory metadata: a NULL or unreadable
+// value is reported as absent rather than making the version unreadable.
+func decodeReadability(raw sql.NullString) *model.Readability {
+	if !raw.Valid {
+		return nil
+	}
+	var out model.Readability
+	if err := json.Unmarshal([]byte(raw.String), &out); err != nil {
+		return nil
+	}
+	return &out
+}
@@ -0,0 +1,168 @@
+package db
+
+import (
+	"container/list"
+	"sync"
+
+	"pandapages/api/internal/model"
+)
+
+// defaultReaderCacheBytes bounds the published versions kept in memory.
+const defaultReaderCacheBytes = 64 << 20
+
+// readerCacheKey names one published version of one story. Version ids are
+// never reused, so an entry can only go stale by being unpublished.
+type readerCacheKey struct {
+	accountID string
+	slug      string
+	versionID string
+}
+
+// readerVersion is the immutable part of a Reader payload: everything that
+// belongs to the version rather than to the story or account.
+type readerVersion struct {
+	readability *model.Readability
+	segments    []model.ReaderSegment
+}
+
+// size approximates the memory an entry holds, dominated by rendered HTML.
+func (v readerVersion) size() int {
+	size := 0
+	for _, segment := range v.segments {
+		size += len(segment.RenderedHTML) + len(segment.ContentKey) + 128
+		if segment.ChapterKey != nil {
+			size += len(*segment.ChapterKey)
+		}
+		for _, hint := range segment.Pronunciations {
+			size += len(hint.Text) + len(hint.Say) + len(hint.IPA)
+		}
+	}
+	return size
+}
+
+type readerCacheEntry struct {
+	key     readerCacheKey
+	version readerVersion
+	size    int
+}
+
+// readerCache is a least-recently-used cache of validated published
+// versions, bounded by their approximate size. A nil cache stores nothing.
+type readerCache struct {
+	mu       sync.Mutex
+	capacity int
+	used     int
+	order    *list.List
+	entries  map[readerCacheKey]*list.Element
+	hits     int64
+	misses   int64
+}
+
+func newReaderCache(capacity int) *readerCache {
+	if capacity <= 0 {
+		return nil
+	}
+	return &readerCache{
+		capacity: capacity,
+		order:    list.New(),
+		entries:  map[readerCacheKey]*list.Element{},
+	}
+}
+
+// get returns a copy of the cached version, so callers may rewrite segments.
+func (c *readerCache) get(key readerCacheKey) (readerVersion, bool) {
+	if c == nil {
+		return readerVersion{}, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	element, ok := c.entries[key]
+	if !ok {
+		c.misses++
+		return readerVersion{}, false
+	}
+	c.hits++
+	c.order.MoveToFront(element)
+	cached := element.Value.(*readerCacheEntry).version
+	return readerVersion{
+		readability: cached.readability,
+		segments:    append([]model.ReaderSegment(nil), cached.segments...),
+	}, true
+}
+
+// put stores a version, evicting the least recently read ones to make room.
+// A version larger than the whole cache is not kept.
+func (c *readerCache) put(key readerCacheKey, version readerVersion) {
+	if c == nil {
+		return
+	}
+	size := version.size()
+	stored := readerVersion{
+		readability: version.readability,
+		segments:    append([]model.ReaderSegment(nil), version.segments...),
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if element, ok := c.entries[key]; ok {
+		c.remove(element)
+	}
+	if size > c.capacity {
+		return
+	}
+	c.entries[key] = c.order.PushFront(&readerCacheEntry{key: key, version: stored, size: size})
+	c.used += size
+	for c.used > c.capacity {
+		c.remove(c.order.Back())
+	}
+}
+
+// forget drops every cached version of a story. Publishing, unpublishing and
+// re-rendering call it, on this instance directly and on the others through
+// invalidationListener, so the old version does not linger until it is
+// evicted.
+func (c *readerCache) forget(accountID, slug string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, element := range c.entries {
+		if key.accountID == accountID && key.slug == slug {
+			c.remove(element)
+		}
+	}
+}
+
+// clear drops every cached version, for when invalidations may have been
+// missed.
+func (c *readerCache) clear() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.order.Init()
+	clear(c.entries)
+	c.used = 0
+}
+
+func (c *readerCache) remove(element *list.Element) {
+	entry := c.order.Remove(element).(*readerCacheEntry)
+	delete(c.entries, entry.key)
+	c.used -= entry.size
+}
+
+// stats reports lookups since startup and the memory now held. A nil cache
+// reports nothing, since every read goes to the database.
+func (c *readerCache) stats() model.CacheStats {
+	if c == nil {
+		return model.CacheStats{}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := cacheStats(c.hits, c.misses)
+	out.Entries = len(c.entries)
+	out.Bytes = c.used
+	out.CapacityBytes = c.capacity
+	return out
+}
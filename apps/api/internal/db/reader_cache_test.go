@@ -0,0 +1,103 @@
+package db
+
+import (
+	"strings"
+	"testing"
+
+	"pandapages/api/internal/model"
+)
+
+func cachedVersion(html string) readerVersion {
+	return readerVersion{segments: []model.ReaderSegment{{Ordinal: 1, Kind: "paragraph", RenderedHTML: html}}}
+}
+
+func TestReaderCacheEvictsLeastRecentlyRead(t *testing.T) {
+	one := cachedVersion(strings.Repeat("a", 100))
+	cache := newReaderCache(2*one.size() + 10)
+	first := readerCacheKey{accountID: "account", slug: "first", versionID: "v1"}
+	second := readerCacheKey{accountID: "account", slug: "second", versionID: "v1"}
+	third := readerCacheKey{accountID: "account", slug: "third", versionID: "v1"}
+
+	cache.put(first, one)
+	cache.put(second, one)
+	if _, ok := cache.get(first); !ok {
+		t.Fatal("first version missing")
+	}
+	cache.put(third, one)
+	if _, ok := cache.get(second); ok {
+		t.Fatal("least recently read version was kept")
+	}
+	if _, ok := cache.get(first); !ok {
+		t.Fatal("recently read version was evicted")
+	}
+
+	cache.put(first, cachedVersion(strings.Repeat("b", 4*one.size())))
+	if _, ok := cache.get(first); ok {
+		t.Fatal("version larger than the cache was stored")
+	}
+}
+
+func TestReaderCacheForgetsEveryVersionOfAStory(t *testing.T) {
+	cache := newReaderCache(1 << 20)
+	old := readerCacheKey{accountID: "account", slug: "story", versionID: "v1"}
+	current := readerCacheKey{accountID: "account", slug: "story", versionID: "v2"}
+	other := readerCacheKey{accountID: "other", slug: "story", versionID: "v3"}
+	for _, key := range []readerCacheKey{old, current, other} {
+		cache.put(key, cachedVersion("<p>text</p>"))
+	}
+
+	cache.forget("account", "story")
+	for _, key := range []readerCacheKey{old, current} {
+		if _, ok := cache.get(key); ok {
+			t.Fatalf("%s survived forget", key.versionID)
+		}
+	}
+	if _, ok := cache.get(other); !ok {
+		t.Fatal("another account's story was forgotten")
+	}
+	if cache.used != cachedVersion("<p>text</p>").size() {
+		t.Fatalf("used = %d after forget", cache.used)
+	}
+}
+
+func TestReaderCacheHandsOutCopies(t *testing.T) {
+	cache := newReaderCache(1 << 20)
+	key := readerCacheKey{accountID: "account", slug: "story", versionID: "v1"}
+	cache.put(key, cachedVersion("<p>original</p>"))
+
+	version, _ := cache.get(key)
+	version.segments[0].RenderedHTML = "<p>rewritten</p>"
+	again, _ := cache.get(key)
+	if again.segments[0].RenderedHTML != "<p>original</p>" {
+		t.Fatalf("cached segment was rewritten: %q", again.segments[0].RenderedHTML)
+	}
+
+	var disabled *readerCache
+	disabled.put(key, version)
+	if _, ok := disabled.get(key); ok {
+		t.Fatal("disabled cache returned a version")
+	}
+}
+
+func TestReaderCacheCountsHitsAndMisses(t *testing.T) {
+	one := cachedVersion("story")
+	cache := newReaderCache(1 << 20)
+	key := readerCacheKey{accountID: "account", slug: "story", versionID: "v1"}
+
+	cache.get(key)
+	cache.put(key, one)
+	cache.get(key)
+	cache.get(key)
+	cache.get(key)
+
+	stats := cache.stats()
+	if stats.Hits != 3 || stats.Misses != 1 || stats.HitRate != 0.75 {
+		t.Fatalf("stats = %#v, want 3 hits, 1 miss", stats)
+	}
+	if stats.Entries != 1 || stats.Bytes != one.size() || stats.CapacityBytes != 1<<20 {
+		t.Fatalf("stats = %#v", stats)
+	}
+	if disabled := (*readerCache)(nil).stats(); disabled != (model.CacheStats{}) {
+		t.Fatalf("nil cache stats = %#v", disabled)
+	}
+}
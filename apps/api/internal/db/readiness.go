@@ -2,11 +2,11 @@ package db
 
 import (
 	"context"
-	"database/sql"
 	"errors"
 	"strings"
 
 	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"pandapages/api/internal/readiness"
 	"pandapages/api/internal/schema"
 )
@@ -34,16 +34,16 @@ type readinessProbe interface {
 }
 
 type sqlReadinessProbe struct {
-	db *sql.DB
+	db *pgxpool.Pool
 }
 
 func (probe sqlReadinessProbe) Ping(ctx context.Context) error {
-	return probe.db.PingContext(ctx)
+	return probe.db.Ping(ctx)
 }
 
 func (probe sqlReadinessProbe) MigrationMetadataExists(ctx context.Context) (bool, error) {
 	var exists bool
-	err := probe.db.QueryRowContext(ctx, `
+	err := probe.db.QueryRow(ctx, `
 		SELECT to_regclass('public.goose_db_version') IS NOT NULL
 	`).Scan(&exists)
 	return exists, err
@@ -51,7 +51,7 @@ func (probe sqlReadinessProbe) MigrationMetadataExists(ctx context.Context) (boo
 
 func (probe sqlReadinessProbe) MigrationState(ctx context.Context) (migrationState, error) {
 	var state migrationState
-	err := probe.db.QueryRowContext(ctx, `
+	err := probe.db.QueryRow(ctx, `
 		WITH latest_version_state AS (
 			SELECT DISTINCT ON (version_id)
 				version_id,
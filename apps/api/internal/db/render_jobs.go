@@ -0,0 +1,423 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"pandapages/api/internal/model"
+	"pandapages/api/internal/storyingest"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const renderJobColumns = `
+	id, account_id, status,
+	total_versions, rendered_versions, unchanged_versions, skipped_versions,
+	COALESCE(cursor_version_id::text, ''), error, created_at, updated_at, finished_at
+`
+
+// AdminStartRenderJob queues a re-render of every stored version of the
+// account. Only one job may be queued or running per account.
+func (s *Store) AdminStartRenderJob(ctx context.Context, accountID string) (model.RenderJob, error) {
+	accountID = strings.TrimSpace(accountID)
+	if !accountIDRe.MatchString(accountID) {
+		return model.RenderJob{}, fmt.Errorf("account required")
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	job, err := scanRenderJob(s.writes(ctx).QueryRow(ctx, `
+		INSERT INTO render_jobs (account_id, total_versions)
+		SELECT $1, count(*)
+		FROM story_versions AS version
+		JOIN stories AS story
+		  ON story.id = version.story_id
+		WHERE story.account_id = $1
+		RETURNING `+renderJobColumns, accountID))
+	if isUniqueViolation(err) {
+		return model.RenderJob{}, model.ErrRenderJobActive
+	}
+	return job, err
+}
+
+func (s *Store) AdminGetRenderJob(ctx context.Context, accountID, jobID string) (model.RenderJob, error) {
+	accountID = strings.TrimSpace(accountID)
+	if !accountIDRe.MatchString(accountID) {
+		return model.RenderJob{}, fmt.Errorf("account required")
+	}
+	if !accountIDRe.MatchString(jobID) {
+		return model.RenderJob{}, model.ErrRenderJobNotFound
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	job, err := scanRenderJob(s.db.QueryRow(ctx, `
+		SELECT `+renderJobColumns+`
+		FROM render_jobs
+		WHERE id = $1
+		  AND account_id = $2
+	`, jobID, accountID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.RenderJob{}, model.ErrRenderJobNotFound
+	}
+	return job, err
+}
+
+// RenderClaimJob leases the oldest queued job, or a running one whose worker
+// stopped renewing its lease. ok is false when there is nothing to do.
+func (s *Store) RenderClaimJob(ctx context.Context, lease time.Duration) (job model.RenderJob, ok bool, err error) {
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	job, err = scanRenderJob(s.db.QueryRow(ctx, `
+		UPDATE render_jobs
+		SET status = 'running',
+		    lease_until = now() + make_interval(secs => $1),
+		    updated_at = now()
+		WHERE id = (
+			SELECT id
+			FROM render_jobs
+			WHERE status = 'queued'
+			   OR (status = 'running' AND lease_until < now())
+			ORDER BY created_at ASC, id ASC
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+renderJobColumns, lease.Seconds()))
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.RenderJob{}, false, nil
+	}
+	if err != nil {
+		return model.RenderJob{}, false, err
+	}
+	return job, true, nil
+}
+
+// RenderJobStep re-renders the next batch of a claimed job's versions, each
+// in its own transaction, then records progress and renews the lease. The
+// job completes with the first short batch. A version that cannot be read
+// fails the job rather than being retried forever.
+func (s *Store) RenderJobStep(ctx context.Context, job model.RenderJob, batch int, lease time.Duration) (model.RenderJob, error) {
+	versionIDs, err := s.renderJobBatch(ctx, job, batch)
+	if err != nil {
+		return model.RenderJob{}, err
+	}
+
+	var rendered, unchanged, skipped int
+	cursor := job.CursorVersionID
+	var failure *string
+	for _, versionID := range versionIDs {
+		outcome, err := s.rerenderVersion(ctx, job.AccountID, versionID)
+		if err != nil {
+			message := "version " + versionID + " could not be re-rendered"
+			failure = &message
+			break
+		}
+		switch outcome {
+		case model.RenderRendered:
+			rendered++
+		case model.RenderUnchanged:
+			unchanged++
+		default:
+			skipped++
+		}
+		cursor = versionID
+	}
+
+	status := model.RenderJobRunning
+	switch {
+	case failure != nil:
+		status = model.RenderJobFailed
+	case len(versionIDs) < batch:
+		status = model.RenderJobCompleted
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	updated, err := scanRenderJob(s.db.QueryRow(ctx, `
+		UPDATE render_jobs
+		SET rendered_versions = rendered_versions + $2,
+		    unchanged_versions = unchanged_versions + $3,
+		    skipped_versions = skipped_versions + $4,
+		    cursor_version_id = NULLIF($5, '')::uuid,
+		    status = $6,
+		    error = $7,
+		    lease_until = CASE WHEN $6 = 'running' THEN now() + make_interval(secs => $8) END,
+		    finished_at = CASE WHEN $6 = 'running' THEN NULL ELSE now() END,
+		    updated_at = now()
+		WHERE id = $1
+		  AND status = 'running'
+		RETURNING `+renderJobColumns,
+		job.ID, rendered, unchanged, skipped, cursor, string(status), failure, lease.Seconds()))
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.RenderJob{}, model.ErrRenderJobNotFound
+	}
+	return updated, err
+}
+
+func (s *Store) renderJobBatch(ctx context.Context, job model.RenderJob, batch int) ([]string, error) {
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	rows, err := s.db.Query(ctx, `
+		SELECT version.id
+		FROM story_versions AS version
+		JOIN stories AS story
+		  ON story.id = version.story_id
+		WHERE story.account_id = $1
+		  AND version.id > COALESCE(NULLIF($2, '')::uuid, '00000000-0000-0000-0000-000000000000')
+		ORDER BY version.id ASC
+		LIMIT $3
+	`, job.AccountID, job.CursorVersionID, batch)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[string])
+}
+
+// rerenderVersion renders one stored version again and, when its HTML
+// changed, swaps the version and segment HTML in one transaction so readers
+// see either the old rendering or the new one. A version whose Markdown the
+// current ingester would segment differently is skipped: rewriting its
+// segment identities would orphan saved reading positions.
+func (s *Store) rerenderVersion(ctx context.Context, accountID, versionID string) (model.RenderOutcome, error) {
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var slug, frontmatterJSON, markdown, renderedHTML string
+	err = tx.QueryRow(ctx, `
+		SELECT story.slug, version.frontmatter::text, version.markdown, version.rendered_html
+		FROM story_versions AS version
+		JOIN stories AS story
+		  ON story.id = version.story_id
+		WHERE version.id = $1
+		  AND story.account_id = $2
+		FOR UPDATE OF version
+	`, versionID, accountID).Scan(&slug, &frontmatterJSON, &markdown, &renderedHTML)
+	if errors.Is(err, sql.ErrNoRows) {
+		// Pruned since the batch was listed.
+		return model.RenderSkipped, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	frontmatter, err := normalizeStoredFrontmatter([]byte(frontmatterJSON))
+	if err != nil {
+		return model.RenderSkipped, nil
+	}
+	canonical, err := frontmatter.canonicalize(slug, markdown)
+	if err != nil || canonical.Markdown != markdown {
+		return model.RenderSkipped, nil
+	}
+
+	same, changed, err := compareRenderedSegments(ctx, tx, versionID, canonical.Segments)
+	if err != nil {
+		return 0, err
+	}
+	if !same {
+		return model.RenderSkipped, nil
+	}
+	if !changed && canonical.RenderedHTML == renderedHTML {
+		return model.RenderUnchanged, nil
+	}
+
+	if err := swapRenderedHTML(ctx, tx, versionID, canonical); err != nil {
+		return 0, err
+	}
+	if err := notifyStoryChanged(ctx, tx, accountID, slug); err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	s.readerCache.forget(accountID, slug)
+	return model.RenderRendered, nil
+}
+
+// compareRenderedSegments locks a version's segments and reports whether
+// fresh has the same identities and Markdown (same), and if so whether any
+// rendered field differs (changed).
+func compareRenderedSegments(ctx context.Context, tx pgx.Tx, versionID string, fresh []storyingest.Segment) (same, changed bool, err error) {
+	rows, err := tx.Query(ctx, `
+		SELECT
+			ordinal,
+			segment_kind,
+			heading_level,
+			content_key,
+			content_occurrence,
+			chapter_key,
+			chapter_occurrence,
+			markdown,
+			rendered_html,
+			word_count,
+			speaker,
+			content_hash
+		FROM story_segments
+		WHERE story_version_id = $1
+		ORDER BY ordinal ASC
+		FOR UPDATE
+	`, versionID)
+	if err != nil {
+		return false, false, err
+	}
+	defer rows.Close()
+
+	index := 0
+	for rows.Next() {
+		var (
+			ordinal           int64
+			kind              string
+			headingLevel      sql.NullInt64
+			contentKey        string
+			contentOccurrence int64
+			chapterKey        sql.NullString
+			chapterOccurrence sql.NullInt64
+			markdown          string
+			renderedHTML      string
+			wordCount         int64
+			speaker           sql.NullString
+			contentHash       sql.NullString
+		)
+		if err := rows.Scan(
+			&ordinal,
+			&kind,
+			&headingLevel,
+			&contentKey,
+			&contentOccurrence,
+			&chapterKey,
+			&chapterOccurrence,
+			&markdown,
+			&renderedHTML,
+			&wordCount,
+			&speaker,
+			&contentHash,
+		); err != nil {
+			return false, false, err
+		}
+		if index >= len(fresh) {
+			return false, false, nil
+		}
+		segment := fresh[index]
+		index++
+		if ordinal != int64(segment.Ordinal) || kind != string(segment.Kind) ||
+			!nullableIntMatches(headingLevel, segment.HeadingLevel) ||
+			contentKey != segment.ContentKey || contentOccurrence != int64(segment.ContentOccurrence) ||
+			!nullableStringMatches(chapterKey, segment.ChapterKey) ||
+			!nullableIntMatches(chapterOccurrence, segment.ChapterOccurrence) ||
+			markdown != segment.Markdown {
+			return false, false, nil
+		}
+		if renderedHTML != segment.RenderedHTML || wordCount != int64(segment.WordCount) ||
+			!nullableStringMatches(speaker, optionalString(segment.Speaker)) ||
+			!nullableStringMatches(contentHash, optionalString(segment.Hash)) {
+			changed = true
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return false, false, err
+	}
+	return index == len(fresh), changed, nil
+}
+
+// swapRenderedHTML writes everything the renderer derives from a version's
+// Markdown. Identities and Markdown are untouched.
+func swapRenderedHTML(ctx context.Context, tx pgx.Tx, versionID string, canonical storyingest.Output) error {
+	readability, err := readabilityJSON(canonical.Readability)
+	if err != nil {
+		return err
+	}
+	vocabulary, err := vocabularyJSON(canonical.Vocabulary)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `
+		UPDATE story_versions
+		SET rendered_html = $2,
+		    readability = $3::jsonb,
+		    vocabulary = $4::jsonb
+		WHERE id = $1
+	`, versionID, canonical.RenderedHTML, string(readability), string(vocabulary)); err != nil {
+		return err
+	}
+
+	count := len(canonical.Segments)
+	ordinals := make([]int, 0, count)
+	html := make([]string, 0, count)
+	words := make([]int, 0, count)
+	speakers := make([]*string, 0, count)
+	hashes := make([]*string, 0, count)
+	hints := make([]*string, 0, count)
+	for _, segment := range canonical.Segments {
+		encoded, err := pronunciationsJSON(segment.Pronunciations)
+		if err != nil {
+			return err
+		}
+		ordinals = append(ordinals, segment.Ordinal)
+		html = append(html, segment.RenderedHTML)
+		words = append(words, segment.WordCount)
+		speakers = append(speakers, optionalString(segment.Speaker))
+		hashes = append(hashes, optionalString(segment.Hash))
+		hints = append(hints, encoded)
+	}
+	_, err = tx.Exec(ctx, `
+		UPDATE story_segments AS segment
+		SET rendered_html = fresh.rendered_html,
+		    word_count = fresh.word_count,
+		    speaker = fresh.speaker,
+		    content_hash = fresh.content_hash,
+		    pronunciations = fresh.pronunciations::jsonb
+		FROM unnest($2::int[], $3::text[], $4::int[], $5::text[], $6::text[], $7::text[])
+		  AS fresh(ordinal, rendered_html, word_count, speaker, content_hash, pronunciations)
+		WHERE segment.story_version_id = $1
+		  AND segment.ordinal = fresh.ordinal
+	`, versionID, ordinals, html, words, speakers, hashes, hints)
+	return err
+}
+
+func scanRenderJob(row pgx.Row) (model.RenderJob, error) {
+	var (
+		job        model.RenderJob
+		status     string
+		createdAt  time.Time
+		updatedAt  time.Time
+		finishedAt sql.NullTime
+	)
+	if err := row.Scan(
+		&job.ID,
+		&job.AccountID,
+		&status,
+		&job.TotalVersions,
+		&job.RenderedVersions,
+		&job.UnchangedVersions,
+		&job.SkippedVersions,
+		&job.CursorVersionID,
+		&job.Error,
+		&createdAt,
+		&updatedAt,
+		&finishedAt,
+	); err != nil {
+		return model.RenderJob{}, err
+	}
+	job.Status = model.RenderJobStatus(status)
+	job.CreatedAt = createdAt.UTC().Format(time.RFC3339Nano)
+	job.UpdatedAt = updatedAt.UTC().Format(time.RFC3339Nano)
+	if finishedAt.Valid {
+		formatted := finishedAt.Time.UTC().Format(time.RFC3339Nano)
+		job.FinishedAt = &formatted
+	}
+	return job, nil
+}
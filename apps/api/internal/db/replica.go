@@ -0,0 +1,134 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"pandapages/api/internal/model"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	// defaultMaxReplicaLag bounds how far behind the primary a Reader read
+	// may be. Progress saved on one device can take this long to show on
+	// another, so it stays well under the time it takes to switch devices.
+	defaultMaxReplicaLag = 2 * time.Second
+	replicaCheckInterval = time.Second
+	replicaCheckTimeout  = time.Second
+)
+
+// replicaLagQuery measures replay lag. A standby that has replayed all it
+// has received is current however old its last transaction; a server that
+// is not a standby at all is always current. NULL means no replay yet.
+const replicaLagQuery = `
+	SELECT CASE
+		WHEN NOT pg_is_in_recovery() THEN 0
+		WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+		ELSE EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp())
+	END::float8
+`
+
+// replica is a read-only standby. A monitor measures its lag every second;
+// reads use it only while the last measurement succeeded within maxLag.
+type replica struct {
+	pool   *pgxpool.Pool
+	maxLag time.Duration
+
+	usable atomic.Bool
+	lag    atomic.Int64 // of the last successful measurement
+
+	stop context.CancelFunc
+	done chan struct{}
+}
+
+// openReplica never fails on an unreachable standby: reads stay on the
+// primary until the monitor first finds it current.
+func openReplica(cfg *pgxpool.Config, maxLag time.Duration) *replica {
+	if maxLag <= 0 {
+		maxLag = defaultMaxReplicaLag
+	}
+	pool, err := pgxpool.NewWithConfig(context.Background(), cfg)
+	if err != nil {
+		panic(err)
+	}
+	ctx, stop := context.WithCancel(context.Background())
+	r := &replica{pool: pool, maxLag: maxLag, stop: stop, done: make(chan struct{})}
+	r.check(ctx)
+	go r.monitor(ctx)
+	return r
+}
+
+func (r *replica) monitor(ctx context.Context) {
+	defer close(r.done)
+	ticker := time.NewTicker(replicaCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.check(ctx)
+		}
+	}
+}
+
+func (r *replica) check(parent context.Context) {
+	ctx, cancel := context.WithTimeout(context.WithValue(parent, queryLabelKey{}, "ReplicaLag"), replicaCheckTimeout)
+	defer cancel()
+	var seconds sql.NullFloat64
+	err := r.pool.QueryRow(ctx, replicaLagQuery).Scan(&seconds)
+	if parent.Err() != nil {
+		return // closing
+	}
+	r.record(time.Duration(seconds.Float64*float64(time.Second)), err == nil && seconds.Valid)
+}
+
+// record keeps one measurement and logs when reads move between servers.
+func (r *replica) record(lag time.Duration, measured bool) {
+	if measured {
+		r.lag.Store(int64(lag))
+	}
+	usable := measured && lag <= r.maxLag
+	if r.usable.Swap(usable) == usable {
+		return
+	}
+	if usable {
+		slog.Info("reading from replica", "lag_ms", lag.Milliseconds())
+	} else {
+		slog.Warn("replica unavailable or lagging; reading from primary", "measured", measured, "lag_ms", lag.Milliseconds(), "max_lag_ms", r.maxLag.Milliseconds())
+	}
+}
+
+func (r *replica) close() {
+	if r == nil {
+		return
+	}
+	r.stop()
+	<-r.done
+	r.pool.Close()
+}
+
+func (r *replica) stats() *model.ReplicaStats {
+	if r == nil {
+		return nil
+	}
+	return &model.ReplicaStats{
+		Pool:     poolStats(r.pool),
+		InUse:    r.usable.Load(),
+		LagMs:    time.Duration(r.lag.Load()).Milliseconds(),
+		MaxLagMs: r.maxLag.Milliseconds(),
+	}
+}
+
+// reads is the pool for a read-only Reader query that tolerates the
+// replica's bounded lag. Writes, and reads that must see them, use s.db.
+func (s *Store) reads() *pgxpool.Pool {
+	if s.replica != nil && s.replica.usable.Load() {
+		return s.replica.pool
+	}
+	return s.db
+}
@@ -0,0 +1,55 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func unconnectedPool(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+	// A pool with no minimum connections does not dial until first use.
+	pool, err := pgxpool.New(t.Context(), "postgres://reader@127.0.0.1:1/pandapages")
+	if err != nil {
+		t.Fatalf("pgxpool.New: %v", err)
+	}
+	t.Cleanup(pool.Close)
+	return pool
+}
+
+func TestReadsUseTheReplicaOnlyWhileItIsCurrent(t *testing.T) {
+	primary, standby := unconnectedPool(t), unconnectedPool(t)
+	store := &Store{db: primary}
+	if store.reads() != primary || store.DatabaseStats().Replica != nil {
+		t.Fatal("a Store without a replica did not read from the primary")
+	}
+
+	store.replica = &replica{pool: standby, maxLag: 2 * time.Second}
+	store.readerCache = newReaderCache(-1)
+	store.defaultProfileByAccount = map[string]string{}
+	if store.reads() != primary {
+		t.Fatal("reads used a replica that has not been measured")
+	}
+
+	for _, step := range []struct {
+		lag      time.Duration
+		measured bool
+		want     *pgxpool.Pool
+	}{
+		{lag: 300 * time.Millisecond, measured: true, want: standby},
+		{lag: 3 * time.Second, measured: true, want: primary},
+		{lag: 2 * time.Second, measured: true, want: standby},
+		{measured: false, want: primary},
+	} {
+		store.replica.record(step.lag, step.measured)
+		if store.reads() != step.want {
+			t.Fatalf("after lag %v measured %v, reads used the wrong pool", step.lag, step.measured)
+		}
+	}
+
+	stats := store.DatabaseStats().Replica
+	if stats == nil || stats.InUse || stats.LagMs != 2000 || stats.MaxLagMs != 2000 {
+		t.Fatalf("replica stats = %#v", stats)
+	}
+}
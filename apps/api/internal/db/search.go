@@ -0,0 +1,122 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"pandapages/api/internal/model"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// EmbeddingPendingVersions returns published versions, across accounts, with
+// no vector from modelName, most recently published stories first.
+func (s *Store) EmbeddingPendingVersions(ctx context.Context, modelName string, limit int) ([]model.EmbeddingSource, error) {
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	rows, err := s.db.Query(ctx, `
+		SELECT version.id, st.account_id, st.title, version.markdown
+		FROM stories st
+		JOIN story_versions AS version
+		  ON version.id = st.published_version_id
+		 AND version.story_id = st.id
+		LEFT JOIN story_version_embeddings AS embedding
+		  ON embedding.story_version_id = version.id
+		 AND embedding.model = $1
+		WHERE st.is_published = true
+		  AND embedding.story_version_id IS NULL
+		ORDER BY st.updated_at DESC, version.id ASC
+		LIMIT $2
+	`, modelName, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []model.EmbeddingSource{}
+	for rows.Next() {
+		var item model.EmbeddingSource
+		if err := rows.Scan(&item.VersionID, &item.AccountID, &item.Title, &item.Markdown); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// EmbeddingSave stores a version's vector, replacing one from an earlier
+// model.
+func (s *Store) EmbeddingSave(ctx context.Context, source model.EmbeddingSource, modelName string, vector []float32) error {
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	return saveEmbedding(ctx, s.db, source, modelName, vector)
+}
+
+type embeddingWriter interface {
+	Exec(context.Context, string, ...any) (pgconn.CommandTag, error)
+}
+
+func saveEmbedding(ctx context.Context, db embeddingWriter, source model.EmbeddingSource, modelName string, vector []float32) error {
+	_, err := db.Exec(ctx, `
+		INSERT INTO story_version_embeddings (story_version_id, account_id, model, embedding)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (story_version_id) DO UPDATE
+		SET model = EXCLUDED.model,
+		    embedding = EXCLUDED.embedding,
+		    created_at = now()
+	`, source.VersionID, source.AccountID, modelName, vector)
+	return err
+}
+
+// SemanticSearch ranks the account's published stories by the cosine
+// similarity of their published version's vector to query, which, like the
+// stored vectors, has unit length. Stories not yet embedded are left out.
+func (s *Store) SemanticSearch(ctx context.Context, accountID, modelName string, query []float32, limit int) (model.SemanticSearchResponse, error) {
+	accountID = strings.TrimSpace(accountID)
+	if !accountIDRe.MatchString(accountID) {
+		return model.SemanticSearchResponse{}, fmt.Errorf("account required")
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	rows, err := s.reads().Query(ctx, `
+		SELECT st.slug, st.title, NULLIF(BTRIM(st.author), ''), st.language, ranked.score
+		FROM stories st
+		JOIN story_version_embeddings AS embedding
+		  ON embedding.story_version_id = st.published_version_id
+		 AND embedding.model = $2
+		 AND cardinality(embedding.embedding) = cardinality($3::real[])
+		CROSS JOIN LATERAL (
+			SELECT sum(pair.stored * pair.asked)::float8 AS score
+			FROM unnest(embedding.embedding, $3::real[]) AS pair(stored, asked)
+		) AS ranked
+		WHERE st.account_id = $1
+		  AND st.is_published = true
+		ORDER BY ranked.score DESC, st.slug ASC
+		LIMIT $4
+	`, accountID, modelName, query, limit)
+	if err != nil {
+		return model.SemanticSearchResponse{}, err
+	}
+	defer rows.Close()
+	items := []model.SemanticSearchResult{}
+	for rows.Next() {
+		var (
+			item   model.SemanticSearchResult
+			author sql.NullString
+		)
+		if err := rows.Scan(&item.Slug, &item.Title, &author, &item.Language, &item.Score); err != nil {
+			return model.SemanticSearchResponse{}, err
+		}
+		item.Author = strPtr(author)
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return model.SemanticSearchResponse{}, err
+	}
+	return model.SemanticSearchResponse{Items: items}, nil
+}
@@ -0,0 +1,209 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"pandapages/api/internal/model"
+	"pandapages/api/internal/storyingest"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// searchReindexJobColumns reads a job aliased as job.
+const searchReindexJobColumns = `
+	job.id, job.account_id, COALESCE(job.slug, ''), job.status,
+	job.total_versions, job.indexed_versions, COALESCE(job.cursor_version_id::text, ''),
+	job.error, job.created_at, job.updated_at, job.finished_at
+`
+
+var searchReindexJobs = leasedJobs[model.SearchReindexJob]{
+	table:    "search_reindex_jobs",
+	columns:  searchReindexJobColumns,
+	done:     "indexed_versions",
+	cursor:   "cursor_version_id",
+	scan:     scanSearchReindexJob,
+	notFound: model.ErrSearchReindexJobNotFound,
+}
+
+// AdminStartSearchReindex queues embedding the published versions of the
+// account's stories again, or only of slug when it is not empty. Only one
+// job may be queued or running per account.
+func (s *Store) AdminStartSearchReindex(ctx context.Context, accountID, slug string) (model.SearchReindexJob, error) {
+	accountID = strings.TrimSpace(accountID)
+	if !accountIDRe.MatchString(accountID) {
+		return model.SearchReindexJob{}, fmt.Errorf("account required")
+	}
+	var only *string
+	if slug != "" {
+		if storyingest.ValidateSlug(slug) != nil {
+			return model.SearchReindexJob{}, fmt.Errorf("%w", model.ErrAdminStoryNotFound)
+		}
+		only = &slug
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	job, err := scanSearchReindexJob(s.writes(ctx).QueryRow(ctx, `
+		WITH job AS (
+			INSERT INTO search_reindex_jobs (account_id, slug, total_versions)
+			SELECT $1, $2, count(*)
+			FROM stories AS story
+			WHERE story.account_id = $1
+			  AND story.is_published = true
+			  AND story.published_version_id IS NOT NULL
+			  AND ($2::text IS NULL OR story.slug = $2)
+			HAVING $2::text IS NULL OR count(*) > 0
+			RETURNING *
+		)
+		SELECT `+searchReindexJobColumns+`
+		FROM job`, accountID, only))
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.SearchReindexJob{}, fmt.Errorf("%w", model.ErrAdminStoryNotFound)
+	}
+	if isUniqueViolation(err) {
+		return model.SearchReindexJob{}, model.ErrSearchReindexJobActive
+	}
+	return job, err
+}
+
+func (s *Store) AdminGetSearchReindexJob(ctx context.Context, accountID, jobID string) (model.SearchReindexJob, error) {
+	accountID = strings.TrimSpace(accountID)
+	if !accountIDRe.MatchString(accountID) {
+		return model.SearchReindexJob{}, fmt.Errorf("account required")
+	}
+	if !accountIDRe.MatchString(jobID) {
+		return model.SearchReindexJob{}, model.ErrSearchReindexJobNotFound
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	job, err := scanSearchReindexJob(s.db.QueryRow(ctx, `
+		SELECT `+searchReindexJobColumns+`
+		FROM search_reindex_jobs AS job
+		WHERE job.id = $1
+		  AND job.account_id = $2
+	`, jobID, accountID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.SearchReindexJob{}, model.ErrSearchReindexJobNotFound
+	}
+	return job, err
+}
+
+// SearchReindexClaimJob leases the oldest queued job, or a running one whose
+// worker stopped renewing its lease. ok is false when there is nothing to do.
+func (s *Store) SearchReindexClaimJob(ctx context.Context, lease time.Duration) (model.SearchReindexJob, bool, error) {
+	return searchReindexJobs.claim(ctx, s, lease)
+}
+
+// SearchReindexPendingVersions lists, in ID order, up to limit published
+// versions after the job's cursor. A story unpublished since the job was
+// queued is left out.
+func (s *Store) SearchReindexPendingVersions(ctx context.Context, job model.SearchReindexJob, limit int) ([]model.EmbeddingSource, error) {
+	var only, cursor *string
+	if job.Slug != "" {
+		only = &job.Slug
+	}
+	if job.CursorVersionID != "" {
+		cursor = &job.CursorVersionID
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	rows, err := s.db.Query(ctx, `
+		SELECT version.id, st.account_id, st.title, version.markdown
+		FROM stories st
+		JOIN story_versions AS version
+		  ON version.id = st.published_version_id
+		 AND version.story_id = st.id
+		WHERE st.account_id = $1
+		  AND st.is_published = true
+		  AND ($2::text IS NULL OR st.slug = $2)
+		  AND ($3::uuid IS NULL OR version.id > $3::uuid)
+		ORDER BY version.id ASC
+		LIMIT $4
+	`, job.AccountID, only, cursor, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []model.EmbeddingSource{}
+	for rows.Next() {
+		var item model.EmbeddingSource
+		if err := rows.Scan(&item.VersionID, &item.AccountID, &item.Title, &item.Markdown); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// SearchReindexRecordVersion replaces one version's vector, then moves the
+// job's cursor past it and renews the lease.
+func (s *Store) SearchReindexRecordVersion(ctx context.Context, job model.SearchReindexJob, source model.EmbeddingSource, modelName string, vector []float32, lease time.Duration) (model.SearchReindexJob, error) {
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return model.SearchReindexJob{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if err := saveEmbedding(ctx, tx, source, modelName, vector); err != nil {
+		return model.SearchReindexJob{}, err
+	}
+	updated, err := searchReindexJobs.advance(ctx, tx, job.ID, 1, source.VersionID, lease)
+	if err != nil {
+		return model.SearchReindexJob{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return model.SearchReindexJob{}, err
+	}
+	return updated, nil
+}
+
+// SearchReindexFinishJob completes a job, or fails it with failure.
+func (s *Store) SearchReindexFinishJob(ctx context.Context, job model.SearchReindexJob, failure *string) (model.SearchReindexJob, error) {
+	return searchReindexJobs.finish(ctx, s, job.ID, failure, nil)
+}
+
+func scanSearchReindexJob(row pgx.Row) (model.SearchReindexJob, error) {
+	var (
+		job        model.SearchReindexJob
+		status     string
+		createdAt  time.Time
+		updatedAt  time.Time
+		finishedAt sql.NullTime
+	)
+	if err := row.Scan(
+		&job.ID,
+		&job.AccountID,
+		&job.Slug,
+		&status,
+		&job.TotalVersions,
+		&job.IndexedVersions,
+		&job.CursorVersionID,
+		&job.Error,
+		&createdAt,
+		&updatedAt,
+		&finishedAt,
+	); err != nil {
+		return model.SearchReindexJob{}, err
+	}
+	job.Status = model.RenderJobStatus(status)
+	job.CreatedAt = createdAt.UTC().Format(time.RFC3339Nano)
+	job.UpdatedAt = updatedAt.UTC().Format(time.RFC3339Nano)
+	if finishedAt.Valid {
+		formatted := finishedAt.Time.UTC().Format(time.RFC3339Nano)
+		job.FinishedAt = &formatted
+	}
+	return job, nil
+}
@@ -0,0 +1,64 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"pandapages/api/internal/storyingest"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// segmentColumns are the story_segments columns a new segment fills.
+var segmentColumns = []string{
+	"story_version_id", "section_id", "ordinal",
+	"segment_kind", "heading_level", "content_key", "content_occurrence",
+	"chapter_key", "chapter_occurrence",
+	"markdown", "rendered_html", "word_count", "speaker", "content_hash", "pronunciations",
+}
+
+// freshSegments collects the new segment rows of one version, written with a
+// single COPY however long the book is.
+type freshSegments struct {
+	versionID string
+	rows      [][]any
+}
+
+func (f *freshSegments) add(section any, seg storyingest.Segment) error {
+	hints, err := pronunciationsJSON(seg.Pronunciations)
+	if err != nil {
+		return err
+	}
+	f.rows = append(f.rows, []any{
+		f.versionID,
+		section,
+		seg.Ordinal,
+		string(seg.Kind),
+		seg.HeadingLevel,
+		seg.ContentKey,
+		seg.ContentOccurrence,
+		seg.ChapterKey,
+		seg.ChapterOccurrence,
+		seg.Markdown,
+		seg.RenderedHTML,
+		seg.WordCount,
+		optionalString(seg.Speaker),
+		optionalString(seg.Hash),
+		hints,
+	})
+	return nil
+}
+
+func (f *freshSegments) copyTo(ctx context.Context, tx pgx.Tx) error {
+	if len(f.rows) == 0 {
+		return nil
+	}
+	copied, err := tx.CopyFrom(ctx, pgx.Identifier{"story_segments"}, segmentColumns, pgx.CopyFromRows(f.rows))
+	if err != nil {
+		return err
+	}
+	if copied != int64(len(f.rows)) {
+		return fmt.Errorf("wrote %d of %d segments", copied, len(f.rows))
+	}
+	return nil
+}
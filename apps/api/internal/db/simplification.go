@@ -0,0 +1,91 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"pandapages/api/internal/model"
+	"pandapages/api/internal/storyingest"
+)
+
+// AdminCheckSimplificationSlug reports whether slug may take a
+// simplification of originalSlug: it must be free, or already hold a
+// simplification of the same original, which then gains a new draft. A
+// missing original is ErrAdminStoryNotFound; any other story at slug is
+// ErrSimplificationSlugTaken.
+func (s *Store) AdminCheckSimplificationSlug(ctx context.Context, accountID, originalSlug, slug string) error {
+	accountID = strings.TrimSpace(accountID)
+	if !accountIDRe.MatchString(accountID) {
+		return fmt.Errorf("account required")
+	}
+	if storyingest.ValidateSlug(originalSlug) != nil {
+		return fmt.Errorf("%w", model.ErrAdminStoryNotFound)
+	}
+	if storyingest.ValidateSlug(slug) != nil || slug == originalSlug {
+		return fmt.Errorf("%w", model.ErrSimplificationSlugTaken)
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	// The primary is read because the answer guards a write that follows.
+	var free bool
+	err := s.db.QueryRow(ctx, `
+		SELECT target.id IS NULL OR target.simplified_from IS NOT DISTINCT FROM original.id
+		FROM stories AS original
+		LEFT JOIN stories AS target
+		  ON target.account_id = original.account_id
+		 AND target.slug = $3
+		WHERE original.account_id = $1
+		  AND original.slug = $2
+	`, accountID, originalSlug, slug).Scan(&free)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w", model.ErrAdminStoryNotFound)
+	}
+	if err != nil {
+		return err
+	}
+	if !free {
+		return fmt.Errorf("%w", model.ErrSimplificationSlugTaken)
+	}
+	return nil
+}
+
+// AdminLinkSimplification marks the story at slug as originalSlug rewritten
+// for a reader of age. Unlike translations, simplifications link to the
+// story they were made from, even when that is itself a simplification.
+// Linking leaves updated_at alone.
+func (s *Store) AdminLinkSimplification(ctx context.Context, accountID, slug, originalSlug string, age int) error {
+	accountID = strings.TrimSpace(accountID)
+	if !accountIDRe.MatchString(accountID) {
+		return fmt.Errorf("account required")
+	}
+	if storyingest.ValidateSlug(slug) != nil || storyingest.ValidateSlug(originalSlug) != nil {
+		return fmt.Errorf("%w", model.ErrAdminStoryNotFound)
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	tag, err := s.writes(ctx).Exec(ctx, `
+		UPDATE stories AS story
+		SET simplified_from = original.id,
+		    simplified_for_age = $4
+		FROM stories AS original
+		WHERE original.account_id = $1
+		  AND original.slug = $3
+		  AND story.account_id = $1
+		  AND story.slug = $2
+		  AND story.id <> original.id
+	`, accountID, slug, originalSlug, age)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%w", model.ErrAdminStoryNotFound)
+	}
+	return nil
+}
@@ -4,94 +4,302 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math"
 	"regexp"
+	"slices"
 	"strings"
 	"sync"
 	"time"
 	"unicode/utf8"
 
+	"pandapages/api/internal/blob"
 	"pandapages/api/internal/model"
 	"pandapages/api/internal/readercontract"
+	"pandapages/api/internal/storyingest"
+	"pandapages/api/internal/webhooks"
 
-	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"golang.org/x/sync/singleflight"
 )
 
 type Store struct {
-	db           *sql.DB
+	db           *pgxpool.Pool
+	replica      *replica
 	queryTimeout time.Duration
+	queries      *queryTracer
 
 	mu sync.Mutex
 
 	// cached "Default" profile per account
 	defaultProfileByAccount map[string]string
+	profileHits             int64
+	profileMisses           int64
+
+	// readerCache keeps validated published versions for ReaderStory, and
+	// readerFlights merges concurrent reads of one story.
+	readerCache   *readerCache
+	readerFlights singleflight.Group
+	invalidations *invalidationListener
+
+	// blobs, when set, keeps new images and recordings outside the database.
+	blobs blob.Store
 }
 
 type Options struct {
-	ConnMaxLifetime time.Duration
-	MaxOpenConns    int
-	MaxIdleConns    int
+	MaxConnLifetime time.Duration
+	MaxConnIdleTime time.Duration
+	MaxConns        int32
+	MinConns        int32
 	QueryTimeout    time.Duration
+
+	// ReaderCacheBytes bounds the published versions ReaderStory keeps in
+	// memory; zero uses the default and a negative value disables the cache.
+	ReaderCacheBytes int
+
+	// SlowQueryThreshold logs statements that take at least this long, with
+	// the Store method that ran them. Zero logs none.
+	SlowQueryThreshold time.Duration
+
+	// ReplicaURL names an optional read-only standby for the Reader's
+	// read-only queries. They fall back to the primary while the standby is
+	// unreachable or more than MaxReplicaLag behind; zero uses the default.
+	ReplicaURL    string
+	MaxReplicaLag time.Duration
+
+	// StatementCacheSize bounds the prepared statements each connection
+	// keeps. Every query is prepared on first use and then executed in one
+	// round trip. Zero uses the default; a negative value never prepares,
+	// for poolers such as PgBouncer in transaction mode.
+	StatementCacheSize int
+
+	// ConnectRetry is how long Open keeps retrying a database that is not
+	// accepting connections yet, with exponential backoff. Zero tries once.
+	ConnectRetry time.Duration
+
+	// Blobs keeps the bytes of new images and recordings; nil keeps them in
+	// the database. Rows keep whichever they were written with, so turning
+	// it off strands those stored while it was on.
+	Blobs blob.Store
+}
+
+// defaultStatementCacheSize is twice pgx's own default. The Store has some
+// 240 static queries and builds others per call, so a cache sized to the
+// static ones alone would evict hot statements under mixed load.
+const defaultStatementCacheSize = 1024
+
+// DefaultOptions returns the pool settings MustOpen uses.
+func DefaultOptions() Options {
+	return Options{
+		MaxConnLifetime:    30 * time.Minute,
+		MaxConnIdleTime:    5 * time.Minute,
+		MaxConns:           10,
+		MinConns:           2,
+		QueryTimeout:       3 * time.Second,
+		SlowQueryThreshold: 250 * time.Millisecond,
+		ConnectRetry:       30 * time.Second,
+	}
+}
+
+// ValidateURL reports whether url is a connection string pgx accepts, without
+// connecting. Errors never repeat a password.
+func ValidateURL(url string) error {
+	_, err := pgxpool.ParseConfig(url)
+	return err
 }
 
 func MustOpen(url string) *Store {
-	return MustOpenWithOptions(url, Options{
-		ConnMaxLifetime: 30 * time.Minute,
-		MaxOpenConns:    10,
-		MaxIdleConns:    5,
-		QueryTimeout:    3 * time.Second,
-	})
+	return MustOpenWithOptions(url, DefaultOptions())
 }
 
 func MustOpenWithOptions(url string, opt Options) *Store {
-	if strings.TrimSpace(url) == "" {
-		panic("DATABASE_URL is required")
-	}
-
-	db, err := sql.Open("pgx", url)
+	store, err := Open(context.Background(), url, opt)
 	if err != nil {
 		panic(err)
 	}
+	return store
+}
 
-	// pool tuning
-	if opt.ConnMaxLifetime > 0 {
-		db.SetConnMaxLifetime(opt.ConnMaxLifetime)
+// Open connects to the database at url, waiting up to opt.ConnectRetry for
+// it to accept connections. Cancelling ctx stops the wait.
+func Open(ctx context.Context, url string, opt Options) (*Store, error) {
+	if strings.TrimSpace(url) == "" {
+		return nil, errors.New("DATABASE_URL is required")
 	}
-	if opt.MaxOpenConns > 0 {
-		db.SetMaxOpenConns(opt.MaxOpenConns)
+	if err := ValidateURL(url); err != nil {
+		return nil, err
 	}
-	if opt.MaxIdleConns > 0 {
-		db.SetMaxIdleConns(opt.MaxIdleConns)
+	if strings.TrimSpace(opt.ReplicaURL) != "" {
+		if err := ValidateURL(opt.ReplicaURL); err != nil {
+			return nil, fmt.Errorf("replica: %w", err)
+		}
 	}
 
+	queries := newQueryTracer(opt.SlowQueryThreshold)
+	cfg := poolConfig(url, opt, queries)
+
 	qt := opt.QueryTimeout
 	if qt <= 0 {
 		qt = 3 * time.Second
 	}
 
-	// ping with timeout to avoid hanging startup
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
-	defer cancel()
-	if err := db.PingContext(ctx); err != nil {
-		panic(err)
+	db, err := connect(ctx, cfg, opt)
+	if err != nil {
+		return nil, err
+	}
+
+	cacheBytes := opt.ReaderCacheBytes
+	if cacheBytes == 0 {
+		cacheBytes = defaultReaderCacheBytes
+	}
+
+	var standby *replica
+	if strings.TrimSpace(opt.ReplicaURL) != "" {
+		standby = openReplica(poolConfig(opt.ReplicaURL, opt, queries), opt.MaxReplicaLag)
 	}
 
-	return &Store{
+	store := &Store{
 		db:                      db,
+		replica:                 standby,
 		queryTimeout:            qt,
+		queries:                 queries,
 		defaultProfileByAccount: map[string]string{},
+		readerCache:             newReaderCache(cacheBytes),
+		blobs:                   opt.Blobs,
 	}
+	// Other instances publish and re-render too; without a cache there is
+	// nothing for their invalidations to evict.
+	if store.readerCache != nil {
+		store.invalidations = listenForInvalidations(cfg.ConnConfig, store.readerCache)
+	}
+	return store, nil
+}
+
+// poolConfig applies opt to the pool for url, panicking on a malformed URL
+// like MustOpen.
+func poolConfig(url string, opt Options, tracer pgx.QueryTracer) *pgxpool.Config {
+	cfg, err := pgxpool.ParseConfig(url)
+	if err != nil {
+		panic(err)
+	}
+
+	// pool tuning
+	if opt.MaxConnLifetime > 0 {
+		cfg.MaxConnLifetime = opt.MaxConnLifetime
+	}
+	if opt.MaxConnIdleTime > 0 {
+		cfg.MaxConnIdleTime = opt.MaxConnIdleTime
+	}
+	if opt.MaxConns > 0 {
+		cfg.MaxConns = opt.MaxConns
+	}
+	if opt.MinConns > 0 {
+		cfg.MinConns = opt.MinConns
+	}
+	configureStatementCache(cfg.ConnConfig, opt.StatementCacheSize)
+	cfg.ConnConfig.Tracer = tracer
+	return cfg
 }
 
-func (s *Store) Close() error { return s.db.Close() }
+// configureStatementCache makes statement reuse explicit rather than relying
+// on pgx defaults. Without preparation, queries still take one round trip but
+// PostgreSQL parses and plans them every time.
+func configureStatementCache(cfg *pgx.ConnConfig, size int) {
+	switch {
+	case size < 0:
+		cfg.DefaultQueryExecMode = pgx.QueryExecModeExec
+		cfg.StatementCacheCapacity = 0
+	case size == 0:
+		cfg.DefaultQueryExecMode = pgx.QueryExecModeCacheStatement
+		cfg.StatementCacheCapacity = defaultStatementCacheSize
+	default:
+		cfg.DefaultQueryExecMode = pgx.QueryExecModeCacheStatement
+		cfg.StatementCacheCapacity = size
+	}
+}
 
-func (s *Store) ctx() (context.Context, context.CancelFunc) {
+func (s *Store) Close() error {
+	s.invalidations.close()
+	s.replica.close()
+	s.db.Close()
+	return nil
+}
+
+// PoolStats reports the connection pool's current state and its acquire
+// counters since startup.
+func (s *Store) PoolStats() model.DatabasePoolStats {
+	return poolStats(s.db)
+}
+
+func poolStats(pool *pgxpool.Pool) model.DatabasePoolStats {
+	stat := pool.Stat()
+	return model.DatabasePoolStats{
+		MaxConns:             stat.MaxConns(),
+		TotalConns:           stat.TotalConns(),
+		AcquiredConns:        stat.AcquiredConns(),
+		IdleConns:            stat.IdleConns(),
+		ConstructingConns:    stat.ConstructingConns(),
+		AcquireCount:         stat.AcquireCount(),
+		EmptyAcquireCount:    stat.EmptyAcquireCount(),
+		CanceledAcquireCount: stat.CanceledAcquireCount(),
+		AcquireDurationMs:    stat.AcquireDuration().Milliseconds(),
+
+		EmptyAcquireWaitMs:      stat.EmptyAcquireWaitTime().Milliseconds(),
+		NewConnsCount:           stat.NewConnsCount(),
+		MaxLifetimeDestroyCount: stat.MaxLifetimeDestroyCount(),
+		MaxIdleDestroyCount:     stat.MaxIdleDestroyCount(),
+	}
+}
+
+// DatabaseStats reports the connection pool alongside the hit rates of the
+// Store's own caches, which between them decide how many connections a
+// deployment needs.
+func (s *Store) DatabaseStats() model.DatabaseStats {
+	var out model.DatabaseStats
+	out.Pool = s.PoolStats()
+	out.Replica = s.replica.stats()
+	out.Caches.Reader = s.readerCache.stats()
+	s.mu.Lock()
+	out.Caches.DefaultProfile = cacheStats(s.profileHits, s.profileMisses)
+	out.Caches.DefaultProfile.Entries = len(s.defaultProfileByAccount)
+	s.mu.Unlock()
+	return out
+}
+
+// cacheStats derives the hit rate from a cache's lookup counters.
+func cacheStats(hits, misses int64) model.CacheStats {
+	out := model.CacheStats{Hits: hits, Misses: misses}
+	if lookups := hits + misses; lookups > 0 {
+		out.HitRate = float64(hits) / float64(lookups)
+	}
+	return out
+}
+
+// QueryStats reports the latency histogram of every Store method that has
+// queried the database since startup.
+func (s *Store) QueryStats() []model.QueryLatency {
+	if s.queries == nil {
+		return []model.QueryLatency{}
+	}
+	return s.queries.stats()
+}
+
+// ctx bounds one Store call by the query timeout as well as by parent, usually
+// the HTTP request's context, so a client that disconnects stops its queries.
+// The call is traced as a child span of parent's that ends with cancel.
+func (s *Store) ctx(parent context.Context) (context.Context, context.CancelFunc) {
 	qt := s.queryTimeout
 	if qt <= 0 {
 		qt = 3 * time.Second
 	}
-	return context.WithTimeout(context.Background(), qt)
+	ctx, span := startStoreSpan(withQueryLabel(parent, 1))
+	ctx, cancel := context.WithTimeout(ctx, qt)
+	return ctx, func() {
+		cancel()
+		span.End()
+	}
 }
 
 func strPtr(ns sql.NullString) *string {
@@ -127,23 +335,23 @@ var accountIDRe = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]
 // one when the table is empty. The transaction-level advisory lock coordinates
 // initialization across processes and replicas; correctness does not depend on
 // this Store's in-process mutex or a cached account id.
-func (s *Store) EnsureDefaultAccount() (string, error) {
-	ctx, cancel := s.ctx()
+func (s *Store) EnsureDefaultAccount(ctx context.Context) (string, error) {
+	ctx, cancel := s.ctx(ctx)
 	defer cancel()
 
-	tx, err := s.db.BeginTx(ctx, nil)
+	tx, err := s.db.Begin(ctx)
 	if err != nil {
 		return "", err
 	}
-	defer func() { _ = tx.Rollback() }()
+	defer func() { _ = tx.Rollback(ctx) }()
 
-	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, ensureDefaultAccountLockID); err != nil {
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock($1)`, ensureDefaultAccountLockID); err != nil {
 		return "", err
 	}
 
 	selectOldest := func() (string, error) {
 		var id string
-		err := tx.QueryRowContext(ctx, `
+		err := tx.QueryRow(ctx, `
 			SELECT id
 			FROM accounts
 			ORDER BY created_at ASC, id ASC
@@ -153,8 +361,8 @@ func (s *Store) EnsureDefaultAccount() (string, error) {
 	}
 
 	id, err := selectOldest()
-	if err == sql.ErrNoRows {
-		if _, err = tx.ExecContext(ctx, `
+	if errors.Is(err, sql.ErrNoRows) {
+		if _, err = tx.Exec(ctx, `
 				INSERT INTO accounts (name)
 				VALUES ('Default')
 			`); err != nil {
@@ -166,7 +374,7 @@ func (s *Store) EnsureDefaultAccount() (string, error) {
 		return "", err
 	}
 
-	if err := tx.Commit(); err != nil {
+	if err := tx.Commit(ctx); err != nil {
 		return "", err
 	}
 
@@ -176,17 +384,17 @@ func (s *Store) EnsureDefaultAccount() (string, error) {
 // AccountExists reports whether accountID identifies an existing account.
 // Malformed identifiers are treated as absent instead of being sent to
 // PostgreSQL as invalid UUID input.
-func (s *Store) AccountExists(accountID string) (bool, error) {
+func (s *Store) AccountExists(ctx context.Context, accountID string) (bool, error) {
 	accountID = strings.TrimSpace(accountID)
 	if !accountIDRe.MatchString(accountID) {
 		return false, nil
 	}
 
-	ctx, cancel := s.ctx()
+	ctx, cancel := s.ctx(ctx)
 	defer cancel()
 
 	var exists bool
-	err := s.db.QueryRowContext(ctx, `
+	err := s.db.QueryRow(ctx, `
 		SELECT EXISTS (
 			SELECT 1
 			FROM accounts
@@ -210,14 +418,16 @@ func (s *Store) getDefaultProfileID(ctx context.Context, accountID string) (stri
 		s.defaultProfileByAccount = map[string]string{}
 	}
 	if id := s.defaultProfileByAccount[accountID]; id != "" {
+		s.profileHits++
 		s.mu.Unlock()
 		return id, nil
 	}
+	s.profileMisses++
 	s.mu.Unlock()
 
 	// select oldest Default for this account
 	var id string
-	err := s.db.QueryRowContext(ctx, `
+	err := s.db.QueryRow(ctx, `
 		SELECT id
 		FROM profiles
 		WHERE account_id = $1 AND name = 'Default'
@@ -225,9 +435,9 @@ func (s *Store) getDefaultProfileID(ctx context.Context, accountID string) (stri
 		LIMIT 1
 	`, accountID).Scan(&id)
 
-	if err == sql.ErrNoRows {
+	if errors.Is(err, sql.ErrNoRows) {
 		// create one if none exist
-		_, err = s.db.ExecContext(ctx, `
+		_, err = s.db.Exec(ctx, `
 			INSERT INTO profiles (account_id, name)
 			SELECT $1, 'Default'
 			WHERE NOT EXISTS (
@@ -239,7 +449,7 @@ func (s *Store) getDefaultProfileID(ctx context.Context, accountID string) (stri
 		}
 
 		// reselect
-		err = s.db.QueryRowContext(ctx, `
+		err = s.db.QueryRow(ctx, `
 			SELECT id
 			FROM profiles
 			WHERE account_id = $1 AND name = 'Default'
@@ -337,15 +547,81 @@ func libraryVersionMetadata(
 	return title, author, language, nil
 }
 
-func (s *Store) Library(accountID string) (model.LibraryReadModel, error) {
-	ctx, cancel := s.ctx()
+// storedChapterRule returns the chapter rule recorded in a published
+// version's frontmatter.
+func storedChapterRule(frontmatterJSON []byte) (readercontract.ChapterRule, error) {
+	decoded, ok := decodeJSONDocument(frontmatterJSON)
+	if !ok {
+		return readercontract.ChapterRule{}, fmt.Errorf("frontmatter must be valid JSON")
+	}
+	values, ok := decoded.(map[string]any)
+	if !ok {
+		return readercontract.ChapterRule{}, fmt.Errorf("frontmatter must be an object")
+	}
+	return storyingest.ChapterRuleFromFrontmatter(values)
+}
+
+// libraryVariants decodes a Library story's language group. Variants come
+// from other stories' published frontmatter, so one that cannot be shown is
+// left out rather than hiding the story that lists it.
+func libraryVariants(originalSlug, variantsJSON sql.NullString) (*string, []model.StoryVariant) {
+	var translationOf *string
+	if originalSlug.Valid && validLibrarySlug(originalSlug.String) {
+		translationOf = &originalSlug.String
+	}
+	var decoded []model.StoryVariant
+	if !variantsJSON.Valid || json.Unmarshal([]byte(variantsJSON.String), &decoded) != nil {
+		return translationOf, nil
+	}
+	var variants []model.StoryVariant
+	for _, variant := range decoded {
+		variant.Language = strings.TrimSpace(variant.Language)
+		if validLibrarySlug(variant.Slug) && variant.Language != "" && utf8.ValidString(variant.Language) {
+			variants = append(variants, variant)
+		}
+	}
+	return translationOf, variants
+}
+
+const (
+	defaultLibraryPageSize = 50
+	maxLibraryPageSize     = 200
+)
+
+func (s *Store) Library(ctx context.Context, accountID string, page model.PageRequest, filter model.LibraryFilter) (model.LibraryReadModel, error) {
+	limit := pageSize(page.Limit, defaultLibraryPageSize, maxLibraryPageSize)
+	var (
+		afterUpdated, afterCreated time.Time
+		afterSlug                  string
+	)
+	after, err := decodeCursor(page.Cursor, &afterUpdated, &afterCreated, &afterSlug)
+	if err != nil {
+		return model.LibraryReadModel{}, err
+	}
+	cursorArgs := []any{nil, nil, nil}
+	if after {
+		cursorArgs = []any{afterUpdated, afterCreated, afterSlug}
+	}
+	// A cursor holds only the sort position, so it pages a filtered Library
+	// as well as the whole one.
+	var decodableSet *int
+	if filter.DecodableSet > 0 {
+		decodableSet = &filter.DecodableSet
+	}
+	var bedtimeMaxWords *int
+	if filter.BedtimeMaxWords > 0 {
+		bedtimeMaxWords = &filter.BedtimeMaxWords
+	}
+
+	ctx, cancel := s.ctx(ctx)
 	defer cancel()
 
 	// Segment rows are kept in this single statement so metadata, progress, and
 	// Reader 2 identities all come from one PostgreSQL snapshot. The ordered
 	// identities are validated by the shared Reader contract in Go rather than
-	// reimplementing its occurrence/chapter rules in SQL.
-	rows, err := s.db.QueryContext(ctx, `
+	// reimplementing its occurrence/chapter rules in SQL. Candidates are
+	// limited to one story past the page, which tells whether another follows.
+	rows, err := s.reads().Query(ctx, `
 		WITH candidates AS (
 			SELECT
 				story.id AS story_id,
@@ -355,13 +631,57 @@ func (s *Store) Library(accountID string) (model.LibraryReadModel, error) {
 				story.published_version_id AS requested_published_version_id,
 				version.id AS published_version_id,
 				version.version AS published_version,
-				version.frontmatter::text AS frontmatter
+				version.frontmatter::text AS frontmatter,
+				story.translation_of,
+				COALESCE(story.translation_of, story.id) AS group_id
 			FROM stories AS story
 			LEFT JOIN story_versions AS version
 			  ON version.id = story.published_version_id
 			 AND version.story_id = story.id
 			WHERE story.account_id = $1
 			  AND story.is_published = true
+			  AND (
+				$2::timestamptz IS NULL
+				OR story.updated_at < $2::timestamptz
+				OR (story.updated_at = $2::timestamptz AND (
+					story.created_at < $3::timestamptz
+					OR (story.created_at = $3::timestamptz AND story.slug > $4::text)
+				))
+			  )
+			  AND ($6::integer IS NULL OR EXISTS (
+				SELECT 1
+				FROM story_version_phonics AS phonics
+				WHERE phonics.story_version_id = story.published_version_id
+				  AND phonics.progression = $7
+				  AND phonics.decodable_set <= $6::integer
+			  ))
+			  AND ($8::integer IS NULL OR (
+				EXISTS (
+					SELECT 1
+					FROM story_tags AS link
+					JOIN tags AS tag
+					  ON tag.id = link.tag_id
+					 AND tag.account_id = $1
+					WHERE link.story_id = story.id
+					  AND lower(tag.name) = $9
+				)
+				AND NOT EXISTS (
+					SELECT 1
+					FROM story_tags AS link
+					JOIN tags AS tag
+					  ON tag.id = link.tag_id
+					 AND tag.account_id = $1
+					WHERE link.story_id = story.id
+					  AND lower(tag.name) = $10
+				)
+				AND (
+					SELECT COALESCE(sum(segment.word_count), 0)
+					FROM story_segments AS segment
+					WHERE segment.story_version_id = story.published_version_id
+				) <= $8::integer
+			  ))
+			ORDER BY story.updated_at DESC, story.created_at DESC, story.slug ASC, story.id ASC
+			LIMIT $5
 		), default_profile AS (
 			SELECT profile.id
 			FROM profiles AS profile
@@ -369,18 +689,34 @@ func (s *Store) Library(accountID string) (model.LibraryReadModel, error) {
 			  AND profile.name = 'Default'
 			ORDER BY profile.created_at ASC, profile.id ASC
 			LIMIT 1
+		), reaction_counts AS (
+			SELECT
+				reaction.story_id,
+				count(*) FILTER (WHERE reaction.reaction = 'loved_it') AS loved_it,
+				count(*) FILTER (WHERE reaction.reaction = 'silly') AS silly,
+				count(*) FILTER (WHERE reaction.reaction = 'scary') AS scary
+			FROM story_reactions AS reaction
+			WHERE reaction.story_id IN (SELECT story_id FROM candidates)
+			GROUP BY reaction.story_id
 		)
 		SELECT
 			candidates.story_id,
 			candidates.slug,
+			candidates.updated_at,
+			candidates.created_at,
 			candidates.frontmatter,
 			candidates.requested_published_version_id,
 			candidates.published_version_id,
 			candidates.published_version,
+			original.slug,
+			variants.variants,
 			progress.story_version_id,
 			progress_version.version,
 			progress.percent,
 			progress.updated_at,
+			reaction_counts.loved_it,
+			reaction_counts.silly,
+			reaction_counts.scary,
 			segment.id,
 			segment.ordinal,
 			segment.segment_kind,
@@ -391,6 +727,25 @@ func (s *Store) Library(accountID string) (model.LibraryReadModel, error) {
 			segment.chapter_occurrence,
 			segment.word_count
 		FROM candidates
+		LEFT JOIN stories AS original
+		  ON original.id = candidates.translation_of
+		 AND original.account_id = $1
+		 AND original.is_published = true
+		LEFT JOIN LATERAL (
+			SELECT jsonb_agg(
+				jsonb_build_object('slug', variant.slug, 'language', variant_version.frontmatter->>'language')
+				ORDER BY variant.slug
+			)::text AS variants
+			FROM stories AS variant
+			JOIN story_versions AS variant_version
+			  ON variant_version.id = variant.published_version_id
+			 AND variant_version.story_id = variant.id
+			WHERE variant.account_id = $1
+			  AND variant.is_published = true
+			  AND variant.id <> candidates.story_id
+			  AND (variant.id = candidates.group_id OR variant.translation_of = candidates.group_id)
+		) AS variants
+		  ON true
 		LEFT JOIN default_profile
 		  ON true
 		LEFT JOIN reading_progress AS progress
@@ -399,6 +754,8 @@ func (s *Store) Library(accountID string) (model.LibraryReadModel, error) {
 		LEFT JOIN story_versions AS progress_version
 		  ON progress_version.id = progress.story_version_id
 		 AND progress_version.story_id = candidates.story_id
+		LEFT JOIN reaction_counts
+		  ON reaction_counts.story_id = candidates.story_id
 		LEFT JOIN story_segments AS segment
 		  ON segment.story_version_id = candidates.published_version_id
 		ORDER BY
@@ -407,7 +764,8 @@ func (s *Store) Library(accountID string) (model.LibraryReadModel, error) {
 			candidates.slug ASC,
 			candidates.story_id ASC,
 			segment.ordinal ASC NULLS FIRST
-	`, accountID)
+	`, append([]any{accountID}, append(cursorArgs, limit+1, decodableSet, filter.Progression,
+		bedtimeMaxWords, model.BedtimeCalmTag, model.BedtimeExcitingTag)...)...)
 	if err != nil {
 		return model.LibraryReadModel{}, err
 	}
@@ -418,12 +776,18 @@ func (s *Store) Library(accountID string) (model.LibraryReadModel, error) {
 		storyID            string
 		publishedVersionID string
 		identities         []readercontract.StoredSegmentIdentity
+		chapters           readercontract.ChapterRule
 		wordCount          int64
 		invalid            bool
 	}
 
-	result := model.LibraryReadModel{Items: make([]model.StoryItem, 0, 16)}
-	var current *storyAccumulator
+	result := model.LibraryReadModel{Page: model.Page[model.StoryItem]{Items: make([]model.StoryItem, 0, 16)}}
+	var (
+		current    *storyAccumulator
+		stories    int
+		more       bool
+		lastSortBy []any
+	)
 	finalize := func() error {
 		if current == nil {
 			return nil
@@ -432,7 +796,7 @@ func (s *Store) Library(accountID string) (model.LibraryReadModel, error) {
 			current.invalid = true
 		}
 		if !current.invalid {
-			chapterCount, err := readercontract.ValidateStoredSegmentIdentities(current.identities)
+			chapterCount, err := readercontract.ValidateStoredSegmentIdentities(current.identities, current.chapters)
 			if err != nil {
 				current.invalid = true
 			} else {
@@ -455,14 +819,21 @@ func (s *Store) Library(accountID string) (model.LibraryReadModel, error) {
 		var (
 			storyID              string
 			slug                 string
+			storyUpdatedAt       time.Time
+			storyCreatedAt       time.Time
 			frontmatterJSON      sql.NullString
 			requestedVersionID   sql.NullString
 			publishedVersionID   sql.NullString
 			publishedVersion     sql.NullInt64
+			originalSlug         sql.NullString
+			variantsJSON         sql.NullString
 			progressVersionID    sql.NullString
 			progressVersion      sql.NullInt64
 			progressPercent      sql.NullFloat64
 			progressUpdatedAt    sql.NullTime
+			reactedLovedIt       sql.NullInt64
+			reactedSilly         sql.NullInt64
+			reactedScary         sql.NullInt64
 			segmentID            sql.NullString
 			segmentOrdinal       sql.NullInt64
 			segmentKind          sql.NullString
@@ -476,14 +847,21 @@ func (s *Store) Library(accountID string) (model.LibraryReadModel, error) {
 		if err := rows.Scan(
 			&storyID,
 			&slug,
+			&storyUpdatedAt,
+			&storyCreatedAt,
 			&frontmatterJSON,
 			&requestedVersionID,
 			&publishedVersionID,
 			&publishedVersion,
+			&originalSlug,
+			&variantsJSON,
 			&progressVersionID,
 			&progressVersion,
 			&progressPercent,
 			&progressUpdatedAt,
+			&reactedLovedIt,
+			&reactedSilly,
+			&reactedScary,
 			&segmentID,
 			&segmentOrdinal,
 			&segmentKind,
@@ -501,6 +879,13 @@ func (s *Store) Library(accountID string) (model.LibraryReadModel, error) {
 			if err := finalize(); err != nil {
 				return model.LibraryReadModel{}, err
 			}
+			current = nil
+			if stories == limit {
+				more = true
+				break
+			}
+			stories++
+			lastSortBy = []any{storyUpdatedAt, storyCreatedAt, slug}
 			current = &storyAccumulator{
 				item:       model.StoryItem{Slug: slug},
 				storyID:    storyID,
@@ -531,6 +916,12 @@ func (s *Store) Library(accountID string) (model.LibraryReadModel, error) {
 				current.item.Author = author
 				current.item.Language = language
 			}
+			if chapters, err := storedChapterRule([]byte(frontmatterJSON.String)); err != nil {
+				current.invalid = true
+			} else {
+				current.chapters = chapters
+			}
+			current.item.TranslationOf, current.item.Variants = libraryVariants(originalSlug, variantsJSON)
 
 			if progressVersionID.Valid {
 				version := int(progressVersion.Int64)
@@ -551,6 +942,13 @@ func (s *Store) Library(accountID string) (model.LibraryReadModel, error) {
 			} else if progressVersion.Valid || progressPercent.Valid || progressUpdatedAt.Valid {
 				current.invalid = true
 			}
+			if reactedLovedIt.Valid {
+				current.item.Reactions = &model.ReactionCounts{
+					LovedIt: reactedLovedIt.Int64,
+					Silly:   reactedSilly.Int64,
+					Scary:   reactedScary.Int64,
+				}
+			}
 		}
 
 		if !segmentID.Valid {
@@ -613,25 +1011,179 @@ func (s *Store) Library(accountID string) (model.LibraryReadModel, error) {
 	if err := finalize(); err != nil {
 		return model.LibraryReadModel{}, err
 	}
+	if more {
+		result.NextCursor = encodeCursor(lastSortBy...)
+	}
+	// The count is its own snapshot; a story published between the two
+	// reads can make it one off the pages.
+	if result.Total, err = pageTotal(ctx, s.reads(), page, `
+		SELECT count(*)
+		FROM stories AS story
+		WHERE story.account_id = $1
+		  AND story.is_published = true
+		  AND ($2::integer IS NULL OR EXISTS (
+			SELECT 1
+			FROM story_version_phonics AS phonics
+			WHERE phonics.story_version_id = story.published_version_id
+			  AND phonics.progression = $3
+			  AND phonics.decodable_set <= $2::integer
+		  ))
+		  AND ($4::integer IS NULL OR (
+			EXISTS (
+				SELECT 1
+				FROM story_tags AS link
+				JOIN tags AS tag
+				  ON tag.id = link.tag_id
+				 AND tag.account_id = $1
+				WHERE link.story_id = story.id
+				  AND lower(tag.name) = $5
+			)
+			AND NOT EXISTS (
+				SELECT 1
+				FROM story_tags AS link
+				JOIN tags AS tag
+				  ON tag.id = link.tag_id
+				 AND tag.account_id = $1
+				WHERE link.story_id = story.id
+				  AND lower(tag.name) = $6
+			)
+			AND (
+				SELECT COALESCE(sum(segment.word_count), 0)
+				FROM story_segments AS segment
+				WHERE segment.story_version_id = story.published_version_id
+			) <= $4::integer
+		  ))
+	`, accountID, decodableSet, filter.Progression, bedtimeMaxWords, model.BedtimeCalmTag, model.BedtimeExcitingTag); err != nil {
+		return model.LibraryReadModel{}, err
+	}
 	return result, nil
 }
 
 /* ----------------------------- Reader ----------------------------- */
 
-func (s *Store) ReaderStory(accountID, slug string) (model.ReaderStory, error) {
-	ctx, cancel := s.ctx()
-	defer cancel()
+// ReaderStory returns a published story. Devices opening the same story at
+// once, as a family does at bedtime, share one read of it.
+func (s *Store) ReaderStory(ctx context.Context, accountID, slug string) (model.ReaderStory, error) {
+	story, err := shareFlight(ctx, &s.readerFlights, accountID+"/"+slug, func() (model.ReaderStory, error) {
+		// The shared read outlives the caller that started it.
+		ctx, cancel := s.ctx(context.WithoutCancel(ctx))
+		defer cancel()
+		return s.readerStory(ctx, accountID, slug)
+	})
+	if err != nil {
+		return model.ReaderStory{}, err
+	}
+	story.Segments = slices.Clone(story.Segments)
+	story.Contributors = slices.Clone(story.Contributors)
+	return story, nil
+}
 
-	// One SQL statement gives all rows one PostgreSQL statement snapshot. A
-	// publication change cannot mix metadata from one version with segments
-	// from another.
-	rows, err := s.db.QueryContext(ctx, `
+// removedOr answers model.ErrStoryRemoved in place of a missing row when
+// slug names a story the account published and then unpublished, so readers
+// can tell a removed story from one that never existed. Other errors pass
+// through.
+func (s *Store) removedOr(ctx context.Context, accountID, slug string, err error) error {
+	if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	var removed bool
+	if lookupErr := s.reads().QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1
+			FROM stories
+			WHERE account_id = $1
+			  AND slug = $2
+			  AND is_published = false
+			  AND unpublished_at IS NOT NULL
+		)
+	`, accountID, slug).Scan(&removed); lookupErr != nil {
+		return lookupErr
+	}
+	if removed {
+		return fmt.Errorf("%w", model.ErrStoryRemoved)
+	}
+	return err
+}
+
+func (s *Store) readerStory(ctx context.Context, accountID, slug string) (model.ReaderStory, error) {
+	// The metadata statement names the published version. Segments belong to
+	// that version id and are immutable, so reading them separately, or from
+	// the cache, cannot mix metadata from one version with segments from
+	// another.
+	var (
+		story            model.ReaderStory
+		author           sql.NullString
+		versionID        string
+		hyphenation      bool
+		contributorsJSON string
+	)
+	pool := s.reads()
+	err := pool.QueryRow(ctx, `
 		SELECT
 			st.slug,
 			st.title,
 			NULLIF(BTRIM(st.author), ''),
 			st.language,
+			version.id,
 			version.version,
+			st.reader_changed_at,
+			account.reader_hyphenation,
+			`+storyContributorsJSON+`
+		FROM stories st
+		JOIN accounts AS account
+		  ON account.id = st.account_id
+		JOIN story_versions AS version
+		  ON version.id = st.published_version_id
+		 AND version.story_id = st.id
+		WHERE st.account_id = $1
+		  AND st.slug = $2
+		  AND st.is_published = true
+		  AND st.published_version_id IS NOT NULL
+	`, accountID, slug).Scan(
+		&story.Slug,
+		&story.Title,
+		&author,
+		&story.Language,
+		&versionID,
+		&story.Version,
+		&story.ChangedAt,
+		&hyphenation,
+		&contributorsJSON,
+	)
+	if err != nil {
+		return model.ReaderStory{}, s.removedOr(ctx, accountID, slug, err)
+	}
+	story.Author = strPtr(author)
+	if err := json.Unmarshal([]byte(contributorsJSON), &story.Contributors); err != nil {
+		return model.ReaderStory{}, fmt.Errorf("decode story contributors: %w", err)
+	}
+
+	key := readerCacheKey{accountID: accountID, slug: story.Slug, versionID: versionID}
+	version, ok := s.readerCache.get(key)
+	if !ok {
+		if version, err = s.readerVersion(ctx, pool, versionID); err != nil {
+			return model.ReaderStory{}, err
+		}
+		s.readerCache.put(key, version)
+	}
+	story.Readability = version.readability
+	story.Segments = version.segments
+	if !hyphenation {
+		// The account turned hyphenation off; stored HTML keeps the soft
+		// hyphens so turning it back on needs no re-import.
+		for index := range story.Segments {
+			story.Segments[index].RenderedHTML = strings.ReplaceAll(story.Segments[index].RenderedHTML, storyingest.SoftHyphen, "")
+		}
+	}
+	return story, nil
+}
+
+// readerVersion loads and validates the segments of one published version.
+func (s *Store) readerVersion(ctx context.Context, pool *pgxpool.Pool, versionID string) (readerVersion, error) {
+	rows, err := pool.Query(ctx, `
+		SELECT
+			version.readability::text,
+			version.frontmatter::text,
 			segment.ordinal,
 			segment.segment_kind,
 			segment.heading_level,
@@ -640,30 +1192,31 @@ func (s *Store) ReaderStory(accountID, slug string) (model.ReaderStory, error) {
 			segment.chapter_key,
 			segment.chapter_occurrence,
 			segment.rendered_html,
-			segment.word_count
-		FROM stories st
-		JOIN story_versions AS version
-		  ON version.id = st.published_version_id
-		 AND version.story_id = st.id
+			segment.word_count,
+			segment.speaker,
+			segment.pronunciations::text,
+			audio.id::text,
+			audio.duration_ms,
+			audio.word_timings::text
+		FROM story_versions AS version
 		LEFT JOIN story_segments AS segment
 		  ON segment.story_version_id = version.id
-		WHERE st.account_id = $1
-		  AND st.slug = $2
-		  AND st.is_published = true
-		  AND st.published_version_id IS NOT NULL
+		LEFT JOIN segment_audio AS audio
+		  ON audio.segment_id = segment.id
+		WHERE version.id = $1
 		ORDER BY segment.ordinal
-	`, accountID, slug)
+	`, versionID)
 	if err != nil {
-		return model.ReaderStory{}, err
+		return readerVersion{}, err
 	}
 	defer rows.Close()
 
-	var story model.ReaderStory
-	found := false
-	story.Segments = make([]model.ReaderSegment, 0, 64)
+	var version readerVersion
+	var frontmatterJSON string
+	version.segments = make([]model.ReaderSegment, 0, 64)
 	for rows.Next() {
 		var (
-			author            sql.NullString
+			readability       sql.NullString
 			ordinal           sql.NullInt64
 			kind              sql.NullString
 			headingLevel      sql.NullInt64
@@ -673,13 +1226,15 @@ func (s *Store) ReaderStory(accountID, slug string) (model.ReaderStory, error) {
 			chapterOccurrence sql.NullInt64
 			renderedHTML      sql.NullString
 			wordCount         sql.NullInt64
+			speaker           sql.NullString
+			pronunciations    sql.NullString
+			audioID           sql.NullString
+			audioDurationMs   sql.NullInt64
+			wordTimings       sql.NullString
 		)
 		if err := rows.Scan(
-			&story.Slug,
-			&story.Title,
-			&author,
-			&story.Language,
-			&story.Version,
+			&readability,
+			&frontmatterJSON,
 			&ordinal,
 			&kind,
 			&headingLevel,
@@ -689,11 +1244,15 @@ func (s *Store) ReaderStory(accountID, slug string) (model.ReaderStory, error) {
 			&chapterOccurrence,
 			&renderedHTML,
 			&wordCount,
+			&speaker,
+			&pronunciations,
+			&audioID,
+			&audioDurationMs,
+			&wordTimings,
 		); err != nil {
-			return model.ReaderStory{}, err
+			return readerVersion{}, err
 		}
-		found = true
-		story.Author = strPtr(author)
+		version.readability = decodeReadability(readability)
 		if !ordinal.Valid {
 			continue
 		}
@@ -718,23 +1277,33 @@ func (s *Store) ReaderStory(accountID, slug string) (model.ReaderStory, error) {
 			value := int(chapterOccurrence.Int64)
 			segment.ChapterOccurrence = &value
 		}
-		story.Segments = append(story.Segments, segment)
+		if speaker.Valid {
+			value := speaker.String
+			segment.Speaker = &value
+		}
+		if segment.Pronunciations, err = decodePronunciations(pronunciations); err != nil {
+			return readerVersion{}, fmt.Errorf("decode segment pronunciations: %w", err)
+		}
+		if audioID.Valid {
+			segment.Audio = &model.SegmentAudio{URL: model.AudioPath + audioID.String, DurationMs: int(audioDurationMs.Int64)}
+			if segment.Audio.Words, err = decodeWordTimings(wordTimings); err != nil {
+				return readerVersion{}, fmt.Errorf("decode segment word timings: %w", err)
+			}
+		}
+		version.segments = append(version.segments, segment)
 	}
 	if err := rows.Err(); err != nil {
-		return model.ReaderStory{}, err
+		return readerVersion{}, err
 	}
-	if !found {
-		return model.ReaderStory{}, sql.ErrNoRows
-	}
-	if len(story.Segments) == 0 {
+	if len(version.segments) == 0 {
 		// Historical versions created outside the current ingestion path must not
 		// produce a successful but unreadable Reader payload.
-		return model.ReaderStory{}, sql.ErrNoRows
+		return readerVersion{}, sql.ErrNoRows
 	}
-	storedIdentities := make([]readercontract.StoredSegmentIdentity, 0, len(story.Segments))
-	for _, segment := range story.Segments {
+	storedIdentities := make([]readercontract.StoredSegmentIdentity, 0, len(version.segments))
+	for _, segment := range version.segments {
 		if segment.WordCount < 0 {
-			return model.ReaderStory{}, fmt.Errorf("published Reader segment word count is invalid")
+			return readerVersion{}, fmt.Errorf("published Reader segment word count is invalid")
 		}
 		storedIdentities = append(storedIdentities, readercontract.StoredSegmentIdentity{
 			Ordinal:           segment.Ordinal,
@@ -746,16 +1315,20 @@ func (s *Store) ReaderStory(accountID, slug string) (model.ReaderStory, error) {
 			ChapterOccurrence: segment.ChapterOccurrence,
 		})
 	}
-	if _, err := readercontract.ValidateStoredSegmentIdentities(storedIdentities); err != nil {
-		return model.ReaderStory{}, fmt.Errorf("validate published Reader segment identities: %w", err)
+	chapters, err := storedChapterRule([]byte(frontmatterJSON))
+	if err != nil {
+		return readerVersion{}, fmt.Errorf("decode published Reader chapter rule: %w", err)
 	}
-	return story, nil
+	if _, err := readercontract.ValidateStoredSegmentIdentities(storedIdentities, chapters); err != nil {
+		return readerVersion{}, fmt.Errorf("validate published Reader segment identities: %w", err)
+	}
+	return version, nil
 }
 
 /* ----------------------------- Progress ----------------------------- */
 
-func (s *Store) ProgressGet(accountID, slug string) (model.ProgressResponse, error) {
-	ctx, cancel := s.ctx()
+func (s *Store) ProgressGet(ctx context.Context, accountID, slug string) (model.ProgressResponse, error) {
+	ctx, cancel := s.ctx(ctx)
 	defer cancel()
 
 	profileID, err := s.getDefaultProfileID(ctx, accountID)
@@ -769,7 +1342,7 @@ func (s *Store) ProgressGet(accountID, slug string) (model.ProgressResponse, err
 		locatorJSON []byte
 		percent     sql.NullFloat64
 	)
-	err = s.db.QueryRowContext(ctx, `
+	err = s.reads().QueryRow(ctx, `
 		SELECT
 			rp.story_version_id IS NOT NULL,
 			sv.version,
@@ -788,7 +1361,7 @@ func (s *Store) ProgressGet(accountID, slug string) (model.ProgressResponse, err
 		  AND st.published_version_id IS NOT NULL
 	`, accountID, slug, profileID).Scan(&hasProgress, &version, &locatorJSON, &percent)
 	if err != nil {
-		return model.ProgressResponse{}, err
+		return model.ProgressResponse{}, s.removedOr(ctx, accountID, slug, err)
 	}
 	if !hasProgress {
 		return model.ProgressResponse{Progress: nil}, nil
@@ -811,8 +1384,8 @@ func (s *Store) ProgressGet(accountID, slug string) (model.ProgressResponse, err
 	}}, nil
 }
 
-func (s *Store) ProgressPut(accountID, slug string, version int, locator readercontract.Locator, percent float64) error {
-	ctx, cancel := s.ctx()
+func (s *Store) ProgressPut(ctx context.Context, accountID, slug string, version int, locator readercontract.Locator, percent float64) error {
+	ctx, cancel := s.ctx(ctx)
 	defer cancel()
 
 	if err := locator.Validate(); err != nil {
@@ -827,14 +1400,14 @@ func (s *Store) ProgressPut(accountID, slug string, version int, locator readerc
 		return err
 	}
 
-	tx, err := s.db.BeginTx(ctx, nil)
+	tx, err := s.db.Begin(ctx)
 	if err != nil {
 		return err
 	}
-	defer func() { _ = tx.Rollback() }()
+	defer func() { _ = tx.Rollback(ctx) }()
 
 	var storyID, versionID string
-	if err := tx.QueryRowContext(ctx, `
+	if err := tx.QueryRow(ctx, `
 		SELECT story.id, version.id
 		FROM stories AS story
 		JOIN story_versions AS version
@@ -847,16 +1420,65 @@ func (s *Store) ProgressPut(accountID, slug string, version int, locator readerc
 		  AND story.published_version_id IS NOT NULL
 		FOR SHARE OF story
 	`, accountID, slug, version).Scan(&storyID, &versionID); err != nil {
+		return s.removedOr(ctx, accountID, slug, err)
+	}
+
+	if err := matchLocatorSegment(ctx, tx, versionID, locator); err != nil {
 		return err
 	}
 
+	locatorJSON, err := json.Marshal(locator)
+	if err != nil {
+		return err
+	}
+	// Only the save that first reaches the end completes the story; later
+	// saves at the end, such as a reread, do not raise the event again.
+	var previous sql.NullFloat64
+	if err := tx.QueryRow(ctx, `
+		SELECT percent
+		FROM reading_progress
+		WHERE profile_id = $1
+		  AND story_id = $2
+		FOR UPDATE
+	`, profileID, storyID).Scan(&previous); err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	if _, err = tx.Exec(ctx, `
+		INSERT INTO reading_progress (profile_id, story_id, story_version_id, locator, percent, updated_at)
+		VALUES ($1,$2,$3,$4,$5,now())
+		ON CONFLICT (profile_id, story_id)
+		DO UPDATE SET
+			story_version_id=EXCLUDED.story_version_id,
+			locator=EXCLUDED.locator,
+			percent=EXCLUDED.percent,
+			updated_at=now()
+	`, profileID, storyID, versionID, locatorJSON, percent); err != nil {
+		return err
+	}
+	finished := percent >= 1 && (!previous.Valid || previous.Float64 < 1)
+	if err := recordReadingActivity(ctx, tx, accountID, profileID, storyID, nil, finished); err != nil {
+		return err
+	}
+	if finished {
+		payload := webhooks.ProgressCompletedPayload(time.Now(), slug, version, percent)
+		if err := enqueueWebhookEvent(ctx, tx, accountID, payload); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// matchLocatorSegment checks that the locator's segment and chapter identity
+// name the segment stored at its ordinal in the version.
+func matchLocatorSegment(ctx context.Context, tx pgx.Tx, versionID string, locator readercontract.Locator) error {
 	var (
 		storedKey               string
 		storedOccurrence        int
 		storedChapterKey        sql.NullString
 		storedChapterOccurrence sql.NullInt64
 	)
-	if err := tx.QueryRowContext(ctx, `
+	if err := tx.QueryRow(ctx, `
 		SELECT
 			content_key,
 			content_occurrence,
@@ -871,7 +1493,7 @@ func (s *Store) ProgressPut(accountID, slug string, version int, locator readerc
 		&storedChapterKey,
 		&storedChapterOccurrence,
 	); err != nil {
-		if err == sql.ErrNoRows {
+		if errors.Is(err, sql.ErrNoRows) {
 			return readercontract.ErrLocatorMismatch
 		}
 		return err
@@ -890,30 +1512,12 @@ func (s *Store) ProgressPut(accountID, slug string, version int, locator readerc
 			return readercontract.ErrLocatorMismatch
 		}
 	}
-
-	locatorJSON, err := json.Marshal(locator)
-	if err != nil {
-		return err
-	}
-	if _, err = tx.ExecContext(ctx, `
-		INSERT INTO reading_progress (profile_id, story_id, story_version_id, locator, percent, updated_at)
-		VALUES ($1,$2,$3,$4,$5,now())
-		ON CONFLICT (profile_id, story_id)
-		DO UPDATE SET
-			story_version_id=EXCLUDED.story_version_id,
-			locator=EXCLUDED.locator,
-			percent=EXCLUDED.percent,
-			updated_at=now()
-	`, profileID, storyID, versionID, locatorJSON, percent); err != nil {
-		return err
-	}
-
-	return tx.Commit()
+	return nil
 }
 
 /* ------------------------- Continue / Recent -------------------- */
 
-func (s *Store) ContinueRecent(accountID string, limit int) ([]model.ContinueItem, error) {
+func (s *Store) ContinueRecent(ctx context.Context, accountID string, limit int) ([]model.ContinueItem, error) {
 	if limit <= 0 {
 		limit = 3
 	}
@@ -921,7 +1525,7 @@ func (s *Store) ContinueRecent(accountID string, limit int) ([]model.ContinueIte
 		limit = 10
 	}
 
-	ctx, cancel := s.ctx()
+	ctx, cancel := s.ctx(ctx)
 	defer cancel()
 
 	profileID, err := s.getDefaultProfileID(ctx, accountID)
@@ -929,7 +1533,7 @@ func (s *Store) ContinueRecent(accountID string, limit int) ([]model.ContinueIte
 		return nil, err
 	}
 
-	rows, err := s.db.QueryContext(ctx, `
+	rows, err := s.db.Query(ctx, `
 		SELECT st.slug, rp.percent, rp.updated_at
 		FROM reading_progress rp
 		JOIN stories st ON st.id = rp.story_id
@@ -963,7 +1567,7 @@ func (s *Store) ContinueRecent(accountID string, limit int) ([]model.ContinueIte
 /* ----------------------------- Settings / Journey ---------------------------- */
 
 func (s *Store) ensureProfileSettingsRow(ctx context.Context, profileID string) error {
-	_, err := s.db.ExecContext(ctx, `
+	_, err := s.writes(ctx).Exec(ctx, `
 		INSERT INTO profile_settings (profile_id)
 		VALUES ($1)
 		ON CONFLICT (profile_id) DO NOTHING
@@ -971,8 +1575,8 @@ func (s *Store) ensureProfileSettingsRow(ctx context.Context, profileID string)
 	return err
 }
 
-func (s *Store) SettingsGet(accountID string) (model.SettingsPayload, error) {
-	ctx, cancel := s.ctx()
+func (s *Store) SettingsGet(ctx context.Context, accountID string) (model.SettingsPayload, error) {
+	ctx, cancel := s.ctx(ctx)
 	defer cancel()
 
 	profileID, err := s.getDefaultProfileID(ctx, accountID)
@@ -997,7 +1601,7 @@ func (s *Store) SettingsGet(accountID string) (model.SettingsPayload, error) {
 	)
 
 	// Scope child/prompt via JOIN conditions to avoid cross-account leakage.
-	err = s.db.QueryRowContext(ctx, `
+	err = s.writes(ctx).QueryRow(ctx, `
 		SELECT
 			cp.id::text,
 			cp.name,
@@ -1052,8 +1656,8 @@ func (s *Store) SettingsGet(accountID string) (model.SettingsPayload, error) {
 	return out, nil
 }
 
-func (s *Store) SettingsPut(accountID string, payload model.SettingsUpsert) (model.SettingsPayload, error) {
-	ctx, cancel := s.ctx()
+func (s *Store) SettingsPut(ctx context.Context, accountID string, payload model.SettingsUpsert) (model.SettingsPayload, error) {
+	ctx, cancel := s.ctx(ctx)
 	defer cancel()
 
 	// harden inputs
@@ -1090,13 +1694,16 @@ func (s *Store) SettingsPut(accountID string, payload model.SettingsUpsert) (mod
 		return model.SettingsPayload{}, err
 	}
 
-	tx, err := s.db.BeginTx(ctx, nil)
+	tx, err := s.writes(ctx).Begin(ctx)
 	if err != nil {
 		return model.SettingsPayload{}, err
 	}
-	defer func() { _ = tx.Rollback() }()
+	defer func() { _ = tx.Rollback(ctx) }()
 
-	var childID string
+	var (
+		childID      string
+		childChanged bool
+	)
 	if payload.Child.Name != "" {
 		intsJSON, _ := json.Marshal(payload.Child.Interests)
 		sensJSON, _ := json.Marshal(payload.Child.Sensitivities)
@@ -1104,23 +1711,29 @@ func (s *Store) SettingsPut(accountID string, payload model.SettingsUpsert) (mod
 		if payload.Child.ID != "" {
 			childID = payload.Child.ID
 			// scope update by account_id to avoid cross-account updates
-			res, err := tx.ExecContext(ctx, `
-				UPDATE child_profiles
+			err := tx.QueryRow(ctx, `
+				UPDATE child_profiles AS child
 				SET name=$3, age_months=$4, interests=$5::jsonb, sensitivities=$6::jsonb, updated_at=now()
-				WHERE id=$1 AND account_id=$2
-			`, childID, accountID, payload.Child.Name, payload.Child.AgeMonths, string(intsJSON), string(sensJSON))
-			if err != nil {
-				return model.SettingsPayload{}, err
-			}
-			n, _ := res.RowsAffected()
-			if n == 0 {
+				FROM (
+					SELECT id, name, age_months, interests, sensitivities
+					FROM child_profiles
+					WHERE id=$1 AND account_id=$2
+					FOR UPDATE
+				) AS old
+				WHERE child.id = old.id
+				RETURNING (old.name, old.age_months, old.interests, old.sensitivities)
+					IS DISTINCT FROM ($3::text, $4::int, $5::jsonb, $6::jsonb)
+			`, childID, accountID, payload.Child.Name, payload.Child.AgeMonths, string(intsJSON), string(sensJSON)).Scan(&childChanged)
+			if errors.Is(err, sql.ErrNoRows) {
 				// If the id doesn't belong to this account, treat as insert.
 				childID = ""
+			} else if err != nil {
+				return model.SettingsPayload{}, err
 			}
 		}
 
 		if childID == "" {
-			err = tx.QueryRowContext(ctx, `
+			err = tx.QueryRow(ctx, `
 				INSERT INTO child_profiles (account_id, name, age_months, interests, sensitivities)
 				VALUES ($1,$2,$3,$4::jsonb,$5::jsonb)
 				RETURNING id
@@ -1128,6 +1741,7 @@ func (s *Store) SettingsPut(accountID string, payload model.SettingsUpsert) (mod
 			if err != nil {
 				return model.SettingsPayload{}, err
 			}
+			childChanged = true
 		}
 	}
 
@@ -1139,7 +1753,7 @@ func (s *Store) SettingsPut(accountID string, payload model.SettingsUpsert) (mod
 		if payload.Prompt.ID != "" {
 			promptID = payload.Prompt.ID
 			// scope update by account_id to avoid cross-account updates
-			res, err := tx.ExecContext(ctx, `
+			res, err := tx.Exec(ctx, `
 				UPDATE prompt_profiles
 				SET name=$3, rules=$4::jsonb, schema_version=$5, updated_at=now()
 				WHERE id=$1 AND account_id=$2
@@ -1147,14 +1761,14 @@ func (s *Store) SettingsPut(accountID string, payload model.SettingsUpsert) (mod
 			if err != nil {
 				return model.SettingsPayload{}, err
 			}
-			n, _ := res.RowsAffected()
+			n := res.RowsAffected()
 			if n == 0 {
 				promptID = ""
 			}
 		}
 
 		if promptID == "" {
-			err = tx.QueryRowContext(ctx, `
+			err = tx.QueryRow(ctx, `
 				INSERT INTO prompt_profiles (account_id, name, rules, schema_version)
 				VALUES ($1,$2,$3::jsonb,$4)
 				RETURNING id
@@ -1166,7 +1780,7 @@ func (s *Store) SettingsPut(accountID string, payload model.SettingsUpsert) (mod
 	}
 
 	if childID != "" || promptID != "" {
-		_, err = tx.ExecContext(ctx, `
+		_, err = tx.Exec(ctx, `
 			UPDATE profile_settings
 			SET active_child_profile_id = COALESCE(NULLIF($2,'' )::uuid, active_child_profile_id),
 			    active_prompt_profile_id = COALESCE(NULLIF($3,'' )::uuid, active_prompt_profile_id),
@@ -1177,10 +1791,17 @@ func (s *Store) SettingsPut(accountID string, payload model.SettingsUpsert) (mod
 			return model.SettingsPayload{}, err
 		}
 	}
+	// Saving settings unchanged, as the settings screen does for a prompt
+	// edit, does not tell subscribers the child changed.
+	if childChanged {
+		if err := enqueueWebhookEvent(ctx, tx, accountID, webhooks.ChildUpdatedPayload(time.Now(), childID)); err != nil {
+			return model.SettingsPayload{}, err
+		}
+	}
 
-	if err := tx.Commit(); err != nil {
+	if err := tx.Commit(ctx); err != nil {
 		return model.SettingsPayload{}, err
 	}
 
-	return s.SettingsGet(accountID)
+	return s.SettingsGet(ctx, accountID)
 }
@@ -1,6 +1,7 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"errors"
@@ -16,6 +17,9 @@ import (
 	"pandapages/api/internal/httpapi"
 	"pandapages/api/internal/model"
 	"pandapages/api/internal/session"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	_ "github.com/jackc/pgx/v5/stdlib"
 )
 
 const (
@@ -72,7 +76,7 @@ func TestAccountStoreIntegration(t *testing.T) {
 			go func() {
 				defer wg.Done()
 				<-start
-				results[i].id, results[i].err = store.EnsureDefaultAccount()
+				results[i].id, results[i].err = store.EnsureDefaultAccount(t.Context())
 			}()
 		}
 
@@ -117,7 +121,7 @@ func TestAccountStoreIntegration(t *testing.T) {
 			t.Fatalf("insert initial account: %v", err)
 		}
 
-		got, err := store.EnsureDefaultAccount()
+		got, err := store.EnsureDefaultAccount(t.Context())
 		if err != nil {
 			t.Fatalf("select initial account: %v", err)
 		}
@@ -138,7 +142,7 @@ func TestAccountStoreIntegration(t *testing.T) {
 			t.Fatalf("insert deterministic accounts: %v", err)
 		}
 
-		got, err = store.EnsureDefaultAccount()
+		got, err = store.EnsureDefaultAccount(t.Context())
 		if err != nil {
 			t.Fatalf("reselect oldest account: %v", err)
 		}
@@ -177,7 +181,7 @@ func TestAccountStoreIntegration(t *testing.T) {
 			{name: "uuid with suffix", id: existingID + "-extra", want: false},
 		} {
 			t.Run(test.name, func(t *testing.T) {
-				got, err := store.AccountExists(test.id)
+				got, err := store.AccountExists(t.Context(), test.id)
 				if err != nil {
 					t.Fatalf("AccountExists(%q): %v", test.id, err)
 				}
@@ -409,7 +413,7 @@ func TestAccountStoreIntegration(t *testing.T) {
 			t.Fatalf("insert account-scoped progress: %v", err)
 		}
 
-		libraryA, err := store.Library(accountA)
+		libraryA, err := store.Library(t.Context(), accountA, model.PageRequest{}, model.LibraryFilter{})
 		if err != nil {
 			t.Fatalf("Library(account A): %v", err)
 		}
@@ -420,6 +424,30 @@ func TestAccountStoreIntegration(t *testing.T) {
 		if len(itemsA) != 2 || itemsA[0].Slug != "no-progress" || itemsA[1].Slug != "shared-story" {
 			t.Fatalf("Library(account A) ordering/scope = %#v", itemsA)
 		}
+		if libraryA.NextCursor != nil {
+			t.Fatalf("Library(account A) single page has a next cursor")
+		}
+		pagedSlugs, pagedUnavailable, pages := []string{}, int64(0), 0
+		for page := (model.PageRequest{Limit: 2}); ; pages++ {
+			paged, err := store.Library(t.Context(), accountA, page, model.LibraryFilter{})
+			if err != nil {
+				t.Fatalf("Library(account A) page %d: %v", pages, err)
+			}
+			for _, item := range paged.Items {
+				pagedSlugs = append(pagedSlugs, item.Slug)
+			}
+			pagedUnavailable += paged.UnavailableItemCount
+			if paged.NextCursor == nil {
+				break
+			}
+			page.Cursor = *paged.NextCursor
+		}
+		if pages != 2 || pagedUnavailable != 3 || len(pagedSlugs) != 2 || pagedSlugs[0] != "no-progress" || pagedSlugs[1] != "shared-story" {
+			t.Fatalf("Library(account A) pages = %d, slugs %v, unavailable %d", pages+1, pagedSlugs, pagedUnavailable)
+		}
+		if _, err := store.Library(t.Context(), accountA, model.PageRequest{Cursor: "not-a-cursor"}, model.LibraryFilter{}); !errors.Is(err, model.ErrInvalidCursor) {
+			t.Fatalf("Library(invalid cursor) error = %v", err)
+		}
 		if itemsA[0].Title != "No progress published" || itemsA[0].Language != "en-GB" ||
 			itemsA[0].Author != nil || itemsA[0].PublishedVersion != 1 ||
 			itemsA[0].WordCount != 9 || itemsA[0].ChapterCount != 0 || itemsA[0].Progress != nil {
@@ -454,7 +482,7 @@ func TestAccountStoreIntegration(t *testing.T) {
 		`, missingPointerC, crossPointerC, accountC, versionB); err != nil {
 			t.Fatalf("insert all-invalid Library candidates: %v", err)
 		}
-		allInvalid, err := store.Library(accountC)
+		allInvalid, err := store.Library(t.Context(), accountC, model.PageRequest{}, model.LibraryFilter{})
 		if err != nil {
 			t.Fatalf("Library(all-invalid account): %v", err)
 		}
@@ -468,7 +496,7 @@ func TestAccountStoreIntegration(t *testing.T) {
 		if strings.Contains(string(allInvalidJSON), "Account B published") || strings.Contains(string(allInvalidJSON), `"cy"`) {
 			t.Fatalf("foreign immutable metadata crossed accounts: %s", allInvalidJSON)
 		}
-		emptyAccount, err := store.Library(accountD)
+		emptyAccount, err := store.Library(t.Context(), accountD, model.PageRequest{}, model.LibraryFilter{})
 		if err != nil {
 			t.Fatalf("Library(empty account): %v", err)
 		}
@@ -508,7 +536,7 @@ func TestAccountStoreIntegration(t *testing.T) {
 		`, validStoryD, corruptStoryD, validVersionD, corruptVersionD); err != nil {
 			t.Fatalf("set partial-library pointers: %v", err)
 		}
-		oneValidOneCorrupt, err := store.Library(accountD)
+		oneValidOneCorrupt, err := store.Library(t.Context(), accountD, model.PageRequest{}, model.LibraryFilter{})
 		if err != nil {
 			t.Fatalf("Library(one valid and one corrupt): %v", err)
 		}
@@ -543,7 +571,7 @@ func TestAccountStoreIntegration(t *testing.T) {
 		`, zeroVersion, zeroStory); err != nil {
 			t.Fatalf("insert historical zero-segment version: %v", err)
 		}
-		if err := store.AdminPublish(accountA, "historical-empty", zeroVersion); !errors.Is(err, model.ErrAdminPublishInvalid) {
+		if err := store.AdminPublish(t.Context(), accountA, "historical-empty", zeroVersion); !errors.Is(err, model.ErrAdminPublishInvalid) {
 			t.Fatalf("zero-segment AdminPublish error = %v", err)
 		}
 		var (
@@ -559,14 +587,14 @@ func TestAccountStoreIntegration(t *testing.T) {
 		if _, err := adminDB.Exec(`UPDATE stories SET is_published = true, published_version_id = $2 WHERE id = $1`, zeroStory, zeroVersion); err != nil {
 			t.Fatalf("publish historical zero-segment version: %v", err)
 		}
-		emptyQuarantine, err := store.Library(accountA)
+		emptyQuarantine, err := store.Library(t.Context(), accountA, model.PageRequest{}, model.LibraryFilter{})
 		if err != nil {
 			t.Fatalf("Library(account A) with historical empty story: %v", err)
 		}
 		if emptyQuarantine.UnavailableItemCount != 4 || len(emptyQuarantine.Items) != 2 {
 			t.Fatalf("historical empty quarantine = %#v", emptyQuarantine)
 		}
-		if _, err := store.ReaderStory(accountA, "historical-empty"); !errors.Is(err, sql.ErrNoRows) {
+		if _, err := store.ReaderStory(t.Context(), accountA, "historical-empty"); !errors.Is(err, sql.ErrNoRows) {
 			t.Fatalf("historical empty ReaderStory error = %v, want sql.ErrNoRows", err)
 		}
 		if _, err := adminDB.Exec(`UPDATE stories SET published_version_id = NULL WHERE id = $1`, zeroStory); err != nil {
@@ -586,7 +614,7 @@ func TestAccountStoreIntegration(t *testing.T) {
 		`, versionA1); err != nil {
 			t.Fatalf("make published metadata incomplete: %v", err)
 		}
-		partial, err := store.Library(accountA)
+		partial, err := store.Library(t.Context(), accountA, model.PageRequest{}, model.LibraryFilter{})
 		if err != nil {
 			t.Fatalf("Library(account A) with corrupt immutable metadata: %v", err)
 		}
@@ -606,7 +634,7 @@ func TestAccountStoreIntegration(t *testing.T) {
 			t.Fatalf("restore published metadata: %v", err)
 		}
 
-		libraryB, err := store.Library(accountB)
+		libraryB, err := store.Library(t.Context(), accountB, model.PageRequest{}, model.LibraryFilter{})
 		if err != nil {
 			t.Fatalf("Library(account B): %v", err)
 		}
@@ -627,7 +655,7 @@ func TestAccountStoreIntegration(t *testing.T) {
 		`, storyA, versionA2); err != nil {
 			t.Fatalf("republish account A story: %v", err)
 		}
-		updatedLibrary, err := store.Library(accountA)
+		updatedLibrary, err := store.Library(t.Context(), accountA, model.PageRequest{}, model.LibraryFilter{})
 		if err != nil {
 			t.Fatalf("Library(account A) after republish: %v", err)
 		}
@@ -648,7 +676,7 @@ func TestAccountStoreIntegration(t *testing.T) {
 		if _, err := adminDB.Exec(`UPDATE story_segments SET word_count = -1 WHERE story_version_id = $1 AND ordinal = 1`, versionA2); err != nil {
 			t.Fatalf("corrupt aggregate fixture: %v", err)
 		}
-		invalidAggregate, err := store.Library(accountA)
+		invalidAggregate, err := store.Library(t.Context(), accountA, model.PageRequest{}, model.LibraryFilter{})
 		if err != nil || invalidAggregate.UnavailableItemCount != 4 || len(invalidAggregate.Items) != 1 {
 			t.Fatalf("malformed aggregate quarantine = %#v / %v", invalidAggregate, err)
 		}
@@ -659,11 +687,11 @@ func TestAccountStoreIntegration(t *testing.T) {
 		if _, err := adminDB.Exec(`UPDATE story_segments SET chapter_occurrence = 2 WHERE story_version_id = $1 AND ordinal = 4`, versionA2); err != nil {
 			t.Fatalf("corrupt chapter propagation fixture: %v", err)
 		}
-		invalidIdentity, err := store.Library(accountA)
+		invalidIdentity, err := store.Library(t.Context(), accountA, model.PageRequest{}, model.LibraryFilter{})
 		if err != nil || invalidIdentity.UnavailableItemCount != 4 || len(invalidIdentity.Items) != 1 {
 			t.Fatalf("malformed identity quarantine = %#v / %v", invalidIdentity, err)
 		}
-		if _, err := store.ReaderStory(accountA, "shared-story"); err == nil || !strings.Contains(err.Error(), "segment identities") {
+		if _, err := store.ReaderStory(t.Context(), accountA, "shared-story"); err == nil || !strings.Contains(err.Error(), "segment identities") {
 			t.Fatalf("ReaderStory malformed identity error = %v", err)
 		}
 		if _, err := adminDB.Exec(`UPDATE story_segments SET chapter_occurrence = 1 WHERE story_version_id = $1 AND ordinal = 4`, versionA2); err != nil {
@@ -673,7 +701,7 @@ func TestAccountStoreIntegration(t *testing.T) {
 		if _, err := adminDB.Exec(`UPDATE reading_progress SET percent = 1.5 WHERE profile_id = $1 AND story_id = $2`, profileA, storyA); err != nil {
 			t.Fatalf("corrupt progress fixture: %v", err)
 		}
-		invalidProgress, err := store.Library(accountA)
+		invalidProgress, err := store.Library(t.Context(), accountA, model.PageRequest{}, model.LibraryFilter{})
 		if err != nil || invalidProgress.UnavailableItemCount != 4 || len(invalidProgress.Items) != 1 {
 			t.Fatalf("malformed progress quarantine = %#v / %v", invalidProgress, err)
 		}
@@ -684,7 +712,7 @@ func TestAccountStoreIntegration(t *testing.T) {
 		if _, err := adminDB.Exec(`UPDATE reading_progress SET story_version_id = $3 WHERE profile_id = $1 AND story_id = $2`, profileA, storyA, versionB); err != nil {
 			t.Fatalf("corrupt progress version fixture: %v", err)
 		}
-		crossStoryProgress, err := store.Library(accountA)
+		crossStoryProgress, err := store.Library(t.Context(), accountA, model.PageRequest{}, model.LibraryFilter{})
 		if err != nil || crossStoryProgress.UnavailableItemCount != 4 || len(crossStoryProgress.Items) != 1 {
 			t.Fatalf("cross-story progress quarantine = %#v / %v", crossStoryProgress, err)
 		}
@@ -720,17 +748,20 @@ func assertIntegrationStatus(t *testing.T, handler http.Handler, cookie *http.Co
 func newAccountIntegrationStore(t *testing.T, databaseURL string) *Store {
 	t.Helper()
 
-	database, err := sql.Open("pgx", databaseURL)
+	config, err := pgxpool.ParseConfig(databaseURL)
+	if err != nil {
+		t.Fatalf("open Store database: %v", err)
+	}
+	config.MaxConns = 2
+	database, err := pgxpool.NewWithConfig(context.Background(), config)
 	if err != nil {
 		t.Fatalf("open Store database: %v", err)
 	}
-	database.SetMaxOpenConns(2)
-	database.SetMaxIdleConns(1)
-	if err := database.Ping(); err != nil {
-		_ = database.Close()
+	if err := database.Ping(context.Background()); err != nil {
+		database.Close()
 		t.Fatalf("ping Store database: %v", err)
 	}
-	t.Cleanup(func() { _ = database.Close() })
+	t.Cleanup(database.Close)
 
 	return &Store{
 		db:                      database,
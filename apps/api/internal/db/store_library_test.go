@@ -1,8 +1,12 @@
 package db
 
 import (
+	"database/sql"
+	"reflect"
 	"testing"
 	"time"
+
+	"pandapages/api/internal/model"
 )
 
 func TestLibraryVersionMetadataUsesOnlyTypedVersionValues(t *testing.T) {
@@ -100,3 +104,21 @@ func TestValidLibraryProgressTimeMatchesRFC3339YearRange(t *testing.T) {
 		}
 	}
 }
+
+func TestLibraryVariantsSkipsVariantsThatCannotBeShown(t *testing.T) {
+	translationOf, variants := libraryVariants(
+		sql.NullString{String: "owls", Valid: true},
+		sql.NullString{String: `[{"slug":"owls-de","language":" de "},{"slug":"Owls_FR","language":"fr"},{"slug":"owls-es","language":null}]`, Valid: true},
+	)
+	if translationOf == nil || *translationOf != "owls" {
+		t.Fatalf("translationOf = %#v", translationOf)
+	}
+	if !reflect.DeepEqual(variants, []model.StoryVariant{{Slug: "owls-de", Language: "de"}}) {
+		t.Fatalf("variants = %#v", variants)
+	}
+
+	translationOf, variants = libraryVariants(sql.NullString{}, sql.NullString{String: "not json", Valid: true})
+	if translationOf != nil || variants != nil {
+		t.Fatalf("ungrouped story = %#v / %#v", translationOf, variants)
+	}
+}
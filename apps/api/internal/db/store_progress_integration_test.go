@@ -1,6 +1,7 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"math"
@@ -12,6 +13,9 @@ import (
 
 	"pandapages/api/internal/model"
 	"pandapages/api/internal/readercontract"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	_ "github.com/jackc/pgx/v5/stdlib"
 )
 
 const (
@@ -120,34 +124,34 @@ func TestProgressStoreIntegration(t *testing.T) {
 	}
 
 	t.Run("known empty progress is distinct from a missing story", func(t *testing.T) {
-		got, err := store.ProgressGet(accountA, slug)
+		got, err := store.ProgressGet(t.Context(), accountA, slug)
 		if err != nil {
 			t.Fatalf("ProgressGet empty: %v", err)
 		}
 		if got.Progress != nil {
 			t.Fatalf("empty progress = %#v, want nil", got.Progress)
 		}
-		if _, err := store.ProgressGet(accountA, "missing-story"); !errors.Is(err, sql.ErrNoRows) {
+		if _, err := store.ProgressGet(t.Context(), accountA, "missing-story"); !errors.Is(err, sql.ErrNoRows) {
 			t.Fatalf("missing ProgressGet error = %v, want sql.ErrNoRows", err)
 		}
 	})
 
 	t.Run("valid typed put creates and updates progress", func(t *testing.T) {
 		first := progressLocator(progressKeyA, 1, 1, 0.25, false)
-		if err := store.ProgressPut(accountA, slug, 1, first, 0.25); err != nil {
+		if err := store.ProgressPut(t.Context(), accountA, slug, 1, first, 0.25); err != nil {
 			t.Fatalf("ProgressPut first: %v", err)
 		}
-		got, err := store.ProgressGet(accountA, slug)
+		got, err := store.ProgressGet(t.Context(), accountA, slug)
 		if err != nil {
 			t.Fatalf("ProgressGet first: %v", err)
 		}
 		assertProgressState(t, got, 1, first, 0.25)
 
 		later := progressLocator(progressKeyB, 1, 3, 0.5, true)
-		if err := store.ProgressPut(accountA, slug, 1, later, 0.75); err != nil {
+		if err := store.ProgressPut(t.Context(), accountA, slug, 1, later, 0.75); err != nil {
 			t.Fatalf("ProgressPut update: %v", err)
 		}
-		got, err = store.ProgressGet(accountA, slug)
+		got, err = store.ProgressGet(t.Context(), accountA, slug)
 		if err != nil {
 			t.Fatalf("ProgressGet update: %v", err)
 		}
@@ -181,10 +185,10 @@ func TestProgressStoreIntegration(t *testing.T) {
 				chapter := *confirmed.Chapter
 				candidate.Chapter = &chapter
 				test.mutate(&candidate)
-				if err := store.ProgressPut(accountA, slug, 1, candidate, 0.9); !errors.Is(err, readercontract.ErrLocatorMismatch) {
+				if err := store.ProgressPut(t.Context(), accountA, slug, 1, candidate, 0.9); !errors.Is(err, readercontract.ErrLocatorMismatch) {
 					t.Fatalf("ProgressPut error = %v, want locator mismatch", err)
 				}
-				got, err := store.ProgressGet(accountA, slug)
+				got, err := store.ProgressGet(t.Context(), accountA, slug)
 				if err != nil {
 					t.Fatalf("ProgressGet after mismatch: %v", err)
 				}
@@ -196,7 +200,7 @@ func TestProgressStoreIntegration(t *testing.T) {
 	t.Run("percentage is rejected rather than clamped", func(t *testing.T) {
 		locator := progressLocator(progressKeyA, 1, 1, 0, false)
 		for _, invalid := range []float64{-0.01, 1.01, math.Inf(1), math.NaN()} {
-			if err := store.ProgressPut(accountA, slug, 1, locator, invalid); err == nil {
+			if err := store.ProgressPut(t.Context(), accountA, slug, 1, locator, invalid); err == nil {
 				t.Fatalf("ProgressPut accepted invalid percent %v", invalid)
 			}
 		}
@@ -204,20 +208,20 @@ func TestProgressStoreIntegration(t *testing.T) {
 
 	t.Run("missing story and version return sql ErrNoRows", func(t *testing.T) {
 		locator := progressLocator(progressKeyA, 1, 1, 0, false)
-		if err := store.ProgressPut(accountA, "missing-story", 1, locator, 0.1); !errors.Is(err, sql.ErrNoRows) {
+		if err := store.ProgressPut(t.Context(), accountA, "missing-story", 1, locator, 0.1); !errors.Is(err, sql.ErrNoRows) {
 			t.Fatalf("missing-story error = %v, want sql.ErrNoRows", err)
 		}
-		if err := store.ProgressPut(accountA, slug, 2, locator, 0.1); !errors.Is(err, sql.ErrNoRows) {
+		if err := store.ProgressPut(t.Context(), accountA, slug, 2, locator, 0.1); !errors.Is(err, sql.ErrNoRows) {
 			t.Fatalf("missing-version error = %v, want sql.ErrNoRows", err)
 		}
 	})
 
 	t.Run("another account cannot access the first account story", func(t *testing.T) {
 		locator := progressLocator(progressKeyA, 1, 1, 0, false)
-		if _, err := store.ProgressGet(accountB, slug); !errors.Is(err, sql.ErrNoRows) {
+		if _, err := store.ProgressGet(t.Context(), accountB, slug); !errors.Is(err, sql.ErrNoRows) {
 			t.Fatalf("cross-account ProgressGet error = %v, want sql.ErrNoRows", err)
 		}
-		if err := store.ProgressPut(accountB, slug, 1, locator, 0.2); !errors.Is(err, sql.ErrNoRows) {
+		if err := store.ProgressPut(t.Context(), accountB, slug, 1, locator, 0.2); !errors.Is(err, sql.ErrNoRows) {
 			t.Fatalf("cross-account ProgressPut error = %v, want sql.ErrNoRows", err)
 		}
 	})
@@ -256,18 +260,18 @@ func TestProgressStoreIntegration(t *testing.T) {
 
 		locatorA := progressLocator(progressKeyA, 1, 1, 0.9, false)
 		locatorB := progressLocator(progressKeyB, 1, 1, 0.4, false)
-		if err := store.ProgressPut(accountA, slug, 1, locatorA, 0.91); err != nil {
+		if err := store.ProgressPut(t.Context(), accountA, slug, 1, locatorA, 0.91); err != nil {
 			t.Fatalf("ProgressPut account A independent: %v", err)
 		}
-		if err := store.ProgressPut(accountB, slug, 1, locatorB, 0.4); err != nil {
+		if err := store.ProgressPut(t.Context(), accountB, slug, 1, locatorB, 0.4); err != nil {
 			t.Fatalf("ProgressPut account B independent: %v", err)
 		}
 
-		gotA, err := store.ProgressGet(accountA, slug)
+		gotA, err := store.ProgressGet(t.Context(), accountA, slug)
 		if err != nil {
 			t.Fatalf("ProgressGet account A independent: %v", err)
 		}
-		gotB, err := store.ProgressGet(accountB, slug)
+		gotB, err := store.ProgressGet(t.Context(), accountB, slug)
 		if err != nil {
 			t.Fatalf("ProgressGet account B independent: %v", err)
 		}
@@ -278,17 +282,20 @@ func TestProgressStoreIntegration(t *testing.T) {
 
 func newProgressIntegrationStore(t *testing.T, databaseURL string) *Store {
 	t.Helper()
-	database, err := sql.Open("pgx", databaseURL)
+	config, err := pgxpool.ParseConfig(databaseURL)
+	if err != nil {
+		t.Fatalf("open progress Store database: %v", err)
+	}
+	config.MaxConns = 4
+	database, err := pgxpool.NewWithConfig(context.Background(), config)
 	if err != nil {
 		t.Fatalf("open progress Store database: %v", err)
 	}
-	database.SetMaxOpenConns(4)
-	database.SetMaxIdleConns(2)
-	if err := database.Ping(); err != nil {
-		_ = database.Close()
+	if err := database.Ping(context.Background()); err != nil {
+		database.Close()
 		t.Fatalf("ping progress Store database: %v", err)
 	}
-	t.Cleanup(func() { _ = database.Close() })
+	t.Cleanup(database.Close)
 
 	return &Store{
 		db:                      database,
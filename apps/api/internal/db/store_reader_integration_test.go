@@ -1,6 +1,7 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"errors"
@@ -10,6 +11,7 @@ import (
 	"net/http/httptest"
 	"os"
 	"reflect"
+	"slices"
 	"strings"
 	"sync"
 	"testing"
@@ -20,6 +22,9 @@ import (
 	"pandapages/api/internal/model"
 	"pandapages/api/internal/readercontract"
 	"pandapages/api/internal/session"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	_ "github.com/jackc/pgx/v5/stdlib"
 )
 
 const (
@@ -67,7 +72,7 @@ func TestReaderStoreIntegration(t *testing.T) {
 	store := newReaderIntegrationStore(t, databaseURL)
 	author := "Panda Pages Test Fixture"
 	language := "en-GB"
-	firstDraft, err := store.AdminDraftUpsert(readerAccountA, model.AdminDraftUpsertRequest{
+	firstDraft, err := store.AdminDraftUpsert(t.Context(), readerAccountA, model.AdminDraftUpsertRequest{
 		Slug:     readerSlug,
 		Title:    "TEST ONLY — Coherent Reader",
 		Author:   &author,
@@ -80,11 +85,11 @@ func TestReaderStoreIntegration(t *testing.T) {
 	if firstDraft.Version != 1 || firstDraft.SegmentsCount != 6 {
 		t.Fatalf("first draft = %#v, want version 1 with six segments", firstDraft)
 	}
-	if err := store.AdminPublish(readerAccountA, readerSlug, firstDraft.StoryVersionID); err != nil {
+	if err := store.AdminPublish(t.Context(), readerAccountA, readerSlug, firstDraft.StoryVersionID); err != nil {
 		t.Fatalf("publish first Reader version: %v", err)
 	}
 
-	secondDraft, err := store.AdminDraftUpsert(readerAccountA, model.AdminDraftUpsertRequest{
+	secondDraft, err := store.AdminDraftUpsert(t.Context(), readerAccountA, model.AdminDraftUpsertRequest{
 		Slug:     readerSlug,
 		Title:    "TEST ONLY — Coherent Reader",
 		Author:   &author,
@@ -97,11 +102,11 @@ func TestReaderStoreIntegration(t *testing.T) {
 	if secondDraft.Version != 2 || secondDraft.SegmentsCount != 2 {
 		t.Fatalf("second draft = %#v, want version 2 with two segments", secondDraft)
 	}
-	if err := store.AdminPublish(readerAccountA, readerSlug, firstDraft.StoryVersionID); err != nil {
+	if err := store.AdminPublish(t.Context(), readerAccountA, readerSlug, firstDraft.StoryVersionID); err != nil {
 		t.Fatalf("restore first publication: %v", err)
 	}
 
-	accountBDraft, err := store.AdminDraftUpsert(readerAccountB, model.AdminDraftUpsertRequest{
+	accountBDraft, err := store.AdminDraftUpsert(t.Context(), readerAccountB, model.AdminDraftUpsertRequest{
 		Slug:     readerSlug,
 		Title:    "Account B isolated story",
 		Author:   &author,
@@ -111,10 +116,10 @@ func TestReaderStoreIntegration(t *testing.T) {
 	if err != nil {
 		t.Fatalf("insert account B Reader draft: %v", err)
 	}
-	if err := store.AdminPublish(readerAccountB, readerSlug, accountBDraft.StoryVersionID); err != nil {
+	if err := store.AdminPublish(t.Context(), readerAccountB, readerSlug, accountBDraft.StoryVersionID); err != nil {
 		t.Fatalf("publish account B story: %v", err)
 	}
-	if _, err := store.AdminDraftUpsert(readerAccountA, model.AdminDraftUpsertRequest{
+	if _, err := store.AdminDraftUpsert(t.Context(), readerAccountA, model.AdminDraftUpsertRequest{
 		Slug:     "unpublished-reader-story",
 		Title:    "Unpublished",
 		Language: &language,
@@ -123,13 +128,41 @@ func TestReaderStoreIntegration(t *testing.T) {
 		t.Fatalf("insert unpublished Reader draft: %v", err)
 	}
 
+	t.Run("publishing invalidates other instances' reader caches", func(t *testing.T) {
+		options := DefaultOptions()
+		options.MaxConns = 1
+		other, err := Open(t.Context(), databaseURL, options)
+		if err != nil {
+			t.Fatalf("open second instance: %v", err)
+		}
+		t.Cleanup(func() { _ = other.Close() })
+
+		// The listener subscribes in the background, so keep republishing
+		// until an invalidation arrives.
+		stale := readerCacheKey{accountID: readerAccountA, slug: readerSlug, versionID: "stale"}
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			other.readerCache.put(stale, cachedVersion("stale"))
+			if err := store.AdminPublish(t.Context(), readerAccountA, readerSlug, firstDraft.StoryVersionID); err != nil {
+				t.Fatalf("republish: %v", err)
+			}
+			time.Sleep(50 * time.Millisecond)
+			if _, ok := other.readerCache.get(stale); !ok {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatal("the second instance never forgot the republished story")
+			}
+		}
+	})
+
 	t.Run("Story Studio catalogue detail source and unpublish contracts", func(t *testing.T) {
 		if firstDraft.Outcome != model.AdminDraftOutcomeCreatedStory ||
 			secondDraft.Outcome != model.AdminDraftOutcomeCreatedVersion {
 			t.Fatalf("initial draft outcomes = %q / %q", firstDraft.Outcome, secondDraft.Outcome)
 		}
 
-		emptyCatalogue, err := store.AdminListStories(readerAccountC)
+		emptyCatalogue, err := store.AdminListStories(t.Context(), readerAccountC, model.PageRequest{})
 		if err != nil {
 			t.Fatalf("list empty account catalogue: %v", err)
 		}
@@ -137,11 +170,11 @@ func TestReaderStoreIntegration(t *testing.T) {
 			t.Fatalf("empty account catalogue = %#v", emptyCatalogue)
 		}
 
-		catalogue, err := store.AdminListStories(readerAccountA)
+		catalogue, err := store.AdminListStories(t.Context(), readerAccountA, model.PageRequest{})
 		if err != nil {
 			t.Fatalf("list account A catalogue: %v", err)
 		}
-		repeatedCatalogue, err := store.AdminListStories(readerAccountA)
+		repeatedCatalogue, err := store.AdminListStories(t.Context(), readerAccountA, model.PageRequest{})
 		if err != nil {
 			t.Fatalf("repeat account A catalogue: %v", err)
 		}
@@ -183,7 +216,7 @@ func TestReaderStoreIntegration(t *testing.T) {
 			}
 		}
 
-		detail, err := store.AdminGetStory(readerAccountA, readerSlug)
+		detail, err := store.AdminGetStory(t.Context(), readerAccountA, readerSlug)
 		if err != nil {
 			t.Fatalf("get account A story detail: %v", err)
 		}
@@ -206,7 +239,7 @@ func TestReaderStoreIntegration(t *testing.T) {
 			t.Fatalf("detail loaded source content: %s", encodedDetail)
 		}
 
-		source, err := store.AdminGetVersionSource(readerAccountA, readerSlug, firstDraft.VersionID)
+		source, err := store.AdminGetVersionSource(t.Context(), readerAccountA, readerSlug, firstDraft.VersionID)
 		if err != nil {
 			t.Fatalf("get protected version source: %v", err)
 		}
@@ -215,17 +248,17 @@ func TestReaderStoreIntegration(t *testing.T) {
 			source.Health != model.AdminVersionHealthReady || !source.IsPublished || source.IsDraft {
 			t.Fatalf("protected version source = %#v", source)
 		}
-		if _, err := store.AdminGetVersionSource(readerAccountA, readerSlug, accountBDraft.VersionID); !errors.Is(err, model.ErrAdminStoryNotFound) {
+		if _, err := store.AdminGetVersionSource(t.Context(), readerAccountA, readerSlug, accountBDraft.VersionID); !errors.Is(err, model.ErrAdminStoryNotFound) {
 			t.Fatalf("cross-account version source error = %v", err)
 		}
-		unpublishedDetail, err := store.AdminGetStory(readerAccountA, "unpublished-reader-story")
+		unpublishedDetail, err := store.AdminGetStory(t.Context(), readerAccountA, "unpublished-reader-story")
 		if err != nil {
 			t.Fatalf("get second story detail: %v", err)
 		}
-		if _, err := store.AdminGetVersionSource(readerAccountA, readerSlug, unpublishedDetail.DraftVersion.VersionID); !errors.Is(err, model.ErrAdminStoryNotFound) {
+		if _, err := store.AdminGetVersionSource(t.Context(), readerAccountA, readerSlug, unpublishedDetail.DraftVersion.VersionID); !errors.Is(err, model.ErrAdminStoryNotFound) {
 			t.Fatalf("cross-story version source error = %v", err)
 		}
-		if _, err := store.AdminGetStory(readerAccountC, readerSlug); !errors.Is(err, model.ErrAdminStoryNotFound) {
+		if _, err := store.AdminGetStory(t.Context(), readerAccountC, readerSlug); !errors.Is(err, model.ErrAdminStoryNotFound) {
 			t.Fatalf("cross-account story detail error = %v", err)
 		}
 
@@ -236,7 +269,7 @@ func TestReaderStoreIntegration(t *testing.T) {
 		if err := adminDB.QueryRow(`SELECT count(*) FROM story_versions`).Scan(&versionsBefore); err != nil {
 			t.Fatalf("count versions before preview: %v", err)
 		}
-		preview, err := store.AdminPreview(model.AdminPreviewRequest{
+		preview, err := store.AdminPreview(t.Context(), model.AdminPreviewRequest{
 			Slug: "preview-only-story", Title: "Preview only", Markdown: "# Preview only\n\nNo rows.\n",
 		})
 		if err != nil || preview.SegmentCount != 2 {
@@ -254,24 +287,24 @@ func TestReaderStoreIntegration(t *testing.T) {
 		}
 
 		const unpublishSlug = "story-studio-unpublish"
-		unpublishDraft, err := store.AdminDraftUpsert(readerAccountA, model.AdminDraftUpsertRequest{
+		unpublishDraft, err := store.AdminDraftUpsert(t.Context(), readerAccountA, model.AdminDraftUpsertRequest{
 			Slug: unpublishSlug, Title: "Story Studio unpublish", Markdown: "# Story Studio unpublish\n\nReadable progress.\n",
 		})
 		if err != nil {
 			t.Fatalf("create unpublish fixture: %v", err)
 		}
 		t.Cleanup(func() { _, _ = adminDB.Exec(`DELETE FROM stories WHERE id = $1`, unpublishDraft.StoryID) })
-		publishedStatus, err := store.AdminPublishStory(readerAccountA, unpublishSlug, unpublishDraft.VersionID)
+		publishedStatus, err := store.AdminPublishStory(t.Context(), readerAccountA, unpublishSlug, unpublishDraft.VersionID, false)
 		if err != nil || publishedStatus.Status != model.AdminStoryStatusPublished ||
 			publishedStatus.PublishedVersion == nil || publishedStatus.PublishedVersion.VersionID != unpublishDraft.VersionID {
 			t.Fatalf("typed publication response/error = %#v / %v", publishedStatus, err)
 		}
-		publishedReader, err := store.ReaderStory(readerAccountA, unpublishSlug)
+		publishedReader, err := store.ReaderStory(t.Context(), readerAccountA, unpublishSlug)
 		if err != nil {
 			t.Fatalf("read unpublish fixture before unpublish: %v", err)
 		}
 		locator := locatorForReaderSegment(publishedReader.Segments[0], 0.4)
-		if err := store.ProgressPut(readerAccountA, unpublishSlug, publishedReader.Version, locator, 0.4); err != nil {
+		if err := store.ProgressPut(t.Context(), readerAccountA, unpublishSlug, publishedReader.Version, locator, 0.4); err != nil {
 			t.Fatalf("store progress before unpublish: %v", err)
 		}
 		var progressBefore int
@@ -279,7 +312,7 @@ func TestReaderStoreIntegration(t *testing.T) {
 			t.Fatalf("count progress before unpublish: %v", err)
 		}
 
-		unpublishedStatus, err := store.AdminUnpublish(readerAccountA, unpublishSlug)
+		unpublishedStatus, err := store.AdminUnpublish(t.Context(), readerAccountA, unpublishSlug)
 		if err != nil {
 			t.Fatalf("unpublish story: %v", err)
 		}
@@ -288,7 +321,7 @@ func TestReaderStoreIntegration(t *testing.T) {
 			unpublishedStatus.DraftVersion.VersionID != unpublishDraft.VersionID || unpublishedStatus.VersionCount != 1 {
 			t.Fatalf("unpublish response = %#v", unpublishedStatus)
 		}
-		repeatedUnpublish, err := store.AdminUnpublish(readerAccountA, unpublishSlug)
+		repeatedUnpublish, err := store.AdminUnpublish(t.Context(), readerAccountA, unpublishSlug)
 		if err != nil || !reflect.DeepEqual(repeatedUnpublish, unpublishedStatus) {
 			t.Fatalf("repeated unpublish response/error = %#v / %v; first %#v", repeatedUnpublish, err, unpublishedStatus)
 		}
@@ -312,10 +345,10 @@ func TestReaderStoreIntegration(t *testing.T) {
 			isPublished || versionCount != 1 || progressAfter != progressBefore || progressAfter != 1 {
 			t.Fatalf("unpublish persistence state = published %#v, draft %#v, active %v, versions %d, progress %d", publishedPointer, draftPointer, isPublished, versionCount, progressAfter)
 		}
-		if _, err := store.ReaderStory(readerAccountA, unpublishSlug); !errors.Is(err, sql.ErrNoRows) {
+		if _, err := store.ReaderStory(t.Context(), readerAccountA, unpublishSlug); !errors.Is(err, sql.ErrNoRows) {
 			t.Fatalf("unpublished Reader lookup error = %v", err)
 		}
-		library, err := store.Library(readerAccountA)
+		library, err := store.Library(t.Context(), readerAccountA, model.PageRequest{}, model.LibraryFilter{})
 		if err != nil {
 			t.Fatalf("library after unpublish: %v", err)
 		}
@@ -324,7 +357,7 @@ func TestReaderStoreIntegration(t *testing.T) {
 				t.Fatalf("unpublished story remained in Library: %#v", item)
 			}
 		}
-		if _, err := store.AdminUnpublish(readerAccountB, unpublishSlug); !errors.Is(err, model.ErrAdminStoryNotFound) {
+		if _, err := store.AdminUnpublish(t.Context(), readerAccountB, unpublishSlug); !errors.Is(err, model.ErrAdminStoryNotFound) {
 			t.Fatalf("cross-account unpublish error = %v", err)
 		}
 
@@ -335,7 +368,7 @@ func TestReaderStoreIntegration(t *testing.T) {
 		`, unpublishDraft.StoryID); err != nil {
 			t.Fatalf("prepare retained-version unpublished state: %v", err)
 		}
-		retained, err := store.AdminGetStory(readerAccountA, unpublishSlug)
+		retained, err := store.AdminGetStory(t.Context(), readerAccountA, unpublishSlug)
 		if err != nil || retained.Status != model.AdminStoryStatusUnpublished ||
 			retained.VersionCount != 1 || retained.DraftVersion != nil || retained.PublishedVersion != nil {
 			t.Fatalf("retained-version unpublished detail/error = %#v / %v", retained, err)
@@ -364,7 +397,7 @@ func TestReaderStoreIntegration(t *testing.T) {
 			if slug == corruptSlug {
 				markdown = fmt.Sprintf("# %s\n\n%s\n", title, privateBodyMarker)
 			}
-			draft, err := store.AdminDraftUpsert(readerAccountC, model.AdminDraftUpsertRequest{
+			draft, err := store.AdminDraftUpsert(t.Context(), readerAccountC, model.AdminDraftUpsertRequest{
 				Slug:     slug,
 				Title:    title,
 				Language: &language,
@@ -379,7 +412,7 @@ func TestReaderStoreIntegration(t *testing.T) {
 					t.Errorf("remove catalogue fixture %s: %v", storyID, err)
 				}
 			})
-			if err := store.AdminPublish(readerAccountC, slug, draft.VersionID); err != nil {
+			if err := store.AdminPublish(t.Context(), readerAccountC, slug, draft.VersionID); err != nil {
 				t.Fatalf("publish %s catalogue fixture: %v", slug, err)
 			}
 			drafts[slug] = draft
@@ -418,11 +451,11 @@ func TestReaderStoreIntegration(t *testing.T) {
 			t.Fatalf("malformed immutable frontmatter shape = type %q / title %t / language %t", frontmatterType, hasTitle, hasLanguage)
 		}
 
-		catalogue, err := store.AdminListStories(readerAccountC)
+		catalogue, err := store.AdminListStories(t.Context(), readerAccountC, model.PageRequest{})
 		if err != nil {
 			t.Fatalf("list catalogue with malformed immutable frontmatter: %v", err)
 		}
-		repeated, err := store.AdminListStories(readerAccountC)
+		repeated, err := store.AdminListStories(t.Context(), readerAccountC, model.PageRequest{})
 		if err != nil {
 			t.Fatalf("repeat catalogue with malformed immutable frontmatter: %v", err)
 		}
@@ -432,6 +465,16 @@ func TestReaderStoreIntegration(t *testing.T) {
 		if len(catalogue.Items) != len(fixtureSlugs) {
 			t.Fatalf("mixed-health catalogue has %d stories, want %d: %#v", len(catalogue.Items), len(fixtureSlugs), catalogue)
 		}
+		if catalogue.Total != nil {
+			t.Fatalf("unrequested catalogue total = %d", *catalogue.Total)
+		}
+		counted, err := store.AdminListStories(t.Context(), readerAccountC, model.PageRequest{Limit: 1, Total: true})
+		if err != nil {
+			t.Fatalf("count catalogue: %v", err)
+		}
+		if len(counted.Items) != 1 || counted.Total == nil || *counted.Total != int64(len(fixtureSlugs)) {
+			t.Fatalf("counted catalogue = %#v", counted)
+		}
 		validCount := 0
 		corruptCount := 0
 		for index, item := range catalogue.Items {
@@ -502,7 +545,7 @@ func TestReaderStoreIntegration(t *testing.T) {
 			t.Fatalf("mixed-health HTTP catalogue differs from Store result:\nHTTP: %#v\nStore: %#v", httpCatalogue, catalogue)
 		}
 
-		library, err := store.Library(readerAccountC)
+		library, err := store.Library(t.Context(), readerAccountC, model.PageRequest{}, model.LibraryFilter{})
 		if err != nil {
 			t.Fatalf("list Library with malformed immutable frontmatter: %v", err)
 		}
@@ -519,7 +562,7 @@ func TestReaderStoreIntegration(t *testing.T) {
 
 	t.Run("Story Studio repair state never follows a foreign pointer or leaks corrupt content", func(t *testing.T) {
 		const pointerSlug = "story-studio-foreign-pointer"
-		pointerDraft, err := store.AdminDraftUpsert(readerAccountA, model.AdminDraftUpsertRequest{
+		pointerDraft, err := store.AdminDraftUpsert(t.Context(), readerAccountA, model.AdminDraftUpsertRequest{
 			Slug: pointerSlug, Title: "Account A pointer story", Markdown: "# Account A pointer story\n\nSafe metadata.\n",
 		})
 		if err != nil {
@@ -533,7 +576,7 @@ func TestReaderStoreIntegration(t *testing.T) {
 		`, pointerDraft.StoryID, accountBDraft.VersionID); err != nil {
 			t.Fatalf("install cross-story pointer: %v", err)
 		}
-		pointerDetail, err := store.AdminGetStory(readerAccountA, pointerSlug)
+		pointerDetail, err := store.AdminGetStory(t.Context(), readerAccountA, pointerSlug)
 		if err != nil {
 			t.Fatalf("get foreign-pointer repair detail: %v", err)
 		}
@@ -543,7 +586,7 @@ func TestReaderStoreIntegration(t *testing.T) {
 		}
 
 		const corruptSlug = "story-studio-corrupt-version"
-		corruptDraft, err := store.AdminDraftUpsert(readerAccountA, model.AdminDraftUpsertRequest{
+		corruptDraft, err := store.AdminDraftUpsert(t.Context(), readerAccountA, model.AdminDraftUpsertRequest{
 			Slug: corruptSlug, Title: "Corrupt version", Markdown: "# Corrupt version\n\nSafe original.\n",
 		})
 		if err != nil {
@@ -558,7 +601,7 @@ func TestReaderStoreIntegration(t *testing.T) {
 		`, corruptDraft.VersionID, privateMarker); err != nil {
 			t.Fatalf("corrupt content hash: %v", err)
 		}
-		corruptDetail, err := store.AdminGetStory(readerAccountA, corruptSlug)
+		corruptDetail, err := store.AdminGetStory(t.Context(), readerAccountA, corruptSlug)
 		if err != nil {
 			t.Fatalf("get corrupt story detail: %v", err)
 		}
@@ -573,7 +616,7 @@ func TestReaderStoreIntegration(t *testing.T) {
 		if strings.Contains(string(encoded), privateMarker) || strings.Contains(string(encoded), "noncanonical persisted content") {
 			t.Fatalf("corrupt detail leaked internal content/diagnostic: %s", encoded)
 		}
-		if _, err := store.AdminGetVersionSource(readerAccountA, corruptSlug, corruptDraft.VersionID); !errors.Is(err, model.ErrAdminVersionRepairRequired) ||
+		if _, err := store.AdminGetVersionSource(t.Context(), readerAccountA, corruptSlug, corruptDraft.VersionID); !errors.Is(err, model.ErrAdminVersionRepairRequired) ||
 			strings.Contains(err.Error(), privateMarker) {
 			t.Fatalf("corrupt version source error = %v", err)
 		}
@@ -586,13 +629,13 @@ func TestReaderStoreIntegration(t *testing.T) {
 			Language: &language,
 			Markdown: "# Idempotent repair story\n\nReadable body.\n",
 		}
-		draft, err := store.AdminDraftUpsert(readerAccountA, req)
+		draft, err := store.AdminDraftUpsert(t.Context(), readerAccountA, req)
 		if err != nil {
 			t.Fatalf("insert idempotency target: %v", err)
 		}
 		t.Cleanup(func() { _, _ = adminDB.Exec(`DELETE FROM stories WHERE id = $1`, draft.StoryID) })
 
-		exactReuse, err := store.AdminDraftUpsert(readerAccountA, req)
+		exactReuse, err := store.AdminDraftUpsert(t.Context(), readerAccountA, req)
 		if err != nil {
 			t.Fatalf("reuse exact immutable version: %v", err)
 		}
@@ -624,7 +667,7 @@ func TestReaderStoreIntegration(t *testing.T) {
 			t.Run("same body changed "+metadataChange.name, func(t *testing.T) {
 				changed := req
 				metadataChange.change(&changed)
-				if _, err := store.AdminDraftUpsert(readerAccountA, changed); !errors.Is(err, model.ErrAdminVersionRepairRequired) {
+				if _, err := store.AdminDraftUpsert(t.Context(), readerAccountA, changed); !errors.Is(err, model.ErrAdminVersionRepairRequired) {
 					t.Fatalf("metadata-only reuse error = %v, want repair-required", err)
 				}
 				var (
@@ -666,7 +709,7 @@ func TestReaderStoreIntegration(t *testing.T) {
 		`, draft.StoryVersionID, strings.Repeat("f", 64)); err != nil {
 			t.Fatalf("tamper same-count idempotency target: %v", err)
 		}
-		if _, err := store.AdminDraftUpsert(readerAccountA, req); !errors.Is(err, model.ErrAdminVersionRepairRequired) {
+		if _, err := store.AdminDraftUpsert(t.Context(), readerAccountA, req); !errors.Is(err, model.ErrAdminVersionRepairRequired) {
 			t.Fatalf("same-count tampered reuse error = %v, want repair-required", err)
 		}
 		var (
@@ -697,10 +740,10 @@ func TestReaderStoreIntegration(t *testing.T) {
 			t.Fatalf("corrupt idempotency target: %v", err)
 		}
 
-		if _, err := store.AdminDraftUpsert(readerAccountA, req); !errors.Is(err, model.ErrAdminVersionRepairRequired) {
+		if _, err := store.AdminDraftUpsert(t.Context(), readerAccountA, req); !errors.Is(err, model.ErrAdminVersionRepairRequired) {
 			t.Fatalf("corrupt idempotent reuse error = %v, want repair-required", err)
 		}
-		if err := store.AdminPublish(readerAccountA, req.Slug, draft.StoryVersionID); !errors.Is(err, model.ErrAdminPublishInvalid) {
+		if err := store.AdminPublish(t.Context(), readerAccountA, req.Slug, draft.StoryVersionID); !errors.Is(err, model.ErrAdminPublishInvalid) {
 			t.Fatalf("publish corrupt idempotency target error = %v, want publish-invalid", err)
 		}
 
@@ -728,12 +771,12 @@ func TestReaderStoreIntegration(t *testing.T) {
 			Language: &language,
 			Markdown: "---\ndisplayNote: Keep this note\nlargeMeasure: 1e21\npresentation:\n  tone: calm\n---\n# Reuse additive frontmatter\n\nReadable body.\n",
 		}
-		draft, err := store.AdminDraftUpsert(readerAccountA, req)
+		draft, err := store.AdminDraftUpsert(t.Context(), readerAccountA, req)
 		if err != nil {
 			t.Fatalf("insert additive-frontmatter target: %v", err)
 		}
 		t.Cleanup(func() { _, _ = adminDB.Exec(`DELETE FROM stories WHERE id = $1`, draft.StoryID) })
-		exact, err := store.AdminDraftUpsert(readerAccountA, req)
+		exact, err := store.AdminDraftUpsert(t.Context(), readerAccountA, req)
 		if err != nil {
 			t.Fatalf("reuse exact additive frontmatter: %v", err)
 		}
@@ -743,7 +786,7 @@ func TestReaderStoreIntegration(t *testing.T) {
 
 		changed := req
 		changed.Markdown = strings.Replace(req.Markdown, "Keep this note", "Different note", 1)
-		if _, err := store.AdminDraftUpsert(readerAccountA, changed); !errors.Is(err, model.ErrAdminVersionRepairRequired) {
+		if _, err := store.AdminDraftUpsert(t.Context(), readerAccountA, changed); !errors.Is(err, model.ErrAdminVersionRepairRequired) {
 			t.Fatalf("changed additive-frontmatter reuse error = %v, want repair-required", err)
 		}
 		var pointer string
@@ -762,7 +805,7 @@ func TestReaderStoreIntegration(t *testing.T) {
 			Language: &language,
 			Markdown: "# Reuse optional author\n\nReadable body.\n",
 		}
-		draft, err := store.AdminDraftUpsert(readerAccountA, req)
+		draft, err := store.AdminDraftUpsert(t.Context(), readerAccountA, req)
 		if err != nil {
 			t.Fatalf("insert optional-author target: %v", err)
 		}
@@ -780,7 +823,7 @@ func TestReaderStoreIntegration(t *testing.T) {
 				if _, err := adminDB.Exec(variant.statement, draft.StoryVersionID); err != nil {
 					t.Fatalf("set %s optional author: %v", variant.name, err)
 				}
-				exact, err := store.AdminDraftUpsert(readerAccountA, req)
+				exact, err := store.AdminDraftUpsert(t.Context(), readerAccountA, req)
 				if err != nil {
 					t.Fatalf("reuse %s optional author: %v", variant.name, err)
 				}
@@ -977,14 +1020,14 @@ func TestReaderStoreIntegration(t *testing.T) {
 					Language: &language,
 					Markdown: "# Persisted mismatch target\n\nFirst paragraph.\n\nSecond paragraph.\n",
 				}
-				draft, err := store.AdminDraftUpsert(readerAccountA, req)
+				draft, err := store.AdminDraftUpsert(t.Context(), readerAccountA, req)
 				if err != nil {
 					t.Fatalf("insert persisted mismatch target: %v", err)
 				}
 				t.Cleanup(func() { _, _ = adminDB.Exec(`DELETE FROM stories WHERE id = $1`, draft.StoryID) })
 				test.mutate(t, draft)
 
-				if _, err := store.AdminDraftUpsert(readerAccountA, req); !errors.Is(err, model.ErrAdminVersionRepairRequired) {
+				if _, err := store.AdminDraftUpsert(t.Context(), readerAccountA, req); !errors.Is(err, model.ErrAdminVersionRepairRequired) {
 					t.Fatalf("corrupt reuse error = %v, want repair-required", err)
 				}
 				var (
@@ -1011,7 +1054,7 @@ func TestReaderStoreIntegration(t *testing.T) {
 			Language: &language,
 			Markdown: "# Reuse version lock\n\nReadable body.\n",
 		}
-		draft, err := store.AdminDraftUpsert(readerAccountA, req)
+		draft, err := store.AdminDraftUpsert(t.Context(), readerAccountA, req)
 		if err != nil {
 			t.Fatalf("insert reuse lock target: %v", err)
 		}
@@ -1039,7 +1082,7 @@ func TestReaderStoreIntegration(t *testing.T) {
 		lockingStore := newReaderIntegrationStoreWithApplicationName(t, databaseURL, reuseApplicationName)
 		reuseResult := make(chan error, 1)
 		go func() {
-			_, err := lockingStore.AdminDraftUpsert(readerAccountA, req)
+			_, err := lockingStore.AdminDraftUpsert(t.Context(), readerAccountA, req)
 			reuseResult <- err
 		}()
 
@@ -1101,7 +1144,7 @@ func TestReaderStoreIntegration(t *testing.T) {
 			Language: &language,
 			Markdown: "# Reuse segment lock\n\nReadable body.\n",
 		}
-		draft, err := store.AdminDraftUpsert(readerAccountA, req)
+		draft, err := store.AdminDraftUpsert(t.Context(), readerAccountA, req)
 		if err != nil {
 			t.Fatalf("insert reuse segment-lock target: %v", err)
 		}
@@ -1129,7 +1172,7 @@ func TestReaderStoreIntegration(t *testing.T) {
 		lockingStore := newReaderIntegrationStoreWithApplicationName(t, databaseURL, reuseApplicationName)
 		reuseResult := make(chan error, 1)
 		go func() {
-			_, err := lockingStore.AdminDraftUpsert(readerAccountA, req)
+			_, err := lockingStore.AdminDraftUpsert(t.Context(), readerAccountA, req)
 			reuseResult <- err
 		}()
 
@@ -1184,9 +1227,54 @@ func TestReaderStoreIntegration(t *testing.T) {
 		}
 	})
 
+	t.Run("new drafts count segments unchanged from the previous draft", func(t *testing.T) {
+		const slug = "segment-reuse-story"
+		first, err := store.AdminDraftUpsert(t.Context(), readerAccountA, model.AdminDraftUpsertRequest{
+			Slug:     slug,
+			Title:    "Segment reuse",
+			Language: &language,
+			Markdown: "# Segment reuse\n\nKept paragraph.\n\nOld ending.\n",
+		})
+		if err != nil {
+			t.Fatalf("insert segment reuse v1: %v", err)
+		}
+		t.Cleanup(func() { _, _ = adminDB.Exec(`DELETE FROM stories WHERE id = $1`, first.StoryID) })
+		if first.UnchangedSegments != 0 {
+			t.Fatalf("first draft has %d unchanged segments", first.UnchangedSegments)
+		}
+		second, err := store.AdminDraftUpsert(t.Context(), readerAccountA, model.AdminDraftUpsertRequest{
+			Slug:     slug,
+			Title:    "Segment reuse",
+			Language: &language,
+			Markdown: "# Segment reuse\n\nNew opening.\n\nKept paragraph.\n\nNew ending.\n",
+		})
+		if err != nil {
+			t.Fatalf("insert segment reuse v2: %v", err)
+		}
+		if second.Version != 2 || second.SegmentCount != 4 || second.UnchangedSegments != 2 {
+			t.Fatalf("second draft = %#v, want four segments with two unchanged", second)
+		}
+		var moved int
+		if err := adminDB.QueryRow(`
+			SELECT ordinal
+			FROM story_segments
+			WHERE story_version_id = $1
+			  AND markdown = 'Kept paragraph.'
+			  AND content_hash IS NOT NULL
+		`, second.StoryVersionID).Scan(&moved); err != nil {
+			t.Fatalf("read unchanged segment: %v", err)
+		}
+		if moved != 3 {
+			t.Fatalf("unchanged segment ordinal = %d, want 3", moved)
+		}
+		if _, err := validateStoredReaderVersion(context.Background(), store.db, second.StoryID, second.StoryVersionID, slug); err != nil {
+			t.Fatalf("second version does not validate: %v", err)
+		}
+	})
+
 	t.Run("publication validates immutable metadata identities and readable content atomically", func(t *testing.T) {
 		const slug = "publication-validation-story"
-		first, err := store.AdminDraftUpsert(readerAccountA, model.AdminDraftUpsertRequest{
+		first, err := store.AdminDraftUpsert(t.Context(), readerAccountA, model.AdminDraftUpsertRequest{
 			Slug:     slug,
 			Title:    "Publication validation v1",
 			Language: &language,
@@ -1196,10 +1284,10 @@ func TestReaderStoreIntegration(t *testing.T) {
 			t.Fatalf("insert publication validation v1: %v", err)
 		}
 		t.Cleanup(func() { _, _ = adminDB.Exec(`DELETE FROM stories WHERE id = $1`, first.StoryID) })
-		if err := store.AdminPublish(readerAccountA, slug, first.StoryVersionID); err != nil {
+		if err := store.AdminPublish(t.Context(), readerAccountA, slug, first.StoryVersionID); err != nil {
 			t.Fatalf("publish validation v1: %v", err)
 		}
-		second, err := store.AdminDraftUpsert(readerAccountA, model.AdminDraftUpsertRequest{
+		second, err := store.AdminDraftUpsert(t.Context(), readerAccountA, model.AdminDraftUpsertRequest{
 			Slug:     slug,
 			Title:    "Publication validation v2",
 			Language: &language,
@@ -1259,7 +1347,7 @@ func TestReaderStoreIntegration(t *testing.T) {
 				); err != nil {
 					t.Fatalf("mutate %s: %v", mutation.name, err)
 				}
-				if err := store.AdminPublish(readerAccountA, slug, second.StoryVersionID); !errors.Is(err, model.ErrAdminPublishInvalid) {
+				if err := store.AdminPublish(t.Context(), readerAccountA, slug, second.StoryVersionID); !errors.Is(err, model.ErrAdminPublishInvalid) {
 					t.Fatalf("publish noncanonical %s error = %v, want publish-invalid", mutation.name, err)
 				}
 				assertPublishedPointer(first.StoryVersionID)
@@ -1292,7 +1380,7 @@ func TestReaderStoreIntegration(t *testing.T) {
 				`, second.StoryVersionID, mutation.path, mutation.badValue); err != nil {
 					t.Fatalf("mutate %s: %v", mutation.name, err)
 				}
-				if err := store.AdminPublish(readerAccountA, slug, second.StoryVersionID); !errors.Is(err, model.ErrAdminPublishInvalid) {
+				if err := store.AdminPublish(t.Context(), readerAccountA, slug, second.StoryVersionID); !errors.Is(err, model.ErrAdminPublishInvalid) {
 					t.Fatalf("publish %s error = %v, want publish-invalid", mutation.name, err)
 				}
 				assertPublishedPointer(first.StoryVersionID)
@@ -1338,12 +1426,12 @@ func TestReaderStoreIntegration(t *testing.T) {
 				); err != nil {
 					t.Fatalf("mutate %s: %v", mutation.name, err)
 				}
-				if err := store.AdminPublish(readerAccountA, slug, second.StoryVersionID); !errors.Is(err, model.ErrAdminPublishInvalid) {
+				if err := store.AdminPublish(t.Context(), readerAccountA, slug, second.StoryVersionID); !errors.Is(err, model.ErrAdminPublishInvalid) {
 					t.Fatalf("publish noncanonical %s error = %v, want publish-invalid", mutation.name, err)
 				}
 				assertPublishedPointer(first.StoryVersionID)
 				if mutation.assertReader {
-					readerStory, err := store.ReaderStory(readerAccountA, slug)
+					readerStory, err := store.ReaderStory(t.Context(), readerAccountA, slug)
 					if err != nil {
 						t.Fatalf("read prior safe publication after %s refusal: %v", mutation.name, err)
 					}
@@ -1398,11 +1486,11 @@ func TestReaderStoreIntegration(t *testing.T) {
 				t.Fatalf("insert raw-HTML-only segment: %v", err)
 			}
 
-			if err := store.AdminPublish(readerAccountA, slug, rawVersionID); !errors.Is(err, model.ErrAdminPublishInvalid) {
+			if err := store.AdminPublish(t.Context(), readerAccountA, slug, rawVersionID); !errors.Is(err, model.ErrAdminPublishInvalid) {
 				t.Fatalf("publish raw-HTML-only version error = %v, want publish-invalid", err)
 			}
 			assertPublishedPointer(first.StoryVersionID)
-			readerStory, err := store.ReaderStory(readerAccountA, slug)
+			readerStory, err := store.ReaderStory(t.Context(), readerAccountA, slug)
 			if err != nil {
 				t.Fatalf("read prior safe publication after raw-only refusal: %v", err)
 			}
@@ -1414,7 +1502,7 @@ func TestReaderStoreIntegration(t *testing.T) {
 		if _, err := adminDB.Exec(`UPDATE story_versions SET frontmatter = frontmatter - 'title' WHERE id = $1`, second.StoryVersionID); err != nil {
 			t.Fatalf("remove immutable title: %v", err)
 		}
-		if err := store.AdminPublish(readerAccountA, slug, second.StoryVersionID); !errors.Is(err, model.ErrAdminPublishInvalid) {
+		if err := store.AdminPublish(t.Context(), readerAccountA, slug, second.StoryVersionID); !errors.Is(err, model.ErrAdminPublishInvalid) {
 			t.Fatalf("publish missing immutable title error = %v, want publish-invalid", err)
 		}
 		assertPublishedPointer(first.StoryVersionID)
@@ -1433,7 +1521,7 @@ func TestReaderStoreIntegration(t *testing.T) {
 		`, second.StoryVersionID); err != nil {
 			t.Fatalf("corrupt chapter propagation: %v", err)
 		}
-		if err := store.AdminPublish(readerAccountA, slug, second.StoryVersionID); !errors.Is(err, model.ErrAdminPublishInvalid) {
+		if err := store.AdminPublish(t.Context(), readerAccountA, slug, second.StoryVersionID); !errors.Is(err, model.ErrAdminPublishInvalid) {
 			t.Fatalf("publish invalid chapter identity error = %v, want publish-invalid", err)
 		}
 		assertPublishedPointer(first.StoryVersionID)
@@ -1470,7 +1558,7 @@ func TestReaderStoreIntegration(t *testing.T) {
 		lockingStore := newReaderIntegrationStoreWithApplicationName(t, databaseURL, publishApplicationName)
 		publishResult := make(chan error, 1)
 		go func() {
-			publishResult <- lockingStore.AdminPublish(readerAccountA, slug, second.StoryVersionID)
+			publishResult <- lockingStore.AdminPublish(t.Context(), readerAccountA, slug, second.StoryVersionID)
 		}()
 		lockDeadline := time.Now().Add(5 * time.Second)
 		lockObserved := false
@@ -1520,18 +1608,18 @@ func TestReaderStoreIntegration(t *testing.T) {
 			t.Fatalf("restore rendered segment: %v", err)
 		}
 
-		if err := store.AdminPublish(readerAccountA, slug, accountBDraft.StoryVersionID); !errors.Is(err, model.ErrAdminPublishNotFound) {
+		if err := store.AdminPublish(t.Context(), readerAccountA, slug, accountBDraft.StoryVersionID); !errors.Is(err, model.ErrAdminPublishNotFound) {
 			t.Fatalf("cross-account version publish error = %v, want existing not-found semantics", err)
 		}
 		assertPublishedPointer(first.StoryVersionID)
-		if err := store.AdminPublish(readerAccountA, slug, second.StoryVersionID); err != nil {
+		if err := store.AdminPublish(t.Context(), readerAccountA, slug, second.StoryVersionID); err != nil {
 			t.Fatalf("publish restored validation v2: %v", err)
 		}
 		assertPublishedPointer(second.StoryVersionID)
 	})
 
 	t.Run("ingestion assigns six ordered identities and H2 chapters", func(t *testing.T) {
-		story, err := store.ReaderStory(readerAccountA, readerSlug)
+		story, err := store.ReaderStory(t.Context(), readerAccountA, readerSlug)
 		if err != nil {
 			t.Fatalf("ReaderStory: %v", err)
 		}
@@ -1592,7 +1680,7 @@ func TestReaderStoreIntegration(t *testing.T) {
 			}
 		}()
 		for range 150 {
-			story, err := store.ReaderStory(readerAccountA, readerSlug)
+			story, err := store.ReaderStory(t.Context(), readerAccountA, readerSlug)
 			if err != nil {
 				close(stop)
 				wg.Wait()
@@ -1614,13 +1702,13 @@ func TestReaderStoreIntegration(t *testing.T) {
 			t.Fatalf("republish loop: %v", err)
 		default:
 		}
-		if err := store.AdminPublish(readerAccountA, readerSlug, firstDraft.StoryVersionID); err != nil {
+		if err := store.AdminPublish(t.Context(), readerAccountA, readerSlug, firstDraft.StoryVersionID); err != nil {
 			t.Fatalf("restore publication after race: %v", err)
 		}
 	})
 
 	t.Run("account and publication boundaries return not found", func(t *testing.T) {
-		accountBStory, err := store.ReaderStory(readerAccountB, readerSlug)
+		accountBStory, err := store.ReaderStory(t.Context(), readerAccountB, readerSlug)
 		if err != nil {
 			t.Fatalf("ReaderStory account B: %v", err)
 		}
@@ -1635,13 +1723,13 @@ func TestReaderStoreIntegration(t *testing.T) {
 			{account: readerAccountA, slug: "unpublished-reader-story"},
 			{account: readerAccountA, slug: "missing-reader-story"},
 		} {
-			if _, err := store.ReaderStory(test.account, test.slug); !errors.Is(err, sql.ErrNoRows) {
+			if _, err := store.ReaderStory(t.Context(), test.account, test.slug); !errors.Is(err, sql.ErrNoRows) {
 				t.Fatalf("ReaderStory(%s, %s) error = %v, want sql.ErrNoRows", test.account, test.slug, err)
 			}
 		}
 	})
 
-	story, err := store.ReaderStory(readerAccountA, readerSlug)
+	story, err := store.ReaderStory(t.Context(), readerAccountA, readerSlug)
 	if err != nil {
 		t.Fatalf("load progress target: %v", err)
 	}
@@ -1650,17 +1738,17 @@ func TestReaderStoreIntegration(t *testing.T) {
 	draftLocator := locatorForStoredReaderSegment(t, adminDB, secondDraft.StoryVersionID, 2, 0.6)
 
 	t.Run("progress validates the exact selected version identity", func(t *testing.T) {
-		empty, err := store.ProgressGet(readerAccountA, readerSlug)
+		empty, err := store.ProgressGet(t.Context(), readerAccountA, readerSlug)
 		if err != nil {
 			t.Fatalf("ProgressGet empty: %v", err)
 		}
 		if empty.Progress != nil {
 			t.Fatalf("empty progress = %#v", empty.Progress)
 		}
-		if err := store.ProgressPut(readerAccountA, readerSlug, story.Version, locator, 0.42); err != nil {
+		if err := store.ProgressPut(t.Context(), readerAccountA, readerSlug, story.Version, locator, 0.42); err != nil {
 			t.Fatalf("ProgressPut valid: %v", err)
 		}
-		got, err := store.ProgressGet(readerAccountA, readerSlug)
+		got, err := store.ProgressGet(t.Context(), readerAccountA, readerSlug)
 		if err != nil {
 			t.Fatalf("ProgressGet saved: %v", err)
 		}
@@ -1675,50 +1763,50 @@ func TestReaderStoreIntegration(t *testing.T) {
 		mismatches[2].Segment.Ordinal++
 		mismatches[3].Chapter = nil
 		for index, mismatch := range mismatches {
-			if err := store.ProgressPut(readerAccountA, readerSlug, story.Version, mismatch, 0.9); !errors.Is(err, readercontract.ErrLocatorMismatch) {
+			if err := store.ProgressPut(t.Context(), readerAccountA, readerSlug, story.Version, mismatch, 0.9); !errors.Is(err, readercontract.ErrLocatorMismatch) {
 				t.Fatalf("mismatch %d error = %v", index, err)
 			}
 		}
-		if err := store.ProgressPut(readerAccountA, readerSlug, 99, locator, 0.2); !errors.Is(err, sql.ErrNoRows) {
+		if err := store.ProgressPut(t.Context(), readerAccountA, readerSlug, 99, locator, 0.2); !errors.Is(err, sql.ErrNoRows) {
 			t.Fatalf("wrong version error = %v, want sql.ErrNoRows", err)
 		}
-		if err := store.ProgressPut(readerAccountC, readerSlug, story.Version, locator, 0.2); !errors.Is(err, sql.ErrNoRows) {
+		if err := store.ProgressPut(t.Context(), readerAccountC, readerSlug, story.Version, locator, 0.2); !errors.Is(err, sql.ErrNoRows) {
 			t.Fatalf("cross-account error = %v, want sql.ErrNoRows", err)
 		}
 	})
 
 	t.Run("draft and previously published versions cannot replace current progress", func(t *testing.T) {
-		if err := store.ProgressPut(readerAccountA, readerSlug, secondDraft.Version, draftLocator, 0.81); !errors.Is(err, sql.ErrNoRows) {
+		if err := store.ProgressPut(t.Context(), readerAccountA, readerSlug, secondDraft.Version, draftLocator, 0.81); !errors.Is(err, sql.ErrNoRows) {
 			t.Fatalf("draft version ProgressPut error = %v, want sql.ErrNoRows", err)
 		}
-		got, err := store.ProgressGet(readerAccountA, readerSlug)
+		got, err := store.ProgressGet(t.Context(), readerAccountA, readerSlug)
 		if err != nil {
 			t.Fatalf("ProgressGet after draft rejection: %v", err)
 		}
 		assertProgressState(t, got, firstDraft.Version, locator, 0.42)
 
-		if err := store.AdminPublish(readerAccountA, readerSlug, secondDraft.StoryVersionID); err != nil {
+		if err := store.AdminPublish(t.Context(), readerAccountA, readerSlug, secondDraft.StoryVersionID); err != nil {
 			t.Fatalf("publish second Reader version: %v", err)
 		}
-		if err := store.ProgressPut(readerAccountA, readerSlug, firstDraft.Version, locator, 0.82); !errors.Is(err, sql.ErrNoRows) {
+		if err := store.ProgressPut(t.Context(), readerAccountA, readerSlug, firstDraft.Version, locator, 0.82); !errors.Is(err, sql.ErrNoRows) {
 			t.Fatalf("previous version ProgressPut error = %v, want sql.ErrNoRows", err)
 		}
-		got, err = store.ProgressGet(readerAccountA, readerSlug)
+		got, err = store.ProgressGet(t.Context(), readerAccountA, readerSlug)
 		if err != nil {
 			t.Fatalf("ProgressGet after previous-version rejection: %v", err)
 		}
 		assertProgressState(t, got, firstDraft.Version, locator, 0.42)
 
-		if err := store.ProgressPut(readerAccountA, readerSlug, secondDraft.Version, draftLocator, 0.83); err != nil {
+		if err := store.ProgressPut(t.Context(), readerAccountA, readerSlug, secondDraft.Version, draftLocator, 0.83); err != nil {
 			t.Fatalf("current second-version ProgressPut: %v", err)
 		}
-		got, err = store.ProgressGet(readerAccountA, readerSlug)
+		got, err = store.ProgressGet(t.Context(), readerAccountA, readerSlug)
 		if err != nil {
 			t.Fatalf("ProgressGet second version: %v", err)
 		}
 		assertProgressState(t, got, secondDraft.Version, draftLocator, 0.83)
 
-		if err := store.AdminPublish(readerAccountA, readerSlug, firstDraft.StoryVersionID); err != nil {
+		if err := store.AdminPublish(t.Context(), readerAccountA, readerSlug, firstDraft.StoryVersionID); err != nil {
 			t.Fatalf("restore first publication: %v", err)
 		}
 	})
@@ -1727,7 +1815,7 @@ func TestReaderStoreIntegration(t *testing.T) {
 		if _, err := adminDB.Exec(`DELETE FROM reading_progress WHERE story_id = $1`, firstDraft.StoryID); err != nil {
 			t.Fatalf("clear progress before lock test: %v", err)
 		}
-		if err := store.AdminPublish(readerAccountA, readerSlug, firstDraft.StoryVersionID); err != nil {
+		if err := store.AdminPublish(t.Context(), readerAccountA, readerSlug, firstDraft.StoryVersionID); err != nil {
 			t.Fatalf("publish first version before lock test: %v", err)
 		}
 
@@ -1758,7 +1846,7 @@ func TestReaderStoreIntegration(t *testing.T) {
 		lockingStore := newReaderIntegrationStoreWithApplicationName(t, databaseURL, progressApplicationName)
 		progressResult := make(chan error, 1)
 		go func() {
-			progressResult <- lockingStore.ProgressPut(readerAccountA, readerSlug, firstDraft.Version, locator, 0.91)
+			progressResult <- lockingStore.ProgressPut(t.Context(), readerAccountA, readerSlug, firstDraft.Version, locator, 0.91)
 		}()
 
 		lockDeadline := time.Now().Add(5 * time.Second)
@@ -1814,11 +1902,568 @@ func TestReaderStoreIntegration(t *testing.T) {
 			t.Fatalf("stale progress rows = %d, want 0", staleProgressCount)
 		}
 
-		if err := store.AdminPublish(readerAccountA, readerSlug, firstDraft.StoryVersionID); err != nil {
+		if err := store.AdminPublish(t.Context(), readerAccountA, readerSlug, firstDraft.StoryVersionID); err != nil {
 			t.Fatalf("restore first publication after lock test: %v", err)
 		}
 	})
 
+	t.Run("moderation verdicts gate publication", func(t *testing.T) {
+		const slug = "moderation-gate"
+		provider := "openai"
+		draft, err := store.AdminDraftUpsert(t.Context(), readerAccountA, model.AdminDraftUpsertRequest{
+			Slug:     slug,
+			Title:    "Moderation gate",
+			Markdown: "# Moderation gate\n\nA storm at sea.\n",
+			Moderation: &model.StoryModeration{
+				Verdict:     model.ModerationBlocked,
+				Provider:    &provider,
+				Findings:    []model.ModerationFinding{{Source: model.ModerationSourceProvider, Category: "violence"}},
+				ModeratedAt: time.Now().UTC().Format(time.RFC3339Nano),
+			},
+		})
+		if err != nil {
+			t.Fatalf("insert moderated draft: %v", err)
+		}
+		t.Cleanup(func() { _, _ = adminDB.Exec(`DELETE FROM stories WHERE id = $1`, draft.StoryID) })
+
+		if _, err := store.AdminPublishStory(t.Context(), readerAccountA, slug, draft.VersionID, true); !errors.Is(err, model.ErrModerationBlocked) {
+			t.Fatalf("publish blocked version error = %v, want ErrModerationBlocked", err)
+		}
+
+		// Reusing the version with a new verdict replaces the old one.
+		reused, err := store.AdminDraftUpsert(t.Context(), readerAccountA, model.AdminDraftUpsertRequest{
+			Slug:     slug,
+			Title:    "Moderation gate",
+			Markdown: "# Moderation gate\n\nA storm at sea.\n",
+			Moderation: &model.StoryModeration{
+				Verdict:     model.ModerationFlagged,
+				Findings:    []model.ModerationFinding{{Source: "child_profile", Category: "storm", Count: 1}},
+				ModeratedAt: time.Now().UTC().Format(time.RFC3339Nano),
+			},
+		})
+		if err != nil || reused.VersionID != draft.VersionID {
+			t.Fatalf("reuse moderated draft = %#v, %v", reused, err)
+		}
+		if _, err := store.AdminPublishStory(t.Context(), readerAccountA, slug, draft.VersionID, false); !errors.Is(err, model.ErrModerationUnacknowledged) {
+			t.Fatalf("publish flagged version error = %v, want ErrModerationUnacknowledged", err)
+		}
+		source, err := store.AdminGetVersionSource(t.Context(), readerAccountA, slug, draft.VersionID)
+		if err != nil {
+			t.Fatalf("AdminVersionSource: %v", err)
+		}
+		if source.Moderation == nil || source.Moderation.Verdict != model.ModerationFlagged || source.Moderation.Provider != nil ||
+			len(source.Moderation.Findings) != 1 || source.Moderation.Findings[0].Category != "storm" {
+			t.Fatalf("version source moderation = %#v", source.Moderation)
+		}
+		if _, err := store.AdminPublishStory(t.Context(), readerAccountA, slug, draft.VersionID, true); err != nil {
+			t.Fatalf("publish acknowledged version: %v", err)
+		}
+	})
+
+	t.Run("up-next queue keeps the chosen order", func(t *testing.T) {
+		slugs := []string{"queue-first", "queue-second"}
+		for _, slug := range slugs {
+			draft, err := store.AdminDraftUpsert(t.Context(), readerAccountA, model.AdminDraftUpsertRequest{
+				Slug:     slug,
+				Title:    "Queued " + slug,
+				Markdown: "# Queued\n\nA story to read next.\n",
+			})
+			if err != nil {
+				t.Fatalf("insert %s: %v", slug, err)
+			}
+			t.Cleanup(func() { _, _ = adminDB.Exec(`DELETE FROM stories WHERE id = $1`, draft.StoryID) })
+			if err := store.AdminPublish(t.Context(), readerAccountA, slug, draft.VersionID); err != nil {
+				t.Fatalf("publish %s: %v", slug, err)
+			}
+			if _, added, err := store.QueueAdd(t.Context(), readerAccountA, slug); err != nil || !added {
+				t.Fatalf("QueueAdd %s = %v, %v", slug, added, err)
+			}
+		}
+		if _, added, err := store.QueueAdd(t.Context(), readerAccountA, slugs[0]); err != nil || added {
+			t.Fatalf("QueueAdd again = %v, %v", added, err)
+		}
+		if _, _, err := store.QueueAdd(t.Context(), readerAccountB, slugs[0]); !errors.Is(err, sql.ErrNoRows) {
+			t.Fatalf("cross-account QueueAdd error = %v, want sql.ErrNoRows", err)
+		}
+		if _, err := store.QueueReorder(t.Context(), readerAccountA, slugs[:1]); !errors.Is(err, model.ErrQueueOrder) {
+			t.Fatalf("partial QueueReorder error = %v, want ErrQueueOrder", err)
+		}
+		items, err := store.QueueReorder(t.Context(), readerAccountA, []string{slugs[1], slugs[0]})
+		if err != nil || len(items) != 2 || items[0].Slug != slugs[1] || items[1].Title != "Queued "+slugs[0] {
+			t.Fatalf("QueueReorder = %#v, %v", items, err)
+		}
+
+		if _, err := store.AdminUnpublish(t.Context(), readerAccountA, slugs[1]); err != nil {
+			t.Fatalf("unpublish %s: %v", slugs[1], err)
+		}
+		items, err = store.Queue(t.Context(), readerAccountA)
+		if err != nil || len(items) != 1 || items[0].Slug != slugs[0] {
+			t.Fatalf("Queue after unpublish = %#v, %v", items, err)
+		}
+		if items, err := store.QueueRemove(t.Context(), readerAccountA, slugs[0]); err != nil || len(items) != 0 {
+			t.Fatalf("QueueRemove = %#v, %v", items, err)
+		}
+		if _, err := store.QueueRemove(t.Context(), readerAccountA, slugs[0]); !errors.Is(err, model.ErrQueueItemNotFound) {
+			t.Fatalf("QueueRemove again error = %v, want ErrQueueItemNotFound", err)
+		}
+	})
+
+	t.Run("reactions replace each other and are counted in the Library", func(t *testing.T) {
+		const slug = "reaction-story"
+		draft, err := store.AdminDraftUpsert(t.Context(), readerAccountA, model.AdminDraftUpsertRequest{
+			Slug:     slug,
+			Title:    "Reacted",
+			Markdown: "# Reacted\n\nA story to feel something about.\n",
+		})
+		if err != nil {
+			t.Fatalf("insert %s: %v", slug, err)
+		}
+		t.Cleanup(func() { _, _ = adminDB.Exec(`DELETE FROM stories WHERE id = $1`, draft.StoryID) })
+		if err := store.AdminPublish(t.Context(), readerAccountA, slug, draft.VersionID); err != nil {
+			t.Fatalf("publish %s: %v", slug, err)
+		}
+
+		silly, lovedIt := model.ReactionSilly, model.ReactionLovedIt
+		if _, err := store.SetStoryReaction(t.Context(), readerAccountA, slug, &silly); err != nil {
+			t.Fatalf("SetStoryReaction silly: %v", err)
+		}
+		out, err := store.SetStoryReaction(t.Context(), readerAccountA, slug, &lovedIt)
+		if err != nil || out.Counts != (model.ReactionCounts{LovedIt: 1}) {
+			t.Fatalf("SetStoryReaction loved_it = %#v, %v", out, err)
+		}
+		if _, err := store.SetStoryReaction(t.Context(), readerAccountB, slug, &silly); !errors.Is(err, sql.ErrNoRows) {
+			t.Fatalf("cross-account SetStoryReaction error = %v, want sql.ErrNoRows", err)
+		}
+
+		library, err := store.Library(t.Context(), readerAccountA, model.PageRequest{}, model.LibraryFilter{})
+		if err != nil {
+			t.Fatalf("Library: %v", err)
+		}
+		index := slices.IndexFunc(library.Items, func(item model.StoryItem) bool { return item.Slug == slug })
+		if index < 0 || library.Items[index].Reactions == nil || *library.Items[index].Reactions != (model.ReactionCounts{LovedIt: 1}) {
+			t.Fatalf("Library reactions = %#v", library.Items)
+		}
+
+		out, err = store.SetStoryReaction(t.Context(), readerAccountA, slug, nil)
+		if err != nil || out.Reaction != nil || out.Counts != (model.ReactionCounts{}) {
+			t.Fatalf("SetStoryReaction nil = %#v, %v", out, err)
+		}
+		if _, err := store.AdminUnpublish(t.Context(), readerAccountA, slug); err != nil {
+			t.Fatalf("unpublish %s: %v", slug, err)
+		}
+		if _, err := store.SetStoryReaction(t.Context(), readerAccountA, slug, &silly); !errors.Is(err, model.ErrStoryRemoved) {
+			t.Fatalf("SetStoryReaction after unpublish error = %v, want ErrStoryRemoved", err)
+		}
+	})
+
+	t.Run("discussion questions follow the published version", func(t *testing.T) {
+		const slug = "discussion-story"
+		draft, err := store.AdminDraftUpsert(t.Context(), readerAccountA, model.AdminDraftUpsertRequest{
+			Slug:     slug,
+			Title:    "The Fox",
+			Markdown: "# The Fox\n\nThe fox was sad.\n",
+		})
+		if err != nil {
+			t.Fatalf("insert %s: %v", slug, err)
+		}
+		t.Cleanup(func() { _, _ = adminDB.Exec(`DELETE FROM stories WHERE id = $1`, draft.StoryID) })
+
+		saved, err := store.AdminSaveDiscussion(t.Context(), readerAccountA, slug, draft.VersionID, 1, []string{"Why do you think the fox was sad?"})
+		if err != nil || saved.Chapter != 1 || saved.UpdatedAt == nil {
+			t.Fatalf("AdminSaveDiscussion = %#v, %v", saved, err)
+		}
+		if _, err := store.AdminSaveDiscussion(t.Context(), readerAccountA, slug, draft.VersionID, 2, []string{"Why?"}); !errors.Is(err, model.ErrQuizChapterNotFound) {
+			t.Fatalf("AdminSaveDiscussion chapter 2 error = %v, want ErrQuizChapterNotFound", err)
+		}
+		if _, err := store.ReaderDiscussion(t.Context(), readerAccountA, slug); !errors.Is(err, sql.ErrNoRows) {
+			t.Fatalf("unpublished ReaderDiscussion error = %v, want sql.ErrNoRows", err)
+		}
+
+		if err := store.AdminPublish(t.Context(), readerAccountA, slug, draft.VersionID); err != nil {
+			t.Fatalf("publish %s: %v", slug, err)
+		}
+		discussion, err := store.ReaderDiscussion(t.Context(), readerAccountA, slug)
+		if err != nil || len(discussion.Chapters) != 1 || discussion.Chapters[0].Questions[0] != "Why do you think the fox was sad?" {
+			t.Fatalf("ReaderDiscussion = %#v, %v", discussion, err)
+		}
+		if _, err := store.ReaderDiscussion(t.Context(), readerAccountB, slug); !errors.Is(err, sql.ErrNoRows) {
+			t.Fatalf("cross-account ReaderDiscussion error = %v, want sql.ErrNoRows", err)
+		}
+
+		if err := store.AdminDeleteDiscussion(t.Context(), readerAccountA, slug, draft.VersionID, 1); err != nil {
+			t.Fatalf("AdminDeleteDiscussion: %v", err)
+		}
+		if err := store.AdminDeleteDiscussion(t.Context(), readerAccountA, slug, draft.VersionID, 1); !errors.Is(err, model.ErrDiscussionNotFound) {
+			t.Fatalf("AdminDeleteDiscussion again error = %v, want ErrDiscussionNotFound", err)
+		}
+		admin, err := store.AdminGetDiscussion(t.Context(), readerAccountA, slug, draft.VersionID)
+		if err != nil || len(admin.Chapters) != 1 || len(admin.Chapters[0].Questions) != 0 || admin.Chapters[0].UpdatedAt != nil {
+			t.Fatalf("AdminGetDiscussion = %#v, %v", admin, err)
+		}
+	})
+
+	t.Run("narration copies recordings of unchanged segments", func(t *testing.T) {
+		const slug = "narration-reuse-story"
+		narrate := func(versionID string) model.NarrationJob {
+			t.Helper()
+			job, err := store.AdminStartNarrationJob(t.Context(), readerAccountA, slug, versionID, "alloy")
+			if err != nil {
+				t.Fatalf("AdminStartNarrationJob: %v", err)
+			}
+			if _, err := adminDB.Exec(`UPDATE narration_jobs SET status = 'running' WHERE id = $1`, job.ID); err != nil {
+				t.Fatalf("run narration job: %v", err)
+			}
+			return job
+		}
+		first, err := store.AdminDraftUpsert(t.Context(), readerAccountA, model.AdminDraftUpsertRequest{
+			Slug:     slug,
+			Title:    "Narrated",
+			Markdown: "# Narrated\n\nKept paragraph.\n\nOld ending.\n",
+		})
+		if err != nil {
+			t.Fatalf("insert %s v1: %v", slug, err)
+		}
+		t.Cleanup(func() { _, _ = adminDB.Exec(`DELETE FROM stories WHERE id = $1`, first.StoryID) })
+
+		job := narrate(first.StoryVersionID)
+		pending, err := store.NarrationPendingSegments(t.Context(), job, 10)
+		if err != nil || len(pending) != 3 {
+			t.Fatalf("first NarrationPendingSegments = %#v, %v", pending, err)
+		}
+		for _, segment := range pending {
+			audio := model.NarrationAudio{ContentType: "audio/wav", Data: []byte("RIFF" + segment.RenderedHTML), Duration: time.Second}
+			if job, err = store.NarrationRecordSegment(t.Context(), job, segment.Ordinal, &audio, time.Minute); err != nil {
+				t.Fatalf("NarrationRecordSegment %d: %v", segment.Ordinal, err)
+			}
+		}
+		if _, err := store.NarrationFinishJob(t.Context(), job, nil); err != nil {
+			t.Fatalf("NarrationFinishJob: %v", err)
+		}
+
+		second, err := store.AdminDraftUpsert(t.Context(), readerAccountA, model.AdminDraftUpsertRequest{
+			Slug:     slug,
+			Title:    "Narrated",
+			Markdown: "# Narrated\n\nKept paragraph.\n\nNew ending.\n",
+		})
+		if err != nil {
+			t.Fatalf("insert %s v2: %v", slug, err)
+		}
+		job = narrate(second.StoryVersionID)
+		if job.TotalSegments != 1 {
+			t.Fatalf("second job = %#v, want one segment to narrate", job)
+		}
+		pending, err = store.NarrationPendingSegments(t.Context(), job, 10)
+		if err != nil || len(pending) != 1 || pending[0].Ordinal != 3 {
+			t.Fatalf("second NarrationPendingSegments = %#v, %v", pending, err)
+		}
+		var copiedData []byte
+		if err := adminDB.QueryRow(`
+			SELECT audio.data
+			FROM segment_audio AS audio
+			JOIN story_segments AS segment
+			  ON segment.id = audio.segment_id
+			WHERE segment.story_version_id = $1
+			  AND segment.ordinal = 2
+			  AND audio.voice = 'alloy'
+		`, second.StoryVersionID).Scan(&copiedData); err != nil || !strings.Contains(string(copiedData), "Kept paragraph.") {
+			t.Fatalf("copied recording = %q, %v", copiedData, err)
+		}
+	})
+
+	t.Run("blob garbage forgets keys a recording still holds", func(t *testing.T) {
+		const slug = "blob-garbage-story"
+		draft, err := store.AdminDraftUpsert(t.Context(), readerAccountA, model.AdminDraftUpsertRequest{
+			Slug:     slug,
+			Title:    "Collected",
+			Markdown: "# Collected\n\nA shared recording.\n",
+		})
+		if err != nil {
+			t.Fatalf("insert %s: %v", slug, err)
+		}
+		t.Cleanup(func() { _, _ = adminDB.Exec(`DELETE FROM stories WHERE id = $1`, draft.StoryID) })
+
+		held := "audio/" + readerAccountA + "/held.wav"
+		dropped := "audio/" + readerAccountA + "/dropped.wav"
+		if _, err := adminDB.Exec(`
+			INSERT INTO segment_audio (account_id, segment_id, content_type, duration_ms, byte_size, blob_key)
+			SELECT $1, segment.id, 'audio/wav', 1000, 4, $3
+			FROM story_segments AS segment
+			WHERE segment.story_version_id = $2
+			ORDER BY segment.ordinal
+			LIMIT 1
+		`, readerAccountA, draft.StoryVersionID, held); err != nil {
+			t.Fatalf("insert recording: %v", err)
+		}
+		if _, err := adminDB.Exec(`INSERT INTO blob_garbage (key) VALUES ($1), ($2)`, held, dropped); err != nil {
+			t.Fatalf("insert garbage: %v", err)
+		}
+		t.Cleanup(func() { _, _ = adminDB.Exec(`DELETE FROM blob_garbage WHERE key IN ($1, $2)`, held, dropped) })
+
+		keys, err := store.BlobGarbage(t.Context(), 1000)
+		if err != nil || slices.Contains(keys, held) || !slices.Contains(keys, dropped) {
+			t.Fatalf("BlobGarbage = %v, %v; want %q and not %q", keys, err, dropped, held)
+		}
+		var remembered bool
+		if err := adminDB.QueryRow(`SELECT EXISTS (SELECT 1 FROM blob_garbage WHERE key = $1)`, held).Scan(&remembered); err != nil || remembered {
+			t.Fatalf("held key still garbage = %t, %v", remembered, err)
+		}
+	})
+
+	t.Run("audited mutations commit only with their audit row", func(t *testing.T) {
+		t.Cleanup(func() {
+			_, _ = adminDB.Exec(`DELETE FROM tags WHERE account_id = $1 AND name IN ('audited-kept', 'audited-dropped')`, readerAccountA)
+		})
+		tagExists := func(name string) bool {
+			var exists bool
+			if err := adminDB.QueryRow(`SELECT EXISTS (SELECT 1 FROM tags WHERE account_id = $1 AND name = $2)`, readerAccountA, name).Scan(&exists); err != nil {
+				t.Fatalf("read tag %s: %v", name, err)
+			}
+			return exists
+		}
+
+		var kept model.AdminTag
+		err := store.AdminAudited(t.Context(), readerAccountA, "integration", func(ctx context.Context) (model.AdminAuditEntry, error) {
+			tag, err := store.AdminCreateTag(ctx, readerAccountA, "audited-kept")
+			kept = tag
+			return model.AdminAuditEntry{Action: model.AdminAuditActionTagCreate, Summary: map[string]any{"tagId": tag.ID}}, err
+		})
+		if err != nil || !tagExists("audited-kept") {
+			t.Fatalf("AdminAudited = %v, tag kept = %t", err, tagExists("audited-kept"))
+		}
+		var audits int
+		if err := adminDB.QueryRow(`
+			SELECT count(*) FROM admin_audit_log
+			WHERE account_id = $1 AND actor = 'integration' AND summary->>'tagId' = $2
+		`, readerAccountA, kept.ID).Scan(&audits); err != nil || audits != 1 {
+			t.Fatalf("audit rows = %d, %v; want 1", audits, err)
+		}
+
+		// An entry that cannot be written rolls the mutation back with it.
+		err = store.AdminAudited(t.Context(), readerAccountA, "integration", func(ctx context.Context) (model.AdminAuditEntry, error) {
+			_, err := store.AdminCreateTag(ctx, readerAccountA, "audited-dropped")
+			return model.AdminAuditEntry{}, err
+		})
+		if err == nil || tagExists("audited-dropped") {
+			t.Fatalf("AdminAudited = %v, tag kept = %t; want an error and no tag", err, tagExists("audited-dropped"))
+		}
+	})
+
+	t.Run("search reindex jobs embed published versions again", func(t *testing.T) {
+		const slug = "search-reindex-story"
+		draft, err := store.AdminDraftUpsert(t.Context(), readerAccountA, model.AdminDraftUpsertRequest{
+			Slug:     slug,
+			Title:    "Reindexed",
+			Markdown: "# Reindexed\n\nA story about boats.\n",
+		})
+		if err != nil {
+			t.Fatalf("insert %s: %v", slug, err)
+		}
+		t.Cleanup(func() { _, _ = adminDB.Exec(`DELETE FROM stories WHERE id = $1`, draft.StoryID) })
+
+		if _, err := store.AdminStartSearchReindex(t.Context(), readerAccountA, slug); !errors.Is(err, model.ErrAdminStoryNotFound) {
+			t.Fatalf("unpublished AdminStartSearchReindex error = %v, want ErrAdminStoryNotFound", err)
+		}
+		if err := store.AdminPublish(t.Context(), readerAccountA, slug, draft.StoryVersionID); err != nil {
+			t.Fatalf("publish %s: %v", slug, err)
+		}
+		source := model.EmbeddingSource{VersionID: draft.StoryVersionID, AccountID: readerAccountA, Title: "Reindexed"}
+		if err := store.EmbeddingSave(t.Context(), source, "old/model", []float32{1, 0}); err != nil {
+			t.Fatalf("EmbeddingSave: %v", err)
+		}
+
+		job, err := store.AdminStartSearchReindex(t.Context(), readerAccountA, slug)
+		if err != nil || job.TotalVersions != 1 || job.Slug != slug {
+			t.Fatalf("AdminStartSearchReindex = %#v, %v", job, err)
+		}
+		if _, err := store.AdminStartSearchReindex(t.Context(), readerAccountA, ""); !errors.Is(err, model.ErrSearchReindexJobActive) {
+			t.Fatalf("second AdminStartSearchReindex error = %v, want ErrSearchReindexJobActive", err)
+		}
+
+		claimed, ok, err := store.SearchReindexClaimJob(t.Context(), time.Minute)
+		if err != nil || !ok || claimed.ID != job.ID {
+			t.Fatalf("SearchReindexClaimJob = %#v, %v, %v", claimed, ok, err)
+		}
+		pending, err := store.SearchReindexPendingVersions(t.Context(), claimed, 10)
+		if err != nil || len(pending) != 1 || pending[0].VersionID != draft.StoryVersionID || !strings.Contains(pending[0].Markdown, "boats") {
+			t.Fatalf("SearchReindexPendingVersions = %#v, %v", pending, err)
+		}
+		if claimed, err = store.SearchReindexRecordVersion(t.Context(), claimed, pending[0], "new/model", []float32{0, 1}, time.Minute); err != nil {
+			t.Fatalf("SearchReindexRecordVersion: %v", err)
+		}
+		if pending, err = store.SearchReindexPendingVersions(t.Context(), claimed, 10); err != nil || len(pending) != 0 {
+			t.Fatalf("SearchReindexPendingVersions after record = %#v, %v", pending, err)
+		}
+		if _, err := store.SearchReindexFinishJob(t.Context(), claimed, nil); err != nil {
+			t.Fatalf("SearchReindexFinishJob: %v", err)
+		}
+
+		read, err := store.AdminGetSearchReindexJob(t.Context(), readerAccountA, job.ID)
+		if err != nil || read.Status != model.RenderJobCompleted || read.IndexedVersions != 1 {
+			t.Fatalf("AdminGetSearchReindexJob = %#v, %v", read, err)
+		}
+		if _, err := store.AdminGetSearchReindexJob(t.Context(), readerAccountB, job.ID); !errors.Is(err, model.ErrSearchReindexJobNotFound) {
+			t.Fatalf("other account AdminGetSearchReindexJob error = %v, want ErrSearchReindexJobNotFound", err)
+		}
+		var modelName string
+		if err := adminDB.QueryRow(`SELECT model FROM story_version_embeddings WHERE story_version_id = $1`, draft.StoryVersionID).Scan(&modelName); err != nil || modelName != "new/model" {
+			t.Fatalf("embedding model = %q, %v", modelName, err)
+		}
+	})
+
+	t.Run("reader changed time follows credits and recordings", func(t *testing.T) {
+		const slug = "reader-changed-story"
+		draft, err := store.AdminDraftUpsert(t.Context(), readerAccountA, model.AdminDraftUpsertRequest{
+			Slug:     slug,
+			Title:    "Changed",
+			Markdown: "# Changed\n\nOne paragraph.\n",
+		})
+		if err != nil {
+			t.Fatalf("insert %s: %v", slug, err)
+		}
+		t.Cleanup(func() { _, _ = adminDB.Exec(`DELETE FROM stories WHERE id = $1`, draft.StoryID) })
+		if err := store.AdminPublish(t.Context(), readerAccountA, slug, draft.StoryVersionID); err != nil {
+			t.Fatalf("publish %s: %v", slug, err)
+		}
+		changedAt := func() time.Time {
+			t.Helper()
+			story, err := store.ReaderStory(t.Context(), readerAccountA, slug)
+			if err != nil || story.ChangedAt.IsZero() {
+				t.Fatalf("ReaderStory = %#v, %v", story, err)
+			}
+			return story.ChangedAt
+		}
+
+		published := changedAt()
+		if _, err := store.AdminAddStoryContributor(t.Context(), readerAccountA, slug, model.StoryContributor{Name: "Ada Illustrator", Role: "illustrator"}); err != nil {
+			t.Fatalf("AdminAddStoryContributor: %v", err)
+		}
+		credited := changedAt()
+		if !credited.After(published) {
+			t.Fatalf("changed at %v after crediting, want after %v", credited, published)
+		}
+
+		if _, err := adminDB.Exec(`
+			INSERT INTO segment_audio (account_id, segment_id, content_type, duration_ms, byte_size, data)
+			SELECT $1, segment.id, 'audio/wav', 1000, 4, 'RIFF'::bytea
+			FROM story_segments AS segment
+			WHERE segment.story_version_id = $2
+		`, readerAccountA, draft.StoryVersionID); err != nil {
+			t.Fatalf("insert recordings: %v", err)
+		}
+		if narrated := changedAt(); !narrated.After(credited) {
+			t.Fatalf("changed at %v after narrating, want after %v", narrated, credited)
+		}
+	})
+
+	t.Run("bedtime keeps short calm stories", func(t *testing.T) {
+		settings, err := store.BedtimeSettings(t.Context(), readerAccountA)
+		if err != nil || settings != model.DefaultBedtimeSettings {
+			t.Fatalf("default BedtimeSettings = %#v, %v", settings, err)
+		}
+		if _, err := store.PutBedtimeSettings(t.Context(), readerAccountA, model.BedtimeSettings{Start: "19:00", End: "07:00", TimeZone: "Mars/Olympus", MaxWords: 10}); !errors.Is(err, model.ErrBedtimeTimeZone) {
+			t.Fatalf("PutBedtimeSettings unknown zone error = %v, want ErrBedtimeTimeZone", err)
+		}
+		want := model.BedtimeSettings{Start: "19:00", End: "07:00", TimeZone: "Europe/London", MaxWords: 10}
+		if saved, err := store.PutBedtimeSettings(t.Context(), readerAccountA, want); err != nil || saved != want {
+			t.Fatalf("PutBedtimeSettings = %#v, %v", saved, err)
+		}
+		t.Cleanup(func() { _, _ = adminDB.Exec(`DELETE FROM bedtime_settings WHERE account_id = $1`, readerAccountA) })
+
+		stories := map[string]string{
+			"bedtime-calm":     "# Calm\n\nThe moon is up.\n",
+			"bedtime-exciting": "# Exciting\n\nThe moon is up.\n",
+			"bedtime-long":     "# Long\n\nThe moon is up over the hill and the sea and the town tonight.\n",
+		}
+		for slug, markdown := range stories {
+			draft, err := store.AdminDraftUpsert(t.Context(), readerAccountA, model.AdminDraftUpsertRequest{Slug: slug, Title: slug, Markdown: markdown})
+			if err != nil {
+				t.Fatalf("insert %s: %v", slug, err)
+			}
+			t.Cleanup(func() { _, _ = adminDB.Exec(`DELETE FROM stories WHERE id = $1`, draft.StoryID) })
+			if err := store.AdminPublish(t.Context(), readerAccountA, slug, draft.VersionID); err != nil {
+				t.Fatalf("publish %s: %v", slug, err)
+			}
+			tags := []string{"Calm"}
+			if slug == "bedtime-exciting" {
+				tags = append(tags, "exciting")
+			}
+			if _, err := store.AdminAddStoryTags(t.Context(), readerAccountA, slug, tags); err != nil {
+				t.Fatalf("tag %s: %v", slug, err)
+			}
+		}
+		t.Cleanup(func() {
+			_, _ = adminDB.Exec(`DELETE FROM tags WHERE account_id = $1 AND lower(name) IN ('calm', 'exciting')`, readerAccountA)
+		})
+
+		library, err := store.Library(t.Context(), readerAccountA, model.PageRequest{Limit: 100}, model.LibraryFilter{BedtimeMaxWords: want.MaxWords})
+		if err != nil {
+			t.Fatalf("bedtime Library: %v", err)
+		}
+		if len(library.Items) != 1 || library.Items[0].Slug != "bedtime-calm" {
+			t.Fatalf("bedtime Library = %#v", library)
+		}
+	})
+
+	t.Run("render jobs restore stale HTML without touching identities", func(t *testing.T) {
+		if _, err := adminDB.Exec(`
+			UPDATE story_segments
+			SET rendered_html = '<p>stale renderer output</p>'
+			WHERE story_version_id = $1
+			  AND ordinal = 2
+		`, accountBDraft.StoryVersionID); err != nil {
+			t.Fatalf("stale account B segment: %v", err)
+		}
+
+		job, err := store.AdminStartRenderJob(t.Context(), readerAccountB)
+		if err != nil {
+			t.Fatalf("AdminStartRenderJob: %v", err)
+		}
+		if job.Status != model.RenderJobQueued || job.TotalVersions != 1 {
+			t.Fatalf("queued job = %#v", job)
+		}
+		if _, err := store.AdminStartRenderJob(t.Context(), readerAccountB); !errors.Is(err, model.ErrRenderJobActive) {
+			t.Fatalf("second AdminStartRenderJob error = %v, want ErrRenderJobActive", err)
+		}
+
+		for {
+			claimed, ok, err := store.RenderClaimJob(t.Context(), time.Minute)
+			if err != nil || !ok {
+				t.Fatalf("RenderClaimJob = %v, %v", ok, err)
+			}
+			for claimed.Status == model.RenderJobRunning {
+				if claimed, err = store.RenderJobStep(t.Context(), claimed, 10, time.Minute); err != nil {
+					t.Fatalf("RenderJobStep: %v", err)
+				}
+			}
+			if claimed.ID == job.ID {
+				break
+			}
+		}
+
+		finished, err := store.AdminGetRenderJob(t.Context(), readerAccountB, job.ID)
+		if err != nil {
+			t.Fatalf("AdminGetRenderJob: %v", err)
+		}
+		if finished.Status != model.RenderJobCompleted || finished.RenderedVersions != 1 ||
+			finished.UnchangedVersions != 0 || finished.SkippedVersions != 0 || finished.FinishedAt == nil {
+			t.Fatalf("finished job = %#v", finished)
+		}
+		if _, err := store.AdminGetRenderJob(t.Context(), readerAccountA, job.ID); !errors.Is(err, model.ErrRenderJobNotFound) {
+			t.Fatalf("cross-account AdminGetRenderJob error = %v", err)
+		}
+
+		story, err := store.ReaderStory(t.Context(), readerAccountB, readerSlug)
+		if err != nil {
+			t.Fatalf("ReaderStory after re-render: %v", err)
+		}
+		if !strings.Contains(story.Segments[1].RenderedHTML, "Private to account B.") {
+			t.Fatalf("re-rendered segment = %q", story.Segments[1].RenderedHTML)
+		}
+		if _, err := validateStoredReaderVersion(context.Background(), store.db, accountBDraft.StoryID, accountBDraft.StoryVersionID, readerSlug); err != nil {
+			t.Fatalf("re-rendered version is not canonical: %v", err)
+		}
+	})
+
 	t.Run("real HTTP exposes clean Reader and strict progress contracts", func(t *testing.T) {
 		sessions, err := session.New("reader-store-integration-session-secret", false)
 		if err != nil {
@@ -1892,7 +2537,7 @@ func TestReaderStoreIntegration(t *testing.T) {
 			0.73,
 		)
 
-		if err := store.AdminPublish(readerAccountA, readerSlug, secondDraft.StoryVersionID); err != nil {
+		if err := store.AdminPublish(t.Context(), readerAccountA, readerSlug, secondDraft.StoryVersionID); err != nil {
 			t.Fatalf("publish second version for HTTP progress: %v", err)
 		}
 		staleResponse := serveReaderRequest(t, handler, cookie, http.MethodPut, "/api/v1/progress/"+readerSlug, progressBody(t, firstDraft.Version, locator, 0.82))
@@ -1963,17 +2608,25 @@ func TestReaderStoreIntegration(t *testing.T) {
 
 func newReaderIntegrationStore(t *testing.T, databaseURL string) *Store {
 	t.Helper()
-	database, err := sql.Open("pgx", databaseURL)
+	return openReaderIntegrationStore(t, databaseURL, 8)
+}
+
+func openReaderIntegrationStore(t *testing.T, databaseURL string, maxConns int32) *Store {
+	t.Helper()
+	config, err := pgxpool.ParseConfig(databaseURL)
+	if err != nil {
+		t.Fatalf("open Reader Store database: %v", err)
+	}
+	config.MaxConns = maxConns
+	database, err := pgxpool.NewWithConfig(context.Background(), config)
 	if err != nil {
 		t.Fatalf("open Reader Store database: %v", err)
 	}
-	database.SetMaxOpenConns(8)
-	database.SetMaxIdleConns(4)
-	if err := database.Ping(); err != nil {
-		_ = database.Close()
+	if err := database.Ping(context.Background()); err != nil {
+		database.Close()
 		t.Fatalf("ping Reader Store database: %v", err)
 	}
-	t.Cleanup(func() { _ = database.Close() })
+	t.Cleanup(database.Close)
 	return &Store{db: database, queryTimeout: 10 * time.Second, defaultProfileByAccount: map[string]string{}}
 }
 
@@ -1983,10 +2636,7 @@ func newReaderIntegrationStoreWithApplicationName(t *testing.T, databaseURL, app
 	if strings.Contains(databaseURL, "?") {
 		separator = "&"
 	}
-	store := newReaderIntegrationStore(t, databaseURL+separator+"application_name="+applicationName)
-	store.db.SetMaxOpenConns(1)
-	store.db.SetMaxIdleConns(1)
-	return store
+	return openReaderIntegrationStore(t, databaseURL+separator+"application_name="+applicationName, 1)
 }
 
 func assertReaderVersionShape(t *testing.T, story model.ReaderStory, version, segments int) {
@@ -0,0 +1,126 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"pandapages/api/internal/model"
+	"pandapages/api/internal/readercontract"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func TestConfigureStatementCache(t *testing.T) {
+	tests := []struct {
+		size     int
+		mode     pgx.QueryExecMode
+		capacity int
+	}{
+		{size: 0, mode: pgx.QueryExecModeCacheStatement, capacity: defaultStatementCacheSize},
+		{size: 32, mode: pgx.QueryExecModeCacheStatement, capacity: 32},
+		{size: -1, mode: pgx.QueryExecModeExec, capacity: 0},
+	}
+	for _, test := range tests {
+		cfg, err := pgx.ParseConfig("postgres://reader@localhost/pandapages")
+		if err != nil {
+			t.Fatalf("parse config: %v", err)
+		}
+		configureStatementCache(cfg, test.size)
+		if cfg.DefaultQueryExecMode != test.mode || cfg.StatementCacheCapacity != test.capacity {
+			t.Fatalf("size %d: mode %v capacity %d, want %v %d", test.size, cfg.DefaultQueryExecMode, cfg.StatementCacheCapacity, test.mode, test.capacity)
+		}
+	}
+}
+
+// BenchmarkHotQueries compares the hot Reader queries prepared once per
+// connection against describing them on every call, which costs an extra
+// round trip. It uses the disposable Reader integration database, where it
+// publishes a story of its own to open and save progress in.
+func BenchmarkHotQueries(b *testing.B) {
+	if os.Getenv(readerIntegrationGuardVar) != "1" {
+		b.Skip("set PP_READER_STORE_TEST_DISPOSABLE=1 to benchmark against the disposable PostgreSQL database")
+	}
+	databaseURL := strings.TrimSpace(os.Getenv(readerIntegrationURLVar))
+	if databaseURL == "" {
+		b.Fatalf("%s is required when %s=1", readerIntegrationURLVar, readerIntegrationGuardVar)
+	}
+
+	for _, mode := range []pgx.QueryExecMode{pgx.QueryExecModeCacheStatement, pgx.QueryExecModeDescribeExec} {
+		b.Run(mode.String(), func(b *testing.B) {
+			store := newStatementBenchmarkStore(b, databaseURL, mode)
+			if _, err := store.db.Exec(context.Background(), `
+				INSERT INTO accounts (id, name) VALUES ($1, 'Reader Account A')
+				ON CONFLICT (id) DO NOTHING
+			`, readerAccountA); err != nil {
+				b.Fatalf("insert benchmark account: %v", err)
+			}
+			story, locator := publishStatementBenchmarkStory(b, store)
+			b.ResetTimer()
+			for b.Loop() {
+				if _, err := store.Library(b.Context(), readerAccountA, model.PageRequest{}, model.LibraryFilter{}); err != nil {
+					b.Fatalf("Library: %v", err)
+				}
+				if _, err := store.ContinueRecent(b.Context(), readerAccountA, 3); err != nil {
+					b.Fatalf("ContinueRecent: %v", err)
+				}
+				if _, err := store.ProgressGet(b.Context(), readerAccountA, readerSlug); err != nil && !errors.Is(err, sql.ErrNoRows) {
+					b.Fatalf("ProgressGet: %v", err)
+				}
+				if _, err := store.ReaderStory(b.Context(), readerAccountA, story.Slug); err != nil {
+					b.Fatalf("ReaderStory: %v", err)
+				}
+				if err := store.ProgressPut(b.Context(), readerAccountA, story.Slug, story.Version, locator, 0.5); err != nil {
+					b.Fatalf("ProgressPut: %v", err)
+				}
+			}
+		})
+	}
+}
+
+// publishStatementBenchmarkStory publishes the benchmark's story, or finds
+// it published by an earlier run, and returns it with a locator to save.
+func publishStatementBenchmarkStory(b *testing.B, store *Store) (model.ReaderStory, readercontract.Locator) {
+	b.Helper()
+	const slug = "statement-benchmark-story"
+	draft, err := store.AdminDraftUpsert(b.Context(), readerAccountA, model.AdminDraftUpsertRequest{
+		Slug:     slug,
+		Title:    "Statement benchmark",
+		Markdown: "# Statement benchmark\n\n## One\n\nA paragraph to read.\n\n## Two\n\nAnother paragraph.\n",
+	})
+	if err != nil {
+		b.Fatalf("draft benchmark story: %v", err)
+	}
+	b.Cleanup(func() {
+		_, _ = store.db.Exec(context.Background(), `DELETE FROM stories WHERE account_id = $1 AND slug = $2`, readerAccountA, slug)
+	})
+	if err := store.AdminPublish(b.Context(), readerAccountA, slug, draft.StoryVersionID); err != nil {
+		b.Fatalf("publish benchmark story: %v", err)
+	}
+	story, err := store.ReaderStory(b.Context(), readerAccountA, slug)
+	if err != nil || len(story.Segments) == 0 {
+		b.Fatalf("ReaderStory = %#v, %v", story, err)
+	}
+	return story, locatorForReaderSegment(story.Segments[len(story.Segments)-1], 0.5)
+}
+
+func newStatementBenchmarkStore(b *testing.B, databaseURL string, mode pgx.QueryExecMode) *Store {
+	b.Helper()
+	config, err := pgxpool.ParseConfig(databaseURL)
+	if err != nil {
+		b.Fatalf("open benchmark database: %v", err)
+	}
+	config.MaxConns = 1
+	config.ConnConfig.DefaultQueryExecMode = mode
+	database, err := pgxpool.NewWithConfig(context.Background(), config)
+	if err != nil {
+		b.Fatalf("open benchmark database: %v", err)
+	}
+	b.Cleanup(database.Close)
+	return &Store{db: database, queryTimeout: 10 * time.Second, defaultProfileByAccount: map[string]string{}}
+}
@@ -0,0 +1,97 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"pandapages/api/internal/model"
+	"pandapages/api/internal/storyingest"
+)
+
+// AdminCheckTranslationSlug reports whether slug may take a translation of
+// originalSlug: it must be free, or already hold a translation in the
+// original's language group, which then gains a new draft. A missing
+// original is ErrAdminStoryNotFound; any other story at slug is
+// ErrTranslationSlugTaken.
+func (s *Store) AdminCheckTranslationSlug(ctx context.Context, accountID, originalSlug, slug string) error {
+	accountID = strings.TrimSpace(accountID)
+	if !accountIDRe.MatchString(accountID) {
+		return fmt.Errorf("account required")
+	}
+	if storyingest.ValidateSlug(originalSlug) != nil {
+		return fmt.Errorf("%w", model.ErrAdminStoryNotFound)
+	}
+	if storyingest.ValidateSlug(slug) != nil {
+		return fmt.Errorf("%w", model.ErrTranslationSlugTaken)
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	// The primary is read because the answer guards a write that follows.
+	var free bool
+	err := s.db.QueryRow(ctx, `
+		SELECT target.id IS NULL OR target.translation_of IS NOT DISTINCT FROM COALESCE(original.translation_of, original.id)
+		FROM stories AS original
+		LEFT JOIN stories AS target
+		  ON target.account_id = original.account_id
+		 AND target.slug = $3
+		WHERE original.account_id = $1
+		  AND original.slug = $2
+	`, accountID, originalSlug, slug).Scan(&free)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w", model.ErrAdminStoryNotFound)
+	}
+	if err != nil {
+		return err
+	}
+	if !free {
+		return fmt.Errorf("%w", model.ErrTranslationSlugTaken)
+	}
+	return nil
+}
+
+// AdminLinkTranslation marks the story at slug as a translation of
+// originalSlug's language group and returns the slug the group is anchored
+// on. Translating a translation links to the same anchor, so groups stay one
+// level deep. Linking leaves updated_at alone: the Library order follows
+// content, not grouping.
+func (s *Store) AdminLinkTranslation(ctx context.Context, accountID, slug, originalSlug string) (string, error) {
+	accountID = strings.TrimSpace(accountID)
+	if !accountIDRe.MatchString(accountID) {
+		return "", fmt.Errorf("account required")
+	}
+	if storyingest.ValidateSlug(slug) != nil || storyingest.ValidateSlug(originalSlug) != nil {
+		return "", fmt.Errorf("%w", model.ErrAdminStoryNotFound)
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	var anchor string
+	err := s.writes(ctx).QueryRow(ctx, `
+		WITH anchor AS (
+			SELECT anchor.id, anchor.slug
+			FROM stories AS original
+			JOIN stories AS anchor
+			  ON anchor.id = COALESCE(original.translation_of, original.id)
+			 AND anchor.account_id = original.account_id
+			WHERE original.account_id = $1
+			  AND original.slug = $3
+		)
+		UPDATE stories AS story
+		SET translation_of = anchor.id
+		FROM anchor
+		WHERE story.account_id = $1
+		  AND story.slug = $2
+		  AND story.id <> anchor.id
+		RETURNING anchor.slug
+	`, accountID, slug, originalSlug).Scan(&anchor)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", fmt.Errorf("%w", model.ErrAdminStoryNotFound)
+	}
+	return anchor, err
+}
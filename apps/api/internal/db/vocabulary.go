@@ -0,0 +1,50 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"pandapages/api/internal/model"
+	"pandapages/api/internal/storyingest"
+)
+
+func vocabularyJSON(v storyingest.Vocabulary) ([]byte, error) {
+	return json.Marshal(model.Vocabulary{
+		Words:         v.Words,
+		DistinctWords: v.Distinct,
+		Frequencies:   v.Frequencies,
+		RareWords:     v.Rare,
+	})
+}
+
+// ReaderVocabulary returns the stored vocabulary of a story's published
+// version. Versions ingested before vocabulary was recorded report
+// sql.ErrNoRows, as a missing story does.
+func (s *Store) ReaderVocabulary(ctx context.Context, accountID, slug string) (model.Vocabulary, error) {
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	var raw sql.NullString
+	if err := s.db.QueryRow(ctx, `
+		SELECT version.vocabulary::text
+		FROM stories st
+		JOIN story_versions AS version
+		  ON version.id = st.published_version_id
+		 AND version.story_id = st.id
+		WHERE st.account_id = $1
+		  AND st.slug = $2
+		  AND st.is_published = true
+	`, accountID, slug).Scan(&raw); err != nil {
+		return model.Vocabulary{}, s.removedOr(ctx, accountID, slug, err)
+	}
+	if !raw.Valid {
+		return model.Vocabulary{}, sql.ErrNoRows
+	}
+	var out model.Vocabulary
+	if err := json.Unmarshal([]byte(raw.String), &out); err != nil {
+		return model.Vocabulary{}, fmt.Errorf("decode story vocabulary: %w", err)
+	}
+	return out, nil
+}
@@ -0,0 +1,64 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"pandapages/api/internal/model"
+)
+
+// Warm runs the default account's first-page queries so a restart does not
+// leave the first reader of the day waiting on cold connections, statements
+// and PostgreSQL buffers. It loads the library and recent progress, then
+// reads up to topStories stories into the Reader cache, most recently read
+// first and then in library order. It returns how many stories it loaded.
+func (s *Store) Warm(ctx context.Context, topStories int) (int, error) {
+	accountID, err := s.EnsureDefaultAccount(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("warm default account: %w", err)
+	}
+	library, err := s.Library(ctx, accountID, model.PageRequest{}, model.LibraryFilter{})
+	if err != nil {
+		return 0, fmt.Errorf("warm library: %w", err)
+	}
+	recent, err := s.ContinueRecent(ctx, accountID, 10)
+	if err != nil {
+		return 0, fmt.Errorf("warm continue: %w", err)
+	}
+
+	warmed := 0
+	for _, slug := range warmSlugs(recent, library.Items) {
+		if warmed >= topStories {
+			break
+		}
+		// A story read earlier may since have been unpublished.
+		if _, err := s.ReaderStory(ctx, accountID, slug); errors.Is(err, sql.ErrNoRows) {
+			continue
+		} else if err != nil {
+			return warmed, fmt.Errorf("warm story %q: %w", slug, err)
+		}
+		warmed++
+	}
+	return warmed, nil
+}
+
+// warmSlugs lists each story once, recently read stories first.
+func warmSlugs(recent []model.ContinueItem, library []model.StoryItem) []string {
+	slugs := make([]string, 0, len(recent)+len(library))
+	seen := map[string]bool{}
+	add := func(slug string) {
+		if !seen[slug] {
+			seen[slug] = true
+			slugs = append(slugs, slug)
+		}
+	}
+	for _, item := range recent {
+		add(item.Slug)
+	}
+	for _, item := range library {
+		add(item.Slug)
+	}
+	return slugs
+}
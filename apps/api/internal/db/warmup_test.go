@@ -0,0 +1,18 @@
+package db
+
+import (
+	"slices"
+	"testing"
+
+	"pandapages/api/internal/model"
+)
+
+func TestWarmSlugsPutRecentReadingFirst(t *testing.T) {
+	recent := []model.ContinueItem{{Slug: "owl"}, {Slug: "moon"}}
+	library := []model.StoryItem{{Slug: "fox"}, {Slug: "moon"}, {Slug: "bear"}}
+
+	got := warmSlugs(recent, library)
+	if want := []string{"owl", "moon", "fox", "bear"}; !slices.Equal(got, want) {
+		t.Fatalf("warmSlugs = %q, want %q", got, want)
+	}
+}
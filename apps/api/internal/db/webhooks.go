@@ -0,0 +1,386 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"pandapages/api/internal/model"
+	"pandapages/api/internal/webhooks"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	defaultWebhookDeliveryLog = 50
+	maxWebhookDeliveryLog     = 200
+)
+
+const adminWebhookColumns = `id, url, array_to_string(events, ','), active, created_at, updated_at`
+
+func (s *Store) AdminListWebhooks(ctx context.Context, accountID string) (model.AdminWebhooksListResponse, error) {
+	accountID = strings.TrimSpace(accountID)
+	if !accountIDRe.MatchString(accountID) {
+		return model.AdminWebhooksListResponse{}, fmt.Errorf("account required")
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	rows, err := s.db.Query(ctx, `
+		SELECT `+adminWebhookColumns+`
+		FROM webhooks
+		WHERE account_id = $1
+		ORDER BY created_at ASC, id ASC
+	`, accountID)
+	if err != nil {
+		return model.AdminWebhooksListResponse{}, err
+	}
+	defer rows.Close()
+	items := []model.AdminWebhook{}
+	for rows.Next() {
+		hook, err := scanAdminWebhook(rows)
+		if err != nil {
+			return model.AdminWebhooksListResponse{}, err
+		}
+		items = append(items, hook)
+	}
+	if err := rows.Err(); err != nil {
+		return model.AdminWebhooksListResponse{}, err
+	}
+	return model.AdminWebhooksListResponse{Items: items}, nil
+}
+
+func (s *Store) AdminCreateWebhook(ctx context.Context, accountID string, req model.AdminWebhookCreate) (model.AdminWebhook, error) {
+	accountID = strings.TrimSpace(accountID)
+	if !accountIDRe.MatchString(accountID) {
+		return model.AdminWebhook{}, fmt.Errorf("account required")
+	}
+	url := strings.TrimSpace(req.URL)
+	if url == "" || len(url) > webhooks.MaxURLBytes {
+		return model.AdminWebhook{}, fmt.Errorf("webhook url invalid")
+	}
+	events, err := joinWebhookEvents(req.Events)
+	if err != nil {
+		return model.AdminWebhook{}, err
+	}
+	if len(req.Secret) < 32 {
+		return model.AdminWebhook{}, fmt.Errorf("webhook secret invalid")
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	return scanAdminWebhook(s.writes(ctx).QueryRow(ctx, `
+		INSERT INTO webhooks (account_id, url, secret, events)
+		VALUES ($1, $2, $3, string_to_array($4, ','))
+		RETURNING `+adminWebhookColumns, accountID, url, req.Secret, events))
+}
+
+func (s *Store) AdminUpdateWebhook(ctx context.Context, accountID string, webhookID string, update model.AdminWebhookUpdate) (model.AdminWebhook, error) {
+	accountID = strings.TrimSpace(accountID)
+	webhookID = strings.TrimSpace(webhookID)
+	if !accountIDRe.MatchString(accountID) {
+		return model.AdminWebhook{}, fmt.Errorf("account required")
+	}
+	if !accountIDRe.MatchString(webhookID) {
+		return model.AdminWebhook{}, fmt.Errorf("%w", model.ErrAdminWebhookNotFound)
+	}
+	var url, events any
+	if update.URL != nil {
+		value := strings.TrimSpace(*update.URL)
+		if value == "" || len(value) > webhooks.MaxURLBytes {
+			return model.AdminWebhook{}, fmt.Errorf("webhook url invalid")
+		}
+		url = value
+	}
+	if update.Events != nil {
+		value, err := joinWebhookEvents(update.Events)
+		if err != nil {
+			return model.AdminWebhook{}, err
+		}
+		events = value
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	hook, err := scanAdminWebhook(s.writes(ctx).QueryRow(ctx, `
+		UPDATE webhooks
+		SET url = COALESCE($3::text, url),
+		    events = COALESCE(string_to_array($4::text, ','), events),
+		    active = COALESCE($5::boolean, active),
+		    updated_at = now()
+		WHERE account_id = $1
+		  AND id = $2
+		RETURNING `+adminWebhookColumns, accountID, webhookID, url, events, update.Active))
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.AdminWebhook{}, fmt.Errorf("%w", model.ErrAdminWebhookNotFound)
+	}
+	return hook, err
+}
+
+// AdminDeleteWebhook removes the webhook and, with it, its delivery log.
+func (s *Store) AdminDeleteWebhook(ctx context.Context, accountID string, webhookID string) error {
+	accountID = strings.TrimSpace(accountID)
+	webhookID = strings.TrimSpace(webhookID)
+	if !accountIDRe.MatchString(accountID) {
+		return fmt.Errorf("account required")
+	}
+	if !accountIDRe.MatchString(webhookID) {
+		return fmt.Errorf("%w", model.ErrAdminWebhookNotFound)
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	result, err := s.writes(ctx).Exec(ctx, `
+		DELETE FROM webhooks
+		WHERE account_id = $1
+		  AND id = $2
+	`, accountID, webhookID)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("%w", model.ErrAdminWebhookNotFound)
+	}
+	return nil
+}
+
+// AdminListWebhookDeliveries returns the newest deliveries first. The log
+// reports outcomes only; payloads are not echoed back.
+func (s *Store) AdminListWebhookDeliveries(ctx context.Context, accountID string, webhookID string, limit int) (model.AdminWebhookDeliveriesResponse, error) {
+	accountID = strings.TrimSpace(accountID)
+	webhookID = strings.TrimSpace(webhookID)
+	if !accountIDRe.MatchString(accountID) {
+		return model.AdminWebhookDeliveriesResponse{}, fmt.Errorf("account required")
+	}
+	if !accountIDRe.MatchString(webhookID) {
+		return model.AdminWebhookDeliveriesResponse{}, fmt.Errorf("%w", model.ErrAdminWebhookNotFound)
+	}
+	if limit <= 0 {
+		limit = defaultWebhookDeliveryLog
+	}
+	if limit > maxWebhookDeliveryLog {
+		limit = maxWebhookDeliveryLog
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return model.AdminWebhookDeliveriesResponse{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var exists bool
+	if err := tx.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM webhooks WHERE account_id = $1 AND id = $2)
+	`, accountID, webhookID).Scan(&exists); err != nil {
+		return model.AdminWebhookDeliveriesResponse{}, err
+	}
+	if !exists {
+		return model.AdminWebhookDeliveriesResponse{}, fmt.Errorf("%w", model.ErrAdminWebhookNotFound)
+	}
+
+	rows, err := tx.Query(ctx, `
+		SELECT id, event, status, attempts, last_status_code, last_error,
+		       created_at, last_attempt_at, next_attempt_at
+		FROM webhook_deliveries
+		WHERE webhook_id = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2
+	`, webhookID, limit)
+	if err != nil {
+		return model.AdminWebhookDeliveriesResponse{}, err
+	}
+	defer rows.Close()
+	items := []model.AdminWebhookDelivery{}
+	for rows.Next() {
+		var (
+			item          model.AdminWebhookDelivery
+			event, status string
+			statusCode    sql.NullInt64
+			lastError     sql.NullString
+			createdAt     time.Time
+			lastAttemptAt sql.NullTime
+			nextAttemptAt sql.NullTime
+		)
+		if err := rows.Scan(&item.ID, &event, &status, &item.Attempts, &statusCode, &lastError,
+			&createdAt, &lastAttemptAt, &nextAttemptAt); err != nil {
+			return model.AdminWebhookDeliveriesResponse{}, err
+		}
+		item.Event = model.WebhookEvent(event)
+		item.Status = model.WebhookDeliveryStatus(status)
+		item.LastStatusCode = nullIntValue(statusCode)
+		item.LastError = nullStringValue(lastError)
+		item.CreatedAt = createdAt.UTC().Format(time.RFC3339Nano)
+		item.LastAttemptAt = nullTimeString(lastAttemptAt)
+		item.NextAttemptAt = nullTimeString(nextAttemptAt)
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return model.AdminWebhookDeliveriesResponse{}, err
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return model.AdminWebhookDeliveriesResponse{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return model.AdminWebhookDeliveriesResponse{}, err
+	}
+	return model.AdminWebhookDeliveriesResponse{WebhookID: webhookID, Items: items}, nil
+}
+
+// WebhookClaimDeliveries leases up to limit due deliveries across accounts.
+// The lease pushes next_attempt_at forward so a crashed dispatcher's claims
+// become due again instead of being lost.
+func (s *Store) WebhookClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]model.PendingWebhookDelivery, error) {
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	rows, err := s.db.Query(ctx, `
+		WITH due AS (
+			SELECT d.id
+			FROM webhook_deliveries d
+			JOIN webhooks w ON w.id = d.webhook_id
+			WHERE d.status = 'pending'
+			  AND d.next_attempt_at <= now()
+			  AND w.active
+			ORDER BY d.next_attempt_at ASC, d.id ASC
+			LIMIT $1
+			FOR UPDATE OF d SKIP LOCKED
+		)
+		UPDATE webhook_deliveries d
+		SET next_attempt_at = now() + make_interval(secs => $2)
+		FROM due, webhooks w
+		WHERE d.id = due.id
+		  AND w.id = d.webhook_id
+		RETURNING d.id, w.url, w.secret, d.event, d.payload::text, d.attempts
+	`, limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []model.PendingWebhookDelivery{}
+	for rows.Next() {
+		var (
+			item    model.PendingWebhookDelivery
+			event   string
+			payload string
+		)
+		if err := rows.Scan(&item.ID, &item.URL, &item.Secret, &event, &payload, &item.Attempts); err != nil {
+			return nil, err
+		}
+		item.Event = model.WebhookEvent(event)
+		item.Payload = []byte(payload)
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+func (s *Store) WebhookRecordAttempt(ctx context.Context, deliveryID string, attempt model.WebhookAttempt) error {
+	status := model.WebhookDeliveryPending
+	switch {
+	case attempt.Succeeded:
+		status = model.WebhookDeliverySucceeded
+		attempt.NextAttemptAt = nil
+	case attempt.NextAttemptAt == nil:
+		status = model.WebhookDeliveryFailed
+	}
+	var statusCode, lastError any
+	if attempt.StatusCode != 0 {
+		statusCode = attempt.StatusCode
+	}
+	if attempt.Error != "" {
+		lastError = attempt.Error
+	}
+
+	ctx, cancel := s.ctx(ctx)
+	defer cancel()
+
+	_, err := s.db.Exec(ctx, `
+		UPDATE webhook_deliveries
+		SET status = $2,
+		    attempts = attempts + 1,
+		    last_status_code = $3,
+		    last_error = $4,
+		    last_attempt_at = now(),
+		    next_attempt_at = $5
+		WHERE id = $1
+	`, deliveryID, string(status), statusCode, lastError, attempt.NextAttemptAt)
+	return err
+}
+
+// enqueueWebhookEvent records one delivery per subscribed webhook inside the
+// caller's transaction, so an event exists exactly when its change commits.
+func enqueueWebhookEvent(ctx context.Context, tx pgx.Tx, accountID string, payload model.WebhookPayload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, `
+		INSERT INTO webhook_deliveries (webhook_id, event, payload, next_attempt_at)
+		SELECT id, $2, $3::jsonb, now()
+		FROM webhooks
+		WHERE account_id = $1
+		  AND active
+		  AND $2 = ANY(events)
+	`, accountID, string(payload.Event), string(body))
+	return err
+}
+
+func scanAdminWebhook(row rowScanner) (model.AdminWebhook, error) {
+	var (
+		hook                 model.AdminWebhook
+		events               string
+		createdAt, updatedAt time.Time
+	)
+	if err := row.Scan(&hook.ID, &hook.URL, &events, &hook.Active, &createdAt, &updatedAt); err != nil {
+		return model.AdminWebhook{}, err
+	}
+	hook.Events = []model.WebhookEvent{}
+	for _, event := range strings.Split(events, ",") {
+		if event != "" {
+			hook.Events = append(hook.Events, model.WebhookEvent(event))
+		}
+	}
+	hook.CreatedAt = createdAt.UTC().Format(time.RFC3339Nano)
+	hook.UpdatedAt = updatedAt.UTC().Format(time.RFC3339Nano)
+	return hook, nil
+}
+
+// joinWebhookEvents validates and de-duplicates events in model.WebhookEvents
+// order for string_to_array.
+func joinWebhookEvents(events []model.WebhookEvent) (string, error) {
+	if len(events) == 0 {
+		return "", fmt.Errorf("webhook events required")
+	}
+	selected := map[model.WebhookEvent]bool{}
+	for _, event := range events {
+		if !event.Valid() {
+			return "", fmt.Errorf("webhook event invalid")
+		}
+		selected[event] = true
+	}
+	ordered := make([]string, 0, len(selected))
+	for _, event := range model.WebhookEvents {
+		if selected[event] {
+			ordered = append(ordered, string(event))
+		}
+	}
+	return strings.Join(ordered, ","), nil
+}
+
+func nullTimeString(value sql.NullTime) *string {
+	if !value.Valid {
+		return nil
+	}
+	formatted := value.Time.UTC().Format(time.RFC3339Nano)
+	return &formatted
+}
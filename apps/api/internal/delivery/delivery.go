@@ -0,0 +1,143 @@
+// Package delivery emails stories to readers' devices. Sending a story queues
+// a delivery row; a Dispatcher claims due rows, makes the published version
+// into an EPUB and mails it through the configured SMTP relay, recording each
+// attempt so failures retry with backoff, as webhook deliveries do.
+package delivery
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"time"
+
+	"pandapages/api/internal/epub"
+	"pandapages/api/internal/model"
+)
+
+const (
+	defaultInterval = 10 * time.Second
+	claimLease      = 5 * time.Minute
+	claimBatch      = 5
+
+	// MaxBookBytes keeps a book, once base64 grows it by a third, inside
+	// the 25 MB most relays and device mailboxes accept.
+	MaxBookBytes = 18 << 20
+)
+
+// retryDelays is the wait after each failed attempt. A delivery is marked
+// failed once every delay has been used.
+var retryDelays = []time.Duration{
+	time.Minute,
+	5 * time.Minute,
+	30 * time.Minute,
+	2 * time.Hour,
+}
+
+type Store interface {
+	DeliveryClaim(ctx context.Context, limit int, lease time.Duration) ([]model.PendingStoryDelivery, error)
+	DeliveryRecordAttempt(ctx context.Context, deliveryID string, attempt model.StoryDeliveryAttempt) error
+	ReaderStory(ctx context.Context, accountID, slug string) (model.ReaderStory, error)
+	Media(ctx context.Context, accountID, mediaID string) (model.Media, []byte, error)
+}
+
+// Sender mails one message; *SMTP is the production implementation.
+type Sender interface {
+	Send(ctx context.Context, message Message) error
+}
+
+type Dispatcher struct {
+	store    Store
+	sender   Sender
+	interval time.Duration
+	now      func() time.Time
+}
+
+func NewDispatcher(store Store, sender Sender) *Dispatcher {
+	return &Dispatcher{store: store, sender: sender, interval: defaultInterval, now: time.Now}
+}
+
+// Run sends due deliveries until ctx is cancelled.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+	for {
+		d.RunOnce(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce claims one batch of due deliveries and attempts each of them.
+func (d *Dispatcher) RunOnce(ctx context.Context) {
+	deliveries, err := d.store.DeliveryClaim(ctx, claimBatch, claimLease)
+	if err != nil {
+		slog.Error("story delivery claim failed")
+		return
+	}
+	for _, delivery := range deliveries {
+		if ctx.Err() != nil {
+			// Unattempted claims become due again when their lease expires.
+			return
+		}
+		attempt := d.deliver(ctx, delivery)
+		if attempt.Error != "" {
+			slog.Warn("story delivery attempt failed", "delivery_id", delivery.ID, "reason", attempt.Error)
+		}
+		// A sent book is recorded even during shutdown, or it would be mailed
+		// again once its lease expires.
+		if err := d.store.DeliveryRecordAttempt(context.WithoutCancel(ctx), delivery.ID, attempt); err != nil {
+			slog.Error("story delivery record failed")
+		}
+	}
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, delivery model.PendingStoryDelivery) model.StoryDeliveryAttempt {
+	now := d.now()
+	story, err := d.store.ReaderStory(ctx, delivery.AccountID, delivery.Slug)
+	if errors.Is(err, sql.ErrNoRows) || errors.Is(err, model.ErrStoryRemoved) {
+		// Unpublished since it was sent; there is nothing to retry.
+		return model.StoryDeliveryAttempt{Error: "story_unavailable"}
+	}
+	if err != nil {
+		return d.failed(delivery, now, 0, "story_query_failed")
+	}
+	book, err := epub.Build(story, func(id string) (model.Media, []byte, error) {
+		return d.store.Media(ctx, delivery.AccountID, id)
+	}, now)
+	if err != nil {
+		return d.failed(delivery, now, story.Version, "book_failed")
+	}
+	if len(book) > MaxBookBytes {
+		return model.StoryDeliveryAttempt{Version: story.Version, Error: "book_too_large"}
+	}
+
+	err = d.sender.Send(ctx, Message{
+		To:      delivery.Address,
+		Subject: story.Title,
+		Text:    story.Title + ", from Panda Pages.\n",
+		Attachment: Attachment{
+			Name:        story.Slug + ".epub",
+			ContentType: epub.ContentType,
+			Data:        book,
+		},
+	})
+	if err != nil {
+		// Relay errors can echo the address and server detail; the log and
+		// the delivery keep a fixed category instead.
+		return d.failed(delivery, now, story.Version, "send_failed")
+	}
+	return model.StoryDeliveryAttempt{Sent: true, Version: story.Version}
+}
+
+func (d *Dispatcher) failed(delivery model.PendingStoryDelivery, now time.Time, version int, reason string) model.StoryDeliveryAttempt {
+	attempt := model.StoryDeliveryAttempt{Version: version, Error: reason}
+	if delivery.Attempts < len(retryDelays) {
+		next := now.Add(retryDelays[delivery.Attempts])
+		attempt.NextAttemptAt = &next
+	}
+	return attempt
+}
@@ -0,0 +1,287 @@
+package delivery
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+
+	"pandapages/api/internal/model"
+)
+
+type fakeStore struct {
+	pending  []model.PendingStoryDelivery
+	attempts map[string]model.StoryDeliveryAttempt
+	storyErr error
+}
+
+func (s *fakeStore) DeliveryClaim(context.Context, int, time.Duration) ([]model.PendingStoryDelivery, error) {
+	claimed := s.pending
+	s.pending = nil
+	return claimed, nil
+}
+
+func (s *fakeStore) DeliveryRecordAttempt(_ context.Context, id string, attempt model.StoryDeliveryAttempt) error {
+	if s.attempts == nil {
+		s.attempts = map[string]model.StoryDeliveryAttempt{}
+	}
+	s.attempts[id] = attempt
+	return nil
+}
+
+func (s *fakeStore) ReaderStory(_ context.Context, accountID, slug string) (model.ReaderStory, error) {
+	if s.storyErr != nil {
+		return model.ReaderStory{}, s.storyErr
+	}
+	return model.ReaderStory{Slug: slug, Title: "Owls", Language: "en", Version: 4, Segments: []model.ReaderSegment{
+		{Ordinal: 1, Kind: "paragraph", RenderedHTML: "<p>Hoot.</p>"},
+	}}, nil
+}
+
+func (s *fakeStore) Media(context.Context, string, string) (model.Media, []byte, error) {
+	return model.Media{}, nil, model.ErrMediaNotFound
+}
+
+type fakeSender struct {
+	sent []Message
+	err  error
+}
+
+func (s *fakeSender) Send(_ context.Context, message Message) error {
+	s.sent = append(s.sent, message)
+	return s.err
+}
+
+func TestDispatcherMailsTheBook(t *testing.T) {
+	now := time.Date(2026, 10, 16, 19, 0, 0, 0, time.UTC)
+	store := &fakeStore{pending: []model.PendingStoryDelivery{{ID: "delivery-1", AccountID: "account-1", Slug: "owls", Address: "reader@kindle.com"}}}
+	sender := &fakeSender{}
+	d := NewDispatcher(store, sender)
+	d.now = func() time.Time { return now }
+	d.RunOnce(context.Background())
+
+	if len(sender.sent) != 1 {
+		t.Fatalf("sent = %d messages", len(sender.sent))
+	}
+	message := sender.sent[0]
+	if message.To != "reader@kindle.com" || message.Subject != "Owls" || message.Attachment.Name != "owls.epub" ||
+		message.Attachment.ContentType != "application/epub+zip" || len(message.Attachment.Data) == 0 {
+		t.Fatalf("message = %+v", message)
+	}
+	if attempt := store.attempts["delivery-1"]; !attempt.Sent || attempt.Version != 4 || attempt.Error != "" {
+		t.Fatalf("attempt = %#v", attempt)
+	}
+}
+
+func TestDispatcherRetriesSendFailuresOnly(t *testing.T) {
+	now := time.Date(2026, 10, 16, 19, 0, 0, 0, time.UTC)
+	store := &fakeStore{pending: []model.PendingStoryDelivery{
+		{ID: "first", Slug: "owls", Address: "a@example.com"},
+		{ID: "last", Slug: "owls", Address: "b@example.com", Attempts: len(retryDelays)},
+	}}
+	d := NewDispatcher(store, &fakeSender{err: errors.New("550 mailbox unavailable")})
+	d.now = func() time.Time { return now }
+	d.RunOnce(context.Background())
+
+	first := store.attempts["first"]
+	if first.Sent || first.Error != "send_failed" || first.NextAttemptAt == nil || !first.NextAttemptAt.Equal(now.Add(retryDelays[0])) {
+		t.Fatalf("first attempt = %#v", first)
+	}
+	if last := store.attempts["last"]; last.NextAttemptAt != nil || last.Error != "send_failed" {
+		t.Fatalf("final attempt = %#v", last)
+	}
+
+	store.pending = []model.PendingStoryDelivery{{ID: "removed", Slug: "owls", Address: "a@example.com"}}
+	store.storyErr = model.ErrStoryRemoved
+	sender := &fakeSender{}
+	d.sender = sender
+	d.RunOnce(context.Background())
+	if removed := store.attempts["removed"]; removed.NextAttemptAt != nil || removed.Error != "story_unavailable" || len(sender.sent) != 0 {
+		t.Fatalf("attempt for an unpublished story = %#v, sent %d", removed, len(sender.sent))
+	}
+}
+
+func TestLoadReadsTheRelay(t *testing.T) {
+	env := func(values map[string]string) func(string) string {
+		return func(key string) string { return values[key] }
+	}
+	if relay, err := Load(env(nil)); err != nil || relay != nil {
+		t.Fatalf("unconfigured Load = %v, %v", relay, err)
+	}
+
+	relay, err := Load(env(map[string]string{"PP_SMTP_HOST": "smtp.example.com", "PP_SMTP_PORT": "465", "PP_SMTP_FROM": "Panda Pages <books@example.com>"}))
+	if err != nil || relay.TLS != TLSImplicit || relay.From != "books@example.com" || relay.Name() != "smtp.example.com:465" {
+		t.Fatalf("Load = %#v, %v", relay, err)
+	}
+	relay, err = Load(env(map[string]string{"PP_SMTP_HOST": "smtp.example.com", "PP_SMTP_FROM": "books@example.com"}))
+	if err != nil || relay.TLS != TLSStartTLS || relay.Port != "587" {
+		t.Fatalf("default Load = %#v, %v", relay, err)
+	}
+
+	for name, values := range map[string]map[string]string{
+		"from":     {"PP_SMTP_HOST": "smtp.example.com"},
+		"tls":      {"PP_SMTP_HOST": "smtp.example.com", "PP_SMTP_FROM": "books@example.com", "PP_SMTP_TLS": "ssl"},
+		"port":     {"PP_SMTP_HOST": "smtp.example.com", "PP_SMTP_FROM": "books@example.com", "PP_SMTP_PORT": "smtp"},
+		"password": {"PP_SMTP_HOST": "smtp.example.com", "PP_SMTP_FROM": "books@example.com", "PP_SMTP_USERNAME": "books"},
+	} {
+		if _, err := Load(env(values)); err == nil {
+			t.Errorf("%s: Load succeeded", name)
+		}
+	}
+}
+
+func TestSMTPSendsTheMessageWithItsAttachment(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+	received := make(chan string, 1)
+	go serveSMTP(listener, received)
+
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	relay := &SMTP{Host: host, Port: port, From: "books@example.com", TLS: TLSNone}
+	data := []byte(strings.Repeat("epub", 40))
+	err = relay.Send(context.Background(), Message{
+		To:         "reader@kindle.com",
+		Subject:    "Owls\r\nBcc: someone@example.com",
+		Text:       "Owls, from Panda Pages.\n",
+		Attachment: Attachment{Name: "owls.epub", ContentType: "application/epub+zip", Data: data},
+	})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	message, err := mail.ReadMessage(strings.NewReader(<-received))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if message.Header.Get("Bcc") != "" || message.Header.Get("To") != "reader@kindle.com" || message.Header.Get("From") != "books@example.com" {
+		t.Fatalf("headers = %v", message.Header)
+	}
+	_, params, err := mime.ParseMediaType(message.Header.Get("Content-Type"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := multipart.NewReader(message.Body, params["boundary"])
+	if text, err := parts.NextPart(); err != nil || text.Header.Get("Content-Type") != "text/plain; charset=utf-8" {
+		t.Fatalf("text part = %v, %v", text, err)
+	}
+	attachment, err := parts.NextPart()
+	if err != nil || attachment.FileName() != "owls.epub" {
+		t.Fatalf("attachment = %v, %v", attachment, err)
+	}
+	// multipart decodes quoted-printable only; base64 is left to the reader.
+	encoded, _ := io.ReadAll(attachment)
+	for _, line := range strings.Split(strings.TrimSpace(string(encoded)), "\r\n") {
+		if len(line) > 76 {
+			t.Fatalf("attachment line of %d characters", len(line))
+		}
+	}
+}
+
+func TestSMTPSendsHTMLWithATextAlternative(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+	received := make(chan string, 1)
+	go serveSMTP(listener, received)
+
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	relay := &SMTP{Host: host, Port: port, From: "books@example.com", TLS: TLSNone}
+	err = relay.Send(context.Background(), Message{
+		To:      "parent@example.com",
+		Subject: "This week",
+		Text:    "Ada read for 20 minutes.\n",
+		HTML:    "<p>Ada read for <b>20 minutes</b>.</p>",
+	})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	message, err := mail.ReadMessage(strings.NewReader(<-received))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, params, _ := mime.ParseMediaType(message.Header.Get("Content-Type"))
+	outer := multipart.NewReader(message.Body, params["boundary"])
+	alternative, err := outer.NextPart()
+	if err != nil {
+		t.Fatal(err)
+	}
+	mediaType, params, _ := mime.ParseMediaType(alternative.Header.Get("Content-Type"))
+	if mediaType != "multipart/alternative" {
+		t.Fatalf("first part = %s", mediaType)
+	}
+	inner := multipart.NewReader(alternative, params["boundary"])
+	var types []string
+	var html string
+	for {
+		part, err := inner.NextPart()
+		if err != nil {
+			break
+		}
+		types = append(types, part.Header.Get("Content-Type"))
+		content, _ := io.ReadAll(part)
+		if strings.HasPrefix(part.Header.Get("Content-Type"), "text/html") {
+			html = string(content)
+		}
+	}
+	if strings.Join(types, ",") != "text/plain; charset=utf-8,text/html; charset=utf-8" || !strings.Contains(html, "<b>20 minutes</b>") {
+		t.Fatalf("alternatives = %v, html %q", types, html)
+	}
+	if _, err := outer.NextPart(); err != io.EOF {
+		t.Fatalf("a message without an attachment has another part: %v", err)
+	}
+}
+
+// serveSMTP answers one SMTP conversation and sends back the message data.
+func serveSMTP(listener net.Listener, received chan<- string) {
+	conn, err := listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	reply := func(line string) { _, _ = io.WriteString(conn, line+"\r\n") }
+	reply("220 test")
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		switch command := strings.ToUpper(strings.TrimSpace(line)); {
+		case strings.HasPrefix(command, "EHLO"):
+			reply("250 test")
+		case command == "DATA":
+			reply("354 go ahead")
+			var data strings.Builder
+			for {
+				line, err := reader.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if line == ".\r\n" {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(line, "."))
+			}
+			received <- data.String()
+			reply("250 queued")
+		case command == "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("250 ok")
+		}
+	}
+}
@@ -0,0 +1,265 @@
+package delivery
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The ways a relay connection is secured: upgraded with STARTTLS, encrypted
+// from the start (usually port 465), or not at all, for a relay on the same
+// host or network.
+const (
+	TLSStartTLS = "starttls"
+	TLSImplicit = "tls"
+	TLSNone     = "none"
+
+	// sendTimeout bounds one whole conversation with the relay, attachment
+	// included.
+	sendTimeout = 2 * time.Minute
+)
+
+// Message is one email: plain text, with an HTML alternative when HTML is
+// set, and a single attachment when Attachment has a name.
+type Message struct {
+	To         string
+	Subject    string
+	Text       string
+	HTML       string
+	Attachment Attachment
+}
+
+type Attachment struct {
+	Name        string
+	ContentType string
+	Data        []byte
+}
+
+// SMTP sends mail through one relay.
+type SMTP struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+	TLS      string
+}
+
+// Load reads the relay from PP_SMTP_*. It returns nil, and no error, when
+// PP_SMTP_HOST is unset.
+func Load(getenv func(string) string) (*SMTP, error) {
+	relay := &SMTP{
+		Host:     strings.TrimSpace(getenv("PP_SMTP_HOST")),
+		Port:     strings.TrimSpace(getenv("PP_SMTP_PORT")),
+		Username: strings.TrimSpace(getenv("PP_SMTP_USERNAME")),
+		Password: getenv("PP_SMTP_PASSWORD"),
+		TLS:      strings.ToLower(strings.TrimSpace(getenv("PP_SMTP_TLS"))),
+	}
+	if relay.Host == "" {
+		return nil, nil
+	}
+	if relay.TLS == "" {
+		relay.TLS = TLSStartTLS
+		if relay.Port == "465" {
+			relay.TLS = TLSImplicit
+		}
+	}
+	if relay.TLS != TLSStartTLS && relay.TLS != TLSImplicit && relay.TLS != TLSNone {
+		return nil, fmt.Errorf("PP_SMTP_TLS must be starttls, tls or none")
+	}
+	if relay.Port == "" {
+		relay.Port = "587"
+		if relay.TLS == TLSImplicit {
+			relay.Port = "465"
+		}
+	}
+	if port, err := strconv.Atoi(relay.Port); err != nil || port < 1 || port > 65535 {
+		return nil, fmt.Errorf("PP_SMTP_PORT must be a port number")
+	}
+	from, err := mail.ParseAddress(strings.TrimSpace(getenv("PP_SMTP_FROM")))
+	if err != nil {
+		return nil, fmt.Errorf("PP_SMTP_FROM must be an email address when PP_SMTP_HOST is set")
+	}
+	relay.From = from.Address
+	if (relay.Username == "") != (relay.Password == "") {
+		return nil, fmt.Errorf("PP_SMTP_USERNAME and PP_SMTP_PASSWORD must be set together")
+	}
+	return relay, nil
+}
+
+// Name identifies the relay in the startup summary without its credentials.
+func (m *SMTP) Name() string {
+	return net.JoinHostPort(m.Host, m.Port)
+}
+
+// Send delivers message through the relay. A relay that does not offer
+// STARTTLS when it is expected is refused rather than sent to in clear.
+func (m *SMTP) Send(ctx context.Context, message Message) error {
+	to, err := mail.ParseAddress(message.To)
+	if err != nil {
+		return fmt.Errorf("recipient is not an email address")
+	}
+	data, err := message.encode(m.From, to.Address, time.Now())
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, sendTimeout)
+	defer cancel()
+	address := net.JoinHostPort(m.Host, m.Port)
+	dialer := &net.Dialer{}
+	var conn net.Conn
+	if m.TLS == TLSImplicit {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: m.Host}}).DialContext(ctx, "tcp", address)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", address)
+	}
+	if err != nil {
+		return err
+	}
+	deadline, _ := ctx.Deadline()
+	_ = conn.SetDeadline(deadline)
+	client, err := smtp.NewClient(conn, m.Host)
+	if err != nil {
+		_ = conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if m.TLS == TLSStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return fmt.Errorf("SMTP relay does not offer STARTTLS")
+		}
+		if err := client.StartTLS(&tls.Config{ServerName: m.Host}); err != nil {
+			return err
+		}
+	}
+	if m.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", m.Username, m.Password, m.Host)); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(m.From); err != nil {
+		return err
+	}
+	if err := client.Rcpt(to.Address); err != nil {
+		return err
+	}
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := writer.Write(data); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// encode writes message as a MIME email: the text, or the text and HTML as
+// alternatives, then any attachment, base64 in 76-character lines.
+func (message Message) encode(from, to string, date time.Time) ([]byte, error) {
+	var body bytes.Buffer
+	parts := multipart.NewWriter(&body)
+
+	if message.HTML == "" {
+		if err := writeQuoted(parts, "text/plain; charset=utf-8", message.Text); err != nil {
+			return nil, err
+		}
+	} else {
+		var alternatives bytes.Buffer
+		inner := multipart.NewWriter(&alternatives)
+		if err := writeQuoted(inner, "text/plain; charset=utf-8", message.Text); err != nil {
+			return nil, err
+		}
+		if err := writeQuoted(inner, "text/html; charset=utf-8", message.HTML); err != nil {
+			return nil, err
+		}
+		if err := inner.Close(); err != nil {
+			return nil, err
+		}
+		part, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type": {mime.FormatMediaType("multipart/alternative", map[string]string{"boundary": inner.Boundary()})},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if _, err := part.Write(alternatives.Bytes()); err != nil {
+			return nil, err
+		}
+	}
+
+	if message.Attachment.Name != "" {
+		attachment, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {mime.FormatMediaType(message.Attachment.ContentType, map[string]string{"name": message.Attachment.Name})},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": message.Attachment.Name})},
+			"Content-Transfer-Encoding": {"base64"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		encoded := base64.StdEncoding.EncodeToString(message.Attachment.Data)
+		for len(encoded) > 76 {
+			if _, err := attachment.Write([]byte(encoded[:76] + "\r\n")); err != nil {
+				return nil, err
+			}
+			encoded = encoded[76:]
+		}
+		if _, err := attachment.Write([]byte(encoded + "\r\n")); err != nil {
+			return nil, err
+		}
+	}
+	if err := parts.Close(); err != nil {
+		return nil, err
+	}
+
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	_, domain, _ := strings.Cut(from, "@")
+	var out bytes.Buffer
+	// Subject can be a story title; the encoder also keeps line breaks in it from
+	// starting a new header.
+	fmt.Fprintf(&out, "From: %s\r\n", from)
+	fmt.Fprintf(&out, "To: %s\r\n", to)
+	fmt.Fprintf(&out, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", message.Subject))
+	fmt.Fprintf(&out, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	fmt.Fprintf(&out, "Message-ID: <%s@%s>\r\n", hex.EncodeToString(id), domain)
+	out.WriteString("MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&out, "Content-Type: multipart/mixed; boundary=%q\r\n\r\n", parts.Boundary())
+	out.Write(body.Bytes())
+	return out.Bytes(), nil
+}
+
+// writeQuoted adds a quoted-printable part of contentType.
+func writeQuoted(parts *multipart.Writer, contentType, content string) error {
+	part, err := parts.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {contentType},
+		"Content-Transfer-Encoding": {"quoted-printable"},
+	})
+	if err != nil {
+		return err
+	}
+	quoted := quotedprintable.NewWriter(part)
+	if _, err := quoted.Write([]byte(content)); err != nil {
+		return err
+	}
+	return quoted.Close()
+}
@@ -0,0 +1,196 @@
+// Package dictionary looks up the words a child taps in the Reader through a
+// dictionary service with the Free Dictionary API's interface
+// (https://dictionaryapi.dev). Answers are kept in the database for every
+// account, since a word means the same to each, and are passed through a
+// filter that leaves out senses not meant for children before they are shown.
+package dictionary
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"pandapages/api/internal/model"
+)
+
+const (
+	defaultURL     = "https://api.dictionaryapi.dev/api/v2/entries"
+	requestTimeout = 10 * time.Second
+	// maxResponseBytes bounds a reply; the longest common entries are about
+	// 100 KB.
+	maxResponseBytes = 1 << 20
+	// maxStoredSenses bounds what is kept of one word. The filter runs when a
+	// word is shown, so a change to it applies to words already kept.
+	maxStoredSenses = 12
+
+	// CacheTTL is how long a kept answer is served before the dictionary is
+	// asked again, and MissTTL the same for a word it did not know, which new
+	// entries are more likely to change.
+	CacheTTL = 30 * 24 * time.Hour
+	MissTTL  = 24 * time.Hour
+
+	MaxWordRunes = 48
+)
+
+// ErrDictionary marks a dictionary that could not be reached or answered
+// with an error status or a reply that could not be used. Its message never
+// carries the reply.
+var ErrDictionary = errors.New("dictionary failed")
+
+// Client asks one dictionary service.
+type Client struct {
+	URL    string
+	client *http.Client
+}
+
+// Load returns the dictionary PP_DICTIONARY_URL names, the public Free
+// Dictionary API when it is unset, or nil when it is off.
+func Load(getenv func(string) string) (*Client, error) {
+	endpoint := strings.TrimSpace(getenv("PP_DICTIONARY_URL"))
+	switch endpoint {
+	case "":
+		endpoint = defaultURL
+	case "off":
+		return nil, nil
+	}
+	if parsed, err := url.Parse(endpoint); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("PP_DICTIONARY_URL must be an http or https URL or off")
+	}
+	return New(endpoint), nil
+}
+
+// New returns a client for the entries endpoint at baseURL, under which a
+// word is at /{language}/{word}.
+func New(baseURL string) *Client {
+	return &Client{URL: strings.TrimRight(baseURL, "/"), client: &http.Client{Timeout: requestTimeout}}
+}
+
+// Name identifies the dictionary in the startup summary.
+func (c *Client) Name() string {
+	if parsed, err := url.Parse(c.URL); err == nil {
+		return parsed.Host
+	}
+	return "dictionary"
+}
+
+// Fetch returns the senses the dictionary lists for word, unfiltered. It
+// reports false, and no error, for a word the dictionary does not know.
+func (c *Client) Fetch(ctx context.Context, language, word string) (model.Definition, bool, error) {
+	endpoint := c.URL + "/" + url.PathEscape(language) + "/" + url.PathEscape(word)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return model.Definition{}, false, err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "pandapages-dictionary/1")
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return model.Definition{}, false, fmt.Errorf("%w: request failed", ErrDictionary)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return model.Definition{}, false, nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return model.Definition{}, false, fmt.Errorf("%w: status %d", ErrDictionary, resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes+1))
+	if err != nil {
+		return model.Definition{}, false, fmt.Errorf("%w: reply could not be read", ErrDictionary)
+	}
+	if len(data) > maxResponseBytes {
+		return model.Definition{}, false, fmt.Errorf("%w: reply exceeds %d bytes", ErrDictionary, maxResponseBytes)
+	}
+	var reply []entry
+	if err := json.Unmarshal(data, &reply); err != nil {
+		return model.Definition{}, false, fmt.Errorf("%w: reply is not a list of entries", ErrDictionary)
+	}
+	out := normalize(word, language, reply)
+	return out, len(out.Senses) > 0, nil
+}
+
+type entry struct {
+	Word      string `json:"word"`
+	Phonetic  string `json:"phonetic"`
+	Phonetics []struct {
+		Text string `json:"text"`
+	} `json:"phonetics"`
+	Meanings []struct {
+		PartOfSpeech string `json:"partOfSpeech"`
+		Definitions  []struct {
+			Definition string `json:"definition"`
+			Example    string `json:"example"`
+		} `json:"definitions"`
+	} `json:"meanings"`
+}
+
+func normalize(word, language string, entries []entry) model.Definition {
+	out := model.Definition{Word: word, Language: language, Senses: []model.WordSense{}}
+	for _, entry := range entries {
+		if out.Phonetic == nil {
+			phonetic := strings.TrimSpace(entry.Phonetic)
+			for _, candidate := range entry.Phonetics {
+				if phonetic != "" {
+					break
+				}
+				phonetic = strings.TrimSpace(candidate.Text)
+			}
+			if phonetic != "" {
+				out.Phonetic = &phonetic
+			}
+		}
+		for _, meaning := range entry.Meanings {
+			for _, definition := range meaning.Definitions {
+				text := strings.Join(strings.Fields(definition.Definition), " ")
+				if text == "" || len(out.Senses) == maxStoredSenses {
+					continue
+				}
+				sense := model.WordSense{PartOfSpeech: strings.TrimSpace(meaning.PartOfSpeech), Definition: text}
+				if example := strings.Join(strings.Fields(definition.Example), " "); example != "" {
+					sense.Example = &example
+				}
+				out.Senses = append(out.Senses, sense)
+			}
+		}
+	}
+	return out
+}
+
+var languageRe = regexp.MustCompile(`^[a-z]{2,3}$`)
+
+// NormalizeLanguage reduces a language tag to the lowercased primary
+// language the dictionary is keyed by, so "en-GB" is "en". Empty is English.
+func NormalizeLanguage(raw string) (string, bool) {
+	primary, _, _ := strings.Cut(strings.TrimSpace(raw), "-")
+	primary = strings.ToLower(primary)
+	if primary == "" {
+		return "en", true
+	}
+	return primary, languageRe.MatchString(primary)
+}
+
+// NormalizeWord lowercases a tapped word and trims the punctuation and
+// quotes around it. A word is letters, with apostrophes and hyphens inside;
+// anything else, such as a phrase or a number, is refused.
+func NormalizeWord(raw string) (string, bool) {
+	word := strings.ToLower(strings.TrimFunc(raw, func(r rune) bool { return !unicode.IsLetter(r) }))
+	word = strings.ReplaceAll(word, "’", "'")
+	if word == "" || utf8.RuneCountInString(word) > MaxWordRunes {
+		return "", false
+	}
+	for _, r := range word {
+		if !unicode.IsLetter(r) && !unicode.Is(unicode.Mn, r) && r != '\'' && r != '-' {
+			return "", false
+		}
+	}
+	return word, true
+}
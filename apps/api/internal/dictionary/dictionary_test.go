@@ -0,0 +1,129 @@
+package dictionary
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"pandapages/api/internal/model"
+)
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+		err   bool
+	}{
+		{value: "", want: defaultURL},
+		{value: "off"},
+		{value: "http://dictionary.internal/entries/", want: "http://dictionary.internal/entries"},
+		{value: "dictionary.internal", err: true},
+	}
+	for _, test := range tests {
+		client, err := Load(func(key string) string {
+			if key == "PP_DICTIONARY_URL" {
+				return test.value
+			}
+			return ""
+		})
+		if (err != nil) != test.err {
+			t.Fatalf("Load(%q) err = %v", test.value, err)
+		}
+		got := ""
+		if client != nil {
+			got = client.URL
+		}
+		if got != test.want {
+			t.Fatalf("Load(%q) = %q, want %q", test.value, got, test.want)
+		}
+	}
+}
+
+func TestFetchNormalizesTheEntries(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/en/owl":
+			w.Write([]byte(`[{"word":"owl","phonetics":[{"text":""},{"text":"/aʊl/"}],"meanings":[
+				{"partOfSpeech":"noun","definitions":[{"definition":"  A bird that\nhunts at night. ","example":"An owl hooted."},{"definition":""}]}]},
+				{"word":"owl","meanings":[{"partOfSpeech":"verb","definitions":[{"definition":"To act wisely."}]}]}]`))
+		case "/en/zorblat":
+			w.WriteHeader(http.StatusNotFound)
+		case "/en/broken":
+			w.Write([]byte(`{"title":"oops"}`))
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+	client := New(server.URL)
+
+	definition, ok, err := client.Fetch(context.Background(), "en", "owl")
+	if err != nil || !ok {
+		t.Fatalf("Fetch owl = %v, %v", ok, err)
+	}
+	if definition.Phonetic == nil || *definition.Phonetic != "/aʊl/" || len(definition.Senses) != 2 ||
+		definition.Senses[0].Definition != "A bird that hunts at night." || definition.Senses[0].Example == nil ||
+		definition.Senses[1].PartOfSpeech != "verb" || definition.Senses[1].Example != nil {
+		t.Fatalf("definition = %#v", definition)
+	}
+	if _, ok, err := client.Fetch(context.Background(), "en", "zorblat"); ok || err != nil {
+		t.Fatalf("Fetch unknown = %v, %v", ok, err)
+	}
+	for _, word := range []string{"broken", "down"} {
+		if _, _, err := client.Fetch(context.Background(), "en", word); !errors.Is(err, ErrDictionary) {
+			t.Fatalf("Fetch %s err = %v", word, err)
+		}
+	}
+}
+
+func TestNormalizeWordAndLanguage(t *testing.T) {
+	words := map[string]string{"“Owl!”": "owl", "Don’t": "don't", "well-known,": "well-known", "two words": "", "42": "", "": ""}
+	for raw, want := range words {
+		if got, ok := NormalizeWord(raw); got != want || ok != (want != "") {
+			t.Fatalf("NormalizeWord(%q) = %q, %v", raw, got, ok)
+		}
+	}
+	languages := map[string]string{"": "en", "en-GB": "en", "FR": "fr", "english": "", "e": ""}
+	for raw, want := range languages {
+		if got, ok := NormalizeLanguage(raw); ok != (want != "") || (ok && got != want) {
+			t.Fatalf("NormalizeLanguage(%q) = %q, %v", raw, got, ok)
+		}
+	}
+}
+
+func TestKidSafeLeavesOutSensesNotMeantForChildren(t *testing.T) {
+	rude := "What a cock-up."
+	plain := "The cock crowed at dawn."
+	definition := model.Definition{Word: "cock", Language: "en", Senses: []model.WordSense{
+		{PartOfSpeech: "noun", Definition: "A male bird, especially a rooster.", Example: &plain},
+	}}
+	if _, ok := KidSafe(definition); ok {
+		t.Fatal("a blocked word was defined")
+	}
+
+	definition.Word = "rooster"
+	definition.Senses = []model.WordSense{
+		{PartOfSpeech: "noun", Definition: "(slang, vulgar) Something rude."},
+		{PartOfSpeech: "noun", Definition: strings.Repeat("long ", 50)},
+		{PartOfSpeech: "noun", Definition: "A male chicken.", Example: &rude},
+		{PartOfSpeech: "noun", Definition: "A weathervane shaped like one."},
+		{PartOfSpeech: "verb", Definition: "To strut."},
+		{PartOfSpeech: "verb", Definition: "To crow."},
+	}
+	got, ok := KidSafe(definition)
+	if !ok || len(got.Senses) != MaxSenses || got.Senses[0].Definition != "A male chicken." || got.Senses[0].Example != nil ||
+		got.Senses[2].Definition != "To strut." {
+		t.Fatalf("KidSafe = %#v, %v", got, ok)
+	}
+	if len(definition.Senses) != 6 || definition.Senses[2].Example == nil {
+		t.Fatal("KidSafe changed its argument")
+	}
+
+	definition.Senses = definition.Senses[:2]
+	if _, ok := KidSafe(definition); ok {
+		t.Fatal("a word with no safe sense was defined")
+	}
+}
@@ -0,0 +1,84 @@
+package dictionary
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"pandapages/api/internal/model"
+)
+
+const (
+	// MaxSenses is how many senses a child is shown; the first ones a
+	// dictionary lists are the most common.
+	MaxSenses = 3
+	// maxDefinitionRunes leaves out the long, technical senses a child
+	// would not follow.
+	maxDefinitionRunes = 200
+)
+
+// blockedWords are never defined for a child, and a sense that uses one is
+// left out. The list is English, as are the dictionary's labels.
+var blockedWords = wordSet(`
+	sex sexual sexually sexy intercourse copulate copulation coitus genital genitals genitalia
+	penis vagina vulva clitoris testicle testicles scrotum anus erection orgasm ejaculate
+	masturbate masturbation porn porno pornography pornographic erotic nude nudity
+	prostitute prostitution brothel whore slut rape raped rapist incest
+	fuck fucking shit cunt bitch bastard dick cock pussy twat wank
+	cocaine heroin methamphetamine suicide
+`)
+
+// blockedLabels mark senses a dictionary itself flags as crude or hurtful;
+// the word may still have other senses worth showing.
+var blockedLabels = wordSet(`
+	vulgar offensive derogatory slur slurs obscene pejorative taboo slang
+`)
+
+func wordSet(list string) map[string]bool {
+	out := map[string]bool{}
+	for _, word := range strings.Fields(list) {
+		out[word] = true
+	}
+	return out
+}
+
+// Blocked reports whether a normalized word is one never defined for a
+// child.
+func Blocked(word string) bool {
+	return blockedWords[word]
+}
+
+// unsafe reports whether text uses a blocked word or label.
+func unsafe(text string) bool {
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool { return !unicode.IsLetter(r) }) {
+		if blockedWords[word] || blockedLabels[word] {
+			return true
+		}
+	}
+	return false
+}
+
+// KidSafe returns the senses of definition a child may be shown, at most
+// MaxSenses of them: those that neither use a blocked word or label nor run
+// too long. An example that fails the same test is dropped from a sense that
+// passes. It reports false when the word is blocked or no sense is left.
+func KidSafe(definition model.Definition) (model.Definition, bool) {
+	if Blocked(definition.Word) {
+		return model.Definition{}, false
+	}
+	out := definition
+	out.Senses = make([]model.WordSense, 0, MaxSenses)
+	for _, sense := range definition.Senses {
+		if len(out.Senses) == MaxSenses {
+			break
+		}
+		if unsafe(sense.Definition) || utf8.RuneCountInString(sense.Definition) > maxDefinitionRunes {
+			continue
+		}
+		if sense.Example != nil && unsafe(*sense.Example) {
+			sense.Example = nil
+		}
+		out.Senses = append(out.Senses, sense)
+	}
+	return out, len(out.Senses) > 0
+}
@@ -0,0 +1,125 @@
+// Package digest emails each opted-in account a weekly report of its
+// reading: minutes per child, books finished and reading streaks. A
+// Dispatcher claims the accounts whose send time has come in their time zone
+// and mails the report through the SMTP relay story delivery uses.
+package digest
+
+import (
+	"bytes"
+	htmltemplate "html/template"
+	"strconv"
+	texttemplate "text/template"
+	"time"
+
+	"pandapages/api/internal/model"
+)
+
+// unassigned names reading saved while no child profile was active.
+const unassigned = "Reading without a profile"
+
+var templateFuncs = map[string]any{
+	"name":    childName,
+	"minutes": minutes,
+	"days":    days,
+}
+
+var textTemplate = texttemplate.Must(texttemplate.New("text").Funcs(templateFuncs).Parse(`Your week of reading, {{.Dates}}
+{{range .Report.Children}}
+{{name .}}
+  Read for {{minutes .Minutes}}{{if .StreakDays}}, on a {{days .StreakDays}} streak{{end}}.
+{{- range .BooksFinished}}
+  Finished {{.Title}}
+{{- end}}
+{{else}}
+No reading was saved this week. A bedtime story is a fine way to start.
+{{end}}
+From Panda Pages. Turn this email off in the Reader's settings.
+`))
+
+var htmlTemplate = htmltemplate.Must(htmltemplate.New("html").Funcs(templateFuncs).Parse(`<!doctype html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Your week of reading</title>
+</head>
+<body style="margin:0;padding:1em;font:16px/1.5 Georgia,serif;color:#222">
+<h1 style="font-size:1.4em">Your week of reading</h1>
+<p style="color:#555">{{.Dates}}</p>
+{{range .Report.Children}}
+<h2 style="font-size:1.15em;margin-bottom:0.25em">{{name .}}</h2>
+<p style="margin-top:0">Read for <strong>{{minutes .Minutes}}</strong>{{if .StreakDays}}, on a <strong>{{days .StreakDays}}</strong> streak{{end}}.</p>
+{{- if .BooksFinished}}
+<ul>
+{{- range .BooksFinished}}
+<li>Finished <em>{{.Title}}</em></li>
+{{- end}}
+</ul>
+{{- end}}
+{{else}}
+<p>No reading was saved this week. A bedtime story is a fine way to start.</p>
+{{end}}
+<p style="color:#555;font-size:0.85em">From Panda Pages. Turn this email off in the Reader's settings.</p>
+</body>
+</html>
+`))
+
+type page struct {
+	Dates  string
+	Report model.ReadingReport
+}
+
+// Render writes the report as an email's subject, plain text and HTML.
+// Names and titles are escaped in the HTML.
+func Render(report model.ReadingReport) (subject, text, html string, err error) {
+	data := page{Dates: dates(report.From, report.To), Report: report}
+	var textOut, htmlOut bytes.Buffer
+	if err := textTemplate.Execute(&textOut, data); err != nil {
+		return "", "", "", err
+	}
+	if err := htmlTemplate.Execute(&htmlOut, data); err != nil {
+		return "", "", "", err
+	}
+	return "Your week of reading, " + data.Dates, textOut.String(), htmlOut.String(), nil
+}
+
+func childName(child model.ChildReading) string {
+	if child.ChildProfileID == nil {
+		return unassigned
+	}
+	if child.Name == "" {
+		return "A child without a name"
+	}
+	return child.Name
+}
+
+func minutes(count int) string {
+	if count == 1 {
+		return "1 minute"
+	}
+	return strconv.Itoa(count) + " minutes"
+}
+
+func days(count int) string {
+	if count == 1 {
+		return "1-day"
+	}
+	return strconv.Itoa(count) + "-day"
+}
+
+// dates formats the week as 11 Oct to 17 Oct 2026, or leaves the stored
+// dates as they are if they cannot be read.
+func dates(from, to string) string {
+	start, err := time.Parse(time.DateOnly, from)
+	if err != nil {
+		return from + " to " + to
+	}
+	end, err := time.Parse(time.DateOnly, to)
+	if err != nil {
+		return from + " to " + to
+	}
+	layout := "2 Jan"
+	if start.Year() != end.Year() {
+		layout = "2 Jan 2006"
+	}
+	return start.Format(layout) + " to " + end.Format("2 Jan 2006")
+}
@@ -0,0 +1,122 @@
+package digest
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"pandapages/api/internal/delivery"
+	"pandapages/api/internal/model"
+)
+
+func testReport() model.ReadingReport {
+	ada := "3a0c7f0e-8b7a-4f55-9a43-a0a1b3b1c2d3"
+	return model.ReadingReport{
+		From:     "2026-10-11",
+		To:       "2026-10-17",
+		TimeZone: "Europe/London",
+		Children: []model.ChildReading{
+			{ChildProfileID: &ada, Name: "Ada <3", Minutes: 42, StreakDays: 5, BooksFinished: []model.FinishedBook{{Slug: "owls", Title: "Owls & Moons"}}},
+			{Name: "", Minutes: 1, BooksFinished: []model.FinishedBook{}},
+		},
+	}
+}
+
+func TestRenderWritesTheWeek(t *testing.T) {
+	subject, text, html, err := Render(testReport())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if subject != "Your week of reading, 11 Oct to 17 Oct 2026" {
+		t.Fatalf("subject = %q", subject)
+	}
+	for _, want := range []string{"Ada <3", "Read for 42 minutes, on a 5-day streak.", "Finished Owls & Moons", unassigned, "Read for 1 minute."} {
+		if !strings.Contains(text, want) {
+			t.Errorf("text lacks %q:\n%s", want, text)
+		}
+	}
+	for _, want := range []string{"Ada &lt;3", "<em>Owls &amp; Moons</em>", "<strong>42 minutes</strong>"} {
+		if !strings.Contains(html, want) {
+			t.Errorf("html lacks %q:\n%s", want, html)
+		}
+	}
+	if strings.Contains(html, "Ada <3") {
+		t.Fatal("a child's name was not escaped")
+	}
+
+	_, text, _, err = Render(model.ReadingReport{From: "2026-12-28", To: "2027-01-03"})
+	if err != nil || !strings.Contains(text, "28 Dec 2026 to 3 Jan 2027") || !strings.Contains(text, "No reading was saved") {
+		t.Fatalf("empty week = %q, %v", text, err)
+	}
+}
+
+type fakeStore struct {
+	due      []model.DueDigest
+	reportAt time.Time
+	recorded map[string]model.DigestAttempt
+}
+
+func (s *fakeStore) DigestClaim(context.Context, int, time.Duration) ([]model.DueDigest, error) {
+	return s.due, nil
+}
+
+func (s *fakeStore) DigestRecordAttempt(_ context.Context, accountID string, attempt model.DigestAttempt) error {
+	s.recorded[accountID] = attempt
+	return nil
+}
+
+func (s *fakeStore) ReadingReport(_ context.Context, _ string, at time.Time) (model.ReadingReport, error) {
+	s.reportAt = at
+	return testReport(), nil
+}
+
+type fakeSender struct {
+	sent []delivery.Message
+	fail map[string]bool
+}
+
+func (f *fakeSender) Send(_ context.Context, message delivery.Message) error {
+	if f.fail[message.To] {
+		return errors.New("550 mailbox parent@example.com unavailable")
+	}
+	f.sent = append(f.sent, message)
+	return nil
+}
+
+func TestDispatcherMailsDueDigests(t *testing.T) {
+	now := time.Date(2026, 10, 17, 18, 0, 0, 0, time.UTC)
+	store := &fakeStore{
+		due: []model.DueDigest{
+			{AccountID: "sent", Address: "parent@example.com"},
+			{AccountID: "retry", Address: "bounce@example.com", Attempts: 1},
+			{AccountID: "spent", Address: "bounce@example.com", Attempts: len(retryDelays)},
+		},
+		recorded: map[string]model.DigestAttempt{},
+	}
+	sender := &fakeSender{fail: map[string]bool{"bounce@example.com": true}}
+	dispatcher := NewDispatcher(store, sender)
+	dispatcher.now = func() time.Time { return now }
+	dispatcher.RunOnce(context.Background())
+
+	if len(sender.sent) != 1 {
+		t.Fatalf("sent = %d messages", len(sender.sent))
+	}
+	message := sender.sent[0]
+	if message.To != "parent@example.com" || message.HTML == "" || message.Text == "" || message.Attachment.Name != "" {
+		t.Fatalf("message = %+v", message)
+	}
+	if !store.reportAt.Equal(now) {
+		t.Fatalf("report at %v", store.reportAt)
+	}
+	if !store.recorded["sent"].Sent {
+		t.Fatalf("sent = %+v", store.recorded["sent"])
+	}
+	if next := store.recorded["retry"].NextAttemptAt; next == nil || !next.Equal(now.Add(retryDelays[1])) {
+		t.Fatalf("retry = %+v", store.recorded["retry"])
+	}
+	if spent := store.recorded["spent"]; spent.Sent || spent.NextAttemptAt != nil {
+		t.Fatalf("spent = %+v", spent)
+	}
+}
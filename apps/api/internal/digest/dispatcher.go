@@ -0,0 +1,107 @@
+package digest
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"pandapages/api/internal/delivery"
+	"pandapages/api/internal/model"
+)
+
+const (
+	defaultInterval = time.Minute
+	claimLease      = 5 * time.Minute
+	claimBatch      = 10
+)
+
+// retryDelays is the wait after each failed send. A digest is skipped for
+// the week once every delay has been used, or its send day ends.
+var retryDelays = []time.Duration{
+	5 * time.Minute,
+	30 * time.Minute,
+	2 * time.Hour,
+}
+
+type Store interface {
+	DigestClaim(ctx context.Context, limit int, lease time.Duration) ([]model.DueDigest, error)
+	DigestRecordAttempt(ctx context.Context, accountID string, attempt model.DigestAttempt) error
+	ReadingReport(ctx context.Context, accountID string, at time.Time) (model.ReadingReport, error)
+}
+
+type Dispatcher struct {
+	store    Store
+	sender   delivery.Sender
+	interval time.Duration
+	now      func() time.Time
+}
+
+func NewDispatcher(store Store, sender delivery.Sender) *Dispatcher {
+	return &Dispatcher{store: store, sender: sender, interval: defaultInterval, now: time.Now}
+}
+
+// Run sends due digests until ctx is cancelled.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+	for {
+		d.RunOnce(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce claims one batch of due digests and sends each of them.
+func (d *Dispatcher) RunOnce(ctx context.Context) {
+	digests, err := d.store.DigestClaim(ctx, claimBatch, claimLease)
+	if err != nil {
+		slog.Error("reading digest claim failed")
+		return
+	}
+	for _, due := range digests {
+		if ctx.Err() != nil {
+			// Unattempted claims become due again when their lease expires.
+			return
+		}
+		attempt, reason := d.send(ctx, due)
+		if reason != "" {
+			slog.Warn("reading digest attempt failed", "account_id", due.AccountID, "reason", reason)
+		}
+		// A sent digest is recorded even during shutdown, or it would be
+		// mailed again once its lease expires.
+		if err := d.store.DigestRecordAttempt(context.WithoutCancel(ctx), due.AccountID, attempt); err != nil {
+			slog.Error("reading digest record failed")
+		}
+	}
+}
+
+// send mails one account's digest. reason is a fixed category when it
+// failed: relay errors can echo the address.
+func (d *Dispatcher) send(ctx context.Context, due model.DueDigest) (model.DigestAttempt, string) {
+	now := d.now()
+	report, err := d.store.ReadingReport(ctx, due.AccountID, now)
+	if err != nil {
+		return d.failed(due, now), "report_failed"
+	}
+	subject, text, html, err := Render(report)
+	if err != nil {
+		return model.DigestAttempt{}, "render_failed"
+	}
+	err = d.sender.Send(ctx, delivery.Message{To: due.Address, Subject: subject, Text: text, HTML: html})
+	if err != nil {
+		return d.failed(due, now), "send_failed"
+	}
+	return model.DigestAttempt{Sent: true}, ""
+}
+
+func (d *Dispatcher) failed(due model.DueDigest, now time.Time) model.DigestAttempt {
+	var attempt model.DigestAttempt
+	if due.Attempts < len(retryDelays) {
+		next := now.Add(retryDelays[due.Attempts])
+		attempt.NextAttemptAt = &next
+	}
+	return attempt
+}
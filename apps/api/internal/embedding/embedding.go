@@ -0,0 +1,168 @@
+// Package embedding turns story text into vectors for semantic search. A
+// Provider embeds a batch of texts; the Worker embeds each published version
+// that has no vector from the configured model yet, the ReindexWorker embeds
+// them again when an admin asks, and the search endpoint embeds the query the
+// same way, so stories match by meaning rather than by shared words.
+package embedding
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	ProviderOpenAI = "openai"
+
+	defaultOpenAIURL = "https://api.openai.com/v1/embeddings"
+	requestTimeout   = 30 * time.Second
+	// maxResponseBytes bounds a reply; a batch of vectors in JSON is a few
+	// hundred kilobytes.
+	maxResponseBytes = 8 << 20
+	// MaxDimensions is the longest vector the schema stores.
+	MaxDimensions = 4096
+)
+
+// ErrProvider marks a provider that answered with an error status or a reply
+// that could not be used. Its message never carries the provider's response.
+var ErrProvider = errors.New("embedding provider failed")
+
+type Provider interface {
+	// Name identifies the provider in logs and the startup summary.
+	Name() string
+	// Model names the vectors the provider makes; stored vectors are only
+	// compared with others from the same model.
+	Model() string
+	// Embed returns one vector per text, in order.
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// Load builds the provider PP_EMBEDDING_PROVIDER names. It returns nil, and
+// no error, when none is configured.
+func Load(getenv func(string) string) (Provider, error) {
+	name := strings.ToLower(strings.TrimSpace(getenv("PP_EMBEDDING_PROVIDER")))
+	endpoint := strings.TrimSpace(getenv("PP_EMBEDDING_URL"))
+	apiKey := strings.TrimSpace(getenv("PP_EMBEDDING_API_KEY"))
+	modelName := strings.TrimSpace(getenv("PP_EMBEDDING_MODEL"))
+	if endpoint != "" {
+		if parsed, err := url.Parse(endpoint); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return nil, fmt.Errorf("PP_EMBEDDING_URL must be an http or https URL")
+		}
+	}
+	if name != "" && modelName == "" {
+		return nil, fmt.Errorf("PP_EMBEDDING_MODEL is required when PP_EMBEDDING_PROVIDER is set")
+	}
+
+	switch name {
+	case "":
+		return nil, nil
+	case ProviderOpenAI:
+		// A local server with OpenAI's API, such as Ollama, needs no key.
+		if apiKey == "" && endpoint == "" {
+			return nil, fmt.Errorf("PP_EMBEDDING_API_KEY is required for the openai provider")
+		}
+		if endpoint == "" {
+			endpoint = defaultOpenAIURL
+		}
+		return &OpenAI{URL: endpoint, APIKey: apiKey, ModelName: modelName, client: &http.Client{Timeout: requestTimeout}}, nil
+	default:
+		return nil, fmt.Errorf("PP_EMBEDDING_PROVIDER must be openai")
+	}
+}
+
+// OpenAI is OpenAI's embeddings endpoint, or any service that copies its API.
+type OpenAI struct {
+	URL       string
+	APIKey    string
+	ModelName string
+	client    *http.Client
+}
+
+func (p *OpenAI) Name() string { return ProviderOpenAI }
+
+func (p *OpenAI) Model() string { return ProviderOpenAI + "/" + p.ModelName }
+
+func (p *OpenAI) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	raw, err := json.Marshal(struct {
+		Model string   `json:"model"`
+		Input []string `json:"input"`
+	}{Model: p.ModelName, Input: texts})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.URL, bytes.NewReader(raw))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "pandapages-embedding/1")
+	if p.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.APIKey)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("%w: status %d", ErrProvider, resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxResponseBytes {
+		return nil, fmt.Errorf("%w: reply exceeds %d bytes", ErrProvider, maxResponseBytes)
+	}
+	var reply struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(data, &reply); err != nil {
+		return nil, fmt.Errorf("%w: reply is not JSON", ErrProvider)
+	}
+	vectors := make([][]float32, len(texts))
+	for _, item := range reply.Data {
+		if item.Index < 0 || item.Index >= len(texts) || vectors[item.Index] != nil {
+			return nil, fmt.Errorf("%w: reply index out of range", ErrProvider)
+		}
+		vectors[item.Index] = item.Embedding
+	}
+	for index, vector := range vectors {
+		if len(vector) == 0 || len(vector) > MaxDimensions {
+			return nil, fmt.Errorf("%w: vector %d has %d dimensions", ErrProvider, index, len(vector))
+		}
+	}
+	return vectors, nil
+}
+
+// Normalize scales vector to unit length, so the dot product of two
+// normalized vectors is their cosine similarity. It reports false for a
+// vector with no direction.
+func Normalize(vector []float32) ([]float32, bool) {
+	var sum float64
+	for _, value := range vector {
+		sum += float64(value) * float64(value)
+	}
+	length := math.Sqrt(sum)
+	if length == 0 || math.IsNaN(length) || math.IsInf(length, 0) {
+		return nil, false
+	}
+	out := make([]float32, len(vector))
+	for index, value := range vector {
+		out[index] = float32(float64(value) / length)
+	}
+	return out, true
+}
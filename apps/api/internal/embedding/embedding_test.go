@@ -0,0 +1,214 @@
+package embedding
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"pandapages/api/internal/model"
+)
+
+func TestLoadBuildsTheConfiguredProvider(t *testing.T) {
+	env := func(values map[string]string) func(string) string {
+		return func(key string) string { return values[key] }
+	}
+	if provider, err := Load(env(nil)); err != nil || provider != nil {
+		t.Fatalf("unconfigured Load = %v, %v", provider, err)
+	}
+	provider, err := Load(env(map[string]string{"PP_EMBEDDING_PROVIDER": "openai", "PP_EMBEDDING_MODEL": "nomic-embed-text", "PP_EMBEDDING_URL": "http://ollama:11434/v1/embeddings"}))
+	if err != nil || provider.Model() != "openai/nomic-embed-text" {
+		t.Fatalf("keyless Load = %#v, %v", provider, err)
+	}
+	for name, values := range map[string]map[string]string{
+		"unknown": {"PP_EMBEDDING_PROVIDER": "word2vec", "PP_EMBEDDING_MODEL": "m"},
+		"model":   {"PP_EMBEDDING_PROVIDER": "openai", "PP_EMBEDDING_API_KEY": "key"},
+		"key":     {"PP_EMBEDDING_PROVIDER": "openai", "PP_EMBEDDING_MODEL": "m"},
+		"url":     {"PP_EMBEDDING_PROVIDER": "openai", "PP_EMBEDDING_MODEL": "m", "PP_EMBEDDING_URL": "ftp://model"},
+	} {
+		if _, err := Load(env(values)); err == nil {
+			t.Errorf("%s: Load succeeded", name)
+		}
+	}
+}
+
+func TestOpenAIEmbedsInInputOrder(t *testing.T) {
+	var body map[string]any
+	var authorization string
+	answer := `{"data":[{"index":1,"embedding":[0,1]},{"index":0,"embedding":[1,0]}]}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(raw, &body)
+		authorization = r.Header.Get("Authorization")
+		_, _ = io.WriteString(w, answer)
+	}))
+	t.Cleanup(server.Close)
+	provider := &OpenAI{URL: server.URL, APIKey: "secret", ModelName: "small", client: server.Client()}
+
+	vectors, err := provider.Embed(context.Background(), []string{"boats", "owls"})
+	if err != nil || len(vectors) != 2 || vectors[0][0] != 1 || vectors[1][1] != 1 {
+		t.Fatalf("Embed = %v, %v", vectors, err)
+	}
+	if body["model"] != "small" || authorization != "Bearer secret" {
+		t.Fatalf("request = %v, Authorization %q", body, authorization)
+	}
+
+	for _, reply := range []string{`{"data":[{"index":0,"embedding":[1]}]}`, `{"data":[{"index":0,"embedding":[1]},{"index":0,"embedding":[1]}]}`, `<html>`} {
+		answer = reply
+		if _, err := provider.Embed(context.Background(), []string{"boats", "owls"}); !errors.Is(err, ErrProvider) {
+			t.Errorf("reply %s: error = %v, want ErrProvider", reply, err)
+		}
+	}
+}
+
+func TestNormalizeAndText(t *testing.T) {
+	vector, ok := Normalize([]float32{3, 4})
+	if !ok || math.Abs(float64(vector[0])-0.6) > 1e-6 || math.Abs(float64(vector[1])-0.8) > 1e-6 {
+		t.Fatalf("Normalize = %v, %v", vector, ok)
+	}
+	if _, ok := Normalize([]float32{0, 0}); ok {
+		t.Fatal("Normalize accepted a zero vector")
+	}
+
+	if text := Text(" Boats ", "A brave little boat.\n"); text != "Boats\n\nA brave little boat." {
+		t.Fatalf("Text = %q", text)
+	}
+	long := Text("Boats", strings.Repeat("sail away ", maxTextRunes))
+	if len([]rune(long)) > maxTextRunes || strings.HasSuffix(long, "sai") {
+		t.Fatalf("long text has %d runes, ends %q", len([]rune(long)), long[len(long)-10:])
+	}
+}
+
+type fakeStore struct {
+	pending []model.EmbeddingSource
+	saved   map[string][]float32
+}
+
+func (s *fakeStore) EmbeddingPendingVersions(_ context.Context, _ string, limit int) ([]model.EmbeddingSource, error) {
+	batch := s.pending[:min(limit, len(s.pending))]
+	s.pending = s.pending[len(batch):]
+	return batch, nil
+}
+
+func (s *fakeStore) EmbeddingSave(_ context.Context, source model.EmbeddingSource, modelName string, vector []float32) error {
+	if s.saved == nil {
+		s.saved = map[string][]float32{}
+	}
+	s.saved[modelName+" "+source.VersionID] = vector
+	return nil
+}
+
+type fakeProvider struct {
+	calls  int
+	failOn string
+}
+
+func (*fakeProvider) Name() string  { return "fake" }
+func (*fakeProvider) Model() string { return "fake/small" }
+
+func (p *fakeProvider) Embed(_ context.Context, texts []string) ([][]float32, error) {
+	p.calls++
+	if p.failOn != "" && strings.HasPrefix(texts[0], p.failOn) {
+		return nil, ErrProvider
+	}
+	vectors := make([][]float32, len(texts))
+	for index := range texts {
+		vectors[index] = []float32{0, 2}
+	}
+	return vectors, nil
+}
+
+func TestWorkerEmbedsEveryPendingVersionInBatches(t *testing.T) {
+	store := &fakeStore{pending: []model.EmbeddingSource{{VersionID: "v1", Title: "Boats"}, {VersionID: "v2", Title: "Owls"}, {VersionID: "v3", Title: "Trains"}}}
+	provider := &fakeProvider{}
+	worker := NewWorker(store, provider)
+	worker.batch = 2
+	worker.RunOnce(context.Background())
+
+	if provider.calls != 2 || len(store.saved) != 3 {
+		t.Fatalf("calls = %d, saved = %v", provider.calls, store.saved)
+	}
+	if vector := store.saved["fake/small v3"]; len(vector) != 2 || vector[1] != 1 {
+		t.Fatalf("saved vector = %v, want unit length", vector)
+	}
+}
+
+type fakeReindexStore struct {
+	fakeStore
+	queued   []model.SearchReindexJob
+	versions []model.EmbeddingSource
+	finished int
+	failure  *string
+}
+
+func (s *fakeReindexStore) SearchReindexClaimJob(context.Context, time.Duration) (model.SearchReindexJob, bool, error) {
+	if len(s.queued) == 0 {
+		return model.SearchReindexJob{}, false, nil
+	}
+	job := s.queued[0]
+	s.queued = s.queued[1:]
+	job.Status = model.RenderJobRunning
+	return job, true, nil
+}
+
+func (s *fakeReindexStore) SearchReindexPendingVersions(_ context.Context, job model.SearchReindexJob, limit int) ([]model.EmbeddingSource, error) {
+	var out []model.EmbeddingSource
+	for _, version := range s.versions {
+		if version.VersionID > job.CursorVersionID && len(out) < limit {
+			out = append(out, version)
+		}
+	}
+	return out, nil
+}
+
+func (s *fakeReindexStore) SearchReindexRecordVersion(ctx context.Context, job model.SearchReindexJob, source model.EmbeddingSource, modelName string, vector []float32, _ time.Duration) (model.SearchReindexJob, error) {
+	_ = s.EmbeddingSave(ctx, source, modelName, vector)
+	job.CursorVersionID = source.VersionID
+	job.IndexedVersions++
+	return job, nil
+}
+
+func (s *fakeReindexStore) SearchReindexFinishJob(_ context.Context, job model.SearchReindexJob, failure *string) (model.SearchReindexJob, error) {
+	s.finished++
+	s.failure = failure
+	job.Status = model.RenderJobCompleted
+	if failure != nil {
+		job.Status = model.RenderJobFailed
+	}
+	return job, nil
+}
+
+func TestReindexWorkerEmbedsEveryVersionOfTheJob(t *testing.T) {
+	store := &fakeReindexStore{
+		queued:   []model.SearchReindexJob{{ID: "job"}},
+		versions: []model.EmbeddingSource{{VersionID: "v1", Title: "Boats"}, {VersionID: "v2", Title: "Owls"}, {VersionID: "v3", Title: "Trains"}},
+	}
+	provider := &fakeProvider{}
+	worker := NewReindexWorker(store, provider)
+	worker.runner.Batch = 2
+	worker.RunOnce(context.Background())
+
+	if provider.calls != 3 || len(store.saved) != 3 || store.finished != 1 || store.failure != nil {
+		t.Fatalf("calls = %d, saved = %v, finished = %d, failure = %v", provider.calls, store.saved, store.finished, store.failure)
+	}
+	if vector := store.saved["fake/small v3"]; len(vector) != 2 || vector[1] != 1 {
+		t.Fatalf("saved vector = %v, want unit length", vector)
+	}
+}
+
+func TestReindexWorkerFailsAJobAtTheVersionTheProviderRefuses(t *testing.T) {
+	store := &fakeReindexStore{
+		queued:   []model.SearchReindexJob{{ID: "job"}},
+		versions: []model.EmbeddingSource{{VersionID: "v1", Title: "Boats"}, {VersionID: "v2", Title: "Owls"}},
+	}
+	NewReindexWorker(store, &fakeProvider{failOn: "Owls"}).RunOnce(context.Background())
+	if len(store.saved) != 1 || store.failure == nil || *store.failure != "version v2 could not be embedded" {
+		t.Fatalf("saved = %v, failure = %v", store.saved, store.failure)
+	}
+}
@@ -0,0 +1,89 @@
+package embedding
+
+import (
+	"context"
+	"time"
+
+	"pandapages/api/internal/jobrunner"
+	"pandapages/api/internal/model"
+	"pandapages/api/internal/providerlog"
+)
+
+const (
+	reindexInterval = 10 * time.Second
+	// The lease is renewed after every version, so it need only outlast one
+	// provider call.
+	reindexLease = requestTimeout + time.Minute
+)
+
+type ReindexStore interface {
+	SearchReindexClaimJob(ctx context.Context, lease time.Duration) (model.SearchReindexJob, bool, error)
+	SearchReindexPendingVersions(ctx context.Context, job model.SearchReindexJob, limit int) ([]model.EmbeddingSource, error)
+	SearchReindexRecordVersion(ctx context.Context, job model.SearchReindexJob, source model.EmbeddingSource, modelName string, vector []float32, lease time.Duration) (model.SearchReindexJob, error)
+	SearchReindexFinishJob(ctx context.Context, job model.SearchReindexJob, failure *string) (model.SearchReindexJob, error)
+}
+
+// ReindexWorker runs admin-requested search reindex jobs, embedding each
+// version again even when it already has a vector from the model. A version
+// the provider cannot embed fails the job; asking again starts it over.
+type ReindexWorker struct {
+	runner jobrunner.Runner[model.SearchReindexJob, model.EmbeddingSource]
+}
+
+func NewReindexWorker(store ReindexStore, provider Provider) *ReindexWorker {
+	return &ReindexWorker{runner: jobrunner.Runner[model.SearchReindexJob, model.EmbeddingSource]{
+		Jobs:     reindexJobs{store: store, provider: provider},
+		Kind:     "search reindex",
+		Item:     "version",
+		Lease:    reindexLease,
+		Interval: reindexInterval,
+		Batch:    batchSize,
+	}}
+}
+
+// Run processes queued jobs until ctx is cancelled.
+func (w *ReindexWorker) Run(ctx context.Context) { w.runner.Run(ctx) }
+
+// RunOnce claims one job and reindexes it until it finishes or ctx ends.
+func (w *ReindexWorker) RunOnce(ctx context.Context) { w.runner.RunOnce(ctx) }
+
+// reindexJobs is the jobrunner side of search reindex jobs.
+type reindexJobs struct {
+	store    ReindexStore
+	provider Provider
+}
+
+func (r reindexJobs) Claim(ctx context.Context, lease time.Duration) (model.SearchReindexJob, bool, error) {
+	return r.store.SearchReindexClaimJob(ctx, lease)
+}
+
+func (r reindexJobs) Pending(ctx context.Context, job model.SearchReindexJob, limit int) ([]model.EmbeddingSource, error) {
+	return r.store.SearchReindexPendingVersions(ctx, job, limit)
+}
+
+func (r reindexJobs) Process(ctx context.Context, job model.SearchReindexJob, source model.EmbeddingSource, lease time.Duration) (model.SearchReindexJob, string, error) {
+	vectors, err := r.provider.Embed(ctx, []string{Text(source.Title, source.Markdown)})
+	if ctx.Err() != nil {
+		return job, "", ctx.Err()
+	}
+	if err != nil || len(vectors) != 1 {
+		providerlog.Failure("search reindex version failed", r.provider.Name(), "job", job.ID, "version", source.VersionID)
+		return job, "version " + source.VersionID + " could not be embedded", nil
+	}
+	vector, ok := Normalize(vectors[0])
+	if !ok {
+		return job, "version " + source.VersionID + " could not be embedded", nil
+	}
+	next, err := r.store.SearchReindexRecordVersion(ctx, job, source, r.provider.Model(), vector, lease)
+	return next, "", err
+}
+
+func (r reindexJobs) Finish(ctx context.Context, job model.SearchReindexJob, failure *string) (model.SearchReindexJob, error) {
+	return r.store.SearchReindexFinishJob(ctx, job, failure)
+}
+
+func (reindexJobs) ID(job model.SearchReindexJob) string { return job.ID }
+
+func (reindexJobs) Summary(job model.SearchReindexJob) []any {
+	return []any{"status", string(job.Status), "indexed", job.IndexedVersions, "total", job.TotalVersions}
+}
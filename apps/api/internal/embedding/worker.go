@@ -0,0 +1,108 @@
+package embedding
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"pandapages/api/internal/model"
+	"pandapages/api/internal/providerlog"
+)
+
+const (
+	defaultInterval = 30 * time.Second
+	batchSize       = 16
+	// maxTextRunes keeps a story well inside the input limit of common
+	// embedding models; its opening says most about what it is about.
+	maxTextRunes = 6000
+)
+
+type Store interface {
+	EmbeddingPendingVersions(ctx context.Context, modelName string, limit int) ([]model.EmbeddingSource, error)
+	EmbeddingSave(ctx context.Context, source model.EmbeddingSource, modelName string, vector []float32) error
+}
+
+type Worker struct {
+	store    Store
+	provider Provider
+	interval time.Duration
+	batch    int
+}
+
+func NewWorker(store Store, provider Provider) *Worker {
+	return &Worker{store: store, provider: provider, interval: defaultInterval, batch: batchSize}
+}
+
+// Run embeds newly published versions until ctx is cancelled.
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		w.RunOnce(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce embeds batches of published versions without a vector from the
+// provider's model until none are left or a batch fails; a failed batch is
+// tried again on the next run.
+func (w *Worker) RunOnce(ctx context.Context) {
+	modelName := w.provider.Model()
+	for ctx.Err() == nil {
+		sources, err := w.store.EmbeddingPendingVersions(ctx, modelName, w.batch)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			slog.Error("embedding pending versions read failed")
+			return
+		}
+		if len(sources) == 0 {
+			return
+		}
+		texts := make([]string, len(sources))
+		for index, source := range sources {
+			texts[index] = Text(source.Title, source.Markdown)
+		}
+		vectors, err := w.provider.Embed(ctx, texts)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil || len(vectors) != len(sources) {
+			providerlog.Failure("embedding batch failed", w.provider.Name(), "versions", len(sources))
+			return
+		}
+		for index, source := range sources {
+			vector, ok := Normalize(vectors[index])
+			if !ok {
+				slog.Warn("embedding vector unusable", "version", source.VersionID)
+				return
+			}
+			if err := w.store.EmbeddingSave(ctx, source, modelName, vector); err != nil {
+				slog.Error("embedding save failed", "version", source.VersionID)
+				return
+			}
+		}
+		slog.Info("story versions embedded", "versions", len(sources))
+	}
+}
+
+// Text is what is embedded for a story: its title, then its Markdown, cut
+// short at a word boundary.
+func Text(title, markdown string) string {
+	text := strings.TrimSpace(title) + "\n\n" + strings.TrimSpace(markdown)
+	runes := []rune(text)
+	if len(runes) <= maxTextRunes {
+		return text
+	}
+	cut := string(runes[:maxTextRunes])
+	if space := strings.LastIndexAny(cut, " \n"); space > 0 {
+		cut = cut[:space]
+	}
+	return cut
+}
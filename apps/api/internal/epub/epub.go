@@ -0,0 +1,276 @@
+// Package epub packages a story's published version as an EPUB 3 book, for
+// e-readers that cannot run the web app and for emailing to a device. The
+// book is one XHTML document made from the stored segment HTML, with a
+// navigation entry per chapter and the story's uploaded images inside it.
+package epub
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+
+	"pandapages/api/internal/model"
+	"pandapages/api/internal/readercontract"
+	"pandapages/api/internal/storyingest"
+)
+
+const ContentType = "application/epub+zip"
+
+// bookStyle keeps to properties e-ink readers honour; font and size are left
+// to the reader's settings.
+const bookStyle = `img{max-width:100%;height:auto}aside{margin:1em 0;padding-left:1em;border-left:2px solid}` +
+	`h1.title{text-align:center}p.author{text-align:center;font-style:italic}`
+
+// imageTypes are the image formats EPUB readers must display, by the file
+// extension they are packaged under.
+var imageTypes = map[string]string{
+	"image/png":     "png",
+	"image/jpeg":    "jpg",
+	"image/gif":     "gif",
+	"image/webp":    "webp",
+	"image/svg+xml": "svg",
+}
+
+// MediaLoader returns one of the account's uploaded images by ID.
+type MediaLoader func(id string) (model.Media, []byte, error)
+
+// Build writes story as an EPUB. Uploaded images are packaged with it; one
+// that is missing or in a format EPUB does not require readers to show, and
+// any image hosted elsewhere, is replaced by its alt text, since e-readers
+// fetch nothing. modified dates the book's metadata and its files.
+func Build(story model.ReaderStory, load MediaLoader, modified time.Time) ([]byte, error) {
+	book := book{
+		Identifier: "urn:pandapages:" + story.Slug,
+		Title:      story.Title,
+		Language:   story.Language,
+		Modified:   modified.UTC().Format(time.RFC3339),
+		images:     map[string]int{},
+	}
+	if book.Language == "" {
+		book.Language = "und"
+	}
+	if story.Author != nil {
+		book.Author = *story.Author
+	}
+
+	var body bytes.Buffer
+	chapter := ""
+	for _, segment := range story.Segments {
+		if segment.RenderedHTML == "" {
+			continue
+		}
+		nodes, err := html.ParseFragment(strings.NewReader(segment.RenderedHTML), &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body})
+		if err != nil {
+			return nil, fmt.Errorf("parse segment %d: %w", segment.Ordinal, err)
+		}
+		// A chapter's entry points at the heading that opens it.
+		if segment.ChapterKey != nil && *segment.ChapterKey != chapter {
+			chapter = *segment.ChapterKey
+			if segment.Kind == string(readercontract.SegmentKindHeading) {
+				if heading := firstElement(nodes); heading != nil {
+					anchor := fmt.Sprintf("s%d", segment.Ordinal)
+					setAttr(heading, "id", anchor)
+					book.Chapters = append(book.Chapters, navEntry{Href: "story.xhtml#" + anchor, Label: strings.TrimSpace(textContent(heading))})
+				}
+			}
+		}
+		for _, node := range nodes {
+			if err := book.packImages(node, load); err != nil {
+				return nil, err
+			}
+			if err := html.Render(&body, node); err != nil {
+				return nil, err
+			}
+		}
+		body.WriteString("\n")
+	}
+	book.Body = body.String()
+
+	var out bytes.Buffer
+	archive := zip.NewWriter(&out)
+	// The mimetype file comes first and uncompressed, so the format can be
+	// recognised from the archive's opening bytes.
+	if err := writeFile(archive, "mimetype", zip.Store, modified, []byte(ContentType)); err != nil {
+		return nil, err
+	}
+	for _, file := range []struct {
+		name     string
+		template *template.Template
+	}{
+		{"META-INF/container.xml", containerTemplate},
+		{"OEBPS/content.opf", packageTemplate},
+		{"OEBPS/nav.xhtml", navTemplate},
+		{"OEBPS/story.xhtml", storyTemplate},
+	} {
+		var rendered bytes.Buffer
+		if err := file.template.Execute(&rendered, book); err != nil {
+			return nil, err
+		}
+		if err := writeFile(archive, file.name, zip.Deflate, modified, rendered.Bytes()); err != nil {
+			return nil, err
+		}
+	}
+	if err := writeFile(archive, "OEBPS/style.css", zip.Deflate, modified, []byte(bookStyle)); err != nil {
+		return nil, err
+	}
+	for _, image := range book.Images {
+		// Images are already compressed.
+		if err := writeFile(archive, "OEBPS/"+image.Href, zip.Store, modified, image.data); err != nil {
+			return nil, err
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+type book struct {
+	Identifier string
+	Title      string
+	Author     string
+	Language   string
+	Modified   string
+	Body       string
+	Chapters   []navEntry
+	Images     []bookImage
+
+	images map[string]int
+}
+
+type navEntry struct {
+	Href  string
+	Label string
+}
+
+type bookImage struct {
+	ID        string
+	Href      string
+	MediaType string
+	data      []byte
+}
+
+// packImages points every img under node at a packaged copy of its image,
+// loading each uploaded image once, and replaces those it cannot package
+// with their alt text.
+func (b *book) packImages(node *html.Node, load MediaLoader) error {
+	if node.Type == html.ElementNode && node.DataAtom == atom.Img {
+		href, err := b.image(attr(node, "src"), load)
+		if err != nil {
+			return err
+		}
+		if href != "" {
+			setAttr(node, "src", href)
+			return nil
+		}
+		if node.Parent != nil {
+			node.Parent.InsertBefore(&html.Node{Type: html.TextNode, Data: attr(node, "alt")}, node)
+			node.Parent.RemoveChild(node)
+		} else {
+			*node = html.Node{Type: html.TextNode, Data: attr(node, "alt")}
+		}
+		return nil
+	}
+	for child := node.FirstChild; child != nil; {
+		next := child.NextSibling
+		if err := b.packImages(child, load); err != nil {
+			return err
+		}
+		child = next
+	}
+	return nil
+}
+
+// image returns where src is packaged, or "" when it cannot be.
+func (b *book) image(src string, load MediaLoader) (string, error) {
+	id, ok := strings.CutPrefix(src, storyingest.MediaPath)
+	if !ok || id == "" {
+		return "", nil
+	}
+	if index, ok := b.images[id]; ok {
+		if index < 0 {
+			return "", nil
+		}
+		return b.Images[index].Href, nil
+	}
+	media, data, err := load(id)
+	if errors.Is(err, model.ErrMediaNotFound) {
+		b.images[id] = -1
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	extension, ok := imageTypes[media.ContentType]
+	if !ok {
+		b.images[id] = -1
+		return "", nil
+	}
+	b.images[id] = len(b.Images)
+	image := bookImage{
+		ID:        fmt.Sprintf("image-%d", len(b.Images)+1),
+		Href:      "images/" + id + "." + extension,
+		MediaType: media.ContentType,
+		data:      data,
+	}
+	b.Images = append(b.Images, image)
+	return image.Href, nil
+}
+
+func writeFile(archive *zip.Writer, name string, method uint16, modified time.Time, data []byte) error {
+	file, err := archive.CreateHeader(&zip.FileHeader{Name: name, Method: method, Modified: modified})
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(data)
+	return err
+}
+
+func firstElement(nodes []*html.Node) *html.Node {
+	for _, node := range nodes {
+		if node.Type == html.ElementNode {
+			return node
+		}
+	}
+	return nil
+}
+
+func attr(node *html.Node, key string) string {
+	for _, attribute := range node.Attr {
+		if attribute.Namespace == "" && attribute.Key == key {
+			return attribute.Val
+		}
+	}
+	return ""
+}
+
+func setAttr(node *html.Node, key, value string) {
+	for index, attribute := range node.Attr {
+		if attribute.Namespace == "" && attribute.Key == key {
+			node.Attr[index].Val = value
+			return
+		}
+	}
+	node.Attr = append(node.Attr, html.Attribute{Key: key, Val: value})
+}
+
+func textContent(node *html.Node) string {
+	if node.Type == html.TextNode {
+		return node.Data
+	}
+	var text strings.Builder
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		text.WriteString(textContent(child))
+	}
+	return text.String()
+}
+
+// xmlText escapes a value for XML text or a quoted attribute.
+var xmlText = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;", "'", "&apos;").Replace
@@ -0,0 +1,133 @@
+package epub
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"pandapages/api/internal/model"
+)
+
+const imageID = "0b0c7f0e-8b7a-4f55-9a43-a0a1b3b1c2d3"
+
+func testStory() model.ReaderStory {
+	author := "Ada & Co"
+	chapter := "the-dark-wood"
+	level := 2
+	return model.ReaderStory{
+		Slug: "owls", Title: "Owls <at> Night", Author: &author, Language: "en-GB", Version: 3,
+		Segments: []model.ReaderSegment{
+			{Ordinal: 1, Kind: "paragraph", RenderedHTML: "<p>Once upon a time.<br>Hoot.</p>"},
+			{Ordinal: 2, Kind: "heading", HeadingLevel: &level, ChapterKey: &chapter, RenderedHTML: "<h2>The <em>Dark</em> Wood</h2>"},
+			{Ordinal: 3, Kind: "paragraph", ChapterKey: &chapter, RenderedHTML: `<p><img src="/api/v1/media/` + imageID + `" alt="An owl"> and <img src="https://example.com/x.png" alt="a moon"></p>`},
+			{Ordinal: 4, Kind: "pagebreak", ChapterKey: &chapter},
+			{Ordinal: 5, Kind: "paragraph", ChapterKey: &chapter, RenderedHTML: `<p><img src="/api/v1/media/` + imageID + `" alt="The owl again"></p>`},
+		},
+	}
+}
+
+func readBook(t *testing.T, data []byte) map[string]string {
+	t.Helper()
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("book is not a zip: %v", err)
+	}
+	files := map[string]string{}
+	for index, file := range archive.File {
+		if index == 0 && (file.Name != "mimetype" || file.Method != zip.Store) {
+			t.Fatalf("first entry = %q (method %d), want an uncompressed mimetype", file.Name, file.Method)
+		}
+		reader, err := file.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, _ := io.ReadAll(reader)
+		files[file.Name] = string(content)
+	}
+	return files
+}
+
+func TestBuildPackagesTheStoryWithItsImagesAndChapters(t *testing.T) {
+	loads := 0
+	load := func(id string) (model.Media, []byte, error) {
+		loads++
+		if id != imageID {
+			return model.Media{}, nil, model.ErrMediaNotFound
+		}
+		return model.Media{ID: id, ContentType: "image/png"}, []byte("\x89PNG"), nil
+	}
+	data, err := Build(testStory(), load, time.Date(2026, time.October, 16, 9, 30, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	files := readBook(t, data)
+	if files["mimetype"] != ContentType || files["OEBPS/images/"+imageID+".png"] != "\x89PNG" || loads != 1 {
+		t.Fatalf("files = %v, loads = %d", keys(files), loads)
+	}
+
+	for _, name := range []string{"META-INF/container.xml", "OEBPS/content.opf", "OEBPS/nav.xhtml", "OEBPS/story.xhtml"} {
+		if err := wellFormed(files[name]); err != nil {
+			t.Fatalf("%s is not well-formed XML: %v\n%s", name, err, files[name])
+		}
+	}
+	opf := files["OEBPS/content.opf"]
+	for _, want := range []string{
+		"<dc:title>Owls &lt;at&gt; Night</dc:title>", "<dc:creator>Ada &amp; Co</dc:creator>", "<dc:language>en-GB</dc:language>",
+		`<meta property="dcterms:modified">2026-10-16T09:30:00Z</meta>`, `href="images/` + imageID + `.png" media-type="image/png"`,
+	} {
+		if !strings.Contains(opf, want) {
+			t.Errorf("content.opf omits %q:\n%s", want, opf)
+		}
+	}
+	if nav := files["OEBPS/nav.xhtml"]; !strings.Contains(nav, `<a href="story.xhtml#s2">The Dark Wood</a>`) {
+		t.Errorf("nav omits the chapter:\n%s", nav)
+	}
+	story := files["OEBPS/story.xhtml"]
+	for _, want := range []string{`<br/>`, `<h2 id="s2">`, `<img src="images/` + imageID + `.png" alt="An owl"/> and a moon</p>`} {
+		if !strings.Contains(story, want) {
+			t.Errorf("story omits %q:\n%s", want, story)
+		}
+	}
+}
+
+func TestBuildReportsMediaFailures(t *testing.T) {
+	failure := errors.New("database unavailable")
+	_, err := Build(testStory(), func(string) (model.Media, []byte, error) { return model.Media{}, nil, failure }, time.Now())
+	if !errors.Is(err, failure) {
+		t.Fatalf("Build error = %v", err)
+	}
+
+	data, err := Build(testStory(), func(string) (model.Media, []byte, error) {
+		return model.Media{ContentType: "image/tiff"}, []byte("II*"), nil
+	}, time.Now())
+	if err != nil {
+		t.Fatalf("Build with an unsupported image: %v", err)
+	}
+	if story := readBook(t, data)["OEBPS/story.xhtml"]; strings.Contains(story, "<img") || !strings.Contains(story, "An owl and a moon") {
+		t.Fatalf("unsupported image kept:\n%s", story)
+	}
+}
+
+func wellFormed(document string) error {
+	decoder := xml.NewDecoder(strings.NewReader(document))
+	for {
+		if _, err := decoder.Token(); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+}
+
+func keys(files map[string]string) []string {
+	out := make([]string, 0, len(files))
+	for name := range files {
+		out = append(out, name)
+	}
+	return out
+}
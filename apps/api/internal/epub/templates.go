@@ -0,0 +1,77 @@
+package epub
+
+import "text/template"
+
+// The templates write XML, so every value goes through xmlText except Body,
+// which html.Render has already escaped.
+var templateFuncs = template.FuncMap{"x": xmlText}
+
+var containerTemplate = template.Must(template.New("container").Parse(`<?xml version="1.0" encoding="UTF-8"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+<rootfiles>
+<rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+</rootfiles>
+</container>
+`))
+
+var packageTemplate = template.Must(template.New("package").Funcs(templateFuncs).Parse(`<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="book-id" xml:lang="{{x .Language}}">
+<metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+<dc:identifier id="book-id">{{x .Identifier}}</dc:identifier>
+<dc:title>{{x .Title}}</dc:title>
+<dc:language>{{x .Language}}</dc:language>
+{{- with .Author}}
+<dc:creator>{{x .}}</dc:creator>
+{{- end}}
+<meta property="dcterms:modified">{{.Modified}}</meta>
+</metadata>
+<manifest>
+<item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+<item id="story" href="story.xhtml" media-type="application/xhtml+xml"/>
+<item id="style" href="style.css" media-type="text/css"/>
+{{- range .Images}}
+<item id="{{.ID}}" href="{{x .Href}}" media-type="{{.MediaType}}"/>
+{{- end}}
+</manifest>
+<spine>
+<itemref idref="story"/>
+</spine>
+</package>
+`))
+
+var navTemplate = template.Must(template.New("nav").Funcs(templateFuncs).Parse(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" xml:lang="{{x .Language}}" lang="{{x .Language}}">
+<head>
+<meta charset="utf-8"/>
+<title>{{x .Title}}</title>
+</head>
+<body>
+<nav epub:type="toc" id="toc">
+<ol>
+<li><a href="story.xhtml">{{x .Title}}</a></li>
+{{- range .Chapters}}
+<li><a href="{{x .Href}}">{{x .Label}}</a></li>
+{{- end}}
+</ol>
+</nav>
+</body>
+</html>
+`))
+
+var storyTemplate = template.Must(template.New("story").Funcs(templateFuncs).Parse(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xml:lang="{{x .Language}}" lang="{{x .Language}}">
+<head>
+<meta charset="utf-8"/>
+<title>{{x .Title}}</title>
+<link rel="stylesheet" type="text/css" href="style.css"/>
+</head>
+<body>
+<h1 class="title">{{x .Title}}</h1>
+{{- with .Author}}
+<p class="author">{{x .}}</p>
+{{- end}}
+{{.Body}}</body>
+</html>
+`))
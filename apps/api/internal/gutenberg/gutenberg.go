@@ -0,0 +1,296 @@
+// Package gutenberg searches the Project Gutenberg catalog through Gutendex
+// (https://gutendex.com), so the Studio can find a book and copy its title,
+// author, language and a text download into an import without leaving the
+// app. Answers are cached for an hour: the catalog changes daily at most, and
+// an admin paging back and forth should not hit the public service each time.
+package gutenberg
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"pandapages/api/internal/model"
+)
+
+const (
+	defaultURL     = "https://gutendex.com"
+	requestTimeout = 15 * time.Second
+	// maxResponseBytes bounds a reply; a page of 32 books is about 50 KB.
+	maxResponseBytes = 2 << 20
+
+	cacheTTL     = time.Hour
+	cacheEntries = 256
+
+	bookPageURL = "https://www.gutenberg.org/ebooks/"
+)
+
+// ErrCatalog marks a catalog that could not be reached or answered with an
+// error status or a reply that could not be used. Its message never carries
+// the reply.
+var ErrCatalog = errors.New("gutenberg catalog failed")
+
+// Catalog searches one Gutendex instance.
+type Catalog struct {
+	URL    string
+	client *http.Client
+	now    func() time.Time
+
+	mu      sync.Mutex
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type cacheEntry struct {
+	key     string
+	page    model.GutenbergSearchResponse
+	expires time.Time
+}
+
+// Load returns the catalog PP_GUTENDEX_URL names, the public Gutendex when it
+// is unset, or nil when it is off.
+func Load(getenv func(string) string) (*Catalog, error) {
+	endpoint := strings.TrimSpace(getenv("PP_GUTENDEX_URL"))
+	switch endpoint {
+	case "":
+		endpoint = defaultURL
+	case "off":
+		return nil, nil
+	}
+	if parsed, err := url.Parse(endpoint); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("PP_GUTENDEX_URL must be an http or https URL or off")
+	}
+	return New(endpoint), nil
+}
+
+// New returns a catalog for the Gutendex instance at baseURL.
+func New(baseURL string) *Catalog {
+	return &Catalog{
+		URL:     strings.TrimRight(baseURL, "/"),
+		client:  &http.Client{Timeout: requestTimeout},
+		now:     time.Now,
+		order:   list.New(),
+		entries: map[string]*list.Element{},
+	}
+}
+
+// Name identifies the catalog in the startup summary.
+func (c *Catalog) Name() string {
+	if parsed, err := url.Parse(c.URL); err == nil {
+		return parsed.Host
+	}
+	return "gutendex"
+}
+
+// Search returns one page of books matching query, most downloaded first.
+func (c *Catalog) Search(ctx context.Context, query model.GutenbergSearchQuery) (model.GutenbergSearchResponse, error) {
+	params := url.Values{}
+	params.Set("search", strings.Join(strings.Fields(query.Query), " "))
+	if len(query.Languages) > 0 {
+		params.Set("languages", strings.Join(query.Languages, ","))
+	}
+	page := max(query.Page, 1)
+	if page > 1 {
+		params.Set("page", strconv.Itoa(page))
+	}
+	endpoint := c.URL + "/books/?" + params.Encode()
+	if cached, ok := c.cached(endpoint); ok {
+		return cached, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return model.GutenbergSearchResponse{}, err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "pandapages-gutenberg/1")
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return model.GutenbergSearchResponse{}, fmt.Errorf("%w: request failed", ErrCatalog)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound && page > 1 {
+		// Gutendex answers 404 for a page past the last one.
+		return model.GutenbergSearchResponse{Items: []model.GutenbergBook{}, Page: page}, nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return model.GutenbergSearchResponse{}, fmt.Errorf("%w: status %d", ErrCatalog, resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes+1))
+	if err != nil {
+		return model.GutenbergSearchResponse{}, fmt.Errorf("%w: reply could not be read", ErrCatalog)
+	}
+	if len(data) > maxResponseBytes {
+		return model.GutenbergSearchResponse{}, fmt.Errorf("%w: reply exceeds %d bytes", ErrCatalog, maxResponseBytes)
+	}
+	var reply gutendexPage
+	if err := json.Unmarshal(data, &reply); err != nil {
+		return model.GutenbergSearchResponse{}, fmt.Errorf("%w: reply is not a page of books", ErrCatalog)
+	}
+
+	out := model.GutenbergSearchResponse{Items: make([]model.GutenbergBook, 0, len(reply.Results)), Count: reply.Count, Page: page}
+	for _, book := range reply.Results {
+		if book.ID <= 0 {
+			continue
+		}
+		out.Items = append(out.Items, normalize(book))
+	}
+	if reply.Next != nil {
+		next := page + 1
+		out.NextPage = &next
+	}
+	c.store(endpoint, out)
+	return out, nil
+}
+
+type gutendexPage struct {
+	Count   int            `json:"count"`
+	Next    *string        `json:"next"`
+	Results []gutendexBook `json:"results"`
+}
+
+type gutendexBook struct {
+	ID      int    `json:"id"`
+	Title   string `json:"title"`
+	Authors []struct {
+		Name string `json:"name"`
+	} `json:"authors"`
+	Languages     []string          `json:"languages"`
+	Copyright     *bool             `json:"copyright"`
+	DownloadCount int               `json:"download_count"`
+	Formats       map[string]string `json:"formats"`
+}
+
+// formatKinds maps the MIME types worth importing to their kind, in the
+// order results list them.
+var formatKinds = []struct {
+	prefix string
+	kind   string
+}{
+	{"text/plain", "text"},
+	{"text/html", "html"},
+	{"application/epub+zip", "epub"},
+}
+
+func normalize(book gutendexBook) model.GutenbergBook {
+	out := model.GutenbergBook{
+		ID:           book.ID,
+		Title:        strings.Join(strings.Fields(book.Title), " "),
+		Languages:    []string{},
+		PublicDomain: book.Copyright != nil && !*book.Copyright,
+		Downloads:    book.DownloadCount,
+		SourceURL:    bookPageURL + strconv.Itoa(book.ID),
+		Formats:      []model.GutenbergFormat{},
+	}
+	var names []string
+	for _, author := range book.Authors {
+		if name := displayName(author.Name); name != "" {
+			names = append(names, name)
+		}
+	}
+	if len(names) > 0 {
+		author := joinNames(names)
+		out.Author = &author
+	}
+	for _, language := range book.Languages {
+		if language = strings.ToLower(strings.TrimSpace(language)); language != "" {
+			out.Languages = append(out.Languages, language)
+		}
+	}
+	if len(out.Languages) > 0 {
+		out.Language = out.Languages[0]
+	}
+
+	// Gutendex keys formats by MIME type, sometimes with a charset; keep one
+	// download of each kind, preferring UTF-8, and skip zipped copies.
+	contentTypes := make([]string, 0, len(book.Formats))
+	for contentType := range book.Formats {
+		contentTypes = append(contentTypes, contentType)
+	}
+	sort.Slice(contentTypes, func(i, j int) bool {
+		iUTF8, jUTF8 := strings.Contains(contentTypes[i], "utf-8"), strings.Contains(contentTypes[j], "utf-8")
+		if iUTF8 != jUTF8 {
+			return iUTF8
+		}
+		return contentTypes[i] < contentTypes[j]
+	})
+	for _, want := range formatKinds {
+		for _, contentType := range contentTypes {
+			link := book.Formats[contentType]
+			if !strings.HasPrefix(contentType, want.prefix) || strings.HasSuffix(link, ".zip") || !webURL(link) {
+				continue
+			}
+			out.Formats = append(out.Formats, model.GutenbergFormat{Kind: want.kind, ContentType: contentType, URL: link})
+			break
+		}
+	}
+	return out
+}
+
+var parentheticalRe = regexp.MustCompile(`\s*\([^)]*\)`)
+
+// displayName turns the catalog's "Carroll, Lewis" into "Lewis Carroll",
+// dropping expansions such as "Baum, L. Frank (Lyman Frank)".
+func displayName(name string) string {
+	name = strings.Join(strings.Fields(parentheticalRe.ReplaceAllString(name, "")), " ")
+	if family, given, ok := strings.Cut(name, ", "); ok && !strings.Contains(given, ",") {
+		return given + " " + family
+	}
+	return name
+}
+
+func joinNames(names []string) string {
+	if len(names) == 1 {
+		return names[0]
+	}
+	return strings.Join(names[:len(names)-1], ", ") + " and " + names[len(names)-1]
+}
+
+func webURL(link string) bool {
+	parsed, err := url.Parse(link)
+	return err == nil && (parsed.Scheme == "https" || parsed.Scheme == "http") && parsed.Host != ""
+}
+
+func (c *Catalog) cached(key string) (model.GutenbergSearchResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	element, ok := c.entries[key]
+	if !ok {
+		return model.GutenbergSearchResponse{}, false
+	}
+	entry := element.Value.(*cacheEntry)
+	if !c.now().Before(entry.expires) {
+		c.order.Remove(element)
+		delete(c.entries, key)
+		return model.GutenbergSearchResponse{}, false
+	}
+	c.order.MoveToFront(element)
+	return entry.page, true
+}
+
+// store keeps a page, evicting the least recently used once the cache is
+// full. Cached pages are never modified, so callers share them.
+func (c *Catalog) store(key string, page model.GutenbergSearchResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if element, ok := c.entries[key]; ok {
+		c.order.Remove(element)
+	}
+	c.entries[key] = c.order.PushFront(&cacheEntry{key: key, page: page, expires: c.now().Add(cacheTTL)})
+	for c.order.Len() > cacheEntries {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
@@ -0,0 +1,147 @@
+package gutenberg
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"pandapages/api/internal/model"
+)
+
+const alicePage = `{"count":40,"next":"https://gutendex.com/books/?page=2&search=alice","previous":null,"results":[{
+	"id":11,
+	"title":"Alice's Adventures in\r\nWonderland",
+	"authors":[{"name":"Carroll, Lewis","birth_year":1832,"death_year":1898}],
+	"languages":["en"],
+	"copyright":false,
+	"download_count":30000,
+	"formats":{
+		"text/plain; charset=us-ascii":"https://www.gutenberg.org/ebooks/11.txt.utf-8",
+		"text/plain; charset=utf-8":"https://www.gutenberg.org/cache/epub/11/pg11.txt",
+		"text/html":"https://www.gutenberg.org/ebooks/11.html.images",
+		"application/epub+zip":"https://www.gutenberg.org/ebooks/11.epub3.images",
+		"application/octet-stream":"https://www.gutenberg.org/cache/epub/11/pg11-h.zip",
+		"image/jpeg":"https://www.gutenberg.org/cache/epub/11/pg11.cover.medium.jpg"
+	}
+},{
+	"id":2591,
+	"title":"Grimms' Fairy Tales",
+	"authors":[{"name":"Grimm, Jacob"},{"name":"Grimm, Wilhelm"},{"name":"Baum, L. Frank (Lyman Frank)"}],
+	"languages":["en","de"],
+	"copyright":null,
+	"download_count":12,
+	"formats":{}
+}]}`
+
+func TestSearchNormalizesTheCatalogPage(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.RequestURI())
+		_, _ = io.WriteString(w, alicePage)
+	}))
+	t.Cleanup(server.Close)
+	catalog := New(server.URL + "/")
+
+	page, err := catalog.Search(context.Background(), model.GutenbergSearchQuery{Query: " alice  wonderland ", Languages: []string{"en", "fr"}})
+	if err != nil || len(page.Items) != 2 || page.Count != 40 || page.Page != 1 || page.NextPage == nil || *page.NextPage != 2 {
+		t.Fatalf("Search = %#v, %v", page, err)
+	}
+	if len(requests) != 1 || requests[0] != "/books/?languages=en%2Cfr&search=alice+wonderland" {
+		t.Fatalf("requests = %v", requests)
+	}
+
+	alice := page.Items[0]
+	if alice.Title != "Alice's Adventures in Wonderland" || alice.Author == nil || *alice.Author != "Lewis Carroll" ||
+		alice.Language != "en" || !alice.PublicDomain || alice.SourceURL != "https://www.gutenberg.org/ebooks/11" {
+		t.Fatalf("alice = %#v", alice)
+	}
+	want := []model.GutenbergFormat{
+		{Kind: "text", ContentType: "text/plain; charset=utf-8", URL: "https://www.gutenberg.org/cache/epub/11/pg11.txt"},
+		{Kind: "html", ContentType: "text/html", URL: "https://www.gutenberg.org/ebooks/11.html.images"},
+		{Kind: "epub", ContentType: "application/epub+zip", URL: "https://www.gutenberg.org/ebooks/11.epub3.images"},
+	}
+	if len(alice.Formats) != len(want) {
+		t.Fatalf("formats = %#v", alice.Formats)
+	}
+	for index := range want {
+		if alice.Formats[index] != want[index] {
+			t.Fatalf("format %d = %#v, want %#v", index, alice.Formats[index], want[index])
+		}
+	}
+
+	grimm := page.Items[1]
+	if grimm.Author == nil || *grimm.Author != "Jacob Grimm, Wilhelm Grimm and L. Frank Baum" || grimm.PublicDomain || len(grimm.Formats) != 0 {
+		t.Fatalf("grimm = %#v", grimm)
+	}
+}
+
+func TestSearchCachesPagesForAnHour(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		_, _ = io.WriteString(w, alicePage)
+	}))
+	t.Cleanup(server.Close)
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	catalog := New(server.URL)
+	catalog.now = func() time.Time { return now }
+
+	query := model.GutenbergSearchQuery{Query: "alice"}
+	for range 3 {
+		if _, err := catalog.Search(context.Background(), query); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := catalog.Search(context.Background(), model.GutenbergSearchQuery{Query: "alice", Page: 2}); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 2 {
+		t.Fatalf("calls = %d, want one per distinct page", calls)
+	}
+	now = now.Add(cacheTTL)
+	if _, err := catalog.Search(context.Background(), query); err != nil || calls != 3 {
+		t.Fatalf("calls after expiry = %d, %v", calls, err)
+	}
+}
+
+func TestSearchFailures(t *testing.T) {
+	status, reply := http.StatusOK, "<html>"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		_, _ = io.WriteString(w, reply)
+	}))
+	t.Cleanup(server.Close)
+	catalog := New(server.URL)
+
+	if _, err := catalog.Search(context.Background(), model.GutenbergSearchQuery{Query: "alice"}); !errors.Is(err, ErrCatalog) {
+		t.Fatalf("HTML reply error = %v", err)
+	}
+	status = http.StatusBadGateway
+	if _, err := catalog.Search(context.Background(), model.GutenbergSearchQuery{Query: "owls"}); !errors.Is(err, ErrCatalog) {
+		t.Fatalf("status error = %v", err)
+	}
+	status, reply = http.StatusNotFound, `{"detail":"Invalid page."}`
+	page, err := catalog.Search(context.Background(), model.GutenbergSearchQuery{Query: "owls", Page: 9})
+	if err != nil || len(page.Items) != 0 || page.NextPage != nil {
+		t.Fatalf("past the last page = %#v, %v", page, err)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	env := func(value string) func(string) string {
+		return func(string) string { return value }
+	}
+	if catalog, err := Load(env("")); err != nil || catalog.URL != defaultURL || catalog.Name() != "gutendex.com" {
+		t.Fatalf("default = %#v, %v", catalog, err)
+	}
+	if catalog, err := Load(env("off")); err != nil || catalog != nil {
+		t.Fatalf("off = %#v, %v", catalog, err)
+	}
+	if _, err := Load(env("gutendex.internal")); err == nil {
+		t.Fatal("Load accepted a URL without a scheme")
+	}
+}
@@ -0,0 +1,65 @@
+package httpadmin
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"pandapages/api/internal/model"
+)
+
+// registerAlignmentRoutes mounts read-along word timing of narrated
+// versions. Like narration, queuing a job spends a provider's budget, so it
+// needs the publisher role, and is refused when no provider is configured.
+func registerAlignmentRoutes(mux *http.ServeMux, store Store, enabled bool, guard func([]model.AdminRole, http.HandlerFunc) http.HandlerFunc) {
+	// POST /api/v1/admin/stories/{slug}/versions/{versionId}/alignment
+	mux.HandleFunc("POST /api/v1/admin/stories/{slug}/versions/{versionId}/alignment", guard(adminPublishRoles, func(w http.ResponseWriter, r *http.Request) {
+		if !enabled {
+			writeErr(w, http.StatusServiceUnavailable, "alignment_unavailable", "no alignment provider is configured")
+			return
+		}
+
+		slug := strings.TrimSpace(r.PathValue("slug"))
+		versionID := strings.TrimSpace(r.PathValue("versionId"))
+		out, err := audited(store, r, func(ctx context.Context) (model.AlignmentJob, model.AdminAuditEntry, error) {
+			out, err := store.AdminStartAlignmentJob(ctx, accountIDFromCtx(r), slug, versionID)
+			return out, model.AdminAuditEntry{Action: model.AdminAuditActionAlign, Slug: out.Slug, Summary: map[string]any{
+				"jobId":     out.ID,
+				"versionId": out.VersionID,
+				"segments":  out.TotalSegments,
+			}}, err
+		})
+		if err != nil {
+			switch {
+			case errors.Is(err, model.ErrAdminStoryNotFound):
+				writeErr(w, http.StatusNotFound, "version_not_found", "story version was not found")
+			case errors.Is(err, model.ErrAlignmentJobActive):
+				writeErr(w, http.StatusConflict, "alignment_job_active", "an alignment job is already queued or running for this version")
+			default:
+				slog.Error("admin alignment job start failed")
+				writeErr(w, http.StatusInternalServerError, "alignment_failed", "alignment job could not be started")
+			}
+			return
+		}
+		noStore(w)
+		writeJSON(w, http.StatusAccepted, out)
+	}))
+
+	// GET /api/v1/admin/alignment-jobs/{id}
+	mux.HandleFunc("GET /api/v1/admin/alignment-jobs/{id}", guard(adminReadRoles, func(w http.ResponseWriter, r *http.Request) {
+		out, err := store.AdminGetAlignmentJob(r.Context(), accountIDFromCtx(r), strings.TrimSpace(r.PathValue("id")))
+		if err != nil {
+			if errors.Is(err, model.ErrAlignmentJobNotFound) {
+				writeErr(w, http.StatusNotFound, "alignment_job_not_found", "alignment job was not found")
+				return
+			}
+			slog.Error("admin alignment job read failed")
+			writeErr(w, http.StatusInternalServerError, "alignment_failed", "alignment job unavailable")
+			return
+		}
+		noStore(w)
+		writeJSON(w, http.StatusOK, out)
+	}))
+}
@@ -0,0 +1,205 @@
+package httpadmin
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+
+	"pandapages/api/internal/blob"
+	"pandapages/api/internal/model"
+)
+
+// backupTimeout replaces the server's read and write timeouts for a backup or
+// restore, which moves every story and image of the account and can take much
+// longer than an ordinary request.
+const backupTimeout = 30 * time.Minute
+
+// registerBackupRoutes mounts the account backup. An archive holds the
+// reader's settings and progress as well as the library, so only the
+// bootstrap key may take one. With a blob store that signs URLs, the archive
+// is stored there and the client redirected to it.
+func registerBackupRoutes(mux *http.ServeMux, store Store, blobs blob.Store, bootstrapGuard func(http.HandlerFunc) http.HandlerFunc) {
+	// GET /api/v1/admin/backup
+	mux.HandleFunc("GET /api/v1/admin/backup", bootstrapGuard(func(w http.ResponseWriter, r *http.Request) {
+		accountID := accountIDFromCtx(r)
+		index, err := store.AdminBackupIndex(r.Context(), accountID)
+		if err != nil {
+			slog.Error("admin backup index failed")
+			writeErr(w, http.StatusInternalServerError, "backup_failed", "backup unavailable")
+			return
+		}
+
+		exportedAt := time.Now().UTC()
+		filename := "pandapages-backup-" + exportedAt.Format("20060102-150405") + ".tar.gz"
+		_ = http.NewResponseController(w).SetWriteDeadline(exportedAt.Add(backupTimeout))
+		if blobs != nil {
+			redirected, err := redirectToBackup(r, store, blobs, w, index, exportedAt, filename)
+			if err != nil {
+				slog.ErrorContext(r.Context(), "admin backup upload failed", "blob_store", blobs.Name(), "err", err)
+				writeErr(w, http.StatusInternalServerError, "backup_failed", "backup unavailable")
+				return
+			}
+			if redirected {
+				return
+			}
+		}
+
+		noStore(w)
+		w.Header().Set("Content-Type", "application/gzip")
+		w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+		w.WriteHeader(http.StatusOK)
+
+		// The status is already sent, so a failure from here on can only stop
+		// the stream. The archive is then left without its gzip trailer, and
+		// a restore rejects it instead of reading part of the library.
+		if err := writeBackup(r, store, w, index, exportedAt); err != nil {
+			slog.ErrorContext(r.Context(), "admin backup stream failed")
+		}
+	}))
+}
+
+// redirectToBackup writes the archive to a temporary file, stores it under
+// exports/backups/ and redirects to a signed URL for it. It reports false,
+// having done nothing, when the store cannot sign URLs.
+func redirectToBackup(r *http.Request, store Store, blobs blob.Store, w http.ResponseWriter, index model.BackupIndex, exportedAt time.Time, filename string) (bool, error) {
+	ctx := r.Context()
+	key := "exports/backups/" + accountIDFromCtx(r) + "/" + filename
+	// Signing is tried first to learn whether the store can; the URL is
+	// signed again once the archive is stored, since that may take a while.
+	if _, err := blobs.SignedURL(ctx, key, blob.SignedURLLifetime, filename); errors.Is(err, blob.ErrUnsigned) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	file, err := os.CreateTemp("", "pandapages-backup-*.tar.gz")
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		_ = file.Close()
+		_ = os.Remove(file.Name())
+	}()
+	if err := writeBackup(r, store, file, index, exportedAt); err != nil {
+		return false, err
+	}
+	size, err := file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return false, err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return false, err
+	}
+	if err := blobs.Put(ctx, key, "application/gzip", file, size); err != nil {
+		return false, err
+	}
+	signed, err := blobs.SignedURL(ctx, key, blob.SignedURLLifetime, filename)
+	if err != nil {
+		return false, err
+	}
+	noStore(w)
+	http.Redirect(w, r, signed, http.StatusFound)
+	return true, nil
+}
+
+// writeBackup streams the archive: manifest.json, settings.json and
+// progress.json, then media/ID for each image and stories/SLUG.json for each
+// story bundle. Images come first so a restore can import each story as it
+// reads it. Only one bundle or image is in memory at a time. A story that
+// cannot be exported is left out and listed in a closing skipped.json.
+func writeBackup(r *http.Request, store Store, w io.Writer, index model.BackupIndex, exportedAt time.Time) error {
+	ctx := r.Context()
+	accountID := accountIDFromCtx(r)
+	zw := gzip.NewWriter(w)
+	tw := tar.NewWriter(zw)
+	archive := backupArchive{tw: tw, modTime: exportedAt}
+
+	manifest := model.BackupManifest{
+		Format:        model.BackupFormat,
+		FormatVersion: model.BackupFormatVersion,
+		ExportedAt:    exportedAt.Format(time.RFC3339Nano),
+		Media:         index.Media,
+	}
+	if err := archive.writeJSON("manifest.json", manifest); err != nil {
+		return err
+	}
+	if err := archive.writeJSON("settings.json", index.Settings); err != nil {
+		return err
+	}
+	if err := archive.writeJSON("progress.json", index.Progress); err != nil {
+		return err
+	}
+
+	for _, media := range index.Media {
+		_, data, err := store.Media(ctx, accountID, media.ID)
+		if err != nil {
+			return err
+		}
+		if err := archive.writeFile("media/"+media.ID, data); err != nil {
+			return err
+		}
+	}
+
+	skipped := []model.BackupSkippedStory{}
+	for _, slug := range index.Stories {
+		bundle, err := store.AdminExportStory(ctx, accountID, slug)
+		switch {
+		case errors.Is(err, model.ErrAdminVersionRepairRequired):
+			skipped = append(skipped, model.BackupSkippedStory{Slug: slug, Reason: "repair_required"})
+			continue
+		case errors.Is(err, model.ErrAdminStoryNotFound):
+			skipped = append(skipped, model.BackupSkippedStory{Slug: slug, Reason: "deleted"})
+			continue
+		case err != nil:
+			return err
+		}
+		bundle.ExportedAt = manifest.ExportedAt
+		if err := archive.writeJSON("stories/"+slug+".json", bundle); err != nil {
+			return err
+		}
+	}
+	if len(skipped) > 0 {
+		slog.WarnContext(ctx, "admin backup skipped stories", "count", len(skipped))
+		if err := archive.writeJSON("skipped.json", skipped); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+type backupArchive struct {
+	tw      *tar.Writer
+	modTime time.Time
+}
+
+func (a backupArchive) writeJSON(name string, value any) error {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return err
+	}
+	return a.writeFile(name, append(data, '\n'))
+}
+
+func (a backupArchive) writeFile(name string, data []byte) error {
+	if err := a.tw.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    0o644,
+		Size:    int64(len(data)),
+		ModTime: a.modTime,
+		Format:  tar.FormatPAX,
+	}); err != nil {
+		return err
+	}
+	_, err := a.tw.Write(data)
+	return err
+}
@@ -1,8 +1,55 @@
 package httpadmin
 
-import "pandapages/api/internal/session"
+import (
+	"log/slog"
+
+	"pandapages/api/internal/blob"
+	"pandapages/api/internal/gutenberg"
+	"pandapages/api/internal/llm"
+	"pandapages/api/internal/maintenance"
+	"pandapages/api/internal/moderation"
+	"pandapages/api/internal/phonics"
+	"pandapages/api/internal/session"
+	"pandapages/api/internal/urlimport"
+)
 
 type Config struct {
 	AdminKey string
 	Sessions *session.Manager
+
+	// SensitivityWords extends every account's child-profile sensitivities
+	// when stories are scanned.
+	SensitivityWords []string
+	// Maintenance is the switch the reader API honours; nil leaves the
+	// maintenance routes unmounted.
+	Maintenance *maintenance.Switch
+	// LogLevel is the process's log level, which the debug routes may change;
+	// nil leaves the log-level route unmounted.
+	LogLevel *slog.LevelVar
+	// Narration reports whether a text-to-speech provider is configured;
+	// without one, narration requests are refused.
+	Narration bool
+	// Alignment reports whether a speech recognizer is configured to time
+	// narrated words; without one, alignment requests are refused.
+	Alignment bool
+	// Search reports whether an embedding provider is configured; without
+	// one, search reindex requests are refused.
+	Search bool
+	// Generator writes stories for the generate route; nil refuses them.
+	Generator llm.Provider
+	// Moderator reviews the stories Generator writes before they are saved;
+	// nil leaves the sensitivity terms alone to flag them.
+	Moderator moderation.Provider
+	// Gutenberg is the catalog the Gutenberg search route proxies; nil
+	// refuses it.
+	Gutenberg *gutenberg.Catalog
+	// Fetcher downloads documents for the import-from-address route; nil
+	// refuses it.
+	Fetcher *urlimport.Fetcher
+	// Phonics is the progression story versions are scored against; nil
+	// refuses the phonics route.
+	Phonics *phonics.Progression
+	// Blobs keeps backups for download when it can sign URLs, so the
+	// archive is redirected to rather than streamed; nil streams it.
+	Blobs blob.Store
 }
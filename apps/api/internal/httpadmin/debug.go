@@ -0,0 +1,77 @@
+package httpadmin
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"pandapages/api/internal/model"
+)
+
+// logLevels are the levels an operator may choose, the same as PP_LOG_LEVEL.
+var logLevels = map[string]slog.Level{
+	"debug": slog.LevelDebug,
+	"info":  slog.LevelInfo,
+	"warn":  slog.LevelWarn,
+	"error": slog.LevelError,
+}
+
+// registerDebugRoutes mounts operator diagnostics. They describe the whole
+// deployment rather than one account, so every route is bootstrap-only.
+func registerDebugRoutes(mux *http.ServeMux, store Store, logLevel *slog.LevelVar, guard func(http.HandlerFunc) http.HandlerFunc) {
+	// GET /api/v1/admin/debug/db
+	mux.HandleFunc("GET /api/v1/admin/debug/db", guard(func(w http.ResponseWriter, r *http.Request) {
+		noStore(w)
+		writeJSON(w, http.StatusOK, store.DatabaseStats())
+	}))
+
+	// GET /api/v1/admin/debug/queries
+	mux.HandleFunc("GET /api/v1/admin/debug/queries", guard(func(w http.ResponseWriter, r *http.Request) {
+		noStore(w)
+		writeJSON(w, http.StatusOK, model.QueryLatencyResponse{Items: store.QueryStats()})
+	}))
+
+	if logLevel == nil {
+		return
+	}
+
+	// GET /api/v1/admin/debug/loglevel
+	mux.HandleFunc("GET /api/v1/admin/debug/loglevel", guard(func(w http.ResponseWriter, r *http.Request) {
+		noStore(w)
+		writeJSON(w, http.StatusOK, model.LogLevel{Level: strings.ToLower(logLevel.Level().String())})
+	}))
+
+	// PUT /api/v1/admin/debug/loglevel
+	//
+	// The level applies to this process until it changes again or restarts,
+	// when PP_LOG_LEVEL applies once more.
+	mux.HandleFunc("PUT /api/v1/admin/debug/loglevel", guard(func(w http.ResponseWriter, r *http.Request) {
+		var body model.LogLevel
+		if err := decodeJSON(w, r, &body); err != nil {
+			writeDecodeError(w, err)
+			return
+		}
+		level, ok := logLevels[strings.ToLower(strings.TrimSpace(body.Level))]
+		if !ok {
+			writeErr(w, http.StatusBadRequest, "log_level_invalid", "level must be one of debug, info, warn, error")
+			return
+		}
+
+		out := model.LogLevel{Level: strings.ToLower(level.String())}
+		err := audit(store, r, func(context.Context) (model.AdminAuditEntry, error) {
+			return model.AdminAuditEntry{Action: model.AdminAuditActionLogLevel, Summary: map[string]any{"level": out.Level}}, nil
+		})
+		if err != nil {
+			slog.Error("admin log level change failed")
+			writeErr(w, http.StatusInternalServerError, "log_level_failed", "log level could not be changed")
+			return
+		}
+		// Log before the change, so raising the level does not hide the line
+		// that explains why the logs went quiet.
+		slog.InfoContext(r.Context(), "log level changed", "from", strings.ToLower(logLevel.Level().String()), "to", out.Level)
+		logLevel.Set(level)
+		noStore(w)
+		writeJSON(w, http.StatusOK, out)
+	}))
+}
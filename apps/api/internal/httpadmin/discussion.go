@@ -0,0 +1,127 @@
+package httpadmin
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"pandapages/api/internal/model"
+)
+
+// registerDiscussionRoutes mounts the conversation prompts kept per chapter
+// of a story version, such as "Why do you think the fox was sad?". Editors
+// write them by hand, so unlike quizzes there is no draft to approve:
+// readers see a chapter's prompts once they are saved on the published
+// version.
+func registerDiscussionRoutes(mux *http.ServeMux, store Store, guard func([]model.AdminRole, http.HandlerFunc) http.HandlerFunc) {
+	// GET /api/v1/admin/stories/{slug}/discussion?versionId=
+	mux.HandleFunc("GET /api/v1/admin/stories/{slug}/discussion", guard(adminReadRoles, func(w http.ResponseWriter, r *http.Request) {
+		slug := strings.TrimSpace(r.PathValue("slug"))
+		versionID, ok := quizVersionID(store, w, r, slug, r.URL.Query().Get("versionId"))
+		if !ok {
+			return
+		}
+		out, err := store.AdminGetDiscussion(r.Context(), accountIDFromCtx(r), slug, versionID)
+		if err != nil {
+			writeDiscussionErr(w, err, "admin discussion read failed")
+			return
+		}
+		noStore(w)
+		writeJSON(w, http.StatusOK, out)
+	}))
+
+	// PUT /api/v1/admin/stories/{slug}/discussion/{chapter}
+	mux.HandleFunc("PUT /api/v1/admin/stories/{slug}/discussion/{chapter}", guard(adminEditRoles, func(w http.ResponseWriter, r *http.Request) {
+		var body model.AdminDiscussionUpdate
+		if err := decodeJSON(w, r, &body); err != nil {
+			writeDecodeError(w, err)
+			return
+		}
+		slug := strings.TrimSpace(r.PathValue("slug"))
+		chapter, ok := quizChapter(w, r)
+		if !ok {
+			return
+		}
+		if fields := validateDiscussionUpdate(&body); len(fields) > 0 {
+			writeFields(w, http.StatusBadRequest, "discussion_invalid", "discussion prompts are invalid", fields)
+			return
+		}
+		versionID, ok := quizVersionID(store, w, r, slug, body.VersionID)
+		if !ok {
+			return
+		}
+		discussion, err := audited(store, r, func(ctx context.Context) (model.AdminChapterDiscussion, model.AdminAuditEntry, error) {
+			discussion, err := store.AdminSaveDiscussion(ctx, accountIDFromCtx(r), slug, versionID, chapter, body.Questions)
+			return discussion, model.AdminAuditEntry{Action: model.AdminAuditActionDiscussion, Slug: slug, Summary: map[string]any{
+				"versionId": versionID,
+				"chapter":   chapter,
+				"questions": len(body.Questions),
+			}}, err
+		})
+		if err != nil {
+			writeDiscussionErr(w, err, "admin discussion save failed")
+			return
+		}
+		noStore(w)
+		writeJSON(w, http.StatusOK, discussion)
+	}))
+
+	// DELETE /api/v1/admin/stories/{slug}/discussion/{chapter}?versionId=
+	mux.HandleFunc("DELETE /api/v1/admin/stories/{slug}/discussion/{chapter}", guard(adminEditRoles, func(w http.ResponseWriter, r *http.Request) {
+		slug := strings.TrimSpace(r.PathValue("slug"))
+		chapter, ok := quizChapter(w, r)
+		if !ok {
+			return
+		}
+		versionID, ok := quizVersionID(store, w, r, slug, r.URL.Query().Get("versionId"))
+		if !ok {
+			return
+		}
+		err := audit(store, r, func(ctx context.Context) (model.AdminAuditEntry, error) {
+			err := store.AdminDeleteDiscussion(ctx, accountIDFromCtx(r), slug, versionID, chapter)
+			return model.AdminAuditEntry{Action: model.AdminAuditActionDiscussRm, Slug: slug, Summary: map[string]any{
+				"versionId": versionID,
+				"chapter":   chapter,
+			}}, err
+		})
+		if err != nil {
+			writeDiscussionErr(w, err, "admin discussion delete failed")
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+}
+
+// validateDiscussionUpdate trims the prompts in place and reports what is
+// wrong with them.
+func validateDiscussionUpdate(body *model.AdminDiscussionUpdate) []model.FieldError {
+	var fields []model.FieldError
+	if len(body.Questions) == 0 || len(body.Questions) > model.MaxDiscussionQuestions {
+		fields = append(fields, model.FieldError{Path: "questions", Code: "invalid", Message: "a chapter has 1 to 5 discussion questions"})
+	}
+	for index := range body.Questions {
+		body.Questions[index] = strings.TrimSpace(body.Questions[index])
+		if body.Questions[index] == "" || utf8.RuneCountInString(body.Questions[index]) > model.MaxDiscussionQuestionLen {
+			fields = append(fields, model.FieldError{Path: "questions." + strconv.Itoa(index), Code: "invalid", Message: "question must be 1 to 300 characters"})
+		}
+	}
+	return fields
+}
+
+func writeDiscussionErr(w http.ResponseWriter, err error, logMsg string) {
+	switch {
+	case errors.Is(err, model.ErrAdminStoryNotFound):
+		writeErr(w, http.StatusNotFound, "version_not_found", "story version was not found")
+	case errors.Is(err, model.ErrQuizChapterNotFound):
+		writeErr(w, http.StatusNotFound, "chapter_not_found", "story version has no such chapter")
+	case errors.Is(err, model.ErrDiscussionNotFound):
+		writeErr(w, http.StatusNotFound, "discussion_not_found", "chapter has no discussion questions")
+	default:
+		slog.Error(logMsg)
+		writeErr(w, http.StatusInternalServerError, "discussion_failed", "discussion request failed")
+	}
+}
@@ -0,0 +1,151 @@
+package httpadmin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"pandapages/api/internal/llm"
+	"pandapages/api/internal/model"
+	"pandapages/api/internal/moderation"
+	"pandapages/api/internal/providerlog"
+	"pandapages/api/internal/storyingest"
+)
+
+const maxThemeRunes = 500
+
+// registerGenerateRoutes mounts story generation. It writes a draft like an
+// import, so it needs the importer role; publishing the result stays a
+// separate, reviewed step, and moderation can hold it back. Every attempt
+// the model was asked for is recorded, including ones whose reply could not
+// be used.
+func registerGenerateRoutes(mux *http.ServeMux, store Store, generator llm.Provider, moderator moderation.Provider, sensitivityWords []string, guard func([]model.AdminRole, http.HandlerFunc) http.HandlerFunc) {
+	// POST /api/v1/admin/generate
+	mux.HandleFunc("POST /api/v1/admin/generate", guard(adminImportRoles, idempotent(store, func(w http.ResponseWriter, r *http.Request) {
+		var body model.AdminGenerateRequest
+		if err := decodeJSON(w, r, &body); err != nil {
+			writeDecodeError(w, err)
+			return
+		}
+		body.Slug = strings.TrimSpace(body.Slug)
+		body.Theme = strings.TrimSpace(body.Theme)
+		var fields []model.FieldError
+		if storyingest.ValidateSlug(body.Slug) != nil {
+			fields = append(fields, model.FieldError{Path: "slug", Code: "invalid", Message: "slug must be lowercase letters, digits and hyphens"})
+		}
+		switch {
+		case body.Theme == "":
+			fields = append(fields, model.FieldError{Path: "theme", Code: "required", Message: "theme is required"})
+		case utf8.RuneCountInString(body.Theme) > maxThemeRunes:
+			fields = append(fields, model.FieldError{Path: "theme", Code: "too_long", Message: "theme must be at most 500 characters"})
+		}
+		if len(fields) > 0 {
+			writeFields(w, http.StatusBadRequest, "generate_invalid", "generation request is invalid", fields)
+			return
+		}
+		if generator == nil {
+			writeErr(w, http.StatusServiceUnavailable, "generation_unavailable", "no language model provider is configured")
+			return
+		}
+
+		accountID := accountIDFromCtx(r)
+		child, profile, err := store.AdminGenerationProfiles(r.Context(), accountID, body.ChildProfileID, body.PromptProfileID)
+		if err != nil {
+			if errors.Is(err, model.ErrProfileNotFound) {
+				writeErr(w, http.StatusNotFound, "profile_not_found", "child or prompt profile was not found")
+				return
+			}
+			slog.Error("admin generation profiles failed")
+			writeErr(w, http.StatusInternalServerError, "generation_failed", "story could not be generated")
+			return
+		}
+
+		prompt := llm.StoryPrompt(child, profile, body.Theme)
+		record := model.GenerationRecord{
+			Slug:            body.Slug,
+			ChildProfileID:  child.ID,
+			PromptProfileID: profile.ID,
+			Theme:           body.Theme,
+			PromptVersion:   llm.PromptVersion,
+			Request:         generationPayload(map[string]any{"provider": generator.Name(), "prompt": prompt}),
+		}
+		recordFailure := func(message string) {
+			record.Status, record.Error = model.GenerationFailed, &message
+			if _, err := store.AdminRecordGeneration(context.WithoutCancel(r.Context()), accountID, record); err != nil {
+				slog.Error("admin generation record failed")
+			}
+		}
+
+		completion, err := generator.Complete(r.Context(), prompt)
+		if err != nil {
+			providerlog.Failure("story generation failed", generator.Name())
+			recordFailure("the language model did not write a story")
+			writeErr(w, http.StatusBadGateway, "generation_failed", "the language model did not write a story")
+			return
+		}
+		record.Model, record.TokensIn, record.TokensOut = completion.Model, completion.TokensIn, completion.TokensOut
+		record.Response = generationPayload(map[string]any{"text": completion.Text})
+
+		title, markdown, err := llm.ParseStory(completion.Text)
+		if err != nil {
+			recordFailure("the language model's reply was not a titled story")
+			writeErr(w, http.StatusBadGateway, "generation_invalid", "the language model's reply was not a titled story")
+			return
+		}
+		verdict := moderateDraft(r.Context(), moderator, sensitivityWords, child.Sensitivities, title, markdown)
+		// The draft, its provenance and the audit row commit together.
+		var generation model.StoryGeneration
+		draft, err := audited(store, r, func(ctx context.Context) (model.AdminDraftUpsertResponse, model.AdminAuditEntry, error) {
+			draft, err := store.AdminDraftUpsert(ctx, accountID, model.AdminDraftUpsertRequest{
+				Slug:       body.Slug,
+				Title:      title,
+				Markdown:   markdown,
+				Moderation: &verdict,
+			})
+			if err != nil {
+				return draft, model.AdminAuditEntry{}, err
+			}
+			succeeded := record
+			succeeded.Status, succeeded.VersionID = model.GenerationSucceeded, draft.VersionID
+			if generation, err = store.AdminRecordGeneration(ctx, accountID, succeeded); err != nil {
+				return draft, model.AdminAuditEntry{}, err
+			}
+			return draft, model.AdminAuditEntry{Action: model.AdminAuditActionGenerate, Slug: draft.Slug, Summary: map[string]any{
+				"versionId":    draft.VersionID,
+				"generationId": generation.ID,
+				"model":        completion.Model,
+				"moderation":   verdict.Verdict,
+			}}, nil
+		})
+		if err != nil {
+			var validationErr *model.AdminValidationError
+			if errors.As(err, &validationErr) {
+				recordFailure("the generated story is not valid")
+				writeIssues(w, http.StatusBadGateway, "generation_invalid", "the generated story is not valid", validationErr.Issues)
+				return
+			}
+			if errors.Is(err, model.ErrAdminVersionRepairRequired) {
+				writeErr(w, http.StatusConflict, "draft_repair_required", "stored story version requires repair")
+				return
+			}
+			slog.Error("admin generated draft failed")
+			writeErr(w, http.StatusInternalServerError, "draft_failed", "story draft could not be saved")
+			return
+		}
+		noStore(w)
+		writeJSON(w, http.StatusCreated, model.AdminGenerateResponse{AdminDraftUpsertResponse: draft, Generation: generation, Moderation: verdict})
+	})))
+}
+
+// generationPayload encodes a generation_jobs payload column.
+func generationPayload(value map[string]any) json.RawMessage {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil
+	}
+	return raw
+}
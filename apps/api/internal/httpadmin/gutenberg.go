@@ -0,0 +1,74 @@
+package httpadmin
+
+import (
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"pandapages/api/internal/gutenberg"
+	"pandapages/api/internal/model"
+)
+
+const (
+	maxGutenbergQueryRunes = 200
+	maxGutenbergLanguages  = 5
+	maxGutenbergPage       = 1000
+)
+
+var gutenbergLanguageRe = regexp.MustCompile(`^[a-z]{2}$`)
+
+// registerGutenbergRoutes mounts the Project Gutenberg catalog search. It
+// only reads a public catalog, but it exists to start an import, so it needs
+// the importer role.
+func registerGutenbergRoutes(mux *http.ServeMux, catalog *gutenberg.Catalog, guard func([]model.AdminRole, http.HandlerFunc) http.HandlerFunc) {
+	// GET /api/v1/admin/gutenberg/search?q=&languages=&page=
+	mux.HandleFunc("GET /api/v1/admin/gutenberg/search", guard(adminImportRoles, func(w http.ResponseWriter, r *http.Request) {
+		values := r.URL.Query()
+		query := model.GutenbergSearchQuery{Query: strings.TrimSpace(values.Get("q")), Page: 1}
+		var fields []model.FieldError
+		if query.Query == "" || utf8.RuneCountInString(query.Query) > maxGutenbergQueryRunes {
+			fields = append(fields, model.FieldError{Path: "q", Code: "invalid", Message: "q must be 1 to 200 characters"})
+		}
+		if raw := strings.TrimSpace(values.Get("languages")); raw != "" {
+			for _, language := range strings.Split(strings.ToLower(raw), ",") {
+				query.Languages = append(query.Languages, strings.TrimSpace(language))
+			}
+			valid := len(query.Languages) <= maxGutenbergLanguages
+			for _, language := range query.Languages {
+				valid = valid && gutenbergLanguageRe.MatchString(language)
+			}
+			if !valid {
+				fields = append(fields, model.FieldError{Path: "languages", Code: "invalid", Message: "languages must be up to 5 two-letter codes such as en,fr"})
+			}
+		}
+		if raw := values.Get("page"); raw != "" {
+			page, err := strconv.Atoi(raw)
+			if err != nil || page < 1 || page > maxGutenbergPage {
+				fields = append(fields, model.FieldError{Path: "page", Code: "invalid", Message: "page must be 1 to 1000"})
+			}
+			query.Page = page
+		}
+		if len(fields) > 0 {
+			writeFields(w, http.StatusBadRequest, "search_invalid", "catalog search is invalid", fields)
+			return
+		}
+		if catalog == nil {
+			writeErr(w, http.StatusServiceUnavailable, "gutenberg_unavailable", "the Gutenberg catalog is turned off")
+			return
+		}
+
+		out, err := catalog.Search(r.Context(), query)
+		if err != nil {
+			// The error can carry the catalog's URL; the log keeps a fixed
+			// category instead.
+			slog.Warn("gutenberg catalog search failed")
+			writeErr(w, http.StatusBadGateway, "gutenberg_failed", "the Gutenberg catalog did not answer")
+			return
+		}
+		noStore(w)
+		writeJSON(w, http.StatusOK, out)
+	}))
+}
@@ -2,51 +2,182 @@ package httpadmin
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
 	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
 	"regexp"
+	"sort"
 	"strings"
+	"time"
 	"unicode/utf8"
 
 	"pandapages/api/internal/httpauth"
+	"pandapages/api/internal/httpmiddleware"
+	"pandapages/api/internal/idempotency"
 	"pandapages/api/internal/model"
 )
 
 type Store interface {
-	AccountExists(accountID string) (bool, error)
+	AccountExists(ctx context.Context, accountID string) (bool, error)
 
-	AdminDraftUpsert(accountID string, req model.AdminDraftUpsertRequest) (model.AdminDraftUpsertResponse, error)
-	AdminPublishStory(accountID string, slug string, versionID string) (model.AdminStoryStatusResponse, error)
-	AdminUnpublish(accountID string, slug string) (model.AdminStoryStatusResponse, error)
-	AdminPreview(req model.AdminPreviewRequest) (model.AdminPreviewResponse, error)
+	AdminDraftUpsert(ctx context.Context, accountID string, req model.AdminDraftUpsertRequest) (model.AdminDraftUpsertResponse, error)
+	AdminPublishStory(ctx context.Context, accountID string, slug string, versionID string, acknowledgeModeration bool) (model.AdminStoryStatusResponse, error)
+	AdminUnpublish(ctx context.Context, accountID string, slug string) (model.AdminStoryStatusResponse, error)
+	AdminPreview(ctx context.Context, req model.AdminPreviewRequest) (model.AdminPreviewResponse, error)
+	AdminLint(ctx context.Context, req model.AdminStoryInput) (model.AdminLintResponse, error)
+	AdminValidate(ctx context.Context, req model.AdminStoryInput) (model.AdminValidateResponse, error)
 
-	AdminListStories(accountID string) (model.AdminStoriesListResponse, error)
-	AdminGetStory(accountID string, slug string) (model.AdminStoryDetailResponse, error)
-	AdminGetVersionSource(accountID string, slug string, versionID string) (model.AdminVersionSourceResponse, error)
+	AdminListStories(ctx context.Context, accountID string, page model.PageRequest) (model.AdminStoriesListResponse, error)
+	AdminGetStory(ctx context.Context, accountID string, slug string) (model.AdminStoryDetailResponse, error)
+	AdminListStoryVersions(ctx context.Context, accountID string, slug string, page model.PageRequest) (model.AdminStoryVersionsResponse, error)
+	AdminPatchStoryMetadata(ctx context.Context, accountID string, slug string, patch model.AdminStoryMetadataPatch) (model.AdminStoryMetadataResponse, error)
+	AdminGetVersionSource(ctx context.Context, accountID string, slug string, versionID string) (model.AdminVersionSourceResponse, error)
+	AdminPruneVersions(ctx context.Context, accountID string, slug string, keep int, dryRun bool) (model.AdminPruneVersionsResponse, error)
+	AdminGetVersionRetention(ctx context.Context, accountID string) (model.AdminVersionRetention, error)
+	AdminSetVersionRetention(ctx context.Context, accountID string, keep *int) (model.AdminVersionRetention, error)
+	AdminGetHyphenation(ctx context.Context, accountID string) (model.AdminHyphenation, error)
+	AdminSetHyphenation(ctx context.Context, accountID string, enabled bool) (model.AdminHyphenation, error)
+	AdminExportStory(ctx context.Context, accountID string, slug string) (model.StoryBundle, error)
+	AdminImportStory(ctx context.Context, accountID string, bundle model.StoryBundle) (model.AdminStoryStatusResponse, error)
+
+	AdminCreateUpload(ctx context.Context, accountID string, req model.AdminUploadCreate) (model.AdminUpload, error)
+	AdminGetUpload(ctx context.Context, accountID string, uploadID string) (model.AdminUpload, error)
+	AdminAppendUploadChunk(ctx context.Context, accountID string, uploadID string, offset int64, data []byte) (model.AdminUpload, error)
+	AdminReadUpload(ctx context.Context, accountID string, uploadID string) (model.AdminUpload, []byte, error)
+	AdminDeleteUpload(ctx context.Context, accountID string, uploadID string) error
+
+	AdminCreateMedia(ctx context.Context, accountID string, contentType string, data []byte) (model.Media, error)
+	Media(ctx context.Context, accountID string, mediaID string) (model.Media, []byte, error)
+
+	AdminBackupIndex(ctx context.Context, accountID string) (model.BackupIndex, error)
+	AdminRestoreMedia(ctx context.Context, accountID string, media model.Media, data []byte) (bool, error)
+	AdminRestoreStory(ctx context.Context, accountID string, bundle model.StoryBundle, conflict model.RestoreConflict) (model.AdminRestoreStory, error)
+	AdminRestoreSettings(ctx context.Context, accountID string, settings model.BackupSettings, overwrite bool) (bool, error)
+	AdminRestoreProgress(ctx context.Context, accountID string, progress model.BackupProgress) (bool, error)
+
+	AdminAudited(ctx context.Context, accountID string, actor string, mutate func(ctx context.Context) (model.AdminAuditEntry, error)) error
+	AdminListAudit(ctx context.Context, accountID string, filter model.AdminAuditFilter) (model.AdminAuditListResponse, error)
+
+	AdminAuthenticateKey(ctx context.Context, accountID string, keyHash string) (model.AdminPrincipal, error)
+	AdminCreateUser(ctx context.Context, accountID string, req model.AdminUserCreate) (model.AdminUserRecord, error)
+	AdminListUsers(ctx context.Context, accountID string) (model.AdminUsersListResponse, error)
+	AdminDisableUser(ctx context.Context, accountID string, userID string) (model.AdminUserRecord, error)
+
+	AdminSensitivityReport(ctx context.Context, accountID string, slug string, versionID string, words []string) (model.AdminSensitivityReport, error)
+
+	AdminListStoryContributors(ctx context.Context, accountID string, slug string) (model.AdminStoryContributorsResponse, error)
+	AdminAddStoryContributor(ctx context.Context, accountID string, slug string, contributor model.StoryContributor) (model.AdminStoryContributorsResponse, error)
+	AdminRemoveStoryContributor(ctx context.Context, accountID string, slug string, contributorID string, role model.ContributorRole) (model.AdminStoryContributorsResponse, error)
+
+	AdminListWebhooks(ctx context.Context, accountID string) (model.AdminWebhooksListResponse, error)
+	AdminCreateWebhook(ctx context.Context, accountID string, req model.AdminWebhookCreate) (model.AdminWebhook, error)
+	AdminUpdateWebhook(ctx context.Context, accountID string, webhookID string, update model.AdminWebhookUpdate) (model.AdminWebhook, error)
+	AdminDeleteWebhook(ctx context.Context, accountID string, webhookID string) error
+	AdminListWebhookDeliveries(ctx context.Context, accountID string, webhookID string, limit int) (model.AdminWebhookDeliveriesResponse, error)
+
+	AdminListTags(ctx context.Context, accountID string, page model.PageRequest) (model.AdminTagsListResponse, error)
+	AdminCreateTag(ctx context.Context, accountID string, name string) (model.AdminTag, error)
+	AdminRenameTag(ctx context.Context, accountID string, tagID string, name string) (model.AdminTag, error)
+	AdminMergeTags(ctx context.Context, accountID string, sourceID string, targetID string) (model.AdminTag, error)
+	AdminDeleteTag(ctx context.Context, accountID string, tagID string) error
+	AdminAddStoryTags(ctx context.Context, accountID string, slug string, names []string) (model.AdminStoryTagsResponse, error)
+	AdminRemoveStoryTags(ctx context.Context, accountID string, slug string, names []string) (model.AdminStoryTagsResponse, error)
+
+	AdminStartRenderJob(ctx context.Context, accountID string) (model.RenderJob, error)
+	AdminGetRenderJob(ctx context.Context, accountID string, jobID string) (model.RenderJob, error)
+	AdminStartNarrationJob(ctx context.Context, accountID string, slug string, versionID string, voice string) (model.NarrationJob, error)
+	AdminGetNarrationJob(ctx context.Context, accountID string, jobID string) (model.NarrationJob, error)
+	AdminStartAlignmentJob(ctx context.Context, accountID string, slug string, versionID string) (model.AlignmentJob, error)
+	AdminGetAlignmentJob(ctx context.Context, accountID string, jobID string) (model.AlignmentJob, error)
+	AdminStartSearchReindex(ctx context.Context, accountID string, slug string) (model.SearchReindexJob, error)
+	AdminGetSearchReindexJob(ctx context.Context, accountID string, jobID string) (model.SearchReindexJob, error)
+	AdminGenerationProfiles(ctx context.Context, accountID string, childProfileID string, promptProfileID string) (model.ChildProfile, model.PromptProfile, error)
+	AdminRecordGeneration(ctx context.Context, accountID string, record model.GenerationRecord) (model.StoryGeneration, error)
+	AdminCheckTranslationSlug(ctx context.Context, accountID string, originalSlug string, slug string) error
+	AdminLinkTranslation(ctx context.Context, accountID string, slug string, originalSlug string) (string, error)
+	AdminCheckSimplificationSlug(ctx context.Context, accountID string, originalSlug string, slug string) error
+	AdminLinkSimplification(ctx context.Context, accountID string, slug string, originalSlug string, age int) error
+	AdminQuizChapter(ctx context.Context, accountID string, slug string, versionID string, chapter int) (model.QuizChapterSource, error)
+	AdminGetQuiz(ctx context.Context, accountID string, slug string, versionID string) (model.AdminQuizResponse, error)
+	AdminSaveQuiz(ctx context.Context, accountID string, slug string, versionID string, chapter int, save model.QuizSave) (model.AdminChapterQuiz, error)
+	AdminDeleteQuiz(ctx context.Context, accountID string, slug string, versionID string, chapter int) error
+	AdminGetDiscussion(ctx context.Context, accountID string, slug string, versionID string) (model.AdminDiscussionResponse, error)
+	AdminSaveDiscussion(ctx context.Context, accountID string, slug string, versionID string, chapter int, questions []string) (model.AdminChapterDiscussion, error)
+	AdminDeleteDiscussion(ctx context.Context, accountID string, slug string, versionID string, chapter int) error
+	AdminPhonics(ctx context.Context, accountID string, slug string, versionID string, progression string) (model.AdminPhonicsResponse, error)
+
+	DatabaseStats() model.DatabaseStats
+	QueryStats() []model.QueryLatency
+
+	idempotency.Store
 }
 
 const (
 	// Admin endpoints need a bigger body limit for large Gutenberg books.
 	// Keep public APIs small; only admin gets this.
 	maxJSONBodyBytes = 20 << 20 // 20MB
+
+	// A gzip-encoded body is held to maxJSONBodyBytes as sent and to
+	// maxInflatedJSONBodyBytes once inflated; Markdown compresses well past
+	// five to one, so the inflated limit is the one imports reach.
+	maxInflatedJSONBodyBytes = 5 * maxJSONBodyBytes
+
+	// The bootstrap PP_ADMIN_KEY cannot distinguish people, so audit rows
+	// record the credential that authorised the mutation.
+	adminKeyActor = "admin_key"
+
+	maxAdminUserNameRunes = 80
+	maxTagsPerRequest     = 50
+	maxContributorRunes   = 200
+	adminKeyPrefix        = "ppak_"
+)
+
+var (
+	adminReadRoles    = model.AdminRoles
+	adminPreviewRoles = []model.AdminRole{model.AdminRoleImporter, model.AdminRoleEditor}
+	adminImportRoles  = []model.AdminRole{model.AdminRoleImporter}
+	adminEditRoles    = []model.AdminRole{model.AdminRoleEditor}
+	adminPublishRoles = []model.AdminRole{model.AdminRolePublisher}
+
+	// bootstrapPrincipal holds every role so a deployment can operate before
+	// any admin users exist.
+	bootstrapPrincipal = model.AdminPrincipal{Name: adminKeyActor, Roles: model.AdminRoles}
 )
 
 var adminVersionIDPattern = regexp.MustCompile("(?i)^[0-9a-f]{8}-[0-9a-f]{4}-[1-8][0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$")
 
 type ctxKey string
 
-const ctxAccountID ctxKey = "pp_account_id"
+const (
+	ctxAccountID ctxKey = "pp_account_id"
+	ctxPrincipal ctxKey = "pp_admin_principal"
+)
 
 func accountIDFromCtx(r *http.Request) string {
 	v, _ := r.Context().Value(ctxAccountID).(string)
 	return v
 }
 
+func principalFromCtx(r *http.Request) model.AdminPrincipal {
+	v, _ := r.Context().Value(ctxPrincipal).(model.AdminPrincipal)
+	return v
+}
+
+func withPrincipal(ctx context.Context, accountID string, principal model.AdminPrincipal) context.Context {
+	ctx = context.WithValue(ctx, ctxAccountID, accountID)
+	return context.WithValue(ctx, ctxPrincipal, principal)
+}
+
 func New(cfg Config, store Store) http.Handler {
 	adminKey := strings.TrimSpace(cfg.AdminKey)
 	if adminKey == "" {
@@ -56,41 +187,87 @@ func New(cfg Config, store Store) http.Handler {
 
 	mux := http.NewServeMux()
 
-	withAdmin := func(next http.HandlerFunc) http.HandlerFunc {
+	// authenticate resolves the caller to an admin principal. Any failure has
+	// already been written to w when ok is false.
+	authenticate := func(w http.ResponseWriter, r *http.Request) (string, model.AdminPrincipal, bool) {
+		// 1) require the shared signed session and its existing account.
+		aid, err := authenticator.Authenticate(r)
+		if errors.Is(err, httpauth.ErrInvalidSession) {
+			cfg.Sessions.Clear(w)
+			writeErr(w, http.StatusUnauthorized, "unauthorized", "unlock required")
+			return "", model.AdminPrincipal{}, false
+		}
+		if err != nil {
+			writeErr(w, http.StatusServiceUnavailable, "session_unavailable", "session validation unavailable")
+			return "", model.AdminPrincipal{}, false
+		}
+
+		// 2) retain the proxy-injected admin key boundary. The bootstrap key is
+		// checked first and never touches the database; every other key is
+		// hashed before it reaches the Store.
+		got := strings.TrimSpace(r.Header.Get("X-PP-Admin-Key"))
+		if adminKeyOK(got, adminKey) {
+			httpmiddleware.SetAccount(r.Context(), aid)
+			return aid, bootstrapPrincipal, true
+		}
+		if got == "" {
+			writeErr(w, http.StatusForbidden, "forbidden", "admin key required")
+			return "", model.AdminPrincipal{}, false
+		}
+		principal, err := store.AdminAuthenticateKey(r.Context(), aid, hashAdminKey(got))
+		if errors.Is(err, model.ErrAdminUserNotFound) {
+			writeErr(w, http.StatusForbidden, "forbidden", "admin key required")
+			return "", model.AdminPrincipal{}, false
+		}
+		if err != nil {
+			slog.Error("admin key validation failed")
+			writeErr(w, http.StatusServiceUnavailable, "admin_unavailable", "admin key validation unavailable")
+			return "", model.AdminPrincipal{}, false
+		}
+		httpmiddleware.SetAccount(r.Context(), aid)
+		return aid, principal, true
+	}
+
+	// withAdmin admits principals holding at least one of roles.
+	withAdmin := func(roles []model.AdminRole, next http.HandlerFunc) http.HandlerFunc {
 		return func(w http.ResponseWriter, r *http.Request) {
-			// 1) require the shared signed session and its existing account.
-			aid, err := authenticator.Authenticate(r)
-			if errors.Is(err, httpauth.ErrInvalidSession) {
-				cfg.Sessions.Clear(w)
-				writeErr(w, http.StatusUnauthorized, "unauthorized", "unlock required")
+			aid, principal, ok := authenticate(w, r)
+			if !ok {
 				return
 			}
-			if err != nil {
-				writeErr(w, http.StatusServiceUnavailable, "session_unavailable", "session validation unavailable")
+			if !principal.HasAnyRole(roles...) {
+				writeErr(w, http.StatusForbidden, "forbidden", "admin role required")
 				return
 			}
+			next(w, r.WithContext(withPrincipal(r.Context(), aid, principal)))
+		}
+	}
 
-			// 2) retain the proxy-injected admin key boundary.
-			got := strings.TrimSpace(r.Header.Get("X-PP-Admin-Key"))
-			if !adminKeyOK(got, adminKey) {
-				writeErr(w, http.StatusForbidden, "forbidden", "admin key required")
+	// withBootstrapAdmin admits only the deployment's PP_ADMIN_KEY, which is
+	// the sole credential allowed to manage admin users and webhooks.
+	withBootstrapAdmin := func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			aid, principal, ok := authenticate(w, r)
+			if !ok {
 				return
 			}
-
-			ctx := context.WithValue(r.Context(), ctxAccountID, aid)
-			next(w, r.WithContext(ctx))
+			if principal.UserID != "" {
+				writeErr(w, http.StatusForbidden, "forbidden", "bootstrap admin key required")
+				return
+			}
+			next(w, r.WithContext(withPrincipal(r.Context(), aid, principal)))
 		}
 	}
 
 	// POST /api/v1/admin/preview
-	mux.HandleFunc("POST /api/v1/admin/preview", withAdmin(func(w http.ResponseWriter, r *http.Request) {
+	mux.HandleFunc("POST /api/v1/admin/preview", withAdmin(adminPreviewRoles, func(w http.ResponseWriter, r *http.Request) {
 		var body model.AdminPreviewRequest
 		if err := decodeJSON(w, r, &body); err != nil {
 			writeDecodeError(w, err)
 			return
 		}
 
-		out, err := store.AdminPreview(body)
+		out, err := store.AdminPreview(r.Context(), body)
 		if err != nil {
 			var validationErr *model.AdminValidationError
 			if errors.As(err, &validationErr) {
@@ -106,28 +283,23 @@ func New(cfg Config, store Store) http.Handler {
 		writeJSON(w, http.StatusOK, out)
 	}))
 
-	// POST /api/v1/admin/stories/draft
-	mux.HandleFunc("POST /api/v1/admin/stories/draft", withAdmin(func(w http.ResponseWriter, r *http.Request) {
-		var body model.AdminDraftUpsertRequest
+	// POST /api/v1/admin/lint
+	mux.HandleFunc("POST /api/v1/admin/lint", withAdmin(adminPreviewRoles, func(w http.ResponseWriter, r *http.Request) {
+		var body model.AdminStoryInput
 		if err := decodeJSON(w, r, &body); err != nil {
 			writeDecodeError(w, err)
 			return
 		}
 
-		aid := accountIDFromCtx(r)
-		out, err := store.AdminDraftUpsert(aid, body)
+		out, err := store.AdminLint(r.Context(), body)
 		if err != nil {
 			var validationErr *model.AdminValidationError
 			if errors.As(err, &validationErr) {
-				writeIssues(w, http.StatusBadRequest, "draft_invalid", "Story content is invalid", validationErr.Issues)
-				return
-			}
-			if errors.Is(err, model.ErrAdminVersionRepairRequired) {
-				writeErr(w, http.StatusConflict, "draft_repair_required", "stored story version requires repair")
+				writeIssues(w, http.StatusBadRequest, "lint_invalid", "Story content is invalid", validationErr.Issues)
 				return
 			}
-			slog.Error("admin story draft failed")
-			writeErr(w, http.StatusInternalServerError, "draft_failed", "story draft could not be saved")
+			slog.Error("admin story lint failed")
+			writeErr(w, http.StatusInternalServerError, "lint_failed", "story lint failed")
 			return
 		}
 
@@ -135,10 +307,53 @@ func New(cfg Config, store Store) http.Handler {
 		writeJSON(w, http.StatusOK, out)
 	}))
 
-	mux.HandleFunc("GET /api/v1/admin/stories", withAdmin(func(w http.ResponseWriter, r *http.Request) {
-		aid := accountIDFromCtx(r)
+	// POST /api/v1/admin/validate
+	mux.HandleFunc("POST /api/v1/admin/validate", withAdmin(adminPreviewRoles, func(w http.ResponseWriter, r *http.Request) {
+		var body model.AdminStoryInput
+		if err := decodeJSON(w, r, &body); err != nil {
+			writeDecodeError(w, err)
+			return
+		}
+
+		out, err := store.AdminValidate(r.Context(), body)
+		if err != nil {
+			slog.Error("admin story validation failed")
+			writeErr(w, http.StatusInternalServerError, "validate_failed", "story validation failed")
+			return
+		}
 
-		out, err := store.AdminListStories(aid)
+		noStore(w)
+		writeJSON(w, http.StatusOK, out)
+	}))
+
+	// POST /api/v1/admin/stories/draft
+	mux.HandleFunc("POST /api/v1/admin/stories/draft", withAdmin(adminImportRoles, idempotent(store, func(w http.ResponseWriter, r *http.Request) {
+		var body model.AdminDraftUpsertRequest
+		if err := decodeJSON(w, r, &body); err != nil {
+			writeDecodeError(w, err)
+			return
+		}
+		serveDraftUpsert(store, w, r, body)
+	})))
+
+	// GET /api/v1/admin/stories?cursor=&limit=
+	mux.HandleFunc("GET /api/v1/admin/stories", withAdmin(adminReadRoles, func(w http.ResponseWriter, r *http.Request) {
+		page, ok := model.ParsePageRequest(r.URL.Query())
+		if !ok {
+			writeErr(w, http.StatusBadRequest, "page_invalid", "page limit must be a positive integer and total true or false")
+			return
+		}
+
+		fields, ok := parseFields(w, r, model.AdminStorySummary{})
+		if !ok {
+			return
+		}
+
+		out, err := store.AdminListStories(r.Context(), accountIDFromCtx(r), page)
+		if errors.Is(err, model.ErrInvalidCursor) {
+			writeErr(w, http.StatusBadRequest, "cursor_invalid", "page cursor is invalid")
+			return
+		}
 		if err != nil {
 			slog.Error("admin story catalogue failed")
 			writeErr(w, http.StatusInternalServerError, "list_failed", "story catalogue unavailable")
@@ -146,13 +361,13 @@ func New(cfg Config, store Store) http.Handler {
 		}
 
 		noStore(w)
-		writeJSON(w, http.StatusOK, out)
+		writeSelected(w, fields, out)
 	}))
 
 	// GET /api/v1/admin/stories/{slug}
-	mux.HandleFunc("GET /api/v1/admin/stories/{slug}", withAdmin(func(w http.ResponseWriter, r *http.Request) {
+	mux.HandleFunc("GET /api/v1/admin/stories/{slug}", withAdmin(adminReadRoles, func(w http.ResponseWriter, r *http.Request) {
 		slug := strings.TrimSpace(r.PathValue("slug"))
-		out, err := store.AdminGetStory(accountIDFromCtx(r), slug)
+		out, err := store.AdminGetStory(r.Context(), accountIDFromCtx(r), slug)
 		if err != nil {
 			if errors.Is(err, model.ErrAdminStoryNotFound) {
 				writeErr(w, http.StatusNotFound, "story_not_found", "story was not found")
@@ -166,11 +381,109 @@ func New(cfg Config, store Store) http.Handler {
 		writeJSON(w, http.StatusOK, out)
 	}))
 
+	// GET /api/v1/admin/stories/{slug}/versions?cursor=&limit=
+	mux.HandleFunc("GET /api/v1/admin/stories/{slug}/versions", withAdmin(adminReadRoles, func(w http.ResponseWriter, r *http.Request) {
+		page, ok := model.ParsePageRequest(r.URL.Query())
+		if !ok {
+			writeErr(w, http.StatusBadRequest, "page_invalid", "page limit must be a positive integer and total true or false")
+			return
+		}
+		fields, ok := parseFields(w, r, model.AdminVersionSummary{})
+		if !ok {
+			return
+		}
+		out, err := store.AdminListStoryVersions(r.Context(), accountIDFromCtx(r), strings.TrimSpace(r.PathValue("slug")), page)
+		if err != nil {
+			if errors.Is(err, model.ErrAdminStoryNotFound) {
+				writeErr(w, http.StatusNotFound, "story_not_found", "story was not found")
+				return
+			}
+			if errors.Is(err, model.ErrInvalidCursor) {
+				writeErr(w, http.StatusBadRequest, "cursor_invalid", "page cursor is invalid")
+				return
+			}
+			slog.Error("admin story versions failed")
+			writeErr(w, http.StatusInternalServerError, "versions_failed", "story versions unavailable")
+			return
+		}
+		noStore(w)
+		writeSelected(w, fields, out)
+	}))
+
+	// PATCH /api/v1/admin/stories/{slug}
+	// Metadata-only changes: the content is not re-ingested and no version is
+	// created. An empty author clears it; an empty tags list removes all tags.
+	mux.HandleFunc("PATCH /api/v1/admin/stories/{slug}", withAdmin(adminEditRoles, func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Title    *string        `json:"title"`
+			Author   *string        `json:"author"`
+			Language *string        `json:"language"`
+			Rights   map[string]any `json:"rights"`
+			Tags     []string       `json:"tags"`
+		}
+		if err := decodeJSON(w, r, &body); err != nil {
+			writeDecodeError(w, err)
+			return
+		}
+		if body.Title == nil && body.Author == nil && body.Language == nil && body.Rights == nil && body.Tags == nil {
+			writeErr(w, http.StatusBadRequest, "bad_request", "no changes requested")
+			return
+		}
+		patch := model.AdminStoryMetadataPatch{
+			Title:    body.Title,
+			Author:   body.Author,
+			Language: body.Language,
+			Rights:   body.Rights,
+		}
+		if body.Tags != nil {
+			patch.Tags = []string{}
+			if len(body.Tags) > 0 {
+				names, ok := normalizeTagNames(body.Tags)
+				if !ok {
+					writeErr(w, http.StatusBadRequest, "tag_invalid", "tags must be at most 50 non-empty names")
+					return
+				}
+				patch.Tags = names
+			}
+		}
+
+		changed := []string{}
+		for field, set := range map[string]bool{
+			"title": body.Title != nil, "author": body.Author != nil, "language": body.Language != nil,
+			"rights": body.Rights != nil, "tags": body.Tags != nil,
+		} {
+			if set {
+				changed = append(changed, field)
+			}
+		}
+		sort.Strings(changed)
+		out, err := audited(store, r, func(ctx context.Context) (model.AdminStoryMetadataResponse, model.AdminAuditEntry, error) {
+			out, err := store.AdminPatchStoryMetadata(ctx, accountIDFromCtx(r), strings.TrimSpace(r.PathValue("slug")), patch)
+			return out, model.AdminAuditEntry{Action: model.AdminAuditActionMetadata, Slug: out.Slug, Summary: map[string]any{"fields": changed}}, err
+		})
+		if err != nil {
+			var validationErr *model.AdminValidationError
+			switch {
+			case errors.As(err, &validationErr):
+				writeIssues(w, http.StatusBadRequest, "metadata_invalid", "Story metadata is invalid", validationErr.Issues)
+			case errors.Is(err, model.ErrAdminStoryNotFound):
+				writeErr(w, http.StatusNotFound, "story_not_found", "story was not found")
+			default:
+				slog.Error("admin story metadata update failed")
+				writeErr(w, http.StatusInternalServerError, "metadata_failed", "story metadata could not be updated")
+			}
+			return
+		}
+
+		noStore(w)
+		writeJSON(w, http.StatusOK, out)
+	}))
+
 	// GET /api/v1/admin/stories/{slug}/versions/{versionId}
-	mux.HandleFunc("GET /api/v1/admin/stories/{slug}/versions/{versionId}", withAdmin(func(w http.ResponseWriter, r *http.Request) {
+	mux.HandleFunc("GET /api/v1/admin/stories/{slug}/versions/{versionId}", withAdmin(adminReadRoles, func(w http.ResponseWriter, r *http.Request) {
 		slug := strings.TrimSpace(r.PathValue("slug"))
 		versionID := strings.TrimSpace(r.PathValue("versionId"))
-		out, err := store.AdminGetVersionSource(accountIDFromCtx(r), slug, versionID)
+		out, err := store.AdminGetVersionSource(r.Context(), accountIDFromCtx(r), slug, versionID)
 		if err != nil {
 			switch {
 			case errors.Is(err, model.ErrAdminStoryNotFound):
@@ -188,7 +501,7 @@ func New(cfg Config, store Store) http.Handler {
 	}))
 
 	// POST /api/v1/admin/stories/{slug}/publish
-	mux.HandleFunc("POST /api/v1/admin/stories/{slug}/publish", withAdmin(func(w http.ResponseWriter, r *http.Request) {
+	mux.HandleFunc("POST /api/v1/admin/stories/{slug}/publish", withAdmin(adminPublishRoles, idempotent(store, func(w http.ResponseWriter, r *http.Request) {
 		slug := strings.TrimSpace(r.PathValue("slug"))
 		if slug == "" {
 			writeErr(w, http.StatusBadRequest, "bad_request", "slug required")
@@ -197,6 +510,9 @@ func New(cfg Config, store Store) http.Handler {
 
 		var body struct {
 			VersionID string `json:"versionId"`
+			// AcknowledgeModeration publishes a version moderation flagged,
+			// once its findings have been reviewed.
+			AcknowledgeModeration bool `json:"acknowledgeModeration"`
 		}
 		if err := decodeJSON(w, r, &body); err != nil {
 			writeDecodeError(w, err)
@@ -209,7 +525,13 @@ func New(cfg Config, store Store) http.Handler {
 			writeErr(w, http.StatusBadRequest, "publish_invalid", "versionId must be a valid identifier")
 			return
 		}
-		out, err := store.AdminPublishStory(aid, slug, body.VersionID)
+		out, err := audited(store, r, func(ctx context.Context) (model.AdminStoryStatusResponse, model.AdminAuditEntry, error) {
+			out, err := store.AdminPublishStory(ctx, aid, slug, body.VersionID, body.AcknowledgeModeration)
+			return out, model.AdminAuditEntry{Action: model.AdminAuditActionPublish, Slug: out.Slug, Summary: map[string]any{
+				"versionId":             body.VersionID,
+				"acknowledgeModeration": body.AcknowledgeModeration,
+			}}, err
+		})
 		if err != nil {
 			if errors.Is(err, model.ErrAdminPublishNotFound) {
 				writeErr(w, http.StatusNotFound, "publish_not_found", "story version was not found")
@@ -219,21 +541,39 @@ func New(cfg Config, store Store) http.Handler {
 				writeErr(w, http.StatusConflict, "publish_repair_required", "story version is unavailable or unreadable")
 				return
 			}
+			if errors.Is(err, model.ErrModerationBlocked) {
+				writeErr(w, http.StatusConflict, "publish_moderation_blocked", "moderation blocked this story version")
+				return
+			}
+			if errors.Is(err, model.ErrModerationUnacknowledged) {
+				writeErr(w, http.StatusConflict, "publish_moderation_flagged", "moderation flagged this story version; acknowledge its findings to publish")
+				return
+			}
 			// Driver errors may contain connection or query detail. Keep both the
 			// browser response and application logs on a fixed safe boundary.
 			slog.Error("admin story publication failed")
 			writeErr(w, http.StatusInternalServerError, "publish_failed", "story publication failed")
 			return
 		}
+		// The scan is advisory and runs after the commit, so its failure only
+		// costs the warning flag.
+		if report, err := store.AdminSensitivityReport(r.Context(), aid, out.Slug, body.VersionID, cfg.SensitivityWords); err != nil {
+			slog.Error("admin sensitivity scan failed")
+		} else {
+			out.SensitivityWarning = report.Flagged
+		}
 
 		noStore(w)
 		writeJSON(w, http.StatusOK, out)
-	}))
+	})))
 
 	// POST /api/v1/admin/stories/{slug}/unpublish
-	mux.HandleFunc("POST /api/v1/admin/stories/{slug}/unpublish", withAdmin(func(w http.ResponseWriter, r *http.Request) {
+	mux.HandleFunc("POST /api/v1/admin/stories/{slug}/unpublish", withAdmin(adminPublishRoles, func(w http.ResponseWriter, r *http.Request) {
 		slug := strings.TrimSpace(r.PathValue("slug"))
-		out, err := store.AdminUnpublish(accountIDFromCtx(r), slug)
+		out, err := audited(store, r, func(ctx context.Context) (model.AdminStoryStatusResponse, model.AdminAuditEntry, error) {
+			out, err := store.AdminUnpublish(ctx, accountIDFromCtx(r), slug)
+			return out, model.AdminAuditEntry{Action: model.AdminAuditActionUnpublish, Slug: out.Slug, Summary: map[string]any{}}, err
+		})
 		if err != nil {
 			if errors.Is(err, model.ErrAdminStoryNotFound) {
 				writeErr(w, http.StatusNotFound, "unpublish_not_found", "story was not found")
@@ -247,6 +587,421 @@ func New(cfg Config, store Store) http.Handler {
 		writeJSON(w, http.StatusOK, out)
 	}))
 
+	// GET /api/v1/admin/stories/{slug}/export
+	mux.HandleFunc("GET /api/v1/admin/stories/{slug}/export", withAdmin(adminReadRoles, func(w http.ResponseWriter, r *http.Request) {
+		slug := strings.TrimSpace(r.PathValue("slug"))
+		out, err := store.AdminExportStory(r.Context(), accountIDFromCtx(r), slug)
+		if err != nil {
+			switch {
+			case errors.Is(err, model.ErrAdminStoryNotFound):
+				writeErr(w, http.StatusNotFound, "story_not_found", "story was not found")
+			case errors.Is(err, model.ErrAdminVersionRepairRequired):
+				writeErr(w, http.StatusConflict, "export_repair_required", "story requires repair before export")
+			default:
+				slog.Error("admin story export failed")
+				writeErr(w, http.StatusInternalServerError, "export_failed", "story export unavailable")
+			}
+			return
+		}
+		noStore(w)
+		w.Header().Set("Content-Disposition", `attachment; filename="`+out.Story.Slug+`.pandapages.json"`)
+		writeJSON(w, http.StatusOK, out)
+	}))
+
+	// POST /api/v1/admin/stories/import
+	mux.HandleFunc("POST /api/v1/admin/stories/import", withAdmin(adminImportRoles, func(w http.ResponseWriter, r *http.Request) {
+		var body model.StoryBundle
+		if err := decodeJSON(w, r, &body); err != nil {
+			writeDecodeError(w, err)
+			return
+		}
+		serveStoryImport(store, w, r, body)
+	}))
+
+	// GET /api/v1/admin/audit?action=&slug=&actor=&since=&until=&cursor=&limit=
+	mux.HandleFunc("GET /api/v1/admin/audit", withAdmin(adminReadRoles, func(w http.ResponseWriter, r *http.Request) {
+		filter, ok := parseAuditFilter(r)
+		if !ok {
+			writeErr(w, http.StatusBadRequest, "audit_filter_invalid", "audit filter is invalid")
+			return
+		}
+		fields, ok := parseFields(w, r, model.AdminAuditRecord{})
+		if !ok {
+			return
+		}
+		out, err := store.AdminListAudit(r.Context(), accountIDFromCtx(r), filter)
+		if errors.Is(err, model.ErrInvalidCursor) {
+			writeErr(w, http.StatusBadRequest, "cursor_invalid", "page cursor is invalid")
+			return
+		}
+		if err != nil {
+			slog.Error("admin audit log query failed")
+			writeErr(w, http.StatusInternalServerError, "audit_failed", "audit log unavailable")
+			return
+		}
+		noStore(w)
+		writeSelected(w, fields, out)
+	}))
+
+	// POST /api/v1/admin/stories/{slug}/tags
+	// DELETE /api/v1/admin/stories/{slug}/tags
+	for _, change := range []struct {
+		method string
+		add    bool
+		action model.AdminAuditAction
+	}{
+		{method: http.MethodPost, add: true, action: model.AdminAuditActionStoryTagAdd},
+		{method: http.MethodDelete, add: false, action: model.AdminAuditActionStoryTagRm},
+	} {
+		mux.HandleFunc(change.method+" /api/v1/admin/stories/{slug}/tags", withAdmin(adminEditRoles, func(w http.ResponseWriter, r *http.Request) {
+			var body struct {
+				Tags []string `json:"tags"`
+			}
+			if err := decodeJSON(w, r, &body); err != nil {
+				writeDecodeError(w, err)
+				return
+			}
+			names, ok := normalizeTagNames(body.Tags)
+			if !ok {
+				writeErr(w, http.StatusBadRequest, "tag_invalid", "tags must be 1-50 non-empty names")
+				return
+			}
+
+			slug := strings.TrimSpace(r.PathValue("slug"))
+			out, err := audited(store, r, func(ctx context.Context) (model.AdminStoryTagsResponse, model.AdminAuditEntry, error) {
+				var (
+					out model.AdminStoryTagsResponse
+					err error
+				)
+				if change.add {
+					out, err = store.AdminAddStoryTags(ctx, accountIDFromCtx(r), slug, names)
+				} else {
+					out, err = store.AdminRemoveStoryTags(ctx, accountIDFromCtx(r), slug, names)
+				}
+				return out, model.AdminAuditEntry{Action: change.action, Slug: out.Slug, Summary: map[string]any{"tags": names}}, err
+			})
+			if err != nil {
+				if errors.Is(err, model.ErrAdminStoryNotFound) {
+					writeErr(w, http.StatusNotFound, "story_not_found", "story was not found")
+					return
+				}
+				slog.Error("admin story tag update failed")
+				writeErr(w, http.StatusInternalServerError, "tag_failed", "story tags could not be updated")
+				return
+			}
+			noStore(w)
+			writeJSON(w, http.StatusOK, out)
+		}))
+	}
+
+	// GET /api/v1/admin/stories/{slug}/sensitivity-report?versionId=
+	mux.HandleFunc("GET /api/v1/admin/stories/{slug}/sensitivity-report", withAdmin(adminReadRoles, func(w http.ResponseWriter, r *http.Request) {
+		versionID := strings.TrimSpace(r.URL.Query().Get("versionId"))
+		if versionID != "" && !adminVersionIDPattern.MatchString(versionID) {
+			writeErr(w, http.StatusBadRequest, "bad_request", "versionId must be a valid identifier")
+			return
+		}
+		out, err := store.AdminSensitivityReport(r.Context(), accountIDFromCtx(r), strings.TrimSpace(r.PathValue("slug")), versionID, cfg.SensitivityWords)
+		if err != nil {
+			if errors.Is(err, model.ErrAdminStoryNotFound) {
+				writeErr(w, http.StatusNotFound, "story_not_found", "story version was not found")
+				return
+			}
+			slog.Error("admin sensitivity report failed")
+			writeErr(w, http.StatusInternalServerError, "sensitivity_failed", "sensitivity report unavailable")
+			return
+		}
+		noStore(w)
+		writeJSON(w, http.StatusOK, out)
+	}))
+
+	// GET /api/v1/admin/stories/{slug}/contributors
+	mux.HandleFunc("GET /api/v1/admin/stories/{slug}/contributors", withAdmin(adminReadRoles, func(w http.ResponseWriter, r *http.Request) {
+		out, err := store.AdminListStoryContributors(r.Context(), accountIDFromCtx(r), strings.TrimSpace(r.PathValue("slug")))
+		if err != nil {
+			writeContributorErr(w, err, "admin contributor list failed")
+			return
+		}
+		noStore(w)
+		writeJSON(w, http.StatusOK, out)
+	}))
+
+	// POST /api/v1/admin/stories/{slug}/contributors
+	mux.HandleFunc("POST /api/v1/admin/stories/{slug}/contributors", withAdmin(adminEditRoles, func(w http.ResponseWriter, r *http.Request) {
+		var body model.StoryContributor
+		if err := decodeJSON(w, r, &body); err != nil {
+			writeDecodeError(w, err)
+			return
+		}
+		body.Name = strings.TrimSpace(body.Name)
+		if body.Name == "" || utf8.RuneCountInString(body.Name) > maxContributorRunes {
+			writeErr(w, http.StatusBadRequest, "contributor_invalid", "name is invalid")
+			return
+		}
+		if !body.Role.Valid() {
+			writeErr(w, http.StatusBadRequest, "contributor_invalid", "role is invalid")
+			return
+		}
+		out, err := audited(store, r, func(ctx context.Context) (model.AdminStoryContributorsResponse, model.AdminAuditEntry, error) {
+			out, err := store.AdminAddStoryContributor(ctx, accountIDFromCtx(r), strings.TrimSpace(r.PathValue("slug")), body)
+			return out, model.AdminAuditEntry{Action: model.AdminAuditActionContribAdd, Slug: out.Slug, Summary: map[string]any{"name": body.Name, "role": body.Role}}, err
+		})
+		if err != nil {
+			writeContributorErr(w, err, "admin contributor add failed")
+			return
+		}
+		noStore(w)
+		writeJSON(w, http.StatusOK, out)
+	}))
+
+	// DELETE /api/v1/admin/stories/{slug}/contributors/{contributorId}/{role}
+	mux.HandleFunc("DELETE /api/v1/admin/stories/{slug}/contributors/{contributorId}/{role}", withAdmin(adminEditRoles, func(w http.ResponseWriter, r *http.Request) {
+		contributorID := strings.TrimSpace(r.PathValue("contributorId"))
+		role := model.ContributorRole(strings.TrimSpace(r.PathValue("role")))
+		out, err := audited(store, r, func(ctx context.Context) (model.AdminStoryContributorsResponse, model.AdminAuditEntry, error) {
+			out, err := store.AdminRemoveStoryContributor(ctx, accountIDFromCtx(r), strings.TrimSpace(r.PathValue("slug")), contributorID, role)
+			return out, model.AdminAuditEntry{Action: model.AdminAuditActionContribRm, Slug: out.Slug, Summary: map[string]any{"contributorId": contributorID, "role": role}}, err
+		})
+		if err != nil {
+			writeContributorErr(w, err, "admin contributor remove failed")
+			return
+		}
+		noStore(w)
+		writeJSON(w, http.StatusOK, out)
+	}))
+
+	// GET /api/v1/admin/tags?cursor=&limit=
+	mux.HandleFunc("GET /api/v1/admin/tags", withAdmin(adminReadRoles, func(w http.ResponseWriter, r *http.Request) {
+		page, ok := model.ParsePageRequest(r.URL.Query())
+		if !ok {
+			writeErr(w, http.StatusBadRequest, "page_invalid", "page limit must be a positive integer and total true or false")
+			return
+		}
+		fields, ok := parseFields(w, r, model.AdminTag{})
+		if !ok {
+			return
+		}
+		out, err := store.AdminListTags(r.Context(), accountIDFromCtx(r), page)
+		if errors.Is(err, model.ErrInvalidCursor) {
+			writeErr(w, http.StatusBadRequest, "cursor_invalid", "page cursor is invalid")
+			return
+		}
+		if err != nil {
+			slog.Error("admin tag list failed")
+			writeErr(w, http.StatusInternalServerError, "tag_failed", "tags unavailable")
+			return
+		}
+		noStore(w)
+		writeSelected(w, fields, out)
+	}))
+
+	// POST /api/v1/admin/tags
+	mux.HandleFunc("POST /api/v1/admin/tags", withAdmin(adminEditRoles, func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Name string `json:"name"`
+		}
+		if err := decodeJSON(w, r, &body); err != nil {
+			writeDecodeError(w, err)
+			return
+		}
+		name, ok := model.NormalizeAdminTagName(body.Name)
+		if !ok {
+			writeErr(w, http.StatusBadRequest, "tag_invalid", "name is invalid")
+			return
+		}
+		tag, err := audited(store, r, func(ctx context.Context) (model.AdminTag, model.AdminAuditEntry, error) {
+			tag, err := store.AdminCreateTag(ctx, accountIDFromCtx(r), name)
+			return tag, model.AdminAuditEntry{Action: model.AdminAuditActionTagCreate, Summary: map[string]any{"tagId": tag.ID, "name": tag.Name}}, err
+		})
+		if err != nil {
+			writeTagErr(w, err, "admin tag creation failed")
+			return
+		}
+		noStore(w)
+		writeJSON(w, http.StatusCreated, tag)
+	}))
+
+	// POST /api/v1/admin/tags/{id}/rename
+	mux.HandleFunc("POST /api/v1/admin/tags/{id}/rename", withAdmin(adminEditRoles, func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Name string `json:"name"`
+		}
+		if err := decodeJSON(w, r, &body); err != nil {
+			writeDecodeError(w, err)
+			return
+		}
+		name, ok := model.NormalizeAdminTagName(body.Name)
+		if !ok {
+			writeErr(w, http.StatusBadRequest, "tag_invalid", "name is invalid")
+			return
+		}
+		tag, err := audited(store, r, func(ctx context.Context) (model.AdminTag, model.AdminAuditEntry, error) {
+			tag, err := store.AdminRenameTag(ctx, accountIDFromCtx(r), strings.TrimSpace(r.PathValue("id")), name)
+			return tag, model.AdminAuditEntry{Action: model.AdminAuditActionTagRename, Summary: map[string]any{"tagId": tag.ID, "name": tag.Name}}, err
+		})
+		if err != nil {
+			writeTagErr(w, err, "admin tag rename failed")
+			return
+		}
+		noStore(w)
+		writeJSON(w, http.StatusOK, tag)
+	}))
+
+	// POST /api/v1/admin/tags/{id}/merge
+	mux.HandleFunc("POST /api/v1/admin/tags/{id}/merge", withAdmin(adminEditRoles, func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			IntoTagID string `json:"intoTagId"`
+		}
+		if err := decodeJSON(w, r, &body); err != nil {
+			writeDecodeError(w, err)
+			return
+		}
+		sourceID := strings.TrimSpace(r.PathValue("id"))
+		targetID := strings.TrimSpace(body.IntoTagID)
+		if targetID == "" || strings.EqualFold(sourceID, targetID) {
+			writeErr(w, http.StatusBadRequest, "tag_invalid", "intoTagId must name a different tag")
+			return
+		}
+		tag, err := audited(store, r, func(ctx context.Context) (model.AdminTag, model.AdminAuditEntry, error) {
+			tag, err := store.AdminMergeTags(ctx, accountIDFromCtx(r), sourceID, targetID)
+			return tag, model.AdminAuditEntry{Action: model.AdminAuditActionTagMerge, Summary: map[string]any{"fromTagId": sourceID, "tagId": tag.ID}}, err
+		})
+		if err != nil {
+			writeTagErr(w, err, "admin tag merge failed")
+			return
+		}
+		noStore(w)
+		writeJSON(w, http.StatusOK, tag)
+	}))
+
+	// DELETE /api/v1/admin/tags/{id}
+	mux.HandleFunc("DELETE /api/v1/admin/tags/{id}", withAdmin(adminEditRoles, func(w http.ResponseWriter, r *http.Request) {
+		tagID := strings.TrimSpace(r.PathValue("id"))
+		err := audit(store, r, func(ctx context.Context) (model.AdminAuditEntry, error) {
+			err := store.AdminDeleteTag(ctx, accountIDFromCtx(r), tagID)
+			return model.AdminAuditEntry{Action: model.AdminAuditActionTagDelete, Summary: map[string]any{"tagId": tagID}}, err
+		})
+		if err != nil {
+			writeTagErr(w, err, "admin tag delete failed")
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	// GET /api/v1/admin/users
+	mux.HandleFunc("GET /api/v1/admin/users", withBootstrapAdmin(func(w http.ResponseWriter, r *http.Request) {
+		out, err := store.AdminListUsers(r.Context(), accountIDFromCtx(r))
+		if err != nil {
+			slog.Error("admin user list failed")
+			writeErr(w, http.StatusInternalServerError, "admin_users_failed", "admin users unavailable")
+			return
+		}
+		noStore(w)
+		writeJSON(w, http.StatusOK, out)
+	}))
+
+	// POST /api/v1/admin/users
+	mux.HandleFunc("POST /api/v1/admin/users", withBootstrapAdmin(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Name  string            `json:"name"`
+			Roles []model.AdminRole `json:"roles"`
+		}
+		if err := decodeJSON(w, r, &body); err != nil {
+			writeDecodeError(w, err)
+			return
+		}
+		body.Name = strings.TrimSpace(body.Name)
+		if body.Name == "" || utf8.RuneCountInString(body.Name) > maxAdminUserNameRunes || body.Name == adminKeyActor {
+			writeErr(w, http.StatusBadRequest, "admin_user_invalid", "name is invalid")
+			return
+		}
+		if len(body.Roles) == 0 {
+			writeErr(w, http.StatusBadRequest, "admin_user_invalid", "at least one role is required")
+			return
+		}
+		for _, role := range body.Roles {
+			if !role.Valid() {
+				writeErr(w, http.StatusBadRequest, "admin_user_invalid", "role is invalid")
+				return
+			}
+		}
+
+		key, err := newAdminKey()
+		if err != nil {
+			slog.Error("admin key generation failed")
+			writeErr(w, http.StatusInternalServerError, "admin_user_failed", "admin user could not be created")
+			return
+		}
+		user, err := audited(store, r, func(ctx context.Context) (model.AdminUserRecord, model.AdminAuditEntry, error) {
+			user, err := store.AdminCreateUser(ctx, accountIDFromCtx(r), model.AdminUserCreate{
+				Name:    body.Name,
+				Roles:   body.Roles,
+				KeyHash: hashAdminKey(key),
+			})
+			return user, model.AdminAuditEntry{Action: model.AdminAuditActionUserCreate, Summary: map[string]any{
+				"userId": user.ID,
+				"name":   user.Name,
+				"roles":  user.Roles,
+			}}, err
+		})
+		if err != nil {
+			if errors.Is(err, model.ErrAdminUserConflict) {
+				writeErr(w, http.StatusConflict, "admin_user_conflict", "admin user already exists")
+				return
+			}
+			slog.Error("admin user creation failed")
+			writeErr(w, http.StatusInternalServerError, "admin_user_failed", "admin user could not be created")
+			return
+		}
+
+		noStore(w)
+		writeJSON(w, http.StatusCreated, model.AdminUserCreateResponse{User: user, Key: key})
+	}))
+
+	// DELETE /api/v1/admin/users/{id}
+	mux.HandleFunc("DELETE /api/v1/admin/users/{id}", withBootstrapAdmin(func(w http.ResponseWriter, r *http.Request) {
+		user, err := audited(store, r, func(ctx context.Context) (model.AdminUserRecord, model.AdminAuditEntry, error) {
+			user, err := store.AdminDisableUser(ctx, accountIDFromCtx(r), strings.TrimSpace(r.PathValue("id")))
+			return user, model.AdminAuditEntry{Action: model.AdminAuditActionUserDisable, Summary: map[string]any{
+				"userId": user.ID,
+				"name":   user.Name,
+			}}, err
+		})
+		if err != nil {
+			if errors.Is(err, model.ErrAdminUserNotFound) {
+				writeErr(w, http.StatusNotFound, "admin_user_not_found", "admin user was not found")
+				return
+			}
+			slog.Error("admin user disable failed")
+			writeErr(w, http.StatusInternalServerError, "admin_user_failed", "admin user could not be disabled")
+			return
+		}
+		noStore(w)
+		writeJSON(w, http.StatusOK, user)
+	}))
+
+	registerUploadRoutes(mux, store, withAdmin)
+	registerMediaRoutes(mux, store, withAdmin)
+	registerWebhookRoutes(mux, store, withBootstrapAdmin)
+	registerRetentionRoutes(mux, store, withAdmin, withBootstrapAdmin)
+	registerHyphenationRoutes(mux, store, withAdmin)
+	registerRenderJobRoutes(mux, store, withBootstrapAdmin)
+	registerDebugRoutes(mux, store, cfg.LogLevel, withBootstrapAdmin)
+	registerBackupRoutes(mux, store, cfg.Blobs, withBootstrapAdmin)
+	registerRestoreRoutes(mux, store, withBootstrapAdmin)
+	registerMaintenanceRoutes(mux, store, cfg.Maintenance, withAdmin, withBootstrapAdmin)
+	registerNarrationRoutes(mux, store, cfg.Narration, withAdmin)
+	registerAlignmentRoutes(mux, store, cfg.Alignment, withAdmin)
+	registerSearchReindexRoutes(mux, store, cfg.Search, withAdmin)
+	registerGenerateRoutes(mux, store, cfg.Generator, cfg.Moderator, cfg.SensitivityWords, withAdmin)
+	registerTranslateRoutes(mux, store, cfg.Generator, cfg.Moderator, cfg.SensitivityWords, withAdmin)
+	registerSimplifyRoutes(mux, store, cfg.Generator, cfg.Moderator, cfg.SensitivityWords, withAdmin)
+	registerQuizRoutes(mux, store, cfg.Generator, withAdmin)
+	registerDiscussionRoutes(mux, store, withAdmin)
+	registerGutenbergRoutes(mux, cfg.Gutenberg, withAdmin)
+	registerImportFetchRoutes(mux, store, cfg.Fetcher, withAdmin)
+	registerPhonicsRoutes(mux, store, cfg.Phonics, withAdmin)
+
 	// Security headers remain local to application responses. The root server
 	// owns the single shared request-observability boundary.
 	h := withSecurityHeaders(mux)
@@ -257,6 +1012,182 @@ func New(cfg Config, store Store) http.Handler {
 
 /* ------------------------------ helpers ------------------------------ */
 
+// audit runs mutate in one transaction with the audit entry it returns, so a
+// mutation commits only together with its audit row and a failed audit write
+// fails the request. Store calls inside mutate must use the ctx it is given.
+func audit(store Store, r *http.Request, mutate func(ctx context.Context) (model.AdminAuditEntry, error)) error {
+	return store.AdminAudited(r.Context(), accountIDFromCtx(r), principalFromCtx(r).Name, mutate)
+}
+
+// audited is audit for mutations that return a result.
+func audited[T any](store Store, r *http.Request, mutate func(ctx context.Context) (T, model.AdminAuditEntry, error)) (T, error) {
+	var out T
+	err := audit(store, r, func(ctx context.Context) (model.AdminAuditEntry, error) {
+		result, entry, err := mutate(ctx)
+		out = result
+		return entry, err
+	})
+	return out, err
+}
+
+// normalizeTagNames validates a request's tag list and drops case-insensitive
+// duplicates, keeping the first spelling.
+func normalizeTagNames(raw []string) ([]string, bool) {
+	if len(raw) == 0 || len(raw) > maxTagsPerRequest {
+		return nil, false
+	}
+	seen := make(map[string]bool, len(raw))
+	names := make([]string, 0, len(raw))
+	for _, value := range raw {
+		name, ok := model.NormalizeAdminTagName(value)
+		if !ok {
+			return nil, false
+		}
+		key := strings.ToLower(name)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		names = append(names, name)
+	}
+	return names, true
+}
+
+func writeContributorErr(w http.ResponseWriter, err error, logMsg string) {
+	switch {
+	case errors.Is(err, model.ErrAdminStoryNotFound):
+		writeErr(w, http.StatusNotFound, "story_not_found", "story was not found")
+	case errors.Is(err, model.ErrAdminContributorNotFound):
+		writeErr(w, http.StatusNotFound, "contributor_not_found", "contributor credit was not found")
+	default:
+		slog.Error(logMsg)
+		writeErr(w, http.StatusInternalServerError, "contributor_failed", "story contributors unavailable")
+	}
+}
+
+func writeTagErr(w http.ResponseWriter, err error, logMsg string) {
+	switch {
+	case errors.Is(err, model.ErrAdminTagNotFound):
+		writeErr(w, http.StatusNotFound, "tag_not_found", "tag was not found")
+	case errors.Is(err, model.ErrAdminTagConflict):
+		writeErr(w, http.StatusConflict, "tag_conflict", "a tag with that name already exists")
+	default:
+		slog.Error(logMsg)
+		writeErr(w, http.StatusInternalServerError, "tag_failed", "tag could not be updated")
+	}
+}
+
+func parseAuditFilter(r *http.Request) (model.AdminAuditFilter, bool) {
+	query := r.URL.Query()
+	filter := model.AdminAuditFilter{
+		Action: model.AdminAuditAction(strings.TrimSpace(query.Get("action"))),
+		Slug:   strings.TrimSpace(query.Get("slug")),
+		Actor:  strings.TrimSpace(query.Get("actor")),
+	}
+	for _, bound := range []struct {
+		name string
+		dst  **time.Time
+	}{
+		{name: "since", dst: &filter.Since},
+		{name: "until", dst: &filter.Until},
+	} {
+		raw := strings.TrimSpace(query.Get(bound.name))
+		if raw == "" {
+			continue
+		}
+		value, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return model.AdminAuditFilter{}, false
+		}
+		*bound.dst = &value
+	}
+	if filter.Since != nil && filter.Until != nil && !filter.Since.Before(*filter.Until) {
+		return model.AdminAuditFilter{}, false
+	}
+	page, ok := model.ParsePageRequest(query)
+	if !ok {
+		return model.AdminAuditFilter{}, false
+	}
+	filter.PageRequest = page
+	return filter, true
+}
+
+// idempotent runs next once per Idempotency-Key, so a draft or publish retried
+// over a flaky connection does not save a second version or undo a later
+// publish.
+func idempotent(store Store, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		idempotency.Serve(store, w, r, accountIDFromCtx(r), maxJSONBodyBytes, next)
+	}
+}
+
+// serveDraftUpsert ingests one draft and reports whether it was saved. Both
+// the JSON route and finalized uploads use it.
+func serveDraftUpsert(store Store, w http.ResponseWriter, r *http.Request, body model.AdminDraftUpsertRequest) bool {
+	out, err := audited(store, r, func(ctx context.Context) (model.AdminDraftUpsertResponse, model.AdminAuditEntry, error) {
+		out, err := store.AdminDraftUpsert(ctx, accountIDFromCtx(r), body)
+		return out, model.AdminAuditEntry{Action: model.AdminAuditActionDraftUpsert, Slug: out.Slug, Summary: map[string]any{
+			"versionId": out.VersionID,
+			"version":   out.Version,
+			"outcome":   out.Outcome,
+		}}, err
+	})
+	if err != nil {
+		var validationErr *model.AdminValidationError
+		if errors.As(err, &validationErr) {
+			writeIssues(w, http.StatusBadRequest, "draft_invalid", "Story content is invalid", validationErr.Issues)
+			return false
+		}
+		if errors.Is(err, model.ErrAdminVersionRepairRequired) {
+			writeErr(w, http.StatusConflict, "draft_repair_required", "stored story version requires repair")
+			return false
+		}
+		slog.Error("admin story draft failed")
+		writeErr(w, http.StatusInternalServerError, "draft_failed", "story draft could not be saved")
+		return false
+	}
+
+	noStore(w)
+	writeJSON(w, http.StatusOK, out)
+	return true
+}
+
+// serveStoryImport imports one bundle and reports whether it was created.
+func serveStoryImport(store Store, w http.ResponseWriter, r *http.Request, body model.StoryBundle) bool {
+	// Importing a published version publishes it, so the importer must
+	// also hold the publisher role.
+	for _, version := range body.Versions {
+		if version.IsPublished && !principalFromCtx(r).HasAnyRole(adminPublishRoles...) {
+			writeErr(w, http.StatusForbidden, "forbidden", "publisher role required to import a published version")
+			return false
+		}
+	}
+
+	out, err := audited(store, r, func(ctx context.Context) (model.AdminStoryStatusResponse, model.AdminAuditEntry, error) {
+		out, err := store.AdminImportStory(ctx, accountIDFromCtx(r), body)
+		return out, model.AdminAuditEntry{Action: model.AdminAuditActionImport, Slug: out.Slug, Summary: map[string]any{
+			"versionCount": out.VersionCount,
+		}}, err
+	})
+	if err != nil {
+		var validationErr *model.AdminValidationError
+		switch {
+		case errors.As(err, &validationErr):
+			writeIssues(w, http.StatusBadRequest, "import_invalid", "Story bundle is invalid", validationErr.Issues)
+		case errors.Is(err, model.ErrAdminStoryConflict):
+			writeErr(w, http.StatusConflict, "story_exists", "a story with this slug already exists")
+		default:
+			slog.Error("admin story import failed")
+			writeErr(w, http.StatusInternalServerError, "import_failed", "story could not be imported")
+		}
+		return false
+	}
+
+	noStore(w)
+	writeJSON(w, http.StatusCreated, out)
+	return true
+}
+
 func adminKeyOK(got, want string) bool {
 	if got == "" || want == "" {
 		return false
@@ -267,21 +1198,74 @@ func adminKeyOK(got, want string) bool {
 	return subtle.ConstantTimeCompare([]byte(got), []byte(want)) == 1
 }
 
+// hashAdminKey is the only form in which admin user keys are stored or looked
+// up. Keys are high-entropy random values, so an unsalted digest suffices.
+func hashAdminKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func newAdminKey() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return adminKeyPrefix + base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
 func noStore(w http.ResponseWriter) {
 	w.Header().Set("Cache-Control", "no-store")
 }
 
+// errUnsupportedEncoding is a request body in a Content-Encoding other than
+// gzip.
+var errUnsupportedEncoding = errors.New("unsupported content encoding")
+
 func decodeJSON(w http.ResponseWriter, r *http.Request, dst any) error {
 	r.Body = http.MaxBytesReader(w, r.Body, maxJSONBodyBytes)
 	defer r.Body.Close()
 
-	raw, err := io.ReadAll(r.Body)
+	body, err := requestBodyReader(w, r)
 	if err != nil {
 		return err
 	}
+	raw, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	return decodeJSONBytes(raw, dst)
+}
+
+// requestBodyReader inflates a body sent with Content-Encoding: gzip. The
+// inflated stream has its own limit so a small body cannot expand without
+// bound.
+func requestBodyReader(w http.ResponseWriter, r *http.Request) (io.Reader, error) {
+	switch encoding := strings.ToLower(strings.TrimSpace(r.Header.Get("Content-Encoding"))); encoding {
+	case "", "identity":
+		return r.Body, nil
+	case "gzip", "x-gzip":
+		zr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				return nil, err
+			}
+			return nil, fmt.Errorf("request body is not gzip: %w", err)
+		}
+		return http.MaxBytesReader(w, zr, maxInflatedJSONBodyBytes), nil
+	default:
+		return nil, errUnsupportedEncoding
+	}
+}
+
+// decodeJSONBytes applies the admin JSON body rules to an already-read body.
+func decodeJSONBytes(raw []byte, dst any) error {
 	if !utf8.Valid(raw) {
 		return errors.New("request body is not valid UTF-8")
 	}
+	if err := httpmiddleware.CheckJSON(raw, dst); err != nil {
+		return err
+	}
 	dec := json.NewDecoder(bytes.NewReader(raw))
 	dec.DisallowUnknownFields()
 
@@ -300,30 +1284,70 @@ func writeDecodeError(w http.ResponseWriter, err error) {
 		writeErr(w, http.StatusRequestEntityTooLarge, "body_too_large", "request body too large")
 		return
 	}
-	writeErr(w, http.StatusBadRequest, "bad_json", "request body must be valid JSON")
+	if errors.Is(err, errUnsupportedEncoding) {
+		w.Header().Set("Accept-Encoding", "gzip")
+		writeErr(w, http.StatusUnsupportedMediaType, "encoding_unsupported", "request body must be sent uncompressed or with gzip")
+		return
+	}
+	fields := httpmiddleware.DecodeFieldErrors(err)
+	if len(fields) == 0 {
+		writeErr(w, http.StatusBadRequest, "bad_json", "request body must be valid JSON")
+		return
+	}
+	writeFields(w, http.StatusBadRequest, "bad_json", "request body must be valid JSON", fields)
+}
+
+// writeFields is writeErr naming the inputs at fault.
+func writeFields(w http.ResponseWriter, status int, code string, msg string, fields []model.FieldError) {
+	noStore(w)
+	body := httpmiddleware.ErrorBody(w, code, msg)
+	body["fields"] = fields
+	writeJSON(w, status, map[string]any{
+		"error": body,
+	})
 }
 
 func writeErr(w http.ResponseWriter, status int, code string, msg string) {
 	noStore(w)
 	writeJSON(w, status, map[string]any{
-		"error": map[string]any{
-			"code":    code,
-			"message": msg,
-		},
+		"error": httpmiddleware.ErrorBody(w, code, msg),
 	})
 }
 
+// writeIssues reports validation issues twice: as issues, which keep their
+// Markdown line for the editor, and as the fields every endpoint shares.
 func writeIssues(w http.ResponseWriter, status int, code string, msg string, issues []model.AdminValidationIssue) {
 	noStore(w)
+	body := httpmiddleware.ErrorBody(w, code, msg)
+	body["issues"] = issues
+	body["fields"] = model.FieldErrors(issues)
 	writeJSON(w, status, map[string]any{
-		"error": map[string]any{
-			"code":    code,
-			"message": msg,
-			"issues":  issues,
-		},
+		"error": body,
 	})
 }
 
+// parseFields reads a list's ?fields= selection of item's fields, answering
+// 400 itself when it names fields item does not have.
+func parseFields(w http.ResponseWriter, r *http.Request, item any) (model.FieldSelection, bool) {
+	fields, problems := model.ParseFieldSelection(r.URL.Query(), item)
+	if problems != nil {
+		writeFields(w, http.StatusBadRequest, "fields_invalid", "fields names unknown item fields", problems)
+		return model.FieldSelection{}, false
+	}
+	return fields, true
+}
+
+// writeSelected writes a list response with its items cut down to fields.
+func writeSelected(w http.ResponseWriter, fields model.FieldSelection, v any) {
+	out, err := fields.Project(v)
+	if err != nil {
+		slog.Error("admin response projection failed")
+		writeErr(w, http.StatusInternalServerError, "encode_failed", "response encoding failed")
+		return
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
 func writeJSON(w http.ResponseWriter, status int, v any) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
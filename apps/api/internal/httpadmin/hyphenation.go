@@ -0,0 +1,53 @@
+package httpadmin
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"pandapages/api/internal/model"
+)
+
+// registerHyphenationRoutes mounts the account setting that decides whether
+// readers receive soft hyphens. It changes presentation only, so editors may
+// set it.
+func registerHyphenationRoutes(mux *http.ServeMux, store Store, guard func([]model.AdminRole, http.HandlerFunc) http.HandlerFunc) {
+	// GET /api/v1/admin/settings/hyphenation
+	mux.HandleFunc("GET /api/v1/admin/settings/hyphenation", guard(adminEditRoles, func(w http.ResponseWriter, r *http.Request) {
+		out, err := store.AdminGetHyphenation(r.Context(), accountIDFromCtx(r))
+		if err != nil {
+			slog.Error("admin hyphenation read failed")
+			writeErr(w, http.StatusInternalServerError, "hyphenation_failed", "hyphenation setting unavailable")
+			return
+		}
+		noStore(w)
+		writeJSON(w, http.StatusOK, out)
+	}))
+
+	// PUT /api/v1/admin/settings/hyphenation
+	mux.HandleFunc("PUT /api/v1/admin/settings/hyphenation", guard(adminEditRoles, func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Enabled *bool `json:"enabled" required:"true"`
+		}
+		if err := decodeJSON(w, r, &body); err != nil {
+			writeDecodeError(w, err)
+			return
+		}
+		if body.Enabled == nil {
+			writeErr(w, http.StatusBadRequest, "hyphenation_invalid", "enabled is required")
+			return
+		}
+
+		out, err := audited(store, r, func(ctx context.Context) (model.AdminHyphenation, model.AdminAuditEntry, error) {
+			out, err := store.AdminSetHyphenation(ctx, accountIDFromCtx(r), *body.Enabled)
+			return out, model.AdminAuditEntry{Action: model.AdminAuditActionHyphenation, Summary: map[string]any{"enabled": out.Enabled}}, err
+		})
+		if err != nil {
+			slog.Error("admin hyphenation update failed")
+			writeErr(w, http.StatusInternalServerError, "hyphenation_failed", "hyphenation setting could not be updated")
+			return
+		}
+		noStore(w)
+		writeJSON(w, http.StatusOK, out)
+	}))
+}
@@ -0,0 +1,86 @@
+package httpadmin
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"pandapages/api/internal/model"
+	"pandapages/api/internal/storyingest"
+	"pandapages/api/internal/urlimport"
+)
+
+// registerImportFetchRoutes mounts importing a story from a web address. The
+// converted document is saved through the same path as a submitted draft, so
+// it is validated and audited the same way, and the address is kept as the
+// story's source.
+func registerImportFetchRoutes(mux *http.ServeMux, store Store, fetcher *urlimport.Fetcher, guard func([]model.AdminRole, http.HandlerFunc) http.HandlerFunc) {
+	// POST /api/v1/admin/import/fetch
+	mux.HandleFunc("POST /api/v1/admin/import/fetch", guard(adminImportRoles, idempotent(store, func(w http.ResponseWriter, r *http.Request) {
+		var body model.AdminFetchImportRequest
+		if err := decodeJSON(w, r, &body); err != nil {
+			writeDecodeError(w, err)
+			return
+		}
+		body.URL = strings.TrimSpace(body.URL)
+		body.Slug = strings.TrimSpace(body.Slug)
+		var fields []model.FieldError
+		if !urlimport.ValidURL(body.URL) {
+			fields = append(fields, model.FieldError{Path: "url", Code: "invalid", Message: "url must be an http or https address without credentials"})
+		}
+		if storyingest.ValidateSlug(body.Slug) != nil {
+			fields = append(fields, model.FieldError{Path: "slug", Code: "invalid", Message: "slug must be lowercase letters, digits and hyphens"})
+		}
+		if len(fields) > 0 {
+			writeFields(w, http.StatusBadRequest, "fetch_invalid", "import request is invalid", fields)
+			return
+		}
+		if fetcher == nil {
+			writeErr(w, http.StatusServiceUnavailable, "fetch_unavailable", "importing from an address is turned off")
+			return
+		}
+
+		doc, err := fetcher.Fetch(r.Context(), body.URL)
+		if err != nil {
+			switch {
+			case errors.Is(err, urlimport.ErrRefused):
+				writeFields(w, http.StatusBadRequest, "fetch_invalid", "import request is invalid", []model.FieldError{
+					{Path: "url", Code: "not_public", Message: "url must be on the public internet"},
+				})
+			case errors.Is(err, urlimport.ErrTooLarge):
+				writeErr(w, http.StatusRequestEntityTooLarge, "fetch_too_large", "document is larger than 20 MB")
+			case errors.Is(err, urlimport.ErrUnsupported):
+				writeErr(w, http.StatusUnsupportedMediaType, "fetch_unsupported", "document must be HTML, Markdown, plain text or .docx")
+			case errors.Is(err, urlimport.ErrUnreadable):
+				writeErr(w, http.StatusUnprocessableEntity, "fetch_unreadable", "document could not be converted")
+			default:
+				// Fetch errors never carry the address, but keep the log to a
+				// fixed category like the other outbound calls.
+				slog.Warn("admin import fetch failed")
+				writeErr(w, http.StatusBadGateway, "fetch_failed", "document could not be downloaded")
+			}
+			return
+		}
+
+		title := strings.TrimSpace(body.Title)
+		if title == "" {
+			title = doc.Title
+		}
+		if title == "" {
+			writeFields(w, http.StatusUnprocessableEntity, "fetch_untitled", "document has no title", []model.FieldError{
+				{Path: "title", Code: "required", Message: "the document has no title of its own; send one"},
+			})
+			return
+		}
+		sourceURL := body.URL
+		serveDraftUpsert(store, w, r, model.AdminDraftUpsertRequest{
+			Slug:      body.Slug,
+			Title:     title,
+			Author:    body.Author,
+			Language:  body.Language,
+			Markdown:  doc.Markdown,
+			SourceURL: &sourceURL,
+		})
+	})))
+}
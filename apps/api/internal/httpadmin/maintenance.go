@@ -0,0 +1,66 @@
+package httpadmin
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"unicode/utf8"
+
+	"pandapages/api/internal/maintenance"
+	"pandapages/api/internal/model"
+)
+
+// registerMaintenanceRoutes mounts the switch that takes the reader API
+// offline. Any admin may see it; turning readers away is a deployment
+// decision, so only the bootstrap key may flip it.
+func registerMaintenanceRoutes(
+	mux *http.ServeMux,
+	store Store,
+	state *maintenance.Switch,
+	guard func([]model.AdminRole, http.HandlerFunc) http.HandlerFunc,
+	bootstrapGuard func(http.HandlerFunc) http.HandlerFunc,
+) {
+	if state == nil {
+		return
+	}
+
+	// GET /api/v1/admin/maintenance
+	mux.HandleFunc("GET /api/v1/admin/maintenance", guard(adminReadRoles, func(w http.ResponseWriter, r *http.Request) {
+		noStore(w)
+		writeJSON(w, http.StatusOK, state.State())
+	}))
+
+	// PUT /api/v1/admin/maintenance
+	mux.HandleFunc("PUT /api/v1/admin/maintenance", bootstrapGuard(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Enabled *bool  `json:"enabled" required:"true"`
+			Message string `json:"message"`
+		}
+		if err := decodeJSON(w, r, &body); err != nil {
+			writeDecodeError(w, err)
+			return
+		}
+		if body.Enabled == nil {
+			writeErr(w, http.StatusBadRequest, "maintenance_invalid", "enabled is required")
+			return
+		}
+		if utf8.RuneCountInString(body.Message) > model.MaxMaintenanceMessageRunes {
+			writeErr(w, http.StatusBadRequest, "maintenance_invalid", "message must be at most 280 characters")
+			return
+		}
+
+		// The switch lives in memory, so it flips only once its audit row
+		// has committed.
+		err := audit(store, r, func(context.Context) (model.AdminAuditEntry, error) {
+			return model.AdminAuditEntry{Action: model.AdminAuditActionMaintenance, Summary: map[string]any{"enabled": *body.Enabled}}, nil
+		})
+		if err != nil {
+			slog.Error("admin maintenance update failed")
+			writeErr(w, http.StatusInternalServerError, "maintenance_failed", "maintenance mode could not be changed")
+			return
+		}
+		out := state.Set(*body.Enabled, body.Message)
+		noStore(w)
+		writeJSON(w, http.StatusOK, out)
+	}))
+}
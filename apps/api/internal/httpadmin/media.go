@@ -0,0 +1,57 @@
+package httpadmin
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"pandapages/api/internal/model"
+)
+
+const maxMediaBytes = 10 << 20 // 10MB
+
+// registerMediaRoutes mounts image upload. The request body is the raw image;
+// its type is sniffed from the bytes rather than trusted from the header.
+func registerMediaRoutes(mux *http.ServeMux, store Store, guard func([]model.AdminRole, http.HandlerFunc) http.HandlerFunc) {
+	// POST /api/v1/admin/media
+	mux.HandleFunc("POST /api/v1/admin/media", guard(adminImportRoles, func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, maxMediaBytes)
+		defer r.Body.Close()
+		data, err := io.ReadAll(r.Body)
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeErr(w, http.StatusRequestEntityTooLarge, "body_too_large", "image exceeds the maximum size")
+			return
+		}
+		if err != nil {
+			writeErr(w, http.StatusBadRequest, "bad_request", "image could not be read")
+			return
+		}
+		if len(data) == 0 {
+			writeErr(w, http.StatusBadRequest, "bad_request", "image must not be empty")
+			return
+		}
+		contentType := http.DetectContentType(data)
+		if !model.ValidMediaContentType(contentType) {
+			writeErr(w, http.StatusUnsupportedMediaType, "media_unsupported", "image must be PNG, JPEG, GIF, or WebP")
+			return
+		}
+
+		media, err := audited(store, r, func(ctx context.Context) (model.Media, model.AdminAuditEntry, error) {
+			media, err := store.AdminCreateMedia(ctx, accountIDFromCtx(r), contentType, data)
+			return media, model.AdminAuditEntry{Action: model.AdminAuditActionMediaUpload, Summary: map[string]any{
+				"mediaId":  media.ID,
+				"byteSize": media.ByteSize,
+			}}, err
+		})
+		if err != nil {
+			slog.Error("admin media upload failed")
+			writeErr(w, http.StatusInternalServerError, "media_failed", "image could not be stored")
+			return
+		}
+		noStore(w)
+		writeJSON(w, http.StatusCreated, media)
+	}))
+}
@@ -0,0 +1,28 @@
+package httpadmin
+
+import (
+	"context"
+	"time"
+
+	"pandapages/api/internal/model"
+	"pandapages/api/internal/moderation"
+)
+
+// moderateDraft reviews a story the language model wrote before it is saved
+// as a draft. sensitivities are those of the child it is written for; the
+// deployment word list applies to every account.
+func moderateDraft(ctx context.Context, moderator moderation.Provider, words, sensitivities []string, title, markdown string) model.StoryModeration {
+	return moderation.Review(ctx, moderator, title, markdown, moderation.Terms(sensitivities, words), time.Now())
+}
+
+// activeSensitivities returns the sensitivities of the account's active
+// child, none when no child is active. Translations and simplifications are
+// not written for a named child, so they are checked against the one whose
+// Library they will reach.
+func activeSensitivities(ctx context.Context, store Store, accountID string) ([]string, error) {
+	child, _, err := store.AdminGenerationProfiles(ctx, accountID, "", "")
+	if err != nil {
+		return nil, err
+	}
+	return child.Sensitivities, nil
+}
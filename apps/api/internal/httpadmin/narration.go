@@ -0,0 +1,84 @@
+package httpadmin
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"pandapages/api/internal/model"
+)
+
+const maxVoiceRunes = 100
+
+// registerNarrationRoutes mounts read-aloud narration. Queuing a job spends
+// the TTS provider's budget, so it needs the publisher role; the worker in
+// cmd/api does the narrating. Without a provider nothing would ever run a
+// job, so the request is refused rather than queued.
+func registerNarrationRoutes(mux *http.ServeMux, store Store, enabled bool, guard func([]model.AdminRole, http.HandlerFunc) http.HandlerFunc) {
+	// POST /api/v1/admin/stories/{slug}/versions/{versionId}/narration
+	mux.HandleFunc("POST /api/v1/admin/stories/{slug}/versions/{versionId}/narration", guard(adminPublishRoles, func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Voice string `json:"voice"`
+		}
+		if err := decodeJSON(w, r, &body); err != nil {
+			writeDecodeError(w, err)
+			return
+		}
+		voice := strings.TrimSpace(body.Voice)
+		if utf8.RuneCountInString(voice) > maxVoiceRunes {
+			writeFields(w, http.StatusBadRequest, "narration_invalid", "narration request is invalid", []model.FieldError{
+				{Path: "voice", Code: "too_long", Message: "voice must be at most 100 characters"},
+			})
+			return
+		}
+		if !enabled {
+			writeErr(w, http.StatusServiceUnavailable, "narration_unavailable", "no text-to-speech provider is configured")
+			return
+		}
+
+		slug := strings.TrimSpace(r.PathValue("slug"))
+		versionID := strings.TrimSpace(r.PathValue("versionId"))
+		out, err := audited(store, r, func(ctx context.Context) (model.NarrationJob, model.AdminAuditEntry, error) {
+			out, err := store.AdminStartNarrationJob(ctx, accountIDFromCtx(r), slug, versionID, voice)
+			return out, model.AdminAuditEntry{Action: model.AdminAuditActionNarrate, Slug: out.Slug, Summary: map[string]any{
+				"jobId":     out.ID,
+				"versionId": out.VersionID,
+				"voice":     out.Voice,
+				"segments":  out.TotalSegments,
+			}}, err
+		})
+		if err != nil {
+			switch {
+			case errors.Is(err, model.ErrAdminStoryNotFound):
+				writeErr(w, http.StatusNotFound, "version_not_found", "story version was not found")
+			case errors.Is(err, model.ErrNarrationJobActive):
+				writeErr(w, http.StatusConflict, "narration_job_active", "a narration job is already queued or running for this version")
+			default:
+				slog.Error("admin narration job start failed")
+				writeErr(w, http.StatusInternalServerError, "narration_failed", "narration job could not be started")
+			}
+			return
+		}
+		noStore(w)
+		writeJSON(w, http.StatusAccepted, out)
+	}))
+
+	// GET /api/v1/admin/narration-jobs/{id}
+	mux.HandleFunc("GET /api/v1/admin/narration-jobs/{id}", guard(adminReadRoles, func(w http.ResponseWriter, r *http.Request) {
+		out, err := store.AdminGetNarrationJob(r.Context(), accountIDFromCtx(r), strings.TrimSpace(r.PathValue("id")))
+		if err != nil {
+			if errors.Is(err, model.ErrNarrationJobNotFound) {
+				writeErr(w, http.StatusNotFound, "narration_job_not_found", "narration job was not found")
+				return
+			}
+			slog.Error("admin narration job read failed")
+			writeErr(w, http.StatusInternalServerError, "narration_failed", "narration job unavailable")
+			return
+		}
+		noStore(w)
+		writeJSON(w, http.StatusOK, out)
+	}))
+}
@@ -0,0 +1,40 @@
+package httpadmin
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"pandapages/api/internal/model"
+	"pandapages/api/internal/phonics"
+)
+
+// registerPhonicsRoutes mounts a story version's phonics analysis. Versions
+// are scored in the background shortly after they are stored, so a new one
+// answers 404 until then.
+func registerPhonicsRoutes(mux *http.ServeMux, store Store, progression *phonics.Progression, guard func([]model.AdminRole, http.HandlerFunc) http.HandlerFunc) {
+	// GET /api/v1/admin/stories/{slug}/phonics?versionId=
+	mux.HandleFunc("GET /api/v1/admin/stories/{slug}/phonics", guard(adminReadRoles, func(w http.ResponseWriter, r *http.Request) {
+		if progression == nil {
+			writeErr(w, http.StatusServiceUnavailable, "phonics_unavailable", "no phonics progression is configured")
+			return
+		}
+		slug := strings.TrimSpace(r.PathValue("slug"))
+		out, err := store.AdminPhonics(r.Context(), accountIDFromCtx(r), slug, r.URL.Query().Get("versionId"), progression.Key())
+		switch {
+		case errors.Is(err, model.ErrAdminStoryNotFound):
+			writeErr(w, http.StatusNotFound, "version_not_found", "story version was not found")
+			return
+		case errors.Is(err, model.ErrPhonicsNotFound):
+			writeErr(w, http.StatusNotFound, "phonics_not_found", "story version has not been scored against the phonics progression")
+			return
+		case err != nil:
+			slog.Error("admin phonics read failed")
+			writeErr(w, http.StatusInternalServerError, "phonics_failed", "phonics analysis could not be read")
+			return
+		}
+		noStore(w)
+		writeJSON(w, http.StatusOK, out)
+	}))
+}
@@ -0,0 +1,271 @@
+package httpadmin
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"pandapages/api/internal/llm"
+	"pandapages/api/internal/model"
+	"pandapages/api/internal/providerlog"
+)
+
+// registerQuizRoutes mounts the comprehension quizzes kept per chapter of a
+// story version. The language model writes a chapter's questions as a draft,
+// which needs the importer role like other generation; editors rewrite and
+// approve them, and only approved quizzes reach readers.
+func registerQuizRoutes(mux *http.ServeMux, store Store, generator llm.Provider, guard func([]model.AdminRole, http.HandlerFunc) http.HandlerFunc) {
+	// GET /api/v1/admin/stories/{slug}/quiz?versionId=
+	mux.HandleFunc("GET /api/v1/admin/stories/{slug}/quiz", guard(adminReadRoles, func(w http.ResponseWriter, r *http.Request) {
+		slug := strings.TrimSpace(r.PathValue("slug"))
+		accountID := accountIDFromCtx(r)
+		versionID, ok := quizVersionID(store, w, r, slug, r.URL.Query().Get("versionId"))
+		if !ok {
+			return
+		}
+		out, err := store.AdminGetQuiz(r.Context(), accountID, slug, versionID)
+		if err != nil {
+			writeQuizErr(w, err, "admin quiz read failed")
+			return
+		}
+		noStore(w)
+		writeJSON(w, http.StatusOK, out)
+	}))
+
+	// POST /api/v1/admin/stories/{slug}/quiz
+	mux.HandleFunc("POST /api/v1/admin/stories/{slug}/quiz", guard(adminImportRoles, idempotent(store, func(w http.ResponseWriter, r *http.Request) {
+		var body model.AdminQuizGenerateRequest
+		if err := decodeJSON(w, r, &body); err != nil {
+			writeDecodeError(w, err)
+			return
+		}
+		slug := strings.TrimSpace(r.PathValue("slug"))
+		if body.Chapter < 1 {
+			writeFields(w, http.StatusBadRequest, "quiz_invalid", "quiz request is invalid", []model.FieldError{
+				{Path: "chapter", Code: "invalid", Message: "chapter must be 1 or more"},
+			})
+			return
+		}
+		if generator == nil {
+			writeErr(w, http.StatusServiceUnavailable, "quiz_unavailable", "no language model provider is configured")
+			return
+		}
+
+		accountID := accountIDFromCtx(r)
+		story, err := store.AdminGetStory(r.Context(), accountID, slug)
+		if err != nil {
+			if errors.Is(err, model.ErrAdminStoryNotFound) {
+				writeErr(w, http.StatusNotFound, "story_not_found", "story was not found")
+				return
+			}
+			slog.Error("admin quiz story failed")
+			writeErr(w, http.StatusInternalServerError, "quiz_failed", "quiz could not be written")
+			return
+		}
+		if body.VersionID = strings.TrimSpace(body.VersionID); body.VersionID == "" {
+			if body.VersionID = defaultQuizVersion(story); body.VersionID == "" {
+				writeErr(w, http.StatusNotFound, "version_not_found", "story has no version to write a quiz for")
+				return
+			}
+		}
+		source, err := store.AdminQuizChapter(r.Context(), accountID, slug, body.VersionID, body.Chapter)
+		if err != nil {
+			writeQuizErr(w, err, "admin quiz chapter failed")
+			return
+		}
+		if strings.TrimSpace(source.Markdown) == "" {
+			writeErr(w, http.StatusUnprocessableEntity, "quiz_chapter_empty", "chapter has no text to ask about")
+			return
+		}
+
+		chapterTitle := ""
+		if source.Title != nil {
+			chapterTitle = *source.Title
+		}
+		completion, err := generator.Complete(r.Context(), llm.QuizPrompt(story.Title, chapterTitle, source.Markdown, story.Language))
+		if err != nil {
+			providerlog.Failure("quiz generation failed", generator.Name())
+			writeErr(w, http.StatusBadGateway, "quiz_failed", "the language model did not write a quiz")
+			return
+		}
+		questions, err := llm.ParseQuiz(completion.Text)
+		if err != nil {
+			writeErr(w, http.StatusBadGateway, "quiz_invalid", "the language model's reply was not a list of questions")
+			return
+		}
+		quiz, err := audited(store, r, func(ctx context.Context) (model.AdminChapterQuiz, model.AdminAuditEntry, error) {
+			quiz, err := store.AdminSaveQuiz(ctx, accountID, slug, body.VersionID, body.Chapter, model.QuizSave{
+				Questions:     questions,
+				Status:        model.QuizDraft,
+				Model:         completion.Model,
+				PromptVersion: llm.QuizPromptVersion,
+			})
+			return quiz, model.AdminAuditEntry{Action: model.AdminAuditActionQuizWrite, Slug: slug, Summary: map[string]any{
+				"versionId": body.VersionID,
+				"chapter":   body.Chapter,
+				"status":    model.QuizDraft,
+				"questions": len(questions),
+				"model":     completion.Model,
+			}}, err
+		})
+		if err != nil {
+			writeQuizErr(w, err, "admin quiz save failed")
+			return
+		}
+		noStore(w)
+		writeJSON(w, http.StatusCreated, quiz)
+	})))
+
+	// PUT /api/v1/admin/stories/{slug}/quiz/{chapter}
+	mux.HandleFunc("PUT /api/v1/admin/stories/{slug}/quiz/{chapter}", guard(adminEditRoles, func(w http.ResponseWriter, r *http.Request) {
+		var body model.AdminQuizUpdate
+		if err := decodeJSON(w, r, &body); err != nil {
+			writeDecodeError(w, err)
+			return
+		}
+		slug := strings.TrimSpace(r.PathValue("slug"))
+		chapter, ok := quizChapter(w, r)
+		if !ok {
+			return
+		}
+		if fields := validateQuizUpdate(&body); len(fields) > 0 {
+			writeFields(w, http.StatusBadRequest, "quiz_invalid", "quiz is invalid", fields)
+			return
+		}
+		accountID := accountIDFromCtx(r)
+		versionID, ok := quizVersionID(store, w, r, slug, body.VersionID)
+		if !ok {
+			return
+		}
+		// Questions written or edited by hand are the editor's, so the
+		// model's provenance is cleared.
+		quiz, err := audited(store, r, func(ctx context.Context) (model.AdminChapterQuiz, model.AdminAuditEntry, error) {
+			quiz, err := store.AdminSaveQuiz(ctx, accountID, slug, versionID, chapter, model.QuizSave{Questions: body.Questions, Status: body.Status})
+			return quiz, model.AdminAuditEntry{Action: model.AdminAuditActionQuizWrite, Slug: slug, Summary: map[string]any{
+				"versionId": versionID,
+				"chapter":   chapter,
+				"status":    body.Status,
+				"questions": len(body.Questions),
+			}}, err
+		})
+		if err != nil {
+			writeQuizErr(w, err, "admin quiz save failed")
+			return
+		}
+		noStore(w)
+		writeJSON(w, http.StatusOK, quiz)
+	}))
+
+	// DELETE /api/v1/admin/stories/{slug}/quiz/{chapter}?versionId=
+	mux.HandleFunc("DELETE /api/v1/admin/stories/{slug}/quiz/{chapter}", guard(adminEditRoles, func(w http.ResponseWriter, r *http.Request) {
+		slug := strings.TrimSpace(r.PathValue("slug"))
+		chapter, ok := quizChapter(w, r)
+		if !ok {
+			return
+		}
+		accountID := accountIDFromCtx(r)
+		versionID, ok := quizVersionID(store, w, r, slug, r.URL.Query().Get("versionId"))
+		if !ok {
+			return
+		}
+		err := audit(store, r, func(ctx context.Context) (model.AdminAuditEntry, error) {
+			err := store.AdminDeleteQuiz(ctx, accountID, slug, versionID, chapter)
+			return model.AdminAuditEntry{Action: model.AdminAuditActionQuizDelete, Slug: slug, Summary: map[string]any{
+				"versionId": versionID,
+				"chapter":   chapter,
+			}}, err
+		})
+		if err != nil {
+			writeQuizErr(w, err, "admin quiz delete failed")
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+}
+
+// defaultQuizVersion is the published version, else the draft, else "".
+// Readers see the published version's quizzes, so that is the one to review.
+func defaultQuizVersion(story model.AdminStoryDetailResponse) string {
+	switch {
+	case story.PublishedVersion != nil:
+		return story.PublishedVersion.VersionID
+	case story.DraftVersion != nil:
+		return story.DraftVersion.VersionID
+	default:
+		return ""
+	}
+}
+
+// quizVersionID returns versionID or, when it is empty, the story's default
+// quiz version. It answers the request itself and reports false when there
+// is none.
+func quizVersionID(store Store, w http.ResponseWriter, r *http.Request, slug, versionID string) (string, bool) {
+	if versionID = strings.TrimSpace(versionID); versionID != "" {
+		return versionID, true
+	}
+	story, err := store.AdminGetStory(r.Context(), accountIDFromCtx(r), slug)
+	if err != nil {
+		if errors.Is(err, model.ErrAdminStoryNotFound) {
+			writeErr(w, http.StatusNotFound, "story_not_found", "story was not found")
+			return "", false
+		}
+		slog.Error("admin quiz story failed")
+		writeErr(w, http.StatusInternalServerError, "quiz_failed", "quiz request failed")
+		return "", false
+	}
+	if versionID = defaultQuizVersion(story); versionID == "" {
+		writeErr(w, http.StatusNotFound, "version_not_found", "story has no version")
+		return "", false
+	}
+	return versionID, true
+}
+
+func quizChapter(w http.ResponseWriter, r *http.Request) (int, bool) {
+	chapter, err := strconv.Atoi(r.PathValue("chapter"))
+	if err != nil || chapter < 1 {
+		writeErr(w, http.StatusNotFound, "chapter_not_found", "story version has no such chapter")
+		return 0, false
+	}
+	return chapter, true
+}
+
+// validateQuizUpdate trims the questions in place and reports what is wrong
+// with them.
+func validateQuizUpdate(body *model.AdminQuizUpdate) []model.FieldError {
+	var fields []model.FieldError
+	if len(body.Questions) == 0 || len(body.Questions) > model.MaxQuizQuestions {
+		fields = append(fields, model.FieldError{Path: "questions", Code: "invalid", Message: "a quiz has 1 to 5 questions"})
+	}
+	for index := range body.Questions {
+		question := &body.Questions[index]
+		question.Question, question.Answer = strings.TrimSpace(question.Question), strings.TrimSpace(question.Answer)
+		if question.Question == "" || utf8.RuneCountInString(question.Question) > model.MaxQuizQuestionLen {
+			fields = append(fields, model.FieldError{Path: "questions." + strconv.Itoa(index) + ".question", Code: "invalid", Message: "question must be 1 to 300 characters"})
+		}
+		if question.Answer == "" || utf8.RuneCountInString(question.Answer) > model.MaxQuizAnswerLen {
+			fields = append(fields, model.FieldError{Path: "questions." + strconv.Itoa(index) + ".answer", Code: "invalid", Message: "answer must be 1 to 300 characters"})
+		}
+	}
+	if body.Status != model.QuizDraft && body.Status != model.QuizApproved {
+		fields = append(fields, model.FieldError{Path: "status", Code: "invalid", Message: "status must be draft or approved"})
+	}
+	return fields
+}
+
+func writeQuizErr(w http.ResponseWriter, err error, logMsg string) {
+	switch {
+	case errors.Is(err, model.ErrAdminStoryNotFound):
+		writeErr(w, http.StatusNotFound, "version_not_found", "story version was not found")
+	case errors.Is(err, model.ErrQuizChapterNotFound):
+		writeErr(w, http.StatusNotFound, "chapter_not_found", "story version has no such chapter")
+	case errors.Is(err, model.ErrQuizNotFound):
+		writeErr(w, http.StatusNotFound, "quiz_not_found", "chapter has no quiz")
+	default:
+		slog.Error(logMsg)
+		writeErr(w, http.StatusInternalServerError, "quiz_failed", "quiz request failed")
+	}
+}
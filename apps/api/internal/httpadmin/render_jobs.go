@@ -0,0 +1,148 @@
+package httpadmin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"pandapages/api/internal/model"
+)
+
+// The events stream rereads its job from the database, since the worker
+// stepping it may run on another instance. A comment line keeps idle proxies
+// from closing the stream, and each stream ends after jobEventsLifetime, when
+// EventSource reconnects by itself, so a restart is never held up for long.
+var (
+	jobEventsPoll      = time.Second
+	jobEventsHeartbeat = 15 * time.Second
+	jobEventsLifetime  = 10 * time.Minute
+)
+
+// registerRenderJobRoutes mounts account-wide re-rendering. A job rewrites the
+// HTML of every stored version, including published ones, so only the
+// bootstrap key may start or follow one; the worker in cmd/api does the
+// rendering.
+func registerRenderJobRoutes(mux *http.ServeMux, store Store, guard func(http.HandlerFunc) http.HandlerFunc) {
+	// POST /api/v1/admin/render-jobs
+	mux.HandleFunc("POST /api/v1/admin/render-jobs", guard(func(w http.ResponseWriter, r *http.Request) {
+		out, err := audited(store, r, func(ctx context.Context) (model.RenderJob, model.AdminAuditEntry, error) {
+			out, err := store.AdminStartRenderJob(ctx, accountIDFromCtx(r))
+			return out, model.AdminAuditEntry{Action: model.AdminAuditActionRender, Summary: map[string]any{
+				"jobId":    out.ID,
+				"versions": out.TotalVersions,
+			}}, err
+		})
+		if err != nil {
+			if errors.Is(err, model.ErrRenderJobActive) {
+				writeErr(w, http.StatusConflict, "render_job_active", "a render job is already queued or running")
+				return
+			}
+			slog.Error("admin render job start failed")
+			writeErr(w, http.StatusInternalServerError, "render_job_failed", "render job could not be started")
+			return
+		}
+		noStore(w)
+		writeJSON(w, http.StatusAccepted, out)
+	}))
+
+	// GET /api/v1/admin/render-jobs/{id}
+	mux.HandleFunc("GET /api/v1/admin/render-jobs/{id}", guard(func(w http.ResponseWriter, r *http.Request) {
+		out, err := store.AdminGetRenderJob(r.Context(), accountIDFromCtx(r), strings.TrimSpace(r.PathValue("id")))
+		if err != nil {
+			if errors.Is(err, model.ErrRenderJobNotFound) {
+				writeErr(w, http.StatusNotFound, "render_job_not_found", "render job was not found")
+				return
+			}
+			slog.Error("admin render job read failed")
+			writeErr(w, http.StatusInternalServerError, "render_job_failed", "render job unavailable")
+			return
+		}
+		noStore(w)
+		writeJSON(w, http.StatusOK, out)
+	}))
+
+	// GET /api/v1/admin/render-jobs/{id}/events streams a render job's
+	// progress as server-sent events: a progress event whenever its counts
+	// move and one done event, after which the stream closes. Each event's
+	// data is the job as GET /api/v1/admin/render-jobs/{id} returns it.
+	mux.HandleFunc("GET /api/v1/admin/render-jobs/{id}/events", guard(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		accountID, jobID := accountIDFromCtx(r), strings.TrimSpace(r.PathValue("id"))
+		job, err := store.AdminGetRenderJob(ctx, accountID, jobID)
+		if err != nil {
+			if errors.Is(err, model.ErrRenderJobNotFound) {
+				writeErr(w, http.StatusNotFound, "render_job_not_found", "render job was not found")
+				return
+			}
+			slog.Error("admin render job read failed")
+			writeErr(w, http.StatusInternalServerError, "render_job_failed", "render job unavailable")
+			return
+		}
+
+		controller := http.NewResponseController(w)
+		noStore(w)
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+
+		send := func(format string, args ...any) bool {
+			_ = controller.SetWriteDeadline(time.Now().Add(2 * jobEventsHeartbeat))
+			if _, err := fmt.Fprintf(w, format, args...); err != nil {
+				return false
+			}
+			return controller.Flush() == nil
+		}
+		sendJob := func(event string, job model.RenderJob) bool {
+			data, _ := json.Marshal(job)
+			return send("event: %s\nid: %s\ndata: %s\n\n", event, job.UpdatedAt, data)
+		}
+
+		poll := time.NewTicker(jobEventsPoll)
+		defer poll.Stop()
+		heartbeat := time.NewTicker(jobEventsHeartbeat)
+		defer heartbeat.Stop()
+		lifetime := time.NewTimer(jobEventsLifetime)
+		defer lifetime.Stop()
+
+		if !send("retry: %d\n\n", jobEventsPoll.Milliseconds()) {
+			return
+		}
+		for last := ""; ; {
+			if job.Status == model.RenderJobCompleted || job.Status == model.RenderJobFailed {
+				sendJob("done", job)
+				return
+			}
+			if job.UpdatedAt != last {
+				if !sendJob("progress", job) {
+					return
+				}
+				last = job.UpdatedAt
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-lifetime.C:
+				return
+			case <-heartbeat.C:
+				if !send(": keep-alive\n\n") {
+					return
+				}
+				continue
+			case <-poll.C:
+			}
+			next, err := store.AdminGetRenderJob(ctx, accountID, jobID)
+			if err != nil {
+				if ctx.Err() == nil {
+					slog.Warn("admin render job events read failed", "job", jobID)
+				}
+				continue
+			}
+			job = next
+		}
+	}))
+}
@@ -0,0 +1,305 @@
+package httpadmin
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"pandapages/api/internal/model"
+	"pandapages/api/internal/storyingest"
+)
+
+// maxRestoreBytes bounds an uploaded backup archive. The archive is spooled
+// to a temporary file, never held in memory.
+const maxRestoreBytes = 1 << 30 // 1GB
+
+// errRestoreArchive marks an archive that is not a whole, well-formed backup.
+var errRestoreArchive = errors.New("backup archive is invalid")
+
+// registerRestoreRoutes mounts backup restore. It can replace stories and the
+// reader's settings, so only the bootstrap key may run it.
+func registerRestoreRoutes(mux *http.ServeMux, store Store, bootstrapGuard func(http.HandlerFunc) http.HandlerFunc) {
+	// POST /api/v1/admin/restore?conflict=skip|overwrite|new-slug
+	mux.HandleFunc("POST /api/v1/admin/restore", bootstrapGuard(func(w http.ResponseWriter, r *http.Request) {
+		conflict := model.RestoreConflictSkip
+		if raw := strings.TrimSpace(r.URL.Query().Get("conflict")); raw != "" {
+			conflict = model.RestoreConflict(raw)
+		}
+		if !conflict.Valid() {
+			writeErr(w, http.StatusBadRequest, "conflict_invalid", "conflict must be skip, overwrite, or new-slug")
+			return
+		}
+
+		controller := http.NewResponseController(w)
+		_ = controller.SetReadDeadline(time.Now().Add(backupTimeout))
+		_ = controller.SetWriteDeadline(time.Now().Add(backupTimeout))
+
+		spool, err := spoolRestoreBody(w, r)
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				writeErr(w, http.StatusRequestEntityTooLarge, "body_too_large", "backup archive exceeds the maximum size")
+				return
+			}
+			writeErr(w, http.StatusBadRequest, "bad_request", "backup archive could not be read")
+			return
+		}
+		defer func() {
+			_ = spool.Close()
+			_ = os.Remove(spool.Name())
+		}()
+
+		// Check the whole archive before changing anything, so a truncated
+		// or corrupt download restores nothing.
+		contents, err := checkBackup(spool)
+		if err != nil {
+			writeErr(w, http.StatusBadRequest, "restore_invalid", err.Error())
+			return
+		}
+		if _, err := spool.Seek(0, io.SeekStart); err != nil {
+			slog.Error("admin restore rewind failed")
+			writeErr(w, http.StatusInternalServerError, "restore_failed", "backup could not be restored")
+			return
+		}
+
+		// The whole restore is one transaction with its audit row, so a
+		// database error partway leaves the account as it was.
+		out, err := audited(store, r, func(ctx context.Context) (model.AdminRestoreResponse, model.AdminAuditEntry, error) {
+			out, err := restoreBackup(ctx, accountIDFromCtx(r), store, spool, contents, conflict)
+			outcomes := map[string]any{}
+			for _, story := range out.Stories {
+				count, _ := outcomes[string(story.Outcome)].(int)
+				outcomes[string(story.Outcome)] = count + 1
+			}
+			return out, model.AdminAuditEntry{Action: model.AdminAuditActionRestore, Summary: map[string]any{
+				"conflict": string(conflict),
+				"stories":  outcomes,
+				"media":    out.MediaRestored,
+				"progress": out.ProgressRestored,
+				"settings": out.SettingsRestored,
+			}}, err
+		})
+		if err != nil {
+			slog.ErrorContext(r.Context(), "admin restore failed")
+			writeErr(w, http.StatusInternalServerError, "restore_failed", "backup could not be restored; nothing was changed")
+			return
+		}
+		noStore(w)
+		writeJSON(w, http.StatusOK, out)
+	}))
+}
+
+func spoolRestoreBody(w http.ResponseWriter, r *http.Request) (*os.File, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxRestoreBytes)
+	defer r.Body.Close()
+	spool, err := os.CreateTemp("", "pandapages-restore-*.tar.gz")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.Copy(spool, r.Body); err != nil {
+		_ = spool.Close()
+		_ = os.Remove(spool.Name())
+		return nil, err
+	}
+	if _, err := spool.Seek(0, io.SeekStart); err != nil {
+		_ = spool.Close()
+		_ = os.Remove(spool.Name())
+		return nil, err
+	}
+	return spool, nil
+}
+
+// backupContents is what checkBackup keeps from the small JSON entries.
+type backupContents struct {
+	media    map[string]model.Media
+	settings *model.BackupSettings
+	progress []model.BackupProgress
+}
+
+// checkBackup reads the whole archive once. It requires the manifest first,
+// only known entries, images before stories and no entry twice, and that
+// every image the manifest lists is present at its recorded size.
+func checkBackup(r io.Reader) (backupContents, error) {
+	contents := backupContents{media: map[string]model.Media{}}
+	seen := map[string]bool{}
+	storiesStarted := false
+	err := readBackup(r, func(header *tar.Header, body io.Reader) error {
+		name := header.Name
+		if seen[name] {
+			return fmt.Errorf("%w: %s appears twice", errRestoreArchive, name)
+		}
+		seen[name] = true
+		if len(seen) == 1 && name != "manifest.json" {
+			return fmt.Errorf("%w: manifest.json must come first", errRestoreArchive)
+		}
+		if header.Size > maxJSONBodyBytes {
+			return fmt.Errorf("%w: %s is too large", errRestoreArchive, name)
+		}
+
+		switch {
+		case name == "manifest.json":
+			var manifest model.BackupManifest
+			if err := json.NewDecoder(body).Decode(&manifest); err != nil {
+				return fmt.Errorf("%w: manifest.json is not valid JSON", errRestoreArchive)
+			}
+			if manifest.Format != model.BackupFormat || manifest.FormatVersion != model.BackupFormatVersion {
+				return fmt.Errorf("%w: backup format is not supported", errRestoreArchive)
+			}
+			for _, media := range manifest.Media {
+				contents.media[media.ID] = media
+			}
+		case name == "settings.json":
+			contents.settings = &model.BackupSettings{}
+			if err := json.NewDecoder(body).Decode(contents.settings); err != nil {
+				return fmt.Errorf("%w: settings.json is not valid JSON", errRestoreArchive)
+			}
+		case name == "progress.json":
+			if err := json.NewDecoder(body).Decode(&contents.progress); err != nil {
+				return fmt.Errorf("%w: progress.json is not valid JSON", errRestoreArchive)
+			}
+		case name == "skipped.json":
+		case strings.HasPrefix(name, "media/"):
+			media, ok := contents.media[strings.TrimPrefix(name, "media/")]
+			if !ok || storiesStarted || header.Size != int64(media.ByteSize) || header.Size > maxMediaBytes {
+				return fmt.Errorf("%w: %s does not match the manifest", errRestoreArchive, name)
+			}
+		case strings.HasPrefix(name, "stories/") && strings.HasSuffix(name, ".json"):
+			if storyingest.ValidateSlug(strings.TrimSuffix(strings.TrimPrefix(name, "stories/"), ".json")) != nil {
+				return fmt.Errorf("%w: %s is not a story bundle", errRestoreArchive, name)
+			}
+			storiesStarted = true
+		default:
+			return fmt.Errorf("%w: unexpected entry %s", errRestoreArchive, name)
+		}
+		return nil
+	})
+	if err != nil {
+		return backupContents{}, err
+	}
+	if !seen["manifest.json"] {
+		return backupContents{}, fmt.Errorf("%w: manifest.json is missing", errRestoreArchive)
+	}
+	for id := range contents.media {
+		if !seen["media/"+id] {
+			return backupContents{}, fmt.Errorf("%w: media/%s is missing", errRestoreArchive, id)
+		}
+	}
+	return contents, nil
+}
+
+// restoreBackup applies a checked archive in its own order: settings, then
+// images, then each story as it is read, and last the reading progress of
+// the stories it wrote. A story that fails validation is reported and the
+// rest still restore.
+func restoreBackup(ctx context.Context, accountID string, store Store, archive io.Reader, contents backupContents, conflict model.RestoreConflict) (model.AdminRestoreResponse, error) {
+	out := model.AdminRestoreResponse{Conflict: conflict, Stories: []model.AdminRestoreStory{}}
+
+	if contents.settings != nil {
+		restored, err := store.AdminRestoreSettings(ctx, accountID, *contents.settings, conflict == model.RestoreConflictOverwrite)
+		if err != nil {
+			return model.AdminRestoreResponse{}, err
+		}
+		out.SettingsRestored = restored
+	}
+
+	restoredAs := map[string]string{}
+	err := readBackup(archive, func(header *tar.Header, body io.Reader) error {
+		switch {
+		case strings.HasPrefix(header.Name, "media/"):
+			data, err := io.ReadAll(body)
+			if err != nil {
+				return err
+			}
+			created, err := store.AdminRestoreMedia(ctx, accountID, contents.media[strings.TrimPrefix(header.Name, "media/")], data)
+			switch {
+			case errors.Is(err, model.ErrMediaConflict):
+				out.MediaConflicts++
+			case err != nil:
+				return err
+			case created:
+				out.MediaRestored++
+			default:
+				out.MediaExisting++
+			}
+		case strings.HasPrefix(header.Name, "stories/"):
+			var bundle model.StoryBundle
+			slug := strings.TrimSuffix(strings.TrimPrefix(header.Name, "stories/"), ".json")
+			if err := json.NewDecoder(body).Decode(&bundle); err != nil || bundle.Story.Slug != slug {
+				out.Stories = append(out.Stories, model.AdminRestoreStory{Slug: slug, Outcome: model.RestoreOutcomeFailed})
+				return nil
+			}
+			story, err := store.AdminRestoreStory(ctx, accountID, bundle, conflict)
+			var validationErr *model.AdminValidationError
+			switch {
+			case errors.As(err, &validationErr):
+				story = model.AdminRestoreStory{Slug: slug, Outcome: model.RestoreOutcomeFailed, Issues: validationErr.Issues}
+			case errors.Is(err, model.ErrAdminStoryConflict):
+				story = model.AdminRestoreStory{Slug: slug, Outcome: model.RestoreOutcomeFailed}
+			case err != nil:
+				return err
+			}
+			if story.Outcome != model.RestoreOutcomeSkipped && story.Outcome != model.RestoreOutcomeFailed {
+				restoredAs[slug] = story.RestoredAs
+			}
+			out.Stories = append(out.Stories, story)
+		}
+		return nil
+	})
+	if err != nil {
+		return model.AdminRestoreResponse{}, err
+	}
+
+	for _, progress := range contents.progress {
+		slug, ok := restoredAs[progress.Slug]
+		if !ok {
+			continue
+		}
+		progress.Slug = slug
+		restored, err := store.AdminRestoreProgress(ctx, accountID, progress)
+		if err != nil {
+			return model.AdminRestoreResponse{}, err
+		}
+		if restored {
+			out.ProgressRestored++
+		}
+	}
+	return out, nil
+}
+
+// readBackup calls visit for each file of a backup archive in order, then
+// reads to the end of the gzip stream so a missing trailer is an error.
+func readBackup(r io.Reader, visit func(header *tar.Header, body io.Reader) error) error {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return fmt.Errorf("%w: not a gzip file", errRestoreArchive)
+	}
+	tr := tar.NewReader(zr)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("%w: the archive is truncated or corrupt", errRestoreArchive)
+		}
+		if header.Typeflag != tar.TypeReg {
+			return fmt.Errorf("%w: %s is not a regular file", errRestoreArchive, header.Name)
+		}
+		if err := visit(header, tr); err != nil {
+			return err
+		}
+	}
+	if _, err := io.Copy(io.Discard, zr); err != nil {
+		return fmt.Errorf("%w: the archive is truncated or corrupt", errRestoreArchive)
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package httpadmin
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"pandapages/api/internal/model"
+)
+
+// registerRetentionRoutes mounts version pruning for one story and the
+// account-wide retention policy. The policy deletes history on every future
+// draft, so only the bootstrap key may change it.
+func registerRetentionRoutes(
+	mux *http.ServeMux,
+	store Store,
+	guard func([]model.AdminRole, http.HandlerFunc) http.HandlerFunc,
+	bootstrapGuard func(http.HandlerFunc) http.HandlerFunc,
+) {
+	// POST /api/v1/admin/stories/{slug}/prune-versions
+	mux.HandleFunc("POST /api/v1/admin/stories/{slug}/prune-versions", guard(adminEditRoles, func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Keep   *int `json:"keep"`
+			DryRun bool `json:"dryRun"`
+		}
+		if err := decodeJSON(w, r, &body); err != nil {
+			writeDecodeError(w, err)
+			return
+		}
+		keep := 0
+		if body.Keep != nil {
+			if !validVersionRetention(*body.Keep) {
+				writeErr(w, http.StatusBadRequest, "retention_invalid", "keep must be between 1 and 1000")
+				return
+			}
+			keep = *body.Keep
+		}
+
+		prune := func(ctx context.Context) (model.AdminPruneVersionsResponse, error) {
+			return store.AdminPruneVersions(ctx, accountIDFromCtx(r), strings.TrimSpace(r.PathValue("slug")), keep, body.DryRun)
+		}
+		// A dry run changes nothing, so it leaves no audit row.
+		var out model.AdminPruneVersionsResponse
+		var err error
+		if body.DryRun {
+			out, err = prune(r.Context())
+		} else {
+			out, err = audited(store, r, func(ctx context.Context) (model.AdminPruneVersionsResponse, model.AdminAuditEntry, error) {
+				out, err := prune(ctx)
+				return out, model.AdminAuditEntry{Action: model.AdminAuditActionPrune, Slug: out.Slug, Summary: map[string]any{
+					"keep":   out.Keep,
+					"pruned": out.PrunedVersions,
+				}}, err
+			})
+		}
+		if err != nil {
+			switch {
+			case errors.Is(err, model.ErrAdminStoryNotFound):
+				writeErr(w, http.StatusNotFound, "story_not_found", "story was not found")
+			case errors.Is(err, model.ErrAdminRetentionUnset):
+				writeErr(w, http.StatusBadRequest, "retention_not_set", "keep is required when no retention policy is set")
+			default:
+				slog.Error("admin version prune failed")
+				writeErr(w, http.StatusInternalServerError, "prune_failed", "versions could not be pruned")
+			}
+			return
+		}
+		noStore(w)
+		writeJSON(w, http.StatusOK, out)
+	}))
+
+	// GET /api/v1/admin/settings/version-retention
+	mux.HandleFunc("GET /api/v1/admin/settings/version-retention", bootstrapGuard(func(w http.ResponseWriter, r *http.Request) {
+		out, err := store.AdminGetVersionRetention(r.Context(), accountIDFromCtx(r))
+		if err != nil {
+			slog.Error("admin version retention read failed")
+			writeErr(w, http.StatusInternalServerError, "retention_failed", "retention policy unavailable")
+			return
+		}
+		noStore(w)
+		writeJSON(w, http.StatusOK, out)
+	}))
+
+	// PUT /api/v1/admin/settings/version-retention
+	mux.HandleFunc("PUT /api/v1/admin/settings/version-retention", bootstrapGuard(func(w http.ResponseWriter, r *http.Request) {
+		var body model.AdminVersionRetention
+		if err := decodeJSON(w, r, &body); err != nil {
+			writeDecodeError(w, err)
+			return
+		}
+		if body.Keep != nil && !validVersionRetention(*body.Keep) {
+			writeErr(w, http.StatusBadRequest, "retention_invalid", "keep must be null or between 1 and 1000")
+			return
+		}
+
+		out, err := audited(store, r, func(ctx context.Context) (model.AdminVersionRetention, model.AdminAuditEntry, error) {
+			out, err := store.AdminSetVersionRetention(ctx, accountIDFromCtx(r), body.Keep)
+			return out, model.AdminAuditEntry{Action: model.AdminAuditActionRetention, Summary: map[string]any{"keep": out.Keep}}, err
+		})
+		if err != nil {
+			slog.Error("admin version retention update failed")
+			writeErr(w, http.StatusInternalServerError, "retention_failed", "retention policy could not be updated")
+			return
+		}
+		noStore(w)
+		writeJSON(w, http.StatusOK, out)
+	}))
+}
+
+func validVersionRetention(keep int) bool {
+	return keep >= model.MinVersionRetention && keep <= model.MaxVersionRetention
+}
@@ -0,0 +1,70 @@
+package httpadmin
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"pandapages/api/internal/model"
+)
+
+// registerSearchReindexRoutes mounts rebuilding the semantic search index,
+// for when a story's vector is stale or the embedding model changed. The
+// worker in cmd/api embeds each published version again; without a provider
+// nothing would ever run a job, so the request is refused rather than queued.
+func registerSearchReindexRoutes(mux *http.ServeMux, store Store, enabled bool, guard func([]model.AdminRole, http.HandlerFunc) http.HandlerFunc) {
+	// POST /api/v1/admin/search/reindex
+	mux.HandleFunc("POST /api/v1/admin/search/reindex", guard(adminEditRoles, func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Slug string `json:"slug"`
+		}
+		if err := decodeJSON(w, r, &body); err != nil {
+			writeDecodeError(w, err)
+			return
+		}
+		if !enabled {
+			writeErr(w, http.StatusServiceUnavailable, "search_unavailable", "semantic search is not configured")
+			return
+		}
+
+		out, err := audited(store, r, func(ctx context.Context) (model.SearchReindexJob, model.AdminAuditEntry, error) {
+			out, err := store.AdminStartSearchReindex(ctx, accountIDFromCtx(r), strings.TrimSpace(body.Slug))
+			return out, model.AdminAuditEntry{Action: model.AdminAuditActionReindex, Slug: out.Slug, Summary: map[string]any{
+				"jobId":    out.ID,
+				"versions": out.TotalVersions,
+			}}, err
+		})
+		if err != nil {
+			switch {
+			case errors.Is(err, model.ErrAdminStoryNotFound):
+				writeErr(w, http.StatusNotFound, "story_not_found", "no published story has this slug")
+			case errors.Is(err, model.ErrSearchReindexJobActive):
+				writeErr(w, http.StatusConflict, "search_reindex_job_active", "a search reindex job is already queued or running")
+			default:
+				slog.Error("admin search reindex start failed")
+				writeErr(w, http.StatusInternalServerError, "search_reindex_failed", "search reindex could not be started")
+			}
+			return
+		}
+		noStore(w)
+		writeJSON(w, http.StatusAccepted, out)
+	}))
+
+	// GET /api/v1/admin/search/reindex-jobs/{id}
+	mux.HandleFunc("GET /api/v1/admin/search/reindex-jobs/{id}", guard(adminReadRoles, func(w http.ResponseWriter, r *http.Request) {
+		out, err := store.AdminGetSearchReindexJob(r.Context(), accountIDFromCtx(r), strings.TrimSpace(r.PathValue("id")))
+		if err != nil {
+			if errors.Is(err, model.ErrSearchReindexJobNotFound) {
+				writeErr(w, http.StatusNotFound, "search_reindex_job_not_found", "search reindex job was not found")
+				return
+			}
+			slog.Error("admin search reindex job read failed")
+			writeErr(w, http.StatusInternalServerError, "search_reindex_failed", "search reindex job unavailable")
+			return
+		}
+		noStore(w)
+		writeJSON(w, http.StatusOK, out)
+	}))
+}
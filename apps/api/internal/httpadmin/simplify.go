@@ -0,0 +1,225 @@
+package httpadmin
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"maps"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"pandapages/api/internal/llm"
+	"pandapages/api/internal/model"
+	"pandapages/api/internal/moderation"
+	"pandapages/api/internal/providerlog"
+	"pandapages/api/internal/storyingest"
+)
+
+// registerSimplifyRoutes mounts age-targeted simplification. Like translation
+// it writes a linked draft with the language model, so it needs the importer
+// role and is recorded in generation_jobs. Only the published version is
+// simplified: it is the text a family has already chosen to read.
+func registerSimplifyRoutes(mux *http.ServeMux, store Store, generator llm.Provider, moderator moderation.Provider, sensitivityWords []string, guard func([]model.AdminRole, http.HandlerFunc) http.HandlerFunc) {
+	// POST /api/v1/admin/stories/{slug}/simplify
+	mux.HandleFunc("POST /api/v1/admin/stories/{slug}/simplify", guard(adminImportRoles, idempotent(store, func(w http.ResponseWriter, r *http.Request) {
+		var body model.AdminSimplifyRequest
+		if err := decodeJSON(w, r, &body); err != nil {
+			writeDecodeError(w, err)
+			return
+		}
+		original := strings.TrimSpace(r.PathValue("slug"))
+		body.Slug = strings.TrimSpace(body.Slug)
+		var fields []model.FieldError
+		if body.Age < model.MinSimplifyAge || body.Age > model.MaxSimplifyAge {
+			fields = append(fields, model.FieldError{Path: "age", Code: "invalid", Message: "age must be 3 to 12"})
+		} else if body.Slug == "" {
+			body.Slug = original + "-age-" + strconv.Itoa(body.Age)
+		}
+		if body.Slug != "" && (storyingest.ValidateSlug(body.Slug) != nil || body.Slug == original) {
+			fields = append(fields, model.FieldError{Path: "slug", Code: "invalid", Message: "slug must be lowercase letters, digits and hyphens, and differ from the original's"})
+		}
+		if len(fields) > 0 {
+			writeFields(w, http.StatusBadRequest, "simplify_invalid", "simplification request is invalid", fields)
+			return
+		}
+		if generator == nil {
+			writeErr(w, http.StatusServiceUnavailable, "simplification_unavailable", "no language model provider is configured")
+			return
+		}
+
+		accountID := accountIDFromCtx(r)
+		story, err := store.AdminGetStory(r.Context(), accountID, original)
+		if err != nil {
+			if errors.Is(err, model.ErrAdminStoryNotFound) {
+				writeErr(w, http.StatusNotFound, "story_not_found", "story was not found")
+				return
+			}
+			slog.Error("admin simplification story failed")
+			writeErr(w, http.StatusInternalServerError, "simplification_failed", "story could not be simplified")
+			return
+		}
+		if story.PublishedVersion == nil {
+			writeErr(w, http.StatusNotFound, "version_not_found", "story has no published version to simplify")
+			return
+		}
+		source, err := store.AdminGetVersionSource(r.Context(), accountID, original, story.PublishedVersion.VersionID)
+		if err != nil {
+			switch {
+			case errors.Is(err, model.ErrAdminStoryNotFound):
+				writeErr(w, http.StatusNotFound, "version_not_found", "story version was not found")
+			case errors.Is(err, model.ErrAdminVersionRepairRequired):
+				writeErr(w, http.StatusConflict, "version_repair_required", "story version requires repair")
+			default:
+				slog.Error("admin simplification source failed")
+				writeErr(w, http.StatusInternalServerError, "simplification_failed", "story could not be simplified")
+			}
+			return
+		}
+		if err := store.AdminCheckSimplificationSlug(r.Context(), accountID, original, body.Slug); err != nil {
+			switch {
+			case errors.Is(err, model.ErrAdminStoryNotFound):
+				writeErr(w, http.StatusNotFound, "story_not_found", "story was not found")
+			case errors.Is(err, model.ErrSimplificationSlugTaken):
+				writeErr(w, http.StatusConflict, "simplification_slug_taken", "slug belongs to a story that is not a simplification of this one")
+			default:
+				slog.Error("admin simplification slug check failed")
+				writeErr(w, http.StatusInternalServerError, "simplification_failed", "story could not be simplified")
+			}
+			return
+		}
+
+		prompt := llm.SimplificationPrompt(source.Title, source.Markdown, source.Language, body.Age)
+		record := model.GenerationRecord{
+			Slug:          body.Slug,
+			PromptVersion: llm.SimplificationPromptVersion,
+			Request: generationPayload(map[string]any{
+				"provider":        generator.Name(),
+				"prompt":          prompt,
+				"simplifiedFrom":  original,
+				"sourceVersionId": source.VersionID,
+				"age":             body.Age,
+			}),
+		}
+		recordFailure := func(message string) {
+			record.Status, record.Error = model.GenerationFailed, &message
+			if _, err := store.AdminRecordGeneration(context.WithoutCancel(r.Context()), accountID, record); err != nil {
+				slog.Error("admin generation record failed")
+			}
+		}
+
+		sensitivities, err := activeSensitivities(r.Context(), store, accountID)
+		if err != nil {
+			slog.Error("admin simplification profiles failed")
+			writeErr(w, http.StatusInternalServerError, "simplification_failed", "story could not be simplified")
+			return
+		}
+
+		completion, err := generator.Complete(r.Context(), prompt)
+		if err != nil {
+			providerlog.Failure("story simplification failed", generator.Name())
+			recordFailure("the language model did not simplify the story")
+			writeErr(w, http.StatusBadGateway, "simplification_failed", "the language model did not simplify the story")
+			return
+		}
+		record.Model, record.TokensIn, record.TokensOut = completion.Model, completion.TokensIn, completion.TokensOut
+		record.Response = generationPayload(map[string]any{"text": completion.Text})
+
+		title, markdown, err := llm.ParseStory(completion.Text)
+		if err != nil {
+			recordFailure("the language model's reply was not a titled story")
+			writeErr(w, http.StatusBadGateway, "simplification_invalid", "the language model's reply was not a titled story")
+			return
+		}
+		var language *string
+		if source.Language != "" {
+			language = &source.Language
+		}
+		verdict := moderateDraft(r.Context(), moderator, sensitivityWords, sensitivities, title, markdown)
+		// The draft, its link, its provenance and the audit row commit
+		// together.
+		var generation model.StoryGeneration
+		draft, err := audited(store, r, func(ctx context.Context) (model.AdminDraftUpsertResponse, model.AdminAuditEntry, error) {
+			draft, err := store.AdminDraftUpsert(ctx, accountID, model.AdminDraftUpsertRequest{
+				Slug:       body.Slug,
+				Title:      title,
+				Author:     source.Author,
+				Markdown:   markdown,
+				Language:   language,
+				SourceURL:  source.SourceURL,
+				Rights:     simplifiedRights(source, body.Age),
+				Moderation: &verdict,
+			})
+			if err != nil {
+				return draft, model.AdminAuditEntry{}, err
+			}
+			if err := store.AdminLinkSimplification(ctx, accountID, draft.Slug, original, body.Age); err != nil {
+				return draft, model.AdminAuditEntry{}, errors.Join(errDraftLink, err)
+			}
+			succeeded := record
+			succeeded.Status, succeeded.VersionID = model.GenerationSucceeded, draft.VersionID
+			if generation, err = store.AdminRecordGeneration(ctx, accountID, succeeded); err != nil {
+				return draft, model.AdminAuditEntry{}, err
+			}
+			return draft, model.AdminAuditEntry{Action: model.AdminAuditActionSimplify, Slug: draft.Slug, Summary: map[string]any{
+				"versionId":       draft.VersionID,
+				"simplifiedFrom":  original,
+				"sourceVersionId": source.VersionID,
+				"age":             body.Age,
+				"generationId":    generation.ID,
+				"model":           completion.Model,
+				"moderation":      verdict.Verdict,
+			}}, nil
+		})
+		if err != nil {
+			if errors.Is(err, errDraftLink) {
+				slog.Error("admin simplification link failed")
+				writeErr(w, http.StatusInternalServerError, "simplification_link_failed", "simplified draft could not be linked to the original")
+				return
+			}
+			var validationErr *model.AdminValidationError
+			if errors.As(err, &validationErr) {
+				recordFailure("the simplified story is not valid")
+				writeIssues(w, http.StatusBadGateway, "simplification_invalid", "the simplified story is not valid", validationErr.Issues)
+				return
+			}
+			if errors.Is(err, model.ErrAdminVersionRepairRequired) {
+				writeErr(w, http.StatusConflict, "draft_repair_required", "stored story version requires repair")
+				return
+			}
+			slog.Error("admin simplified draft failed")
+			writeErr(w, http.StatusInternalServerError, "draft_failed", "story draft could not be saved")
+			return
+		}
+		noStore(w)
+		writeJSON(w, http.StatusCreated, model.AdminSimplifyResponse{
+			AdminDraftUpsertResponse: draft,
+			SimplifiedFrom:           original,
+			Age:                      body.Age,
+			Generation:               generation,
+			Moderation:               verdict,
+		})
+	})))
+}
+
+// simplifiedRights keeps the original's rights and records what the
+// simplification was adapted from, so attribution survives even if the
+// original is later deleted.
+func simplifiedRights(source model.AdminVersionSourceResponse, age int) map[string]any {
+	rights := make(map[string]any, len(source.Rights)+1)
+	maps.Copy(rights, source.Rights)
+	adaptedFrom := map[string]any{
+		"slug":      source.Slug,
+		"title":     source.Title,
+		"versionId": source.VersionID,
+		"age":       age,
+	}
+	if source.Author != nil {
+		adaptedFrom["author"] = *source.Author
+	}
+	if source.SourceURL != nil {
+		adaptedFrom["sourceUrl"] = *source.SourceURL
+	}
+	rights["adaptedFrom"] = adaptedFrom
+	return rights
+}
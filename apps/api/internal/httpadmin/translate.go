@@ -0,0 +1,226 @@
+package httpadmin
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"pandapages/api/internal/llm"
+	"pandapages/api/internal/model"
+	"pandapages/api/internal/moderation"
+	"pandapages/api/internal/providerlog"
+	"pandapages/api/internal/storyingest"
+)
+
+// languageTagRe accepts BCP 47 shaped tags such as "fr", "pt-BR" or
+// "zh-Hant-TW" without checking them against the registry.
+var languageTagRe = regexp.MustCompile(`^[A-Za-z]{2,3}(-[A-Za-z0-9]{1,8})*$`)
+
+const maxLanguageTagLen = 35
+
+// errDraftLink marks a translated or simplified draft that could not be
+// linked to its original, so it was not kept.
+var errDraftLink = errors.New("draft could not be linked to its original")
+
+// registerTranslateRoutes mounts machine translation. Like generation it
+// writes a draft, so it needs the importer role, and it uses the same
+// language model and generation_jobs history. The draft is linked to the
+// original so the Library can offer the two as language variants once both
+// are published.
+func registerTranslateRoutes(mux *http.ServeMux, store Store, generator llm.Provider, moderator moderation.Provider, sensitivityWords []string, guard func([]model.AdminRole, http.HandlerFunc) http.HandlerFunc) {
+	// POST /api/v1/admin/stories/{slug}/translate
+	mux.HandleFunc("POST /api/v1/admin/stories/{slug}/translate", guard(adminImportRoles, idempotent(store, func(w http.ResponseWriter, r *http.Request) {
+		var body model.AdminTranslateRequest
+		if err := decodeJSON(w, r, &body); err != nil {
+			writeDecodeError(w, err)
+			return
+		}
+		original := strings.TrimSpace(r.PathValue("slug"))
+		body.Language = strings.TrimSpace(body.Language)
+		body.Slug = strings.TrimSpace(body.Slug)
+		body.VersionID = strings.TrimSpace(body.VersionID)
+		var fields []model.FieldError
+		if len(body.Language) > maxLanguageTagLen || !languageTagRe.MatchString(body.Language) {
+			fields = append(fields, model.FieldError{Path: "language", Code: "invalid", Message: "language must be a language tag such as fr or pt-BR"})
+		} else if body.Slug == "" {
+			body.Slug = original + "-" + strings.ToLower(body.Language)
+		}
+		if body.Slug != "" && (storyingest.ValidateSlug(body.Slug) != nil || body.Slug == original) {
+			fields = append(fields, model.FieldError{Path: "slug", Code: "invalid", Message: "slug must be lowercase letters, digits and hyphens, and differ from the original's"})
+		}
+		if len(fields) > 0 {
+			writeFields(w, http.StatusBadRequest, "translate_invalid", "translation request is invalid", fields)
+			return
+		}
+		if generator == nil {
+			writeErr(w, http.StatusServiceUnavailable, "translation_unavailable", "no language model provider is configured")
+			return
+		}
+
+		accountID := accountIDFromCtx(r)
+		if body.VersionID == "" {
+			story, err := store.AdminGetStory(r.Context(), accountID, original)
+			if err != nil {
+				if errors.Is(err, model.ErrAdminStoryNotFound) {
+					writeErr(w, http.StatusNotFound, "story_not_found", "story was not found")
+					return
+				}
+				slog.Error("admin translation story failed")
+				writeErr(w, http.StatusInternalServerError, "translation_failed", "story could not be translated")
+				return
+			}
+			switch {
+			case story.PublishedVersion != nil:
+				body.VersionID = story.PublishedVersion.VersionID
+			case story.DraftVersion != nil:
+				body.VersionID = story.DraftVersion.VersionID
+			default:
+				writeErr(w, http.StatusNotFound, "version_not_found", "story has no version to translate")
+				return
+			}
+		}
+		source, err := store.AdminGetVersionSource(r.Context(), accountID, original, body.VersionID)
+		if err != nil {
+			switch {
+			case errors.Is(err, model.ErrAdminStoryNotFound):
+				writeErr(w, http.StatusNotFound, "version_not_found", "story version was not found")
+			case errors.Is(err, model.ErrAdminVersionRepairRequired):
+				writeErr(w, http.StatusConflict, "version_repair_required", "story version requires repair")
+			default:
+				slog.Error("admin translation source failed")
+				writeErr(w, http.StatusInternalServerError, "translation_failed", "story could not be translated")
+			}
+			return
+		}
+		if source.Language != "" && storyingest.SameLanguage(source.Language, body.Language) {
+			writeFields(w, http.StatusBadRequest, "translate_invalid", "translation request is invalid", []model.FieldError{
+				{Path: "language", Code: "same_language", Message: "story is already in that language"},
+			})
+			return
+		}
+		if err := store.AdminCheckTranslationSlug(r.Context(), accountID, original, body.Slug); err != nil {
+			switch {
+			case errors.Is(err, model.ErrAdminStoryNotFound):
+				writeErr(w, http.StatusNotFound, "story_not_found", "story was not found")
+			case errors.Is(err, model.ErrTranslationSlugTaken):
+				writeErr(w, http.StatusConflict, "translation_slug_taken", "slug belongs to a story that is not a translation of this one")
+			default:
+				slog.Error("admin translation slug check failed")
+				writeErr(w, http.StatusInternalServerError, "translation_failed", "story could not be translated")
+			}
+			return
+		}
+
+		prompt := llm.TranslationPrompt(source.Title, source.Markdown, source.Language, body.Language)
+		record := model.GenerationRecord{
+			Slug:          body.Slug,
+			PromptVersion: llm.TranslationPromptVersion,
+			Request: generationPayload(map[string]any{
+				"provider":        generator.Name(),
+				"prompt":          prompt,
+				"translationOf":   original,
+				"sourceVersionId": source.VersionID,
+				"language":        body.Language,
+			}),
+		}
+		recordFailure := func(message string) {
+			record.Status, record.Error = model.GenerationFailed, &message
+			if _, err := store.AdminRecordGeneration(context.WithoutCancel(r.Context()), accountID, record); err != nil {
+				slog.Error("admin generation record failed")
+			}
+		}
+
+		sensitivities, err := activeSensitivities(r.Context(), store, accountID)
+		if err != nil {
+			slog.Error("admin translation profiles failed")
+			writeErr(w, http.StatusInternalServerError, "translation_failed", "story could not be translated")
+			return
+		}
+
+		completion, err := generator.Complete(r.Context(), prompt)
+		if err != nil {
+			providerlog.Failure("story translation failed", generator.Name())
+			recordFailure("the language model did not translate the story")
+			writeErr(w, http.StatusBadGateway, "translation_failed", "the language model did not translate the story")
+			return
+		}
+		record.Model, record.TokensIn, record.TokensOut = completion.Model, completion.TokensIn, completion.TokensOut
+		record.Response = generationPayload(map[string]any{"text": completion.Text})
+
+		title, markdown, err := llm.ParseStory(completion.Text)
+		if err != nil {
+			recordFailure("the language model's reply was not a titled story")
+			writeErr(w, http.StatusBadGateway, "translation_invalid", "the language model's reply was not a titled story")
+			return
+		}
+		verdict := moderateDraft(r.Context(), moderator, sensitivityWords, sensitivities, title, markdown)
+		// The draft, its link, its provenance and the audit row commit
+		// together.
+		var (
+			translationOf string
+			generation    model.StoryGeneration
+		)
+		draft, err := audited(store, r, func(ctx context.Context) (model.AdminDraftUpsertResponse, model.AdminAuditEntry, error) {
+			draft, err := store.AdminDraftUpsert(ctx, accountID, model.AdminDraftUpsertRequest{
+				Slug:       body.Slug,
+				Title:      title,
+				Author:     source.Author,
+				Markdown:   markdown,
+				Language:   &body.Language,
+				SourceURL:  source.SourceURL,
+				Rights:     source.Rights,
+				Moderation: &verdict,
+			})
+			if err != nil {
+				return draft, model.AdminAuditEntry{}, err
+			}
+			if translationOf, err = store.AdminLinkTranslation(ctx, accountID, draft.Slug, original); err != nil {
+				return draft, model.AdminAuditEntry{}, errors.Join(errDraftLink, err)
+			}
+			succeeded := record
+			succeeded.Status, succeeded.VersionID = model.GenerationSucceeded, draft.VersionID
+			if generation, err = store.AdminRecordGeneration(ctx, accountID, succeeded); err != nil {
+				return draft, model.AdminAuditEntry{}, err
+			}
+			return draft, model.AdminAuditEntry{Action: model.AdminAuditActionTranslate, Slug: draft.Slug, Summary: map[string]any{
+				"versionId":       draft.VersionID,
+				"translationOf":   translationOf,
+				"sourceVersionId": source.VersionID,
+				"language":        body.Language,
+				"generationId":    generation.ID,
+				"model":           completion.Model,
+				"moderation":      verdict.Verdict,
+			}}, nil
+		})
+		if err != nil {
+			if errors.Is(err, errDraftLink) {
+				slog.Error("admin translation link failed")
+				writeErr(w, http.StatusInternalServerError, "translation_link_failed", "translated draft could not be linked to the original")
+				return
+			}
+			var validationErr *model.AdminValidationError
+			if errors.As(err, &validationErr) {
+				recordFailure("the translated story is not valid")
+				writeIssues(w, http.StatusBadGateway, "translation_invalid", "the translated story is not valid", validationErr.Issues)
+				return
+			}
+			if errors.Is(err, model.ErrAdminVersionRepairRequired) {
+				writeErr(w, http.StatusConflict, "draft_repair_required", "stored story version requires repair")
+				return
+			}
+			slog.Error("admin translated draft failed")
+			writeErr(w, http.StatusInternalServerError, "draft_failed", "story draft could not be saved")
+			return
+		}
+		noStore(w)
+		writeJSON(w, http.StatusCreated, model.AdminTranslateResponse{
+			AdminDraftUpsertResponse: draft,
+			TranslationOf:            translationOf,
+			Generation:               generation,
+			Moderation:               verdict,
+		})
+	})))
+}
@@ -0,0 +1,200 @@
+package httpadmin
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"pandapages/api/internal/model"
+)
+
+const (
+	// Uploads lift the single-request JSON cap for very large imports while
+	// keeping each request small.
+	maxUploadBytes      = 256 << 20 // 256MB
+	maxUploadChunkBytes = 8 << 20   // 8MB
+)
+
+// registerUploadRoutes mounts the resumable upload protocol: create an
+// upload, PUT chunks in order, then finalize to ingest the assembled body
+// exactly as the matching JSON route would.
+func registerUploadRoutes(mux *http.ServeMux, store Store, guard func([]model.AdminRole, http.HandlerFunc) http.HandlerFunc) {
+	// POST /api/v1/admin/uploads
+	mux.HandleFunc("POST /api/v1/admin/uploads", guard(adminImportRoles, func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Kind       model.AdminUploadKind `json:"kind"`
+			TotalBytes int64                 `json:"totalBytes"`
+			SHA256     string                `json:"sha256"`
+		}
+		if err := decodeJSON(w, r, &body); err != nil {
+			writeDecodeError(w, err)
+			return
+		}
+		body.SHA256 = strings.ToLower(strings.TrimSpace(body.SHA256))
+		if !body.Kind.Valid() {
+			writeErr(w, http.StatusBadRequest, "upload_invalid", "kind must be draft or bundle")
+			return
+		}
+		if body.TotalBytes <= 0 {
+			writeErr(w, http.StatusBadRequest, "upload_invalid", "totalBytes must be positive")
+			return
+		}
+		if body.TotalBytes > maxUploadBytes {
+			writeErr(w, http.StatusRequestEntityTooLarge, "upload_too_large", "upload exceeds the maximum size")
+			return
+		}
+		if body.SHA256 != "" && !validUploadChecksum(body.SHA256) {
+			writeErr(w, http.StatusBadRequest, "upload_invalid", "sha256 must be 64 hexadecimal characters")
+			return
+		}
+
+		upload, err := store.AdminCreateUpload(r.Context(), accountIDFromCtx(r), model.AdminUploadCreate{
+			Kind:       body.Kind,
+			TotalBytes: body.TotalBytes,
+			SHA256:     body.SHA256,
+		})
+		if err != nil {
+			slog.Error("admin upload creation failed")
+			writeErr(w, http.StatusInternalServerError, "upload_failed", "upload could not be started")
+			return
+		}
+		upload.MaxChunkBytes = maxUploadChunkBytes
+		noStore(w)
+		writeJSON(w, http.StatusCreated, upload)
+	}))
+
+	// GET /api/v1/admin/uploads/{id}
+	mux.HandleFunc("GET /api/v1/admin/uploads/{id}", guard(adminImportRoles, func(w http.ResponseWriter, r *http.Request) {
+		upload, err := store.AdminGetUpload(r.Context(), accountIDFromCtx(r), r.PathValue("id"))
+		if err != nil {
+			writeUploadErr(w, err)
+			return
+		}
+		upload.MaxChunkBytes = maxUploadChunkBytes
+		noStore(w)
+		writeJSON(w, http.StatusOK, upload)
+	}))
+
+	// PUT /api/v1/admin/uploads/{id}/chunks?offset=
+	mux.HandleFunc("PUT /api/v1/admin/uploads/{id}/chunks", guard(adminImportRoles, func(w http.ResponseWriter, r *http.Request) {
+		offset, err := strconv.ParseInt(strings.TrimSpace(r.URL.Query().Get("offset")), 10, 64)
+		if err != nil || offset < 0 {
+			writeErr(w, http.StatusBadRequest, "bad_request", "offset must be a non-negative integer")
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, maxUploadChunkBytes)
+		defer r.Body.Close()
+		data, err := io.ReadAll(r.Body)
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeErr(w, http.StatusRequestEntityTooLarge, "body_too_large", "chunk exceeds the maximum chunk size")
+			return
+		}
+		if err != nil {
+			writeErr(w, http.StatusBadRequest, "bad_request", "chunk could not be read")
+			return
+		}
+		if len(data) == 0 {
+			writeErr(w, http.StatusBadRequest, "bad_request", "chunk must not be empty")
+			return
+		}
+
+		upload, err := store.AdminAppendUploadChunk(r.Context(), accountIDFromCtx(r), r.PathValue("id"), offset, data)
+		if err != nil {
+			writeUploadErr(w, err)
+			return
+		}
+		upload.MaxChunkBytes = maxUploadChunkBytes
+		noStore(w)
+		writeJSON(w, http.StatusOK, upload)
+	}))
+
+	// POST /api/v1/admin/uploads/{id}/finalize
+	mux.HandleFunc("POST /api/v1/admin/uploads/{id}/finalize", guard(adminImportRoles, func(w http.ResponseWriter, r *http.Request) {
+		aid := accountIDFromCtx(r)
+		uploadID := r.PathValue("id")
+		upload, content, err := store.AdminReadUpload(r.Context(), aid, uploadID)
+		if err != nil {
+			writeUploadErr(w, err)
+			return
+		}
+		if upload.ReceivedBytes != upload.TotalBytes || int64(len(content)) != upload.TotalBytes {
+			writeErr(w, http.StatusConflict, "upload_incomplete", "upload has not received every byte")
+			return
+		}
+		if upload.SHA256 != nil {
+			sum := sha256.Sum256(content)
+			if hex.EncodeToString(sum[:]) != *upload.SHA256 {
+				writeErr(w, http.StatusBadRequest, "upload_checksum_mismatch", "upload does not match its sha256")
+				return
+			}
+		}
+
+		var ok bool
+		switch upload.Kind {
+		case model.AdminUploadKindDraft:
+			var body model.AdminDraftUpsertRequest
+			if err := decodeJSONBytes(content, &body); err != nil {
+				writeErr(w, http.StatusBadRequest, "bad_json", "upload must be valid JSON")
+				return
+			}
+			ok = serveDraftUpsert(store, w, r, body)
+		case model.AdminUploadKindBundle:
+			var body model.StoryBundle
+			if err := decodeJSONBytes(content, &body); err != nil {
+				writeErr(w, http.StatusBadRequest, "bad_json", "upload must be valid JSON")
+				return
+			}
+			ok = serveStoryImport(store, w, r, body)
+		default:
+			slog.Error("admin upload has unknown kind")
+			writeErr(w, http.StatusInternalServerError, "upload_failed", "upload could not be finalized")
+			return
+		}
+		// A failed ingest keeps the upload so the client can inspect the
+		// error and retry; a successful one no longer needs the chunks, and
+		// any left behind by a failed delete expire on their own.
+		if ok {
+			if err := store.AdminDeleteUpload(r.Context(), aid, uploadID); err != nil {
+				slog.Error("admin upload cleanup failed")
+			}
+		}
+	}))
+
+	// DELETE /api/v1/admin/uploads/{id}
+	mux.HandleFunc("DELETE /api/v1/admin/uploads/{id}", guard(adminImportRoles, func(w http.ResponseWriter, r *http.Request) {
+		if err := store.AdminDeleteUpload(r.Context(), accountIDFromCtx(r), r.PathValue("id")); err != nil {
+			writeUploadErr(w, err)
+			return
+		}
+		noStore(w)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+}
+
+func writeUploadErr(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, model.ErrAdminUploadNotFound):
+		writeErr(w, http.StatusNotFound, "upload_not_found", "upload was not found")
+	case errors.Is(err, model.ErrAdminUploadOffset):
+		writeErr(w, http.StatusConflict, "upload_offset_mismatch", "chunk offset does not match received bytes")
+	case errors.Is(err, model.ErrAdminUploadTooLarge):
+		writeErr(w, http.StatusRequestEntityTooLarge, "upload_too_large", "chunk exceeds the declared upload size")
+	default:
+		slog.Error("admin upload operation failed")
+		writeErr(w, http.StatusInternalServerError, "upload_failed", "upload operation failed")
+	}
+}
+
+func validUploadChecksum(value string) bool {
+	if len(value) != sha256.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(value)
+	return err == nil
+}
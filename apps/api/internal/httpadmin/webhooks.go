@@ -0,0 +1,170 @@
+package httpadmin
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"pandapages/api/internal/model"
+	"pandapages/api/internal/webhooks"
+)
+
+// registerWebhookRoutes mounts webhook management. Webhooks hold signing
+// secrets and send account events off-site, so every route is bootstrap-only.
+func registerWebhookRoutes(mux *http.ServeMux, store Store, guard func(http.HandlerFunc) http.HandlerFunc) {
+	// GET /api/v1/admin/webhooks
+	mux.HandleFunc("GET /api/v1/admin/webhooks", guard(func(w http.ResponseWriter, r *http.Request) {
+		out, err := store.AdminListWebhooks(r.Context(), accountIDFromCtx(r))
+		if err != nil {
+			slog.Error("admin webhook list failed")
+			writeErr(w, http.StatusInternalServerError, "webhooks_failed", "webhooks unavailable")
+			return
+		}
+		noStore(w)
+		writeJSON(w, http.StatusOK, out)
+	}))
+
+	// POST /api/v1/admin/webhooks
+	mux.HandleFunc("POST /api/v1/admin/webhooks", guard(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			URL    string               `json:"url"`
+			Events []model.WebhookEvent `json:"events"`
+		}
+		if err := decodeJSON(w, r, &body); err != nil {
+			writeDecodeError(w, err)
+			return
+		}
+		body.URL = strings.TrimSpace(body.URL)
+		if !webhooks.ValidURL(body.URL) {
+			writeErr(w, http.StatusBadRequest, "webhook_invalid", "url must be an absolute http or https URL on the public internet")
+			return
+		}
+		if !webhooks.ValidEvents(body.Events) {
+			writeErr(w, http.StatusBadRequest, "webhook_invalid", "events are invalid")
+			return
+		}
+
+		secret, err := webhooks.NewSecret()
+		if err != nil {
+			slog.Error("webhook secret generation failed")
+			writeErr(w, http.StatusInternalServerError, "webhook_failed", "webhook could not be created")
+			return
+		}
+		hook, err := audited(store, r, func(ctx context.Context) (model.AdminWebhook, model.AdminAuditEntry, error) {
+			hook, err := store.AdminCreateWebhook(ctx, accountIDFromCtx(r), model.AdminWebhookCreate{
+				URL:    body.URL,
+				Events: body.Events,
+				Secret: secret,
+			})
+			return hook, model.AdminAuditEntry{Action: model.AdminAuditActionHookCreate, Summary: map[string]any{
+				"webhookId": hook.ID,
+				"events":    hook.Events,
+			}}, err
+		})
+		if err != nil {
+			slog.Error("admin webhook creation failed")
+			writeErr(w, http.StatusInternalServerError, "webhook_failed", "webhook could not be created")
+			return
+		}
+
+		noStore(w)
+		writeJSON(w, http.StatusCreated, model.AdminWebhookCreateResponse{Webhook: hook, Secret: secret})
+	}))
+
+	// PATCH /api/v1/admin/webhooks/{id}
+	mux.HandleFunc("PATCH /api/v1/admin/webhooks/{id}", guard(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			URL    *string              `json:"url"`
+			Events []model.WebhookEvent `json:"events"`
+			Active *bool                `json:"active"`
+		}
+		if err := decodeJSON(w, r, &body); err != nil {
+			writeDecodeError(w, err)
+			return
+		}
+		if body.URL == nil && body.Events == nil && body.Active == nil {
+			writeErr(w, http.StatusBadRequest, "webhook_invalid", "no changes requested")
+			return
+		}
+		if body.URL != nil {
+			value := strings.TrimSpace(*body.URL)
+			if !webhooks.ValidURL(value) {
+				writeErr(w, http.StatusBadRequest, "webhook_invalid", "url must be an absolute http or https URL on the public internet")
+				return
+			}
+			body.URL = &value
+		}
+		if body.Events != nil && !webhooks.ValidEvents(body.Events) {
+			writeErr(w, http.StatusBadRequest, "webhook_invalid", "events are invalid")
+			return
+		}
+
+		hook, err := audited(store, r, func(ctx context.Context) (model.AdminWebhook, model.AdminAuditEntry, error) {
+			hook, err := store.AdminUpdateWebhook(ctx, accountIDFromCtx(r), strings.TrimSpace(r.PathValue("id")), model.AdminWebhookUpdate{
+				URL:    body.URL,
+				Events: body.Events,
+				Active: body.Active,
+			})
+			return hook, model.AdminAuditEntry{Action: model.AdminAuditActionHookUpdate, Summary: map[string]any{
+				"webhookId": hook.ID,
+				"events":    hook.Events,
+				"active":    hook.Active,
+			}}, err
+		})
+		if err != nil {
+			writeWebhookErr(w, err, "webhook could not be updated")
+			return
+		}
+		noStore(w)
+		writeJSON(w, http.StatusOK, hook)
+	}))
+
+	// DELETE /api/v1/admin/webhooks/{id}
+	mux.HandleFunc("DELETE /api/v1/admin/webhooks/{id}", guard(func(w http.ResponseWriter, r *http.Request) {
+		webhookID := strings.TrimSpace(r.PathValue("id"))
+		err := audit(store, r, func(ctx context.Context) (model.AdminAuditEntry, error) {
+			err := store.AdminDeleteWebhook(ctx, accountIDFromCtx(r), webhookID)
+			return model.AdminAuditEntry{Action: model.AdminAuditActionHookDelete, Summary: map[string]any{
+				"webhookId": webhookID,
+			}}, err
+		})
+		if err != nil {
+			writeWebhookErr(w, err, "webhook could not be deleted")
+			return
+		}
+		noStore(w)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	// GET /api/v1/admin/webhooks/{id}/deliveries?limit=
+	mux.HandleFunc("GET /api/v1/admin/webhooks/{id}/deliveries", guard(func(w http.ResponseWriter, r *http.Request) {
+		limit := 0
+		if raw := strings.TrimSpace(r.URL.Query().Get("limit")); raw != "" {
+			value, err := strconv.Atoi(raw)
+			if err != nil || value < 1 {
+				writeErr(w, http.StatusBadRequest, "bad_request", "limit must be a positive integer")
+				return
+			}
+			limit = value
+		}
+		out, err := store.AdminListWebhookDeliveries(r.Context(), accountIDFromCtx(r), strings.TrimSpace(r.PathValue("id")), limit)
+		if err != nil {
+			writeWebhookErr(w, err, "webhook deliveries unavailable")
+			return
+		}
+		noStore(w)
+		writeJSON(w, http.StatusOK, out)
+	}))
+}
+
+func writeWebhookErr(w http.ResponseWriter, err error, message string) {
+	if errors.Is(err, model.ErrAdminWebhookNotFound) {
+		writeErr(w, http.StatusNotFound, "webhook_not_found", "webhook was not found")
+		return
+	}
+	slog.Error("admin webhook operation failed")
+	writeErr(w, http.StatusInternalServerError, "webhook_failed", message)
+}
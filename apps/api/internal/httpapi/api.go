@@ -1,9 +1,12 @@
 package httpapi
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
 	"crypto/subtle"
 	"database/sql"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"io"
@@ -12,34 +15,109 @@ import (
 	"strings"
 	"time"
 
+	"pandapages/api/internal/blob"
+	"pandapages/api/internal/dictionary"
+	"pandapages/api/internal/embedding"
 	"pandapages/api/internal/httpauth"
+	"pandapages/api/internal/httpmiddleware"
+	"pandapages/api/internal/idempotency"
+	"pandapages/api/internal/maintenance"
 	"pandapages/api/internal/model"
+	"pandapages/api/internal/openapi"
+	"pandapages/api/internal/phonics"
+	"pandapages/api/internal/ratelimit"
 	"pandapages/api/internal/readercontract"
 	"pandapages/api/internal/readiness"
 	"pandapages/api/internal/session"
+	"pandapages/api/internal/webpush"
 )
 
 type Config struct {
 	Passcode string
 	Sessions *session.Manager
+
+	// ReadLimit budgets GET and HEAD requests per client and WriteLimit
+	// everything else; nil leaves that kind unthrottled.
+	ReadLimit  *ratelimit.Limiter
+	WriteLimit *ratelimit.Limiter
+	// TrustForwardedFor identifies anonymous clients by X-Forwarded-For,
+	// which is only safe when a proxy in front of the API always sets it.
+	TrustForwardedFor bool
+	// Maintenance, when on, takes the reader routes offline; nil never does.
+	Maintenance *maintenance.Switch
+	// Delivery is whether an SMTP relay is configured to email stories;
+	// without one, sending answers 503.
+	Delivery bool
+	// Embedder embeds search queries; nil answers semantic search with 503.
+	Embedder embedding.Provider
+	// Dictionary defines tapped words; nil answers definitions with 503.
+	Dictionary *dictionary.Client
+	// Phonics is the progression the Library's decodable filter uses; nil
+	// answers that filter and the progression with 503.
+	Phonics *phonics.Progression
+	// Push sends Web Push notifications; nil answers the key and subscribing
+	// with 503.
+	Push *webpush.Sender
+	// Blobs holds narration kept outside the database and caches EPUB
+	// downloads; when it signs URLs, those downloads are redirected to it.
+	// nil builds every book on request.
+	Blobs blob.Store
 }
 
 type Store interface {
 	// Phase A: derive an account id from today's unlock mechanism.
-	EnsureDefaultAccount() (string, error)
-	AccountExists(accountID string) (bool, error)
+	EnsureDefaultAccount(ctx context.Context) (string, error)
+	AccountExists(ctx context.Context, accountID string) (bool, error)
 	CheckReadiness(context.Context) error
 
-	Library(accountID string) (model.LibraryReadModel, error)
-	ReaderStory(accountID, slug string) (model.ReaderStory, error)
+	Library(ctx context.Context, accountID string, page model.PageRequest, filter model.LibraryFilter) (model.LibraryReadModel, error)
+	ReaderStory(ctx context.Context, accountID, slug string) (model.ReaderStory, error)
+	ReaderVocabulary(ctx context.Context, accountID, slug string) (model.Vocabulary, error)
+	ReaderQuiz(ctx context.Context, accountID, slug string) (model.StoryQuiz, error)
+	ReaderDiscussion(ctx context.Context, accountID, slug string) (model.StoryDiscussion, error)
+	DictionaryEntry(ctx context.Context, language, word string) (model.DictionaryEntry, error)
+	DictionarySave(ctx context.Context, language, word string, definition *model.Definition) error
+	Media(ctx context.Context, accountID, mediaID string) (model.Media, []byte, error)
+	SegmentAudio(ctx context.Context, accountID, audioID string) (model.NarrationAudio, error)
+
+	ProgressGet(ctx context.Context, accountID, slug string) (model.ProgressResponse, error)
+	ProgressPut(ctx context.Context, accountID, slug string, version int, locator readercontract.Locator, percent float64) error
+
+	ContinueRecent(ctx context.Context, accountID string, limit int) ([]model.ContinueItem, error)
+
+	Queue(ctx context.Context, accountID string) ([]model.QueueItem, error)
+	QueueAdd(ctx context.Context, accountID, slug string) ([]model.QueueItem, bool, error)
+	QueueRemove(ctx context.Context, accountID, slug string) ([]model.QueueItem, error)
+	QueueReorder(ctx context.Context, accountID string, slugs []string) ([]model.QueueItem, error)
+	SetStoryReaction(ctx context.Context, accountID, slug string, reaction *model.StoryReaction) (model.StoryReactionResponse, error)
+
+	SettingsGet(ctx context.Context, accountID string) (model.SettingsPayload, error)
+	SettingsPut(ctx context.Context, accountID string, payload model.SettingsUpsert) (model.SettingsPayload, error)
 
-	ProgressGet(accountID, slug string) (model.ProgressResponse, error)
-	ProgressPut(accountID, slug string, version int, locator readercontract.Locator, percent float64) error
+	DeliveryDestinations(ctx context.Context, accountID string) (model.DeliveryDestinationsResponse, error)
+	CreateDeliveryDestination(ctx context.Context, accountID string, create model.DeliveryDestinationCreate) (model.DeliveryDestination, error)
+	DeleteDeliveryDestination(ctx context.Context, accountID, destinationID string) error
+	SendStory(ctx context.Context, accountID, slug, destinationID string) (model.StoryDelivery, error)
+	StoryDelivery(ctx context.Context, accountID, deliveryID string) (model.StoryDelivery, error)
+	StoryDeliveries(ctx context.Context, accountID string) (model.StoryDeliveriesResponse, error)
 
-	ContinueRecent(accountID string, limit int) ([]model.ContinueItem, error)
+	PushSubscribe(ctx context.Context, accountID string, save model.PushSubscriptionSave) (model.PushSubscription, error)
+	PushUnsubscribe(ctx context.Context, accountID, endpoint string) error
+	PushSettings(ctx context.Context, accountID string) (model.PushSettings, error)
+	PutPushSettings(ctx context.Context, accountID string, settings model.PushSettings) (model.PushSettings, error)
 
-	SettingsGet(accountID string) (model.SettingsPayload, error)
-	SettingsPut(accountID string, payload model.SettingsUpsert) (model.SettingsPayload, error)
+	DigestSettings(ctx context.Context, accountID string) (model.DigestSettings, error)
+	PutDigestSettings(ctx context.Context, accountID string, settings model.DigestSettings) (model.DigestSettings, error)
+	BedtimeSettings(ctx context.Context, accountID string) (model.BedtimeSettings, error)
+	PutBedtimeSettings(ctx context.Context, accountID string, settings model.BedtimeSettings) (model.BedtimeSettings, error)
+	ReadingReport(ctx context.Context, accountID string, at time.Time) (model.ReadingReport, error)
+	ChildDashboard(ctx context.Context, accountID, childID string, at time.Time) (model.ChildDashboard, error)
+
+	RecordClientEvents(ctx context.Context, accountID string, events []model.ClientEvent) (model.ClientEventsResponse, error)
+
+	SemanticSearch(ctx context.Context, accountID, modelName string, query []float32, limit int) (model.SemanticSearchResponse, error)
+
+	idempotency.Store
 }
 
 const (
@@ -113,7 +191,7 @@ func New(cfg Config, store Store) http.Handler {
 			return
 		}
 
-		accountID, err := store.EnsureDefaultAccount()
+		accountID, err := store.EnsureDefaultAccount(r.Context())
 		if err != nil {
 			writeErr(w, http.StatusInternalServerError, "db", "account init failed")
 			return
@@ -163,6 +241,19 @@ func New(cfg Config, store Store) http.Handler {
 		writeJSON(w, http.StatusOK, map[string]any{"ok": true})
 	})
 
+	// The OpenAPI document describes the API rather than an account, so it
+	// needs no session.
+	mux.HandleFunc("/api/v1/openapi.json", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w, []string{http.MethodGet})
+			return
+		}
+		writeRevalidated(w, r, "application/json", func(out io.Writer, _ func()) error {
+			_, err := out.Write(openapi.Document())
+			return err
+		})
+	})
+
 	type authedHandler func(w http.ResponseWriter, r *http.Request, accountID string)
 
 	withUnlock := func(next authedHandler) http.HandlerFunc {
@@ -177,28 +268,75 @@ func New(cfg Config, store Store) http.Handler {
 				writeErr(w, http.StatusServiceUnavailable, "session_unavailable", "session validation unavailable")
 				return
 			}
+			httpmiddleware.SetAccount(r.Context(), accountID)
 			next(w, r, accountID)
 		}
 	}
 
-	// Library
+	// withIdempotency runs a write sent with an Idempotency-Key once, so a
+	// retried progress save cannot land after a newer one made meanwhile.
+	withIdempotency := func(next authedHandler) authedHandler {
+		return func(w http.ResponseWriter, r *http.Request, accountID string) {
+			if r.Method == http.MethodGet || r.Method == http.MethodHead {
+				next(w, r, accountID)
+				return
+			}
+			idempotency.Serve(store, w, r, accountID, maxJSONBodyBytes, func(w http.ResponseWriter, r *http.Request) {
+				next(w, r, accountID)
+			})
+		}
+	}
+
+	// Library, one page at a time: ?cursor= continues from nextCursor.
+	// ?mode=bedtime lists only calm, short stories while the account's
+	// bedtime window is open; see bedtime.go.
 	mux.HandleFunc("/api/v1/library", withUnlock(func(w http.ResponseWriter, r *http.Request, accountID string) {
 		if r.Method != http.MethodGet {
 			methodNotAllowed(w, []string{http.MethodGet})
 			return
 		}
+		page, ok := model.ParsePageRequest(r.URL.Query())
+		if !ok {
+			writeErr(w, http.StatusBadRequest, "page_invalid", "page limit must be a positive integer and total true or false")
+			return
+		}
+		fields, ok := parseFields(w, r, model.StoryItem{})
+		if !ok {
+			return
+		}
+		filter, ok := libraryFilter(w, r, cfg.Phonics)
+		if !ok {
+			return
+		}
+		if filter.BedtimeMaxWords, ok = libraryBedtime(store, w, r, accountID, time.Now()); !ok {
+			return
+		}
 
-		library, err := store.Library(accountID)
+		library, err := store.Library(r.Context(), accountID, page, filter)
+		if errors.Is(err, model.ErrInvalidCursor) {
+			writeErr(w, http.StatusBadRequest, "cursor_invalid", "page cursor is invalid")
+			return
+		}
 		if err != nil {
 			writeErr(w, http.StatusInternalServerError, "db", "library query failed")
 			return
 		}
 
+		library.Bedtime = filter.BedtimeMaxWords > 0
+
 		noStore(w)
-		writeJSON(w, http.StatusOK, library)
+		writeSelected(w, fields, library)
 	}))
 
-	// Reader 2: one coherent published-version payload.
+	// Reader 2: one coherent published-version payload, and the vocabulary
+	// stored with that version at /api/v1/reader/{slug}/vocabulary. Both are
+	// cached by the browser and revalidated by ETag.
+	// ?mode=kid leaves out asides; the default grown-up view keeps them.
+	// ?include=meta sends only the story's metadata and ?include=segments
+	// its segments without HTML, for slow connections and outline views.
+	// /api/v1/reader/{slug}.html, or an Accept that prefers text/html, is
+	// the story as a standalone page, and /api/v1/reader/{slug}.epub is the
+	// story as an EPUB book.
 	mux.HandleFunc("/api/v1/reader/", withUnlock(func(w http.ResponseWriter, r *http.Request, accountID string) {
 		if r.Method != http.MethodGet {
 			methodNotAllowed(w, []string{http.MethodGet})
@@ -210,12 +348,56 @@ func New(cfg Config, store Store) http.Handler {
 			writeErr(w, http.StatusBadRequest, "slug", "missing slug")
 			return
 		}
-		if strings.Contains(slug, "/") {
+		if storySlug, ok := strings.CutSuffix(slug, "/vocabulary"); ok && storySlug != "" && !strings.Contains(storySlug, "/") {
+			vocabulary, err := store.ReaderVocabulary(r.Context(), accountID, storySlug)
+			if errors.Is(err, model.ErrStoryRemoved) {
+				writeErr(w, http.StatusGone, "story_removed", "story was removed")
+				return
+			}
+			if errors.Is(err, sql.ErrNoRows) {
+				writeErr(w, http.StatusNotFound, "not_found", "story vocabulary not found")
+				return
+			}
+			if err != nil {
+				writeErr(w, http.StatusInternalServerError, "db", "vocabulary query failed")
+				return
+			}
+
+			writeRevalidatedJSON(w, r, vocabulary)
+			return
+		}
+		// The same URL is JSON or a page depending on Accept, so caches must
+		// key on it; the .html form is always a page.
+		asHTML, asEPUB := false, false
+		if storySlug, ok := strings.CutSuffix(slug, ".html"); ok {
+			slug, asHTML = storySlug, true
+		} else if storySlug, ok := strings.CutSuffix(slug, ".epub"); ok {
+			slug, asEPUB = storySlug, true
+		} else {
+			w.Header().Add("Vary", "Accept")
+			asHTML = prefersHTML(r.Header.Get("Accept"))
+		}
+		if slug == "" || strings.Contains(slug, "/") {
 			writeErr(w, http.StatusNotFound, "not_found", "reader story not found")
 			return
 		}
 
-		p, err := store.ReaderStory(accountID, slug)
+		kidMode, ok := readerKidMode(r.URL.Query().Get("mode"))
+		if !ok {
+			writeErr(w, http.StatusBadRequest, "mode", "mode must be kid or grownup")
+			return
+		}
+		include, ok := parseInclusion(r.URL.Query().Get("include"))
+		if !ok {
+			writeErr(w, http.StatusBadRequest, "include_invalid", "include must list meta, segments or html")
+			return
+		}
+
+		p, err := store.ReaderStory(r.Context(), accountID, slug)
+		if errors.Is(err, model.ErrStoryRemoved) {
+			writeErr(w, http.StatusGone, "story_removed", "story was removed")
+			return
+		}
 		if errors.Is(err, sql.ErrNoRows) {
 			writeErr(w, http.StatusNotFound, "not_found", "story not found")
 			return
@@ -224,13 +406,133 @@ func New(cfg Config, store Store) http.Handler {
 			writeErr(w, http.StatusInternalServerError, "db", "reader query failed")
 			return
 		}
+		if kidMode {
+			p.Segments = withoutAsides(p.Segments)
+			if len(p.Segments) == 0 {
+				writeErr(w, http.StatusNotFound, "not_found", "story has nothing to read in kid mode")
+				return
+			}
+		}
 
-		noStore(w)
-		writeJSON(w, http.StatusOK, p)
+		if asEPUB {
+			writeReaderEPUB(store, cfg.Blobs, w, r, accountID, p)
+			return
+		}
+		if asHTML {
+			w.Header().Set("Content-Security-Policy", storyPagePolicy)
+			writeRevalidatedSince(w, r, readerStoryETag(p, kidMode, "html"), p.ChangedAt, "text/html; charset=utf-8", func(out io.Writer, _ func()) error {
+				return encodeReaderStoryHTML(out, p)
+			})
+			return
+		}
+		writeRevalidatedSince(w, r, readerStoryETag(p, kidMode, include.String()), p.ChangedAt, "application/json", func(out io.Writer, flush func()) error {
+			return encodeReaderStory(out, p, include, flush)
+		})
+	}))
+
+	// Stories found by meaning rather than shared words; see search.go.
+	mux.HandleFunc("/api/v1/search/semantic", withUnlock(func(w http.ResponseWriter, r *http.Request, accountID string) {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w, []string{http.MethodGet})
+			return
+		}
+		serveSemanticSearch(store, cfg.Embedder, w, r, accountID)
+	}))
+
+	// Child-safe definitions of tapped words; see define.go.
+	mux.HandleFunc("/api/v1/define", withUnlock(func(w http.ResponseWriter, r *http.Request, _ string) {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w, []string{http.MethodGet})
+			return
+		}
+		serveDefine(store, cfg.Dictionary, w, r)
+	}))
+
+	// The phonics progression whose sets the Library's decodable filter
+	// counts; see phonics.go.
+	mux.HandleFunc("/api/v1/phonics", withUnlock(func(w http.ResponseWriter, r *http.Request, _ string) {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w, []string{http.MethodGet})
+			return
+		}
+		servePhonics(cfg.Phonics, w, r)
+	}))
+
+	// Several Reader payloads at once; see serveStoryBatch.
+	mux.HandleFunc("/api/v1/stories/batch", withUnlock(func(w http.ResponseWriter, r *http.Request, accountID string) {
+		if r.Method != http.MethodPost {
+			methodNotAllowed(w, []string{http.MethodPost})
+			return
+		}
+		serveStoryBatch(store, w, r, accountID)
+	}))
+
+	// Story images referenced as media:<id>. Media rows are immutable, so a
+	// browser may keep them for as long as it likes.
+	mux.HandleFunc("/api/v1/media/", withUnlock(func(w http.ResponseWriter, r *http.Request, accountID string) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			methodNotAllowed(w, []string{http.MethodGet, http.MethodHead})
+			return
+		}
+
+		id := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1/media/"), "/")
+		media, data, err := store.Media(r.Context(), accountID, id)
+		if errors.Is(err, model.ErrMediaNotFound) {
+			writeErr(w, http.StatusNotFound, "not_found", "media not found")
+			return
+		}
+		if err != nil {
+			writeErr(w, http.StatusInternalServerError, "db", "media query failed")
+			return
+		}
+
+		w.Header().Set("Content-Type", media.ContentType)
+		w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+		w.Header().Set("Cache-Control", "private, max-age=31536000, immutable")
+		w.Header().Set("Content-Security-Policy", "default-src 'none'")
+		w.WriteHeader(http.StatusOK)
+		if r.Method == http.MethodGet {
+			_, _ = w.Write(data)
+		}
+	}))
+
+	// Segment narration, linked from the Reader payload. A recording is never
+	// changed in place, and ServeContent answers the range requests audio
+	// elements make while seeking. One kept in a signing blob store is
+	// redirected there instead.
+	mux.HandleFunc("/api/v1/audio/", withUnlock(func(w http.ResponseWriter, r *http.Request, accountID string) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			methodNotAllowed(w, []string{http.MethodGet, http.MethodHead})
+			return
+		}
+
+		id := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1/audio/"), "/")
+		audio, err := store.SegmentAudio(r.Context(), accountID, id)
+		if errors.Is(err, model.ErrAudioNotFound) {
+			writeErr(w, http.StatusNotFound, "not_found", "audio not found")
+			return
+		}
+		if err != nil {
+			writeErr(w, http.StatusInternalServerError, "db", "audio query failed")
+			return
+		}
+
+		if audio.BlobKey != "" {
+			data, ok := serveAudioBlob(cfg.Blobs, w, r, audio.BlobKey)
+			if !ok {
+				return
+			}
+			audio.Data = data
+		}
+
+		w.Header().Set("Content-Type", audio.ContentType)
+		w.Header().Set("Cache-Control", "private, max-age=31536000, immutable")
+		w.Header().Set("Content-Security-Policy", "default-src 'none'")
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(audio.Data))
 	}))
 
 	// Progress
-	mux.HandleFunc("/api/v1/progress/", withUnlock(func(w http.ResponseWriter, r *http.Request, accountID string) {
+	mux.HandleFunc("/api/v1/progress/", withUnlock(withIdempotency(func(w http.ResponseWriter, r *http.Request, accountID string) {
 		slug := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1/progress/"), "/")
 		if slug == "" {
 			writeErr(w, http.StatusBadRequest, "slug", "missing slug")
@@ -239,7 +541,11 @@ func New(cfg Config, store Store) http.Handler {
 
 		switch r.Method {
 		case http.MethodGet:
-			st, err := store.ProgressGet(accountID, slug)
+			st, err := store.ProgressGet(r.Context(), accountID, slug)
+			if errors.Is(err, model.ErrStoryRemoved) {
+				writeErr(w, http.StatusGone, "story_removed", "story was removed")
+				return
+			}
 			if errors.Is(err, sql.ErrNoRows) {
 				writeErr(w, http.StatusNotFound, "not_found", "story not found")
 				return
@@ -264,27 +570,44 @@ func New(cfg Config, store Store) http.Handler {
 				return
 			}
 			if body.Version <= 0 {
-				writeErr(w, http.StatusBadRequest, "version", "version must be > 0")
+				writeFields(w, http.StatusBadRequest, "version", "version must be > 0", []model.FieldError{
+					{Path: "version", Code: "out_of_range", Message: "version must be > 0"},
+				})
 				return
 			}
 			if body.Locator == nil {
-				writeErr(w, http.StatusBadRequest, "locator_invalid", "locator is required")
+				writeFields(w, http.StatusBadRequest, "locator_invalid", "locator is required", []model.FieldError{
+					{Path: "locator", Code: "required", Message: "locator is required"},
+				})
 				return
 			}
 			if err := body.Locator.Validate(); err != nil {
-				writeErr(w, http.StatusBadRequest, "locator_invalid", "invalid Reader locator")
+				field := model.FieldError{Path: "locator", Code: "invalid", Message: err.Error()}
+				var locatorErr *readercontract.LocatorError
+				if errors.As(err, &locatorErr) {
+					field.Path += "." + locatorErr.Field
+				}
+				writeFields(w, http.StatusBadRequest, "locator_invalid", "invalid Reader locator", []model.FieldError{field})
 				return
 			}
 			if body.Percent == nil {
-				writeErr(w, http.StatusBadRequest, "percent", "percent is required")
+				writeFields(w, http.StatusBadRequest, "percent", "percent is required", []model.FieldError{
+					{Path: "percent", Code: "required", Message: "percent is required"},
+				})
 				return
 			}
 			if *body.Percent < 0 || *body.Percent > 1 {
-				writeErr(w, http.StatusBadRequest, "percent", "percent must be between 0 and 1")
+				writeFields(w, http.StatusBadRequest, "percent", "percent must be between 0 and 1", []model.FieldError{
+					{Path: "percent", Code: "out_of_range", Message: "percent must be between 0 and 1"},
+				})
 				return
 			}
 
-			err := store.ProgressPut(accountID, slug, body.Version, *body.Locator, *body.Percent)
+			err := store.ProgressPut(r.Context(), accountID, slug, body.Version, *body.Locator, *body.Percent)
+			if errors.Is(err, model.ErrStoryRemoved) {
+				writeErr(w, http.StatusGone, "story_removed", "story was removed")
+				return
+			}
 			if errors.Is(err, sql.ErrNoRows) {
 				writeErr(w, http.StatusNotFound, "not_found", "story/version not found")
 				return
@@ -306,9 +629,170 @@ func New(cfg Config, store Store) http.Handler {
 			methodNotAllowed(w, []string{http.MethodGet, http.MethodPut})
 			return
 		}
+	})))
+
+	// Read-along: one device drives a story, the others follow it live.
+	readAlong := newReadAlongHub()
+	mux.HandleFunc("/api/v1/ws/read-along/", withUnlock(func(w http.ResponseWriter, r *http.Request, accountID string) {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w, []string{http.MethodGet})
+			return
+		}
+		slug := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1/ws/read-along/"), "/")
+		if slug == "" || strings.Contains(slug, "/") {
+			writeErr(w, http.StatusBadRequest, "slug", "missing slug")
+			return
+		}
+		readAlong.serveReadAlong(w, r, accountID, slug)
 	}))
 
-	// Continue (top N recent)
+	// Emailing stories to readers' devices, such as a Kindle's
+	// Send-to-Kindle address; see delivery.go. GET /api/v1/story/{slug}/quiz
+	// is the story's comprehension quiz; see quiz.go, and GET
+	// /api/v1/story/{slug}/discussion its conversation prompts; see
+	// discussion.go. POST and DELETE /api/v1/story/{slug}/reaction set and
+	// take back the profile's reaction; see reaction.go.
+	mux.HandleFunc("/api/v1/story/", withUnlock(withIdempotency(func(w http.ResponseWriter, r *http.Request, accountID string) {
+		path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1/story/"), "/")
+		if slug, ok := strings.CutSuffix(path, "/quiz"); ok && slug != "" && !strings.Contains(slug, "/") {
+			if r.Method != http.MethodGet {
+				methodNotAllowed(w, []string{http.MethodGet})
+				return
+			}
+			serveStoryQuiz(store, w, r, accountID, slug)
+			return
+		}
+		if slug, ok := strings.CutSuffix(path, "/discussion"); ok && slug != "" && !strings.Contains(slug, "/") {
+			if r.Method != http.MethodGet {
+				methodNotAllowed(w, []string{http.MethodGet})
+				return
+			}
+			serveStoryDiscussion(store, w, r, accountID, slug)
+			return
+		}
+		if slug, ok := strings.CutSuffix(path, "/reaction"); ok && slug != "" && !strings.Contains(slug, "/") {
+			serveStoryReaction(store, w, r, accountID, slug)
+			return
+		}
+		slug, ok := strings.CutSuffix(path, "/send")
+		if !ok || slug == "" || strings.Contains(slug, "/") {
+			writeErr(w, http.StatusNotFound, "not_found", "route not found")
+			return
+		}
+		if r.Method != http.MethodPost {
+			methodNotAllowed(w, []string{http.MethodPost})
+			return
+		}
+		serveStorySend(store, cfg.Delivery, w, r, accountID, slug)
+	})))
+
+	mux.HandleFunc("/api/v1/delivery-destinations", withUnlock(withIdempotency(func(w http.ResponseWriter, r *http.Request, accountID string) {
+		serveDeliveryDestinations(store, w, r, accountID)
+	})))
+
+	mux.HandleFunc("/api/v1/delivery-destinations/", withUnlock(func(w http.ResponseWriter, r *http.Request, accountID string) {
+		if r.Method != http.MethodDelete {
+			methodNotAllowed(w, []string{http.MethodDelete})
+			return
+		}
+		id := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1/delivery-destinations/"), "/")
+		serveDeliveryDestinationDelete(store, w, r, accountID, id)
+	}))
+
+	mux.HandleFunc("/api/v1/deliveries", withUnlock(func(w http.ResponseWriter, r *http.Request, accountID string) {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w, []string{http.MethodGet})
+			return
+		}
+		serveStoryDeliveries(store, w, r, accountID, "")
+	}))
+
+	mux.HandleFunc("/api/v1/deliveries/", withUnlock(func(w http.ResponseWriter, r *http.Request, accountID string) {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w, []string{http.MethodGet})
+			return
+		}
+		id := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1/deliveries/"), "/")
+		if id == "" {
+			writeErr(w, http.StatusNotFound, "not_found", "delivery not found")
+			return
+		}
+		serveStoryDeliveries(store, w, r, accountID, id)
+	}))
+
+	// Web Push: bedtime reminders and new-story notifications; see push.go.
+	mux.HandleFunc("/api/v1/push/key", withUnlock(func(w http.ResponseWriter, r *http.Request, accountID string) {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w, []string{http.MethodGet})
+			return
+		}
+		servePushKey(cfg.Push, w)
+	}))
+
+	mux.HandleFunc("/api/v1/push/subscribe", withUnlock(withIdempotency(func(w http.ResponseWriter, r *http.Request, accountID string) {
+		if r.Method != http.MethodPost {
+			methodNotAllowed(w, []string{http.MethodPost})
+			return
+		}
+		servePushSubscribe(store, cfg.Push, w, r, accountID)
+	})))
+
+	mux.HandleFunc("/api/v1/push/unsubscribe", withUnlock(func(w http.ResponseWriter, r *http.Request, accountID string) {
+		if r.Method != http.MethodPost {
+			methodNotAllowed(w, []string{http.MethodPost})
+			return
+		}
+		servePushUnsubscribe(store, w, r, accountID)
+	}))
+
+	mux.HandleFunc("/api/v1/push/settings", withUnlock(func(w http.ResponseWriter, r *http.Request, accountID string) {
+		servePushSettings(store, w, r, accountID)
+	}))
+
+	// Reader events, kept as daily counts; see events.go. A retried batch
+	// sent with its Idempotency-Key is counted once.
+	mux.HandleFunc("/api/v1/events", withUnlock(withIdempotency(func(w http.ResponseWriter, r *http.Request, accountID string) {
+		if r.Method != http.MethodPost {
+			methodNotAllowed(w, []string{http.MethodPost})
+			return
+		}
+		serveClientEvents(store, w, r, accountID)
+	})))
+
+	// The weekly reading digest email; see digest.go.
+	mux.HandleFunc("/api/v1/digest/settings", withUnlock(func(w http.ResponseWriter, r *http.Request, accountID string) {
+		serveDigestSettings(store, w, r, accountID)
+	}))
+
+	mux.HandleFunc("/api/v1/digest/report", withUnlock(func(w http.ResponseWriter, r *http.Request, accountID string) {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w, []string{http.MethodGet})
+			return
+		}
+		serveReadingReport(store, w, r, accountID)
+	}))
+
+	// The window for the Library's ?mode=bedtime; see bedtime.go.
+	mux.HandleFunc("/api/v1/bedtime/settings", withUnlock(func(w http.ResponseWriter, r *http.Request, accountID string) {
+		serveBedtimeSettings(store, w, r, accountID)
+	}))
+
+	// One child's reading in depth; see dashboard.go.
+	mux.HandleFunc("/api/v1/children/", withUnlock(func(w http.ResponseWriter, r *http.Request, accountID string) {
+		path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1/children/"), "/")
+		childID, ok := strings.CutSuffix(path, "/dashboard")
+		if !ok || childID == "" || strings.Contains(childID, "/") {
+			writeErr(w, http.StatusNotFound, "not_found", "route not found")
+			return
+		}
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w, []string{http.MethodGet})
+			return
+		}
+		serveChildDashboard(store, w, r, accountID, childID)
+	}))
+
+	// Continue (top N recent), with the up-next queue for the home screen
 	mux.HandleFunc("/api/v1/continue", withUnlock(func(w http.ResponseWriter, r *http.Request, accountID string) {
 		if r.Method != http.MethodGet {
 			methodNotAllowed(w, []string{http.MethodGet})
@@ -328,7 +812,12 @@ func New(cfg Config, store Store) http.Handler {
 			limit = maxContinueLim
 		}
 
-		items, err := store.ContinueRecent(accountID, limit)
+		fields, ok := parseFields(w, r, model.ContinueItem{})
+		if !ok {
+			return
+		}
+
+		items, err := store.ContinueRecent(r.Context(), accountID, limit)
 		if err != nil {
 			// For v1: treat "no rows" as empty list; anything else is 500.
 			if errors.Is(err, sql.ErrNoRows) {
@@ -338,16 +827,40 @@ func New(cfg Config, store Store) http.Handler {
 				return
 			}
 		}
+		upNext, err := store.Queue(r.Context(), accountID)
+		if err != nil {
+			writeErr(w, http.StatusInternalServerError, "db", "queue query failed")
+			return
+		}
+		if upNext == nil {
+			upNext = []model.QueueItem{}
+		}
 
 		noStore(w)
-		writeJSON(w, http.StatusOK, map[string]any{"items": items})
+		out := model.ContinueResponse{UpNext: upNext}
+		out.Items = items
+		writeSelected(w, fields, out)
 	}))
 
+	// The up-next queue a parent lines up; see queue.go.
+	mux.HandleFunc("/api/v1/queue", withUnlock(withIdempotency(func(w http.ResponseWriter, r *http.Request, accountID string) {
+		serveQueue(store, w, r, accountID)
+	})))
+
+	mux.HandleFunc("/api/v1/queue/", withUnlock(withIdempotency(func(w http.ResponseWriter, r *http.Request, accountID string) {
+		slug := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1/queue/"), "/")
+		if slug == "" || strings.Contains(slug, "/") {
+			writeErr(w, http.StatusNotFound, "not_found", "route not found")
+			return
+		}
+		serveQueueItem(store, w, r, accountID, slug)
+	})))
+
 	// Settings / Journey
 	mux.HandleFunc("/api/v1/settings", withUnlock(func(w http.ResponseWriter, r *http.Request, accountID string) {
 		switch r.Method {
 		case http.MethodGet:
-			out, err := store.SettingsGet(accountID)
+			out, err := store.SettingsGet(r.Context(), accountID)
 			if err != nil {
 				if errors.Is(err, sql.ErrNoRows) {
 					out = model.SettingsPayload{}
@@ -366,7 +879,7 @@ func New(cfg Config, store Store) http.Handler {
 				writeDecodeError(w, err)
 				return
 			}
-			out, err := store.SettingsPut(accountID, body)
+			out, err := store.SettingsPut(r.Context(), accountID, body)
 			if err != nil {
 				writeErr(w, http.StatusInternalServerError, "db", "settings update failed")
 				return
@@ -382,7 +895,7 @@ func New(cfg Config, store Store) http.Handler {
 	}))
 
 	// middleware wrapping
-	h := withSecurityHeaders(mux)
+	h := withSecurityHeaders(withMaintenance(cfg, withRateLimit(cfg, mux)))
 
 	return h
 }
@@ -401,10 +914,145 @@ func validPasscode(passcode string) bool {
 	return true
 }
 
+// readerKidMode reads the Reader mode parameter. An empty value is the
+// grown-up view.
+func readerKidMode(mode string) (bool, bool) {
+	switch mode {
+	case "kid":
+		return true, true
+	case "", "grownup":
+		return false, true
+	}
+	return false, false
+}
+
+// readerStoryETag names one representation of a story as of p.ChangedAt.
+// The triggers that keep that time move it on every change the Reader could
+// show, so the tag is known before the body is encoded and the body is
+// encoded once.
+func readerStoryETag(p model.ReaderStory, kidMode bool, representation string) string {
+	key := strings.Join([]string{
+		p.Slug,
+		strconv.Itoa(p.Version),
+		strconv.FormatInt(p.ChangedAt.UnixMicro(), 10),
+		strconv.FormatBool(kidMode),
+		representation,
+	}, "\x00")
+	sum := sha256.Sum256([]byte(key))
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// withoutAsides drops grown-up asides. Ordinals keep their gaps, so progress
+// saved in either view names the same segments.
+func withoutAsides(segments []model.ReaderSegment) []model.ReaderSegment {
+	kept := make([]model.ReaderSegment, 0, len(segments))
+	for _, segment := range segments {
+		if segment.Kind != string(readercontract.SegmentKindAside) {
+			kept = append(kept, segment)
+		}
+	}
+	return kept
+}
+
 func noStore(w http.ResponseWriter) {
 	w.Header().Set("Cache-Control", "no-store")
 }
 
+// writeRevalidatedJSON serves published content a browser may keep but must
+// revalidate: the Reader URL does not name a version, and the title, credits
+// and the account's hyphenation can change without a new one. The ETag hashes
+// the body, so an unchanged book answers 304 instead of re-sending segments.
+func writeRevalidatedJSON(w http.ResponseWriter, r *http.Request, v any) {
+	writeRevalidated(w, r, "application/json", func(out io.Writer, _ func()) error {
+		return json.NewEncoder(out).Encode(v)
+	})
+}
+
+// writeRevalidated runs encode twice: once into a hash for the ETag and
+// length, then into the response, flushing where encode asks. Neither pass
+// holds the whole body.
+func writeRevalidated(w http.ResponseWriter, r *http.Request, contentType string, encode func(out io.Writer, flush func()) error) {
+	sum := sha256.New()
+	digest := &countingWriter{w: sum}
+	if err := encode(digest, func() {}); err != nil {
+		writeErr(w, http.StatusInternalServerError, "encode", "response encoding failed")
+		return
+	}
+	if revalidated(w, r, `"`+hex.EncodeToString(sum.Sum(nil)[:16])+`"`, time.Time{}) {
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(digest.n, 10))
+	w.WriteHeader(http.StatusOK)
+	controller := http.NewResponseController(w)
+	_ = encode(w, func() { _ = controller.Flush() })
+}
+
+// writeRevalidatedSince serves content whose ETag the caller already knows,
+// encoding it once straight into the response. It also sends when the
+// content last changed as Last-Modified and answers a matching
+// If-Modified-Since with 304, so clients and caches that revalidate by date
+// need not download the body. If-None-Match, when sent, takes precedence.
+func writeRevalidatedSince(w http.ResponseWriter, r *http.Request, etag string, changedAt time.Time, contentType string, encode func(out io.Writer, flush func()) error) {
+	if revalidated(w, r, etag, changedAt) {
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+	controller := http.NewResponseController(w)
+	_ = encode(w, func() { _ = controller.Flush() })
+}
+
+// revalidated sets the validators and caching headers, and answers 304 when
+// the request's conditions show the client already has this content. It
+// reports whether it did.
+func revalidated(w http.ResponseWriter, r *http.Request, etag string, changedAt time.Time) bool {
+	// Last-Modified counts whole seconds, so a second change within the
+	// second it names would go unseen; it is only sent once that second has
+	// passed.
+	lastModified := changedAt.UTC().Truncate(time.Second)
+	if changedAt.IsZero() || time.Since(changedAt) < time.Second {
+		lastModified = time.Time{}
+	}
+
+	w.Header().Set("Cache-Control", "private, no-cache")
+	w.Header().Set("ETag", etag)
+	if !lastModified.IsZero() {
+		w.Header().Set("Last-Modified", lastModified.Format(http.TimeFormat))
+	}
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" {
+		if etagMatches(ifNoneMatch, etag) {
+			w.WriteHeader(http.StatusNotModified)
+			return true
+		}
+	} else if notModifiedSince(r.Header.Get("If-Modified-Since"), lastModified) {
+		w.WriteHeader(http.StatusNotModified)
+		return true
+	}
+	return false
+}
+
+// notModifiedSince reports whether content last modified at lastModified is
+// unchanged since the If-Modified-Since date. It is false without either.
+func notModifiedSince(ifModifiedSince string, lastModified time.Time) bool {
+	if ifModifiedSince == "" || lastModified.IsZero() {
+		return false
+	}
+	since, err := http.ParseTime(ifModifiedSince)
+	return err == nil && !lastModified.After(since)
+}
+
+// etagMatches applies If-None-Match's weak comparison.
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
 func methodNotAllowed(w http.ResponseWriter, allow []string) {
 	w.Header().Set("Allow", strings.Join(allow, ", "))
 	writeErr(w, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed")
@@ -414,7 +1062,14 @@ func decodeJSON(w http.ResponseWriter, r *http.Request, dst any) error {
 	r.Body = http.MaxBytesReader(w, r.Body, maxJSONBodyBytes)
 	defer r.Body.Close()
 
-	dec := json.NewDecoder(r.Body)
+	raw, err := io.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	if err := httpmiddleware.CheckJSON(raw, dst); err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
 	dec.DisallowUnknownFields()
 
 	if err := dec.Decode(dst); err != nil {
@@ -434,19 +1089,50 @@ func writeDecodeError(w http.ResponseWriter, err error) {
 		writeErr(w, http.StatusRequestEntityTooLarge, "body_too_large", "request body too large")
 		return
 	}
-	writeErr(w, http.StatusBadRequest, "bad_json", err.Error())
+	writeFields(w, http.StatusBadRequest, "bad_json", err.Error(), httpmiddleware.DecodeFieldErrors(err))
 }
 
 func writeErr(w http.ResponseWriter, status int, code string, msg string) {
 	noStore(w)
 	writeJSON(w, status, map[string]any{
-		"error": map[string]any{
-			"code":    code,
-			"message": msg,
-		},
+		"error": httpmiddleware.ErrorBody(w, code, msg),
+	})
+}
+
+// writeFields is writeErr naming the inputs at fault, so the client can
+// highlight them.
+func writeFields(w http.ResponseWriter, status int, code string, msg string, fields []model.FieldError) {
+	noStore(w)
+	body := httpmiddleware.ErrorBody(w, code, msg)
+	if len(fields) > 0 {
+		body["fields"] = fields
+	}
+	writeJSON(w, status, map[string]any{
+		"error": body,
 	})
 }
 
+// parseFields reads a list's ?fields= selection of item's fields, answering
+// 400 itself when it names fields item does not have.
+func parseFields(w http.ResponseWriter, r *http.Request, item any) (model.FieldSelection, bool) {
+	fields, problems := model.ParseFieldSelection(r.URL.Query(), item)
+	if problems != nil {
+		writeFields(w, http.StatusBadRequest, "fields_invalid", "fields names unknown item fields", problems)
+		return model.FieldSelection{}, false
+	}
+	return fields, true
+}
+
+// writeSelected writes a list response with its items cut down to fields.
+func writeSelected(w http.ResponseWriter, fields model.FieldSelection, v any) {
+	out, err := fields.Project(v)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, "encode", "response encoding failed")
+		return
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
 func writeJSON(w http.ResponseWriter, status int, v any) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
@@ -2,10 +2,13 @@ package httpapi
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"slices"
 	"strings"
 	"testing"
 	"time"
@@ -34,13 +37,30 @@ type authTestStore struct {
 	readinessCalls   int
 	libraryCalls     int
 	libraryAccount   string
+	libraryCtx       context.Context
 	libraryResponse  model.LibraryReadModel
+	libraryPage      model.PageRequest
+	libraryFilter    model.LibraryFilter
 	libraryErr       error
 	readerCalls      int
 	readerAccount    string
 	readerSlug       string
 	readerResponse   model.ReaderStory
 	readerErr        error
+	// readerStories, when set, answers by slug; other slugs are not found.
+	readerStories    map[string]model.ReaderStory
+	vocabularySlug   string
+	vocabulary       model.Vocabulary
+	vocabularyErr    error
+	quizSlug         string
+	quiz             model.StoryQuiz
+	quizErr          error
+	discussionSlug   string
+	discussion       model.StoryDiscussion
+	discussionErr    error
+	dictionaryEntry  *model.DictionaryEntry
+	dictionaryErr    error
+	dictionarySaved  []*model.Definition
 	progressGetCalls int
 	progressGetState model.ProgressResponse
 	progressGetErr   error
@@ -51,9 +71,46 @@ type authTestStore struct {
 	progressLocator  readercontract.Locator
 	progressPercent  float64
 	progressPutErr   error
+	mediaAccount     string
+	mediaID          string
+	media            model.Media
+	mediaData        []byte
+	mediaErr         error
+	audioID          string
+	audio            model.NarrationAudio
+	audioErr         error
+	idempotent       map[string]model.IdempotentResponse
+	destinations     []model.DeliveryDestination
+	destinationErr   error
+	sendSlug         string
+	sendDestination  string
+	delivery         model.StoryDelivery
+	deliveryErr      error
+	searchModel      string
+	searchQuery      []float32
+	searchLimit      int
+	searchResults    []model.SemanticSearchResult
+	pushSaved        []model.PushSubscriptionSave
+	pushExists       bool
+	pushErr          error
+	pushSettings     *model.PushSettings
+	digestSettings   *model.DigestSettings
+	digestErr        error
+	report           model.ReadingReport
+	events           []model.ClientEvent
+	eventsErr        error
+	queue            []model.QueueItem
+	queueErr         error
+	reaction         *model.StoryReaction
+	reactionErr      error
+	dashboardChild   string
+	dashboard        model.ChildDashboard
+	dashboardErr     error
+	bedtimeSettings  *model.BedtimeSettings
+	bedtimeErr       error
 }
 
-func (s *authTestStore) EnsureDefaultAccount() (string, error) {
+func (s *authTestStore) EnsureDefaultAccount(_ context.Context) (string, error) {
 	s.ensureCalls++
 	if s.ensureErr != nil {
 		return "", s.ensureErr
@@ -64,7 +121,7 @@ func (s *authTestStore) EnsureDefaultAccount() (string, error) {
 	return s.accountID, nil
 }
 
-func (s *authTestStore) AccountExists(accountID string) (bool, error) {
+func (s *authTestStore) AccountExists(_ context.Context, accountID string) (bool, error) {
 	s.existsCalls++
 	if s.accountExistsErr != nil {
 		return false, s.accountExistsErr
@@ -80,9 +137,12 @@ func (s *authTestStore) CheckReadiness(ctx context.Context) error {
 	return s.readinessErr
 }
 
-func (s *authTestStore) Library(accountID string) (model.LibraryReadModel, error) {
+func (s *authTestStore) Library(ctx context.Context, accountID string, page model.PageRequest, filter model.LibraryFilter) (model.LibraryReadModel, error) {
 	s.libraryCalls++
+	s.libraryCtx = ctx
 	s.libraryAccount = accountID
+	s.libraryPage = page
+	s.libraryFilter = filter
 	if s.libraryErr != nil {
 		return model.LibraryReadModel{}, s.libraryErr
 	}
@@ -92,19 +152,71 @@ func (s *authTestStore) Library(accountID string) (model.LibraryReadModel, error
 	return s.libraryResponse, nil
 }
 
-func (s *authTestStore) ReaderStory(accountID, slug string) (model.ReaderStory, error) {
+func (s *authTestStore) ReaderStory(_ context.Context, accountID, slug string) (model.ReaderStory, error) {
 	s.readerCalls++
 	s.readerAccount = accountID
 	s.readerSlug = slug
+	if s.readerStories != nil {
+		story, ok := s.readerStories[slug]
+		if !ok {
+			return model.ReaderStory{}, sql.ErrNoRows
+		}
+		return story, nil
+	}
 	return s.readerResponse, s.readerErr
 }
 
-func (s *authTestStore) ProgressGet(string, string) (model.ProgressResponse, error) {
+func (s *authTestStore) ReaderQuiz(_ context.Context, accountID, slug string) (model.StoryQuiz, error) {
+	s.readerAccount = accountID
+	s.quizSlug = slug
+	return s.quiz, s.quizErr
+}
+
+func (s *authTestStore) ReaderDiscussion(_ context.Context, accountID, slug string) (model.StoryDiscussion, error) {
+	s.readerAccount = accountID
+	s.discussionSlug = slug
+	return s.discussion, s.discussionErr
+}
+
+func (s *authTestStore) DictionaryEntry(context.Context, string, string) (model.DictionaryEntry, error) {
+	if s.dictionaryErr != nil {
+		return model.DictionaryEntry{}, s.dictionaryErr
+	}
+	if s.dictionaryEntry == nil {
+		return model.DictionaryEntry{}, sql.ErrNoRows
+	}
+	return *s.dictionaryEntry, nil
+}
+
+func (s *authTestStore) DictionarySave(_ context.Context, _, _ string, definition *model.Definition) error {
+	s.dictionarySaved = append(s.dictionarySaved, definition)
+	return nil
+}
+
+func (s *authTestStore) ReaderVocabulary(_ context.Context, accountID, slug string) (model.Vocabulary, error) {
+	s.readerAccount = accountID
+	s.vocabularySlug = slug
+	return s.vocabulary, s.vocabularyErr
+}
+
+func (s *authTestStore) Media(_ context.Context, accountID, mediaID string) (model.Media, []byte, error) {
+	s.mediaAccount = accountID
+	s.mediaID = mediaID
+	return s.media, s.mediaData, s.mediaErr
+}
+
+func (s *authTestStore) SegmentAudio(_ context.Context, accountID, audioID string) (model.NarrationAudio, error) {
+	s.mediaAccount = accountID
+	s.audioID = audioID
+	return s.audio, s.audioErr
+}
+
+func (s *authTestStore) ProgressGet(context.Context, string, string) (model.ProgressResponse, error) {
 	s.progressGetCalls++
 	return s.progressGetState, s.progressGetErr
 }
 
-func (s *authTestStore) ProgressPut(accountID, slug string, version int, locator readercontract.Locator, percent float64) error {
+func (s *authTestStore) ProgressPut(_ context.Context, accountID, slug string, version int, locator readercontract.Locator, percent float64) error {
 	s.progressPutCalls++
 	s.progressAccount = accountID
 	s.progressSlug = slug
@@ -114,18 +226,217 @@ func (s *authTestStore) ProgressPut(accountID, slug string, version int, locator
 	return s.progressPutErr
 }
 
-func (*authTestStore) ContinueRecent(string, int) ([]model.ContinueItem, error) {
+func (*authTestStore) ContinueRecent(context.Context, string, int) ([]model.ContinueItem, error) {
 	return nil, nil
 }
 
-func (*authTestStore) SettingsGet(string) (model.SettingsPayload, error) {
+func (s *authTestStore) Queue(context.Context, string) ([]model.QueueItem, error) {
+	return s.queue, s.queueErr
+}
+
+func (s *authTestStore) QueueAdd(_ context.Context, _, slug string) ([]model.QueueItem, bool, error) {
+	if s.queueErr != nil {
+		return nil, false, s.queueErr
+	}
+	for _, item := range s.queue {
+		if item.Slug == slug {
+			return s.queue, false, nil
+		}
+	}
+	s.queue = append(s.queue, model.QueueItem{Slug: slug, Title: strings.ToUpper(slug)})
+	return s.queue, true, nil
+}
+
+func (s *authTestStore) QueueRemove(_ context.Context, _, slug string) ([]model.QueueItem, error) {
+	if s.queueErr != nil {
+		return nil, s.queueErr
+	}
+	for i, item := range s.queue {
+		if item.Slug == slug {
+			s.queue = append(s.queue[:i], s.queue[i+1:]...)
+			return s.queue, nil
+		}
+	}
+	return nil, model.ErrQueueItemNotFound
+}
+
+func (s *authTestStore) QueueReorder(_ context.Context, _ string, slugs []string) ([]model.QueueItem, error) {
+	if s.queueErr != nil {
+		return nil, s.queueErr
+	}
+	if len(slugs) != len(s.queue) {
+		return nil, model.ErrQueueOrder
+	}
+	reordered := make([]model.QueueItem, 0, len(slugs))
+	for _, slug := range slugs {
+		index := slices.IndexFunc(s.queue, func(item model.QueueItem) bool { return item.Slug == slug })
+		if index < 0 {
+			return nil, model.ErrQueueOrder
+		}
+		reordered = append(reordered, s.queue[index])
+	}
+	s.queue = reordered
+	return s.queue, nil
+}
+
+func (s *authTestStore) SetStoryReaction(_ context.Context, _, _ string, reaction *model.StoryReaction) (model.StoryReactionResponse, error) {
+	if s.reactionErr != nil {
+		return model.StoryReactionResponse{}, s.reactionErr
+	}
+	s.reaction = reaction
+	out := model.StoryReactionResponse{Reaction: reaction, Counts: model.ReactionCounts{Silly: 2}}
+	if reaction != nil && *reaction == model.ReactionLovedIt {
+		out.Counts.LovedIt++
+	}
+	return out, nil
+}
+
+func (*authTestStore) SettingsGet(context.Context, string) (model.SettingsPayload, error) {
 	return model.SettingsPayload{}, nil
 }
 
-func (*authTestStore) SettingsPut(_ string, payload model.SettingsUpsert) (model.SettingsPayload, error) {
+func (*authTestStore) SettingsPut(_ context.Context, _ string, payload model.SettingsUpsert) (model.SettingsPayload, error) {
 	return model.SettingsPayload{Child: payload.Child, Prompt: payload.Prompt}, nil
 }
 
+func (s *authTestStore) DeliveryDestinations(context.Context, string) (model.DeliveryDestinationsResponse, error) {
+	return model.DeliveryDestinationsResponse{Items: s.destinations}, s.destinationErr
+}
+
+func (s *authTestStore) CreateDeliveryDestination(_ context.Context, _ string, create model.DeliveryDestinationCreate) (model.DeliveryDestination, error) {
+	if s.destinationErr != nil {
+		return model.DeliveryDestination{}, s.destinationErr
+	}
+	destination := model.DeliveryDestination{ID: "destination-1", Name: create.Name, Address: create.Address}
+	s.destinations = append(s.destinations, destination)
+	return destination, nil
+}
+
+func (s *authTestStore) DeleteDeliveryDestination(_ context.Context, _ string, destinationID string) error {
+	s.sendDestination = destinationID
+	return s.destinationErr
+}
+
+func (s *authTestStore) SendStory(_ context.Context, _ string, slug, destinationID string) (model.StoryDelivery, error) {
+	s.sendSlug = slug
+	s.sendDestination = destinationID
+	return s.delivery, s.deliveryErr
+}
+
+func (s *authTestStore) StoryDelivery(_ context.Context, _ string, deliveryID string) (model.StoryDelivery, error) {
+	if s.deliveryErr != nil || deliveryID != s.delivery.ID {
+		return model.StoryDelivery{}, fmt.Errorf("%w", model.ErrDeliveryNotFound)
+	}
+	return s.delivery, nil
+}
+
+func (s *authTestStore) StoryDeliveries(context.Context, string) (model.StoryDeliveriesResponse, error) {
+	return model.StoryDeliveriesResponse{Items: []model.StoryDelivery{s.delivery}}, s.deliveryErr
+}
+
+func (s *authTestStore) PushSubscribe(_ context.Context, _ string, save model.PushSubscriptionSave) (model.PushSubscription, error) {
+	if s.pushErr != nil {
+		return model.PushSubscription{}, s.pushErr
+	}
+	s.pushSaved = append(s.pushSaved, save)
+	return model.PushSubscription{ID: "push-1", CreatedAt: "2026-10-17T19:00:00Z", Created: !s.pushExists}, nil
+}
+
+func (s *authTestStore) PushUnsubscribe(context.Context, string, string) error {
+	return s.pushErr
+}
+
+func (s *authTestStore) PushSettings(context.Context, string) (model.PushSettings, error) {
+	if s.pushSettings == nil {
+		return model.DefaultPushSettings, nil
+	}
+	return *s.pushSettings, nil
+}
+
+func (s *authTestStore) PutPushSettings(_ context.Context, _ string, settings model.PushSettings) (model.PushSettings, error) {
+	if s.pushErr != nil {
+		return model.PushSettings{}, s.pushErr
+	}
+	s.pushSettings = &settings
+	return settings, nil
+}
+
+func (s *authTestStore) DigestSettings(context.Context, string) (model.DigestSettings, error) {
+	if s.digestSettings == nil {
+		return model.DefaultDigestSettings, nil
+	}
+	return *s.digestSettings, nil
+}
+
+func (s *authTestStore) PutDigestSettings(_ context.Context, _ string, settings model.DigestSettings) (model.DigestSettings, error) {
+	if s.digestErr != nil {
+		return model.DigestSettings{}, s.digestErr
+	}
+	s.digestSettings = &settings
+	return settings, nil
+}
+
+func (s *authTestStore) BedtimeSettings(context.Context, string) (model.BedtimeSettings, error) {
+	if s.bedtimeErr != nil {
+		return model.BedtimeSettings{}, s.bedtimeErr
+	}
+	if s.bedtimeSettings == nil {
+		return model.DefaultBedtimeSettings, nil
+	}
+	return *s.bedtimeSettings, nil
+}
+
+func (s *authTestStore) PutBedtimeSettings(_ context.Context, _ string, settings model.BedtimeSettings) (model.BedtimeSettings, error) {
+	if s.bedtimeErr != nil {
+		return model.BedtimeSettings{}, s.bedtimeErr
+	}
+	s.bedtimeSettings = &settings
+	return settings, nil
+}
+
+func (s *authTestStore) ReadingReport(context.Context, string, time.Time) (model.ReadingReport, error) {
+	return s.report, s.digestErr
+}
+
+func (s *authTestStore) ChildDashboard(_ context.Context, _, childID string, _ time.Time) (model.ChildDashboard, error) {
+	s.dashboardChild = childID
+	return s.dashboard, s.dashboardErr
+}
+
+func (s *authTestStore) RecordClientEvents(_ context.Context, _ string, events []model.ClientEvent) (model.ClientEventsResponse, error) {
+	if s.eventsErr != nil {
+		return model.ClientEventsResponse{}, s.eventsErr
+	}
+	s.events = append(s.events, events...)
+	return model.ClientEventsResponse{Recorded: len(events)}, nil
+}
+
+func (s *authTestStore) SemanticSearch(_ context.Context, _ string, modelName string, query []float32, limit int) (model.SemanticSearchResponse, error) {
+	s.searchModel, s.searchQuery, s.searchLimit = modelName, query, limit
+	return model.SemanticSearchResponse{Items: s.searchResults}, nil
+}
+
+func (s *authTestStore) IdempotencyClaim(_ context.Context, accountID, key, fingerprint string) (model.IdempotentResponse, bool, error) {
+	if prior, ok := s.idempotent[accountID+"/"+key]; ok {
+		return prior, false, nil
+	}
+	if s.idempotent == nil {
+		s.idempotent = map[string]model.IdempotentResponse{}
+	}
+	s.idempotent[accountID+"/"+key] = model.IdempotentResponse{Fingerprint: fingerprint}
+	return model.IdempotentResponse{}, true, nil
+}
+
+func (s *authTestStore) IdempotencySave(_ context.Context, accountID, key string, response model.IdempotentResponse) error {
+	s.idempotent[accountID+"/"+key] = response
+	return nil
+}
+
+func (s *authTestStore) IdempotencyRelease(_ context.Context, accountID, key, _ string) error {
+	delete(s.idempotent, accountID+"/"+key)
+	return nil
+}
+
 func testSessionManager(t *testing.T, secure bool, now func() time.Time) *session.Manager {
 	t.Helper()
 	manager, err := session.New(testSessionSecret, secure, session.WithClock(now))
@@ -0,0 +1,132 @@
+package httpapi
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"pandapages/api/internal/model"
+)
+
+func TestBedtimeSettingsRoundTrip(t *testing.T) {
+	manager := testSessionManager(t, false, func() time.Time { return testSessionTime })
+	store := &authTestStore{accountExists: true}
+	handler := testHandler(t, store, manager)
+
+	response := httptest.NewRecorder()
+	handler.ServeHTTP(response, sessionRequest(t, manager, http.MethodGet, "/api/v1/bedtime/settings"))
+	if response.Code != http.StatusOK || !strings.Contains(response.Body.String(), `"start":"18:30"`) {
+		t.Fatalf("settings = %d; body = %s", response.Code, response.Body.String())
+	}
+
+	request := sessionRequest(t, manager, http.MethodPut, "/api/v1/bedtime/settings")
+	request.Body = io.NopCloser(strings.NewReader(`{"start":" 19:00 ","end":"06:30","timeZone":"Europe/London","maxWords":800}`))
+	response = httptest.NewRecorder()
+	handler.ServeHTTP(response, request)
+	if response.Code != http.StatusOK {
+		t.Fatalf("put = %d; body = %s", response.Code, response.Body.String())
+	}
+	if saved := store.bedtimeSettings; saved == nil || saved.Start != "19:00" || saved.End != "06:30" || saved.MaxWords != 800 {
+		t.Fatalf("saved = %+v", saved)
+	}
+
+	for _, body := range []string{
+		`{"start":"7pm","end":"06:30","timeZone":"UTC","maxWords":800}`,
+		`{"start":"19:00","end":"19:00","timeZone":"UTC","maxWords":800}`,
+		`{"start":"19:00","end":"06:30","timeZone":"Local","maxWords":800}`,
+		`{"start":"19:00","end":"06:30","timeZone":"UTC","maxWords":0}`,
+	} {
+		request := sessionRequest(t, manager, http.MethodPut, "/api/v1/bedtime/settings")
+		request.Body = io.NopCloser(strings.NewReader(body))
+		response := httptest.NewRecorder()
+		handler.ServeHTTP(response, request)
+		if response.Code != http.StatusBadRequest || !strings.Contains(response.Body.String(), "bedtime_settings_invalid") {
+			t.Fatalf("%s = %d; body = %s", body, response.Code, response.Body.String())
+		}
+	}
+}
+
+func TestBedtimeWindowOpen(t *testing.T) {
+	at := func(value string) time.Time {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return parsed
+	}
+	evening := model.BedtimeSettings{Start: "19:00", End: "07:00", TimeZone: "UTC"}
+	nap := model.BedtimeSettings{Start: "13:00", End: "14:30", TimeZone: "UTC"}
+	london := model.BedtimeSettings{Start: "19:00", End: "07:00", TimeZone: "Europe/London"}
+	tests := []struct {
+		settings model.BedtimeSettings
+		now      string
+		want     bool
+	}{
+		{settings: evening, now: "2026-10-17T19:00:00Z", want: true},
+		{settings: evening, now: "2026-10-17T23:59:00Z", want: true},
+		{settings: evening, now: "2026-10-18T06:59:00Z", want: true},
+		{settings: evening, now: "2026-10-18T07:00:00Z", want: false},
+		{settings: evening, now: "2026-10-17T12:00:00Z", want: false},
+		{settings: nap, now: "2026-10-17T13:30:00Z", want: true},
+		{settings: nap, now: "2026-10-17T19:30:00Z", want: false},
+		// 18:30 UTC is 19:30 in London during summer time.
+		{settings: london, now: "2026-07-01T18:30:00Z", want: true},
+		{settings: evening, now: "2026-07-01T18:30:00Z", want: false},
+	}
+	for _, test := range tests {
+		if got := bedtimeWindowOpen(test.settings, at(test.now)); got != test.want {
+			t.Errorf("%s-%s %s at %s = %v, want %v", test.settings.Start, test.settings.End, test.settings.TimeZone, test.now, got, test.want)
+		}
+	}
+}
+
+func TestLibraryBedtimeModeFiltersOnlyInsideTheWindow(t *testing.T) {
+	manager := testSessionManager(t, false, func() time.Time { return testSessionTime })
+	now := time.Now().UTC()
+	window := func(from, to time.Duration) *model.BedtimeSettings {
+		return &model.BedtimeSettings{Start: now.Add(from).Format("15:04"), End: now.Add(to).Format("15:04"), TimeZone: "UTC", MaxWords: 900}
+	}
+	tests := []struct {
+		name     string
+		path     string
+		settings *model.BedtimeSettings
+		maxWords int
+	}{
+		{name: "open", path: "/api/v1/library?mode=bedtime", settings: window(-time.Hour, time.Hour), maxWords: 900},
+		{name: "closed", path: "/api/v1/library?mode=bedtime", settings: window(time.Hour, 2*time.Hour)},
+		{name: "not asked", path: "/api/v1/library", settings: window(-time.Hour, time.Hour)},
+	}
+	for _, test := range tests {
+		store := &authTestStore{accountExists: true, bedtimeSettings: test.settings}
+		response := httptest.NewRecorder()
+		testHandler(t, store, manager).ServeHTTP(response, sessionRequest(t, manager, http.MethodGet, test.path))
+		if response.Code != http.StatusOK || store.libraryFilter.BedtimeMaxWords != test.maxWords {
+			t.Fatalf("%s: status = %d, filter = %+v; body = %s", test.name, response.Code, store.libraryFilter, response.Body)
+		}
+		if got := strings.Contains(response.Body.String(), `"bedtime":true`); got != (test.maxWords > 0) {
+			t.Fatalf("%s: body = %s", test.name, response.Body)
+		}
+	}
+
+	for _, test := range []struct {
+		path   string
+		err    error
+		status int
+		code   string
+	}{
+		{path: "/api/v1/library?mode=naptime", status: http.StatusBadRequest, code: "mode_invalid"},
+		{path: "/api/v1/library?mode=bedtime", err: fmt.Errorf("private failure"), status: http.StatusInternalServerError, code: "db"},
+	} {
+		store := &authTestStore{accountExists: true, bedtimeErr: test.err}
+		response := httptest.NewRecorder()
+		testHandler(t, store, manager).ServeHTTP(response, sessionRequest(t, manager, http.MethodGet, test.path))
+		if response.Code != test.status || !strings.Contains(response.Body.String(), `"code":"`+test.code+`"`) ||
+			strings.Contains(response.Body.String(), "private") || store.libraryCalls != 0 {
+			t.Fatalf("%s: status = %d; body = %s", test.path, response.Code, response.Body)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package httpapi
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"pandapages/api/internal/blob"
+	"pandapages/api/internal/model"
+)
+
+// signingBlobs is an in-memory blob store that signs URLs.
+type signingBlobs struct {
+	objects map[string][]byte
+	puts    int
+}
+
+func (b *signingBlobs) Name() string { return "test" }
+
+func (b *signingBlobs) Put(_ context.Context, key, _ string, body io.Reader, _ int64) error {
+	data, err := io.ReadAll(body)
+	b.objects[key] = data
+	b.puts++
+	return err
+}
+
+func (b *signingBlobs) Get(_ context.Context, key string) (io.ReadCloser, error) {
+	data, ok := b.objects[key]
+	if !ok {
+		return nil, blob.ErrNotFound
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (b *signingBlobs) Exists(_ context.Context, key string) (bool, error) {
+	_, ok := b.objects[key]
+	return ok, nil
+}
+
+func (b *signingBlobs) Delete(_ context.Context, key string) error {
+	delete(b.objects, key)
+	return nil
+}
+
+func (b *signingBlobs) SignedURL(_ context.Context, key string, _ time.Duration, filename string) (string, error) {
+	return "https://blobs.example/" + key + "?download=" + filename, nil
+}
+
+func TestAudioEndpointRedirectsToSignedBlobs(t *testing.T) {
+	manager := testSessionManager(t, false, func() time.Time { return testSessionTime })
+	store := &authTestStore{
+		accountExists: true,
+		audio:         model.NarrationAudio{ContentType: model.NarrationAudioType, BlobKey: "audio/a/one.wav"},
+	}
+	handler := New(Config{Passcode: "123456", Sessions: manager, Blobs: &signingBlobs{}}, store)
+	response := httptest.NewRecorder()
+	handler.ServeHTTP(response, sessionRequest(t, manager, http.MethodGet, "/api/v1/audio/5f0c7f0e-8b7a-4f55-9a43-a0a1b3b1c2d3"))
+
+	if response.Code != http.StatusFound || response.Header().Get("Location") != "https://blobs.example/audio/a/one.wav?download=" {
+		t.Fatalf("status = %d, location %q", response.Code, response.Header().Get("Location"))
+	}
+	if response.Header().Get("Cache-Control") != "no-store" {
+		t.Fatalf("Cache-Control = %q", response.Header().Get("Cache-Control"))
+	}
+}
+
+func TestAudioEndpointServesBlobsThatCannotBeSigned(t *testing.T) {
+	manager := testSessionManager(t, false, func() time.Time { return testSessionTime })
+	dir, err := blob.NewDir(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewDir: %v", err)
+	}
+	data := "RIFFfakeWAVEdata"
+	if err := dir.Put(context.Background(), "audio/a/one.wav", "audio/wav", strings.NewReader(data), int64(len(data))); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	store := &authTestStore{
+		accountExists: true,
+		audio:         model.NarrationAudio{ContentType: model.NarrationAudioType, BlobKey: "audio/a/one.wav"},
+	}
+
+	request := sessionRequest(t, manager, http.MethodGet, "/api/v1/audio/5f0c7f0e-8b7a-4f55-9a43-a0a1b3b1c2d3")
+	request.Header.Set("Range", "bytes=4-7")
+	response := httptest.NewRecorder()
+	New(Config{Passcode: "123456", Sessions: manager, Blobs: dir}, store).ServeHTTP(response, request)
+	if response.Code != http.StatusPartialContent || response.Body.String() != "fake" {
+		t.Fatalf("status = %d; body = %q", response.Code, response.Body.String())
+	}
+
+	response = httptest.NewRecorder()
+	testHandler(t, store, manager).ServeHTTP(response, sessionRequest(t, manager, http.MethodGet, "/api/v1/audio/5f0c7f0e-8b7a-4f55-9a43-a0a1b3b1c2d3"))
+	if response.Code != http.StatusInternalServerError {
+		t.Fatalf("status without a blob store = %d", response.Code)
+	}
+}
+
+func TestReaderEPUBIsStoredOnceAndRedirected(t *testing.T) {
+	manager := testSessionManager(t, false, func() time.Time { return testSessionTime })
+	store := &authTestStore{
+		accountExists: true,
+		readerResponse: model.ReaderStory{Slug: "owls", Title: "Owls", Language: "en", Version: 2, Segments: []model.ReaderSegment{
+			{Ordinal: 1, Kind: "paragraph", RenderedHTML: "<p>Hoot.</p>"},
+		}},
+	}
+	blobs := &signingBlobs{objects: map[string][]byte{}}
+	handler := New(Config{Passcode: "123456", Sessions: manager, Blobs: blobs}, store)
+
+	var location string
+	for range 2 {
+		response := httptest.NewRecorder()
+		handler.ServeHTTP(response, sessionRequest(t, manager, http.MethodGet, "/api/v1/reader/owls.epub"))
+		if response.Code != http.StatusFound {
+			t.Fatalf("status = %d", response.Code)
+		}
+		location = response.Header().Get("Location")
+	}
+	if blobs.puts != 1 || !strings.HasPrefix(location, "https://blobs.example/exports/epub/"+testAccountID+"/") ||
+		!strings.HasSuffix(location, ".epub?download=owls.epub") {
+		t.Fatalf("puts = %d, location %q", blobs.puts, location)
+	}
+
+	store.readerResponse.Segments[0].RenderedHTML = "<p>Hoo&shy;t.</p>"
+	response := httptest.NewRecorder()
+	handler.ServeHTTP(response, sessionRequest(t, manager, http.MethodGet, "/api/v1/reader/owls.epub"))
+	if blobs.puts != 2 || response.Header().Get("Location") == location {
+		t.Fatalf("a rendering change reused the book: puts = %d", blobs.puts)
+	}
+}
@@ -0,0 +1,62 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"pandapages/api/internal/model"
+)
+
+func TestChildDashboardAnswersForTheNamedChild(t *testing.T) {
+	manager := testSessionManager(t, false, func() time.Time { return testSessionTime })
+	store := &authTestStore{accountExists: true, dashboard: model.ChildDashboard{
+		ChildProfileID: "child-1",
+		Name:           "Ada",
+		Minutes:        42,
+		StreakDays:     3,
+		FavoriteTags:   []model.DashboardTag{{Name: "animals", Minutes: 30}},
+	}}
+	response := httptest.NewRecorder()
+	testHandler(t, store, manager).ServeHTTP(response, sessionRequest(t, manager, http.MethodGet, "/api/v1/children/child-1/dashboard"))
+	if response.Code != http.StatusOK || response.Header().Get("Cache-Control") != "no-store" {
+		t.Fatalf("status = %d; body = %s", response.Code, response.Body)
+	}
+	if store.dashboardChild != "child-1" {
+		t.Fatalf("child = %q", store.dashboardChild)
+	}
+	var dashboard model.ChildDashboard
+	if err := json.Unmarshal(response.Body.Bytes(), &dashboard); err != nil || dashboard.Minutes != 42 || dashboard.FavoriteTags[0].Name != "animals" {
+		t.Fatalf("dashboard = %+v, %v", dashboard, err)
+	}
+}
+
+func TestChildDashboardFailureContracts(t *testing.T) {
+	tests := []struct {
+		method string
+		path   string
+		err    error
+		status int
+		code   string
+	}{
+		{method: http.MethodGet, path: "/api/v1/children/child-1/dashboard", err: fmt.Errorf("%w", model.ErrProfileNotFound), status: http.StatusNotFound, code: "not_found"},
+		{method: http.MethodGet, path: "/api/v1/children/child-1/dashboard", err: fmt.Errorf("private failure"), status: http.StatusInternalServerError, code: "db"},
+		{method: http.MethodGet, path: "/api/v1/children/child-1", status: http.StatusNotFound, code: "not_found"},
+		{method: http.MethodGet, path: "/api/v1/children/a/b/dashboard", status: http.StatusNotFound, code: "not_found"},
+		{method: http.MethodPost, path: "/api/v1/children/child-1/dashboard", status: http.StatusMethodNotAllowed},
+	}
+	for _, test := range tests {
+		manager := testSessionManager(t, false, func() time.Time { return testSessionTime })
+		store := &authTestStore{accountExists: true, dashboardErr: test.err}
+		response := httptest.NewRecorder()
+		testHandler(t, store, manager).ServeHTTP(response, sessionRequest(t, manager, test.method, test.path))
+		if response.Code != test.status || (test.code != "" && !strings.Contains(response.Body.String(), `"code":"`+test.code+`"`)) ||
+			strings.Contains(response.Body.String(), "private") {
+			t.Fatalf("%s %s: status = %d; body = %s", test.method, test.path, response.Code, response.Body)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package httpapi
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"pandapages/api/internal/dictionary"
+	"pandapages/api/internal/model"
+)
+
+func TestDefineAsksTheDictionaryOnceAndFiltersTheSenses(t *testing.T) {
+	manager := testSessionManager(t, false, func() time.Time { return testSessionTime })
+	var paths []string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		if r.URL.Path == "/en/zorblat" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`[{"word":"lantern","phonetic":"/ˈlæntən/","meanings":[{"partOfSpeech":"noun","definitions":[
+			{"definition":"(slang) Something rude.","example":"A rude lantern."},
+			{"definition":"A case that holds a light and protects it from the wind.","example":"She lit the lantern."}]}]}]`))
+	}))
+	defer upstream.Close()
+	store := &authTestStore{accountExists: true}
+	handler := New(Config{Passcode: "123456", Sessions: manager, Dictionary: dictionary.New(upstream.URL)}, store)
+
+	response := httptest.NewRecorder()
+	handler.ServeHTTP(response, sessionRequest(t, manager, http.MethodGet, "/api/v1/define?word=%E2%80%9CLantern,%E2%80%9D&lang=en-GB"))
+	if response.Code != http.StatusOK || response.Header().Get("ETag") == "" {
+		t.Fatalf("status = %d; body = %s", response.Code, response.Body.String())
+	}
+	body := response.Body.String()
+	if !strings.Contains(body, `"word":"lantern"`) || !strings.Contains(body, "protects it from the wind") || strings.Contains(body, "rude") {
+		t.Fatalf("body = %s", body)
+	}
+	if strings.Join(paths, ",") != "/en/lantern" || len(store.dictionarySaved) != 1 || len(store.dictionarySaved[0].Senses) != 2 {
+		t.Fatalf("paths = %v, saved = %#v", paths, store.dictionarySaved)
+	}
+
+	// A kept answer is served without asking again, and an unknown word is
+	// kept as such.
+	store.dictionaryEntry = &model.DictionaryEntry{Definition: store.dictionarySaved[0], FetchedAt: time.Now()}
+	response = httptest.NewRecorder()
+	handler.ServeHTTP(response, sessionRequest(t, manager, http.MethodGet, "/api/v1/define?word=lantern"))
+	if response.Code != http.StatusOK || len(paths) != 1 {
+		t.Fatalf("cached status = %d, paths = %v", response.Code, paths)
+	}
+	store.dictionaryEntry = nil
+	response = httptest.NewRecorder()
+	handler.ServeHTTP(response, sessionRequest(t, manager, http.MethodGet, "/api/v1/define?word=zorblat"))
+	if response.Code != http.StatusNotFound || !strings.Contains(response.Body.String(), `"code":"word_not_found"`) ||
+		len(store.dictionarySaved) != 2 || store.dictionarySaved[1] != nil {
+		t.Fatalf("unknown status = %d, saved = %#v", response.Code, store.dictionarySaved)
+	}
+}
+
+func TestDefineFailureContracts(t *testing.T) {
+	manager := testSessionManager(t, false, func() time.Time { return testSessionTime })
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer failing.Close()
+	stale := &model.DictionaryEntry{
+		Definition: &model.Definition{Word: "moon", Language: "en", Senses: []model.WordSense{{PartOfSpeech: "noun", Definition: "The bright thing in the night sky."}}},
+		FetchedAt:  time.Now().Add(-2 * dictionary.CacheTTL),
+	}
+	tests := []struct {
+		name     string
+		path     string
+		client   *dictionary.Client
+		entry    *model.DictionaryEntry
+		storeErr error
+		status   int
+		code     string
+	}{
+		{name: "phrase", path: "/api/v1/define?word=two+words", client: dictionary.New(failing.URL), status: http.StatusBadRequest, code: "word_invalid"},
+		{name: "language", path: "/api/v1/define?word=moon&lang=english", client: dictionary.New(failing.URL), status: http.StatusBadRequest, code: "lang_invalid"},
+		{name: "blocked", path: "/api/v1/define?word=Porn", status: http.StatusNotFound, code: "word_not_found"},
+		{name: "unconfigured", path: "/api/v1/define?word=moon", status: http.StatusServiceUnavailable, code: "define_unavailable"},
+		{name: "database", path: "/api/v1/define?word=moon", client: dictionary.New(failing.URL), storeErr: errors.New("down"), status: http.StatusInternalServerError, code: "db"},
+		{name: "upstream", path: "/api/v1/define?word=moon", client: dictionary.New(failing.URL), status: http.StatusBadGateway, code: "define_failed"},
+		{name: "stale", path: "/api/v1/define?word=moon", client: dictionary.New(failing.URL), entry: stale, status: http.StatusOK},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			store := &authTestStore{accountExists: true, dictionaryEntry: test.entry, dictionaryErr: test.storeErr}
+			response := httptest.NewRecorder()
+			New(Config{Passcode: "123456", Sessions: manager, Dictionary: test.client}, store).ServeHTTP(
+				response, sessionRequest(t, manager, http.MethodGet, test.path),
+			)
+			if response.Code != test.status || (test.code != "" && !strings.Contains(response.Body.String(), `"code":"`+test.code+`"`)) {
+				t.Fatalf("status = %d, want %d %s; body = %s", response.Code, test.status, test.code, response.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package httpapi
+
+import (
+	"archive/zip"
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"pandapages/api/internal/model"
+)
+
+const testDestinationID = "3a0c7f0e-8b7a-4f55-9a43-a0a1b3b1c2d3"
+
+func TestStorySendQueuesADelivery(t *testing.T) {
+	manager := testSessionManager(t, false, func() time.Time { return testSessionTime })
+	store := &authTestStore{
+		accountExists: true,
+		delivery:      model.StoryDelivery{ID: "delivery-1", Slug: "owls", Address: "reader@kindle.com", Status: model.DeliveryPending},
+	}
+	handler := New(Config{Passcode: "123456", Sessions: manager, Delivery: true}, store)
+
+	request := sessionRequest(t, manager, http.MethodPost, "/api/v1/story/owls/send")
+	request.Body = io.NopCloser(strings.NewReader(`{"destinationId":"` + testDestinationID + `"}`))
+	response := httptest.NewRecorder()
+	handler.ServeHTTP(response, request)
+
+	if response.Code != http.StatusAccepted || response.Header().Get("Location") != "/api/v1/deliveries/delivery-1" {
+		t.Fatalf("status = %d, Location %q; body = %s", response.Code, response.Header().Get("Location"), response.Body.String())
+	}
+	if store.sendSlug != "owls" || store.sendDestination != testDestinationID {
+		t.Fatalf("SendStory(%q, %q)", store.sendSlug, store.sendDestination)
+	}
+	var delivery model.StoryDelivery
+	if err := json.Unmarshal(response.Body.Bytes(), &delivery); err != nil || delivery.Status != model.DeliveryPending {
+		t.Fatalf("delivery = %#v, %v", delivery, err)
+	}
+
+	response = httptest.NewRecorder()
+	handler.ServeHTTP(response, sessionRequest(t, manager, http.MethodGet, "/api/v1/deliveries/delivery-1"))
+	if response.Code != http.StatusOK || !strings.Contains(response.Body.String(), `"status":"pending"`) {
+		t.Fatalf("delivery status = %d; body = %s", response.Code, response.Body.String())
+	}
+}
+
+func TestStorySendFailureContracts(t *testing.T) {
+	manager := testSessionManager(t, false, func() time.Time { return testSessionTime })
+	tests := []struct {
+		name     string
+		disabled bool
+		path     string
+		body     string
+		err      error
+		status   int
+		code     string
+	}{
+		{name: "unconfigured", disabled: true, status: http.StatusServiceUnavailable, code: "delivery_unavailable"},
+		{name: "destination required", body: `{}`, status: http.StatusBadRequest, code: "send_invalid"},
+		{name: "story missing", err: sql.ErrNoRows, status: http.StatusNotFound, code: "not_found"},
+		{name: "story removed", err: fmt.Errorf("%w", model.ErrStoryRemoved), status: http.StatusGone, code: "story_removed"},
+		{name: "destination missing", err: fmt.Errorf("%w", model.ErrDestinationNotFound), status: http.StatusNotFound, code: "destination_not_found"},
+		{name: "database", err: fmt.Errorf("database unavailable"), status: http.StatusInternalServerError, code: "db"},
+		{name: "route", path: "/api/v1/story/owls/print", status: http.StatusNotFound, code: "not_found"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			store := &authTestStore{accountExists: true, deliveryErr: test.err}
+			path, body := test.path, test.body
+			if path == "" {
+				path = "/api/v1/story/owls/send"
+			}
+			if body == "" {
+				body = `{"destinationId":"` + testDestinationID + `"}`
+			}
+			request := sessionRequest(t, manager, http.MethodPost, path)
+			request.Body = io.NopCloser(strings.NewReader(body))
+			response := httptest.NewRecorder()
+			New(Config{Passcode: "123456", Sessions: manager, Delivery: !test.disabled}, store).ServeHTTP(response, request)
+			if response.Code != test.status || !strings.Contains(response.Body.String(), `"code":"`+test.code+`"`) {
+				t.Fatalf("status = %d, want %d %s; body = %s", response.Code, test.status, test.code, response.Body.String())
+			}
+		})
+	}
+}
+
+func TestDeliveryDestinationsAreManagedPerAccount(t *testing.T) {
+	manager := testSessionManager(t, false, func() time.Time { return testSessionTime })
+	store := &authTestStore{accountExists: true}
+	handler := testHandler(t, store, manager)
+
+	create := func(body string) *httptest.ResponseRecorder {
+		request := sessionRequest(t, manager, http.MethodPost, "/api/v1/delivery-destinations")
+		request.Body = io.NopCloser(strings.NewReader(body))
+		response := httptest.NewRecorder()
+		handler.ServeHTTP(response, request)
+		return response
+	}
+	if response := create(`{"name":"Kindle","address":"reader@kindle.com"}`); response.Code != http.StatusCreated {
+		t.Fatalf("create status = %d; body = %s", response.Code, response.Body.String())
+	}
+	response := create(`{"name":" ","address":"Reader <reader@kindle.com>"}`)
+	if response.Code != http.StatusBadRequest || !strings.Contains(response.Body.String(), `"path":"name"`) || !strings.Contains(response.Body.String(), `"path":"address"`) {
+		t.Fatalf("invalid create = %d; body = %s", response.Code, response.Body.String())
+	}
+	for err, code := range map[error]string{model.ErrDestinationExists: "destination_exists", model.ErrDestinationLimit: "destination_limit"} {
+		store.destinationErr = fmt.Errorf("%w", err)
+		if response := create(`{"name":"Kindle","address":"reader@kindle.com"}`); response.Code != http.StatusConflict || !strings.Contains(response.Body.String(), code) {
+			t.Fatalf("%s create = %d; body = %s", code, response.Code, response.Body.String())
+		}
+	}
+	store.destinationErr = nil
+
+	response = httptest.NewRecorder()
+	handler.ServeHTTP(response, sessionRequest(t, manager, http.MethodGet, "/api/v1/delivery-destinations"))
+	if response.Code != http.StatusOK || !strings.Contains(response.Body.String(), `"address":"reader@kindle.com"`) {
+		t.Fatalf("list = %d; body = %s", response.Code, response.Body.String())
+	}
+
+	response = httptest.NewRecorder()
+	handler.ServeHTTP(response, sessionRequest(t, manager, http.MethodDelete, "/api/v1/delivery-destinations/"+testDestinationID))
+	if response.Code != http.StatusNoContent || store.sendDestination != testDestinationID {
+		t.Fatalf("delete = %d (%q)", response.Code, store.sendDestination)
+	}
+	store.destinationErr = fmt.Errorf("%w", model.ErrDestinationNotFound)
+	response = httptest.NewRecorder()
+	handler.ServeHTTP(response, sessionRequest(t, manager, http.MethodDelete, "/api/v1/delivery-destinations/"+testDestinationID))
+	if response.Code != http.StatusNotFound {
+		t.Fatalf("missing delete = %d", response.Code)
+	}
+}
+
+func TestReaderServesTheStoryAsAnEPUB(t *testing.T) {
+	manager := testSessionManager(t, false, func() time.Time { return testSessionTime })
+	store := &authTestStore{
+		accountExists: true,
+		readerResponse: model.ReaderStory{Slug: "owls", Title: "Owls", Language: "en", Version: 2, Segments: []model.ReaderSegment{
+			{Ordinal: 1, Kind: "paragraph", RenderedHTML: "<p>Hoot.</p>"},
+		}},
+	}
+	response := httptest.NewRecorder()
+	testHandler(t, store, manager).ServeHTTP(response, sessionRequest(t, manager, http.MethodGet, "/api/v1/reader/owls.epub"))
+
+	if response.Code != http.StatusOK || response.Header().Get("Content-Type") != "application/epub+zip" || store.readerSlug != "owls" {
+		t.Fatalf("status = %d, type %q, slug %q", response.Code, response.Header().Get("Content-Type"), store.readerSlug)
+	}
+	if response.Header().Get("Content-Disposition") != `attachment; filename="owls.epub"` {
+		t.Fatalf("Content-Disposition = %q", response.Header().Get("Content-Disposition"))
+	}
+	if _, err := zip.NewReader(bytes.NewReader(response.Body.Bytes()), int64(response.Body.Len())); err != nil {
+		t.Fatalf("book is not a zip: %v", err)
+	}
+}
@@ -0,0 +1,69 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"pandapages/api/internal/model"
+)
+
+func TestDigestSettingsRoundTrip(t *testing.T) {
+	manager := testSessionManager(t, false, func() time.Time { return testSessionTime })
+	store := &authTestStore{accountExists: true}
+	handler := testHandler(t, store, manager)
+
+	response := httptest.NewRecorder()
+	handler.ServeHTTP(response, sessionRequest(t, manager, http.MethodGet, "/api/v1/digest/settings"))
+	if response.Code != http.StatusOK || !strings.Contains(response.Body.String(), `"enabled":false`) {
+		t.Fatalf("settings = %d; body = %s", response.Code, response.Body.String())
+	}
+
+	request := sessionRequest(t, manager, http.MethodPut, "/api/v1/digest/settings")
+	request.Body = io.NopCloser(strings.NewReader(`{"enabled":true,"address":"Parent@Example.COM","day":" Friday ","time":"17:30","timeZone":"Europe/London"}`))
+	response = httptest.NewRecorder()
+	handler.ServeHTTP(response, request)
+	if response.Code != http.StatusOK {
+		t.Fatalf("put = %d; body = %s", response.Code, response.Body.String())
+	}
+	if saved := store.digestSettings; saved == nil || saved.Address != "Parent@example.com" || saved.Day != "friday" || saved.Time != "17:30" {
+		t.Fatalf("saved = %+v", saved)
+	}
+
+	for _, body := range []string{
+		`{"enabled":true,"address":"","day":"friday","time":"17:30","timeZone":"UTC"}`,
+		`{"enabled":false,"address":"Parent <parent@example.com>","day":"friday","time":"17:30","timeZone":"UTC"}`,
+		`{"enabled":true,"address":"parent@example.com","day":"someday","time":"17:30","timeZone":"UTC"}`,
+		`{"enabled":true,"address":"parent@example.com","day":"friday","time":"5pm","timeZone":"UTC"}`,
+		`{"enabled":true,"address":"parent@example.com","day":"friday","time":"17:30","timeZone":"Local"}`,
+	} {
+		request := sessionRequest(t, manager, http.MethodPut, "/api/v1/digest/settings")
+		request.Body = io.NopCloser(strings.NewReader(body))
+		response := httptest.NewRecorder()
+		handler.ServeHTTP(response, request)
+		if response.Code != http.StatusBadRequest || !strings.Contains(response.Body.String(), "digest_settings_invalid") {
+			t.Fatalf("%s = %d; body = %s", body, response.Code, response.Body.String())
+		}
+	}
+}
+
+func TestReadingReportServesThisWeek(t *testing.T) {
+	manager := testSessionManager(t, false, func() time.Time { return testSessionTime })
+	store := &authTestStore{accountExists: true, report: model.ReadingReport{
+		From: "2026-10-11", To: "2026-10-17", TimeZone: "UTC",
+		Children: []model.ChildReading{{Name: "Ada", Minutes: 12, BooksFinished: []model.FinishedBook{}}},
+	}}
+	response := httptest.NewRecorder()
+	testHandler(t, store, manager).ServeHTTP(response, sessionRequest(t, manager, http.MethodGet, "/api/v1/digest/report"))
+	if response.Code != http.StatusOK {
+		t.Fatalf("report = %d; body = %s", response.Code, response.Body.String())
+	}
+	var report model.ReadingReport
+	if err := json.Unmarshal(response.Body.Bytes(), &report); err != nil || len(report.Children) != 1 || report.Children[0].Minutes != 12 {
+		t.Fatalf("report = %+v, %v", report, err)
+	}
+}
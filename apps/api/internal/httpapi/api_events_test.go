@@ -0,0 +1,63 @@
+package httpapi
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"pandapages/api/internal/model"
+)
+
+func TestClientEventsAreRecorded(t *testing.T) {
+	manager := testSessionManager(t, false, func() time.Time { return testSessionTime })
+	store := &authTestStore{accountExists: true}
+	handler := testHandler(t, store, manager)
+
+	now := time.Now().UTC()
+	body := fmt.Sprintf(`{"events":[
+		{"kind":"story_opened","slug":"owls","at":%q},
+		{"kind":"segment_viewed","slug":" owls ","at":%q},
+		{"kind":"read_aloud_used","slug":"owls","at":%q},
+		{"kind":"story_opened","slug":"owls","at":%q}
+	]}`, now.Format(time.RFC3339), now.Format(time.RFC3339), now.Format(time.RFC3339), now.Add(-8*24*time.Hour).Format(time.RFC3339))
+	request := sessionRequest(t, manager, http.MethodPost, "/api/v1/events")
+	request.Body = io.NopCloser(strings.NewReader(body))
+	response := httptest.NewRecorder()
+	handler.ServeHTTP(response, request)
+
+	if response.Code != http.StatusOK || !strings.Contains(response.Body.String(), `"recorded":3,"dropped":1`) {
+		t.Fatalf("status = %d; body = %s", response.Code, response.Body.String())
+	}
+	if len(store.events) != 3 || store.events[1].Slug != "owls" || store.events[2].Kind != model.ClientEventReadAloudUsed {
+		t.Fatalf("events = %+v", store.events)
+	}
+}
+
+func TestClientEventsRefuseMalformedBatches(t *testing.T) {
+	manager := testSessionManager(t, false, func() time.Time { return testSessionTime })
+	at := time.Now().UTC().Format(time.RFC3339)
+	tooMany := strings.TrimSuffix(strings.Repeat(`{"kind":"story_opened","slug":"owls","at":"`+at+`"},`, model.MaxClientEvents+1), ",")
+	for name, body := range map[string]string{
+		"empty":    `{"events":[]}`,
+		"too many": `{"events":[` + tooMany + `]}`,
+		"kind":     `{"events":[{"kind":"page_scrolled","slug":"owls","at":"` + at + `"}]}`,
+		"slug":     `{"events":[{"kind":"story_opened","slug":"","at":"` + at + `"}]}`,
+		"at":       `{"events":[{"kind":"story_opened","slug":"owls"}]}`,
+		"extra":    `{"events":[{"kind":"story_opened","slug":"owls","at":"` + at + `","userAgent":"x"}]}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			store := &authTestStore{accountExists: true}
+			request := sessionRequest(t, manager, http.MethodPost, "/api/v1/events")
+			request.Body = io.NopCloser(strings.NewReader(body))
+			response := httptest.NewRecorder()
+			testHandler(t, store, manager).ServeHTTP(response, request)
+			if response.Code != http.StatusBadRequest || len(store.events) != 0 {
+				t.Fatalf("status = %d; body = %s", response.Code, response.Body.String())
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package httpapi
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
@@ -20,7 +21,7 @@ func TestLibraryEndpointReturnsEnrichedSafeReadModel(t *testing.T) {
 		accountExists: true,
 		libraryResponse: model.LibraryReadModel{
 			UnavailableItemCount: 1,
-			Items: []model.StoryItem{
+			Page: model.Page[model.StoryItem]{Items: []model.StoryItem{
 				{
 					Slug:             "the-three-little-pigs",
 					Title:            "The Three Little Pigs",
@@ -45,7 +46,7 @@ func TestLibraryEndpointReturnsEnrichedSafeReadModel(t *testing.T) {
 					ChapterCount:     7,
 					Progress:         nil,
 				},
-			},
+			}},
 		},
 	}
 	response := httptest.NewRecorder()
@@ -142,3 +143,108 @@ func TestLibraryEndpointMethodAndSafeFailureContracts(t *testing.T) {
 		}
 	})
 }
+
+func TestLibraryQueriesStopWhenTheClientDisconnects(t *testing.T) {
+	manager := testSessionManager(t, false, func() time.Time { return testSessionTime })
+	store := &authTestStore{accountExists: true}
+	ctx, disconnect := context.WithCancel(t.Context())
+	request := sessionRequest(t, manager, http.MethodGet, "/api/v1/library").WithContext(ctx)
+
+	testHandler(t, store, manager).ServeHTTP(httptest.NewRecorder(), request)
+	if store.libraryCtx == nil || store.libraryCtx.Err() != nil {
+		t.Fatalf("Library context = %v before the disconnect", store.libraryCtx)
+	}
+	disconnect()
+	if !errors.Is(store.libraryCtx.Err(), context.Canceled) {
+		t.Fatalf("Library context err = %v after the disconnect, want canceled", store.libraryCtx.Err())
+	}
+}
+
+func TestLibraryEndpointPagesByCursor(t *testing.T) {
+	manager := testSessionManager(t, false, func() time.Time { return testSessionTime })
+	next := "next-page"
+	store := &authTestStore{accountExists: true, libraryResponse: model.LibraryReadModel{Page: model.Page[model.StoryItem]{NextCursor: &next}}}
+	response := httptest.NewRecorder()
+	testHandler(t, store, manager).ServeHTTP(
+		response,
+		sessionRequest(t, manager, http.MethodGet, "/api/v1/library?cursor=this-page&limit=20"),
+	)
+	if response.Code != http.StatusOK {
+		t.Fatalf("status = %d; body = %s", response.Code, response.Body.String())
+	}
+	if store.libraryPage != (model.PageRequest{Cursor: "this-page", Limit: 20}) {
+		t.Fatalf("Library page = %#v", store.libraryPage)
+	}
+	var payload map[string]any
+	if err := json.Unmarshal(response.Body.Bytes(), &payload); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if payload["nextCursor"] != next {
+		t.Fatalf("nextCursor = %#v", payload["nextCursor"])
+	}
+	if _, ok := payload["total"]; ok {
+		t.Fatalf("unrequested total = %#v", payload["total"])
+	}
+
+	total := int64(41)
+	store.libraryResponse.Total = &total
+	response = httptest.NewRecorder()
+	testHandler(t, store, manager).ServeHTTP(response, sessionRequest(t, manager, http.MethodGet, "/api/v1/library?total=true"))
+	if response.Code != http.StatusOK || !store.libraryPage.Total || !strings.Contains(response.Body.String(), `"total":41`) {
+		t.Fatalf("total status = %d, page = %#v, body = %s", response.Code, store.libraryPage, response.Body.String())
+	}
+
+	for _, test := range []struct {
+		path string
+		err  error
+		code string
+	}{
+		{path: "/api/v1/library?limit=0", code: "page_invalid"},
+		{path: "/api/v1/library?limit=ten", code: "page_invalid"},
+		{path: "/api/v1/library?total=maybe", code: "page_invalid"},
+		{path: "/api/v1/library?cursor=forged", err: model.ErrInvalidCursor, code: "cursor_invalid"},
+	} {
+		store := &authTestStore{accountExists: true, libraryErr: test.err}
+		response := httptest.NewRecorder()
+		testHandler(t, store, manager).ServeHTTP(response, sessionRequest(t, manager, http.MethodGet, test.path))
+		if response.Code != http.StatusBadRequest || !strings.Contains(response.Body.String(), test.code) {
+			t.Fatalf("%s: status = %d; body = %s", test.path, response.Code, response.Body.String())
+		}
+	}
+}
+
+func TestLibraryAndContinueSendOnlyTheFieldsAsked(t *testing.T) {
+	manager := testSessionManager(t, false, func() time.Time { return testSessionTime })
+	next := "next-page"
+	store := &authTestStore{accountExists: true, libraryResponse: model.LibraryReadModel{Page: model.Page[model.StoryItem]{
+		Items:      []model.StoryItem{{Slug: "fox", Title: "Fox", Language: "en", PublishedVersion: 2, WordCount: 300}},
+		NextCursor: &next,
+	}}}
+	handler := testHandler(t, store, manager)
+	get := func(path string) *httptest.ResponseRecorder {
+		response := httptest.NewRecorder()
+		handler.ServeHTTP(response, sessionRequest(t, manager, http.MethodGet, path))
+		return response
+	}
+
+	response := get("/api/v1/library?fields=title,%20slug,author")
+	if response.Code != http.StatusOK {
+		t.Fatalf("status = %d; body = %s", response.Code, response.Body.String())
+	}
+	if body := response.Body.String(); !strings.Contains(body, `"items":[{"slug":"fox","title":"Fox","author":null}]`) ||
+		!strings.Contains(body, `"nextCursor":"next-page"`) || !strings.Contains(body, `"unavailableItemCount":0`) {
+		t.Fatalf("projected library = %s", body)
+	}
+
+	response = get("/api/v1/library?fields=slug,markdown")
+	if response.Code != http.StatusBadRequest || !strings.Contains(response.Body.String(), `"path":"fields.1"`) ||
+		strings.Contains(response.Body.String(), `"markdown"`) {
+		t.Fatalf("unknown field = %d %s", response.Code, response.Body.String())
+	}
+	if response := get("/api/v1/continue?fields=percent"); response.Code != http.StatusOK || response.Body.String() != `{"items":[],"nextCursor":null,"upNext":[]}`+"\n" {
+		t.Fatalf("projected continue = %d %s", response.Code, response.Body.String())
+	}
+	if response := get("/api/v1/continue?fields=title"); response.Code != http.StatusBadRequest {
+		t.Fatalf("continue unknown field = %d", response.Code)
+	}
+}
@@ -0,0 +1,119 @@
+package httpapi
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"pandapages/api/internal/model"
+)
+
+func TestMediaEndpointServesAccountImage(t *testing.T) {
+	manager := testSessionManager(t, false, func() time.Time { return testSessionTime })
+	mediaID := "0b0c7f0e-8b7a-4f55-9a43-a0a1b3b1c2d3"
+	store := &authTestStore{
+		accountExists: true,
+		media:         model.Media{ID: mediaID, ContentType: "image/png"},
+		mediaData:     []byte("\x89PNG\r\n\x1a\nfake"),
+	}
+	response := httptest.NewRecorder()
+	testHandler(t, store, manager).ServeHTTP(response, sessionRequest(t, manager, http.MethodGet, "/api/v1/media/"+mediaID))
+
+	if response.Code != http.StatusOK {
+		t.Fatalf("status = %d; body = %s", response.Code, response.Body.String())
+	}
+	if store.mediaAccount != testAccountID || store.mediaID != mediaID {
+		t.Fatalf("Media scope = %q %q", store.mediaAccount, store.mediaID)
+	}
+	if response.Header().Get("Content-Type") != "image/png" || response.Body.String() != string(store.mediaData) {
+		t.Fatalf("media response = %q %q", response.Header().Get("Content-Type"), response.Body.String())
+	}
+	if response.Header().Get("Cache-Control") != "private, max-age=31536000, immutable" {
+		t.Fatalf("Cache-Control = %q", response.Header().Get("Cache-Control"))
+	}
+}
+
+func TestMediaEndpointFailureContracts(t *testing.T) {
+	manager := testSessionManager(t, false, func() time.Time { return testSessionTime })
+	tests := []struct {
+		name   string
+		method string
+		err    error
+		status int
+	}{
+		{name: "missing", method: http.MethodGet, err: fmt.Errorf("%w", model.ErrMediaNotFound), status: http.StatusNotFound},
+		{name: "database", method: http.MethodGet, err: fmt.Errorf("database unavailable"), status: http.StatusInternalServerError},
+		{name: "method", method: http.MethodPost, status: http.StatusMethodNotAllowed},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			store := &authTestStore{accountExists: true, mediaErr: test.err}
+			response := httptest.NewRecorder()
+			testHandler(t, store, manager).ServeHTTP(response, sessionRequest(t, manager, test.method, "/api/v1/media/anything"))
+			if response.Code != test.status {
+				t.Fatalf("status = %d, want %d", response.Code, test.status)
+			}
+		})
+	}
+
+	response := httptest.NewRecorder()
+	testHandler(t, &authTestStore{accountExists: true}, manager).ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/api/v1/media/anything", nil))
+	if response.Code != http.StatusUnauthorized {
+		t.Fatalf("unauthenticated status = %d", response.Code)
+	}
+}
+
+func TestAudioEndpointServesRangesOfSegmentNarration(t *testing.T) {
+	manager := testSessionManager(t, false, func() time.Time { return testSessionTime })
+	audioID := "5f0c7f0e-8b7a-4f55-9a43-a0a1b3b1c2d3"
+	store := &authTestStore{
+		accountExists: true,
+		audio:         model.NarrationAudio{ContentType: model.NarrationAudioType, Data: []byte("RIFFfakeWAVEdata")},
+	}
+	response := httptest.NewRecorder()
+	testHandler(t, store, manager).ServeHTTP(response, sessionRequest(t, manager, http.MethodGet, "/api/v1/audio/"+audioID))
+
+	if response.Code != http.StatusOK || response.Body.String() != "RIFFfakeWAVEdata" {
+		t.Fatalf("status = %d; body = %q", response.Code, response.Body.String())
+	}
+	if store.mediaAccount != testAccountID || store.audioID != audioID {
+		t.Fatalf("SegmentAudio scope = %q %q", store.mediaAccount, store.audioID)
+	}
+	if response.Header().Get("Content-Type") != "audio/wav" || response.Header().Get("Accept-Ranges") != "bytes" {
+		t.Fatalf("audio headers = %v", response.Header())
+	}
+
+	request := sessionRequest(t, manager, http.MethodGet, "/api/v1/audio/"+audioID)
+	request.Header.Set("Range", "bytes=4-7")
+	response = httptest.NewRecorder()
+	testHandler(t, store, manager).ServeHTTP(response, request)
+	if response.Code != http.StatusPartialContent || response.Body.String() != "fake" {
+		t.Fatalf("range status = %d; body = %q", response.Code, response.Body.String())
+	}
+}
+
+func TestAudioEndpointFailureContracts(t *testing.T) {
+	manager := testSessionManager(t, false, func() time.Time { return testSessionTime })
+	tests := []struct {
+		name   string
+		method string
+		err    error
+		status int
+	}{
+		{name: "missing", method: http.MethodGet, err: fmt.Errorf("%w", model.ErrAudioNotFound), status: http.StatusNotFound},
+		{name: "database", method: http.MethodGet, err: fmt.Errorf("database unavailable"), status: http.StatusInternalServerError},
+		{name: "method", method: http.MethodPost, status: http.StatusMethodNotAllowed},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			store := &authTestStore{accountExists: true, audioErr: test.err}
+			response := httptest.NewRecorder()
+			testHandler(t, store, manager).ServeHTTP(response, sessionRequest(t, manager, test.method, "/api/v1/audio/anything"))
+			if response.Code != test.status {
+				t.Fatalf("status = %d, want %d", response.Code, test.status)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package httpapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"pandapages/api/internal/phonics"
+)
+
+func TestLibraryDecodableFilter(t *testing.T) {
+	manager := testSessionManager(t, false, func() time.Time { return testSessionTime })
+	progression, err := phonics.Load(func(string) string { return "" })
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name        string
+		path        string
+		progression *phonics.Progression
+		status      int
+		code        string
+		set         int
+	}{
+		{name: "unfiltered", path: "/api/v1/library", progression: progression, status: http.StatusOK},
+		{name: "set", path: "/api/v1/library?decodable=3&limit=10", progression: progression, status: http.StatusOK, set: 3},
+		{name: "past the last set", path: "/api/v1/library?decodable=14", progression: progression, status: http.StatusBadRequest, code: "decodable_invalid"},
+		{name: "not a number", path: "/api/v1/library?decodable=one", progression: progression, status: http.StatusBadRequest, code: "decodable_invalid"},
+		{name: "unconfigured", path: "/api/v1/library?decodable=3", status: http.StatusServiceUnavailable, code: "phonics_unavailable"},
+		{name: "unconfigured and unfiltered", path: "/api/v1/library", status: http.StatusOK},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			store := &authTestStore{accountExists: true}
+			response := httptest.NewRecorder()
+			New(Config{Passcode: "123456", Sessions: manager, Phonics: test.progression}, store).ServeHTTP(
+				response, sessionRequest(t, manager, http.MethodGet, test.path),
+			)
+			if response.Code != test.status || (test.code != "" && !strings.Contains(response.Body.String(), `"code":"`+test.code+`"`)) {
+				t.Fatalf("status = %d, want %d %s; body = %s", response.Code, test.status, test.code, response.Body.String())
+			}
+			if test.status != http.StatusOK {
+				if store.libraryCalls != 0 {
+					t.Fatal("a refused filter reached Library storage")
+				}
+				return
+			}
+			if store.libraryFilter.DecodableSet != test.set || (test.set > 0 && store.libraryFilter.Progression != progression.Key()) {
+				t.Fatalf("filter = %#v", store.libraryFilter)
+			}
+		})
+	}
+}
+
+func TestPhonicsServesTheProgression(t *testing.T) {
+	manager := testSessionManager(t, false, func() time.Time { return testSessionTime })
+	progression, err := phonics.Load(func(string) string { return "" })
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := &authTestStore{accountExists: true}
+
+	response := httptest.NewRecorder()
+	New(Config{Passcode: "123456", Sessions: manager, Phonics: progression}, store).ServeHTTP(
+		response, sessionRequest(t, manager, http.MethodGet, "/api/v1/phonics"),
+	)
+	body := response.Body.String()
+	if response.Code != http.StatusOK || response.Header().Get("ETag") == "" ||
+		!strings.Contains(body, `"name":"letters-and-sounds"`) || !strings.Contains(body, `"graphemes":["s","a","t","p"]`) {
+		t.Fatalf("status = %d; body = %s", response.Code, body)
+	}
+
+	response = httptest.NewRecorder()
+	testHandler(t, store, manager).ServeHTTP(response, sessionRequest(t, manager, http.MethodGet, "/api/v1/phonics"))
+	if response.Code != http.StatusServiceUnavailable || !strings.Contains(response.Body.String(), `"code":"phonics_unavailable"`) {
+		t.Fatalf("unconfigured status = %d; body = %s", response.Code, response.Body.String())
+	}
+}
@@ -33,21 +33,24 @@ func TestProgressPutStrictlyValidatesLocatorV2BeforeStore(t *testing.T) {
 		name     string
 		body     string
 		wantCode string
+		wantPath string
 	}{
-		{name: "missing locator", body: `{"version":1,"percent":0.2}`, wantCode: "locator_invalid"},
-		{name: "null locator", body: `{"version":1,"locator":null,"percent":0.2}`, wantCode: "locator_invalid"},
-		{name: "Reader 1 locator", body: `{"version":1,"locator":{"mode":"scroll","scrollY":2},"percent":0.2}`, wantCode: "bad_json"},
-		{name: "wrong schema", body: strings.Replace(validProgressBody(0.2), `"schema":2`, `"schema":1`, 1), wantCode: "locator_invalid"},
-		{name: "uppercase key", body: strings.Replace(validProgressBody(0.2), progressTestKey, strings.ToUpper(progressTestKey), 1), wantCode: "locator_invalid"},
-		{name: "zero occurrence", body: strings.Replace(validProgressBody(0.2), `"occurrence":1`, `"occurrence":0`, 1), wantCode: "locator_invalid"},
-		{name: "zero ordinal", body: strings.Replace(validProgressBody(0.2), `"ordinal":4`, `"ordinal":0`, 1), wantCode: "locator_invalid"},
-		{name: "offset above one", body: strings.Replace(validProgressBody(0.2), `"offset":0.35`, `"offset":1.01`, 1), wantCode: "locator_invalid"},
-		{name: "partial chapter", body: strings.Replace(validProgressBody(0.2), `,"occurrence":1}}`, `}}`, 1), wantCode: "locator_invalid"},
-		{name: "unknown top-level locator field", body: strings.Replace(validProgressBody(0.2), `"schema":2`, `"schema":2,"mode":"scroll"`, 1), wantCode: "bad_json"},
-		{name: "unknown segment field", body: strings.Replace(validProgressBody(0.2), `"offset":0.35`, `"offset":0.35,"page":2`, 1), wantCode: "bad_json"},
-		{name: "missing percent", body: strings.Replace(validProgressBody(0.2), `,"percent":0.2`, "", 1), wantCode: "percent"},
-		{name: "percent below zero", body: validProgressBody(-0.1), wantCode: "percent"},
-		{name: "percent above one", body: validProgressBody(1.1), wantCode: "percent"},
+		{name: "missing locator", body: `{"version":1,"percent":0.2}`, wantCode: "locator_invalid", wantPath: "locator"},
+		{name: "null locator", body: `{"version":1,"locator":null,"percent":0.2}`, wantCode: "locator_invalid", wantPath: "locator"},
+		{name: "Reader 1 locator", body: `{"version":1,"locator":{"mode":"scroll","scrollY":2},"percent":0.2}`, wantCode: "bad_json", wantPath: "locator"},
+		{name: "wrong schema", body: strings.Replace(validProgressBody(0.2), `"schema":2`, `"schema":1`, 1), wantCode: "locator_invalid", wantPath: "locator.schema"},
+		{name: "uppercase key", body: strings.Replace(validProgressBody(0.2), progressTestKey, strings.ToUpper(progressTestKey), 1), wantCode: "locator_invalid", wantPath: "locator.segment.key"},
+		{name: "zero occurrence", body: strings.Replace(validProgressBody(0.2), `"occurrence":1`, `"occurrence":0`, 1), wantCode: "locator_invalid", wantPath: "locator.segment.occurrence"},
+		{name: "zero ordinal", body: strings.Replace(validProgressBody(0.2), `"ordinal":4`, `"ordinal":0`, 1), wantCode: "locator_invalid", wantPath: "locator.segment.ordinal"},
+		{name: "string ordinal", body: strings.Replace(validProgressBody(0.2), `"ordinal":4`, `"ordinal":"4"`, 1), wantCode: "bad_json", wantPath: "locator.segment.ordinal"},
+		{name: "offset above one", body: strings.Replace(validProgressBody(0.2), `"offset":0.35`, `"offset":1.01`, 1), wantCode: "locator_invalid", wantPath: "locator.segment.offset"},
+		{name: "partial chapter", body: strings.Replace(validProgressBody(0.2), `,"occurrence":1}}`, `}}`, 1), wantCode: "locator_invalid", wantPath: "locator.chapter.occurrence"},
+		{name: "unknown top-level locator field", body: strings.Replace(validProgressBody(0.2), `"schema":2`, `"schema":2,"mode":"scroll"`, 1), wantCode: "bad_json", wantPath: "locator"},
+		{name: "unknown segment field", body: strings.Replace(validProgressBody(0.2), `"offset":0.35`, `"offset":0.35,"page":2`, 1), wantCode: "bad_json", wantPath: "locator.segment"},
+		{name: "zero version", body: strings.Replace(validProgressBody(0.2), `"version":2`, `"version":0`, 1), wantCode: "version", wantPath: "version"},
+		{name: "missing percent", body: strings.Replace(validProgressBody(0.2), `,"percent":0.2`, "", 1), wantCode: "percent", wantPath: "percent"},
+		{name: "percent below zero", body: validProgressBody(-0.1), wantCode: "percent", wantPath: "percent"},
+		{name: "percent above one", body: validProgressBody(1.1), wantCode: "percent", wantPath: "percent"},
 	}
 
 	for _, test := range tests {
@@ -69,7 +72,8 @@ func TestProgressPutStrictlyValidatesLocatorV2BeforeStore(t *testing.T) {
 			}
 			var payload struct {
 				Error struct {
-					Code string `json:"code"`
+					Code   string             `json:"code"`
+					Fields []model.FieldError `json:"fields"`
 				} `json:"error"`
 			}
 			if err := json.Unmarshal(response.Body.Bytes(), &payload); err != nil {
@@ -78,6 +82,13 @@ func TestProgressPutStrictlyValidatesLocatorV2BeforeStore(t *testing.T) {
 			if payload.Error.Code != test.wantCode {
 				t.Fatalf("error code = %q, want %q; body = %s", payload.Error.Code, test.wantCode, response.Body.String())
 			}
+			if test.wantPath == "" {
+				if len(payload.Error.Fields) != 0 {
+					t.Fatalf("fields = %+v, want none", payload.Error.Fields)
+				}
+			} else if len(payload.Error.Fields) != 1 || payload.Error.Fields[0].Path != test.wantPath || payload.Error.Fields[0].Message == "" {
+				t.Fatalf("fields = %+v, want one naming %q", payload.Error.Fields, test.wantPath)
+			}
 		})
 	}
 }
@@ -94,6 +105,7 @@ func TestProgressPutResponseFollowsTypedStoreResult(t *testing.T) {
 	}{
 		{name: "success", wantStatus: http.StatusOK, wantOK: true},
 		{name: "not found", storeErr: sql.ErrNoRows, wantStatus: http.StatusNotFound, wantCode: "not_found"},
+		{name: "removed", storeErr: model.ErrStoryRemoved, wantStatus: http.StatusGone, wantCode: "story_removed"},
 		{name: "locator mismatch", storeErr: readercontract.ErrLocatorMismatch, wantStatus: http.StatusBadRequest, wantCode: "locator_mismatch"},
 		{name: "database failure", storeErr: errors.New("private database detail"), wantStatus: http.StatusInternalServerError, wantCode: "db"},
 	} {
@@ -141,6 +153,35 @@ func TestProgressPutResponseFollowsTypedStoreResult(t *testing.T) {
 	}
 }
 
+func TestProgressPutRetriedWithIdempotencyKeyIsSavedOnce(t *testing.T) {
+	manager := testSessionManager(t, false, func() time.Time { return testSessionTime })
+	store := &authTestStore{accountExists: true}
+	handler := testHandler(t, store, manager)
+	put := func(percent float64) *httptest.ResponseRecorder {
+		request := sessionRequest(t, manager, http.MethodPut, "/api/v1/progress/test-story")
+		body := validProgressBody(percent)
+		request.Body = io.NopCloser(strings.NewReader(body))
+		request.ContentLength = int64(len(body))
+		request.Header.Set("Idempotency-Key", "save-1")
+		response := httptest.NewRecorder()
+		handler.ServeHTTP(response, request)
+		return response
+	}
+
+	first := put(0.5)
+	retry := put(0.5)
+	if first.Code != http.StatusOK || retry.Code != http.StatusOK || retry.Body.String() != first.Body.String() {
+		t.Fatalf("first/retry = %d %s / %d %s", first.Code, first.Body.String(), retry.Code, retry.Body.String())
+	}
+	if retry.Header().Get("Idempotent-Replayed") != "true" || store.progressPutCalls != 1 {
+		t.Fatalf("retry replayed = %q, ProgressPut calls = %d; want a replay without a second save",
+			retry.Header().Get("Idempotent-Replayed"), store.progressPutCalls)
+	}
+	if reused := put(0.7); reused.Code != http.StatusUnprocessableEntity || store.progressPutCalls != 1 {
+		t.Fatalf("reused key = %d, ProgressPut calls = %d", reused.Code, store.progressPutCalls)
+	}
+}
+
 func TestProgressGetDistinguishesMissingStoryFromKnownEmptyProgress(t *testing.T) {
 	manager := testSessionManager(t, false, func() time.Time { return testSessionTime })
 
@@ -173,6 +214,18 @@ func TestProgressGetDistinguishesMissingStoryFromKnownEmptyProgress(t *testing.T
 			t.Fatalf("status = %d, want 404; body = %s", response.Code, response.Body.String())
 		}
 	})
+
+	t.Run("removed story", func(t *testing.T) {
+		store := &authTestStore{accountExists: true, progressGetErr: model.ErrStoryRemoved}
+		response := httptest.NewRecorder()
+		testHandler(t, store, manager).ServeHTTP(
+			response,
+			sessionRequest(t, manager, http.MethodGet, "/api/v1/progress/removed"),
+		)
+		if response.Code != http.StatusGone || !strings.Contains(response.Body.String(), `"story_removed"`) {
+			t.Fatalf("response = %d %s, want 410 story_removed", response.Code, response.Body.String())
+		}
+	})
 }
 
 func TestProgressPutRequiresVerifiedSession(t *testing.T) {
@@ -0,0 +1,177 @@
+package httpapi
+
+import (
+	"context"
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"pandapages/api/internal/model"
+	"pandapages/api/internal/webpush"
+)
+
+type pushKeyStore struct{}
+
+func (pushKeyStore) PushVAPIDKey(_ context.Context, candidate []byte) ([]byte, error) {
+	return candidate, nil
+}
+
+func testPushSender(t *testing.T) *webpush.Sender {
+	t.Helper()
+	sender, err := webpush.New(context.Background(), pushKeyStore{}, "mailto:ops@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sender
+}
+
+func testSubscription(t *testing.T, endpoint string) string {
+	t.Helper()
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	auth := make([]byte, 16)
+	_, _ = rand.Read(auth)
+	body, _ := json.Marshal(model.PushSubscribeRequest{
+		Endpoint: endpoint,
+		Keys: model.PushSubscriptionKeys{
+			P256DH: base64.RawURLEncoding.EncodeToString(key.PublicKey().Bytes()),
+			Auth:   base64.RawURLEncoding.EncodeToString(auth),
+		},
+	})
+	return string(body)
+}
+
+func TestPushSubscribeStoresTheBrowser(t *testing.T) {
+	manager := testSessionManager(t, false, func() time.Time { return testSessionTime })
+	store := &authTestStore{accountExists: true}
+	sender := testPushSender(t)
+	handler := New(Config{Passcode: "123456", Sessions: manager, Push: sender}, store)
+
+	response := httptest.NewRecorder()
+	handler.ServeHTTP(response, sessionRequest(t, manager, http.MethodGet, "/api/v1/push/key"))
+	if response.Code != http.StatusOK || !strings.Contains(response.Body.String(), sender.PublicKey()) {
+		t.Fatalf("key = %d; body = %s", response.Code, response.Body.String())
+	}
+
+	request := sessionRequest(t, manager, http.MethodPost, "/api/v1/push/subscribe")
+	request.Body = io.NopCloser(strings.NewReader(testSubscription(t, "https://push.example/send/abc")))
+	response = httptest.NewRecorder()
+	handler.ServeHTTP(response, request)
+	if response.Code != http.StatusCreated {
+		t.Fatalf("subscribe = %d; body = %s", response.Code, response.Body.String())
+	}
+	if len(store.pushSaved) != 1 || store.pushSaved[0].Endpoint != "https://push.example/send/abc" || len(store.pushSaved[0].P256DH) != 65 || len(store.pushSaved[0].Auth) != 16 {
+		t.Fatalf("saved = %+v", store.pushSaved)
+	}
+
+	store.pushExists = true
+	request = sessionRequest(t, manager, http.MethodPost, "/api/v1/push/subscribe")
+	request.Body = io.NopCloser(strings.NewReader(testSubscription(t, "https://push.example/send/abc")))
+	response = httptest.NewRecorder()
+	handler.ServeHTTP(response, request)
+	if response.Code != http.StatusOK {
+		t.Fatalf("resubscribe = %d; body = %s", response.Code, response.Body.String())
+	}
+
+	request = sessionRequest(t, manager, http.MethodPost, "/api/v1/push/unsubscribe")
+	request.Body = io.NopCloser(strings.NewReader(`{"endpoint":"https://push.example/send/abc"}`))
+	response = httptest.NewRecorder()
+	handler.ServeHTTP(response, request)
+	if response.Code != http.StatusNoContent {
+		t.Fatalf("unsubscribe = %d; body = %s", response.Code, response.Body.String())
+	}
+}
+
+func TestPushSubscribeFailureContracts(t *testing.T) {
+	manager := testSessionManager(t, false, func() time.Time { return testSessionTime })
+	tests := []struct {
+		name     string
+		disabled bool
+		body     string
+		err      error
+		status   int
+		code     string
+	}{
+		{name: "unconfigured", disabled: true, status: http.StatusServiceUnavailable, code: "push_unavailable"},
+		{name: "plain http", body: testSubscription(t, "http://push.example/send"), status: http.StatusBadRequest, code: "push_subscription_invalid"},
+		{name: "bad keys", body: `{"endpoint":"https://push.example/send","keys":{"p256dh":"AAAA","auth":"AAAA"}}`, status: http.StatusBadRequest, code: "push_subscription_invalid"},
+		{name: "limit", err: fmt.Errorf("%w", model.ErrPushSubscriptionLimit), status: http.StatusConflict, code: "push_subscription_limit"},
+		{name: "database", err: fmt.Errorf("database unavailable"), status: http.StatusInternalServerError, code: "db"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			store := &authTestStore{accountExists: true, pushErr: test.err}
+			cfg := Config{Passcode: "123456", Sessions: manager}
+			if !test.disabled {
+				cfg.Push = testPushSender(t)
+			}
+			body := test.body
+			if body == "" {
+				body = testSubscription(t, "https://push.example/send")
+			}
+			request := sessionRequest(t, manager, http.MethodPost, "/api/v1/push/subscribe")
+			request.Body = io.NopCloser(strings.NewReader(body))
+			response := httptest.NewRecorder()
+			New(cfg, store).ServeHTTP(response, request)
+			if response.Code != test.status || !strings.Contains(response.Body.String(), `"code":"`+test.code+`"`) {
+				t.Fatalf("status = %d; body = %s", response.Code, response.Body.String())
+			}
+		})
+	}
+}
+
+func TestPushUnsubscribeUnknownEndpoint(t *testing.T) {
+	manager := testSessionManager(t, false, func() time.Time { return testSessionTime })
+	store := &authTestStore{accountExists: true, pushErr: fmt.Errorf("%w", model.ErrPushSubscriptionNotFound)}
+	request := sessionRequest(t, manager, http.MethodPost, "/api/v1/push/unsubscribe")
+	request.Body = io.NopCloser(strings.NewReader(`{"endpoint":"https://push.example/send/abc"}`))
+	response := httptest.NewRecorder()
+	testHandler(t, store, manager).ServeHTTP(response, request)
+	if response.Code != http.StatusNotFound {
+		t.Fatalf("status = %d; body = %s", response.Code, response.Body.String())
+	}
+}
+
+func TestPushSettingsRoundTrip(t *testing.T) {
+	manager := testSessionManager(t, false, func() time.Time { return testSessionTime })
+	store := &authTestStore{accountExists: true}
+	handler := testHandler(t, store, manager)
+
+	response := httptest.NewRecorder()
+	handler.ServeHTTP(response, sessionRequest(t, manager, http.MethodGet, "/api/v1/push/settings"))
+	if response.Code != http.StatusOK || !strings.Contains(response.Body.String(), `"reminderTime":"19:00"`) {
+		t.Fatalf("settings = %d; body = %s", response.Code, response.Body.String())
+	}
+
+	request := sessionRequest(t, manager, http.MethodPut, "/api/v1/push/settings")
+	request.Body = io.NopCloser(strings.NewReader(`{"reminders":true,"reminderTime":"18:30","timeZone":"Europe/London","newStories":false}`))
+	response = httptest.NewRecorder()
+	handler.ServeHTTP(response, request)
+	if response.Code != http.StatusOK || store.pushSettings == nil || store.pushSettings.ReminderTime != "18:30" || store.pushSettings.NewStories {
+		t.Fatalf("put = %d; body = %s", response.Code, response.Body.String())
+	}
+
+	for _, body := range []string{
+		`{"reminders":true,"reminderTime":"24:00","timeZone":"UTC","newStories":true}`,
+		`{"reminders":true,"reminderTime":"18:30","timeZone":"Local","newStories":true}`,
+		`{"reminders":true,"reminderTime":"18:30","timeZone":"Mars/Olympus","newStories":true}`,
+	} {
+		request := sessionRequest(t, manager, http.MethodPut, "/api/v1/push/settings")
+		request.Body = io.NopCloser(strings.NewReader(body))
+		response := httptest.NewRecorder()
+		handler.ServeHTTP(response, request)
+		if response.Code != http.StatusBadRequest || !strings.Contains(response.Body.String(), "push_settings_invalid") {
+			t.Fatalf("%s = %d; body = %s", body, response.Code, response.Body.String())
+		}
+	}
+}
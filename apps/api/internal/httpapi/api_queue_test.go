@@ -0,0 +1,90 @@
+package httpapi
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"pandapages/api/internal/model"
+)
+
+func TestQueueAddsReordersAndRemovesStories(t *testing.T) {
+	manager := testSessionManager(t, false, func() time.Time { return testSessionTime })
+	store := &authTestStore{accountExists: true}
+	handler := testHandler(t, store, manager)
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		request := sessionRequest(t, manager, method, path)
+		if body != "" {
+			request.Body = io.NopCloser(strings.NewReader(body))
+		}
+		response := httptest.NewRecorder()
+		handler.ServeHTTP(response, request)
+		return response
+	}
+
+	for _, slug := range []string{"owls", "boats"} {
+		if rec := serve(http.MethodPost, "/api/v1/queue/"+slug, ""); rec.Code != http.StatusCreated {
+			t.Fatalf("add %s status = %d; body = %s", slug, rec.Code, rec.Body)
+		}
+	}
+	rec := serve(http.MethodPost, "/api/v1/queue/owls", "")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"items":[{"slug":"owls"`) {
+		t.Fatalf("add again status = %d; body = %s", rec.Code, rec.Body)
+	}
+
+	rec = serve(http.MethodPut, "/api/v1/queue", `{"slugs":[" boats ","owls"]}`)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"items":[{"slug":"boats"`) {
+		t.Fatalf("reorder status = %d; body = %s", rec.Code, rec.Body)
+	}
+	if rec := serve(http.MethodPut, "/api/v1/queue", `{"slugs":["boats"]}`); rec.Code != http.StatusConflict ||
+		!strings.Contains(rec.Body.String(), `"code":"queue_order_mismatch"`) {
+		t.Fatalf("partial reorder status = %d; body = %s", rec.Code, rec.Body)
+	}
+	if rec := serve(http.MethodPut, "/api/v1/queue", `{}`); rec.Code != http.StatusBadRequest {
+		t.Fatalf("empty reorder status = %d; body = %s", rec.Code, rec.Body)
+	}
+
+	rec = serve(http.MethodGet, "/api/v1/continue", "")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"upNext":[{"slug":"boats","title":"BOATS"`) {
+		t.Fatalf("continue status = %d; body = %s", rec.Code, rec.Body)
+	}
+
+	if rec := serve(http.MethodDelete, "/api/v1/queue/boats", ""); rec.Code != http.StatusOK || strings.Contains(rec.Body.String(), "boats") {
+		t.Fatalf("remove status = %d; body = %s", rec.Code, rec.Body)
+	}
+	if rec := serve(http.MethodDelete, "/api/v1/queue/boats", ""); rec.Code != http.StatusNotFound {
+		t.Fatalf("remove again status = %d; body = %s", rec.Code, rec.Body)
+	}
+	rec = serve(http.MethodGet, "/api/v1/queue", "")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"items":[{"slug":"owls"`) || rec.Header().Get("Cache-Control") != "no-store" {
+		t.Fatalf("list status = %d; body = %s", rec.Code, rec.Body)
+	}
+}
+
+func TestQueueAddFailureContracts(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+		code   string
+	}{
+		{err: sql.ErrNoRows, status: http.StatusNotFound, code: "not_found"},
+		{err: fmt.Errorf("%w", model.ErrStoryRemoved), status: http.StatusGone, code: "story_removed"},
+		{err: fmt.Errorf("%w", model.ErrQueueFull), status: http.StatusConflict, code: "queue_full"},
+		{err: fmt.Errorf("private failure"), status: http.StatusInternalServerError, code: "db"},
+	}
+	for _, test := range tests {
+		manager := testSessionManager(t, false, func() time.Time { return testSessionTime })
+		store := &authTestStore{accountExists: true, queueErr: test.err}
+		response := httptest.NewRecorder()
+		testHandler(t, store, manager).ServeHTTP(response, sessionRequest(t, manager, http.MethodPost, "/api/v1/queue/owls"))
+		if response.Code != test.status || !strings.Contains(response.Body.String(), `"code":"`+test.code+`"`) ||
+			strings.Contains(response.Body.String(), "private") {
+			t.Fatalf("%v: status = %d; body = %s", test.err, response.Code, response.Body)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package httpapi
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"pandapages/api/internal/model"
+)
+
+func TestStoryReactionSetsAndTakesBackTheProfilesReaction(t *testing.T) {
+	manager := testSessionManager(t, false, func() time.Time { return testSessionTime })
+	store := &authTestStore{accountExists: true}
+	handler := testHandler(t, store, manager)
+	serve := func(method, body string) *httptest.ResponseRecorder {
+		request := sessionRequest(t, manager, method, "/api/v1/story/owls/reaction")
+		if body != "" {
+			request.Body = io.NopCloser(strings.NewReader(body))
+		}
+		response := httptest.NewRecorder()
+		handler.ServeHTTP(response, request)
+		return response
+	}
+
+	rec := serve(http.MethodPost, `{"reaction":" loved_it "}`)
+	if rec.Code != http.StatusOK || rec.Body.String() != `{"reaction":"loved_it","counts":{"lovedIt":1,"silly":2,"scary":0}}`+"\n" ||
+		rec.Header().Get("Cache-Control") != "no-store" {
+		t.Fatalf("react status = %d; body = %q", rec.Code, rec.Body)
+	}
+	if store.reaction == nil || *store.reaction != model.ReactionLovedIt {
+		t.Fatalf("stored reaction = %v", store.reaction)
+	}
+
+	rec = serve(http.MethodPost, `{"reaction":"boring"}`)
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), `"path":"reaction"`) {
+		t.Fatalf("unknown reaction status = %d; body = %s", rec.Code, rec.Body)
+	}
+
+	rec = serve(http.MethodDelete, "")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"reaction":null`) || store.reaction != nil {
+		t.Fatalf("take back status = %d; body = %s", rec.Code, rec.Body)
+	}
+
+	if rec := serve(http.MethodGet, ""); rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("GET status = %d; body = %s", rec.Code, rec.Body)
+	}
+}
+
+func TestStoryReactionFailureContracts(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+		code   string
+	}{
+		{err: sql.ErrNoRows, status: http.StatusNotFound, code: "not_found"},
+		{err: fmt.Errorf("%w", model.ErrStoryRemoved), status: http.StatusGone, code: "story_removed"},
+		{err: fmt.Errorf("private failure"), status: http.StatusInternalServerError, code: "db"},
+	}
+	for _, test := range tests {
+		manager := testSessionManager(t, false, func() time.Time { return testSessionTime })
+		store := &authTestStore{accountExists: true, reactionErr: test.err}
+		request := sessionRequest(t, manager, http.MethodPost, "/api/v1/story/owls/reaction")
+		request.Body = io.NopCloser(strings.NewReader(`{"reaction":"scary"}`))
+		response := httptest.NewRecorder()
+		testHandler(t, store, manager).ServeHTTP(response, request)
+		if response.Code != test.status || !strings.Contains(response.Body.String(), `"code":"`+test.code+`"`) ||
+			strings.Contains(response.Body.String(), "private") {
+			t.Fatalf("%v: status = %d; body = %s", test.err, response.Code, response.Body)
+		}
+	}
+}
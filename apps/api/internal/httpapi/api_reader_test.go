@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"encoding/json"
 	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -52,8 +53,8 @@ func TestReaderEndpointReturnsOneSafeCoherentPayload(t *testing.T) {
 	if store.readerCalls != 1 || store.readerAccount != testAccountID || store.readerSlug != "moonlit-cafe" {
 		t.Fatalf("ReaderStory calls/scope = %d %q %q", store.readerCalls, store.readerAccount, store.readerSlug)
 	}
-	if response.Header().Get("Cache-Control") != "no-store" {
-		t.Fatal("Reader response is cacheable")
+	if response.Header().Get("Cache-Control") != "private, no-cache" || response.Header().Get("ETag") == "" {
+		t.Fatalf("Reader caching headers = %q %q", response.Header().Get("Cache-Control"), response.Header().Get("ETag"))
 	}
 	var payload map[string]any
 	if err := json.Unmarshal(response.Body.Bytes(), &payload); err != nil {
@@ -74,6 +75,112 @@ func TestReaderEndpointReturnsOneSafeCoherentPayload(t *testing.T) {
 	}
 }
 
+func TestReaderEndpointRevalidatesUnchangedPayloads(t *testing.T) {
+	manager := testSessionManager(t, false, func() time.Time { return testSessionTime })
+	store := &authTestStore{
+		accountExists: true,
+		readerResponse: model.ReaderStory{
+			Slug:     "moonlit-cafe",
+			Title:    "Moonlit Café",
+			Language: "en",
+			Version:  1,
+			Segments: []model.ReaderSegment{{Ordinal: 1, Kind: "paragraph", ContentKey: strings.Repeat("a", 64), ContentOccurrence: 1, RenderedHTML: "<p>Hello.</p>", WordCount: 1}},
+		},
+	}
+	handler := testHandler(t, store, manager)
+	first := httptest.NewRecorder()
+	handler.ServeHTTP(first, sessionRequest(t, manager, http.MethodGet, "/api/v1/reader/moonlit-cafe"))
+	etag := first.Header().Get("ETag")
+	if first.Code != http.StatusOK || etag == "" {
+		t.Fatalf("first response = %d %q", first.Code, etag)
+	}
+
+	request := sessionRequest(t, manager, http.MethodGet, "/api/v1/reader/moonlit-cafe")
+	request.Header.Set("If-None-Match", `"other", W/`+etag)
+	unchanged := httptest.NewRecorder()
+	handler.ServeHTTP(unchanged, request)
+	if unchanged.Code != http.StatusNotModified || unchanged.Body.Len() != 0 || unchanged.Header().Get("ETag") != etag {
+		t.Fatalf("revalidation = %d %q; body = %q", unchanged.Code, unchanged.Header().Get("ETag"), unchanged.Body.String())
+	}
+
+	request = sessionRequest(t, manager, http.MethodGet, "/api/v1/reader/moonlit-cafe?include=meta")
+	request.Header.Set("If-None-Match", etag)
+	projected := httptest.NewRecorder()
+	handler.ServeHTTP(projected, request)
+	if projected.Code != http.StatusOK || projected.Header().Get("ETag") == etag {
+		t.Fatalf("metadata only = %d %q", projected.Code, projected.Header().Get("ETag"))
+	}
+
+	// The database moves ChangedAt with every change the Reader could show.
+	store.readerResponse.Title = "Moonlit Café, revised"
+	store.readerResponse.ChangedAt = testSessionTime
+	request = sessionRequest(t, manager, http.MethodGet, "/api/v1/reader/moonlit-cafe")
+	request.Header.Set("If-None-Match", etag)
+	changed := httptest.NewRecorder()
+	handler.ServeHTTP(changed, request)
+	if changed.Code != http.StatusOK || changed.Header().Get("ETag") == etag {
+		t.Fatalf("changed story = %d %q", changed.Code, changed.Header().Get("ETag"))
+	}
+	if changed.Header().Get("Content-Length") != "" || !strings.Contains(changed.Body.String(), "revised") {
+		t.Fatalf("changed story Content-Length = %q; body = %q", changed.Header().Get("Content-Length"), changed.Body.String())
+	}
+}
+
+func TestReaderEndpointRevalidatesByLastModified(t *testing.T) {
+	manager := testSessionManager(t, false, func() time.Time { return testSessionTime })
+	changedAt := time.Date(2026, 10, 16, 19, 30, 15, 500_000_000, time.UTC)
+	store := &authTestStore{
+		accountExists: true,
+		readerResponse: model.ReaderStory{
+			Slug:      "moonlit-cafe",
+			Title:     "Moonlit Café",
+			Language:  "en",
+			Version:   1,
+			Segments:  []model.ReaderSegment{{Ordinal: 1, Kind: "paragraph", ContentKey: strings.Repeat("a", 64), ContentOccurrence: 1, RenderedHTML: "<p>Hello.</p>", WordCount: 1}},
+			ChangedAt: changedAt,
+		},
+	}
+	handler := testHandler(t, store, manager)
+	first := httptest.NewRecorder()
+	handler.ServeHTTP(first, sessionRequest(t, manager, http.MethodGet, "/api/v1/reader/moonlit-cafe"))
+	lastModified := first.Header().Get("Last-Modified")
+	if first.Code != http.StatusOK || lastModified != "Fri, 16 Oct 2026 19:30:15 GMT" {
+		t.Fatalf("first response = %d, Last-Modified %q", first.Code, lastModified)
+	}
+
+	revalidate := func(ifModifiedSince, ifNoneMatch string) *httptest.ResponseRecorder {
+		t.Helper()
+		request := sessionRequest(t, manager, http.MethodGet, "/api/v1/reader/moonlit-cafe")
+		request.Header.Set("If-Modified-Since", ifModifiedSince)
+		if ifNoneMatch != "" {
+			request.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		response := httptest.NewRecorder()
+		handler.ServeHTTP(response, request)
+		return response
+	}
+	if unchanged := revalidate(lastModified, ""); unchanged.Code != http.StatusNotModified || unchanged.Body.Len() != 0 ||
+		unchanged.Header().Get("Last-Modified") != lastModified {
+		t.Fatalf("revalidation = %d, Last-Modified %q; body = %q", unchanged.Code, unchanged.Header().Get("Last-Modified"), unchanged.Body.String())
+	}
+	if older := revalidate("Fri, 16 Oct 2026 19:30:14 GMT", ""); older.Code != http.StatusOK {
+		t.Fatalf("older If-Modified-Since = %d, want 200", older.Code)
+	}
+	if mismatched := revalidate(lastModified, `"other"`); mismatched.Code != http.StatusOK {
+		t.Fatalf("If-None-Match mismatch with matching date = %d, want 200", mismatched.Code)
+	}
+
+	store.readerResponse.ChangedAt = changedAt.Add(time.Minute)
+	if changed := revalidate(lastModified, ""); changed.Code != http.StatusOK || changed.Header().Get("Last-Modified") != "Fri, 16 Oct 2026 19:31:15 GMT" {
+		t.Fatalf("changed story = %d, Last-Modified %q", changed.Code, changed.Header().Get("Last-Modified"))
+	}
+
+	store.readerResponse.ChangedAt = time.Now()
+	if fresh := revalidate(lastModified, ""); fresh.Code != http.StatusOK || fresh.Header().Get("Last-Modified") != "" {
+		t.Fatalf("story changed this second = %d, Last-Modified %q", fresh.Code, fresh.Header().Get("Last-Modified"))
+	}
+}
+
 func TestReaderEndpointMethodAndFailureContracts(t *testing.T) {
 	manager := testSessionManager(t, false, func() time.Time { return testSessionTime })
 	tests := []struct {
@@ -84,6 +191,7 @@ func TestReaderEndpointMethodAndFailureContracts(t *testing.T) {
 		wantAllow  string
 	}{
 		{name: "missing story", method: http.MethodGet, store: &authTestStore{accountExists: true, readerErr: sql.ErrNoRows}, wantStatus: http.StatusNotFound},
+		{name: "removed story", method: http.MethodGet, store: &authTestStore{accountExists: true, readerErr: model.ErrStoryRemoved}, wantStatus: http.StatusGone},
 		{name: "store failure", method: http.MethodGet, store: &authTestStore{accountExists: true, readerErr: errors.New("private SQL detail")}, wantStatus: http.StatusInternalServerError},
 		{name: "method mismatch", method: http.MethodPost, store: &authTestStore{accountExists: true}, wantStatus: http.StatusMethodNotAllowed, wantAllow: http.MethodGet},
 	}
@@ -107,6 +215,302 @@ func TestReaderEndpointMethodAndFailureContracts(t *testing.T) {
 	}
 }
 
+func TestReaderVocabularyEndpoint(t *testing.T) {
+	manager := testSessionManager(t, false, func() time.Time { return testSessionTime })
+	store := &authTestStore{
+		accountExists: true,
+		vocabulary: model.Vocabulary{
+			Words:         3,
+			DistinctWords: 2,
+			Frequencies:   map[string]int{"moon": 2, "lantern": 1},
+			RareWords:     []string{"lantern"},
+		},
+	}
+	response := httptest.NewRecorder()
+	testHandler(t, store, manager).ServeHTTP(
+		response,
+		sessionRequest(t, manager, http.MethodGet, "/api/v1/reader/moonlit-cafe/vocabulary"),
+	)
+	if response.Code != http.StatusOK {
+		t.Fatalf("status = %d; body = %s", response.Code, response.Body.String())
+	}
+	if store.readerCalls != 0 || store.readerAccount != testAccountID || store.vocabularySlug != "moonlit-cafe" {
+		t.Fatalf("vocabulary scope = %d %q %q", store.readerCalls, store.readerAccount, store.vocabularySlug)
+	}
+	var payload model.Vocabulary
+	if err := json.Unmarshal(response.Body.Bytes(), &payload); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if payload.Words != 3 || payload.Frequencies["moon"] != 2 || len(payload.RareWords) != 1 {
+		t.Fatalf("vocabulary = %#v", payload)
+	}
+
+	missing := httptest.NewRecorder()
+	testHandler(t, &authTestStore{accountExists: true, vocabularyErr: sql.ErrNoRows}, manager).ServeHTTP(
+		missing,
+		sessionRequest(t, manager, http.MethodGet, "/api/v1/reader/moonlit-cafe/vocabulary"),
+	)
+	if missing.Code != http.StatusNotFound {
+		t.Fatalf("missing status = %d, want 404", missing.Code)
+	}
+}
+
+func TestStoryQuizEndpoint(t *testing.T) {
+	manager := testSessionManager(t, false, func() time.Time { return testSessionTime })
+	title := "The Station"
+	store := &authTestStore{
+		accountExists: true,
+		quiz: model.StoryQuiz{Slug: "moonlit-cafe", Version: 2, Chapters: []model.ChapterQuiz{
+			{Chapter: 1, Title: &title, Questions: []model.QuizQuestion{{Question: "Who yawned?", Answer: "The train"}}},
+		}},
+	}
+	response := httptest.NewRecorder()
+	testHandler(t, store, manager).ServeHTTP(
+		response,
+		sessionRequest(t, manager, http.MethodGet, "/api/v1/story/moonlit-cafe/quiz"),
+	)
+	if response.Code != http.StatusOK || response.Header().Get("ETag") == "" {
+		t.Fatalf("status = %d; body = %s", response.Code, response.Body.String())
+	}
+	if store.readerAccount != testAccountID || store.quizSlug != "moonlit-cafe" {
+		t.Fatalf("quiz scope = %q %q", store.readerAccount, store.quizSlug)
+	}
+	var payload model.StoryQuiz
+	if err := json.Unmarshal(response.Body.Bytes(), &payload); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(payload.Chapters) != 1 || payload.Chapters[0].Questions[0].Answer != "The train" {
+		t.Fatalf("quiz = %#v", payload)
+	}
+
+	for _, test := range []struct {
+		method string
+		err    error
+		status int
+	}{
+		{method: http.MethodGet, err: sql.ErrNoRows, status: http.StatusNotFound},
+		{method: http.MethodGet, err: model.ErrStoryRemoved, status: http.StatusGone},
+		{method: http.MethodDelete, status: http.StatusMethodNotAllowed},
+	} {
+		response := httptest.NewRecorder()
+		testHandler(t, &authTestStore{accountExists: true, quizErr: test.err}, manager).ServeHTTP(
+			response,
+			sessionRequest(t, manager, test.method, "/api/v1/story/moonlit-cafe/quiz"),
+		)
+		if response.Code != test.status {
+			t.Fatalf("%s %v status = %d, want %d", test.method, test.err, response.Code, test.status)
+		}
+	}
+}
+
+func TestStoryDiscussionEndpoint(t *testing.T) {
+	manager := testSessionManager(t, false, func() time.Time { return testSessionTime })
+	title := "The Fox"
+	store := &authTestStore{
+		accountExists: true,
+		discussion: model.StoryDiscussion{Slug: "moonlit-cafe", Version: 2, Chapters: []model.ChapterDiscussion{
+			{Chapter: 1, Title: &title, Questions: []string{"Why do you think the fox was sad?"}},
+		}},
+	}
+	response := httptest.NewRecorder()
+	testHandler(t, store, manager).ServeHTTP(
+		response,
+		sessionRequest(t, manager, http.MethodGet, "/api/v1/story/moonlit-cafe/discussion"),
+	)
+	if response.Code != http.StatusOK || response.Header().Get("ETag") == "" {
+		t.Fatalf("status = %d; body = %s", response.Code, response.Body.String())
+	}
+	if store.readerAccount != testAccountID || store.discussionSlug != "moonlit-cafe" {
+		t.Fatalf("discussion scope = %q %q", store.readerAccount, store.discussionSlug)
+	}
+	var payload model.StoryDiscussion
+	if err := json.Unmarshal(response.Body.Bytes(), &payload); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(payload.Chapters) != 1 || payload.Chapters[0].Questions[0] != "Why do you think the fox was sad?" {
+		t.Fatalf("discussion = %#v", payload)
+	}
+
+	for _, test := range []struct {
+		method string
+		err    error
+		status int
+	}{
+		{method: http.MethodGet, err: sql.ErrNoRows, status: http.StatusNotFound},
+		{method: http.MethodGet, err: model.ErrStoryRemoved, status: http.StatusGone},
+		{method: http.MethodPost, status: http.StatusMethodNotAllowed},
+	} {
+		response := httptest.NewRecorder()
+		testHandler(t, &authTestStore{accountExists: true, discussionErr: test.err}, manager).ServeHTTP(
+			response,
+			sessionRequest(t, manager, test.method, "/api/v1/story/moonlit-cafe/discussion"),
+		)
+		if response.Code != test.status {
+			t.Fatalf("%s %v status = %d, want %d", test.method, test.err, response.Code, test.status)
+		}
+	}
+}
+
+func TestReaderKidModeLeavesOutAsides(t *testing.T) {
+	manager := testSessionManager(t, false, func() time.Time { return testSessionTime })
+	segment := func(ordinal int, kind string) model.ReaderSegment {
+		return model.ReaderSegment{
+			Ordinal:           ordinal,
+			Kind:              kind,
+			ContentKey:        strings.Repeat(string(rune('a'+ordinal)), 64),
+			ContentOccurrence: 1,
+			RenderedHTML:      "<p>text</p>",
+			WordCount:         1,
+		}
+	}
+	read := func(t *testing.T, story model.ReaderStory, path string) (*httptest.ResponseRecorder, model.ReaderStory) {
+		t.Helper()
+		response := httptest.NewRecorder()
+		testHandler(t, &authTestStore{accountExists: true, readerResponse: story}, manager).ServeHTTP(
+			response,
+			sessionRequest(t, manager, http.MethodGet, path),
+		)
+		var payload model.ReaderStory
+		if response.Code == http.StatusOK {
+			if err := json.Unmarshal(response.Body.Bytes(), &payload); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+		}
+		return response, payload
+	}
+	story := model.ReaderStory{
+		Slug: "moonlit-cafe", Title: "Moonlit Café", Language: "en", Version: 1,
+		Segments: []model.ReaderSegment{segment(1, "paragraph"), segment(2, "aside"), segment(3, "paragraph")},
+	}
+
+	for _, path := range []string{"/api/v1/reader/moonlit-cafe", "/api/v1/reader/moonlit-cafe?mode=grownup"} {
+		response, payload := read(t, story, path)
+		if response.Code != http.StatusOK || len(payload.Segments) != 3 {
+			t.Fatalf("%s: status = %d, segments = %#v", path, response.Code, payload.Segments)
+		}
+	}
+	response, payload := read(t, story, "/api/v1/reader/moonlit-cafe?mode=kid")
+	if response.Code != http.StatusOK {
+		t.Fatalf("kid status = %d; body = %s", response.Code, response.Body.String())
+	}
+	if len(payload.Segments) != 2 || payload.Segments[0].Ordinal != 1 || payload.Segments[1].Ordinal != 3 {
+		t.Fatalf("kid segments = %#v", payload.Segments)
+	}
+
+	onlyAsides := story
+	onlyAsides.Segments = []model.ReaderSegment{segment(1, "aside")}
+	if response, _ := read(t, onlyAsides, "/api/v1/reader/moonlit-cafe?mode=kid"); response.Code != http.StatusNotFound {
+		t.Fatalf("aside-only kid status = %d, want 404", response.Code)
+	}
+	if response, _ := read(t, story, "/api/v1/reader/moonlit-cafe?mode=toddler"); response.Code != http.StatusBadRequest {
+		t.Fatalf("unknown mode status = %d, want 400", response.Code)
+	}
+}
+
+func TestReaderServesAStandalonePageToBrowsersThatAskForOne(t *testing.T) {
+	manager := testSessionManager(t, false, func() time.Time { return testSessionTime })
+	author := "A & B"
+	store := &authTestStore{
+		accountExists: true,
+		readerResponse: model.ReaderStory{
+			Slug: "moonlit-cafe", Title: "Moonlit <Café>", Author: &author, Language: "en-GB", Version: 1,
+			Segments: []model.ReaderSegment{{Ordinal: 1, Kind: "paragraph", ContentKey: strings.Repeat("a", 64), ContentOccurrence: 1, RenderedHTML: `<p>Hello <img src="/api/v1/media/x" alt=""></p>`, WordCount: 1}},
+		},
+	}
+	handler := testHandler(t, store, manager)
+	read := func(path, accept string) *httptest.ResponseRecorder {
+		request := sessionRequest(t, manager, http.MethodGet, path)
+		if accept != "" {
+			request.Header.Set("Accept", accept)
+		}
+		response := httptest.NewRecorder()
+		handler.ServeHTTP(response, request)
+		return response
+	}
+
+	for _, tc := range []struct{ path, accept string }{
+		{"/api/v1/reader/moonlit-cafe.html", ""},
+		{"/api/v1/reader/moonlit-cafe", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8"},
+	} {
+		page := read(tc.path, tc.accept)
+		body := page.Body.String()
+		if page.Code != http.StatusOK || page.Header().Get("Content-Type") != "text/html; charset=utf-8" || page.Header().Get("ETag") == "" {
+			t.Fatalf("%s %q = %d %v", tc.path, tc.accept, page.Code, page.Header())
+		}
+		if !strings.Contains(body, `<html lang="en-GB">`) || !strings.Contains(body, "<h1>Moonlit &lt;Café&gt;</h1>") ||
+			!strings.Contains(body, "<p>A &amp; B</p>") || !strings.Contains(body, `<p>Hello <img src="/api/v1/media/x" alt=""></p>`) {
+			t.Fatalf("%s page = %s", tc.path, body)
+		}
+		if policy := page.Header().Get("Content-Security-Policy"); !strings.Contains(policy, "default-src 'none'") || strings.Contains(policy, "script-src") {
+			t.Fatalf("%s policy = %q", tc.path, policy)
+		}
+	}
+
+	for _, accept := range []string{"", "*/*", "application/json", "text/html;q=0.5, application/json"} {
+		response := read("/api/v1/reader/moonlit-cafe", accept)
+		if response.Code != http.StatusOK || response.Header().Get("Content-Type") != "application/json" || response.Header().Get("Vary") != "Accept" {
+			t.Fatalf("Accept %q = %d %v", accept, response.Code, response.Header())
+		}
+	}
+	if response := read("/api/v1/reader/.html", ""); response.Code != http.StatusNotFound {
+		t.Fatalf("empty slug page = %d, want 404", response.Code)
+	}
+}
+
+func TestReaderIncludeSelectsFields(t *testing.T) {
+	manager := testSessionManager(t, false, func() time.Time { return testSessionTime })
+	story := model.ReaderStory{
+		Slug: "moonlit-cafe", Title: "Moonlit Café", Language: "en", Version: 1,
+		Segments: []model.ReaderSegment{{
+			Ordinal: 1, Kind: "paragraph", ContentKey: strings.Repeat("a", 64), ContentOccurrence: 1,
+			RenderedHTML: "<p>text</p>", WordCount: 1,
+		}},
+	}
+	read := func(t *testing.T, path string) (*httptest.ResponseRecorder, map[string]any) {
+		t.Helper()
+		response := httptest.NewRecorder()
+		testHandler(t, &authTestStore{accountExists: true, readerResponse: story}, manager).ServeHTTP(
+			response,
+			sessionRequest(t, manager, http.MethodGet, path),
+		)
+		var payload map[string]any
+		if response.Code == http.StatusOK {
+			if err := json.Unmarshal(response.Body.Bytes(), &payload); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+		}
+		return response, payload
+	}
+
+	_, meta := read(t, "/api/v1/reader/moonlit-cafe?include=meta")
+	if _, ok := meta["segments"]; ok || meta["title"] != "Moonlit Café" || meta["version"] != float64(1) {
+		t.Fatalf("include=meta payload = %#v", meta)
+	}
+
+	_, outline := read(t, "/api/v1/reader/moonlit-cafe?include=meta,segments")
+	segments, _ := outline["segments"].([]any)
+	if len(segments) != 1 {
+		t.Fatalf("include=segments payload = %#v", outline)
+	}
+	segment := segments[0].(map[string]any)
+	if _, ok := segment["renderedHtml"]; ok || segment["ordinal"] != float64(1) || segment["wordCount"] != float64(1) {
+		t.Fatalf("include=segments segment = %#v", segment)
+	}
+
+	for _, path := range []string{"/api/v1/reader/moonlit-cafe?include=html", "/api/v1/reader/moonlit-cafe"} {
+		response, full := read(t, path)
+		segments, _ := full["segments"].([]any)
+		if len(segments) != 1 || segments[0].(map[string]any)["renderedHtml"] != "<p>text</p>" {
+			t.Fatalf("%s: status %d payload = %#v", path, response.Code, full)
+		}
+	}
+
+	response, _ := read(t, "/api/v1/reader/moonlit-cafe?include=audio")
+	if response.Code != http.StatusBadRequest || !strings.Contains(response.Body.String(), "include_invalid") {
+		t.Fatalf("unknown include = %d %s", response.Code, response.Body.String())
+	}
+}
+
 func TestReaderEndpointAuthenticationAndSessionInfrastructureRemainDistinct(t *testing.T) {
 	manager := testSessionManager(t, false, func() time.Time { return testSessionTime })
 
@@ -149,3 +553,74 @@ func TestReaderOnePathsAreRemoved(t *testing.T) {
 		t.Fatal("removed Reader 1 path reached Reader Store")
 	}
 }
+
+func TestStoryBatchReturnsEachStoryInOrderAndListsTheMissing(t *testing.T) {
+	manager := testSessionManager(t, false, func() time.Time { return testSessionTime })
+	story := func(slug string, kinds ...string) model.ReaderStory {
+		out := model.ReaderStory{Slug: slug, Title: slug, Language: "en", Version: 1, Segments: []model.ReaderSegment{}}
+		for index, kind := range kinds {
+			out.Segments = append(out.Segments, model.ReaderSegment{Ordinal: index + 1, Kind: kind, ContentKey: strings.Repeat("a", 64), ContentOccurrence: 1, RenderedHTML: "<p>text</p>", WordCount: 1})
+		}
+		return out
+	}
+	store := &authTestStore{accountExists: true, readerStories: map[string]model.ReaderStory{
+		"owl":   story("owl", "paragraph", "aside"),
+		"fox":   story("fox", "paragraph"),
+		"notes": story("notes", "aside"),
+	}}
+	handler := testHandler(t, store, manager)
+	batch := func(body string) *httptest.ResponseRecorder {
+		request := sessionRequest(t, manager, http.MethodPost, "/api/v1/stories/batch")
+		request.Body = io.NopCloser(strings.NewReader(body))
+		response := httptest.NewRecorder()
+		handler.ServeHTTP(response, request)
+		return response
+	}
+
+	response := batch(`{"slugs":["fox","gone","owl","notes"],"mode":"kid","include":"segments"}`)
+	if response.Code != http.StatusOK || response.Header().Get("Cache-Control") != "no-store" {
+		t.Fatalf("batch = %d %v; body = %s", response.Code, response.Header(), response.Body.String())
+	}
+	var out struct {
+		Items   []map[string]any `json:"items"`
+		Missing []string         `json:"missing"`
+	}
+	if err := json.Unmarshal(response.Body.Bytes(), &out); err != nil {
+		t.Fatalf("decode %s: %v", response.Body.String(), err)
+	}
+	if len(out.Items) != 2 || out.Items[0]["slug"] != "fox" || out.Items[1]["slug"] != "owl" || strings.Join(out.Missing, ",") != "gone,notes" {
+		t.Fatalf("batch = %s", response.Body.String())
+	}
+	segments := out.Items[1]["segments"].([]any)
+	if len(segments) != 1 {
+		t.Fatalf("kid mode kept the aside: %#v", segments)
+	}
+	if _, ok := segments[0].(map[string]any)["renderedHtml"]; ok {
+		t.Fatalf("include=segments sent HTML: %#v", segments)
+	}
+
+	if response := batch(`{"slugs":["fox"]}`); response.Code != http.StatusOK || !strings.HasSuffix(response.Body.String(), `],"missing":[]}`+"\n") {
+		t.Fatalf("single batch = %d %s", response.Code, response.Body.String())
+	}
+	for body, path := range map[string]string{
+		`{"slugs":[]}`:                     "slugs",
+		`{"slugs":["fox","fox"]}`:          "slugs.1",
+		`{"slugs":["Not A Slug"]}`:         "slugs.0",
+		`{"slugs":["fox"],"mode":"baby"}`:  "mode",
+		`{"slugs":["fox"],"include":"xx"}`: "include",
+	} {
+		response := batch(body)
+		if response.Code != http.StatusBadRequest || !strings.Contains(response.Body.String(), `"path":"`+path+`"`) {
+			t.Fatalf("%s = %d %s", body, response.Code, response.Body.String())
+		}
+	}
+	many := `{"slugs":["s0"` + strings.Repeat(`,"s0"`, maxBatchSlugs) + `]}`
+	if response := batch(many); response.Code != http.StatusBadRequest {
+		t.Fatalf("oversized batch = %d", response.Code)
+	}
+	get := httptest.NewRecorder()
+	handler.ServeHTTP(get, sessionRequest(t, manager, http.MethodGet, "/api/v1/stories/batch"))
+	if get.Code != http.StatusMethodNotAllowed || get.Header().Get("Allow") != http.MethodPost {
+		t.Fatalf("GET batch = %d %v", get.Code, get.Header())
+	}
+}
@@ -0,0 +1,81 @@
+package httpapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"pandapages/api/internal/model"
+)
+
+type fakeEmbedder struct {
+	texts []string
+	err   error
+}
+
+func (*fakeEmbedder) Name() string  { return "fake" }
+func (*fakeEmbedder) Model() string { return "fake/small" }
+
+func (e *fakeEmbedder) Embed(_ context.Context, texts []string) ([][]float32, error) {
+	e.texts = texts
+	if e.err != nil {
+		return nil, e.err
+	}
+	return [][]float32{{3, 4}}, nil
+}
+
+func TestSemanticSearchEmbedsTheQuery(t *testing.T) {
+	manager := testSessionManager(t, false, func() time.Time { return testSessionTime })
+	store := &authTestStore{
+		accountExists: true,
+		searchResults: []model.SemanticSearchResult{{Slug: "the-brave-boat", Title: "The Brave Boat", Language: "en", Score: 0.82}},
+	}
+	embedder := &fakeEmbedder{}
+	handler := New(Config{Passcode: "123456", Sessions: manager, Embedder: embedder}, store)
+
+	response := httptest.NewRecorder()
+	handler.ServeHTTP(response, sessionRequest(t, manager, http.MethodGet, "/api/v1/search/semantic?q=+the+one+about+the+brave+little+boat+&limit=5"))
+
+	if response.Code != http.StatusOK || !strings.Contains(response.Body.String(), `"slug":"the-brave-boat"`) {
+		t.Fatalf("status = %d; body = %s", response.Code, response.Body.String())
+	}
+	if len(embedder.texts) != 1 || embedder.texts[0] != "the one about the brave little boat" {
+		t.Fatalf("embedded %q", embedder.texts)
+	}
+	if store.searchModel != "fake/small" || store.searchLimit != 5 || len(store.searchQuery) != 2 || store.searchQuery[0] != 0.6 || store.searchQuery[1] != 0.8 {
+		t.Fatalf("SemanticSearch(%q, %v, %d)", store.searchModel, store.searchQuery, store.searchLimit)
+	}
+}
+
+func TestSemanticSearchFailureContracts(t *testing.T) {
+	manager := testSessionManager(t, false, func() time.Time { return testSessionTime })
+	tests := []struct {
+		name     string
+		path     string
+		embedder *fakeEmbedder
+		status   int
+		code     string
+	}{
+		{name: "query", path: "/api/v1/search/semantic?q=+", embedder: &fakeEmbedder{}, status: http.StatusBadRequest, code: "query_invalid"},
+		{name: "limit", path: "/api/v1/search/semantic?q=boats&limit=26", embedder: &fakeEmbedder{}, status: http.StatusBadRequest, code: "limit_invalid"},
+		{name: "unconfigured", path: "/api/v1/search/semantic?q=boats", status: http.StatusServiceUnavailable, code: "search_unavailable"},
+		{name: "provider", path: "/api/v1/search/semantic?q=boats", embedder: &fakeEmbedder{err: errors.New("quota")}, status: http.StatusBadGateway, code: "search_failed"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cfg := Config{Passcode: "123456", Sessions: manager}
+			if test.embedder != nil {
+				cfg.Embedder = test.embedder
+			}
+			response := httptest.NewRecorder()
+			New(cfg, &authTestStore{accountExists: true}).ServeHTTP(response, sessionRequest(t, manager, http.MethodGet, test.path))
+			if response.Code != test.status || !strings.Contains(response.Body.String(), `"code":"`+test.code+`"`) {
+				t.Fatalf("status = %d, want %d %s; body = %s", response.Code, test.status, test.code, response.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package httpapi
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"pandapages/api/internal/model"
+	"pandapages/api/internal/storyingest"
+)
+
+// maxBatchSlugs bounds one batch request, which holds every story it names
+// in memory before answering.
+const maxBatchSlugs = 25
+
+type storyBatchRequest struct {
+	Slugs   []string `json:"slugs"`
+	Include string   `json:"include"`
+	Mode    string   `json:"mode"`
+}
+
+// serveStoryBatch answers POST /api/v1/stories/batch with the Reader payload
+// of each named story, in the order asked, for clients filling an offline
+// shelf. include and mode mean what they mean on the Reader URL. Slugs with
+// nothing to read, including a story of asides in kid mode, are listed under
+// missing rather than failing the batch.
+func serveStoryBatch(store Store, w http.ResponseWriter, r *http.Request, accountID string) {
+	var body storyBatchRequest
+	if err := decodeJSON(w, r, &body); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
+	var fields []model.FieldError
+	if len(body.Slugs) == 0 || len(body.Slugs) > maxBatchSlugs {
+		fields = append(fields, model.FieldError{Path: "slugs", Code: "out_of_range", Message: "slugs must name 1 to " + strconv.Itoa(maxBatchSlugs) + " stories"})
+	}
+	seen := make(map[string]bool, len(body.Slugs))
+	for index, slug := range body.Slugs {
+		if storyingest.ValidateSlug(slug) != nil {
+			fields = append(fields, model.FieldError{Path: "slugs." + strconv.Itoa(index), Code: "invalid", Message: "slug is invalid"})
+		} else if seen[slug] {
+			fields = append(fields, model.FieldError{Path: "slugs." + strconv.Itoa(index), Code: "duplicate", Message: "slug is already in the batch"})
+		}
+		seen[slug] = true
+	}
+	include, ok := parseInclusion(body.Include)
+	if !ok {
+		fields = append(fields, model.FieldError{Path: "include", Code: "invalid", Message: "include must list meta, segments or html"})
+	}
+	kidMode, ok := readerKidMode(body.Mode)
+	if !ok {
+		fields = append(fields, model.FieldError{Path: "mode", Code: "invalid", Message: "mode must be kid or grownup"})
+	}
+	if len(fields) > 0 {
+		writeFields(w, http.StatusBadRequest, "batch_invalid", "story batch is invalid", fields)
+		return
+	}
+
+	stories := make([]model.ReaderStory, 0, len(body.Slugs))
+	missing := []string{}
+	for _, slug := range body.Slugs {
+		story, err := store.ReaderStory(r.Context(), accountID, slug)
+		if errors.Is(err, sql.ErrNoRows) || errors.Is(err, model.ErrStoryRemoved) {
+			missing = append(missing, slug)
+			continue
+		}
+		if err != nil {
+			writeErr(w, http.StatusInternalServerError, "db", "reader query failed")
+			return
+		}
+		if kidMode {
+			if story.Segments = withoutAsides(story.Segments); len(story.Segments) == 0 {
+				missing = append(missing, slug)
+				continue
+			}
+		}
+		stories = append(stories, story)
+	}
+
+	noStore(w)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	controller := http.NewResponseController(w)
+	_ = encodeStoryBatch(w, stories, missing, include, func() { _ = controller.Flush() })
+}
+
+// encodeStoryBatch writes {"items":[...],"missing":[...]}, each item encoded
+// as the Reader URL would send it.
+func encodeStoryBatch(w io.Writer, stories []model.ReaderStory, missing []string, in inclusion, flush func()) error {
+	if _, err := io.WriteString(w, `{"items":[`); err != nil {
+		return err
+	}
+	for index, story := range stories {
+		if index > 0 {
+			if _, err := io.WriteString(w, ","); err != nil {
+				return err
+			}
+		}
+		if err := encodeReaderStory(w, story, in, flush); err != nil {
+			return err
+		}
+		flush()
+	}
+	tail, err := json.Marshal(missing)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, `],"missing":`+string(tail)+"}\n")
+	return err
+}
@@ -0,0 +1,119 @@
+package httpapi
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"pandapages/api/internal/model"
+)
+
+// serveBedtimeSettings answers GET and PUT /api/v1/bedtime/settings, the
+// window during which the Library's ?mode=bedtime lists only calm, short
+// stories.
+func serveBedtimeSettings(store Store, w http.ResponseWriter, r *http.Request, accountID string) {
+	switch r.Method {
+	case http.MethodGet:
+		settings, err := store.BedtimeSettings(r.Context(), accountID)
+		if err != nil {
+			writeErr(w, http.StatusInternalServerError, "db", "bedtime settings query failed")
+			return
+		}
+		noStore(w)
+		writeJSON(w, http.StatusOK, settings)
+
+	case http.MethodPut:
+		var body model.BedtimeSettings
+		if err := decodeJSON(w, r, &body); err != nil {
+			writeDecodeError(w, err)
+			return
+		}
+		body.Start = strings.TrimSpace(body.Start)
+		body.End = strings.TrimSpace(body.End)
+		body.TimeZone = strings.TrimSpace(body.TimeZone)
+		var fields []model.FieldError
+		if !clockTimeRe.MatchString(body.Start) {
+			fields = append(fields, model.FieldError{Path: "start", Code: "invalid", Message: "start must be HH:MM"})
+		}
+		switch {
+		case !clockTimeRe.MatchString(body.End):
+			fields = append(fields, model.FieldError{Path: "end", Code: "invalid", Message: "end must be HH:MM"})
+		case body.End == body.Start:
+			fields = append(fields, model.FieldError{Path: "end", Code: "invalid", Message: "end must differ from start"})
+		}
+		if !validTimeZone(body.TimeZone) {
+			fields = append(fields, model.FieldError{Path: "timeZone", Code: "invalid", Message: "timeZone must be an IANA time zone such as Europe/London"})
+		}
+		if body.MaxWords < 1 || body.MaxWords > model.MaxBedtimeWords {
+			fields = append(fields, model.FieldError{Path: "maxWords", Code: "invalid", Message: "maxWords must be from 1 to 100000"})
+		}
+		if len(fields) > 0 {
+			writeFields(w, http.StatusBadRequest, "bedtime_settings_invalid", "bedtime settings are invalid", fields)
+			return
+		}
+
+		settings, err := store.PutBedtimeSettings(r.Context(), accountID, body)
+		if errors.Is(err, model.ErrBedtimeTimeZone) {
+			writeFields(w, http.StatusBadRequest, "bedtime_settings_invalid", "bedtime settings are invalid", []model.FieldError{
+				{Path: "timeZone", Code: "invalid", Message: "timeZone must be an IANA time zone such as Europe/London"},
+			})
+			return
+		}
+		if err != nil {
+			writeErr(w, http.StatusInternalServerError, "db", "bedtime settings update failed")
+			return
+		}
+		noStore(w)
+		writeJSON(w, http.StatusOK, settings)
+
+	default:
+		methodNotAllowed(w, []string{http.MethodGet, http.MethodPut})
+	}
+}
+
+// libraryBedtime reads the Library's ?mode and returns the word limit of
+// the account's bedtime window when ?mode=bedtime is asked for while it is
+// open, or 0. It answers the request itself and reports false when it
+// cannot.
+func libraryBedtime(store Store, w http.ResponseWriter, r *http.Request, accountID string, now time.Time) (int, bool) {
+	switch strings.TrimSpace(r.URL.Query().Get("mode")) {
+	case "":
+		return 0, true
+	case "bedtime":
+	default:
+		writeErr(w, http.StatusBadRequest, "mode_invalid", "mode must be bedtime")
+		return 0, false
+	}
+	settings, err := store.BedtimeSettings(r.Context(), accountID)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, "db", "bedtime settings query failed")
+		return 0, false
+	}
+	if !bedtimeWindowOpen(settings, now) {
+		return 0, true
+	}
+	return settings.MaxWords, true
+}
+
+// bedtimeWindowOpen reports whether now falls in the window, which includes
+// its start and not its end. A time zone this process does not know is
+// taken as UTC.
+func bedtimeWindowOpen(settings model.BedtimeSettings, now time.Time) bool {
+	start, startErr := time.Parse("15:04", settings.Start)
+	end, endErr := time.Parse("15:04", settings.End)
+	if startErr != nil || endErr != nil {
+		return false
+	}
+	if location, err := time.LoadLocation(settings.TimeZone); err == nil && settings.TimeZone != "" {
+		now = now.In(location)
+	} else {
+		now = now.UTC()
+	}
+	minute := func(t time.Time) int { return t.Hour()*60 + t.Minute() }
+	at, from, to := minute(now), minute(start), minute(end)
+	if from < to {
+		return at >= from && at < to
+	}
+	return at >= from || at < to
+}
@@ -0,0 +1,61 @@
+package httpapi
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+
+	"pandapages/api/internal/blob"
+)
+
+// redirectToBlob sends the client to a signed URL for the object at key and
+// reports whether it did; a store that cannot sign leaves the caller to
+// serve the bytes. The redirect is not cached, since the URL expires.
+func redirectToBlob(blobs blob.Store, w http.ResponseWriter, r *http.Request, key, filename string) (bool, error) {
+	signed, err := blobs.SignedURL(r.Context(), key, blob.SignedURLLifetime, filename)
+	if errors.Is(err, blob.ErrUnsigned) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	noStore(w)
+	http.Redirect(w, r, signed, http.StatusFound)
+	return true, nil
+}
+
+// storeBlob makes the object at key with build and stores it, unless it is
+// there already, and returns the bytes it made.
+func storeBlob(ctx context.Context, blobs blob.Store, key, contentType string, build func() ([]byte, error)) ([]byte, error) {
+	exists, err := blobs.Exists(ctx, key)
+	if err != nil || exists {
+		return nil, err
+	}
+	data, err := build()
+	if err != nil {
+		return nil, err
+	}
+	return data, blobs.Put(ctx, key, contentType, bytes.NewReader(data), int64(len(data)))
+}
+
+// serveAudioBlob redirects to a recording kept in the blob store, or
+// returns its bytes when the store cannot sign URLs. It reports false once
+// it has answered.
+func serveAudioBlob(blobs blob.Store, w http.ResponseWriter, r *http.Request, key string) ([]byte, bool) {
+	if blobs == nil {
+		writeErr(w, http.StatusInternalServerError, "blob", "audio storage is not configured")
+		return nil, false
+	}
+	redirected, err := redirectToBlob(blobs, w, r, key, "")
+	if err == nil && !redirected {
+		var data []byte
+		if data, err = blob.ReadAll(r.Context(), blobs, key); err == nil {
+			return data, true
+		}
+	}
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, "blob", "audio query failed")
+	}
+	return nil, false
+}
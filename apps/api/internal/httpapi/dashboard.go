@@ -0,0 +1,28 @@
+package httpapi
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"pandapages/api/internal/model"
+)
+
+// serveChildDashboard answers GET /api/v1/children/{id}/dashboard with one
+// child profile's reading over the last four weeks: recent stories,
+// favorite tags, reading level by week, streak and minutes by hour of day.
+// The reading report covers every child at once; this is one child in
+// depth.
+func serveChildDashboard(store Store, w http.ResponseWriter, r *http.Request, accountID, childID string) {
+	dashboard, err := store.ChildDashboard(r.Context(), accountID, childID, time.Now())
+	if errors.Is(err, model.ErrProfileNotFound) {
+		writeErr(w, http.StatusNotFound, "not_found", "child profile not found")
+		return
+	}
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, "db", "dashboard query failed")
+		return
+	}
+	noStore(w)
+	writeJSON(w, http.StatusOK, dashboard)
+}
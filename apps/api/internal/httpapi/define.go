@@ -0,0 +1,75 @@
+package httpapi
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"pandapages/api/internal/dictionary"
+	"pandapages/api/internal/model"
+)
+
+// serveDefine answers GET /api/v1/define?word=&lang= with the senses of a
+// tapped word a child may be shown. Answers are kept in the database and
+// the dictionary is asked only for words not looked up recently; when it
+// cannot be reached, an expired answer is better than none. A word the
+// filter blocks is not found, exactly as an unknown word is.
+func serveDefine(store Store, client *dictionary.Client, w http.ResponseWriter, r *http.Request) {
+	word, ok := dictionary.NormalizeWord(r.URL.Query().Get("word"))
+	if !ok {
+		writeErr(w, http.StatusBadRequest, "word_invalid", "word must be a single word of up to 48 letters")
+		return
+	}
+	language, ok := dictionary.NormalizeLanguage(r.URL.Query().Get("lang"))
+	if !ok {
+		writeErr(w, http.StatusBadRequest, "lang_invalid", "lang must be a language tag such as en or en-GB")
+		return
+	}
+	if dictionary.Blocked(word) {
+		writeErr(w, http.StatusNotFound, "word_not_found", "no definition was found")
+		return
+	}
+	if client == nil {
+		writeErr(w, http.StatusServiceUnavailable, "define_unavailable", "no dictionary is configured")
+		return
+	}
+
+	entry, err := store.DictionaryEntry(r.Context(), language, word)
+	kept := err == nil
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		writeErr(w, http.StatusInternalServerError, "db", "dictionary query failed")
+		return
+	}
+	ttl := dictionary.CacheTTL
+	if entry.Definition == nil {
+		ttl = dictionary.MissTTL
+	}
+	if !kept || time.Since(entry.FetchedAt) >= ttl {
+		definition, found, err := client.Fetch(r.Context(), language, word)
+		switch {
+		case err != nil && !kept:
+			writeErr(w, http.StatusBadGateway, "define_failed", "the dictionary did not answer")
+			return
+		case err == nil:
+			entry = model.DictionaryEntry{}
+			if found {
+				entry.Definition = &definition
+			}
+			// A failed save still leaves a good answer to give; the next
+			// tap asks the dictionary again.
+			_ = store.DictionarySave(r.Context(), language, word, entry.Definition)
+		}
+	}
+
+	if entry.Definition == nil {
+		writeErr(w, http.StatusNotFound, "word_not_found", "no definition was found")
+		return
+	}
+	definition, ok := dictionary.KidSafe(*entry.Definition)
+	if !ok {
+		writeErr(w, http.StatusNotFound, "word_not_found", "no definition was found")
+		return
+	}
+	writeRevalidatedJSON(w, r, definition)
+}
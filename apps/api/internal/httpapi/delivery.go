@@ -0,0 +1,208 @@
+package httpapi
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"pandapages/api/internal/blob"
+	"pandapages/api/internal/epub"
+	"pandapages/api/internal/model"
+)
+
+// serveStorySend answers POST /api/v1/story/{slug}/send by queueing the
+// published story for email to one of the account's destinations. The
+// delivery is answered straight away; its status moves on as the dispatcher
+// sends it.
+func serveStorySend(store Store, enabled bool, w http.ResponseWriter, r *http.Request, accountID, slug string) {
+	if !enabled {
+		writeErr(w, http.StatusServiceUnavailable, "delivery_unavailable", "email delivery is not configured")
+		return
+	}
+	var body model.StorySendRequest
+	if err := decodeJSON(w, r, &body); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+	if strings.TrimSpace(body.DestinationID) == "" {
+		writeFields(w, http.StatusBadRequest, "send_invalid", "destination is required", []model.FieldError{
+			{Path: "destinationId", Code: "required", Message: "destinationId is required"},
+		})
+		return
+	}
+
+	delivery, err := store.SendStory(r.Context(), accountID, slug, body.DestinationID)
+	if errors.Is(err, model.ErrStoryRemoved) {
+		writeErr(w, http.StatusGone, "story_removed", "story was removed")
+		return
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		writeErr(w, http.StatusNotFound, "not_found", "story not found")
+		return
+	}
+	if errors.Is(err, model.ErrDestinationNotFound) {
+		writeErr(w, http.StatusNotFound, "destination_not_found", "delivery destination not found")
+		return
+	}
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, "db", "story send failed")
+		return
+	}
+
+	noStore(w)
+	w.Header().Set("Location", "/api/v1/deliveries/"+delivery.ID)
+	writeJSON(w, http.StatusAccepted, delivery)
+}
+
+// serveDeliveryDestinations answers GET and POST /api/v1/delivery-destinations.
+func serveDeliveryDestinations(store Store, w http.ResponseWriter, r *http.Request, accountID string) {
+	switch r.Method {
+	case http.MethodGet:
+		destinations, err := store.DeliveryDestinations(r.Context(), accountID)
+		if err != nil {
+			writeErr(w, http.StatusInternalServerError, "db", "delivery destinations query failed")
+			return
+		}
+		noStore(w)
+		writeJSON(w, http.StatusOK, destinations)
+
+	case http.MethodPost:
+		var body model.DeliveryDestinationCreate
+		if err := decodeJSON(w, r, &body); err != nil {
+			writeDecodeError(w, err)
+			return
+		}
+		var fields []model.FieldError
+		if !model.ValidDeliveryDestinationName(strings.TrimSpace(body.Name)) {
+			fields = append(fields, model.FieldError{Path: "name", Code: "invalid", Message: "name must be 1 to 80 characters"})
+		}
+		if _, ok := model.DeliveryAddress(body.Address); !ok {
+			fields = append(fields, model.FieldError{Path: "address", Code: "invalid", Message: "address must be one email address"})
+		}
+		if len(fields) > 0 {
+			writeFields(w, http.StatusBadRequest, "destination_invalid", "delivery destination is invalid", fields)
+			return
+		}
+
+		destination, err := store.CreateDeliveryDestination(r.Context(), accountID, body)
+		if errors.Is(err, model.ErrDestinationExists) {
+			writeErr(w, http.StatusConflict, "destination_exists", "address is already a delivery destination")
+			return
+		}
+		if errors.Is(err, model.ErrDestinationLimit) {
+			writeErr(w, http.StatusConflict, "destination_limit", "too many delivery destinations")
+			return
+		}
+		if err != nil {
+			writeErr(w, http.StatusInternalServerError, "db", "delivery destination create failed")
+			return
+		}
+		noStore(w)
+		writeJSON(w, http.StatusCreated, destination)
+
+	default:
+		methodNotAllowed(w, []string{http.MethodGet, http.MethodPost})
+	}
+}
+
+// serveDeliveryDestinationDelete answers DELETE
+// /api/v1/delivery-destinations/{id}.
+func serveDeliveryDestinationDelete(store Store, w http.ResponseWriter, r *http.Request, accountID, id string) {
+	err := store.DeleteDeliveryDestination(r.Context(), accountID, id)
+	if errors.Is(err, model.ErrDestinationNotFound) {
+		writeErr(w, http.StatusNotFound, "not_found", "delivery destination not found")
+		return
+	}
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, "db", "delivery destination delete failed")
+		return
+	}
+	noStore(w)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// serveStoryDeliveries answers GET /api/v1/deliveries, and
+// /api/v1/deliveries/{id} when id is set.
+func serveStoryDeliveries(store Store, w http.ResponseWriter, r *http.Request, accountID, id string) {
+	if id == "" {
+		deliveries, err := store.StoryDeliveries(r.Context(), accountID)
+		if err != nil {
+			writeErr(w, http.StatusInternalServerError, "db", "deliveries query failed")
+			return
+		}
+		noStore(w)
+		writeJSON(w, http.StatusOK, deliveries)
+		return
+	}
+	delivery, err := store.StoryDelivery(r.Context(), accountID, id)
+	if errors.Is(err, model.ErrDeliveryNotFound) {
+		writeErr(w, http.StatusNotFound, "not_found", "delivery not found")
+		return
+	}
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, "db", "delivery query failed")
+		return
+	}
+	noStore(w)
+	writeJSON(w, http.StatusOK, delivery)
+}
+
+// writeReaderEPUB answers /api/v1/reader/{slug}.epub with the book a send
+// would mail. Without a blob store it is made on each request and not
+// cached. With one it is kept there under a hash of the story, so a book is
+// made once for each rendering, and a store that signs URLs serves it.
+func writeReaderEPUB(store Store, blobs blob.Store, w http.ResponseWriter, r *http.Request, accountID string, story model.ReaderStory) {
+	build := func() ([]byte, error) {
+		return epub.Build(story, func(id string) (model.Media, []byte, error) {
+			return store.Media(r.Context(), accountID, id)
+		}, time.Now())
+	}
+	filename := story.Slug + ".epub"
+
+	var (
+		book []byte
+		err  error
+	)
+	if blobs == nil {
+		book, err = build()
+	} else {
+		key, keyErr := epubBlobKey(accountID, story)
+		if err = keyErr; err == nil {
+			book, err = storeBlob(r.Context(), blobs, key, epub.ContentType, build)
+		}
+		if err == nil {
+			var redirected bool
+			if redirected, err = redirectToBlob(blobs, w, r, key, filename); redirected {
+				return
+			}
+		}
+		if err == nil && book == nil {
+			book, err = blob.ReadAll(r.Context(), blobs, key)
+		}
+	}
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, "epub", "book build failed")
+		return
+	}
+	noStore(w)
+	w.Header().Set("Content-Type", epub.ContentType)
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(book)
+}
+
+// epubBlobKey names a story's book by what it is made from, since a version
+// is rendered again when hyphenation or narration changes.
+func epubBlobKey(accountID string, story model.ReaderStory) (string, error) {
+	encoded, err := json.Marshal(story)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(encoded)
+	return "exports/epub/" + accountID + "/" + hex.EncodeToString(sum[:]) + ".epub", nil
+}
@@ -0,0 +1,87 @@
+package httpapi
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"pandapages/api/internal/model"
+)
+
+// serveDigestSettings answers GET and PUT /api/v1/digest/settings. Settings
+// can be kept while no SMTP relay is configured; nothing is sent until one
+// is.
+func serveDigestSettings(store Store, w http.ResponseWriter, r *http.Request, accountID string) {
+	switch r.Method {
+	case http.MethodGet:
+		settings, err := store.DigestSettings(r.Context(), accountID)
+		if err != nil {
+			writeErr(w, http.StatusInternalServerError, "db", "digest settings query failed")
+			return
+		}
+		noStore(w)
+		writeJSON(w, http.StatusOK, settings)
+
+	case http.MethodPut:
+		var body model.DigestSettings
+		if err := decodeJSON(w, r, &body); err != nil {
+			writeDecodeError(w, err)
+			return
+		}
+		body.Day = strings.ToLower(strings.TrimSpace(body.Day))
+		body.Time = strings.TrimSpace(body.Time)
+		body.TimeZone = strings.TrimSpace(body.TimeZone)
+		var fields []model.FieldError
+		if address := strings.TrimSpace(body.Address); address != "" || body.Enabled {
+			stored, ok := model.DeliveryAddress(address)
+			if !ok {
+				fields = append(fields, model.FieldError{Path: "address", Code: "invalid", Message: "address must be one email address"})
+			}
+			body.Address = stored
+		}
+		if !slices.Contains(model.DigestDays, body.Day) {
+			fields = append(fields, model.FieldError{Path: "day", Code: "invalid", Message: "day must be a day of the week such as sunday"})
+		}
+		if !clockTimeRe.MatchString(body.Time) {
+			fields = append(fields, model.FieldError{Path: "time", Code: "invalid", Message: "time must be HH:MM"})
+		}
+		if !validTimeZone(body.TimeZone) {
+			fields = append(fields, model.FieldError{Path: "timeZone", Code: "invalid", Message: "timeZone must be an IANA time zone such as Europe/London"})
+		}
+		if len(fields) > 0 {
+			writeFields(w, http.StatusBadRequest, "digest_settings_invalid", "digest settings are invalid", fields)
+			return
+		}
+
+		settings, err := store.PutDigestSettings(r.Context(), accountID, body)
+		if errors.Is(err, model.ErrDigestTimeZone) {
+			writeFields(w, http.StatusBadRequest, "digest_settings_invalid", "digest settings are invalid", []model.FieldError{
+				{Path: "timeZone", Code: "invalid", Message: "timeZone must be an IANA time zone such as Europe/London"},
+			})
+			return
+		}
+		if err != nil {
+			writeErr(w, http.StatusInternalServerError, "db", "digest settings update failed")
+			return
+		}
+		noStore(w)
+		writeJSON(w, http.StatusOK, settings)
+
+	default:
+		methodNotAllowed(w, []string{http.MethodGet, http.MethodPut})
+	}
+}
+
+// serveReadingReport answers GET /api/v1/digest/report with the report a
+// digest sent now would carry.
+func serveReadingReport(store Store, w http.ResponseWriter, r *http.Request, accountID string) {
+	report, err := store.ReadingReport(r.Context(), accountID, time.Now())
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, "db", "reading report query failed")
+		return
+	}
+	noStore(w)
+	writeJSON(w, http.StatusOK, report)
+}
@@ -0,0 +1,32 @@
+package httpapi
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"pandapages/api/internal/model"
+)
+
+// serveStoryDiscussion answers GET /api/v1/story/{slug}/discussion with the
+// conversation prompts editors wrote for each chapter of the published
+// version, for a grown-up to talk over once the chapter is read. Chapters
+// carry the same chapterKey and chapterOccurrence as the Reader's segments.
+// A story with no prompts is not found.
+func serveStoryDiscussion(store Store, w http.ResponseWriter, r *http.Request, accountID, slug string) {
+	discussion, err := store.ReaderDiscussion(r.Context(), accountID, slug)
+	if errors.Is(err, model.ErrStoryRemoved) {
+		writeErr(w, http.StatusGone, "story_removed", "story was removed")
+		return
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		writeErr(w, http.StatusNotFound, "not_found", "story discussion not found")
+		return
+	}
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, "db", "discussion query failed")
+		return
+	}
+
+	writeRevalidatedJSON(w, r, discussion)
+}
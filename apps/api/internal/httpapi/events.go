@@ -0,0 +1,72 @@
+package httpapi
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"pandapages/api/internal/model"
+)
+
+// maxEventSlugBytes bounds an event's slug; stored slugs are far shorter.
+const maxEventSlugBytes = 200
+
+// serveClientEvents answers POST /api/v1/events with a batch of Reader
+// events. A malformed event refuses the batch; events too old to count, or
+// for stories the account has not published, are dropped and counted.
+func serveClientEvents(store Store, w http.ResponseWriter, r *http.Request, accountID string) {
+	var body model.ClientEventBatch
+	if err := decodeJSON(w, r, &body); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+	if len(body.Events) == 0 || len(body.Events) > model.MaxClientEvents {
+		writeFields(w, http.StatusBadRequest, "events_invalid", "events are invalid", []model.FieldError{
+			{Path: "events", Code: "invalid", Message: fmt.Sprintf("events must hold 1 to %d events", model.MaxClientEvents)},
+		})
+		return
+	}
+
+	now := time.Now()
+	var (
+		fields  []model.FieldError
+		events  = make([]model.ClientEvent, 0, len(body.Events))
+		dropped int
+	)
+	for i, event := range body.Events {
+		path := fmt.Sprintf("events[%d]", i)
+		if !slices.Contains(model.ClientEventKinds, event.Kind) {
+			fields = append(fields, model.FieldError{Path: path + ".kind", Code: "invalid", Message: "kind must be story_opened, segment_viewed or read_aloud_used"})
+		}
+		event.Slug = strings.TrimSpace(event.Slug)
+		if event.Slug == "" || len(event.Slug) > maxEventSlugBytes {
+			fields = append(fields, model.FieldError{Path: path + ".slug", Code: "invalid", Message: "slug is required"})
+		}
+		if event.At.IsZero() {
+			fields = append(fields, model.FieldError{Path: path + ".at", Code: "required", Message: "at is required"})
+		}
+		if len(fields) > 0 {
+			continue
+		}
+		if event.At.Before(now.Add(-model.ClientEventMaxAge)) || event.At.After(now.Add(model.ClientEventMaxSkew)) {
+			dropped++
+			continue
+		}
+		events = append(events, event)
+	}
+	if len(fields) > 0 {
+		writeFields(w, http.StatusBadRequest, "events_invalid", "events are invalid", fields)
+		return
+	}
+
+	result, err := store.RecordClientEvents(r.Context(), accountID, events)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, "db", "events record failed")
+		return
+	}
+	result.Dropped += dropped
+	noStore(w)
+	writeJSON(w, http.StatusOK, result)
+}
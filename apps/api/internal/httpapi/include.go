@@ -0,0 +1,79 @@
+package httpapi
+
+import (
+	"strings"
+
+	"pandapages/api/internal/model"
+)
+
+// inclusion is a response's ?include= selection: a comma-separated subset of
+// meta, segments and html. Metadata is always sent; segments adds the
+// segment list without HTML, and html adds the list with it. Leaving include
+// out sends everything, so existing clients see no change.
+type inclusion struct {
+	segments bool
+	html     bool
+}
+
+var includeEverything = inclusion{segments: true, html: true}
+
+func parseInclusion(raw string) (inclusion, bool) {
+	if raw == "" {
+		return includeEverything, true
+	}
+	var in inclusion
+	for _, field := range strings.Split(raw, ",") {
+		switch strings.TrimSpace(field) {
+		case "meta":
+		case "segments":
+			in.segments = true
+		case "html":
+			in.segments, in.html = true, true
+		default:
+			return inclusion{}, false
+		}
+	}
+	return in, true
+}
+
+// String names the selection the way ?include= would, for cache keys.
+func (in inclusion) String() string {
+	switch {
+	case in.html:
+		return "html"
+	case in.segments:
+		return "segments"
+	default:
+		return "meta"
+	}
+}
+
+// The projections below embed the model and shadow one field, which
+// encoding/json prefers to the embedded field; a nil pointer is then omitted.
+// Every other field keeps its usual order.
+
+// readerStoryMeta is a ReaderStory without its segments.
+type readerStoryMeta struct {
+	model.ReaderStory
+	Segments *struct{} `json:"segments,omitempty"`
+}
+
+// readerStorySelection is a ReaderStory with its segments projected.
+type readerStorySelection struct {
+	model.ReaderStory
+	Segments []any `json:"segments"`
+}
+
+// readerSegmentOutline is a ReaderSegment without its HTML.
+type readerSegmentOutline struct {
+	model.ReaderSegment
+	RenderedHTML *struct{} `json:"renderedHtml,omitempty"`
+}
+
+// segment returns what in sends of one segment.
+func (in inclusion) segment(segment model.ReaderSegment) any {
+	if in.html {
+		return segment
+	}
+	return readerSegmentOutline{ReaderSegment: segment}
+}
@@ -0,0 +1,29 @@
+package httpapi
+
+import (
+	"net/http"
+	"strings"
+)
+
+// maintenanceRetryAfter is the Retry-After, in seconds, of a maintenance
+// response. Maintenance has no known end, so it only paces retries.
+const maintenanceRetryAfter = "60"
+
+// withMaintenance answers every reader request with 503 while maintenance is
+// on. The probes keep answering so the orchestrator does not restart or
+// unroute the instance, and unlock keeps working because admin routes need
+// the session it issues.
+func withMaintenance(cfg Config, next http.Handler) http.Handler {
+	if cfg.Maintenance == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		state := cfg.Maintenance.State()
+		if !state.Enabled || r.URL.Path == "/healthz" || r.URL.Path == "/readyz" || strings.HasPrefix(r.URL.Path, "/api/v1/auth/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Set("Retry-After", maintenanceRetryAfter)
+		writeErr(w, http.StatusServiceUnavailable, "maintenance", state.Message)
+	})
+}
@@ -0,0 +1,45 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"pandapages/api/internal/maintenance"
+)
+
+func TestMaintenanceTurnsReadersAwayButKeepsProbesAndUnlock(t *testing.T) {
+	manager := testSessionManager(t, false, func() time.Time { return testSessionTime })
+	state := maintenance.New(true)
+	handler := New(Config{Passcode: "123456", Sessions: manager, Maintenance: state}, &authTestStore{})
+
+	serve := func(req *http.Request) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	refused := serve(sessionRequest(t, manager, http.MethodGet, "/api/v1/continue"))
+	if refused.Code != http.StatusServiceUnavailable || refused.Header().Get("Retry-After") != "60" {
+		t.Fatalf("continue during maintenance = %d %v", refused.Code, refused.Header())
+	}
+	var body struct {
+		Error struct{ Code, Message string } `json:"error"`
+	}
+	if err := json.Unmarshal(refused.Body.Bytes(), &body); err != nil || body.Error.Code != "maintenance" || body.Error.Message != maintenance.DefaultMessage {
+		t.Fatalf("maintenance body = %s", refused.Body.String())
+	}
+
+	for _, path := range []string{"/healthz", "/api/v1/auth/status"} {
+		if rec := serve(httptest.NewRequest(http.MethodGet, path, nil)); rec.Code == http.StatusServiceUnavailable {
+			t.Fatalf("%s was taken offline: %s", path, rec.Body.String())
+		}
+	}
+
+	state.Set(false, "")
+	if rec := serve(sessionRequest(t, manager, http.MethodGet, "/api/v1/continue")); rec.Code == http.StatusServiceUnavailable {
+		t.Fatalf("continue after maintenance = %d", rec.Code)
+	}
+}
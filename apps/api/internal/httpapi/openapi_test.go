@@ -0,0 +1,49 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestOpenAPIDocumentIsServedWithoutASession(t *testing.T) {
+	store := &authTestStore{}
+	manager := testSessionManager(t, false, func() time.Time { return testSessionTime })
+	handler := testHandler(t, store, manager)
+
+	response := httptest.NewRecorder()
+	handler.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/api/v1/openapi.json", nil))
+	if response.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", response.Code, response.Body.String())
+	}
+	var document struct {
+		OpenAPI string         `json:"openapi"`
+		Paths   map[string]any `json:"paths"`
+	}
+	if err := json.Unmarshal(response.Body.Bytes(), &document); err != nil {
+		t.Fatalf("document is not JSON: %v", err)
+	}
+	if document.OpenAPI == "" || document.Paths["/api/v1/reader/{slug}"] == nil {
+		t.Fatalf("document = %+v, want an OpenAPI document describing the reader", document.OpenAPI)
+	}
+	if store.existsCalls != 0 {
+		t.Fatalf("document consulted the account store %d times", store.existsCalls)
+	}
+
+	etag := response.Header().Get("ETag")
+	revalidate := httptest.NewRequest(http.MethodGet, "/api/v1/openapi.json", nil)
+	revalidate.Header.Set("If-None-Match", etag)
+	response = httptest.NewRecorder()
+	handler.ServeHTTP(response, revalidate)
+	if etag == "" || response.Code != http.StatusNotModified {
+		t.Fatalf("revalidation with ETag %q = %d, want 304", etag, response.Code)
+	}
+
+	response = httptest.NewRecorder()
+	handler.ServeHTTP(response, httptest.NewRequest(http.MethodPost, "/api/v1/openapi.json", nil))
+	if response.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("POST status = %d, want 405", response.Code)
+	}
+}
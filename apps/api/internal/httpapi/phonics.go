@@ -0,0 +1,41 @@
+package httpapi
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"pandapages/api/internal/model"
+	"pandapages/api/internal/phonics"
+)
+
+// libraryFilter reads the Library's decodable parameter: a set of the
+// configured progression, keeping stories a child taught it and the sets
+// before it can read. It answers the request itself and reports false when
+// the parameter cannot be used.
+func libraryFilter(w http.ResponseWriter, r *http.Request, progression *phonics.Progression) (model.LibraryFilter, bool) {
+	raw := strings.TrimSpace(r.URL.Query().Get("decodable"))
+	if raw == "" {
+		return model.LibraryFilter{}, true
+	}
+	if progression == nil {
+		writeErr(w, http.StatusServiceUnavailable, "phonics_unavailable", "no phonics progression is configured")
+		return model.LibraryFilter{}, false
+	}
+	set, err := strconv.Atoi(raw)
+	if err != nil || set < 1 || set > progression.SetCount() {
+		writeErr(w, http.StatusBadRequest, "decodable_invalid", "decodable must be a set of the phonics progression, from 1 to "+strconv.Itoa(progression.SetCount()))
+		return model.LibraryFilter{}, false
+	}
+	return model.LibraryFilter{Progression: progression.Key(), DecodableSet: set}, true
+}
+
+// servePhonics answers GET /api/v1/phonics with the configured progression,
+// so a Reader can name the sets it offers to filter by.
+func servePhonics(progression *phonics.Progression, w http.ResponseWriter, r *http.Request) {
+	if progression == nil {
+		writeErr(w, http.StatusServiceUnavailable, "phonics_unavailable", "no phonics progression is configured")
+		return
+	}
+	writeRevalidatedJSON(w, r, progression.Model())
+}
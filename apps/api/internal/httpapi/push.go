@@ -0,0 +1,158 @@
+package httpapi
+
+import (
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"pandapages/api/internal/model"
+	"pandapages/api/internal/webpush"
+)
+
+var clockTimeRe = regexp.MustCompile(`^(?:[01][0-9]|2[0-3]):[0-5][0-9]$`)
+
+// servePushKey answers GET /api/v1/push/key with the key browsers subscribe
+// with.
+func servePushKey(sender *webpush.Sender, w http.ResponseWriter) {
+	if sender == nil {
+		writeErr(w, http.StatusServiceUnavailable, "push_unavailable", "push notifications are not configured")
+		return
+	}
+	writeJSON(w, http.StatusOK, model.PushKey{PublicKey: sender.PublicKey()})
+}
+
+// servePushSubscribe answers POST /api/v1/push/subscribe with a browser's
+// PushSubscription: 201 when it is new, 200 when the browser was already
+// subscribed and its keys were replaced.
+func servePushSubscribe(store Store, sender *webpush.Sender, w http.ResponseWriter, r *http.Request, accountID string) {
+	if sender == nil {
+		writeErr(w, http.StatusServiceUnavailable, "push_unavailable", "push notifications are not configured")
+		return
+	}
+	var body model.PushSubscribeRequest
+	if err := decodeJSON(w, r, &body); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+	var fields []model.FieldError
+	endpoint := strings.TrimSpace(body.Endpoint)
+	if !webpush.ValidEndpoint(endpoint) {
+		fields = append(fields, model.FieldError{Path: "endpoint", Code: "invalid", Message: "endpoint must be an https URL"})
+	}
+	p256dh, auth, ok := webpush.DecodeKeys(body.Keys)
+	if !ok {
+		fields = append(fields, model.FieldError{Path: "keys", Code: "invalid", Message: "keys must be the subscription's p256dh and auth keys"})
+	}
+	if len(fields) > 0 {
+		writeFields(w, http.StatusBadRequest, "push_subscription_invalid", "push subscription is invalid", fields)
+		return
+	}
+
+	subscription, err := store.PushSubscribe(r.Context(), accountID, model.PushSubscriptionSave{Endpoint: endpoint, P256DH: p256dh, Auth: auth})
+	if errors.Is(err, model.ErrPushSubscriptionLimit) {
+		writeErr(w, http.StatusConflict, "push_subscription_limit", "too many push subscriptions")
+		return
+	}
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, "db", "push subscribe failed")
+		return
+	}
+	status := http.StatusOK
+	if subscription.Created {
+		status = http.StatusCreated
+	}
+	noStore(w)
+	writeJSON(w, status, subscription)
+}
+
+// servePushUnsubscribe answers POST /api/v1/push/unsubscribe. It is a POST
+// with the endpoint in the body, since endpoints are URLs.
+func servePushUnsubscribe(store Store, w http.ResponseWriter, r *http.Request, accountID string) {
+	var body model.PushUnsubscribeRequest
+	if err := decodeJSON(w, r, &body); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+	endpoint := strings.TrimSpace(body.Endpoint)
+	if endpoint == "" {
+		writeFields(w, http.StatusBadRequest, "push_unsubscribe_invalid", "endpoint is required", []model.FieldError{
+			{Path: "endpoint", Code: "required", Message: "endpoint is required"},
+		})
+		return
+	}
+	err := store.PushUnsubscribe(r.Context(), accountID, endpoint)
+	if errors.Is(err, model.ErrPushSubscriptionNotFound) {
+		writeErr(w, http.StatusNotFound, "not_found", "push subscription not found")
+		return
+	}
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, "db", "push unsubscribe failed")
+		return
+	}
+	noStore(w)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// servePushSettings answers GET and PUT /api/v1/push/settings. Settings can
+// be kept while push is not configured; nothing is sent until it is.
+func servePushSettings(store Store, w http.ResponseWriter, r *http.Request, accountID string) {
+	switch r.Method {
+	case http.MethodGet:
+		settings, err := store.PushSettings(r.Context(), accountID)
+		if err != nil {
+			writeErr(w, http.StatusInternalServerError, "db", "push settings query failed")
+			return
+		}
+		noStore(w)
+		writeJSON(w, http.StatusOK, settings)
+
+	case http.MethodPut:
+		var body model.PushSettings
+		if err := decodeJSON(w, r, &body); err != nil {
+			writeDecodeError(w, err)
+			return
+		}
+		body.ReminderTime = strings.TrimSpace(body.ReminderTime)
+		body.TimeZone = strings.TrimSpace(body.TimeZone)
+		var fields []model.FieldError
+		if !clockTimeRe.MatchString(body.ReminderTime) {
+			fields = append(fields, model.FieldError{Path: "reminderTime", Code: "invalid", Message: "reminderTime must be HH:MM"})
+		}
+		if !validTimeZone(body.TimeZone) {
+			fields = append(fields, model.FieldError{Path: "timeZone", Code: "invalid", Message: "timeZone must be an IANA time zone such as Europe/London"})
+		}
+		if len(fields) > 0 {
+			writeFields(w, http.StatusBadRequest, "push_settings_invalid", "push settings are invalid", fields)
+			return
+		}
+
+		settings, err := store.PutPushSettings(r.Context(), accountID, body)
+		if errors.Is(err, model.ErrPushTimeZone) {
+			writeFields(w, http.StatusBadRequest, "push_settings_invalid", "push settings are invalid", []model.FieldError{
+				{Path: "timeZone", Code: "invalid", Message: "timeZone must be an IANA time zone such as Europe/London"},
+			})
+			return
+		}
+		if err != nil {
+			writeErr(w, http.StatusInternalServerError, "db", "push settings update failed")
+			return
+		}
+		noStore(w)
+		writeJSON(w, http.StatusOK, settings)
+
+	default:
+		methodNotAllowed(w, []string{http.MethodGet, http.MethodPut})
+	}
+}
+
+// validTimeZone accepts IANA names only; "Local" and "" mean the server's
+// zone to Go and nothing to a reader.
+func validTimeZone(name string) bool {
+	if name == "" || name == "Local" {
+		return false
+	}
+	_, err := time.LoadLocation(name)
+	return err == nil
+}
@@ -0,0 +1,105 @@
+package httpapi
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+
+	"pandapages/api/internal/model"
+)
+
+// serveQueue answers GET /api/v1/queue with the up-next queue, and PUT with
+// the queue reordered: the body names every queued story once, first story
+// first.
+func serveQueue(store Store, w http.ResponseWriter, r *http.Request, accountID string) {
+	switch r.Method {
+	case http.MethodGet:
+		items, err := store.Queue(r.Context(), accountID)
+		if err != nil {
+			writeErr(w, http.StatusInternalServerError, "db", "queue query failed")
+			return
+		}
+		noStore(w)
+		writeJSON(w, http.StatusOK, model.QueueResponse{Items: items})
+
+	case http.MethodPut:
+		var body model.QueueReorderRequest
+		if err := decodeJSON(w, r, &body); err != nil {
+			writeDecodeError(w, err)
+			return
+		}
+		if body.Slugs == nil {
+			writeFields(w, http.StatusBadRequest, "queue_order_invalid", "slugs are required", []model.FieldError{
+				{Path: "slugs", Code: "required", Message: "slugs must list every queued story"},
+			})
+			return
+		}
+		for i, slug := range body.Slugs {
+			body.Slugs[i] = strings.TrimSpace(slug)
+		}
+		items, err := store.QueueReorder(r.Context(), accountID, body.Slugs)
+		if errors.Is(err, model.ErrQueueOrder) {
+			writeErr(w, http.StatusConflict, "queue_order_mismatch", "slugs must name every queued story exactly once")
+			return
+		}
+		if err != nil {
+			writeErr(w, http.StatusInternalServerError, "db", "queue reorder failed")
+			return
+		}
+		noStore(w)
+		writeJSON(w, http.StatusOK, model.QueueResponse{Items: items})
+
+	default:
+		methodNotAllowed(w, []string{http.MethodGet, http.MethodPut})
+	}
+}
+
+// serveQueueItem answers POST /api/v1/queue/{slug}, which puts a published
+// story at the end of the queue (201, or 200 when it was already queued and
+// keeps its place), and DELETE, which takes it out. Both answer with the
+// whole queue.
+func serveQueueItem(store Store, w http.ResponseWriter, r *http.Request, accountID, slug string) {
+	switch r.Method {
+	case http.MethodPost:
+		items, added, err := store.QueueAdd(r.Context(), accountID, slug)
+		if errors.Is(err, model.ErrStoryRemoved) {
+			writeErr(w, http.StatusGone, "story_removed", "story was removed")
+			return
+		}
+		if errors.Is(err, sql.ErrNoRows) {
+			writeErr(w, http.StatusNotFound, "not_found", "story not found")
+			return
+		}
+		if errors.Is(err, model.ErrQueueFull) {
+			writeErr(w, http.StatusConflict, "queue_full", "too many stories queued")
+			return
+		}
+		if err != nil {
+			writeErr(w, http.StatusInternalServerError, "db", "queue add failed")
+			return
+		}
+		status := http.StatusOK
+		if added {
+			status = http.StatusCreated
+		}
+		noStore(w)
+		writeJSON(w, status, model.QueueResponse{Items: items})
+
+	case http.MethodDelete:
+		items, err := store.QueueRemove(r.Context(), accountID, slug)
+		if errors.Is(err, model.ErrQueueItemNotFound) {
+			writeErr(w, http.StatusNotFound, "not_found", "story is not queued")
+			return
+		}
+		if err != nil {
+			writeErr(w, http.StatusInternalServerError, "db", "queue remove failed")
+			return
+		}
+		noStore(w)
+		writeJSON(w, http.StatusOK, model.QueueResponse{Items: items})
+
+	default:
+		methodNotAllowed(w, []string{http.MethodPost, http.MethodDelete})
+	}
+}
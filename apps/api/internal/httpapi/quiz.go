@@ -0,0 +1,32 @@
+package httpapi
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"pandapages/api/internal/model"
+)
+
+// serveStoryQuiz answers GET /api/v1/story/{slug}/quiz with the approved
+// comprehension questions, answers included, for each chapter of the
+// published version, so a grown-up can ask them once the chapter is read.
+// Chapters carry the same chapterKey and chapterOccurrence as the Reader's
+// segments. A story with no approved quiz is not found.
+func serveStoryQuiz(store Store, w http.ResponseWriter, r *http.Request, accountID, slug string) {
+	quiz, err := store.ReaderQuiz(r.Context(), accountID, slug)
+	if errors.Is(err, model.ErrStoryRemoved) {
+		writeErr(w, http.StatusGone, "story_removed", "story was removed")
+		return
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		writeErr(w, http.StatusNotFound, "not_found", "story quiz not found")
+		return
+	}
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, "db", "quiz query failed")
+		return
+	}
+
+	writeRevalidatedJSON(w, r, quiz)
+}
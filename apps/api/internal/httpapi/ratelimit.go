@@ -0,0 +1,93 @@
+package httpapi
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"pandapages/api/internal/httpmiddleware"
+	"pandapages/api/internal/model"
+)
+
+// withRateLimit spends one token per request from the client's read or write
+// budget. A signed session is the client; without one, the address is. The
+// probes stay unthrottled so an orchestrator never mistakes a busy client
+// for a dead process.
+func withRateLimit(cfg Config, next http.Handler) http.Handler {
+	if cfg.ReadLimit == nil && cfg.WriteLimit == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/healthz" || r.URL.Path == "/readyz" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		limiter, budget := cfg.WriteLimit, "write"
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			limiter, budget = cfg.ReadLimit, "read"
+		}
+		if limiter == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		decision := limiter.Allow(budget + ":" + rateLimitClient(cfg, r))
+		w.Header().Set("RateLimit-Limit", strconv.Itoa(decision.Limit))
+		w.Header().Set("RateLimit-Remaining", strconv.Itoa(decision.Remaining))
+		w.Header().Set("RateLimit-Reset", wholeSeconds(decision.Reset))
+		if !decision.Allowed {
+			w.Header().Set("Retry-After", wholeSeconds(decision.RetryAfter))
+			noStore(w)
+			body := httpmiddleware.ErrorBody(w, "rate_limited", "too many requests; retry after the Retry-After delay")
+			body["rateLimit"] = model.RateLimit{
+				Budget:            budget,
+				Limit:             decision.Limit,
+				Remaining:         decision.Remaining,
+				ResetSeconds:      ceilSeconds(decision.Reset),
+				RetryAfterSeconds: ceilSeconds(decision.RetryAfter),
+			}
+			writeJSON(w, http.StatusTooManyRequests, map[string]any{"error": body})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func rateLimitClient(cfg Config, r *http.Request) string {
+	if cfg.Sessions != nil {
+		if claims, err := cfg.Sessions.FromRequest(r); err == nil {
+			return "session:" + claims.AccountID + ":" + strconv.FormatInt(claims.IssuedAt.Unix(), 10)
+		}
+	}
+	return "ip:" + clientIP(r, cfg.TrustForwardedFor)
+}
+
+// clientIP is the peer address, or behind a trusted proxy the address that
+// proxy appended to X-Forwarded-For. Earlier entries are client-supplied.
+func clientIP(r *http.Request, trustForwardedFor bool) string {
+	if trustForwardedFor {
+		forwarded := r.Header.Values("X-Forwarded-For")
+		if len(forwarded) > 0 {
+			hops := strings.Split(forwarded[len(forwarded)-1], ",")
+			if hop := strings.TrimSpace(hops[len(hops)-1]); hop != "" {
+				return hop
+			}
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func wholeSeconds(d time.Duration) string {
+	return strconv.FormatInt(ceilSeconds(d), 10)
+}
+
+func ceilSeconds(d time.Duration) int64 {
+	return int64(math.Ceil(d.Seconds()))
+}
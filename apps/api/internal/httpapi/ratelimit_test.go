@@ -0,0 +1,86 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"pandapages/api/internal/model"
+	"pandapages/api/internal/ratelimit"
+)
+
+func TestRateLimitSeparatesReadAndWriteBudgets(t *testing.T) {
+	manager := testSessionManager(t, false, func() time.Time { return testSessionTime })
+	store := &authTestStore{}
+	handler := New(Config{
+		Passcode:   "123456",
+		Sessions:   manager,
+		ReadLimit:  ratelimit.New(2),
+		WriteLimit: ratelimit.New(1),
+	}, store)
+
+	serve := func(req *http.Request) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	for _, remaining := range []string{"1", "0"} {
+		rec := serve(sessionRequest(t, manager, http.MethodGet, "/api/v1/continue"))
+		if rec.Code == http.StatusTooManyRequests {
+			t.Fatalf("read leaving %s was throttled", remaining)
+		}
+		if rec.Header().Get("RateLimit-Limit") != "2" || rec.Header().Get("RateLimit-Remaining") != remaining {
+			t.Fatalf("read leaving %s headers = %v", remaining, rec.Header())
+		}
+	}
+
+	refused := serve(sessionRequest(t, manager, http.MethodGet, "/api/v1/continue"))
+	if refused.Code != http.StatusTooManyRequests {
+		t.Fatalf("third read = %d, want 429", refused.Code)
+	}
+	if refused.Header().Get("Retry-After") != "30" || refused.Header().Get("RateLimit-Remaining") != "0" || refused.Header().Get("RateLimit-Reset") != "60" {
+		t.Fatalf("refused headers = %v", refused.Header())
+	}
+	var body struct {
+		Error struct {
+			Code      string
+			RateLimit model.RateLimit `json:"rateLimit"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(refused.Body.Bytes(), &body); err != nil || body.Error.Code != "rate_limited" ||
+		body.Error.RateLimit != (model.RateLimit{Budget: "read", Limit: 2, Remaining: 0, ResetSeconds: 60, RetryAfterSeconds: 30}) {
+		t.Fatalf("refused body = %s", refused.Body.String())
+	}
+
+	if rec := serve(sessionRequest(t, manager, http.MethodPost, "/api/v1/auth/logout")); rec.Code != http.StatusOK || rec.Header().Get("RateLimit-Limit") != "1" {
+		t.Fatalf("write after exhausted reads = %d %v", rec.Code, rec.Header())
+	}
+	if rec := serve(httptest.NewRequest(http.MethodGet, "/api/v1/continue", nil)); rec.Code == http.StatusTooManyRequests {
+		t.Fatal("an anonymous client shared the session's budget")
+	}
+	for range 3 {
+		if rec := serve(httptest.NewRequest(http.MethodGet, "/healthz", nil)); rec.Code != http.StatusOK || rec.Header().Get("RateLimit-Limit") != "" {
+			t.Fatalf("healthz = %d %v", rec.Code, rec.Header())
+		}
+	}
+}
+
+func TestClientIPTrustsOnlyTheLastForwardedHop(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/library", nil)
+	req.RemoteAddr = "172.18.0.5:41234"
+	req.Header.Add("X-Forwarded-For", "203.0.113.9, 198.51.100.7")
+
+	if got := clientIP(req, false); got != "172.18.0.5" {
+		t.Fatalf("untrusted clientIP = %q", got)
+	}
+	if got := clientIP(req, true); got != "198.51.100.7" {
+		t.Fatalf("trusted clientIP = %q", got)
+	}
+	req.Header.Del("X-Forwarded-For")
+	if got := clientIP(req, true); got != "172.18.0.5" {
+		t.Fatalf("clientIP without a forwarded header = %q", got)
+	}
+}
@@ -0,0 +1,54 @@
+package httpapi
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+
+	"pandapages/api/internal/model"
+)
+
+// serveStoryReaction answers POST /api/v1/story/{slug}/reaction, which
+// records the profile's reaction to a published story and replaces any
+// earlier one, and DELETE, which takes it back. Both answer with the story's
+// reaction counts after the change.
+func serveStoryReaction(store Store, w http.ResponseWriter, r *http.Request, accountID, slug string) {
+	var reaction *model.StoryReaction
+	switch r.Method {
+	case http.MethodPost:
+		var body model.StoryReactionRequest
+		if err := decodeJSON(w, r, &body); err != nil {
+			writeDecodeError(w, err)
+			return
+		}
+		body.Reaction = model.StoryReaction(strings.TrimSpace(string(body.Reaction)))
+		if !body.Reaction.Valid() {
+			writeFields(w, http.StatusBadRequest, "reaction_invalid", "reaction is invalid", []model.FieldError{
+				{Path: "reaction", Code: "invalid", Message: "reaction must be loved_it, silly or scary"},
+			})
+			return
+		}
+		reaction = &body.Reaction
+	case http.MethodDelete:
+	default:
+		methodNotAllowed(w, []string{http.MethodPost, http.MethodDelete})
+		return
+	}
+
+	out, err := store.SetStoryReaction(r.Context(), accountID, slug, reaction)
+	if errors.Is(err, model.ErrStoryRemoved) {
+		writeErr(w, http.StatusGone, "story_removed", "story was removed")
+		return
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		writeErr(w, http.StatusNotFound, "not_found", "story not found")
+		return
+	}
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, "db", "reaction update failed")
+		return
+	}
+	noStore(w)
+	writeJSON(w, http.StatusOK, out)
+}
@@ -0,0 +1,280 @@
+package httpapi
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sync"
+	"time"
+
+	"golang.org/x/net/websocket"
+
+	"pandapages/api/internal/readercontract"
+)
+
+// Read-along lets one device drive a story while others follow it. Devices
+// reading the same story for the same account and profile share a room; a
+// driver's positions are relayed to every other member, and a device that
+// joins late starts from the last one. Rooms live in this process, so devices
+// only meet when they reach the same API instance.
+const (
+	readAlongMaxMembers = 16
+	readAlongMaxMessage = 4 << 10
+	// readAlongIdle closes a connection that has sent nothing, not even a
+	// ping, for this long. Browsers cannot send protocol pings, so clients
+	// send {"type":"ping"} instead.
+	readAlongIdle = 75 * time.Second
+	readAlongSend = 10 * time.Second
+	// readAlongQueue is how many messages may wait for a slow member before
+	// it is dropped; it reconnects and starts again from the last position.
+	readAlongQueue = 16
+)
+
+var readAlongProfilePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+type readAlongRoom struct {
+	accountID string
+	profile   string
+	slug      string
+}
+
+type readAlongMember struct {
+	drives bool
+	send   chan []byte
+}
+
+type readAlongState struct {
+	members  map[*readAlongMember]struct{}
+	position []byte
+}
+
+// readAlongHub relays messages between the members of each room. Sends never
+// block: a member whose queue is full is dropped.
+type readAlongHub struct {
+	mu    sync.Mutex
+	rooms map[readAlongRoom]*readAlongState
+}
+
+func newReadAlongHub() *readAlongHub {
+	return &readAlongHub{rooms: map[readAlongRoom]*readAlongState{}}
+}
+
+// readAlongMessage is every message either way. Clients send ping and, when
+// driving, position; the server sends position, presence and error.
+type readAlongMessage struct {
+	Type      string                  `json:"type"`
+	Locator   *readercontract.Locator `json:"locator,omitempty"`
+	Drivers   *int                    `json:"drivers,omitempty"`
+	Followers *int                    `json:"followers,omitempty"`
+	Code      string                  `json:"code,omitempty"`
+	Message   string                  `json:"message,omitempty"`
+}
+
+// join adds member to room and queues the room's last position for it. It
+// reports false when the room is full.
+func (h *readAlongHub) join(room readAlongRoom, member *readAlongMember) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	state := h.rooms[room]
+	if state == nil {
+		state = &readAlongState{members: map[*readAlongMember]struct{}{}}
+		h.rooms[room] = state
+	}
+	if len(state.members) >= readAlongMaxMembers {
+		return false
+	}
+	state.members[member] = struct{}{}
+	if state.position != nil {
+		h.deliverLocked(room, state, member, state.position)
+	}
+	h.presenceLocked(room, state)
+	return true
+}
+
+// leave removes member from room, if it is still there, and closes its queue.
+func (h *readAlongHub) leave(room readAlongRoom, member *readAlongMember) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	state := h.rooms[room]
+	if state == nil {
+		return
+	}
+	if _, ok := state.members[member]; !ok {
+		return
+	}
+	h.dropLocked(room, state, member)
+	h.presenceLocked(room, state)
+}
+
+// move records a driver's position and relays it to the rest of the room.
+func (h *readAlongHub) move(room readAlongRoom, from *readAlongMember, position []byte) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	state := h.rooms[room]
+	if state == nil {
+		return
+	}
+	state.position = position
+	for member := range state.members {
+		if member != from {
+			h.deliverLocked(room, state, member, position)
+		}
+	}
+}
+
+// tell queues a message for one member only.
+func (h *readAlongHub) tell(room readAlongRoom, member *readAlongMember, message []byte) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if state := h.rooms[room]; state != nil {
+		if _, ok := state.members[member]; ok {
+			h.deliverLocked(room, state, member, message)
+		}
+	}
+}
+
+func (h *readAlongHub) deliverLocked(room readAlongRoom, state *readAlongState, member *readAlongMember, message []byte) {
+	select {
+	case member.send <- message:
+	default:
+		h.dropLocked(room, state, member)
+	}
+}
+
+func (h *readAlongHub) dropLocked(room readAlongRoom, state *readAlongState, member *readAlongMember) {
+	delete(state.members, member)
+	close(member.send)
+	if len(state.members) == 0 {
+		delete(h.rooms, room)
+	}
+}
+
+func (h *readAlongHub) presenceLocked(room readAlongRoom, state *readAlongState) {
+	drivers, followers := 0, 0
+	for member := range state.members {
+		if member.drives {
+			drivers++
+		} else {
+			followers++
+		}
+	}
+	message, _ := json.Marshal(readAlongMessage{Type: "presence", Drivers: &drivers, Followers: &followers})
+	for member := range state.members {
+		h.deliverLocked(room, state, member, message)
+	}
+}
+
+// serveReadAlong upgrades the request and relays messages until either side
+// goes away. ?role=drive lets the device move the room; anything else
+// follows. ?profile= names the reading profile, so two children reading the
+// same story on one account do not share a room.
+func (h *readAlongHub) serveReadAlong(w http.ResponseWriter, r *http.Request, accountID, slug string) {
+	profile := r.URL.Query().Get("profile")
+	if profile == "" {
+		profile = "default"
+	}
+	if !readAlongProfilePattern.MatchString(profile) {
+		writeErr(w, http.StatusBadRequest, "profile_invalid", "profile must be 1 to 64 letters, digits, - or _")
+		return
+	}
+	room := readAlongRoom{accountID: accountID, profile: profile, slug: slug}
+	drives := r.URL.Query().Get("role") == "drive"
+
+	server := websocket.Server{
+		Handshake: sameOriginHandshake,
+		Handler: func(conn *websocket.Conn) {
+			conn.MaxPayloadBytes = readAlongMaxMessage
+			h.relay(conn, room, drives)
+		},
+	}
+	server.ServeHTTP(hijackableWriter{w}, r)
+}
+
+func (h *readAlongHub) relay(conn *websocket.Conn, room readAlongRoom, drives bool) {
+	member := &readAlongMember{drives: drives, send: make(chan []byte, readAlongQueue)}
+	if !h.join(room, member) {
+		_ = conn.SetWriteDeadline(time.Now().Add(readAlongSend))
+		_ = websocket.JSON.Send(conn, readAlongMessage{Type: "error", Code: "room_full", Message: "too many devices are reading along"})
+		return
+	}
+	defer h.leave(room, member)
+
+	go func() {
+		for message := range member.send {
+			_ = conn.SetWriteDeadline(time.Now().Add(readAlongSend))
+			if err := websocket.Message.Send(conn, string(message)); err != nil {
+				// Closing ends the read loop below, which leaves the room.
+				_ = conn.Close()
+				return
+			}
+		}
+		_ = conn.Close()
+	}()
+
+	for {
+		_ = conn.SetReadDeadline(time.Now().Add(readAlongIdle))
+		var raw []byte
+		if err := websocket.Message.Receive(conn, &raw); err != nil {
+			return
+		}
+		var message readAlongMessage
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&message); err != nil {
+			h.refuse(room, member, "bad_json", "message must be valid JSON")
+			continue
+		}
+		switch message.Type {
+		case "ping":
+		case "position":
+			if !drives {
+				h.refuse(room, member, "not_driving", "only a driving device moves the room")
+				continue
+			}
+			if message.Locator == nil || message.Locator.Validate() != nil {
+				h.refuse(room, member, "locator_invalid", "invalid Reader locator")
+				continue
+			}
+			position, _ := json.Marshal(readAlongMessage{Type: "position", Locator: message.Locator})
+			h.move(room, member, position)
+		default:
+			h.refuse(room, member, "type_invalid", "type must be ping or position")
+		}
+	}
+}
+
+func (h *readAlongHub) refuse(room readAlongRoom, member *readAlongMember, code, text string) {
+	message, _ := json.Marshal(readAlongMessage{Type: "error", Code: code, Message: text})
+	h.tell(room, member, message)
+}
+
+// sameOriginHandshake refuses pages on other sites, which would otherwise
+// ride the session cookie into a room. Clients that send no Origin are not
+// browsers and are admitted on the session alone.
+func sameOriginHandshake(config *websocket.Config, r *http.Request) error {
+	raw := r.Header.Get("Origin")
+	if raw == "" {
+		return nil
+	}
+	origin, err := url.Parse(raw)
+	if err != nil || origin.Host != r.Host {
+		return errors.New("read-along origin is another site")
+	}
+	config.Origin = origin
+	return nil
+}
+
+// hijackableWriter reaches the connection beneath the middleware's response
+// wrappers, which x/net/websocket asserts on directly.
+type hijackableWriter struct {
+	http.ResponseWriter
+}
+
+func (w hijackableWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(w.ResponseWriter).Hijack()
+}
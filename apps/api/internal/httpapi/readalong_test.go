@@ -0,0 +1,117 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/websocket"
+
+	"pandapages/api/internal/readercontract"
+	"pandapages/api/internal/session"
+)
+
+func dialReadAlong(t *testing.T, server *httptest.Server, manager *session.Manager, path, origin string) *websocket.Conn {
+	t.Helper()
+	token, err := manager.Issue(testAccountID)
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+	config, err := websocket.NewConfig("ws"+strings.TrimPrefix(server.URL, "http")+path, origin)
+	if err != nil {
+		t.Fatalf("NewConfig: %v", err)
+	}
+	config.Header.Set("Cookie", session.CookieName+"="+token)
+	conn, err := websocket.DialConfig(config)
+	if err != nil {
+		t.Fatalf("dial %s: %v", path, err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+// nextReadAlong returns the next message of kind, skipping presence updates
+// unless they are what the test waits for.
+func nextReadAlong(t *testing.T, conn *websocket.Conn, kind string) readAlongMessage {
+	t.Helper()
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	for {
+		var message readAlongMessage
+		if err := websocket.JSON.Receive(conn, &message); err != nil {
+			t.Fatalf("waiting for %s: %v", kind, err)
+		}
+		if message.Type == kind {
+			return message
+		}
+		if message.Type != "presence" {
+			t.Fatalf("got %+v while waiting for %s", message, kind)
+		}
+	}
+}
+
+func TestReadAlongRelaysTheDriversPositionToFollowers(t *testing.T) {
+	manager := testSessionManager(t, false, time.Now)
+	server := httptest.NewServer(testHandler(t, &authTestStore{accountExists: true}, manager))
+	defer server.Close()
+
+	driver := dialReadAlong(t, server, manager, "/api/v1/ws/read-along/fox?role=drive", server.URL)
+	follower := dialReadAlong(t, server, manager, "/api/v1/ws/read-along/fox", server.URL)
+	if presence := nextReadAlong(t, driver, "presence"); *presence.Drivers != 1 {
+		t.Fatalf("presence = %+v", presence)
+	}
+
+	var locator readercontract.Locator
+	if err := json.Unmarshal([]byte(`{"schema":2,"segment":{"key":"`+progressTestKey+`","occurrence":1,"ordinal":4,"offset":0.5}}`), &locator); err != nil {
+		t.Fatal(err)
+	}
+	if err := websocket.JSON.Send(driver, readAlongMessage{Type: "position", Locator: &locator}); err != nil {
+		t.Fatal(err)
+	}
+	if got := nextReadAlong(t, follower, "position"); got.Locator == nil || got.Locator.Segment.Ordinal != 4 {
+		t.Fatalf("follower position = %+v", got)
+	}
+
+	// A device joining late starts where the driver is.
+	late := dialReadAlong(t, server, manager, "/api/v1/ws/read-along/fox", server.URL)
+	if got := nextReadAlong(t, late, "position"); got.Locator == nil || got.Locator.Segment.Ordinal != 4 {
+		t.Fatalf("late joiner position = %+v", got)
+	}
+
+	// Followers cannot move the room, and other stories are other rooms.
+	if err := websocket.JSON.Send(follower, readAlongMessage{Type: "position", Locator: &locator}); err != nil {
+		t.Fatal(err)
+	}
+	if got := nextReadAlong(t, follower, "error"); got.Code != "not_driving" {
+		t.Fatalf("follower move = %+v", got)
+	}
+	other := dialReadAlong(t, server, manager, "/api/v1/ws/read-along/owl", server.URL)
+	if presence := nextReadAlong(t, other, "presence"); *presence.Drivers != 0 || *presence.Followers != 1 {
+		t.Fatalf("other story presence = %+v", presence)
+	}
+}
+
+func TestReadAlongRefusesOtherSitesAndLockedSessions(t *testing.T) {
+	manager := testSessionManager(t, false, time.Now)
+	server := httptest.NewServer(testHandler(t, &authTestStore{accountExists: true}, manager))
+	defer server.Close()
+
+	token, _ := manager.Issue(testAccountID)
+	config, _ := websocket.NewConfig("ws"+strings.TrimPrefix(server.URL, "http")+"/api/v1/ws/read-along/fox", "https://elsewhere.example")
+	config.Header.Set("Cookie", session.CookieName+"="+token)
+	if conn, err := websocket.DialConfig(config); err == nil {
+		_ = conn.Close()
+		t.Fatal("cross-site handshake was accepted")
+	}
+
+	response, err := http.Get(server.URL + "/api/v1/ws/read-along/fox")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = response.Body.Close()
+	if response.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("locked status = %d, want 401", response.StatusCode)
+	}
+}
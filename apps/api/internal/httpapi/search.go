@@ -0,0 +1,59 @@
+package httpapi
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"pandapages/api/internal/embedding"
+)
+
+const (
+	maxSearchQueryRunes  = 500
+	defaultSemanticLimit = 10
+	maxSemanticLimit     = 25
+)
+
+// serveSemanticSearch answers GET /api/v1/search/semantic?q= with the
+// published stories closest in meaning to q, found by embedding it with the
+// same model as the stories.
+func serveSemanticSearch(store Store, embedder embedding.Provider, w http.ResponseWriter, r *http.Request, accountID string) {
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" || utf8.RuneCountInString(query) > maxSearchQueryRunes {
+		writeErr(w, http.StatusBadRequest, "query_invalid", "q must be 1 to "+strconv.Itoa(maxSearchQueryRunes)+" characters")
+		return
+	}
+	limit := defaultSemanticLimit
+	if value := strings.TrimSpace(r.URL.Query().Get("limit")); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > maxSemanticLimit {
+			writeErr(w, http.StatusBadRequest, "limit_invalid", "limit must be 1 to "+strconv.Itoa(maxSemanticLimit))
+			return
+		}
+		limit = n
+	}
+	if embedder == nil {
+		writeErr(w, http.StatusServiceUnavailable, "search_unavailable", "semantic search is not configured")
+		return
+	}
+
+	vectors, err := embedder.Embed(r.Context(), []string{query})
+	if err != nil || len(vectors) != 1 {
+		writeErr(w, http.StatusBadGateway, "search_failed", "query could not be embedded")
+		return
+	}
+	vector, ok := embedding.Normalize(vectors[0])
+	if !ok {
+		writeErr(w, http.StatusBadGateway, "search_failed", "query could not be embedded")
+		return
+	}
+	results, err := store.SemanticSearch(r.Context(), accountID, embedder.Model(), vector, limit)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, "db", "search query failed")
+		return
+	}
+
+	noStore(w)
+	writeJSON(w, http.StatusOK, results)
+}
@@ -0,0 +1,114 @@
+package httpapi
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"html/template"
+	"io"
+	"mime"
+	"strconv"
+	"strings"
+
+	"pandapages/api/internal/model"
+)
+
+// The Reader's HTML view is a whole page built from the stored segment HTML,
+// for e-ink and other simple browsers that cannot run the web app. It is
+// served for /api/v1/reader/{slug}.html, and for the plain Reader URL when
+// the Accept header prefers text/html to JSON, as a browser's navigation
+// does. It has no script; the page stylesheet is inline and pinned by hash.
+const storyPageStyle = `body{max-width:36em;margin:0 auto;padding:1em;font:1.25em/1.5 Georgia,serif}` +
+	`img{max-width:100%;height:auto}aside{margin:1em 0;padding-left:1em;border-left:2px solid}`
+
+var storyPageTemplate = template.Must(template.New("story").Parse(`<!doctype html>
+<html lang="{{.Language}}">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>` + storyPageStyle + `</style>
+</head>
+<body>
+<article>
+<header>
+<h1>{{.Title}}</h1>
+{{- with .Author}}
+<p>{{.}}</p>
+{{- end}}
+</header>
+{{range .Segments}}{{.}}
+{{end -}}
+</article>
+</body>
+</html>
+`))
+
+// storyPagePolicy lets the page show its own style and the account's images
+// and nothing else.
+var storyPagePolicy = func() string {
+	sum := sha256.Sum256([]byte(storyPageStyle))
+	return "default-src 'none'; img-src 'self'; style-src 'sha256-" + base64.StdEncoding.EncodeToString(sum[:]) +
+		"'; base-uri 'none'; form-action 'none'; frame-ancestors 'none'"
+}()
+
+type storyPage struct {
+	Language string
+	Title    string
+	Author   string
+	Segments []template.HTML
+}
+
+// encodeReaderStoryHTML writes story as a page. Segment HTML is the output of
+// the story renderer, which the web app also inserts as markup.
+func encodeReaderStoryHTML(out io.Writer, story model.ReaderStory) error {
+	page := storyPage{Language: story.Language, Title: story.Title, Segments: make([]template.HTML, 0, len(story.Segments))}
+	if story.Author != nil {
+		page.Author = *story.Author
+	}
+	for _, segment := range story.Segments {
+		if segment.RenderedHTML != "" {
+			page.Segments = append(page.Segments, template.HTML(segment.RenderedHTML))
+		}
+	}
+	return storyPageTemplate.Execute(out, page)
+}
+
+// prefersHTML reports whether accept ranks text/html above application/json.
+// A tie, including a missing header or */*, keeps JSON, which every existing
+// client expects.
+func prefersHTML(accept string) bool {
+	return acceptQuality(accept, "text/html") > acceptQuality(accept, "application/json")
+}
+
+// acceptQuality is the q-value accept gives mediaType through its most
+// specific matching range.
+func acceptQuality(accept, mediaType string) float64 {
+	major, _, _ := strings.Cut(mediaType, "/")
+	quality, specificity := 0.0, -1
+	for _, part := range strings.Split(accept, ",") {
+		media, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		rank := -1
+		switch media {
+		case mediaType:
+			rank = 2
+		case major + "/*":
+			rank = 1
+		case "*/*":
+			rank = 0
+		}
+		if rank <= specificity {
+			continue
+		}
+		q := 1.0
+		if raw, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(raw, 64); err != nil || q < 0 || q > 1 {
+				continue
+			}
+		}
+		quality, specificity = q, rank
+	}
+	return quality
+}
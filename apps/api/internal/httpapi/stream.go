@@ -0,0 +1,73 @@
+package httpapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+
+	"pandapages/api/internal/model"
+)
+
+// readerFlushSegments is how many segments are written between flushes, so
+// the Reader can start on a long book before the rest arrives.
+const readerFlushSegments = 64
+
+var emptySegmentsSuffix = []byte(`"segments":[]}`)
+
+// encodeReaderStory writes the fields of story that in selects as JSON a
+// segment at a time, so a long book is never held as one encoded buffer. The
+// bytes are exactly what json.Encoder produces for the whole selection.
+func encodeReaderStory(w io.Writer, story model.ReaderStory, in inclusion, flush func()) error {
+	if !in.segments {
+		return json.NewEncoder(w).Encode(readerStoryMeta{ReaderStory: story})
+	}
+	segments := story.Segments
+	story.Segments = []model.ReaderSegment{}
+	head, err := json.Marshal(story)
+	if err != nil {
+		return err
+	}
+	// Segments is the last field of a ReaderStory; anything else is encoded
+	// whole rather than streamed wrongly.
+	if !bytes.HasSuffix(head, emptySegmentsSuffix) {
+		whole := readerStorySelection{ReaderStory: story, Segments: make([]any, len(segments))}
+		for index, segment := range segments {
+			whole.Segments[index] = in.segment(segment)
+		}
+		return json.NewEncoder(w).Encode(whole)
+	}
+	if _, err := w.Write(head[:len(head)-len("]}")]); err != nil {
+		return err
+	}
+	for index, segment := range segments {
+		raw, err := json.Marshal(in.segment(segment))
+		if err != nil {
+			return err
+		}
+		if index > 0 {
+			if _, err := io.WriteString(w, ","); err != nil {
+				return err
+			}
+		}
+		if _, err := w.Write(raw); err != nil {
+			return err
+		}
+		if (index+1)%readerFlushSegments == 0 {
+			flush()
+		}
+	}
+	_, err = io.WriteString(w, "]}\n")
+	return err
+}
+
+// countingWriter counts the bytes written through it.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
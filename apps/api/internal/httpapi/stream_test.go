@@ -0,0 +1,77 @@
+package httpapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"pandapages/api/internal/model"
+)
+
+func TestEncodeReaderStoryMatchesEncodingTheWholeStory(t *testing.T) {
+	author := "A <Bold> & Author"
+	level := 2
+	segments := make([]model.ReaderSegment, 0, 2*readerFlushSegments+1)
+	for ordinal := 1; ordinal <= cap(segments); ordinal++ {
+		segments = append(segments, model.ReaderSegment{
+			Ordinal:           ordinal,
+			Kind:              "paragraph",
+			HeadingLevel:      &level,
+			ContentKey:        strings.Repeat("a", 64),
+			ContentOccurrence: ordinal,
+			RenderedHTML:      "<p>Café & “quotes”  </p>",
+			WordCount:         2,
+		})
+	}
+	for _, story := range []model.ReaderStory{
+		{Slug: "long", Title: "Long", Author: &author, Language: "en", Version: 2, Segments: segments},
+		{Slug: "empty", Title: "Empty", Language: "en", Version: 1},
+	} {
+		var want bytes.Buffer
+		if err := json.NewEncoder(&want).Encode(story); err != nil {
+			t.Fatalf("encode whole story: %v", err)
+		}
+		var got bytes.Buffer
+		flushes := 0
+		if err := encodeReaderStory(&got, story, includeEverything, func() { flushes++ }); err != nil {
+			t.Fatalf("stream story: %v", err)
+		}
+		if story.Segments == nil {
+			// A nil slice streams as [] rather than null; both decode alike.
+			var decoded model.ReaderStory
+			if err := json.Unmarshal(got.Bytes(), &decoded); err != nil || decoded.Slug != story.Slug || len(decoded.Segments) != 0 {
+				t.Fatalf("streamed empty story = %s", got.String())
+			}
+			continue
+		}
+		if got.String() != want.String() {
+			t.Fatalf("streamed story differs:\n got %.200s\nwant %.200s", got.String(), want.String())
+		}
+		if flushes != len(segments)/readerFlushSegments {
+			t.Fatalf("flushes = %d", flushes)
+		}
+	}
+}
+
+func TestEncodeReaderStoryOutlineMatchesEncodingTheProjection(t *testing.T) {
+	segments := make([]model.ReaderSegment, readerFlushSegments+1)
+	projected := make([]any, len(segments))
+	for index := range segments {
+		segments[index] = model.ReaderSegment{Ordinal: index + 1, Kind: "paragraph", RenderedHTML: "<p>heavy</p>", WordCount: 1}
+		projected[index] = readerSegmentOutline{ReaderSegment: segments[index]}
+	}
+	story := model.ReaderStory{Slug: "outline", Title: "Outline", Language: "en", Version: 1, Segments: segments}
+
+	var want bytes.Buffer
+	if err := json.NewEncoder(&want).Encode(readerStorySelection{ReaderStory: story, Segments: projected}); err != nil {
+		t.Fatalf("encode projection: %v", err)
+	}
+	var got bytes.Buffer
+	if err := encodeReaderStory(&got, story, inclusion{segments: true}, func() {}); err != nil {
+		t.Fatalf("stream outline: %v", err)
+	}
+	if got.String() != want.String() || strings.Contains(got.String(), "renderedHtml") {
+		t.Fatalf("streamed outline differs:\n got %.200s\nwant %.200s", got.String(), want.String())
+	}
+}
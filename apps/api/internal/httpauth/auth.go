@@ -1,6 +1,7 @@
 package httpauth
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
@@ -11,7 +12,7 @@ import (
 var ErrInvalidSession = errors.New("invalid session")
 
 type AccountStore interface {
-	AccountExists(accountID string) (bool, error)
+	AccountExists(ctx context.Context, accountID string) (bool, error)
 }
 
 type Authenticator struct {
@@ -35,7 +36,7 @@ func (a *Authenticator) Authenticate(r *http.Request) (string, error) {
 		return "", ErrInvalidSession
 	}
 
-	exists, err := a.accounts.AccountExists(claims.AccountID)
+	exists, err := a.accounts.AccountExists(r.Context(), claims.AccountID)
 	if err != nil {
 		return "", fmt.Errorf("validate session account: %w", err)
 	}
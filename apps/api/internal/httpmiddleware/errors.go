@@ -0,0 +1,49 @@
+package httpmiddleware
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+
+	"pandapages/api/internal/model"
+)
+
+// DecodeFieldErrors names the fields a JSON decoding error is about: every
+// problem a CheckJSON *ValidationError found, or the one field a decoder's
+// type error names. Syntax errors belong to no field and yield none.
+func DecodeFieldErrors(err error) []model.FieldError {
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		return validationErr.Fields
+	}
+	var typeErr *json.UnmarshalTypeError
+	if !errors.As(err, &typeErr) || typeErr.Field == "" {
+		return nil
+	}
+	return []model.FieldError{{
+		Path:    typeErr.Field,
+		Code:    "type_mismatch",
+		Message: typeErr.Field + " must be " + jsonKind(typeErr.Type),
+	}}
+}
+
+func jsonKind(t reflect.Type) string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.String:
+		return "a string"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "an integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	default:
+		return "an object"
+	}
+}
@@ -0,0 +1,42 @@
+package httpmiddleware
+
+import (
+	"context"
+	"log/slog"
+)
+
+// NewLogHandler wraps handler so every record logged with a request's
+// context, from a handler or anything it calls, carries the request ID
+// without each call site passing it.
+func NewLogHandler(handler slog.Handler) slog.Handler {
+	return logHandler{Handler: handler}
+}
+
+type logHandler struct {
+	slog.Handler
+}
+
+func (h logHandler) Handle(ctx context.Context, record slog.Record) error {
+	if info := requestInfoFrom(ctx); info != nil && !hasAttr(record, "request_id") {
+		record = record.Clone()
+		record.AddAttrs(slog.String("request_id", info.id))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h logHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return logHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h logHandler) WithGroup(name string) slog.Handler {
+	return logHandler{Handler: h.Handler.WithGroup(name)}
+}
+
+func hasAttr(record slog.Record, key string) bool {
+	found := false
+	record.Attrs(func(attr slog.Attr) bool {
+		found = attr.Key == key
+		return !found
+	})
+	return found
+}
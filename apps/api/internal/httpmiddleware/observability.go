@@ -20,20 +20,43 @@ const (
 	randomIDBytes   = 16
 )
 
-type requestIDContextKey struct{}
+type requestInfoContextKey struct{}
+
+// requestInfo is what the access log learns about a request. The ID is fixed
+// on arrival; the account is filled in by whichever handler authenticates it.
+type requestInfo struct {
+	id        string
+	accountID string
+}
+
+func requestInfoFrom(ctx context.Context) *requestInfo {
+	info, _ := ctx.Value(requestInfoContextKey{}).(*requestInfo)
+	return info
+}
 
 // RequestIDFromContext returns the validated or generated request ID attached
 // to a request by Observe.
 func RequestIDFromContext(r *http.Request) string {
-	requestID, _ := r.Context().Value(requestIDContextKey{}).(string)
-	return requestID
+	if info := requestInfoFrom(r.Context()); info != nil {
+		return info.id
+	}
+	return ""
 }
 
-// Observe applies middleware in the deliberate order request ID -> completion
-// logging -> panic recovery -> application handler. This ensures recovered
-// panics are recorded as one completed 500 request with the same request ID.
+// SetAccount records the account a request acted for in its access log. It
+// does nothing outside Observe.
+func SetAccount(ctx context.Context, accountID string) {
+	if info := requestInfoFrom(ctx); info != nil {
+		info.accountID = accountID
+	}
+}
+
+// Observe applies middleware in the deliberate order request ID -> tracing ->
+// completion logging -> panic recovery -> application handler. This ensures
+// recovered panics are recorded as one completed 500 request with the same
+// request ID and trace.
 func Observe(next http.Handler) http.Handler {
-	return withRequestID(withCompletionLog(withRecovery(next)))
+	return withRequestID(withTrace(withCompletionLog(withRecovery(next))))
 }
 
 func withRequestID(next http.Handler) http.Handler {
@@ -44,15 +67,11 @@ func withRequestID(next http.Handler) http.Handler {
 		}
 
 		w.Header().Set(RequestIDHeader, requestID)
-		ctx := contextWithRequestID(r, requestID)
+		ctx := context.WithValue(r.Context(), requestInfoContextKey{}, &requestInfo{id: requestID})
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
 
-func contextWithRequestID(r *http.Request, requestID string) context.Context {
-	return context.WithValue(r.Context(), requestIDContextKey{}, requestID)
-}
-
 func acceptedRequestID(values []string) string {
 	if len(values) != 1 || !validRequestID(values[0]) {
 		return ""
@@ -127,14 +146,21 @@ func withCompletionLog(next http.Handler) http.Handler {
 		metrics := &responseMetrics{ResponseWriter: w, status: http.StatusOK}
 
 		defer func() {
-			slog.InfoContext(r.Context(), "http request completed",
+			attrs := []any{
 				"request_id", RequestIDFromContext(r),
 				"method", r.Method,
 				"path", safePath(r),
 				"status", metrics.status,
 				"duration", time.Since(started),
 				"response_bytes", metrics.bytes,
-			)
+			}
+			if info := requestInfoFrom(r.Context()); info != nil && info.accountID != "" {
+				attrs = append(attrs, "account", info.accountID)
+			}
+			if traceID := traceIDFromContext(r.Context()); traceID != "" {
+				attrs = append(attrs, "trace_id", traceID)
+			}
+			slog.InfoContext(r.Context(), "http request completed", attrs...)
 		}()
 
 		next.ServeHTTP(metrics, r)
@@ -176,9 +202,19 @@ func writePanicResponse(w http.ResponseWriter) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusInternalServerError)
 	_ = json.NewEncoder(w).Encode(map[string]any{
-		"error": map[string]any{
-			"code":    "panic",
-			"message": "internal error",
-		},
+		"error": ErrorBody(w, "panic", "internal error"),
 	})
 }
+
+// ErrorBody is the "error" object of a JSON error response. It repeats the
+// response's request ID so a reader reporting a failure can quote it.
+func ErrorBody(w http.ResponseWriter, code, message string) map[string]any {
+	body := map[string]any{
+		"code":    code,
+		"message": message,
+	}
+	if requestID := w.Header().Get(RequestIDHeader); requestID != "" {
+		body["requestId"] = requestID
+	}
+	return body
+}
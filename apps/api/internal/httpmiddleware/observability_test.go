@@ -134,6 +134,9 @@ func TestObserveRecoversPanicWithRequestIDStackAndOneCompletion(t *testing.T) {
 	if !strings.Contains(response.Body.String(), `"code":"panic"`) || strings.Contains(response.Body.String(), panicSecret) || strings.Contains(response.Body.String(), "goroutine") {
 		t.Fatalf("panic response is not the safe contract: %s", response.Body.String())
 	}
+	if !strings.Contains(response.Body.String(), `"requestId":"`+requestID+`"`) {
+		t.Fatalf("panic response omits the request ID: %s", response.Body.String())
+	}
 
 	records := decodeLogRecords(t, logs.String())
 	if len(records) != 2 {
@@ -166,6 +169,37 @@ func TestObserveRecoversPanicWithRequestIDStackAndOneCompletion(t *testing.T) {
 	}
 }
 
+func TestObserveLogsTheAccountAndTagsHandlerLogs(t *testing.T) {
+	var output bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(NewLogHandler(slog.NewJSONHandler(&output, nil))))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
+	const requestID = "support-ticket-42"
+	handler := Observe(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		SetAccount(r.Context(), "account-1")
+		slog.WarnContext(r.Context(), "story cache miss")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	request := httptest.NewRequest(http.MethodGet, "/api/v1/library", nil)
+	request.Header.Set(RequestIDHeader, requestID)
+	handler.ServeHTTP(httptest.NewRecorder(), request)
+
+	records := decodeLogRecords(t, output.String())
+	if len(records) != 2 {
+		t.Fatalf("log record count = %d, want 2; logs = %s", len(records), output.String())
+	}
+	if records[0]["msg"] != "story cache miss" || records[0]["request_id"] != requestID {
+		t.Fatalf("handler log = %#v, want it tagged with the request ID", records[0])
+	}
+	if records[1]["account"] != "account-1" || records[1]["request_id"] != requestID {
+		t.Fatalf("access log = %#v, want the account and request ID", records[1])
+	}
+	if strings.Count(output.String(), `"request_id"`) != 2 {
+		t.Fatalf("request ID repeated within a record: %s", output.String())
+	}
+}
+
 func TestResponseMetricsSupportsResponseControllerUnwrap(t *testing.T) {
 	_ = captureLogs(t)
 	handler := Observe(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
@@ -0,0 +1,70 @@
+package httpmiddleware
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("pandapages/api/internal/httpmiddleware")
+
+// withTrace opens the server span every Store and ingest span of the request
+// nests under, continuing the caller's trace when it sent a traceparent.
+// The span is named by method and, once a mux has matched it, route pattern;
+// raw paths carry slugs and would make every story its own span name.
+func withTrace(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := tracer.Start(ctx, r.Method,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.request.method", r.Method),
+				attribute.String("url.path", safePath(r)),
+				attribute.String("pandapages.request_id", RequestIDFromContext(r)),
+			),
+		)
+		defer span.End()
+
+		metrics := &responseMetrics{ResponseWriter: w, status: http.StatusOK}
+		traced := r.WithContext(ctx)
+		next.ServeHTTP(metrics, traced)
+
+		// ServeMux records the pattern it matched on the request it was given.
+		if route := routeOf(traced.Pattern); route != "" {
+			span.SetName(r.Method + " " + route)
+			span.SetAttributes(attribute.String("http.route", route))
+		}
+		span.SetAttributes(
+			attribute.Int("http.response.status_code", metrics.status),
+			attribute.Int64("http.response.body.size", metrics.bytes),
+		)
+		if metrics.status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, strconv.Itoa(metrics.status))
+		}
+	})
+}
+
+// routeOf drops the method from a pattern such as "GET /stories/{slug}".
+func routeOf(pattern string) string {
+	if _, route, ok := strings.Cut(pattern, " "); ok {
+		return strings.TrimSpace(route)
+	}
+	return pattern
+}
+
+// traceIDFromContext returns the trace a request belongs to, or "" when it is
+// not being traced.
+func traceIDFromContext(ctx context.Context) string {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.IsValid() {
+		return ""
+	}
+	return spanContext.TraceID().String()
+}
@@ -0,0 +1,66 @@
+package httpmiddleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestObserveTracesRequestsUnderTheCallersTrace(t *testing.T) {
+	logs := captureLogs(t)
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	var handlerSpan trace.SpanContext
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /stories/{slug}", func(w http.ResponseWriter, r *http.Request) {
+		handlerSpan = trace.SpanContextFromContext(r.Context())
+		w.WriteHeader(http.StatusBadGateway)
+	})
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	request := httptest.NewRequest(http.MethodGet, "/stories/the-snail", nil)
+	request.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	Observe(mux).ServeHTTP(httptest.NewRecorder(), request)
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("ended spans = %d, want 1", len(spans))
+	}
+	span := spans[0]
+	if span.Name() != "GET /stories/{slug}" || span.SpanKind() != trace.SpanKindServer {
+		t.Fatalf("span = %q (%v), want the matched route as a server span", span.Name(), span.SpanKind())
+	}
+	if got := span.SpanContext().TraceID().String(); got != traceID {
+		t.Fatalf("trace ID = %s, want the caller's %s", got, traceID)
+	}
+	if handlerSpan.SpanID() != span.SpanContext().SpanID() {
+		t.Fatal("handler context does not carry the request span")
+	}
+	if span.Status().Code != codes.Error {
+		t.Fatalf("span status = %v, want an error for a 502", span.Status())
+	}
+	attributes := map[attribute.Key]attribute.Value{}
+	for _, kv := range span.Attributes() {
+		attributes[kv.Key] = kv.Value
+	}
+	if attributes["http.route"].AsString() != "/stories/{slug}" ||
+		attributes["url.path"].AsString() != "/stories/the-snail" ||
+		attributes["http.response.status_code"].AsInt64() != http.StatusBadGateway {
+		t.Fatalf("span attributes = %v", span.Attributes())
+	}
+
+	records := decodeLogRecords(t, logs.String())
+	if len(records) != 1 || records[0]["trace_id"] != traceID {
+		t.Fatalf("completion log = %v, want trace_id %s", records, traceID)
+	}
+}
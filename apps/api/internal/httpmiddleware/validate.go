@@ -0,0 +1,233 @@
+package httpmiddleware
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"maps"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"pandapages/api/internal/model"
+)
+
+// ValidationError is every structural problem CheckJSON found in a body, so
+// a client fixes them in one round trip instead of one per request.
+type ValidationError struct {
+	Fields []model.FieldError
+}
+
+func (e *ValidationError) Error() string {
+	if len(e.Fields) == 1 {
+		return "request body has 1 problem"
+	}
+	return "request body has " + strconv.Itoa(len(e.Fields)) + " problems"
+}
+
+var (
+	jsonUnmarshalerType = reflect.TypeFor[json.Unmarshaler]()
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+)
+
+// CheckJSON compares raw with the shape of dst before it is decoded and
+// returns a *ValidationError naming every problem: values of the wrong type,
+// keys a struct marks `required:"true"` that are missing, and unknown keys.
+// Unknown keys are counted against the object holding them rather than
+// named, as the name is the client's own text. Bodies that are not JSON at
+// all return nil; the decoder reports those.
+func CheckJSON(raw []byte, dst any) error {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var value any
+	if err := dec.Decode(&value); err != nil {
+		return nil
+	}
+	var fields []model.FieldError
+	checkValue(value, reflect.TypeOf(dst), "", &fields)
+	if len(fields) == 0 {
+		return nil
+	}
+	return &ValidationError{Fields: fields}
+}
+
+func checkValue(value any, t reflect.Type, path string, fields *[]model.FieldError) {
+	if value == nil || t == nil {
+		// null leaves any Go value as it was.
+		return
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if reflect.PointerTo(t).Implements(jsonUnmarshalerType) || reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		// The type defines its own JSON; the decoder checks it.
+		return
+	}
+	mismatch := func() {
+		*fields = append(*fields, model.FieldError{Path: path, Code: "type_mismatch", Message: describe(path) + " must be " + jsonKind(t)})
+	}
+	switch t.Kind() {
+	case reflect.Interface:
+	case reflect.Struct:
+		object, ok := value.(map[string]any)
+		if !ok {
+			mismatch()
+			return
+		}
+		checkObject(object, t, path, fields)
+	case reflect.Map:
+		object, ok := value.(map[string]any)
+		if !ok {
+			mismatch()
+			return
+		}
+		// Map keys are data, not field names, and stay out of paths.
+		for _, key := range slices.Sorted(maps.Keys(object)) {
+			checkValue(object[key], t.Elem(), path, fields)
+		}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			if _, ok := value.(string); !ok {
+				mismatch()
+			}
+			return
+		}
+		items, ok := value.([]any)
+		if !ok {
+			mismatch()
+			return
+		}
+		for index, item := range items {
+			checkValue(item, t.Elem(), join(path, strconv.Itoa(index)), fields)
+		}
+	case reflect.String:
+		if _, ok := value.(string); !ok {
+			mismatch()
+		}
+	case reflect.Bool:
+		if _, ok := value.(bool); !ok {
+			mismatch()
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		number, ok := value.(json.Number)
+		if !ok {
+			mismatch()
+		} else if _, err := strconv.ParseInt(number.String(), 10, t.Bits()); err != nil {
+			mismatch()
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		number, ok := value.(json.Number)
+		if !ok {
+			mismatch()
+		} else if _, err := strconv.ParseUint(number.String(), 10, t.Bits()); err != nil {
+			mismatch()
+		}
+	case reflect.Float32, reflect.Float64:
+		if _, ok := value.(json.Number); !ok {
+			mismatch()
+		}
+	}
+}
+
+func checkObject(object map[string]any, t reflect.Type, path string, fields *[]model.FieldError) {
+	known := structFields(t)
+	unknown := 0
+	seen := map[string]bool{}
+	for _, key := range slices.Sorted(maps.Keys(object)) {
+		item := object[key]
+		field, ok := matchField(known, key)
+		if !ok {
+			unknown++
+			continue
+		}
+		seen[field.name] = true
+		if !field.quoted {
+			checkValue(item, field.typ, join(path, field.name), fields)
+		}
+	}
+	for _, field := range known {
+		if field.required && !seen[field.name] {
+			*fields = append(*fields, model.FieldError{Path: join(path, field.name), Code: "required", Message: join(path, field.name) + " is required"})
+		}
+	}
+	if unknown > 0 {
+		message := describe(path) + " has " + strconv.Itoa(unknown) + " unknown fields"
+		if unknown == 1 {
+			message = describe(path) + " has an unknown field"
+		}
+		*fields = append(*fields, model.FieldError{Path: path, Code: "unknown_field", Message: message})
+	}
+}
+
+type structField struct {
+	name     string
+	typ      reflect.Type
+	required bool
+	quoted   bool
+}
+
+// structFields lists the fields encoding/json decodes into t, flattening
+// embedded structs.
+func structFields(t reflect.Type) []structField {
+	var out []structField
+	for i := range t.NumField() {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" || (!field.IsExported() && !field.Anonymous) {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				out = append(out, structFields(embedded)...)
+				continue
+			}
+		}
+		if name == "" {
+			name = field.Name
+		}
+		out = append(out, structField{
+			name:     name,
+			typ:      field.Type,
+			required: field.Tag.Get("required") == "true",
+			quoted:   strings.Contains(","+options+",", ",string,"),
+		})
+	}
+	return out
+}
+
+// matchField finds key as encoding/json does: an exact name first, then one
+// equal under case folding.
+func matchField(fields []structField, key string) (structField, bool) {
+	for _, field := range fields {
+		if field.name == key {
+			return field, true
+		}
+	}
+	for _, field := range fields {
+		if strings.EqualFold(field.name, key) {
+			return field, true
+		}
+	}
+	return structField{}, false
+}
+
+func join(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// describe names path in a message; the body itself has the empty path.
+func describe(path string) string {
+	if path == "" {
+		return "the body"
+	}
+	return path
+}
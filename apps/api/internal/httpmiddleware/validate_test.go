@@ -0,0 +1,50 @@
+package httpmiddleware
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"pandapages/api/internal/model"
+)
+
+func TestCheckJSONReportsEveryProblemWithItsPath(t *testing.T) {
+	type rights struct {
+		License string `json:"license" required:"true"`
+	}
+	type body struct {
+		Name   string            `json:"name" required:"true"`
+		Count  int8              `json:"count"`
+		Rights rights            `json:"rights"`
+		Pages  []rights          `json:"pages"`
+		Labels map[string]string `json:"labels"`
+		Note   *string           `json:"note"`
+	}
+
+	err := CheckJSON([]byte(`{"COUNT":300,"rights":{"x":1,"y":2},"pages":[{"license":"cc"},{"license":false}],"labels":{"secret":1},"note":null,"extra":true}`), &body{})
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("err = %v, want *ValidationError", err)
+	}
+	want := []model.FieldError{
+		{Path: "count", Code: "type_mismatch", Message: "count must be an integer"},
+		{Path: "labels", Code: "type_mismatch", Message: "labels must be a string"},
+		{Path: "pages.1.license", Code: "type_mismatch", Message: "pages.1.license must be a string"},
+		{Path: "rights.license", Code: "required", Message: "rights.license is required"},
+		{Path: "rights", Code: "unknown_field", Message: "rights has 2 unknown fields"},
+		{Path: "name", Code: "required", Message: "name is required"},
+		{Path: "", Code: "unknown_field", Message: "the body has an unknown field"},
+	}
+	if !reflect.DeepEqual(validationErr.Fields, want) {
+		t.Fatalf("fields = %#v", validationErr.Fields)
+	}
+	if got := DecodeFieldErrors(err); !reflect.DeepEqual(got, want) {
+		t.Fatalf("DecodeFieldErrors = %#v", got)
+	}
+
+	for _, raw := range []string{`{"name":"ok","count":-128}`, `not json`} {
+		if err := CheckJSON([]byte(raw), &body{}); err != nil {
+			t.Fatalf("CheckJSON(%s) = %v, want nil", raw, err)
+		}
+	}
+}
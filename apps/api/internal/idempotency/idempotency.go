@@ -0,0 +1,182 @@
+// Package idempotency lets clients on flaky networks retry unsafe requests.
+// A request sent with an Idempotency-Key runs once per account and key: a
+// retry with the same key and body gets the first response again instead of
+// repeating the change, and a retry with a different body is refused.
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"pandapages/api/internal/httpmiddleware"
+	"pandapages/api/internal/model"
+)
+
+const (
+	// Header carries the client's key, which it keeps for every retry of one
+	// logical request.
+	Header = "Idempotency-Key"
+	// ReplayedHeader marks a response replayed from an earlier request.
+	ReplayedHeader = "Idempotent-Replayed"
+
+	maxKeyLength = 255
+)
+
+type Store interface {
+	IdempotencyClaim(ctx context.Context, accountID, key, fingerprint string) (model.IdempotentResponse, bool, error)
+	IdempotencySave(ctx context.Context, accountID, key string, response model.IdempotentResponse) error
+	IdempotencyRelease(ctx context.Context, accountID, key, fingerprint string) error
+}
+
+// Serve runs next at most once for the request's Idempotency-Key. Requests
+// without one, and bodies over maxBody, which next will refuse anyway, pass
+// straight through. Responses below 500 are kept for retries; after a server
+// error the key is released so that a retry runs the request again.
+func Serve(store Store, w http.ResponseWriter, r *http.Request, accountID string, maxBody int64, next http.HandlerFunc) {
+	key := r.Header.Get(Header)
+	if key == "" {
+		next(w, r)
+		return
+	}
+	if !validKey(key) {
+		writeErr(w, http.StatusBadRequest, "idempotency_key_invalid", "Idempotency-Key must be 1 to 255 visible ASCII characters")
+		return
+	}
+
+	raw, err := io.ReadAll(io.LimitReader(r.Body, maxBody+1))
+	if err != nil || int64(len(raw)) > maxBody {
+		r.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(raw), r.Body), r.Body}
+		next(w, r)
+		return
+	}
+	r.Body = io.NopCloser(bytes.NewReader(raw))
+	fingerprint := requestFingerprint(r, raw)
+
+	prior, claimed, err := store.IdempotencyClaim(r.Context(), accountID, key, fingerprint)
+	if err != nil {
+		slog.Error("idempotency key claim failed")
+		writeErr(w, http.StatusServiceUnavailable, "idempotency_unavailable", "request could not be made idempotent")
+		return
+	}
+	if !claimed {
+		replay(w, prior, fingerprint)
+		return
+	}
+
+	recorder := &recorder{ResponseWriter: w}
+	saved := false
+	// Saving outlives a client that gave up waiting, since its retry is the
+	// one that needs the response.
+	ctx := context.WithoutCancel(r.Context())
+	defer func() {
+		if !saved {
+			if err := store.IdempotencyRelease(ctx, accountID, key, fingerprint); err != nil {
+				slog.Warn("idempotency key release failed")
+			}
+		}
+	}()
+	next(recorder, r)
+
+	status := recorder.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	if status >= http.StatusInternalServerError {
+		return
+	}
+	err = store.IdempotencySave(ctx, accountID, key, model.IdempotentResponse{
+		Fingerprint: fingerprint,
+		Status:      status,
+		ContentType: recorder.Header().Get("Content-Type"),
+		Body:        recorder.body.Bytes(),
+	})
+	if err != nil {
+		slog.Warn("idempotency response save failed")
+		return
+	}
+	saved = true
+}
+
+// replay answers a retry from what its key already holds.
+func replay(w http.ResponseWriter, prior model.IdempotentResponse, fingerprint string) {
+	switch {
+	case prior.Fingerprint != fingerprint:
+		writeErr(w, http.StatusUnprocessableEntity, "idempotency_key_reused", "Idempotency-Key was already used for a different request")
+	case prior.Status == 0:
+		w.Header().Set("Retry-After", "1")
+		writeErr(w, http.StatusConflict, "idempotency_key_in_flight", "a request with this Idempotency-Key is still running")
+	default:
+		w.Header().Set("Cache-Control", "no-store")
+		if prior.ContentType != "" {
+			w.Header().Set("Content-Type", prior.ContentType)
+		}
+		w.Header().Set(ReplayedHeader, "true")
+		w.WriteHeader(prior.Status)
+		_, _ = w.Write(prior.Body)
+	}
+}
+
+// requestFingerprint identifies what a key was first used for, so that a
+// key sent again with another request is caught rather than replayed.
+func requestFingerprint(r *http.Request, body []byte) string {
+	hash := sha256.New()
+	_, _ = io.WriteString(hash, r.Method+" "+r.URL.Path+"\n")
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func validKey(key string) bool {
+	if len(key) > maxKeyLength {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] < 0x21 || key[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// recorder passes a response through while keeping a copy for retries.
+type recorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (r *recorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *recorder) Write(p []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	r.body.Write(p)
+	return r.ResponseWriter.Write(p)
+}
+
+func (r *recorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+func writeErr(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"error": httpmiddleware.ErrorBody(w, code, message),
+	})
+}
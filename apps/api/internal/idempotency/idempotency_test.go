@@ -0,0 +1,105 @@
+package idempotency
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"pandapages/api/internal/model"
+)
+
+type memStore struct {
+	responses map[string]model.IdempotentResponse
+}
+
+func (s *memStore) IdempotencyClaim(_ context.Context, accountID, key, fingerprint string) (model.IdempotentResponse, bool, error) {
+	if prior, ok := s.responses[accountID+"/"+key]; ok {
+		return prior, false, nil
+	}
+	s.responses[accountID+"/"+key] = model.IdempotentResponse{Fingerprint: fingerprint}
+	return model.IdempotentResponse{}, true, nil
+}
+
+func (s *memStore) IdempotencySave(_ context.Context, accountID, key string, response model.IdempotentResponse) error {
+	s.responses[accountID+"/"+key] = response
+	return nil
+}
+
+func (s *memStore) IdempotencyRelease(_ context.Context, accountID, key, _ string) error {
+	delete(s.responses, accountID+"/"+key)
+	return nil
+}
+
+func TestServeRunsAKeyedRequestOnce(t *testing.T) {
+	store := &memStore{responses: map[string]model.IdempotentResponse{}}
+	calls := 0
+	status := http.StatusCreated
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(`{"saved":` + string(body) + `}`))
+	}
+	serve := func(key, body string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodPut, "/api/v1/progress/fox", strings.NewReader(body))
+		if key != "" {
+			request.Header.Set(Header, key)
+		}
+		response := httptest.NewRecorder()
+		Serve(store, response, request, "account", 1<<10, handler)
+		return response
+	}
+
+	first := serve("retry-1", "1")
+	if first.Code != http.StatusCreated || first.Header().Get(ReplayedHeader) != "" || calls != 1 {
+		t.Fatalf("first = %d %q, calls = %d", first.Code, first.Header().Get(ReplayedHeader), calls)
+	}
+	retry := serve("retry-1", "1")
+	if retry.Code != http.StatusCreated || retry.Body.String() != `{"saved":1}` ||
+		retry.Header().Get("Content-Type") != "application/json" || retry.Header().Get(ReplayedHeader) != "true" || calls != 1 {
+		t.Fatalf("retry = %d %q %v, calls = %d", retry.Code, retry.Body.String(), retry.Header(), calls)
+	}
+
+	if reused := serve("retry-1", "2"); reused.Code != http.StatusUnprocessableEntity || calls != 1 ||
+		!strings.Contains(reused.Body.String(), `"idempotency_key_reused"`) {
+		t.Fatalf("reused key = %d %s, calls = %d", reused.Code, reused.Body.String(), calls)
+	}
+	if unkeyed := serve("", "1"); unkeyed.Code != http.StatusCreated || calls != 2 {
+		t.Fatalf("unkeyed = %d, calls = %d", unkeyed.Code, calls)
+	}
+	if invalid := serve("has space", "1"); invalid.Code != http.StatusBadRequest || calls != 2 {
+		t.Fatalf("invalid key = %d, calls = %d", invalid.Code, calls)
+	}
+
+	status = http.StatusInternalServerError
+	serve("retry-2", "3")
+	status = http.StatusOK
+	if again := serve("retry-2", "3"); again.Code != http.StatusOK || again.Header().Get(ReplayedHeader) != "" || calls != 4 {
+		t.Fatalf("retry after server error = %d, calls = %d; want the request run again", again.Code, calls)
+	}
+}
+
+func TestServeRefusesARetryWhileTheFirstIsRunning(t *testing.T) {
+	store := &memStore{responses: map[string]model.IdempotentResponse{}}
+	var retry *httptest.ResponseRecorder
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		request := httptest.NewRequest(http.MethodPost, "/api/v1/admin/stories/fox/publish", strings.NewReader(`{}`))
+		request.Header.Set(Header, "slow")
+		retry = httptest.NewRecorder()
+		Serve(store, retry, request, "account", 1<<10, func(http.ResponseWriter, *http.Request) {
+			t.Fatal("retry ran while the first request was running")
+		})
+		w.WriteHeader(http.StatusOK)
+	}
+	request := httptest.NewRequest(http.MethodPost, "/api/v1/admin/stories/fox/publish", strings.NewReader(`{}`))
+	request.Header.Set(Header, "slow")
+	Serve(store, httptest.NewRecorder(), request, "account", 1<<10, handler)
+
+	if retry.Code != http.StatusConflict || retry.Header().Get("Retry-After") == "" {
+		t.Fatalf("concurrent retry = %d %v, want 409 with Retry-After", retry.Code, retry.Header())
+	}
+}
@@ -0,0 +1,110 @@
+// Package jobrunner drives leased background jobs that work through a list of
+// items one at a time, as narration, alignment and search reindexing do. The
+// Store claims a job under a lease and keeps a cursor past the last item it
+// recorded, renewing the lease each time, so a job interrupted by a restart is
+// claimed again once its lease expires and resumes after that item.
+package jobrunner
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// Jobs is the work of one kind of job: J is a job and I one of its items.
+type Jobs[J, I any] interface {
+	// Claim leases the oldest job waiting to run; ok is false when there is
+	// none.
+	Claim(ctx context.Context, lease time.Duration) (job J, ok bool, err error)
+	// Pending lists, in order, up to limit items after the job's cursor.
+	Pending(ctx context.Context, job J, limit int) ([]I, error)
+	// Process does the work of one item and records it, returning the job
+	// with its cursor moved past the item and its lease renewed. A non-empty
+	// failure fails the job there instead; err is a failure to record, left
+	// for a later claim to retry.
+	Process(ctx context.Context, job J, item I, lease time.Duration) (next J, failure string, err error)
+	// Finish completes the job, or fails it with failure.
+	Finish(ctx context.Context, job J, failure *string) (J, error)
+	// ID names the job in logs, and Summary gives the counts logged once it
+	// has finished.
+	ID(job J) string
+	Summary(job J) []any
+}
+
+// Runner claims jobs of one kind and works each through to the end.
+type Runner[J, I any] struct {
+	Jobs Jobs[J, I]
+	// Kind and Item name the job and its items in logs, as "narration" and
+	// "segment".
+	Kind string
+	Item string
+	// Lease is renewed after every item, so it need only outlast one.
+	Lease    time.Duration
+	Interval time.Duration
+	Batch    int
+}
+
+// Run processes queued jobs until ctx is cancelled.
+func (r *Runner[J, I]) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.Interval)
+	defer ticker.Stop()
+	for {
+		r.RunOnce(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce claims one job and works through its items until it finishes or
+// ctx ends. An unfinished job is picked up again once its lease expires.
+func (r *Runner[J, I]) RunOnce(ctx context.Context) {
+	job, ok, err := r.Jobs.Claim(ctx, r.Lease)
+	if err != nil {
+		slog.Error(r.Kind + " job claim failed")
+		return
+	}
+	if !ok {
+		return
+	}
+	for {
+		items, err := r.Jobs.Pending(ctx, job, r.Batch)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			slog.Error(r.Kind+" job "+r.Item+"s read failed", "job", r.Jobs.ID(job))
+			return
+		}
+		if len(items) == 0 {
+			r.finish(ctx, job, nil)
+			return
+		}
+		for _, item := range items {
+			next, failure, err := r.Jobs.Process(ctx, job, item, r.Lease)
+			if ctx.Err() != nil {
+				return
+			}
+			if failure != "" {
+				r.finish(ctx, job, &failure)
+				return
+			}
+			if err != nil {
+				slog.Error(r.Kind+" "+r.Item+" record failed", "job", r.Jobs.ID(job))
+				return
+			}
+			job = next
+		}
+	}
+}
+
+func (r *Runner[J, I]) finish(ctx context.Context, job J, failure *string) {
+	finished, err := r.Jobs.Finish(ctx, job, failure)
+	if err != nil {
+		slog.Error(r.Kind+" job finish failed", "job", r.Jobs.ID(job))
+		return
+	}
+	slog.Info(r.Kind+" job finished", append([]any{"job", r.Jobs.ID(finished)}, r.Jobs.Summary(finished)...)...)
+}
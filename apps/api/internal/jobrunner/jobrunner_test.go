@@ -0,0 +1,87 @@
+package jobrunner
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type fakeJob struct {
+	id     string
+	cursor int
+	done   int
+}
+
+type fakeJobs struct {
+	queued    []fakeJob
+	items     []int
+	failOn    int
+	recordErr error
+	finished  []*string
+}
+
+func (f *fakeJobs) Claim(context.Context, time.Duration) (fakeJob, bool, error) {
+	if len(f.queued) == 0 {
+		return fakeJob{}, false, nil
+	}
+	job := f.queued[0]
+	f.queued = f.queued[1:]
+	return job, true, nil
+}
+
+func (f *fakeJobs) Pending(_ context.Context, job fakeJob, limit int) ([]int, error) {
+	var out []int
+	for _, item := range f.items {
+		if item > job.cursor && len(out) < limit {
+			out = append(out, item)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeJobs) Process(_ context.Context, job fakeJob, item int, _ time.Duration) (fakeJob, string, error) {
+	if item == f.failOn {
+		return job, "item refused", nil
+	}
+	if f.recordErr != nil {
+		return job, "", f.recordErr
+	}
+	job.cursor, job.done = item, job.done+1
+	return job, "", nil
+}
+
+func (f *fakeJobs) Finish(_ context.Context, job fakeJob, failure *string) (fakeJob, error) {
+	f.finished = append(f.finished, failure)
+	return job, nil
+}
+
+func (*fakeJobs) ID(job fakeJob) string { return job.id }
+
+func (*fakeJobs) Summary(job fakeJob) []any { return []any{"done", job.done} }
+
+func newRunner(jobs *fakeJobs) *Runner[fakeJob, int] {
+	return &Runner[fakeJob, int]{Jobs: jobs, Kind: "test", Item: "item", Lease: time.Minute, Interval: time.Minute, Batch: 2}
+}
+
+func TestRunnerWorksAJobInBatchesAndFinishesIt(t *testing.T) {
+	jobs := &fakeJobs{queued: []fakeJob{{id: "job"}}, items: []int{1, 2, 3, 4, 5}}
+	newRunner(jobs).RunOnce(context.Background())
+	if len(jobs.finished) != 1 || jobs.finished[0] != nil {
+		t.Fatalf("finished = %v", jobs.finished)
+	}
+}
+
+func TestRunnerFailsAJobAtARefusedItemAndLeavesRecordErrorsForTheLease(t *testing.T) {
+	jobs := &fakeJobs{queued: []fakeJob{{id: "job"}}, items: []int{1, 2, 3}, failOn: 2}
+	newRunner(jobs).RunOnce(context.Background())
+	if len(jobs.finished) != 1 || jobs.finished[0] == nil || *jobs.finished[0] != "item refused" {
+		t.Fatalf("finished = %v", jobs.finished)
+	}
+
+	jobs = &fakeJobs{queued: []fakeJob{{id: "job"}}, items: []int{1}, recordErr: errors.New("db down")}
+	newRunner(jobs).RunOnce(context.Background())
+	if len(jobs.finished) != 0 {
+		t.Fatalf("job with an unrecorded item finished: %v", jobs.finished)
+	}
+}
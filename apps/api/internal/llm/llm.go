@@ -0,0 +1,140 @@
+// Package llm writes stories with a large language model. A Provider sends one
+// prompt to a chat API and returns the reply; StoryPrompt composes that prompt
+// from an account's prompt rules and child profile, and ParseStory turns the
+// reply into a draft's title and Markdown.
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	ProviderOpenAI    = "openai"
+	ProviderAnthropic = "anthropic"
+
+	// A whole story can take a model a minute or more; this bounds one that
+	// stops answering, well inside the server's write timeout.
+	requestTimeout = 3 * time.Minute
+	// maxResponseBytes bounds a reply far beyond any story a child reads.
+	maxResponseBytes = 1 << 20
+	// maxTokens is the reply budget asked of the model.
+	maxTokens = 4096
+)
+
+// ErrProvider marks a provider that answered with an error status or a reply
+// that could not be used. Its message never carries the provider's response.
+var ErrProvider = errors.New("llm provider failed")
+
+type Provider interface {
+	// Name identifies the provider in logs and the startup summary.
+	Name() string
+	// Complete sends prompt and returns the model's reply.
+	Complete(ctx context.Context, prompt Prompt) (Completion, error)
+}
+
+// Prompt is one request: standing instructions and the task itself.
+type Prompt struct {
+	System string `json:"system"`
+	User   string `json:"user"`
+}
+
+// Completion is a model's reply with the usage it reported.
+type Completion struct {
+	Text      string
+	Model     string
+	TokensIn  int
+	TokensOut int
+}
+
+// Load builds the provider PP_LLM_PROVIDER names. It returns nil, and no
+// error, when none is configured. PP_LLM_MODEL is always required: model
+// names change too often for a default to stay useful.
+func Load(getenv func(string) string) (Provider, error) {
+	name := strings.ToLower(strings.TrimSpace(getenv("PP_LLM_PROVIDER")))
+	endpoint := strings.TrimSpace(getenv("PP_LLM_URL"))
+	apiKey := strings.TrimSpace(getenv("PP_LLM_API_KEY"))
+	modelName := strings.TrimSpace(getenv("PP_LLM_MODEL"))
+	if endpoint != "" {
+		if parsed, err := url.Parse(endpoint); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return nil, fmt.Errorf("PP_LLM_URL must be an http or https URL")
+		}
+	}
+	if name != "" && modelName == "" {
+		return nil, fmt.Errorf("PP_LLM_MODEL is required when PP_LLM_PROVIDER is set")
+	}
+
+	switch name {
+	case "":
+		return nil, nil
+	case ProviderOpenAI:
+		// A local server with OpenAI's API, such as Ollama, needs no key.
+		if apiKey == "" && endpoint == "" {
+			return nil, fmt.Errorf("PP_LLM_API_KEY is required for the openai provider")
+		}
+		if endpoint == "" {
+			endpoint = defaultOpenAIURL
+		}
+		return &OpenAI{URL: endpoint, APIKey: apiKey, Model: modelName, client: newHTTPClient()}, nil
+	case ProviderAnthropic:
+		if apiKey == "" {
+			return nil, fmt.Errorf("PP_LLM_API_KEY is required for the anthropic provider")
+		}
+		if endpoint == "" {
+			endpoint = defaultAnthropicURL
+		}
+		return &Anthropic{URL: endpoint, APIKey: apiKey, Model: modelName, client: newHTTPClient()}, nil
+	default:
+		return nil, fmt.Errorf("PP_LLM_PROVIDER must be openai or anthropic")
+	}
+}
+
+func newHTTPClient() *http.Client {
+	return &http.Client{Timeout: requestTimeout}
+}
+
+// post sends body as JSON and decodes the provider's JSON answer into out.
+func post(ctx context.Context, client *http.Client, endpoint string, body any, header http.Header, out any) error {
+	raw, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(raw))
+	if err != nil {
+		return err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "pandapages-llm/1")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%w: status %d", ErrProvider, resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes+1))
+	if err != nil {
+		return err
+	}
+	if len(data) > maxResponseBytes {
+		return fmt.Errorf("%w: reply exceeds %d bytes", ErrProvider, maxResponseBytes)
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("%w: reply is not JSON", ErrProvider)
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"pandapages/api/internal/model"
+)
+
+func TestStoryPromptCarriesTheProfilesAndTheme(t *testing.T) {
+	child := model.ChildProfile{AgeMonths: 58, Interests: []string{"trains", " ", "owls"}, Sensitivities: []string{"spiders"}}
+	profile := model.PromptProfile{Rules: json.RawMessage(`{"tone":"calm, warm, UK English"}`)}
+	prompt := StoryPrompt(child, profile, " a sleepy train ")
+
+	for _, want := range []string{`"tone": "calm, warm, UK English"`, `("# Title")`} {
+		if !strings.Contains(prompt.System, want) {
+			t.Errorf("system prompt omits %q:\n%s", want, prompt.System)
+		}
+	}
+	want := "Write a story about: a sleepy train\nThe reader is 4 years old.\nThey love: trains, owls.\nLeave out entirely, even in passing: spiders."
+	if prompt.User != want {
+		t.Fatalf("user prompt = %q, want %q", prompt.User, want)
+	}
+
+	bare := StoryPrompt(model.ChildProfile{AgeMonths: 18}, model.PromptProfile{Rules: json.RawMessage(`{}`)}, "owls")
+	if strings.Contains(bare.System, "house rules") || bare.User != "Write a story about: owls\nThe reader is 18 months old." {
+		t.Fatalf("bare prompt = %#v", bare)
+	}
+}
+
+func TestTranslationPromptCarriesTheStoryAndLanguages(t *testing.T) {
+	prompt := TranslationPrompt(" The Night Train ", "The train yawned.\n\n![Owl](/media/abc)\n", "en", "pt-BR")
+	want := "Translate this story from \"en\" into \"pt-BR\" (BCP 47 language tags).\n\n# The Night Train\n\nThe train yawned.\n\n![Owl](/media/abc)"
+	if prompt.User != want || !strings.Contains(prompt.System, `("# Title")`) {
+		t.Fatalf("prompt = %#v", prompt)
+	}
+	if unknown := TranslationPrompt("Owls", "Hoot.", "", "fr"); !strings.HasPrefix(unknown.User, "Translate this story into \"fr\" (a BCP 47 language tag).") {
+		t.Fatalf("prompt without a source language = %q", unknown.User)
+	}
+}
+
+func TestSimplificationPromptTargetsTheAge(t *testing.T) {
+	prompt := SimplificationPrompt(" The Night Train ", "The locomotive exhaled.\n", "en", 5)
+	want := "Rewrite this story for a 5-year-old reader. " + readingGuide(5) + "\nKeep it in \"en\" (a BCP 47 language tag).\n\n# The Night Train\n\nThe locomotive exhaled."
+	if prompt.User != want || !strings.Contains(prompt.System, `("# Title")`) {
+		t.Fatalf("prompt = %#v", prompt)
+	}
+	if readingGuide(4) == readingGuide(10) {
+		t.Fatal("reading guide does not change with age")
+	}
+}
+
+func TestParseStory(t *testing.T) {
+	title, markdown, err := ParseStory("```markdown\r\n# The Night Train\r\n\r\nThe train yawned.\r\n\r\n## Home\r\nAll asleep.\r\n```")
+	if err != nil || title != "The Night Train" || markdown != "The train yawned.\n\n## Home\nAll asleep.\n" {
+		t.Fatalf("ParseStory = %q %q, %v", title, markdown, err)
+	}
+	for _, reply := range []string{"", "Once upon a time.", "# Title only", "## Not a title\n\nText."} {
+		if _, _, err := ParseStory(reply); !errors.Is(err, ErrStoryFormat) {
+			t.Errorf("ParseStory(%q) error = %v", reply, err)
+		}
+	}
+}
+
+func TestQuizPromptAndParseQuiz(t *testing.T) {
+	prompt := QuizPrompt("The Night Train", " Home ", "All asleep.\n", "fr")
+	want := "Write questions on the chapter \"Home\" of the story \"The Night Train\".\nWrite them in \"fr\" (a BCP 47 language tag).\n\nAll asleep."
+	if prompt.User != want || !strings.Contains(prompt.System, "3 to 5 objects") {
+		t.Fatalf("prompt = %#v", prompt)
+	}
+
+	questions, err := ParseQuiz("```json\n" + `[{"question":" Who yawned? ","answer":"The train"},{"question":"Where?","answer":"Home"},{"question":"When?","answer":"At night"}]` + "\n```")
+	if err != nil || len(questions) != 3 || questions[0].Question != "Who yawned?" || questions[2].Answer != "At night" {
+		t.Fatalf("ParseQuiz = %#v, %v", questions, err)
+	}
+	for _, reply := range []string{
+		"",
+		"1. Who yawned?",
+		`[{"question":"Who yawned?","answer":"The train"}]`,
+		`[{"question":"Who?","answer":"A"},{"question":"Where?","answer":""},{"question":"When?","answer":"C"}]`,
+	} {
+		if _, err := ParseQuiz(reply); !errors.Is(err, ErrQuizFormat) {
+			t.Errorf("ParseQuiz(%q) error = %v", reply, err)
+		}
+	}
+}
+
+func TestLoadBuildsTheConfiguredProvider(t *testing.T) {
+	env := func(values map[string]string) func(string) string {
+		return func(key string) string { return values[key] }
+	}
+	if provider, err := Load(env(nil)); err != nil || provider != nil {
+		t.Fatalf("unconfigured Load = %v, %v", provider, err)
+	}
+
+	provider, err := Load(env(map[string]string{"PP_LLM_PROVIDER": "OpenAI", "PP_LLM_MODEL": "local", "PP_LLM_URL": "http://ollama:11434/v1/chat/completions"}))
+	if err != nil {
+		t.Fatalf("keyless openai Load error = %v", err)
+	}
+	if openai, ok := provider.(*OpenAI); !ok || openai.URL != "http://ollama:11434/v1/chat/completions" || openai.Model != "local" {
+		t.Fatalf("openai provider = %#v", provider)
+	}
+
+	for name, values := range map[string]map[string]string{
+		"unknown":       {"PP_LLM_PROVIDER": "eliza", "PP_LLM_MODEL": "m"},
+		"model":         {"PP_LLM_PROVIDER": "openai", "PP_LLM_API_KEY": "key"},
+		"openai key":    {"PP_LLM_PROVIDER": "openai", "PP_LLM_MODEL": "m"},
+		"anthropic key": {"PP_LLM_PROVIDER": "anthropic", "PP_LLM_MODEL": "m", "PP_LLM_URL": "https://proxy.example"},
+		"malformed url": {"PP_LLM_PROVIDER": "openai", "PP_LLM_MODEL": "m", "PP_LLM_URL": "ftp://model"},
+	} {
+		if _, err := Load(env(values)); err == nil {
+			t.Errorf("%s: Load succeeded", name)
+		}
+	}
+}
+
+func TestProvidersSendThePromptAndReadTheReply(t *testing.T) {
+	var got *http.Request
+	var body map[string]any
+	status, answer := http.StatusOK, ""
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw, _ := io.ReadAll(r.Body)
+		got, body = r, nil
+		_ = json.Unmarshal(raw, &body)
+		w.WriteHeader(status)
+		_, _ = io.WriteString(w, answer)
+	}))
+	t.Cleanup(server.Close)
+	prompt := Prompt{System: "Be gentle.", User: "Write about owls."}
+
+	answer = `{"model":"gpt-x-2026","choices":[{"message":{"role":"assistant","content":"# Owls"},"finish_reason":"stop"}],"usage":{"prompt_tokens":12,"completion_tokens":34}}`
+	openai := &OpenAI{URL: server.URL, APIKey: "secret", Model: "gpt-x", client: server.Client()}
+	completion, err := openai.Complete(context.Background(), prompt)
+	if err != nil || completion != (Completion{Text: "# Owls", Model: "gpt-x-2026", TokensIn: 12, TokensOut: 34}) {
+		t.Fatalf("openai completion = %#v, error %v", completion, err)
+	}
+	messages, _ := body["messages"].([]any)
+	if got.Header.Get("Authorization") != "Bearer secret" || body["model"] != "gpt-x" || len(messages) != 2 {
+		t.Fatalf("openai request = %v, headers %v", body, got.Header)
+	}
+
+	answer = `{"model":"m","content":[{"type":"text","text":"# Owls"},{"type":"text","text":"\n\nHoot."}],"stop_reason":"end_turn","usage":{"input_tokens":5,"output_tokens":6}}`
+	anthropic := &Anthropic{URL: server.URL, APIKey: "secret", Model: "m", client: server.Client()}
+	completion, err = anthropic.Complete(context.Background(), prompt)
+	if err != nil || completion.Text != "# Owls\n\nHoot." || completion.TokensOut != 6 {
+		t.Fatalf("anthropic completion = %#v, error %v", completion, err)
+	}
+	if got.Header.Get("X-Api-Key") != "secret" || got.Header.Get("Anthropic-Version") != anthropicVersion || body["system"] != "Be gentle." {
+		t.Fatalf("anthropic request = %v, headers %v", body, got.Header)
+	}
+
+	for name, reply := range map[string]struct {
+		status int
+		answer string
+	}{
+		"refused":  {status: http.StatusTooManyRequests, answer: `{"error":"slow down"}`},
+		"not json": {status: http.StatusOK, answer: "<html>"},
+		"cut off":  {status: http.StatusOK, answer: `{"choices":[{"message":{"content":"# Ow"},"finish_reason":"length"}]}`},
+		"empty":    {status: http.StatusOK, answer: `{"choices":[]}`},
+	} {
+		status, answer = reply.status, reply.answer
+		if _, err := openai.Complete(context.Background(), prompt); !errors.Is(err, ErrProvider) {
+			t.Errorf("%s: error = %v, want ErrProvider", name, err)
+		}
+	}
+}
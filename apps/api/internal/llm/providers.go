@@ -0,0 +1,134 @@
+package llm
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	defaultOpenAIURL    = "https://api.openai.com/v1/chat/completions"
+	defaultAnthropicURL = "https://api.anthropic.com/v1/messages"
+	anthropicVersion    = "2023-06-01"
+)
+
+// OpenAI is OpenAI's chat completions endpoint, or any service that copies
+// its API.
+type OpenAI struct {
+	URL    string
+	APIKey string
+	Model  string
+	client *http.Client
+}
+
+func (p *OpenAI) Name() string { return ProviderOpenAI }
+
+func (p *OpenAI) Complete(ctx context.Context, prompt Prompt) (Completion, error) {
+	type message struct {
+		Role    string `json:"role"`
+		Content string `json:"content"`
+	}
+	body := struct {
+		Model     string    `json:"model"`
+		Messages  []message `json:"messages"`
+		MaxTokens int       `json:"max_tokens"`
+	}{
+		Model:     p.Model,
+		Messages:  []message{{Role: "system", Content: prompt.System}, {Role: "user", Content: prompt.User}},
+		MaxTokens: maxTokens,
+	}
+	var header http.Header
+	if p.APIKey != "" {
+		header = http.Header{"Authorization": {"Bearer " + p.APIKey}}
+	}
+	var reply struct {
+		Model   string `json:"model"`
+		Choices []struct {
+			Message      message `json:"message"`
+			FinishReason string  `json:"finish_reason"`
+		} `json:"choices"`
+		Usage struct {
+			PromptTokens     int `json:"prompt_tokens"`
+			CompletionTokens int `json:"completion_tokens"`
+		} `json:"usage"`
+	}
+	if err := post(ctx, p.client, p.URL, body, header, &reply); err != nil {
+		return Completion{}, err
+	}
+	if len(reply.Choices) == 0 {
+		return Completion{}, fmt.Errorf("%w: reply has no choices", ErrProvider)
+	}
+	if reply.Choices[0].FinishReason == "length" {
+		return Completion{}, fmt.Errorf("%w: reply was cut off", ErrProvider)
+	}
+	return Completion{
+		Text:      reply.Choices[0].Message.Content,
+		Model:     cmp.Or(reply.Model, p.Model),
+		TokensIn:  reply.Usage.PromptTokens,
+		TokensOut: reply.Usage.CompletionTokens,
+	}, nil
+}
+
+// Anthropic is Anthropic's messages endpoint.
+type Anthropic struct {
+	URL    string
+	APIKey string
+	Model  string
+	client *http.Client
+}
+
+func (p *Anthropic) Name() string { return ProviderAnthropic }
+
+func (p *Anthropic) Complete(ctx context.Context, prompt Prompt) (Completion, error) {
+	type message struct {
+		Role    string `json:"role"`
+		Content string `json:"content"`
+	}
+	body := struct {
+		Model     string    `json:"model"`
+		System    string    `json:"system"`
+		Messages  []message `json:"messages"`
+		MaxTokens int       `json:"max_tokens"`
+	}{
+		Model:     p.Model,
+		System:    prompt.System,
+		Messages:  []message{{Role: "user", Content: prompt.User}},
+		MaxTokens: maxTokens,
+	}
+	var reply struct {
+		Model   string `json:"model"`
+		Content []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"content"`
+		StopReason string `json:"stop_reason"`
+		Usage      struct {
+			InputTokens  int `json:"input_tokens"`
+			OutputTokens int `json:"output_tokens"`
+		} `json:"usage"`
+	}
+	err := post(ctx, p.client, p.URL, body, http.Header{
+		"X-Api-Key":         {p.APIKey},
+		"Anthropic-Version": {anthropicVersion},
+	}, &reply)
+	if err != nil {
+		return Completion{}, err
+	}
+	if reply.StopReason == "max_tokens" {
+		return Completion{}, fmt.Errorf("%w: reply was cut off", ErrProvider)
+	}
+	var text strings.Builder
+	for _, block := range reply.Content {
+		if block.Type == "text" {
+			text.WriteString(block.Text)
+		}
+	}
+	return Completion{
+		Text:      text.String(),
+		Model:     cmp.Or(reply.Model, p.Model),
+		TokensIn:  reply.Usage.InputTokens,
+		TokensOut: reply.Usage.OutputTokens,
+	}, nil
+}
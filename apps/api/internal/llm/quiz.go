@@ -0,0 +1,73 @@
+package llm
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"pandapages/api/internal/model"
+)
+
+// QuizPromptVersion names the template QuizPrompt builds.
+const QuizPromptVersion = "quiz-v1"
+
+// The reply is JSON rather than Markdown: each answer must stay with its
+// question, and ParseQuiz depends on it.
+var quizFormat = fmt.Sprintf(`Reply with the questions only, as a JSON array of %d to %d objects:
+[{"question": "...", "answer": "..."}]
+- Each question can be answered from the chapter alone and has one short answer a child could say aloud.
+- Ask about what happened, who did it and why, not about words or spelling.
+- No trick questions, and nothing the chapter leaves unclear.
+- No notes, Markdown or code fences.`, model.MinQuizQuestions, model.MaxQuizQuestions)
+
+// QuizPrompt asks for comprehension questions on one chapter of a story,
+// given as the story's title, the chapter's title, which may be empty, and
+// the chapter's Markdown. language is the story's language, which the
+// questions are written in, and may be empty when it is not known.
+func QuizPrompt(storyTitle, chapterTitle, markdown, language string) Prompt {
+	system := "You write gentle comprehension questions that a grown-up asks a young child after reading a chapter together, " +
+		"to see whether they caught what happened.\n\n" + quizFormat
+
+	var user strings.Builder
+	if chapterTitle = strings.TrimSpace(chapterTitle); chapterTitle != "" {
+		fmt.Fprintf(&user, "Write questions on the chapter %q of the story %q.\n", chapterTitle, strings.TrimSpace(storyTitle))
+	} else {
+		fmt.Fprintf(&user, "Write questions on the story %q.\n", strings.TrimSpace(storyTitle))
+	}
+	if language = strings.TrimSpace(language); language != "" {
+		fmt.Fprintf(&user, "Write them in %q (a BCP 47 language tag).\n", language)
+	}
+	fmt.Fprintf(&user, "\n%s", strings.TrimSpace(markdown))
+	return Prompt{System: system, User: user.String()}
+}
+
+// ErrQuizFormat marks a reply that does not follow quizFormat.
+var ErrQuizFormat = errors.New("reply is not a list of quiz questions")
+
+// ParseQuiz reads the questions from a reply. A reply wrapped in a code
+// fence is unwrapped first, as ParseStory does.
+func ParseQuiz(reply string) ([]model.QuizQuestion, error) {
+	text := strings.TrimSpace(strings.ReplaceAll(reply, "\r\n", "\n"))
+	if strings.HasPrefix(text, "```") && strings.HasSuffix(text, "```") {
+		if _, inner, ok := strings.Cut(text, "\n"); ok {
+			text = strings.TrimSpace(strings.TrimSuffix(inner, "```"))
+		}
+	}
+	var questions []model.QuizQuestion
+	if err := json.Unmarshal([]byte(text), &questions); err != nil {
+		return nil, ErrQuizFormat
+	}
+	if len(questions) < model.MinQuizQuestions || len(questions) > model.MaxQuizQuestions {
+		return nil, ErrQuizFormat
+	}
+	for index, question := range questions {
+		question.Question, question.Answer = strings.TrimSpace(question.Question), strings.TrimSpace(question.Answer)
+		if question.Question == "" || question.Answer == "" ||
+			len([]rune(question.Question)) > model.MaxQuizQuestionLen || len([]rune(question.Answer)) > model.MaxQuizAnswerLen {
+			return nil, ErrQuizFormat
+		}
+		questions[index] = question
+	}
+	return questions, nil
+}
@@ -0,0 +1,48 @@
+package llm
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SimplificationPromptVersion names the template SimplificationPrompt builds.
+const SimplificationPromptVersion = "simplify-v1"
+
+// The reply keeps storyFormat's title line so ParseStory reads it.
+const simplificationFormat = `Reply with the rewritten story only, in Markdown:
+- The first line is the title as a level-one heading ("# Title"); keep the original title unless it is too hard to read.
+- Then the rewritten story, keeping its chapters as headings and its images where they fall.
+- Copy image and link targets unchanged.
+- No notes, frontmatter or code fences.`
+
+// readingGuide describes what a reader of age can manage, in the terms a
+// model can write to.
+func readingGuide(age int) string {
+	switch {
+	case age <= 4:
+		return "Use sentences of at most eight words, the most common everyday words, and repetition a child can join in with."
+	case age <= 6:
+		return "Use short sentences of about ten words, common words a beginning reader can sound out, and explain any harder word in the text."
+	case age <= 8:
+		return "Use sentences of about twelve words, familiar vocabulary, and no more than one new idea per sentence."
+	default:
+		return "Use clear sentences of moderate length and replace rare or old-fashioned words with everyday ones."
+	}
+}
+
+// SimplificationPrompt asks for a story, given as its title and Markdown
+// body, rewritten so a child of age can read it alone. language is the
+// story's language, which the rewrite keeps, and may be empty when it is
+// not known.
+func SimplificationPrompt(title, markdown, language string, age int) Prompt {
+	system := "You rewrite stories so young children can read them on their own. Keep every character, event and the ending; " +
+		"shorten sentences and choose simpler words, but do not add morals, new events or commentary.\n\n" + simplificationFormat
+
+	var user strings.Builder
+	fmt.Fprintf(&user, "Rewrite this story for a %d-year-old reader. %s\n", age, readingGuide(age))
+	if language = strings.TrimSpace(language); language != "" {
+		fmt.Fprintf(&user, "Keep it in %q (a BCP 47 language tag).\n", language)
+	}
+	fmt.Fprintf(&user, "\n# %s\n\n%s", strings.TrimSpace(title), strings.TrimSpace(markdown))
+	return Prompt{System: system, User: user.String()}
+}
@@ -0,0 +1,106 @@
+package llm
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"pandapages/api/internal/model"
+)
+
+// PromptVersion names the template StoryPrompt builds; generation_jobs
+// records it so stories written by an older template can be told apart.
+const PromptVersion = "story-v1"
+
+// The reply format is fixed here rather than left to the prompt profile:
+// ParseStory depends on it.
+const storyFormat = `Reply with the story only, in Markdown:
+- The first line is the title as a level-one heading ("# Title").
+- Then the story in short paragraphs. A longer story may open chapters with level-two headings ("## Chapter title").
+- No frontmatter, HTML, images, links, tables or code.`
+
+// StoryPrompt composes the prompt for one story: the prompt profile's rules
+// as standing instructions, and the child's age, interests and sensitivities
+// with the theme as the task. Either profile may be empty.
+func StoryPrompt(child model.ChildProfile, profile model.PromptProfile, theme string) Prompt {
+	var system strings.Builder
+	system.WriteString("You write original stories for young children to read or have read to them.\n\n")
+	if rules := promptRules(profile.Rules); rules != "" {
+		system.WriteString("Follow these house rules, given as JSON:\n")
+		system.WriteString(rules)
+		system.WriteString("\n\n")
+	}
+	system.WriteString(storyFormat)
+
+	var user strings.Builder
+	fmt.Fprintf(&user, "Write a story about: %s\n", strings.TrimSpace(theme))
+	if child.AgeMonths > 0 {
+		fmt.Fprintf(&user, "The reader is %s old.\n", childAge(child.AgeMonths))
+	}
+	if interests := nonEmpty(child.Interests); len(interests) > 0 {
+		fmt.Fprintf(&user, "They love: %s.\n", strings.Join(interests, ", "))
+	}
+	if sensitivities := nonEmpty(child.Sensitivities); len(sensitivities) > 0 {
+		fmt.Fprintf(&user, "Leave out entirely, even in passing: %s.\n", strings.Join(sensitivities, ", "))
+	}
+	return Prompt{System: system.String(), User: strings.TrimRight(user.String(), "\n")}
+}
+
+// promptRules indents a profile's rules, or returns "" when there are none.
+func promptRules(raw json.RawMessage) string {
+	var rules map[string]any
+	if json.Unmarshal(raw, &rules) != nil || len(rules) == 0 {
+		return ""
+	}
+	out, err := json.MarshalIndent(rules, "", "  ")
+	if err != nil {
+		return ""
+	}
+	return string(out)
+}
+
+// childAge is months until two, then whole years.
+func childAge(months int) string {
+	switch {
+	case months == 1:
+		return "1 month"
+	case months < 24:
+		return fmt.Sprintf("%d months", months)
+	default:
+		return fmt.Sprintf("%d years", months/12)
+	}
+}
+
+func nonEmpty(values []string) []string {
+	out := make([]string, 0, len(values))
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			out = append(out, value)
+		}
+	}
+	return out
+}
+
+// ErrStoryFormat marks a reply that does not follow storyFormat.
+var ErrStoryFormat = errors.New("reply is not a titled Markdown story")
+
+// ParseStory splits a reply into its title and the Markdown that follows the
+// title heading. A reply wrapped in a code fence, as models sometimes do, is
+// unwrapped first.
+func ParseStory(reply string) (title, markdown string, err error) {
+	text := strings.TrimSpace(strings.ReplaceAll(reply, "\r\n", "\n"))
+	if strings.HasPrefix(text, "```") && strings.HasSuffix(text, "```") {
+		if _, inner, ok := strings.Cut(text, "\n"); ok {
+			text = strings.TrimSpace(strings.TrimSuffix(inner, "```"))
+		}
+	}
+	first, rest, _ := strings.Cut(text, "\n")
+	heading, ok := strings.CutPrefix(strings.TrimSpace(first), "# ")
+	title = strings.TrimSpace(heading)
+	markdown = strings.TrimSpace(rest)
+	if !ok || title == "" || markdown == "" {
+		return "", "", ErrStoryFormat
+	}
+	return title, markdown + "\n", nil
+}
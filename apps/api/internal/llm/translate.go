@@ -0,0 +1,34 @@
+package llm
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TranslationPromptVersion names the template TranslationPrompt builds.
+const TranslationPromptVersion = "translate-v1"
+
+// The reply keeps storyFormat's title line so ParseStory reads it; the body
+// keeps whatever Markdown the original used, images included.
+const translationFormat = `Reply with the translation only, in Markdown:
+- The first line is the translated title as a level-one heading ("# Title").
+- Then the translated story, keeping every heading, paragraph break, list and emphasis where the original has it.
+- Copy image and link targets unchanged; translate only their text.
+- No notes, frontmatter or code fences.`
+
+// TranslationPrompt asks for a story, given as its title and Markdown body,
+// in the language tagged to. from is the story's own language and may be
+// empty when it is not known.
+func TranslationPrompt(title, markdown, from, to string) Prompt {
+	system := "You translate stories for young children. Keep the meaning, tone and reading level of the original; " +
+		"rhymes and wordplay may be recreated in the new language rather than translated word for word.\n\n" + translationFormat
+
+	var user strings.Builder
+	if from = strings.TrimSpace(from); from != "" {
+		fmt.Fprintf(&user, "Translate this story from %q into %q (BCP 47 language tags).\n\n", from, strings.TrimSpace(to))
+	} else {
+		fmt.Fprintf(&user, "Translate this story into %q (a BCP 47 language tag).\n\n", strings.TrimSpace(to))
+	}
+	fmt.Fprintf(&user, "# %s\n\n%s", strings.TrimSpace(title), strings.TrimSpace(markdown))
+	return Prompt{System: system, User: user.String()}
+}
@@ -0,0 +1,57 @@
+// Package maintenance holds the switch that takes the reader API offline
+// while an operator works on the deployment, such as during a risky
+// migration. Admin routes ignore it, so the operator can still work and turn
+// it off again.
+//
+// The switch lives in the process. PP_MAINTENANCE sets it at startup for
+// every instance; the admin endpoint flips only the instance it reaches.
+package maintenance
+
+import (
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"pandapages/api/internal/model"
+)
+
+// DefaultMessage is what readers see when no message was given.
+const DefaultMessage = "Panda Pages is being updated. Please try again in a few minutes."
+
+type Switch struct {
+	state atomic.Pointer[model.MaintenanceState]
+	now   func() time.Time
+}
+
+// New returns a switch that starts on or off.
+func New(enabled bool) *Switch {
+	s := &Switch{now: time.Now}
+	s.Set(enabled, "")
+	return s
+}
+
+// State reports whether maintenance is on. A nil switch is always off.
+func (s *Switch) State() model.MaintenanceState {
+	if s == nil {
+		return model.MaintenanceState{Message: DefaultMessage}
+	}
+	return *s.state.Load()
+}
+
+// Set turns maintenance on or off. A blank message uses DefaultMessage.
+// Since records when maintenance was turned on and is kept while it stays on.
+func (s *Switch) Set(enabled bool, message string) model.MaintenanceState {
+	next := model.MaintenanceState{Enabled: enabled, Message: strings.TrimSpace(message)}
+	if next.Message == "" {
+		next.Message = DefaultMessage
+	}
+	if enabled {
+		since := s.now().UTC().Format(time.RFC3339)
+		if previous := s.state.Load(); previous != nil && previous.Enabled && previous.Since != nil {
+			since = *previous.Since
+		}
+		next.Since = &since
+	}
+	s.state.Store(&next)
+	return next
+}
@@ -0,0 +1,32 @@
+package maintenance
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSwitchKeepsWhenMaintenanceStarted(t *testing.T) {
+	now := time.Date(2026, 10, 16, 7, 0, 0, 0, time.UTC)
+	s := New(false)
+	s.now = func() time.Time { return now }
+
+	if state := s.State(); state.Enabled || state.Since != nil || state.Message != DefaultMessage {
+		t.Fatalf("initial state = %#v", state)
+	}
+	on := s.Set(true, "  Back after the migration.  ")
+	if !on.Enabled || on.Message != "Back after the migration." || on.Since == nil || *on.Since != "2026-10-16T07:00:00Z" {
+		t.Fatalf("on = %#v", on)
+	}
+	now = now.Add(time.Hour)
+	if again := s.Set(true, ""); again.Since == nil || *again.Since != "2026-10-16T07:00:00Z" || again.Message != DefaultMessage {
+		t.Fatalf("second set = %#v, want the original start kept", again)
+	}
+	if off := s.Set(false, ""); off.Enabled || off.Since != nil {
+		t.Fatalf("off = %#v", off)
+	}
+
+	var unset *Switch
+	if unset.State().Enabled {
+		t.Fatal("a nil switch reported maintenance")
+	}
+}
@@ -10,6 +10,24 @@ type AdminStoryInput struct {
 	Language  *string        `json:"language"`
 	SourceURL *string        `json:"sourceUrl"`
 	Rights    map[string]any `json:"rights"`
+
+	// MarkdownExtensions opts into extended syntax such as "gfm"; when
+	// omitted, frontmatter markdownExtensions applies.
+	MarkdownExtensions []string `json:"markdownExtensions,omitempty"`
+
+	// ChapterLevel and ChapterPattern choose which headings open chapters;
+	// when omitted, frontmatter chapterLevel and chapterPattern apply, and
+	// otherwise every H2 does.
+	ChapterLevel   int    `json:"chapterLevel,omitempty"`
+	ChapterPattern string `json:"chapterPattern,omitempty"`
+
+	// NumberChapters numbers unnumbered chapter headings, as in "Chapter 3 —
+	// The Dark Forest"; when omitted, frontmatter numberChapters applies.
+	NumberChapters bool `json:"numberChapters,omitempty"`
+
+	// Moderation is the verdict on generated text, stored on the version the
+	// draft writes or reuses. Only the server sets it.
+	Moderation *StoryModeration `json:"-"`
 }
 
 // Preview and draft creation deliberately share one input contract and one
@@ -17,10 +35,13 @@ type AdminStoryInput struct {
 type AdminPreviewRequest = AdminStoryInput
 type AdminDraftUpsertRequest = AdminStoryInput
 
+// AdminValidationIssue.Line is 1-based within the submitted Markdown,
+// frontmatter included, and is omitted for issues that belong to a field.
 type AdminValidationIssue struct {
 	Field   string `json:"field"`
 	Code    string `json:"code"`
 	Message string `json:"message"`
+	Line    int    `json:"line,omitempty"`
 }
 
 type AdminValidationError struct {
@@ -31,6 +52,14 @@ func (e *AdminValidationError) Error() string {
 	return fmt.Sprintf("admin story input has %d validation issue(s)", len(e.Issues))
 }
 
+// AdminValidateResponse reports every blocking issue a draft would hit, plus
+// warnings for content that would be accepted but not rendered.
+type AdminValidateResponse struct {
+	Valid    bool                   `json:"valid"`
+	Issues   []AdminValidationIssue `json:"issues"`
+	Warnings []AdminValidationIssue `json:"warnings"`
+}
+
 type AdminPreviewResponse struct {
 	Slug         string                 `json:"slug"`
 	Title        string                 `json:"title"`
@@ -42,9 +71,28 @@ type AdminPreviewResponse struct {
 	SegmentCount int                    `json:"segmentCount"`
 	WordCount    int                    `json:"wordCount"`
 	ChapterCount int                    `json:"chapterCount"`
+	Readability  Readability            `json:"readability"`
 	Warnings     []AdminValidationIssue `json:"warnings"`
 }
 
+// AdminLintLocator uses the Reader segment identity so warnings point at the
+// same segment the admin previews.
+type AdminLintLocator struct {
+	Ordinal    int    `json:"ordinal"`
+	ContentKey string `json:"contentKey"`
+}
+
+// AdminLintWarning is advisory; lint findings never block a draft.
+type AdminLintWarning struct {
+	Code    string            `json:"code"`
+	Message string            `json:"message"`
+	Segment *AdminLintLocator `json:"segment"`
+}
+
+type AdminLintResponse struct {
+	Warnings []AdminLintWarning `json:"warnings"`
+}
+
 type AdminDraftOutcome string
 
 const (
@@ -54,14 +102,21 @@ const (
 )
 
 type AdminDraftUpsertResponse struct {
-	Slug         string            `json:"slug"`
-	VersionID    string            `json:"versionId"`
-	Version      int               `json:"version"`
-	SegmentCount int               `json:"segmentCount"`
-	WordCount    int               `json:"wordCount"`
-	ChapterCount int               `json:"chapterCount"`
-	RenderedHTML string            `json:"renderedHtml"`
-	Outcome      AdminDraftOutcome `json:"outcome"`
+	Slug         string             `json:"slug"`
+	VersionID    string             `json:"versionId"`
+	Version      int                `json:"version"`
+	SegmentCount int                `json:"segmentCount"`
+	WordCount    int                `json:"wordCount"`
+	ChapterCount int                `json:"chapterCount"`
+	RenderedHTML string             `json:"renderedHtml"`
+	Outcome      AdminDraftOutcome  `json:"outcome"`
+	Warnings     []AdminLintWarning `json:"warnings"`
+
+	// UnchangedSegments counts segments whose content is unchanged from the
+	// previous draft. Every segment is still written for the new version;
+	// narrating it copies the unchanged ones' recordings rather than paying
+	// for them again.
+	UnchangedSegments int `json:"unchangedSegments"`
 
 	// These aliases keep existing Store-level tests and internal callers source
 	// compatible without exposing database story IDs or legacy field names.
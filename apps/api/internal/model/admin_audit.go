@@ -0,0 +1,73 @@
+package model
+
+import "time"
+
+type AdminAuditAction string
+
+const (
+	AdminAuditActionDraftUpsert AdminAuditAction = "story.draft_upsert"
+	AdminAuditActionPublish     AdminAuditAction = "story.publish"
+	AdminAuditActionUnpublish   AdminAuditAction = "story.unpublish"
+	AdminAuditActionImport      AdminAuditAction = "story.import"
+	AdminAuditActionMetadata    AdminAuditAction = "story.metadata_update"
+	AdminAuditActionPrune       AdminAuditAction = "story.prune_versions"
+	AdminAuditActionRetention   AdminAuditAction = "account.version_retention"
+	AdminAuditActionHyphenation AdminAuditAction = "account.hyphenation"
+	AdminAuditActionUserCreate  AdminAuditAction = "admin_user.create"
+	AdminAuditActionUserDisable AdminAuditAction = "admin_user.disable"
+	AdminAuditActionStoryTagAdd AdminAuditAction = "story.tags_add"
+	AdminAuditActionStoryTagRm  AdminAuditAction = "story.tags_remove"
+	AdminAuditActionContribAdd  AdminAuditAction = "story.contributor_add"
+	AdminAuditActionContribRm   AdminAuditAction = "story.contributor_remove"
+	AdminAuditActionTagCreate   AdminAuditAction = "tag.create"
+	AdminAuditActionTagRename   AdminAuditAction = "tag.rename"
+	AdminAuditActionTagMerge    AdminAuditAction = "tag.merge"
+	AdminAuditActionTagDelete   AdminAuditAction = "tag.delete"
+	AdminAuditActionHookCreate  AdminAuditAction = "webhook.create"
+	AdminAuditActionHookUpdate  AdminAuditAction = "webhook.update"
+	AdminAuditActionHookDelete  AdminAuditAction = "webhook.delete"
+	AdminAuditActionMediaUpload AdminAuditAction = "media.upload"
+	AdminAuditActionRender      AdminAuditAction = "account.rerender"
+	AdminAuditActionReindex     AdminAuditAction = "account.search_reindex"
+	AdminAuditActionNarrate     AdminAuditAction = "story.narrate"
+	AdminAuditActionAlign       AdminAuditAction = "story.align"
+	AdminAuditActionGenerate    AdminAuditAction = "story.generate"
+	AdminAuditActionTranslate   AdminAuditAction = "story.translate"
+	AdminAuditActionSimplify    AdminAuditAction = "story.simplify"
+	AdminAuditActionQuizWrite   AdminAuditAction = "story.quiz_write"
+	AdminAuditActionQuizDelete  AdminAuditAction = "story.quiz_delete"
+	AdminAuditActionDiscussion  AdminAuditAction = "story.discussion_write"
+	AdminAuditActionDiscussRm   AdminAuditAction = "story.discussion_delete"
+	AdminAuditActionRestore     AdminAuditAction = "account.restore"
+	AdminAuditActionMaintenance AdminAuditAction = "deployment.maintenance"
+	AdminAuditActionLogLevel    AdminAuditAction = "deployment.log_level"
+)
+
+// AdminAuditEntry is one admin mutation as recorded by the admin HTTP boundary.
+// Summary must stay small and must never carry story bodies or credentials.
+type AdminAuditEntry struct {
+	Actor   string
+	Action  AdminAuditAction
+	Slug    string
+	Summary map[string]any
+}
+
+type AdminAuditFilter struct {
+	Action AdminAuditAction
+	Slug   string
+	Actor  string
+	Since  *time.Time
+	Until  *time.Time
+	PageRequest
+}
+
+type AdminAuditRecord struct {
+	ID        string           `json:"id"`
+	Actor     string           `json:"actor"`
+	Action    AdminAuditAction `json:"action"`
+	Slug      *string          `json:"slug"`
+	Summary   map[string]any   `json:"summary"`
+	CreatedAt string           `json:"createdAt"`
+}
+
+type AdminAuditListResponse = Page[AdminAuditRecord]
@@ -0,0 +1,92 @@
+package model
+
+import "pandapages/api/internal/readercontract"
+
+const (
+	BackupFormat        = "pandapages.backup"
+	BackupFormatVersion = 1
+)
+
+// BackupManifest opens a backup archive and lists the images it holds.
+type BackupManifest struct {
+	Format        string  `json:"format"`
+	FormatVersion int     `json:"formatVersion"`
+	ExportedAt    string  `json:"exportedAt"`
+	Media         []Media `json:"media"`
+}
+
+// BackupSkippedStory names a story a backup left out: one that needs repair,
+// or one deleted while the backup ran.
+type BackupSkippedStory struct {
+	Slug   string `json:"slug"`
+	Reason string `json:"reason"`
+}
+
+// BackupSettings carries the account's reader settings and library policies.
+type BackupSettings struct {
+	Reader       SettingsPayload `json:"reader"`
+	Hyphenation  bool            `json:"hyphenation"`
+	KeepVersions *int            `json:"keepVersions"`
+}
+
+// BackupProgress is the reader's place in one story. Version is the number
+// of the version the locator points into.
+type BackupProgress struct {
+	Slug      string                 `json:"slug"`
+	Version   int                    `json:"version"`
+	Locator   readercontract.Locator `json:"locator"`
+	Percent   float64                `json:"percent"`
+	UpdatedAt string                 `json:"updatedAt"`
+}
+
+// BackupIndex is everything a backup holds apart from the story bundles and
+// media bytes, which are read one at a time while the archive streams.
+type BackupIndex struct {
+	Stories  []string
+	Media    []Media
+	Settings BackupSettings
+	Progress []BackupProgress
+}
+
+// RestoreConflict says what a restore does with a story whose slug the
+// account already uses.
+type RestoreConflict string
+
+const (
+	RestoreConflictSkip      RestoreConflict = "skip"
+	RestoreConflictOverwrite RestoreConflict = "overwrite"
+	RestoreConflictNewSlug   RestoreConflict = "new-slug"
+)
+
+func (c RestoreConflict) Valid() bool {
+	return c == RestoreConflictSkip || c == RestoreConflictOverwrite || c == RestoreConflictNewSlug
+}
+
+type RestoreOutcome string
+
+const (
+	RestoreOutcomeCreated     RestoreOutcome = "created"
+	RestoreOutcomeOverwritten RestoreOutcome = "overwritten"
+	RestoreOutcomeRenamed     RestoreOutcome = "renamed"
+	RestoreOutcomeSkipped     RestoreOutcome = "skipped"
+	RestoreOutcomeFailed      RestoreOutcome = "failed"
+)
+
+// AdminRestoreStory reports one story of a restore. RestoredAs is the slug
+// it was written under, which differs from Slug only when it was renamed.
+type AdminRestoreStory struct {
+	Slug       string                 `json:"slug"`
+	RestoredAs string                 `json:"restoredAs,omitempty"`
+	Outcome    RestoreOutcome         `json:"outcome"`
+	Issues     []AdminValidationIssue `json:"issues,omitempty"`
+}
+
+type AdminRestoreResponse struct {
+	Conflict         RestoreConflict     `json:"conflict"`
+	Stories          []AdminRestoreStory `json:"stories"`
+	MediaRestored    int                 `json:"mediaRestored"`
+	MediaExisting    int                 `json:"mediaExisting"`
+	MediaConflicts   int                 `json:"mediaConflicts"`
+	ProgressRestored int                 `json:"progressRestored"`
+	SettingsRestored bool                `json:"settingsRestored"`
+}
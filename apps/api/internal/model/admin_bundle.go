@@ -0,0 +1,71 @@
+package model
+
+// Story bundles move one story, with its full version history, between
+// pandapages instances. Sections and segments are included so consumers can
+// read a bundle without re-rendering, but import re-derives them from each
+// version's Markdown and rejects a bundle whose derived content disagrees.
+const (
+	StoryBundleFormat        = "pandapages.story-bundle"
+	StoryBundleFormatVersion = 1
+)
+
+type StoryBundle struct {
+	Format        string               `json:"format"`
+	FormatVersion int                  `json:"formatVersion"`
+	ExportedAt    string               `json:"exportedAt,omitempty"`
+	Story         StoryBundleStory     `json:"story"`
+	Versions      []StoryBundleVersion `json:"versions"`
+	Media         []StoryBundleMedia   `json:"media"`
+}
+
+type StoryBundleStory struct {
+	Slug         string             `json:"slug"`
+	Title        string             `json:"title"`
+	Author       *string            `json:"author"`
+	Language     string             `json:"language"`
+	Rights       map[string]any     `json:"rights"`
+	SourceURL    *string            `json:"sourceUrl"`
+	Contributors []StoryContributor `json:"contributors"`
+	Tags         []string           `json:"tags"`
+}
+
+type StoryBundleVersion struct {
+	Version      int                  `json:"version"`
+	CreatedAt    string               `json:"createdAt,omitempty"`
+	IsDraft      bool                 `json:"isDraft"`
+	IsPublished  bool                 `json:"isPublished"`
+	Frontmatter  map[string]any       `json:"frontmatter"`
+	Markdown     string               `json:"markdown"`
+	RenderedHTML string               `json:"renderedHtml"`
+	ContentHash  string               `json:"contentHash"`
+	Readability  *Readability         `json:"readability"`
+	Sections     []StoryBundleSection `json:"sections"`
+	Segments     []StoryBundleSegment `json:"segments"`
+}
+
+type StoryBundleSection struct {
+	Ordinal int     `json:"ordinal"`
+	Kind    string  `json:"kind"`
+	Title   *string `json:"title"`
+}
+
+type StoryBundleSegment struct {
+	Ordinal           int     `json:"ordinal"`
+	SectionOrdinal    *int    `json:"sectionOrdinal"`
+	Kind              string  `json:"kind"`
+	HeadingLevel      *int    `json:"headingLevel"`
+	ContentKey        string  `json:"contentKey"`
+	ContentOccurrence int     `json:"contentOccurrence"`
+	ChapterKey        *string `json:"chapterKey"`
+	ChapterOccurrence *int    `json:"chapterOccurrence"`
+	Markdown          string  `json:"markdown"`
+	RenderedHTML      string  `json:"renderedHtml"`
+	WordCount         int     `json:"wordCount"`
+}
+
+// StoryBundleMedia lists a referenced image and the version numbers whose
+// Markdown uses it. Bundles carry references only, never media bytes.
+type StoryBundleMedia struct {
+	URL      string `json:"url"`
+	Versions []int  `json:"versions"`
+}
@@ -0,0 +1,41 @@
+package model
+
+// GutenbergSearchQuery is a catalog search. Languages are two-letter codes;
+// an empty list searches every language. Page counts from 1.
+type GutenbergSearchQuery struct {
+	Query     string
+	Languages []string
+	Page      int
+}
+
+// GutenbergFormat is one download of a book. Kind is text, html or epub;
+// other formats Gutendex lists, such as zips and cover images, are left out.
+type GutenbergFormat struct {
+	Kind        string `json:"kind"`
+	ContentType string `json:"contentType"`
+	URL         string `json:"url"`
+}
+
+// GutenbergBook is a catalog entry, shaped so its fields can be copied into a
+// draft: Author is display order ("Lewis Carroll"), Language is the first
+// language listed, and SourceURL is the book's page on gutenberg.org.
+type GutenbergBook struct {
+	ID           int               `json:"id"`
+	Title        string            `json:"title"`
+	Author       *string           `json:"author"`
+	Language     string            `json:"language"`
+	Languages    []string          `json:"languages"`
+	PublicDomain bool              `json:"publicDomain"`
+	Downloads    int               `json:"downloads"`
+	SourceURL    string            `json:"sourceUrl"`
+	Formats      []GutenbergFormat `json:"formats"`
+}
+
+// GutenbergSearchResponse is one page of matching books, most downloaded
+// first. NextPage is omitted on the last page.
+type GutenbergSearchResponse struct {
+	Items    []GutenbergBook `json:"items"`
+	Count    int             `json:"count"`
+	Page     int             `json:"page"`
+	NextPage *int            `json:"nextPage"`
+}
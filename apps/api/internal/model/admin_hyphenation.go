@@ -0,0 +1,7 @@
+package model
+
+// AdminHyphenation is the account setting deciding whether readers receive
+// the soft hyphens of stories ingested with the hyphenation extension.
+type AdminHyphenation struct {
+	Enabled bool `json:"enabled"`
+}
@@ -0,0 +1,13 @@
+package model
+
+// AdminFetchImportRequest asks for the document at URL to be downloaded,
+// converted to Markdown and saved as Slug's draft. Title, Author and Language
+// override what the document says about itself; a document with no title of
+// its own needs Title.
+type AdminFetchImportRequest struct {
+	URL      string  `json:"url"`
+	Slug     string  `json:"slug"`
+	Title    string  `json:"title,omitempty"`
+	Author   *string `json:"author,omitempty"`
+	Language *string `json:"language,omitempty"`
+}
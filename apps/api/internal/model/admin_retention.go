@@ -0,0 +1,20 @@
+package model
+
+const (
+	MinVersionRetention = 1
+	MaxVersionRetention = 1000
+)
+
+// AdminVersionRetention is the account policy. A nil Keep disables automatic
+// pruning; prune requests must then name a count.
+type AdminVersionRetention struct {
+	Keep *int `json:"keep"`
+}
+
+type AdminPruneVersionsResponse struct {
+	Slug           string `json:"slug"`
+	Keep           int    `json:"keep"`
+	DryRun         bool   `json:"dryRun"`
+	PrunedVersions []int  `json:"prunedVersions"`
+	VersionCount   int    `json:"versionCount"`
+}
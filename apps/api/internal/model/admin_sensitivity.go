@@ -0,0 +1,19 @@
+package model
+
+type AdminSensitivityMatch struct {
+	Term    string           `json:"term"`
+	Source  string           `json:"source"`
+	Segment AdminLintLocator `json:"segment"`
+	Count   int              `json:"count"`
+}
+
+// AdminSensitivityReport lists where a story version mentions topics from the
+// account's active child profiles or the deployment word list.
+type AdminSensitivityReport struct {
+	Slug      string                  `json:"slug"`
+	VersionID string                  `json:"versionId"`
+	Version   int                     `json:"version"`
+	Terms     int                     `json:"terms"`
+	Flagged   bool                    `json:"flagged"`
+	Matches   []AdminSensitivityMatch `json:"matches"`
+}
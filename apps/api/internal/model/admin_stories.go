@@ -34,12 +34,14 @@ type AdminStorySummary struct {
 	PublishedVersion *AdminVersionPointerSummary `json:"publishedVersion"`
 	DraftVersion     *AdminVersionPointerSummary `json:"draftVersion"`
 	VersionCount     int                         `json:"versionCount"`
+	Readability      *Readability                `json:"readability"`
 	UpdatedAt        string                      `json:"updatedAt"`
 }
 
-type AdminStoriesListResponse struct {
-	Items []AdminStorySummary `json:"items"`
-}
+type AdminStoriesListResponse = Page[AdminStorySummary]
+
+// AdminStoryVersionsResponse is one page of a story's versions, newest first.
+type AdminStoryVersionsResponse = Page[AdminVersionSummary]
 
 type AdminVersionSummary struct {
 	VersionID    string             `json:"versionId"`
@@ -50,6 +52,7 @@ type AdminVersionSummary struct {
 	SegmentCount int                `json:"segmentCount"`
 	WordCount    int                `json:"wordCount"`
 	ChapterCount int                `json:"chapterCount"`
+	Readability  *Readability       `json:"readability"`
 	Health       AdminVersionHealth `json:"health"`
 }
 
@@ -64,6 +67,7 @@ type AdminStoryDetailResponse struct {
 	PublishedVersion *AdminVersionPointerSummary `json:"publishedVersion"`
 	DraftVersion     *AdminVersionPointerSummary `json:"draftVersion"`
 	VersionCount     int                         `json:"versionCount"`
+	Readability      *Readability                `json:"readability"`
 	CreatedAt        string                      `json:"createdAt"`
 	UpdatedAt        string                      `json:"updatedAt"`
 	Versions         []AdminVersionSummary       `json:"versions"`
@@ -87,6 +91,10 @@ type AdminVersionSourceResponse struct {
 	IsDraft      bool               `json:"isDraft"`
 	IsPublished  bool               `json:"isPublished"`
 	Health       AdminVersionHealth `json:"health"`
+	// Generation is set on versions written by story generation, and
+	// Moderation on those generated text was moderated for.
+	Generation *StoryGeneration `json:"generation"`
+	Moderation *StoryModeration `json:"moderation"`
 }
 
 type AdminStoryStatusResponse struct {
@@ -96,4 +104,29 @@ type AdminStoryStatusResponse struct {
 	DraftVersion     *AdminVersionPointerSummary `json:"draftVersion"`
 	VersionCount     int                         `json:"versionCount"`
 	UpdatedAt        string                      `json:"updatedAt"`
+
+	// SensitivityWarning is only set by publish, when the published version
+	// matches a sensitivity term. Publication is never blocked by it.
+	SensitivityWarning bool `json:"sensitivityWarning,omitempty"`
+}
+
+// AdminStoryMetadataPatch changes catalogue metadata on the story row only.
+// Nil fields are left alone; an empty Author clears it and an empty Tags
+// slice removes every tag. Versions keep the metadata they were created with.
+type AdminStoryMetadataPatch struct {
+	Title    *string
+	Author   *string
+	Language *string
+	Rights   map[string]any
+	Tags     []string
+}
+
+type AdminStoryMetadataResponse struct {
+	Slug      string         `json:"slug"`
+	Title     string         `json:"title"`
+	Author    *string        `json:"author"`
+	Language  string         `json:"language"`
+	Rights    map[string]any `json:"rights"`
+	Tags      []AdminTag     `json:"tags"`
+	UpdatedAt string         `json:"updatedAt"`
 }
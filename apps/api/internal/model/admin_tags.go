@@ -0,0 +1,40 @@
+package model
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const MaxAdminTagNameRunes = 64
+
+type AdminTag struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	StoryCount int    `json:"storyCount"`
+	CreatedAt  string `json:"createdAt"`
+}
+
+type AdminTagsListResponse = Page[AdminTag]
+
+// AdminStoryTagsResponse is the complete tag set of one story after a change.
+type AdminStoryTagsResponse struct {
+	Slug string     `json:"slug"`
+	Tags []AdminTag `json:"tags"`
+}
+
+// NormalizeAdminTagName collapses whitespace runs and reports whether the
+// result is a usable tag name. Case is preserved; uniqueness is case-folded
+// by the database.
+func NormalizeAdminTagName(name string) (string, bool) {
+	name = strings.Join(strings.Fields(name), " ")
+	if name == "" || utf8.RuneCountInString(name) > MaxAdminTagNameRunes {
+		return "", false
+	}
+	for _, r := range name {
+		if unicode.IsControl(r) {
+			return "", false
+		}
+	}
+	return name, true
+}
@@ -0,0 +1,34 @@
+package model
+
+// AdminUploadKind names the endpoint a finalized upload is ingested through.
+type AdminUploadKind string
+
+const (
+	// AdminUploadKindDraft carries an AdminDraftUpsertRequest.
+	AdminUploadKindDraft AdminUploadKind = "draft"
+	// AdminUploadKindBundle carries a StoryBundle.
+	AdminUploadKindBundle AdminUploadKind = "bundle"
+)
+
+func (k AdminUploadKind) Valid() bool {
+	return k == AdminUploadKindDraft || k == AdminUploadKindBundle
+}
+
+type AdminUploadCreate struct {
+	Kind       AdminUploadKind
+	TotalBytes int64
+	SHA256     string
+}
+
+// AdminUpload reports how far an upload has progressed. A client resumes by
+// sending the next chunk at ReceivedBytes.
+type AdminUpload struct {
+	ID            string          `json:"id"`
+	Kind          AdminUploadKind `json:"kind"`
+	TotalBytes    int64           `json:"totalBytes"`
+	ReceivedBytes int64           `json:"receivedBytes"`
+	SHA256        *string         `json:"sha256"`
+	MaxChunkBytes int64           `json:"maxChunkBytes"`
+	CreatedAt     string          `json:"createdAt"`
+	ExpiresAt     string          `json:"expiresAt"`
+}
@@ -0,0 +1,69 @@
+package model
+
+type AdminRole string
+
+const (
+	// AdminRoleImporter may preview content and ingest draft versions.
+	AdminRoleImporter AdminRole = "importer"
+	// AdminRoleEditor may preview content and edit story metadata.
+	AdminRoleEditor AdminRole = "editor"
+	// AdminRolePublisher may change which version readers see.
+	AdminRolePublisher AdminRole = "publisher"
+)
+
+// AdminRoles lists every assignable role in a stable order.
+var AdminRoles = []AdminRole{AdminRoleImporter, AdminRoleEditor, AdminRolePublisher}
+
+func (r AdminRole) Valid() bool {
+	for _, role := range AdminRoles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// AdminPrincipal is the authenticated admin behind a request. Name is what the
+// audit log records as the actor.
+type AdminPrincipal struct {
+	UserID string
+	Name   string
+	Roles  []AdminRole
+}
+
+func (p AdminPrincipal) HasAnyRole(roles ...AdminRole) bool {
+	for _, want := range roles {
+		for _, have := range p.Roles {
+			if want == have {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// AdminUserCreate carries only the digest of a newly generated key; the raw key
+// never crosses the Store boundary.
+type AdminUserCreate struct {
+	Name    string
+	Roles   []AdminRole
+	KeyHash string
+}
+
+type AdminUserRecord struct {
+	ID         string      `json:"id"`
+	Name       string      `json:"name"`
+	Roles      []AdminRole `json:"roles"`
+	CreatedAt  string      `json:"createdAt"`
+	DisabledAt *string     `json:"disabledAt"`
+}
+
+type AdminUsersListResponse struct {
+	Items []AdminUserRecord `json:"items"`
+}
+
+// AdminUserCreateResponse is the only response that ever contains an admin key.
+type AdminUserCreateResponse struct {
+	User AdminUserRecord `json:"user"`
+	Key  string          `json:"key"`
+}
@@ -0,0 +1,24 @@
+package model
+
+// Bedtime mode keeps stories tagged BedtimeCalmTag and leaves out those
+// tagged BedtimeExcitingTag; tags match whatever their case.
+const (
+	BedtimeCalmTag     = "calm"
+	BedtimeExcitingTag = "exciting"
+	// MaxBedtimeWords bounds the words a story may have to count as short.
+	MaxBedtimeWords = 100000
+)
+
+// BedtimeSettings chooses when the Library's ?mode=bedtime applies: from
+// Start to End, HH:MM in TimeZone, an IANA name such as Europe/London. A
+// window whose End is earlier than its Start runs past midnight. During it,
+// only calm stories of at most MaxWords words are listed.
+type BedtimeSettings struct {
+	Start    string `json:"start"`
+	End      string `json:"end"`
+	TimeZone string `json:"timeZone"`
+	MaxWords int    `json:"maxWords"`
+}
+
+// DefaultBedtimeSettings apply to an account that has not chosen its own.
+var DefaultBedtimeSettings = BedtimeSettings{Start: "18:30", End: "07:00", TimeZone: "UTC", MaxWords: 1500}
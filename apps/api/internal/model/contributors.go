@@ -0,0 +1,46 @@
+package model
+
+type ContributorRole string
+
+// Roles are listed in the order readers see credits.
+const (
+	ContributorRoleAuthor      ContributorRole = "author"
+	ContributorRoleReteller    ContributorRole = "reteller"
+	ContributorRoleTranslator  ContributorRole = "translator"
+	ContributorRoleIllustrator ContributorRole = "illustrator"
+	ContributorRoleEditor      ContributorRole = "editor"
+)
+
+var ContributorRoles = []ContributorRole{
+	ContributorRoleAuthor,
+	ContributorRoleReteller,
+	ContributorRoleTranslator,
+	ContributorRoleIllustrator,
+	ContributorRoleEditor,
+}
+
+func (r ContributorRole) Valid() bool {
+	for _, role := range ContributorRoles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// StoryContributor is the public credit shown with a published story.
+type StoryContributor struct {
+	Name string          `json:"name"`
+	Role ContributorRole `json:"role"`
+}
+
+type AdminStoryContributor struct {
+	ContributorID string          `json:"contributorId"`
+	Name          string          `json:"name"`
+	Role          ContributorRole `json:"role"`
+}
+
+type AdminStoryContributorsResponse struct {
+	Slug  string                  `json:"slug"`
+	Items []AdminStoryContributor `json:"items"`
+}
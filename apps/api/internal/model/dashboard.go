@@ -0,0 +1,55 @@
+package model
+
+import "time"
+
+// A child's dashboard covers DashboardWeeks weeks ending today, lists up to
+// DashboardRecentStories stories and DashboardFavoriteTags tags.
+const (
+	DashboardWeeks         = 4
+	DashboardRecentStories = 10
+	DashboardFavoriteTags  = 5
+)
+
+// ChildDashboard is one child's reading from From to To, local dates in
+// the account's digest time zone. Like the reading report it counts the
+// minutes in which the Reader saved progress while the child was active;
+// StreakDays follows the report's rule.
+type ChildDashboard struct {
+	ChildProfileID string `json:"childProfileId"`
+	Name           string `json:"name"`
+	From           string `json:"from"`
+	To             string `json:"to"`
+	TimeZone       string `json:"timeZone"`
+	Minutes        int    `json:"minutes"`
+	StreakDays     int    `json:"streakDays"`
+	// Recent is the stories read, most recently read first.
+	Recent []DashboardStory `json:"recent"`
+	// FavoriteTags ranks the tags of the stories read by minutes spent.
+	FavoriteTags []DashboardTag `json:"favoriteTags"`
+	// LevelTrend is one entry per week, oldest first.
+	LevelTrend []DashboardLevel `json:"levelTrend"`
+	// TimeOfDay holds the minutes read in each local hour, from midnight.
+	TimeOfDay []int `json:"timeOfDay"`
+}
+
+type DashboardStory struct {
+	Slug       string    `json:"slug"`
+	Title      string    `json:"title"`
+	Minutes    int       `json:"minutes"`
+	Finished   bool      `json:"finished"`
+	LastReadAt time.Time `json:"lastReadAt"`
+}
+
+type DashboardTag struct {
+	Name    string `json:"name"`
+	Minutes int    `json:"minutes"`
+}
+
+// DashboardLevel is the week from From: Grade is the Flesch-Kincaid grade
+// of the published versions read, weighted by minutes, and nil when none of
+// them has readability metrics.
+type DashboardLevel struct {
+	From    string   `json:"from"`
+	Minutes int      `json:"minutes"`
+	Grade   *float64 `json:"grade"`
+}
@@ -0,0 +1,73 @@
+package model
+
+// DatabasePoolStats is a snapshot of the PostgreSQL connection pool. Counts
+// are connections now; the acquire counters accumulate since startup.
+type DatabasePoolStats struct {
+	MaxConns             int32 `json:"maxConns"`
+	TotalConns           int32 `json:"totalConns"`
+	AcquiredConns        int32 `json:"acquiredConns"`
+	IdleConns            int32 `json:"idleConns"`
+	ConstructingConns    int32 `json:"constructingConns"`
+	AcquireCount         int64 `json:"acquireCount"`
+	EmptyAcquireCount    int64 `json:"emptyAcquireCount"`
+	CanceledAcquireCount int64 `json:"canceledAcquireCount"`
+	AcquireDurationMs    int64 `json:"acquireDurationMs"`
+
+	// EmptyAcquireWaitMs is the time spent waiting for a free connection;
+	// steady growth means MaxConns is too small for the load.
+	EmptyAcquireWaitMs      int64 `json:"emptyAcquireWaitMs"`
+	NewConnsCount           int64 `json:"newConnsCount"`
+	MaxLifetimeDestroyCount int64 `json:"maxLifetimeDestroyCount"`
+	MaxIdleDestroyCount     int64 `json:"maxIdleDestroyCount"`
+}
+
+// CacheStats counts lookups in one Store cache since startup. HitRate is
+// Hits over all lookups, or zero before the first.
+type CacheStats struct {
+	Hits          int64   `json:"hits"`
+	Misses        int64   `json:"misses"`
+	HitRate       float64 `json:"hitRate"`
+	Entries       int     `json:"entries"`
+	Bytes         int     `json:"bytes,omitempty"`
+	CapacityBytes int     `json:"capacityBytes,omitempty"`
+}
+
+// ReplicaStats describes the optional read replica. InUse says whether
+// Reader reads go to it now; LagMs is its last measured replay lag.
+type ReplicaStats struct {
+	Pool     DatabasePoolStats `json:"pool"`
+	InUse    bool              `json:"inUse"`
+	LagMs    int64             `json:"lagMs"`
+	MaxLagMs int64             `json:"maxLagMs"`
+}
+
+// DatabaseStats is the Store's connection pools and in-memory caches.
+// Replica is omitted when none is configured.
+type DatabaseStats struct {
+	Pool    DatabasePoolStats `json:"pool"`
+	Replica *ReplicaStats     `json:"replica,omitempty"`
+	Caches  struct {
+		Reader         CacheStats `json:"reader"`
+		DefaultProfile CacheStats `json:"defaultProfile"`
+	} `json:"caches"`
+}
+
+// QueryLatency is the latency histogram of one Store query since startup.
+// Buckets are cumulative, like Prometheus histograms: each counts the queries
+// no slower than LeMs, and Count includes those slower than every bound.
+type QueryLatency struct {
+	Query   string               `json:"query"`
+	Count   int64                `json:"count"`
+	TotalMs float64              `json:"totalMs"`
+	Buckets []QueryLatencyBucket `json:"buckets"`
+}
+
+type QueryLatencyBucket struct {
+	LeMs  float64 `json:"leMs"`
+	Count int64   `json:"count"`
+}
+
+// QueryLatencyResponse lists every Store query that has run, by name.
+type QueryLatencyResponse struct {
+	Items []QueryLatency `json:"items"`
+}
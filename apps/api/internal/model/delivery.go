@@ -0,0 +1,107 @@
+package model
+
+import (
+	"net/mail"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// MaxDeliveryDestinations bounds how many addresses one account may keep.
+	MaxDeliveryDestinations         = 10
+	MaxDeliveryDestinationNameRunes = 80
+	maxDeliveryAddressBytes         = 254
+)
+
+// DeliveryDestination is an email address an account sends books to, such as
+// a Kindle's Send-to-Kindle address.
+type DeliveryDestination struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Address   string `json:"address"`
+	CreatedAt string `json:"createdAt"`
+}
+
+type DeliveryDestinationCreate struct {
+	Name    string `json:"name"`
+	Address string `json:"address"`
+}
+
+type DeliveryDestinationsResponse struct {
+	Items []DeliveryDestination `json:"items"`
+}
+
+// ValidDeliveryDestinationName reports whether name, already trimmed, may
+// label a destination.
+func ValidDeliveryDestinationName(name string) bool {
+	return name != "" && utf8.RuneCountInString(name) <= MaxDeliveryDestinationNameRunes
+}
+
+// DeliveryAddress returns address as it is stored, the bare address with its
+// domain lowercased, or false when it is not one email address.
+func DeliveryAddress(address string) (string, bool) {
+	address = strings.TrimSpace(address)
+	parsed, err := mail.ParseAddress(address)
+	if err != nil || parsed.Name != "" || parsed.Address != address {
+		return "", false
+	}
+	local, domain, ok := strings.Cut(parsed.Address, "@")
+	if !ok || local == "" || domain == "" || len(parsed.Address) > maxDeliveryAddressBytes {
+		return "", false
+	}
+	return local + "@" + strings.ToLower(domain), true
+}
+
+// StorySendRequest names the destination a story is emailed to.
+type StorySendRequest struct {
+	DestinationID string `json:"destinationId"`
+}
+
+type DeliveryStatus string
+
+const (
+	DeliveryPending DeliveryStatus = "pending"
+	DeliverySent    DeliveryStatus = "sent"
+	DeliveryFailed  DeliveryStatus = "failed"
+)
+
+// StoryDelivery is one story emailed as an EPUB. Address is kept as it was
+// when the story was sent, so removing the destination later leaves the
+// history readable. Version is the published version the book was made
+// from, once it has been.
+type StoryDelivery struct {
+	ID            string         `json:"id"`
+	Slug          string         `json:"slug"`
+	DestinationID *string        `json:"destinationId"`
+	Address       string         `json:"address"`
+	Status        DeliveryStatus `json:"status"`
+	Version       *int           `json:"version"`
+	Attempts      int            `json:"attempts"`
+	Error         *string        `json:"error"`
+	CreatedAt     string         `json:"createdAt"`
+	SentAt        *string        `json:"sentAt"`
+}
+
+type StoryDeliveriesResponse struct {
+	Items []StoryDelivery `json:"items"`
+}
+
+// PendingStoryDelivery is a claimed delivery as the dispatcher needs it.
+type PendingStoryDelivery struct {
+	ID        string
+	AccountID string
+	Slug      string
+	Address   string
+	Attempts  int
+}
+
+// StoryDeliveryAttempt records one send. A failed attempt with
+// NextAttemptAt is retried then; without it the delivery has failed.
+// Version is zero when no book was made.
+type StoryDeliveryAttempt struct {
+	Sent          bool
+	Version       int
+	Error         string
+	NextAttemptAt *time.Time
+}
@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+// Definition is what a dictionary says a word means, as the Reader shows it
+// when a child taps the word. Senses are in the dictionary's order, most
+// common first.
+type Definition struct {
+	Word     string      `json:"word"`
+	Language string      `json:"language"`
+	Phonetic *string     `json:"phonetic"`
+	Senses   []WordSense `json:"senses"`
+}
+
+type WordSense struct {
+	PartOfSpeech string  `json:"partOfSpeech"`
+	Definition   string  `json:"definition"`
+	Example      *string `json:"example"`
+}
+
+// DictionaryEntry is a kept dictionary answer. Definition is nil for a word
+// the dictionary did not know.
+type DictionaryEntry struct {
+	Definition *Definition
+	FetchedAt  time.Time
+}
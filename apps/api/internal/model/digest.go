@@ -0,0 +1,60 @@
+package model
+
+import "time"
+
+// DigestDays names the days a digest can be sent on, from Sunday.
+var DigestDays = []string{"sunday", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday"}
+
+// DigestSettings chooses the account's weekly email. When Enabled, the
+// reading report is sent to Address each Day at Time, HH:MM in TimeZone, an
+// IANA name such as Europe/London.
+type DigestSettings struct {
+	Enabled  bool   `json:"enabled"`
+	Address  string `json:"address"`
+	Day      string `json:"day"`
+	Time     string `json:"time"`
+	TimeZone string `json:"timeZone"`
+}
+
+// DefaultDigestSettings apply to an account that has not chosen its own.
+var DefaultDigestSettings = DigestSettings{Enabled: false, Address: "", Day: "sunday", Time: "18:00", TimeZone: "UTC"}
+
+// ReadingReport is one account's reading over the seven days from From to
+// To, local dates in TimeZone. Reading saved while no child profile was
+// active is reported under a child with no ID.
+type ReadingReport struct {
+	From     string         `json:"from"`
+	To       string         `json:"to"`
+	TimeZone string         `json:"timeZone"`
+	Children []ChildReading `json:"children"`
+}
+
+// ChildReading is one child's week. Minutes counts the minutes in which the
+// reader saved progress; StreakDays is the run of days with any reading
+// that ends on To, or the day before when nothing has been read on To yet.
+type ChildReading struct {
+	ChildProfileID *string        `json:"childProfileId"`
+	Name           string         `json:"name"`
+	Minutes        int            `json:"minutes"`
+	BooksFinished  []FinishedBook `json:"booksFinished"`
+	StreakDays     int            `json:"streakDays"`
+}
+
+type FinishedBook struct {
+	Slug  string `json:"slug"`
+	Title string `json:"title"`
+}
+
+// DueDigest is an account whose digest is due, with the address it goes to.
+type DueDigest struct {
+	AccountID string
+	Address   string
+	Attempts  int
+}
+
+// DigestAttempt records one send. A failed attempt with NextAttemptAt is
+// tried again then; without it that week's digest is skipped.
+type DigestAttempt struct {
+	Sent          bool
+	NextAttemptAt *time.Time
+}
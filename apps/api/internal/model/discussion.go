@@ -0,0 +1,46 @@
+package model
+
+// An editor writes up to MaxDiscussionQuestions conversation prompts per
+// chapter.
+const (
+	MaxDiscussionQuestions   = 5
+	MaxDiscussionQuestionLen = 300
+)
+
+// ChapterDiscussion is the conversation prompts for one chapter, numbered
+// and identified as a ChapterQuiz is.
+type ChapterDiscussion struct {
+	Chapter           int      `json:"chapter"`
+	ChapterKey        *string  `json:"chapterKey"`
+	ChapterOccurrence *int     `json:"chapterOccurrence"`
+	Title             *string  `json:"title"`
+	Questions         []string `json:"questions"`
+}
+
+// StoryDiscussion is the conversation prompts of a story's published
+// version, in chapter order.
+type StoryDiscussion struct {
+	Slug     string              `json:"slug"`
+	Version  int                 `json:"version"`
+	Chapters []ChapterDiscussion `json:"chapters"`
+}
+
+// AdminChapterDiscussion is one chapter as the Studio edits it. A chapter
+// with no prompts yet has no questions and a nil UpdatedAt.
+type AdminChapterDiscussion struct {
+	ChapterDiscussion
+	UpdatedAt *string `json:"updatedAt"`
+}
+
+type AdminDiscussionResponse struct {
+	Slug      string                   `json:"slug"`
+	VersionID string                   `json:"versionId"`
+	Chapters  []AdminChapterDiscussion `json:"chapters"`
+}
+
+// AdminDiscussionUpdate replaces a chapter's prompts. An empty VersionID
+// uses the published version, else the draft.
+type AdminDiscussionUpdate struct {
+	VersionID string   `json:"versionId,omitempty"`
+	Questions []string `json:"questions"`
+}
@@ -0,0 +1,38 @@
+package model
+
+import "strings"
+
+// FieldError points at one invalid input in a request body, so a client can
+// highlight it. Path is the input's dotted JSON path, such as
+// "locator.segment.ordinal"; array elements are numbered from zero.
+type FieldError struct {
+	Path    string `json:"path"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// issueIndex rewrites the "versions[2].version" indexing of admin issues.
+var issueIndex = strings.NewReplacer("[", ".", "]", "")
+
+// FieldErrors converts admin validation issues into the shared field error
+// model. Issues about a Markdown line are about the field holding it.
+func FieldErrors(issues []AdminValidationIssue) []FieldError {
+	fields := make([]FieldError, 0, len(issues))
+	for _, issue := range issues {
+		fields = append(fields, FieldError{Path: issueIndex.Replace(issue.Field), Code: issue.Code, Message: issue.Message})
+	}
+	return fields
+}
+
+// RateLimit explains a rate_limited refusal in the body as well as the
+// headers, so a client can back off without parsing them. Budget names the
+// read or write bucket, which holds Limit requests and refills continuously;
+// ResetSeconds is how long until it is full again, and RetryAfterSeconds how
+// long until the refused request would be allowed.
+type RateLimit struct {
+	Budget            string `json:"budget"`
+	Limit             int    `json:"limit"`
+	Remaining         int    `json:"remaining"`
+	ResetSeconds      int64  `json:"resetSeconds"`
+	RetryAfterSeconds int64  `json:"retryAfterSeconds"`
+}
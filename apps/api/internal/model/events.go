@@ -0,0 +1,43 @@
+package model
+
+import "time"
+
+const (
+	// MaxClientEvents bounds one batch of Reader events.
+	MaxClientEvents = 100
+	// ClientEventMaxAge is how old an event may be and still count; a
+	// device offline for longer loses its events.
+	ClientEventMaxAge = 7 * 24 * time.Hour
+	// ClientEventMaxSkew allows for a device clock running ahead.
+	ClientEventMaxSkew = 5 * time.Minute
+)
+
+type ClientEventKind string
+
+const (
+	ClientEventStoryOpened   ClientEventKind = "story_opened"
+	ClientEventSegmentViewed ClientEventKind = "segment_viewed"
+	ClientEventReadAloudUsed ClientEventKind = "read_aloud_used"
+)
+
+// ClientEventKinds lists the events the Reader may send.
+var ClientEventKinds = []ClientEventKind{ClientEventStoryOpened, ClientEventSegmentViewed, ClientEventReadAloudUsed}
+
+// ClientEvent is one thing a reader did with a story, at At by the device's
+// clock. Nothing else about the device or reader is sent.
+type ClientEvent struct {
+	Kind ClientEventKind `json:"kind"`
+	Slug string          `json:"slug"`
+	At   time.Time       `json:"at"`
+}
+
+type ClientEventBatch struct {
+	Events []ClientEvent `json:"events"`
+}
+
+// ClientEventsResponse counts the batch's events that were recorded and
+// those dropped: for a story the account has not published, or too old.
+type ClientEventsResponse struct {
+	Recorded int `json:"recorded"`
+	Dropped  int `json:"dropped"`
+}
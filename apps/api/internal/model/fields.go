@@ -0,0 +1,125 @@
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// FieldSelection is a list's ?fields= selection: the item fields to send,
+// kept in the item's own order. The zero value sends every field.
+type FieldSelection struct {
+	names []string
+}
+
+// ParseFieldSelection reads the comma-separated fields query parameter shared
+// by list endpoints. item is one list item; its top-level JSON names are the
+// fields that may be asked for. Unknown names are reported by position so the
+// response never echoes client input.
+func ParseFieldSelection(query url.Values, item any) (FieldSelection, []FieldError) {
+	raw := strings.TrimSpace(query.Get("fields"))
+	if raw == "" {
+		return FieldSelection{}, nil
+	}
+	allowed := jsonFieldNames(reflect.TypeOf(item))
+	asked := map[string]bool{}
+	var problems []FieldError
+	for index, name := range strings.Split(raw, ",") {
+		name = strings.TrimSpace(name)
+		if !slices.Contains(allowed, name) {
+			problems = append(problems, FieldError{
+				Path:    "fields." + strconv.Itoa(index),
+				Code:    "unknown",
+				Message: "fields may name " + strings.Join(allowed, ", "),
+			})
+			continue
+		}
+		asked[name] = true
+	}
+	if len(problems) > 0 {
+		return FieldSelection{}, problems
+	}
+	selection := FieldSelection{names: make([]string, 0, len(asked))}
+	for _, name := range allowed {
+		if asked[name] {
+			selection.names = append(selection.names, name)
+		}
+	}
+	return selection, nil
+}
+
+// Project returns response with each of its items cut down to the selected
+// fields. response is a list envelope whose items are under "items"; the
+// rest of the envelope, such as nextCursor, is sent whole.
+func (s FieldSelection) Project(response any) (any, error) {
+	if s.names == nil {
+		return response, nil
+	}
+	encoded, err := json.Marshal(response)
+	if err != nil {
+		return nil, err
+	}
+	var envelope map[string]json.RawMessage
+	if err := json.Unmarshal(encoded, &envelope); err != nil {
+		return nil, err
+	}
+	var items []map[string]json.RawMessage
+	if err := json.Unmarshal(envelope["items"], &items); err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	out.WriteByte('[')
+	for index, item := range items {
+		if index > 0 {
+			out.WriteByte(',')
+		}
+		out.WriteByte('{')
+		for field, name := range s.names {
+			if field > 0 {
+				out.WriteByte(',')
+			}
+			key, _ := json.Marshal(name)
+			out.Write(key)
+			out.WriteByte(':')
+			if value, ok := item[name]; ok {
+				out.Write(value)
+			} else {
+				out.WriteString("null")
+			}
+		}
+		out.WriteByte('}')
+	}
+	out.WriteByte(']')
+	envelope["items"] = out.Bytes()
+	return envelope, nil
+}
+
+// jsonFieldNames lists the names encoding/json gives t's fields, in order,
+// flattening embedded structs.
+func jsonFieldNames(t reflect.Type) []string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	var names []string
+	for i := range t.NumField() {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" || (!field.IsExported() && !field.Anonymous) {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			names = append(names, jsonFieldNames(field.Type)...)
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		names = append(names, name)
+	}
+	return names
+}
@@ -0,0 +1,60 @@
+package model
+
+import "encoding/json"
+
+// GenerationStatus is a generation_jobs row's outcome. Generation runs within
+// its request, so a row is written only once it has succeeded or failed.
+type GenerationStatus string
+
+const (
+	GenerationSucceeded GenerationStatus = "succeeded"
+	GenerationFailed    GenerationStatus = "failed"
+)
+
+// AdminGenerateRequest asks for a new story draft at Slug about Theme. Empty
+// profile IDs use the account's active child and prompt profiles.
+type AdminGenerateRequest struct {
+	Slug            string `json:"slug"`
+	Theme           string `json:"theme"`
+	ChildProfileID  string `json:"childProfileId,omitempty"`
+	PromptProfileID string `json:"promptProfileId,omitempty"`
+}
+
+// StoryGeneration is how a version was written: the model, the prompt
+// template and the profiles it was given.
+type StoryGeneration struct {
+	ID              string  `json:"id"`
+	Model           string  `json:"model"`
+	PromptVersion   string  `json:"promptVersion"`
+	Theme           string  `json:"theme"`
+	ChildProfileID  *string `json:"childProfileId"`
+	PromptProfileID *string `json:"promptProfileId"`
+	TokensIn        *int    `json:"tokensIn"`
+	TokensOut       *int    `json:"tokensOut"`
+	CreatedAt       string  `json:"createdAt"`
+}
+
+// GenerationRecord is one attempt as written to generation_jobs. Request and
+// Response keep the prompt and the raw completion for review; a failed
+// attempt has Error and no version.
+type GenerationRecord struct {
+	Status          GenerationStatus
+	Slug            string
+	VersionID       string
+	ChildProfileID  string
+	PromptProfileID string
+	Theme           string
+	Model           string
+	PromptVersion   string
+	Request         json.RawMessage
+	Response        json.RawMessage
+	Error           *string
+	TokensIn        int
+	TokensOut       int
+}
+
+type AdminGenerateResponse struct {
+	AdminDraftUpsertResponse
+	Generation StoryGeneration `json:"generation"`
+	Moderation StoryModeration `json:"moderation"`
+}
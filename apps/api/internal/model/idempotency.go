@@ -0,0 +1,11 @@
+package model
+
+// IdempotentResponse is the answer to an unsafe request sent with an
+// Idempotency-Key, kept so that a retry gets the same answer. Fingerprint
+// identifies the request; Status is zero while it is still running.
+type IdempotentResponse struct {
+	Fingerprint string
+	Status      int
+	ContentType string
+	Body        []byte
+}
@@ -0,0 +1,6 @@
+package model
+
+// LogLevel is the API's current log level: debug, info, warn or error.
+type LogLevel struct {
+	Level string `json:"level"`
+}
@@ -0,0 +1,13 @@
+package model
+
+// MaxMaintenanceMessageRunes bounds the message readers see during
+// maintenance.
+const MaxMaintenanceMessageRunes = 280
+
+// MaintenanceState is whether the reader API is offline for maintenance.
+// Since is when it was turned on, in RFC 3339.
+type MaintenanceState struct {
+	Enabled bool    `json:"enabled"`
+	Message string  `json:"message"`
+	Since   *string `json:"since"`
+}
@@ -0,0 +1,25 @@
+package model
+
+// MediaContentTypes are the image types accepted for upload and served back.
+var MediaContentTypes = []string{"image/png", "image/jpeg", "image/gif", "image/webp"}
+
+func ValidMediaContentType(contentType string) bool {
+	for _, candidate := range MediaContentTypes {
+		if candidate == contentType {
+			return true
+		}
+	}
+	return false
+}
+
+// Media describes one uploaded image. Ref is what story Markdown uses in an
+// image destination; URL is where the Reader loads it from.
+type Media struct {
+	ID          string `json:"id"`
+	Ref         string `json:"ref"`
+	URL         string `json:"url"`
+	ContentType string `json:"contentType"`
+	ByteSize    int    `json:"byteSize"`
+	SHA256      string `json:"sha256"`
+	CreatedAt   string `json:"createdAt"`
+}
@@ -14,12 +14,122 @@ var (
 	// ErrAdminPublishInvalid marks an expected publish refusal whose public
 	// response must not reveal which internal invariant failed.
 	ErrAdminPublishInvalid = errors.New("story version cannot be published")
+	// ErrModerationBlocked marks a publish of a version moderation blocked.
+	ErrModerationBlocked = errors.New("story version was blocked by moderation")
+	// ErrModerationUnacknowledged marks a publish of a version moderation
+	// flagged, without the publisher acknowledging the findings.
+	ErrModerationUnacknowledged = errors.New("story version was flagged by moderation")
 	// ErrAdminVersionRepairRequired marks a corrupt idempotency target that must
 	// not be reused or mutated as though it were a healthy immutable version.
 	ErrAdminVersionRepairRequired = errors.New("stored story version requires repair")
 	// ErrAdminStoryNotFound intentionally covers missing, cross-account, and
 	// cross-story admin targets so ownership boundaries are not disclosed.
 	ErrAdminStoryNotFound = errors.New("admin story resource was not found")
+	// ErrAdminStoryConflict marks an import whose slug already exists.
+	ErrAdminStoryConflict = errors.New("admin story already exists")
+	// ErrAdminUserNotFound covers unknown, disabled, and cross-account admin
+	// users so credential probes cannot tell them apart.
+	ErrAdminUserNotFound = errors.New("admin user was not found")
+	// ErrAdminUserConflict marks a duplicate admin user name within an account.
+	ErrAdminUserConflict = errors.New("admin user already exists")
+	// ErrAdminTagNotFound covers missing and cross-account tags.
+	ErrAdminTagNotFound = errors.New("admin tag was not found")
+	// ErrAdminTagConflict marks a tag name already used within the account.
+	ErrAdminTagConflict = errors.New("admin tag already exists")
+	// ErrAdminContributorNotFound marks a credit the story does not carry.
+	ErrAdminContributorNotFound = errors.New("story contributor was not found")
+	// ErrAdminWebhookNotFound covers missing and cross-account webhooks.
+	ErrAdminWebhookNotFound = errors.New("admin webhook was not found")
+	// ErrAdminUploadNotFound covers missing, expired, and cross-account uploads.
+	ErrAdminUploadNotFound = errors.New("admin upload was not found")
+	// ErrAdminUploadOffset marks a chunk that does not start where the upload
+	// currently ends.
+	ErrAdminUploadOffset = errors.New("admin upload offset does not match")
+	// ErrAdminUploadTooLarge marks a chunk that would exceed the declared size.
+	ErrAdminUploadTooLarge = errors.New("admin upload exceeds its declared size")
+	// ErrAdminRetentionUnset marks a prune with no count and no account policy.
+	ErrAdminRetentionUnset = errors.New("version retention is not configured")
+	// ErrRenderJobNotFound covers missing and cross-account render jobs.
+	ErrRenderJobNotFound = errors.New("render job was not found")
+	// ErrRenderJobActive marks a request while the account's previous
+	// render job is still queued or running.
+	ErrRenderJobActive = errors.New("a render job is already active")
+	// ErrNarrationJobNotFound covers missing and cross-account narration jobs.
+	ErrNarrationJobNotFound = errors.New("narration job was not found")
+	// ErrNarrationJobActive marks a request while the version's previous
+	// narration job is still queued or running.
+	ErrNarrationJobActive = errors.New("a narration job is already active")
+	// ErrAlignmentJobNotFound covers missing and cross-account alignment
+	// jobs.
+	ErrAlignmentJobNotFound = errors.New("alignment job was not found")
+	// ErrAlignmentJobActive marks a request while the version's previous
+	// alignment job is still queued or running.
+	ErrAlignmentJobActive = errors.New("an alignment job is already active")
+	// ErrSearchReindexJobNotFound covers missing and cross-account search
+	// reindex jobs.
+	ErrSearchReindexJobNotFound = errors.New("search reindex job was not found")
+	// ErrSearchReindexJobActive marks a request while the account's previous
+	// search reindex job is still queued or running.
+	ErrSearchReindexJobActive = errors.New("a search reindex job is already active")
+	// ErrAudioNotFound covers missing and cross-account segment audio.
+	ErrAudioNotFound = errors.New("audio was not found")
+	// ErrProfileNotFound covers missing and cross-account child and prompt
+	// profiles named by a generation request.
+	ErrProfileNotFound = errors.New("profile was not found")
+	// ErrTranslationSlugTaken marks a translation aimed at a slug that holds
+	// a story outside the original's language group.
+	ErrTranslationSlugTaken = errors.New("slug belongs to another story")
+	// ErrSimplificationSlugTaken marks a simplification aimed at a slug that
+	// holds a story other than a simplification of the same original.
+	ErrSimplificationSlugTaken = errors.New("slug belongs to another story")
+	// ErrQuizChapterNotFound marks a quiz for a chapter the story version
+	// does not have.
+	ErrQuizChapterNotFound = errors.New("story version has no such chapter")
+	// ErrQuizNotFound marks a chapter with no quiz written.
+	ErrQuizNotFound = errors.New("chapter has no quiz")
+	// ErrDiscussionNotFound marks a chapter with no discussion prompts
+	// written.
+	ErrDiscussionNotFound = errors.New("chapter has no discussion prompts")
+	// ErrPhonicsNotFound marks a story version not yet scored against the
+	// phonics progression, or written in another language.
+	ErrPhonicsNotFound = errors.New("story version has no phonics analysis")
+	// ErrDestinationNotFound covers missing and cross-account delivery
+	// destinations.
+	ErrDestinationNotFound = errors.New("delivery destination was not found")
+	// ErrDestinationExists marks an address the account already sends to.
+	ErrDestinationExists = errors.New("delivery destination already exists")
+	// ErrDestinationLimit marks an account at MaxDeliveryDestinations.
+	ErrDestinationLimit = errors.New("too many delivery destinations")
+	// ErrDeliveryNotFound covers missing and cross-account story deliveries.
+	ErrDeliveryNotFound = errors.New("story delivery was not found")
+	// ErrPushSubscriptionNotFound covers unknown and cross-account push
+	// subscriptions.
+	ErrPushSubscriptionNotFound = errors.New("push subscription was not found")
+	// ErrPushSubscriptionLimit marks an account at MaxPushSubscriptions.
+	ErrPushSubscriptionLimit = errors.New("too many push subscriptions")
+	// ErrPushTimeZone marks a reminder time zone the database does not know.
+	ErrPushTimeZone = errors.New("time zone is not known")
+	// ErrDigestTimeZone marks a digest time zone the database does not
+	// know.
+	ErrDigestTimeZone = errors.New("time zone is not known")
+	// ErrBedtimeTimeZone marks a bedtime time zone the database does not
+	// know.
+	ErrBedtimeTimeZone = errors.New("time zone is not known")
+	// ErrStoryRemoved marks a Reader request for a story that was published
+	// and has since been unpublished, as opposed to one that never was.
+	ErrStoryRemoved = errors.New("story was removed")
+	// ErrMediaNotFound covers missing and cross-account media.
+	ErrMediaNotFound = errors.New("media was not found")
+	// ErrMediaConflict marks a restored image whose ID already holds other
+	// bytes, or belongs to another account.
+	ErrMediaConflict = errors.New("media ID is already in use")
+	// ErrQueueFull marks a profile at MaxQueueItems.
+	ErrQueueFull = errors.New("too many stories queued")
+	// ErrQueueItemNotFound marks a story that is not in the queue.
+	ErrQueueItemNotFound = errors.New("story is not queued")
+	// ErrQueueOrder marks a reorder that does not name every queued story
+	// exactly once.
+	ErrQueueOrder = errors.New("order must name every queued story once")
 )
 
 type StoryItem struct {
@@ -31,14 +141,28 @@ type StoryItem struct {
 	WordCount        int64                   `json:"wordCount"`
 	ChapterCount     int64                   `json:"chapterCount"`
 	Progress         *LibraryProgressSummary `json:"progress"`
+	// TranslationOf is the published original of a translated story, and
+	// Variants every other published language of the same story.
+	TranslationOf *string        `json:"translationOf,omitempty"`
+	Variants      []StoryVariant `json:"variants,omitempty"`
+	// Reactions counts how the account's profiles reacted to the story; it
+	// is left out until someone has.
+	Reactions *ReactionCounts `json:"reactions,omitempty"`
 }
 
 // LibraryReadModel is the account-scoped bookshelf response. Items that cannot
 // be represented safely from their immutable published version are omitted and
 // counted without exposing their metadata or internal identifiers.
+// LibraryReadModel is one page of the Library. UnavailableItemCount counts
+// the page's stories that could not be shown; Total, when asked for, counts
+// them too.
+//
+// Bedtime is true when ?mode=bedtime narrowed the page because the
+// account's bedtime window was open.
 type LibraryReadModel struct {
-	Items                []StoryItem `json:"items"`
-	UnavailableItemCount int64       `json:"unavailableItemCount"`
+	Page[StoryItem]
+	UnavailableItemCount int64 `json:"unavailableItemCount"`
+	Bedtime              bool  `json:"bedtime,omitempty"`
 }
 
 type LibraryProgressSummary struct {
@@ -49,12 +173,17 @@ type LibraryProgressSummary struct {
 }
 
 type ReaderStory struct {
-	Slug     string          `json:"slug"`
-	Title    string          `json:"title"`
-	Author   *string         `json:"author"`
-	Language string          `json:"language"`
-	Version  int             `json:"version"`
-	Segments []ReaderSegment `json:"segments"`
+	Slug         string             `json:"slug"`
+	Title        string             `json:"title"`
+	Author       *string            `json:"author"`
+	Contributors []StoryContributor `json:"contributors"`
+	Language     string             `json:"language"`
+	Version      int                `json:"version"`
+	Readability  *Readability       `json:"readability"`
+	Segments     []ReaderSegment    `json:"segments"`
+
+	// ChangedAt is when anything above last changed, for Last-Modified.
+	ChangedAt time.Time `json:"-"`
 }
 
 type ReaderSegment struct {
@@ -67,6 +196,21 @@ type ReaderSegment struct {
 	ChapterOccurrence *int    `json:"chapterOccurrence"`
 	RenderedHTML      string  `json:"renderedHtml"`
 	WordCount         int     `json:"wordCount"`
+	// Speaker is set on dialogue paragraphs for read-aloud voice switching.
+	Speaker *string `json:"speaker,omitempty"`
+	// Pronunciations lists the segment's pronunciation hints in order.
+	Pronunciations []ReaderPronunciation `json:"pronunciations,omitempty"`
+	// Audio is the segment's narration for read-aloud playback, once the
+	// version has been narrated.
+	Audio *SegmentAudio `json:"audio,omitempty"`
+}
+
+// ReaderPronunciation tells readers and TTS engines how to say Text. Say is
+// a respelling such as her-MY-oh-nee; IPA is optional.
+type ReaderPronunciation struct {
+	Text string `json:"text"`
+	Say  string `json:"say"`
+	IPA  string `json:"ipa,omitempty"`
 }
 
 type Progress struct {
@@ -85,3 +229,10 @@ type ContinueItem struct {
 	Percent   float64   `json:"percent"`
 	UpdatedAt time.Time `json:"updatedAt"`
 }
+
+// ContinueResponse is the whole recent list; it is never more than one page.
+// UpNext is the profile's queue, so the home screen needs one request.
+type ContinueResponse struct {
+	Page[ContinueItem]
+	UpNext []QueueItem `json:"upNext"`
+}
@@ -0,0 +1,33 @@
+package model
+
+// ModerationVerdict is what moderation decided about a generated version. A
+// blocked version cannot be published; a flagged one only once a publisher
+// acknowledges the findings.
+type ModerationVerdict string
+
+const (
+	ModerationPassed  ModerationVerdict = "passed"
+	ModerationFlagged ModerationVerdict = "flagged"
+	ModerationBlocked ModerationVerdict = "blocked"
+)
+
+// ModerationSourceProvider marks a finding from the moderation provider; the
+// sensitivity sources mark terms the text matched.
+const ModerationSourceProvider = "provider"
+
+// ModerationFinding is one reason for a verdict: a category the provider
+// flagged, or a sensitivity term and how often the text used it.
+type ModerationFinding struct {
+	Source   string `json:"source"`
+	Category string `json:"category"`
+	Count    int    `json:"count,omitempty"`
+}
+
+// StoryModeration is the verdict stored on a generated version. Provider is
+// nil when only the sensitivity rules ran.
+type StoryModeration struct {
+	Verdict     ModerationVerdict   `json:"verdict"`
+	Provider    *string             `json:"provider"`
+	Findings    []ModerationFinding `json:"findings"`
+	ModeratedAt string              `json:"moderatedAt"`
+}
@@ -0,0 +1,95 @@
+package model
+
+import "time"
+
+const (
+	// NarrationAudioType is the only audio format stored. Every provider is
+	// asked for WAV, whose header gives the duration without decoding.
+	NarrationAudioType = "audio/wav"
+	// AudioPath serves a segment's narration by its audio ID.
+	AudioPath = "/api/v1/audio/"
+)
+
+// NarrationJob reads every narratable segment of one story version aloud. It
+// moves through the same statuses as a RenderJob.
+type NarrationJob struct {
+	ID               string          `json:"id"`
+	Slug             string          `json:"slug"`
+	VersionID        string          `json:"versionId"`
+	Voice            string          `json:"voice"`
+	Status           RenderJobStatus `json:"status"`
+	TotalSegments    int             `json:"totalSegments"`
+	NarratedSegments int             `json:"narratedSegments"`
+	Error            *string         `json:"error"`
+	CreatedAt        string          `json:"createdAt"`
+	UpdatedAt        string          `json:"updatedAt"`
+	FinishedAt       *string         `json:"finishedAt"`
+
+	// AccountID and CursorOrdinal are for the worker; clients follow
+	// progress through the counts.
+	AccountID     string `json:"-"`
+	CursorOrdinal int    `json:"-"`
+}
+
+// NarrationSegment is one segment a narration job has still to read: its
+// rendered HTML and pronunciation hints, from which the TTS script is made.
+type NarrationSegment struct {
+	Ordinal        int
+	RenderedHTML   string
+	Pronunciations []ReaderPronunciation
+}
+
+// NarrationAudio is one segment's recording as a provider returned it. A
+// stored recording kept in the blob store has BlobKey instead of Data.
+type NarrationAudio struct {
+	ContentType string
+	Data        []byte
+	Duration    time.Duration
+	BlobKey     string
+}
+
+// SegmentAudio is where the Reader loads a segment's narration from. Words
+// times each word of the segment's text in the recording, once it has been
+// aligned, so the Reader can highlight words as they are spoken.
+type SegmentAudio struct {
+	URL        string       `json:"url"`
+	DurationMs int          `json:"durationMs"`
+	Words      []WordTiming `json:"words,omitempty"`
+}
+
+// WordTiming is when one word of a segment is spoken, in milliseconds from
+// the start of its recording. Words are the text content of the segment's
+// rendered HTML split at whitespace, in order, with their punctuation.
+type WordTiming struct {
+	Text    string `json:"text"`
+	StartMs int    `json:"startMs"`
+	EndMs   int    `json:"endMs"`
+}
+
+// AlignmentJob times the words of every narrated segment of one story
+// version. It moves through the same statuses as a RenderJob.
+type AlignmentJob struct {
+	ID              string          `json:"id"`
+	Slug            string          `json:"slug"`
+	VersionID       string          `json:"versionId"`
+	Status          RenderJobStatus `json:"status"`
+	TotalSegments   int             `json:"totalSegments"`
+	AlignedSegments int             `json:"alignedSegments"`
+	Error           *string         `json:"error"`
+	CreatedAt       string          `json:"createdAt"`
+	UpdatedAt       string          `json:"updatedAt"`
+	FinishedAt      *string         `json:"finishedAt"`
+
+	// AccountID and CursorOrdinal are for the worker; clients follow
+	// progress through the counts.
+	AccountID     string `json:"-"`
+	CursorOrdinal int    `json:"-"`
+}
+
+// AlignmentSegment is one narrated segment an alignment job has still to
+// time: its text, as narration read it, and its recording.
+type AlignmentSegment struct {
+	NarrationSegment
+	AudioID string
+	Audio   NarrationAudio
+}
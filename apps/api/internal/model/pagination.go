@@ -0,0 +1,51 @@
+package model
+
+import (
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidCursor reports a page cursor the list did not issue.
+var ErrInvalidCursor = errors.New("invalid page cursor")
+
+// PageRequest asks for one page of a keyset-paginated list. An empty Cursor
+// starts at the first page and a zero Limit uses the list's default; lists
+// cap larger limits rather than rejecting them. Total asks for the number of
+// items across every page, which costs the list a count.
+type PageRequest struct {
+	Cursor string
+	Limit  int
+	Total  bool
+}
+
+// ParsePageRequest reads the cursor, limit and total query parameters shared
+// by every paginated list. Cursors are validated by the list that issued them.
+func ParsePageRequest(query url.Values) (PageRequest, bool) {
+	page := PageRequest{Cursor: strings.TrimSpace(query.Get("cursor"))}
+	if raw := strings.TrimSpace(query.Get("limit")); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 {
+			return PageRequest{}, false
+		}
+		page.Limit = limit
+	}
+	if raw := strings.TrimSpace(query.Get("total")); raw != "" {
+		total, err := strconv.ParseBool(raw)
+		if err != nil {
+			return PageRequest{}, false
+		}
+		page.Total = total
+	}
+	return page, true
+}
+
+// Page is the envelope every list response shares. NextCursor is nil on the
+// last page. Total is the number of items across every page, sent only when
+// the request asked for it.
+type Page[T any] struct {
+	Items      []T     `json:"items"`
+	NextCursor *string `json:"nextCursor"`
+	Total      *int64  `json:"total,omitempty"`
+}
@@ -0,0 +1,61 @@
+package model
+
+// PhonicsProgression is the order in which a phonics scheme teaches
+// letter-sound correspondences, as numbered sets. A child who has been taught
+// sets 1 to n can sound out a word spelled with those sets' graphemes, and is
+// taught the sets' tricky words, which do not follow them, by sight.
+type PhonicsProgression struct {
+	Name     string       `json:"name"`
+	Language string       `json:"language"`
+	Sets     []PhonicsSet `json:"sets"`
+}
+
+type PhonicsSet struct {
+	Name        string   `json:"name"`
+	Graphemes   []string `json:"graphemes"`
+	TrickyWords []string `json:"trickyWords"`
+}
+
+// PhonicsAnalysis scores a story version against a progression. Sets has one
+// entry per set, giving the share of the version's words a child taught it
+// and every set before could read. DecodableSet is the first set at which
+// that share reaches the decodable threshold, and nil when none does.
+// Undecodable lists the most frequent words no set covers.
+type PhonicsAnalysis struct {
+	Progression  string            `json:"progression"`
+	Words        int               `json:"words"`
+	DecodableSet *int              `json:"decodableSet"`
+	Sets         []PhonicsSetScore `json:"sets"`
+	Undecodable  []string          `json:"undecodable"`
+}
+
+type PhonicsSetScore struct {
+	Set            int     `json:"set"`
+	DecodableShare float64 `json:"decodableShare"`
+}
+
+// PhonicsSource is a story version waiting to be scored: the word
+// frequencies stored with its vocabulary.
+type PhonicsSource struct {
+	VersionID   string
+	AccountID   string
+	Frequencies map[string]int
+}
+
+// LibraryFilter narrows the Library. Progression is the key of the
+// configured phonics progression; DecodableSet, when above zero, keeps only
+// stories whose published version is decodable by that set.
+// BedtimeMaxWords, when above zero, keeps only calm stories of at most that
+// many words; see BedtimeSettings.
+type LibraryFilter struct {
+	Progression     string
+	DecodableSet    int
+	BedtimeMaxWords int
+}
+
+// AdminPhonicsResponse is a story version's analysis as the Studio shows it.
+type AdminPhonicsResponse struct {
+	Slug      string `json:"slug"`
+	VersionID string `json:"versionId"`
+	PhonicsAnalysis
+}
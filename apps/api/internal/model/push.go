@@ -0,0 +1,106 @@
+package model
+
+import "time"
+
+const (
+	// MaxPushSubscriptions bounds how many browsers one account may keep
+	// subscribed.
+	MaxPushSubscriptions = 20
+	// MaxPushEndpointBytes bounds a push service URL; real ones are a few
+	// hundred bytes.
+	MaxPushEndpointBytes = 2048
+)
+
+// PushKey is the VAPID public key browsers pass to pushManager.subscribe as
+// applicationServerKey, base64url encoded.
+type PushKey struct {
+	PublicKey string `json:"publicKey"`
+}
+
+// PushSubscriptionKeys are the browser's encryption keys, base64url encoded
+// as PushSubscription.toJSON gives them.
+type PushSubscriptionKeys struct {
+	P256DH string `json:"p256dh"`
+	Auth   string `json:"auth"`
+}
+
+// PushSubscribeRequest is a browser's PushSubscription.toJSON.
+type PushSubscribeRequest struct {
+	Endpoint string               `json:"endpoint"`
+	Keys     PushSubscriptionKeys `json:"keys"`
+}
+
+type PushUnsubscribeRequest struct {
+	Endpoint string `json:"endpoint"`
+}
+
+// PushSubscription is one browser the account's notifications go to. The
+// keys are decoded and checked before they are stored.
+type PushSubscription struct {
+	ID        string `json:"id"`
+	CreatedAt string `json:"createdAt"`
+	// Created is false when the browser was already subscribed.
+	Created bool `json:"-"`
+}
+
+// PushSubscriptionSave is a validated subscription for the Store.
+type PushSubscriptionSave struct {
+	Endpoint string
+	P256DH   []byte
+	Auth     []byte
+}
+
+// PushSettings chooses the account's notifications. A bedtime reminder is
+// sent once a day at ReminderTime, HH:MM in TimeZone, an IANA name such as
+// Europe/London; NewStories announces each story published for the first
+// time.
+type PushSettings struct {
+	Reminders    bool   `json:"reminders"`
+	ReminderTime string `json:"reminderTime"`
+	TimeZone     string `json:"timeZone"`
+	NewStories   bool   `json:"newStories"`
+}
+
+// DefaultPushSettings apply to an account that has not chosen its own.
+var DefaultPushSettings = PushSettings{Reminders: false, ReminderTime: "19:00", TimeZone: "UTC", NewStories: true}
+
+type PushKind string
+
+const (
+	PushKindReminder PushKind = "reminder"
+	PushKindStory    PushKind = "story"
+)
+
+// PendingPush is a claimed notification for one browser, as the dispatcher
+// needs it. Slug is set for a story notification.
+type PendingPush struct {
+	ID        string
+	AccountID string
+	Kind      PushKind
+	Slug      string
+	Endpoint  string
+	P256DH    []byte
+	Auth      []byte
+	Attempts  int
+	CreatedAt time.Time
+}
+
+// PushAttempt records one send. Gone means the push service no longer knows
+// the subscription, which is then removed. A failed attempt with
+// NextAttemptAt is retried then; without it the notification is dropped.
+type PushAttempt struct {
+	Sent          bool
+	Gone          bool
+	NextAttemptAt *time.Time
+}
+
+// PushNotification is the payload a browser's service worker receives and
+// shows with showNotification.
+type PushNotification struct {
+	Kind  PushKind `json:"kind"`
+	Title string   `json:"title"`
+	Body  string   `json:"body"`
+	URL   string   `json:"url"`
+	// Tag lets a newer notification of the same kind replace an older one.
+	Tag string `json:"tag"`
+}
@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+// MaxQueueItems bounds how many stories one profile may line up.
+const MaxQueueItems = 50
+
+// QueueItem is a story lined up for the child to read next.
+type QueueItem struct {
+	Slug    string    `json:"slug"`
+	Title   string    `json:"title"`
+	AddedAt time.Time `json:"addedAt"`
+}
+
+// QueueResponse is the whole up-next queue, first story first; it is never
+// more than one page.
+type QueueResponse = Page[QueueItem]
+
+// QueueReorderRequest names every queued story once, in its new order.
+type QueueReorderRequest struct {
+	Slugs []string `json:"slugs"`
+}
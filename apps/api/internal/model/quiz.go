@@ -0,0 +1,96 @@
+package model
+
+// The language model writes MinQuizQuestions to MaxQuizQuestions questions
+// per chapter; an editor may trim a quiz to fewer but not add more.
+const (
+	MinQuizQuestions   = 3
+	MaxQuizQuestions   = 5
+	MaxQuizQuestionLen = 300
+	MaxQuizAnswerLen   = 300
+)
+
+// QuizStatus is a chapter quiz's review state. Readers see approved quizzes
+// only.
+type QuizStatus string
+
+const (
+	QuizDraft    QuizStatus = "draft"
+	QuizApproved QuizStatus = "approved"
+)
+
+type QuizQuestion struct {
+	Question string `json:"question"`
+	Answer   string `json:"answer"`
+}
+
+// ChapterQuiz is the quiz for one chapter, numbered from 1 in reading order.
+// ChapterKey and ChapterOccurrence are the chapter's Reader identity, as on
+// its segments; both are nil for a story without chapters.
+type ChapterQuiz struct {
+	Chapter           int            `json:"chapter"`
+	ChapterKey        *string        `json:"chapterKey"`
+	ChapterOccurrence *int           `json:"chapterOccurrence"`
+	Title             *string        `json:"title"`
+	Questions         []QuizQuestion `json:"questions"`
+}
+
+// StoryQuiz is the approved quizzes of a story's published version, in
+// chapter order.
+type StoryQuiz struct {
+	Slug     string        `json:"slug"`
+	Version  int           `json:"version"`
+	Chapters []ChapterQuiz `json:"chapters"`
+}
+
+// QuizChapterSource is the text of one chapter, as a quiz is written from
+// it.
+type QuizChapterSource struct {
+	Chapter           int
+	ChapterCount      int
+	ChapterKey        *string
+	ChapterOccurrence *int
+	Title             *string
+	Markdown          string
+}
+
+// AdminChapterQuiz is one chapter as the Studio reviews it. A chapter with
+// no quiz yet has a nil Status and no questions; Model and PromptVersion are
+// set on questions the language model wrote and cleared when an editor
+// rewrites them.
+type AdminChapterQuiz struct {
+	ChapterQuiz
+	Status        *QuizStatus `json:"status"`
+	Model         *string     `json:"model"`
+	PromptVersion *string     `json:"promptVersion"`
+	UpdatedAt     *string     `json:"updatedAt"`
+}
+
+type AdminQuizResponse struct {
+	Slug      string             `json:"slug"`
+	VersionID string             `json:"versionId"`
+	Chapters  []AdminChapterQuiz `json:"chapters"`
+}
+
+// AdminQuizGenerateRequest asks for draft questions for one chapter. An
+// empty VersionID uses the published version, else the draft.
+type AdminQuizGenerateRequest struct {
+	VersionID string `json:"versionId,omitempty"`
+	Chapter   int    `json:"chapter"`
+}
+
+// AdminQuizUpdate replaces a chapter's questions and sets its review state.
+// An empty VersionID uses the published version, else the draft.
+type AdminQuizUpdate struct {
+	VersionID string         `json:"versionId,omitempty"`
+	Questions []QuizQuestion `json:"questions"`
+	Status    QuizStatus     `json:"status"`
+}
+
+// QuizSave is a chapter quiz as written to storage. Model and PromptVersion
+// are empty for questions written by hand.
+type QuizSave struct {
+	Questions     []QuizQuestion
+	Status        QuizStatus
+	Model         string
+	PromptVersion string
+}
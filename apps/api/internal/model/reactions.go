@@ -0,0 +1,38 @@
+package model
+
+// StoryReaction is a child's one-tap feeling about a story.
+type StoryReaction string
+
+const (
+	ReactionLovedIt StoryReaction = "loved_it"
+	ReactionSilly   StoryReaction = "silly"
+	ReactionScary   StoryReaction = "scary"
+)
+
+// Valid reports whether r is one of the known reactions.
+func (r StoryReaction) Valid() bool {
+	switch r {
+	case ReactionLovedIt, ReactionSilly, ReactionScary:
+		return true
+	}
+	return false
+}
+
+// ReactionCounts counts a story's reactions across the account's profiles.
+type ReactionCounts struct {
+	LovedIt int64 `json:"lovedIt"`
+	Silly   int64 `json:"silly"`
+	Scary   int64 `json:"scary"`
+}
+
+// StoryReactionRequest is the body of POST /api/v1/story/{slug}/reaction.
+type StoryReactionRequest struct {
+	Reaction StoryReaction `json:"reaction"`
+}
+
+// StoryReactionResponse is the profile's reaction, nil once taken back, and
+// the story's counts after the change.
+type StoryReactionResponse struct {
+	Reaction *StoryReaction `json:"reaction"`
+	Counts   ReactionCounts `json:"counts"`
+}
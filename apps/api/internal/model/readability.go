@@ -0,0 +1,14 @@
+package model
+
+// Readability is stored per story version. It is nil for versions ingested
+// before readability metrics existed.
+type Readability struct {
+	Words               int     `json:"words"`
+	Sentences           int     `json:"sentences"`
+	Syllables           int     `json:"syllables"`
+	AvgWordsPerSentence float64 `json:"avgWordsPerSentence"`
+	AvgSyllablesPerWord float64 `json:"avgSyllablesPerWord"`
+	AvgWordLength       float64 `json:"avgWordLength"`
+	FleschReadingEase   float64 `json:"fleschReadingEase"`
+	FleschKincaidGrade  float64 `json:"fleschKincaidGrade"`
+}
@@ -0,0 +1,41 @@
+package model
+
+type RenderJobStatus string
+
+const (
+	RenderJobQueued    RenderJobStatus = "queued"
+	RenderJobRunning   RenderJobStatus = "running"
+	RenderJobCompleted RenderJobStatus = "completed"
+	RenderJobFailed    RenderJobStatus = "failed"
+)
+
+// RenderJob re-renders every stored version of an account from its stored
+// Markdown. Each version is either rendered (its HTML changed and was
+// swapped), unchanged, or skipped because the current renderer would change
+// its segment identities, which would strand saved reading positions.
+type RenderJob struct {
+	ID                string          `json:"id"`
+	Status            RenderJobStatus `json:"status"`
+	TotalVersions     int             `json:"totalVersions"`
+	RenderedVersions  int             `json:"renderedVersions"`
+	UnchangedVersions int             `json:"unchangedVersions"`
+	SkippedVersions   int             `json:"skippedVersions"`
+	Error             *string         `json:"error"`
+	CreatedAt         string          `json:"createdAt"`
+	UpdatedAt         string          `json:"updatedAt"`
+	FinishedAt        *string         `json:"finishedAt"`
+
+	// AccountID and CursorVersionID are for the worker; clients follow
+	// progress through the counts.
+	AccountID       string `json:"-"`
+	CursorVersionID string `json:"-"`
+}
+
+// RenderOutcome is what one version's re-render did.
+type RenderOutcome int
+
+const (
+	RenderUnchanged RenderOutcome = iota
+	RenderRendered
+	RenderSkipped
+)
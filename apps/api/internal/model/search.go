@@ -0,0 +1,44 @@
+package model
+
+// EmbeddingSource is a published version as the embedding worker reads it.
+type EmbeddingSource struct {
+	VersionID string
+	AccountID string
+	Title     string
+	Markdown  string
+}
+
+// SemanticSearchResult is a published story ranked by how close its meaning
+// is to the query. Score is the cosine similarity, at most 1; how high a
+// good match scores depends on the embedding model.
+type SemanticSearchResult struct {
+	Slug     string  `json:"slug"`
+	Title    string  `json:"title"`
+	Author   *string `json:"author"`
+	Language string  `json:"language"`
+	Score    float64 `json:"score"`
+}
+
+type SemanticSearchResponse struct {
+	Items []SemanticSearchResult `json:"items"`
+}
+
+// SearchReindexJob embeds the published versions of an account's stories,
+// or only of Slug, again, replacing their vectors. It moves through the same
+// statuses as a RenderJob.
+type SearchReindexJob struct {
+	ID              string          `json:"id"`
+	Slug            string          `json:"slug,omitempty"`
+	Status          RenderJobStatus `json:"status"`
+	TotalVersions   int             `json:"totalVersions"`
+	IndexedVersions int             `json:"indexedVersions"`
+	Error           *string         `json:"error"`
+	CreatedAt       string          `json:"createdAt"`
+	UpdatedAt       string          `json:"updatedAt"`
+	FinishedAt      *string         `json:"finishedAt"`
+
+	// AccountID and CursorVersionID are for the worker; clients follow
+	// progress through the counts.
+	AccountID       string `json:"-"`
+	CursorVersionID string `json:"-"`
+}
@@ -0,0 +1,25 @@
+package model
+
+// The reader ages a story can be simplified for.
+const (
+	MinSimplifyAge = 3
+	MaxSimplifyAge = 12
+)
+
+// AdminSimplifyRequest asks for a story's published version rewritten for a
+// reader of Age. An empty Slug appends the age to the original's slug, as in
+// "the-night-train-age-5".
+type AdminSimplifyRequest struct {
+	Age  int    `json:"age"`
+	Slug string `json:"slug,omitempty"`
+}
+
+// AdminSimplifyResponse is the simplified draft and the story it was
+// rewritten from.
+type AdminSimplifyResponse struct {
+	AdminDraftUpsertResponse
+	SimplifiedFrom string          `json:"simplifiedFrom"`
+	Age            int             `json:"age"`
+	Generation     StoryGeneration `json:"generation"`
+	Moderation     StoryModeration `json:"moderation"`
+}
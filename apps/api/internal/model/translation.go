@@ -0,0 +1,27 @@
+package model
+
+// AdminTranslateRequest asks for a machine translation of a story into
+// Language. An empty Slug appends the language to the original's slug; an
+// empty VersionID translates the published version, or the draft of a story
+// that was never published.
+type AdminTranslateRequest struct {
+	Language  string `json:"language"`
+	Slug      string `json:"slug,omitempty"`
+	VersionID string `json:"versionId,omitempty"`
+}
+
+// AdminTranslateResponse is the translated draft. TranslationOf is the slug
+// of the story its language group is anchored on, which is the original's
+// original when a translation was translated again.
+type AdminTranslateResponse struct {
+	AdminDraftUpsertResponse
+	TranslationOf string          `json:"translationOf"`
+	Generation    StoryGeneration `json:"generation"`
+	Moderation    StoryModeration `json:"moderation"`
+}
+
+// StoryVariant is another published language of a Library story.
+type StoryVariant struct {
+	Slug     string `json:"slug"`
+	Language string `json:"language"`
+}
@@ -0,0 +1,12 @@
+package model
+
+// Vocabulary is stored per story version so vocabulary and quiz features can
+// read word counts without re-tokenizing the story. Frequencies keeps the most
+// frequent lowercased words; RareWords lists words outside the common-words
+// list, most frequent first, and is empty for non-English stories.
+type Vocabulary struct {
+	Words         int            `json:"words"`
+	DistinctWords int            `json:"distinctWords"`
+	Frequencies   map[string]int `json:"frequencies"`
+	RareWords     []string       `json:"rareWords"`
+}
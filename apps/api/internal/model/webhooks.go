@@ -0,0 +1,137 @@
+package model
+
+import "time"
+
+type WebhookEvent string
+
+const (
+	WebhookEventStoryPublished    WebhookEvent = "story.published"
+	WebhookEventStoryUnpublished  WebhookEvent = "story.unpublished"
+	WebhookEventProgressCompleted WebhookEvent = "progress.completed"
+	WebhookEventChildUpdated      WebhookEvent = "child.updated"
+)
+
+// WebhookEvents lists every subscribable event in a stable order.
+var WebhookEvents = []WebhookEvent{
+	WebhookEventStoryPublished,
+	WebhookEventStoryUnpublished,
+	WebhookEventProgressCompleted,
+	WebhookEventChildUpdated,
+}
+
+func (e WebhookEvent) Valid() bool {
+	for _, event := range WebhookEvents {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliverySucceeded WebhookDeliveryStatus = "succeeded"
+	WebhookDeliveryFailed    WebhookDeliveryStatus = "failed"
+)
+
+// AdminWebhook never carries the signing secret.
+type AdminWebhook struct {
+	ID        string         `json:"id"`
+	URL       string         `json:"url"`
+	Events    []WebhookEvent `json:"events"`
+	Active    bool           `json:"active"`
+	CreatedAt string         `json:"createdAt"`
+	UpdatedAt string         `json:"updatedAt"`
+}
+
+type AdminWebhookCreate struct {
+	URL    string
+	Events []WebhookEvent
+	Secret string
+}
+
+// AdminWebhookUpdate changes only the fields that are set.
+type AdminWebhookUpdate struct {
+	URL    *string
+	Events []WebhookEvent
+	Active *bool
+}
+
+type AdminWebhooksListResponse struct {
+	Items []AdminWebhook `json:"items"`
+}
+
+// AdminWebhookCreateResponse is the only response that ever contains a
+// webhook secret.
+type AdminWebhookCreateResponse struct {
+	Webhook AdminWebhook `json:"webhook"`
+	Secret  string       `json:"secret"`
+}
+
+type AdminWebhookDelivery struct {
+	ID             string                `json:"id"`
+	Event          WebhookEvent          `json:"event"`
+	Status         WebhookDeliveryStatus `json:"status"`
+	Attempts       int                   `json:"attempts"`
+	LastStatusCode *int                  `json:"lastStatusCode"`
+	LastError      *string               `json:"lastError"`
+	CreatedAt      string                `json:"createdAt"`
+	LastAttemptAt  *string               `json:"lastAttemptAt"`
+	NextAttemptAt  *string               `json:"nextAttemptAt"`
+}
+
+type AdminWebhookDeliveriesResponse struct {
+	WebhookID string                 `json:"webhookId"`
+	Items     []AdminWebhookDelivery `json:"items"`
+}
+
+// WebhookPayload is the JSON body of every delivery. Exactly one of the
+// subject fields is set, chosen by the event's prefix.
+type WebhookPayload struct {
+	Event      WebhookEvent          `json:"event"`
+	OccurredAt string                `json:"occurredAt"`
+	Story      *WebhookStoryState    `json:"story,omitempty"`
+	Progress   *WebhookProgressState `json:"progress,omitempty"`
+	Child      *WebhookChildState    `json:"child,omitempty"`
+}
+
+type WebhookStoryState struct {
+	Slug             string                      `json:"slug"`
+	Status           AdminStoryStatus            `json:"status"`
+	PublishedVersion *AdminVersionPointerSummary `json:"publishedVersion"`
+}
+
+// WebhookProgressState describes a story read to the end.
+type WebhookProgressState struct {
+	Slug    string  `json:"slug"`
+	Version int     `json:"version"`
+	Percent float64 `json:"percent"`
+}
+
+// WebhookChildState names the child profile that was created or changed.
+// Receivers read the profile itself through the settings API, so a delivery
+// never carries a child's name, age or sensitivities off-site.
+type WebhookChildState struct {
+	ID string `json:"id"`
+}
+
+// PendingWebhookDelivery is a claimed delivery handed to the dispatcher.
+type PendingWebhookDelivery struct {
+	ID       string
+	URL      string
+	Secret   string
+	Event    WebhookEvent
+	Payload  []byte
+	Attempts int
+}
+
+// WebhookAttempt is the outcome of one delivery attempt. A nil NextAttemptAt
+// on a failed attempt means retries are exhausted.
+type WebhookAttempt struct {
+	Succeeded     bool
+	StatusCode    int
+	Error         string
+	NextAttemptAt *time.Time
+}
@@ -0,0 +1,219 @@
+// Package moderation reviews stories a language model wrote before they can be
+// published. A Provider, such as OpenAI's moderation endpoint, finds harmful
+// text; Review adds whole-word checks against the child's sensitivities and
+// the deployment word list, and decides the verdict stored on the version.
+package moderation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"pandapages/api/internal/model"
+	"pandapages/api/internal/providerlog"
+	"pandapages/api/internal/sensitivity"
+)
+
+const (
+	ProviderOpenAI = "openai"
+
+	defaultOpenAIURL   = "https://api.openai.com/v1/moderations"
+	defaultOpenAIModel = "omni-moderation-latest"
+	requestTimeout     = 30 * time.Second
+	// maxResponseBytes bounds a reply; one result with its scores is a few
+	// kilobytes.
+	maxResponseBytes = 1 << 20
+)
+
+// unavailable is the finding a failed provider leaves, so an unchecked story
+// still needs a publisher's acknowledgement.
+const unavailable = "unavailable"
+
+// ErrProvider marks a provider that answered with an error status or a reply
+// that could not be used. Its message never carries the provider's response.
+var ErrProvider = errors.New("moderation provider failed")
+
+type Provider interface {
+	// Name identifies the provider in logs, the startup summary and stored
+	// verdicts.
+	Name() string
+	// Moderate returns the categories of harm the provider found in text,
+	// none when it passed.
+	Moderate(ctx context.Context, text string) ([]string, error)
+}
+
+// Load builds the provider PP_MODERATION_PROVIDER names. It returns nil, and
+// no error, when none is configured; generated stories are then checked
+// against the sensitivity terms alone.
+func Load(getenv func(string) string) (Provider, error) {
+	name := strings.ToLower(strings.TrimSpace(getenv("PP_MODERATION_PROVIDER")))
+	endpoint := strings.TrimSpace(getenv("PP_MODERATION_URL"))
+	apiKey := strings.TrimSpace(getenv("PP_MODERATION_API_KEY"))
+	modelName := strings.TrimSpace(getenv("PP_MODERATION_MODEL"))
+	if endpoint != "" {
+		if parsed, err := url.Parse(endpoint); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return nil, fmt.Errorf("PP_MODERATION_URL must be an http or https URL")
+		}
+	}
+
+	switch name {
+	case "":
+		return nil, nil
+	case ProviderOpenAI:
+		if apiKey == "" && endpoint == "" {
+			return nil, fmt.Errorf("PP_MODERATION_API_KEY is required for the openai provider")
+		}
+		if endpoint == "" {
+			endpoint = defaultOpenAIURL
+		}
+		if modelName == "" {
+			modelName = defaultOpenAIModel
+		}
+		return &OpenAI{URL: endpoint, APIKey: apiKey, ModelName: modelName, client: &http.Client{Timeout: requestTimeout}}, nil
+	default:
+		return nil, fmt.Errorf("PP_MODERATION_PROVIDER must be openai")
+	}
+}
+
+// OpenAI is OpenAI's moderation endpoint, or any service that copies its API.
+type OpenAI struct {
+	URL       string
+	APIKey    string
+	ModelName string
+	client    *http.Client
+}
+
+func (p *OpenAI) Name() string { return ProviderOpenAI }
+
+func (p *OpenAI) Moderate(ctx context.Context, text string) ([]string, error) {
+	raw, err := json.Marshal(struct {
+		Model string `json:"model"`
+		Input string `json:"input"`
+	}{Model: p.ModelName, Input: text})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.URL, bytes.NewReader(raw))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "pandapages-moderation/1")
+	if p.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.APIKey)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("%w: status %d", ErrProvider, resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxResponseBytes {
+		return nil, fmt.Errorf("%w: reply exceeds %d bytes", ErrProvider, maxResponseBytes)
+	}
+	var reply struct {
+		Results []struct {
+			Flagged    bool            `json:"flagged"`
+			Categories map[string]bool `json:"categories"`
+		} `json:"results"`
+	}
+	if err := json.Unmarshal(data, &reply); err != nil {
+		return nil, fmt.Errorf("%w: reply is not JSON", ErrProvider)
+	}
+	if len(reply.Results) == 0 {
+		return nil, fmt.Errorf("%w: reply has no result", ErrProvider)
+	}
+	categories := []string{}
+	for _, result := range reply.Results {
+		for category, flagged := range result.Categories {
+			if flagged && !contains(categories, category) {
+				categories = append(categories, category)
+			}
+		}
+		// A result flagged without naming a category still blocks.
+		if result.Flagged && len(categories) == 0 {
+			categories = append(categories, "flagged")
+		}
+	}
+	sort.Strings(categories)
+	return categories, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Review moderates a generated story. Categories the provider finds block
+// it; sensitivity terms it uses flag it for a publisher to review, as does a
+// provider that fails, so a story is never passed unchecked. A nil provider
+// leaves the terms alone to decide.
+func Review(ctx context.Context, provider Provider, title, markdown string, terms []sensitivity.Term, now time.Time) model.StoryModeration {
+	text := strings.TrimSpace(title) + "\n\n" + markdown
+	out := model.StoryModeration{
+		Verdict:     model.ModerationPassed,
+		Findings:    []model.ModerationFinding{},
+		ModeratedAt: now.UTC().Format(time.RFC3339Nano),
+	}
+
+	if provider != nil {
+		name := provider.Name()
+		out.Provider = &name
+		categories, err := provider.Moderate(ctx, text)
+		if err != nil {
+			providerlog.Failure("story moderation failed", name)
+			categories = nil
+			out.Verdict = model.ModerationFlagged
+			out.Findings = append(out.Findings, model.ModerationFinding{Source: model.ModerationSourceProvider, Category: unavailable})
+		}
+		for _, category := range categories {
+			out.Verdict = model.ModerationBlocked
+			out.Findings = append(out.Findings, model.ModerationFinding{Source: model.ModerationSourceProvider, Category: category})
+		}
+	}
+
+	// The story is scanned as one segment: a finding names the term, and
+	// the version's sensitivity report places it.
+	matches := sensitivity.Scan([]sensitivity.Segment{{RenderedHTML: html.EscapeString(text)}}, terms)
+	for _, match := range matches {
+		out.Findings = append(out.Findings, model.ModerationFinding{Source: match.Source, Category: match.Term, Count: match.Count})
+	}
+	if len(matches) > 0 && out.Verdict == model.ModerationPassed {
+		out.Verdict = model.ModerationFlagged
+	}
+	return out
+}
+
+// Terms lists a child's sensitivities ahead of the deployment word list, as
+// the sensitivity report does.
+func Terms(sensitivities, words []string) []sensitivity.Term {
+	terms := make([]sensitivity.Term, 0, len(sensitivities)+len(words))
+	for _, text := range sensitivities {
+		terms = append(terms, sensitivity.Term{Text: text, Source: sensitivity.SourceChildProfile})
+	}
+	for _, text := range words {
+		terms = append(terms, sensitivity.Term{Text: text, Source: sensitivity.SourceWordList})
+	}
+	return terms
+}
@@ -0,0 +1,116 @@
+package moderation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"pandapages/api/internal/model"
+)
+
+func TestLoadBuildsTheConfiguredProvider(t *testing.T) {
+	env := func(values map[string]string) func(string) string {
+		return func(key string) string { return values[key] }
+	}
+	if provider, err := Load(env(nil)); err != nil || provider != nil {
+		t.Fatalf("unconfigured Load = %v, %v", provider, err)
+	}
+	provider, err := Load(env(map[string]string{"PP_MODERATION_PROVIDER": "OpenAI", "PP_MODERATION_API_KEY": "key"}))
+	openai, ok := provider.(*OpenAI)
+	if err != nil || !ok || openai.URL != defaultOpenAIURL || openai.ModelName != defaultOpenAIModel {
+		t.Fatalf("default Load = %#v, %v", provider, err)
+	}
+	if _, err := Load(env(map[string]string{"PP_MODERATION_PROVIDER": "openai", "PP_MODERATION_URL": "http://moderation:8080/v1/moderations"})); err != nil {
+		t.Fatalf("keyless Load: %v", err)
+	}
+	for name, values := range map[string]map[string]string{
+		"unknown": {"PP_MODERATION_PROVIDER": "perspective", "PP_MODERATION_API_KEY": "key"},
+		"key":     {"PP_MODERATION_PROVIDER": "openai"},
+		"url":     {"PP_MODERATION_PROVIDER": "openai", "PP_MODERATION_URL": "ftp://moderation"},
+	} {
+		if _, err := Load(env(values)); err == nil {
+			t.Errorf("%s: Load succeeded", name)
+		}
+	}
+}
+
+func TestOpenAIReturnsFlaggedCategories(t *testing.T) {
+	var body map[string]any
+	var authorization string
+	answer := `{"results":[{"flagged":true,"categories":{"violence":true,"self-harm":false,"harassment":true}}]}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(raw, &body)
+		authorization = r.Header.Get("Authorization")
+		_, _ = io.WriteString(w, answer)
+	}))
+	t.Cleanup(server.Close)
+	provider := &OpenAI{URL: server.URL, APIKey: "secret", ModelName: "omni", client: server.Client()}
+
+	categories, err := provider.Moderate(context.Background(), "A storm.")
+	if err != nil || strings.Join(categories, ",") != "harassment,violence" {
+		t.Fatalf("Moderate = %v, %v", categories, err)
+	}
+	if body["model"] != "omni" || body["input"] != "A storm." || authorization != "Bearer secret" {
+		t.Fatalf("request = %v, Authorization %q", body, authorization)
+	}
+
+	answer = `{"results":[{"flagged":false,"categories":{"violence":false}}]}`
+	if categories, err := provider.Moderate(context.Background(), "A calm sea."); err != nil || len(categories) != 0 {
+		t.Fatalf("passed Moderate = %v, %v", categories, err)
+	}
+	for _, reply := range []string{`{"results":[]}`, `<html>`} {
+		answer = reply
+		if _, err := provider.Moderate(context.Background(), "A storm."); !errors.Is(err, ErrProvider) {
+			t.Errorf("reply %s: error = %v, want ErrProvider", reply, err)
+		}
+	}
+}
+
+type stubProvider struct {
+	categories []string
+	err        error
+}
+
+func (p stubProvider) Name() string { return "stub" }
+
+func (p stubProvider) Moderate(context.Context, string) ([]string, error) {
+	return p.categories, p.err
+}
+
+func TestReviewDecidesTheVerdict(t *testing.T) {
+	now := time.Date(2026, 10, 17, 19, 0, 0, 0, time.UTC)
+	terms := Terms([]string{"spiders"}, []string{"storm", "Spiders"})
+
+	passed := Review(context.Background(), nil, "Night Train", "The train yawned.", terms, now)
+	if passed.Verdict != model.ModerationPassed || passed.Provider != nil || len(passed.Findings) != 0 ||
+		passed.ModeratedAt != "2026-10-17T19:00:00Z" {
+		t.Fatalf("passed = %#v", passed)
+	}
+
+	flagged := Review(context.Background(), stubProvider{}, "Storm <b>Night</b>", "Spiders & a storm, then spiders.", terms, now)
+	if flagged.Verdict != model.ModerationFlagged || flagged.Provider == nil || *flagged.Provider != "stub" ||
+		len(flagged.Findings) != 2 ||
+		flagged.Findings[0] != (model.ModerationFinding{Source: "child_profile", Category: "spiders", Count: 2}) ||
+		flagged.Findings[1] != (model.ModerationFinding{Source: "word_list", Category: "storm", Count: 2}) {
+		t.Fatalf("flagged = %#v", flagged)
+	}
+
+	blocked := Review(context.Background(), stubProvider{categories: []string{"violence"}}, "Storm", "Calm.", terms, now)
+	if blocked.Verdict != model.ModerationBlocked || len(blocked.Findings) != 2 ||
+		blocked.Findings[0] != (model.ModerationFinding{Source: model.ModerationSourceProvider, Category: "violence"}) {
+		t.Fatalf("blocked = %#v", blocked)
+	}
+
+	// A provider that fails never lets the story pass unchecked.
+	unchecked := Review(context.Background(), stubProvider{err: ErrProvider}, "Night Train", "Calm.", terms, now)
+	if unchecked.Verdict != model.ModerationFlagged || len(unchecked.Findings) != 1 || unchecked.Findings[0].Category != "unavailable" {
+		t.Fatalf("unchecked = %#v", unchecked)
+	}
+}
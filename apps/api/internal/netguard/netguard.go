@@ -0,0 +1,40 @@
+// Package netguard keeps connections to addresses that users choose, such as
+// a document to import or a browser's push endpoint, on the public internet,
+// so they cannot be used to reach the server's own network.
+package netguard
+
+import (
+	"errors"
+	"net"
+	"net/netip"
+	"syscall"
+)
+
+// ErrRefused marks a dial to an address that is not on the public internet.
+var ErrRefused = errors.New("address is not public")
+
+// cgnat is the carrier-grade NAT range, which netip counts as global.
+var cgnat = netip.MustParsePrefix("100.64.0.0/10")
+
+// PublicOnly is a net.Dialer Control that refuses every address but public
+// unicast ones. It runs on the address dialled, after name resolution, so a
+// name that resolves to a private address is refused too; a client using it
+// must not use a proxy, which would hide the real destination.
+func PublicOnly(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return ErrRefused
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil || !Public(ip) {
+		return ErrRefused
+	}
+	return nil
+}
+
+// Public reports whether ip is a public unicast address, for checking an
+// address written into a URL before anything dials it.
+func Public(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	return ip.IsGlobalUnicast() && !ip.IsPrivate() && !cgnat.Contains(ip)
+}
@@ -0,0 +1,274 @@
+// Package openapi describes the public and admin HTTP APIs as an OpenAPI 3
+// document, for generating typed clients.
+//
+// The operations are listed by hand in operations.go, and a test checks that
+// list against the routes httpapi and httpadmin actually mount. Request and
+// response schemas are generated from the model types the handlers decode and
+// encode, so a model change reaches the document without editing it.
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"pandapages/api/internal/idempotency"
+	"pandapages/api/internal/model"
+)
+
+// Version is the API version the document describes.
+const Version = "1.0.0"
+
+// Auth is the credential an operation needs.
+type Auth int
+
+const (
+	// AuthNone needs nothing: probes, unlock and this document.
+	AuthNone Auth = iota
+	// AuthSession needs the session cookie unlock issues.
+	AuthSession
+	// AuthAdmin needs the session and an admin key with one of the roles
+	// the operation's description names.
+	AuthAdmin
+	// AuthBootstrap needs the session and the deployment's PP_ADMIN_KEY.
+	AuthBootstrap
+)
+
+// Param is a query parameter.
+type Param struct {
+	Name        string
+	Type        string // "string", "integer" or "boolean"
+	Description string
+	Required    bool
+}
+
+// Operation is one method on one path. Path uses ServeMux pattern syntax,
+// whose {name} wildcards are also OpenAPI's path parameters.
+type Operation struct {
+	Method      string
+	Path        string
+	Tag         string
+	Summary     string
+	Description string
+	Auth        Auth
+	Query       []Param
+	// Idempotent operations accept an Idempotency-Key header.
+	Idempotent bool
+
+	// Request is a value of the JSON body's type, or nil for no body.
+	// RequestContentType replaces JSON for a raw body.
+	Request            any
+	RequestContentType string
+
+	// Status is the success status, 200 when zero. Response is a value of
+	// the JSON body's type, or nil for no body; ResponseContentType replaces
+	// JSON for a raw one.
+	Status              int
+	Response            any
+	ResponseContentType string
+}
+
+// Document returns the OpenAPI document as JSON. It is built once.
+var Document = sync.OnceValue(func() []byte {
+	raw, err := json.Marshal(build(Operations))
+	if err != nil {
+		panic(err) // every value is a map, slice or string
+	}
+	return raw
+})
+
+var pathParam = regexp.MustCompile(`\{([A-Za-z]+)\}`)
+
+func build(operations []Operation) map[string]any {
+	schemas := newSchemaBuilder()
+	schemas.components["Error"] = errorSchema
+	schemas.of(model.FieldError{})
+	schemas.of(model.AdminValidationIssue{})
+	schemas.of(model.RateLimit{})
+
+	paths := map[string]map[string]any{}
+	tags := map[string]bool{}
+	for _, op := range operations {
+		item := paths[op.Path]
+		if item == nil {
+			item = map[string]any{}
+			paths[op.Path] = item
+		}
+		tags[op.Tag] = true
+
+		operation := map[string]any{
+			"operationId": operationID(op),
+			"tags":        []string{op.Tag},
+			"summary":     op.Summary,
+			"responses":   responses(op, schemas),
+		}
+		if op.Description != "" {
+			operation["description"] = op.Description
+		}
+		if security := security(op.Auth); security != nil {
+			operation["security"] = security
+		}
+		var parameters []any
+		for _, match := range pathParam.FindAllStringSubmatch(op.Path, -1) {
+			parameters = append(parameters, map[string]any{
+				"name": match[1], "in": "path", "required": true, "schema": map[string]any{"type": "string"},
+			})
+		}
+		for _, param := range op.Query {
+			parameter := map[string]any{
+				"name": param.Name, "in": "query", "schema": map[string]any{"type": param.Type},
+			}
+			if param.Description != "" {
+				parameter["description"] = param.Description
+			}
+			if param.Required {
+				parameter["required"] = true
+			}
+			parameters = append(parameters, parameter)
+		}
+		if op.Idempotent {
+			parameters = append(parameters, idempotencyKeyParam)
+		}
+		if parameters != nil {
+			operation["parameters"] = parameters
+		}
+		if body := requestBody(op, schemas); body != nil {
+			operation["requestBody"] = body
+		}
+		item[strings.ToLower(op.Method)] = operation
+	}
+
+	tagNames := make([]string, 0, len(tags))
+	for tag := range tags {
+		tagNames = append(tagNames, tag)
+	}
+	sort.Strings(tagNames)
+	tagList := make([]any, 0, len(tagNames))
+	for _, tag := range tagNames {
+		tagList = append(tagList, map[string]any{"name": tag})
+	}
+
+	return map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":       "Panda Pages API",
+			"version":     Version,
+			"description": "The reader API under /api/v1 and the admin API under /api/v1/admin. Errors share one body: {\"error\": {\"code\", \"message\", \"requestId\"}}.",
+		},
+		"tags":  tagList,
+		"paths": paths,
+		"components": map[string]any{
+			"schemas": schemas.components,
+			"securitySchemes": map[string]any{
+				"session":  map[string]any{"type": "apiKey", "in": "cookie", "name": "pp_session", "description": "Issued by POST /api/v1/auth/unlock."},
+				"adminKey": map[string]any{"type": "apiKey", "in": "header", "name": "X-PP-Admin-Key", "description": "PP_ADMIN_KEY or a per-user admin key."},
+			},
+		},
+	}
+}
+
+// operationID names an operation for generated clients, for example
+// GET /api/v1/admin/stories/{slug}/versions becomes getAdminStoriesSlugVersions.
+func operationID(op Operation) string {
+	id := strings.ToLower(op.Method)
+	for _, part := range strings.Split(strings.TrimPrefix(op.Path, "/api/v1"), "/") {
+		part = strings.Trim(part, "{}")
+		for _, word := range strings.FieldsFunc(part, func(r rune) bool { return r == '-' || r == '.' || r == '_' }) {
+			id += strings.ToUpper(word[:1]) + word[1:]
+		}
+	}
+	return id
+}
+
+func security(auth Auth) []any {
+	switch auth {
+	case AuthSession:
+		return []any{map[string]any{"session": []string{}}}
+	case AuthAdmin, AuthBootstrap:
+		return []any{map[string]any{"session": []string{}, "adminKey": []string{}}}
+	default:
+		return nil
+	}
+}
+
+func requestBody(op Operation, schemas *schemaBuilder) map[string]any {
+	switch {
+	case op.RequestContentType != "":
+		return map[string]any{"required": true, "content": map[string]any{
+			op.RequestContentType: map[string]any{"schema": map[string]any{"type": "string", "format": "binary"}},
+		}}
+	case op.Request != nil:
+		body := map[string]any{"required": true, "content": map[string]any{
+			"application/json": map[string]any{"schema": schemas.of(op.Request)},
+		}}
+		if op.Auth == AuthAdmin || op.Auth == AuthBootstrap {
+			body["description"] = adminBodyDescription
+		}
+		return body
+	default:
+		return nil
+	}
+}
+
+func responses(op Operation, schemas *schemaBuilder) map[string]any {
+	status := op.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	success := map[string]any{"description": http.StatusText(status)}
+	switch {
+	case op.ResponseContentType != "":
+		success["content"] = map[string]any{
+			op.ResponseContentType: map[string]any{"schema": map[string]any{"type": "string", "format": "binary"}},
+		}
+	case op.Response != nil:
+		success["content"] = map[string]any{
+			"application/json": map[string]any{"schema": schemas.of(op.Response)},
+		}
+	}
+	return map[string]any{
+		strconv.Itoa(status): success,
+		"default": map[string]any{
+			"description": "An error.",
+			"content":     map[string]any{"application/json": map[string]any{"schema": map[string]any{"$ref": "#/components/schemas/Error"}}},
+		},
+	}
+}
+
+// adminBodyDescription documents the encodings admin JSON bodies accept.
+const adminBodyDescription = "Up to 20 MB as sent. Send Content-Encoding: gzip to compress it; " +
+	"the inflated body may then be up to 100 MB. Other encodings are refused with 415."
+
+// idempotencyKeyParam documents the key a client keeps across retries of
+// one write.
+var idempotencyKeyParam = map[string]any{
+	"name": idempotency.Header, "in": "header", "schema": map[string]any{"type": "string", "maxLength": 255},
+	"description": "Runs the request once: a retry with the same key and body replays the first response " +
+		"with Idempotent-Replayed: true, and one with another body is refused with 422. Keys are kept for 24 hours.",
+}
+
+// errorSchema is the body every error shares. Validation failures add the
+// fields at fault, and admin ones also the issues found; a rate_limited 429
+// adds the budget that ran out.
+var errorSchema = map[string]any{
+	"type":     "object",
+	"required": []string{"error"},
+	"properties": map[string]any{
+		"error": map[string]any{
+			"type":     "object",
+			"required": []string{"code", "message"},
+			"properties": map[string]any{
+				"code":      map[string]any{"type": "string"},
+				"message":   map[string]any{"type": "string"},
+				"requestId": map[string]any{"type": "string"},
+				"fields":    map[string]any{"type": "array", "items": map[string]any{"$ref": "#/components/schemas/FieldError"}},
+				"issues":    map[string]any{"type": "array", "items": map[string]any{"$ref": "#/components/schemas/AdminValidationIssue"}},
+				"rateLimit": map[string]any{"$ref": "#/components/schemas/RateLimit"},
+			},
+		},
+	},
+}
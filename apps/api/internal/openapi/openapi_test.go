@@ -0,0 +1,161 @@
+package openapi_test
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"pandapages/api/internal/httpadmin"
+	"pandapages/api/internal/httpapi"
+	"pandapages/api/internal/maintenance"
+	"pandapages/api/internal/openapi"
+	"pandapages/api/internal/session"
+)
+
+func TestDocumentReferencesOnlyDefinedSchemas(t *testing.T) {
+	var doc struct {
+		OpenAPI    string                               `json:"openapi"`
+		Paths      map[string]map[string]map[string]any `json:"paths"`
+		Components struct {
+			Schemas map[string]any `json:"schemas"`
+		} `json:"components"`
+	}
+	raw := openapi.Document()
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		t.Fatalf("document is not JSON: %v", err)
+	}
+	if doc.OpenAPI != "3.0.3" {
+		t.Fatalf("openapi = %q", doc.OpenAPI)
+	}
+
+	ids := map[string]bool{}
+	count := 0
+	for path, item := range doc.Paths {
+		for method, operation := range item {
+			count++
+			id, _ := operation["operationId"].(string)
+			if id == "" || ids[id] {
+				t.Fatalf("%s %s operationId %q is missing or repeated", method, path, id)
+			}
+			ids[id] = true
+		}
+	}
+	if count != len(openapi.Operations) {
+		t.Fatalf("document has %d operations, want %d; is one listed twice?", count, len(openapi.Operations))
+	}
+
+	for _, match := range regexp.MustCompile(`"\$ref":"#/components/schemas/([^"]+)"`).FindAllStringSubmatch(string(raw), -1) {
+		if _, ok := doc.Components.Schemas[match[1]]; !ok {
+			t.Fatalf("reference to undefined schema %q", match[1])
+		}
+	}
+	for _, name := range []string{"ReaderStory", "Locator", "AdminStoryDetailResponse", "Error"} {
+		if _, ok := doc.Components.Schemas[name]; !ok {
+			t.Fatalf("schema %q is missing", name)
+		}
+	}
+}
+
+// TestEveryOperationReachesAHandler sends each documented operation,
+// unauthenticated, to the real handlers. A handler answers 401 or its own
+// error; only an unmounted route gets the ServeMux's 404 or 405.
+func TestEveryOperationReachesAHandler(t *testing.T) {
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.DiscardHandler))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
+	sessions, err := session.New(strings.Repeat("s", 32), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	public := httpapi.New(httpapi.Config{Passcode: "123456", Sessions: sessions}, readyStore{})
+	admin := httpadmin.New(httpadmin.Config{
+		AdminKey: "admin-key", Sessions: sessions,
+		Maintenance: maintenance.New(false), LogLevel: new(slog.LevelVar),
+	}, adminStore{})
+
+	wildcard := regexp.MustCompile(`\{[A-Za-z]+\}`)
+	for _, op := range openapi.Operations {
+		path := wildcard.ReplaceAllString(op.Path, "x")
+		handler := public
+		if strings.HasPrefix(path, "/api/v1/admin/") {
+			handler = admin
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(op.Method, path, strings.NewReader("{}")))
+		if rec.Code == http.StatusNotFound || rec.Code == http.StatusMethodNotAllowed {
+			t.Errorf("%s %s = %d; the route is not mounted", op.Method, op.Path, rec.Code)
+		}
+	}
+}
+
+// TestEveryMountedRouteIsDocumented reads the patterns httpapi and httpadmin
+// mount from their source and looks each one up among the operations.
+// Patterns without a method, as most reader routes use, only need their path.
+func TestEveryMountedRouteIsDocumented(t *testing.T) {
+	documented := map[string]bool{}
+	for _, op := range openapi.Operations {
+		documented[op.Method+" "+op.Path] = true
+		documented[op.Path] = true
+	}
+
+	pattern := regexp.MustCompile(`HandleFunc\((?:[a-z.]+\+)?"([A-Z]+ )?\s*(/[^"]*)"`)
+	found := 0
+	for _, dir := range []string{"../httpapi", "../httpadmin"} {
+		files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, file := range files {
+			if strings.HasSuffix(file, "_test.go") {
+				continue
+			}
+			source, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, match := range pattern.FindAllStringSubmatch(string(source), -1) {
+				found++
+				route := match[1] + match[2]
+				if match[1] == "" {
+					// Reader routes match a prefix and route the rest themselves.
+					route = strings.TrimSuffix(match[2], "/")
+					if !documented[route] && !documentedUnder(route+"/") {
+						t.Errorf("%s mounts %q, which the document does not describe", file, match[2])
+					}
+					continue
+				}
+				if !documented[route] {
+					t.Errorf("%s mounts %q, which the document does not describe", file, route)
+				}
+			}
+		}
+	}
+	if found < 50 {
+		t.Fatalf("found only %d routes; has the registration style changed?", found)
+	}
+}
+
+func documentedUnder(prefix string) bool {
+	for _, op := range openapi.Operations {
+		if strings.HasPrefix(op.Path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// readyStore answers readiness; no other route reaches its store without a
+// session.
+type readyStore struct{ httpapi.Store }
+
+func (readyStore) CheckReadiness(context.Context) error { return nil }
+
+type adminStore struct{ httpadmin.Store }
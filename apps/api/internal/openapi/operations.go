@@ -0,0 +1,440 @@
+package openapi
+
+import (
+	"net/http"
+
+	"pandapages/api/internal/model"
+	"pandapages/api/internal/readercontract"
+)
+
+const (
+	tagProbes   = "probes"
+	tagAuth     = "auth"
+	tagReader   = "reader"
+	tagStudio   = "admin: stories"
+	tagTags     = "admin: tags"
+	tagUploads  = "admin: uploads and media"
+	tagAccount  = "admin: account"
+	tagOperator = "admin: deployment"
+)
+
+// Role descriptions for admin operations; the bootstrap key holds them all.
+const (
+	anyRole       = "Any admin role."
+	previewRoles  = "Needs the importer or editor role."
+	importerRole  = "Needs the importer role."
+	editorRole    = "Needs the editor role."
+	publisherRole = "Needs the publisher role."
+	bootstrapOnly = "Only the bootstrap PP_ADMIN_KEY."
+)
+
+// fieldsParam cuts each list item down to the fields named.
+var fieldsParam = Param{Name: "fields", Type: "string", Description: "Comma-separated item fields to send; the default is all of them. Unknown names are refused with 400."}
+
+var pageParams = []Param{
+	{Name: "cursor", Type: "string", Description: "nextCursor of the previous page."},
+	{Name: "limit", Type: "integer", Description: "Items per page."},
+	{Name: "total", Type: "boolean", Description: "true adds total, the number of items across every page, at the cost of a count."},
+	fieldsParam,
+}
+
+type okResponse struct {
+	OK bool `json:"ok"`
+}
+
+type readinessResponse struct {
+	Status string `json:"status"`
+	Reason string `json:"reason,omitempty"`
+}
+
+type unlockRequest struct {
+	Passcode string `json:"passcode"`
+}
+
+type authStatusResponse struct {
+	Unlocked bool `json:"unlocked"`
+}
+
+type progressUpdate struct {
+	Version int                    `json:"version"`
+	Locator readercontract.Locator `json:"locator"`
+	Percent float64                `json:"percent"`
+}
+
+type storyBatchRequest struct {
+	Slugs   []string `json:"slugs"`
+	Include string   `json:"include,omitempty"`
+	Mode    string   `json:"mode,omitempty"`
+}
+
+type storyBatchResponse struct {
+	Items   []model.ReaderStory `json:"items"`
+	Missing []string            `json:"missing"`
+}
+
+type storyMetadataPatch struct {
+	Title    *string        `json:"title,omitempty"`
+	Author   *string        `json:"author,omitempty"`
+	Language *string        `json:"language,omitempty"`
+	Rights   map[string]any `json:"rights,omitempty"`
+	Tags     []string       `json:"tags,omitempty"`
+}
+
+type publishChoice struct {
+	VersionID             string `json:"versionId"`
+	AcknowledgeModeration bool   `json:"acknowledgeModeration,omitempty"`
+}
+
+type storyTagsChange struct {
+	Tags []string `json:"tags"`
+}
+
+type tagName struct {
+	Name string `json:"name"`
+}
+
+type tagMerge struct {
+	IntoTagID string `json:"intoTagId"`
+}
+
+type userCreate struct {
+	Name  string            `json:"name"`
+	Roles []model.AdminRole `json:"roles"`
+}
+
+type pruneRequest struct {
+	Keep   *int `json:"keep,omitempty"`
+	DryRun bool `json:"dryRun,omitempty"`
+}
+
+type narrationRequest struct {
+	Voice string `json:"voice,omitempty"`
+}
+
+type searchReindexRequest struct {
+	Slug string `json:"slug,omitempty"`
+}
+
+type hyphenationChange struct {
+	Enabled bool `json:"enabled"`
+}
+
+type uploadCreate struct {
+	Kind       model.AdminUploadKind `json:"kind"`
+	TotalBytes int64                 `json:"totalBytes"`
+	SHA256     string                `json:"sha256,omitempty"`
+}
+
+type webhookCreate struct {
+	URL    string               `json:"url"`
+	Events []model.WebhookEvent `json:"events"`
+}
+
+type webhookUpdate struct {
+	URL    *string              `json:"url,omitempty"`
+	Events []model.WebhookEvent `json:"events,omitempty"`
+	Active *bool                `json:"active,omitempty"`
+}
+
+type maintenanceChange struct {
+	Enabled bool   `json:"enabled"`
+	Message string `json:"message,omitempty"`
+}
+
+// Operations is every route httpapi and httpadmin mount.
+var Operations = []Operation{
+	{Method: http.MethodGet, Path: "/healthz", Tag: tagProbes, Summary: "Report that the process is up", ResponseContentType: "text/plain"},
+	{Method: http.MethodGet, Path: "/readyz", Tag: tagProbes, Summary: "Report whether the database and schema are ready", Response: readinessResponse{}},
+	{Method: http.MethodGet, Path: "/api/v1/openapi.json", Tag: tagProbes, Summary: "This document"},
+
+	{Method: http.MethodPost, Path: "/api/v1/auth/unlock", Tag: tagAuth, Summary: "Unlock with the passcode and receive a session cookie", Request: unlockRequest{}, Response: okResponse{}},
+	{Method: http.MethodGet, Path: "/api/v1/auth/status", Tag: tagAuth, Summary: "Report whether the session is unlocked", Response: authStatusResponse{}},
+	{Method: http.MethodPost, Path: "/api/v1/auth/logout", Tag: tagAuth, Summary: "Clear the session cookie", Response: okResponse{}},
+
+	{
+		Method: http.MethodGet, Path: "/api/v1/library", Tag: tagReader, Summary: "List the library a page at a time", Auth: AuthSession,
+		Query: append([]Param{
+			{Name: "decodable", Type: "integer", Description: "A set of the phonics progression; keeps stories a child taught it and the sets before it can read. Stories not yet scored are left out. Answers 503 when no progression is configured."},
+			{Name: "mode", Type: "string", Description: "bedtime keeps, inside the account's bedtime window, stories tagged calm, not tagged exciting and at most maxWords long, and sets bedtime on the page; outside the window the library is unfiltered."},
+		}, pageParams...),
+		Response: model.LibraryReadModel{},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/reader/{slug}", Tag: tagReader, Summary: "Read a story's published version", Auth: AuthSession,
+		Description: "Revalidated by ETag, or by Last-Modified, which changes with anything the story reads as. A story that was published and has since been unpublished answers 410 story_removed rather than 404.",
+		Query: []Param{
+			{Name: "mode", Type: "string", Description: "kid leaves out asides; grownup, the default, keeps them."},
+			{Name: "include", Type: "string", Description: "Comma-separated meta, segments and html; the default is all three."},
+		},
+		Response: model.ReaderStory{},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/reader/{slug}.html", Tag: tagReader, Summary: "Read a story's published version as a web page", Auth: AuthSession,
+		Description:         "For browsers that cannot run the web app. The plain Reader URL answers the same way when Accept prefers text/html to JSON. Revalidated by ETag or Last-Modified.",
+		Query:               []Param{{Name: "mode", Type: "string", Description: "kid leaves out asides; grownup, the default, keeps them."}},
+		ResponseContentType: "text/html",
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/reader/{slug}.epub", Tag: tagReader, Summary: "Download a story's published version as an EPUB book", Auth: AuthSession,
+		Description:         "The book sending the story emails. Uploaded images are packaged with it; other images become their alt text. With a blob store that signs URLs, answers 302 to a short-lived download link instead.",
+		ResponseContentType: "application/epub+zip",
+	},
+	{Method: http.MethodGet, Path: "/api/v1/reader/{slug}/vocabulary", Tag: tagReader, Summary: "Read the vocabulary of a story's published version", Auth: AuthSession, Description: "Revalidated by ETag.", Response: model.Vocabulary{}},
+	{
+		Method: http.MethodPost, Path: "/api/v1/stories/batch", Tag: tagReader, Summary: "Read up to 25 stories' published versions at once", Auth: AuthSession,
+		Description: "include and mode mean what they mean on the Reader URL. Items follow the order of slugs; slugs with nothing to read are listed under missing.",
+		Request:     storyBatchRequest{}, Response: storyBatchResponse{},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/search/semantic", Tag: tagReader, Summary: "Find published stories by what they are about", Auth: AuthSession,
+		Description: "Ranks stories by how close their meaning is to q, so they match without sharing its words; score is the cosine similarity. " +
+			"Stories are embedded shortly after they are published and are left out until then. Answers 503 when no embedding provider is configured and 502 when it fails.",
+		Query: []Param{
+			{Name: "q", Type: "string", Description: "What the story is like, up to 500 characters.", Required: true},
+			{Name: "limit", Type: "integer", Description: "1 to 25, default 10."},
+		},
+		Response: model.SemanticSearchResponse{},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/define", Tag: tagReader, Summary: "Define a word the reader tapped", Auth: AuthSession,
+		Description: "Looks the word up in a dictionary and keeps the answer for every account. Only up to three senses suitable for children are shown, " +
+			"so a word with none, or one never defined for a child, answers 404 word_not_found. Answers 503 when no dictionary is configured and 502 when it fails. Revalidated by ETag.",
+		Query: []Param{
+			{Name: "word", Type: "string", Description: "One word, as tapped; surrounding punctuation is ignored.", Required: true},
+			{Name: "lang", Type: "string", Description: "The story's language tag, default en."},
+		},
+		Response: model.Definition{},
+	},
+	{Method: http.MethodGet, Path: "/api/v1/phonics", Tag: tagReader, Summary: "Read the phonics progression the Library filters by", Auth: AuthSession, Description: "Sets are numbered from 1 in the order listed. Answers 503 when no progression is configured. Revalidated by ETag.", Response: model.PhonicsProgression{}},
+	{Method: http.MethodGet, Path: "/api/v1/media/{id}", Tag: tagReader, Summary: "Download an image a story references", Auth: AuthSession, ResponseContentType: "image/*"},
+	{Method: http.MethodHead, Path: "/api/v1/media/{id}", Tag: tagReader, Summary: "Check an image a story references", Auth: AuthSession},
+	{Method: http.MethodGet, Path: "/api/v1/audio/{id}", Tag: tagReader, Summary: "Download a segment's narration", Description: "Linked from a reader segment's audio.url. Range requests are answered. Narration kept in a blob store that signs URLs answers 302 to a short-lived link instead.", Auth: AuthSession, ResponseContentType: "audio/wav"},
+	{Method: http.MethodHead, Path: "/api/v1/audio/{id}", Tag: tagReader, Summary: "Check a segment's narration", Auth: AuthSession},
+	{Method: http.MethodGet, Path: "/api/v1/progress/{slug}", Tag: tagReader, Summary: "Read the reader's place in a story", Auth: AuthSession, Description: "A removed story answers 410 story_removed, so its saved place can be dropped.", Response: model.ProgressResponse{}},
+	{Method: http.MethodPut, Path: "/api/v1/progress/{slug}", Tag: tagReader, Summary: "Save the reader's place in a story", Auth: AuthSession, Description: "A removed story answers 410 story_removed.", Idempotent: true, Request: progressUpdate{}, Response: okResponse{}},
+	{
+		Method: http.MethodGet, Path: "/api/v1/continue", Tag: tagReader, Summary: "List the stories read most recently", Auth: AuthSession,
+		Description: "upNext is the whole up-next queue, so the home screen needs one request; fields applies only to items.",
+		Query:       []Param{{Name: "limit", Type: "integer", Description: "1 to 10, default 3."}, fieldsParam},
+		Response:    model.ContinueResponse{},
+	},
+	{Method: http.MethodGet, Path: "/api/v1/queue", Tag: tagReader, Summary: "List the stories lined up to read next", Auth: AuthSession, Description: "First story first. Stories taken down are left out.", Response: model.QueueResponse{}},
+	{Method: http.MethodPut, Path: "/api/v1/queue", Tag: tagReader, Summary: "Reorder the up-next queue", Auth: AuthSession, Description: "slugs names every queued story exactly once, first story first; anything else answers 409 queue_order_mismatch.", Idempotent: true, Request: model.QueueReorderRequest{}, Response: model.QueueResponse{}},
+	{Method: http.MethodPost, Path: "/api/v1/queue/{slug}", Tag: tagReader, Summary: "Line up a story to read next", Auth: AuthSession, Description: "Adds a published story at the end of the queue and answers with the whole queue: 201 when it was added, 200 when it was already queued and keeps its place. A full queue of 50 answers 409 queue_full and a removed story 410 story_removed.", Idempotent: true, Status: http.StatusCreated, Response: model.QueueResponse{}},
+	{Method: http.MethodDelete, Path: "/api/v1/queue/{slug}", Tag: tagReader, Summary: "Take a story out of the up-next queue", Auth: AuthSession, Description: "Answers with the whole queue, or 404 when the story is not queued.", Idempotent: true, Response: model.QueueResponse{}},
+	{
+		Method: http.MethodGet, Path: "/api/v1/ws/read-along/{slug}", Tag: tagReader, Summary: "Read a story along with other devices", Auth: AuthSession,
+		Description: "Upgrades to a WebSocket. Devices on the same account, profile and story share a room: a driving device sends " +
+			`{"type":"position","locator":…} and every other member receives it, and a device that joins late receives the last one. ` +
+			`The server also sends {"type":"presence","drivers":n,"followers":n} when members come and go, and {"type":"error","code","message"} ` +
+			`for a refused message. Clients send {"type":"ping"} at least every 75 seconds to stay connected.`,
+		Query: []Param{
+			{Name: "role", Type: "string", Description: "drive lets the device move the room; otherwise it follows."},
+			{Name: "profile", Type: "string", Description: "The reading profile, so two children reading one story do not share a room; default is default."},
+		},
+		Status: http.StatusSwitchingProtocols,
+	},
+	{
+		Method: http.MethodPost, Path: "/api/v1/story/{slug}/send", Tag: tagReader, Summary: "Email a story as an EPUB to a delivery destination", Auth: AuthSession,
+		Description: "Queues the delivery and answers at once; GET its Location for the status. The book is made from the version published when it is sent, " +
+			"and failed sends are retried with backoff. Answers 503 when no SMTP relay is configured and 410 story_removed for a removed story.",
+		Idempotent: true, Request: model.StorySendRequest{}, Status: http.StatusAccepted, Response: model.StoryDelivery{},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/story/{slug}/quiz", Tag: tagReader, Summary: "Read the comprehension quiz of a story's published version", Auth: AuthSession,
+		Description: "Approved questions and their answers, by chapter; a chapter's chapterKey and chapterOccurrence match its segments'. Revalidated by ETag. " +
+			"A story with no approved quiz answers 404 and a removed story 410 story_removed.",
+		Response: model.StoryQuiz{},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/story/{slug}/discussion", Tag: tagReader, Summary: "Read the discussion questions of a story's published version", Auth: AuthSession,
+		Description: "Questions for a grown-up to talk over with the child after each chapter; a chapter's chapterKey and chapterOccurrence match its segments'. Revalidated by ETag. " +
+			"A story with no questions answers 404 and a removed story 410 story_removed.",
+		Response: model.StoryDiscussion{},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/v1/story/{slug}/reaction", Tag: tagReader, Summary: "React to a story", Auth: AuthSession,
+		Description: "reaction is loved_it, silly or scary and replaces any the profile gave before. Answers with the story's counts across the account's profiles, which the Library shows as reactions. A removed story answers 410 story_removed.",
+		Idempotent:  true, Request: model.StoryReactionRequest{}, Response: model.StoryReactionResponse{},
+	},
+	{Method: http.MethodDelete, Path: "/api/v1/story/{slug}/reaction", Tag: tagReader, Summary: "Take back a reaction to a story", Auth: AuthSession, Description: "Answers with the story's counts and a null reaction.", Idempotent: true, Response: model.StoryReactionResponse{}},
+	{Method: http.MethodGet, Path: "/api/v1/deliveries", Tag: tagReader, Summary: "List the 50 most recent story deliveries", Auth: AuthSession, Response: model.StoryDeliveriesResponse{}},
+	{Method: http.MethodGet, Path: "/api/v1/deliveries/{id}", Tag: tagReader, Summary: "Read a story delivery's status", Auth: AuthSession, Response: model.StoryDelivery{}},
+	{Method: http.MethodGet, Path: "/api/v1/delivery-destinations", Tag: tagReader, Summary: "List the addresses stories can be emailed to", Auth: AuthSession, Response: model.DeliveryDestinationsResponse{}},
+	{
+		Method: http.MethodPost, Path: "/api/v1/delivery-destinations", Tag: tagReader, Summary: "Add an address stories can be emailed to", Auth: AuthSession,
+		Description: "Such as a Kindle's Send-to-Kindle address, which must also approve the relay's sender. An account keeps up to 10; an address already kept, or an eleventh, answers 409.",
+		Idempotent:  true, Request: model.DeliveryDestinationCreate{}, Status: http.StatusCreated, Response: model.DeliveryDestination{},
+	},
+	{Method: http.MethodDelete, Path: "/api/v1/delivery-destinations/{id}", Tag: tagReader, Summary: "Remove a delivery destination", Auth: AuthSession, Description: "Deliveries to it not yet sent fail with destination_removed.", Status: http.StatusNoContent},
+	{Method: http.MethodGet, Path: "/api/v1/settings", Tag: tagReader, Summary: "Read the active child and prompt profiles", Auth: AuthSession, Response: model.SettingsPayload{}},
+	{Method: http.MethodPut, Path: "/api/v1/settings", Tag: tagReader, Summary: "Save the active child and prompt profiles", Auth: AuthSession, Request: model.SettingsUpsert{}, Response: model.SettingsPayload{}},
+	{
+		Method: http.MethodPost, Path: "/api/v1/events", Tag: tagReader, Summary: "Record a batch of Reader events", Auth: AuthSession,
+		Description: "Up to 100 story_opened, segment_viewed and read_aloud_used events, each with the story's slug and the device time it happened. They are kept only as daily counts per story and child profile; segment views and read aloud also count toward the reading report's minutes. " +
+			"Events more than seven days old, or for stories the account has not published, are dropped and counted. Send a retried batch with the same Idempotency-Key so it is counted once.",
+		Idempotent: true, Request: model.ClientEventBatch{}, Response: model.ClientEventsResponse{},
+	},
+	{Method: http.MethodGet, Path: "/api/v1/digest/settings", Tag: tagReader, Summary: "Read the account's weekly email choices", Auth: AuthSession, Response: model.DigestSettings{}},
+	{Method: http.MethodPut, Path: "/api/v1/digest/settings", Tag: tagReader, Summary: "Choose the account's weekly email", Auth: AuthSession, Description: "When enabled, the reading report is emailed to address each day, a day of the week, at time, HH:MM in timeZone, an IANA name. Nothing is sent while no SMTP relay is configured.", Request: model.DigestSettings{}, Response: model.DigestSettings{}},
+	{Method: http.MethodGet, Path: "/api/v1/digest/report", Tag: tagReader, Summary: "Read the report a weekly email sent now would carry", Auth: AuthSession, Description: "Minutes read, books finished and reading streaks per child over the last seven days in the digest's time zone. A minute counts when the Reader saved progress in it; reading is credited to the child profile active at the time.", Response: model.ReadingReport{}},
+	{Method: http.MethodGet, Path: "/api/v1/bedtime/settings", Tag: tagReader, Summary: "Read the account's bedtime window", Auth: AuthSession, Response: model.BedtimeSettings{}},
+	{Method: http.MethodPut, Path: "/api/v1/bedtime/settings", Tag: tagReader, Summary: "Choose the account's bedtime window", Auth: AuthSession, Description: "The window runs from start up to end, HH:MM in timeZone, an IANA name, and may cross midnight. The library's mode=bedtime applies only inside it, keeping stories of at most maxWords words.", Request: model.BedtimeSettings{}, Response: model.BedtimeSettings{}},
+	{Method: http.MethodGet, Path: "/api/v1/children/{id}/dashboard", Tag: tagReader, Summary: "Read one child's reading dashboard", Auth: AuthSession, Description: "One child profile's last four weeks in the digest's time zone, counted like the reading report: recent stories, favorite tags by minutes read, the Flesch-Kincaid grade of what was read each week, the reading streak and minutes by local hour. A missing child profile answers 404.", Response: model.ChildDashboard{}},
+	{Method: http.MethodGet, Path: "/api/v1/push/key", Tag: tagReader, Summary: "Read the key browsers subscribe to notifications with", Auth: AuthSession, Description: "Pass publicKey to pushManager.subscribe as applicationServerKey. Answers 503 when PP_PUSH_SUBJECT is unset.", Response: model.PushKey{}},
+	{
+		Method: http.MethodPost, Path: "/api/v1/push/subscribe", Tag: tagReader, Summary: "Send the account's notifications to a browser", Auth: AuthSession,
+		Description: "The body is the browser's PushSubscription.toJSON. Answers 201 for a new browser and 200 when it was already subscribed, replacing its keys. An account keeps up to 20 browsers; a 21st answers 409. Answers 503 when push is not configured.",
+		Idempotent:  true, Request: model.PushSubscribeRequest{}, Status: http.StatusCreated, Response: model.PushSubscription{},
+	},
+	{Method: http.MethodPost, Path: "/api/v1/push/unsubscribe", Tag: tagReader, Summary: "Stop sending notifications to a browser", Auth: AuthSession, Request: model.PushUnsubscribeRequest{}, Status: http.StatusNoContent},
+	{Method: http.MethodGet, Path: "/api/v1/push/settings", Tag: tagReader, Summary: "Read the account's notification choices", Auth: AuthSession, Response: model.PushSettings{}},
+	{Method: http.MethodPut, Path: "/api/v1/push/settings", Tag: tagReader, Summary: "Choose the account's notifications", Auth: AuthSession, Description: "A bedtime reminder goes to every subscribed browser once a day at reminderTime, HH:MM in timeZone, an IANA name; newStories announces each story published for the first time.", Request: model.PushSettings{}, Response: model.PushSettings{}},
+
+	{Method: http.MethodPost, Path: "/api/v1/admin/preview", Tag: tagStudio, Summary: "Render Markdown without saving it", Auth: AuthAdmin, Description: previewRoles, Request: model.AdminPreviewRequest{}, Response: model.AdminPreviewResponse{}},
+	{Method: http.MethodPost, Path: "/api/v1/admin/lint", Tag: tagStudio, Summary: "Lint a story without saving it", Auth: AuthAdmin, Description: previewRoles, Request: model.AdminStoryInput{}, Response: model.AdminLintResponse{}},
+	{Method: http.MethodPost, Path: "/api/v1/admin/validate", Tag: tagStudio, Summary: "Validate a story without saving it", Auth: AuthAdmin, Description: previewRoles, Request: model.AdminStoryInput{}, Response: model.AdminValidateResponse{}},
+	{Method: http.MethodPost, Path: "/api/v1/admin/stories/draft", Tag: tagStudio, Summary: "Save a story's draft", Auth: AuthAdmin, Description: importerRole, Idempotent: true, Request: model.AdminDraftUpsertRequest{}, Response: model.AdminDraftUpsertResponse{}},
+	{Method: http.MethodGet, Path: "/api/v1/admin/stories", Tag: tagStudio, Summary: "List stories a page at a time", Auth: AuthAdmin, Description: anyRole, Query: pageParams, Response: model.AdminStoriesListResponse{}},
+	{Method: http.MethodGet, Path: "/api/v1/admin/stories/{slug}", Tag: tagStudio, Summary: "Read a story's details", Auth: AuthAdmin, Description: anyRole, Response: model.AdminStoryDetailResponse{}},
+	{Method: http.MethodPatch, Path: "/api/v1/admin/stories/{slug}", Tag: tagStudio, Summary: "Change a story's metadata without a new version", Auth: AuthAdmin, Description: editorRole, Request: storyMetadataPatch{}, Response: model.AdminStoryMetadataResponse{}},
+	{Method: http.MethodGet, Path: "/api/v1/admin/stories/{slug}/versions", Tag: tagStudio, Summary: "List a story's versions a page at a time", Auth: AuthAdmin, Description: anyRole, Query: pageParams, Response: model.AdminStoryVersionsResponse{}},
+	{Method: http.MethodGet, Path: "/api/v1/admin/stories/{slug}/versions/{versionId}", Tag: tagStudio, Summary: "Read a version's source", Auth: AuthAdmin, Description: anyRole, Response: model.AdminVersionSourceResponse{}},
+	{Method: http.MethodPost, Path: "/api/v1/admin/stories/{slug}/versions/{versionId}/narration", Tag: tagStudio, Summary: "Narrate a version in the background", Auth: AuthAdmin, Description: publisherRole + " Segments already narrated in the voice are kept, and segments whose content the account has recorded in the voice before, as when unchanged from an earlier version, get a copy of that recording. Answers 503 when no text-to-speech provider is configured.", Request: narrationRequest{}, Status: http.StatusAccepted, Response: model.NarrationJob{}},
+	{Method: http.MethodGet, Path: "/api/v1/admin/narration-jobs/{id}", Tag: tagStudio, Summary: "Read a narration job's progress", Auth: AuthAdmin, Description: anyRole, Response: model.NarrationJob{}},
+	{Method: http.MethodPost, Path: "/api/v1/admin/stories/{slug}/versions/{versionId}/alignment", Tag: tagStudio, Summary: "Time a narrated version's words in the background", Auth: AuthAdmin, Description: publisherRole + " Transcribes each recording not yet aligned and matches it to the segment's text, so reader segments' audio.words time every word for read-along highlighting. Narrating a segment again clears its timings. Answers 503 when no alignment provider is configured.", Status: http.StatusAccepted, Response: model.AlignmentJob{}},
+	{Method: http.MethodGet, Path: "/api/v1/admin/alignment-jobs/{id}", Tag: tagStudio, Summary: "Read an alignment job's progress", Auth: AuthAdmin, Description: anyRole, Response: model.AlignmentJob{}},
+	{Method: http.MethodPost, Path: "/api/v1/admin/search/reindex", Tag: tagStudio, Summary: "Rebuild the semantic search index in the background", Auth: AuthAdmin, Description: editorRole + " Embeds the published version of every story in the account again, or only of slug. Each version's vector is replaced as it is embedded, so search keeps working while the job runs. Answers 503 when no embedding provider is configured and 409 while another reindex job is queued or running.", Request: searchReindexRequest{}, Status: http.StatusAccepted, Response: model.SearchReindexJob{}},
+	{Method: http.MethodGet, Path: "/api/v1/admin/search/reindex-jobs/{id}", Tag: tagStudio, Summary: "Read a search reindex job's progress", Auth: AuthAdmin, Description: anyRole, Response: model.SearchReindexJob{}},
+	{Method: http.MethodPost, Path: "/api/v1/admin/stories/{slug}/publish", Tag: tagStudio, Summary: "Publish a version", Auth: AuthAdmin, Description: publisherRole + " Answers 409 publish_moderation_blocked for a version moderation blocked, and 409 publish_moderation_flagged for a flagged one unless acknowledgeModeration is true.", Idempotent: true, Request: publishChoice{}, Response: model.AdminStoryStatusResponse{}},
+	{Method: http.MethodPost, Path: "/api/v1/admin/stories/{slug}/unpublish", Tag: tagStudio, Summary: "Unpublish a story", Auth: AuthAdmin, Description: publisherRole, Response: model.AdminStoryStatusResponse{}},
+	{Method: http.MethodGet, Path: "/api/v1/admin/stories/{slug}/export", Tag: tagStudio, Summary: "Export a story's bundle", Auth: AuthAdmin, Description: anyRole, Response: model.StoryBundle{}},
+	{
+		Method: http.MethodPost, Path: "/api/v1/admin/import/fetch", Tag: tagStudio, Summary: "Save a story's draft from a web page, Markdown file or Google Doc", Auth: AuthAdmin,
+		Description: importerRole + " Downloads url, converts HTML, .docx or Markdown to Markdown and saves it as the draft, with url as its source. A Google Docs link is fetched as its .docx export, so the document must be shared by link. " +
+			"Unless title is sent, the document's own is used: its frontmatter title, Title-styled paragraph or leading heading; a document with none answers 422 fetch_untitled. " +
+			"Addresses off the public internet are refused unless PP_IMPORT_FETCH is any; 415 means the type is not supported and 502 that the download failed.",
+		Idempotent: true, Request: model.AdminFetchImportRequest{}, Response: model.AdminDraftUpsertResponse{},
+	},
+	{Method: http.MethodPost, Path: "/api/v1/admin/stories/import", Tag: tagStudio, Summary: "Import a story's bundle", Auth: AuthAdmin, Description: importerRole + " Importing a published version also needs the publisher role.", Request: model.StoryBundle{}, Status: http.StatusCreated, Response: model.AdminStoryStatusResponse{}},
+	{Method: http.MethodPost, Path: "/api/v1/admin/generate", Tag: tagStudio, Summary: "Write a story draft with the language model", Auth: AuthAdmin, Description: importerRole + " Empty profile IDs use the active child and prompt profiles. The draft is moderated before it is saved and the verdict is returned and stored on the version. Answers 503 when no language model provider is configured and 502 when its reply cannot be used; every attempt is recorded.", Idempotent: true, Request: model.AdminGenerateRequest{}, Status: http.StatusCreated, Response: model.AdminGenerateResponse{}},
+	{Method: http.MethodPost, Path: "/api/v1/admin/stories/{slug}/translate", Tag: tagStudio, Summary: "Translate a story into a new draft with the language model", Auth: AuthAdmin, Description: importerRole + " The draft is linked to the original's language group, which the Library lists as variants once both are published. An empty slug appends the language to the original's. The draft is moderated like a generated one. Answers 409 when the slug holds an unrelated story, 503 when no language model provider is configured and 502 when its reply cannot be used.", Idempotent: true, Request: model.AdminTranslateRequest{}, Status: http.StatusCreated, Response: model.AdminTranslateResponse{}},
+	{Method: http.MethodPost, Path: "/api/v1/admin/stories/{slug}/simplify", Tag: tagStudio, Summary: "Rewrite a published story for a younger reader with the language model", Auth: AuthAdmin, Description: importerRole + " The age is 3 to 12. The draft keeps the original's author, rights and source address, adds rights.adaptedFrom naming the published version it was written from, and is linked to the original. An empty slug appends the age to the original's. The draft is moderated like a generated one. Answers 404 when the story is not published, 409 when the slug holds an unrelated story, 503 when no language model provider is configured and 502 when its reply cannot be used.", Idempotent: true, Request: model.AdminSimplifyRequest{}, Status: http.StatusCreated, Response: model.AdminSimplifyResponse{}},
+	{
+		Method: http.MethodGet, Path: "/api/v1/admin/stories/{slug}/quiz", Tag: tagStudio, Summary: "List a version's chapters with their comprehension quizzes", Auth: AuthAdmin, Description: anyRole + " A chapter with no quiz has a null status.",
+		Query:    []Param{{Name: "versionId", Type: "string", Description: "The version; the default is the published one, else the draft."}},
+		Response: model.AdminQuizResponse{},
+	},
+	{Method: http.MethodPost, Path: "/api/v1/admin/stories/{slug}/quiz", Tag: tagStudio, Summary: "Write a chapter's comprehension quiz with the language model", Auth: AuthAdmin, Description: importerRole + " Chapters are numbered from 1; a story without chapters has only chapter 1. The questions replace any the chapter had, as a draft readers do not see until it is approved. An empty versionId uses the published version, else the draft. Answers 503 when no language model provider is configured and 502 when its reply cannot be used.", Idempotent: true, Request: model.AdminQuizGenerateRequest{}, Status: http.StatusCreated, Response: model.AdminChapterQuiz{}},
+	{Method: http.MethodPut, Path: "/api/v1/admin/stories/{slug}/quiz/{chapter}", Tag: tagStudio, Summary: "Rewrite or approve a chapter's comprehension quiz", Auth: AuthAdmin, Description: editorRole + " One to five questions, each with an answer. Readers see approved quizzes of the published version. An empty versionId uses the published version, else the draft.", Request: model.AdminQuizUpdate{}, Response: model.AdminChapterQuiz{}},
+	{
+		Method: http.MethodDelete, Path: "/api/v1/admin/stories/{slug}/quiz/{chapter}", Tag: tagStudio, Summary: "Delete a chapter's comprehension quiz", Auth: AuthAdmin, Description: editorRole,
+		Query:  []Param{{Name: "versionId", Type: "string", Description: "The version; the default is the published one, else the draft."}},
+		Status: http.StatusNoContent,
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/admin/stories/{slug}/discussion", Tag: tagStudio, Summary: "List a version's chapters with their discussion questions", Auth: AuthAdmin, Description: anyRole + " A chapter with no questions has an empty list and a null updatedAt.",
+		Query:    []Param{{Name: "versionId", Type: "string", Description: "The version; the default is the published one, else the draft."}},
+		Response: model.AdminDiscussionResponse{},
+	},
+	{Method: http.MethodPut, Path: "/api/v1/admin/stories/{slug}/discussion/{chapter}", Tag: tagStudio, Summary: "Write a chapter's discussion questions", Auth: AuthAdmin, Description: editorRole + " One to five questions of up to 300 characters, replacing any the chapter had; chapters are numbered as for quizzes. Readers see them on the published version at once. An empty versionId uses the published version, else the draft.", Request: model.AdminDiscussionUpdate{}, Response: model.AdminChapterDiscussion{}},
+	{
+		Method: http.MethodDelete, Path: "/api/v1/admin/stories/{slug}/discussion/{chapter}", Tag: tagStudio, Summary: "Delete a chapter's discussion questions", Auth: AuthAdmin, Description: editorRole,
+		Query:  []Param{{Name: "versionId", Type: "string", Description: "The version; the default is the published one, else the draft."}},
+		Status: http.StatusNoContent,
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/admin/stories/{slug}/phonics", Tag: tagStudio, Summary: "Score a version against the phonics progression", Auth: AuthAdmin,
+		Description: anyRole + " decodableSet is the first set at which 90% of the version's words can be read, and null when none reaches it. " +
+			"Versions in the progression's language are scored in the background shortly after they are stored; until then, and for other languages, the route answers 404 phonics_not_found. Answers 503 when no progression is configured.",
+		Query:    []Param{{Name: "versionId", Type: "string", Description: "The version; the default is the published one, else the latest."}},
+		Response: model.AdminPhonicsResponse{},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/admin/gutenberg/search", Tag: tagStudio, Summary: "Search the Project Gutenberg catalog", Auth: AuthAdmin,
+		Description: importerRole + " Proxies Gutendex, caching each page for an hour. A result's title, author, language and sourceUrl fill a draft, and its text format is the book to import. " +
+			"Answers 503 when PP_GUTENDEX_URL is off and 502 when the catalog does not answer.",
+		Query: []Param{
+			{Name: "q", Type: "string", Description: "Words from the title or author, up to 200 characters.", Required: true},
+			{Name: "languages", Type: "string", Description: "Comma-separated two-letter codes, up to 5; the default is every language."},
+			{Name: "page", Type: "integer", Description: "1 to 1000, default 1; a page holds up to 32 books."},
+		},
+		Response: model.GutenbergSearchResponse{},
+	},
+	{Method: http.MethodPost, Path: "/api/v1/admin/stories/{slug}/tags", Tag: tagStudio, Summary: "Tag a story", Auth: AuthAdmin, Description: editorRole, Request: storyTagsChange{}, Response: model.AdminStoryTagsResponse{}},
+	{Method: http.MethodDelete, Path: "/api/v1/admin/stories/{slug}/tags", Tag: tagStudio, Summary: "Untag a story", Auth: AuthAdmin, Description: editorRole, Request: storyTagsChange{}, Response: model.AdminStoryTagsResponse{}},
+	{
+		Method: http.MethodGet, Path: "/api/v1/admin/stories/{slug}/sensitivity-report", Tag: tagStudio, Summary: "Match a version against the child profiles' sensitivities", Auth: AuthAdmin, Description: anyRole,
+		Query:    []Param{{Name: "versionId", Type: "string", Description: "The version to scan; the default is the published one."}},
+		Response: model.AdminSensitivityReport{},
+	},
+	{Method: http.MethodGet, Path: "/api/v1/admin/stories/{slug}/contributors", Tag: tagStudio, Summary: "List a story's contributors", Auth: AuthAdmin, Description: anyRole, Response: model.AdminStoryContributorsResponse{}},
+	{Method: http.MethodPost, Path: "/api/v1/admin/stories/{slug}/contributors", Tag: tagStudio, Summary: "Credit a contributor", Auth: AuthAdmin, Description: editorRole, Request: model.StoryContributor{}, Response: model.AdminStoryContributorsResponse{}},
+	{Method: http.MethodDelete, Path: "/api/v1/admin/stories/{slug}/contributors/{contributorId}/{role}", Tag: tagStudio, Summary: "Remove a contributor's credit", Auth: AuthAdmin, Description: editorRole, Response: model.AdminStoryContributorsResponse{}},
+	{Method: http.MethodPost, Path: "/api/v1/admin/stories/{slug}/prune-versions", Tag: tagStudio, Summary: "Delete old versions", Auth: AuthAdmin, Description: editorRole, Request: pruneRequest{}, Response: model.AdminPruneVersionsResponse{}},
+
+	{Method: http.MethodGet, Path: "/api/v1/admin/tags", Tag: tagTags, Summary: "List tags a page at a time", Auth: AuthAdmin, Description: anyRole, Query: pageParams, Response: model.AdminTagsListResponse{}},
+	{Method: http.MethodPost, Path: "/api/v1/admin/tags", Tag: tagTags, Summary: "Create a tag", Auth: AuthAdmin, Description: editorRole, Request: tagName{}, Status: http.StatusCreated, Response: model.AdminTag{}},
+	{Method: http.MethodPost, Path: "/api/v1/admin/tags/{id}/rename", Tag: tagTags, Summary: "Rename a tag", Auth: AuthAdmin, Description: editorRole, Request: tagName{}, Response: model.AdminTag{}},
+	{Method: http.MethodPost, Path: "/api/v1/admin/tags/{id}/merge", Tag: tagTags, Summary: "Merge a tag into another", Auth: AuthAdmin, Description: editorRole, Request: tagMerge{}, Response: model.AdminTag{}},
+	{Method: http.MethodDelete, Path: "/api/v1/admin/tags/{id}", Tag: tagTags, Summary: "Delete a tag", Auth: AuthAdmin, Description: editorRole, Status: http.StatusNoContent},
+
+	{Method: http.MethodPost, Path: "/api/v1/admin/uploads", Tag: tagUploads, Summary: "Start a resumable upload", Auth: AuthAdmin, Description: importerRole, Request: uploadCreate{}, Status: http.StatusCreated, Response: model.AdminUpload{}},
+	{Method: http.MethodGet, Path: "/api/v1/admin/uploads/{id}", Tag: tagUploads, Summary: "Read an upload's progress", Auth: AuthAdmin, Description: importerRole, Response: model.AdminUpload{}},
+	{
+		Method: http.MethodPut, Path: "/api/v1/admin/uploads/{id}/chunks", Tag: tagUploads, Summary: "Append a chunk to an upload", Auth: AuthAdmin, Description: importerRole,
+		Query:              []Param{{Name: "offset", Type: "integer", Description: "The bytes received so far.", Required: true}},
+		RequestContentType: "application/octet-stream", Response: model.AdminUpload{},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/v1/admin/uploads/{id}/finalize", Tag: tagUploads, Summary: "Ingest a complete upload", Auth: AuthAdmin,
+		Description: importerRole + " A draft upload answers as POST /api/v1/admin/stories/draft does, with 200; a bundle as POST /api/v1/admin/stories/import does, with 201.",
+		Response:    model.AdminDraftUpsertResponse{},
+	},
+	{Method: http.MethodDelete, Path: "/api/v1/admin/uploads/{id}", Tag: tagUploads, Summary: "Abandon an upload", Auth: AuthAdmin, Description: importerRole, Status: http.StatusNoContent},
+	{Method: http.MethodPost, Path: "/api/v1/admin/media", Tag: tagUploads, Summary: "Upload an image", Auth: AuthAdmin, Description: importerRole, RequestContentType: "image/*", Status: http.StatusCreated, Response: model.Media{}},
+
+	{Method: http.MethodGet, Path: "/api/v1/admin/audit", Tag: tagAccount, Summary: "List admin changes a page at a time", Auth: AuthAdmin, Description: anyRole, Query: append([]Param{
+		{Name: "action", Type: "string"},
+		{Name: "slug", Type: "string"},
+		{Name: "actor", Type: "string"},
+		{Name: "since", Type: "string", Description: "RFC 3339."},
+		{Name: "until", Type: "string", Description: "RFC 3339."},
+	}, pageParams...), Response: model.AdminAuditListResponse{}},
+	{Method: http.MethodGet, Path: "/api/v1/admin/users", Tag: tagAccount, Summary: "List admin users", Auth: AuthBootstrap, Description: bootstrapOnly, Response: model.AdminUsersListResponse{}},
+	{Method: http.MethodPost, Path: "/api/v1/admin/users", Tag: tagAccount, Summary: "Create an admin user and their key", Auth: AuthBootstrap, Description: bootstrapOnly, Request: userCreate{}, Status: http.StatusCreated, Response: model.AdminUserCreateResponse{}},
+	{Method: http.MethodDelete, Path: "/api/v1/admin/users/{id}", Tag: tagAccount, Summary: "Disable an admin user", Auth: AuthBootstrap, Description: bootstrapOnly, Response: model.AdminUserRecord{}},
+	{Method: http.MethodGet, Path: "/api/v1/admin/webhooks", Tag: tagAccount, Summary: "List webhooks", Auth: AuthBootstrap, Description: bootstrapOnly, Response: model.AdminWebhooksListResponse{}},
+	{Method: http.MethodPost, Path: "/api/v1/admin/webhooks", Tag: tagAccount, Summary: "Create a webhook and its signing secret", Auth: AuthBootstrap, Description: bootstrapOnly, Request: webhookCreate{}, Status: http.StatusCreated, Response: model.AdminWebhookCreateResponse{}},
+	{Method: http.MethodPatch, Path: "/api/v1/admin/webhooks/{id}", Tag: tagAccount, Summary: "Change a webhook", Auth: AuthBootstrap, Description: bootstrapOnly, Request: webhookUpdate{}, Response: model.AdminWebhook{}},
+	{Method: http.MethodDelete, Path: "/api/v1/admin/webhooks/{id}", Tag: tagAccount, Summary: "Delete a webhook", Auth: AuthBootstrap, Description: bootstrapOnly, Status: http.StatusNoContent},
+	{
+		Method: http.MethodGet, Path: "/api/v1/admin/webhooks/{id}/deliveries", Tag: tagAccount, Summary: "List a webhook's recent deliveries", Auth: AuthBootstrap, Description: bootstrapOnly,
+		Query:    []Param{{Name: "limit", Type: "integer"}},
+		Response: model.AdminWebhookDeliveriesResponse{},
+	},
+	{Method: http.MethodGet, Path: "/api/v1/admin/settings/hyphenation", Tag: tagAccount, Summary: "Read whether the Reader hyphenates", Auth: AuthAdmin, Description: editorRole, Response: model.AdminHyphenation{}},
+	{Method: http.MethodPut, Path: "/api/v1/admin/settings/hyphenation", Tag: tagAccount, Summary: "Turn hyphenation on or off", Auth: AuthAdmin, Description: editorRole, Request: hyphenationChange{}, Response: model.AdminHyphenation{}},
+	{Method: http.MethodGet, Path: "/api/v1/admin/settings/version-retention", Tag: tagAccount, Summary: "Read how many versions each story keeps", Auth: AuthBootstrap, Description: bootstrapOnly, Response: model.AdminVersionRetention{}},
+	{Method: http.MethodPut, Path: "/api/v1/admin/settings/version-retention", Tag: tagAccount, Summary: "Set how many versions each story keeps", Auth: AuthBootstrap, Description: bootstrapOnly, Request: model.AdminVersionRetention{}, Response: model.AdminVersionRetention{}},
+	{Method: http.MethodPost, Path: "/api/v1/admin/render-jobs", Tag: tagAccount, Summary: "Re-render every story in the background", Auth: AuthBootstrap, Description: bootstrapOnly, Status: http.StatusAccepted, Response: model.RenderJob{}},
+	{Method: http.MethodGet, Path: "/api/v1/admin/render-jobs/{id}", Tag: tagAccount, Summary: "Read a re-render job's progress", Auth: AuthBootstrap, Description: bootstrapOnly, Response: model.RenderJob{}},
+	{
+		Method: http.MethodGet, Path: "/api/v1/admin/render-jobs/{id}/events", Tag: tagAccount, Summary: "Follow a re-render job's progress live", Auth: AuthBootstrap,
+		Description:         bootstrapOnly + " Server-sent events: progress whenever the job's counts move, then done, after which the stream closes. Each event's data is the RenderJob.",
+		ResponseContentType: "text/event-stream",
+	},
+	{Method: http.MethodGet, Path: "/api/v1/admin/backup", Tag: tagAccount, Summary: "Download the account as a .tar.gz backup", Auth: AuthBootstrap, Description: bootstrapOnly + " With a blob store that signs URLs, the archive is stored there and answered with 302 to a short-lived download link.", ResponseContentType: "application/gzip"},
+	{
+		Method: http.MethodPost, Path: "/api/v1/admin/restore", Tag: tagAccount, Summary: "Restore a backup into the account", Auth: AuthBootstrap, Description: bootstrapOnly,
+		Query:              []Param{{Name: "conflict", Type: "string", Description: "skip, the default, overwrite or new-slug."}},
+		RequestContentType: "application/gzip", Response: model.AdminRestoreResponse{},
+	},
+
+	{Method: http.MethodGet, Path: "/api/v1/admin/maintenance", Tag: tagOperator, Summary: "Read whether maintenance mode is on", Auth: AuthAdmin, Description: anyRole, Response: model.MaintenanceState{}},
+	{Method: http.MethodPut, Path: "/api/v1/admin/maintenance", Tag: tagOperator, Summary: "Turn maintenance mode on or off", Auth: AuthBootstrap, Description: bootstrapOnly, Request: maintenanceChange{}, Response: model.MaintenanceState{}},
+	{Method: http.MethodGet, Path: "/api/v1/admin/debug/db", Tag: tagOperator, Summary: "Read connection pool and cache statistics", Auth: AuthBootstrap, Description: bootstrapOnly, Response: model.DatabaseStats{}},
+	{Method: http.MethodGet, Path: "/api/v1/admin/debug/queries", Tag: tagOperator, Summary: "Read query latency histograms", Auth: AuthBootstrap, Description: bootstrapOnly, Response: model.QueryLatencyResponse{}},
+	{Method: http.MethodGet, Path: "/api/v1/admin/debug/loglevel", Tag: tagOperator, Summary: "Read the log level", Auth: AuthBootstrap, Description: bootstrapOnly, Response: model.LogLevel{}},
+	{Method: http.MethodPut, Path: "/api/v1/admin/debug/loglevel", Tag: tagOperator, Summary: "Change the log level until the next restart", Auth: AuthBootstrap, Description: bootstrapOnly, Request: model.LogLevel{}, Response: model.LogLevel{}},
+}
@@ -0,0 +1,148 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var (
+	timeType       = reflect.TypeFor[time.Time]()
+	rawMessageType = reflect.TypeFor[json.RawMessage]()
+)
+
+// schemaBuilder turns Go types into schemas the way encoding/json would
+// encode them. Named structs become components referenced by name.
+type schemaBuilder struct {
+	components map[string]any
+	named      map[reflect.Type]string
+}
+
+func newSchemaBuilder() *schemaBuilder {
+	return &schemaBuilder{components: map[string]any{}, named: map[reflect.Type]string{}}
+}
+
+// of returns the schema of value's type.
+func (b *schemaBuilder) of(value any) map[string]any {
+	return b.schema(reflect.TypeOf(value))
+}
+
+func (b *schemaBuilder) schema(t reflect.Type) map[string]any {
+	switch {
+	case t == timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case t == rawMessageType:
+		return map[string]any{} // any JSON value
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return nullable(b.schema(t.Elem()))
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return map[string]any{"type": "integer"}
+	case reflect.Int64, reflect.Uint64:
+		return map[string]any{"type": "integer", "format": "int64"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]any{"type": "string", "format": "byte"}
+		}
+		return map[string]any{"type": "array", "items": b.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": b.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return b.object(t)
+		}
+		return b.ref(t)
+	default: // interfaces
+		return map[string]any{}
+	}
+}
+
+// ref registers a named struct as a component. Its name is the Go type's,
+// capitalised, and qualified by package only when two packages share it.
+func (b *schemaBuilder) ref(t reflect.Type) map[string]any {
+	name, ok := b.named[t]
+	if !ok {
+		name = capitalize(typeName(t))
+		if _, taken := b.components[name]; taken {
+			name = capitalize(t.PkgPath()[strings.LastIndex(t.PkgPath(), "/")+1:]) + name
+		}
+		b.named[t] = name
+		b.components[name] = map[string]any{} // placeholder for recursive types
+		b.components[name] = b.object(t)
+	}
+	return map[string]any{"$ref": "#/components/schemas/" + name}
+}
+
+func (b *schemaBuilder) object(t reflect.Type) map[string]any {
+	properties := map[string]any{}
+	var required []string
+	b.fields(t, properties, &required)
+	out := map[string]any{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		out["required"] = required
+	}
+	return out
+}
+
+// fields adds t's encoded fields, flattening embedded structs as
+// encoding/json does. A field without omitempty is always present.
+func (b *schemaBuilder) fields(t reflect.Type, properties map[string]any, required *[]string) {
+	for field := range t.Fields() {
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			b.fields(field.Type, properties, required)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		properties[name] = b.schema(field.Type)
+		if !strings.Contains(options, "omitempty") && !strings.Contains(options, "omitzero") {
+			*required = append(*required, name)
+		}
+	}
+}
+
+// nullable marks a schema as also accepting null. A reference cannot carry
+// siblings in OpenAPI 3.0, so it is wrapped.
+func nullable(schema map[string]any) map[string]any {
+	if _, ok := schema["$ref"]; ok {
+		return map[string]any{"allOf": []any{schema}, "nullable": true}
+	}
+	schema["nullable"] = true
+	return schema
+}
+
+// typeName is t's name without type arguments, which reflect spells out
+// with their package paths: Page[pkg/model.StoryItem] becomes StoryItemPage.
+func typeName(t reflect.Type) string {
+	name, args, generic := strings.Cut(t.Name(), "[")
+	if !generic {
+		return name
+	}
+	prefix := ""
+	for _, arg := range strings.Split(strings.TrimSuffix(args, "]"), ",") {
+		prefix += capitalize(arg[strings.LastIndex(arg, ".")+1:])
+	}
+	return prefix + name
+}
+
+func capitalize(name string) string {
+	return strings.ToUpper(name[:1]) + name[1:]
+}
@@ -0,0 +1,250 @@
+// Package phonics scores stories against a phonics progression: the order in
+// which a scheme teaches letter-sound sets to early readers. A story is
+// decodable by a set when nearly all of its words can be sounded out with
+// that set and the ones before it, or are tricky words already taught, so a
+// family can pick books a child can read on their own.
+package phonics
+
+import (
+	"bytes"
+	"crypto/sha256"
+	_ "embed"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"pandapages/api/internal/model"
+)
+
+const (
+	// DecodableShare is the share of a story's words, counted each time
+	// they appear, a child must be able to read for it to be decodable.
+	// Schemes ask that early books be 90% to 100% decodable.
+	DecodableShare = 0.9
+
+	maxSets         = 50
+	maxSetNameRunes = 80
+	maxUndecodable  = 20
+)
+
+//go:embed letters_and_sounds.json
+var lettersAndSounds []byte
+
+var (
+	nameRe     = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,62}$`)
+	languageRe = regexp.MustCompile(`^[a-z]{2,3}$`)
+	graphemeRe = regexp.MustCompile(`^\p{Ll}{1,4}$`)
+	trickyRe   = regexp.MustCompile(`^\p{Ll}+(?:'\p{Ll}+)?$`)
+)
+
+// Progression is a validated progression ready to score stories.
+type Progression struct {
+	spec model.PhonicsProgression
+	key  string
+	// graphemes and tricky give the first set, numbered from 1, teaching
+	// each grapheme and tricky word.
+	graphemes map[string]int
+	tricky    map[string]int
+	longest   int
+}
+
+// Load returns the progression in the JSON file PP_PHONICS_PROGRESSION
+// names, the bundled Letters and Sounds progression when it is unset, or nil
+// when it is off.
+func Load(getenv func(string) string) (*Progression, error) {
+	path := strings.TrimSpace(getenv("PP_PHONICS_PROGRESSION"))
+	switch path {
+	case "":
+		return Parse(lettersAndSounds)
+	case "off":
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("PP_PHONICS_PROGRESSION could not be read")
+	}
+	progression, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("PP_PHONICS_PROGRESSION: %w", err)
+	}
+	return progression, nil
+}
+
+// Parse reads and validates a progression. Graphemes and tricky words are
+// lowercased, since stories are scored by their lowercased words.
+func Parse(data []byte) (*Progression, error) {
+	var raw model.PhonicsProgression
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&raw); err != nil {
+		return nil, fmt.Errorf("progression is not valid JSON")
+	}
+	if !nameRe.MatchString(raw.Name) {
+		return nil, fmt.Errorf("progression name must be lowercase letters, digits and hyphens")
+	}
+	if !languageRe.MatchString(raw.Language) {
+		return nil, fmt.Errorf("progression language must be a primary language subtag such as en")
+	}
+	if len(raw.Sets) == 0 || len(raw.Sets) > maxSets {
+		return nil, fmt.Errorf("progression must have 1 to %d sets", maxSets)
+	}
+	p := &Progression{
+		spec:      model.PhonicsProgression{Name: raw.Name, Language: raw.Language, Sets: make([]model.PhonicsSet, len(raw.Sets))},
+		graphemes: map[string]int{},
+		tricky:    map[string]int{},
+	}
+	for index, set := range raw.Sets {
+		number := index + 1
+		out := model.PhonicsSet{Name: strings.TrimSpace(set.Name), Graphemes: []string{}, TrickyWords: []string{}}
+		if out.Name == "" || utf8.RuneCountInString(out.Name) > maxSetNameRunes {
+			return nil, fmt.Errorf("set %d must have a name of 1 to %d characters", number, maxSetNameRunes)
+		}
+		for _, grapheme := range set.Graphemes {
+			grapheme = strings.ToLower(strings.TrimSpace(grapheme))
+			if !graphemeRe.MatchString(grapheme) {
+				return nil, fmt.Errorf("set %d grapheme %q must be 1 to 4 letters", number, grapheme)
+			}
+			out.Graphemes = append(out.Graphemes, grapheme)
+			if _, ok := p.graphemes[grapheme]; !ok {
+				p.graphemes[grapheme] = number
+			}
+			p.longest = max(p.longest, utf8.RuneCountInString(grapheme))
+		}
+		for _, word := range set.TrickyWords {
+			word = strings.ToLower(strings.TrimSpace(strings.ReplaceAll(word, "’", "'")))
+			if !trickyRe.MatchString(word) {
+				return nil, fmt.Errorf("set %d tricky word %q must be one word", number, word)
+			}
+			out.TrickyWords = append(out.TrickyWords, word)
+			if _, ok := p.tricky[word]; !ok {
+				p.tricky[word] = number
+			}
+		}
+		if len(out.Graphemes) == 0 && len(out.TrickyWords) == 0 {
+			return nil, fmt.Errorf("set %d must teach a grapheme or a tricky word", number)
+		}
+		p.spec.Sets[index] = out
+	}
+	canonical, err := json.Marshal(p.spec)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(canonical)
+	p.key = p.spec.Name + "@" + hex.EncodeToString(sum[:6])
+	return p, nil
+}
+
+// Key names the progression and its contents. Analyses are stored under it,
+// so editing the progression has every story scored again.
+func (p *Progression) Key() string {
+	return p.key
+}
+
+// Name identifies the progression in the startup summary.
+func (p *Progression) Name() string {
+	return p.spec.Name
+}
+
+// Language is the primary language of the stories the progression scores.
+func (p *Progression) Language() string {
+	return p.spec.Language
+}
+
+// SetCount is the number of sets, numbered from 1.
+func (p *Progression) SetCount() int {
+	return len(p.spec.Sets)
+}
+
+// Model returns the progression as the API shows it.
+func (p *Progression) Model() model.PhonicsProgression {
+	return p.spec
+}
+
+// WordSet is the first set by which a child could read word, or 0 when no
+// set covers it. A word is covered when it is a tricky word or can be
+// spelled with the progression's graphemes. It is spelled the way a scheme
+// teaches it to be sounded out, with as few graphemes as possible, so
+// "night" is n-igh-t and needs the set that teaches igh; the set is the
+// latest among them. Apostrophes are silent.
+func (p *Progression) WordSet(word string) int {
+	word = strings.ToLower(strings.ReplaceAll(word, "’", "'"))
+	best := p.tricky[word]
+	letters := []rune(strings.ReplaceAll(word, "'", ""))
+	if len(letters) == 0 {
+		return best
+	}
+	// spellings[i] is the shortest spelling of the first i letters and, of
+	// those, the one taught earliest; a zero parts means none.
+	type spelling struct{ parts, set int }
+	spellings := make([]spelling, len(letters)+1)
+	for end := 1; end <= len(letters); end++ {
+		for size := 1; size <= p.longest && size <= end; size++ {
+			before := spellings[end-size]
+			if end-size > 0 && before.parts == 0 {
+				continue
+			}
+			set, ok := p.graphemes[string(letters[end-size:end])]
+			if !ok {
+				continue
+			}
+			candidate := spelling{parts: before.parts + 1, set: max(before.set, set)}
+			current := spellings[end]
+			if current.parts == 0 || candidate.parts < current.parts || (candidate.parts == current.parts && candidate.set < current.set) {
+				spellings[end] = candidate
+			}
+		}
+	}
+	if spelled := spellings[len(letters)]; spelled.parts > 0 && (best == 0 || spelled.set < best) {
+		best = spelled.set
+	}
+	return best
+}
+
+// Analyze scores a story version by its word frequencies, as stored with its
+// vocabulary.
+func (p *Progression) Analyze(frequencies map[string]int) model.PhonicsAnalysis {
+	out := model.PhonicsAnalysis{Progression: p.key, Sets: make([]model.PhonicsSetScore, len(p.spec.Sets)), Undecodable: []string{}}
+	// covered[n] counts the words first readable at set n.
+	covered := make([]int, len(p.spec.Sets)+1)
+	var undecodable []string
+	for word, count := range frequencies {
+		if count <= 0 {
+			continue
+		}
+		out.Words += count
+		if set := p.WordSet(word); set > 0 {
+			covered[set] += count
+		} else {
+			undecodable = append(undecodable, word)
+		}
+	}
+	readable := 0
+	for index := range p.spec.Sets {
+		readable += covered[index+1]
+		share := 0.0
+		if out.Words > 0 {
+			share = float64(readable) / float64(out.Words)
+		}
+		out.Sets[index] = model.PhonicsSetScore{Set: index + 1, DecodableShare: share}
+		if out.DecodableSet == nil && out.Words > 0 && share >= DecodableShare {
+			set := index + 1
+			out.DecodableSet = &set
+		}
+	}
+	sort.Slice(undecodable, func(i, j int) bool {
+		if frequencies[undecodable[i]] != frequencies[undecodable[j]] {
+			return frequencies[undecodable[i]] > frequencies[undecodable[j]]
+		}
+		return undecodable[i] < undecodable[j]
+	})
+	if len(undecodable) > maxUndecodable {
+		undecodable = undecodable[:maxUndecodable]
+	}
+	out.Undecodable = append(out.Undecodable, undecodable...)
+	return out
+}
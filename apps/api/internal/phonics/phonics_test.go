@@ -0,0 +1,154 @@
+package phonics
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"pandapages/api/internal/model"
+)
+
+func testProgression(t *testing.T) *Progression {
+	t.Helper()
+	progression, err := Parse([]byte(`{"name":"test","language":"en","sets":[
+		{"name":"One","graphemes":["s","a","t","p"],"trickyWords":[]},
+		{"name":"Two","graphemes":["i","n","m","d","sh"],"trickyWords":["The"]},
+		{"name":"Three","graphemes":["o","g","igh"],"trickyWords":["I"]}
+	]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return progression
+}
+
+func TestWordSetFindsTheEarliestSpelling(t *testing.T) {
+	progression := testProgression(t)
+	tests := map[string]int{
+		"sat":   1,
+		"tap's": 1,
+		"pin":   2,
+		"ship":  2,
+		"the":   2,
+		"i":     2, // the grapheme comes before the tricky word
+		"sigh":  3,
+		"dog":   3,
+		"The":   2,
+		"cat":   0,
+		"ship2": 0,
+	}
+	for word, want := range tests {
+		if got := progression.WordSet(word); got != want {
+			t.Fatalf("WordSet(%q) = %d, want %d", word, got, want)
+		}
+	}
+}
+
+func TestAnalyzeFindsTheFirstDecodableSet(t *testing.T) {
+	progression := testProgression(t)
+	analysis := progression.Analyze(map[string]int{"sat": 5, "the": 3, "pin": 1, "dog": 10, "cat": 1, "zebra": 1, "ignored": 0})
+	if analysis.Words != 21 || analysis.Progression != progression.Key() {
+		t.Fatalf("analysis = %#v", analysis)
+	}
+	if len(analysis.Sets) != 3 || analysis.Sets[0].DecodableShare != 5.0/21 || analysis.Sets[1].DecodableShare != 9.0/21 || analysis.Sets[2].DecodableShare != 19.0/21 {
+		t.Fatalf("sets = %#v", analysis.Sets)
+	}
+	if analysis.DecodableSet == nil || *analysis.DecodableSet != 3 || strings.Join(analysis.Undecodable, ",") != "cat,zebra" {
+		t.Fatalf("decodable = %v, undecodable = %v", analysis.DecodableSet, analysis.Undecodable)
+	}
+
+	if empty := progression.Analyze(nil); empty.DecodableSet != nil || empty.Words != 0 || len(empty.Sets) != 3 {
+		t.Fatalf("empty = %#v", empty)
+	}
+}
+
+func TestParseRejectsUnusableProgressions(t *testing.T) {
+	tests := map[string]string{
+		"json":     `{"name":"x","language":"en","sets":[],"extra":true}`,
+		"name":     `{"name":"Letters And Sounds","language":"en","sets":[{"name":"One","graphemes":["s"]}]}`,
+		"language": `{"name":"x","language":"en-GB","sets":[{"name":"One","graphemes":["s"]}]}`,
+		"sets":     `{"name":"x","language":"en","sets":[]}`,
+		"setName":  `{"name":"x","language":"en","sets":[{"name":" ","graphemes":["s"]}]}`,
+		"grapheme": `{"name":"x","language":"en","sets":[{"name":"One","graphemes":["a_e"]}]}`,
+		"tricky":   `{"name":"x","language":"en","sets":[{"name":"One","trickyWords":["two words"]}]}`,
+		"empty":    `{"name":"x","language":"en","sets":[{"name":"One","graphemes":[],"trickyWords":[]}]}`,
+	}
+	for name, raw := range tests {
+		if _, err := Parse([]byte(raw)); err == nil {
+			t.Fatalf("%s: Parse accepted %s", name, raw)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	bundled, err := Load(func(string) string { return "" })
+	if err != nil || bundled == nil || bundled.Name() != "letters-and-sounds" || bundled.Language() != "en" || bundled.SetCount() != 13 {
+		t.Fatalf("bundled = %v, %v", bundled, err)
+	}
+	if bundled.WordSet("cat") != 3 || bundled.WordSet("the") != 4 || bundled.WordSet("night") != 9 {
+		t.Fatalf("bundled word sets = %d %d %d", bundled.WordSet("cat"), bundled.WordSet("the"), bundled.WordSet("night"))
+	}
+	if off, err := Load(func(string) string { return "off" }); off != nil || err != nil {
+		t.Fatalf("off = %v, %v", off, err)
+	}
+
+	path := filepath.Join(t.TempDir(), "phonics.json")
+	if err := os.WriteFile(path, []byte(`{"name":"letters-and-sounds","language":"en","sets":[{"name":"One","graphemes":["s","a","t","p"]}]}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	custom, err := Load(func(string) string { return path })
+	if err != nil || custom.SetCount() != 1 {
+		t.Fatalf("custom = %v, %v", custom, err)
+	}
+	// The same name with other sets scores stories again.
+	if custom.Key() == bundled.Key() || !strings.HasPrefix(custom.Key(), "letters-and-sounds@") {
+		t.Fatalf("keys = %q, %q", custom.Key(), bundled.Key())
+	}
+	if _, err := Load(func(string) string { return filepath.Join(t.TempDir(), "missing.json") }); err == nil || !strings.Contains(err.Error(), "PP_PHONICS_PROGRESSION") {
+		t.Fatalf("missing file error = %v", err)
+	}
+}
+
+type fakeStore struct {
+	pending  [][]model.PhonicsSource
+	saved    []model.PhonicsAnalysis
+	saveErr  error
+	language string
+}
+
+func (s *fakeStore) PhonicsPendingVersions(_ context.Context, _, language string, _ int) ([]model.PhonicsSource, error) {
+	s.language = language
+	if len(s.pending) == 0 {
+		return nil, nil
+	}
+	next := s.pending[0]
+	s.pending = s.pending[1:]
+	return next, nil
+}
+
+func (s *fakeStore) PhonicsSave(_ context.Context, _ model.PhonicsSource, analysis model.PhonicsAnalysis) error {
+	if s.saveErr != nil {
+		return s.saveErr
+	}
+	s.saved = append(s.saved, analysis)
+	return nil
+}
+
+func TestWorkerScoresEveryPendingBatch(t *testing.T) {
+	store := &fakeStore{pending: [][]model.PhonicsSource{
+		{{VersionID: "a", Frequencies: map[string]int{"sat": 1}}, {VersionID: "b"}},
+		{{VersionID: "c", Frequencies: map[string]int{"dog": 1}}},
+	}}
+	NewWorker(store, testProgression(t)).RunOnce(t.Context())
+	if len(store.saved) != 3 || store.language != "en" || *store.saved[0].DecodableSet != 1 || store.saved[1].DecodableSet != nil {
+		t.Fatalf("saved = %#v", store.saved)
+	}
+
+	store = &fakeStore{pending: [][]model.PhonicsSource{{{VersionID: "a"}}, {{VersionID: "b"}}}, saveErr: errors.New("down")}
+	NewWorker(store, testProgression(t)).RunOnce(t.Context())
+	if len(store.pending) != 1 {
+		t.Fatalf("a failed save did not stop the run: %d batches left", len(store.pending))
+	}
+}
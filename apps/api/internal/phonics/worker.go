@@ -0,0 +1,71 @@
+package phonics
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"pandapages/api/internal/model"
+)
+
+const (
+	defaultInterval = 30 * time.Second
+	batchSize       = 64
+)
+
+type Store interface {
+	PhonicsPendingVersions(ctx context.Context, progression, language string, limit int) ([]model.PhonicsSource, error)
+	PhonicsSave(ctx context.Context, source model.PhonicsSource, analysis model.PhonicsAnalysis) error
+}
+
+type Worker struct {
+	store       Store
+	progression *Progression
+	interval    time.Duration
+	batch       int
+}
+
+func NewWorker(store Store, progression *Progression) *Worker {
+	return &Worker{store: store, progression: progression, interval: defaultInterval, batch: batchSize}
+}
+
+// Run scores new story versions until ctx is cancelled.
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		w.RunOnce(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce scores batches of versions in the progression's language that have
+// no analysis under its key until none are left or a save fails; a failed
+// version is tried again on the next run.
+func (w *Worker) RunOnce(ctx context.Context) {
+	key := w.progression.Key()
+	for ctx.Err() == nil {
+		sources, err := w.store.PhonicsPendingVersions(ctx, key, w.progression.Language(), w.batch)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			slog.Error("phonics pending versions read failed")
+			return
+		}
+		if len(sources) == 0 {
+			return
+		}
+		for _, source := range sources {
+			if err := w.store.PhonicsSave(ctx, source, w.progression.Analyze(source.Frequencies)); err != nil {
+				slog.Error("phonics save failed", "version", source.VersionID)
+				return
+			}
+		}
+		slog.Info("story versions scored for phonics", "versions", len(sources))
+	}
+}
@@ -0,0 +1,13 @@
+// Package providerlog logs failed calls to outside providers: language
+// models, moderation, text-to-speech, speech recognition and embeddings.
+package providerlog
+
+import "log/slog"
+
+// Failure logs msg as a warning naming provider, with args as extra
+// attributes. It takes no error on purpose: a provider's errors can embed
+// its URL, and with it a key in the query string, so the log keeps the fixed
+// category msg names instead.
+func Failure(msg, provider string, args ...any) {
+	slog.Warn(msg, append(args, "provider", provider)...)
+}
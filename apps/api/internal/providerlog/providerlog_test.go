@@ -0,0 +1,24 @@
+package providerlog
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+)
+
+func TestFailureNamesTheProviderAfterTheOtherAttributes(t *testing.T) {
+	var out bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&out, &slog.HandlerOptions{ReplaceAttr: func(_ []string, attr slog.Attr) slog.Attr {
+		if attr.Key == slog.TimeKey {
+			return slog.Attr{}
+		}
+		return attr
+	}})))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
+	Failure("narration segment failed", "openai", "job", "job-id", "ordinal", 3)
+	if got, want := out.String(), "level=WARN msg=\"narration segment failed\" job=job-id ordinal=3 provider=openai\n"; got != want {
+		t.Fatalf("log = %q, want %q", got, want)
+	}
+}
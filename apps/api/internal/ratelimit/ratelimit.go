@@ -0,0 +1,111 @@
+// Package ratelimit keeps one token bucket per client key. A bucket holds a
+// minute's worth of requests and refills continuously, so a client may spend
+// its whole budget in a burst but then proceeds at the sustained rate.
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often idle buckets are dropped. A bucket that has
+// refilled completely is indistinguishable from a new one.
+const sweepInterval = time.Minute
+
+// Decision is the outcome of one request against a client's bucket.
+type Decision struct {
+	Allowed bool
+	// Limit is the bucket's capacity and Remaining the whole requests left
+	// in it after this one.
+	Limit     int
+	Remaining int
+	// Reset is how long until the bucket is full again; for a refused
+	// request, RetryAfter is how long until the next one would be allowed.
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+type Limiter struct {
+	limit int
+	rate  float64 // tokens per second
+	now   func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+// New returns a limiter allowing perMinute requests per minute per key, or
+// nil, which allows everything, when perMinute is not positive.
+func New(perMinute int) *Limiter {
+	if perMinute <= 0 {
+		return nil
+	}
+	return &Limiter{
+		limit:   perMinute,
+		rate:    float64(perMinute) / 60,
+		now:     time.Now,
+		buckets: map[string]*bucket{},
+	}
+}
+
+// Allow takes one token from key's bucket if it has one.
+func (l *Limiter) Allow(key string) Decision {
+	if l == nil {
+		return Decision{Allowed: true}
+	}
+	now := l.now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(l.limit), last: now}
+		l.buckets[key] = b
+	}
+	b.refill(now, l.rate, float64(l.limit))
+
+	decision := Decision{Limit: l.limit}
+	if b.tokens >= 1 {
+		b.tokens--
+		decision.Allowed = true
+	} else {
+		decision.RetryAfter = l.wait(1 - b.tokens)
+	}
+	decision.Remaining = int(math.Floor(b.tokens))
+	decision.Reset = l.wait(float64(l.limit) - b.tokens)
+	return decision
+}
+
+// wait is how long the bucket takes to gain tokens.
+func (l *Limiter) wait(tokens float64) time.Duration {
+	return time.Duration(math.Ceil(tokens / l.rate * float64(time.Second)))
+}
+
+func (b *bucket) refill(now time.Time, rate, capacity float64) {
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens = min(capacity, b.tokens+elapsed*rate)
+	}
+	b.last = now
+}
+
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < sweepInterval {
+		return
+	}
+	l.lastSweep = now
+	for key, b := range l.buckets {
+		b.refill(now, l.rate, float64(l.limit))
+		if b.tokens >= float64(l.limit) {
+			delete(l.buckets, key)
+		}
+	}
+}
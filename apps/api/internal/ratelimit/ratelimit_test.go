@@ -0,0 +1,67 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func testLimiter(perMinute int, now *time.Time) *Limiter {
+	limiter := New(perMinute)
+	limiter.now = func() time.Time { return *now }
+	return limiter
+}
+
+func TestLimiterAllowsABurstThenTheSustainedRate(t *testing.T) {
+	now := time.Date(2026, 10, 16, 7, 0, 0, 0, time.UTC)
+	limiter := testLimiter(6, &now)
+
+	for request := 1; request <= 6; request++ {
+		decision := limiter.Allow("reader")
+		if !decision.Allowed || decision.Remaining != 6-request || decision.Limit != 6 {
+			t.Fatalf("request %d = %#v", request, decision)
+		}
+	}
+	refused := limiter.Allow("reader")
+	if refused.Allowed || refused.Remaining != 0 || refused.RetryAfter != 10*time.Second || refused.Reset != time.Minute {
+		t.Fatalf("refused = %#v", refused)
+	}
+	if other := limiter.Allow("someone-else"); !other.Allowed {
+		t.Fatalf("another key shared the bucket: %#v", other)
+	}
+
+	now = now.Add(10 * time.Second)
+	if decision := limiter.Allow("reader"); !decision.Allowed || decision.Remaining != 0 {
+		t.Fatalf("after one refill interval = %#v", decision)
+	}
+	if decision := limiter.Allow("reader"); decision.Allowed {
+		t.Fatalf("second request after one refill interval = %#v", decision)
+	}
+}
+
+func TestLimiterForgetsIdleBuckets(t *testing.T) {
+	now := time.Date(2026, 10, 16, 7, 0, 0, 0, time.UTC)
+	limiter := testLimiter(60, &now)
+	limiter.Allow("idle")
+	limiter.Allow("busy")
+
+	now = now.Add(2 * time.Minute)
+	for range 60 {
+		limiter.Allow("busy")
+	}
+	if _, ok := limiter.buckets["idle"]; ok {
+		t.Fatal("idle bucket was kept after refilling")
+	}
+	if _, ok := limiter.buckets["busy"]; !ok {
+		t.Fatal("busy bucket was dropped")
+	}
+}
+
+func TestNilLimiterAllowsEverything(t *testing.T) {
+	var limiter *Limiter
+	if New(0) != nil {
+		t.Fatal("New(0) returned a limiter")
+	}
+	if decision := limiter.Allow("anyone"); !decision.Allowed {
+		t.Fatalf("nil limiter refused: %#v", decision)
+	}
+}
@@ -16,7 +16,18 @@ const (
 	SegmentKindHeading   SegmentKind = "heading"
 	SegmentKindParagraph SegmentKind = "paragraph"
 	SegmentKindOther     SegmentKind = "other"
-	canonicalSeparator               = '\x1f'
+	// SegmentKindPageBreak is an authored page boundary. It has no visible
+	// content but keeps an identity so progress can land on it.
+	SegmentKindPageBreak SegmentKind = "pagebreak"
+	// SegmentKindVerse is a poem or rhyme whose line breaks are kept.
+	SegmentKindVerse SegmentKind = "verse"
+	// SegmentKindAside is a note for grown-ups set beside the story, which
+	// kid mode leaves out.
+	SegmentKindAside SegmentKind = "aside"
+	// SegmentKindSceneBreak divides two scenes. Like a page break it has no
+	// words, but the Reader draws it and read-aloud pauses on it.
+	SegmentKindSceneBreak SegmentKind = "scene-break"
+	canonicalSeparator                = '\x1f'
 )
 
 var (
@@ -24,6 +35,36 @@ var (
 	ErrLocatorMismatch = errors.New("reader locator does not match the selected story version")
 )
 
+// DefaultChapterLevel is the heading level that opens a chapter when a story
+// does not choose another.
+const DefaultChapterLevel = 2
+
+// ChapterRule decides which headings open a chapter: headings at Level whose
+// text matches Pattern, when one is set. The zero value is every H2.
+type ChapterRule struct {
+	Level   int
+	Pattern *regexp.Regexp
+}
+
+func (rule ChapterRule) level() int {
+	if rule.Level == 0 {
+		return DefaultChapterLevel
+	}
+	return rule.Level
+}
+
+// Opens reports whether a segment opens a chapter under the rule. Pattern is
+// matched against the heading text without its leading # markers.
+func (rule ChapterRule) Opens(kind SegmentKind, headingLevel int, markdown string) bool {
+	if kind != SegmentKindHeading || headingLevel != rule.level() {
+		return false
+	}
+	if rule.Pattern == nil {
+		return true
+	}
+	return rule.Pattern.MatchString(strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(markdown), "#")))
+}
+
 type SegmentIdentityInput struct {
 	Kind         SegmentKind
 	HeadingLevel *int
@@ -91,7 +132,8 @@ func validateIdentityInput(input SegmentIdentityInput) (int, error) {
 			return 0, fmt.Errorf("heading level must be between 1 and 6")
 		}
 		return *input.HeadingLevel, nil
-	case SegmentKindParagraph, SegmentKindOther:
+	case SegmentKindParagraph, SegmentKindOther, SegmentKindPageBreak, SegmentKindVerse, SegmentKindAside,
+		SegmentKindSceneBreak:
 		if input.HeadingLevel != nil {
 			return 0, fmt.Errorf("heading level is only valid for heading segments")
 		}
@@ -101,9 +143,9 @@ func validateIdentityInput(input SegmentIdentityInput) (int, error) {
 	}
 }
 
-// AssignSegmentIdentities computes version-scoped content and H2 chapter
-// occurrences in stable segment order.
-func AssignSegmentIdentities(inputs []SegmentIdentityInput) ([]SegmentIdentity, error) {
+// AssignSegmentIdentities computes version-scoped content and chapter
+// occurrences in stable segment order, opening chapters by rule.
+func AssignSegmentIdentities(inputs []SegmentIdentityInput, rule ChapterRule) ([]SegmentIdentity, error) {
 	identities := make([]SegmentIdentity, 0, len(inputs))
 	contentOccurrences := make(map[string]int)
 	chapterOccurrences := make(map[string]int)
@@ -125,7 +167,7 @@ func AssignSegmentIdentities(inputs []SegmentIdentityInput) ([]SegmentIdentity,
 			ContentOccurrence: contentOccurrences[key],
 		}
 
-		if input.Kind == SegmentKindHeading && headingLevel == 2 {
+		if rule.Opens(input.Kind, headingLevel, input.Markdown) {
 			chapterOccurrences[key]++
 			chapterKey := key
 			chapterOccurrence := chapterOccurrences[key]
@@ -148,8 +190,11 @@ func AssignSegmentIdentities(inputs []SegmentIdentityInput) ([]SegmentIdentity,
 // ValidateStoredSegmentIdentities verifies that a stored version still obeys
 // the sequence contract produced by AssignSegmentIdentities. Content keys
 // cannot be recomputed without loading private Markdown, so this checks their
-// canonical shape and version-scoped occurrence/chapter relationships.
-func ValidateStoredSegmentIdentities(segments []StoredSegmentIdentity) (int, error) {
+// canonical shape and version-scoped occurrence/chapter relationships. Without
+// Markdown a rule's Pattern cannot be evaluated, so when one is set a heading
+// at the chapter level opens a chapter exactly when its stored chapter
+// identity is its own; equal content keys always match the pattern alike.
+func ValidateStoredSegmentIdentities(segments []StoredSegmentIdentity, rule ChapterRule) (int, error) {
 	contentOccurrences := make(map[string]int)
 	chapterOccurrences := make(map[string]int)
 	var currentChapterKey *string
@@ -176,7 +221,12 @@ func ValidateStoredSegmentIdentities(segments []StoredSegmentIdentity) (int, err
 			return 0, fmt.Errorf("segment %d: content occurrence is not sequential", index+1)
 		}
 
-		if segment.Kind == SegmentKindHeading && headingLevel == 2 {
+		opens := segment.Kind == SegmentKindHeading && headingLevel == rule.level()
+		if opens && rule.Pattern != nil {
+			opens = segment.ChapterKey != nil && *segment.ChapterKey == segment.ContentKey &&
+				segment.ChapterOccurrence != nil && *segment.ChapterOccurrence == chapterOccurrences[segment.ContentKey]+1
+		}
+		if opens {
 			chapterOccurrences[segment.ContentKey]++
 			chapterKey := segment.ContentKey
 			chapterOccurrence := chapterOccurrences[segment.ContentKey]
@@ -187,14 +237,14 @@ func ValidateStoredSegmentIdentities(segments []StoredSegmentIdentity) (int, err
 
 		if currentChapterKey == nil {
 			if segment.ChapterKey != nil || segment.ChapterOccurrence != nil {
-				return 0, fmt.Errorf("segment %d: chapter identity exists before the first H2", index+1)
+				return 0, fmt.Errorf("segment %d: chapter identity exists before the first chapter heading", index+1)
 			}
 			continue
 		}
 		if segment.ChapterKey == nil || segment.ChapterOccurrence == nil ||
 			*segment.ChapterKey != *currentChapterKey ||
 			*segment.ChapterOccurrence != *currentChapterOccurrence {
-			return 0, fmt.Errorf("segment %d: chapter identity does not match the current H2", index+1)
+			return 0, fmt.Errorf("segment %d: chapter identity does not match the current chapter heading", index+1)
 		}
 	}
 
@@ -205,28 +255,39 @@ func ValidContentKey(key string) bool {
 	return contentKeyPattern.MatchString(key)
 }
 
+// LocatorError reports the first invalid locator field. Field is its dotted
+// JSON path within the locator, such as "segment.ordinal".
+type LocatorError struct {
+	Field   string
+	Message string
+}
+
+func (e *LocatorError) Error() string {
+	return e.Message
+}
+
 func (locator Locator) Validate() error {
 	if locator.Schema != 2 {
-		return fmt.Errorf("schema must equal 2")
+		return &LocatorError{Field: "schema", Message: "schema must equal 2"}
 	}
 	if !ValidContentKey(locator.Segment.Key) {
-		return fmt.Errorf("segment key must be lowercase SHA-256 hex")
+		return &LocatorError{Field: "segment.key", Message: "segment key must be lowercase SHA-256 hex"}
 	}
 	if locator.Segment.Occurrence < 1 {
-		return fmt.Errorf("segment occurrence must be positive")
+		return &LocatorError{Field: "segment.occurrence", Message: "segment occurrence must be positive"}
 	}
 	if locator.Segment.Ordinal < 1 {
-		return fmt.Errorf("segment ordinal must be positive")
+		return &LocatorError{Field: "segment.ordinal", Message: "segment ordinal must be positive"}
 	}
 	if math.IsNaN(locator.Segment.Offset) || math.IsInf(locator.Segment.Offset, 0) || locator.Segment.Offset < 0 || locator.Segment.Offset > 1 {
-		return fmt.Errorf("segment offset must be between 0 and 1")
+		return &LocatorError{Field: "segment.offset", Message: "segment offset must be between 0 and 1"}
 	}
 	if locator.Chapter != nil {
 		if !ValidContentKey(locator.Chapter.Key) {
-			return fmt.Errorf("chapter key must be lowercase SHA-256 hex")
+			return &LocatorError{Field: "chapter.key", Message: "chapter key must be lowercase SHA-256 hex"}
 		}
 		if locator.Chapter.Occurrence < 1 {
-			return fmt.Errorf("chapter occurrence must be positive")
+			return &LocatorError{Field: "chapter.occurrence", Message: "chapter occurrence must be positive"}
 		}
 	}
 	return nil
@@ -1,6 +1,7 @@
 package readercontract
 
 import (
+	"regexp"
 	"strings"
 	"testing"
 )
@@ -79,7 +80,7 @@ func TestAssignSegmentIdentitiesUsesStableOccurrencesAndH2Chapters(t *testing.T)
 		{Kind: SegmentKindParagraph, Markdown: "After repeat."},
 	}
 
-	got, err := AssignSegmentIdentities(inputs)
+	got, err := AssignSegmentIdentities(inputs, ChapterRule{})
 	if err != nil {
 		t.Fatalf("AssignSegmentIdentities: %v", err)
 	}
@@ -113,7 +114,7 @@ func TestValidateStoredSegmentIdentities(t *testing.T) {
 		{Kind: SegmentKindParagraph, Markdown: "Repeated."},
 		{Kind: SegmentKindHeading, HeadingLevel: intPointer(2), Markdown: "## Chapter"},
 	}
-	assigned, err := AssignSegmentIdentities(inputs)
+	assigned, err := AssignSegmentIdentities(inputs, ChapterRule{})
 	if err != nil {
 		t.Fatalf("AssignSegmentIdentities: %v", err)
 	}
@@ -130,7 +131,7 @@ func TestValidateStoredSegmentIdentities(t *testing.T) {
 		})
 	}
 
-	chapterCount, err := ValidateStoredSegmentIdentities(valid)
+	chapterCount, err := ValidateStoredSegmentIdentities(valid, ChapterRule{})
 	if err != nil || chapterCount != 2 {
 		t.Fatalf("valid stored identities = chapters %d / error %v", chapterCount, err)
 	}
@@ -161,13 +162,62 @@ func TestValidateStoredSegmentIdentities(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			segments := append([]StoredSegmentIdentity(nil), valid...)
 			test.mutate(segments)
-			if _, err := ValidateStoredSegmentIdentities(segments); err == nil {
+			if _, err := ValidateStoredSegmentIdentities(segments, ChapterRule{}); err == nil {
 				t.Fatalf("accepted malformed stored identities: %#v", segments)
 			}
 		})
 	}
 }
 
+func TestChapterRuleLevelAndPattern(t *testing.T) {
+	inputs := []SegmentIdentityInput{
+		{Kind: SegmentKindHeading, HeadingLevel: intPointer(1), Markdown: "# Contents"},
+		{Kind: SegmentKindHeading, HeadingLevel: intPointer(1), Markdown: "# Chapter 1"},
+		{Kind: SegmentKindParagraph, Markdown: "Opening."},
+		{Kind: SegmentKindHeading, HeadingLevel: intPointer(2), Markdown: "## Aside"},
+		{Kind: SegmentKindHeading, HeadingLevel: intPointer(1), Markdown: "# Afterword"},
+		{Kind: SegmentKindHeading, HeadingLevel: intPointer(1), Markdown: "# Chapter 2"},
+	}
+	rule := ChapterRule{Level: 1, Pattern: regexp.MustCompile(`^Chapter \d+$`)}
+	assigned, err := AssignSegmentIdentities(inputs, rule)
+	if err != nil {
+		t.Fatalf("AssignSegmentIdentities: %v", err)
+	}
+	if assigned[0].ChapterKey != nil {
+		t.Fatal("unmatched H1 opened a chapter")
+	}
+	for _, index := range []int{2, 3, 4} {
+		if assigned[index].ChapterKey == nil || *assigned[index].ChapterKey != assigned[1].ContentKey {
+			t.Fatalf("segment %d chapter = %#v, want the first matching H1", index+1, assigned[index])
+		}
+	}
+	if assigned[5].ChapterKey == nil || *assigned[5].ChapterKey != assigned[5].ContentKey {
+		t.Fatalf("second matching H1 chapter = %#v", assigned[5])
+	}
+
+	stored := make([]StoredSegmentIdentity, 0, len(assigned))
+	for index, identity := range assigned {
+		stored = append(stored, StoredSegmentIdentity{
+			Ordinal:           index + 1,
+			Kind:              identity.Kind,
+			HeadingLevel:      identity.HeadingLevel,
+			ContentKey:        identity.ContentKey,
+			ContentOccurrence: identity.ContentOccurrence,
+			ChapterKey:        identity.ChapterKey,
+			ChapterOccurrence: identity.ChapterOccurrence,
+		})
+	}
+	if chapters, err := ValidateStoredSegmentIdentities(stored, rule); err != nil || chapters != 2 {
+		t.Fatalf("stored identities under rule = chapters %d / error %v", chapters, err)
+	}
+	if _, err := ValidateStoredSegmentIdentities(stored, ChapterRule{Level: 1}); err == nil {
+		t.Fatal("accepted identities that skip an H1 without a pattern")
+	}
+	if _, err := ValidateStoredSegmentIdentities(stored, ChapterRule{}); err == nil {
+		t.Fatal("accepted H1 chapters under the default H2 rule")
+	}
+}
+
 func TestLocatorValidation(t *testing.T) {
 	valid := Locator{
 		Schema: 2,
@@ -0,0 +1,82 @@
+// Package renderjobs runs admin-requested re-renders. The Store walks an
+// account's versions in batches and swaps each version's HTML atomically; a
+// Worker claims queued jobs under a lease and drives them to completion, so
+// a job interrupted by a restart resumes where its last batch ended.
+package renderjobs
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"pandapages/api/internal/model"
+)
+
+const (
+	defaultInterval = 10 * time.Second
+	claimLease      = time.Minute
+	batchSize       = 25
+)
+
+type Store interface {
+	RenderClaimJob(ctx context.Context, lease time.Duration) (model.RenderJob, bool, error)
+	RenderJobStep(ctx context.Context, job model.RenderJob, batch int, lease time.Duration) (model.RenderJob, error)
+}
+
+type Worker struct {
+	store    Store
+	interval time.Duration
+	batch    int
+}
+
+func NewWorker(store Store) *Worker {
+	return &Worker{store: store, interval: defaultInterval, batch: batchSize}
+}
+
+// Run processes queued jobs until ctx is cancelled.
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		w.RunOnce(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce claims one job and steps it until it finishes or ctx ends. An
+// unfinished job is picked up again once its lease expires.
+func (w *Worker) RunOnce(ctx context.Context) {
+	job, ok, err := w.store.RenderClaimJob(ctx, claimLease)
+	if err != nil {
+		slog.Error("render job claim failed")
+		return
+	}
+	if !ok {
+		return
+	}
+	for job.Status == model.RenderJobRunning {
+		if ctx.Err() != nil {
+			return
+		}
+		next, err := w.store.RenderJobStep(ctx, job, w.batch, claimLease)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			slog.Error("render job step failed", "job", job.ID)
+			return
+		}
+		job = next
+	}
+	slog.Info("render job finished",
+		"job", job.ID,
+		"status", string(job.Status),
+		"rendered", job.RenderedVersions,
+		"unchanged", job.UnchangedVersions,
+		"skipped", job.SkippedVersions,
+	)
+}
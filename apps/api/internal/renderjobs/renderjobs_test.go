@@ -0,0 +1,73 @@
+package renderjobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"pandapages/api/internal/model"
+)
+
+type fakeStore struct {
+	queued   []model.RenderJob
+	versions int
+	steps    int
+	stepErr  error
+}
+
+func (s *fakeStore) RenderClaimJob(context.Context, time.Duration) (model.RenderJob, bool, error) {
+	if len(s.queued) == 0 {
+		return model.RenderJob{}, false, nil
+	}
+	job := s.queued[0]
+	s.queued = s.queued[1:]
+	job.Status = model.RenderJobRunning
+	return job, true, nil
+}
+
+func (s *fakeStore) RenderJobStep(_ context.Context, job model.RenderJob, batch int, _ time.Duration) (model.RenderJob, error) {
+	s.steps++
+	if s.stepErr != nil {
+		return model.RenderJob{}, s.stepErr
+	}
+	done := job.RenderedVersions + job.UnchangedVersions
+	step := min(batch, s.versions-done)
+	job.RenderedVersions += step
+	if step < batch {
+		job.Status = model.RenderJobCompleted
+	}
+	return job, nil
+}
+
+func TestWorkerStepsAJobToCompletion(t *testing.T) {
+	store := &fakeStore{queued: []model.RenderJob{{ID: "job", Status: model.RenderJobQueued}}, versions: 7}
+	worker := NewWorker(store)
+	worker.batch = 3
+
+	worker.RunOnce(context.Background())
+	if store.steps != 3 {
+		t.Fatalf("steps = %d, want 3 batches for 7 versions", store.steps)
+	}
+	worker.RunOnce(context.Background())
+	if store.steps != 3 {
+		t.Fatalf("steps = %d after the queue emptied", store.steps)
+	}
+}
+
+func TestWorkerLeavesAnInterruptedJobForItsLease(t *testing.T) {
+	store := &fakeStore{queued: []model.RenderJob{{ID: "job"}}, versions: 100}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	NewWorker(store).RunOnce(ctx)
+	if store.steps != 0 {
+		t.Fatalf("steps = %d after cancellation", store.steps)
+	}
+
+	store = &fakeStore{queued: []model.RenderJob{{ID: "job"}}, stepErr: errors.New("database gone")}
+	NewWorker(store).RunOnce(context.Background())
+	if store.steps != 1 {
+		t.Fatalf("steps = %d, want the worker to stop at the first failure", store.steps)
+	}
+}
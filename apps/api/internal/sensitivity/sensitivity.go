@@ -0,0 +1,109 @@
+// Package sensitivity matches story text against the topics a family has asked
+// to avoid. Matching is advisory: it flags stories for a human to review and
+// never blocks publication.
+package sensitivity
+
+import (
+	"html"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	SourceChildProfile = "child_profile"
+	SourceWordList     = "word_list"
+)
+
+type Term struct {
+	Text   string
+	Source string
+}
+
+// Segment is one Reader segment's rendered HTML and identity.
+type Segment struct {
+	Ordinal      int
+	ContentKey   string
+	RenderedHTML string
+}
+
+type Match struct {
+	Term       string
+	Source     string
+	Ordinal    int
+	ContentKey string
+	Count      int
+}
+
+var tagRe = regexp.MustCompile(`<[^>]*>`)
+
+// NormalizeTerms lower-cases terms, collapses internal whitespace, and drops
+// blanks and duplicates. When a term appears in several sources the first
+// occurrence wins, so callers list child-profile terms first.
+func NormalizeTerms(terms []Term) []Term {
+	seen := make(map[string]bool, len(terms))
+	out := make([]Term, 0, len(terms))
+	for _, term := range terms {
+		text := strings.ToLower(strings.Join(strings.Fields(term.Text), " "))
+		if text == "" || seen[text] {
+			continue
+		}
+		seen[text] = true
+		out = append(out, Term{Text: text, Source: term.Source})
+	}
+	return out
+}
+
+// Scan reports whole-word, case-insensitive occurrences of each term per
+// segment. Multi-word terms match across any run of whitespace.
+func Scan(segments []Segment, terms []Term) []Match {
+	terms = NormalizeTerms(terms)
+	patterns := make([]*regexp.Regexp, len(terms))
+	for index, term := range terms {
+		words := strings.Fields(term.Text)
+		for i := range words {
+			words[i] = regexp.QuoteMeta(words[i])
+		}
+		patterns[index] = regexp.MustCompile(`(?i)(?:^|[^\p{L}\p{N}])(` + strings.Join(words, `\s+`) + `)(?:$|[^\p{L}\p{N}])`)
+	}
+
+	matches := []Match{}
+	for _, segment := range segments {
+		text := html.UnescapeString(tagRe.ReplaceAllString(segment.RenderedHTML, " "))
+		for index, pattern := range patterns {
+			count := countOverlapping(pattern, text)
+			if count == 0 {
+				continue
+			}
+			matches = append(matches, Match{
+				Term:       terms[index].Text,
+				Source:     terms[index].Source,
+				Ordinal:    segment.Ordinal,
+				ContentKey: segment.ContentKey,
+				Count:      count,
+			})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Ordinal != matches[j].Ordinal {
+			return matches[i].Ordinal < matches[j].Ordinal
+		}
+		return matches[i].Term < matches[j].Term
+	})
+	return matches
+}
+
+// countOverlapping restarts each search at the end of the matched term rather
+// than the end of its trailing boundary character, so "fire fire" counts two.
+func countOverlapping(pattern *regexp.Regexp, text string) int {
+	count := 0
+	for offset := 0; offset < len(text); {
+		loc := pattern.FindStringSubmatchIndex(text[offset:])
+		if loc == nil {
+			break
+		}
+		count++
+		offset += loc[3]
+	}
+	return count
+}
@@ -0,0 +1,36 @@
+package sensitivity
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestScanMatchesWholeWordsCaseInsensitively(t *testing.T) {
+	segments := []Segment{
+		{Ordinal: 1, ContentKey: "a", RenderedHTML: "<h1>The Fire</h1>"},
+		{Ordinal: 2, ContentKey: "b", RenderedHTML: "<p>Fireflies danced. <em>Fire</em>, fire! A dark\n  forest.</p>"},
+		{Ordinal: 3, ContentKey: "c", RenderedHTML: "<p>Nothing to see.</p>"},
+	}
+	terms := []Term{
+		{Text: " FIRE ", Source: SourceChildProfile},
+		{Text: "fire", Source: SourceWordList},
+		{Text: "dark forest", Source: SourceWordList},
+		{Text: "  ", Source: SourceWordList},
+	}
+
+	got := Scan(segments, terms)
+	want := []Match{
+		{Term: "fire", Source: SourceChildProfile, Ordinal: 1, ContentKey: "a", Count: 1},
+		{Term: "dark forest", Source: SourceWordList, Ordinal: 2, ContentKey: "b", Count: 1},
+		{Term: "fire", Source: SourceChildProfile, Ordinal: 2, ContentKey: "b", Count: 2},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Scan() = %#v, want %#v", got, want)
+	}
+}
+
+func TestScanWithoutTermsReportsNothing(t *testing.T) {
+	if got := Scan([]Segment{{Ordinal: 1, RenderedHTML: "<p>Anything</p>"}}, nil); len(got) != 0 {
+		t.Fatalf("Scan() = %#v", got)
+	}
+}
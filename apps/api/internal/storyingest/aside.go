@@ -0,0 +1,153 @@
+package storyingest
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// asideMarkerRe matches the first line of a callout block quote, such as
+// > [!note-for-grownups]. The label names the kind of note.
+var asideMarkerRe = regexp.MustCompile(`^\[!([A-Za-z][A-Za-z0-9-]{0,39})\]$`)
+
+// Aside is a callout addressed to grown-ups rather than part of the story.
+// Its children are the block quote's content without the marker line.
+type Aside struct {
+	ast.BaseBlock
+	Label string
+
+	// source is the byte range of the whole block quote, marker included.
+	source text.Segment
+}
+
+// KindAside is the NodeKind of Aside.
+var KindAside = ast.NewNodeKind("Aside")
+
+func (n *Aside) Kind() ast.NodeKind {
+	return KindAside
+}
+
+func (n *Aside) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"Label": n.Label}, nil)
+}
+
+// markdown returns the authored block quote, so re-rendering the segment
+// produces the aside again.
+func (n *Aside) markdown(source []byte) string {
+	return strings.TrimSpace(string(n.source.Value(source)))
+}
+
+// blockSpan returns the source lines covered by the blocks inside n, widened
+// to whole lines so container markers such as "> " are included.
+func blockSpan(source []byte, n ast.Node) (text.Segment, bool) {
+	span := text.NewSegment(-1, -1)
+	_ = ast.Walk(n, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering || node.Type() != ast.TypeBlock {
+			return ast.WalkContinue, nil
+		}
+		lines := node.Lines()
+		for i := 0; i < lines.Len(); i++ {
+			line := lines.At(i)
+			if span.Start < 0 || line.Start < span.Start {
+				span.Start = line.Start
+			}
+			if line.Stop > span.Stop {
+				span.Stop = line.Stop
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+	if span.Start < 0 {
+		return span, false
+	}
+	for span.Start > 0 && source[span.Start-1] != '\n' {
+		span.Start--
+	}
+	return span, true
+}
+
+// asideTransformer replaces top-level block quotes opening with a marker
+// line by Aside nodes. Other block quotes are left alone.
+type asideTransformer struct{}
+
+func (asideTransformer) Transform(doc *ast.Document, reader text.Reader, _ parser.Context) {
+	src := reader.Source()
+	for n := doc.FirstChild(); n != nil; n = n.NextSibling() {
+		quote, ok := n.(*ast.Blockquote)
+		if !ok {
+			continue
+		}
+		first, ok := quote.FirstChild().(*ast.Paragraph)
+		if !ok || first.Lines().Len() == 0 {
+			continue
+		}
+		marker := first.Lines().At(0)
+		match := asideMarkerRe.FindSubmatch(util.TrimRightSpace(marker.Value(src)))
+		if match == nil {
+			continue
+		}
+		span, ok := blockSpan(src, quote)
+		if !ok {
+			continue
+		}
+		aside := &Aside{Label: strings.ToLower(string(match[1])), source: span}
+		dropMarkerLine(first, marker)
+		for child := quote.FirstChild(); child != nil; {
+			next := child.NextSibling()
+			aside.AppendChild(aside, child)
+			child = next
+		}
+		aside.SetBlankPreviousLines(quote.HasBlankPreviousLines())
+		doc.ReplaceChild(doc, quote, aside)
+		n = aside
+	}
+}
+
+// dropMarkerLine removes the marker line and its inline text from the first
+// paragraph of an aside, and the paragraph itself when nothing is left.
+func dropMarkerLine(paragraph *ast.Paragraph, marker text.Segment) {
+	for child := paragraph.FirstChild(); child != nil; {
+		next := child.NextSibling()
+		t, ok := child.(*ast.Text)
+		if !ok || t.Segment.Stop > marker.Stop {
+			break
+		}
+		paragraph.RemoveChild(paragraph, child)
+		child = next
+	}
+	lines := text.NewSegments()
+	for i := 1; i < paragraph.Lines().Len(); i++ {
+		lines.Append(paragraph.Lines().At(i))
+	}
+	paragraph.SetLines(lines)
+	if lines.Len() == 0 {
+		paragraph.Parent().RemoveChild(paragraph.Parent(), paragraph)
+	}
+}
+
+type asideRenderer struct{}
+
+func (asideRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(KindAside, func(w util.BufWriter, _ []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			_, _ = w.WriteString("</aside>\n")
+			return ast.WalkContinue, nil
+		}
+		label := n.(*Aside).Label
+		_, _ = w.WriteString(`<aside class="aside" data-aside="` + string(util.EscapeHTML([]byte(label))) + "\">\n")
+		return ast.WalkContinue, nil
+	})
+}
+
+type aside struct{}
+
+func (aside) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(parser.WithASTTransformers(util.Prioritized(asideTransformer{}, 900)))
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(util.Prioritized(asideRenderer{}, 500)))
+}
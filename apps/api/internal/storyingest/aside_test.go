@@ -0,0 +1,55 @@
+package storyingest
+
+import (
+	"strings"
+	"testing"
+
+	"pandapages/api/internal/readercontract"
+)
+
+func TestIngestTagsAsides(t *testing.T) {
+	markdown := strings.Join([]string{
+		"The panda fell asleep.",
+		"",
+		"> [!Note-for-grownups]",
+		"> Ask which *dream* they would choose.",
+		">",
+		"> Then turn off the light.",
+		"",
+		"> [!tip]",
+		"",
+		"> An ordinary quote.",
+		"",
+	}, "\n")
+	out, err := Ingest(Input{Slug: "sleep", Title: "Sleep", Markdown: markdown, MarkdownExtensions: []string{ExtensionAsides}})
+	if err != nil {
+		t.Fatalf("Ingest returned error: %v", err)
+	}
+	if len(out.Segments) != 2 {
+		t.Fatalf("segments = %#v", out.Segments)
+	}
+	aside := out.Segments[1]
+	if aside.Kind != readercontract.SegmentKindAside || aside.WordCount != 11 {
+		t.Fatalf("aside segment = %#v", aside)
+	}
+	want := "<aside class=\"aside\" data-aside=\"note-for-grownups\">\n<p>Ask which <em>dream</em> they would choose.</p>\n<p>Then turn off the light.</p>\n</aside>\n"
+	if aside.RenderedHTML != want {
+		t.Fatalf("aside HTML = %q", aside.RenderedHTML)
+	}
+	if !strings.HasPrefix(aside.Markdown, "> [!Note-for-grownups]\n") || !strings.HasSuffix(aside.Markdown, "> Then turn off the light.") {
+		t.Fatalf("aside markdown = %q", aside.Markdown)
+	}
+	if got := MeasureReadability(out.Segments).Words; got != 4 {
+		t.Fatalf("readability words = %d, want the story's 4", got)
+	}
+
+	plain, err := Ingest(Input{Slug: "sleep", Title: "Sleep", Markdown: markdown})
+	if err != nil {
+		t.Fatalf("Ingest without asides returned error: %v", err)
+	}
+	for _, segment := range plain.Segments {
+		if segment.Kind == readercontract.SegmentKindAside {
+			t.Fatalf("aside without the extension: %#v", segment)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package storyingest
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"pandapages/api/internal/readercontract"
+)
+
+// Chapters open at H2 unless a story chooses another heading level and,
+// optionally, a pattern the heading text must match. A non-default choice is
+// recorded in frontmatter so stored versions re-derive the same chapters.
+const (
+	ChapterLevelKey   = "chapterLevel"
+	ChapterPatternKey = "chapterPattern"
+
+	maxChapterPatternLength = 200
+)
+
+// OpensChapter reports whether the segment is the heading of a chapter. A
+// heading carries its own chapter identity only when it opens one.
+func (s Segment) OpensChapter() bool {
+	return s.Kind == readercontract.SegmentKindHeading && s.ChapterKey != nil && *s.ChapterKey == s.ContentKey &&
+		s.ChapterOccurrence != nil && *s.ChapterOccurrence == s.ContentOccurrence
+}
+
+// chapterLevelValue accepts an integer level as decoded from YAML or JSON.
+func chapterLevelValue(raw any) (int, bool) {
+	var level int
+	switch typed := raw.(type) {
+	case int:
+		level = typed
+	case int64:
+		level = int(typed)
+	case float64:
+		level = int(typed)
+		if float64(level) != typed {
+			return 0, false
+		}
+	case json.Number:
+		value, err := typed.Int64()
+		if err != nil {
+			return 0, false
+		}
+		level = int(value)
+	default:
+		return 0, false
+	}
+	return level, level >= 1 && level <= 6
+}
+
+func compileChapterPattern(pattern string) (*regexp.Regexp, error) {
+	if len(pattern) > maxChapterPatternLength {
+		return nil, fmt.Errorf("chapterPattern must be at most %d characters", maxChapterPatternLength)
+	}
+	compiled, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("chapterPattern is not a valid regular expression")
+	}
+	return compiled, nil
+}
+
+// resolveChapterRule prefers the explicit input fields and falls back to
+// frontmatter, returning the rule with the level and pattern to record.
+func resolveChapterRule(in Input, fm map[string]any) (readercontract.ChapterRule, int, string, error) {
+	level, levelField := in.ChapterLevel, "chapterLevel"
+	if level == 0 {
+		levelField = "frontmatter." + ChapterLevelKey
+		if raw, exists := fm[ChapterLevelKey]; exists {
+			value, ok := chapterLevelValue(raw)
+			if !ok {
+				return readercontract.ChapterRule{}, 0, "", inputError(levelField, "invalid_chapter_level", 0, "chapterLevel must be a heading level from 1 to 6")
+			}
+			level = value
+		}
+	}
+	if level == 0 {
+		level = readercontract.DefaultChapterLevel
+	}
+	if level < 1 || level > 6 {
+		return readercontract.ChapterRule{}, 0, "", inputError(levelField, "invalid_chapter_level", 0, "chapterLevel must be a heading level from 1 to 6")
+	}
+
+	pattern, patternField := strings.TrimSpace(in.ChapterPattern), "chapterPattern"
+	if pattern == "" {
+		patternField = "frontmatter." + ChapterPatternKey
+		if raw, exists := fm[ChapterPatternKey]; exists && raw != nil {
+			value, ok := raw.(string)
+			if !ok {
+				return readercontract.ChapterRule{}, 0, "", inputError(patternField, "invalid_chapter_pattern", 0, "chapterPattern must be a string")
+			}
+			pattern = strings.TrimSpace(value)
+		}
+	}
+	rule := readercontract.ChapterRule{Level: level}
+	if pattern != "" {
+		compiled, err := compileChapterPattern(pattern)
+		if err != nil {
+			return readercontract.ChapterRule{}, 0, "", inputError(patternField, "invalid_chapter_pattern", 0, err.Error())
+		}
+		rule.Pattern = compiled
+	}
+	return rule, level, pattern, nil
+}
+
+// ChapterRuleFromFrontmatter returns the chapter rule a stored version was
+// ingested with.
+func ChapterRuleFromFrontmatter(fm map[string]any) (readercontract.ChapterRule, error) {
+	rule, _, _, err := resolveChapterRule(Input{}, fm)
+	return rule, err
+}
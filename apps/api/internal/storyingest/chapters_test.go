@@ -0,0 +1,162 @@
+package storyingest
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestIngestUsesFrontmatterChapterRule(t *testing.T) {
+	markdown := strings.Join([]string{
+		"---",
+		"chapterLevel: 1",
+		`chapterPattern: '^Chapter \d+'`,
+		"---",
+		"# Preface",
+		"",
+		"Before the story.",
+		"",
+		"# Chapter 1",
+		"",
+		"## A scene",
+		"",
+		"# Chapter 2",
+		"",
+	}, "\n")
+	out, err := Ingest(Input{Slug: "novel", Title: "Novel", Markdown: markdown})
+	if err != nil {
+		t.Fatalf("Ingest returned error: %v", err)
+	}
+	var opens []string
+	for _, segment := range out.Segments {
+		if segment.OpensChapter() {
+			opens = append(opens, segment.Markdown)
+		}
+	}
+	if got := strings.Join(opens, "|"); got != "# Chapter 1|# Chapter 2" {
+		t.Fatalf("chapter headings = %q", got)
+	}
+	if out.Segments[0].ChapterKey != nil {
+		t.Fatal("preface was placed in a chapter")
+	}
+	if scene := out.Segments[3]; scene.ChapterKey == nil || *scene.ChapterKey != out.Segments[2].ContentKey {
+		t.Fatalf("H2 scene chapter = %#v", scene)
+	}
+	if out.Frontmatter[ChapterLevelKey] != 1 || out.Frontmatter[ChapterPatternKey] != `^Chapter \d+` {
+		t.Fatalf("frontmatter = %#v", out.Frontmatter)
+	}
+
+	// Stored frontmatter round-trips through JSON before re-canonicalisation.
+	encoded, _ := json.Marshal(out.Frontmatter)
+	var stored map[string]any
+	if err := json.Unmarshal(encoded, &stored); err != nil {
+		t.Fatal(err)
+	}
+	body := markdown[strings.Index(markdown, "# Preface"):]
+	again, err := CanonicalizeStoredBody(Input{Slug: "novel", Title: "Novel", Markdown: body}, stored)
+	if err != nil {
+		t.Fatalf("CanonicalizeStoredBody returned error: %v", err)
+	}
+	for index := range out.Segments {
+		if again.Segments[index].OpensChapter() != out.Segments[index].OpensChapter() {
+			t.Fatalf("stored segment %d chapter opening changed", index+1)
+		}
+	}
+}
+
+func TestIngestChapterInputOverridesFrontmatter(t *testing.T) {
+	markdown := "---\nchapterLevel: 1\n---\n# Book\n\n### Part\n"
+	out, err := Ingest(Input{Slug: "book", Title: "Book", Markdown: markdown, ChapterLevel: 3})
+	if err != nil {
+		t.Fatalf("Ingest returned error: %v", err)
+	}
+	if out.Segments[0].OpensChapter() || !out.Segments[1].OpensChapter() {
+		t.Fatalf("segments = %#v", out.Segments)
+	}
+	if out.Frontmatter[ChapterLevelKey] != 3 {
+		t.Fatalf("frontmatter chapterLevel = %#v", out.Frontmatter[ChapterLevelKey])
+	}
+
+	plain, err := Ingest(Input{Slug: "plain", Title: "Plain", Markdown: "## One\n"})
+	if err != nil {
+		t.Fatalf("Ingest returned error: %v", err)
+	}
+	if _, ok := plain.Frontmatter[ChapterLevelKey]; ok {
+		t.Fatal("default chapter level was recorded in frontmatter")
+	}
+}
+
+func TestIngestRejectsInvalidChapterRule(t *testing.T) {
+	for _, test := range []struct {
+		name  string
+		input Input
+		field string
+		code  string
+	}{
+		{name: "level input", input: Input{ChapterLevel: 7}, field: "chapterLevel", code: "invalid_chapter_level"},
+		{name: "pattern input", input: Input{ChapterPattern: "Chapter ("}, field: "chapterPattern", code: "invalid_chapter_pattern"},
+		{name: "level frontmatter", input: Input{Markdown: "---\nchapterLevel: two\n---\n"}, field: "frontmatter.chapterLevel", code: "invalid_chapter_level"},
+		{name: "pattern frontmatter", input: Input{Markdown: "---\nchapterPattern: '['\n---\n"}, field: "frontmatter.chapterPattern", code: "invalid_chapter_pattern"},
+		{name: "numbering frontmatter", input: Input{Markdown: "---\nnumberChapters: yes please\n---\n"}, field: "frontmatter.numberChapters", code: "invalid_chapter_numbering"},
+		{name: "numbering with pattern", input: Input{NumberChapters: true, ChapterPattern: "One"}, field: "numberChapters", code: "invalid_chapter_numbering"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			in := test.input
+			in.Slug, in.Title = "story", "Story"
+			in.Markdown += "## One\n"
+			_, err := Ingest(in)
+			var inputErr *InputError
+			if !errors.As(err, &inputErr) {
+				t.Fatalf("Ingest error = %v, want InputError", err)
+			}
+			if problem := inputErr.Problems[0]; problem.Field != test.field || problem.Code != test.code {
+				t.Fatalf("problem = %#v", problem)
+			}
+		})
+	}
+}
+
+func TestIngestNumbersUnnumberedChapters(t *testing.T) {
+	markdown := "# The Forest Book\n\n## The Dark Forest\n\nTrees.\n\n### A Clearing\n\nLight.\n\n## Home\n\nTea.\n"
+	out, err := Ingest(Input{Slug: "forest", Title: "Forest", Markdown: markdown, NumberChapters: true})
+	if err != nil {
+		t.Fatalf("Ingest returned error: %v", err)
+	}
+	var chapters []string
+	for _, segment := range out.Segments {
+		if segment.OpensChapter() {
+			chapters = append(chapters, segment.Markdown)
+		}
+	}
+	if got := strings.Join(chapters, "|"); got != "## Chapter 1 — The Dark Forest|## Chapter 2 — Home" {
+		t.Fatalf("chapter headings = %s", got)
+	}
+	if got := out.Segments[1].RenderedHTML; !strings.Contains(got, ">Chapter 1 — The Dark Forest</h2>") {
+		t.Fatalf("rendered chapter heading = %q", got)
+	}
+	if out.Segments[0].Markdown != "# The Forest Book" || out.Segments[3].Markdown != "### A Clearing" {
+		t.Fatalf("non-chapter headings changed: %#v", out.Segments)
+	}
+	if out.Frontmatter[NumberChaptersKey] != true {
+		t.Fatalf("frontmatter = %#v", out.Frontmatter)
+	}
+	stored, err := CanonicalizeStoredBody(Input{Slug: "forest", Title: "Forest", Markdown: out.Markdown}, out.Frontmatter)
+	if err != nil || stored.Segments[1].ContentKey != out.Segments[1].ContentKey {
+		t.Fatalf("stored re-derivation = %#v, %v", stored.Segments, err)
+	}
+
+	french, err := Ingest(Input{Slug: "foret", Title: "Forêt", Language: "fr", Markdown: "## La forêt\n\nArbres.\n", NumberChapters: true})
+	if err != nil || french.Segments[0].Markdown != "## Chapitre 1 — La forêt" {
+		t.Fatalf("French chapter = %#v, %v", french.Segments, err)
+	}
+
+	numbered := "## Chapter 1: Woods\n\nTrees.\n\n## Home\n\nTea.\n"
+	kept, err := Ingest(Input{Slug: "forest", Title: "Forest", Markdown: numbered, NumberChapters: true})
+	if err != nil {
+		t.Fatalf("Ingest returned error: %v", err)
+	}
+	if kept.Segments[0].Markdown != "## Chapter 1: Woods" || kept.Segments[2].Markdown != "## Home" {
+		t.Fatalf("numbered source was renumbered: %#v", kept.Segments)
+	}
+}
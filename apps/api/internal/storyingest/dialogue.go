@@ -0,0 +1,99 @@
+package storyingest
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// A dialogue paragraph starts with @Speaker: and a space. Speaker names are
+// short so they stay usable as voice and CSS class names.
+var dialogueRe = regexp.MustCompile(`^@([\p{L}\p{N}][\p{L}\p{N}' _-]{0,39}?):[ \t]+`)
+
+var speakerClassRe = regexp.MustCompile(`[^\p{L}\p{N}]+`)
+
+// Dialogue is a paragraph spoken by a named speaker. Its Lines keep the
+// @Speaker: prefix as authored; its children do not.
+type Dialogue struct {
+	ast.BaseBlock
+	Speaker string
+}
+
+// KindDialogue is the NodeKind of Dialogue.
+var KindDialogue = ast.NewNodeKind("Dialogue")
+
+func (n *Dialogue) Kind() ast.NodeKind {
+	return KindDialogue
+}
+
+func (n *Dialogue) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"Speaker": n.Speaker}, nil)
+}
+
+// speakerClass returns the CSS class suffix for a speaker name.
+func speakerClass(speaker string) string {
+	return strings.Trim(speakerClassRe.ReplaceAllString(strings.ToLower(speaker), "-"), "-")
+}
+
+// dialogueTransformer replaces top-level paragraphs that open with a speaker
+// tag by Dialogue nodes.
+type dialogueTransformer struct{}
+
+func (dialogueTransformer) Transform(doc *ast.Document, reader text.Reader, _ parser.Context) {
+	src := reader.Source()
+	for n := doc.FirstChild(); n != nil; n = n.NextSibling() {
+		paragraph, ok := n.(*ast.Paragraph)
+		if !ok {
+			continue
+		}
+		first, ok := paragraph.FirstChild().(*ast.Text)
+		if !ok {
+			continue
+		}
+		match := dialogueRe.FindSubmatch(first.Segment.Value(src))
+		if match == nil {
+			continue
+		}
+		first.Segment = first.Segment.WithStart(first.Segment.Start + len(match[0]))
+
+		dialogue := &Dialogue{Speaker: strings.TrimSpace(string(match[1]))}
+		dialogue.SetLines(paragraph.Lines())
+		dialogue.SetBlankPreviousLines(paragraph.HasBlankPreviousLines())
+		for child := paragraph.FirstChild(); child != nil; {
+			next := child.NextSibling()
+			dialogue.AppendChild(dialogue, child)
+			child = next
+		}
+		doc.ReplaceChild(doc, paragraph, dialogue)
+		n = dialogue
+	}
+}
+
+type dialogueRenderer struct{}
+
+func (dialogueRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(KindDialogue, func(w util.BufWriter, _ []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			_, _ = w.WriteString("</span></p>\n")
+			return ast.WalkContinue, nil
+		}
+		speaker := n.(*Dialogue).Speaker
+		name := string(util.EscapeHTML([]byte(speaker)))
+		_, _ = w.WriteString(`<p class="dialogue"><span class="speaker speaker-` + speakerClass(speaker) +
+			`" data-speaker="` + name + `"><span class="speaker-name">` + name + `:</span> `)
+		return ast.WalkContinue, nil
+	})
+}
+
+type dialogue struct{}
+
+func (dialogue) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(parser.WithASTTransformers(util.Prioritized(dialogueTransformer{}, 900)))
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(util.Prioritized(dialogueRenderer{}, 500)))
+}
@@ -0,0 +1,53 @@
+package storyingest
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIngestTagsDialogueSpeakers(t *testing.T) {
+	markdown := strings.Join([]string{
+		"@Narrator: Once upon a time.",
+		"",
+		"@Town Mouse: Come *with* me & see.",
+		"",
+		"@nobody:here stays a paragraph.",
+		"",
+	}, "\n")
+	out, err := Ingest(Input{Slug: "mice", Title: "Mice", Markdown: markdown, MarkdownExtensions: []string{ExtensionDialogue}})
+	if err != nil {
+		t.Fatalf("Ingest returned error: %v", err)
+	}
+	if len(out.Segments) != 3 {
+		t.Fatalf("segments = %#v", out.Segments)
+	}
+
+	mouse := out.Segments[1]
+	if mouse.Speaker != "Town Mouse" || mouse.Kind != "paragraph" {
+		t.Fatalf("dialogue segment = %#v", mouse)
+	}
+	if mouse.Markdown != "@Town Mouse: Come *with* me & see." {
+		t.Fatalf("dialogue markdown = %q", mouse.Markdown)
+	}
+	want := `<p class="dialogue"><span class="speaker speaker-town-mouse" data-speaker="Town Mouse"><span class="speaker-name">Town Mouse:</span> Come <em>with</em> me &amp; see.</span></p>`
+	if strings.TrimSpace(mouse.RenderedHTML) != want {
+		t.Fatalf("dialogue HTML = %s", mouse.RenderedHTML)
+	}
+	if mouse.WordCount != 5 {
+		t.Fatalf("dialogue word count = %d, want 5 without the speaker tag", mouse.WordCount)
+	}
+	if out.Segments[0].Speaker != "Narrator" {
+		t.Fatalf("narrator speaker = %q", out.Segments[0].Speaker)
+	}
+	if plain := out.Segments[2]; plain.Speaker != "" || strings.Contains(plain.RenderedHTML, "dialogue") {
+		t.Fatalf("tag without a space was read as dialogue: %#v", plain)
+	}
+
+	without, err := Ingest(Input{Slug: "mice", Title: "Mice", Markdown: markdown})
+	if err != nil {
+		t.Fatalf("Ingest returned error: %v", err)
+	}
+	if without.Segments[1].Speaker != "" || !strings.Contains(without.Segments[1].RenderedHTML, "@Town Mouse:") {
+		t.Fatalf("dialogue applied without the extension: %#v", without.Segments[1])
+	}
+}
@@ -0,0 +1,222 @@
+package storyingest
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	east "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// Markdown extensions are opt-in per story version. The enabled set is
+// recorded in frontmatter under MarkdownExtensionsKey, so a stored version is
+// always re-rendered with exactly the options it was ingested with and older
+// versions keep rendering as plain CommonMark.
+const (
+	MarkdownExtensionsKey = "markdownExtensions"
+
+	// ExtensionGFM enables GitHub Flavored Markdown: tables, strikethrough,
+	// task lists and bare-URL autolinks.
+	ExtensionGFM = "gfm"
+
+	// ExtensionFootnotes enables [^label] references and their definitions,
+	// which the Reader shows at the end of the chapter that first cites them.
+	ExtensionFootnotes = "footnotes"
+
+	// ExtensionTypographer renders straight quotes, dashes and ellipses as
+	// their typographic forms. Stored Markdown keeps what the author typed.
+	ExtensionTypographer = "typographer"
+
+	// ExtensionPageBreaks turns lines holding only \pagebreak or
+	// <!-- pagebreak --> into page-break segments.
+	ExtensionPageBreaks = "pagebreaks"
+
+	// ExtensionSceneBreaks turns separator lines such as ***, * * * or —
+	// into scene-break segments instead of dropping or keeping them as text.
+	ExtensionSceneBreaks = "scenebreaks"
+
+	// ExtensionDialogue reads paragraphs opening with @Speaker: as lines of
+	// dialogue, so read-aloud can switch voice per speaker.
+	ExtensionDialogue = "dialogue"
+
+	// ExtensionVerse keeps the line breaks of ```verse fences, which become
+	// verse segments of their own.
+	ExtensionVerse = "verse"
+
+	// ExtensionHyphenation adds soft hyphens to long words, using rules for
+	// the story language, so justified text breaks evenly on narrow screens.
+	ExtensionHyphenation = "hyphenation"
+
+	// ExtensionPronunciation reads [word](say: respelling) as a pronunciation
+	// hint, recorded on its segment for read-aloud.
+	ExtensionPronunciation = "pronunciation"
+
+	// ExtensionAsides reads block quotes opening with a [!label] line, such
+	// as > [!note-for-grownups], as asides: notes for grown-ups that kid mode
+	// leaves out.
+	ExtensionAsides = "asides"
+)
+
+var markdownExtenders = map[string]goldmark.Extender{
+	ExtensionGFM:           extension.GFM,
+	ExtensionFootnotes:     extension.Footnote,
+	ExtensionTypographer:   extension.Typographer,
+	ExtensionPageBreaks:    pageBreaks{},
+	ExtensionSceneBreaks:   sceneBreaks{},
+	ExtensionDialogue:      dialogue{},
+	ExtensionVerse:         verse{},
+	ExtensionHyphenation:   hyphenation{},
+	ExtensionPronunciation: pronunciation{},
+	ExtensionAsides:        aside{},
+}
+
+// typographerSources maps each typographer substitution back to the
+// punctuation it replaced, so text read from the tree stays as authored.
+var typographerSources = map[string]string{
+	"&lsquo;":  "'",
+	"&rsquo;":  "'",
+	"&ldquo;":  `"`,
+	"&rdquo;":  `"`,
+	"&ndash;":  "--",
+	"&mdash;":  "---",
+	"&hellip;": "...",
+	"&laquo;":  "<<",
+	"&raquo;":  ">>",
+}
+
+// SupportedExtensions lists the extension names Ingest accepts.
+func SupportedExtensions() []string {
+	names := make([]string, 0, len(markdownExtenders))
+	for name := range markdownExtenders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// normalizeExtensions accepts a []string or a decoded YAML/JSON list and
+// returns the sorted, de-duplicated names. It reports false for any value
+// that is not a list of supported names.
+func normalizeExtensions(raw any) ([]string, bool) {
+	var values []string
+	switch typed := raw.(type) {
+	case nil:
+		return nil, true
+	case []string:
+		values = typed
+	case []any:
+		for _, value := range typed {
+			name, ok := value.(string)
+			if !ok {
+				return nil, false
+			}
+			values = append(values, name)
+		}
+	default:
+		return nil, false
+	}
+	seen := map[string]bool{}
+	names := make([]string, 0, len(values))
+	for _, value := range values {
+		name := strings.ToLower(strings.TrimSpace(value))
+		if _, ok := markdownExtenders[name]; !ok {
+			return nil, false
+		}
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, true
+}
+
+// engine renders and parses Markdown with one fixed extension set.
+type engine struct {
+	markdown goldmark.Markdown
+}
+
+func newEngine(names []string, language string) engine {
+	extenders := make([]goldmark.Extender, 0, len(names))
+	for _, name := range names {
+		if name == ExtensionHyphenation {
+			extenders = append(extenders, hyphenation{language: language})
+			continue
+		}
+		extenders = append(extenders, markdownExtenders[name])
+	}
+	return engine{markdown: goldmark.New(
+		goldmark.WithParserOptions(
+			parser.WithAutoHeadingID(),
+			parser.WithASTTransformers(util.Prioritized(mediaTransformer{}, 1000)),
+		),
+		goldmark.WithExtensions(extenders...),
+	)}
+}
+
+func (e engine) render(md string) (string, error) {
+	var buf bytes.Buffer
+	if err := e.markdown.Convert([]byte(md), &buf); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// renderNode renders one node of a tree returned by parse, keeping state
+// that only the whole document knows, such as footnote numbering.
+func (e engine) renderNode(src []byte, n ast.Node) (string, error) {
+	var buf bytes.Buffer
+	if err := e.markdown.Renderer().Render(&buf, src, n); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func (e engine) parse(src []byte) ast.Node {
+	return e.markdown.Parser().Parse(text.NewReader(src))
+}
+
+// tableSource returns the Markdown lines spanning a GFM table, from its header
+// row through its last body row, including the delimiter row.
+func tableSource(src []byte, table *east.Table) string {
+	first, last := table.FirstChild(), table.LastChild()
+	if first == nil || first.Pos() < 0 || last.Pos() < 0 {
+		return ""
+	}
+	start := first.Pos()
+	for start > 0 && src[start-1] != '\n' {
+		start--
+	}
+	stop := lineEnd(src, last.Pos())
+	if last == first {
+		// A header-only table still ends at its delimiter row.
+		stop = lineEnd(src, min(stop+1, len(src)))
+	}
+	return strings.TrimSpace(string(src[start:stop]))
+}
+
+func lineEnd(src []byte, pos int) int {
+	for pos < len(src) && src[pos] != '\n' {
+		pos++
+	}
+	return pos
+}
+
+// tableText joins the cell text of a table for word counting.
+func tableText(src []byte, table *east.Table) string {
+	cells := []string{}
+	for row := table.FirstChild(); row != nil; row = row.NextSibling() {
+		for cell := row.FirstChild(); cell != nil; cell = cell.NextSibling() {
+			if value := textContent(src, cell); value != "" {
+				cells = append(cells, value)
+			}
+		}
+	}
+	return strings.Join(cells, " ")
+}
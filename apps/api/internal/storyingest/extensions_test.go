@@ -0,0 +1,127 @@
+package storyingest
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"pandapages/api/internal/readercontract"
+)
+
+const gfmStory = "# Pandas\n\nBamboo is ~~optional~~ essential.\n\n| Panda | Bamboo |\n| ----- | -----: |\n| Mei   | 12 kg  |\n| Tian  | 14 kg  |\n\nThe end.\n"
+
+func TestIngestLeavesGFMOffByDefault(t *testing.T) {
+	out, err := Ingest(Input{Slug: "pandas", Title: "Pandas", Markdown: gfmStory})
+	if err != nil {
+		t.Fatalf("Ingest returned error: %v", err)
+	}
+	if strings.Contains(out.RenderedHTML, "<table>") || strings.Contains(out.RenderedHTML, "<del>") {
+		t.Fatalf("GFM rendered without opting in: %s", out.RenderedHTML)
+	}
+	if _, ok := out.Frontmatter[MarkdownExtensionsKey]; ok {
+		t.Fatalf("frontmatter records extensions without opting in: %v", out.Frontmatter)
+	}
+}
+
+func TestIngestRendersGFMTablesAsSegments(t *testing.T) {
+	out, err := Ingest(Input{Slug: "pandas", Title: "Pandas", Markdown: gfmStory, MarkdownExtensions: []string{"GFM", "gfm"}})
+	if err != nil {
+		t.Fatalf("Ingest returned error: %v", err)
+	}
+	for _, want := range []string{"<table>", "<del>optional</del>"} {
+		if !strings.Contains(out.RenderedHTML, want) {
+			t.Errorf("rendered HTML does not contain %q: %s", want, out.RenderedHTML)
+		}
+	}
+	if !reflect.DeepEqual(out.MarkdownExtensions, []string{ExtensionGFM}) {
+		t.Fatalf("extensions = %v", out.MarkdownExtensions)
+	}
+	if len(out.Segments) != 4 {
+		t.Fatalf("segments = %d, want 4", len(out.Segments))
+	}
+	table := out.Segments[2]
+	wantMarkdown := "| Panda | Bamboo |\n| ----- | -----: |\n| Mei   | 12 kg  |\n| Tian  | 14 kg  |"
+	if table.Kind != readercontract.SegmentKindOther || table.Markdown != wantMarkdown {
+		t.Fatalf("table segment = %q %q", table.Kind, table.Markdown)
+	}
+	if !strings.Contains(table.RenderedHTML, `<td style="text-align:right">14 kg</td>`) {
+		t.Fatalf("table segment HTML = %s", table.RenderedHTML)
+	}
+	if table.WordCount != 8 {
+		t.Fatalf("table word count = %d, want 8", table.WordCount)
+	}
+
+	// Stored versions re-render from their recorded frontmatter.
+	encoded, err := json.Marshal(out.Frontmatter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stored map[string]any
+	if err := json.Unmarshal(encoded, &stored); err != nil {
+		t.Fatal(err)
+	}
+	again, err := CanonicalizeStoredBody(Input{Slug: "pandas", Title: "Pandas", Markdown: out.Markdown}, stored)
+	if err != nil {
+		t.Fatalf("CanonicalizeStoredBody returned error: %v", err)
+	}
+	if again.RenderedHTML != out.RenderedHTML || !reflect.DeepEqual(again.Segments, out.Segments) {
+		t.Fatal("stored body canonicalised differently from ingest")
+	}
+}
+
+func TestIngestReadsExtensionsFromFrontmatter(t *testing.T) {
+	markdown := "---\nmarkdownExtensions: [gfm]\n---\n" + gfmStory
+	out, err := Ingest(Input{Slug: "pandas", Title: "Pandas", Markdown: markdown})
+	if err != nil {
+		t.Fatalf("Ingest returned error: %v", err)
+	}
+	if !strings.Contains(out.RenderedHTML, "<table>") {
+		t.Fatalf("frontmatter extensions ignored: %s", out.RenderedHTML)
+	}
+
+	for _, markdown := range []string{"---\nmarkdownExtensions: [mermaid]\n---\nStory", "---\nmarkdownExtensions: gfm\n---\nStory"} {
+		_, err := Ingest(Input{Slug: "pandas", Title: "Pandas", Markdown: markdown})
+		var inputErr *InputError
+		if !errors.As(err, &inputErr) || inputErr.Problems[0].Code != "unsupported_extension" || inputErr.Problems[0].Line != 2 {
+			t.Fatalf("%q: error = %#v, want located unsupported_extension", markdown, err)
+		}
+	}
+	_, err = Ingest(Input{Slug: "pandas", Title: "Pandas", Markdown: "Story", MarkdownExtensions: []string{"mermaid"}})
+	var inputErr *InputError
+	if !errors.As(err, &inputErr) || inputErr.Problems[0].Field != "markdownExtensions" {
+		t.Fatalf("error = %#v, want markdownExtensions rejection", err)
+	}
+}
+
+func TestIngestTypographerRendersButKeepsMarkdown(t *testing.T) {
+	markdown := "## \"Home\" -- at last\n\nIt's late... \"Goodnight,\" said Panda---softly.\n"
+	out, err := Ingest(Input{Slug: "home", Title: "Home", Markdown: markdown, MarkdownExtensions: []string{ExtensionTypographer}})
+	if err != nil {
+		t.Fatalf("Ingest returned error: %v", err)
+	}
+	if out.Markdown != markdown {
+		t.Fatalf("markdown changed: %q", out.Markdown)
+	}
+	heading, paragraph := out.Segments[0], out.Segments[1]
+	if heading.Markdown != `## "Home" -- at last` || paragraph.Markdown != strings.TrimSpace(markdown[strings.Index(markdown, "It's"):]) {
+		t.Fatalf("segment markdown = %q, %q", heading.Markdown, paragraph.Markdown)
+	}
+	if !strings.Contains(heading.RenderedHTML, "&ldquo;Home&rdquo; &ndash; at last") {
+		t.Fatalf("heading HTML = %s", heading.RenderedHTML)
+	}
+	for _, want := range []string{"It&rsquo;s late&hellip;", "&ldquo;Goodnight,&rdquo;", "Panda&mdash;softly"} {
+		if !strings.Contains(paragraph.RenderedHTML, want) || !strings.Contains(out.RenderedHTML, want) {
+			t.Errorf("rendered HTML does not contain %q: %s", want, paragraph.RenderedHTML)
+		}
+	}
+
+	plain, err := Ingest(Input{Slug: "home", Title: "Home", Markdown: markdown})
+	if err != nil {
+		t.Fatalf("Ingest returned error: %v", err)
+	}
+	if strings.Contains(plain.RenderedHTML, "&ldquo;") || plain.Segments[0].ContentKey != heading.ContentKey {
+		t.Fatal("typography changed output without opting in")
+	}
+}
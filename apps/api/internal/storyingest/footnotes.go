@@ -0,0 +1,108 @@
+package storyingest
+
+import (
+	"strconv"
+	"strings"
+
+	"pandapages/api/internal/readercontract"
+
+	"github.com/yuin/goldmark/ast"
+	east "github.com/yuin/goldmark/extension/ast"
+)
+
+// chapterFootnotes places each footnote definition at the end of the H2
+// chapter that first references it, so a reader meets the note before the
+// chapter turns. Numbering stays document-wide, matching the full render.
+type chapterFootnotes struct {
+	byIndex map[int]*east.Footnote
+	pending []int
+	emitted map[int]bool
+}
+
+// newChapterFootnotes returns nil when the document has no referenced
+// footnotes; the nil value ignores references and never flushes.
+func newChapterFootnotes(doc ast.Node) *chapterFootnotes {
+	list, ok := doc.LastChild().(*east.FootnoteList)
+	if !ok {
+		return nil
+	}
+	notes := &chapterFootnotes{byIndex: map[int]*east.Footnote{}, emitted: map[int]bool{}}
+	for n := list.FirstChild(); n != nil; n = n.NextSibling() {
+		if footnote, ok := n.(*east.Footnote); ok && footnote.Index > 0 {
+			notes.byIndex[footnote.Index] = footnote
+		}
+	}
+	return notes
+}
+
+// refer records the footnotes block cites and reports whether it cites any.
+func (c *chapterFootnotes) refer(block ast.Node) bool {
+	if c == nil {
+		return false
+	}
+	found := false
+	_ = ast.Walk(block, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		link, ok := n.(*east.FootnoteLink)
+		if !entering || !ok {
+			return ast.WalkContinue, nil
+		}
+		found = true
+		if _, known := c.byIndex[link.Index]; known && !c.emitted[link.Index] {
+			c.emitted[link.Index] = true
+			c.pending = append(c.pending, link.Index)
+		}
+		return ast.WalkContinue, nil
+	})
+	return found
+}
+
+// flush returns a segment holding the footnotes cited since the last flush.
+func (c *chapterFootnotes) flush(e engine, src []byte) (Segment, bool) {
+	if c == nil || len(c.pending) == 0 {
+		return Segment{}, false
+	}
+	var md, rendered, words strings.Builder
+	rendered.WriteString("<div class=\"footnotes\" role=\"doc-endnotes\">\n<hr>\n<ol")
+	// Indices are assigned in citation order, so each chapter's run is contiguous.
+	if first := c.pending[0]; first > 1 {
+		rendered.WriteString(` start="` + strconv.Itoa(first) + `"`)
+	}
+	rendered.WriteString(">\n")
+	for index, number := range c.pending {
+		footnote := c.byIndex[number]
+		if index > 0 {
+			md.WriteString("\n\n")
+		}
+		body := footnoteBody(src, footnote)
+		md.WriteString("[^" + string(footnote.Ref) + "]: " + body)
+		item, _ := e.renderNode(src, footnote)
+		rendered.WriteString(item)
+		words.WriteString(body + " ")
+	}
+	rendered.WriteString("</ol>\n</div>\n")
+	c.pending = nil
+
+	return Segment{
+		Kind:         readercontract.SegmentKindOther,
+		Markdown:     md.String(),
+		RenderedHTML: rendered.String(),
+		WordCount:    wordCount(words.String()),
+	}, true
+}
+
+// footnoteBody rebuilds a definition's Markdown after its label. The parser
+// detaches definitions from their place in the body, so there is no single
+// source span to copy.
+func footnoteBody(src []byte, footnote *east.Footnote) string {
+	parts := []string{}
+	for child := footnote.FirstChild(); child != nil; child = child.NextSibling() {
+		part := extractBlockSource(src, child)
+		if part == "" {
+			part = textContent(src, child)
+		}
+		if part != "" {
+			parts = append(parts, strings.ReplaceAll(part, "\n", "\n    "))
+		}
+	}
+	return strings.Join(parts, "\n\n    ")
+}
@@ -0,0 +1,81 @@
+package storyingest
+
+import (
+	"strings"
+	"testing"
+
+	"pandapages/api/internal/readercontract"
+)
+
+func TestIngestPlacesFootnotesAtChapterEnd(t *testing.T) {
+	markdown := strings.Join([]string{
+		"## One",
+		"",
+		"The fox[^fox] met the crow.[^crow]",
+		"",
+		"[^fox]: Reynard, in the original.",
+		"[^crow]: A rook in some translations.",
+		"",
+		"## Two",
+		"",
+		"The fox again.[^fox] And the bear.[^bear]",
+		"",
+		"[^bear]: Bruin.",
+		"[^unused]: Never cited.",
+		"",
+	}, "\n")
+	out, err := Ingest(Input{Slug: "fables", Title: "Fables", Markdown: markdown, MarkdownExtensions: []string{ExtensionFootnotes}})
+	if err != nil {
+		t.Fatalf("Ingest returned error: %v", err)
+	}
+
+	var kinds []string
+	for _, segment := range out.Segments {
+		kinds = append(kinds, string(segment.Kind))
+	}
+	if got := strings.Join(kinds, ","); got != "heading,paragraph,other,heading,paragraph,other" {
+		t.Fatalf("segment kinds = %s", got)
+	}
+	for index, segment := range out.Segments {
+		if segment.Ordinal != index+1 {
+			t.Fatalf("segment %d ordinal = %d", index, segment.Ordinal)
+		}
+	}
+
+	cite := out.Segments[1]
+	if !strings.Contains(cite.RenderedHTML, `href="#fn:2"`) || strings.Contains(cite.RenderedHTML, "[^") {
+		t.Fatalf("citing paragraph HTML = %s", cite.RenderedHTML)
+	}
+	first := out.Segments[2]
+	if first.Markdown != "[^fox]: Reynard, in the original.\n\n[^crow]: A rook in some translations." {
+		t.Fatalf("first footnotes markdown = %q", first.Markdown)
+	}
+	for _, want := range []string{`<ol>`, `<li id="fn:1">`, `href="#fnref:1"`, `<li id="fn:2">`} {
+		if !strings.Contains(first.RenderedHTML, want) {
+			t.Errorf("first footnotes HTML does not contain %q: %s", want, first.RenderedHTML)
+		}
+	}
+
+	// A repeat citation links back to the first chapter's note; only the new
+	// note is placed after chapter two, numbered on from the first chapter.
+	second := out.Segments[5]
+	if second.Markdown != "[^bear]: Bruin." || !strings.Contains(second.RenderedHTML, `<ol start="3">`) {
+		t.Fatalf("second footnotes = %q %s", second.Markdown, second.RenderedHTML)
+	}
+	if second.Kind != readercontract.SegmentKindOther || second.ChapterKey == nil || *second.ChapterKey != *out.Segments[3].ChapterKey {
+		t.Fatal("chapter footnotes are not part of their chapter")
+	}
+	if strings.Contains(out.RenderedHTML, "Never cited") {
+		t.Fatal("uncited footnote rendered")
+	}
+}
+
+func TestIngestLeavesFootnotesOffByDefault(t *testing.T) {
+	out, err := Ingest(Input{Slug: "fables", Title: "Fables", Markdown: "The fox.[^1]\n\n[^1]: Reynard.\n"})
+	if err != nil {
+		t.Fatalf("Ingest returned error: %v", err)
+	}
+	if len(out.Segments) != 2 || out.Segments[1].Markdown != "[^1]: Reynard." || strings.Contains(out.RenderedHTML, "footnotes") {
+		t.Fatalf("footnotes parsed without opting in: %+v", out.Segments)
+	}
+}
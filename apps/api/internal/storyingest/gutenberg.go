@@ -0,0 +1,62 @@
+package storyingest
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	gutenbergStartRe = regexp.MustCompile(`(?i)^\s*(?:\\?\*){3}\s*START OF (?:THE|THIS) PROJECT GUTENBERG E-?BOOK\b`)
+	gutenbergEndRe   = regexp.MustCompile(`(?i)^\s*(?:\\?\*){3}\s*END OF (?:THE|THIS) PROJECT GUTENBERG E-?BOOK\b`)
+	contentsRe       = regexp.MustCompile(`(?i)^(?:#{1,6}\s+)?(?:table of )?contents\.?$`)
+)
+
+// StripGutenberg removes Project Gutenberg packaging from submitted Markdown:
+// the header before the START marker, the END marker with the licence after
+// it, and a table of contents running up to the next heading. Frontmatter is
+// kept. Each removal is reported as a Problem located in the original input,
+// so callers can tell the admin what changed. Markdown without any of these
+// is returned unchanged.
+func StripGutenberg(markdown string) (string, []Problem) {
+	body, bodyLine := markdown, 1
+	if _, split, _, line, err := cutFrontmatter(markdown); err == nil {
+		body, bodyLine = split, line
+	}
+	prefix := markdown[:len(markdown)-len(body)]
+	lines := strings.Split(body, "\n")
+	offset := 0
+	problems := []Problem{}
+
+	for index, line := range lines {
+		if gutenbergStartRe.MatchString(line) {
+			problems = append(problems, Problem{Field: "markdown", Code: "boilerplate_removed", Message: "Project Gutenberg header removed", Line: bodyLine + index})
+			lines, offset = lines[index+1:], index+1
+			break
+		}
+	}
+	for index, line := range lines {
+		if gutenbergEndRe.MatchString(line) {
+			problems = append(problems, Problem{Field: "markdown", Code: "boilerplate_removed", Message: "Project Gutenberg licence removed", Line: bodyLine + offset + index})
+			lines = lines[:index]
+			break
+		}
+	}
+	for index, line := range lines {
+		if !contentsRe.MatchString(strings.TrimSpace(line)) {
+			continue
+		}
+		for next := index + 1; next < len(lines); next++ {
+			if strings.HasPrefix(strings.TrimSpace(lines[next]), "#") {
+				problems = append(problems, Problem{Field: "markdown", Code: "contents_removed", Message: "Table of contents removed", Line: bodyLine + offset + index})
+				lines = append(lines[:index:index], lines[next:]...)
+				break
+			}
+		}
+		break
+	}
+
+	if len(problems) == 0 {
+		return markdown, problems
+	}
+	return prefix + strings.TrimLeft(strings.Join(lines, "\n"), "\n"), problems
+}
@@ -0,0 +1,55 @@
+package storyingest
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStripGutenbergRemovesPackaging(t *testing.T) {
+	markdown := strings.Join([]string{
+		"---",
+		"title: The Tale",
+		"---",
+		"The Project Gutenberg eBook of The Tale",
+		"",
+		"*** START OF THE PROJECT GUTENBERG EBOOK THE TALE ***",
+		"",
+		"CONTENTS",
+		"",
+		"Chapter I ..... 1",
+		"",
+		"## Chapter I",
+		"",
+		"Once upon a time.",
+		"",
+		"*** END OF THE PROJECT GUTENBERG EBOOK THE TALE ***",
+		"",
+		"Updated editions will replace the previous one.",
+	}, "\n")
+	got, problems := StripGutenberg(markdown)
+	want := "---\ntitle: The Tale\n---\n## Chapter I\n\nOnce upon a time.\n"
+	if got != want {
+		t.Fatalf("StripGutenberg = %q, want %q", got, want)
+	}
+	var lines []int
+	for _, problem := range problems {
+		lines = append(lines, problem.Line)
+	}
+	if len(problems) != 3 || problems[0].Code != "boilerplate_removed" || problems[2].Code != "contents_removed" ||
+		lines[0] != 6 || lines[1] != 16 || lines[2] != 8 {
+		t.Fatalf("problems = %#v", problems)
+	}
+}
+
+func TestStripGutenbergLeavesOrdinaryStories(t *testing.T) {
+	for _, markdown := range []string{
+		"# Tale\n\nOnce upon a time.\n",
+		// A contents line with no following heading is not removed.
+		"Contents\n\nThe box held contents of every kind.\n",
+	} {
+		got, problems := StripGutenberg(markdown)
+		if got != markdown || len(problems) != 0 {
+			t.Fatalf("StripGutenberg(%q) = %q, %#v", markdown, got, problems)
+		}
+	}
+}
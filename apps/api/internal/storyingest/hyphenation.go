@@ -0,0 +1,180 @@
+package storyingest
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/util"
+)
+
+// SoftHyphen marks a point where a browser may break a word. Readers that do
+// not want hyphenation remove it from rendered HTML.
+const SoftHyphen = "\u00ad"
+
+const (
+	// minHyphenatedWord is the shortest word hyphenation touches; shorter
+	// words rarely upset justification on a phone-width column.
+	minHyphenatedWord = 7
+	// Each part of a hyphenated word keeps at least this many letters, so no
+	// line ends in a lone syllable such as "a-".
+	minHyphenPrefix = 2
+	minHyphenSuffix = 3
+)
+
+// hyphenationClusters lists per language the consonant pairs that spell one
+// sound and so are never split. Languages missing here are not hyphenated.
+var hyphenationClusters = map[string][]string{
+	"en": {"ch", "ck", "gh", "ng", "ph", "qu", "sh", "th", "wh", "wr"},
+	"fr": {"ch", "gn", "ph", "qu", "th"},
+	"de": {"ch", "ck", "ph", "qu", "sch", "th"},
+	"es": {"ch", "ll", "qu", "rr"},
+	"it": {"ch", "gh", "gl", "gn", "qu", "sc"},
+	"pt": {"ch", "lh", "nh", "qu", "rr", "ss"},
+	"nl": {"ch", "ng", "sch", "th"},
+}
+
+// hyphenationLanguage returns the cluster set for a language tag, or false
+// when hyphenation does not support it.
+func hyphenationLanguage(language string) ([]string, bool) {
+	for primary, clusters := range hyphenationClusters {
+		if SameLanguage(language, primary) {
+			return clusters, true
+		}
+	}
+	return nil, false
+}
+
+func isHyphenVowel(r rune) bool {
+	return strings.ContainsRune("aeiouyàâäáãåæèéêëìíîïòóôöõøùúûüœ", unicode.ToLower(r))
+}
+
+// hyphenateWord inserts soft hyphens before syllable onsets: a consonant, or
+// a cluster, followed by a vowel. The break goes after a vowel (ba-by) or
+// between two consonants that do not form a cluster (lan-tern). It is a
+// heuristic, not a dictionary, so it places fewer breaks rather than wrong
+// ones.
+func hyphenateWord(word []rune, clusters []string) string {
+	if len(word) < minHyphenatedWord {
+		return string(word)
+	}
+	lower := []rune(strings.ToLower(string(word)))
+	vowel := func(at int) bool { return at >= 0 && at < len(lower) && isHyphenVowel(lower[at]) }
+	consonant := func(at int) bool { return at >= 0 && at < len(lower) && !isHyphenVowel(lower[at]) }
+	clusterAt := func(at int) int {
+		for _, cluster := range clusters {
+			runes := []rune(cluster)
+			if at+len(runes) <= len(lower) && string(lower[at:at+len(runes)]) == cluster {
+				return len(runes)
+			}
+		}
+		return 0
+	}
+	onset := func(at int) bool {
+		if size := clusterAt(at); size > 0 {
+			return vowel(at + size)
+		}
+		return consonant(at) && vowel(at+1)
+	}
+	splitsCluster := func(at int) bool {
+		for start := max(at-2, 0); start < at; start++ {
+			if start+clusterAt(start) > at {
+				return true
+			}
+		}
+		return false
+	}
+
+	var b strings.Builder
+	last := 0
+	for index := minHyphenPrefix; index <= len(word)-minHyphenSuffix; index++ {
+		if index-last < minHyphenPrefix || !onset(index) || splitsCluster(index) {
+			continue
+		}
+		if !vowel(index-1) && !(consonant(index-1) && vowel(index-2)) {
+			continue
+		}
+		b.WriteString(string(word[last:index]))
+		b.WriteString(SoftHyphen)
+		last = index
+	}
+	b.WriteString(string(word[last:]))
+	return b.String()
+}
+
+// hyphenateText hyphenates each run of letters. Runs touching '&' or '\' are
+// left alone so entity references and escapes still resolve.
+func hyphenateText(value string, clusters []string) string {
+	runes := []rune(value)
+	var b strings.Builder
+	for index := 0; index < len(runes); {
+		if !unicode.IsLetter(runes[index]) {
+			b.WriteRune(runes[index])
+			index++
+			continue
+		}
+		end := index
+		for end < len(runes) && unicode.IsLetter(runes[end]) {
+			end++
+		}
+		protected := index > 0 && (runes[index-1] == '&' || runes[index-1] == '\\')
+		if protected {
+			b.WriteString(string(runes[index:end]))
+		} else {
+			b.WriteString(hyphenateWord(runes[index:end], clusters))
+		}
+		index = end
+	}
+	return b.String()
+}
+
+// hyphenationRenderer replaces the HTML renderer for text, adding soft
+// hyphens to prose. Code spans are written as authored.
+type hyphenationRenderer struct {
+	clusters []string
+	writer   html.Writer
+}
+
+func (r hyphenationRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(ast.KindText, func(w util.BufWriter, src []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		n := node.(*ast.Text)
+		value := n.Segment.Value(src)
+		if n.IsRaw() {
+			r.writer.RawWrite(w, value)
+			return ast.WalkContinue, nil
+		}
+		if _, code := n.Parent().(*ast.CodeSpan); !code {
+			value = []byte(hyphenateText(string(value), r.clusters))
+		}
+		r.writer.Write(w, value)
+		if n.HardLineBreak() {
+			_, _ = w.WriteString("<br>\n")
+		} else if n.SoftLineBreak() {
+			_ = w.WriteByte('\n')
+		}
+		return ast.WalkContinue, nil
+	})
+}
+
+// hyphenation is built per ingest because its rules depend on the story
+// language; see newEngine.
+type hyphenation struct {
+	language string
+}
+
+func (h hyphenation) Extend(m goldmark.Markdown) {
+	clusters, ok := hyphenationLanguage(h.language)
+	if !ok {
+		return
+	}
+	// The default HTML renderer registers text at priority 1000; a lower
+	// value registers later and so replaces it.
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(util.Prioritized(
+		hyphenationRenderer{clusters: clusters, writer: html.DefaultWriter}, 500)))
+}
@@ -0,0 +1,39 @@
+package storyingest
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIngestHyphenatesLongWords(t *testing.T) {
+	markdown := "The wonderful lantern glowed beside `wonderful` code.\n"
+	out, err := Ingest(Input{Slug: "lantern", Title: "Lantern", Language: "en-GB", Markdown: markdown, MarkdownExtensions: []string{ExtensionHyphenation}})
+	if err != nil {
+		t.Fatalf("Ingest returned error: %v", err)
+	}
+	want := "<p>The won\u00adder\u00adful lan\u00adtern glowed beside <code>wonderful</code> code.</p>\n"
+	if got := out.Segments[0].RenderedHTML; got != want {
+		t.Fatalf("rendered HTML = %q, want %q", got, want)
+	}
+	if out.Segments[0].WordCount != 7 || out.Readability.Words != 7 || out.Vocabulary.Frequencies["wonderful"] != 2 {
+		t.Fatalf("soft hyphens changed word counts: %d, %#v, %#v", out.Segments[0].WordCount, out.Readability, out.Vocabulary.Frequencies)
+	}
+}
+
+func TestHyphenationSkipsUnsupportedLanguages(t *testing.T) {
+	markdown := "Пожалуйста, расскажите сказку.\n"
+	out, err := Ingest(Input{Slug: "skazka", Title: "Skazka", Language: "ru", Markdown: markdown, MarkdownExtensions: []string{ExtensionHyphenation}})
+	if err != nil {
+		t.Fatalf("Ingest returned error: %v", err)
+	}
+	if strings.Contains(out.RenderedHTML, SoftHyphen) {
+		t.Fatalf("rendered HTML = %q", out.RenderedHTML)
+	}
+}
+
+func TestHyphenateTextLeavesEntitiesAlone(t *testing.T) {
+	clusters, _ := hyphenationLanguage("en")
+	if got := hyphenateText("&NotGreaterFullEqual; breakfast", clusters); got != "&NotGreaterFullEqual; break\u00adfast" {
+		t.Fatalf("hyphenateText = %q", got)
+	}
+}
@@ -0,0 +1,109 @@
+package storyingest
+
+import (
+	"encoding/json"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// LanguageConfidenceKey records how sure detection was when a story's
+// language was detected rather than declared.
+const LanguageConfidenceKey = "languageConfidence"
+
+// DefaultLanguage is used when a story declares no language and detection
+// cannot tell.
+const DefaultLanguage = "en-GB"
+
+// minLanguageEvidence is how many function words detection needs before it
+// will name a language.
+const minLanguageEvidence = 8
+
+// languageStopwords are frequent function words per language. Detection
+// counts them, so a few hundred words of prose are enough to separate these
+// languages; anything else falls back to DefaultLanguage.
+var languageStopwords = map[string][]string{
+	DefaultLanguage: {"the", "and", "of", "to", "in", "is", "was", "he", "she", "it", "that", "you", "with", "for", "his", "her", "on", "had", "but", "not", "they", "at", "said", "were"},
+	"fr":            {"le", "la", "les", "et", "des", "un", "une", "est", "il", "elle", "qui", "dans", "pas", "pour", "sur", "au", "du", "ne", "je", "vous", "avec", "était", "mais", "ce"},
+	"de":            {"der", "die", "das", "und", "ist", "nicht", "ein", "eine", "zu", "den", "mit", "sich", "auf", "für", "im", "dem", "von", "sie", "er", "es", "ich", "war", "auch", "wie"},
+	"es":            {"el", "los", "las", "y", "que", "en", "una", "es", "no", "se", "por", "con", "para", "su", "al", "lo", "como", "pero", "del", "le", "ya", "muy", "dijo", "estaba"},
+	"it":            {"il", "gli", "e", "di", "che", "non", "è", "per", "con", "della", "si", "ma", "come", "anche", "sono", "nel", "alla", "era", "questo", "una", "mi", "ha", "lo", "le"},
+	"pt":            {"o", "os", "e", "que", "não", "um", "uma", "é", "em", "do", "da", "para", "com", "se", "por", "mas", "como", "ao", "dos", "ele", "ela", "disse", "muito", "estava"},
+	"nl":            {"de", "het", "een", "en", "van", "is", "niet", "dat", "die", "te", "op", "met", "zijn", "voor", "er", "ook", "aan", "maar", "als", "hij", "zij", "was", "ik", "naar"},
+}
+
+var stopwordLanguages = func() map[string][]string {
+	index := map[string][]string{}
+	for language, words := range languageStopwords {
+		for _, word := range words {
+			index[word] = append(index[word], language)
+		}
+	}
+	return index
+}()
+
+// DetectLanguage names the language of text and how confident detection is,
+// from 0 to 1. It reports false when the text has too little evidence.
+func DetectLanguage(text string) (string, float64, bool) {
+	scores := map[string]int{}
+	total := 0
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '\''
+	}) {
+		languages := stopwordLanguages[word]
+		for _, language := range languages {
+			scores[language]++
+		}
+		if len(languages) > 0 {
+			total++
+		}
+	}
+	languages := make([]string, 0, len(scores))
+	for language := range scores {
+		languages = append(languages, language)
+	}
+	// Ties go to the alphabetically first tag so detection is deterministic.
+	sort.Slice(languages, func(i, j int) bool {
+		if scores[languages[i]] != scores[languages[j]] {
+			return scores[languages[i]] > scores[languages[j]]
+		}
+		return languages[i] < languages[j]
+	})
+	if len(languages) == 0 || scores[languages[0]] < minLanguageEvidence {
+		return "", 0, false
+	}
+	confidence := float64(scores[languages[0]]) / float64(total)
+	return languages[0], math.Round(confidence*100) / 100, true
+}
+
+// SameLanguage reports whether two language tags share a primary language,
+// so en-GB and en-US agree.
+func SameLanguage(left, right string) bool {
+	primary := func(tag string) string {
+		tag, _, _ = strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+		return tag
+	}
+	return primary(left) == primary(right)
+}
+
+// languageConfidence returns a stored detection confidence, which must be a
+// number from 0 to 1.
+func languageConfidence(raw any) (float64, bool) {
+	var value float64
+	switch typed := raw.(type) {
+	case float64:
+		value = typed
+	case int:
+		value = float64(typed)
+	case json.Number:
+		parsed, err := typed.Float64()
+		if err != nil {
+			return 0, false
+		}
+		value = parsed
+	default:
+		return 0, false
+	}
+	return value, value >= 0 && value <= 1
+}
@@ -0,0 +1,58 @@
+package storyingest
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+const frenchTale = "Il était une fois une petite souris qui vivait dans la maison du meunier. " +
+	"Elle ne sortait pas le jour, mais la nuit elle allait dans la cuisine pour chercher du pain et des miettes sur la table."
+
+func TestDetectLanguage(t *testing.T) {
+	language, confidence, ok := DetectLanguage(frenchTale)
+	if !ok || language != "fr" || confidence < 0.6 || confidence > 1 {
+		t.Fatalf("DetectLanguage = %q, %v, %v", language, confidence, ok)
+	}
+	if _, _, ok := DetectLanguage("Once upon a time."); ok {
+		t.Fatal("detected a language from a single sentence")
+	}
+	if !SameLanguage("en-GB", "EN-us") || SameLanguage("en-GB", "fr") {
+		t.Fatal("SameLanguage compares primary subtags")
+	}
+}
+
+func TestIngestDetectsUndeclaredLanguage(t *testing.T) {
+	out, err := Ingest(Input{Slug: "souris", Title: "La souris", Markdown: frenchTale})
+	if err != nil {
+		t.Fatalf("Ingest returned error: %v", err)
+	}
+	confidence, ok := out.Frontmatter[LanguageConfidenceKey].(float64)
+	if out.Language != "fr" || out.Frontmatter["language"] != "fr" || !ok || confidence <= 0 {
+		t.Fatalf("language = %q, frontmatter = %#v", out.Language, out.Frontmatter)
+	}
+
+	// Re-canonicalising the stored version keeps the recorded detection.
+	encoded, _ := json.Marshal(out.Frontmatter)
+	var stored map[string]any
+	if err := json.Unmarshal(encoded, &stored); err != nil {
+		t.Fatal(err)
+	}
+	again, err := CanonicalizeStoredBody(Input{Slug: "souris", Title: "La souris", Language: "fr", Markdown: frenchTale}, stored)
+	if err != nil {
+		t.Fatalf("CanonicalizeStoredBody returned error: %v", err)
+	}
+	if again.Frontmatter[LanguageConfidenceKey] != confidence {
+		t.Fatalf("stored frontmatter = %#v", again.Frontmatter)
+	}
+
+	declared, err := Ingest(Input{Slug: "souris", Title: "La souris", Language: "en-GB", Markdown: frenchTale})
+	if err != nil {
+		t.Fatalf("Ingest returned error: %v", err)
+	}
+	if declared.Language != "en-GB" {
+		t.Fatalf("declared language = %q", declared.Language)
+	}
+	if _, ok := declared.Frontmatter[LanguageConfidenceKey]; ok {
+		t.Fatal("declared language recorded a detection confidence")
+	}
+}
@@ -0,0 +1,102 @@
+package storyingest
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+// Stories reference uploaded media as ![alt](media:<id>). Rendering rewrites
+// the destination to MediaPath, which serves the account's media by ID.
+const (
+	MediaRefPrefix = "media:"
+	MediaPath      = "/api/v1/media/"
+)
+
+var mediaIDRe = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+
+// MediaURL returns the serving path for an uploaded media ID.
+func MediaURL(id string) string {
+	return MediaPath + id
+}
+
+// mediaTransformer rewrites media: image destinations before rendering.
+// Malformed references are left in place for ingest to reject.
+type mediaTransformer struct{}
+
+func (mediaTransformer) Transform(doc *ast.Document, _ text.Reader, _ parser.Context) {
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		image, ok := n.(*ast.Image)
+		if !entering || !ok {
+			return ast.WalkContinue, nil
+		}
+		destination := strings.TrimSpace(string(image.Destination))
+		if id, found := strings.CutPrefix(destination, MediaRefPrefix); found {
+			if id = strings.ToLower(id); mediaIDRe.MatchString(id) {
+				image.Destination = []byte(MediaURL(id))
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+}
+
+// storyMedia is what a parsed body references: uploaded media IDs, every
+// distinct image destination as rendered, and media: references that are not
+// valid IDs.
+type storyMedia struct {
+	IDs       []string
+	URLs      []string
+	Malformed []string
+}
+
+func collectMedia(doc ast.Node) storyMedia {
+	media := storyMedia{IDs: []string{}, URLs: []string{}}
+	seen := map[string]bool{}
+	for _, destination := range imageDestinations(doc) {
+		switch {
+		case strings.HasPrefix(destination, MediaRefPrefix):
+			media.Malformed = append(media.Malformed, destination)
+			continue
+		case strings.HasPrefix(destination, MediaPath):
+			if id := strings.TrimPrefix(destination, MediaPath); mediaIDRe.MatchString(id) && !seen[id] {
+				seen[id] = true
+				media.IDs = append(media.IDs, id)
+			}
+		}
+		media.URLs = append(media.URLs, destination)
+	}
+	return media
+}
+
+// MediaRefs returns the distinct image destinations a story body references,
+// in document order. Destinations are reported verbatim; callers decide how
+// to resolve or fetch them.
+func MediaRefs(markdown string) []string {
+	src := []byte(markdown)
+	return imageDestinations(goldmark.New().Parser().Parse(text.NewReader(src)))
+}
+
+func imageDestinations(doc ast.Node) []string {
+	refs := []string{}
+	seen := map[string]bool{}
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		image, ok := n.(*ast.Image)
+		if !ok {
+			return ast.WalkContinue, nil
+		}
+		destination := strings.TrimSpace(string(image.Destination))
+		if destination != "" && !seen[destination] {
+			seen[destination] = true
+			refs = append(refs, destination)
+		}
+		return ast.WalkContinue, nil
+	})
+	return refs
+}
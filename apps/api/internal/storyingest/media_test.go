@@ -0,0 +1,53 @@
+package storyingest
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMediaRefsListsDistinctImagesInOrder(t *testing.T) {
+	markdown := "# Title\n\n![Fox](images/fox.png) and ![Hen](https://example.test/hen.jpg)\n\n" +
+		"Again ![Fox again](images/fox.png)\n\n[not an image](images/link.png)\n"
+
+	got := MediaRefs(markdown)
+	want := []string{"images/fox.png", "https://example.test/hen.jpg"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("MediaRefs = %#v, want %#v", got, want)
+	}
+}
+
+func TestMediaRefsEmptyWithoutImages(t *testing.T) {
+	if got := MediaRefs("# Title\n\nNo pictures here.\n"); len(got) != 0 {
+		t.Fatalf("MediaRefs = %#v, want empty", got)
+	}
+}
+
+func TestIngestRewritesUploadedMediaReferences(t *testing.T) {
+	id := "0b0c7f0e-8b7a-4f55-9a43-a0a1b3b1c2d3"
+	markdown := "![Fox](media:" + id + ")\n\n![Hen](https://example.test/hen.jpg) and ![Fox again](media:" + id + ")\n"
+	out, err := Ingest(Input{Slug: "farm", Title: "Farm", Markdown: markdown})
+	if err != nil {
+		t.Fatalf("Ingest returned error: %v", err)
+	}
+	if !reflect.DeepEqual(out.MediaIDs, []string{id}) {
+		t.Fatalf("MediaIDs = %#v", out.MediaIDs)
+	}
+	if !reflect.DeepEqual(out.MediaURLs, []string{MediaURL(id), "https://example.test/hen.jpg"}) {
+		t.Fatalf("MediaURLs = %#v", out.MediaURLs)
+	}
+	wantSrc := `src="/api/v1/media/` + id + `"`
+	if !strings.Contains(out.RenderedHTML, wantSrc) || !strings.Contains(out.Segments[0].RenderedHTML, wantSrc) {
+		t.Fatalf("rendered HTML = %s", out.RenderedHTML)
+	}
+	if out.Markdown != markdown || !strings.Contains(out.Segments[0].Markdown, "media:"+id) {
+		t.Fatal("media references were rewritten in stored Markdown")
+	}
+
+	_, err = Ingest(Input{Slug: "farm", Title: "Farm", Markdown: "![Fox](media:fox.png)\n"})
+	var inputErr *InputError
+	if !errors.As(err, &inputErr) || inputErr.Problems[0].Code != "invalid_media" {
+		t.Fatalf("error = %#v, want invalid_media", err)
+	}
+}
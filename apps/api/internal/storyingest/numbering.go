@@ -0,0 +1,97 @@
+package storyingest
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"pandapages/api/internal/readercontract"
+
+	"github.com/yuin/goldmark/ast"
+)
+
+// NumberChaptersKey records in frontmatter that chapter headings are
+// numbered at ingest, so stored versions re-derive the same headings.
+const NumberChaptersKey = "numberChapters"
+
+// chapterWords names a chapter in the languages hyphenation supports; other
+// languages use English.
+var chapterWords = map[string]string{
+	"en": "Chapter",
+	"fr": "Chapitre",
+	"de": "Kapitel",
+	"es": "Capítulo",
+	"it": "Capitolo",
+	"pt": "Capítulo",
+	"nl": "Hoofdstuk",
+}
+
+// numberedHeadingRe matches headings that already carry a number, such as
+// "Chapter 3", "Part Two", "12. The Storm" or "IV: Home".
+var numberedHeadingRe = regexp.MustCompile(`^(?:(?i:(?:chapter|chapitre|kapitel|cap[ií]tulo|capitolo|hoofdstuk|part|book)\s+(?:\d+|[ivxlcdm]+|one|two|three|four|five|six|seven|eight|nine|ten|first|second|third)\b)|\d+\b|[IVXLCDM]+[.:)])`)
+
+func chapterWord(language string) string {
+	for primary, word := range chapterWords {
+		if SameLanguage(language, primary) {
+			return word
+		}
+	}
+	return chapterWords["en"]
+}
+
+// numberedChapterTitle prefixes a chapter title with its number, as in
+// "Chapter 3 — The Dark Forest". An empty title becomes "Chapter 3".
+func numberedChapterTitle(number int, title, language string) string {
+	label := fmt.Sprintf("%s %d", chapterWord(language), number)
+	if title == "" {
+		return label
+	}
+	return label + " — " + title
+}
+
+// resolveChapterNumbering prefers the explicit input field and falls back to
+// frontmatter. Numbered headings would no longer match a chapter pattern, so
+// the two cannot be combined.
+func resolveChapterNumbering(in Input, fm map[string]any, chapterPattern string) (bool, error) {
+	number, field := in.NumberChapters, "numberChapters"
+	if !number {
+		field = "frontmatter." + NumberChaptersKey
+		if raw, exists := fm[NumberChaptersKey]; exists && raw != nil {
+			value, ok := raw.(bool)
+			if !ok {
+				return false, inputError(field, "invalid_chapter_numbering", 0, "numberChapters must be true or false")
+			}
+			number = value
+		}
+	}
+	if number && chapterPattern != "" {
+		return false, inputError(field, "invalid_chapter_numbering", 0, "numberChapters cannot be combined with chapterPattern")
+	}
+	return number, nil
+}
+
+// headingSourceText is the heading text a heading segment is built from.
+func headingSourceText(src []byte, heading *ast.Heading) string {
+	if txt := textContent(src, heading); txt != "" {
+		return txt
+	}
+	return extractBlockSource(src, heading)
+}
+
+// chaptersNumbered reports whether any chapter heading already carries a
+// number. Numbering is all or nothing, so such a story is left as authored
+// rather than numbered twice.
+func chaptersNumbered(src []byte, doc ast.Node, rule readercontract.ChapterRule) bool {
+	for n := doc.FirstChild(); n != nil; n = n.NextSibling() {
+		heading, ok := n.(*ast.Heading)
+		if !ok {
+			continue
+		}
+		txt := headingSourceText(src, heading)
+		block := strings.Repeat("#", heading.Level) + " " + txt
+		if rule.Opens(readercontract.SegmentKindHeading, heading.Level, block) && numberedHeadingRe.MatchString(txt) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,82 @@
+package storyingest
+
+import (
+	"bytes"
+	"regexp"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// A page break is a line holding only \pagebreak or <!-- pagebreak -->.
+var pageBreakRe = regexp.MustCompile(`(?i)^(?:\\pagebreak|<!--\s*pagebreak\s*-->)$`)
+
+// PageBreak is an authored page boundary for fixed-layout stories.
+type PageBreak struct {
+	ast.BaseBlock
+}
+
+// KindPageBreak is the NodeKind of PageBreak.
+var KindPageBreak = ast.NewNodeKind("PageBreak")
+
+func (n *PageBreak) Kind() ast.NodeKind {
+	return KindPageBreak
+}
+
+func (n *PageBreak) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, nil, nil)
+}
+
+type pageBreakParser struct{}
+
+func (pageBreakParser) Trigger() []byte {
+	return []byte{'\\', '<'}
+}
+
+func (pageBreakParser) Open(_ ast.Node, reader text.Reader, _ parser.Context) (ast.Node, parser.State) {
+	line, segment := reader.PeekLine()
+	if !pageBreakRe.Match(bytes.TrimSpace(line)) {
+		return nil, parser.NoChildren
+	}
+	node := &PageBreak{}
+	segment = segment.TrimLeftSpace(reader.Source())
+	node.Lines().Append(segment.TrimRightSpace(reader.Source()))
+	reader.AdvanceToEOL()
+	return node, parser.NoChildren
+}
+
+func (pageBreakParser) Continue(ast.Node, text.Reader, parser.Context) parser.State {
+	return parser.Close
+}
+
+func (pageBreakParser) Close(ast.Node, text.Reader, parser.Context) {}
+
+func (pageBreakParser) CanInterruptParagraph() bool {
+	return true
+}
+
+func (pageBreakParser) CanAcceptIndentedLine() bool {
+	return false
+}
+
+type pageBreakRenderer struct{}
+
+func (pageBreakRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(KindPageBreak, func(w util.BufWriter, _ []byte, _ ast.Node, entering bool) (ast.WalkStatus, error) {
+		if entering {
+			_, _ = w.WriteString("<div class=\"page-break\" role=\"separator\"></div>\n")
+		}
+		return ast.WalkSkipChildren, nil
+	})
+}
+
+type pageBreaks struct{}
+
+func (pageBreaks) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(parser.WithBlockParsers(util.Prioritized(pageBreakParser{}, 150)))
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(util.Prioritized(pageBreakRenderer{}, 500)))
+}
@@ -0,0 +1,44 @@
+package storyingest
+
+import (
+	"strings"
+	"testing"
+
+	"pandapages/api/internal/readercontract"
+)
+
+func TestIngestTurnsPageBreakDirectivesIntoSegments(t *testing.T) {
+	markdown := "The bear woke up.\n\\pagebreak\nThe bear ate honey.\n\n  <!-- PageBreak -->\n\nThe bear slept.\n"
+	out, err := Ingest(Input{Slug: "bear", Title: "Bear", Markdown: markdown, MarkdownExtensions: []string{ExtensionPageBreaks}})
+	if err != nil {
+		t.Fatalf("Ingest returned error: %v", err)
+	}
+	var kinds []string
+	for _, segment := range out.Segments {
+		kinds = append(kinds, string(segment.Kind))
+	}
+	if got := strings.Join(kinds, ","); got != "paragraph,pagebreak,paragraph,pagebreak,paragraph" {
+		t.Fatalf("segment kinds = %s", got)
+	}
+	first, second := out.Segments[1], out.Segments[3]
+	if first.Markdown != `\pagebreak` || second.Markdown != "<!-- PageBreak -->" || first.ContentKey == second.ContentKey {
+		t.Fatalf("page breaks = %q %q", first.Markdown, second.Markdown)
+	}
+	wantHTML := "<div class=\"page-break\" role=\"separator\"></div>\n"
+	if first.RenderedHTML != wantHTML || first.WordCount != 0 || first.Kind != readercontract.SegmentKindPageBreak {
+		t.Fatalf("page break segment = %#v", first)
+	}
+	if strings.Count(out.RenderedHTML, wantHTML) != 2 {
+		t.Fatalf("rendered HTML = %s", out.RenderedHTML)
+	}
+
+	plain, err := Ingest(Input{Slug: "bear", Title: "Bear", Markdown: markdown})
+	if err != nil {
+		t.Fatalf("Ingest returned error: %v", err)
+	}
+	for _, segment := range plain.Segments {
+		if segment.Kind == readercontract.SegmentKindPageBreak {
+			t.Fatal("page breaks parsed without opting in")
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package storyingest
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("pandapages/api/internal/storyingest")
+
+// phases traces the stages of one ingest as consecutive child spans of the
+// caller's span, so a slow ingest shows whether parsing, rendering or
+// segmentation took the time. Without a caller span nothing is recorded:
+// re-rendering stored versions in the background would otherwise start a
+// new trace per version.
+type phases struct {
+	ctx     context.Context
+	current trace.Span
+}
+
+func newPhases(ctx context.Context) *phases {
+	return &phases{ctx: ctx}
+}
+
+// start ends the running phase, if any, and begins the named one.
+func (p *phases) start(name string) {
+	p.end()
+	if !trace.SpanContextFromContext(p.ctx).IsValid() {
+		return
+	}
+	_, p.current = tracer.Start(p.ctx, "storyingest."+name)
+}
+
+// end ends the running phase; ingest defers it to cover early returns.
+func (p *phases) end() {
+	if p.current != nil {
+		p.current.End()
+		p.current = nil
+	}
+}
@@ -0,0 +1,47 @@
+package storyingest
+
+import (
+	"slices"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestIngestContextTracesEachPhaseUnderTheCallersSpan(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	in := Input{Slug: "the-snail", Title: "The Snail", Markdown: "# One\n\nThe snail went home.\n"}
+	if _, err := Ingest(in); err != nil {
+		t.Fatalf("Ingest: %v", err)
+	}
+	if spans := recorder.Ended(); len(spans) != 0 {
+		t.Fatalf("ingest without a caller span recorded %d spans", len(spans))
+	}
+
+	ctx, parent := otel.Tracer("test").Start(t.Context(), "request")
+	if _, err := IngestContext(ctx, in); err != nil {
+		t.Fatalf("IngestContext: %v", err)
+	}
+	parent.End()
+
+	var names []string
+	for _, span := range recorder.Ended() {
+		if span.Name() == "request" {
+			continue
+		}
+		if span.Parent().SpanID() != parent.SpanContext().SpanID() {
+			t.Fatalf("%s is not a child of the caller's span", span.Name())
+		}
+		names = append(names, span.Name())
+	}
+	want := []string{
+		"storyingest.frontmatter", "storyingest.language", "storyingest.parse", "storyingest.render",
+		"storyingest.segment", "storyingest.identify", "storyingest.measure",
+	}
+	if !slices.Equal(names, want) {
+		t.Fatalf("phases = %v, want %v", names, want)
+	}
+}
@@ -0,0 +1,188 @@
+package storyingest
+
+import (
+	"bytes"
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+	"go.yaml.in/yaml/v3"
+)
+
+// Problem is one located defect in story input. Line is 1-based within the
+// submitted Markdown, frontmatter included; zero means the problem belongs to
+// a field rather than a line.
+type Problem struct {
+	Field   string
+	Code    string
+	Message string
+	Line    int
+}
+
+// InputError reports why Ingest rejected its input. Callers that only need a
+// message can use Error; validation surfaces read Problems.
+type InputError struct {
+	Problems []Problem
+}
+
+func (e *InputError) Error() string {
+	if len(e.Problems) == 0 {
+		return "story input is invalid"
+	}
+	return e.Problems[0].Message
+}
+
+func inputError(field, code string, line int, message string) *InputError {
+	return &InputError{Problems: []Problem{{Field: field, Code: code, Message: message, Line: line}}}
+}
+
+var yamlLineRe = regexp.MustCompile(`line (\d+):`)
+
+// yamlErrorLine returns the frontmatter-relative line named by a YAML error.
+func yamlErrorLine(err error) int {
+	match := yamlLineRe.FindStringSubmatch(err.Error())
+	if match == nil {
+		return 0
+	}
+	line, _ := strconv.Atoi(match[1])
+	return line
+}
+
+// frontmatterStringKeys are read as story metadata, so any other YAML type
+// would be silently ignored.
+var frontmatterStringKeys = []string{"title", "author", "language", "sourceUrl"}
+
+// frontmatterTypeProblems checks the keys Ingest reads from frontmatter.
+// firstLine is the Markdown line of the opening delimiter.
+func frontmatterTypeProblems(fmText string, firstLine int) []Problem {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(fmText), &doc); err != nil || len(doc.Content) == 0 {
+		return nil
+	}
+	mapping := doc.Content[0]
+	if mapping.Kind != yaml.MappingNode {
+		return nil
+	}
+	problems := []Problem{}
+	for index := 0; index+1 < len(mapping.Content); index += 2 {
+		key, value := mapping.Content[index], mapping.Content[index+1]
+		line := firstLine + key.Line
+		switch {
+		case key.Value == "rights":
+			if value.Kind != yaml.MappingNode {
+				problems = append(problems, Problem{Field: "frontmatter.rights", Code: "type_mismatch", Message: "rights must be an object", Line: line})
+			}
+		case key.Value == MarkdownExtensionsKey:
+			var names any
+			if value.Decode(&names) != nil {
+				names = value.Value
+			}
+			if _, ok := normalizeExtensions(names); !ok {
+				problems = append(problems, Problem{Field: "frontmatter." + MarkdownExtensionsKey, Code: "unsupported_extension", Message: "markdownExtensions must list supported extensions", Line: line})
+			}
+		case key.Value == ChapterLevelKey:
+			var level any
+			if value.Decode(&level) != nil {
+				level = value.Value
+			}
+			if _, ok := chapterLevelValue(level); !ok {
+				problems = append(problems, Problem{Field: "frontmatter." + ChapterLevelKey, Code: "invalid_chapter_level", Message: "chapterLevel must be a heading level from 1 to 6", Line: line})
+			}
+		case key.Value == ChapterPatternKey:
+			if value.Kind != yaml.ScalarNode || (value.Tag != "!!str" && value.Tag != "!!null") {
+				problems = append(problems, Problem{Field: "frontmatter." + ChapterPatternKey, Code: "invalid_chapter_pattern", Message: "chapterPattern must be a string", Line: line})
+			} else if _, err := compileChapterPattern(strings.TrimSpace(value.Value)); err != nil {
+				problems = append(problems, Problem{Field: "frontmatter." + ChapterPatternKey, Code: "invalid_chapter_pattern", Message: err.Error(), Line: line})
+			}
+		case key.Value == NumberChaptersKey:
+			if value.Kind != yaml.ScalarNode || (value.Tag != "!!bool" && value.Tag != "!!null") {
+				problems = append(problems, Problem{Field: "frontmatter." + NumberChaptersKey, Code: "invalid_chapter_numbering", Message: "numberChapters must be true or false", Line: line})
+			}
+		case containsString(frontmatterStringKeys, key.Value):
+			if value.Kind != yaml.ScalarNode || (value.Tag != "!!str" && value.Tag != "!!null") {
+				problems = append(problems, Problem{Field: "frontmatter." + key.Value, Code: "type_mismatch", Message: key.Value + " must be a string", Line: line})
+			}
+		}
+	}
+	return problems
+}
+
+func containsString(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckFrontmatter returns the frontmatter problems Ingest would reject
+// markdown for, without needing the rest of the story input.
+func CheckFrontmatter(markdown string) []Problem {
+	_, _, err := splitFrontmatter(markdown)
+	var inputErr *InputError
+	if errors.As(err, &inputErr) {
+		return inputErr.Problems
+	}
+	return nil
+}
+
+// UnsupportedConstructs lists Markdown that Ingest accepts but the renderer
+// drops, such as raw HTML. HTML comments are editorial notes and are not
+// reported.
+func UnsupportedConstructs(markdown string) []Problem {
+	body, bodyLine := markdown, 1
+	if _, split, _, line, err := cutFrontmatter(markdown); err == nil {
+		body, bodyLine = split, line
+	}
+	src := []byte(body)
+	doc := goldmark.New().Parser().Parse(text.NewReader(src))
+
+	problems := []Problem{}
+	report := func(start int, raw []byte) {
+		if bytes.HasPrefix(bytes.TrimSpace(raw), []byte("<!--")) {
+			return
+		}
+		line := bodyLine + bytes.Count(src[:start], []byte("\n"))
+		// Opening and closing tags on one line are a single finding.
+		if len(problems) > 0 && problems[len(problems)-1].Line == line {
+			return
+		}
+		problems = append(problems, Problem{
+			Field:   "markdown",
+			Code:    "unsupported",
+			Message: "Raw HTML is not rendered",
+			Line:    line,
+		})
+	}
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch x := n.(type) {
+		case *ast.HTMLBlock:
+			if x.Lines().Len() > 0 {
+				first := x.Lines().At(0)
+				report(first.Start, first.Value(src))
+			}
+			return ast.WalkSkipChildren, nil
+		case *ast.RawHTML:
+			if x.Segments.Len() > 0 {
+				first := x.Segments.At(0)
+				report(first.Start, first.Value(src))
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+	return problems
+}
+
+// frontmatterStart returns the Markdown line holding the opening delimiter,
+// counting the blank lines cutFrontmatter trims before it.
+func frontmatterStart(md, trimmed string) int {
+	return strings.Count(md[:len(md)-len(trimmed)], "\n") + 1
+}
@@ -0,0 +1,112 @@
+package storyingest
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// A pronunciation hint is [word](say: respelling), optionally followed by
+// "; ipa: phonemes". The link-like form degrades to readable text in plain
+// Markdown viewers.
+var pronunciationRe = regexp.MustCompile(`^\[([^\[\]\n]{1,80})\]\(say:[ \t]*([^;()\n]{1,80}?)(?:;[ \t]*ipa:[ \t]*([^;()\n]{1,80}?))?[ \t]*\)`)
+
+// Pronunciation records how to say a word, for readers and TTS engines.
+// Respelling is a reader-friendly spelling such as her-MY-oh-nee; IPA is
+// optional.
+type Pronunciation struct {
+	Text       string
+	Respelling string
+	IPA        string
+}
+
+// PronunciationHint is an inline node whose children are the hinted word.
+type PronunciationHint struct {
+	ast.BaseInline
+	Respelling string
+	IPA        string
+}
+
+// KindPronunciationHint is the NodeKind of PronunciationHint.
+var KindPronunciationHint = ast.NewNodeKind("PronunciationHint")
+
+func (n *PronunciationHint) Kind() ast.NodeKind {
+	return KindPronunciationHint
+}
+
+func (n *PronunciationHint) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"Respelling": n.Respelling, "IPA": n.IPA}, nil)
+}
+
+type pronunciationParser struct{}
+
+func (pronunciationParser) Trigger() []byte {
+	return []byte{'['}
+}
+
+// Parse claims [word](say: …) before the link parser sees the bracket. Any
+// other bracket is left for links.
+func (pronunciationParser) Parse(_ ast.Node, block text.Reader, _ parser.Context) ast.Node {
+	line, segment := block.PeekLine()
+	match := pronunciationRe.FindSubmatchIndex(line)
+	if match == nil {
+		return nil
+	}
+	word := strings.TrimSpace(string(line[match[2]:match[3]]))
+	respelling := strings.TrimSpace(string(line[match[4]:match[5]]))
+	if word == "" || respelling == "" {
+		return nil
+	}
+	node := &PronunciationHint{Respelling: respelling}
+	if match[6] >= 0 {
+		node.IPA = strings.TrimSpace(string(line[match[6]:match[7]]))
+	}
+	node.AppendChild(node, ast.NewTextSegment(text.NewSegment(segment.Start+match[2], segment.Start+match[3])))
+	block.Advance(match[1])
+	return node
+}
+
+type pronunciationRenderer struct{}
+
+func (pronunciationRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(KindPronunciationHint, func(w util.BufWriter, _ []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			_, _ = w.WriteString("</span>")
+			return ast.WalkContinue, nil
+		}
+		hint := n.(*PronunciationHint)
+		_, _ = w.WriteString(`<span class="pronunciation" tabindex="0" data-say="` + string(util.EscapeHTML([]byte(hint.Respelling))) + `"`)
+		if hint.IPA != "" {
+			_, _ = w.WriteString(` data-ipa="` + string(util.EscapeHTML([]byte(hint.IPA))) + `"`)
+		}
+		_, _ = w.WriteString(">")
+		return ast.WalkContinue, nil
+	})
+}
+
+// pronunciationsIn lists the hints inside a block in document order.
+func pronunciationsIn(src []byte, n ast.Node) []Pronunciation {
+	var hints []Pronunciation
+	_ = ast.Walk(n, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if hint, ok := node.(*PronunciationHint); ok && entering {
+			hints = append(hints, Pronunciation{Text: textContent(src, hint), Respelling: hint.Respelling, IPA: hint.IPA})
+			return ast.WalkSkipChildren, nil
+		}
+		return ast.WalkContinue, nil
+	})
+	return hints
+}
+
+type pronunciation struct{}
+
+func (pronunciation) Extend(m goldmark.Markdown) {
+	// The link parser runs at priority 200; lower values run first.
+	m.Parser().AddOptions(parser.WithInlineParsers(util.Prioritized(pronunciationParser{}, 150)))
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(util.Prioritized(pronunciationRenderer{}, 500)))
+}
@@ -0,0 +1,41 @@
+package storyingest
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestIngestRecordsPronunciationHints(t *testing.T) {
+	markdown := "[Hermione](say: her-MY-oh-nee) met [Siobhan](say: shiv-AWN; ipa: ʃɪˈvɔːn) by the [lake](https://example.com).\n"
+	out, err := Ingest(Input{Slug: "names", Title: "Names", Markdown: markdown, MarkdownExtensions: []string{ExtensionPronunciation}})
+	if err != nil {
+		t.Fatalf("Ingest returned error: %v", err)
+	}
+	segment := out.Segments[0]
+	want := `<p><span class="pronunciation" tabindex="0" data-say="her-MY-oh-nee">Hermione</span> met ` +
+		`<span class="pronunciation" tabindex="0" data-say="shiv-AWN" data-ipa="ʃɪˈvɔːn">Siobhan</span> by the ` +
+		`<a href="https://example.com">lake</a>.</p>` + "\n"
+	if segment.RenderedHTML != want {
+		t.Fatalf("rendered HTML = %q", segment.RenderedHTML)
+	}
+	hints := []Pronunciation{
+		{Text: "Hermione", Respelling: "her-MY-oh-nee"},
+		{Text: "Siobhan", Respelling: "shiv-AWN", IPA: "ʃɪˈvɔːn"},
+	}
+	if !reflect.DeepEqual(segment.Pronunciations, hints) {
+		t.Fatalf("pronunciations = %#v", segment.Pronunciations)
+	}
+	if segment.WordCount != 6 {
+		t.Fatalf("word count = %d, want 6", segment.WordCount)
+	}
+}
+
+func TestPronunciationHintsAreOptIn(t *testing.T) {
+	out, err := Ingest(Input{Slug: "names", Title: "Names", Markdown: "[Hermione](say: her-MY-oh-nee) waved.\n"})
+	if err != nil {
+		t.Fatalf("Ingest returned error: %v", err)
+	}
+	if len(out.Segments[0].Pronunciations) != 0 || out.Segments[0].RenderedHTML != "<p>[Hermione](say: her-MY-oh-nee) waved.</p>\n" {
+		t.Fatalf("segment = %#v", out.Segments[0])
+	}
+}
@@ -0,0 +1,103 @@
+package storyingest
+
+import (
+	"html"
+	"math"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"pandapages/api/internal/readercontract"
+)
+
+// Readability holds plain-text metrics of a story's body. The Flesch formulas
+// and the syllable heuristic are calibrated for English; for other languages
+// the counts stay meaningful but the scores are only indicative.
+type Readability struct {
+	Words               int
+	Sentences           int
+	Syllables           int
+	AvgWordsPerSentence float64
+	AvgSyllablesPerWord float64
+	AvgWordLength       float64
+	FleschReadingEase   float64
+	FleschKincaidGrade  float64
+}
+
+var (
+	readabilityTagRe  = regexp.MustCompile(`<[^>]*>`)
+	sentenceEndRe     = regexp.MustCompile(`[.!?]+(?:["'”’)\]]*)(?:\s|$)`)
+	vowelGroupRe      = regexp.MustCompile(`[aeiouy]+`)
+	silentTrailingERe = regexp.MustCompile(`[^aeiouy]e$`)
+)
+
+// renderedText returns the text a reader sees in rendered HTML, without the
+// soft hyphens hyphenation adds inside words.
+func renderedText(renderedHTML string) string {
+	text := html.UnescapeString(readabilityTagRe.ReplaceAllString(renderedHTML, " "))
+	return strings.ReplaceAll(text, SoftHyphen, "")
+}
+
+// MeasureReadability scores paragraph and other body segments. Headings are
+// excluded because titles are not sentences and would skew the averages, and
+// asides because children do not read them.
+func MeasureReadability(segments []Segment) Readability {
+	var r Readability
+	letters := 0
+	for _, segment := range segments {
+		if segment.Kind == readercontract.SegmentKindHeading || segment.Kind == readercontract.SegmentKindAside {
+			continue
+		}
+		text := renderedText(segment.RenderedHTML)
+		words := 0
+		for _, field := range strings.Fields(text) {
+			word := strings.TrimFunc(field, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+			if word == "" {
+				continue
+			}
+			words++
+			letters += len([]rune(word))
+			r.Syllables += countSyllables(word)
+		}
+		if words == 0 {
+			continue
+		}
+		r.Words += words
+		sentences := len(sentenceEndRe.FindAllStringIndex(strings.TrimSpace(text), -1))
+		if sentences == 0 {
+			// A segment without terminal punctuation still reads as one sentence.
+			sentences = 1
+		}
+		r.Sentences += sentences
+	}
+	if r.Words == 0 {
+		return r
+	}
+
+	wordsPerSentence := float64(r.Words) / float64(r.Sentences)
+	syllablesPerWord := float64(r.Syllables) / float64(r.Words)
+	r.AvgWordsPerSentence = round2(wordsPerSentence)
+	r.AvgSyllablesPerWord = round2(syllablesPerWord)
+	r.AvgWordLength = round2(float64(letters) / float64(r.Words))
+	r.FleschReadingEase = round2(206.835 - 1.015*wordsPerSentence - 84.6*syllablesPerWord)
+	r.FleschKincaidGrade = round2(0.39*wordsPerSentence + 11.8*syllablesPerWord - 15.59)
+	return r
+}
+
+// countSyllables approximates English syllables by vowel groups, discounting a
+// silent trailing "e". Every word has at least one syllable.
+func countSyllables(word string) int {
+	word = strings.ToLower(word)
+	count := len(vowelGroupRe.FindAllStringIndex(word, -1))
+	if count > 1 && silentTrailingERe.MatchString(word) && !strings.HasSuffix(word, "le") {
+		count--
+	}
+	if count < 1 {
+		count = 1
+	}
+	return count
+}
+
+func round2(value float64) float64 {
+	return math.Round(value*100) / 100
+}
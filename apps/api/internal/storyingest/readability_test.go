@@ -0,0 +1,37 @@
+package storyingest
+
+import "testing"
+
+func TestMeasureReadabilityScoresBodyText(t *testing.T) {
+	out, err := Ingest(Input{
+		Slug:     "readability",
+		Title:    "Readability",
+		Markdown: "# A Very Long Title That Is Ignored\n\nThe cat sat. The dog ran home!\n\nA little cake\n",
+	})
+	if err != nil {
+		t.Fatalf("Ingest: %v", err)
+	}
+
+	got := out.Readability
+	want := Readability{
+		Words:               10,
+		Sentences:           3,
+		Syllables:           11,
+		AvgWordsPerSentence: 3.33,
+		AvgSyllablesPerWord: 1.1,
+		AvgWordLength:       3.3,
+		FleschReadingEase:   110.39,
+		FleschKincaidGrade:  -1.31,
+	}
+	if got != want {
+		t.Fatalf("Readability = %#v, want %#v", got, want)
+	}
+}
+
+func TestCountSyllables(t *testing.T) {
+	for word, want := range map[string]int{"cat": 1, "cake": 1, "little": 2, "rhythm": 1, "banana": 3, "Hmm": 1} {
+		if got := countSyllables(word); got != want {
+			t.Errorf("countSyllables(%q) = %d, want %d", word, got, want)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package storyingest
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// renderWorkers bounds the goroutines converting one document's blocks.
+var renderWorkers = runtime.GOMAXPROCS(0)
+
+// blockRenders collects the blocks whose HTML depends only on their own
+// Markdown, so they can be converted concurrently once segmentation has fixed
+// the segment order. A goldmark.Markdown is safe for concurrent use and the
+// engine's extensions keep no per-document state, so every worker shares it.
+type blockRenders struct {
+	segments []int
+	blocks   []string
+}
+
+func (r *blockRenders) add(segment int, block string) {
+	r.segments = append(r.segments, segment)
+	r.blocks = append(r.blocks, block)
+}
+
+// render stores each block's HTML in its segment. Workers write distinct
+// elements of segs, so they need no lock.
+func (r *blockRenders) render(md engine, segs []Segment) {
+	workers := min(renderWorkers, len(r.blocks))
+	if workers <= 1 {
+		for index, block := range r.blocks {
+			segs[r.segments[index]].RenderedHTML, _ = md.render(block)
+		}
+		return
+	}
+
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Go(func() {
+			for {
+				index := int(next.Add(1) - 1)
+				if index >= len(r.blocks) {
+					return
+				}
+				segs[r.segments[index]].RenderedHTML, _ = md.render(r.blocks[index])
+			}
+		})
+	}
+	wg.Wait()
+}
@@ -0,0 +1,84 @@
+package storyingest
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// longBook exercises every block kind rendered after segmentation, along with
+// footnoted blocks that must still render from the document tree.
+func longBook(chapters int) Input {
+	var b strings.Builder
+	for chapter := 1; chapter <= chapters; chapter++ {
+		fmt.Fprintf(&b, "## Chapter %d\n\n", chapter)
+		for paragraph := 1; paragraph <= 20; paragraph++ {
+			fmt.Fprintf(&b, "Paragraph %d of chapter %d, with *emphasis* and \"quotes\".\n\n", paragraph, chapter)
+		}
+		fmt.Fprintf(&b, "A cited line.[^c%d]\n\n[^c%d]: Note for chapter %d.\n\n", chapter, chapter, chapter)
+		b.WriteString("| Name | Age |\n| --- | --- |\n| Panda | 4 |\n\n")
+		b.WriteString("```verse\nRoses are red\nPandas are black\n```\n\n")
+		b.WriteString("\\pagebreak\n\n* * *\n\n")
+	}
+	return Input{
+		Slug:     "long-book",
+		Title:    "Long Book",
+		Language: "en",
+		Markdown: b.String(),
+		MarkdownExtensions: []string{
+			ExtensionFootnotes, ExtensionGFM, ExtensionTypographer,
+			ExtensionVerse, ExtensionPageBreaks, ExtensionSceneBreaks,
+		},
+	}
+}
+
+func TestIngestRendersConcurrentlyLikeSequentially(t *testing.T) {
+	in := longBook(30)
+
+	previous := renderWorkers
+	t.Cleanup(func() { renderWorkers = previous })
+	renderWorkers = 1
+	sequential, err := Ingest(in)
+	if err != nil {
+		t.Fatalf("sequential Ingest: %v", err)
+	}
+	renderWorkers = 8
+	concurrent, err := Ingest(in)
+	if err != nil {
+		t.Fatalf("concurrent Ingest: %v", err)
+	}
+
+	if len(sequential.Segments) < 30*25 {
+		t.Fatalf("segments = %d, want every block of the book", len(sequential.Segments))
+	}
+	if !reflect.DeepEqual(sequential, concurrent) {
+		for index := range sequential.Segments {
+			if !reflect.DeepEqual(sequential.Segments[index], concurrent.Segments[index]) {
+				t.Fatalf("segment %d differs:\nsequential %#v\nconcurrent %#v", index, sequential.Segments[index], concurrent.Segments[index])
+			}
+		}
+		t.Fatal("concurrent output differs outside the segments")
+	}
+	for _, segment := range concurrent.Segments {
+		if strings.TrimSpace(segment.RenderedHTML) == "" {
+			t.Fatalf("segment %d was not rendered: %#v", segment.Ordinal, segment)
+		}
+	}
+}
+
+func BenchmarkIngestLongBook(b *testing.B) {
+	in := longBook(150)
+	for _, workers := range []int{1, max(renderWorkers, 4)} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			previous := renderWorkers
+			renderWorkers = workers
+			defer func() { renderWorkers = previous }()
+			for b.Loop() {
+				if _, err := Ingest(in); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
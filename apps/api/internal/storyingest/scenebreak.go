@@ -0,0 +1,90 @@
+package storyingest
+
+import (
+	"bytes"
+	"regexp"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// A scene break is a line holding only a separator: three or more of *, -
+// or _ (a Markdown thematic break, spaced or not), or a run of dashes, tildes,
+// bullets, asterisms, section signs or hashes such as — or # or ⁂.
+var sceneBreakRe = regexp.MustCompile(`^(?:(?:\*[ \t]*){3,}|(?:-[ \t]*){3,}|(?:_[ \t]*){3,}|(?:[—―~•·⁂§#][ \t]*)+)$`)
+
+// SceneBreak separates two scenes of a story. It carries no words but tells
+// the Reader to draw a divider and read-aloud to pause.
+type SceneBreak struct {
+	ast.BaseBlock
+}
+
+// KindSceneBreak is the NodeKind of SceneBreak.
+var KindSceneBreak = ast.NewNodeKind("SceneBreak")
+
+func (n *SceneBreak) Kind() ast.NodeKind {
+	return KindSceneBreak
+}
+
+func (n *SceneBreak) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, nil, nil)
+}
+
+type sceneBreakParser struct{}
+
+// Trigger lists the first bytes of every separator; 0xE2 starts the dashes,
+// bullet and asterism, 0xC2 the middle dot and section sign.
+func (sceneBreakParser) Trigger() []byte {
+	return []byte{'*', '-', '_', '~', '#', 0xE2, 0xC2}
+}
+
+// Open claims separator lines before the thematic break parser. A line of
+// dashes under a paragraph still underlines a setext heading, whose parser
+// runs first.
+func (sceneBreakParser) Open(_ ast.Node, reader text.Reader, _ parser.Context) (ast.Node, parser.State) {
+	line, segment := reader.PeekLine()
+	if !sceneBreakRe.Match(bytes.TrimSpace(line)) {
+		return nil, parser.NoChildren
+	}
+	node := &SceneBreak{}
+	segment = segment.TrimLeftSpace(reader.Source())
+	node.Lines().Append(segment.TrimRightSpace(reader.Source()))
+	reader.AdvanceToEOL()
+	return node, parser.NoChildren
+}
+
+func (sceneBreakParser) Continue(ast.Node, text.Reader, parser.Context) parser.State {
+	return parser.Close
+}
+
+func (sceneBreakParser) Close(ast.Node, text.Reader, parser.Context) {}
+
+func (sceneBreakParser) CanInterruptParagraph() bool {
+	return true
+}
+
+func (sceneBreakParser) CanAcceptIndentedLine() bool {
+	return false
+}
+
+type sceneBreakRenderer struct{}
+
+func (sceneBreakRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(KindSceneBreak, func(w util.BufWriter, _ []byte, _ ast.Node, entering bool) (ast.WalkStatus, error) {
+		if entering {
+			_, _ = w.WriteString("<hr class=\"scene-break\">\n")
+		}
+		return ast.WalkSkipChildren, nil
+	})
+}
+
+type sceneBreaks struct{}
+
+func (sceneBreaks) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(parser.WithBlockParsers(util.Prioritized(sceneBreakParser{}, 150)))
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(util.Prioritized(sceneBreakRenderer{}, 500)))
+}
@@ -0,0 +1,62 @@
+package storyingest
+
+import (
+	"strings"
+	"testing"
+
+	"pandapages/api/internal/readercontract"
+)
+
+func TestIngestNormalizesSceneBreaks(t *testing.T) {
+	markdown := strings.Join([]string{
+		"The owl left the barn.",
+		"",
+		"* * *",
+		"",
+		"Morning came.",
+		"***",
+		"Noon came.",
+		"",
+		"—",
+		"",
+		"Night fell.",
+		"",
+		"Setext heading",
+		"---",
+		"",
+		"The end.",
+		"",
+	}, "\n")
+	out, err := Ingest(Input{Slug: "owl", Title: "Owl", Markdown: markdown, MarkdownExtensions: []string{ExtensionSceneBreaks}})
+	if err != nil {
+		t.Fatalf("Ingest returned error: %v", err)
+	}
+	var kinds []string
+	for _, segment := range out.Segments {
+		kinds = append(kinds, string(segment.Kind))
+	}
+	want := "paragraph,scene-break,paragraph,scene-break,paragraph,scene-break,paragraph,heading,paragraph"
+	if got := strings.Join(kinds, ","); got != want {
+		t.Fatalf("segment kinds = %s", got)
+	}
+	first, second := out.Segments[1], out.Segments[3]
+	if first.Markdown != "* * *" || second.Markdown != "***" || first.ContentKey == second.ContentKey {
+		t.Fatalf("scene breaks = %q %q", first.Markdown, second.Markdown)
+	}
+	if first.Kind != readercontract.SegmentKindSceneBreak || first.WordCount != 0 || first.RenderedHTML != "<hr class=\"scene-break\">\n" {
+		t.Fatalf("scene break segment = %#v", first)
+	}
+	if strings.Count(out.RenderedHTML, "scene-break") != 3 {
+		t.Fatalf("rendered HTML = %s", out.RenderedHTML)
+	}
+
+	plain, err := Ingest(Input{Slug: "owl", Title: "Owl", Markdown: markdown})
+	if err != nil {
+		t.Fatalf("Ingest returned error: %v", err)
+	}
+	for _, segment := range plain.Segments {
+		if segment.Kind == readercontract.SegmentKindSceneBreak {
+			t.Fatal("scene breaks parsed without opting in")
+		}
+	}
+}
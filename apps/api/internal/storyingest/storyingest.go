@@ -1,7 +1,7 @@
 package storyingest
 
 import (
-	"bytes"
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
@@ -12,9 +12,8 @@ import (
 
 	"pandapages/api/internal/readercontract"
 
-	"github.com/yuin/goldmark"
 	"github.com/yuin/goldmark/ast"
-	"github.com/yuin/goldmark/parser"
+	east "github.com/yuin/goldmark/extension/ast"
 	"github.com/yuin/goldmark/text"
 	"go.yaml.in/yaml/v3"
 )
@@ -30,6 +29,21 @@ type Input struct {
 	Language  string
 	SourceURL string
 	Rights    map[string]any
+
+	// MarkdownExtensions opts the story into extended Markdown syntax; see
+	// SupportedExtensions. When empty, frontmatter markdownExtensions applies.
+	MarkdownExtensions []string
+
+	// ChapterLevel is the heading level that opens a chapter, and
+	// ChapterPattern an optional regular expression its text must match.
+	// When unset, frontmatter chapterLevel and chapterPattern apply.
+	ChapterLevel   int
+	ChapterPattern string
+
+	// NumberChapters prefixes unnumbered chapter headings with their number,
+	// as in "Chapter 3 — The Dark Forest". When unset, frontmatter
+	// numberChapters applies.
+	NumberChapters bool
 }
 
 type Segment struct {
@@ -43,6 +57,16 @@ type Segment struct {
 	Markdown          string
 	RenderedHTML      string
 	WordCount         int
+
+	// Speaker names who says a dialogue paragraph; empty otherwise.
+	Speaker string
+
+	// Pronunciations lists the pronunciation hints in the segment.
+	Pronunciations []Pronunciation
+
+	// Hash covers everything stored for the segment, so an unchanged segment
+	// keeps its hash across versions even when its ordinal moves.
+	Hash string
 }
 
 type Output struct {
@@ -58,7 +82,16 @@ type Output struct {
 	RenderedHTML string
 	ContentHash  string
 
-	Segments []Segment
+	Segments    []Segment
+	Readability Readability
+	Vocabulary  Vocabulary
+
+	MarkdownExtensions []string
+
+	// MediaIDs lists the uploaded media the body references; MediaURLs every
+	// distinct image source as rendered. Both are in document order.
+	MediaIDs  []string
+	MediaURLs []string
 }
 
 func ValidateSlug(slug string) error {
@@ -72,14 +105,40 @@ const maxFrontmatterBytes = 64 << 10 // 64 KiB
 
 // Parse optional YAML frontmatter --- ... ---.
 func splitFrontmatter(md string) (fm map[string]any, body string, err error) {
+	fmText, body, firstLine, _, err := cutFrontmatter(md)
+	if err != nil {
+		return nil, "", err
+	}
+	if firstLine == 0 {
+		return map[string]any{}, md, nil
+	}
+
+	out := map[string]any{}
+	if err := yaml.Unmarshal([]byte(fmText), &out); err != nil {
+		line := yamlErrorLine(err)
+		if line > 0 {
+			line += firstLine
+		}
+		return nil, "", inputError("frontmatter", "invalid_yaml", line, fmt.Sprintf("invalid YAML frontmatter: %v", err))
+	}
+	if problems := frontmatterTypeProblems(fmText, firstLine); len(problems) > 0 {
+		return nil, "", &InputError{Problems: problems}
+	}
+	return out, body, nil
+}
+
+// cutFrontmatter separates the frontmatter text from the body without
+// decoding it. firstLine is zero when the Markdown has no frontmatter.
+func cutFrontmatter(md string) (fmText, body string, firstLine, bodyLine int, err error) {
 	s := strings.TrimLeft(md, "\ufeff \t\r\n")
 	if !strings.HasPrefix(s, "---\n") && !strings.HasPrefix(s, "---\r\n") {
-		return map[string]any{}, md, nil
+		return "", md, 0, 1, nil
 	}
+	firstLine = frontmatterStart(md, s)
 
 	lines := strings.Split(s, "\n")
 	if len(lines) < 3 {
-		return nil, "", fmt.Errorf("frontmatter is not closed")
+		return "", "", 0, 0, inputError("frontmatter", "unclosed", firstLine, "frontmatter is not closed")
 	}
 	end := -1
 	for i := 1; i < len(lines); i++ {
@@ -89,31 +148,14 @@ func splitFrontmatter(md string) (fm map[string]any, body string, err error) {
 		}
 	}
 	if end == -1 {
-		return nil, "", fmt.Errorf("frontmatter is not closed")
+		return "", "", 0, 0, inputError("frontmatter", "unclosed", firstLine, "frontmatter is not closed")
 	}
 
-	fmText := strings.Join(lines[1:end], "\n")
+	fmText = strings.Join(lines[1:end], "\n")
 	if len(fmText) > maxFrontmatterBytes {
-		return nil, "", fmt.Errorf("frontmatter exceeds %d bytes", maxFrontmatterBytes)
-	}
-	body = strings.Join(lines[end+1:], "\n")
-
-	out := map[string]any{}
-	if err := yaml.Unmarshal([]byte(fmText), &out); err != nil {
-		return nil, "", fmt.Errorf("invalid YAML frontmatter: %w", err)
+		return "", "", 0, 0, inputError("frontmatter", "too_large", firstLine, fmt.Sprintf("frontmatter exceeds %d bytes", maxFrontmatterBytes))
 	}
-	return out, body, nil
-}
-
-func render(md string) (string, error) {
-	mdr := goldmark.New(
-		goldmark.WithParserOptions(parser.WithAutoHeadingID()),
-	)
-	var buf bytes.Buffer
-	if err := mdr.Convert([]byte(md), &buf); err != nil {
-		return "", err
-	}
-	return buf.String(), nil
+	return fmText, strings.Join(lines[end+1:], "\n"), firstLine, firstLine + end + 1, nil
 }
 
 func wordCount(s string) int {
@@ -158,9 +200,13 @@ func textContent(src []byte, n ast.Node) string {
 	var walk func(ast.Node)
 	walk = func(x ast.Node) {
 		for c := x.FirstChild(); c != nil; c = c.NextSibling() {
-			if t, ok := c.(*ast.Text); ok {
+			switch t := c.(type) {
+			case *ast.Text:
 				seg := t.Segment
 				b.Write(src[seg.Start:seg.Stop])
+			case *ast.String:
+				// Typographer substitutions carry no source segment.
+				b.WriteString(typographerSources[string(t.Value)])
 			}
 			walk(c)
 		}
@@ -171,26 +217,32 @@ func textContent(src []byte, n ast.Node) string {
 
 func validateUTF8(in Input) error {
 	values := []struct {
+		key   string
 		name  string
 		value string
 	}{
-		{name: "slug", value: in.Slug},
-		{name: "title", value: in.Title},
-		{name: "author", value: in.Author},
-		{name: "markdown", value: in.Markdown},
-		{name: "language", value: in.Language},
-		{name: "source URL", value: in.SourceURL},
+		{key: "slug", name: "slug", value: in.Slug},
+		{key: "title", name: "title", value: in.Title},
+		{key: "author", name: "author", value: in.Author},
+		{key: "markdown", name: "markdown", value: in.Markdown},
+		{key: "language", name: "language", value: in.Language},
+		{key: "sourceUrl", name: "source URL", value: in.SourceURL},
 	}
 	for _, field := range values {
 		if !utf8.ValidString(field.value) {
-			return fmt.Errorf("%s is not valid UTF-8", field.name)
+			return inputError(field.key, "invalid_encoding", 0, field.name+" is not valid UTF-8")
 		}
 	}
 	return nil
 }
 
 func Ingest(in Input) (Output, error) {
-	return ingest(in, false, nil)
+	return ingest(context.Background(), in, false, nil)
+}
+
+// IngestContext is Ingest traced as phases under ctx's span.
+func IngestContext(ctx context.Context, in Input) (Output, error) {
+	return ingest(ctx, in, false, nil)
 }
 
 // CanonicalizeStoredBody applies the same rendering, segmentation, and Reader
@@ -198,10 +250,13 @@ func Ingest(in Input) (Output, error) {
 // has already been removed. Stored bodies must not be reparsed for frontmatter:
 // a legitimate body can itself begin with a thematic break.
 func CanonicalizeStoredBody(in Input, frontmatter map[string]any) (Output, error) {
-	return ingest(in, true, frontmatter)
+	return ingest(context.Background(), in, true, frontmatter)
 }
 
-func ingest(in Input, bodyAlreadySplit bool, presetFrontmatter map[string]any) (Output, error) {
+func ingest(ctx context.Context, in Input, bodyAlreadySplit bool, presetFrontmatter map[string]any) (Output, error) {
+	phase := newPhases(ctx)
+	defer phase.end()
+
 	if err := validateUTF8(in); err != nil {
 		return Output{}, err
 	}
@@ -211,18 +266,19 @@ func ingest(in Input, bodyAlreadySplit bool, presetFrontmatter map[string]any) (
 	in.Author = strings.TrimSpace(in.Author)
 
 	if in.Title == "" {
-		return Output{}, fmt.Errorf("title is required")
+		return Output{}, inputError("title", "required", 0, "title is required")
 	}
 	if in.Slug == "" {
-		return Output{}, fmt.Errorf("slug is required")
+		return Output{}, inputError("slug", "required", 0, "slug is required")
 	}
 	if err := ValidateSlug(in.Slug); err != nil {
-		return Output{}, err
+		return Output{}, inputError("slug", "invalid", 0, err.Error())
 	}
 	if strings.TrimSpace(in.Markdown) == "" {
-		return Output{}, fmt.Errorf("markdown is required")
+		return Output{}, inputError("markdown", "required", 0, "markdown is required")
 	}
 
+	phase.start("frontmatter")
 	fm := map[string]any{}
 	body := in.Markdown
 	if bodyAlreadySplit {
@@ -251,14 +307,25 @@ func ingest(in Input, bodyAlreadySplit bool, presetFrontmatter map[string]any) (
 		in.SourceURL = strings.TrimSpace(v)
 	}
 
+	// An undeclared language is detected, and the confidence kept so later
+	// canonicalisation of the stored version reproduces it.
+	phase.start("language")
+	var detectedConfidence *float64
 	if in.Language == "" {
-		in.Language = "en-GB"
+		in.Language = DefaultLanguage
+		if language, confidence, ok := DetectLanguage(body); ok {
+			in.Language, detectedConfidence = language, &confidence
+		}
+	} else if confidence, ok := languageConfidence(fm[LanguageConfidenceKey]); ok && fm["language"] == in.Language {
+		detectedConfidence = &confidence
 	}
+	phase.end()
+
 	if len(in.Rights) == 0 {
 		if rawRights, exists := fm["rights"]; exists {
 			rights, ok := rawRights.(map[string]any)
 			if !ok {
-				return Output{}, fmt.Errorf("rights must be an object")
+				return Output{}, inputError("frontmatter.rights", "type_mismatch", 0, "rights must be an object")
 			}
 			in.Rights = make(map[string]any, len(rights))
 			for key, value := range rights {
@@ -270,71 +337,223 @@ func ingest(in Input, bodyAlreadySplit bool, presetFrontmatter map[string]any) (
 		in.Rights = map[string]any{}
 	}
 
-	// full render
-	fullHTML, err := render(body)
+	extensionsField := "markdownExtensions"
+	rawExtensions := any(in.MarkdownExtensions)
+	if len(in.MarkdownExtensions) == 0 {
+		extensionsField = "frontmatter.markdownExtensions"
+		rawExtensions = fm[MarkdownExtensionsKey]
+	}
+	extensions, ok := normalizeExtensions(rawExtensions)
+	if !ok {
+		return Output{}, inputError(extensionsField, "unsupported_extension", 0,
+			"markdownExtensions must list supported extensions: "+strings.Join(SupportedExtensions(), ", "))
+	}
+	chapters, chapterLevel, chapterPattern, err := resolveChapterRule(in, fm)
+	if err != nil {
+		return Output{}, err
+	}
+	numberChapters, err := resolveChapterNumbering(in, fm, chapterPattern)
 	if err != nil {
 		return Output{}, err
 	}
+	phase.start("parse")
+	md := newEngine(extensions, in.Language)
 
 	sum := sha256.Sum256([]byte(body))
 	hash := hex.EncodeToString(sum[:])
 
 	// AST segmentation (blocks)
-	mdr := goldmark.New()
-	reader := text.NewReader([]byte(body))
-	doc := mdr.Parser().Parse(reader)
-
 	src := []byte(body)
+	doc := md.parse(src)
+	media := collectMedia(doc)
+	if len(media.Malformed) > 0 {
+		return Output{}, inputError("markdown", "invalid_media", 0, fmt.Sprintf("image %q is not a valid media reference", media.Malformed[0]))
+	}
+	// The full rendering reuses the segmentation tree rather than parsing
+	// the document a second time; rendering does not modify the tree.
+	phase.start("render")
+	fullHTML, err := md.renderNode(src, doc)
+	if err != nil {
+		return Output{}, err
+	}
+	phase.start("segment")
+	numbering := numberChapters && !chaptersNumbered(src, doc, chapters)
+	chapterNumber := 0
+	notes := newChapterFootnotes(doc)
+	// Blocks citing footnotes render from the document tree so their links
+	// keep document-wide numbers.
+	renderBlock := func(n ast.Node, block string) string {
+		if notes.refer(n) {
+			h, _ := md.renderNode(src, n)
+			return h
+		}
+		h, _ := md.render(block)
+		return h
+	}
+	flushFootnotes := func(segs []Segment, ordinal int) ([]Segment, int) {
+		if segment, ok := notes.flush(md, src); ok {
+			segment.Ordinal = ordinal
+			return append(segs, segment), ordinal + 1
+		}
+		return segs, ordinal
+	}
+
 	segs := make([]Segment, 0, 64)
+	// Standalone blocks are converted after the walk, concurrently; only
+	// the segment just appended can be rendered later.
+	var pending blockRenders
+	renderLater := func(n ast.Node, block string) {
+		if n != nil && notes.refer(n) {
+			segs[len(segs)-1].RenderedHTML, _ = md.renderNode(src, n)
+			return
+		}
+		pending.add(len(segs)-1, block)
+	}
 	ordinal := 1
 	for n := doc.FirstChild(); n != nil; n = n.NextSibling() {
+		before := len(segs)
 		switch x := n.(type) {
+		case *east.FootnoteList:
+			continue
+
+		case *PageBreak:
+			block := extractBlockSource(src, x)
+
+			segs = append(segs, Segment{
+				Ordinal: ordinal, Kind: readercontract.SegmentKindPageBreak,
+				Markdown: block,
+			})
+			renderLater(nil, block)
+			ordinal++
+
+		case *SceneBreak:
+			block := extractBlockSource(src, x)
+
+			segs = append(segs, Segment{
+				Ordinal: ordinal, Kind: readercontract.SegmentKindSceneBreak,
+				Markdown: block,
+			})
+			renderLater(nil, block)
+			ordinal++
+
 		case *ast.Heading:
-			txt := textContent(src, x)
-			if txt == "" {
-				txt = extractBlockSource(src, x)
-			}
+			txt := headingSourceText(src, x)
 			level := x.Level
-			md := strings.Repeat("#", level) + " " + txt
-			h, _ := render(md)
+			block := strings.Repeat("#", level) + " " + txt
+			if chapters.Opens(readercontract.SegmentKindHeading, level, block) {
+				segs, ordinal = flushFootnotes(segs, ordinal)
+				if numbering {
+					// Section titles come from the heading, so the number
+					// reaches both.
+					chapterNumber++
+					txt = numberedChapterTitle(chapterNumber, txt, in.Language)
+					block = strings.Repeat("#", level) + " " + txt
+				}
+			}
+			notes.refer(x)
 			headingLevel := level
 
 			segs = append(segs, Segment{
 				Ordinal: ordinal, Kind: readercontract.SegmentKindHeading, HeadingLevel: &headingLevel,
-				Markdown: md, RenderedHTML: h, WordCount: wordCount(txt),
+				Markdown: block, WordCount: wordCount(txt),
 			})
+			renderLater(nil, block)
 			ordinal++
 
 		case *ast.Paragraph:
-			md := extractBlockSource(src, x)
-			if md == "" {
-				md = textContent(src, x)
+			block := extractBlockSource(src, x)
+			if block == "" {
+				block = textContent(src, x)
 			}
-			h, _ := render(md)
 
 			segs = append(segs, Segment{
 				Ordinal: ordinal, Kind: readercontract.SegmentKindParagraph,
-				Markdown: md, RenderedHTML: h, WordCount: wordCount(md),
+				Markdown: block, WordCount: wordCount(block),
 			})
+			renderLater(x, block)
+			ordinal++
+
+		case *Verse:
+			content := x.content(src)
+			if strings.TrimSpace(content) == "" {
+				continue
+			}
+			block := verseSource(content)
+
+			segs = append(segs, Segment{
+				Ordinal: ordinal, Kind: readercontract.SegmentKindVerse,
+				Markdown: block, WordCount: wordCount(content),
+			})
+			renderLater(nil, block)
+			ordinal++
+
+		case *Aside:
+			block := x.markdown(src)
+			h := renderBlock(x, block)
+			// The text of an aside spans paragraphs, so count it from the
+			// rendering, where block tags separate them.
+			words := wordCount(renderedText(h))
+			if words == 0 {
+				continue
+			}
+
+			segs = append(segs, Segment{
+				Ordinal: ordinal, Kind: readercontract.SegmentKindAside,
+				Markdown: block, RenderedHTML: h, WordCount: words,
+			})
+			ordinal++
+
+		case *Dialogue:
+			block := extractBlockSource(src, x)
+
+			segs = append(segs, Segment{
+				Ordinal: ordinal, Kind: readercontract.SegmentKindParagraph, Speaker: x.Speaker,
+				Markdown: block, WordCount: wordCount(textContent(src, x)),
+			})
+			renderLater(x, block)
+			ordinal++
+
+		case *east.Table:
+			// Tables have no Lines of their own; keep the full source rows.
+			block := tableSource(src, x)
+			if block == "" {
+				continue
+			}
+
+			segs = append(segs, Segment{
+				Ordinal: ordinal, Kind: readercontract.SegmentKindOther,
+				Markdown: block, WordCount: wordCount(tableText(src, x)),
+			})
+			renderLater(x, block)
 			ordinal++
 
 		default:
 			// fallback: try to preserve original block text if possible
-			md := extractBlockSource(src, n)
-			if strings.TrimSpace(md) == "" {
+			block := extractBlockSource(src, n)
+			if strings.TrimSpace(block) == "" {
 				continue
 			}
-			h, _ := render(md)
 
 			segs = append(segs, Segment{
 				Ordinal: ordinal, Kind: readercontract.SegmentKindOther,
-				Markdown: md, RenderedHTML: h, WordCount: wordCount(md),
+				Markdown: block, WordCount: wordCount(block),
 			})
+			renderLater(n, block)
 			ordinal++
 		}
+		if hints := pronunciationsIn(src, n); len(hints) > 0 && len(segs) > before {
+			segment := &segs[len(segs)-1]
+			segment.Pronunciations = hints
+			if segment.Kind == readercontract.SegmentKindParagraph {
+				// Respellings are not words of the story.
+				segment.WordCount = wordCount(textContent(src, n))
+			}
+		}
 	}
+	segs, _ = flushFootnotes(segs, ordinal)
+	pending.render(md, segs)
 	if len(segs) == 0 {
-		return Output{}, fmt.Errorf("story must contain at least one readable segment")
+		return Output{}, inputError("markdown", "no_readable_content", 0, "story must contain at least one readable segment")
 	}
 	hasReadableSegment := false
 	for _, segment := range segs {
@@ -344,9 +563,10 @@ func ingest(in Input, bodyAlreadySplit bool, presetFrontmatter map[string]any) (
 		}
 	}
 	if !hasReadableSegment {
-		return Output{}, fmt.Errorf("story must contain at least one readable segment")
+		return Output{}, inputError("markdown", "no_readable_content", 0, "story must contain at least one readable segment")
 	}
 
+	phase.start("identify")
 	identityInputs := make([]readercontract.SegmentIdentityInput, 0, len(segs))
 	for _, segment := range segs {
 		identityInputs = append(identityInputs, readercontract.SegmentIdentityInput{
@@ -355,7 +575,7 @@ func ingest(in Input, bodyAlreadySplit bool, presetFrontmatter map[string]any) (
 			Markdown:     segment.Markdown,
 		})
 	}
-	identities, err := readercontract.AssignSegmentIdentities(identityInputs)
+	identities, err := readercontract.AssignSegmentIdentities(identityInputs, chapters)
 	if err != nil {
 		return Output{}, err
 	}
@@ -364,8 +584,14 @@ func ingest(in Input, bodyAlreadySplit bool, presetFrontmatter map[string]any) (
 		segs[index].ContentOccurrence = identities[index].ContentOccurrence
 		segs[index].ChapterKey = identities[index].ChapterKey
 		segs[index].ChapterOccurrence = identities[index].ChapterOccurrence
+		segs[index].Hash = segmentHash(segs[index])
 	}
 
+	phase.start("measure")
+	readability := MeasureReadability(segs)
+	vocabulary := MeasureVocabulary(segs, in.Language)
+	phase.end()
+
 	source := map[string]any{}
 	if strings.TrimSpace(in.SourceURL) != "" {
 		source["url"] = strings.TrimSpace(in.SourceURL)
@@ -386,6 +612,21 @@ func ingest(in Input, bodyAlreadySplit bool, presetFrontmatter map[string]any) (
 		}
 		frontmatter["rights"] = rights
 	}
+	if len(extensions) > 0 {
+		frontmatter[MarkdownExtensionsKey] = extensions
+	}
+	if detectedConfidence != nil {
+		frontmatter[LanguageConfidenceKey] = *detectedConfidence
+	}
+	if chapterLevel != readercontract.DefaultChapterLevel {
+		frontmatter[ChapterLevelKey] = chapterLevel
+	}
+	if chapterPattern != "" {
+		frontmatter[ChapterPatternKey] = chapterPattern
+	}
+	if numberChapters {
+		frontmatter[NumberChaptersKey] = true
+	}
 
 	// merge fm → frontmatter (but keep explicit fields authoritative)
 	for k, v := range fm {
@@ -407,5 +648,29 @@ func ingest(in Input, bodyAlreadySplit bool, presetFrontmatter map[string]any) (
 		RenderedHTML: fullHTML,
 		ContentHash:  hash,
 		Segments:     segs,
+		Readability:  readability,
+		Vocabulary:   vocabulary,
+
+		MarkdownExtensions: extensions,
+		MediaIDs:           media.IDs,
+		MediaURLs:          media.URLs,
 	}, nil
 }
+
+// segmentHash hashes a segment's stored content. Each field is length
+// prefixed so different splits of the same bytes cannot collide.
+func segmentHash(segment Segment) string {
+	level := 0
+	if segment.HeadingLevel != nil {
+		level = *segment.HeadingLevel
+	}
+	hints := make([]string, 0, len(segment.Pronunciations))
+	for _, hint := range segment.Pronunciations {
+		hints = append(hints, fmt.Sprintf("%d:%s%d:%s%d:%s", len(hint.Text), hint.Text, len(hint.Respelling), hint.Respelling, len(hint.IPA), hint.IPA))
+	}
+	hash := sha256.New()
+	for _, field := range []string{string(segment.Kind), fmt.Sprint(level), segment.Speaker, segment.Markdown, segment.RenderedHTML, strings.Join(hints, "")} {
+		fmt.Fprintf(hash, "%d:%s", len(field), field)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
@@ -1,6 +1,7 @@
 package storyingest
 
 import (
+	"errors"
 	"strings"
 	"testing"
 	"unicode/utf8"
@@ -146,3 +147,37 @@ func TestCanonicalizeStoredBodyDoesNotReparseFrontmatter(t *testing.T) {
 		t.Fatalf("stored additive frontmatter was not preserved: %#v", out.Frontmatter)
 	}
 }
+
+func TestIngestLocatesFrontmatterProblems(t *testing.T) {
+	_, err := Ingest(Input{Slug: "typed", Title: "Typed", Markdown: "\n\n---\nlanguage: 42\n---\nStory"})
+	var inputErr *InputError
+	if !errors.As(err, &inputErr) || len(inputErr.Problems) != 1 {
+		t.Fatalf("Ingest error = %v", err)
+	}
+	problem := inputErr.Problems[0]
+	if problem.Field != "frontmatter.language" || problem.Code != "type_mismatch" || problem.Line != 4 {
+		t.Fatalf("problem = %#v", problem)
+	}
+
+	problems := UnsupportedConstructs("---\ntitle: Story\n---\nText <span>x</span>\n\n<!-- note -->\n")
+	if len(problems) != 1 || problems[0].Line != 4 {
+		t.Fatalf("unsupported constructs = %#v", problems)
+	}
+}
+
+func TestSegmentHashFollowsContentNotPosition(t *testing.T) {
+	first, err := Ingest(Input{Slug: "hash", Title: "Hash", Markdown: "Kept paragraph.\n\nOld ending.\n"})
+	if err != nil {
+		t.Fatalf("Ingest returned error: %v", err)
+	}
+	second, err := Ingest(Input{Slug: "hash", Title: "Hash", Markdown: "New opening.\n\nKept paragraph.\n\nOld ending!\n"})
+	if err != nil {
+		t.Fatalf("Ingest returned error: %v", err)
+	}
+	if len(first.Segments[0].Hash) != 64 || first.Segments[0].Hash != second.Segments[1].Hash {
+		t.Fatalf("moved segment hash changed: %q vs %q", first.Segments[0].Hash, second.Segments[1].Hash)
+	}
+	if first.Segments[1].Hash == second.Segments[2].Hash {
+		t.Fatal("edited segment kept its hash")
+	}
+}
@@ -0,0 +1,103 @@
+package storyingest
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// verseLanguage is the info string of a verse fence. Inside one, every line
+// break is kept and blank lines separate stanzas.
+const verseLanguage = "verse"
+
+// verseMarkdown renders verse content with each soft line break kept.
+var verseMarkdown = goldmark.New(goldmark.WithRendererOptions(html.WithHardWraps()))
+
+// Verse is a poem or rhyme whose line structure is part of its meaning.
+type Verse struct {
+	ast.BaseBlock
+}
+
+// KindVerse is the NodeKind of Verse.
+var KindVerse = ast.NewNodeKind("Verse")
+
+func (n *Verse) Kind() ast.NodeKind {
+	return KindVerse
+}
+
+func (n *Verse) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, nil, nil)
+}
+
+func (n *Verse) content(source []byte) string {
+	var b strings.Builder
+	for i := 0; i < n.Lines().Len(); i++ {
+		line := n.Lines().At(i)
+		b.Write(line.Value(source))
+	}
+	return b.String()
+}
+
+// verseSource returns the Markdown fence for verse content, with a fence long
+// enough that no content line can close it.
+func verseSource(content string) string {
+	fence := 3
+	for _, line := range strings.Split(content, "\n") {
+		run := len(line) - len(strings.TrimLeft(line, "`"))
+		if run >= fence {
+			fence = run + 1
+		}
+	}
+	marker := strings.Repeat("`", fence)
+	return marker + verseLanguage + "\n" + strings.TrimRight(content, "\n") + "\n" + marker
+}
+
+// verseTransformer replaces verse fences by Verse nodes.
+type verseTransformer struct{}
+
+func (verseTransformer) Transform(doc *ast.Document, reader text.Reader, _ parser.Context) {
+	src := reader.Source()
+	for n := doc.FirstChild(); n != nil; n = n.NextSibling() {
+		fence, ok := n.(*ast.FencedCodeBlock)
+		if !ok || !strings.EqualFold(string(fence.Language(src)), verseLanguage) {
+			continue
+		}
+		verse := &Verse{}
+		verse.SetLines(fence.Lines())
+		verse.SetBlankPreviousLines(fence.HasBlankPreviousLines())
+		doc.ReplaceChild(doc, fence, verse)
+		n = verse
+	}
+}
+
+type verseRenderer struct{}
+
+func (verseRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(KindVerse, func(w util.BufWriter, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkSkipChildren, nil
+		}
+		var buf bytes.Buffer
+		if err := verseMarkdown.Convert([]byte(n.(*Verse).content(source)), &buf); err != nil {
+			return ast.WalkStop, err
+		}
+		_, _ = w.WriteString("<div class=\"verse\">\n")
+		_, _ = w.Write(buf.Bytes())
+		_, _ = w.WriteString("</div>\n")
+		return ast.WalkSkipChildren, nil
+	})
+}
+
+type verse struct{}
+
+func (verse) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(parser.WithASTTransformers(util.Prioritized(verseTransformer{}, 900)))
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(util.Prioritized(verseRenderer{}, 500)))
+}
@@ -0,0 +1,54 @@
+package storyingest
+
+import (
+	"strings"
+	"testing"
+
+	"pandapages/api/internal/readercontract"
+)
+
+func TestIngestKeepsVerseLines(t *testing.T) {
+	markdown := strings.Join([]string{
+		"Before the rhyme.",
+		"",
+		"```verse",
+		"Twinkle, twinkle, *little* star,",
+		"How I wonder what you are!",
+		"",
+		"Up above the world so high,",
+		"```",
+		"",
+		"```go",
+		"code stays code",
+		"```",
+		"",
+	}, "\n")
+	out, err := Ingest(Input{Slug: "star", Title: "Star", Markdown: markdown, MarkdownExtensions: []string{ExtensionVerse}})
+	if err != nil {
+		t.Fatalf("Ingest returned error: %v", err)
+	}
+	if len(out.Segments) != 3 {
+		t.Fatalf("segments = %#v", out.Segments)
+	}
+	verse := out.Segments[1]
+	if verse.Kind != readercontract.SegmentKindVerse || verse.WordCount != 16 {
+		t.Fatalf("verse segment = %#v", verse)
+	}
+	want := "<div class=\"verse\">\n<p>Twinkle, twinkle, <em>little</em> star,<br>\nHow I wonder what you are!</p>\n<p>Up above the world so high,</p>\n</div>\n"
+	if verse.RenderedHTML != want {
+		t.Fatalf("verse HTML = %q", verse.RenderedHTML)
+	}
+	if !strings.HasPrefix(verse.Markdown, "```verse\n") || !strings.HasSuffix(verse.Markdown, "\n```") {
+		t.Fatalf("verse markdown = %q", verse.Markdown)
+	}
+	if code := out.Segments[2]; code.Kind != readercontract.SegmentKindOther || strings.Contains(code.RenderedHTML, "verse") {
+		t.Fatalf("code fence = %#v", code)
+	}
+}
+
+func TestVerseSourceOutrunsContentFences(t *testing.T) {
+	got := verseSource("a\n```\nb\n")
+	if got != "````verse\na\n```\nb\n````" {
+		t.Fatalf("verseSource = %q", got)
+	}
+}
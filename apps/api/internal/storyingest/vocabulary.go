@@ -0,0 +1,120 @@
+package storyingest
+
+import (
+	_ "embed"
+	"sort"
+	"strings"
+	"unicode"
+
+	"pandapages/api/internal/readercontract"
+)
+
+// Vocabulary holds the word counts of a story's body. Frequencies keeps the
+// most frequent words, lowercased; Rare lists words outside the bundled
+// common-words list, most frequent first. Rare is only computed for English,
+// the language of that list.
+type Vocabulary struct {
+	Words       int
+	Distinct    int
+	Frequencies map[string]int
+	Rare        []string
+}
+
+const (
+	// maxVocabularyEntries bounds the stored frequency map for long books.
+	maxVocabularyEntries = 5000
+	maxRareWords         = 50
+	minRareWordLength    = 4
+)
+
+//go:embed common_words_en.txt
+var commonWordsEN string
+
+var commonWords = func() map[string]bool {
+	words := map[string]bool{}
+	for _, line := range strings.Split(commonWordsEN, "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+			words[line] = true
+		}
+	}
+	return words
+}()
+
+var inflectionSuffixes = []string{"s", "es", "ed", "d", "ing", "ly", "er", "est", "'s"}
+
+// isCommonWord matches a word, or the word with a common inflection removed,
+// against the common-words list.
+func isCommonWord(word string) bool {
+	if commonWords[word] {
+		return true
+	}
+	for _, suffix := range inflectionSuffixes {
+		stem, ok := strings.CutSuffix(word, suffix)
+		if !ok {
+			continue
+		}
+		if commonWords[stem] || commonWords[stem+"e"] {
+			return true
+		}
+		// running -> run, bigger -> big
+		if len(stem) > 2 && stem[len(stem)-1] == stem[len(stem)-2] && commonWords[stem[:len(stem)-1]] {
+			return true
+		}
+	}
+	return false
+}
+
+// MeasureVocabulary counts the words of body segments. Headings and asides
+// are left out as readability does, so chapter titles and grown-up notes do
+// not inflate their words.
+func MeasureVocabulary(segments []Segment, language string) Vocabulary {
+	counts := map[string]int{}
+	total := 0
+	for _, segment := range segments {
+		if segment.Kind == readercontract.SegmentKindHeading || segment.Kind == readercontract.SegmentKindAside {
+			continue
+		}
+		text := renderedText(segment.RenderedHTML)
+		for _, field := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+			return !unicode.IsLetter(r) && r != '\'' && r != '’'
+		}) {
+			word := strings.Trim(strings.ReplaceAll(field, "’", "'"), "'")
+			if word == "" {
+				continue
+			}
+			counts[word]++
+			total++
+		}
+	}
+
+	words := make([]string, 0, len(counts))
+	for word := range counts {
+		words = append(words, word)
+	}
+	sort.Slice(words, func(i, j int) bool {
+		if counts[words[i]] != counts[words[j]] {
+			return counts[words[i]] > counts[words[j]]
+		}
+		return words[i] < words[j]
+	})
+
+	vocabulary := Vocabulary{Words: total, Distinct: len(words), Frequencies: map[string]int{}, Rare: []string{}}
+	for index, word := range words {
+		if index == maxVocabularyEntries {
+			break
+		}
+		vocabulary.Frequencies[word] = counts[word]
+	}
+	if !SameLanguage(language, "en") {
+		return vocabulary
+	}
+	for _, word := range words {
+		if len(vocabulary.Rare) == maxRareWords {
+			break
+		}
+		if len([]rune(word)) >= minRareWordLength && !isCommonWord(word) {
+			vocabulary.Rare = append(vocabulary.Rare, word)
+		}
+	}
+	return vocabulary
+}
@@ -0,0 +1,33 @@
+package storyingest
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestIngestRecordsVocabulary(t *testing.T) {
+	markdown := "# The Lantern\n\nThe lanterns glowed. The badger's lantern glowed brightest.\n"
+	out, err := Ingest(Input{Slug: "lantern", Title: "Lantern", Language: "en-GB", Markdown: markdown})
+	if err != nil {
+		t.Fatalf("Ingest returned error: %v", err)
+	}
+	vocabulary := out.Vocabulary
+	if vocabulary.Words != 8 || vocabulary.Distinct != 6 {
+		t.Fatalf("vocabulary counts = %#v", vocabulary)
+	}
+	if vocabulary.Frequencies["the"] != 2 || vocabulary.Frequencies["glowed"] != 2 || vocabulary.Frequencies["badger"] != 0 {
+		t.Fatalf("frequencies = %#v", vocabulary.Frequencies)
+	}
+	// Headings are not counted, and "brightest" inflects a common word.
+	if want := []string{"glowed", "badger's", "lantern", "lanterns"}; !reflect.DeepEqual(vocabulary.Rare, want) {
+		t.Fatalf("rare words = %#v, want %#v", vocabulary.Rare, want)
+	}
+}
+
+func TestMeasureVocabularySkipsRareWordsOutsideEnglish(t *testing.T) {
+	segments := []Segment{{Kind: "paragraph", RenderedHTML: "<p>Le renard dormait.</p>"}}
+	vocabulary := MeasureVocabulary(segments, "fr")
+	if vocabulary.Words != 3 || len(vocabulary.Rare) != 0 || vocabulary.Rare == nil {
+		t.Fatalf("vocabulary = %#v", vocabulary)
+	}
+}
@@ -0,0 +1,119 @@
+// Package storylint runs advisory structural checks over an ingested story.
+// Lint never rejects content: every finding is a warning the admin may ignore.
+package storylint
+
+import (
+	"html"
+	"regexp"
+	"strings"
+
+	"pandapages/api/internal/readercontract"
+	"pandapages/api/internal/storyingest"
+)
+
+const (
+	CodeMissingTitle     = "missing_title_heading"
+	CodeEmptyHeading     = "empty_heading"
+	CodeBrokenImage      = "broken_image"
+	CodeLongParagraph    = "long_paragraph"
+	CodeUnclosedEmphasis = "unclosed_emphasis"
+	CodeFrontMatter      = "front_matter"
+	CodeBackMatter       = "back_matter"
+	CodeLanguageMismatch = "language_mismatch"
+
+	// LongParagraphWords is roughly two screens of a phone Reader.
+	LongParagraphWords = 400
+
+	// languageMismatchConfidence is how sure detection must be before a
+	// declared language is questioned.
+	languageMismatchConfidence = 0.6
+)
+
+// Warning locates a finding by segment ordinal and content key so the admin UI
+// can point at the same segment identity the Reader uses. Story-level findings
+// have a zero Ordinal and an empty ContentKey.
+type Warning struct {
+	Code       string
+	Message    string
+	Ordinal    int
+	ContentKey string
+}
+
+var (
+	markdownImageRe  = regexp.MustCompile(`!\[`)
+	renderedImageRe  = regexp.MustCompile(`<img\s`)
+	emptyImageSrcRe  = regexp.MustCompile(`<img\s[^>]*src=""`)
+	codeBlockRe      = regexp.MustCompile(`(?s)<(code|pre)[^>]*>.*?</(code|pre)>`)
+	tagRe            = regexp.MustCompile(`<[^>]*>`)
+	strayEmphasisRe  = regexp.MustCompile(`(^|\s)[*_]{1,3}[^\s*_]|[^\s*_][*_]{1,3}($|[\s.,;:!?])`)
+	headingMarkersRe = regexp.MustCompile(`^#{1,6}\s*$`)
+
+	// Section titles that book editions carry but a Reader story rarely
+	// should. Transcriber's notes can sit at either end.
+	frontMatterRe = regexp.MustCompile(`(?i)^(?:preface|foreword|dedication|(?:list of )?illustrations|produced by\b.*|transcriber'?s? notes?)\.?$`)
+	backMatterRe  = regexp.MustCompile(`(?i)^(?:appendix\b.*|index|glossary|colophon|end ?notes|footnotes|transcriber'?s? notes?)\.?$`)
+)
+
+func Lint(out storyingest.Output) []Warning {
+	warnings := []Warning{}
+
+	hasTitle := false
+	for _, segment := range out.Segments {
+		if segment.Kind == readercontract.SegmentKindHeading && segment.HeadingLevel != nil && *segment.HeadingLevel == 1 {
+			hasTitle = true
+			break
+		}
+	}
+	if !hasTitle {
+		warnings = append(warnings, Warning{Code: CodeMissingTitle, Message: "Story has no level-one title heading"})
+	}
+
+	texts := make([]string, 0, len(out.Segments))
+	for _, segment := range out.Segments {
+		texts = append(texts, segment.Markdown)
+	}
+	if detected, confidence, ok := storyingest.DetectLanguage(strings.Join(texts, "\n")); ok &&
+		confidence >= languageMismatchConfidence && !storyingest.SameLanguage(detected, out.Language) {
+		warnings = append(warnings, Warning{Code: CodeLanguageMismatch, Message: "Text reads as " + detected + " but the story declares " + out.Language})
+	}
+
+	for index, segment := range out.Segments {
+		at := func(code, message string) {
+			warnings = append(warnings, Warning{Code: code, Message: message, Ordinal: segment.Ordinal, ContentKey: segment.ContentKey})
+		}
+
+		if segment.Kind == readercontract.SegmentKindHeading && headingMarkersRe.MatchString(strings.TrimSpace(segment.Markdown)) {
+			at(CodeEmptyHeading, "Heading has no text")
+		}
+		if segment.Kind == readercontract.SegmentKindHeading {
+			title := strings.TrimSpace(strings.TrimLeft(segment.Markdown, "#"))
+			switch {
+			case index < len(out.Segments)/2 && frontMatterRe.MatchString(title):
+				at(CodeFrontMatter, "Section looks like book front matter; remove it unless readers need it")
+			case index >= len(out.Segments)/2 && backMatterRe.MatchString(title):
+				at(CodeBackMatter, "Section looks like book back matter; remove it unless readers need it")
+			}
+		}
+		if segment.Kind == readercontract.SegmentKindParagraph && segment.WordCount > LongParagraphWords {
+			at(CodeLongParagraph, "Paragraph is very long; consider splitting it")
+		}
+
+		wantImages := len(markdownImageRe.FindAllStringIndex(segment.Markdown, -1))
+		gotImages := len(renderedImageRe.FindAllStringIndex(segment.RenderedHTML, -1))
+		if gotImages < wantImages || emptyImageSrcRe.MatchString(segment.RenderedHTML) {
+			at(CodeBrokenImage, "Image reference is missing or unresolved")
+		}
+
+		if segment.Kind != readercontract.SegmentKindOther && strayEmphasisRe.MatchString(visibleText(segment.RenderedHTML)) {
+			at(CodeUnclosedEmphasis, "Emphasis marker is not closed")
+		}
+	}
+	return warnings
+}
+
+// visibleText drops code, which may legitimately contain emphasis markers,
+// and every tag, leaving the literal characters a reader would see.
+func visibleText(rendered string) string {
+	rendered = codeBlockRe.ReplaceAllString(rendered, " ")
+	return html.UnescapeString(tagRe.ReplaceAllString(rendered, " "))
+}
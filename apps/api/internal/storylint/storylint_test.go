@@ -0,0 +1,79 @@
+package storylint
+
+import (
+	"strings"
+	"testing"
+
+	"pandapages/api/internal/storyingest"
+)
+
+func lintMarkdown(t *testing.T, markdown string) []Warning {
+	t.Helper()
+	out, err := storyingest.Ingest(storyingest.Input{Slug: "lint-story", Title: "Lint Story", Markdown: markdown})
+	if err != nil {
+		t.Fatalf("Ingest: %v", err)
+	}
+	return Lint(out)
+}
+
+func warningCodes(warnings []Warning) []string {
+	codes := make([]string, 0, len(warnings))
+	for _, warning := range warnings {
+		codes = append(codes, warning.Code)
+	}
+	return codes
+}
+
+func TestLintCleanStoryHasNoWarnings(t *testing.T) {
+	warnings := lintMarkdown(t, "# Title\n\n## Chapter\n\nA *quiet* **night** with `a*b` code and ![moon](moon.png).\n")
+	if len(warnings) != 0 {
+		t.Fatalf("warnings = %#v", warnings)
+	}
+}
+
+func TestLintReportsStructuralProblemsWithLocators(t *testing.T) {
+	long := strings.TrimSpace(strings.Repeat("word ", LongParagraphWords+1))
+	warnings := lintMarkdown(t, "## Chapter\n\n##\n\nAn *unclosed thought.\n\n![missing][nowhere]\n\n![empty]()\n\n"+long+"\n")
+
+	want := []string{CodeMissingTitle, CodeEmptyHeading, CodeUnclosedEmphasis, CodeBrokenImage, CodeBrokenImage, CodeLongParagraph}
+	if got := warningCodes(warnings); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("codes = %v, want %v", got, want)
+	}
+	if warnings[0].Ordinal != 0 || warnings[0].ContentKey != "" {
+		t.Fatalf("story-level warning has a locator: %#v", warnings[0])
+	}
+	for _, warning := range warnings[1:] {
+		if warning.Ordinal == 0 || len(warning.ContentKey) != 64 {
+			t.Fatalf("segment warning is missing its locator: %#v", warning)
+		}
+	}
+}
+
+func TestLintMarksFrontAndBackMatter(t *testing.T) {
+	warnings := lintMarkdown(t, strings.Join([]string{
+		"# Title", "## Preface", "Why I wrote it.", "## Chapter", "A story.",
+		"## Index", "Rabbits, 1.", "## Preface", "A late preface stays.",
+	}, "\n\n")+"\n")
+	want := []string{CodeFrontMatter, CodeBackMatter}
+	if got := warningCodes(warnings); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("codes = %v, want %v", got, want)
+	}
+	if warnings[0].Ordinal != 2 || warnings[1].Ordinal != 6 {
+		t.Fatalf("warnings = %#v", warnings)
+	}
+}
+
+func TestLintWarnsWhenTextDisagreesWithDeclaredLanguage(t *testing.T) {
+	text := "# Titre\n\nIl était une fois une petite souris qui vivait dans la maison du meunier. " +
+		"Elle ne sortait pas le jour, mais la nuit elle allait dans la cuisine pour chercher du pain et des miettes sur la table.\n"
+	out, err := storyingest.Ingest(storyingest.Input{Slug: "souris", Title: "Souris", Language: "en-GB", Markdown: text})
+	if err != nil {
+		t.Fatalf("Ingest: %v", err)
+	}
+	if got := warningCodes(Lint(out)); strings.Join(got, ",") != CodeLanguageMismatch {
+		t.Fatalf("codes = %v", got)
+	}
+	if got := lintMarkdown(t, text); len(got) != 0 {
+		t.Fatalf("detected language warned: %#v", got)
+	}
+}
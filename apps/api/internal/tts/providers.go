@@ -0,0 +1,105 @@
+package tts
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"strings"
+
+	"pandapages/api/internal/model"
+)
+
+const (
+	defaultOpenAIURL   = "https://api.openai.com/v1/audio/speech"
+	defaultOpenAIModel = "tts-1"
+	defaultOpenAIVoice = "alloy"
+	defaultAzureVoice  = "en-US-JennyNeural"
+	// azureOutputFormat is WAV, like every other provider's answer.
+	azureOutputFormat = "riff-24khz-16bit-mono-pcm"
+)
+
+// Piper is a local Piper HTTP server, which answers a JSON text with WAV. An
+// empty voice leaves the choice to the server.
+type Piper struct {
+	URL    string
+	Voice  string
+	client *http.Client
+}
+
+func (p *Piper) Name() string { return ProviderPiper }
+
+func (p *Piper) Synthesize(ctx context.Context, text, voice string) (model.NarrationAudio, error) {
+	body, err := json.Marshal(struct {
+		Text  string `json:"text"`
+		Voice string `json:"voice,omitempty"`
+	}{Text: text, Voice: cmp.Or(voice, p.Voice)})
+	if err != nil {
+		return model.NarrationAudio{}, err
+	}
+	return post(ctx, p.client, p.URL, "application/json", body, nil)
+}
+
+// OpenAI is OpenAI's speech endpoint, or any service that copies its API.
+type OpenAI struct {
+	URL    string
+	APIKey string
+	Model  string
+	Voice  string
+	client *http.Client
+}
+
+func (p *OpenAI) Name() string { return ProviderOpenAI }
+
+func (p *OpenAI) Synthesize(ctx context.Context, text, voice string) (model.NarrationAudio, error) {
+	body, err := json.Marshal(struct {
+		Model          string `json:"model"`
+		Input          string `json:"input"`
+		Voice          string `json:"voice"`
+		ResponseFormat string `json:"response_format"`
+	}{Model: p.Model, Input: text, Voice: cmp.Or(voice, p.Voice), ResponseFormat: "wav"})
+	if err != nil {
+		return model.NarrationAudio{}, err
+	}
+	return post(ctx, p.client, p.URL, "application/json", body, http.Header{
+		"Authorization": {"Bearer " + p.APIKey},
+	})
+}
+
+// Azure is Azure AI Speech, which reads SSML. The voice name, such as
+// en-GB-SoniaNeural, also gives the SSML language.
+type Azure struct {
+	URL    string
+	APIKey string
+	Voice  string
+	client *http.Client
+}
+
+func (p *Azure) Name() string { return ProviderAzure }
+
+func (p *Azure) Synthesize(ctx context.Context, text, voice string) (model.NarrationAudio, error) {
+	voice = cmp.Or(voice, p.Voice)
+	var ssml strings.Builder
+	ssml.WriteString(`<speak version="1.0" xmlns="http://www.w3.org/2001/10/synthesis" xml:lang="`)
+	_ = xml.EscapeText(&ssml, []byte(azureLanguage(voice)))
+	ssml.WriteString(`"><voice name="`)
+	_ = xml.EscapeText(&ssml, []byte(voice))
+	ssml.WriteString(`">`)
+	_ = xml.EscapeText(&ssml, []byte(text))
+	ssml.WriteString(`</voice></speak>`)
+	return post(ctx, p.client, p.URL, "application/ssml+xml", []byte(ssml.String()), http.Header{
+		"Ocp-Apim-Subscription-Key": {p.APIKey},
+		"X-Microsoft-Outputformat":  {azureOutputFormat},
+	})
+}
+
+// azureLanguage takes the locale from a voice name: en-US-JennyNeural is
+// en-US.
+func azureLanguage(voice string) string {
+	parts := strings.SplitN(voice, "-", 3)
+	if len(parts) < 3 {
+		return "en-US"
+	}
+	return parts[0] + "-" + parts[1]
+}
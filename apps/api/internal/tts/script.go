@@ -0,0 +1,30 @@
+package tts
+
+import (
+	"html"
+	"regexp"
+	"strings"
+
+	"pandapages/api/internal/model"
+	"pandapages/api/internal/storyingest"
+)
+
+var tagRe = regexp.MustCompile(`<[^>]*>`)
+
+// Script is what a provider reads for one segment: its rendered text without
+// markup or soft hyphens, with each pronunciation hint's word replaced by
+// its respelling. An empty script has nothing to say, as for an image.
+func Script(segment model.NarrationSegment) string {
+	text := html.UnescapeString(tagRe.ReplaceAllString(segment.RenderedHTML, " "))
+	text = strings.ReplaceAll(text, storyingest.SoftHyphen, "")
+	text = strings.Join(strings.Fields(text), " ")
+	for _, hint := range segment.Pronunciations {
+		if strings.TrimSpace(hint.Text) == "" || strings.TrimSpace(hint.Say) == "" {
+			continue
+		}
+		// The same boundaries as the sensitivity scan: whole words only.
+		pattern := regexp.MustCompile(`(^|[^\p{L}\p{N}])` + regexp.QuoteMeta(hint.Text) + `($|[^\p{L}\p{N}])`)
+		text = pattern.ReplaceAllString(text, "${1}"+strings.ReplaceAll(hint.Say, "$", "$$")+"${2}")
+	}
+	return text
+}
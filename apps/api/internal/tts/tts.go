@@ -0,0 +1,131 @@
+// Package tts narrates stories. A Provider turns one segment's script into
+// WAV audio, either from a local Piper server or a cloud service; a Worker
+// claims queued narration jobs and drives them a segment at a time, storing
+// each recording as it arrives, so a job interrupted by a restart resumes
+// after the last segment it saved.
+package tts
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"pandapages/api/internal/model"
+)
+
+const (
+	ProviderPiper  = "piper"
+	ProviderOpenAI = "openai"
+	ProviderAzure  = "azure"
+
+	// A long paragraph takes a cloud service several seconds; this bounds a
+	// provider that stops answering.
+	requestTimeout = 2 * time.Minute
+	// maxAudioBytes is about ten minutes of 24kHz 16-bit mono WAV, far more
+	// than one segment needs.
+	maxAudioBytes = 32 << 20
+)
+
+// ErrProvider marks a provider that answered with an error status or audio
+// that could not be used. Its message never carries the provider's response.
+var ErrProvider = errors.New("tts provider failed")
+
+type Provider interface {
+	// Name identifies the provider in logs and the startup summary.
+	Name() string
+	// Synthesize reads text aloud in voice, or in the provider's default
+	// voice when voice is empty, and returns WAV audio.
+	Synthesize(ctx context.Context, text, voice string) (model.NarrationAudio, error)
+}
+
+// Load builds the provider PP_TTS_PROVIDER names. It returns nil, and no
+// error, when none is configured.
+func Load(getenv func(string) string) (Provider, error) {
+	name := strings.ToLower(strings.TrimSpace(getenv("PP_TTS_PROVIDER")))
+	endpoint := strings.TrimSpace(getenv("PP_TTS_URL"))
+	apiKey := strings.TrimSpace(getenv("PP_TTS_API_KEY"))
+	voice := strings.TrimSpace(getenv("PP_TTS_VOICE"))
+	if endpoint != "" {
+		if parsed, err := url.Parse(endpoint); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return nil, fmt.Errorf("PP_TTS_URL must be an http or https URL")
+		}
+	}
+
+	switch name {
+	case "":
+		return nil, nil
+	case ProviderPiper:
+		if endpoint == "" {
+			return nil, fmt.Errorf("PP_TTS_URL is required for the piper provider")
+		}
+		return &Piper{URL: endpoint, Voice: voice, client: newHTTPClient()}, nil
+	case ProviderOpenAI:
+		if apiKey == "" {
+			return nil, fmt.Errorf("PP_TTS_API_KEY is required for the openai provider")
+		}
+		return &OpenAI{
+			URL:    cmp.Or(endpoint, defaultOpenAIURL),
+			APIKey: apiKey,
+			Model:  cmp.Or(strings.TrimSpace(getenv("PP_TTS_MODEL")), defaultOpenAIModel),
+			Voice:  cmp.Or(voice, defaultOpenAIVoice),
+			client: newHTTPClient(),
+		}, nil
+	case ProviderAzure:
+		region := strings.TrimSpace(getenv("PP_TTS_REGION"))
+		if apiKey == "" || (region == "" && endpoint == "") {
+			return nil, fmt.Errorf("PP_TTS_API_KEY and PP_TTS_REGION are required for the azure provider")
+		}
+		if endpoint == "" {
+			endpoint = "https://" + region + ".tts.speech.microsoft.com/cognitiveservices/v1"
+		}
+		return &Azure{URL: endpoint, APIKey: apiKey, Voice: cmp.Or(voice, defaultAzureVoice), client: newHTTPClient()}, nil
+	default:
+		return nil, fmt.Errorf("PP_TTS_PROVIDER must be piper, openai or azure")
+	}
+}
+
+func newHTTPClient() *http.Client {
+	return &http.Client{Timeout: requestTimeout}
+}
+
+// post sends body and returns the WAV the provider answers with.
+func post(ctx context.Context, client *http.Client, endpoint, contentType string, body []byte, header http.Header) (model.NarrationAudio, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return model.NarrationAudio{}, err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Accept", model.NarrationAudioType)
+	req.Header.Set("User-Agent", "pandapages-tts/1")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return model.NarrationAudio{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return model.NarrationAudio{}, fmt.Errorf("%w: status %d", ErrProvider, resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxAudioBytes+1))
+	if err != nil {
+		return model.NarrationAudio{}, err
+	}
+	if len(data) > maxAudioBytes {
+		return model.NarrationAudio{}, fmt.Errorf("%w: audio exceeds %d bytes", ErrProvider, maxAudioBytes)
+	}
+	duration, err := WAVDuration(data)
+	if err != nil {
+		return model.NarrationAudio{}, fmt.Errorf("%w: %v", ErrProvider, err)
+	}
+	return model.NarrationAudio{ContentType: model.NarrationAudioType, Data: data, Duration: duration}, nil
+}
@@ -0,0 +1,270 @@
+package tts
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"pandapages/api/internal/model"
+)
+
+// testWAV is a 16kHz 16-bit mono WAV of the given length, with a LIST chunk
+// before the audio as some encoders write.
+func testWAV(duration time.Duration, dataSize uint32) []byte {
+	const byteRate = 16000 * 2
+	samples := int(duration * byteRate / time.Second)
+	out := []byte("RIFF\x00\x00\x00\x00WAVE")
+	out = append(out, "fmt "...)
+	out = binary.LittleEndian.AppendUint32(out, 16)
+	out = binary.LittleEndian.AppendUint16(out, 1)
+	out = binary.LittleEndian.AppendUint16(out, 1)
+	out = binary.LittleEndian.AppendUint32(out, 16000)
+	out = binary.LittleEndian.AppendUint32(out, byteRate)
+	out = binary.LittleEndian.AppendUint16(out, 2)
+	out = binary.LittleEndian.AppendUint16(out, 16)
+	out = append(out, "LIST\x03\x00\x00\x00abc\x00"...)
+	out = append(out, "data"...)
+	if dataSize == 0 {
+		dataSize = uint32(samples)
+	}
+	out = binary.LittleEndian.AppendUint32(out, dataSize)
+	return append(out, make([]byte, samples)...)
+}
+
+func TestWAVDuration(t *testing.T) {
+	if got, err := WAVDuration(testWAV(1500*time.Millisecond, 0)); err != nil || got != 1500*time.Millisecond {
+		t.Fatalf("WAVDuration = %v, %v; want 1.5s", got, err)
+	}
+	// A streamed header gives no size; the audio present is measured.
+	if got, err := WAVDuration(testWAV(2*time.Second, 0xFFFFFFFF)); err != nil || got != 2*time.Second {
+		t.Fatalf("streamed WAVDuration = %v, %v; want 2s", got, err)
+	}
+	for name, data := range map[string][]byte{
+		"mp3":      []byte("ID3\x04\x00\x00\x00\x00\x00\x00"),
+		"no audio": testWAV(0, 0)[:44+12],
+		"no fmt":   []byte("RIFF\x00\x00\x00\x00WAVEdata\x04\x00\x00\x00abcd"),
+	} {
+		if _, err := WAVDuration(data); err == nil {
+			t.Errorf("%s: WAVDuration succeeded", name)
+		}
+	}
+}
+
+func TestScriptReadsTheSegmentsTextWithItsPronunciations(t *testing.T) {
+	segment := model.NarrationSegment{
+		RenderedHTML: "<p>Hermione met Hermiones <em>cat</em> &amp; said &ldquo;hel\u00adlo&rdquo;.</p>",
+		Pronunciations: []model.ReaderPronunciation{
+			{Text: "Hermione", Say: "her-MY-oh-nee"},
+			{Text: "cat", Say: ""},
+		},
+	}
+	want := "her-MY-oh-nee met Hermiones cat & said “hello”."
+	if got := Script(segment); got != want {
+		t.Fatalf("Script = %q, want %q", got, want)
+	}
+	if got := Script(model.NarrationSegment{RenderedHTML: `<figure><img src="/api/v1/media/x" alt=""></figure>`}); got != "" {
+		t.Fatalf("image script = %q, want none", got)
+	}
+}
+
+func TestLoadBuildsTheConfiguredProvider(t *testing.T) {
+	env := func(values map[string]string) func(string) string {
+		return func(key string) string { return values[key] }
+	}
+	if provider, err := Load(env(nil)); err != nil || provider != nil {
+		t.Fatalf("unconfigured Load = %v, %v", provider, err)
+	}
+
+	provider, err := Load(env(map[string]string{"PP_TTS_PROVIDER": "Azure", "PP_TTS_API_KEY": "key", "PP_TTS_REGION": "uksouth"}))
+	if err != nil {
+		t.Fatalf("azure Load error = %v", err)
+	}
+	azure, ok := provider.(*Azure)
+	if !ok || azure.URL != "https://uksouth.tts.speech.microsoft.com/cognitiveservices/v1" || azure.Voice != defaultAzureVoice {
+		t.Fatalf("azure provider = %#v", provider)
+	}
+
+	for name, values := range map[string]map[string]string{
+		"unknown":       {"PP_TTS_PROVIDER": "festival"},
+		"piper url":     {"PP_TTS_PROVIDER": "piper"},
+		"openai key":    {"PP_TTS_PROVIDER": "openai"},
+		"azure region":  {"PP_TTS_PROVIDER": "azure", "PP_TTS_API_KEY": "key"},
+		"malformed url": {"PP_TTS_PROVIDER": "piper", "PP_TTS_URL": "ftp://piper"},
+	} {
+		if _, err := Load(env(values)); err == nil {
+			t.Errorf("%s: Load succeeded", name)
+		}
+	}
+}
+
+func TestProvidersSendTheirRequestAndMeasureTheAnswer(t *testing.T) {
+	var got *http.Request
+	var body string
+	status := http.StatusOK
+	answer := testWAV(time.Second, 0)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw, _ := io.ReadAll(r.Body)
+		got, body = r, string(raw)
+		w.WriteHeader(status)
+		_, _ = w.Write(answer)
+	}))
+	t.Cleanup(server.Close)
+	client := server.Client()
+
+	openai := &OpenAI{URL: server.URL, APIKey: "secret", Model: defaultOpenAIModel, Voice: defaultOpenAIVoice, client: client}
+	audio, err := openai.Synthesize(context.Background(), "Once upon a time.", "")
+	if err != nil || audio.Duration != time.Second || audio.ContentType != "audio/wav" {
+		t.Fatalf("openai audio = %v %q, error %v", audio.Duration, audio.ContentType, err)
+	}
+	var request map[string]string
+	if err := json.Unmarshal([]byte(body), &request); err != nil || request["voice"] != "alloy" ||
+		request["input"] != "Once upon a time." || request["response_format"] != "wav" {
+		t.Fatalf("openai request = %s", body)
+	}
+	if got.Header.Get("Authorization") != "Bearer secret" {
+		t.Fatalf("openai Authorization = %q", got.Header.Get("Authorization"))
+	}
+
+	piper := &Piper{URL: server.URL, client: client}
+	if _, err := piper.Synthesize(context.Background(), "Hello", "en_GB-alba"); err != nil || body != `{"text":"Hello","voice":"en_GB-alba"}` {
+		t.Fatalf("piper request = %s, error %v", body, err)
+	}
+
+	azure := &Azure{URL: server.URL, APIKey: "secret", Voice: defaultAzureVoice, client: client}
+	if _, err := azure.Synthesize(context.Background(), "Cats & <dogs>", "en-GB-SoniaNeural"); err != nil {
+		t.Fatalf("azure error = %v", err)
+	}
+	if !strings.Contains(body, `xml:lang="en-GB"`) || !strings.Contains(body, `<voice name="en-GB-SoniaNeural">Cats &amp; &lt;dogs&gt;</voice>`) ||
+		got.Header.Get("Ocp-Apim-Subscription-Key") != "secret" || got.Header.Get("X-Microsoft-Outputformat") != azureOutputFormat {
+		t.Fatalf("azure request = %s, headers %v", body, got.Header)
+	}
+
+	status = http.StatusUnauthorized
+	if _, err := piper.Synthesize(context.Background(), "Hello", ""); !errors.Is(err, ErrProvider) {
+		t.Fatalf("refused error = %v, want ErrProvider", err)
+	}
+	status, answer = http.StatusOK, []byte("ID3 not a wav")
+	if _, err := piper.Synthesize(context.Background(), "Hello", ""); !errors.Is(err, ErrProvider) {
+		t.Fatalf("mp3 error = %v, want ErrProvider", err)
+	}
+}
+
+type fakeStore struct {
+	queued   []model.NarrationJob
+	segments []model.NarrationSegment
+	recorded []*model.NarrationAudio
+	failure  *string
+	finished int
+}
+
+func (s *fakeStore) NarrationClaimJob(context.Context, time.Duration) (model.NarrationJob, bool, error) {
+	if len(s.queued) == 0 {
+		return model.NarrationJob{}, false, nil
+	}
+	job := s.queued[0]
+	s.queued = s.queued[1:]
+	job.Status = model.RenderJobRunning
+	return job, true, nil
+}
+
+func (s *fakeStore) NarrationPendingSegments(_ context.Context, job model.NarrationJob, limit int) ([]model.NarrationSegment, error) {
+	var out []model.NarrationSegment
+	for _, segment := range s.segments {
+		if segment.Ordinal > job.CursorOrdinal && len(out) < limit {
+			out = append(out, segment)
+		}
+	}
+	return out, nil
+}
+
+func (s *fakeStore) NarrationRecordSegment(_ context.Context, job model.NarrationJob, ordinal int, audio *model.NarrationAudio, _ time.Duration) (model.NarrationJob, error) {
+	s.recorded = append(s.recorded, audio)
+	job.CursorOrdinal = ordinal
+	if audio != nil {
+		job.NarratedSegments++
+	}
+	return job, nil
+}
+
+func (s *fakeStore) NarrationFinishJob(_ context.Context, job model.NarrationJob, failure *string) (model.NarrationJob, error) {
+	s.finished++
+	s.failure = failure
+	job.Status = model.RenderJobCompleted
+	if failure != nil {
+		job.Status = model.RenderJobFailed
+	}
+	return job, nil
+}
+
+type fakeProvider struct {
+	voices  []string
+	failOn  string
+	scripts []string
+}
+
+func (p *fakeProvider) Name() string { return "fake" }
+
+func (p *fakeProvider) Synthesize(_ context.Context, text, voice string) (model.NarrationAudio, error) {
+	if text == p.failOn {
+		return model.NarrationAudio{}, ErrProvider
+	}
+	p.scripts = append(p.scripts, text)
+	p.voices = append(p.voices, voice)
+	return model.NarrationAudio{ContentType: model.NarrationAudioType, Data: []byte(text), Duration: time.Second}, nil
+}
+
+func TestWorkerNarratesEverySegmentAndSkipsSilentOnes(t *testing.T) {
+	store := &fakeStore{
+		queued: []model.NarrationJob{{ID: "job", Voice: "nova", Status: model.RenderJobQueued}},
+		segments: []model.NarrationSegment{
+			{Ordinal: 1, RenderedHTML: "<p>One.</p>"},
+			{Ordinal: 2, RenderedHTML: `<figure><img src="x" alt=""></figure>`},
+			{Ordinal: 3, RenderedHTML: "<p>Three.</p>"},
+		},
+	}
+	provider := &fakeProvider{}
+	worker := NewWorker(store, provider)
+	worker.runner.Batch = 2
+
+	worker.RunOnce(context.Background())
+	if strings.Join(provider.scripts, "|") != "One.|Three." || provider.voices[0] != "nova" {
+		t.Fatalf("scripts = %q, voices = %q", provider.scripts, provider.voices)
+	}
+	if len(store.recorded) != 3 || store.recorded[1] != nil || store.finished != 1 || store.failure != nil {
+		t.Fatalf("recorded = %v, finished = %d, failure = %v", store.recorded, store.finished, store.failure)
+	}
+	worker.RunOnce(context.Background())
+	if store.finished != 1 {
+		t.Fatalf("finished = %d after the queue emptied", store.finished)
+	}
+}
+
+func TestWorkerFailsAJobAtTheSegmentTheProviderRefuses(t *testing.T) {
+	store := &fakeStore{
+		queued: []model.NarrationJob{{ID: "job"}},
+		segments: []model.NarrationSegment{
+			{Ordinal: 1, RenderedHTML: "<p>One.</p>"},
+			{Ordinal: 2, RenderedHTML: "<p>Two.</p>"},
+			{Ordinal: 3, RenderedHTML: "<p>Three.</p>"},
+		},
+	}
+	NewWorker(store, &fakeProvider{failOn: "Two."}).RunOnce(context.Background())
+	if len(store.recorded) != 1 || store.failure == nil || *store.failure != "segment 2 could not be narrated" {
+		t.Fatalf("recorded = %d, failure = %v", len(store.recorded), store.failure)
+	}
+
+	store = &fakeStore{queued: []model.NarrationJob{{ID: "job"}}, segments: store.segments}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	NewWorker(store, &fakeProvider{}).RunOnce(ctx)
+	if len(store.recorded) != 0 || store.finished != 0 {
+		t.Fatalf("cancelled worker recorded %d and finished %d", len(store.recorded), store.finished)
+	}
+}
@@ -0,0 +1,45 @@
+package tts
+
+import (
+	"encoding/binary"
+	"errors"
+	"time"
+)
+
+// WAVDuration reads a RIFF WAVE header and returns how long its audio plays.
+// A streamed WAV may give its data size as unknown (0xFFFFFFFF) or too large;
+// the bytes actually present are measured instead.
+func WAVDuration(data []byte) (time.Duration, error) {
+	if len(data) < 12 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WAVE" {
+		return 0, errors.New("audio is not WAV")
+	}
+	var byteRate uint32
+	for offset := 12; offset+8 <= len(data); {
+		id := string(data[offset : offset+4])
+		size := int64(binary.LittleEndian.Uint32(data[offset+4 : offset+8]))
+		body := offset + 8
+		switch id {
+		case "fmt ":
+			if size < 16 || int64(body)+16 > int64(len(data)) {
+				return 0, errors.New("WAV format chunk is truncated")
+			}
+			byteRate = binary.LittleEndian.Uint32(data[body+8 : body+12])
+		case "data":
+			if byteRate == 0 {
+				return 0, errors.New("WAV has no usable format chunk")
+			}
+			size = min(size, int64(len(data)-body))
+			if size <= 0 {
+				return 0, errors.New("WAV has no audio")
+			}
+			return time.Duration(size) * time.Second / time.Duration(byteRate), nil
+		}
+		// Chunks are padded to an even length.
+		next := int64(body) + size + size%2
+		if next > int64(len(data)) {
+			break
+		}
+		offset = int(next)
+	}
+	return 0, errors.New("WAV has no data chunk")
+}
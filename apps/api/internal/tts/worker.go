@@ -0,0 +1,91 @@
+package tts
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"pandapages/api/internal/jobrunner"
+	"pandapages/api/internal/model"
+	"pandapages/api/internal/providerlog"
+)
+
+const (
+	defaultInterval = 10 * time.Second
+	// The lease is renewed after every segment, so it need only outlast one
+	// provider call.
+	claimLease = requestTimeout + time.Minute
+	batchSize  = 10
+)
+
+type Store interface {
+	NarrationClaimJob(ctx context.Context, lease time.Duration) (model.NarrationJob, bool, error)
+	NarrationPendingSegments(ctx context.Context, job model.NarrationJob, limit int) ([]model.NarrationSegment, error)
+	NarrationRecordSegment(ctx context.Context, job model.NarrationJob, ordinal int, audio *model.NarrationAudio, lease time.Duration) (model.NarrationJob, error)
+	NarrationFinishJob(ctx context.Context, job model.NarrationJob, failure *string) (model.NarrationJob, error)
+}
+
+// Worker narrates queued jobs. A segment the provider cannot read fails the
+// job; asking again resumes from it, since segments already narrated in the
+// job's voice are kept.
+type Worker struct {
+	runner jobrunner.Runner[model.NarrationJob, model.NarrationSegment]
+}
+
+func NewWorker(store Store, provider Provider) *Worker {
+	return &Worker{runner: jobrunner.Runner[model.NarrationJob, model.NarrationSegment]{
+		Jobs:     narrationJobs{store: store, provider: provider},
+		Kind:     "narration",
+		Item:     "segment",
+		Lease:    claimLease,
+		Interval: defaultInterval,
+		Batch:    batchSize,
+	}}
+}
+
+// Run processes queued jobs until ctx is cancelled.
+func (w *Worker) Run(ctx context.Context) { w.runner.Run(ctx) }
+
+// RunOnce claims one job and narrates it until it finishes or ctx ends.
+func (w *Worker) RunOnce(ctx context.Context) { w.runner.RunOnce(ctx) }
+
+// narrationJobs is the jobrunner side of narration jobs.
+type narrationJobs struct {
+	store    Store
+	provider Provider
+}
+
+func (n narrationJobs) Claim(ctx context.Context, lease time.Duration) (model.NarrationJob, bool, error) {
+	return n.store.NarrationClaimJob(ctx, lease)
+}
+
+func (n narrationJobs) Pending(ctx context.Context, job model.NarrationJob, limit int) ([]model.NarrationSegment, error) {
+	return n.store.NarrationPendingSegments(ctx, job, limit)
+}
+
+func (n narrationJobs) Process(ctx context.Context, job model.NarrationJob, segment model.NarrationSegment, lease time.Duration) (model.NarrationJob, string, error) {
+	var audio *model.NarrationAudio
+	if script := Script(segment); script != "" {
+		spoken, err := n.provider.Synthesize(ctx, script, job.Voice)
+		if ctx.Err() != nil {
+			return job, "", ctx.Err()
+		}
+		if err != nil {
+			providerlog.Failure("narration segment failed", n.provider.Name(), "job", job.ID, "ordinal", segment.Ordinal)
+			return job, "segment " + strconv.Itoa(segment.Ordinal) + " could not be narrated", nil
+		}
+		audio = &spoken
+	}
+	next, err := n.store.NarrationRecordSegment(ctx, job, segment.Ordinal, audio, lease)
+	return next, "", err
+}
+
+func (n narrationJobs) Finish(ctx context.Context, job model.NarrationJob, failure *string) (model.NarrationJob, error) {
+	return n.store.NarrationFinishJob(ctx, job, failure)
+}
+
+func (narrationJobs) ID(job model.NarrationJob) string { return job.ID }
+
+func (narrationJobs) Summary(job model.NarrationJob) []any {
+	return []any{"status", string(job.Status), "narrated", job.NarratedSegments, "total", job.TotalSegments}
+}
@@ -0,0 +1,116 @@
+package urlimport
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// Converter turns one kind of document into Markdown.
+type Converter interface {
+	// Accepts reports whether the converter reads a download of mediaType,
+	// without parameters, whose path ends in extension, such as ".md".
+	Accepts(mediaType, extension string) bool
+	Convert(data []byte) (Document, error)
+}
+
+// Markdown reads Markdown and plain text, which is Markdown already. A title
+// in frontmatter is the title; otherwise a leading level-one heading is
+// lifted out to become it.
+type Markdown struct{}
+
+func (Markdown) Accepts(mediaType, extension string) bool {
+	switch mediaType {
+	case "text/markdown", "text/x-markdown", "text/plain":
+		return true
+	}
+	return extension == ".md" || extension == ".markdown"
+}
+
+func (Markdown) Convert(data []byte) (Document, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	if !utf8.Valid(data) {
+		return Document{}, errors.New("text is not UTF-8")
+	}
+	text := strings.ReplaceAll(string(data), "\r\n", "\n")
+	doc := Document{Markdown: text, Format: "markdown"}
+	if header, ok := strings.CutPrefix(text, "---\n"); ok {
+		var frontmatter struct {
+			Title string `yaml:"title"`
+		}
+		if header, _, ok := strings.Cut(header, "\n---"); ok && yaml.Unmarshal([]byte(header), &frontmatter) == nil {
+			doc.Title = strings.TrimSpace(frontmatter.Title)
+		}
+		return doc, nil
+	}
+	trimmed := strings.TrimLeft(text, " \t\n")
+	first, rest, _ := strings.Cut(trimmed, "\n")
+	if heading, ok := strings.CutPrefix(strings.TrimSpace(first), "# "); ok && strings.TrimSpace(heading) != "" {
+		doc.Title = strings.TrimSpace(heading)
+		doc.Markdown = strings.TrimLeft(rest, "\n")
+	}
+	return doc, nil
+}
+
+// block is one converted paragraph, heading or list item. List numbers the
+// list an item belongs to, counting from 1; it is 0 for other blocks.
+type block struct {
+	text string
+	list int
+}
+
+// joinBlocks separates blocks with a blank line, except for items of the
+// same list, which stay together.
+func joinBlocks(blocks []block) string {
+	var out strings.Builder
+	for index, b := range blocks {
+		if index > 0 {
+			if b.list != 0 && b.list == blocks[index-1].list {
+				out.WriteString("\n")
+			} else {
+				out.WriteString("\n\n")
+			}
+		}
+		out.WriteString(b.text)
+	}
+	if out.Len() > 0 {
+		out.WriteString("\n")
+	}
+	return out.String()
+}
+
+var markdownEscaper = strings.NewReplacer(`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "[", `\[`, "]", `\]`, "<", `\<`)
+
+// escapeText keeps characters in document text from being read as Markdown
+// syntax.
+func escapeText(text string) string {
+	return markdownEscaper.Replace(text)
+}
+
+// escapeLineStart keeps a paragraph that happens to start like a heading,
+// list item or quote from becoming one.
+func escapeLineStart(text string) string {
+	switch {
+	case strings.HasPrefix(text, "#"), strings.HasPrefix(text, ">"), strings.HasPrefix(text, "- "), strings.HasPrefix(text, "+ "):
+		return `\` + text
+	}
+	digits := len(text) - len(strings.TrimLeft(text, "0123456789"))
+	if digits > 0 && (strings.HasPrefix(text[digits:], ". ") || strings.HasPrefix(text[digits:], ") ")) {
+		return text[:digits] + `\` + text[digits:]
+	}
+	return text
+}
+
+// emphasize wraps text in marker, keeping surrounding spaces outside it, as
+// Markdown does not treat "** bold**" as emphasis.
+func emphasize(text, marker string) string {
+	core := strings.TrimSpace(text)
+	if core == "" {
+		return text
+	}
+	start := strings.Index(text, core)
+	return text[:start] + marker + core + marker + text[start+len(core):]
+}
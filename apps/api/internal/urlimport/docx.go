@@ -0,0 +1,254 @@
+package urlimport
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+)
+
+const (
+	wordNamespace = "http://schemas.openxmlformats.org/wordprocessingml/2006/main"
+	relNamespace  = "http://schemas.openxmlformats.org/officeDocument/2006/relationships"
+	// maxPartBytes bounds one inflated part of the archive, so a small
+	// download cannot expand without limit.
+	maxPartBytes = 32 << 20
+)
+
+// DOCX reads Word documents, which is what Google Docs exports. Headings,
+// list paragraphs, bold, italics, line breaks and links carry over; images,
+// comments and footnotes do not. A paragraph in the Title style becomes the
+// title, and otherwise a leading Heading 1 does.
+type DOCX struct{}
+
+func (DOCX) Accepts(mediaType, extension string) bool {
+	return mediaType == docxType || extension == ".docx"
+}
+
+func (DOCX) Convert(data []byte) (Document, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return Document{}, errors.New("document is not a .docx archive")
+	}
+	body, err := readPart(archive, "word/document.xml")
+	if err != nil {
+		return Document{}, err
+	}
+	links := map[string]string{}
+	if rels, err := readPart(archive, "word/_rels/document.xml.rels"); err == nil {
+		links = hyperlinks(rels)
+	}
+
+	w := docxWriter{links: links}
+	if err := w.read(body); err != nil {
+		return Document{}, errors.New("document body is not valid XML")
+	}
+	doc := Document{Title: w.title, Format: "docx"}
+	blocks := w.blocks
+	if doc.Title == "" && w.leadHeading {
+		doc.Title, blocks = strings.TrimPrefix(blocks[0].text, "# "), blocks[1:]
+		doc.Title = strings.NewReplacer(`\`, "").Replace(doc.Title)
+	}
+	doc.Markdown = joinBlocks(blocks)
+	return doc, nil
+}
+
+func readPart(archive *zip.Reader, name string) ([]byte, error) {
+	for _, file := range archive.File {
+		if file.Name != name {
+			continue
+		}
+		part, err := file.Open()
+		if err != nil {
+			return nil, errors.New("document part could not be opened")
+		}
+		defer part.Close()
+		data, err := io.ReadAll(io.LimitReader(part, maxPartBytes+1))
+		if err != nil || len(data) > maxPartBytes {
+			return nil, errors.New("document part could not be read")
+		}
+		return data, nil
+	}
+	return nil, errors.New("document has no " + name)
+}
+
+// hyperlinks maps relationship ids to the external links they name.
+func hyperlinks(rels []byte) map[string]string {
+	var doc struct {
+		Relationships []struct {
+			ID         string `xml:"Id,attr"`
+			Target     string `xml:"Target,attr"`
+			TargetMode string `xml:"TargetMode,attr"`
+		} `xml:"Relationship"`
+	}
+	links := map[string]string{}
+	if xml.Unmarshal(rels, &doc) != nil {
+		return links
+	}
+	for _, rel := range doc.Relationships {
+		if rel.TargetMode == "External" {
+			if target := linkTarget(rel.Target); target != "" {
+				links[rel.ID] = target
+			}
+		}
+	}
+	return links
+}
+
+type docxWriter struct {
+	links       map[string]string
+	blocks      []block
+	title       string
+	leadHeading bool
+
+	// The paragraph being read.
+	paragraph strings.Builder
+	style     string
+	listLevel int
+	listItem  bool
+	// The run being read and its formatting.
+	run    strings.Builder
+	bold   bool
+	italic bool
+	// The hyperlink being read, if any, and the text read inside it.
+	link     string
+	linkText *strings.Builder
+}
+
+func (w *docxWriter) read(body []byte) error {
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	inText := false
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			if t.Name.Space != wordNamespace {
+				continue
+			}
+			switch t.Name.Local {
+			case "p":
+				w.paragraph.Reset()
+				w.style, w.listLevel, w.listItem = "", 0, false
+			case "pStyle":
+				w.style = wordAttr(t, "val")
+			case "ilvl":
+				if level := wordAttr(t, "val"); len(level) == 1 && level[0] >= '0' && level[0] <= '8' {
+					w.listLevel = int(level[0] - '0')
+				}
+			case "numPr":
+				w.listItem = true
+			case "r":
+				w.run.Reset()
+				w.bold, w.italic = false, false
+			case "b":
+				w.bold = wordToggle(t)
+			case "i":
+				w.italic = wordToggle(t)
+			case "t":
+				inText = true
+			case "tab":
+				w.run.WriteString(" ")
+			case "br", "cr":
+				w.run.WriteString("\\\n")
+			case "hyperlink":
+				w.link = w.links[attrNS(t, relNamespace, "id")]
+				w.linkText = &strings.Builder{}
+			}
+		case xml.CharData:
+			if inText {
+				w.run.WriteString(escapeText(string(t)))
+			}
+		case xml.EndElement:
+			if t.Name.Space != wordNamespace {
+				continue
+			}
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "r":
+				w.endRun()
+			case "hyperlink":
+				text := w.linkText.String()
+				w.linkText = nil
+				if w.link != "" && strings.TrimSpace(text) != "" {
+					text = "[" + strings.TrimSpace(text) + "](" + w.link + ")"
+				}
+				w.paragraph.WriteString(text)
+			case "p":
+				w.endParagraph()
+			}
+		}
+	}
+}
+
+func (w *docxWriter) endRun() {
+	text := w.run.String()
+	if w.bold {
+		text = emphasize(text, "**")
+	}
+	if w.italic {
+		text = emphasize(text, "_")
+	}
+	if w.linkText != nil {
+		w.linkText.WriteString(text)
+		return
+	}
+	w.paragraph.WriteString(text)
+}
+
+func (w *docxWriter) endParagraph() {
+	text := strings.TrimSpace(spacesRe.ReplaceAllString(w.paragraph.String(), " "))
+	text = strings.TrimSpace(strings.TrimSuffix(text, `\`))
+	if text == "" {
+		return
+	}
+	style := strings.ToLower(strings.ReplaceAll(w.style, " ", ""))
+	switch {
+	case style == "title":
+		if w.title == "" && len(w.blocks) == 0 {
+			w.title = strings.NewReplacer(`\`, "").Replace(text)
+			return
+		}
+		w.blocks = append(w.blocks, block{text: "# " + text})
+	case strings.HasPrefix(style, "heading") && len(style) == len("heading1") && style[7] >= '1' && style[7] <= '6':
+		level := int(style[7] - '0')
+		if level == 1 && len(w.blocks) == 0 && w.title == "" {
+			w.leadHeading = true
+		}
+		w.blocks = append(w.blocks, block{text: strings.Repeat("#", level) + " " + text})
+	case w.listItem || style == "listparagraph":
+		w.blocks = append(w.blocks, block{text: strings.Repeat("  ", w.listLevel) + "- " + text, list: 1})
+	default:
+		w.blocks = append(w.blocks, block{text: escapeLineStart(text)})
+	}
+}
+
+func wordAttr(element xml.StartElement, name string) string {
+	return attrNS(element, wordNamespace, name)
+}
+
+func attrNS(element xml.StartElement, space, name string) string {
+	for _, a := range element.Attr {
+		if a.Name.Space == space && a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// wordToggle reads an on/off property such as <w:b/> or <w:b w:val="0"/>.
+func wordToggle(element xml.StartElement) bool {
+	switch wordAttr(element, "val") {
+	case "0", "false", "off":
+		return false
+	}
+	return true
+}
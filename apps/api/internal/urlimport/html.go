@@ -0,0 +1,253 @@
+package urlimport
+
+import (
+	"bytes"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+var spacesRe = regexp.MustCompile(` {2,}`)
+
+// HTML reads web pages. Headings, paragraphs, lists, quotes, emphasis and
+// links carry over; scripts, navigation, forms and images do not. A leading
+// h1 becomes the title, and otherwise the page's <title> does.
+type HTML struct{}
+
+func (HTML) Accepts(mediaType, extension string) bool {
+	switch mediaType {
+	case "text/html", "application/xhtml+xml":
+		return true
+	}
+	return extension == ".html" || extension == ".htm"
+}
+
+func (HTML) Convert(data []byte) (Document, error) {
+	root, err := html.Parse(bytes.NewReader(data))
+	if err != nil {
+		return Document{}, err
+	}
+	w := &htmlWriter{inline: &strings.Builder{}}
+	w.walk(root)
+	w.flush("", 0)
+
+	doc := Document{Title: w.pageTitle, Format: "html"}
+	blocks := w.blocks
+	if w.leadTitle != "" && len(blocks) > 0 && strings.HasPrefix(blocks[0].text, "# ") {
+		doc.Title, blocks = w.leadTitle, blocks[1:]
+	}
+	doc.Markdown = joinBlocks(blocks)
+	return doc, nil
+}
+
+type htmlList struct {
+	ordered bool
+	next    int
+}
+
+type htmlWriter struct {
+	blocks    []block
+	inline    *strings.Builder
+	lists     []htmlList
+	listCount int
+	quote     int
+	pageTitle string
+	leadTitle string
+}
+
+func (w *htmlWriter) walk(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		text := n.Data
+		if text == "" {
+			return
+		}
+		if isSpace(text[0]) {
+			w.inline.WriteString(" ")
+		}
+		if words := strings.Fields(text); len(words) > 0 {
+			w.inline.WriteString(escapeText(strings.Join(words, " ")))
+			if isSpace(text[len(text)-1]) {
+				w.inline.WriteString(" ")
+			}
+		}
+		return
+	case html.DocumentNode:
+		w.children(n)
+		return
+	case html.ElementNode:
+	default:
+		return
+	}
+
+	switch n.DataAtom {
+	case atom.Head:
+		w.findTitle(n)
+	case atom.Script, atom.Style, atom.Noscript, atom.Template, atom.Iframe, atom.Svg, atom.Nav,
+		atom.Form, atom.Button, atom.Select, atom.Textarea, atom.Img, atom.Picture, atom.Video, atom.Audio:
+	case atom.Br:
+		w.inline.WriteString("\\\n")
+	case atom.Hr:
+		w.flush("", 0)
+		w.emit("***", 0)
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		w.flush("", 0)
+		level := int(n.Data[1] - '0')
+		if level == 1 && len(w.blocks) == 0 && w.leadTitle == "" {
+			w.leadTitle = strings.Join(strings.Fields(textContent(n)), " ")
+		}
+		w.children(n)
+		w.flush(strings.Repeat("#", level)+" ", 0)
+	case atom.Ul, atom.Ol:
+		w.flush("", 0)
+		list := htmlList{ordered: n.DataAtom == atom.Ol, next: 1}
+		if start, err := strconv.Atoi(attr(n, "start")); err == nil && start >= 0 {
+			list.next = start
+		}
+		if len(w.lists) == 0 {
+			w.listCount++
+		}
+		w.lists = append(w.lists, list)
+		w.children(n)
+		w.flush("", 0)
+		w.lists = w.lists[:len(w.lists)-1]
+	case atom.Li:
+		w.flush("", 0)
+		w.children(n)
+		marker := "- "
+		if depth := len(w.lists); depth > 0 {
+			list := &w.lists[depth-1]
+			if list.ordered {
+				marker = strconv.Itoa(list.next) + ". "
+				list.next++
+			}
+			marker = strings.Repeat("  ", depth-1) + marker
+		}
+		w.flush(marker, w.listCount)
+	case atom.Blockquote:
+		w.flush("", 0)
+		w.quote++
+		w.children(n)
+		w.flush("", 0)
+		w.quote--
+	case atom.Pre:
+		w.flush("", 0)
+		w.emit("```\n"+strings.TrimRight(textContent(n), "\n")+"\n```", 0)
+	case atom.Strong, atom.B:
+		w.wrap(n, func(text string) string { return emphasize(text, "**") })
+	case atom.Em, atom.I:
+		w.wrap(n, func(text string) string { return emphasize(text, "_") })
+	case atom.A:
+		href := linkTarget(attr(n, "href"))
+		w.wrap(n, func(text string) string {
+			if href == "" || strings.TrimSpace(text) == "" {
+				return text
+			}
+			return "[" + strings.TrimSpace(text) + "](" + href + ")"
+		})
+	case atom.P, atom.Div, atom.Section, atom.Article, atom.Main, atom.Header, atom.Footer, atom.Aside,
+		atom.Figure, atom.Figcaption, atom.Table, atom.Tr, atom.Dl, atom.Dt, atom.Dd, atom.Address, atom.Body:
+		w.flush("", 0)
+		w.children(n)
+		w.flush("", 0)
+	case atom.Td, atom.Th:
+		w.children(n)
+		w.inline.WriteString(" ")
+	default:
+		w.children(n)
+	}
+}
+
+func (w *htmlWriter) children(n *html.Node) {
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		w.walk(child)
+	}
+}
+
+// wrap renders n's children on their own and writes them through format.
+func (w *htmlWriter) wrap(n *html.Node, format func(string) string) {
+	outer := w.inline
+	w.inline = &strings.Builder{}
+	w.children(n)
+	inner := w.inline.String()
+	w.inline = outer
+	w.inline.WriteString(format(inner))
+}
+
+// flush ends the paragraph being written, if it has any text, under prefix.
+func (w *htmlWriter) flush(prefix string, list int) {
+	text := spacesRe.ReplaceAllString(strings.TrimSpace(w.inline.String()), " ")
+	text = strings.TrimSpace(strings.TrimSuffix(text, `\`))
+	w.inline.Reset()
+	if text == "" {
+		return
+	}
+	if prefix == "" {
+		text = escapeLineStart(text)
+	}
+	w.emit(prefix+text, list)
+}
+
+func (w *htmlWriter) emit(text string, list int) {
+	if w.quote > 0 {
+		quote := strings.Repeat("> ", w.quote)
+		text = quote + strings.ReplaceAll(text, "\n", "\n"+quote)
+	}
+	w.blocks = append(w.blocks, block{text: text, list: list})
+}
+
+func (w *htmlWriter) findTitle(n *html.Node) {
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == html.ElementNode && child.DataAtom == atom.Title {
+			w.pageTitle = strings.Join(strings.Fields(textContent(child)), " ")
+			return
+		}
+	}
+}
+
+func attr(n *html.Node, name string) string {
+	for _, a := range n.Attr {
+		if a.Namespace == "" && a.Key == name {
+			return strings.TrimSpace(a.Val)
+		}
+	}
+	return ""
+}
+
+func textContent(n *html.Node) string {
+	var out strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			out.WriteString(n.Data)
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(n)
+	return out.String()
+}
+
+// linkTarget is href when it is a web address, with Google's redirect
+// wrapper, which Docs puts around every link it exports, removed.
+func linkTarget(href string) string {
+	parsed, err := url.Parse(href)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return ""
+	}
+	if parsed.Host == "www.google.com" && parsed.Path == "/url" {
+		if target := parsed.Query().Get("q"); target != "" {
+			return linkTarget(target)
+		}
+	}
+	return strings.NewReplacer("(", "%28", ")", "%29", " ", "%20").Replace(parsed.String())
+}
+
+func isSpace(b byte) bool {
+	return b == ' ' || b == '\n' || b == '\t' || b == '\r' || b == '\f'
+}
@@ -0,0 +1,226 @@
+// Package urlimport downloads a story from a web address and turns it into
+// Markdown, so a parent who wrote one in Google Docs, or published it as a web
+// page, can import it without converting it by hand. A Fetcher downloads the
+// document and hands it to the first Converter that reads its type; Google
+// Docs edit and view links are rewritten to their .docx export.
+package urlimport
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"pandapages/api/internal/netguard"
+)
+
+const (
+	requestTimeout = 30 * time.Second
+	// MaxDocumentBytes matches the admin body limit: a .docx export carries
+	// its images, so it can be much larger than the story it holds.
+	MaxDocumentBytes = 20 << 20
+	maxRedirects     = 5
+	// MaxURLBytes bounds the address an admin may ask for.
+	MaxURLBytes = 2048
+
+	docxType = "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+)
+
+var (
+	// ErrRefused marks an address that is not on the public internet, so
+	// the fetch cannot be used to reach the server's own network.
+	ErrRefused = netguard.ErrRefused
+	// ErrFetch marks a download that failed or answered with an error
+	// status. Its message never carries the response.
+	ErrFetch = errors.New("document download failed")
+	// ErrTooLarge marks a document over MaxDocumentBytes.
+	ErrTooLarge = errors.New("document is too large")
+	// ErrUnsupported marks a document no Converter reads.
+	ErrUnsupported = errors.New("document type is not supported")
+	// ErrUnreadable marks a document of a supported type that could not be
+	// converted, such as a corrupt .docx.
+	ErrUnreadable = errors.New("document could not be converted")
+)
+
+// Document is a converted download. Title is empty when the document did not
+// name itself.
+type Document struct {
+	Title    string
+	Markdown string
+	// Format names the converter that read it: markdown, html or docx.
+	Format string
+}
+
+// Fetcher downloads documents and converts them.
+type Fetcher struct {
+	client       *http.Client
+	converters   []Converter
+	allowPrivate bool
+}
+
+// Load returns the fetcher PP_IMPORT_FETCH asks for: public (the default)
+// refuses private and loopback addresses, any allows them for documents on
+// the local network, and off returns nil.
+func Load(getenv func(string) string) (*Fetcher, error) {
+	switch strings.ToLower(strings.TrimSpace(getenv("PP_IMPORT_FETCH"))) {
+	case "", "public":
+		return New(false), nil
+	case "any":
+		return New(true), nil
+	case "off":
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("PP_IMPORT_FETCH must be public, any or off")
+	}
+}
+
+// New returns a Fetcher with the Markdown, HTML and .docx converters.
+// Unless allowPrivate is set, every connection, redirects included, is
+// refused unless it goes to a public address; the check runs on the address
+// dialled, so a name that resolves to a private one is refused too.
+func New(allowPrivate bool) *Fetcher {
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	if !allowPrivate {
+		dialer.Control = netguard.PublicOnly
+	}
+	transport := &http.Transport{
+		// No proxy: the address check must see the real destination.
+		Proxy:                 nil,
+		DialContext:           dialer.DialContext,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ResponseHeaderTimeout: requestTimeout,
+	}
+	return &Fetcher{
+		client: &http.Client{
+			Timeout:   requestTimeout,
+			Transport: transport,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				if len(via) >= maxRedirects {
+					return fmt.Errorf("%w: too many redirects", ErrFetch)
+				}
+				if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+					return fmt.Errorf("%w: redirect leaves the web", ErrFetch)
+				}
+				return nil
+			},
+		},
+		converters:   []Converter{Markdown{}, HTML{}, DOCX{}},
+		allowPrivate: allowPrivate,
+	}
+}
+
+// Scope is the addresses the fetcher may reach, public or any, for the
+// startup summary.
+func (f *Fetcher) Scope() string {
+	if f.allowPrivate {
+		return "any"
+	}
+	return "public"
+}
+
+// ValidURL reports whether raw is an absolute http or https URL that may be
+// fetched. Credentials are refused so they are never stored as a story's
+// source.
+func ValidURL(raw string) bool {
+	if raw == "" || len(raw) > MaxURLBytes {
+		return false
+	}
+	parsed, err := url.Parse(raw)
+	if err != nil || parsed.Host == "" || parsed.User != nil {
+		return false
+	}
+	return parsed.Scheme == "http" || parsed.Scheme == "https"
+}
+
+var googleDocRe = regexp.MustCompile(`^/document/(?:u/\d+/)?d/([A-Za-z0-9_-]+)`)
+
+// downloadURL is where raw's document is downloaded from: a Google Docs
+// link becomes its .docx export, which keeps headings and emphasis that the
+// plain-text export loses; anything else is fetched as given.
+func downloadURL(raw string) string {
+	parsed, err := url.Parse(raw)
+	if err != nil || parsed.Host != "docs.google.com" {
+		return raw
+	}
+	match := googleDocRe.FindStringSubmatch(parsed.Path)
+	if match == nil {
+		return raw
+	}
+	return "https://docs.google.com/document/d/" + match[1] + "/export?format=docx"
+}
+
+// Fetch downloads raw and converts it.
+func (f *Fetcher) Fetch(ctx context.Context, raw string) (Document, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, downloadURL(raw), nil)
+	if err != nil {
+		return Document{}, fmt.Errorf("%w: invalid address", ErrFetch)
+	}
+	req.Header.Set("Accept", "text/markdown, text/html;q=0.9, "+docxType+";q=0.9, text/plain;q=0.5, */*;q=0.1")
+	req.Header.Set("User-Agent", "pandapages-import/1")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		if errors.Is(err, ErrRefused) {
+			return Document{}, ErrRefused
+		}
+		// Transport errors embed the address and resolver detail.
+		return Document{}, fmt.Errorf("%w: request failed", ErrFetch)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return Document{}, fmt.Errorf("%w: status %d", ErrFetch, resp.StatusCode)
+	}
+	if resp.Request.URL.Host == "accounts.google.com" {
+		// Google answers a document that is not shared by link with its
+		// sign-in page, which would otherwise import as a story.
+		return Document{}, fmt.Errorf("%w: document is not shared by link", ErrFetch)
+	}
+	if resp.ContentLength > MaxDocumentBytes {
+		return Document{}, ErrTooLarge
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, MaxDocumentBytes+1))
+	if err != nil {
+		return Document{}, fmt.Errorf("%w: body could not be read", ErrFetch)
+	}
+	if len(data) > MaxDocumentBytes {
+		return Document{}, ErrTooLarge
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	extension := strings.ToLower(path.Ext(resp.Request.URL.Path))
+	if mediaType == "" || mediaType == "application/octet-stream" {
+		mediaType = sniff(data)
+	}
+	for _, converter := range f.converters {
+		if !converter.Accepts(mediaType, extension) {
+			continue
+		}
+		doc, err := converter.Convert(data)
+		if err != nil {
+			return Document{}, fmt.Errorf("%w: %v", ErrUnreadable, err)
+		}
+		if strings.TrimSpace(doc.Markdown) == "" {
+			return Document{}, fmt.Errorf("%w: document has no text", ErrUnreadable)
+		}
+		return doc, nil
+	}
+	return Document{}, fmt.Errorf("%w: %s", ErrUnsupported, mediaType)
+}
+
+// sniff guesses the type of a download served without a useful one.
+func sniff(data []byte) string {
+	if bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+		return docxType
+	}
+	mediaType, _, _ := mime.ParseMediaType(http.DetectContentType(data))
+	return mediaType
+}
@@ -0,0 +1,172 @@
+package urlimport
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMarkdownLiftsALeadingHeading(t *testing.T) {
+	doc, err := Markdown{}.Convert([]byte("\xef\xbb\xbf# The Brave Boat\r\n\r\nIt sailed.\r\n"))
+	if err != nil || doc.Title != "The Brave Boat" || doc.Markdown != "It sailed.\n" {
+		t.Fatalf("Convert = %#v, %v", doc, err)
+	}
+	doc, err = Markdown{}.Convert([]byte("---\ntitle: Owls\n---\n# Night\n"))
+	if err != nil || doc.Title != "Owls" || doc.Markdown != "---\ntitle: Owls\n---\n# Night\n" {
+		t.Fatalf("frontmatter Convert = %#v, %v", doc, err)
+	}
+	if _, err := (Markdown{}).Convert([]byte{0xff, 0xfe}); err == nil {
+		t.Fatal("Convert accepted text that is not UTF-8")
+	}
+}
+
+func TestHTMLConvertsTheReadableParts(t *testing.T) {
+	page := `<!doctype html><html><head><title>Page title</title><style>p{}</style></head><body>
+<nav><a href="/">Home</a></nav>
+<h1>The Brave <em>Little</em> Boat</h1>
+<p>Once   upon a <strong>stormy </strong>night,<br>the boat *sailed*.</p>
+<h2>Chapter 1</h2>
+<ul><li>oars</li><li>a <a href="https://www.google.com/url?q=https://example.com/sail&amp;sa=D">sail</a></li></ul>
+<ol start="3"><li>three</li></ol>
+<blockquote><p>Hold fast.</p></blockquote>
+<p># not a heading</p>
+<script>alert(1)</script>
+<img src="boat.png" alt="a boat">
+</body></html>`
+	doc, err := HTML{}.Convert([]byte(page))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Once upon a **stormy** night,\\\nthe boat \\*sailed\\*.\n\n" +
+		"## Chapter 1\n\n" +
+		"- oars\n- a [sail](https://example.com/sail)\n\n" +
+		"3. three\n\n" +
+		"> Hold fast.\n\n" +
+		"\\# not a heading\n"
+	if doc.Title != "The Brave Little Boat" || doc.Markdown != want || doc.Format != "html" {
+		t.Fatalf("Convert = %q / %q, want\n%q", doc.Title, doc.Markdown, want)
+	}
+
+	doc, err = HTML{}.Convert([]byte(`<title> Owls </title><p>Hoot.</p>`))
+	if err != nil || doc.Title != "Owls" || doc.Markdown != "Hoot.\n" {
+		t.Fatalf("untitled page = %#v, %v", doc, err)
+	}
+}
+
+const docxBody = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><w:body>
+<w:p><w:pPr><w:pStyle w:val="Title"/></w:pPr><w:r><w:t>The Brave Boat</w:t></w:r></w:p>
+<w:p><w:pPr><w:pStyle w:val="Heading2"/></w:pPr><w:r><w:t>Chapter 1</w:t></w:r></w:p>
+<w:p><w:r><w:t xml:space="preserve">Once upon a </w:t></w:r><w:r><w:rPr><w:b/></w:rPr><w:t xml:space="preserve">stormy </w:t></w:r><w:r><w:rPr><w:i/><w:b w:val="0"/></w:rPr><w:t>night</w:t></w:r><w:r><w:br/><w:t>it sailed.</w:t></w:r></w:p>
+<w:p><w:pPr><w:numPr><w:ilvl w:val="0"/><w:numId w:val="1"/></w:numPr></w:pPr><w:r><w:t>oars</w:t></w:r></w:p>
+<w:p><w:pPr><w:numPr><w:ilvl w:val="1"/><w:numId w:val="1"/></w:numPr></w:pPr><w:hyperlink r:id="rId9"><w:r><w:t>sail</w:t></w:r></w:hyperlink></w:p>
+<w:p><w:r><w:t>1. Not a list</w:t></w:r></w:p>
+<w:p/>
+</w:body></w:document>`
+
+const docxRels = `<?xml version="1.0" encoding="UTF-8"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId9" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/hyperlink" Target="https://example.com/sail" TargetMode="External"/>
+</Relationships>`
+
+func testDOCX(t *testing.T, body string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for name, content := range map[string]string{"word/document.xml": body, "word/_rels/document.xml.rels": docxRels} {
+		part, err := archive.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = io.WriteString(part, content)
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestDOCXConvertsParagraphsAndRuns(t *testing.T) {
+	doc, err := DOCX{}.Convert(testDOCX(t, docxBody))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "## Chapter 1\n\n" +
+		"Once upon a **stormy** _night_\\\nit sailed.\n\n" +
+		"- oars\n  - [sail](https://example.com/sail)\n\n" +
+		"1\\. Not a list\n"
+	if doc.Title != "The Brave Boat" || doc.Markdown != want || doc.Format != "docx" {
+		t.Fatalf("Convert = %q / %q, want\n%q", doc.Title, doc.Markdown, want)
+	}
+
+	if _, err := (DOCX{}).Convert([]byte("not a zip")); err == nil {
+		t.Fatal("Convert accepted a file that is not an archive")
+	}
+}
+
+func TestFetchConvertsByContentType(t *testing.T) {
+	docx := testDOCX(t, docxBody)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/story.md":
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			_, _ = io.WriteString(w, "# Owls\n\nHoot.\n")
+		case "/export":
+			w.Header().Set("Content-Type", "application/octet-stream")
+			_, _ = w.Write(docx)
+		case "/picture":
+			w.Header().Set("Content-Type", "image/png")
+			_, _ = io.WriteString(w, "\x89PNG")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	fetcher := New(true)
+
+	doc, err := fetcher.Fetch(context.Background(), server.URL+"/story.md")
+	if err != nil || doc.Title != "Owls" || doc.Format != "markdown" {
+		t.Fatalf("markdown = %#v, %v", doc, err)
+	}
+	doc, err = fetcher.Fetch(context.Background(), server.URL+"/export")
+	if err != nil || doc.Title != "The Brave Boat" || doc.Format != "docx" {
+		t.Fatalf("sniffed docx = %#v, %v", doc, err)
+	}
+	if _, err := fetcher.Fetch(context.Background(), server.URL+"/picture"); !errors.Is(err, ErrUnsupported) {
+		t.Fatalf("image error = %v", err)
+	}
+	if _, err := fetcher.Fetch(context.Background(), server.URL+"/missing"); !errors.Is(err, ErrFetch) {
+		t.Fatalf("missing error = %v", err)
+	}
+	if _, err := New(false).Fetch(context.Background(), server.URL+"/story.md"); !errors.Is(err, ErrRefused) {
+		t.Fatalf("loopback error = %v, want ErrRefused", err)
+	}
+}
+
+func TestDownloadURLExportsGoogleDocs(t *testing.T) {
+	for raw, want := range map[string]string{
+		"https://docs.google.com/document/d/1AbC-d_9/edit?usp=sharing": "https://docs.google.com/document/d/1AbC-d_9/export?format=docx",
+		"https://docs.google.com/document/u/0/d/1AbC/view":             "https://docs.google.com/document/d/1AbC/export?format=docx",
+		"https://docs.google.com/spreadsheets/d/1AbC/edit":             "https://docs.google.com/spreadsheets/d/1AbC/edit",
+		"https://example.com/document/d/1AbC/edit":                     "https://example.com/document/d/1AbC/edit",
+	} {
+		if got := downloadURL(raw); got != want {
+			t.Errorf("downloadURL(%q) = %q, want %q", raw, got, want)
+		}
+	}
+	for raw, want := range map[string]bool{
+		"https://example.com/story.md": true,
+		"ftp://example.com/story.md":   false,
+		"https://user:pw@example.com/": false,
+		"/story.md":                    false,
+	} {
+		if ValidURL(raw) != want {
+			t.Errorf("ValidURL(%q) = %v", raw, !want)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package webhooks
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"net/netip"
+	"net/url"
+	"strings"
+	"time"
+
+	"pandapages/api/internal/model"
+	"pandapages/api/internal/netguard"
+)
+
+// The helpers below are shared by everything that manages subscriptions or
+// raises events: the admin routes validate and mint webhooks with them, and
+// the Store builds every queued payload with them, whether the change came
+// through the reader API or the admin API.
+
+const (
+	// SecretPrefix marks webhook signing secrets so they are recognisable
+	// when pasted into a receiver's configuration or found in a leak.
+	SecretPrefix = "whsec_"
+	MaxURLBytes  = 2048
+)
+
+// ValidURL reports whether raw is an absolute http or https URL a webhook may
+// deliver to. Credentials and fragments are refused, as receivers never see
+// the latter and the former would be stored beside the secret. So is a host
+// that names the server's own network outright, such as localhost or a
+// private address; a name that only resolves to one is refused when the
+// Dispatcher dials it.
+func ValidURL(raw string) bool {
+	if raw == "" || len(raw) > MaxURLBytes {
+		return false
+	}
+	parsed, err := url.Parse(raw)
+	if err != nil || parsed.Host == "" || parsed.User != nil || parsed.Fragment != "" {
+		return false
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return false
+	}
+	host := strings.ToLower(strings.TrimSuffix(parsed.Hostname(), "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return false
+	}
+	if ip, err := netip.ParseAddr(host); err == nil && !netguard.Public(ip) {
+		return false
+	}
+	return true
+}
+
+// ValidEvents reports whether events names at least one event and only known
+// ones.
+func ValidEvents(events []model.WebhookEvent) bool {
+	if len(events) == 0 {
+		return false
+	}
+	for _, event := range events {
+		if !event.Valid() {
+			return false
+		}
+	}
+	return true
+}
+
+// NewSecret returns a fresh signing secret.
+func NewSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return SecretPrefix + base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// StoryPayload describes a story's publication state after event.
+func StoryPayload(event model.WebhookEvent, at time.Time, story model.AdminStoryStatusResponse) model.WebhookPayload {
+	return model.WebhookPayload{
+		Event:      event,
+		OccurredAt: occurredAt(at),
+		Story: &model.WebhookStoryState{
+			Slug:             story.Slug,
+			Status:           story.Status,
+			PublishedVersion: story.PublishedVersion,
+		},
+	}
+}
+
+// ProgressCompletedPayload describes a story read to the end.
+func ProgressCompletedPayload(at time.Time, slug string, version int, percent float64) model.WebhookPayload {
+	return model.WebhookPayload{
+		Event:      model.WebhookEventProgressCompleted,
+		OccurredAt: occurredAt(at),
+		Progress:   &model.WebhookProgressState{Slug: slug, Version: version, Percent: percent},
+	}
+}
+
+// ChildUpdatedPayload names a child profile that was created or changed.
+func ChildUpdatedPayload(at time.Time, childID string) model.WebhookPayload {
+	return model.WebhookPayload{
+		Event:      model.WebhookEventChildUpdated,
+		OccurredAt: occurredAt(at),
+		Child:      &model.WebhookChildState{ID: childID},
+	}
+}
+
+func occurredAt(at time.Time) string {
+	return at.UTC().Format(time.RFC3339Nano)
+}
@@ -0,0 +1,168 @@
+// Package webhooks delivers queued webhook events. Deliveries are written by
+// the Store in the same transaction as the change they describe; a Dispatcher
+// claims due rows, POSTs them with an HMAC signature, and records each attempt
+// so failures retry with backoff until the schedule is exhausted.
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"pandapages/api/internal/model"
+	"pandapages/api/internal/netguard"
+)
+
+const (
+	EventHeader     = "X-PP-Event"
+	DeliveryHeader  = "X-PP-Delivery"
+	TimestampHeader = "X-PP-Timestamp"
+	// SignatureHeader carries "v1=" and the hex HMAC-SHA256 of
+	// "<timestamp>.<body>" keyed by the webhook secret.
+	SignatureHeader = "X-PP-Signature"
+
+	defaultInterval = 5 * time.Second
+	requestTimeout  = 10 * time.Second
+	claimLease      = time.Minute
+	claimBatch      = 20
+)
+
+// retryDelays is the wait after each failed attempt. A delivery is marked
+// failed once every delay has been used.
+var retryDelays = []time.Duration{
+	30 * time.Second,
+	2 * time.Minute,
+	10 * time.Minute,
+	30 * time.Minute,
+	2 * time.Hour,
+}
+
+type Store interface {
+	WebhookClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]model.PendingWebhookDelivery, error)
+	WebhookRecordAttempt(ctx context.Context, deliveryID string, attempt model.WebhookAttempt) error
+}
+
+type Dispatcher struct {
+	store    Store
+	client   *http.Client
+	interval time.Duration
+	now      func() time.Time
+}
+
+func NewDispatcher(store Store) *Dispatcher {
+	return &Dispatcher{
+		store:    store,
+		client:   newHTTPClient(),
+		interval: defaultInterval,
+		now:      time.Now,
+	}
+}
+
+// newHTTPClient reaches public addresses only: a webhook URL is whatever an
+// editor typed, and a name in it may resolve to the server's own network.
+func newHTTPClient() *http.Client {
+	dialer := &net.Dialer{Timeout: requestTimeout, Control: netguard.PublicOnly}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// No proxy: the address check must see the real destination.
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{
+		Timeout:   requestTimeout,
+		Transport: transport,
+		// A redirect is reported as the receiver's answer, not followed.
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// Sign returns the SignatureHeader value for body sent at timestamp.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Run delivers due events until ctx is cancelled.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+	for {
+		d.RunOnce(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce claims one batch of due deliveries and attempts each of them.
+func (d *Dispatcher) RunOnce(ctx context.Context) {
+	deliveries, err := d.store.WebhookClaimDeliveries(ctx, claimBatch, claimLease)
+	if err != nil {
+		slog.Error("webhook delivery claim failed")
+		return
+	}
+	for _, delivery := range deliveries {
+		if ctx.Err() != nil {
+			// Unattempted claims become due again when their lease expires.
+			return
+		}
+		attempt := d.deliver(ctx, delivery)
+		// A sent event is recorded even during shutdown, or it would be sent
+		// again once its lease expires.
+		if err := d.store.WebhookRecordAttempt(context.WithoutCancel(ctx), delivery.ID, attempt); err != nil {
+			slog.Error("webhook attempt record failed")
+		}
+	}
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, delivery model.PendingWebhookDelivery) model.WebhookAttempt {
+	now := d.now()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return d.failed(delivery, now, 0, "request_invalid")
+	}
+	timestamp := now.Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "pandapages-webhooks/1")
+	req.Header.Set(EventHeader, string(delivery.Event))
+	req.Header.Set(DeliveryHeader, delivery.ID)
+	req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(SignatureHeader, Sign(delivery.Secret, timestamp, delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if errors.Is(err, netguard.ErrRefused) {
+		return d.failed(delivery, now, 0, "address_refused")
+	}
+	if err != nil {
+		// Transport errors can embed the receiver URL and resolver detail;
+		// the log keeps a fixed category instead.
+		return d.failed(delivery, now, 0, "request_failed")
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return d.failed(delivery, now, resp.StatusCode, "unexpected_status")
+	}
+	return model.WebhookAttempt{Succeeded: true, StatusCode: resp.StatusCode}
+}
+
+func (d *Dispatcher) failed(delivery model.PendingWebhookDelivery, now time.Time, statusCode int, reason string) model.WebhookAttempt {
+	attempt := model.WebhookAttempt{StatusCode: statusCode, Error: reason}
+	if delivery.Attempts < len(retryDelays) {
+		next := now.Add(retryDelays[delivery.Attempts])
+		attempt.NextAttemptAt = &next
+	}
+	return attempt
+}
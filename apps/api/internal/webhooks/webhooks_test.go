@@ -0,0 +1,196 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"pandapages/api/internal/model"
+)
+
+type fakeStore struct {
+	pending  []model.PendingWebhookDelivery
+	attempts map[string]model.WebhookAttempt
+}
+
+func (s *fakeStore) WebhookClaimDeliveries(_ context.Context, limit int, _ time.Duration) ([]model.PendingWebhookDelivery, error) {
+	claimed := s.pending
+	s.pending = nil
+	return claimed, nil
+}
+
+func (s *fakeStore) WebhookRecordAttempt(_ context.Context, id string, attempt model.WebhookAttempt) error {
+	if s.attempts == nil {
+		s.attempts = map[string]model.WebhookAttempt{}
+	}
+	s.attempts[id] = attempt
+	return nil
+}
+
+func testDispatcher(store Store, now time.Time) *Dispatcher {
+	d := NewDispatcher(store)
+	// Test receivers listen on loopback, which the real client refuses.
+	d.client.Transport = http.DefaultTransport
+	d.now = func() time.Time { return now }
+	return d
+}
+
+func TestDispatcherSignsDeliveries(t *testing.T) {
+	now := time.Date(2026, 7, 12, 12, 0, 0, 0, time.UTC)
+	payload := []byte(`{"event":"story.published"}`)
+	var got *http.Request
+	var gotBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		gotBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	store := &fakeStore{pending: []model.PendingWebhookDelivery{{
+		ID: "delivery-1", URL: server.URL, Secret: "secret", Event: model.WebhookEventStoryPublished, Payload: payload,
+	}}}
+	testDispatcher(store, now).RunOnce(context.Background())
+
+	if got == nil || string(gotBody) != string(payload) {
+		t.Fatalf("request = %v, body = %s", got, gotBody)
+	}
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	if got.Header.Get(TimestampHeader) != timestamp || got.Header.Get(EventHeader) != "story.published" ||
+		got.Header.Get(DeliveryHeader) != "delivery-1" {
+		t.Fatalf("headers = %v", got.Header)
+	}
+	if got.Header.Get(SignatureHeader) != Sign("secret", now.Unix(), payload) {
+		t.Fatalf("signature = %q", got.Header.Get(SignatureHeader))
+	}
+	if attempt := store.attempts["delivery-1"]; !attempt.Succeeded || attempt.StatusCode != http.StatusNoContent {
+		t.Fatalf("attempt = %#v", attempt)
+	}
+}
+
+func TestSignMatchesKnownVector(t *testing.T) {
+	// printf '1700000000.{}' | openssl dgst -sha256 -hmac secret
+	want := "v1=b8569b78799ff9e3cbff0fc2d63a33a2b57f3282abd07c37ae5e8e7d79a5f163"
+	if got := Sign("secret", 1700000000, []byte("{}")); got != want {
+		t.Fatalf("Sign = %q, want %q", got, want)
+	}
+}
+
+func TestDispatcherSchedulesRetriesUntilExhausted(t *testing.T) {
+	now := time.Date(2026, 7, 12, 12, 0, 0, 0, time.UTC)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	store := &fakeStore{pending: []model.PendingWebhookDelivery{
+		{ID: "first", URL: server.URL, Secret: "secret", Payload: []byte(`{}`)},
+		{ID: "last", URL: server.URL, Secret: "secret", Payload: []byte(`{}`), Attempts: len(retryDelays)},
+	}}
+	testDispatcher(store, now).RunOnce(context.Background())
+
+	first := store.attempts["first"]
+	if first.Succeeded || first.StatusCode != http.StatusBadGateway || first.NextAttemptAt == nil ||
+		!first.NextAttemptAt.Equal(now.Add(retryDelays[0])) {
+		t.Fatalf("first attempt = %#v", first)
+	}
+	if last := store.attempts["last"]; last.Succeeded || last.NextAttemptAt != nil {
+		t.Fatalf("exhausted attempt = %#v", last)
+	}
+}
+
+func TestDispatcherDoesNotFollowRedirectsOrRecordTransportDetail(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/elsewhere", http.StatusFound)
+	}))
+	store := &fakeStore{pending: []model.PendingWebhookDelivery{
+		{ID: "redirect", URL: server.URL, Secret: "secret", Payload: []byte(`{}`)},
+		{ID: "closed", URL: "http://127.0.0.1:1/hook", Secret: "secret", Payload: []byte(`{}`)},
+	}}
+	testDispatcher(store, time.Now()).RunOnce(context.Background())
+	server.Close()
+
+	if attempt := store.attempts["redirect"]; attempt.StatusCode != http.StatusFound || attempt.Error != "unexpected_status" {
+		t.Fatalf("redirect attempt = %#v", attempt)
+	}
+	if attempt := store.attempts["closed"]; attempt.StatusCode != 0 || attempt.Error != "request_failed" {
+		t.Fatalf("transport attempt = %#v", attempt)
+	}
+}
+
+func TestDispatcherRefusesPrivateReceivers(t *testing.T) {
+	reached := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reached = true
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	store := &fakeStore{pending: []model.PendingWebhookDelivery{
+		{ID: "loopback", URL: server.URL, Secret: "secret", Payload: []byte(`{}`)},
+	}}
+	d := NewDispatcher(store)
+	d.now = time.Now
+	d.RunOnce(context.Background())
+
+	if attempt := store.attempts["loopback"]; reached || attempt.Succeeded || attempt.Error != "address_refused" || attempt.NextAttemptAt == nil {
+		t.Fatalf("loopback attempt = %#v, reached = %t", attempt, reached)
+	}
+}
+
+func TestValidURLRefusesThisServersNetwork(t *testing.T) {
+	for raw, valid := range map[string]bool{
+		"https://hooks.example.test/pandapages": true,
+		"http://93.184.216.34/hook":             true,
+		"http://localhost/hook":                 false,
+		"http://LOCALHOST./hook":                false,
+		"http://api.localhost/hook":             false,
+		"http://127.0.0.1:8080/hook":            false,
+		"http://[::1]/hook":                     false,
+		"http://[::ffff:10.0.0.1]/hook":         false,
+		"http://192.168.1.20/hook":              false,
+		"http://169.254.169.254/latest":         false,
+		"http://0.0.0.0/hook":                   false,
+	} {
+		if got := ValidURL(raw); got != valid {
+			t.Errorf("ValidURL(%q) = %t, want %t", raw, got, valid)
+		}
+	}
+}
+
+func TestPayloadsCarryOnlyTheirEventsSubject(t *testing.T) {
+	at := time.Date(2026, 7, 12, 12, 0, 0, 0, time.FixedZone("BST", 3600))
+	for _, tc := range []struct {
+		payload model.WebhookPayload
+		want    string
+	}{
+		{
+			payload: StoryPayload(model.WebhookEventStoryUnpublished, at, model.AdminStoryStatusResponse{Slug: "fox", Status: "draft"}),
+			want:    `{"event":"story.unpublished","occurredAt":"2026-07-12T11:00:00Z","story":{"slug":"fox","status":"draft","publishedVersion":null}}`,
+		},
+		{
+			payload: ProgressCompletedPayload(at, "fox", 3, 1),
+			want:    `{"event":"progress.completed","occurredAt":"2026-07-12T11:00:00Z","progress":{"slug":"fox","version":3,"percent":1}}`,
+		},
+		{
+			payload: ChildUpdatedPayload(at, "child-id"),
+			want:    `{"event":"child.updated","occurredAt":"2026-07-12T11:00:00Z","child":{"id":"child-id"}}`,
+		},
+	} {
+		got, err := json.Marshal(tc.payload)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != tc.want {
+			t.Errorf("payload = %s, want %s", got, tc.want)
+		}
+		if !tc.payload.Event.Valid() {
+			t.Errorf("%s is not a subscribable event", tc.payload.Event)
+		}
+	}
+}
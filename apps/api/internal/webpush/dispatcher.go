@@ -0,0 +1,162 @@
+package webpush
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"time"
+
+	"pandapages/api/internal/model"
+)
+
+const (
+	defaultInterval = 30 * time.Second
+	claimLease      = 2 * time.Minute
+	claimBatch      = 20
+
+	// reminderTTL is how long a reminder stays worth showing: one that
+	// reaches a browser after bedtime is noise.
+	reminderTTL = time.Hour
+	storyTTL    = 24 * time.Hour
+)
+
+// retryDelays is the wait after each failed send. A notification is dropped
+// once every delay has been used.
+var retryDelays = []time.Duration{
+	time.Minute,
+	5 * time.Minute,
+	30 * time.Minute,
+}
+
+type Store interface {
+	PushQueueReminders(ctx context.Context) (int, error)
+	PushClaim(ctx context.Context, limit int, lease time.Duration) ([]model.PendingPush, error)
+	PushRecordAttempt(ctx context.Context, push model.PendingPush, attempt model.PushAttempt) error
+	ReaderStory(ctx context.Context, accountID, slug string) (model.ReaderStory, error)
+}
+
+// Pusher sends one encrypted message; *Sender is the production
+// implementation.
+type Pusher interface {
+	Send(ctx context.Context, endpoint string, p256dh, auth, payload []byte, ttl time.Duration) error
+}
+
+type Dispatcher struct {
+	store    Store
+	pusher   Pusher
+	interval time.Duration
+	now      func() time.Time
+}
+
+func NewDispatcher(store Store, pusher Pusher) *Dispatcher {
+	return &Dispatcher{store: store, pusher: pusher, interval: defaultInterval, now: time.Now}
+}
+
+// Run queues reminders and sends due notifications until ctx is cancelled.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+	for {
+		d.RunOnce(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce queues the reminders now due and sends one batch of
+// notifications.
+func (d *Dispatcher) RunOnce(ctx context.Context) {
+	if _, err := d.store.PushQueueReminders(ctx); err != nil {
+		slog.Error("push reminder queue failed")
+	}
+	pushes, err := d.store.PushClaim(ctx, claimBatch, claimLease)
+	if err != nil {
+		slog.Error("push claim failed")
+		return
+	}
+	for _, push := range pushes {
+		if ctx.Err() != nil {
+			// Unattempted claims become due again when their lease expires.
+			return
+		}
+		attempt := d.send(ctx, push)
+		if err := d.store.PushRecordAttempt(context.WithoutCancel(ctx), push, attempt); err != nil {
+			slog.Error("push record failed")
+		}
+	}
+}
+
+func (d *Dispatcher) send(ctx context.Context, push model.PendingPush) model.PushAttempt {
+	now := d.now()
+	notification, ttl, ok, err := d.notification(ctx, push)
+	if err != nil {
+		return d.failed(push, now)
+	}
+	if !ok {
+		return model.PushAttempt{}
+	}
+	payload, err := json.Marshal(notification)
+	if err != nil {
+		return model.PushAttempt{}
+	}
+	err = d.pusher.Send(ctx, push.Endpoint, push.P256DH, push.Auth, payload, ttl)
+	switch {
+	case err == nil:
+		return model.PushAttempt{Sent: true}
+	case errors.Is(err, ErrGone):
+		return model.PushAttempt{Gone: true}
+	}
+	// Push service errors can carry the endpoint, which identifies the
+	// browser; the log keeps a fixed message instead.
+	slog.Warn("push send failed", "push_id", push.ID, "kind", push.Kind)
+	return d.failed(push, now)
+}
+
+// notification builds what the browser shows. ok is false when there is
+// nothing left worth sending.
+func (d *Dispatcher) notification(ctx context.Context, push model.PendingPush) (model.PushNotification, time.Duration, bool, error) {
+	switch push.Kind {
+	case model.PushKindReminder:
+		if d.now().Sub(push.CreatedAt) > reminderTTL {
+			return model.PushNotification{}, 0, false, nil
+		}
+		return model.PushNotification{
+			Kind:  model.PushKindReminder,
+			Title: "Time for a bedtime story",
+			Body:  "Pick up where you left off, or find something new in the Library.",
+			URL:   "/library",
+			Tag:   "reminder",
+		}, reminderTTL, true, nil
+	case model.PushKindStory:
+		story, err := d.store.ReaderStory(ctx, push.AccountID, push.Slug)
+		if errors.Is(err, sql.ErrNoRows) || errors.Is(err, model.ErrStoryRemoved) {
+			// Unpublished since it was announced; there is nothing to show.
+			return model.PushNotification{}, 0, false, nil
+		}
+		if err != nil {
+			return model.PushNotification{}, 0, false, err
+		}
+		return model.PushNotification{
+			Kind:  model.PushKindStory,
+			Title: "A new story: " + story.Title,
+			Body:  "It is waiting in your Library.",
+			URL:   "/read/" + story.Slug,
+			Tag:   "story-" + story.Slug,
+		}, storyTTL, true, nil
+	}
+	return model.PushNotification{}, 0, false, nil
+}
+
+func (d *Dispatcher) failed(push model.PendingPush, now time.Time) model.PushAttempt {
+	var attempt model.PushAttempt
+	if push.Attempts < len(retryDelays) {
+		next := now.Add(retryDelays[push.Attempts])
+		attempt.NextAttemptAt = &next
+	}
+	return attempt
+}
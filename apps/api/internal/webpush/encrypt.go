@@ -0,0 +1,123 @@
+package webpush
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+const (
+	authSecretBytes = 16
+	saltBytes       = 16
+	// recordSize is the aes128gcm record size; a message is one record.
+	recordSize = 4096
+	// vapidLifetime is how long a signed token is valid. Services accept at
+	// most 24 hours.
+	vapidLifetime = 12 * time.Hour
+)
+
+func validPoint(point []byte) bool {
+	_, err := ecdh.P256().NewPublicKey(point)
+	return err == nil
+}
+
+// encrypt makes an aes128gcm message body for a browser, as RFC 8291 sets
+// out: a fresh key pair agrees a secret with the browser's p256dh key, which
+// with its auth secret and a random salt derives the content key.
+func encrypt(payload, p256dh, auth []byte) ([]byte, error) {
+	browser, err := ecdh.P256().NewPublicKey(p256dh)
+	if err != nil {
+		return nil, fmt.Errorf("subscription key is invalid")
+	}
+	if len(auth) != authSecretBytes {
+		return nil, fmt.Errorf("subscription auth secret is invalid")
+	}
+	local, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	shared, err := local.ECDH(browser)
+	if err != nil {
+		return nil, err
+	}
+	localPublic := local.PublicKey().Bytes()
+
+	keyInfo := "WebPush: info\x00" + string(p256dh) + string(localPublic)
+	ikm, err := hkdf.Key(sha256.New, shared, auth, keyInfo, 32)
+	if err != nil {
+		return nil, err
+	}
+	salt := make([]byte, saltBytes)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	prk, err := hkdf.Extract(sha256.New, ikm, salt)
+	if err != nil {
+		return nil, err
+	}
+	contentKey, err := hkdf.Expand(sha256.New, prk, "Content-Encoding: aes128gcm\x00", 16)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := hkdf.Expand(sha256.New, prk, "Content-Encoding: nonce\x00", 12)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(contentKey)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	// The header is the salt, record size and the sender's public key; 0x02
+	// pads the only record as the last.
+	out := append([]byte{}, salt...)
+	out = binary.BigEndian.AppendUint32(out, recordSize)
+	out = append(out, byte(len(localPublic)))
+	out = append(out, localPublic...)
+	plaintext := append(append([]byte{}, payload...), 0x02)
+	return gcm.Seal(out, nonce, plaintext, nil), nil
+}
+
+// vapidToken signs an ES256 JWT for the endpoint's push service.
+func (s *Sender) vapidToken(endpoint string) (string, error) {
+	parsed, err := url.Parse(endpoint)
+	if err != nil || parsed.Host == "" {
+		return "", fmt.Errorf("push endpoint is invalid")
+	}
+	header, err := json.Marshal(map[string]string{"typ": "JWT", "alg": "ES256"})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]any{
+		"aud": parsed.Scheme + "://" + parsed.Host,
+		"exp": s.now().Add(vapidLifetime).Unix(),
+		"sub": s.subject,
+	})
+	if err != nil {
+		return "", err
+	}
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(signed))
+	r, sig, err := ecdsa.Sign(rand.Reader, s.key, digest[:])
+	if err != nil {
+		return "", err
+	}
+	signature := make([]byte, 64)
+	r.FillBytes(signature[:32])
+	sig.FillBytes(signature[32:])
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
@@ -0,0 +1,207 @@
+// Package webpush sends notifications to readers' browsers with the Web Push
+// protocol: each message is encrypted to the browser's keys (RFC 8291) and
+// posted to its push service, signed with the server's VAPID key (RFC 8292).
+// The key pair is made on first start and kept in the database, so every
+// instance signs with the same one. A Dispatcher queues the day's bedtime
+// reminders and sends queued notifications, as the delivery dispatcher
+// sends books.
+package webpush
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"pandapages/api/internal/model"
+	"pandapages/api/internal/netguard"
+)
+
+const (
+	// requestTimeout bounds one push service request; they answer at once.
+	requestTimeout = 30 * time.Second
+	// maxPayloadBytes keeps a message, once encrypted, within the 4096 bytes
+	// every push service accepts.
+	maxPayloadBytes = 3000
+)
+
+var (
+	// ErrGone marks a subscription the push service no longer knows, as
+	// after the reader revoked permission.
+	ErrGone = errors.New("push subscription is gone")
+	// ErrPushService marks a push service that refused a message. Its message
+	// never carries the service's response.
+	ErrPushService = errors.New("push service failed")
+)
+
+// KeyStore keeps the VAPID key.
+type KeyStore interface {
+	PushVAPIDKey(ctx context.Context, candidate []byte) ([]byte, error)
+}
+
+// Load reads PP_PUSH_SUBJECT, the mailto: or https: address push services
+// can reach the operator at. Push is off, and Load returns "", when it is
+// unset.
+func Load(getenv func(string) string) (string, error) {
+	subject := strings.TrimSpace(getenv("PP_PUSH_SUBJECT"))
+	if subject == "" {
+		return "", nil
+	}
+	if !validSubject(subject) {
+		return "", fmt.Errorf("PP_PUSH_SUBJECT must be a mailto: or https: address")
+	}
+	return subject, nil
+}
+
+func validSubject(subject string) bool {
+	if address, ok := strings.CutPrefix(subject, "mailto:"); ok {
+		parsed, err := mail.ParseAddress(address)
+		return err == nil && parsed.Address == address
+	}
+	parsed, err := url.Parse(subject)
+	return err == nil && parsed.Scheme == "https" && parsed.Host != "" && parsed.User == nil
+}
+
+// Sender signs and sends push messages.
+type Sender struct {
+	key     *ecdsa.PrivateKey
+	public  []byte
+	subject string
+	client  *http.Client
+	now     func() time.Time
+}
+
+// New loads the server's VAPID key from store, making it if there is none.
+func New(ctx context.Context, store KeyStore, subject string) (*Sender, error) {
+	candidate, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := candidate.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	stored, err := store.PushVAPIDKey(ctx, raw)
+	if err != nil {
+		return nil, fmt.Errorf("load VAPID key: %w", err)
+	}
+	key, err := ecdsa.ParseRawPrivateKey(elliptic.P256(), stored)
+	if err != nil {
+		return nil, fmt.Errorf("stored VAPID key is invalid")
+	}
+	return newSender(key, subject, newHTTPClient())
+}
+
+func newSender(key *ecdsa.PrivateKey, subject string, client *http.Client) (*Sender, error) {
+	public, err := key.PublicKey.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	return &Sender{key: key, public: public, subject: subject, client: client, now: time.Now}, nil
+}
+
+// newHTTPClient reaches public addresses only and never follows redirects:
+// an endpoint is whatever a browser, or anyone unlocking the account, sent.
+func newHTTPClient() *http.Client {
+	dialer := &net.Dialer{Timeout: 10 * time.Second, Control: netguard.PublicOnly}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{
+		Timeout:   requestTimeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// PublicKey is the VAPID public key browsers subscribe with, base64url
+// encoded.
+func (s *Sender) PublicKey() string {
+	return base64.RawURLEncoding.EncodeToString(s.public)
+}
+
+// Send encrypts payload to a browser's keys and posts it to the browser's
+// push service, which keeps it for up to ttl while the browser is offline.
+func (s *Sender) Send(ctx context.Context, endpoint string, p256dh, auth, payload []byte, ttl time.Duration) error {
+	if len(payload) > maxPayloadBytes {
+		return fmt.Errorf("push payload exceeds %d bytes", maxPayloadBytes)
+	}
+	body, err := encrypt(payload, p256dh, auth)
+	if err != nil {
+		return err
+	}
+	token, err := s.vapidToken(endpoint)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("TTL", strconv.Itoa(int(ttl.Seconds())))
+	req.Header.Set("Urgency", "normal")
+	req.Header.Set("Authorization", "vapid t="+token+", k="+s.PublicKey())
+	req.Header.Set("User-Agent", "pandapages-push/1")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		// Transport errors embed the endpoint, which identifies the browser.
+		return fmt.Errorf("%w: request failed", ErrPushService)
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return ErrGone
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		return fmt.Errorf("%w: status %d", ErrPushService, resp.StatusCode)
+	}
+	return nil
+}
+
+// ValidEndpoint reports whether raw is an https URL a subscription may send
+// to. Credentials and fragments are refused.
+func ValidEndpoint(raw string) bool {
+	if raw == "" || len(raw) > model.MaxPushEndpointBytes {
+		return false
+	}
+	parsed, err := url.Parse(raw)
+	if err != nil || parsed.Host == "" || parsed.User != nil || parsed.Fragment != "" {
+		return false
+	}
+	return parsed.Scheme == "https"
+}
+
+// DecodeKeys decodes and checks a browser's subscription keys: p256dh must
+// be a P-256 point and auth 16 bytes.
+func DecodeKeys(keys model.PushSubscriptionKeys) (p256dh, auth []byte, ok bool) {
+	p256dh, err := decodeBase64URL(keys.P256DH)
+	if err != nil || !validPoint(p256dh) {
+		return nil, nil, false
+	}
+	auth, err = decodeBase64URL(keys.Auth)
+	if err != nil || len(auth) != authSecretBytes {
+		return nil, nil, false
+	}
+	return p256dh, auth, true
+}
+
+// decodeBase64URL accepts base64url with or without padding, as browsers
+// differ.
+func decodeBase64URL(value string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(strings.TrimSpace(value), "="))
+}
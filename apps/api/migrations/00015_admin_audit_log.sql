@@ -0,0 +1,33 @@
+-- +goose Up
+BEGIN;
+
+-- Append-only record of admin mutations. Rows are account-scoped and keep the
+-- story slug as text so the trail survives later story deletion or renames.
+CREATE TABLE admin_audit_log (
+  id         UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  account_id UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+  actor      TEXT NOT NULL,
+  action     TEXT NOT NULL,
+  story_slug TEXT,
+  summary    JSONB NOT NULL DEFAULT '{}'::jsonb,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT admin_audit_log_actor_check CHECK (btrim(actor) <> ''),
+  CONSTRAINT admin_audit_log_action_check CHECK (action ~ '^[a-z][a-z_.]*$'),
+  CONSTRAINT admin_audit_log_summary_check CHECK (jsonb_typeof(summary) = 'object')
+);
+
+CREATE INDEX admin_audit_log_account_created_idx
+  ON admin_audit_log (account_id, created_at DESC, id DESC);
+
+CREATE INDEX admin_audit_log_account_slug_idx
+  ON admin_audit_log (account_id, story_slug, created_at DESC)
+  WHERE story_slug IS NOT NULL;
+
+COMMIT;
+
+-- +goose Down
+BEGIN;
+
+DROP TABLE IF EXISTS admin_audit_log;
+
+COMMIT;
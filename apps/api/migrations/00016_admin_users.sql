@@ -0,0 +1,32 @@
+-- +goose Up
+BEGIN;
+
+-- Individually attributable admin credentials. Only a SHA-256 digest of each
+-- high-entropy key is stored; the key itself is shown once at creation time.
+CREATE TABLE admin_users (
+  id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  account_id  UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+  name        TEXT NOT NULL,
+  key_hash    TEXT NOT NULL,
+  roles       TEXT[] NOT NULL,
+  created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+  disabled_at TIMESTAMPTZ,
+  CONSTRAINT admin_users_name_check CHECK (btrim(name) <> '' AND char_length(name) <= 80),
+  CONSTRAINT admin_users_key_hash_check CHECK (key_hash ~ '^[0-9a-f]{64}$'),
+  CONSTRAINT admin_users_roles_check CHECK (
+    cardinality(roles) >= 1
+    AND roles <@ ARRAY['importer', 'editor', 'publisher']::text[]
+  )
+);
+
+CREATE UNIQUE INDEX admin_users_key_hash_key ON admin_users (key_hash);
+CREATE UNIQUE INDEX admin_users_account_name_key ON admin_users (account_id, lower(name));
+
+COMMIT;
+
+-- +goose Down
+BEGIN;
+
+DROP TABLE IF EXISTS admin_users;
+
+COMMIT;
@@ -0,0 +1,33 @@
+-- +goose Up
+BEGIN;
+
+-- Curated, account-scoped tags. Story links are mutable catalogue metadata and
+-- deliberately live outside immutable story versions and their frontmatter.
+CREATE TABLE tags (
+  id         UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  account_id UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+  name       TEXT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT tags_name_check CHECK (name = btrim(name) AND name <> '' AND char_length(name) <= 64)
+);
+
+CREATE UNIQUE INDEX tags_account_name_key ON tags (account_id, lower(name));
+
+CREATE TABLE story_tags (
+  story_id   UUID NOT NULL REFERENCES stories(id) ON DELETE CASCADE,
+  tag_id     UUID NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (story_id, tag_id)
+);
+
+CREATE INDEX story_tags_tag_id_idx ON story_tags (tag_id);
+
+COMMIT;
+
+-- +goose Down
+BEGIN;
+
+DROP TABLE IF EXISTS story_tags;
+DROP TABLE IF EXISTS tags;
+
+COMMIT;
@@ -0,0 +1,18 @@
+-- +goose Up
+BEGIN;
+
+-- The application has only ever written 'author'; the admin contributor API
+-- now assigns the remaining credited roles.
+ALTER TABLE story_contributors
+  ADD CONSTRAINT story_contributors_role_check
+  CHECK (role IN ('author', 'reteller', 'translator', 'illustrator', 'editor'));
+
+COMMIT;
+
+-- +goose Down
+BEGIN;
+
+ALTER TABLE story_contributors
+  DROP CONSTRAINT IF EXISTS story_contributors_role_check;
+
+COMMIT;
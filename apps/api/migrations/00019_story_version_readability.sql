@@ -0,0 +1,20 @@
+-- +goose Up
+BEGIN;
+
+-- Readability metrics are computed at ingestion. Versions created before this
+-- migration keep NULL; their metrics appear once the story is re-imported.
+ALTER TABLE story_versions
+  ADD COLUMN readability JSONB,
+  ADD CONSTRAINT story_versions_readability_check
+    CHECK (readability IS NULL OR jsonb_typeof(readability) = 'object');
+
+COMMIT;
+
+-- +goose Down
+BEGIN;
+
+ALTER TABLE story_versions
+  DROP CONSTRAINT IF EXISTS story_versions_readability_check,
+  DROP COLUMN IF EXISTS readability;
+
+COMMIT;
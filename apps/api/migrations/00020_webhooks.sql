@@ -0,0 +1,59 @@
+-- +goose Up
+BEGIN;
+
+-- Account-scoped webhook endpoints. The secret signs every delivery, so it is
+-- stored as issued and only ever returned by the create response.
+CREATE TABLE webhooks (
+  id         UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  account_id UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+  url        TEXT NOT NULL,
+  secret     TEXT NOT NULL,
+  events     TEXT[] NOT NULL,
+  active     BOOLEAN NOT NULL DEFAULT true,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT webhooks_url_check CHECK (url ~ '^https?://' AND char_length(url) <= 2048),
+  CONSTRAINT webhooks_secret_check CHECK (char_length(secret) >= 32),
+  CONSTRAINT webhooks_events_check CHECK (
+    cardinality(events) >= 1
+    AND events <@ ARRAY['story.published', 'story.unpublished']::text[]
+  )
+);
+
+CREATE INDEX webhooks_account_idx ON webhooks (account_id, created_at);
+
+-- One row per event per webhook. Rows are written in the same transaction as
+-- the change they describe and double as the delivery log.
+CREATE TABLE webhook_deliveries (
+  id               UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  webhook_id       UUID NOT NULL REFERENCES webhooks(id) ON DELETE CASCADE,
+  event            TEXT NOT NULL,
+  payload          JSONB NOT NULL,
+  status           TEXT NOT NULL DEFAULT 'pending',
+  attempts         INTEGER NOT NULL DEFAULT 0,
+  last_status_code INTEGER,
+  last_error       TEXT,
+  next_attempt_at  TIMESTAMPTZ,
+  last_attempt_at  TIMESTAMPTZ,
+  created_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT webhook_deliveries_status_check CHECK (status IN ('pending', 'succeeded', 'failed')),
+  CONSTRAINT webhook_deliveries_attempts_check CHECK (attempts >= 0),
+  CONSTRAINT webhook_deliveries_pending_check CHECK ((status = 'pending') = (next_attempt_at IS NOT NULL))
+);
+
+CREATE INDEX webhook_deliveries_webhook_created_idx
+  ON webhook_deliveries (webhook_id, created_at DESC, id DESC);
+
+CREATE INDEX webhook_deliveries_due_idx
+  ON webhook_deliveries (next_attempt_at)
+  WHERE status = 'pending';
+
+COMMIT;
+
+-- +goose Down
+BEGIN;
+
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhooks;
+
+COMMIT;
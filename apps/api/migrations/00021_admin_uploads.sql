@@ -0,0 +1,39 @@
+-- +goose Up
+BEGIN;
+
+-- Resumable admin uploads. Chunks are appended strictly in order and the
+-- assembled body is ingested, then deleted, on finalize. Abandoned uploads
+-- expire and are swept when new uploads start.
+CREATE TABLE admin_uploads (
+  id             UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  account_id     UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+  kind           TEXT NOT NULL,
+  total_bytes    BIGINT NOT NULL,
+  received_bytes BIGINT NOT NULL DEFAULT 0,
+  sha256         TEXT,
+  created_at     TIMESTAMPTZ NOT NULL DEFAULT now(),
+  expires_at     TIMESTAMPTZ NOT NULL,
+  CONSTRAINT admin_uploads_kind_check CHECK (kind IN ('draft', 'bundle')),
+  CONSTRAINT admin_uploads_bytes_check CHECK (total_bytes > 0 AND received_bytes BETWEEN 0 AND total_bytes),
+  CONSTRAINT admin_uploads_sha256_check CHECK (sha256 IS NULL OR sha256 ~ '^[0-9a-f]{64}$')
+);
+
+CREATE INDEX admin_uploads_expires_idx ON admin_uploads (expires_at);
+
+CREATE TABLE admin_upload_chunks (
+  upload_id   UUID NOT NULL REFERENCES admin_uploads(id) ON DELETE CASCADE,
+  byte_offset BIGINT NOT NULL,
+  data        BYTEA NOT NULL,
+  PRIMARY KEY (upload_id, byte_offset),
+  CONSTRAINT admin_upload_chunks_data_check CHECK (octet_length(data) > 0)
+);
+
+COMMIT;
+
+-- +goose Down
+BEGIN;
+
+DROP TABLE IF EXISTS admin_upload_chunks;
+DROP TABLE IF EXISTS admin_uploads;
+
+COMMIT;
@@ -0,0 +1,21 @@
+-- +goose Up
+BEGIN;
+
+-- Optional per-account retention for story versions. When set, each new
+-- draft version prunes older versions beyond this count; versions that are
+-- the draft, published, or referenced by reading progress are always kept.
+ALTER TABLE accounts
+  ADD COLUMN version_retention INTEGER NULL,
+  ADD CONSTRAINT accounts_version_retention_check
+    CHECK (version_retention IS NULL OR version_retention BETWEEN 1 AND 1000);
+
+COMMIT;
+
+-- +goose Down
+BEGIN;
+
+ALTER TABLE accounts
+  DROP CONSTRAINT IF EXISTS accounts_version_retention_check,
+  DROP COLUMN IF EXISTS version_retention;
+
+COMMIT;
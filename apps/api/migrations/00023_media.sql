@@ -0,0 +1,28 @@
+-- +goose Up
+BEGIN;
+
+-- Uploaded story images. Stories reference them as media:<id>, and ingest
+-- rejects references to media the account does not own. Rows are immutable.
+CREATE TABLE media (
+  id           UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  account_id   UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+  content_type TEXT NOT NULL,
+  byte_size    INTEGER NOT NULL,
+  sha256       TEXT NOT NULL,
+  data         BYTEA NOT NULL,
+  created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT media_content_type_check CHECK (content_type IN ('image/png', 'image/jpeg', 'image/gif', 'image/webp')),
+  CONSTRAINT media_size_check CHECK (byte_size > 0 AND byte_size = octet_length(data)),
+  CONSTRAINT media_sha256_check CHECK (sha256 ~ '^[0-9a-f]{64}$')
+);
+
+CREATE INDEX media_account_created_idx ON media (account_id, created_at DESC);
+
+COMMIT;
+
+-- +goose Down
+BEGIN;
+
+DROP TABLE IF EXISTS media;
+
+COMMIT;
@@ -0,0 +1,23 @@
+-- +goose Up
+BEGIN;
+
+-- Authored page breaks are their own Reader segment kind so fixed-layout
+-- stories can paginate exactly where the author chose.
+ALTER TABLE story_segments
+  DROP CONSTRAINT story_segments_kind_check,
+  ADD CONSTRAINT story_segments_kind_check
+    CHECK (segment_kind IN ('heading', 'paragraph', 'other', 'pagebreak'));
+
+COMMIT;
+
+-- +goose Down
+BEGIN;
+
+-- Segment kind is part of each content key, so page breaks cannot be
+-- relabelled; this fails while any version still contains one.
+ALTER TABLE story_segments
+  DROP CONSTRAINT story_segments_kind_check,
+  ADD CONSTRAINT story_segments_kind_check
+    CHECK (segment_kind IN ('heading', 'paragraph', 'other'));
+
+COMMIT;
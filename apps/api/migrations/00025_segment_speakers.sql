@@ -0,0 +1,23 @@
+-- +goose Up
+BEGIN;
+
+-- Dialogue paragraphs name their speaker so read-aloud can switch voices.
+-- The @Speaker: tag stays in the segment Markdown and content key.
+ALTER TABLE story_segments
+  ADD COLUMN speaker TEXT NULL,
+  ADD CONSTRAINT story_segments_speaker_check
+    CHECK (
+      speaker IS NULL
+      OR (segment_kind = 'paragraph' AND char_length(speaker) BETWEEN 1 AND 40)
+    );
+
+COMMIT;
+
+-- +goose Down
+BEGIN;
+
+ALTER TABLE story_segments
+  DROP CONSTRAINT story_segments_speaker_check,
+  DROP COLUMN speaker;
+
+COMMIT;
@@ -0,0 +1,23 @@
+-- +goose Up
+BEGIN;
+
+-- Verse keeps its authored line breaks, so the Reader lays it out as its own
+-- segment kind rather than a reflowable paragraph.
+ALTER TABLE story_segments
+  DROP CONSTRAINT story_segments_kind_check,
+  ADD CONSTRAINT story_segments_kind_check
+    CHECK (segment_kind IN ('heading', 'paragraph', 'other', 'pagebreak', 'verse'));
+
+COMMIT;
+
+-- +goose Down
+BEGIN;
+
+-- Segment kind is part of each content key, so verse cannot be relabelled;
+-- this fails while any version still contains some.
+ALTER TABLE story_segments
+  DROP CONSTRAINT story_segments_kind_check,
+  ADD CONSTRAINT story_segments_kind_check
+    CHECK (segment_kind IN ('heading', 'paragraph', 'other', 'pagebreak'));
+
+COMMIT;
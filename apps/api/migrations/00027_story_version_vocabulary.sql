@@ -0,0 +1,20 @@
+-- +goose Up
+BEGIN;
+
+-- Word frequencies and rare words are computed at ingestion. Versions created
+-- before this migration keep NULL until the story is re-imported.
+ALTER TABLE story_versions
+  ADD COLUMN vocabulary JSONB,
+  ADD CONSTRAINT story_versions_vocabulary_check
+    CHECK (vocabulary IS NULL OR jsonb_typeof(vocabulary) = 'object');
+
+COMMIT;
+
+-- +goose Down
+BEGIN;
+
+ALTER TABLE story_versions
+  DROP CONSTRAINT IF EXISTS story_versions_vocabulary_check,
+  DROP COLUMN IF EXISTS vocabulary;
+
+COMMIT;
@@ -0,0 +1,27 @@
+-- +goose Up
+BEGIN;
+
+-- A per-segment hash of the rendered content tells which segments of a new
+-- version are unchanged from an earlier one. Segments written before this
+-- migration keep NULL.
+ALTER TABLE story_segments
+  ADD COLUMN content_hash TEXT NULL,
+  ADD CONSTRAINT story_segments_content_hash_check
+    CHECK (content_hash IS NULL OR content_hash ~ '^[0-9a-f]{64}$');
+
+CREATE INDEX story_segments_version_content_hash_idx
+  ON story_segments (story_version_id, content_hash)
+  WHERE content_hash IS NOT NULL;
+
+COMMIT;
+
+-- +goose Down
+BEGIN;
+
+DROP INDEX IF EXISTS story_segments_version_content_hash_idx;
+
+ALTER TABLE story_segments
+  DROP CONSTRAINT story_segments_content_hash_check,
+  DROP COLUMN content_hash;
+
+COMMIT;
@@ -0,0 +1,17 @@
+-- +goose Up
+BEGIN;
+
+-- Stories ingested with the hyphenation extension carry soft hyphens. An
+-- account can turn them off for its readers without re-importing anything.
+ALTER TABLE accounts
+  ADD COLUMN reader_hyphenation BOOLEAN NOT NULL DEFAULT true;
+
+COMMIT;
+
+-- +goose Down
+BEGIN;
+
+ALTER TABLE accounts
+  DROP COLUMN IF EXISTS reader_hyphenation;
+
+COMMIT;
@@ -0,0 +1,20 @@
+-- +goose Up
+BEGIN;
+
+-- Pronunciation hints ([word](say: respelling)) are kept per segment so TTS
+-- engines can read them without parsing rendered HTML.
+ALTER TABLE story_segments
+  ADD COLUMN pronunciations JSONB NULL,
+  ADD CONSTRAINT story_segments_pronunciations_check
+    CHECK (pronunciations IS NULL OR jsonb_typeof(pronunciations) = 'array');
+
+COMMIT;
+
+-- +goose Down
+BEGIN;
+
+ALTER TABLE story_segments
+  DROP CONSTRAINT story_segments_pronunciations_check,
+  DROP COLUMN pronunciations;
+
+COMMIT;
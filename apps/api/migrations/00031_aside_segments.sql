@@ -0,0 +1,23 @@
+-- +goose Up
+BEGIN;
+
+-- Asides are notes for grown-ups that kid mode leaves out, so the Reader must
+-- tell them apart from the story's own paragraphs.
+ALTER TABLE story_segments
+  DROP CONSTRAINT story_segments_kind_check,
+  ADD CONSTRAINT story_segments_kind_check
+    CHECK (segment_kind IN ('heading', 'paragraph', 'other', 'pagebreak', 'verse', 'aside'));
+
+COMMIT;
+
+-- +goose Down
+BEGIN;
+
+-- Segment kind is part of each content key, so asides cannot be relabelled;
+-- this fails while any version still contains some.
+ALTER TABLE story_segments
+  DROP CONSTRAINT story_segments_kind_check,
+  ADD CONSTRAINT story_segments_kind_check
+    CHECK (segment_kind IN ('heading', 'paragraph', 'other', 'pagebreak', 'verse'));
+
+COMMIT;
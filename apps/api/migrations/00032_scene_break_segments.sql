@@ -0,0 +1,23 @@
+-- +goose Up
+BEGIN;
+
+-- Scene breaks carry no words, but the Reader draws them as dividers and
+-- read-aloud pauses on them, so they need a kind of their own.
+ALTER TABLE story_segments
+  DROP CONSTRAINT story_segments_kind_check,
+  ADD CONSTRAINT story_segments_kind_check
+    CHECK (segment_kind IN ('heading', 'paragraph', 'other', 'pagebreak', 'verse', 'aside', 'scene-break'));
+
+COMMIT;
+
+-- +goose Down
+BEGIN;
+
+-- Segment kind is part of each content key, so scene breaks cannot be
+-- relabelled; this fails while any version still contains some.
+ALTER TABLE story_segments
+  DROP CONSTRAINT story_segments_kind_check,
+  ADD CONSTRAINT story_segments_kind_check
+    CHECK (segment_kind IN ('heading', 'paragraph', 'other', 'pagebreak', 'verse', 'aside'));
+
+COMMIT;
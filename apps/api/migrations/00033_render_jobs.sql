@@ -0,0 +1,45 @@
+-- +goose Up
+BEGIN;
+
+-- Re-render jobs rebuild stored HTML from stored Markdown after the renderer
+-- changes. A worker claims a job under a lease and walks the account's
+-- versions in id order, so cursor_version_id is where a restart resumes.
+CREATE TABLE render_jobs (
+  id                 UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  account_id         UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+  status             TEXT NOT NULL DEFAULT 'queued',
+  total_versions     INTEGER NOT NULL,
+  rendered_versions  INTEGER NOT NULL DEFAULT 0,
+  unchanged_versions INTEGER NOT NULL DEFAULT 0,
+  skipped_versions   INTEGER NOT NULL DEFAULT 0,
+  cursor_version_id  UUID,
+  lease_until        TIMESTAMPTZ,
+  error              TEXT,
+  created_at         TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at         TIMESTAMPTZ NOT NULL DEFAULT now(),
+  finished_at        TIMESTAMPTZ,
+  CONSTRAINT render_jobs_status_check CHECK (status IN ('queued', 'running', 'completed', 'failed')),
+  CONSTRAINT render_jobs_counts_check CHECK (
+    total_versions >= 0 AND rendered_versions >= 0
+    AND unchanged_versions >= 0 AND skipped_versions >= 0
+  ),
+  CONSTRAINT render_jobs_finished_check CHECK ((status IN ('completed', 'failed')) = (finished_at IS NOT NULL))
+);
+
+-- One job at a time per account: a second request while one is pending
+-- conflicts instead of racing it over the same versions.
+CREATE UNIQUE INDEX render_jobs_one_active_idx
+  ON render_jobs (account_id)
+  WHERE status IN ('queued', 'running');
+
+CREATE INDEX render_jobs_account_created_idx
+  ON render_jobs (account_id, created_at DESC, id DESC);
+
+COMMIT;
+
+-- +goose Down
+BEGIN;
+
+DROP TABLE IF EXISTS render_jobs;
+
+COMMIT;
@@ -0,0 +1,31 @@
+-- +goose Up
+BEGIN;
+
+-- Responses to unsafe requests sent with an Idempotency-Key, replayed when a
+-- client retries with the same key. A row without a status is a request still
+-- running. Expired rows are swept when new keys are claimed.
+CREATE TABLE idempotency_keys (
+  account_id   UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+  key          TEXT NOT NULL,
+  fingerprint  TEXT NOT NULL,
+  status       INTEGER,
+  content_type TEXT,
+  body         BYTEA,
+  created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+  expires_at   TIMESTAMPTZ NOT NULL,
+  PRIMARY KEY (account_id, key),
+  CONSTRAINT idempotency_keys_key_check CHECK (char_length(key) BETWEEN 1 AND 255),
+  CONSTRAINT idempotency_keys_fingerprint_check CHECK (fingerprint ~ '^[0-9a-f]{64}$'),
+  CONSTRAINT idempotency_keys_response_check CHECK ((status IS NULL) = (body IS NULL))
+);
+
+CREATE INDEX idempotency_keys_expires_idx ON idempotency_keys (expires_at);
+
+COMMIT;
+
+-- +goose Down
+BEGIN;
+
+DROP TABLE IF EXISTS idempotency_keys;
+
+COMMIT;
@@ -0,0 +1,33 @@
+-- +goose Up
+BEGIN;
+
+-- Webhooks may also subscribe to reader activity: a story read to the end and
+-- a child profile created or changed.
+ALTER TABLE webhooks DROP CONSTRAINT webhooks_events_check;
+ALTER TABLE webhooks ADD CONSTRAINT webhooks_events_check CHECK (
+  cardinality(events) >= 1
+  AND events <@ ARRAY[
+    'story.published',
+    'story.unpublished',
+    'progress.completed',
+    'child.updated'
+  ]::text[]
+);
+
+COMMIT;
+
+-- +goose Down
+BEGIN;
+
+UPDATE webhooks
+SET events = array_remove(array_remove(events, 'progress.completed'), 'child.updated')
+WHERE events && ARRAY['progress.completed', 'child.updated']::text[];
+DELETE FROM webhooks WHERE cardinality(events) = 0;
+
+ALTER TABLE webhooks DROP CONSTRAINT webhooks_events_check;
+ALTER TABLE webhooks ADD CONSTRAINT webhooks_events_check CHECK (
+  cardinality(events) >= 1
+  AND events <@ ARRAY['story.published', 'story.unpublished']::text[]
+);
+
+COMMIT;
@@ -0,0 +1,17 @@
+-- +goose Up
+BEGIN;
+
+-- Readers are told a story was removed, rather than never there, when it was
+-- published once and then unpublished. Publishing it again clears the mark.
+ALTER TABLE stories
+  ADD COLUMN unpublished_at TIMESTAMPTZ;
+
+COMMIT;
+
+-- +goose Down
+BEGIN;
+
+ALTER TABLE stories
+  DROP COLUMN IF EXISTS unpublished_at;
+
+COMMIT;
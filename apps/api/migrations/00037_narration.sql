@@ -0,0 +1,60 @@
+-- +goose Up
+BEGIN;
+
+-- Narration jobs read one story version aloud, a segment at a time, through
+-- the configured TTS provider. As with render jobs, a worker claims a job
+-- under a lease and cursor_ordinal is where a restart resumes.
+CREATE TABLE narration_jobs (
+  id                UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  account_id        UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+  story_version_id  UUID NOT NULL REFERENCES story_versions(id) ON DELETE CASCADE,
+  voice             TEXT NOT NULL DEFAULT '',
+  status            TEXT NOT NULL DEFAULT 'queued',
+  total_segments    INTEGER NOT NULL,
+  narrated_segments INTEGER NOT NULL DEFAULT 0,
+  cursor_ordinal    INTEGER,
+  lease_until       TIMESTAMPTZ,
+  error             TEXT,
+  created_at        TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at        TIMESTAMPTZ NOT NULL DEFAULT now(),
+  finished_at       TIMESTAMPTZ,
+  CONSTRAINT narration_jobs_status_check CHECK (status IN ('queued', 'running', 'completed', 'failed')),
+  CONSTRAINT narration_jobs_counts_check CHECK (total_segments >= 0 AND narrated_segments >= 0),
+  CONSTRAINT narration_jobs_finished_check CHECK ((status IN ('completed', 'failed')) = (finished_at IS NOT NULL))
+);
+
+-- One job at a time per version, so two requests cannot pay to narrate the
+-- same segments twice.
+CREATE UNIQUE INDEX narration_jobs_one_active_idx
+  ON narration_jobs (story_version_id)
+  WHERE status IN ('queued', 'running');
+
+CREATE INDEX narration_jobs_account_created_idx
+  ON narration_jobs (account_id, created_at DESC, id DESC);
+
+-- One recording per segment. Segments are immutable, so a recording stays
+-- valid until its version is pruned; narrating again replaces it.
+CREATE TABLE segment_audio (
+  id           UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  account_id   UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+  segment_id   UUID NOT NULL UNIQUE REFERENCES story_segments(id) ON DELETE CASCADE,
+  voice        TEXT NOT NULL DEFAULT '',
+  content_type TEXT NOT NULL,
+  duration_ms  INTEGER NOT NULL,
+  byte_size    INTEGER NOT NULL,
+  data         BYTEA NOT NULL,
+  created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT segment_audio_content_type_check CHECK (content_type = 'audio/wav'),
+  CONSTRAINT segment_audio_duration_check CHECK (duration_ms > 0),
+  CONSTRAINT segment_audio_size_check CHECK (byte_size > 0 AND byte_size = octet_length(data))
+);
+
+COMMIT;
+
+-- +goose Down
+BEGIN;
+
+DROP TABLE IF EXISTS segment_audio;
+DROP TABLE IF EXISTS narration_jobs;
+
+COMMIT;
@@ -0,0 +1,41 @@
+-- +goose Up
+BEGIN;
+
+-- generation_jobs has existed since v1.1 without an owner. Story generation
+-- now writes a row per attempt, so each belongs to an account like every
+-- other admin record. Rows from before accounts take the owner of their
+-- story or, failing that, of their child or prompt profile; any that match
+-- none cannot be attributed and are dropped.
+ALTER TABLE generation_jobs
+  ADD COLUMN account_id UUID REFERENCES accounts(id) ON DELETE CASCADE;
+
+UPDATE generation_jobs AS job
+SET account_id = COALESCE(
+  (SELECT account_id FROM stories WHERE id = job.story_id),
+  (SELECT account_id FROM child_profiles WHERE id = job.child_profile_id),
+  (SELECT account_id FROM prompt_profiles WHERE id = job.prompt_profile_id)
+);
+
+DELETE FROM generation_jobs WHERE account_id IS NULL;
+
+ALTER TABLE generation_jobs
+  ALTER COLUMN account_id SET NOT NULL;
+
+CREATE INDEX generation_jobs_account_created_idx
+  ON generation_jobs (account_id, created_at DESC, id DESC);
+
+CREATE INDEX generation_jobs_version_idx
+  ON generation_jobs (story_version_id, created_at DESC)
+  WHERE story_version_id IS NOT NULL;
+
+COMMIT;
+
+-- +goose Down
+BEGIN;
+
+DROP INDEX IF EXISTS generation_jobs_version_idx;
+DROP INDEX IF EXISTS generation_jobs_account_created_idx;
+ALTER TABLE generation_jobs
+  DROP COLUMN IF EXISTS account_id;
+
+COMMIT;
@@ -0,0 +1,27 @@
+-- +goose Up
+BEGIN;
+
+-- A translated story points at the story it was translated from. Links are
+-- kept one level deep: a translation of a translation points at the same
+-- original, so an original and its translations form one group of language
+-- variants. Deleting the original leaves its translations standing alone.
+ALTER TABLE stories
+  ADD COLUMN translation_of UUID REFERENCES stories(id) ON DELETE SET NULL,
+  ADD CONSTRAINT stories_translation_of_not_self CHECK (translation_of <> id);
+
+CREATE INDEX stories_translation_of_idx
+  ON stories (translation_of)
+  WHERE translation_of IS NOT NULL;
+
+COMMIT;
+
+-- +goose Down
+BEGIN;
+
+DROP INDEX IF EXISTS stories_translation_of_idx;
+
+ALTER TABLE stories
+  DROP CONSTRAINT IF EXISTS stories_translation_of_not_self,
+  DROP COLUMN IF EXISTS translation_of;
+
+COMMIT;
@@ -0,0 +1,56 @@
+-- +goose Up
+BEGIN;
+
+-- Email addresses an account sends books to, such as a Kindle's
+-- Send-to-Kindle address.
+CREATE TABLE delivery_destinations (
+  id         UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  account_id UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+  name       TEXT NOT NULL,
+  address    TEXT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT delivery_destinations_name_check CHECK (btrim(name) <> '' AND char_length(name) <= 80),
+  CONSTRAINT delivery_destinations_address_check CHECK (char_length(address) <= 254),
+  CONSTRAINT delivery_destinations_account_address_key UNIQUE (account_id, address)
+);
+
+-- One row per story emailed. The address is copied from the destination so
+-- the log stays readable once the destination is removed; the dispatcher
+-- claims due rows under a lease and retries failures with backoff, as it does
+-- for webhook deliveries.
+CREATE TABLE story_deliveries (
+  id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  account_id      UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+  story_id        UUID NOT NULL REFERENCES stories(id) ON DELETE CASCADE,
+  destination_id  UUID REFERENCES delivery_destinations(id) ON DELETE SET NULL,
+  address         TEXT NOT NULL,
+  status          TEXT NOT NULL DEFAULT 'pending',
+  version         INTEGER,
+  attempts        INTEGER NOT NULL DEFAULT 0,
+  last_error      TEXT,
+  next_attempt_at TIMESTAMPTZ,
+  last_attempt_at TIMESTAMPTZ,
+  sent_at         TIMESTAMPTZ,
+  created_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT story_deliveries_status_check CHECK (status IN ('pending', 'sent', 'failed')),
+  CONSTRAINT story_deliveries_attempts_check CHECK (attempts >= 0),
+  CONSTRAINT story_deliveries_pending_check CHECK ((status = 'pending') = (next_attempt_at IS NOT NULL)),
+  CONSTRAINT story_deliveries_sent_check CHECK ((status = 'sent') = (sent_at IS NOT NULL))
+);
+
+CREATE INDEX story_deliveries_account_created_idx
+  ON story_deliveries (account_id, created_at DESC, id DESC);
+
+CREATE INDEX story_deliveries_due_idx
+  ON story_deliveries (next_attempt_at)
+  WHERE status = 'pending';
+
+COMMIT;
+
+-- +goose Down
+BEGIN;
+
+DROP TABLE IF EXISTS story_deliveries;
+DROP TABLE IF EXISTS delivery_destinations;
+
+COMMIT;
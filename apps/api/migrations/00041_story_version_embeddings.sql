@@ -0,0 +1,30 @@
+-- +goose Up
+BEGIN;
+
+-- One embedding per published version, for semantic search. The Postgres
+-- image ships without pgvector and migrations run without the superuser an
+-- untrusted extension needs, so vectors are REAL[] scaled to unit length: a
+-- dot product is their cosine similarity, and an account's library is small
+-- enough to rank by scanning. model names the provider and model that made
+-- the vector; vectors from different models are never compared.
+CREATE TABLE story_version_embeddings (
+  story_version_id UUID PRIMARY KEY REFERENCES story_versions(id) ON DELETE CASCADE,
+  account_id       UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+  model            TEXT NOT NULL,
+  embedding        REAL[] NOT NULL,
+  created_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT story_version_embeddings_model_check CHECK (btrim(model) <> ''),
+  CONSTRAINT story_version_embeddings_dimensions_check CHECK (cardinality(embedding) BETWEEN 1 AND 4096)
+);
+
+CREATE INDEX story_version_embeddings_account_model_idx
+  ON story_version_embeddings (account_id, model);
+
+COMMIT;
+
+-- +goose Down
+BEGIN;
+
+DROP TABLE IF EXISTS story_version_embeddings;
+
+COMMIT;
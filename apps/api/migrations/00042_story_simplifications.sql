@@ -0,0 +1,31 @@
+-- +goose Up
+BEGIN;
+
+-- A simplified story is a separate story rewritten for younger readers. It
+-- points at the story it was rewritten from and the age it was aimed at.
+-- Deleting the original leaves the simplification standing alone; its rights
+-- still name where the text came from.
+ALTER TABLE stories
+  ADD COLUMN simplified_from UUID REFERENCES stories(id) ON DELETE SET NULL,
+  ADD COLUMN simplified_for_age SMALLINT,
+  ADD CONSTRAINT stories_simplified_from_not_self CHECK (simplified_from <> id),
+  ADD CONSTRAINT stories_simplified_for_age_range CHECK (simplified_for_age BETWEEN 3 AND 12);
+
+CREATE INDEX stories_simplified_from_idx
+  ON stories (simplified_from)
+  WHERE simplified_from IS NOT NULL;
+
+COMMIT;
+
+-- +goose Down
+BEGIN;
+
+DROP INDEX IF EXISTS stories_simplified_from_idx;
+
+ALTER TABLE stories
+  DROP CONSTRAINT IF EXISTS stories_simplified_for_age_range,
+  DROP CONSTRAINT IF EXISTS stories_simplified_from_not_self,
+  DROP COLUMN IF EXISTS simplified_for_age,
+  DROP COLUMN IF EXISTS simplified_from;
+
+COMMIT;
@@ -0,0 +1,42 @@
+-- +goose Up
+BEGIN;
+
+-- Comprehension questions for one chapter of one story version, numbered from
+-- 1 in reading order; a story without chapters has a single chapter 1. The
+-- chapter's identity and title are copied from its heading segment when the
+-- quiz is written, so readers can match a quiz to the chapter they finished.
+-- Questions written by the language model are drafts until an editor
+-- approves them; readers only see approved quizzes.
+CREATE TABLE story_quizzes (
+  story_version_id   UUID NOT NULL REFERENCES story_versions(id) ON DELETE CASCADE,
+  chapter            INTEGER NOT NULL,
+  account_id         UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+  chapter_key        TEXT,
+  chapter_occurrence INTEGER,
+  title              TEXT,
+  questions          JSONB NOT NULL,
+  status             TEXT NOT NULL DEFAULT 'draft',
+  model              TEXT,
+  prompt_version     TEXT,
+  created_at         TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at         TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (story_version_id, chapter),
+  CONSTRAINT story_quizzes_chapter_check CHECK (chapter >= 1),
+  CONSTRAINT story_quizzes_chapter_identity_check CHECK ((chapter_key IS NULL) = (chapter_occurrence IS NULL)),
+  CONSTRAINT story_quizzes_questions_check CHECK (
+    jsonb_typeof(questions) = 'array' AND jsonb_array_length(questions) BETWEEN 1 AND 5
+  ),
+  CONSTRAINT story_quizzes_status_check CHECK (status IN ('draft', 'approved'))
+);
+
+CREATE INDEX story_quizzes_account_idx
+  ON story_quizzes (account_id);
+
+COMMIT;
+
+-- +goose Down
+BEGIN;
+
+DROP TABLE IF EXISTS story_quizzes;
+
+COMMIT;
@@ -0,0 +1,25 @@
+-- +goose Up
+BEGIN;
+
+-- Dictionary answers for the Reader's tapped words. A word means the same to
+-- every account, so entries are shared. A word the dictionary did not know
+-- is kept as a NULL definition, so repeated taps on a name or a made-up word
+-- do not ask it again until the entry expires.
+CREATE TABLE dictionary_entries (
+  language   TEXT NOT NULL,
+  word       TEXT NOT NULL,
+  definition JSONB,
+  fetched_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (language, word),
+  CONSTRAINT dictionary_entries_definition_check
+    CHECK (definition IS NULL OR jsonb_typeof(definition) = 'object')
+);
+
+COMMIT;
+
+-- +goose Down
+BEGIN;
+
+DROP TABLE IF EXISTS dictionary_entries;
+
+COMMIT;
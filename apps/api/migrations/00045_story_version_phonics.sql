@@ -0,0 +1,32 @@
+-- +goose Up
+BEGIN;
+
+-- One phonics analysis per story version, drafts included so the Studio can
+-- see a story's decodability before it is published. progression is the key
+-- of the progression it was scored against, which changes when the
+-- progression is edited; a version is scored again under the new key and the
+-- row replaced. decodable_set is copied out of the analysis so the Library
+-- can filter on it.
+CREATE TABLE story_version_phonics (
+  story_version_id UUID PRIMARY KEY REFERENCES story_versions(id) ON DELETE CASCADE,
+  account_id       UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+  progression      TEXT NOT NULL,
+  decodable_set    INTEGER,
+  analysis         JSONB NOT NULL,
+  created_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT story_version_phonics_progression_check CHECK (btrim(progression) <> ''),
+  CONSTRAINT story_version_phonics_decodable_set_check CHECK (decodable_set IS NULL OR decodable_set >= 1),
+  CONSTRAINT story_version_phonics_analysis_check CHECK (jsonb_typeof(analysis) = 'object')
+);
+
+CREATE INDEX story_version_phonics_account_progression_idx
+  ON story_version_phonics (account_id, progression, decodable_set);
+
+COMMIT;
+
+-- +goose Down
+BEGIN;
+
+DROP TABLE IF EXISTS story_version_phonics;
+
+COMMIT;
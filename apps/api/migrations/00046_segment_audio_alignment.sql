@@ -0,0 +1,50 @@
+-- +goose Up
+BEGIN;
+
+-- Word timings say when each word of a segment is spoken in its recording,
+-- so the Reader can highlight along. They belong to one recording: narrating
+-- the segment again clears them.
+ALTER TABLE segment_audio
+  ADD COLUMN word_timings JSONB,
+  ADD CONSTRAINT segment_audio_word_timings_check
+    CHECK (word_timings IS NULL OR jsonb_typeof(word_timings) = 'array');
+
+-- Alignment jobs time the words of one story version's recordings, a segment
+-- at a time, through the configured speech recognizer. They are leased and
+-- resumed like narration jobs.
+CREATE TABLE alignment_jobs (
+  id               UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  account_id       UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+  story_version_id UUID NOT NULL REFERENCES story_versions(id) ON DELETE CASCADE,
+  status           TEXT NOT NULL DEFAULT 'queued',
+  total_segments   INTEGER NOT NULL,
+  aligned_segments INTEGER NOT NULL DEFAULT 0,
+  cursor_ordinal   INTEGER,
+  lease_until      TIMESTAMPTZ,
+  error            TEXT,
+  created_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+  finished_at      TIMESTAMPTZ,
+  CONSTRAINT alignment_jobs_status_check CHECK (status IN ('queued', 'running', 'completed', 'failed')),
+  CONSTRAINT alignment_jobs_counts_check CHECK (total_segments >= 0 AND aligned_segments >= 0),
+  CONSTRAINT alignment_jobs_finished_check CHECK ((status IN ('completed', 'failed')) = (finished_at IS NOT NULL))
+);
+
+CREATE UNIQUE INDEX alignment_jobs_one_active_idx
+  ON alignment_jobs (story_version_id)
+  WHERE status IN ('queued', 'running');
+
+CREATE INDEX alignment_jobs_account_created_idx
+  ON alignment_jobs (account_id, created_at DESC, id DESC);
+
+COMMIT;
+
+-- +goose Down
+BEGIN;
+
+DROP TABLE IF EXISTS alignment_jobs;
+ALTER TABLE segment_audio
+  DROP CONSTRAINT IF EXISTS segment_audio_word_timings_check,
+  DROP COLUMN IF EXISTS word_timings;
+
+COMMIT;
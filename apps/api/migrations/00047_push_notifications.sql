@@ -0,0 +1,77 @@
+-- +goose Up
+BEGIN;
+
+-- The VAPID key pair identifies this server to browsers' push services. It
+-- is made on first start and shared by every instance; browsers subscribed
+-- with it must subscribe again if it is ever replaced.
+CREATE TABLE push_vapid_keys (
+  id          BOOLEAN PRIMARY KEY DEFAULT true,
+  private_key BYTEA NOT NULL,
+  created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT push_vapid_keys_single_check CHECK (id),
+  CONSTRAINT push_vapid_keys_private_key_check CHECK (octet_length(private_key) = 32)
+);
+
+-- One row per subscribed browser. An endpoint belongs to one account at a
+-- time: a browser that unlocks another account moves with it.
+CREATE TABLE push_subscriptions (
+  id         UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  account_id UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+  endpoint   TEXT NOT NULL UNIQUE,
+  p256dh     BYTEA NOT NULL,
+  auth       BYTEA NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT push_subscriptions_p256dh_check CHECK (octet_length(p256dh) = 65),
+  CONSTRAINT push_subscriptions_auth_check CHECK (octet_length(auth) = 16)
+);
+
+CREATE INDEX push_subscriptions_account_idx
+  ON push_subscriptions (account_id, created_at);
+
+-- An account without a row has the defaults: no reminder, new stories on.
+-- last_reminded_on is the local date of the last reminder, so each day has
+-- at most one.
+CREATE TABLE push_settings (
+  account_id       UUID PRIMARY KEY REFERENCES accounts(id) ON DELETE CASCADE,
+  reminders        BOOLEAN NOT NULL DEFAULT false,
+  reminder_time    TIME NOT NULL DEFAULT '19:00',
+  time_zone        TEXT NOT NULL DEFAULT 'UTC',
+  new_stories      BOOLEAN NOT NULL DEFAULT true,
+  last_reminded_on DATE,
+  updated_at       TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX push_settings_reminders_idx
+  ON push_settings (account_id)
+  WHERE reminders;
+
+-- Notifications waiting for one browser. A row is deleted once sent or
+-- given up on, and with its subscription.
+CREATE TABLE push_deliveries (
+  id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  subscription_id UUID NOT NULL REFERENCES push_subscriptions(id) ON DELETE CASCADE,
+  kind            TEXT NOT NULL,
+  slug            TEXT,
+  attempts        INTEGER NOT NULL DEFAULT 0,
+  next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  created_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT push_deliveries_kind_check CHECK (
+    (kind = 'reminder' AND slug IS NULL) OR (kind = 'story' AND slug IS NOT NULL)
+  )
+);
+
+CREATE INDEX push_deliveries_due_idx
+  ON push_deliveries (next_attempt_at, id);
+
+COMMIT;
+
+-- +goose Down
+BEGIN;
+
+DROP TABLE IF EXISTS push_deliveries;
+DROP TABLE IF EXISTS push_settings;
+DROP TABLE IF EXISTS push_subscriptions;
+DROP TABLE IF EXISTS push_vapid_keys;
+
+COMMIT;
@@ -0,0 +1,50 @@
+-- +goose Up
+BEGIN;
+
+-- A minute in which a progress save lands is a minute of reading, credited
+-- to the child profile active at the time. finished marks the save that
+-- first reached a story's end.
+CREATE TABLE reading_activity (
+  account_id       UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+  story_id         UUID NOT NULL REFERENCES stories(id) ON DELETE CASCADE,
+  minute           TIMESTAMPTZ NOT NULL,
+  child_profile_id UUID REFERENCES child_profiles(id) ON DELETE SET NULL,
+  finished         BOOLEAN NOT NULL DEFAULT false,
+  PRIMARY KEY (account_id, story_id, minute),
+  CONSTRAINT reading_activity_minute_check CHECK (minute = date_trunc('minute', minute))
+);
+
+CREATE INDEX reading_activity_account_minute_idx
+  ON reading_activity (account_id, minute);
+
+-- An account without a row gets no digest. send_day counts from Sunday, as
+-- extract(dow) does; last_sent_on is the local date of the last digest, so
+-- each week has at most one. A failed send is tried again from retry_at.
+CREATE TABLE digest_settings (
+  account_id   UUID PRIMARY KEY REFERENCES accounts(id) ON DELETE CASCADE,
+  enabled      BOOLEAN NOT NULL DEFAULT false,
+  address      TEXT NOT NULL,
+  send_day     SMALLINT NOT NULL DEFAULT 0,
+  send_time    TIME NOT NULL DEFAULT '18:00',
+  time_zone    TEXT NOT NULL DEFAULT 'UTC',
+  last_sent_on DATE,
+  retry_at     TIMESTAMPTZ,
+  attempts     INTEGER NOT NULL DEFAULT 0,
+  updated_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT digest_settings_send_day_check CHECK (send_day BETWEEN 0 AND 6),
+  CONSTRAINT digest_settings_address_check CHECK (address = '' OR position('@' IN address) > 1)
+);
+
+CREATE INDEX digest_settings_enabled_idx
+  ON digest_settings (account_id)
+  WHERE enabled;
+
+COMMIT;
+
+-- +goose Down
+BEGIN;
+
+DROP TABLE IF EXISTS digest_settings;
+DROP TABLE IF EXISTS reading_activity;
+
+COMMIT;
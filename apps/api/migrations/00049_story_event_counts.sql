@@ -0,0 +1,29 @@
+-- +goose Up
+BEGIN;
+
+-- Reader events are kept only as daily counts per story and child profile:
+-- no event, device or time finer than the UTC day is stored. A row for a
+-- child goes with the child's profile.
+CREATE TABLE story_event_counts (
+  account_id       UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+  story_id         UUID NOT NULL REFERENCES stories(id) ON DELETE CASCADE,
+  child_profile_id UUID REFERENCES child_profiles(id) ON DELETE CASCADE,
+  kind             TEXT NOT NULL,
+  day              DATE NOT NULL,
+  count            INTEGER NOT NULL,
+  CONSTRAINT story_event_counts_kind_check CHECK (kind IN ('story_opened', 'segment_viewed', 'read_aloud_used')),
+  CONSTRAINT story_event_counts_count_check CHECK (count > 0),
+  CONSTRAINT story_event_counts_key UNIQUE NULLS NOT DISTINCT (account_id, story_id, child_profile_id, kind, day)
+);
+
+CREATE INDEX story_event_counts_account_day_idx
+  ON story_event_counts (account_id, day);
+
+COMMIT;
+
+-- +goose Down
+BEGIN;
+
+DROP TABLE IF EXISTS story_event_counts;
+
+COMMIT;
@@ -0,0 +1,85 @@
+-- +goose Up
+BEGIN;
+
+-- With a blob store configured, images and recordings keep their bytes
+-- there under blob_key instead of in data. Rows written before keep theirs
+-- in the database.
+ALTER TABLE media
+  ALTER COLUMN data DROP NOT NULL,
+  ADD COLUMN blob_key TEXT,
+  DROP CONSTRAINT media_size_check,
+  ADD CONSTRAINT media_size_check CHECK (byte_size > 0 AND (data IS NULL OR byte_size = octet_length(data))),
+  ADD CONSTRAINT media_storage_check CHECK ((data IS NULL) <> (blob_key IS NULL));
+
+ALTER TABLE segment_audio
+  ALTER COLUMN data DROP NOT NULL,
+  ADD COLUMN blob_key TEXT,
+  DROP CONSTRAINT segment_audio_size_check,
+  ADD CONSTRAINT segment_audio_size_check CHECK (byte_size > 0 AND (data IS NULL OR byte_size = octet_length(data))),
+  ADD CONSTRAINT segment_audio_storage_check CHECK ((data IS NULL) <> (blob_key IS NULL));
+
+-- Objects no row references any more, left by replaced recordings and
+-- deleted versions, stories and accounts. Every key is used by one row only,
+-- so the sweeper may delete each as soon as it is listed.
+CREATE TABLE blob_garbage (
+  key        TEXT PRIMARY KEY,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- +goose StatementBegin
+CREATE FUNCTION collect_blob_garbage() RETURNS trigger AS $$
+BEGIN
+  IF OLD.blob_key IS NOT NULL AND (TG_OP = 'DELETE' OR NEW.blob_key IS DISTINCT FROM OLD.blob_key) THEN
+    INSERT INTO blob_garbage (key) VALUES (OLD.blob_key) ON CONFLICT (key) DO NOTHING;
+  END IF;
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER media_blob_garbage
+  AFTER UPDATE OF blob_key OR DELETE ON media
+  FOR EACH ROW EXECUTE FUNCTION collect_blob_garbage();
+
+CREATE TRIGGER segment_audio_blob_garbage
+  AFTER UPDATE OF blob_key OR DELETE ON segment_audio
+  FOR EACH ROW EXECUTE FUNCTION collect_blob_garbage();
+
+COMMIT;
+
+-- +goose Down
+BEGIN;
+
+-- Going back would lose the bytes of every row kept in the blob store, so
+-- it is refused while there are any.
+-- +goose StatementBegin
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM media WHERE blob_key IS NOT NULL)
+     OR EXISTS (SELECT 1 FROM segment_audio WHERE blob_key IS NOT NULL) THEN
+    RAISE EXCEPTION 'media or narration is kept in the blob store';
+  END IF;
+END;
+$$;
+-- +goose StatementEnd
+
+DROP TRIGGER IF EXISTS segment_audio_blob_garbage ON segment_audio;
+DROP TRIGGER IF EXISTS media_blob_garbage ON media;
+DROP FUNCTION IF EXISTS collect_blob_garbage();
+DROP TABLE IF EXISTS blob_garbage;
+
+ALTER TABLE segment_audio
+  DROP CONSTRAINT IF EXISTS segment_audio_storage_check,
+  DROP CONSTRAINT segment_audio_size_check,
+  DROP COLUMN IF EXISTS blob_key,
+  ALTER COLUMN data SET NOT NULL,
+  ADD CONSTRAINT segment_audio_size_check CHECK (byte_size > 0 AND byte_size = octet_length(data));
+
+ALTER TABLE media
+  DROP CONSTRAINT IF EXISTS media_storage_check,
+  DROP CONSTRAINT media_size_check,
+  DROP COLUMN IF EXISTS blob_key,
+  ALTER COLUMN data SET NOT NULL,
+  ADD CONSTRAINT media_size_check CHECK (byte_size > 0 AND byte_size = octet_length(data));
+
+COMMIT;
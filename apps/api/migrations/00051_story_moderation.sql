@@ -0,0 +1,34 @@
+-- +goose Up
+BEGIN;
+
+-- Versions a language model wrote carry the moderation verdict they were
+-- given when the draft was saved. A blocked version cannot be published and a
+-- flagged one only once a publisher acknowledges it; versions written by
+-- hand have no verdict.
+ALTER TABLE story_versions
+  ADD COLUMN moderation_verdict TEXT,
+  ADD COLUMN moderation_provider TEXT,
+  ADD COLUMN moderation_findings JSONB,
+  ADD COLUMN moderated_at TIMESTAMPTZ,
+  ADD CONSTRAINT story_versions_moderation_verdict_check
+    CHECK (moderation_verdict IN ('passed', 'flagged', 'blocked')),
+  ADD CONSTRAINT story_versions_moderation_check
+    CHECK (
+      (moderation_verdict IS NULL AND moderation_provider IS NULL AND moderation_findings IS NULL AND moderated_at IS NULL)
+      OR (moderation_verdict IS NOT NULL AND jsonb_typeof(moderation_findings) = 'array' AND moderated_at IS NOT NULL)
+    );
+
+COMMIT;
+
+-- +goose Down
+BEGIN;
+
+ALTER TABLE story_versions
+  DROP CONSTRAINT IF EXISTS story_versions_moderation_check,
+  DROP CONSTRAINT IF EXISTS story_versions_moderation_verdict_check,
+  DROP COLUMN IF EXISTS moderated_at,
+  DROP COLUMN IF EXISTS moderation_findings,
+  DROP COLUMN IF EXISTS moderation_provider,
+  DROP COLUMN IF EXISTS moderation_verdict;
+
+COMMIT;
@@ -0,0 +1,26 @@
+-- +goose Up
+BEGIN;
+
+-- The up-next queue lines up stories for a profile to read, in the order a
+-- parent chose. Positions only order the rows and may have gaps. A story
+-- taken down leaves the queue the next time it changes.
+CREATE TABLE reading_queue (
+  profile_id UUID NOT NULL REFERENCES profiles(id) ON DELETE CASCADE,
+  story_id   UUID NOT NULL REFERENCES stories(id) ON DELETE CASCADE,
+  position   INTEGER NOT NULL,
+  added_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (profile_id, story_id),
+  CONSTRAINT reading_queue_position_check CHECK (position >= 0)
+);
+
+CREATE INDEX reading_queue_profile_position_idx
+  ON reading_queue (profile_id, position);
+
+COMMIT;
+
+-- +goose Down
+BEGIN;
+
+DROP TABLE IF EXISTS reading_queue;
+
+COMMIT;
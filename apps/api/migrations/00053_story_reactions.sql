@@ -0,0 +1,25 @@
+-- +goose Up
+BEGIN;
+
+-- A profile keeps at most one reaction per story; reacting again replaces
+-- it. The Library counts them per story across the account's profiles.
+CREATE TABLE story_reactions (
+  profile_id UUID NOT NULL REFERENCES profiles(id) ON DELETE CASCADE,
+  story_id   UUID NOT NULL REFERENCES stories(id) ON DELETE CASCADE,
+  reaction   TEXT NOT NULL,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (profile_id, story_id),
+  CONSTRAINT story_reactions_reaction_check CHECK (reaction IN ('loved_it', 'silly', 'scary'))
+);
+
+CREATE INDEX story_reactions_story_idx
+  ON story_reactions (story_id);
+
+COMMIT;
+
+-- +goose Down
+BEGIN;
+
+DROP TABLE IF EXISTS story_reactions;
+
+COMMIT;
@@ -0,0 +1,36 @@
+-- +goose Up
+BEGIN;
+
+-- Conversation prompts an editor writes for one chapter of one story
+-- version, for a grown-up to talk over with the child once the chapter is
+-- read. Chapters are numbered and identified as in story_quizzes. Prompts
+-- are written by hand, so readers see them as soon as they are saved.
+CREATE TABLE story_discussions (
+  story_version_id   UUID NOT NULL REFERENCES story_versions(id) ON DELETE CASCADE,
+  chapter            INTEGER NOT NULL,
+  account_id         UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+  chapter_key        TEXT,
+  chapter_occurrence INTEGER,
+  title              TEXT,
+  questions          JSONB NOT NULL,
+  created_at         TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at         TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (story_version_id, chapter),
+  CONSTRAINT story_discussions_chapter_check CHECK (chapter >= 1),
+  CONSTRAINT story_discussions_chapter_identity_check CHECK ((chapter_key IS NULL) = (chapter_occurrence IS NULL)),
+  CONSTRAINT story_discussions_questions_check CHECK (
+    jsonb_typeof(questions) = 'array' AND jsonb_array_length(questions) BETWEEN 1 AND 5
+  )
+);
+
+CREATE INDEX story_discussions_account_idx
+  ON story_discussions (account_id);
+
+COMMIT;
+
+-- +goose Down
+BEGIN;
+
+DROP TABLE IF EXISTS story_discussions;
+
+COMMIT;
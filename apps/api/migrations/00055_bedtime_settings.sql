@@ -0,0 +1,25 @@
+-- +goose Up
+BEGIN;
+
+-- The Library's bedtime mode applies from start_time to end_time in
+-- time_zone; a window whose end is earlier than its start runs past
+-- midnight. An account without a row uses the defaults in the API.
+CREATE TABLE bedtime_settings (
+  account_id UUID PRIMARY KEY REFERENCES accounts(id) ON DELETE CASCADE,
+  start_time TIME NOT NULL,
+  end_time   TIME NOT NULL,
+  time_zone  TEXT NOT NULL,
+  max_words  INTEGER NOT NULL,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT bedtime_settings_window_check CHECK (start_time <> end_time),
+  CONSTRAINT bedtime_settings_max_words_check CHECK (max_words > 0)
+);
+
+COMMIT;
+
+-- +goose Down
+BEGIN;
+
+DROP TABLE IF EXISTS bedtime_settings;
+
+COMMIT;
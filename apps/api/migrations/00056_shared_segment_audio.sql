@@ -0,0 +1,66 @@
+-- +goose Up
+BEGIN;
+
+-- A narration job copies recordings of unchanged segments from earlier
+-- versions, so a segment_audio object in the blob store can now be kept by
+-- several rows. Its key becomes garbage only when the last of them goes.
+CREATE INDEX segment_audio_blob_key_idx
+  ON segment_audio (blob_key)
+  WHERE blob_key IS NOT NULL;
+
+-- A narration job finds earlier recordings by the content hash of their
+-- segment.
+CREATE INDEX story_segments_content_hash_idx
+  ON story_segments (content_hash)
+  WHERE content_hash IS NOT NULL;
+
+-- +goose StatementBegin
+CREATE FUNCTION collect_segment_audio_blob_garbage() RETURNS trigger AS $$
+BEGIN
+  IF OLD.blob_key IS NOT NULL AND (TG_OP = 'DELETE' OR NEW.blob_key IS DISTINCT FROM OLD.blob_key)
+     AND NOT EXISTS (SELECT 1 FROM segment_audio WHERE blob_key = OLD.blob_key) THEN
+    INSERT INTO blob_garbage (key) VALUES (OLD.blob_key) ON CONFLICT (key) DO NOTHING;
+  END IF;
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+DROP TRIGGER segment_audio_blob_garbage ON segment_audio;
+CREATE TRIGGER segment_audio_blob_garbage
+  AFTER UPDATE OF blob_key OR DELETE ON segment_audio
+  FOR EACH ROW EXECUTE FUNCTION collect_segment_audio_blob_garbage();
+
+COMMIT;
+
+-- +goose Down
+BEGIN;
+
+-- Going back would let the sweeper delete an object other rows still keep,
+-- so it is refused while any is shared.
+-- +goose StatementBegin
+DO $$
+BEGIN
+  IF EXISTS (
+    SELECT 1
+    FROM segment_audio
+    WHERE blob_key IS NOT NULL
+    GROUP BY blob_key
+    HAVING count(*) > 1
+  ) THEN
+    RAISE EXCEPTION 'segment audio blobs are shared between recordings';
+  END IF;
+END;
+$$;
+-- +goose StatementEnd
+
+DROP TRIGGER IF EXISTS segment_audio_blob_garbage ON segment_audio;
+CREATE TRIGGER segment_audio_blob_garbage
+  AFTER UPDATE OF blob_key OR DELETE ON segment_audio
+  FOR EACH ROW EXECUTE FUNCTION collect_blob_garbage();
+DROP FUNCTION IF EXISTS collect_segment_audio_blob_garbage();
+
+DROP INDEX IF EXISTS story_segments_content_hash_idx;
+DROP INDEX IF EXISTS segment_audio_blob_key_idx;
+
+COMMIT;
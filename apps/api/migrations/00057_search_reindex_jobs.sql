@@ -0,0 +1,41 @@
+-- +goose Up
+BEGIN;
+
+-- Search reindex jobs embed the published versions of an account's stories,
+-- or of one story, again, replacing their vectors a version at a time. They
+-- are leased and resumed like narration jobs, walking versions in ID order.
+CREATE TABLE search_reindex_jobs (
+  id                UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  account_id        UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+  slug              TEXT,
+  status            TEXT NOT NULL DEFAULT 'queued',
+  total_versions    INTEGER NOT NULL,
+  indexed_versions  INTEGER NOT NULL DEFAULT 0,
+  cursor_version_id UUID,
+  lease_until       TIMESTAMPTZ,
+  error             TEXT,
+  created_at        TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at        TIMESTAMPTZ NOT NULL DEFAULT now(),
+  finished_at       TIMESTAMPTZ,
+  CONSTRAINT search_reindex_jobs_status_check CHECK (status IN ('queued', 'running', 'completed', 'failed')),
+  CONSTRAINT search_reindex_jobs_counts_check CHECK (total_versions >= 0 AND indexed_versions >= 0),
+  CONSTRAINT search_reindex_jobs_finished_check CHECK ((status IN ('completed', 'failed')) = (finished_at IS NOT NULL))
+);
+
+-- One job at a time per account, so two requests cannot pay to embed the
+-- same versions twice.
+CREATE UNIQUE INDEX search_reindex_jobs_one_active_idx
+  ON search_reindex_jobs (account_id)
+  WHERE status IN ('queued', 'running');
+
+CREATE INDEX search_reindex_jobs_account_created_idx
+  ON search_reindex_jobs (account_id, created_at DESC, id DESC);
+
+COMMIT;
+
+-- +goose Down
+BEGIN;
+
+DROP TABLE IF EXISTS search_reindex_jobs;
+
+COMMIT;
@@ -0,0 +1,143 @@
+-- +goose Up
+BEGIN;
+
+-- reader_changed_at is when anything the Reader serves for a story last
+-- changed, and is its Last-Modified. The published version's created_at is
+-- not enough: narration, alignment, re-rendering, metadata edits and
+-- contributors all change what a version reads as. Triggers keep it, so no
+-- write path can forget to.
+ALTER TABLE stories
+  ADD COLUMN reader_changed_at TIMESTAMPTZ NOT NULL DEFAULT now();
+
+-- +goose StatementBegin
+CREATE FUNCTION touch_story_reader_changed() RETURNS trigger AS $$
+BEGIN
+  NEW.reader_changed_at := now();
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER stories_reader_changed
+  BEFORE UPDATE ON stories
+  FOR EACH ROW
+  WHEN ((OLD.slug, OLD.title, OLD.author, OLD.language, OLD.is_published, OLD.published_version_id)
+        IS DISTINCT FROM (NEW.slug, NEW.title, NEW.author, NEW.language, NEW.is_published, NEW.published_version_id))
+  EXECUTE FUNCTION touch_story_reader_changed();
+
+-- touch_stories_reader_changed stamps the stories a change to another table
+-- reaches. Statement triggers read the changed rows as changed; the
+-- accounts trigger is a row trigger, since transition tables cannot be
+-- combined with a column list.
+-- +goose StatementBegin
+CREATE FUNCTION touch_stories_reader_changed() RETURNS trigger AS $$
+BEGIN
+  CASE TG_TABLE_NAME
+  WHEN 'story_versions' THEN
+    UPDATE stories SET reader_changed_at = now()
+    WHERE published_version_id IN (SELECT id FROM changed);
+  WHEN 'story_segments' THEN
+    UPDATE stories SET reader_changed_at = now()
+    WHERE published_version_id IN (SELECT story_version_id FROM changed);
+  WHEN 'segment_audio' THEN
+    UPDATE stories SET reader_changed_at = now()
+    WHERE published_version_id IN (
+      SELECT segment.story_version_id
+      FROM story_segments AS segment
+      JOIN changed
+        ON changed.segment_id = segment.id
+    );
+  WHEN 'story_contributors' THEN
+    UPDATE stories SET reader_changed_at = now()
+    WHERE id IN (SELECT story_id FROM changed);
+  WHEN 'contributors' THEN
+    UPDATE stories SET reader_changed_at = now()
+    WHERE id IN (
+      SELECT credit.story_id
+      FROM story_contributors AS credit
+      JOIN changed
+        ON changed.id = credit.contributor_id
+    );
+  WHEN 'accounts' THEN
+    UPDATE stories SET reader_changed_at = now()
+    WHERE account_id = NEW.id;
+  END CASE;
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER story_versions_reader_changed
+  AFTER UPDATE ON story_versions
+  REFERENCING NEW TABLE AS changed
+  FOR EACH STATEMENT EXECUTE FUNCTION touch_stories_reader_changed();
+
+CREATE TRIGGER story_segments_reader_changed
+  AFTER UPDATE ON story_segments
+  REFERENCING NEW TABLE AS changed
+  FOR EACH STATEMENT EXECUTE FUNCTION touch_stories_reader_changed();
+
+CREATE TRIGGER segment_audio_reader_changed_insert
+  AFTER INSERT ON segment_audio
+  REFERENCING NEW TABLE AS changed
+  FOR EACH STATEMENT EXECUTE FUNCTION touch_stories_reader_changed();
+
+CREATE TRIGGER segment_audio_reader_changed_update
+  AFTER UPDATE ON segment_audio
+  REFERENCING NEW TABLE AS changed
+  FOR EACH STATEMENT EXECUTE FUNCTION touch_stories_reader_changed();
+
+CREATE TRIGGER segment_audio_reader_changed_delete
+  AFTER DELETE ON segment_audio
+  REFERENCING OLD TABLE AS changed
+  FOR EACH STATEMENT EXECUTE FUNCTION touch_stories_reader_changed();
+
+CREATE TRIGGER story_contributors_reader_changed_insert
+  AFTER INSERT ON story_contributors
+  REFERENCING NEW TABLE AS changed
+  FOR EACH STATEMENT EXECUTE FUNCTION touch_stories_reader_changed();
+
+CREATE TRIGGER story_contributors_reader_changed_update
+  AFTER UPDATE ON story_contributors
+  REFERENCING NEW TABLE AS changed
+  FOR EACH STATEMENT EXECUTE FUNCTION touch_stories_reader_changed();
+
+CREATE TRIGGER story_contributors_reader_changed_delete
+  AFTER DELETE ON story_contributors
+  REFERENCING OLD TABLE AS changed
+  FOR EACH STATEMENT EXECUTE FUNCTION touch_stories_reader_changed();
+
+CREATE TRIGGER contributors_reader_changed
+  AFTER UPDATE ON contributors
+  REFERENCING NEW TABLE AS changed
+  FOR EACH STATEMENT EXECUTE FUNCTION touch_stories_reader_changed();
+
+CREATE TRIGGER accounts_reader_changed
+  AFTER UPDATE OF reader_hyphenation ON accounts
+  FOR EACH ROW
+  WHEN (OLD.reader_hyphenation IS DISTINCT FROM NEW.reader_hyphenation)
+  EXECUTE FUNCTION touch_stories_reader_changed();
+
+COMMIT;
+
+-- +goose Down
+BEGIN;
+
+DROP TRIGGER IF EXISTS accounts_reader_changed ON accounts;
+DROP TRIGGER IF EXISTS contributors_reader_changed ON contributors;
+DROP TRIGGER IF EXISTS story_contributors_reader_changed_delete ON story_contributors;
+DROP TRIGGER IF EXISTS story_contributors_reader_changed_update ON story_contributors;
+DROP TRIGGER IF EXISTS story_contributors_reader_changed_insert ON story_contributors;
+DROP TRIGGER IF EXISTS segment_audio_reader_changed_delete ON segment_audio;
+DROP TRIGGER IF EXISTS segment_audio_reader_changed_update ON segment_audio;
+DROP TRIGGER IF EXISTS segment_audio_reader_changed_insert ON segment_audio;
+DROP TRIGGER IF EXISTS story_segments_reader_changed ON story_segments;
+DROP TRIGGER IF EXISTS story_versions_reader_changed ON story_versions;
+DROP FUNCTION IF EXISTS touch_stories_reader_changed();
+DROP TRIGGER IF EXISTS stories_reader_changed ON stories;
+DROP FUNCTION IF EXISTS touch_story_reader_changed();
+
+ALTER TABLE stories
+  DROP COLUMN IF EXISTS reader_changed_at;
+
+COMMIT;
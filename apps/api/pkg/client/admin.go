@@ -0,0 +1,49 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+)
+
+// The methods here need a client made WithAdminKey, whose key holds the role
+// each endpoint asks for.
+
+// Draft saves a story's Markdown as its draft, creating the story the first
+// time its slug is used.
+func (c *Client) Draft(ctx context.Context, draft DraftRequest) (DraftResponse, error) {
+	var out DraftResponse
+	err := c.Do(ctx, http.MethodPost, "/api/v1/admin/stories/draft", draft, &out)
+	return out, err
+}
+
+// Publish publishes one version of a story.
+func (c *Client) Publish(ctx context.Context, slug, versionID string) (StoryStatus, error) {
+	var out StoryStatus
+	body := map[string]string{"versionId": versionID}
+	err := c.Do(ctx, http.MethodPost, "/api/v1/admin/stories/"+url.PathEscape(slug)+"/publish", body, &out)
+	return out, err
+}
+
+// AdminStory returns a story's details, drafts and unpublished stories
+// included.
+func (c *Client) AdminStory(ctx context.Context, slug string) (AdminStory, error) {
+	var out AdminStory
+	err := c.Do(ctx, http.MethodGet, "/api/v1/admin/stories/"+url.PathEscape(slug), nil, &out)
+	return out, err
+}
+
+// AdminStories returns one page of every story, newest change first.
+func (c *Client) AdminStories(ctx context.Context, page PageRequest) (AdminStoriesPage, error) {
+	var out AdminStoriesPage
+	err := c.Do(ctx, http.MethodGet, "/api/v1/admin/stories"+pageQuery(page), nil, &out)
+	return out, err
+}
+
+// Export returns a story's bundle as the API sent it, ready to save.
+func (c *Client) Export(ctx context.Context, slug string) (json.RawMessage, error) {
+	var out json.RawMessage
+	err := c.Do(ctx, http.MethodGet, "/api/v1/admin/stories/"+url.PathEscape(slug)+"/export", nil, &out)
+	return out, err
+}
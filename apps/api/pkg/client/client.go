@@ -0,0 +1,242 @@
+// Package client calls the PandaPages API from Go: the Reader endpoints a
+// session unlocked with the library passcode may use, and the admin
+// endpoints an admin key opens on top of it.
+//
+// A Client unlocks on its first call and keeps the session cookie it is
+// given; when the session lapses it unlocks once more and retries. Requests
+// and responses are the API's own types, re-exported here.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// AdminKeyHeader carries the admin key on every request of a client
+	// that has one.
+	AdminKeyHeader = "X-PP-Admin-Key"
+
+	defaultTimeout = 5 * time.Minute
+	maxErrorBytes  = 1 << 20
+)
+
+// Client is safe for concurrent use.
+type Client struct {
+	base     *url.URL
+	http     *http.Client
+	passcode string
+	adminKey string
+
+	mu       sync.Mutex
+	unlocked bool
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithAdminKey sends key, PP_ADMIN_KEY or a per-user admin key, with every
+// request so the admin methods are allowed.
+func WithAdminKey(key string) Option {
+	return func(c *Client) { c.adminKey = key }
+}
+
+// WithHTTPClient sends requests through hc. A cookie jar is added when hc has
+// none, as the session lives in a cookie.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) { c.http = hc }
+}
+
+// New returns a client of the API at baseURL, such as
+// https://pandapages.example, that unlocks with passcode.
+func New(baseURL, passcode string, options ...Option) (*Client, error) {
+	base, err := url.Parse(strings.TrimRight(strings.TrimSpace(baseURL), "/"))
+	if err != nil || (base.Scheme != "http" && base.Scheme != "https") || base.Host == "" {
+		return nil, errors.New("API URL must be an http or https URL")
+	}
+	if passcode == "" {
+		return nil, errors.New("passcode is required")
+	}
+	c := &Client{base: base, passcode: passcode, http: &http.Client{Timeout: defaultTimeout}}
+	for _, option := range options {
+		option(c)
+	}
+	if c.http.Jar == nil {
+		jar, err := cookiejar.New(nil)
+		if err != nil {
+			return nil, err
+		}
+		withJar := *c.http
+		withJar.Jar = jar
+		c.http = &withJar
+	}
+	return c, nil
+}
+
+// Error is an error response of the API.
+type Error struct {
+	Status    int               `json:"-"`
+	Code      string            `json:"code"`
+	Message   string            `json:"message"`
+	RequestID string            `json:"requestId"`
+	Fields    []FieldError      `json:"fields"`
+	Issues    []ValidationIssue `json:"issues"`
+}
+
+func (e *Error) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s (%d %s)", e.Message, e.Status, e.Code)
+	for _, field := range e.Fields {
+		fmt.Fprintf(&b, "\n  %s: %s", field.Path, field.Message)
+	}
+	for _, issue := range e.Issues {
+		b.WriteString("\n  ")
+		if issue.Line > 0 {
+			fmt.Fprintf(&b, "line %d: ", issue.Line)
+		}
+		fmt.Fprintf(&b, "%s: %s", issue.Field, issue.Message)
+	}
+	if e.RequestID != "" {
+		fmt.Fprintf(&b, "\n  request ID %s", e.RequestID)
+	}
+	return b.String()
+}
+
+// IsNotFound reports whether err is the API's answer that something does not
+// exist, as opposed to a failure to ask.
+func IsNotFound(err error) bool {
+	var apiErr *Error
+	return errors.As(err, &apiErr) && apiErr.Status == http.StatusNotFound
+}
+
+// IsRemoved reports whether err is the API's answer that a story was
+// published and has since been taken down, so a client can say so and drop
+// any place it saved in it.
+func IsRemoved(err error) bool {
+	var apiErr *Error
+	return errors.As(err, &apiErr) && apiErr.Status == http.StatusGone
+}
+
+// Unlock starts a session with the passcode. Other methods call it as
+// needed; calling it first reports a wrong passcode early.
+func (c *Client) Unlock(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.unlockLocked(ctx)
+}
+
+func (c *Client) unlockLocked(ctx context.Context) error {
+	if c.unlocked {
+		return nil
+	}
+	body := map[string]string{"passcode": c.passcode}
+	if err := c.send(ctx, http.MethodPost, "/api/v1/auth/unlock", body, nil); err != nil {
+		return fmt.Errorf("unlock: %w", err)
+	}
+	c.unlocked = true
+	return nil
+}
+
+// Do sends a request to path with body encoded as JSON, when not nil, and
+// decodes the response into out, when not nil; a *json.RawMessage receives
+// the body as sent. It is for endpoints the typed methods do not cover.
+func (c *Client) Do(ctx context.Context, method, path string, body, out any) error {
+	if err := c.Unlock(ctx); err != nil {
+		return err
+	}
+	err := c.send(ctx, method, path, body, out)
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.Status != http.StatusUnauthorized {
+		return err
+	}
+
+	// The session expired or was revoked: unlock again and retry once.
+	c.mu.Lock()
+	c.unlocked = false
+	err = c.unlockLocked(ctx)
+	c.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return c.send(ctx, method, path, body, out)
+}
+
+func (c *Client) send(ctx context.Context, method, path string, body, out any) error {
+	var encoded []byte
+	if body != nil {
+		var err error
+		if encoded, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+	request, err := http.NewRequestWithContext(ctx, method, c.base.String()+path, bytes.NewReader(encoded))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Accept", "application/json")
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	if c.adminKey != "" {
+		request.Header.Set(AdminKeyHeader, c.adminKey)
+	}
+
+	response, err := c.http.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode >= http.StatusBadRequest {
+		return readError(response)
+	}
+	if out == nil {
+		_, _ = io.Copy(io.Discard, response.Body)
+		return nil
+	}
+	if raw, ok := out.(*json.RawMessage); ok {
+		*raw, err = io.ReadAll(response.Body)
+		return err
+	}
+	return json.NewDecoder(response.Body).Decode(out)
+}
+
+func readError(response *http.Response) error {
+	var envelope struct {
+		Error *Error `json:"error"`
+	}
+	raw, _ := io.ReadAll(io.LimitReader(response.Body, maxErrorBytes))
+	if err := json.Unmarshal(raw, &envelope); err != nil || envelope.Error == nil {
+		return &Error{Status: response.StatusCode, Code: "unexpected_response", Message: http.StatusText(response.StatusCode)}
+	}
+	envelope.Error.Status = response.StatusCode
+	return envelope.Error
+}
+
+// pageQuery is the query string asking for page.
+func pageQuery(page PageRequest) string {
+	query := url.Values{}
+	if page.Cursor != "" {
+		query.Set("cursor", page.Cursor)
+	}
+	if page.Limit > 0 {
+		query.Set("limit", fmt.Sprint(page.Limit))
+	}
+	if page.Total {
+		query.Set("total", "true")
+	}
+	if len(query) == 0 {
+		return ""
+	}
+	return "?" + query.Encode()
+}
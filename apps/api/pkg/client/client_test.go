@@ -0,0 +1,128 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"pandapages/api/internal/model"
+)
+
+// fakeAPI answers a few Reader and admin routes, insisting on the session
+// cookie from unlock. expire makes the next request find the session gone.
+type fakeAPI struct {
+	unlocks  int
+	expire   bool
+	adminKey string
+	query    string
+	saved    Progress
+}
+
+func (f *fakeAPI) server(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/auth/unlock", func(w http.ResponseWriter, r *http.Request) {
+		var body struct{ Passcode string }
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body.Passcode != "123456" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":{"code":"unauthorized","message":"invalid passcode"}}`))
+			return
+		}
+		f.unlocks++
+		http.SetCookie(w, &http.Cookie{Name: "pp_session", Value: "signed", Path: "/"})
+	})
+	guarded := http.NewServeMux()
+	guarded.HandleFunc("GET /api/v1/library", func(w http.ResponseWriter, r *http.Request) {
+		f.query = r.URL.RawQuery
+		_ = json.NewEncoder(w).Encode(Library{Page: model.Page[StoryItem]{Items: []StoryItem{{Slug: "the-snail"}}}})
+	})
+	guarded.HandleFunc("GET /api/v1/progress/{slug}", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"progress": f.saved})
+	})
+	guarded.HandleFunc("PUT /api/v1/progress/{slug}", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&f.saved)
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	})
+	guarded.HandleFunc("POST /api/v1/admin/stories/draft", func(w http.ResponseWriter, r *http.Request) {
+		f.adminKey = r.Header.Get(AdminKeyHeader)
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error":{"code":"bad_json","message":"request body must be valid JSON","requestId":"req-1",` +
+			`"fields":[{"path":"title","code":"required","message":"title is required"}]}}`))
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if cookie, err := r.Cookie("pp_session"); err != nil || cookie.Value != "signed" || f.expire {
+			f.expire = false
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":{"code":"unauthorized","message":"unlock required"}}`))
+			return
+		}
+		guarded.ServeHTTP(w, r)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestClientUnlocksOnceAndAgainWhenTheSessionLapses(t *testing.T) {
+	api := &fakeAPI{}
+	c, err := New(api.server(t).URL+"/", "123456", WithAdminKey("ppak_editor"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	library, err := c.Library(t.Context(), PageRequest{Cursor: "next page", Limit: 20, Total: true})
+	if err != nil || len(library.Items) != 1 || library.Items[0].Slug != "the-snail" {
+		t.Fatalf("library = %#v, error %v", library, err)
+	}
+	if api.query != "cursor=next+page&limit=20&total=true" {
+		t.Fatalf("library query = %q", api.query)
+	}
+
+	api.expire = true
+	progress := Progress{Version: 2, Percent: 0.5, Locator: Locator{Schema: 2, Segment: LocatorSegment{Key: "k", Occurrence: 1, Ordinal: 3}}}
+	if err := c.SaveProgress(t.Context(), "the-snail", progress); err != nil {
+		t.Fatalf("save progress: %v", err)
+	}
+	got, err := c.Progress(t.Context(), "the-snail")
+	if err != nil || got == nil || *got != progress {
+		t.Fatalf("progress = %#v, error %v", got, err)
+	}
+	if api.unlocks != 2 {
+		t.Fatalf("unlocks = %d, want 2", api.unlocks)
+	}
+
+	_, err = c.Draft(t.Context(), DraftRequest{Slug: "x"})
+	if err == nil || !strings.Contains(err.Error(), "title: title is required") || !strings.Contains(err.Error(), "request ID req-1") {
+		t.Fatalf("draft error = %v", err)
+	}
+	if api.adminKey != "ppak_editor" {
+		t.Fatalf("admin key = %q", api.adminKey)
+	}
+}
+
+func TestClientReportsAWrongPasscodeAndBadURLs(t *testing.T) {
+	api := &fakeAPI{}
+	c, err := New(api.server(t).URL, "000000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = c.Unlock(t.Context())
+	var apiErr *Error
+	if err == nil || !strings.HasPrefix(err.Error(), "unlock:") || !strings.Contains(err.Error(), "invalid passcode") ||
+		!errors.As(err, &apiErr) || apiErr.Status != http.StatusUnauthorized {
+		t.Fatalf("unlock error = %v", err)
+	}
+
+	for _, raw := range []string{"", "ftp://example.test", "http://"} {
+		if _, err := New(raw, "123456"); err == nil {
+			t.Fatalf("New(%q) succeeded", raw)
+		}
+	}
+	if _, err := New("https://example.test", ""); err == nil {
+		t.Fatal("New without a passcode succeeded")
+	}
+}
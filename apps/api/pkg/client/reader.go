@@ -0,0 +1,206 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"pandapages/api/internal/model"
+)
+
+// Library returns one page of the stories the Reader lists.
+func (c *Client) Library(ctx context.Context, page PageRequest) (Library, error) {
+	var out Library
+	err := c.Do(ctx, http.MethodGet, "/api/v1/library"+pageQuery(page), nil, &out)
+	return out, err
+}
+
+// DecodableLibrary returns one page of the stories a child taught the
+// phonics progression's sets 1 to set can read.
+func (c *Client) DecodableLibrary(ctx context.Context, page PageRequest, set int) (Library, error) {
+	query := pageQuery(page)
+	if query == "" {
+		query = "?"
+	} else {
+		query += "&"
+	}
+	var out Library
+	err := c.Do(ctx, http.MethodGet, "/api/v1/library"+query+"decodable="+strconv.Itoa(set), nil, &out)
+	return out, err
+}
+
+// Phonics returns the phonics progression DecodableLibrary counts sets of.
+func (c *Client) Phonics(ctx context.Context) (PhonicsProgression, error) {
+	var out PhonicsProgression
+	err := c.Do(ctx, http.MethodGet, "/api/v1/phonics", nil, &out)
+	return out, err
+}
+
+// Story returns the published version of a story, segment HTML included.
+func (c *Client) Story(ctx context.Context, slug string) (ReaderStory, error) {
+	var out ReaderStory
+	err := c.Do(ctx, http.MethodGet, "/api/v1/reader/"+url.PathEscape(slug), nil, &out)
+	return out, err
+}
+
+// Segments returns the segments of a story's published version without
+// their HTML, as an outline view needs.
+func (c *Client) Segments(ctx context.Context, slug string) ([]ReaderSegment, error) {
+	var out ReaderStory
+	if err := c.Do(ctx, http.MethodGet, "/api/v1/reader/"+url.PathEscape(slug)+"?include=segments", nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Segments, nil
+}
+
+// Progress returns the reader's place in a story, or nil before they have
+// opened it.
+func (c *Client) Progress(ctx context.Context, slug string) (*Progress, error) {
+	var out struct {
+		Progress *Progress `json:"progress"`
+	}
+	err := c.Do(ctx, http.MethodGet, "/api/v1/progress/"+url.PathEscape(slug), nil, &out)
+	return out.Progress, err
+}
+
+// SaveProgress records the reader's place in a story.
+func (c *Client) SaveProgress(ctx context.Context, slug string, progress Progress) error {
+	return c.Do(ctx, http.MethodPut, "/api/v1/progress/"+url.PathEscape(slug), progress, nil)
+}
+
+// Continue returns the stories read most recently, newest first.
+func (c *Client) Continue(ctx context.Context) (ContinueResponse, error) {
+	var out ContinueResponse
+	err := c.Do(ctx, http.MethodGet, "/api/v1/continue", nil, &out)
+	return out, err
+}
+
+// Queue returns the stories lined up to read next, first story first.
+func (c *Client) Queue(ctx context.Context) ([]QueueItem, error) {
+	var out struct {
+		Items []QueueItem `json:"items"`
+	}
+	err := c.Do(ctx, http.MethodGet, "/api/v1/queue", nil, &out)
+	return out.Items, err
+}
+
+// QueueAdd puts a story at the end of the queue; a story already queued
+// keeps its place.
+func (c *Client) QueueAdd(ctx context.Context, slug string) ([]QueueItem, error) {
+	var out struct {
+		Items []QueueItem `json:"items"`
+	}
+	err := c.Do(ctx, http.MethodPost, "/api/v1/queue/"+url.PathEscape(slug), nil, &out)
+	return out.Items, err
+}
+
+// QueueRemove takes a story out of the queue.
+func (c *Client) QueueRemove(ctx context.Context, slug string) ([]QueueItem, error) {
+	var out struct {
+		Items []QueueItem `json:"items"`
+	}
+	err := c.Do(ctx, http.MethodDelete, "/api/v1/queue/"+url.PathEscape(slug), nil, &out)
+	return out.Items, err
+}
+
+// QueueReorder puts the queue in the order slugs gives, which must name
+// every queued story once.
+func (c *Client) QueueReorder(ctx context.Context, slugs []string) ([]QueueItem, error) {
+	var out struct {
+		Items []QueueItem `json:"items"`
+	}
+	err := c.Do(ctx, http.MethodPut, "/api/v1/queue", model.QueueReorderRequest{Slugs: slugs}, &out)
+	return out.Items, err
+}
+
+// Settings returns the active child and prompt profiles.
+func (c *Client) Settings(ctx context.Context) (Settings, error) {
+	var out Settings
+	err := c.Do(ctx, http.MethodGet, "/api/v1/settings", nil, &out)
+	return out, err
+}
+
+// SaveSettings saves the active child and prompt profiles.
+func (c *Client) SaveSettings(ctx context.Context, settings SettingsUpsert) (Settings, error) {
+	var out Settings
+	err := c.Do(ctx, http.MethodPut, "/api/v1/settings", settings, &out)
+	return out, err
+}
+
+// SearchSemantic returns the published stories closest in meaning to query,
+// best match first.
+func (c *Client) SearchSemantic(ctx context.Context, query string) ([]SemanticSearchResult, error) {
+	var out model.SemanticSearchResponse
+	if err := c.Do(ctx, http.MethodGet, "/api/v1/search/semantic?q="+url.QueryEscape(query), nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Items, nil
+}
+
+// Quiz returns the approved comprehension questions of a story's published
+// version, by chapter.
+func (c *Client) Quiz(ctx context.Context, slug string) (StoryQuiz, error) {
+	var out StoryQuiz
+	err := c.Do(ctx, http.MethodGet, "/api/v1/story/"+url.PathEscape(slug)+"/quiz", nil, &out)
+	return out, err
+}
+
+// ChildDashboard returns one child profile's reading over the last four
+// weeks.
+func (c *Client) ChildDashboard(ctx context.Context, childID string) (ChildDashboard, error) {
+	var out ChildDashboard
+	err := c.Do(ctx, http.MethodGet, "/api/v1/children/"+url.PathEscape(childID)+"/dashboard", nil, &out)
+	return out, err
+}
+
+// Discussion returns the questions for a grown-up to talk over after each
+// chapter of a story's published version.
+func (c *Client) Discussion(ctx context.Context, slug string) (StoryDiscussion, error) {
+	var out StoryDiscussion
+	err := c.Do(ctx, http.MethodGet, "/api/v1/story/"+url.PathEscape(slug)+"/discussion", nil, &out)
+	return out, err
+}
+
+// React records the profile's reaction to a story, replacing any earlier
+// one, and returns the story's reaction counts.
+func (c *Client) React(ctx context.Context, slug string, reaction StoryReaction) (ReactionCounts, error) {
+	var out model.StoryReactionResponse
+	err := c.Do(ctx, http.MethodPost, "/api/v1/story/"+url.PathEscape(slug)+"/reaction", model.StoryReactionRequest{Reaction: reaction}, &out)
+	return out.Counts, err
+}
+
+// Unreact takes back the profile's reaction to a story and returns the
+// story's reaction counts.
+func (c *Client) Unreact(ctx context.Context, slug string) (ReactionCounts, error) {
+	var out model.StoryReactionResponse
+	err := c.Do(ctx, http.MethodDelete, "/api/v1/story/"+url.PathEscape(slug)+"/reaction", nil, &out)
+	return out.Counts, err
+}
+
+// Define returns the child-safe senses of a word tapped in a story written
+// in language, which may be empty for English.
+func (c *Client) Define(ctx context.Context, word, language string) (Definition, error) {
+	query := url.Values{"word": {word}}
+	if language != "" {
+		query.Set("lang", language)
+	}
+	var out Definition
+	err := c.Do(ctx, http.MethodGet, "/api/v1/define?"+query.Encode(), nil, &out)
+	return out, err
+}
+
+// SendStory emails a story as an EPUB to one of the account's delivery
+// destinations. The delivery is queued; Delivery reports how it went.
+func (c *Client) SendStory(ctx context.Context, slug, destinationID string) (StoryDelivery, error) {
+	var out StoryDelivery
+	err := c.Do(ctx, http.MethodPost, "/api/v1/story/"+url.PathEscape(slug)+"/send", model.StorySendRequest{DestinationID: destinationID}, &out)
+	return out, err
+}
+
+// Delivery returns the status of a story sent with SendStory.
+func (c *Client) Delivery(ctx context.Context, id string) (StoryDelivery, error) {
+	var out StoryDelivery
+	err := c.Do(ctx, http.MethodGet, "/api/v1/deliveries/"+url.PathEscape(id), nil, &out)
+	return out, err
+}
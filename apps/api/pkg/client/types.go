@@ -0,0 +1,71 @@
+package client
+
+import (
+	"pandapages/api/internal/model"
+	"pandapages/api/internal/readercontract"
+)
+
+// The API's request and response types, named here so code outside this
+// module can use them.
+type (
+	FieldError      = model.FieldError
+	ValidationIssue = model.AdminValidationIssue
+	PageRequest     = model.PageRequest
+
+	Library          = model.LibraryReadModel
+	StoryItem        = model.StoryItem
+	StoryVariant     = model.StoryVariant
+	ReaderStory      = model.ReaderStory
+	ReaderSegment    = model.ReaderSegment
+	SegmentAudio     = model.SegmentAudio
+	WordTiming       = model.WordTiming
+	Progress         = model.Progress
+	Locator          = readercontract.Locator
+	LocatorSegment   = readercontract.LocatorSegment
+	LocatorChapter   = readercontract.LocatorChapter
+	ContinueResponse = model.ContinueResponse
+	QueueItem        = model.QueueItem
+	StoryReaction    = model.StoryReaction
+	ReactionCounts   = model.ReactionCounts
+	Settings         = model.SettingsPayload
+	SettingsUpsert   = model.SettingsUpsert
+
+	DeliveryDestination = model.DeliveryDestination
+	StoryDelivery       = model.StoryDelivery
+
+	PushSubscription = model.PushSubscription
+	PushSettings     = model.PushSettings
+	DigestSettings   = model.DigestSettings
+	BedtimeSettings  = model.BedtimeSettings
+	ReadingReport    = model.ReadingReport
+	ChildDashboard   = model.ChildDashboard
+	ClientEvent      = model.ClientEvent
+
+	SemanticSearchResult = model.SemanticSearchResult
+
+	StoryQuiz    = model.StoryQuiz
+	ChapterQuiz  = model.ChapterQuiz
+	QuizQuestion = model.QuizQuestion
+	Definition   = model.Definition
+	WordSense    = model.WordSense
+
+	StoryDiscussion   = model.StoryDiscussion
+	ChapterDiscussion = model.ChapterDiscussion
+
+	PhonicsProgression = model.PhonicsProgression
+	PhonicsSet         = model.PhonicsSet
+
+	DraftRequest      = model.AdminDraftUpsertRequest
+	DraftResponse     = model.AdminDraftUpsertResponse
+	StoryStatus       = model.AdminStoryStatusResponse
+	AdminStory        = model.AdminStoryDetailResponse
+	AdminStoriesPage  = model.AdminStoriesListResponse
+	NarrationJob      = model.NarrationJob
+	SearchReindexJob  = model.SearchReindexJob
+	GenerateRequest   = model.AdminGenerateRequest
+	GenerateResponse  = model.AdminGenerateResponse
+	StoryGeneration   = model.StoryGeneration
+	StoryModeration   = model.StoryModeration
+	TranslateRequest  = model.AdminTranslateRequest
+	TranslateResponse = model.AdminTranslateResponse
+)
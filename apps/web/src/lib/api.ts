@@ -2,6 +2,7 @@ import {
   isReaderContentKey,
   parseReaderLocatorV2,
   type ReaderLocatorV2,
+  type ReaderPronunciation,
   type ReaderSegmentKind,
   type ReaderStorySegment,
 } from './reader-locator-v2'
@@ -437,27 +438,91 @@ export function parseLibraryResponse(value: unknown): LibraryResponse {
   return { items, unavailableItemCount }
 }
 
+function libraryNextCursor(value: unknown): string | null {
+  if (
+    !isRecord(value) ||
+    !Object.hasOwn(value, 'nextCursor') ||
+    value.nextCursor === null
+  ) {
+    return null
+  }
+  if (typeof value.nextCursor !== 'string' || value.nextCursor.length === 0) {
+    return invalidLibraryResponse()
+  }
+  return value.nextCursor
+}
+
+// The Library arrives a page at a time; following nextCursor keeps the shelf
+// complete however many stories the account has.
 export async function getLibrary(): Promise<LibraryResponse> {
-  const data = await request<unknown>('/api/v1/library')
-  return parseLibraryResponse(data)
+  let data = await request<unknown>('/api/v1/library')
+  const library = parseLibraryResponse(data)
+  const slugs = new Set(library.items.map((item) => item.slug))
+  for (
+    let cursor = libraryNextCursor(data);
+    cursor !== null;
+    cursor = libraryNextCursor(data)
+  ) {
+    data = await request<unknown>(
+      `/api/v1/library?cursor=${encodeURIComponent(cursor)}`,
+    )
+    const page = parseLibraryResponse(data)
+    for (const item of page.items) {
+      if (slugs.has(item.slug)) return invalidLibraryResponse()
+      slugs.add(item.slug)
+      library.items.push(item)
+    }
+    library.unavailableItemCount += page.unavailableItemCount
+  }
+  return library
 }
 
 /* ----------------------------- Story ---------------------------- */
 
+export type ReaderContributor = {
+  name: string
+  role: string
+}
+
+export type ReaderReadability = {
+  words: number
+  sentences: number
+  syllables: number
+  avgWordsPerSentence: number
+  avgSyllablesPerWord: number
+  avgWordLength: number
+  fleschReadingEase: number
+  fleschKincaidGrade: number
+}
+
+const readerReadabilityKeys = [
+  'words',
+  'sentences',
+  'syllables',
+  'avgWordsPerSentence',
+  'avgSyllablesPerWord',
+  'avgWordLength',
+  'fleschReadingEase',
+  'fleschKincaidGrade',
+] as const
+
 export type ReaderStoryPayload = {
   slug: string
   title: string
   author: string | null
   language: string
   version: number
+  contributors?: ReaderContributor[]
+  readability?: ReaderReadability | null
   segments: ReaderStorySegment[]
 }
 
 function hasExactKeys(
   record: Record<string, unknown>,
   required: readonly string[],
+  optional: readonly string[] = [],
 ): boolean {
-  const allowed = new Set(required)
+  const allowed = new Set([...required, ...optional])
   return (
     required.every((key) => Object.hasOwn(record, key)) &&
     Object.keys(record).every((key) => allowed.has(key))
@@ -481,9 +546,25 @@ function parseReaderSegment(value: unknown): ReaderStorySegment {
       'chapterOccurrence',
       'renderedHtml',
       'wordCount',
-    ]) ||
+    ], ['speaker', 'pronunciations']) ||
     !isPositiveInteger(value.ordinal) ||
-    !['heading', 'paragraph', 'other'].includes(String(value.kind)) ||
+    ![
+      'heading',
+      'paragraph',
+      'other',
+      'pagebreak',
+      'verse',
+      'aside',
+      'scene-break',
+    ].includes(
+      String(value.kind),
+    ) ||
+    (value.speaker !== undefined &&
+      (value.kind !== 'paragraph' ||
+        typeof value.speaker !== 'string' ||
+        value.speaker.trim() === '')) ||
+    (value.pronunciations !== undefined &&
+      !isReaderPronunciationList(value.pronunciations)) ||
     !isReaderContentKey(value.contentKey) ||
     !isPositiveInteger(value.contentOccurrence) ||
     typeof value.renderedHtml !== 'string' ||
@@ -523,9 +604,30 @@ function parseReaderSegment(value: unknown): ReaderStorySegment {
     chapterOccurrence: hasChapter ? Number(value.chapterOccurrence) : null,
     renderedHtml: value.renderedHtml,
     wordCount: Number(value.wordCount),
+    ...(typeof value.speaker === 'string' ? { speaker: value.speaker } : {}),
+    ...(Array.isArray(value.pronunciations)
+      ? { pronunciations: value.pronunciations as ReaderPronunciation[] }
+      : {}),
   }
 }
 
+function isReaderPronunciationList(value: unknown): boolean {
+  return (
+    Array.isArray(value) &&
+    value.length > 0 &&
+    value.every(
+      (hint) =>
+        isRecord(hint) &&
+        hasExactKeys(hint, ['text', 'say'], ['ipa']) &&
+        typeof hint.text === 'string' &&
+        hint.text.trim() !== '' &&
+        typeof hint.say === 'string' &&
+        hint.say.trim() !== '' &&
+        (hint.ipa === undefined || typeof hint.ipa === 'string'),
+    )
+  )
+}
+
 export function parseReaderStoryPayload(value: unknown): ReaderStoryPayload {
   if (
     !isRecord(value) ||
@@ -536,7 +638,7 @@ export function parseReaderStoryPayload(value: unknown): ReaderStoryPayload {
       'language',
       'version',
       'segments',
-    ]) ||
+    ], ['contributors', 'readability']) ||
     typeof value.slug !== 'string' ||
     value.slug.length === 0 ||
     typeof value.title !== 'string' ||
@@ -544,7 +646,11 @@ export function parseReaderStoryPayload(value: unknown): ReaderStoryPayload {
     typeof value.language !== 'string' ||
     !isPositiveInteger(value.version) ||
     !Array.isArray(value.segments) ||
-    value.segments.length === 0
+    value.segments.length === 0 ||
+    (value.contributors !== undefined && !isReaderContributorList(value.contributors)) ||
+    (value.readability !== undefined &&
+      value.readability !== null &&
+      !isReaderReadability(value.readability))
   ) {
     throw new Error('Invalid Reader response')
   }
@@ -562,10 +668,36 @@ export function parseReaderStoryPayload(value: unknown): ReaderStoryPayload {
     author: value.author,
     language: value.language,
     version: value.version,
+    ...(Array.isArray(value.contributors)
+      ? { contributors: value.contributors as ReaderContributor[] }
+      : {}),
+    ...(value.readability !== undefined
+      ? { readability: value.readability as ReaderReadability | null }
+      : {}),
     segments,
   }
 }
 
+function isReaderReadability(value: unknown): boolean {
+  if (!isRecord(value) || !hasExactKeys(value, readerReadabilityKeys)) {
+    return false
+  }
+  return readerReadabilityKeys.every((key) => Number.isFinite(value[key]))
+}
+
+function isReaderContributorList(value: unknown): boolean {
+  return (
+    Array.isArray(value) &&
+    value.every(
+      (contributor) =>
+        isRecord(contributor) &&
+        hasExactKeys(contributor, ['name', 'role']) &&
+        typeof contributor.name === 'string' &&
+        typeof contributor.role === 'string',
+    )
+  )
+}
+
 export async function getReaderStory(
   slug: string,
   signal?: AbortSignal,
@@ -1112,11 +1244,44 @@ export async function adminDraftUpsertStory(
   return parseAdminDraftUpsertResponse(data)
 }
 
+function adminNextCursor(value: unknown): string | null {
+  const record = adminRecord(value)
+  if (!Object.hasOwn(record, 'nextCursor') || record.nextCursor === null) {
+    return null
+  }
+  if (
+    typeof record.nextCursor !== 'string' ||
+    record.nextCursor.length === 0
+  ) {
+    throw new Error('Invalid admin response')
+  }
+  return record.nextCursor
+}
+
+// The catalogue is paginated; every page is fetched so the studio list stays
+// complete.
 export async function adminListStories(
   signal?: AbortSignal,
 ): Promise<AdminStoriesListResponse> {
-  const data = await request<unknown>('/api/v1/admin/stories', { signal })
-  return parseAdminStoriesListResponse(data)
+  let data = await request<unknown>('/api/v1/admin/stories', { signal })
+  const catalogue = parseAdminStoriesListResponse(data)
+  const slugs = new Set(catalogue.items.map((item) => item.slug))
+  for (
+    let cursor = adminNextCursor(data);
+    cursor !== null;
+    cursor = adminNextCursor(data)
+  ) {
+    data = await request<unknown>(
+      `/api/v1/admin/stories?cursor=${encodeURIComponent(cursor)}`,
+      { signal },
+    )
+    for (const item of parseAdminStoriesListResponse(data).items) {
+      if (slugs.has(item.slug)) throw new Error('Invalid admin response')
+      slugs.add(item.slug)
+      catalogue.items.push(item)
+    }
+  }
+  return catalogue
 }
 
 export async function adminGetStory(
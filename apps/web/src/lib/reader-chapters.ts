@@ -30,12 +30,16 @@ export function readerHeadingText(renderedHtml: string): string {
 export function buildReaderChapters(
   segments: readonly ReaderStorySegment[],
 ): ReaderChapter[] {
-  return segments.flatMap((segment) => {
+  // A chapter opens at the heading where its identity first appears; the
+  // server decides which heading level that is.
+  return segments.flatMap((segment, index) => {
+    const previous = index > 0 ? segments[index - 1] : null
     if (
       segment.kind !== 'heading' ||
-      segment.headingLevel !== 2 ||
       segment.chapterKey === null ||
-      segment.chapterOccurrence === null
+      segment.chapterOccurrence === null ||
+      (previous?.chapterKey === segment.chapterKey &&
+        previous?.chapterOccurrence === segment.chapterOccurrence)
     ) return []
     return [{
       key: segment.chapterKey,
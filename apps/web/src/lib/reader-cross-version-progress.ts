@@ -66,7 +66,15 @@ function validCurrentSegments(
     if (
       !isPositiveInteger(segment.ordinal) ||
       segment.ordinal <= previousOrdinal ||
-      !['heading', 'paragraph', 'other'].includes(segment.kind) ||
+      ![
+        'heading',
+        'paragraph',
+        'other',
+        'pagebreak',
+        'verse',
+        'aside',
+        'scene-break',
+      ].includes(segment.kind) ||
       !headingValid ||
       !isReaderContentKey(segment.contentKey) ||
       !isPositiveInteger(segment.contentOccurrence) ||
@@ -201,7 +209,6 @@ export function mapReaderProgressAcrossVersions({
     const chapterHeading = currentSegments.find(
       (segment) =>
         segment.kind === 'heading' &&
-        segment.headingLevel === 2 &&
         segment.chapterKey === locator.chapter?.key &&
         segment.chapterOccurrence === locator.chapter?.occurrence,
     )
@@ -1,4 +1,11 @@
-export type ReaderSegmentKind = 'heading' | 'paragraph' | 'other'
+export type ReaderSegmentKind =
+  | 'heading'
+  | 'paragraph'
+  | 'other'
+  | 'pagebreak'
+  | 'verse'
+  | 'aside'
+  | 'scene-break'
 
 export type ReaderStorySegment = {
   ordinal: number
@@ -10,6 +17,14 @@ export type ReaderStorySegment = {
   chapterOccurrence: number | null
   renderedHtml: string
   wordCount: number
+  speaker?: string
+  pronunciations?: ReaderPronunciation[]
+}
+
+export type ReaderPronunciation = {
+  text: string
+  say: string
+  ipa?: string
 }
 
 export type ReaderLocatorV2 = {
@@ -333,6 +333,9 @@ function readerSegmentEstimatedLines(
   segment: ReaderStorySegment,
   capacity: ReaderPageCapacity,
 ): number {
+  // An authored page break only ends a page; it takes no space on it.
+  if (isPageBreak(segment)) return 0
+
   const words = safeWordCount(segment)
   const workload = readerSegmentTextWorkload(segment.renderedHtml)
   if (segment.kind === 'heading') {
@@ -368,16 +371,28 @@ function readerSegmentEstimatedLines(
         capacity.charactersPerLine,
     ),
     Math.ceil(workload.weightedCharacters / capacity.charactersPerLine),
+    // Verse keeps its authored lines, and a blank line between stanzas.
+    segment.kind === 'verse' ? verseLines(segment.renderedHtml) : 0,
   )
   // Every coherent segment is a block. Reserving at least one line for block
   // separation prevents many tiny paragraphs from being packed unrealistically.
-  return textLines + (segment.kind === 'other' ? 2 : 1)
+  return textLines + (segment.kind === 'other' || segment.kind === 'aside' ? 2 : 1)
+}
+
+function verseLines(renderedHtml: string): number {
+  const breaks = renderedHtml.match(/<br\s*\/?>/gi)?.length ?? 0
+  const stanzas = renderedHtml.match(/<\/p>/gi)?.length ?? 0
+  return breaks + Math.max(0, stanzas * 2 - 1)
 }
 
 function isHeading(segment: ReaderStorySegment): boolean {
   return segment.kind === 'heading'
 }
 
+function isPageBreak(segment: ReaderStorySegment): boolean {
+  return segment.kind === 'pagebreak'
+}
+
 function rebalanceTinyFinalPage(
   pages: ReaderPage[],
   estimates: ReadonlyMap<ReaderStorySegment, number>,
@@ -387,6 +402,8 @@ function rebalanceTinyFinalPage(
   const previous = pages.at(-2)
   const final = pages.at(-1)
   if (!previous || !final || previous.oversized || final.oversized) return
+  const authoredBreak = previous.segments.at(-1)
+  if (authoredBreak && isPageBreak(authoredBreak)) return
 
   const minimumUsefulLines = Math.max(2, Math.ceil(capacityLines * 0.35))
   while (
@@ -467,6 +484,15 @@ export function buildReaderPages(
     const segmentLines = estimates[index]
     if (!segment || segmentLines === undefined) continue
 
+    // A break straight after a page boundary closes that page rather than
+    // leaving an empty one.
+    const lastPage = pages.at(-1)
+    if (isPageBreak(segment) && pageSegments.length === 0 && lastPage) {
+      lastPage.segments = [...lastPage.segments, segment]
+      lastPage.endOrdinal = segment.ordinal
+      continue
+    }
+
     const forcedOversized =
       options.forcedOversizedSegmentIdentities?.has(
         readerPageSegmentIdentity(segment),
@@ -507,6 +533,7 @@ export function buildReaderPages(
 
     pageSegments.push(segment)
     estimatedLines += segmentLines
+    if (isPageBreak(segment)) flush()
   }
   flush()
   rebalanceTinyFinalPage(pages, estimatesBySegment, capacity.capacityLines)
@@ -243,6 +243,45 @@ test('Library boundary ignores harmless additive fields but rejects unsafe data
   }
 })
 
+test('getLibrary follows nextCursor until the last page', async (t) => {
+  const originalFetch = globalThis.fetch
+  t.after(() => {
+    globalThis.fetch = originalFetch
+  })
+
+  const { module: api } = await apiModule()
+  const pages = {
+    '/api/v1/library': { items: [story()], unavailableItemCount: 1, nextCursor: 'page/2' },
+    '/api/v1/library?cursor=page%2F2': {
+      items: [story({ slug: 'the-snow-queen' })],
+      unavailableItemCount: 2,
+      nextCursor: null,
+    },
+  }
+  const urls = []
+  globalThis.fetch = async (url) => {
+    urls.push(url)
+    return new Response(JSON.stringify(pages[url]), {
+      status: 200,
+      headers: { 'Content-Type': 'application/json' },
+    })
+  }
+
+  const library = await api.getLibrary()
+  assert.deepEqual(urls, Object.keys(pages))
+  assert.deepEqual(
+    library.items.map((item) => item.slug),
+    [story().slug, 'the-snow-queen'],
+  )
+  assert.equal(library.unavailableItemCount, 3)
+
+  pages['/api/v1/library?cursor=page%2F2'].items = [story()]
+  await assert.rejects(
+    api.getLibrary(),
+    (error) => api.isInvalidLibraryResponseError(error),
+  )
+})
+
 test('getLibrary uses the fixed credentialed route and rejects malformed success bodies', async (t) => {
   const originalFetch = globalThis.fetch
   t.after(() => {
@@ -269,7 +308,6 @@ test('getLibrary uses the fixed credentialed route and rejects malformed success
     [['/api/v1/library', 'include']],
   )
   assert.match(source, /request<unknown>\('\/api\/v1\/library'\)/)
-  assert.match(source, /return parseLibraryResponse\(data\)/)
 
   globalThis.fetch = async () =>
     new Response(JSON.stringify({ items: [{ slug: 'incomplete' }] }), {
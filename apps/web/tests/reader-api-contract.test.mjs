@@ -44,6 +44,23 @@ function validStory(overrides = {}) {
 test('Reader payload boundary accepts one coherent strict response', async () => {
   const api = await apiModule()
   assert.deepEqual(api.parseReaderStoryPayload(validStory()), validStory())
+  const credited = validStory({ contributors: [{ name: 'Mei', role: 'illustrator' }] })
+  assert.deepEqual(api.parseReaderStoryPayload(credited), credited)
+  const measured = validStory({
+    readability: {
+      words: 2,
+      sentences: 1,
+      syllables: 3,
+      avgWordsPerSentence: 2,
+      avgSyllablesPerWord: 1.5,
+      avgWordLength: 3.5,
+      fleschReadingEase: 77.9,
+      fleschKincaidGrade: 2.9,
+    },
+  })
+  assert.deepEqual(api.parseReaderStoryPayload(measured), measured)
+  const unmeasured = validStory({ readability: null })
+  assert.deepEqual(api.parseReaderStoryPayload(unmeasured), unmeasured)
   for (const invalid of [
     { ...validStory(), html: '<h1>duplicate</h1>' },
     { ...validStory(), version: 0 },
@@ -58,6 +75,8 @@ test('Reader payload boundary accepts one coherent strict response', async () =>
       ],
     },
     { ...validStory(), segments: [validSegment({ markdown: '# private' })] },
+    { ...validStory(), contributors: [{ name: 'Mei' }] },
+    { ...validStory(), readability: { words: 2 } },
   ]) {
     assert.throws(() => api.parseReaderStoryPayload(invalid), /Reader/)
   }
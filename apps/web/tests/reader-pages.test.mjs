@@ -103,6 +103,39 @@ test('capacity covers mobile, landscape, tablet, desktop and preference changes'
   assert.deepEqual(repeated, first)
 })
 
+test('authored page breaks end pages without adding empty ones', async () => {
+  const pages = await moduleAt('../src/lib/reader-pages.ts')
+  const pageBreak = (ordinal) =>
+    segment({
+      ordinal,
+      kind: 'pagebreak',
+      renderedHtml: '<div class="page-break" role="separator"></div>',
+      wordCount: 0,
+    })
+  const segments = [
+    segment({ ordinal: 1, wordCount: 6 }),
+    pageBreak(2),
+    pageBreak(3),
+    segment({ ordinal: 4, wordCount: 6 }),
+    pageBreak(5),
+    segment({ ordinal: 6, wordCount: 6 }),
+  ]
+  const result = pages.buildReaderPages(segments, metrics())
+
+  assert.deepEqual(
+    result.map((page) => [page.startOrdinal, page.endOrdinal]),
+    [
+      [1, 3],
+      [4, 5],
+      [6, 6],
+    ],
+  )
+  assert.deepEqual(
+    result.flatMap((page) => page.segments.map((item) => item.ordinal)),
+    [1, 2, 3, 4, 5, 6],
+  )
+})
+
 test('grouping includes every segment once and keeps a short H2 with following content', async () => {
   const pages = await moduleAt('../src/lib/reader-pages.ts')
   const segments = [
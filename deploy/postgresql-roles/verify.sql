@@ -164,7 +164,42 @@ WITH runtime_table(name) AS (
     ('story_contributors'),
     ('story_sections'),
     ('story_segments'),
-    ('story_versions')
+    ('story_versions'),
+    -- Tables later migrations made runtime, by migration and request.
+    ('admin_audit_log'),          -- 00015, synth-3863
+    ('admin_users'),              -- 00016, synth-3864
+    ('tags'),                     -- 00017, synth-3865
+    ('story_tags'),               -- 00017, synth-3865
+    ('webhooks'),                 -- 00020, synth-3874
+    ('webhook_deliveries'),       -- 00020, synth-3874
+    ('admin_uploads'),            -- 00021, synth-3876
+    ('admin_upload_chunks'),      -- 00021, synth-3876
+    ('media'),                    -- 00023, synth-3884
+    ('render_jobs'),              -- 00033, synth-3916
+    ('idempotency_keys'),         -- 00034, synth-3944
+    ('narration_jobs'),           -- 00037, synth-3957
+    ('segment_audio'),            -- 00037, synth-3957
+    ('generation_jobs'),          -- 00038, synth-3958
+    ('delivery_destinations'),    -- 00040, synth-3961
+    ('story_deliveries'),         -- 00040, synth-3961
+    ('story_version_embeddings'), -- 00041, synth-3963
+    ('story_quizzes'),            -- 00043, synth-3967
+    ('dictionary_entries'),       -- 00044, synth-3968
+    ('story_version_phonics'),    -- 00045, synth-3969
+    ('alignment_jobs'),           -- 00046, synth-3970
+    ('push_vapid_keys'),          -- 00047, synth-3971
+    ('push_subscriptions'),       -- 00047, synth-3971
+    ('push_settings'),            -- 00047, synth-3971
+    ('push_deliveries'),          -- 00047, synth-3971
+    ('reading_activity'),         -- 00048, synth-3972
+    ('digest_settings'),          -- 00048, synth-3972
+    ('story_event_counts'),       -- 00049, synth-3973
+    ('blob_garbage'),             -- 00050, synth-3974
+    ('reading_queue'),            -- 00052, synth-3976
+    ('story_reactions'),          -- 00053, synth-3977
+    ('story_discussions'),        -- 00054, synth-3978
+    ('bedtime_settings'),         -- 00055, synth-3980
+    ('search_reindex_jobs')       -- 00057, synth-3963
 ), checked AS (
   SELECT
     runtime_table.name,
@@ -182,7 +217,7 @@ WITH runtime_table(name) AS (
     AND class.relnamespace = 'public'::regnamespace
     AND class.relkind IN ('r', 'p')
 )
-SELECT count(*) = 12 AND bool_and(
+SELECT count(*) = 46 AND bool_and(
   oid IS NOT NULL
   AND can_select
   AND can_insert